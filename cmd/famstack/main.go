@@ -18,7 +18,14 @@ func main() {
 			cmds.StartCommand(),
 			cmds.EncryptionCommand(),
 			cmds.UserCommand(),
+			cmds.LockoutsCommand(),
 			cmds.UpdateCommand(),
+			cmds.MigrateCommand(),
+			cmds.SeedCommand(),
+			cmds.StorageCommand(),
+			cmds.DoctorCommand(),
+			cmds.InstallServiceCommand(),
+			cmds.UninstallServiceCommand(),
 			cmds.VersionCommand(),
 		},
 	}
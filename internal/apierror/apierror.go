@@ -0,0 +1,125 @@
+// Package apierror writes API errors in one JSON envelope, so clients can
+// branch on a stable code instead of parsing messages:
+//
+//	{"error": {"code": "not_found", "message": "Task not found"}}
+//
+// Validation failures add the offending fields:
+//
+//	{"error": {"code": "validation_failed", "message": "...",
+//	           "field_errors": [{"field": "title", "message": "title is required"}]}}
+//
+// And a shared session refused something its member's password would allow
+// says how to upgrade:
+//
+//	{"error": {"code": "upgrade_required", "message": "...",
+//	           "upgrade": {"entity": "task", "action": "delete", "endpoint": "/auth/upgrade"}}}
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"famstack/internal/validation"
+)
+
+// Code identifies the kind of error
+type Code string
+
+const (
+	CodeBadRequest       Code = "bad_request"
+	CodeValidationFailed Code = "validation_failed"
+	CodeUnauthorized     Code = "unauthorized"
+	CodeForbidden        Code = "forbidden"
+	CodeUpgradeRequired  Code = "upgrade_required"
+	CodeNotFound         Code = "not_found"
+	CodeMethodNotAllowed Code = "method_not_allowed"
+	CodeConflict         Code = "conflict"
+	CodeTooLarge         Code = "payload_too_large"
+	CodeRateLimited      Code = "rate_limited"
+	CodeInternal         Code = "internal_error"
+	CodeUnavailable      Code = "unavailable"
+)
+
+// Body is the error object inside the envelope
+type Body struct {
+	Code        Code                        `json:"code"`
+	Message     string                      `json:"message"`
+	FieldErrors validation.ValidationErrors `json:"field_errors,omitempty"`
+	Upgrade     *Upgrade                    `json:"upgrade,omitempty"`
+}
+
+// Upgrade is what a shared session needs to upgrade to for the request
+type Upgrade struct {
+	Entity   string `json:"entity"`
+	Action   string `json:"action"`
+	Endpoint string `json:"endpoint"`
+}
+
+// Envelope is the JSON written for every API error
+type Envelope struct {
+	Error Body `json:"error"`
+}
+
+// CodeForStatus returns the code that goes with an HTTP status
+func CodeForStatus(status int) Code {
+	switch status {
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodeTooLarge
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// Error writes message with the code that goes with status. It takes the
+// same arguments as http.Error, which it replaces in API handlers.
+func Error(w http.ResponseWriter, message string, status int) {
+	Write(w, status, Body{Code: CodeForStatus(status), Message: message})
+}
+
+// Validation writes a 400 listing the fields that failed validation
+func Validation(w http.ResponseWriter, errs validation.ValidationErrors) {
+	Write(w, http.StatusBadRequest, Body{
+		Code:        CodeValidationFailed,
+		Message:     "Validation failed",
+		FieldErrors: errs,
+	})
+}
+
+// WriteValidation writes a 400 when err holds validation errors and reports
+// whether it did
+func WriteValidation(w http.ResponseWriter, err error) bool {
+	var validationErrs validation.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return false
+	}
+	Validation(w, validationErrs)
+	return true
+}
+
+// Write writes body in the error envelope with status
+func Write(w http.ResponseWriter, status int, body Body) {
+	h := w.Header()
+	// Drop headers set for a success response that never came, as http.Error does
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(Envelope{Error: body}) // nolint:errcheck
+}
@@ -0,0 +1,38 @@
+package apierror
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"famstack/internal/validation"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_WritesEnvelopeWithStatusCode(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	Error(recorder, "Task not found", http.StatusNotFound)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":{"code":"not_found","message":"Task not found"}}`, recorder.Body.String())
+}
+
+func TestWriteValidation(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	assert.False(t, WriteValidation(recorder, fmt.Errorf("database is locked")))
+
+	errs := validation.ValidationErrors{{Field: "title", Message: "title is required"}}
+	assert.True(t, WriteValidation(recorder, fmt.Errorf("failed to create task: %w", errs)))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.JSONEq(t, `{"error":{"code":"validation_failed","message":"Validation failed",
+		"field_errors":[{"field":"title","message":"title is required"}]}}`, recorder.Body.String())
+}
+
+func TestCodeForStatus(t *testing.T) {
+	assert.Equal(t, CodeForbidden, CodeForStatus(http.StatusForbidden))
+	assert.Equal(t, CodeBadRequest, CodeForStatus(http.StatusUnprocessableEntity))
+	assert.Equal(t, CodeInternal, CodeForStatus(http.StatusBadGateway))
+}
@@ -0,0 +1,221 @@
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/ids"
+)
+
+// API tokens let scripts and home automation call the JSON API with an
+// Authorization: Bearer header instead of a browser session. A personal
+// token acts as the member who made it; a family token, which only admins
+// make, acts as a standard member of the family. Either way the token's
+// scopes cap what it can do.
+
+// APITokenPrefix starts every API token, telling them apart from session JWTs
+const APITokenPrefix = "fsk_"
+
+// API token kinds
+const (
+	APITokenPersonal = "personal"
+	APITokenFamily   = "family"
+)
+
+// apiTokenDisplayLength is how much of a token is kept to recognize it by
+const apiTokenDisplayLength = 12
+
+// apiTokenUseInterval limits how often a token's last use is written
+const apiTokenUseInterval = time.Minute
+
+// apiTokenEntities are the entities a scope can name
+var apiTokenEntities = []Entity{
+	EntityTask, EntityCalendar, EntitySchedule, EntityFamily, EntityUser, EntityIntegration, EntitySetting,
+}
+
+// APIToken is a token for calling the API, without the token itself
+type APIToken struct {
+	ID          string     `json:"id" db:"id"`
+	FamilyID    string     `json:"family_id" db:"family_id"`
+	MemberID    string     `json:"member_id" db:"member_id"`
+	Name        string     `json:"name" db:"name"`
+	Kind        string     `json:"kind" db:"kind"`
+	Scopes      []string   `json:"scopes"`
+	ScopeList   string     `json:"-" db:"scopes"`
+	TokenPrefix string     `json:"token_prefix" db:"token_prefix"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+// APIScopes returns every scope a token can have: read and write for each
+// entity, where write covers creating, changing and deleting
+func APIScopes() []string {
+	scopes := make([]string, 0, 2*len(apiTokenEntities))
+	for _, entity := range apiTokenEntities {
+		scopes = append(scopes, string(entity)+":read", string(entity)+":write")
+	}
+	return scopes
+}
+
+// scopeAllows reports whether scopes cover action on entity
+func scopeAllows(scopes []string, entity Entity, action Action) bool {
+	access := "write"
+	if action == ActionRead {
+		access = "read"
+	}
+	return slices.Contains(scopes, string(entity)+":"+access)
+}
+
+// CreateAPIToken makes a token for a member of familyID. The token is
+// returned once and only its hash is kept. A zero expiresIn makes a token
+// that doesn't expire.
+func (s *Service) CreateAPIToken(familyID, memberID, name, kind string, scopes []string, expiresIn time.Duration) (*APIToken, string, error) {
+	if kind != APITokenPersonal && kind != APITokenFamily {
+		return nil, "", fmt.Errorf("kind must be personal or family")
+	}
+	known := APIScopes()
+	for _, scope := range scopes {
+		if !slices.Contains(known, scope) {
+			return nil, "", fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+	token := APITokenPrefix + secret
+
+	now := time.Now().UTC()
+	apiToken := &APIToken{
+		ID:          ids.New("apitoken"),
+		FamilyID:    familyID,
+		MemberID:    memberID,
+		Name:        name,
+		Kind:        kind,
+		Scopes:      scopes,
+		ScopeList:   strings.Join(scopes, ","),
+		TokenPrefix: token[:apiTokenDisplayLength],
+		CreatedAt:   now,
+	}
+	if expiresIn > 0 {
+		expiresAt := now.Add(expiresIn)
+		apiToken.ExpiresAt = &expiresAt
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO api_tokens (id, family_id, member_id, name, kind, scopes, token_hash, token_prefix, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, apiToken.ID, apiToken.FamilyID, apiToken.MemberID, apiToken.Name, apiToken.Kind, apiToken.ScopeList,
+		hashLoginToken(token), apiToken.TokenPrefix, apiToken.CreatedAt, apiToken.ExpiresAt)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create API token: %w", err)
+	}
+	return apiToken, token, nil
+}
+
+// ListAPITokens returns the member's personal tokens and, for admins, the
+// family's tokens, newest first. Revoked tokens are included.
+func (s *Service) ListAPITokens(familyID, memberID string, includeFamily bool) ([]APIToken, error) {
+	tokens, err := database.QueryAll[APIToken](s.db, `
+		SELECT id, family_id, member_id, name, kind, scopes, token_prefix, created_at, expires_at, last_used_at, revoked_at
+		FROM api_tokens
+		WHERE family_id = ? AND ((kind = ? AND member_id = ?) OR (kind = ? AND ?))
+		ORDER BY created_at DESC
+	`, familyID, APITokenPersonal, memberID, APITokenFamily, includeFamily)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API tokens: %w", err)
+	}
+	for i := range tokens {
+		tokens[i].Scopes = splitScopes(tokens[i].ScopeList)
+	}
+	return tokens, nil
+}
+
+// RevokeAPIToken stops a token working. Members revoke their own personal
+// tokens; admins also revoke the family's.
+func (s *Service) RevokeAPIToken(familyID, memberID, tokenID string, includeFamily bool) error {
+	result, err := s.db.Exec(`
+		UPDATE api_tokens SET revoked_at = ?
+		WHERE id = ? AND family_id = ? AND revoked_at IS NULL
+			AND ((kind = ? AND member_id = ?) OR (kind = ? AND ?))
+	`, time.Now().UTC(), tokenID, familyID, APITokenPersonal, memberID, APITokenFamily, includeFamily)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API token: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("API token not found")
+	}
+	return nil
+}
+
+// validateAPIToken returns the session an API token acts with
+func (s *Service) validateAPIToken(token string) (*Session, error) {
+	apiToken, err := database.QueryOne[APIToken](s.db, `
+		SELECT id, family_id, member_id, name, kind, scopes, token_prefix, created_at, expires_at, last_used_at, revoked_at
+		FROM api_tokens
+		WHERE token_hash = ?
+	`, hashLoginToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("invalid API token")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API token: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if apiToken.RevokedAt != nil {
+		return nil, fmt.Errorf("API token has been revoked")
+	}
+	if apiToken.ExpiresAt != nil && !now.Before(*apiToken.ExpiresAt) {
+		return nil, fmt.Errorf("API token has expired")
+	}
+
+	member, err := s.getFamilyMemberByID(apiToken.MemberID)
+	if err != nil || !member.IsActive {
+		return nil, fmt.Errorf("API token's member is no longer active")
+	}
+
+	session := &Session{
+		UserID:     member.ID,
+		FamilyID:   apiToken.FamilyID,
+		Role:       RoleUser,
+		IssuedAt:   apiToken.CreatedAt,
+		ExpiresAt:  now.Add(time.Hour), // checked again on every request
+		Scopes:     splitScopes(apiToken.ScopeList),
+		APITokenID: apiToken.ID,
+	}
+	if apiToken.ExpiresAt != nil {
+		session.ExpiresAt = *apiToken.ExpiresAt
+	}
+	if apiToken.Kind == APITokenPersonal {
+		if member.Role != nil {
+			session.Role = Role(*member.Role)
+		}
+		if session.Grants, err = s.grantsFor(member.ID); err != nil {
+			return nil, err
+		}
+	}
+	session.OriginalRole = session.Role
+
+	if apiToken.LastUsedAt == nil || now.Sub(*apiToken.LastUsedAt) >= apiTokenUseInterval {
+		if _, err := s.db.Exec(`UPDATE api_tokens SET last_used_at = ? WHERE id = ?`, now, apiToken.ID); err != nil {
+			fmt.Printf("Failed to note use of API token %s: %v\n", apiToken.ID, err)
+		}
+	}
+	return session, nil
+}
+
+func splitScopes(list string) []string {
+	if list == "" {
+		return []string{}
+	}
+	return strings.Split(list, ",")
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIToken_ActsAsMemberWithinScopes(t *testing.T) {
+	service := setupLockoutTest(t)
+
+	apiToken, token, err := service.CreateAPIToken("fam_lockout", "member_lockout", "Home Assistant", APITokenPersonal,
+		[]string{"task:read", "task:write"}, 24*time.Hour)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, APITokenPrefix))
+	assert.Equal(t, token[:apiTokenDisplayLength], apiToken.TokenPrefix)
+
+	session, err := service.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "member_lockout", session.UserID)
+	assert.Equal(t, RoleAdmin, session.Role)
+	authorization := NewAuthorizationService(session)
+	assert.True(t, authorization.HasPermission(EntityTask, ActionDelete, nil))
+	assert.False(t, authorization.HasPermission(EntitySetting, ActionRead, nil), "outside the token's scopes")
+
+	member, err := service.GetFamilyMemberByToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "member_lockout", member.ID)
+
+	tokens, err := service.ListAPITokens("fam_lockout", "member_lockout", false)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, []string{"task:read", "task:write"}, tokens[0].Scopes)
+	assert.NotNil(t, tokens[0].LastUsedAt)
+
+	assert.True(t, service.IssuedToken(token))
+	assert.False(t, service.IssuedToken(APITokenPrefix+"made-up"))
+	assert.False(t, service.IssuedToken("not.a.jwt"))
+
+	require.NoError(t, service.RevokeAPIToken("fam_lockout", "member_lockout", apiToken.ID, false))
+	_, err = service.ValidateToken(token)
+	assert.EqualError(t, err, "API token has been revoked")
+	assert.False(t, service.IssuedToken(token))
+	assert.EqualError(t, service.RevokeAPIToken("fam_lockout", "member_lockout", apiToken.ID, false), "API token not found")
+}
+
+func TestAPIToken_FamilyTokenActsAsStandardMember(t *testing.T) {
+	service := setupLockoutTest(t)
+
+	apiToken, token, err := service.CreateAPIToken("fam_lockout", "member_lockout", "Wall display", APITokenFamily,
+		[]string{"calendar:read", "setting:read"}, 0)
+	require.NoError(t, err)
+	assert.Nil(t, apiToken.ExpiresAt)
+
+	session, err := service.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, RoleUser, session.Role)
+	assert.False(t, NewAuthorizationService(session).HasPermission(EntitySetting, ActionRead, nil), "scopes don't add to the role")
+
+	tokens, err := service.ListAPITokens("fam_lockout", "member_other", false)
+	require.NoError(t, err)
+	assert.Empty(t, tokens, "family tokens are listed for admins only")
+	assert.EqualError(t, service.RevokeAPIToken("fam_lockout", "member_other", apiToken.ID, false), "API token not found")
+
+	_, _, err = service.CreateAPIToken("fam_lockout", "member_lockout", "Bad", APITokenPersonal, []string{"task:admin"}, 0)
+	assert.EqualError(t, err, `unknown scope "task:admin"`)
+}
+
+func TestAPITokenMayWrite(t *testing.T) {
+	readOnly := &Session{APITokenID: "apitoken_1", Scopes: []string{"task:read"}}
+	assert.True(t, apiTokenMayWrite(readOnly, httptest.NewRequest("GET", "/api/v1/me/preferences", nil)))
+	assert.False(t, apiTokenMayWrite(readOnly, httptest.NewRequest("POST", "/api/v1/me/preferences", nil)))
+
+	writer := &Session{APITokenID: "apitoken_2", Role: RoleUser, Scopes: []string{"task:write"}}
+	assert.False(t, apiTokenMayWrite(writer, httptest.NewRequest("POST", "/api/v1/me/preferences", nil)), "a write scope is for its entity's routes")
+	assert.True(t, NewAuthorizationService(writer).HasPermission(EntityTask, ActionCreate, nil))
+
+	assert.True(t, apiTokenMayWrite(&Session{}, httptest.NewRequest("POST", "/api/v1/me/preferences", nil)), "sign-ins aren't scoped")
+}
+
+func TestGetCurrentPermissions_CappedByScopes(t *testing.T) {
+	session := &Session{Role: RoleAdmin, Scopes: []string{"calendar:read"}}
+	assert.Equal(t, []string{"calendar:read:any"}, NewAuthorizationService(session).GetCurrentPermissions())
+}
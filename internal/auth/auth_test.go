@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"slices"
 	"testing"
 	"time"
 )
@@ -173,3 +174,38 @@ func TestRoleDowngradeUpgrade(t *testing.T) {
 		t.Errorf("Expected OriginalRole %s, got %s", RoleUser, upgradedClaims.OriginalRole)
 	}
 }
+
+func TestCapabilityGrants(t *testing.T) {
+	grants := PermissionSet{}
+	grants.addGrant(CapabilityManageIntegrations, true)
+	grants.addGrant(CapabilityCreateTasks, false)
+	grants.addGrant(CapabilityEditCalendar, false)
+
+	user := NewAuthorizationService(&Session{UserID: "kid", Role: RoleUser, OriginalRole: RoleUser, Grants: grants})
+	if !user.HasPermission(EntityIntegration, ActionCreate, nil) {
+		t.Error("A grant should give a capability the role lacks")
+	}
+	if user.HasPermission(EntityTask, ActionCreate, nil) {
+		t.Error("Taking a capability away should remove it from the role")
+	}
+	owner := "kid"
+	if user.HasPermission(EntityCalendar, ActionUpdate, &owner) {
+		t.Error("Taking a capability away should cover the member's own items too")
+	}
+	if !user.HasPermission(EntityTask, ActionRead, nil) {
+		t.Error("Permissions outside the grants should come from the role")
+	}
+
+	shared := NewAuthorizationService(&Session{UserID: "kid", Role: RoleShared, OriginalRole: RoleUser, Grants: grants})
+	if shared.HasPermission(EntityIntegration, ActionCreate, nil) {
+		t.Error("Grants should not apply to shared sessions")
+	}
+	if !shared.CanUpgradeToAccess(EntityIntegration, ActionCreate, nil) {
+		t.Error("Upgrading should restore granted capabilities")
+	}
+
+	permissions := PermissionList(RoleUser, grants)
+	if !slices.Contains(permissions, "integration:create:any") || slices.Contains(permissions, "task:create:any") {
+		t.Errorf("Permission list should reflect grants, got %v", permissions)
+	}
+}
@@ -1,5 +1,10 @@
 package auth
 
+import (
+	"slices"
+	"strings"
+)
+
 // AuthorizationService handles permission checking
 type AuthorizationService struct {
 	session *Session
@@ -30,9 +35,13 @@ func (a *AuthorizationService) HasPermission(entity Entity, action Action, resou
 	return false
 }
 
-// hasExactPermission checks for a specific permission with exact scope
+// hasExactPermission checks for a specific permission with exact scope,
+// counting the member's grants
 func (a *AuthorizationService) hasExactPermission(entity Entity, action Action, scope PermissionScope) bool {
-	return HasPermission(a.session.Role, entity, action, scope)
+	if a.session.Scopes != nil && !scopeAllows(a.session.Scopes, entity, action) {
+		return false
+	}
+	return allows(a.session.Role, a.session.Grants, MakePermission(entity, action, scope))
 }
 
 // isOwner checks if the current user owns the resource
@@ -65,17 +74,17 @@ func (a *AuthorizationService) CanUpgradeToAccess(entity Entity, action Action,
 	}
 
 	// Check if the original role would have this permission
-	originalPermissions := RolePermissions[a.session.OriginalRole]
+	role, grants := a.session.OriginalRole, a.session.Grants
 
 	// Try "any" scope first
 	anyPerm := MakePermission(entity, action, ScopeAny)
-	if originalPermissions[anyPerm] {
+	if allows(role, grants, anyPerm) {
 		return true
 	}
 
 	// Try "own" scope
 	ownPerm := MakePermission(entity, action, ScopeOwn)
-	if originalPermissions[ownPerm] {
+	if allows(role, grants, ownPerm) {
 		return true // Would work if they own the resource after auth
 	}
 
@@ -95,6 +104,7 @@ func (a *AuthorizationService) WouldHavePermissionAfterUpgrade(entity Entity, ac
 			FamilyID:     a.session.FamilyID,
 			Role:         a.session.OriginalRole,
 			OriginalRole: a.session.OriginalRole,
+			Grants:       a.session.Grants,
 		},
 	}
 
@@ -103,13 +113,23 @@ func (a *AuthorizationService) WouldHavePermissionAfterUpgrade(entity Entity, ac
 
 // GetCurrentPermissions returns all permissions for the current session
 func (a *AuthorizationService) GetCurrentPermissions() []string {
-	return GetPermissionList(a.session.Role)
+	permissions := PermissionList(a.session.Role, a.session.Grants)
+	if a.session.Scopes == nil {
+		return permissions
+	}
+
+	// An API token's scopes cap its role
+	return slices.DeleteFunc(permissions, func(permission string) bool {
+		entity, rest, _ := strings.Cut(permission, ":")
+		action, _, _ := strings.Cut(rest, ":")
+		return !scopeAllows(a.session.Scopes, Entity(entity), Action(action))
+	})
 }
 
 // GetOriginalPermissions returns what permissions would be available after upgrade
 func (a *AuthorizationService) GetOriginalPermissions() []string {
 	if a.session.Role == RoleShared {
-		return GetPermissionList(a.session.OriginalRole)
+		return PermissionList(a.session.OriginalRole, a.session.Grants)
 	}
 	return a.GetCurrentPermissions()
 }
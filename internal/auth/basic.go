@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"famstack/internal/models"
+)
+
+// Basic auth limits. Calendar apps send the same email and password on every
+// poll, so a verified password is trusted for a while rather than hashed and
+// audited each time. Failures are counted apart from sign-in lockouts, per
+// email and per client address, so a misconfigured app can't lock its owner
+// out of the web app.
+const (
+	basicAuthCacheTTL      = 5 * time.Minute
+	maxBasicAuthFailures   = 10
+	basicAuthFailureWindow = 15 * time.Minute
+)
+
+// basicAuth holds verified Basic credentials and recent failures. The zero
+// value is ready to use.
+type basicAuth struct {
+	mu       sync.Mutex
+	verified map[[sha256.Size]byte]verifiedCredential
+	failures map[lockoutKey][]time.Time
+}
+
+// verifiedCredential is a member whose password was checked, and the hash it
+// was checked against so a password change ends the trust
+type verifiedCredential struct {
+	memberID     string
+	passwordHash string
+	until        time.Time
+}
+
+// VerifyBasicAuth checks HTTP Basic credentials (email and password) for
+// clients such as CalDAV calendar apps, returning a session that lasts as
+// long as the credentials are trusted. Unlike Login it doesn't record
+// successful sign-ins; failures are audited and, past their limit, return a
+// *LockoutError for any password not already trusted.
+func (s *Service) VerifyBasicAuth(email, password, ip string) (*Session, *models.FamilyMember, error) {
+	now := time.Now().UTC()
+	subject := normalizeEmail(email)
+	keys := []lockoutKey{{LockoutEmail, subject}, {LockoutIP, ip}}
+
+	credential := sha256.Sum256([]byte(subject + "\x00" + password))
+	if user, ok := s.basic.cached(s, credential, now); ok {
+		session, err := s.basicAuthSession(user, now)
+		return session, user, err
+	}
+
+	if until, limited := s.basic.limited(now, keys); limited {
+		return nil, nil, &LockoutError{Until: until}
+	}
+
+	user, err := s.authenticate(email, password)
+	if errors.Is(err, errInvalidCredentials) {
+		var memberID *string
+		if user != nil {
+			memberID = &user.ID
+		}
+		s.auditLogin(subject, ip, memberID, LoginInvalidCredentials, now)
+		s.basic.fail(now, keys)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.basic.remember(credential, verifiedCredential{memberID: user.ID, passwordHash: *user.PasswordHash, until: now.Add(basicAuthCacheTTL)}, now)
+	session, err := s.basicAuthSession(user, now)
+	return session, user, err
+}
+
+// basicAuthSession is the session for a member verified by Basic auth
+func (s *Service) basicAuthSession(user *models.FamilyMember, now time.Time) (*Session, error) {
+	role := Role(*user.Role)
+	grants, err := s.grantsFor(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get grants: %w", err)
+	}
+	return &Session{
+		UserID:       user.ID,
+		FamilyID:     user.FamilyID,
+		Role:         role,
+		OriginalRole: role,
+		IssuedAt:     now,
+		ExpiresAt:    now.Add(basicAuthCacheTTL),
+		Grants:       grants,
+	}, nil
+}
+
+// cached returns the member behind a verified credential, reloaded so that a
+// deactivation or password change takes effect at once
+func (b *basicAuth) cached(s *Service, credential [sha256.Size]byte, now time.Time) (*models.FamilyMember, bool) {
+	b.mu.Lock()
+	verified, ok := b.verified[credential]
+	b.mu.Unlock()
+	if !ok || now.After(verified.until) {
+		return nil, false
+	}
+
+	user, err := s.getFamilyMemberByID(verified.memberID)
+	if err != nil || !user.IsActive || user.Role == nil || user.PasswordHash == nil || *user.PasswordHash != verified.passwordHash {
+		b.mu.Lock()
+		delete(b.verified, credential)
+		b.mu.Unlock()
+		return nil, false
+	}
+	return user, true
+}
+
+func (b *basicAuth) remember(credential [sha256.Size]byte, verified verifiedCredential, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.verified == nil {
+		b.verified = make(map[[sha256.Size]byte]verifiedCredential)
+	}
+	// Drop expired entries so the cache can't grow without bound
+	for key, entry := range b.verified {
+		if now.After(entry.until) {
+			delete(b.verified, key)
+		}
+	}
+	b.verified[credential] = verified
+}
+
+// limited reports whether any of keys has reached maxBasicAuthFailures, and
+// until when
+func (b *basicAuth) limited(now time.Time, keys []lockoutKey) (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, key := range keys {
+		recent := recentFailures(b.failures[key], now)
+		if len(recent) >= maxBasicAuthFailures {
+			return recent[0].Add(basicAuthFailureWindow), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (b *basicAuth) fail(now time.Time, keys []lockoutKey) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures == nil {
+		b.failures = make(map[lockoutKey][]time.Time)
+	}
+	for key, attempts := range b.failures {
+		if len(recentFailures(attempts, now)) == 0 {
+			delete(b.failures, key)
+		}
+	}
+	for _, key := range keys {
+		if key.subject != "" {
+			b.failures[key] = append(recentFailures(b.failures[key], now), now)
+		}
+	}
+}
+
+// recentFailures drops the failures older than basicAuthFailureWindow
+func recentFailures(attempts []time.Time, now time.Time) []time.Time {
+	for i, attempt := range attempts {
+		if now.Sub(attempt) < basicAuthFailureWindow {
+			return attempts[i:]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func countLoginAttempts(t *testing.T, service *Service) int {
+	var count int
+	require.NoError(t, service.db.QueryRow(`SELECT COUNT(*) FROM login_attempts`).Scan(&count))
+	return count
+}
+
+func TestVerifyBasicAuth_TrustsVerifiedPasswordWithoutAuditing(t *testing.T) {
+	service := setupLockoutTest(t)
+
+	for range 3 {
+		session, user, err := service.VerifyBasicAuth("pat@example.com", "correct-horse", "192.0.2.1")
+		require.NoError(t, err)
+		assert.Equal(t, "member_lockout", user.ID)
+		assert.Equal(t, "fam_lockout", session.FamilyID)
+		assert.Equal(t, RoleAdmin, session.Role)
+	}
+	assert.Zero(t, countLoginAttempts(t, service), "successful polls aren't sign-ins")
+
+	// A new password ends the trust in the old one
+	hash, err := HashPassword("battery-staple")
+	require.NoError(t, err)
+	_, err = service.db.Exec(`UPDATE family_members SET password_hash = ? WHERE id = ?`, hash, "member_lockout")
+	require.NoError(t, err)
+	_, _, err = service.VerifyBasicAuth("pat@example.com", "correct-horse", "192.0.2.1")
+	assert.EqualError(t, err, "invalid credentials")
+
+	// As does deactivation
+	_, _, err = service.VerifyBasicAuth("pat@example.com", "battery-staple", "192.0.2.1")
+	require.NoError(t, err)
+	_, err = service.db.Exec(`UPDATE family_members SET is_active = FALSE WHERE id = ?`, "member_lockout")
+	require.NoError(t, err)
+	_, _, err = service.VerifyBasicAuth("pat@example.com", "battery-staple", "192.0.2.1")
+	assert.EqualError(t, err, "invalid credentials")
+}
+
+func TestVerifyBasicAuth_LimitsFailuresApartFromSignIn(t *testing.T) {
+	service := setupLockoutTest(t)
+
+	// Trusted before the failures start
+	_, _, err := service.VerifyBasicAuth("pat@example.com", "correct-horse", "192.0.2.1")
+	require.NoError(t, err)
+
+	for range maxBasicAuthFailures {
+		_, _, err := service.VerifyBasicAuth("pat@example.com", "wrong", "192.0.2.9")
+		require.EqualError(t, err, "invalid credentials")
+	}
+	assert.Equal(t, maxBasicAuthFailures, countLoginAttempts(t, service), "failures are audited")
+
+	_, _, err = service.VerifyBasicAuth("pat@example.com", "wrong", "192.0.2.9")
+	var lockoutErr *LockoutError
+	require.True(t, errors.As(err, &lockoutErr))
+
+	// The calendar app that already had the password keeps working, and
+	// Basic auth failures don't lock the member out of signing in
+	_, _, err = service.VerifyBasicAuth("pat@example.com", "correct-horse", "192.0.2.1")
+	require.NoError(t, err)
+	_, err = service.Login("pat@example.com", "correct-horse", "192.0.2.9")
+	require.NoError(t, err)
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"famstack/internal/database"
+)
+
+// MemberGrant gives a member a capability beyond their role, or with Granted
+// false takes one away
+type MemberGrant struct {
+	MemberID   string     `json:"member_id" db:"member_id"`
+	Capability Capability `json:"capability" db:"capability"`
+	Granted    bool       `json:"granted" db:"granted"`
+	GrantedBy  *string    `json:"granted_by,omitempty" db:"granted_by"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ListGrants returns the grants on a family's members
+func (s *Service) ListGrants(familyID string) ([]MemberGrant, error) {
+	grants, err := database.QueryAll[MemberGrant](s.db, `
+		SELECT g.member_id, g.capability, g.granted, g.granted_by, g.updated_at
+		FROM member_grants g
+		JOIN family_members m ON m.id = g.member_id
+		WHERE m.family_id = ?
+		ORDER BY m.display_order, m.first_name, g.capability
+	`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list grants: %w", err)
+	}
+	return grants, nil
+}
+
+// SetGrant gives a member of familyID a capability, or takes it away.
+// Admins have every capability already and can't have one taken away.
+func (s *Service) SetGrant(familyID, grantedBy, memberID string, capability Capability, granted bool) error {
+	if !capability.IsValid() {
+		return fmt.Errorf("unknown capability")
+	}
+	member, err := s.getFamilyMemberByID(memberID)
+	if err != nil || member.FamilyID != familyID {
+		return fmt.Errorf("member not found")
+	}
+	if member.Role != nil && Role(*member.Role) == RoleAdmin {
+		return fmt.Errorf("admins have every capability")
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO member_grants (member_id, capability, granted, granted_by, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (member_id, capability) DO UPDATE SET
+			granted = excluded.granted,
+			granted_by = excluded.granted_by,
+			updated_at = excluded.updated_at
+	`, memberID, capability, granted, grantedBy, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save grant: %w", err)
+	}
+	return nil
+}
+
+// ClearGrant removes a grant, so the member gets what their role allows
+func (s *Service) ClearGrant(familyID, memberID string, capability Capability) error {
+	result, err := s.db.Exec(`
+		DELETE FROM member_grants
+		WHERE member_id = ? AND capability = ?
+			AND member_id IN (SELECT id FROM family_members WHERE family_id = ?)
+	`, memberID, capability, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to clear grant: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("grant not found")
+	}
+	return nil
+}
+
+// grantsFor returns the permissions a member's grants change
+func (s *Service) grantsFor(memberID string) (PermissionSet, error) {
+	grants, err := database.QueryAll[MemberGrant](s.db, `
+		SELECT member_id, capability, granted, granted_by, updated_at
+		FROM member_grants
+		WHERE member_id = ?
+	`, memberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get grants: %w", err)
+	}
+
+	set := PermissionSet{}
+	for _, grant := range grants {
+		set.addGrant(grant.Capability, grant.Granted)
+	}
+	return set, nil
+}
@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/ids"
+	"famstack/internal/validation"
+)
+
+// Guest passes: a parent gives a babysitter or grandparent a link to today's
+// schedule and the tasks the parent picked, optionally letting them tick
+// those tasks off. A guest has no account; the link's token goes in a cookie
+// of its own that the rest of the API doesn't accept, and is checked on
+// every request so a pass stops working the moment it runs out or is
+// revoked.
+
+// GuestCookieName is the cookie holding a guest pass's token
+const GuestCookieName = "guest_token"
+
+// Guest pass access levels
+const (
+	GuestAccessRead  = "read"  // see the day
+	GuestAccessTasks = "tasks" // and mark the picked tasks done
+)
+
+// maxGuestPassHours caps how far ahead a pass can run, a month
+const maxGuestPassHours = 30 * 24
+
+// guestPassUseInterval limits how often a pass's last use is written
+const guestPassUseInterval = time.Minute
+
+var errInvalidGuestPass = errors.New("invalid or expired guest pass")
+
+// GuestPass is a guest's time-boxed access, without its token
+type GuestPass struct {
+	ID         string     `json:"id" db:"id"`
+	FamilyID   string     `json:"family_id" db:"family_id"`
+	Name       string     `json:"name" db:"name"`
+	Access     string     `json:"access" db:"access"`
+	TaskIDs    []string   `json:"task_ids"`
+	TaskList   string     `json:"-" db:"task_ids"`
+	CreatedBy  *string    `json:"created_by,omitempty" db:"created_by"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// NewGuestPass is a just-created pass with the link to give the guest,
+// which is not kept and so is shown only once
+type NewGuestPass struct {
+	GuestPass
+	Link string `json:"link"`
+}
+
+// CanCompleteTasks reports whether the guest may mark their tasks done
+func (p *GuestPass) CanCompleteTasks() bool {
+	return p.Access == GuestAccessTasks
+}
+
+// CreateGuestPass gives a guest access to familyID's day until the hours in
+// req run out. The link is relative when server.public_url isn't set.
+func (s *Service) CreateGuestPass(familyID, createdBy string, req *CreateGuestPassRequest) (*NewGuestPass, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	validator := validation.NewValidator()
+	validator.Required("name", req.Name)
+	validator.MaxLength("name", req.Name, 100)
+	validator.OneOf("access", req.Access, []string{GuestAccessRead, GuestAccessTasks})
+	validateGuestHours(validator, "expires_in_hours", req.ExpiresInHours)
+	if err := validator.ToError(); err != nil {
+		return nil, err
+	}
+
+	taskIDs := slices.Compact(slices.Sorted(slices.Values(req.TaskIDs)))
+	if err := s.checkFamilyTasks(familyID, taskIDs); err != nil {
+		return nil, err
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	pass := GuestPass{
+		ID:        ids.New("guest"),
+		FamilyID:  familyID,
+		Name:      req.Name,
+		Access:    req.Access,
+		TaskIDs:   taskIDs,
+		TaskList:  strings.Join(taskIDs, ","),
+		CreatedBy: &createdBy,
+		ExpiresAt: now.Add(time.Duration(req.ExpiresInHours) * time.Hour),
+		CreatedAt: now,
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO guest_passes (id, family_id, name, access, task_ids, token_hash, created_by, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, pass.ID, pass.FamilyID, pass.Name, pass.Access, pass.TaskList, hashLoginToken(token), pass.CreatedBy, pass.ExpiresAt, pass.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create guest pass: %w", err)
+	}
+	return &NewGuestPass{GuestPass: pass, Link: s.magic.publicURL + "/guest/" + token}, nil
+}
+
+// ListGuestPasses returns a family's passes that still work, soonest to run
+// out first
+func (s *Service) ListGuestPasses(familyID string) ([]GuestPass, error) {
+	passes, err := database.QueryAll[GuestPass](s.db, `
+		SELECT id, family_id, name, access, task_ids, created_by, expires_at, last_used_at, revoked_at, created_at
+		FROM guest_passes
+		WHERE family_id = ? AND revoked_at IS NULL AND expires_at > ?
+		ORDER BY expires_at
+	`, familyID, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list guest passes: %w", err)
+	}
+	for i := range passes {
+		passes[i].TaskIDs = splitScopes(passes[i].TaskList)
+	}
+	return passes, nil
+}
+
+// ExtendGuestPass adds hours to a pass that still works, up to a month from
+// now
+func (s *Service) ExtendGuestPass(familyID, passID string, hours int) (*GuestPass, error) {
+	validator := validation.NewValidator()
+	validateGuestHours(validator, "hours", hours)
+	if err := validator.ToError(); err != nil {
+		return nil, err
+	}
+
+	pass, err := s.getGuestPass(`id = ? AND family_id = ?`, passID, familyID)
+	if errors.Is(err, errInvalidGuestPass) {
+		return nil, fmt.Errorf("guest pass not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	expiresAt := pass.ExpiresAt.Add(time.Duration(hours) * time.Hour)
+	if latest := now.Add(maxGuestPassHours * time.Hour); expiresAt.After(latest) {
+		expiresAt = latest
+	}
+	if _, err := s.db.Exec(`UPDATE guest_passes SET expires_at = ? WHERE id = ?`, expiresAt, pass.ID); err != nil {
+		return nil, fmt.Errorf("failed to extend guest pass: %w", err)
+	}
+	pass.ExpiresAt = expiresAt
+	return pass, nil
+}
+
+// RevokeGuestPass stops a pass working at once
+func (s *Service) RevokeGuestPass(familyID, passID string) error {
+	result, err := s.db.Exec(`
+		UPDATE guest_passes SET revoked_at = ?
+		WHERE id = ? AND family_id = ? AND revoked_at IS NULL
+	`, time.Now().UTC(), passID, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke guest pass: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("guest pass not found")
+	}
+	return nil
+}
+
+// GuestPassByToken returns the pass a guest's token belongs to while it
+// still works
+func (s *Service) GuestPassByToken(token string) (*GuestPass, error) {
+	if token == "" {
+		return nil, errInvalidGuestPass
+	}
+	pass, err := s.getGuestPass(`token_hash = ?`, hashLoginToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if pass.LastUsedAt == nil || now.Sub(*pass.LastUsedAt) >= guestPassUseInterval {
+		if _, err := s.db.Exec(`UPDATE guest_passes SET last_used_at = ? WHERE id = ?`, now, pass.ID); err != nil {
+			fmt.Printf("Failed to note use of guest pass %s: %v\n", pass.ID, err)
+		}
+	}
+	return pass, nil
+}
+
+// IsInvalidGuestPass reports whether err means a guest's pass doesn't work
+// (any more)
+func IsInvalidGuestPass(err error) bool {
+	return errors.Is(err, errInvalidGuestPass)
+}
+
+// getGuestPass returns the pass matching where if it still works
+func (s *Service) getGuestPass(where string, args ...any) (*GuestPass, error) {
+	pass, err := database.QueryOne[GuestPass](s.db, `
+		SELECT id, family_id, name, access, task_ids, created_by, expires_at, last_used_at, revoked_at, created_at
+		FROM guest_passes
+		WHERE `+where, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errInvalidGuestPass
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guest pass: %w", err)
+	}
+	if pass.RevokedAt != nil || !time.Now().UTC().Before(pass.ExpiresAt) {
+		return nil, errInvalidGuestPass
+	}
+	pass.TaskIDs = splitScopes(pass.TaskList)
+	return pass, nil
+}
+
+// checkFamilyTasks makes sure every task belongs to familyID
+func (s *Service) checkFamilyTasks(familyID string, taskIDs []string) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	args := []any{familyID}
+	for _, id := range taskIDs {
+		args = append(args, id)
+	}
+	var found int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE family_id = ? AND id IN (?`+
+		strings.Repeat(", ?", len(taskIDs)-1)+`)`, args...).Scan(&found)
+	if err != nil {
+		return fmt.Errorf("failed to check tasks: %w", err)
+	}
+	if found != len(taskIDs) {
+		return validation.ValidationErrors{{Field: "task_ids", Message: "task_ids must be tasks in the family"}}
+	}
+	return nil
+}
+
+func validateGuestHours(validator *validation.Validator, field string, hours int) {
+	if hours < 1 || hours > maxGuestPassHours {
+		validator.AddErrorf(field, "%s must be between 1 and %d", field, maxGuestPassHours)
+	}
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"famstack/internal/validation"
+)
+
+func TestGuestPass_Lifecycle(t *testing.T) {
+	service := setupLockoutTest(t)
+	_, err := service.db.Exec(`INSERT INTO tasks (id, family_id, title, task_type, created_by) VALUES (?, ?, ?, ?, ?)`,
+		"task_bedtime", "fam_lockout", "Bedtime by 8", "chore", "member_lockout")
+	require.NoError(t, err)
+
+	_, err = service.CreateGuestPass("fam_lockout", "member_lockout", &CreateGuestPassRequest{
+		Name: "Sitter", Access: GuestAccessTasks, TaskIDs: []string{"task_elsewhere"}, ExpiresInHours: 4,
+	})
+	var validationErrs validation.ValidationErrors
+	assert.ErrorAs(t, err, &validationErrs, "tasks must be the family's")
+
+	pass, err := service.CreateGuestPass("fam_lockout", "member_lockout", &CreateGuestPassRequest{
+		Name: " Sitter ", Access: GuestAccessTasks, TaskIDs: []string{"task_bedtime", "task_bedtime"}, ExpiresInHours: 4,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Sitter", pass.Name)
+	assert.Equal(t, []string{"task_bedtime"}, pass.TaskIDs)
+	assert.WithinDuration(t, time.Now().Add(4*time.Hour), pass.ExpiresAt, time.Minute)
+	assert.True(t, strings.HasPrefix(pass.Link, "/guest/"), "the link is relative without a public URL")
+
+	found, err := service.GuestPassByToken(strings.TrimPrefix(pass.Link, "/guest/"))
+	require.NoError(t, err)
+	assert.Equal(t, pass.ID, found.ID)
+	assert.True(t, found.CanCompleteTasks())
+	assert.Equal(t, []string{"task_bedtime"}, found.TaskIDs)
+
+	extended, err := service.ExtendGuestPass("fam_lockout", pass.ID, 2)
+	require.NoError(t, err)
+	assert.WithinDuration(t, pass.ExpiresAt.Add(2*time.Hour), extended.ExpiresAt, time.Second)
+	extended, err = service.ExtendGuestPass("fam_lockout", pass.ID, maxGuestPassHours)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(maxGuestPassHours*time.Hour), extended.ExpiresAt, time.Minute,
+		"a pass runs a month from now at most")
+	_, err = service.ExtendGuestPass("fam_other", pass.ID, 2)
+	assert.EqualError(t, err, "guest pass not found")
+
+	passes, err := service.ListGuestPasses("fam_lockout")
+	require.NoError(t, err)
+	assert.Len(t, passes, 1)
+
+	require.NoError(t, service.RevokeGuestPass("fam_lockout", pass.ID))
+	_, err = service.GuestPassByToken(strings.TrimPrefix(pass.Link, "/guest/"))
+	assert.True(t, IsInvalidGuestPass(err), "a revoked pass stops working at once")
+	passes, err = service.ListGuestPasses("fam_lockout")
+	require.NoError(t, err)
+	assert.Empty(t, passes)
+}
+
+func TestGuestPass_Expires(t *testing.T) {
+	service := setupLockoutTest(t)
+
+	pass, err := service.CreateGuestPass("fam_lockout", "member_lockout", &CreateGuestPassRequest{
+		Name: "Grandma", Access: GuestAccessRead, ExpiresInHours: 1,
+	})
+	require.NoError(t, err)
+	assert.False(t, pass.CanCompleteTasks())
+
+	_, err = service.db.Exec(`UPDATE guest_passes SET expires_at = ? WHERE id = ?`, time.Now().UTC().Add(-time.Minute), pass.ID)
+	require.NoError(t, err)
+	_, err = service.GuestPassByToken(strings.TrimPrefix(pass.Link, "/guest/"))
+	assert.True(t, IsInvalidGuestPass(err))
+	_, err = service.ExtendGuestPass("fam_lockout", pass.ID, 1)
+	assert.EqualError(t, err, "guest pass not found", "a pass that ran out can't be brought back")
+}
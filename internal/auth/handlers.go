@@ -2,14 +2,24 @@ package auth
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
+
+	"famstack/internal/csrf"
+	"famstack/internal/middleware"
+	"famstack/internal/validation"
 )
 
 // Handlers provides HTTP handlers for authentication endpoints
 type Handlers struct {
-	authService *Service
+	authService   *Service
+	secureCookies bool
 }
 
 // NewHandlers creates new authentication handlers
@@ -19,6 +29,11 @@ func NewHandlers(authService *Service) *Handlers {
 	}
 }
 
+// SetSecureCookies marks the cookies the handlers set as HTTPS-only
+func (h *Handlers) SetSecureCookies(secure bool) {
+	h.secureCookies = secure
+}
+
 // HandleLogin handles user login requests
 func (h *Handlers) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -42,17 +57,21 @@ func (h *Handlers) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	fmt.Printf("🔐 Login attempt for email: %s\n", req.Email)
 
 	// Authenticate user
-	authResponse, err := h.authService.Login(req.Email, req.Password)
+	authResponse, err := h.authService.Login(req.Email, req.Password, middleware.ClientIP(r))
 	if err != nil {
 		fmt.Printf("❌ Login failed for %s: %v\n", req.Email, err)
+		if h.writeLockout(w, err) {
+			return
+		}
 		h.writeError(w, "Invalid credentials", http.StatusUnauthorized)
 		return
 	}
 
 	fmt.Printf("✅ Login successful for %s (User ID: %s)\n", req.Email, authResponse.User.ID)
 
-	// Set JWT token in HTTP-only cookie
+	// Set JWT token in HTTP-only cookie, with a new CSRF token to go with it
 	h.setAuthCookie(w, authResponse.Token)
+	csrf.Rotate(w)
 
 	// Return response (without token in JSON for security)
 	response := map[string]interface{}{
@@ -65,6 +84,401 @@ func (h *Handlers) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, response)
 }
 
+// HandleMagicLinkRequest emails a sign-in link. The response is the same
+// whether or not the email belongs to a member who can use one.
+func (h *Handlers) HandleMagicLinkRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req MagicLinkRequest
+	if decodeErr := json.NewDecoder(r.Body).Decode(&req); decodeErr != nil || req.Email == "" {
+		h.writeError(w, "Email is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authService.RequestMagicLink(r.Context(), req.Email); err != nil {
+		if errors.Is(err, errMagicLinksDisabled) {
+			h.writeError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		fmt.Printf("❌ Magic link request failed: %v\n", err)
+		h.writeError(w, "Failed to send sign-in link", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, map[string]string{
+		"message": "If that email can sign in with a link, one is on its way",
+	})
+}
+
+// HandleMagicLogin signs in with an emailed link (GET ?token=), redirecting
+// to the app, or with a one-time code (POST {"code"}), answering like
+// HandleLogin
+func (h *Handlers) HandleMagicLogin(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		authResponse, err := h.authService.RedeemLoginToken(LoginTokenLink, r.URL.Query().Get("token"), middleware.ClientIP(r))
+		if err != nil {
+			fmt.Printf("❌ Magic link sign-in failed: %v\n", err)
+			http.Redirect(w, r, "/login?error=magic_link", http.StatusSeeOther)
+			return
+		}
+		h.setAuthCookie(w, authResponse.Token)
+		csrf.Rotate(w)
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+
+	case http.MethodPost:
+		var req LoginCodeRequest
+		if decodeErr := json.NewDecoder(r.Body).Decode(&req); decodeErr != nil || req.Code == "" {
+			h.writeError(w, "Code is required", http.StatusBadRequest)
+			return
+		}
+
+		authResponse, err := h.authService.RedeemLoginToken(LoginTokenCode, req.Code, middleware.ClientIP(r))
+		if err != nil {
+			fmt.Printf("❌ Code sign-in failed: %v\n", err)
+			if h.writeLockout(w, err) {
+				return
+			}
+			h.writeError(w, "Invalid or expired code", http.StatusUnauthorized)
+			return
+		}
+
+		h.setAuthCookie(w, authResponse.Token)
+		csrf.Rotate(w)
+		h.writeJSON(w, map[string]interface{}{
+			"user":        authResponse.User,
+			"session":     authResponse.Session,
+			"permissions": authResponse.Permissions,
+		})
+
+	default:
+		h.writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleCreateLoginCode creates a one-time sign-in code for a member of the
+// caller's family
+func (h *Handlers) HandleCreateLoginCode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := GetSessionFromContext(r.Context())
+	if session == nil {
+		h.writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateLoginCodeRequest
+	if decodeErr := json.NewDecoder(r.Body).Decode(&req); decodeErr != nil || req.MemberID == "" {
+		h.writeError(w, "member_id is required", http.StatusBadRequest)
+		return
+	}
+
+	code, err := h.authService.CreateLoginCode(session.FamilyID, session.UserID, req.MemberID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errMagicLinksDisabled), err.Error() == "member not found":
+			h.writeError(w, err.Error(), http.StatusNotFound)
+		case err.Error() == "member cannot sign in with a code":
+			h.writeError(w, err.Error(), http.StatusBadRequest)
+		default:
+			h.writeError(w, "Failed to create sign-in code", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.writeJSON(w, code)
+}
+
+// HandleTrustedDevices manages the devices members can sign in on with a
+// PIN: POST {"name"} trusts the caller's device, GET lists the family's
+// devices and DELETE ?id= stops trusting one
+func (h *Handlers) HandleTrustedDevices(w http.ResponseWriter, r *http.Request) {
+	session := GetSessionFromContext(r.Context())
+	if session == nil {
+		h.writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		devices, err := h.authService.ListTrustedDevices(session.FamilyID)
+		if err != nil {
+			h.writeError(w, "Failed to list devices", http.StatusInternalServerError)
+			return
+		}
+		if devices == nil {
+			devices = []TrustedDevice{}
+		}
+		h.writeJSON(w, devices)
+
+	case http.MethodPost:
+		var req TrustDeviceRequest
+		if decodeErr := json.NewDecoder(r.Body).Decode(&req); decodeErr != nil || req.Name == "" {
+			h.writeError(w, "Device name is required", http.StatusBadRequest)
+			return
+		}
+
+		device, token, err := h.authService.TrustDevice(session.FamilyID, session.UserID, req.Name)
+		if err != nil {
+			h.writeError(w, "Failed to trust device", http.StatusInternalServerError)
+			return
+		}
+		h.setDeviceCookie(w, token)
+		h.writeJSON(w, device)
+
+	case http.MethodDelete:
+		if err := h.authService.RevokeTrustedDevice(session.FamilyID, r.URL.Query().Get("id")); err != nil {
+			if err.Error() == "device not found" {
+				h.writeError(w, "Device not found", http.StatusNotFound)
+				return
+			}
+			h.writeError(w, "Failed to revoke device", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		h.writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandlePINs lets parents manage PINs: PUT {"member_id","pin"} sets one and
+// DELETE ?member_id= removes one
+func (h *Handlers) HandlePINs(w http.ResponseWriter, r *http.Request) {
+	session := GetSessionFromContext(r.Context())
+	if session == nil {
+		h.writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var req PINRequest
+		if decodeErr := json.NewDecoder(r.Body).Decode(&req); decodeErr != nil || req.MemberID == "" {
+			h.writeError(w, "member_id and pin are required", http.StatusBadRequest)
+			return
+		}
+		if !isPIN(req.PIN) {
+			h.writeError(w, "PIN must be 4 digits", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.authService.SetPIN(session.FamilyID, session.UserID, req.MemberID, req.PIN); err != nil {
+			if err.Error() == "member not found" {
+				h.writeError(w, "Member not found", http.StatusNotFound)
+				return
+			}
+			h.writeError(w, "Failed to set PIN", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case http.MethodDelete:
+		if err := h.authService.ClearPIN(session.FamilyID, r.URL.Query().Get("member_id")); err != nil {
+			if err.Error() == "PIN not found" {
+				h.writeError(w, "PIN not found", http.StatusNotFound)
+				return
+			}
+			h.writeError(w, "Failed to clear PIN", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		h.writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleInvitations manages invitations to take over a member: POST
+// {"member_id","role","email"} creates one, GET lists those still open and
+// DELETE ?id= revokes one
+func (h *Handlers) HandleInvitations(w http.ResponseWriter, r *http.Request) {
+	session := GetSessionFromContext(r.Context())
+	if session == nil {
+		h.writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		invitations, err := h.authService.ListInvitations(session.FamilyID)
+		if err != nil {
+			h.writeError(w, "Failed to list invitations", http.StatusInternalServerError)
+			return
+		}
+		if invitations == nil {
+			invitations = []Invitation{}
+		}
+		h.writeJSON(w, invitations)
+
+	case http.MethodPost:
+		var req CreateInvitationRequest
+		if decodeErr := json.NewDecoder(r.Body).Decode(&req); decodeErr != nil {
+			h.writeError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		invitation, err := h.authService.CreateInvitation(r.Context(), session.FamilyID, session.UserID, session.Role, &req)
+		if err != nil {
+			var validationErrs validation.ValidationErrors
+			switch {
+			case errors.As(err, &validationErrs), err.Error() == "member can already sign in":
+				h.writeError(w, err.Error(), http.StatusBadRequest)
+			case err.Error() == "member not found":
+				h.writeError(w, "Member not found", http.StatusNotFound)
+			default:
+				fmt.Printf("❌ Failed to create invitation: %v\n", err)
+				h.writeError(w, "Failed to create invitation", http.StatusInternalServerError)
+			}
+			return
+		}
+		h.writeJSON(w, invitation)
+
+	case http.MethodDelete:
+		if err := h.authService.RevokeInvitation(session.FamilyID, r.URL.Query().Get("id")); err != nil {
+			if err.Error() == "invitation not found" {
+				h.writeError(w, "Invitation not found", http.StatusNotFound)
+				return
+			}
+			h.writeError(w, "Failed to revoke invitation", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		h.writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleInvite serves /auth/invite/{token}, where the token may also be an
+// invitation's code. A browser following an emailed link is sent on to the
+// login page; GET with Accept: application/json returns who the invitation
+// is for, and POST {"email","password"} accepts it, answering like
+// HandleLogin.
+func (h *Handlers) HandleInvite(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.URL.Path, "/auth/invite/")
+
+	switch r.Method {
+	case http.MethodGet:
+		if !strings.Contains(r.Header.Get("Accept"), "application/json") {
+			http.Redirect(w, r, "/login?invite="+url.QueryEscape(token), http.StatusSeeOther)
+			return
+		}
+		details, err := h.authService.GetInvitation(token)
+		if err != nil {
+			if !errors.Is(err, errInvalidInvitation) {
+				fmt.Printf("❌ Failed to get invitation: %v\n", err)
+			}
+			h.writeError(w, "Invalid or expired invitation", http.StatusNotFound)
+			return
+		}
+		h.writeJSON(w, details)
+
+	case http.MethodPost:
+		var req AcceptInvitationRequest
+		if decodeErr := json.NewDecoder(r.Body).Decode(&req); decodeErr != nil || req.Password == "" {
+			h.writeError(w, "Password is required", http.StatusBadRequest)
+			return
+		}
+
+		authResponse, err := h.authService.AcceptInvitation(token, &req, middleware.ClientIP(r))
+		if err != nil {
+			fmt.Printf("❌ Invitation acceptance failed: %v\n", err)
+			if h.writeLockout(w, err) {
+				return
+			}
+			var validationErrs validation.ValidationErrors
+			switch {
+			case errors.As(err, &validationErrs):
+				h.writeError(w, err.Error(), http.StatusBadRequest)
+			case errors.Is(err, errInvalidInvitation):
+				h.writeError(w, "Invalid or expired invitation", http.StatusNotFound)
+			default:
+				h.writeError(w, "Failed to accept invitation", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		h.setAuthCookie(w, authResponse.Token)
+		csrf.Rotate(w)
+		h.writeJSON(w, map[string]interface{}{
+			"user":        authResponse.User,
+			"session":     authResponse.Session,
+			"permissions": authResponse.Permissions,
+		})
+
+	default:
+		h.writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandlePINLogin serves a trusted device's profile picker: GET lists the
+// members with a PIN and POST {"member_id","pin"} signs one in, answering
+// like HandleLogin
+func (h *Handlers) HandlePINLogin(w http.ResponseWriter, r *http.Request) {
+	deviceToken := ""
+	if cookie, err := r.Cookie(DeviceCookieName); err == nil {
+		deviceToken = cookie.Value
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		profiles, err := h.authService.ListPINProfiles(deviceToken)
+		if err != nil {
+			if errors.Is(err, errUntrustedDevice) {
+				h.writeError(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			h.writeError(w, "Failed to list profiles", http.StatusInternalServerError)
+			return
+		}
+		if profiles == nil {
+			profiles = []PINProfile{}
+		}
+		h.writeJSON(w, profiles)
+
+	case http.MethodPost:
+		var req PINRequest
+		if decodeErr := json.NewDecoder(r.Body).Decode(&req); decodeErr != nil || req.MemberID == "" || req.PIN == "" {
+			h.writeError(w, "member_id and pin are required", http.StatusBadRequest)
+			return
+		}
+
+		authResponse, err := h.authService.PINLogin(deviceToken, req.MemberID, req.PIN, middleware.ClientIP(r))
+		if err != nil {
+			fmt.Printf("❌ PIN sign-in failed for %s: %v\n", req.MemberID, err)
+			if errors.Is(err, errUntrustedDevice) {
+				h.writeError(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			if h.writeLockout(w, err) {
+				return
+			}
+			h.writeError(w, "Invalid PIN", http.StatusUnauthorized)
+			return
+		}
+
+		// Keep a device in use trusted
+		h.setDeviceCookie(w, deviceToken)
+		h.setAuthCookie(w, authResponse.Token)
+		csrf.Rotate(w)
+		h.writeJSON(w, map[string]interface{}{
+			"user":        authResponse.User,
+			"session":     authResponse.Session,
+			"permissions": authResponse.Permissions,
+		})
+
+	default:
+		h.writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
 // HandleLogout handles user logout requests
 func (h *Handlers) HandleLogout(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -72,8 +486,9 @@ func (h *Handlers) HandleLogout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Clear the auth cookie
+	// Clear the auth cookie and retire the CSRF token that went with it
 	h.clearAuthCookie(w)
+	csrf.Rotate(w)
 
 	h.writeJSON(w, map[string]string{"message": "Logged out successfully"})
 }
@@ -234,7 +649,7 @@ func (h *Handlers) HandleMe(w http.ResponseWriter, r *http.Request) {
 	response := map[string]interface{}{
 		"user":        user,
 		"session":     session,
-		"permissions": GetPermissionList(session.Role),
+		"permissions": NewAuthorizationService(session).GetCurrentPermissions(),
 	}
 
 	h.writeJSON(w, response)
@@ -261,6 +676,19 @@ func (h *Handlers) extractToken(r *http.Request) (string, error) {
 	return "", fmt.Errorf("no token found")
 }
 
+// writeLockout answers a sign-in refused by a lockout with 429 and
+// Retry-After, reporting whether err was one
+func (h *Handlers) writeLockout(w http.ResponseWriter, err error) bool {
+	var lockoutErr *LockoutError
+	if !errors.As(err, &lockoutErr) {
+		return false
+	}
+	retryAfter := int(math.Ceil(time.Until(lockoutErr.Until).Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+	h.writeError(w, err.Error(), http.StatusTooManyRequests)
+	return true
+}
+
 // setAuthCookie sets the JWT token as an HTTP-only cookie
 func (h *Handlers) setAuthCookie(w http.ResponseWriter, token string) {
 	cookie := &http.Cookie{
@@ -268,13 +696,27 @@ func (h *Handlers) setAuthCookie(w http.ResponseWriter, token string) {
 		Value:    token,
 		Path:     "/",
 		HttpOnly: true,
-		Secure:   false, // Set to true in production with HTTPS
+		Secure:   h.secureCookies,
 		SameSite: http.SameSiteLaxMode,
 		MaxAge:   7 * 24 * 60 * 60, // 7 days
 	}
 	http.SetCookie(w, cookie)
 }
 
+// setDeviceCookie marks the browser as a trusted device. Unlike the auth
+// cookie it outlives sign-outs.
+func (h *Handlers) setDeviceCookie(w http.ResponseWriter, token string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     DeviceCookieName,
+		Value:    token,
+		Path:     "/auth/pin",
+		HttpOnly: true,
+		Secure:   h.secureCookies,
+		SameSite: http.SameSiteStrictMode,
+		MaxAge:   int(deviceCookieMaxAge.Seconds()),
+	})
+}
+
 // clearAuthCookie clears the authentication cookie
 func (h *Handlers) clearAuthCookie(w http.ResponseWriter) {
 	cookie := &http.Cookie{
@@ -282,7 +724,7 @@ func (h *Handlers) clearAuthCookie(w http.ResponseWriter) {
 		Value:    "",
 		Path:     "/",
 		HttpOnly: true,
-		Secure:   false,
+		Secure:   h.secureCookies,
 		SameSite: http.SameSiteLaxMode,
 		MaxAge:   -1, // Expire immediately
 		Expires:  time.Unix(0, 0),
@@ -314,3 +756,16 @@ func (h *Handlers) writeError(w http.ResponseWriter, message string, status int)
 		fmt.Printf("Failed to encode error response: %v\n", err)
 	}
 }
+
+// isPIN reports whether pin is 4 digits
+func isPIN(pin string) bool {
+	if len(pin) != 4 {
+		return false
+	}
+	for _, c := range pin {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
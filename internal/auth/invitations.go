@@ -0,0 +1,325 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/ids"
+	"famstack/internal/notify"
+	"famstack/internal/validation"
+)
+
+// Invitations: a parent invites someone to take over a family member who
+// can't sign in yet, like a partner added during setup. An invitation is a
+// link, emailed if the parent gives an address, and a code to hand over;
+// either works until the invitation is accepted, revoked, or runs out.
+// Accepting sets the member's email, password and role and signs them in.
+
+const (
+	defaultInvitationDays = 7
+	maxInvitationDays     = 30
+)
+
+var errInvalidInvitation = errors.New("invalid or expired invitation")
+
+// Invitation is an invitation to take over a family member
+type Invitation struct {
+	ID         string     `json:"id" db:"id"`
+	FamilyID   string     `json:"family_id" db:"family_id"`
+	MemberID   string     `json:"member_id" db:"member_id"`
+	Role       Role       `json:"role" db:"role"`
+	Email      *string    `json:"email,omitempty" db:"email"`
+	CreatedBy  *string    `json:"created_by,omitempty" db:"created_by"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty" db:"accepted_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// NewInvitation is a just-created invitation with its link and code, which
+// are not kept and so are shown only once
+type NewInvitation struct {
+	Invitation
+	Link string `json:"link,omitempty"`
+	Code string `json:"code"`
+}
+
+// InvitationDetails is what the person accepting an invitation is shown
+type InvitationDetails struct {
+	FamilyName string    `json:"family_name" db:"family_name"`
+	FirstName  string    `json:"first_name" db:"first_name"`
+	Role       Role      `json:"role" db:"role"`
+	Email      *string   `json:"email,omitempty" db:"email"`
+	ExpiresAt  time.Time `json:"expires_at" db:"expires_at"`
+}
+
+// CreateInvitation invites someone to take over a member of familyID who has
+// no password yet. With an email, the link is sent there. Only admins can
+// invite an admin.
+func (s *Service) CreateInvitation(ctx context.Context, familyID, createdBy string, creatorRole Role, req *CreateInvitationRequest) (*NewInvitation, error) {
+	validator := validation.NewValidator()
+	validator.Required("member_id", req.MemberID)
+	validator.OneOf("role", string(req.Role), []string{string(RoleUser), string(RoleAdmin)})
+	if req.Role == RoleAdmin && creatorRole != RoleAdmin {
+		validator.AddError("role", "only admins can invite an admin")
+	}
+	if req.Email != "" {
+		if _, err := mail.ParseAddress(req.Email); err != nil {
+			validator.AddError("email", "email must be a valid email address")
+		}
+	}
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > maxInvitationDays {
+		validator.AddErrorf("expires_in_days", "expires_in_days must be between 1 and %d", maxInvitationDays)
+	}
+	if err := validator.ToError(); err != nil {
+		return nil, err
+	}
+
+	member, err := s.getFamilyMemberByID(req.MemberID)
+	if err != nil || member.FamilyID != familyID || !member.IsActive {
+		return nil, fmt.Errorf("member not found")
+	}
+	if member.PasswordHash != nil {
+		return nil, fmt.Errorf("member can already sign in")
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	code, err := randomCode()
+	if err != nil {
+		return nil, err
+	}
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultInvitationDays
+	}
+	now := time.Now().UTC()
+	invitation := Invitation{
+		ID:        ids.New("invite"),
+		FamilyID:  familyID,
+		MemberID:  member.ID,
+		Role:      req.Role,
+		CreatedBy: &createdBy,
+		ExpiresAt: now.AddDate(0, 0, days),
+		CreatedAt: now,
+	}
+	if req.Email != "" {
+		email := normalizeEmail(req.Email)
+		invitation.Email = &email
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO family_invitations (id, family_id, member_id, role, email, token_hash, code_hash, created_by, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, invitation.ID, invitation.FamilyID, invitation.MemberID, invitation.Role, invitation.Email,
+		hashLoginToken(token), hashLoginToken(code), invitation.CreatedBy, invitation.ExpiresAt, invitation.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save invitation: %w", err)
+	}
+
+	created := &NewInvitation{Invitation: invitation, Code: formatLoginCode(code)}
+	if s.magic.publicURL != "" {
+		created.Link = s.magic.publicURL + "/auth/invite/" + token
+	}
+
+	if invitation.Email != nil {
+		if s.magic.email == nil || created.Link == "" {
+			return nil, fmt.Errorf("emailed invitations need email notifications and server.public_url set up")
+		}
+		printer := s.familyPrinter(invitation.FamilyID)
+		_, err = s.magic.email.Send(ctx, notify.Recipient{
+			MemberID: member.ID,
+			Name:     member.DisplayName(),
+			Email:    *invitation.Email,
+		}, notify.Message{
+			Title: printer.Sprintf("invitation.title"),
+			Body:  printer.Sprintf("invitation.body", member.FirstName, printer.DayMonth(invitation.ExpiresAt)),
+			URL:   created.Link,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to email invitation: %w", err)
+		}
+	}
+	return created, nil
+}
+
+// ListInvitations returns a family's invitations that can still be
+// accepted, newest first
+func (s *Service) ListInvitations(familyID string) ([]Invitation, error) {
+	invitations, err := database.QueryAll[Invitation](s.db, `
+		SELECT id, family_id, member_id, role, email, created_by, expires_at, accepted_at, revoked_at, created_at
+		FROM family_invitations
+		WHERE family_id = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?
+		ORDER BY created_at DESC
+	`, familyID, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+	return invitations, nil
+}
+
+// RevokeInvitation stops an invitation that hasn't been accepted working
+func (s *Service) RevokeInvitation(familyID, invitationID string) error {
+	result, err := s.db.Exec(`
+		UPDATE family_invitations SET revoked_at = ?
+		WHERE id = ? AND family_id = ? AND accepted_at IS NULL AND revoked_at IS NULL
+	`, time.Now().UTC(), invitationID, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("invitation not found")
+	}
+	return nil
+}
+
+// GetInvitation returns what an invitation's link token or code invites to
+func (s *Service) GetInvitation(tokenOrCode string) (*InvitationDetails, error) {
+	invitation, err := s.findInvitation(tokenOrCode, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	details, err := database.QueryOne[InvitationDetails](s.db, `
+		SELECT f.name AS family_name, m.first_name
+		FROM families f JOIN family_members m ON m.family_id = f.id
+		WHERE m.id = ?
+	`, invitation.MemberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	details.Role = invitation.Role
+	details.Email = invitation.Email
+	details.ExpiresAt = invitation.ExpiresAt
+	return details, nil
+}
+
+// AcceptInvitation sets the invited member's credentials and signs them in.
+// An emailed invitation keeps the email it was sent to. Failures to find the
+// invitation count against ip like failed password sign-ins.
+func (s *Service) AcceptInvitation(tokenOrCode string, req *AcceptInvitationRequest, ip string) (*AuthResponse, error) {
+	now := time.Now().UTC()
+	if err := s.checkLockout(now, lockoutKey{LockoutIP, ip}); err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.findInvitation(tokenOrCode, now)
+	if errors.Is(err, errInvalidInvitation) {
+		s.countTokenFailure(ip, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	email := normalizeEmail(req.Email)
+	// The link reaching the inbox it was sent to verifies the email
+	verified := invitation.Email != nil
+	if verified {
+		email = *invitation.Email
+	}
+
+	validator := validation.NewValidator()
+	if _, parseErr := mail.ParseAddress(email); parseErr != nil {
+		validator.AddError("email", "email must be a valid email address")
+	}
+	if passwordErr := ValidatePassword(req.Password); passwordErr != nil {
+		validator.AddError("password", passwordErr.Error())
+	}
+	if err = validator.ToError(); err != nil {
+		return nil, err
+	}
+
+	var taken int
+	err = s.db.QueryRow(`SELECT COUNT(*) FROM family_members WHERE lower(email) = ? AND id <> ?`,
+		email, invitation.MemberID).Scan(&taken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+	if taken > 0 {
+		return nil, validation.ValidationErrors{{Field: "email", Message: "email is already in use"}}
+	}
+
+	hash, err := HashPassword(req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	err = s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		// Only one acceptance can win a race for the same invitation
+		result, err := tx.Exec(`
+			UPDATE family_invitations SET accepted_at = ?
+			WHERE id = ? AND accepted_at IS NULL AND revoked_at IS NULL
+		`, now, invitation.ID)
+		if err != nil {
+			return fmt.Errorf("failed to accept invitation: %w", err)
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return errInvalidInvitation
+		}
+
+		result, err = tx.Exec(`
+			UPDATE family_members SET email = ?, password_hash = ?, role = ?, email_verified = ?, updated_at = ?
+			WHERE id = ? AND password_hash IS NULL AND is_active = TRUE
+		`, email, hash, invitation.Role, verified, now, invitation.MemberID)
+		if err != nil {
+			return fmt.Errorf("failed to set credentials: %w", err)
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			// The member signed up some other way since
+			return errInvalidInvitation
+		}
+
+		// Other invitations for the member are moot now
+		_, err = tx.Exec(`
+			UPDATE family_invitations SET revoked_at = ?
+			WHERE member_id = ? AND id <> ? AND accepted_at IS NULL AND revoked_at IS NULL
+		`, now, invitation.MemberID, invitation.ID)
+		if err != nil {
+			return fmt.Errorf("failed to revoke other invitations: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.getFamilyMemberByID(invitation.MemberID)
+	if err != nil {
+		return nil, err
+	}
+	s.auditLogin(email, ip, &member.ID, LoginSucceeded, now)
+	if updateErr := s.updateLastLogin(member.ID); updateErr != nil {
+		fmt.Printf("Failed to update last login for user %s: %v\n", member.ID, updateErr)
+	}
+	return s.newAuthResponse(member, invitation.Role)
+}
+
+// findInvitation returns the invitation a link token or code belongs to if
+// it can still be accepted
+func (s *Service) findInvitation(tokenOrCode string, now time.Time) (*Invitation, error) {
+	if tokenOrCode == "" {
+		return nil, errInvalidInvitation
+	}
+	invitation, err := database.QueryOne[Invitation](s.db, `
+		SELECT id, family_id, member_id, role, email, created_by, expires_at, accepted_at, revoked_at, created_at
+		FROM family_invitations
+		WHERE token_hash = ? OR code_hash = ?
+	`, hashLoginToken(tokenOrCode), hashLoginToken(normalizeLoginCode(tokenOrCode)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errInvalidInvitation
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	if invitation.AcceptedAt != nil || invitation.RevokedAt != nil || !now.Before(invitation.ExpiresAt) {
+		return nil, errInvalidInvitation
+	}
+	return invitation, nil
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"famstack/internal/validation"
+)
+
+func setupInvitationTest(t *testing.T) (*Service, *recordingChannel) {
+	service, email := setupMagicLinkTest(t)
+
+	now := time.Now()
+	_, err := service.db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, is_active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"member_partner", "fam_lockout", "Alex", "Parent", "adult", true, now, now)
+	require.NoError(t, err)
+	return service, email
+}
+
+func TestInvitation_EmailedLink(t *testing.T) {
+	service, email := setupInvitationTest(t)
+	ctx := context.Background()
+
+	_, err := service.CreateInvitation(ctx, "fam_lockout", "member_kid", RoleUser,
+		&CreateInvitationRequest{MemberID: "member_partner", Role: RoleAdmin})
+	var validationErrs validation.ValidationErrors
+	assert.ErrorAs(t, err, &validationErrs, "only admins can invite an admin")
+	_, err = service.CreateInvitation(ctx, "fam_lockout", "member_lockout", RoleAdmin,
+		&CreateInvitationRequest{MemberID: "member_lockout", Role: RoleUser})
+	assert.EqualError(t, err, "member can already sign in")
+
+	invitation, err := service.CreateInvitation(ctx, "fam_lockout", "member_lockout", RoleAdmin,
+		&CreateInvitationRequest{MemberID: "member_partner", Role: RoleAdmin, Email: "Alex@Example.com"})
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, defaultInvitationDays), invitation.ExpiresAt, time.Minute)
+	require.Len(t, email.sent, 1)
+	assert.Equal(t, invitation.Link, email.sent[0].URL)
+	link, err := url.Parse(invitation.Link)
+	require.NoError(t, err)
+	token := path.Base(link.Path)
+
+	details, err := service.GetInvitation(token)
+	require.NoError(t, err)
+	assert.Equal(t, "Lockout Family", details.FamilyName)
+	assert.Equal(t, "Alex", details.FirstName)
+	assert.Equal(t, "alex@example.com", *details.Email)
+
+	// The emailed address wins over whatever is typed in
+	response, err := service.AcceptInvitation(token, &AcceptInvitationRequest{Email: "other@example.com", Password: "battery-staple"}, "192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, "member_partner", response.User.ID)
+	assert.Equal(t, RoleAdmin, response.Session.Role)
+	assert.True(t, response.User.EmailVerified)
+
+	_, err = service.AcceptInvitation(token, &AcceptInvitationRequest{Password: "battery-staple"}, "192.0.2.1")
+	assert.ErrorIs(t, err, errInvalidInvitation, "an invitation works once")
+
+	login, err := service.Login("alex@example.com", "battery-staple", "192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, "member_partner", login.User.ID)
+}
+
+func TestInvitation_CodeAndRevoke(t *testing.T) {
+	service, email := setupInvitationTest(t)
+	ctx := context.Background()
+
+	first, err := service.CreateInvitation(ctx, "fam_lockout", "member_lockout", RoleAdmin,
+		&CreateInvitationRequest{MemberID: "member_partner", Role: RoleUser})
+	require.NoError(t, err)
+	assert.Empty(t, email.sent, "nothing is emailed without an address")
+	assert.Regexp(t, `^[A-Z2-9]{4}-[A-Z2-9]{4}$`, first.Code)
+
+	require.NoError(t, service.RevokeInvitation("fam_lockout", first.ID))
+	assert.EqualError(t, service.RevokeInvitation("fam_lockout", first.ID), "invitation not found")
+	_, err = service.GetInvitation(first.Code)
+	assert.ErrorIs(t, err, errInvalidInvitation)
+
+	second, err := service.CreateInvitation(ctx, "fam_lockout", "member_lockout", RoleAdmin,
+		&CreateInvitationRequest{MemberID: "member_partner", Role: RoleUser, ExpiresInDays: 2})
+	require.NoError(t, err)
+	third, err := service.CreateInvitation(ctx, "fam_lockout", "member_lockout", RoleAdmin,
+		&CreateInvitationRequest{MemberID: "member_partner", Role: RoleUser})
+	require.NoError(t, err)
+
+	invitations, err := service.ListInvitations("fam_lockout")
+	require.NoError(t, err)
+	assert.Len(t, invitations, 2)
+
+	_, err = service.AcceptInvitation(second.Code, &AcceptInvitationRequest{Email: "pat@example.com", Password: "battery-staple"}, "192.0.2.1")
+	assert.ErrorContains(t, err, "email is already in use")
+	_, err = service.AcceptInvitation(second.Code, &AcceptInvitationRequest{Email: "alex@example.com", Password: "short"}, "192.0.2.1")
+	assert.ErrorContains(t, err, "password must be at least 8 characters")
+
+	response, err := service.AcceptInvitation(" "+second.Code[:4]+second.Code[5:], &AcceptInvitationRequest{Email: "Alex@Example.com", Password: "battery-staple"}, "192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, RoleUser, response.Session.Role)
+	assert.False(t, response.User.EmailVerified, "a code doesn't prove the email")
+
+	_, err = service.GetInvitation(third.Code)
+	assert.ErrorIs(t, err, errInvalidInvitation, "accepting one invitation revokes the member's others")
+	invitations, err = service.ListInvitations("fam_lockout")
+	require.NoError(t, err)
+	assert.Empty(t, invitations)
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Account linking lets a voice assistant, such as an Alexa skill, act as a
+// member. The member approves the assistant in the browser, which hands the
+// assistant a short-lived code; the assistant trades it for a personal API
+// token limited to the scopes it needs. Unlinking is revoking that token.
+
+// linkCodeTTL is how long an assistant has to trade a code
+const linkCodeTTL = 5 * time.Minute
+
+// ErrInvalidLinkCode is returned for a code that is unknown, used, expired
+// or presented by another client or with another redirect URI
+var ErrInvalidLinkCode = errors.New("invalid or expired authorization code")
+
+// CreateLinkCode issues a code that links the member to the client once
+// traded through ExchangeLinkCode
+func (s *Service) CreateLinkCode(familyID, memberID, clientID, redirectURI string) (string, error) {
+	code, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
+	_, err = s.db.Exec(`
+		INSERT INTO account_link_codes (code_hash, client_id, redirect_uri, family_id, member_id, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, hashLoginToken(code), clientID, redirectURI, familyID, memberID, now, now.Add(linkCodeTTL))
+	if err != nil {
+		return "", fmt.Errorf("failed to create authorization code: %w", err)
+	}
+	return code, nil
+}
+
+// ExchangeLinkCode trades a code for a personal API token named name with
+// the given scopes. Each code works once.
+func (s *Service) ExchangeLinkCode(code, clientID, redirectURI, name string, scopes []string) (string, error) {
+	codeHash := hashLoginToken(code)
+	var codeClientID, codeRedirectURI, familyID, memberID string
+	var expiresAt time.Time
+	err := s.db.QueryRow(`
+		SELECT client_id, redirect_uri, family_id, member_id, expires_at
+		FROM account_link_codes
+		WHERE code_hash = ?
+	`, codeHash).Scan(&codeClientID, &codeRedirectURI, &familyID, &memberID, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrInvalidLinkCode
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get authorization code: %w", err)
+	}
+
+	// Deleting first makes the code single-use even when two exchanges race
+	result, err := s.db.Exec(`DELETE FROM account_link_codes WHERE code_hash = ?`, codeHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to use authorization code: %w", err)
+	}
+	if deleted, _ := result.RowsAffected(); deleted != 1 {
+		return "", ErrInvalidLinkCode
+	}
+	if codeClientID != clientID || codeRedirectURI != redirectURI || !time.Now().UTC().Before(expiresAt) {
+		return "", ErrInvalidLinkCode
+	}
+
+	_, token, err := s.CreateAPIToken(familyID, memberID, name, APITokenPersonal, scopes, 0)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
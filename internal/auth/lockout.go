@@ -0,0 +1,283 @@
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/ids"
+)
+
+// Sign-in lockout limits. Failures are counted per email and, across emails,
+// per client address; once either passes its limit, sign-ins for it are
+// refused until the lockout runs out, even with the right password.
+const (
+	maxEmailFailures      = 5
+	maxIPFailures         = 20
+	maxPINFailures        = 5                // per member, on trusted devices
+	loginFailureWindow    = 15 * time.Minute // failures older than this are forgotten
+	loginLockoutDuration  = 15 * time.Minute
+	loginAttemptRetention = 90 * 24 * time.Hour
+)
+
+// Lockout kinds
+const (
+	LockoutEmail = "email"
+	LockoutIP    = "ip"
+	LockoutPIN   = "pin" // per member, see PINLogin
+)
+
+// Sign-in attempt outcomes
+const (
+	LoginSucceeded          = "succeeded"
+	LoginInvalidCredentials = "invalid_credentials"
+	LoginLockedOut          = "locked_out"
+)
+
+// LoginAttempt is one recorded sign-in attempt
+type LoginAttempt struct {
+	ID        string    `json:"id" db:"id"`
+	Email     string    `json:"email" db:"email"`
+	IPAddress string    `json:"ip_address" db:"ip_address"`
+	MemberID  *string   `json:"member_id,omitempty" db:"member_id"`
+	Outcome   string    `json:"outcome" db:"outcome"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Lockout counts the recent failed sign-ins for an email or client address
+type Lockout struct {
+	Kind           string     `json:"kind" db:"kind"`
+	Subject        string     `json:"subject" db:"subject"`
+	Failures       int        `json:"failures" db:"failures"`
+	FirstFailureAt time.Time  `json:"first_failure_at" db:"first_failure_at"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+}
+
+// LockoutError is returned by Login while the email or address is locked out
+type LockoutError struct {
+	Until time.Time
+}
+
+func (e *LockoutError) Error() string {
+	return "too many failed sign-in attempts, please try again later"
+}
+
+// errInvalidCredentials is what a failed sign-in reports, whether the email
+// or the password was wrong
+var errInvalidCredentials = errors.New("invalid credentials")
+
+// lockoutKey names what failures are counted against
+type lockoutKey struct {
+	kind    string
+	subject string
+}
+
+// checkLockout returns a LockoutError when any of keys is locked out
+func (s *Service) checkLockout(now time.Time, keys ...lockoutKey) error {
+	conditions := make([]string, len(keys))
+	args := make([]any, 0, 2*len(keys)+1)
+	for i, key := range keys {
+		conditions[i] = "(kind = ? AND subject = ?)"
+		args = append(args, key.kind, key.subject)
+	}
+	args = append(args, now)
+
+	lockouts, err := database.QueryAll[Lockout](s.db, `
+		SELECT kind, subject, failures, first_failure_at, locked_until
+		FROM login_lockouts
+		WHERE (`+strings.Join(conditions, " OR ")+`) AND locked_until > ?
+		ORDER BY locked_until DESC
+	`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to check lockouts: %w", err)
+	}
+	if len(lockouts) > 0 {
+		return &LockoutError{Until: *lockouts[0].LockedUntil}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed sign-in against the email and the
+// address, locking out whichever passes its limit
+func (s *Service) recordLoginFailure(email, ip string, now time.Time) error {
+	if err := s.countFailure(LockoutEmail, email, maxEmailFailures, now); err != nil {
+		return err
+	}
+	if ip == "" {
+		return nil
+	}
+	return s.countFailure(LockoutIP, ip, maxIPFailures, now)
+}
+
+func (s *Service) countFailure(kind, subject string, limit int, now time.Time) error {
+	lockout, err := database.QueryOne[Lockout](s.db, `
+		SELECT kind, subject, failures, first_failure_at, locked_until
+		FROM login_lockouts
+		WHERE kind = ? AND subject = ?
+	`, kind, subject)
+	switch {
+	case errors.Is(err, sql.ErrNoRows) || (err == nil && now.Sub(lockout.FirstFailureAt) > loginFailureWindow):
+		lockout = &Lockout{Kind: kind, Subject: subject, FirstFailureAt: now}
+	case err != nil:
+		return fmt.Errorf("failed to get lockout: %w", err)
+	}
+
+	lockout.Failures++
+	if lockout.Failures >= limit {
+		until := now.Add(loginLockoutDuration)
+		lockout.LockedUntil = &until
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO login_lockouts (kind, subject, failures, first_failure_at, locked_until)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (kind, subject) DO UPDATE SET
+			failures = excluded.failures,
+			first_failure_at = excluded.first_failure_at,
+			locked_until = excluded.locked_until
+	`, kind, subject, lockout.Failures, lockout.FirstFailureAt, lockout.LockedUntil)
+	if err != nil {
+		return fmt.Errorf("failed to save lockout: %w", err)
+	}
+	return nil
+}
+
+// recordLoginAttempt adds an attempt to the audit log. Successful sign-ins
+// also reset the email's failure count and prune old attempts.
+func (s *Service) recordLoginAttempt(email, ip string, memberID *string, outcome string, now time.Time) error {
+	_, err := s.db.Exec(`
+		INSERT INTO login_attempts (id, email, ip_address, member_id, outcome, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, ids.New("login"), email, ip, memberID, outcome, now)
+	if err != nil {
+		return fmt.Errorf("failed to record login attempt: %w", err)
+	}
+	if outcome != LoginSucceeded {
+		return nil
+	}
+
+	if _, err := s.db.Exec(`DELETE FROM login_lockouts WHERE kind = ? AND subject = ?`, LockoutEmail, email); err != nil {
+		return fmt.Errorf("failed to reset lockout: %w", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM login_attempts WHERE created_at < ?`, now.Add(-loginAttemptRetention)); err != nil {
+		return fmt.Errorf("failed to prune login attempts: %w", err)
+	}
+	return nil
+}
+
+// familyMemberEmails and familyMemberIDs select a family's members for
+// scoping the sign-in audit to them
+const (
+	familyMemberEmails = `SELECT lower(email) FROM family_members WHERE family_id = ? AND email IS NOT NULL`
+	familyMemberIDs    = `SELECT id FROM family_members WHERE family_id = ?`
+)
+
+// familyLockouts limits lockouts to a family's: its members' emails and PINs.
+// Addresses are shared between families, so IP lockouts are left to the
+// server operator, see ListServerLockouts.
+const familyLockouts = `((kind = '` + LockoutEmail + `' AND subject IN (` + familyMemberEmails + `))
+	OR (kind = '` + LockoutPIN + `' AND subject IN (` + familyMemberIDs + `)))`
+
+// ListLoginAttempts returns the most recent sign-in attempts on a family's
+// members, newest first. Attempts on emails that match no member belong to
+// no family, see ListServerLoginAttempts.
+func (s *Service) ListLoginAttempts(familyID string, limit int) ([]LoginAttempt, error) {
+	attempts, err := database.QueryAll[LoginAttempt](s.db, `
+		SELECT id, email, ip_address, member_id, outcome, created_at
+		FROM login_attempts
+		WHERE member_id IN (`+familyMemberIDs+`) OR email IN (`+familyMemberEmails+`)
+		ORDER BY created_at DESC
+		LIMIT ?
+	`, familyID, familyID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list login attempts: %w", err)
+	}
+	return attempts, nil
+}
+
+// ListServerLoginAttempts returns the most recent sign-in attempts across
+// every family, and on emails that match no member, newest first. It is for
+// the server operator.
+func (s *Service) ListServerLoginAttempts(limit int) ([]LoginAttempt, error) {
+	attempts, err := database.QueryAll[LoginAttempt](s.db, `
+		SELECT id, email, ip_address, member_id, outcome, created_at
+		FROM login_attempts
+		ORDER BY created_at DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list login attempts: %w", err)
+	}
+	return attempts, nil
+}
+
+// ListLockouts returns a family's members' emails and PINs locked out right
+// now
+func (s *Service) ListLockouts(familyID string) ([]Lockout, error) {
+	lockouts, err := database.QueryAll[Lockout](s.db, `
+		SELECT kind, subject, failures, first_failure_at, locked_until
+		FROM login_lockouts
+		WHERE `+familyLockouts+` AND locked_until > ?
+		ORDER BY locked_until DESC
+	`, familyID, familyID, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lockouts: %w", err)
+	}
+	return lockouts, nil
+}
+
+// ListServerLockouts returns every email, address and PIN locked out right
+// now. It is for the server operator.
+func (s *Service) ListServerLockouts() ([]Lockout, error) {
+	lockouts, err := database.QueryAll[Lockout](s.db, `
+		SELECT kind, subject, failures, first_failure_at, locked_until
+		FROM login_lockouts
+		WHERE locked_until > ?
+		ORDER BY locked_until DESC
+	`, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lockouts: %w", err)
+	}
+	return lockouts, nil
+}
+
+// ClearLockout lifts a lockout on a family's member and forgets the failures
+// behind it. Emails still under their limit aren't locked out, so there is
+// nothing to clear.
+func (s *Service) ClearLockout(familyID, kind, subject string) error {
+	if kind == LockoutEmail {
+		subject = normalizeEmail(subject)
+	}
+	return s.clearLockout(`
+		DELETE FROM login_lockouts
+		WHERE kind = ? AND subject = ? AND locked_until > ? AND `+familyLockouts,
+		kind, subject, time.Now().UTC(), familyID, familyID)
+}
+
+// ClearServerLockout is ClearLockout for the server operator, for any email,
+// address or PIN
+func (s *Service) ClearServerLockout(kind, subject string) error {
+	if kind == LockoutEmail {
+		subject = normalizeEmail(subject)
+	}
+	return s.clearLockout(`DELETE FROM login_lockouts WHERE kind = ? AND subject = ? AND locked_until > ?`,
+		kind, subject, time.Now().UTC())
+}
+
+func (s *Service) clearLockout(query string, args ...any) error {
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to clear lockout: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("lockout not found")
+	}
+	return nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
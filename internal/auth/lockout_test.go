@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"famstack/internal/database"
+)
+
+func setupLockoutTest(t *testing.T) *Service {
+	dbFile := fmt.Sprintf("test_auth_%d.db", time.Now().UnixNano())
+	db, err := database.New(dbFile)
+	require.NoError(t, err)
+	require.NoError(t, db.MigrateUp())
+	t.Cleanup(func() {
+		db.Close()
+		os.Remove(dbFile)
+	})
+
+	key, err := GenerateSecretKey()
+	require.NoError(t, err)
+	service := &Service{db: db, jwtManager: NewJWTManager(key, "famstack-test"), upgradeAttempts: make(map[string][]time.Time)}
+
+	hash, err := HashPassword("correct-horse")
+	require.NoError(t, err)
+	now := time.Now()
+	_, err = db.Exec(`INSERT INTO families (id, name) VALUES (?, ?)`, "fam_lockout", "Lockout Family")
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, email, password_hash, role, email_verified, is_active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		"member_lockout", "fam_lockout", "Pat", "Parent", "adult", "pat@example.com", hash, "admin", true, true, now, now)
+	require.NoError(t, err)
+	return service
+}
+
+func TestLogin_LocksOutAfterRepeatedFailures(t *testing.T) {
+	service := setupLockoutTest(t)
+
+	for range maxEmailFailures {
+		_, err := service.Login("pat@example.com", "wrong", "192.0.2.1")
+		require.EqualError(t, err, "invalid credentials")
+	}
+
+	// Locked out even with the right password, from any address
+	_, err := service.Login("Pat@Example.com", "correct-horse", "192.0.2.2")
+	var lockoutErr *LockoutError
+	require.True(t, errors.As(err, &lockoutErr))
+	assert.WithinDuration(t, time.Now().Add(loginLockoutDuration), lockoutErr.Until, time.Minute)
+
+	lockouts, err := service.ListLockouts("fam_lockout")
+	require.NoError(t, err)
+	require.Len(t, lockouts, 1)
+	assert.Equal(t, LockoutEmail, lockouts[0].Kind)
+	assert.Equal(t, "pat@example.com", lockouts[0].Subject)
+
+	assert.EqualError(t, service.ClearServerLockout(LockoutIP, "192.0.2.1"), "lockout not found", "the address is under its limit")
+	require.NoError(t, service.ClearLockout("fam_lockout", LockoutEmail, "PAT@example.com"))
+
+	response, err := service.Login("pat@example.com", "correct-horse", "192.0.2.2")
+	require.NoError(t, err)
+	assert.Equal(t, "member_lockout", response.User.ID)
+
+	attempts, err := service.ListLoginAttempts("fam_lockout", 10)
+	require.NoError(t, err)
+	require.Len(t, attempts, maxEmailFailures+2)
+	assert.Equal(t, LoginSucceeded, attempts[0].Outcome)
+	assert.Equal(t, LoginLockedOut, attempts[1].Outcome)
+	assert.Equal(t, LoginInvalidCredentials, attempts[2].Outcome)
+	require.NotNil(t, attempts[2].MemberID)
+	assert.Equal(t, "192.0.2.1", attempts[2].IPAddress)
+}
+
+func TestLogin_RefusesDeactivatedMember(t *testing.T) {
+	service := setupLockoutTest(t)
+
+	response, err := service.Login("pat@example.com", "correct-horse", "192.0.2.1")
+	require.NoError(t, err)
+
+	_, err = service.db.Exec(`UPDATE family_members SET is_active = FALSE WHERE id = ?`, "member_lockout")
+	require.NoError(t, err)
+
+	_, err = service.Login("pat@example.com", "correct-horse", "192.0.2.1")
+	assert.EqualError(t, err, "invalid credentials")
+
+	// A session signed in before can't be extended
+	_, err = service.RefreshToken(response.Token)
+	assert.EqualError(t, err, "member is no longer active")
+}
+
+func TestLockouts_ScopedToFamily(t *testing.T) {
+	service := setupLockoutTest(t)
+	_, err := service.db.Exec(`INSERT INTO families (id, name) VALUES (?, ?)`, "fam_other", "Other Family")
+	require.NoError(t, err)
+
+	for range maxEmailFailures {
+		_, err := service.Login("pat@example.com", "wrong", "192.0.2.1")
+		require.EqualError(t, err, "invalid credentials")
+	}
+	for i := range maxIPFailures {
+		_, err := service.Login(fmt.Sprintf("nobody%d@example.com", i), "wrong", "192.0.2.9")
+		require.EqualError(t, err, "invalid credentials")
+	}
+
+	// Another family's admins see none of it
+	lockouts, err := service.ListLockouts("fam_other")
+	require.NoError(t, err)
+	assert.Empty(t, lockouts)
+	attempts, err := service.ListLoginAttempts("fam_other", 100)
+	require.NoError(t, err)
+	assert.Empty(t, attempts)
+	assert.EqualError(t, service.ClearLockout("fam_other", LockoutEmail, "pat@example.com"), "lockout not found")
+
+	// The family sees its member's email, but not the address or the
+	// attempts on emails that match no member
+	lockouts, err = service.ListLockouts("fam_lockout")
+	require.NoError(t, err)
+	require.Len(t, lockouts, 1)
+	assert.Equal(t, "pat@example.com", lockouts[0].Subject)
+	attempts, err = service.ListLoginAttempts("fam_lockout", 100)
+	require.NoError(t, err)
+	assert.Len(t, attempts, maxEmailFailures)
+	assert.EqualError(t, service.ClearLockout("fam_lockout", LockoutIP, "192.0.2.9"), "lockout not found")
+
+	// The server operator sees everything
+	lockouts, err = service.ListServerLockouts()
+	require.NoError(t, err)
+	assert.Len(t, lockouts, 2)
+	attempts, err = service.ListServerLoginAttempts(100)
+	require.NoError(t, err)
+	assert.Len(t, attempts, maxEmailFailures+maxIPFailures)
+	require.NoError(t, service.ClearServerLockout(LockoutIP, "192.0.2.9"))
+}
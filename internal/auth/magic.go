@@ -0,0 +1,327 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"famstack/internal/config"
+	"famstack/internal/database"
+	"famstack/internal/models"
+	"famstack/internal/notify"
+	"famstack/internal/templates"
+)
+
+// Passwordless sign-in: a member of an allowed type, usually a kid, signs in
+// with a link emailed to them or a short code a parent creates for them.
+// Tokens and codes work once, run out after a few minutes, and are stored
+// only as hashes.
+
+// Login token kinds
+const (
+	LoginTokenLink = "link"
+	LoginTokenCode = "code"
+)
+
+const (
+	defaultMagicLinkExpiry = 15 * time.Minute
+	loginCodeLength        = 8
+	// loginCodeAlphabet leaves out letters easily mistaken for digits
+	loginCodeAlphabet = "ABCDEFGHJKMNPQRSTVWXYZ23456789"
+)
+
+var (
+	errMagicLinksDisabled = errors.New("magic link sign-in is turned off")
+	errInvalidLoginToken  = errors.New("invalid or expired sign-in link or code")
+)
+
+// LoginCode is a one-time sign-in code a parent created for a member
+type LoginCode struct {
+	MemberID  string    `json:"member_id"`
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// loginToken is a row of login_tokens
+type loginToken struct {
+	MemberID  string     `db:"member_id"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+}
+
+// magicLinks holds what passwordless sign-in needs beyond the database
+type magicLinks struct {
+	cfg       config.MagicLinkConfig
+	publicURL string
+	email     notify.Channel // nil when email notifications are off
+}
+
+// SetMagicLinks turns on passwordless sign-in as cfg says. Links are emailed
+// through email and point at publicURL; without either, only codes work.
+func (s *Service) SetMagicLinks(cfg config.MagicLinkConfig, publicURL string, email notify.Channel) {
+	s.magic = magicLinks{cfg: cfg, publicURL: strings.TrimSuffix(publicURL, "/"), email: email}
+}
+
+// RequestMagicLink emails a sign-in link to the member with this email. To
+// keep emails from being probed, an email that matches no member allowed to
+// use links is not an error.
+func (s *Service) RequestMagicLink(ctx context.Context, email string) error {
+	if !s.magic.cfg.Enabled {
+		return errMagicLinksDisabled
+	}
+	if s.magic.email == nil || s.magic.publicURL == "" {
+		return fmt.Errorf("emailed sign-in links need email notifications and server.public_url set up")
+	}
+
+	var memberID string
+	err := s.db.QueryRow(`SELECT id FROM family_members WHERE lower(email) = ? AND is_active = TRUE`,
+		normalizeEmail(email)).Scan(&memberID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find member: %w", err)
+	}
+	member, err := s.getFamilyMemberByID(memberID)
+	if err != nil {
+		return err
+	}
+	if !s.magicLinkAllowed(member) {
+		return nil
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+	expiresAt, err := s.saveLoginToken(hashLoginToken(token), member.ID, LoginTokenLink, nil)
+	if err != nil {
+		return err
+	}
+
+	printer := s.familyPrinter(member.FamilyID)
+	_, err = s.magic.email.Send(ctx, notify.Recipient{
+		MemberID: member.ID,
+		Name:     member.DisplayName(),
+		Email:    *member.Email,
+	}, notify.Message{
+		Title: printer.Sprintf("magic_link.title"),
+		Body: printer.Sprintf("magic_link.body",
+			member.FirstName, printer.Clock(expiresAt)+" "+expiresAt.Format("MST")),
+		URL: s.magic.publicURL + "/auth/magic?token=" + token,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to email sign-in link: %w", err)
+	}
+	return nil
+}
+
+// CreateLoginCode creates a one-time code a member of familyID can sign in
+// with, for a parent to hand over
+func (s *Service) CreateLoginCode(familyID, createdBy, memberID string) (*LoginCode, error) {
+	if !s.magic.cfg.Enabled {
+		return nil, errMagicLinksDisabled
+	}
+
+	member, err := s.getFamilyMemberByID(memberID)
+	if err != nil || member.FamilyID != familyID {
+		return nil, fmt.Errorf("member not found")
+	}
+	if !s.magicLinkAllowed(member) {
+		return nil, fmt.Errorf("member cannot sign in with a code")
+	}
+
+	code, err := randomCode()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt, err := s.saveLoginToken(hashLoginToken(code), member.ID, LoginTokenCode, &createdBy)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginCode{
+		MemberID:  member.ID,
+		Code:      formatLoginCode(code),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// RedeemLoginToken signs in with an emailed link's token or a code. Failures
+// count against ip like failed password sign-ins.
+func (s *Service) RedeemLoginToken(kind, token, ip string) (*AuthResponse, error) {
+	if !s.magic.cfg.Enabled {
+		return nil, errMagicLinksDisabled
+	}
+	now := time.Now().UTC()
+	if err := s.checkLockout(now, lockoutKey{LockoutIP, ip}); err != nil {
+		return nil, err
+	}
+
+	if kind == LoginTokenCode {
+		token = normalizeLoginCode(token)
+	}
+	member, err := s.useLoginToken(kind, hashLoginToken(token), now)
+	if errors.Is(err, errInvalidLoginToken) {
+		s.countTokenFailure(ip, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	email := ""
+	if member.Email != nil {
+		email = normalizeEmail(*member.Email)
+	}
+	s.auditLogin(email, ip, &member.ID, LoginSucceeded, now)
+	if updateErr := s.updateLastLogin(member.ID); updateErr != nil {
+		fmt.Printf("Failed to update last login for user %s: %v\n", member.ID, updateErr)
+	}
+
+	// Members without a role of their own sign in as standard members
+	role := RoleUser
+	if member.Role != nil {
+		role = Role(*member.Role)
+	}
+	return s.newAuthResponse(member, role)
+}
+
+// countTokenFailure records a token or code that didn't work, counting it
+// against ip like a failed password sign-in
+func (s *Service) countTokenFailure(ip string, now time.Time) {
+	s.auditLogin("", ip, nil, LoginInvalidCredentials, now)
+	if ip != "" {
+		if err := s.countFailure(LockoutIP, ip, maxIPFailures, now); err != nil {
+			fmt.Printf("Failed to record login failure for %s: %v\n", ip, err)
+		}
+	}
+}
+
+// useLoginToken marks a token used and returns the member it signs in
+func (s *Service) useLoginToken(kind, tokenHash string, now time.Time) (*models.FamilyMember, error) {
+	token, err := database.QueryOne[loginToken](s.db, `
+		SELECT member_id, expires_at, used_at FROM login_tokens WHERE token_hash = ? AND kind = ?
+	`, tokenHash, kind)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errInvalidLoginToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login token: %w", err)
+	}
+	if token.UsedAt != nil || !now.Before(token.ExpiresAt) {
+		return nil, errInvalidLoginToken
+	}
+
+	// Only one redemption can win a race for the same token
+	result, err := s.db.Exec(`UPDATE login_tokens SET used_at = ? WHERE token_hash = ? AND used_at IS NULL`, now, tokenHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use login token: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, errInvalidLoginToken
+	}
+
+	member, err := s.getFamilyMemberByID(token.MemberID)
+	if err != nil {
+		return nil, err
+	}
+	// The member's type or the config may have changed since
+	if !s.magicLinkAllowed(member) {
+		return nil, errInvalidLoginToken
+	}
+	return member, nil
+}
+
+func (s *Service) saveLoginToken(tokenHash, memberID, kind string, createdBy *string) (time.Time, error) {
+	expiry := defaultMagicLinkExpiry
+	if s.magic.cfg.ExpiryMinutes > 0 {
+		expiry = time.Duration(s.magic.cfg.ExpiryMinutes) * time.Minute
+	}
+	now := time.Now().UTC()
+	expiresAt := now.Add(expiry)
+
+	_, err := s.db.Exec(`
+		INSERT INTO login_tokens (token_hash, member_id, kind, created_by, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, tokenHash, memberID, kind, createdBy, expiresAt, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to save login token: %w", err)
+	}
+	return expiresAt, nil
+}
+
+// magicLinkAllowed reports whether the config lets member sign in without a
+// password
+func (s *Service) magicLinkAllowed(member *models.FamilyMember) bool {
+	memberTypes := s.magic.cfg.MemberTypes
+	if len(memberTypes) == 0 {
+		memberTypes = []string{string(models.MemberTypeChild)}
+	}
+	return member.IsActive && slices.Contains(memberTypes, string(member.MemberType))
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// randomCode returns a code of loginCodeLength characters from
+// loginCodeAlphabet
+func randomCode() (string, error) {
+	code := make([]byte, loginCodeLength)
+	for i := range code {
+		b := make([]byte, 1)
+		// Rejection sampling keeps every character equally likely
+		for {
+			if _, err := rand.Read(b); err != nil {
+				return "", fmt.Errorf("failed to generate code: %w", err)
+			}
+			if int(b[0]) < 256-256%len(loginCodeAlphabet) {
+				break
+			}
+		}
+		code[i] = loginCodeAlphabet[int(b[0])%len(loginCodeAlphabet)]
+	}
+	return string(code), nil
+}
+
+// formatLoginCode splits a code in half to make it easier to read out
+func formatLoginCode(code string) string {
+	half := len(code) / 2
+	return code[:half] + "-" + code[half:]
+}
+
+func hashLoginToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeLoginCode accepts a code typed in lowercase or with separators
+func normalizeLoginCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(code))
+}
+
+// familyPrinter words emails in the family's locale, or the default one when
+// it can't be read: a sign-in link in the wrong language beats none
+func (s *Service) familyPrinter(familyID string) *templates.Printer {
+	var locale string
+	if err := s.db.QueryRow(`SELECT locale FROM families WHERE id = ?`, familyID).Scan(&locale); err != nil {
+		locale = templates.DefaultLocale
+	}
+	return templates.NewPrinter(locale)
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"famstack/internal/config"
+	"famstack/internal/notify"
+)
+
+type recordingChannel struct {
+	sent []notify.Message
+}
+
+func (c *recordingChannel) Name() string { return notify.ChannelEmail }
+
+func (c *recordingChannel) Send(_ context.Context, _ notify.Recipient, msg notify.Message) (*notify.SendResult, error) {
+	c.sent = append(c.sent, msg)
+	return &notify.SendResult{}, nil
+}
+
+func setupMagicLinkTest(t *testing.T) (*Service, *recordingChannel) {
+	service := setupLockoutTest(t)
+	email := &recordingChannel{}
+	service.SetMagicLinks(config.MagicLinkConfig{Enabled: true}, "https://famstack.example.com/", email)
+
+	now := time.Now()
+	_, err := service.db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, email, is_active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		"member_kid", "fam_lockout", "Kim", "Kid", "child", "kim@example.com", true, now, now)
+	require.NoError(t, err)
+	return service, email
+}
+
+func TestMagicLink_SignsInOnce(t *testing.T) {
+	service, email := setupMagicLinkTest(t)
+
+	require.NoError(t, service.RequestMagicLink(context.Background(), "Kim@Example.com"))
+	require.Len(t, email.sent, 1)
+	link, err := url.Parse(email.sent[0].URL)
+	require.NoError(t, err)
+	assert.Equal(t, "famstack.example.com", link.Host)
+	assert.Equal(t, "/auth/magic", link.Path)
+
+	response, err := service.RedeemLoginToken(LoginTokenLink, link.Query().Get("token"), "192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, "member_kid", response.User.ID)
+	assert.Equal(t, RoleUser, response.Session.Role)
+
+	_, err = service.RedeemLoginToken(LoginTokenLink, link.Query().Get("token"), "192.0.2.1")
+	assert.ErrorIs(t, err, errInvalidLoginToken, "a link works once")
+
+	// Adults sign in with their password; no email goes out and nothing says why
+	require.NoError(t, service.RequestMagicLink(context.Background(), "pat@example.com"))
+	require.NoError(t, service.RequestMagicLink(context.Background(), "nobody@example.com"))
+	assert.Len(t, email.sent, 1)
+}
+
+func TestLoginCode_SignsInOnce(t *testing.T) {
+	service, _ := setupMagicLinkTest(t)
+
+	_, err := service.CreateLoginCode("fam_other", "member_lockout", "member_kid")
+	assert.EqualError(t, err, "member not found")
+	_, err = service.CreateLoginCode("fam_lockout", "member_kid", "member_lockout")
+	assert.EqualError(t, err, "member cannot sign in with a code")
+
+	code, err := service.CreateLoginCode("fam_lockout", "member_lockout", "member_kid")
+	require.NoError(t, err)
+	assert.Regexp(t, `^[A-Z2-9]{4}-[A-Z2-9]{4}$`, code.Code)
+
+	_, err = service.RedeemLoginToken(LoginTokenLink, code.Code, "192.0.2.1")
+	assert.ErrorIs(t, err, errInvalidLoginToken, "a code is not a link token")
+
+	response, err := service.RedeemLoginToken(LoginTokenCode, " "+strings.ToLower(code.Code), "192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, "member_kid", response.User.ID)
+
+	_, err = service.RedeemLoginToken(LoginTokenCode, code.Code, "192.0.2.1")
+	assert.ErrorIs(t, err, errInvalidLoginToken, "a code works once")
+}
+
+func TestNormalizeLoginCode(t *testing.T) {
+	assert.Equal(t, "ABCD2345", normalizeLoginCode("abcd-2345"))
+	assert.Equal(t, "ABCD2345", normalizeLoginCode(" ABCD 2345 "))
+}
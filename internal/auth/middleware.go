@@ -2,11 +2,16 @@ package auth
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"famstack/internal/apierror"
+	"famstack/internal/middleware"
 	"famstack/internal/models"
 )
 
@@ -32,8 +37,22 @@ func NewMiddleware(authService *Service) *Middleware {
 	}
 }
 
-// RequireAuth middleware that requires valid authentication
+// RequireAuth middleware that requires valid authentication. Routes behind it
+// name no entity an API token's scopes could cover, so API tokens may only
+// read them.
 func (m *Middleware) RequireAuth(next http.Handler) http.Handler {
+	return m.authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !apiTokenMayWrite(GetSessionFromContext(r.Context()), r) {
+			m.writeError(w, r, "API tokens can't make changes here", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}))
+}
+
+// authenticate puts the request's session and user in its context, leaving
+// authorization to what follows
+func (m *Middleware) authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Extract token from request
 		token, err := m.extractToken(r)
@@ -64,12 +83,66 @@ func (m *Middleware) RequireAuth(next http.Handler) http.Handler {
 	})
 }
 
+// apiTokenMayWrite keeps API tokens to reading on routes that only require a
+// sign-in. RequireEntityAction checks a token's scope for the route's entity
+// instead.
+func apiTokenMayWrite(session *Session, r *http.Request) bool {
+	if session.APITokenID == "" {
+		return true
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// RequireBasicAuth is RequireAuth for clients that can only send a username
+// and password, such as CalDAV calendar apps. A token is still accepted, and
+// the handler checks its scopes per request; otherwise HTTP Basic credentials (email and password) are checked with
+// VerifyBasicAuth and failures get a WWW-Authenticate challenge for realm.
+func (m *Middleware) RequireBasicAuth(realm string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, err := m.extractToken(r); err == nil {
+				m.authenticate(next).ServeHTTP(w, r)
+				return
+			}
+
+			email, password, ok := r.BasicAuth()
+			if !ok {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q, charset=\"UTF-8\"", realm))
+				http.Error(w, "Authentication required", http.StatusUnauthorized)
+				return
+			}
+
+			session, user, err := m.authService.VerifyBasicAuth(email, password, middleware.ClientIP(r))
+			var lockoutErr *LockoutError
+			if errors.As(err, &lockoutErr) {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(time.Until(lockoutErr.Until).Seconds()))))
+				http.Error(w, lockoutErr.Error(), http.StatusTooManyRequests)
+				return
+			}
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q, charset=\"UTF-8\"", realm))
+				http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), SessionContextKey, session)
+			ctx = context.WithValue(ctx, UserContextKey, user)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 // RequireEntityAction middleware that requires specific entity/action permissions
 func (m *Middleware) RequireEntityAction(entity Entity, action Action) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// First ensure authentication
-			m.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			m.authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				session := GetSessionFromContext(r.Context())
 				if session == nil {
 					m.writeError(w, r, "Authentication required", http.StatusUnauthorized)
@@ -169,27 +242,25 @@ func (m *Middleware) extractResourceOwnerID(r *http.Request, entity Entity) *str
 		// For calendar events, extract owner ID from the event
 		return extractCalendarEventOwnerID(r)
 	case EntityFamily:
-		// For families, the user's family_id should match the family being accessed
-		// Extract family ID from URL path (e.g., /api/v1/families/{family_id})
-		path := r.URL.Path
-		if strings.Contains(path, "/families/") {
-			parts := strings.Split(path, "/families/")
-			if len(parts) > 1 {
-				familyID := strings.Split(parts[1], "/")[0]
-				return &familyID
-			}
+		// For families, the user's family_id should match the family being accessed,
+		// the {familyID} in routes like /api/v1/families/{familyID}
+		if familyID := r.PathValue("familyID"); familyID != "" {
+			return &familyID
 		}
 		return nil
 	case EntitySchedule:
-		// For schedules, we'll handle the ownership check in the handler
-		// since it requires database access. Return nil to skip middleware-level ownership check.
+		// The handlers check a schedule's creator, which takes the database,
+		// so here a member only needs to be allowed to change their own
+		if session := GetSessionFromContext(r.Context()); session != nil {
+			return &session.UserID
+		}
 		return nil
 	default:
 		return nil
 	}
 }
 
-// writeError writes a JSON error response or redirects to login for page requests
+// writeError writes an API error or redirects to login for page requests
 func (m *Middleware) writeError(w http.ResponseWriter, r *http.Request, message string, status int) {
 	// For page requests, redirect to login instead of returning JSON
 	if status == http.StatusUnauthorized && m.isPageRequest(r) {
@@ -197,18 +268,7 @@ func (m *Middleware) writeError(w http.ResponseWriter, r *http.Request, message
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-
-	response := map[string]interface{}{
-		"error":   "authentication_error",
-		"message": message,
-	}
-
-	encodeErr := json.NewEncoder(w).Encode(response)
-	if encodeErr != nil {
-		fmt.Printf("error ending response: %v\n", encodeErr)
-	}
+	apierror.Write(w, status, apierror.Body{Code: apierror.CodeForStatus(status), Message: message})
 }
 
 // isPageRequest determines if this is a page request vs API request
@@ -219,21 +279,11 @@ func (m *Middleware) isPageRequest(r *http.Request) bool {
 
 // writeUpgradeRequired writes a response indicating upgrade is needed
 func (m *Middleware) writeUpgradeRequired(w http.ResponseWriter, entity Entity, action Action) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusForbidden)
-
-	response := map[string]interface{}{
-		"error":            "upgrade_required",
-		"message":          "This action requires full access. Please enter your password.",
-		"entity":           entity,
-		"action":           action,
-		"upgrade_endpoint": "/auth/upgrade",
-	}
-
-	encodeErr := json.NewEncoder(w).Encode(response)
-	if encodeErr != nil {
-		fmt.Printf("error encoding response: %v\n", encodeErr)
-	}
+	apierror.Write(w, http.StatusForbidden, apierror.Body{
+		Code:    apierror.CodeUpgradeRequired,
+		Message: "This action requires full access. Please enter your password.",
+		Upgrade: &apierror.Upgrade{Entity: string(entity), Action: string(action), Endpoint: "/auth/upgrade"},
+	})
 }
 
 // Helper functions for extracting from context
@@ -254,6 +304,22 @@ func GetUserFromContext(ctx context.Context) *models.FamilyMember {
 	return nil
 }
 
+// CalendarViewerFromContext returns who the request's calendar listings are
+// for, see CalendarViewerFor
+func CalendarViewerFromContext(ctx context.Context) *models.CalendarViewer {
+	return CalendarViewerFor(GetSessionFromContext(ctx), GetUserFromContext(ctx))
+}
+
+// CalendarViewerFor returns who a member's calendar listings are for. A
+// shared session is on a screen the whole family sees, so it gets nil and
+// sees what a guest would.
+func CalendarViewerFor(session *Session, user *models.FamilyMember) *models.CalendarViewer {
+	if session == nil || user == nil || session.Role == RoleShared {
+		return nil
+	}
+	return &models.CalendarViewer{MemberID: user.ID, Adult: user.IsAdult()}
+}
+
 // extractTaskOwnerID extracts the owner ID for a task resource
 func extractTaskOwnerID(r *http.Request) *string {
 	// In a real implementation, this would query the database to get the task's assigned_to field
@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewareErrors_UseAPIEnvelope(t *testing.T) {
+	m := NewMiddleware(nil)
+
+	recorder := httptest.NewRecorder()
+	m.writeError(recorder, httptest.NewRequest("POST", "/api/v1/me/preferences", nil), "API tokens can't make changes here", http.StatusForbidden)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.JSONEq(t, `{"error":{"code":"forbidden","message":"API tokens can't make changes here"}}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	m.writeError(recorder, httptest.NewRequest("GET", "/calendar", nil), "Authentication required", http.StatusUnauthorized)
+	assert.Equal(t, http.StatusSeeOther, recorder.Code, "pages go to the sign-in page")
+
+	recorder = httptest.NewRecorder()
+	m.writeUpgradeRequired(recorder, EntityTask, ActionDelete)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.JSONEq(t, `{"error":{"code":"upgrade_required","message":"This action requires full access. Please enter your password.",
+		"upgrade":{"entity":"task","action":"delete","endpoint":"/auth/upgrade"}}}`, recorder.Body.String())
+}
@@ -49,6 +49,9 @@ var RolePermissions = map[Role]PermissionSet{
 		// Users - can view family members
 		MakePermission(EntityUser, ActionRead, ScopeAny): true,
 
+		// Integrations - can view; managing them takes the manage_integrations capability
+		MakePermission(EntityIntegration, ActionRead, ScopeAny): true,
+
 		// No access to settings
 	},
 
@@ -84,7 +87,128 @@ var RolePermissions = map[Role]PermissionSet{
 		// Settings - full access
 		MakePermission(EntitySetting, ActionRead, ScopeAny):   true,
 		MakePermission(EntitySetting, ActionUpdate, ScopeAny): true,
+
+		// Integrations - full access
+		MakePermission(EntityIntegration, ActionRead, ScopeAny):   true,
+		MakePermission(EntityIntegration, ActionCreate, ScopeAny): true,
+		MakePermission(EntityIntegration, ActionUpdate, ScopeAny): true,
+		MakePermission(EntityIntegration, ActionDelete, ScopeAny): true,
+	},
+}
+
+// Capability is a bundle of permissions parents can grant a member beyond
+// their role, or take away from it
+type Capability string
+
+const (
+	CapabilityCreateTasks        Capability = "create_tasks"
+	CapabilityEditCalendar       Capability = "edit_calendar"
+	CapabilityManageSchedules    Capability = "manage_schedules"
+	CapabilityManageIntegrations Capability = "manage_integrations"
+)
+
+// CapabilityInfo describes a capability for the permissions API
+type CapabilityInfo struct {
+	Name        Capability `json:"name"`
+	Description string     `json:"description"`
+	Permissions []string   `json:"permissions"`
+}
+
+// capabilityActions lists the entity actions each capability covers
+var capabilityActions = map[Capability][]struct {
+	entity Entity
+	action Action
+}{
+	CapabilityCreateTasks: {
+		{EntityTask, ActionCreate},
+	},
+	CapabilityEditCalendar: {
+		{EntityCalendar, ActionCreate},
+		{EntityCalendar, ActionUpdate},
+		{EntityCalendar, ActionDelete},
+	},
+	CapabilityManageSchedules: {
+		{EntitySchedule, ActionCreate},
+		{EntitySchedule, ActionUpdate},
+		{EntitySchedule, ActionDelete},
 	},
+	CapabilityManageIntegrations: {
+		{EntityIntegration, ActionCreate},
+		{EntityIntegration, ActionUpdate},
+		{EntityIntegration, ActionDelete},
+	},
+}
+
+var capabilityDescriptions = map[Capability]string{
+	CapabilityCreateTasks:        "Create tasks",
+	CapabilityEditCalendar:       "Add, change and remove anyone's calendar events",
+	CapabilityManageSchedules:    "Add, change and remove anyone's schedules",
+	CapabilityManageIntegrations: "Connect and manage integrations such as Google Calendar",
+}
+
+// Capabilities returns every capability, for parents to pick from
+func Capabilities() []CapabilityInfo {
+	names := []Capability{CapabilityCreateTasks, CapabilityEditCalendar, CapabilityManageSchedules, CapabilityManageIntegrations}
+	infos := make([]CapabilityInfo, 0, len(names))
+	for _, name := range names {
+		var permissions []string
+		for _, p := range capabilityActions[name] {
+			permissions = append(permissions, string(MakePermission(p.entity, p.action, ScopeAny)))
+		}
+		infos = append(infos, CapabilityInfo{Name: name, Description: capabilityDescriptions[name], Permissions: permissions})
+	}
+	return infos
+}
+
+// IsValid reports whether c is a known capability
+func (c Capability) IsValid() bool {
+	_, ok := capabilityActions[c]
+	return ok
+}
+
+// addGrant sets the permissions a capability covers in set. A grant gives
+// them for anyone's items; taking a capability away removes them for the
+// member's own items too.
+func (set PermissionSet) addGrant(capability Capability, granted bool) {
+	for _, p := range capabilityActions[capability] {
+		set[MakePermission(p.entity, p.action, ScopeAny)] = granted
+		if !granted {
+			set[MakePermission(p.entity, p.action, ScopeOwn)] = false
+		}
+	}
+}
+
+// allows reports whether role, adjusted by grants, has permission. Shared
+// sessions get their role's permissions only.
+func allows(role Role, grants PermissionSet, permission Permission) bool {
+	if role != RoleShared {
+		if granted, ok := grants[permission]; ok {
+			return granted
+		}
+	}
+	return RolePermissions[role][permission]
+}
+
+// PermissionList returns the permission strings for a role adjusted by a
+// member's grants
+func PermissionList(role Role, grants PermissionSet) []string {
+	list := GetPermissionList(role)
+	if role == RoleShared || len(grants) == 0 {
+		return list
+	}
+
+	filtered := list[:0]
+	for _, permission := range list {
+		if allows(role, grants, Permission(permission)) {
+			filtered = append(filtered, permission)
+		}
+	}
+	for permission, granted := range grants {
+		if granted && !RolePermissions[role][permission] {
+			filtered = append(filtered, string(permission))
+		}
+	}
+	return filtered
 }
 
 // GetPermissionList returns a list of permission strings for a role
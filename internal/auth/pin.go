@@ -0,0 +1,270 @@
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/ids"
+)
+
+// PIN sign-in: a parent marks a shared device, like the kitchen tablet, as
+// trusted, and on it members switch profiles with a 4-digit PIN. A PIN only
+// works together with a trusted device's cookie, and a few wrong guesses lock
+// the member's PIN out.
+
+// DeviceCookieName is the cookie holding a trusted device's token
+const DeviceCookieName = "device_token"
+
+// deviceCookieMaxAge is how long a device stays trusted without being used
+const deviceCookieMaxAge = 180 * 24 * time.Hour
+
+var errUntrustedDevice = errors.New("this device is not trusted for PIN sign-in")
+
+// TrustedDevice is a device members can sign in on with a PIN
+type TrustedDevice struct {
+	ID         string     `json:"id" db:"id"`
+	FamilyID   string     `json:"family_id" db:"family_id"`
+	Name       string     `json:"name" db:"name"`
+	CreatedBy  *string    `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+}
+
+// PINProfile is a member who can sign in with a PIN, as a trusted device's
+// profile picker shows them
+type PINProfile struct {
+	MemberID  string  `json:"member_id" db:"id"`
+	FirstName string  `json:"first_name" db:"first_name"`
+	AvatarURL *string `json:"avatar_url,omitempty" db:"avatar_url"`
+}
+
+// TrustDevice marks the device a parent is signed in on as trusted. The
+// returned token goes in the device's cookie and is not kept.
+func (s *Service) TrustDevice(familyID, createdBy, name string) (*TrustedDevice, string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	device := &TrustedDevice{
+		ID:        ids.New("device"),
+		FamilyID:  familyID,
+		Name:      name,
+		CreatedBy: &createdBy,
+		CreatedAt: time.Now().UTC(),
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO trusted_devices (id, family_id, name, token_hash, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, device.ID, device.FamilyID, device.Name, hashLoginToken(token), device.CreatedBy, device.CreatedAt)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to trust device: %w", err)
+	}
+	return device, token, nil
+}
+
+// ListTrustedDevices returns a family's trusted devices, most recently used
+// first
+func (s *Service) ListTrustedDevices(familyID string) ([]TrustedDevice, error) {
+	devices, err := database.QueryAll[TrustedDevice](s.db, `
+		SELECT id, family_id, name, created_by, created_at, last_used_at
+		FROM trusted_devices
+		WHERE family_id = ?
+		ORDER BY COALESCE(last_used_at, created_at) DESC
+	`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trusted devices: %w", err)
+	}
+	return devices, nil
+}
+
+// RevokeTrustedDevice stops a device being trusted
+func (s *Service) RevokeTrustedDevice(familyID, deviceID string) error {
+	result, err := s.db.Exec(`DELETE FROM trusted_devices WHERE id = ? AND family_id = ?`, deviceID, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke device: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("device not found")
+	}
+	return nil
+}
+
+// SetPIN sets or replaces a member's PIN and lifts any lockout on it
+func (s *Service) SetPIN(familyID, updatedBy, memberID, pin string) error {
+	member, err := s.getFamilyMemberByID(memberID)
+	if err != nil || member.FamilyID != familyID {
+		return fmt.Errorf("member not found")
+	}
+
+	pinHash, err := HashPassword(pin)
+	if err != nil {
+		return fmt.Errorf("failed to hash PIN: %w", err)
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO member_pins (member_id, pin_hash, updated_by, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (member_id) DO UPDATE SET
+			pin_hash = excluded.pin_hash,
+			updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at
+	`, memberID, pinHash, updatedBy, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to set PIN: %w", err)
+	}
+
+	if _, err := s.db.Exec(`DELETE FROM login_lockouts WHERE kind = ? AND subject = ?`, LockoutPIN, memberID); err != nil {
+		return fmt.Errorf("failed to reset lockout: %w", err)
+	}
+	return nil
+}
+
+// ClearPIN removes a member's PIN, so they can no longer sign in with one
+func (s *Service) ClearPIN(familyID, memberID string) error {
+	result, err := s.db.Exec(`
+		DELETE FROM member_pins
+		WHERE member_id = ? AND member_id IN (SELECT id FROM family_members WHERE family_id = ?)
+	`, memberID, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to clear PIN: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("PIN not found")
+	}
+	return nil
+}
+
+// ListPINProfiles returns the members who can sign in with a PIN on the
+// device holding deviceToken
+func (s *Service) ListPINProfiles(deviceToken string) ([]PINProfile, error) {
+	device, err := s.trustedDevice(deviceToken)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := database.QueryAll[PINProfile](s.db, `
+		SELECT m.id, m.first_name, m.avatar_url
+		FROM family_members m
+		JOIN member_pins p ON p.member_id = m.id
+		WHERE m.family_id = ? AND m.is_active = TRUE
+		ORDER BY m.display_order, m.first_name
+	`, device.FamilyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PIN profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+// PINLogin signs a member in with their PIN on a trusted device. Admins get
+// a shared session, which their password upgrades as usual, so a PIN never
+// grants admin rights on its own.
+func (s *Service) PINLogin(deviceToken, memberID, pin, ip string) (*AuthResponse, error) {
+	device, err := s.trustedDevice(deviceToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only members of the device's family can be picked on it
+	member, err := s.getFamilyMemberByID(memberID)
+	if err != nil || member.FamilyID != device.FamilyID || !member.IsActive {
+		return nil, errInvalidCredentials
+	}
+
+	now := time.Now().UTC()
+	if err := s.checkLockout(now, lockoutKey{LockoutPIN, member.ID}, lockoutKey{LockoutIP, ip}); err != nil {
+		var lockoutErr *LockoutError
+		if errors.As(err, &lockoutErr) {
+			s.auditLogin("", ip, &member.ID, LoginLockedOut, now)
+		}
+		return nil, err
+	}
+
+	err = s.checkPIN(member.ID, pin)
+	if errors.Is(err, errInvalidCredentials) {
+		s.auditLogin("", ip, &member.ID, LoginInvalidCredentials, now)
+		if failErr := s.countFailure(LockoutPIN, member.ID, maxPINFailures, now); failErr != nil {
+			fmt.Printf("Failed to record PIN failure for %s: %v\n", member.ID, failErr)
+		}
+		if ip != "" {
+			if failErr := s.countFailure(LockoutIP, ip, maxIPFailures, now); failErr != nil {
+				fmt.Printf("Failed to record login failure for %s: %v\n", ip, failErr)
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditLogin("", ip, &member.ID, LoginSucceeded, now)
+	if _, err := s.db.Exec(`DELETE FROM login_lockouts WHERE kind = ? AND subject = ?`, LockoutPIN, member.ID); err != nil {
+		fmt.Printf("Failed to reset PIN lockout for %s: %v\n", member.ID, err)
+	}
+	if updateErr := s.updateLastLogin(member.ID); updateErr != nil {
+		fmt.Printf("Failed to update last login for user %s: %v\n", member.ID, updateErr)
+	}
+
+	role := RoleUser
+	if member.Role != nil {
+		role = Role(*member.Role)
+	}
+	response, err := s.newAuthResponse(member, role)
+	if err != nil || role != RoleAdmin {
+		return response, err
+	}
+
+	shared, err := s.DowngradeToShared(response.Token)
+	if err != nil {
+		return nil, err
+	}
+	response.Token = shared.Token
+	response.Session = shared.Session
+	response.Permissions = shared.Permissions
+	return response, nil
+}
+
+// checkPIN checks a member's PIN
+func (s *Service) checkPIN(memberID, pin string) error {
+	var pinHash string
+	err := s.db.QueryRow(`SELECT pin_hash FROM member_pins WHERE member_id = ?`, memberID).Scan(&pinHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errInvalidCredentials
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get PIN: %w", err)
+	}
+
+	valid, err := VerifyPassword(pin, pinHash)
+	if err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+	if !valid {
+		return errInvalidCredentials
+	}
+	return nil
+}
+
+// trustedDevice returns the device holding token, noting that it was used
+func (s *Service) trustedDevice(token string) (*TrustedDevice, error) {
+	if token == "" {
+		return nil, errUntrustedDevice
+	}
+	device, err := database.QueryOne[TrustedDevice](s.db, `
+		SELECT id, family_id, name, created_by, created_at, last_used_at
+		FROM trusted_devices
+		WHERE token_hash = ?
+	`, hashLoginToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errUntrustedDevice
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trusted device: %w", err)
+	}
+
+	if _, err := s.db.Exec(`UPDATE trusted_devices SET last_used_at = ? WHERE id = ?`, time.Now().UTC(), device.ID); err != nil {
+		fmt.Printf("Failed to note use of device %s: %v\n", device.ID, err)
+	}
+	return device, nil
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupPINTest(t *testing.T) (*Service, string) {
+	service := setupLockoutTest(t)
+
+	now := time.Now()
+	_, err := service.db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, is_active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"member_kid", "fam_lockout", "Kim", "Kid", "child", true, now, now)
+	require.NoError(t, err)
+
+	_, token, err := service.TrustDevice("fam_lockout", "member_lockout", "Kitchen tablet")
+	require.NoError(t, err)
+	return service, token
+}
+
+func TestPINLogin(t *testing.T) {
+	service, device := setupPINTest(t)
+	require.NoError(t, service.SetPIN("fam_lockout", "member_lockout", "member_kid", "1234"))
+	require.NoError(t, service.SetPIN("fam_lockout", "member_lockout", "member_lockout", "9876"))
+
+	profiles, err := service.ListPINProfiles(device)
+	require.NoError(t, err)
+	assert.Len(t, profiles, 2)
+
+	response, err := service.PINLogin(device, "member_kid", "1234", "192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, RoleUser, response.Session.Role)
+
+	// An admin's PIN only gets a shared session
+	response, err = service.PINLogin(device, "member_lockout", "9876", "192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, RoleShared, response.Session.Role)
+	assert.Equal(t, RoleAdmin, response.Session.OriginalRole)
+
+	_, err = service.PINLogin("not-a-device", "member_kid", "1234", "192.0.2.1")
+	assert.ErrorIs(t, err, errUntrustedDevice)
+}
+
+func TestPINLogin_LocksOutAfterRepeatedFailures(t *testing.T) {
+	service, device := setupPINTest(t)
+	require.NoError(t, service.SetPIN("fam_lockout", "member_lockout", "member_kid", "1234"))
+
+	for range maxPINFailures {
+		_, err := service.PINLogin(device, "member_kid", "0000", "192.0.2.1")
+		require.ErrorIs(t, err, errInvalidCredentials)
+	}
+	_, err := service.PINLogin(device, "member_kid", "1234", "192.0.2.1")
+	var lockoutErr *LockoutError
+	require.True(t, errors.As(err, &lockoutErr))
+
+	// A parent resetting the PIN lifts the lockout
+	require.NoError(t, service.SetPIN("fam_lockout", "member_lockout", "member_kid", "4321"))
+	_, err = service.PINLogin(device, "member_kid", "4321", "192.0.2.1")
+	require.NoError(t, err)
+
+	require.NoError(t, service.ClearPIN("fam_lockout", "member_kid"))
+	_, err = service.PINLogin(device, "member_kid", "4321", "192.0.2.1")
+	assert.ErrorIs(t, err, errInvalidCredentials)
+}
+
+func TestIsPIN(t *testing.T) {
+	assert.True(t, isPIN("0420"))
+	assert.False(t, isPIN("123"))
+	assert.False(t, isPIN("12a4"))
+	assert.False(t, isPIN("١٢٣٤"), "only ASCII digits")
+}
@@ -2,7 +2,9 @@ package auth
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,6 +21,9 @@ type Service struct {
 	// Rate limiting for password attempts
 	upgradeAttempts map[string][]time.Time
 	upgradeMutex    sync.RWMutex
+
+	magic magicLinks
+	basic basicAuth
 }
 
 // NewService creates a new authentication service using encryption service for JWT signing
@@ -37,28 +42,36 @@ func NewService(db *database.Fascade, encryptionService *encryption.Service, iss
 	}
 }
 
-// Login authenticates a user with email and password
-func (s *Service) Login(email, password string) (*AuthResponse, error) {
-	// Get user by email
-	user, err := s.getFamilyMemberByEmail(email)
-	if err != nil {
-		return nil, fmt.Errorf("invalid credentials")
-	}
+// Login authenticates a user with email and password. ip is the client's
+// address: failed attempts are counted against it and the email, and while
+// either is locked out Login returns a *LockoutError.
+func (s *Service) Login(email, password, ip string) (*AuthResponse, error) {
+	now := time.Now().UTC()
+	subject := normalizeEmail(email)
 
-	// Check if user has auth info
-	if user.PasswordHash == nil || user.Role == nil {
-		return nil, fmt.Errorf("invalid credentials")
+	if err := s.checkLockout(now, lockoutKey{LockoutEmail, subject}, lockoutKey{LockoutIP, ip}); err != nil {
+		var lockoutErr *LockoutError
+		if errors.As(err, &lockoutErr) {
+			s.auditLogin(subject, ip, nil, LoginLockedOut, now)
+		}
+		return nil, err
 	}
 
-	// Verify password
-	valid, err := VerifyPassword(password, *user.PasswordHash)
-	if err != nil {
-		return nil, fmt.Errorf("authentication failed: %w", err)
+	user, err := s.authenticate(email, password)
+	if errors.Is(err, errInvalidCredentials) {
+		var memberID *string
+		if user != nil {
+			memberID = &user.ID
+		}
+		s.auditLogin(subject, ip, memberID, LoginInvalidCredentials, now)
+		if failErr := s.recordLoginFailure(subject, ip, now); failErr != nil {
+			fmt.Printf("Failed to record login failure for %s: %v\n", subject, failErr)
+		}
 	}
-
-	if !valid {
-		return nil, fmt.Errorf("invalid credentials")
+	if err != nil {
+		return nil, err
 	}
+	s.auditLogin(subject, ip, &user.ID, LoginSucceeded, now)
 
 	// Update last login time
 	if updateErr := s.updateLastLogin(user.ID); updateErr != nil {
@@ -66,29 +79,67 @@ func (s *Service) Login(email, password string) (*AuthResponse, error) {
 		fmt.Printf("Failed to update last login for user %s: %v\n", user.ID, updateErr)
 	}
 
-	// Create JWT token (4 hours expiration for full sessions)
+	return s.newAuthResponse(user, Role(*user.Role))
+}
 
-	token, err := s.jwtManager.CreateToken(user.ID, user.FamilyID, Role(*user.Role), 4*time.Hour)
+// newAuthResponse starts a full session for a member who has signed in
+func (s *Service) newAuthResponse(user *models.FamilyMember, role Role) (*AuthResponse, error) {
+	// Create JWT token (4 hours expiration for full sessions)
+	token, err := s.jwtManager.CreateToken(user.ID, user.FamilyID, role, 4*time.Hour)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create token: %w", err)
 	}
 
 	// Create session from token
-
 	claims, err := s.jwtManager.ValidateToken(token)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse created token: %w", err)
 	}
-	session := SessionFromJWTClaims(claims)
+	session, err := s.sessionFromClaims(claims)
+	if err != nil {
+		return nil, err
+	}
 
 	return &AuthResponse{
 		User:        user,
 		Session:     session,
 		Token:       token,
-		Permissions: GetPermissionList(Role(*user.Role)),
+		Permissions: PermissionList(role, session.Grants),
 	}, nil
 }
 
+// authenticate checks a password, returning the member it belongs to. A wrong
+// password still returns the member, for the audit log.
+func (s *Service) authenticate(email, password string) (*models.FamilyMember, error) {
+	user, err := s.getFamilyMemberByEmail(email)
+	if err != nil {
+		return nil, errInvalidCredentials
+	}
+
+	// Check if user has auth info and hasn't been deactivated
+	if user.PasswordHash == nil || user.Role == nil || !user.IsActive {
+		return user, errInvalidCredentials
+	}
+
+	// Verify password
+	valid, err := VerifyPassword(password, *user.PasswordHash)
+	if err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+	if !valid {
+		return user, errInvalidCredentials
+	}
+	return user, nil
+}
+
+// auditLogin records a sign-in attempt. A failure to record it is logged
+// rather than failing the sign-in.
+func (s *Service) auditLogin(email, ip string, memberID *string, outcome string, now time.Time) {
+	if err := s.recordLoginAttempt(email, ip, memberID, outcome, now); err != nil {
+		fmt.Printf("Failed to audit login for %s: %v\n", email, err)
+	}
+}
+
 // DowngradeToShared downgrades a user session to shared mode
 func (s *Service) DowngradeToShared(token string) (*TokenResponse, error) {
 	// Validate current token
@@ -165,23 +216,57 @@ func (s *Service) UpgradeWithPassword(token, password string) (*TokenResponse, e
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse upgraded token: %w", err)
 	}
-	session := SessionFromJWTClaims(originalClaims)
+	session, err := s.sessionFromClaims(originalClaims)
+	if err != nil {
+		return nil, err
+	}
 
 	return &TokenResponse{
 		Token:       originalToken,
 		Session:     session,
-		Permissions: GetPermissionList(session.Role),
+		Permissions: PermissionList(session.Role, session.Grants),
 	}, nil
 }
 
 // ValidateToken validates a JWT token and returns session info
 func (s *Service) ValidateToken(token string) (*Session, error) {
+	if strings.HasPrefix(token, APITokenPrefix) {
+		return s.validateAPIToken(token)
+	}
+
 	claims, err := s.jwtManager.ValidateToken(token)
 	if err != nil {
 		return nil, err
 	}
 
-	return SessionFromJWTClaims(claims), nil
+	return s.sessionFromClaims(claims)
+}
+
+// IssuedToken reports whether token is a session or API token this server
+// issued and hasn't revoked. It is cheaper than ValidateToken, which also
+// loads the session, for callers like the rate limiter that only need to
+// know a token isn't made up.
+func (s *Service) IssuedToken(token string) bool {
+	if strings.HasPrefix(token, APITokenPrefix) {
+		var exists int
+		err := s.db.QueryRow(`SELECT COUNT(*) FROM api_tokens WHERE token_hash = ? AND revoked_at IS NULL`,
+			hashLoginToken(token)).Scan(&exists)
+		return err == nil && exists > 0
+	}
+	_, err := s.jwtManager.ValidateToken(token)
+	return err == nil
+}
+
+// sessionFromClaims is SessionFromJWTClaims with the member's grants, which
+// can change while a token is valid
+func (s *Service) sessionFromClaims(claims *JWTClaims) (*Session, error) {
+	session := SessionFromJWTClaims(claims)
+	grants, err := s.grantsFor(session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	session.Grants = grants
+	return session, nil
 }
 
 // RefreshToken creates a new token with extended expiration
@@ -191,6 +276,12 @@ func (s *Service) RefreshToken(token string) (*TokenResponse, error) {
 		return nil, fmt.Errorf("invalid token: %w", err)
 	}
 
+	// A deactivated member's session ends when its token expires
+	member, err := s.getFamilyMemberByID(claims.UserID)
+	if err != nil || !member.IsActive {
+		return nil, fmt.Errorf("member is no longer active")
+	}
+
 	// Create new token with 4 hours expiration
 	newToken, err := s.jwtManager.RefreshToken(claims, 4*time.Hour)
 	if err != nil {
@@ -202,17 +293,28 @@ func (s *Service) RefreshToken(token string) (*TokenResponse, error) {
 	if tokenErr != nil {
 		return nil, fmt.Errorf("failed to validate token: %v", tokenErr)
 	}
-	session := SessionFromJWTClaims(newClaims)
+	session, err := s.sessionFromClaims(newClaims)
+	if err != nil {
+		return nil, err
+	}
 
 	return &TokenResponse{
 		Token:       newToken,
 		Session:     session,
-		Permissions: GetPermissionList(session.Role),
+		Permissions: PermissionList(session.Role, session.Grants),
 	}, nil
 }
 
 // GetFamilyMemberByToken gets user info from a valid token
 func (s *Service) GetFamilyMemberByToken(token string) (*models.FamilyMember, error) {
+	if strings.HasPrefix(token, APITokenPrefix) {
+		session, err := s.validateAPIToken(token)
+		if err != nil {
+			return nil, err
+		}
+		return s.getFamilyMemberByID(session.UserID)
+	}
+
 	claims, err := s.jwtManager.ValidateToken(token)
 	if err != nil {
 		return nil, err
@@ -24,6 +24,9 @@ const (
 	EntityCalendar Entity = "calendar"
 	EntitySchedule Entity = "schedule"
 	EntitySetting  Entity = "setting"
+	// EntityIntegration is an external service connected to the family,
+	// such as a Google calendar
+	EntityIntegration Entity = "integration"
 )
 
 // Action represents operations that can be performed
@@ -56,6 +59,14 @@ type Session struct {
 	OriginalRole Role      `json:"original_role"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	IssuedAt     time.Time `json:"issued_at"`
+
+	// Grants are the member's capability grants on top of their role, see
+	// SetGrant
+	Grants PermissionSet `json:"-"`
+	// Scopes cap what a session from an API token can do; nil for sign-ins
+	Scopes []string `json:"-"`
+	// APITokenID is set when the session comes from an API token
+	APITokenID string `json:"-"`
 }
 
 // IsExpired checks if the session has expired
@@ -91,6 +102,62 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required,min=8"`
 }
 
+// MagicLinkRequest asks for a sign-in link by email
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// LoginCodeRequest signs in with a one-time code
+type LoginCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// CreateLoginCodeRequest asks for a one-time code for a member
+type CreateLoginCodeRequest struct {
+	MemberID string `json:"member_id" validate:"required"`
+}
+
+// TrustDeviceRequest marks the caller's device as trusted for PIN sign-in
+type TrustDeviceRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// PINRequest sets a member's PIN, or signs them in with it
+type PINRequest struct {
+	MemberID string `json:"member_id" validate:"required"`
+	PIN      string `json:"pin" validate:"required,len=4"`
+}
+
+// CreateInvitationRequest invites someone to take over a member. With an
+// email, the link is sent there; either way a code comes back to hand over.
+type CreateInvitationRequest struct {
+	MemberID      string `json:"member_id" validate:"required"`
+	Role          Role   `json:"role" validate:"required"`
+	Email         string `json:"email,omitempty"`
+	ExpiresInDays int    `json:"expires_in_days,omitempty"`
+}
+
+// AcceptInvitationRequest sets the credentials an invitation was accepted
+// with. The email is fixed for an emailed invitation.
+type AcceptInvitationRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// CreateGuestPassRequest gives a guest a link to today's schedule and the
+// picked tasks for a number of hours
+type CreateGuestPassRequest struct {
+	Name           string   `json:"name" validate:"required,max=100"`
+	Access         string   `json:"access" validate:"required,oneof=read tasks"`
+	TaskIDs        []string `json:"task_ids,omitempty"`
+	ExpiresInHours int      `json:"expires_in_hours" validate:"required,min=1,max=720"`
+}
+
+// ExtendGuestPassRequest adds hours to a guest pass
+type ExtendGuestPassRequest struct {
+	Hours int `json:"hours" validate:"required,min=1,max=720"`
+}
+
 // PasswordUpgradeRequest represents a password challenge for upgrading permissions
 type PasswordUpgradeRequest struct {
 	Password string `json:"password" validate:"required"`
@@ -1,26 +1,114 @@
 package calendar
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"strconv"
 	"time"
 
+	"golang.org/x/oauth2"
 	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 
 	"famstack/internal/oauth"
+	"famstack/internal/tracing"
+)
+
+// Errors callers can act on; Google's own error is wrapped alongside them
+var (
+	// ErrUnauthorized means the user's token was rejected or could not be
+	// refreshed, so they need to reconnect their account
+	ErrUnauthorized = errors.New("google calendar authorization expired or revoked")
+	// ErrRateLimited means Google still refused the request after retrying
+	ErrRateLimited = errors.New("google calendar rate limit exceeded")
+	// ErrSyncTokenExpired means Google no longer accepts a sync token, so the
+	// calendar needs a full sync
+	ErrSyncTokenExpired = errors.New("google calendar sync token expired")
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultBackoff    = time.Second
+	maxRetryWait      = time.Minute
 )
 
 // GoogleClient handles Google Calendar API interactions
 type GoogleClient struct {
 	oauthService *oauth.Service
+
+	// tokenSource returns the user's token source; refreshes made through it
+	// use the HTTP client in ctx. Tests replace it.
+	tokenSource func(ctx context.Context, userID string) (oauth2.TokenSource, error)
+	transport   http.RoundTripper // nil uses http.DefaultTransport
+	maxRetries  int
+	backoff     time.Duration
+	sleep       func(ctx context.Context, d time.Duration) error
 }
 
 // NewGoogleClient creates a new Google Calendar client
 func NewGoogleClient(oauthService *oauth.Service) *GoogleClient {
-	return &GoogleClient{
+	c := &GoogleClient{
 		oauthService: oauthService,
+		maxRetries:   defaultMaxRetries,
+		backoff:      defaultBackoff,
+		sleep:        sleep,
 	}
+	c.tokenSource = c.storedTokenSource
+	return c
+}
+
+// NewGoogleClientWithTransport creates a client that gets tokens from
+// tokenSource instead of stored OAuth tokens and sends every request, token
+// refreshes included, through transport. Tests use it to replay recorded
+// responses.
+func NewGoogleClientWithTransport(tokenSource func(ctx context.Context, userID string) (oauth2.TokenSource, error), transport http.RoundTripper) *GoogleClient {
+	return &GoogleClient{
+		tokenSource: tokenSource,
+		transport:   transport,
+		maxRetries:  defaultMaxRetries,
+		backoff:     defaultBackoff,
+		sleep:       sleep,
+	}
+}
+
+// storedTokenSource refreshes the user's stored token as needed
+func (c *GoogleClient) storedTokenSource(ctx context.Context, userID string) (oauth2.TokenSource, error) {
+	token, err := c.oauthService.GetToken(userID, oauth.ProviderGoogle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OAuth token: %w", err)
+	}
+	return c.oauthService.GetOAuth2Config().TokenSource(ctx, c.oauthService.GetOAuth2Token(token)), nil
+}
+
+// service creates a Calendar service for the user. Token refreshes and API
+// calls share the client's transport, are traced as children of any span in
+// ctx, and API calls are retried when Google rate limits them.
+func (c *GoogleClient) service(ctx context.Context, userID string) (*calendar.Service, error) {
+	base := c.transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	base = tracing.Transport(base)
+
+	tokenSource, err := c.tokenSource(context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: base}), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := &http.Client{Transport: &oauth2.Transport{
+		Source: tokenSource,
+		Base:   &retryTransport{base: base, maxRetries: c.maxRetries, backoff: c.backoff, sleep: c.sleep},
+	}}
+	calendarService, err := calendar.NewService(ctx, option.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create calendar service: %w", err)
+	}
+	return calendarService, nil
 }
 
 // GoogleEvent represents a Google Calendar event
@@ -31,6 +119,7 @@ type GoogleEvent struct {
 	Start       GoogleDateTime   `json:"start"`
 	End         GoogleDateTime   `json:"end"`
 	Attendees   []GoogleAttendee `json:"attendees,omitempty"`
+	Organizer   *GoogleAttendee  `json:"organizer,omitempty"`
 	Location    string           `json:"location,omitempty"`
 	Status      string           `json:"status"`
 	Created     time.Time        `json:"created"`
@@ -56,137 +145,151 @@ type GoogleAttendee struct {
 }
 
 // GetEvents fetches events from Google Calendar
-func (c *GoogleClient) GetEvents(userID string, calendarID string, timeMin, timeMax time.Time) ([]GoogleEvent, error) {
-	// Get OAuth token for user
-	token, err := c.oauthService.GetToken(userID, oauth.ProviderGoogle)
+func (c *GoogleClient) GetEvents(ctx context.Context, userID string, calendarID string, timeMin, timeMax time.Time) ([]GoogleEvent, error) {
+	changes, err := c.GetEventChanges(ctx, userID, calendarID, timeMin, timeMax, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get OAuth token: %w", err)
+		return nil, err
 	}
+	return changes.Events, nil
+}
 
-	// Create OAuth2 token source with auto-refresh
-	oauth2Config := c.oauthService.GetOAuth2Config()
-	oauth2Token := c.oauthService.GetOAuth2Token(token)
-	tokenSource := oauth2Config.TokenSource(context.Background(), oauth2Token)
+// EventChanges are a calendar's events, or the changes to them, with the
+// token that lists the changes made after them
+type EventChanges struct {
+	Events []GoogleEvent
+	// Full is set when Events are every event in the range rather than the
+	// changes since a sync token
+	Full          bool
+	NextSyncToken string
+}
 
-	// Create Calendar service
-	ctx := context.Background()
-	calendarService, err := calendar.NewService(ctx, option.WithTokenSource(tokenSource))
+// GetEventChanges lists the events changed since syncToken was issued,
+// cancelled ones included. Without a syncToken it lists every event from
+// timeMin to timeMax, a full sync. Either way the result carries the token
+// for the next call. ErrSyncTokenExpired means Google no longer accepts
+// syncToken, and a full sync is needed.
+func (c *GoogleClient) GetEventChanges(ctx context.Context, userID, calendarID string, timeMin, timeMax time.Time, syncToken string) (*EventChanges, error) {
+	calendarService, err := c.service(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create calendar service: %w", err)
+		return nil, err
 	}
 
-	// Build events list call
+	// An incremental list repeats the full list's parameters, less the range
+	// and order, which Google doesn't take with a sync token
 	eventsCall := calendarService.Events.List(calendarID).
-		TimeMin(timeMin.Format(time.RFC3339)).
-		TimeMax(timeMax.Format(time.RFC3339)).
 		SingleEvents(true).
-		OrderBy("startTime").
 		MaxResults(2500).
 		ShowDeleted(false).
 		ShowHiddenInvitations(false)
+	if syncToken != "" {
+		eventsCall = eventsCall.SyncToken(syncToken)
+	} else {
+		eventsCall = eventsCall.
+			TimeMin(timeMin.Format(time.RFC3339)).
+			TimeMax(timeMax.Format(time.RFC3339)).
+			OrderBy("startTime")
+	}
 
-	// Execute the request
-	events, err := eventsCall.Do()
+	// Collect every page; only the last carries the next token
+	changes := &EventChanges{Events: []GoogleEvent{}, Full: syncToken == ""}
+	err = eventsCall.Pages(ctx, func(page *calendar.Events) error {
+		for _, item := range page.Items {
+			changes.Events = append(changes.Events, toGoogleEvent(item))
+		}
+		if page.NextSyncToken != "" {
+			changes.NextSyncToken = page.NextSyncToken
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve events: %w", err)
+		return nil, fmt.Errorf("failed to retrieve events: %w", classifyError(err))
 	}
+	return changes, nil
+}
 
-	// Convert to our custom format
-	var googleEvents []GoogleEvent
-	for _, item := range events.Items {
-		googleEvent := GoogleEvent{
-			ID:               item.Id,
-			Summary:          item.Summary,
-			Description:      item.Description,
-			Location:         item.Location,
-			Status:           item.Status,
-			Recurrence:       item.Recurrence,
-			RecurringEventId: item.RecurringEventId,
-		}
+// toGoogleEvent converts an event from the API to our custom format
+func toGoogleEvent(item *calendar.Event) GoogleEvent {
+	googleEvent := GoogleEvent{
+		ID:               item.Id,
+		Summary:          item.Summary,
+		Description:      item.Description,
+		Location:         item.Location,
+		Status:           item.Status,
+		Recurrence:       item.Recurrence,
+		RecurringEventId: item.RecurringEventId,
+	}
 
-		// Convert original start time for recurring event instances
-		if item.OriginalStartTime != nil {
-			googleEvent.OriginalStartTime = &GoogleDateTime{
-				DateTime: item.OriginalStartTime.DateTime,
-				Date:     item.OriginalStartTime.Date,
-				TimeZone: item.OriginalStartTime.TimeZone,
-			}
+	// Convert original start time for recurring event instances
+	if item.OriginalStartTime != nil {
+		googleEvent.OriginalStartTime = &GoogleDateTime{
+			DateTime: item.OriginalStartTime.DateTime,
+			Date:     item.OriginalStartTime.Date,
+			TimeZone: item.OriginalStartTime.TimeZone,
 		}
+	}
 
-		// Convert start time
-		if item.Start != nil {
-			googleEvent.Start = GoogleDateTime{
-				DateTime: item.Start.DateTime,
-				Date:     item.Start.Date,
-				TimeZone: item.Start.TimeZone,
-			}
+	// Convert start time
+	if item.Start != nil {
+		googleEvent.Start = GoogleDateTime{
+			DateTime: item.Start.DateTime,
+			Date:     item.Start.Date,
+			TimeZone: item.Start.TimeZone,
 		}
+	}
 
-		// Convert end time
-		if item.End != nil {
-			googleEvent.End = GoogleDateTime{
-				DateTime: item.End.DateTime,
-				Date:     item.End.Date,
-				TimeZone: item.End.TimeZone,
-			}
+	// Convert end time
+	if item.End != nil {
+		googleEvent.End = GoogleDateTime{
+			DateTime: item.End.DateTime,
+			Date:     item.End.Date,
+			TimeZone: item.End.TimeZone,
 		}
+	}
 
-		// Convert attendees
-		for _, attendee := range item.Attendees {
-			googleEvent.Attendees = append(googleEvent.Attendees, GoogleAttendee{
-				Email:          attendee.Email,
-				DisplayName:    attendee.DisplayName,
-				ResponseStatus: attendee.ResponseStatus,
-			})
-		}
+	// Convert attendees
+	for _, attendee := range item.Attendees {
+		googleEvent.Attendees = append(googleEvent.Attendees, GoogleAttendee{
+			Email:          attendee.Email,
+			DisplayName:    attendee.DisplayName,
+			ResponseStatus: attendee.ResponseStatus,
+		})
+	}
 
-		// Parse timestamps
-		if item.Created != "" {
-			if created, err := time.Parse(time.RFC3339, item.Created); err == nil {
-				googleEvent.Created = created
-			}
+	// Parse timestamps
+	if item.Created != "" {
+		if created, err := time.Parse(time.RFC3339, item.Created); err == nil {
+			googleEvent.Created = created
 		}
-		if item.Updated != "" {
-			if updated, err := time.Parse(time.RFC3339, item.Updated); err == nil {
-				googleEvent.Updated = updated
-			}
+	}
+	if item.Updated != "" {
+		if updated, err := time.Parse(time.RFC3339, item.Updated); err == nil {
+			googleEvent.Updated = updated
 		}
-
-		googleEvents = append(googleEvents, googleEvent)
 	}
 
-	return googleEvents, nil
+	return googleEvent
 }
 
 // GetCalendars fetches list of calendars for the user
-func (c *GoogleClient) GetCalendars(userID string) ([]GoogleCalendar, error) {
-	// Get OAuth token for user
-	token, err := c.oauthService.GetToken(userID, oauth.ProviderGoogle)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get OAuth token: %w", err)
-	}
-
-	// Create OAuth2 token source with auto-refresh
-	oauth2Config := c.oauthService.GetOAuth2Config()
-	oauth2Token := c.oauthService.GetOAuth2Token(token)
-	tokenSource := oauth2Config.TokenSource(context.Background(), oauth2Token)
-
-	// Create Calendar service
-	ctx := context.Background()
-	calendarService, err := calendar.NewService(ctx, option.WithTokenSource(tokenSource))
+func (c *GoogleClient) GetCalendars(ctx context.Context, userID string) ([]GoogleCalendar, error) {
+	calendarService, err := c.service(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create calendar service: %w", err)
+		return nil, err
 	}
 
-	// Get calendar list
-	calendarList, err := calendarService.CalendarList.List().Do()
+	// Get calendar list, every page
+	var items []*calendar.CalendarListEntry
+	err = calendarService.CalendarList.List().Pages(ctx, func(page *calendar.CalendarList) error {
+		items = append(items, page.Items...)
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve calendar list: %w", err)
+		return nil, fmt.Errorf("failed to retrieve calendar list: %w", classifyError(err))
 	}
 
 	// Convert to our custom format
 	var googleCalendars []GoogleCalendar
-	for _, item := range calendarList.Items {
+	for _, item := range items {
 		googleCalendars = append(googleCalendars, GoogleCalendar{
 			ID:          item.Id,
 			Summary:     item.Summary,
@@ -209,3 +312,108 @@ type GoogleCalendar struct {
 	AccessRole  string `json:"accessRole"`
 	Selected    bool   `json:"selected,omitempty"`
 }
+
+// classifyError wraps Google's error with ErrUnauthorized or ErrRateLimited
+// when it is one of those
+func classifyError(err error) error {
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		// The refresh token was revoked or has expired
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	}
+
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	switch {
+	case apiErr.Code == http.StatusUnauthorized:
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	case apiErr.Code == http.StatusGone:
+		return fmt.Errorf("%w: %w", ErrSyncTokenExpired, err)
+	case apiErr.Code == http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %w", ErrRateLimited, err)
+	case apiErr.Code == http.StatusForbidden:
+		for _, item := range apiErr.Errors {
+			if isRateLimitReason(item.Reason) {
+				return fmt.Errorf("%w: %w", ErrRateLimited, err)
+			}
+		}
+	}
+	return err
+}
+
+func isRateLimitReason(reason string) bool {
+	return reason == "rateLimitExceeded" || reason == "userRateLimitExceeded"
+}
+
+// retryTransport retries requests Google rejects for rate limiting. It waits
+// as long as Retry-After asks, or backs off exponentially.
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	backoff    time.Duration
+	sleep      func(ctx context.Context, d time.Duration) error
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || attempt >= t.maxRetries || !rateLimited(resp) {
+			return resp, err
+		}
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil // the body can't be sent again
+		}
+
+		wait := retryAfter(resp, t.backoff<<attempt)
+		resp.Body.Close()
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// rateLimited reports whether a response is a rate limit. Google answers some
+// of them with 403, telling them apart from permission errors by the reason.
+func rateLimited(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return err == nil && (bytes.Contains(body, []byte(`"rateLimitExceeded"`)) || bytes.Contains(body, []byte(`"userRateLimitExceeded"`)))
+	}
+	return false
+}
+
+// retryAfter reads a Retry-After given in seconds, capped at maxRetryWait
+func retryAfter(resp *http.Response, fallback time.Duration) time.Duration {
+	wait := fallback
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		wait = time.Duration(seconds) * time.Second
+	}
+	return min(wait, maxRetryWait)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,252 @@
+// Package chaos injects latency, server errors and database busy errors into
+// HTTP requests and background jobs so the SPA's and the job system's retry
+// and fallback paths can be exercised against realistic failures.
+//
+// It is meant for development only: the server installs the middleware only
+// in dev mode. Faults come from the chaos section of the config file, and a
+// single request can set its own with the X-Famstack-Chaos header, e.g.
+//
+//	X-Famstack-Chaos: error=0.5, latency=1, latency_ms=800
+//
+// or turn injection off with "X-Famstack-Chaos: off".
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"famstack/internal/config"
+	"famstack/internal/jobsystem"
+)
+
+// Header names used by the middleware
+const (
+	HeaderFaults   = "X-Famstack-Chaos"          // per-request faults, or "off"
+	HeaderInjected = "X-Famstack-Chaos-Injected" // names the fault a response carries
+)
+
+// ErrInjected is the failure injected into jobs
+var ErrInjected = errors.New("chaos: injected failure")
+
+// ErrDBBusy mimics the error SQLite returns when the database stays locked
+// past the busy timeout
+var ErrDBBusy = errors.New("database is locked (5) (SQLITE_BUSY)")
+
+// fault is the outcome picked for one request or job
+type fault string
+
+const (
+	faultNone   fault = ""
+	faultError  fault = "error"
+	faultDBBusy fault = "db_busy"
+)
+
+// Faults are the injection rates, each between 0 and 1
+type Faults struct {
+	LatencyRate float64
+	Latency     time.Duration // an injected delay is between half this and this
+	ErrorRate   float64
+	DBBusyRate  float64
+}
+
+// FaultsFromConfig converts the chaos section of the config file
+func FaultsFromConfig(cfg config.ChaosConfig) Faults {
+	return Faults{
+		LatencyRate: cfg.LatencyRate,
+		Latency:     time.Duration(cfg.LatencyMs) * time.Millisecond,
+		ErrorRate:   cfg.ErrorRate,
+		DBBusyRate:  cfg.DBBusyRate,
+	}
+}
+
+// Validate checks that every rate is a probability and the delay isn't negative
+func (f Faults) Validate() error {
+	rates := []struct {
+		name string
+		rate float64
+	}{{"latency", f.LatencyRate}, {"error", f.ErrorRate}, {"db_busy", f.DBBusyRate}}
+	for _, r := range rates {
+		if r.rate < 0 || r.rate > 1 {
+			return fmt.Errorf("%s rate must be between 0 and 1", r.name)
+		}
+	}
+	if f.ErrorRate+f.DBBusyRate > 1 {
+		return fmt.Errorf("error and db_busy rates must not add up to more than 1")
+	}
+	if f.Latency < 0 {
+		return fmt.Errorf("latency must not be negative")
+	}
+	return nil
+}
+
+// ParseFaults applies a header value such as "error=0.2,latency_ms=500" on top
+// of base. Keys not named keep their base value.
+func ParseFaults(value string, base Faults) (Faults, error) {
+	faults := base
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, raw, ok := strings.Cut(part, "=")
+		if !ok {
+			return Faults{}, fmt.Errorf("expected key=value, got %q", part)
+		}
+		number, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return Faults{}, fmt.Errorf("invalid value for %s: %q", key, raw)
+		}
+
+		switch strings.TrimSpace(key) {
+		case "latency":
+			faults.LatencyRate = number
+		case "latency_ms":
+			faults.Latency = time.Duration(number * float64(time.Millisecond))
+		case "error":
+			faults.ErrorRate = number
+		case "db_busy":
+			faults.DBBusyRate = number
+		default:
+			return Faults{}, fmt.Errorf("unknown fault %q", key)
+		}
+	}
+	return faults, faults.Validate()
+}
+
+// Injector decides which faults to inject. Rolls come from math/rand unless
+// a test replaces them.
+type Injector struct {
+	enabled bool // inject without a header
+	faults  Faults
+	roll    func() float64
+}
+
+// NewInjector creates an injector from the chaos section of the config file.
+// A disabled config still lets requests opt in with the header.
+func NewInjector(cfg config.ChaosConfig) (*Injector, error) {
+	faults := FaultsFromConfig(cfg)
+	if err := faults.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid chaos config: %w", err)
+	}
+	return &Injector{enabled: cfg.Enabled, faults: faults, roll: rand.Float64}, nil
+}
+
+// Enabled reports whether faults are injected without a request asking
+func (i *Injector) Enabled() bool {
+	return i.enabled
+}
+
+// pick rolls for a delay and for at most one failure
+func (i *Injector) pick(faults Faults) (time.Duration, fault) {
+	var delay time.Duration
+	if faults.Latency > 0 && i.roll() < faults.LatencyRate {
+		delay = faults.Latency/2 + time.Duration(i.roll()*float64(faults.Latency/2))
+	}
+
+	roll := i.roll()
+	switch {
+	case roll < faults.ErrorRate:
+		return delay, faultError
+	case roll < faults.ErrorRate+faults.DBBusyRate:
+		return delay, faultDBBusy
+	default:
+		return delay, faultNone
+	}
+}
+
+// Middleware injects faults into API requests. Pages, static files and the
+// health check are left alone so the SPA itself still loads.
+func (i *Injector) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		faults, active := i.faults, i.enabled
+		if header := strings.TrimSpace(r.Header.Get(HeaderFaults)); header != "" {
+			if strings.EqualFold(header, "off") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			parsed, err := ParseFaults(header, i.faults)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid %s header: %v", HeaderFaults, err), http.StatusBadRequest)
+				return
+			}
+			faults, active = parsed, true
+		}
+		if !active {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		delay, picked := i.pick(faults)
+		if delay > 0 {
+			if err := sleep(r.Context(), delay); err != nil {
+				return // the client gave up
+			}
+			w.Header().Set(HeaderInjected, "latency")
+		}
+
+		switch picked {
+		case faultError:
+			w.Header().Set(HeaderInjected, string(faultError))
+			http.Error(w, "Injected fault: internal server error", http.StatusInternalServerError)
+		case faultDBBusy:
+			// Handlers surface database errors as 500s with the driver message
+			w.Header().Set(HeaderInjected, string(faultDBBusy))
+			http.Error(w, fmt.Sprintf("Injected fault: %v", ErrDBBusy), http.StatusInternalServerError)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// WrapJob injects faults into a job handler when the injector is enabled.
+// Injected failures are returned before the handler runs, so the job system
+// retries a job that did no work.
+func (i *Injector) WrapJob(handler jobsystem.JobHandler) jobsystem.JobHandler {
+	if !i.enabled {
+		return handler
+	}
+
+	return func(ctx context.Context, job *jobsystem.Job) error {
+		delay, picked := i.pick(i.faults)
+		logger := jobsystem.LoggerFromContext(ctx)
+		if delay > 0 {
+			logger.Warn("chaos: delaying job", "delay_ms", delay.Milliseconds())
+			if err := sleep(ctx, delay); err != nil {
+				return err
+			}
+		}
+
+		switch picked {
+		case faultError:
+			logger.Warn("chaos: failing job")
+			return ErrInjected
+		case faultDBBusy:
+			logger.Warn("chaos: failing job with database busy")
+			return fmt.Errorf("%w: %w", ErrInjected, ErrDBBusy)
+		default:
+			return handler(ctx, job)
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
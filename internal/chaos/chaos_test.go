@@ -0,0 +1,116 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"famstack/internal/config"
+	"famstack/internal/jobsystem"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedRolls returns the given rolls in order, then 0.99 forever
+func fixedRolls(rolls ...float64) func() float64 {
+	return func() float64 {
+		if len(rolls) == 0 {
+			return 0.99
+		}
+		roll := rolls[0]
+		rolls = rolls[1:]
+		return roll
+	}
+}
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func serve(injector *Injector, path, header string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	if header != "" {
+		req.Header.Set(HeaderFaults, header)
+	}
+	rec := httptest.NewRecorder()
+	injector.Middleware(okHandler()).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware_InjectsConfiguredFaults(t *testing.T) {
+	injector, err := NewInjector(config.ChaosConfig{Enabled: true, ErrorRate: 0.1, DBBusyRate: 0.1})
+	require.NoError(t, err)
+
+	injector.roll = fixedRolls(0.05)
+	rec := serve(injector, "/api/v1/tasks", "")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "error", rec.Header().Get(HeaderInjected))
+
+	injector.roll = fixedRolls(0.15)
+	rec = serve(injector, "/api/v1/tasks", "")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "db_busy", rec.Header().Get(HeaderInjected))
+	assert.Contains(t, rec.Body.String(), "database is locked")
+
+	injector.roll = fixedRolls(0.5)
+	assert.Equal(t, http.StatusOK, serve(injector, "/api/v1/tasks", "").Code)
+
+	// Pages and opted-out requests are never touched
+	injector.roll = fixedRolls(0)
+	assert.Equal(t, http.StatusOK, serve(injector, "/tasks", "").Code)
+	injector.roll = fixedRolls(0)
+	assert.Equal(t, http.StatusOK, serve(injector, "/api/v1/tasks", "off").Code)
+}
+
+func TestMiddleware_HeaderOptsIn(t *testing.T) {
+	injector, err := NewInjector(config.ChaosConfig{})
+	require.NoError(t, err)
+
+	injector.roll = fixedRolls(0)
+	assert.Equal(t, http.StatusOK, serve(injector, "/api/v1/tasks", "").Code)
+
+	injector.roll = fixedRolls(0, 0, 0)
+	rec := serve(injector, "/api/v1/tasks", "latency=1, latency_ms=2, error=1")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "error", rec.Header().Get(HeaderInjected))
+
+	rec = serve(injector, "/api/v1/tasks", "error=2")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = serve(injector, "/api/v1/tasks", "explode=1")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestWrapJob(t *testing.T) {
+	ran := 0
+	handler := func(ctx context.Context, job *jobsystem.Job) error {
+		ran++
+		return nil
+	}
+
+	disabled, err := NewInjector(config.ChaosConfig{ErrorRate: 1})
+	require.NoError(t, err)
+	require.NoError(t, disabled.WrapJob(handler)(context.Background(), &jobsystem.Job{}))
+	assert.Equal(t, 1, ran)
+
+	injector, err := NewInjector(config.ChaosConfig{Enabled: true, DBBusyRate: 0.5, LatencyRate: 1, LatencyMs: 1000})
+	require.NoError(t, err)
+	injector.roll = fixedRolls(0, 0, 0.1)
+
+	// A delay longer than the job's deadline ends with the context error
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = injector.WrapJob(handler)(ctx, &jobsystem.Job{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	injector.faults.Latency = 0
+	injector.roll = fixedRolls(0.1)
+	err = injector.WrapJob(handler)(context.Background(), &jobsystem.Job{})
+	assert.True(t, errors.Is(err, ErrDBBusy) && errors.Is(err, ErrInjected))
+	assert.Equal(t, 1, ran)
+}
@@ -0,0 +1,93 @@
+package cmds
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"famstack/internal/config"
+	"famstack/internal/database"
+	"famstack/internal/doctor"
+	"famstack/internal/encryption"
+)
+
+// DoctorCommand returns the diagnostics command configuration
+func DoctorCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "doctor",
+		Usage: "Check the database, migrations, encryption key, OAuth setup, job queue and clock, and say how to fix what's wrong",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "db",
+				Value: "famstack.db",
+				Usage: "Database file path or postgres:// URL",
+			},
+			&cli.StringFlag{
+				Name:  "config",
+				Value: "famstack-config.json",
+				Usage: "Configuration file path",
+			},
+		},
+		Action: runDoctor,
+	}
+}
+
+// runDoctor prints a line per finding and fails if any check failed, so it
+// can gate a deploy script
+func runDoctor(ctx *cli.Context) error {
+	configManager, err := config.NewManager(ctx.String("config"))
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+	cfg := configManager.GetConfig()
+
+	// An explicit --db wins over the database configured in the file
+	dbPath := ctx.String("db")
+	if cfg.Database.URL != "" && !ctx.IsSet("db") {
+		dbPath = cfg.Database.URL
+	}
+
+	db, err := database.New(dbPath)
+	if err != nil {
+		fmt.Printf("❌ Database: %v\n   → Check --db, or database.url in %s\n", err, ctx.String("config"))
+		return fmt.Errorf("database could not be opened")
+	}
+	defer db.Close()
+
+	d := &doctor.Doctor{
+		DB:     db,
+		Config: cfg,
+		NewEncryption: func() (*encryption.Service, error) {
+			return encryption.NewService(*config.DefaultEncryptionSettings())
+		},
+		Now: time.Now,
+	}
+	findings := d.Run()
+
+	for _, finding := range findings {
+		fmt.Printf("%s %s: %s\n", doctorIcon(finding.Status), finding.Check, finding.Message)
+		if finding.Fix != "" && finding.Status != doctor.StatusOK {
+			fmt.Printf("   → %s\n", finding.Fix)
+		}
+	}
+
+	if doctor.Failed(findings) {
+		return fmt.Errorf("some checks failed")
+	}
+	fmt.Println("\nNo problems that stop FamStack from running.")
+	return nil
+}
+
+func doctorIcon(status doctor.Status) string {
+	switch status {
+	case doctor.StatusOK:
+		return "✅"
+	case doctor.StatusWarn:
+		return "⚠️ "
+	case doctor.StatusFail:
+		return "❌"
+	default:
+		return "⏭️ "
+	}
+}
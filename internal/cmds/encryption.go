@@ -65,7 +65,7 @@ func encryptionStatus(ctx *cli.Context) error {
 			fmt.Printf("     %s %s (%s)\n", statusIcon, keyName, status)
 		}
 	case "fixed_key":
-		fmt.Println("   ⚠️  Using fixed key (development only)")
+		fmt.Println("   Using the key in FAMSTACK_FIXED_KEY_VALUE")
 	}
 
 	return nil
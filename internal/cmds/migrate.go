@@ -0,0 +1,390 @@
+package cmds
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"famstack/internal/database"
+)
+
+// MigrateCommand returns the schema migration command configuration
+func MigrateCommand() *cli.Command {
+	dbFlag := &cli.StringFlag{
+		Name:  "db",
+		Value: "famstack.db",
+		Usage: "Database file path or postgres:// URL",
+	}
+	phaseFlag := &cli.StringFlag{
+		Name:  "phase",
+		Value: database.PhasePreDeploy,
+		Usage: "Migration phase (pre-deploy, post-deploy, all)",
+	}
+
+	dryRunFlag := &cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Print the SQL that would run without changing anything",
+	}
+	noBackupFlag := &cli.BoolFlag{
+		Name:  "no-backup",
+		Usage: "Skip copying a SQLite database aside before changing it",
+	}
+	allowEditedFlag := &cli.BoolFlag{
+		Name:  "allow-edited",
+		Usage: "Go ahead although applied migrations were edited, accepting their new checksums",
+	}
+
+	return &cli.Command{
+		Name:    "migrate",
+		Aliases: []string{"m"},
+		Usage:   "Database schema migration commands",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "plan",
+				Usage:  "Describe the pending migrations for a phase, with locks and estimated durations",
+				Flags:  []cli.Flag{dbFlag, phaseFlag},
+				Action: planMigrations,
+			},
+			{
+				Name:   "up",
+				Usage:  "Apply the pending migrations for a phase",
+				Flags:  []cli.Flag{dbFlag, phaseFlag, dryRunFlag, noBackupFlag, allowEditedFlag},
+				Action: migrateUp,
+			},
+			{
+				Name:   "down",
+				Usage:  "Roll back the most recently applied migration",
+				Flags:  []cli.Flag{dbFlag, dryRunFlag, noBackupFlag, allowEditedFlag},
+				Action: migrateDown,
+			},
+			{
+				Name:      "to",
+				Usage:     "Apply or roll back migrations until the schema is at a version (0 rolls back everything)",
+				ArgsUsage: "<version>",
+				Flags:     []cli.Flag{dbFlag, dryRunFlag, noBackupFlag, allowEditedFlag},
+				Action:    migrateTo,
+			},
+			{
+				Name:      "baseline",
+				Usage:     "Mark migrations up to a version as applied without running them, for a schema created another way",
+				ArgsUsage: "<version>",
+				Flags:     []cli.Flag{dbFlag},
+				Action:    baselineMigrations,
+			},
+			{
+				Name:   "status",
+				Usage:  "List migrations with their phase, whether they have been applied and whether they were edited since",
+				Flags:  []cli.Flag{dbFlag},
+				Action: migrationStatus,
+			},
+		},
+	}
+}
+
+// planMigrations prints what 'migrate up' would do for the phase
+func planMigrations(ctx *cli.Context) error {
+	phase := ctx.String("phase")
+	if !database.ValidPhase(phase) {
+		return fmt.Errorf("unknown phase %q (expected pre-deploy, post-deploy or all)", phase)
+	}
+
+	db, err := database.New(ctx.String("db"))
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer db.Close()
+
+	plan, err := db.PlanMigrations(phase)
+	if err != nil {
+		return fmt.Errorf("failed to plan migrations: %w", err)
+	}
+
+	fmt.Printf("Schema version %d, this build goes to %d (phase: %s)\n\n", plan.CurrentVersion, plan.LatestVersion, plan.Phase)
+	if len(plan.Migrations) == 0 {
+		fmt.Println("Nothing to apply.")
+	}
+
+	for _, migration := range plan.Migrations {
+		fmt.Printf("%s [%s] ~%s\n", migration.Name, migration.Phase, formatEstimate(migration.Estimate))
+		for _, step := range migration.Statements {
+			target := step.Table
+			if target == "" {
+				target = "-"
+			}
+			fmt.Printf("  %-13s %-28s %-28s %8d rows  ~%s\n", step.Operation, target, step.Lock, step.Rows, formatEstimate(step.Estimate))
+		}
+	}
+
+	if len(plan.Migrations) > 0 {
+		fmt.Printf("\nEstimated total: ~%s. Readers such as the kitchen display keep working; writes wait for each statement.\n", formatEstimate(plan.Estimate))
+	}
+	for _, warning := range plan.Warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+
+	return nil
+}
+
+// migrateUp applies the phase's pending migrations
+func migrateUp(ctx *cli.Context) error {
+	phase := ctx.String("phase")
+	if !database.ValidPhase(phase) {
+		return fmt.Errorf("unknown phase %q (expected pre-deploy, post-deploy or all)", phase)
+	}
+
+	db, err := database.New(ctx.String("db"))
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer db.Close()
+
+	plan, err := db.PlanMigrations(phase)
+	if err != nil {
+		return fmt.Errorf("failed to plan migrations: %w", err)
+	}
+	if ctx.Bool("dry-run") {
+		return printMigrationSQL(db, plan.Migrations, nil)
+	}
+	if len(plan.Migrations) > 0 {
+		if err := prepareToMigrate(ctx, db); err != nil {
+			return err
+		}
+	}
+
+	applied, err := db.MigratePhase(phase)
+	for _, migration := range applied {
+		fmt.Printf("✅ Applied %s [%s]\n", migration.Name, migration.Phase)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run %s migrations: %w", phase, err)
+	}
+
+	if len(applied) == 0 {
+		fmt.Printf("No pending %s migrations\n", phase)
+	}
+	return nil
+}
+
+// migrateDown rolls back the newest applied migration
+func migrateDown(ctx *cli.Context) error {
+	db, err := database.New(ctx.String("db"))
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer db.Close()
+
+	last, err := db.LastAppliedMigration()
+	if err != nil {
+		return fmt.Errorf("failed to list migrations: %w", err)
+	}
+	if last == nil {
+		fmt.Println("No applied migrations to roll back")
+		return nil
+	}
+	if ctx.Bool("dry-run") {
+		return printMigrationSQL(db, nil, []database.MigrationInfo{*last})
+	}
+	if err := prepareToMigrate(ctx, db); err != nil {
+		return err
+	}
+
+	rolledBack, err := db.MigrateDown()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("↩️  Rolled back %s\n", rolledBack.Name)
+	return nil
+}
+
+// migrateTo moves the schema to the version given
+func migrateTo(ctx *cli.Context) error {
+	version, err := migrationVersionArg(ctx)
+	if err != nil {
+		return err
+	}
+
+	db, err := database.New(ctx.String("db"))
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer db.Close()
+
+	up, down, err := db.MigrationsTo(version)
+	if err != nil {
+		return err
+	}
+	if ctx.Bool("dry-run") {
+		return printMigrationSQL(db, up, down)
+	}
+	if len(up) == 0 && len(down) == 0 {
+		fmt.Printf("Schema is already at version %d\n", version)
+		return nil
+	}
+	if err := prepareToMigrate(ctx, db); err != nil {
+		return err
+	}
+
+	changed, err := db.MigrateTo(version)
+	for _, migration := range changed {
+		if migration.Applied {
+			fmt.Printf("✅ Applied %s [%s]\n", migration.Name, migration.Phase)
+		} else {
+			fmt.Printf("↩️  Rolled back %s\n", migration.Name)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to migrate to version %d: %w", version, err)
+	}
+	return nil
+}
+
+// baselineMigrations marks migrations applied without running them
+func baselineMigrations(ctx *cli.Context) error {
+	version, err := migrationVersionArg(ctx)
+	if err != nil {
+		return err
+	}
+
+	db, err := database.New(ctx.String("db"))
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer db.Close()
+
+	marked, err := db.Baseline(version)
+	for _, migration := range marked {
+		fmt.Printf("📌 Marked %s applied\n", migration.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to baseline at version %d: %w", version, err)
+	}
+	if len(marked) == 0 {
+		fmt.Printf("Every migration up to %d is already applied\n", version)
+	}
+	return nil
+}
+
+// migrationVersionArg reads the version argument of 'to' and 'baseline'
+func migrationVersionArg(ctx *cli.Context) (int64, error) {
+	if ctx.NArg() != 1 {
+		return 0, fmt.Errorf("expected a migration version, like 'famstack migrate %s 37'", ctx.Command.Name)
+	}
+	version, err := strconv.ParseInt(ctx.Args().First(), 10, 64)
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid migration version %q", ctx.Args().First())
+	}
+	return version, nil
+}
+
+// prepareToMigrate runs before a migration changes the schema: it refuses
+// to go on past edited migrations unless told to, and backs up a SQLite
+// database first
+func prepareToMigrate(ctx *cli.Context, db *database.Fascade) error {
+	edited, err := db.EditedMigrations()
+	if err != nil {
+		return fmt.Errorf("failed to check migration checksums: %w", err)
+	}
+	if len(edited) > 0 {
+		names := make([]string, len(edited))
+		for i, migration := range edited {
+			names[i] = migration.Name
+		}
+		if !ctx.Bool("allow-edited") {
+			return fmt.Errorf("applied migrations were edited since they ran: %s; check nothing needs rerunning, then pass --allow-edited",
+				strings.Join(names, ", "))
+		}
+		if err := db.AcceptEditedMigrations(); err != nil {
+			return fmt.Errorf("failed to accept edited migrations: %w", err)
+		}
+		fmt.Printf("Accepted edits to %s\n", strings.Join(names, ", "))
+	}
+
+	if ctx.Bool("no-backup") {
+		return nil
+	}
+	if db.Dialect() != database.DialectSQLite {
+		fmt.Println("ℹ️  Not backing up a PostgreSQL database; take a pg_dump first if you need one")
+		return nil
+	}
+	backupPath := fmt.Sprintf("%s.%s.bak", ctx.String("db"), time.Now().Format("20060102-150405"))
+	if err := db.Backup(backupPath); err != nil {
+		return err
+	}
+	fmt.Printf("💾 Backed up the database to %s\n", backupPath)
+	return nil
+}
+
+// printMigrationSQL prints the SQL a change would run, rollbacks first
+func printMigrationSQL(db *database.Fascade, up, down []database.MigrationInfo) error {
+	if len(up) == 0 && len(down) == 0 {
+		fmt.Println("Nothing to do.")
+		return nil
+	}
+	for _, migration := range down {
+		statements, err := db.MigrationSQL(migration, false)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("-- Roll back %s\n%s\n\n", migration.Name, statements)
+	}
+	for _, migration := range up {
+		statements, err := db.MigrationSQL(migration, true)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("-- Apply %s [%s]\n%s\n\n", migration.Name, migration.Phase, statements)
+	}
+	return nil
+}
+
+// migrationStatus lists every embedded migration
+func migrationStatus(ctx *cli.Context) error {
+	db, err := database.New(ctx.String("db"))
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer db.Close()
+
+	migrations, err := db.ListMigrations()
+	if err != nil {
+		return fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	fmt.Printf("%-8s %-45s %-12s %-8s\n", "Version", "Name", "Phase", "Applied")
+	fmt.Println(strings.Repeat("-", 76))
+	edited := 0
+	for _, migration := range migrations {
+		applied := "no"
+		if migration.Applied {
+			applied = "yes"
+		}
+		if migration.Edited {
+			applied = "edited"
+			edited++
+		}
+		fmt.Printf("%-8d %-45s %-12s %-8s\n", migration.Version, migration.Name, migration.Phase, applied)
+	}
+
+	if edited > 0 {
+		fmt.Printf("\n⚠️  %d applied migration(s) changed after they ran; their edits won't reach this database\n", edited)
+	}
+
+	if err := db.CheckSchemaCompatibility(); err != nil {
+		fmt.Printf("\n⚠️  %v\n", err)
+	}
+	return nil
+}
+
+// formatEstimate rounds an estimate for display
+func formatEstimate(d time.Duration) string {
+	if d < time.Millisecond {
+		return "<1ms"
+	}
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
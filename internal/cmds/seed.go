@@ -0,0 +1,88 @@
+package cmds
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"famstack/internal/auth"
+	"famstack/internal/config"
+	"famstack/internal/database"
+	"famstack/internal/encryption"
+	"famstack/internal/seed"
+)
+
+// SeedCommand returns the demo data command configuration
+func SeedCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "seed",
+		Usage: "Fill the database with a demo family to explore famstack with",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "db",
+				Value: "famstack.db",
+				Usage: "Database file path or postgres:// URL",
+			},
+			&cli.BoolFlag{
+				Name:  "reset",
+				Usage: "Replace the demo family if it already exists",
+			},
+			&cli.Int64Flag{
+				Name:  "seed",
+				Value: 1,
+				Usage: "Random seed; the same seed and start date give the same data",
+			},
+			&cli.StringFlag{
+				Name:  "start",
+				Usage: "First day of the month seeded, YYYY-MM-DD (default today)",
+			},
+			&cli.StringFlag{
+				Name:  "timezone",
+				Value: "America/Chicago",
+				Usage: "The demo family's timezone",
+			},
+		},
+		Action: seedDemoFamily,
+	}
+}
+
+func seedDemoFamily(ctx *cli.Context) error {
+	opts := seed.Options{
+		Seed:     ctx.Int64("seed"),
+		Timezone: ctx.String("timezone"),
+		Reset:    ctx.Bool("reset"),
+	}
+	if start := ctx.String("start"); start != "" {
+		parsed, err := time.Parse("2006-01-02", start)
+		if err != nil {
+			return fmt.Errorf("invalid start date %q, expected YYYY-MM-DD", start)
+		}
+		opts.Start = parsed
+	}
+
+	db, err := database.New(ctx.String("db"))
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer db.Close()
+	if err := db.MigrateUp(); err != nil {
+		return err
+	}
+
+	encryptionService, err := encryption.NewService(*config.DefaultEncryptionSettings())
+	if err != nil {
+		return fmt.Errorf("failed to initialize encryption service: %w", err)
+	}
+	authService := auth.NewService(db, encryptionService, "famstack")
+
+	summary, err := seed.Run(db, authService, opts)
+	if err != nil {
+		return fmt.Errorf("failed to seed demo data: %w", err)
+	}
+
+	fmt.Printf("🌱 Seeded %s: %d members, %d schedules, %d tasks, %d events and a demo integration\n",
+		summary.FamilyID, summary.Members, summary.Schedules, summary.Tasks, summary.Events)
+	fmt.Printf("Sign in as %s with password %s\n", seed.DemoEmail, seed.DemoPassword)
+	return nil
+}
@@ -0,0 +1,594 @@
+package cmds
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/urfave/cli/v2"
+
+	"famstack/internal/config"
+	"famstack/internal/encryption"
+)
+
+// serviceOptions describe the service install-service writes
+type serviceOptions struct {
+	Name    string
+	User    string
+	Group   string
+	Binary  string
+	DataDir string
+	Port    string
+	// Env is the service's environment, written to EnvFile for systemd and
+	// into the plist for launchd
+	Env     map[string]string
+	EnvFile string
+}
+
+// Args is the command line the service runs
+func (o *serviceOptions) Args() []string {
+	return []string{o.Binary, "start", "--port", o.Port, "--db", filepath.Join(o.DataDir, "famstack.db")}
+}
+
+// EnvKeys lists Env's keys in order, so the files come out the same each time
+func (o *serviceOptions) EnvKeys() []string {
+	keys := make([]string, 0, len(o.Env))
+	for key := range o.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// PrivilegedPort reports whether the port needs CAP_NET_BIND_SERVICE
+func (o *serviceOptions) PrivilegedPort() bool {
+	port, err := strconv.Atoi(o.Port)
+	return err == nil && port < 1024
+}
+
+// redacted returns a copy with the master key hidden, for dry-run output
+func (o *serviceOptions) redacted() *serviceOptions {
+	shown := *o
+	shown.Env = make(map[string]string, len(o.Env))
+	for key, value := range o.Env {
+		shown.Env[key] = value
+	}
+	if _, ok := shown.Env["FAMSTACK_FIXED_KEY_VALUE"]; ok {
+		shown.Env["FAMSTACK_FIXED_KEY_VALUE"] = "<master key>"
+	}
+	return &shown
+}
+
+func (o *serviceOptions) unitPath() string {
+	return filepath.Join("/etc/systemd/system", o.Name+".service")
+}
+
+func (o *serviceOptions) plistPath() string {
+	return filepath.Join("/Library/LaunchDaemons", o.label()+".plist")
+}
+
+func (o *serviceOptions) label() string {
+	return "com.famstack." + o.Name
+}
+
+// serviceNameFlag and serviceDryRunFlag are shared by install-service and
+// uninstall-service
+func serviceNameFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "name",
+		Value: "famstack",
+		Usage: "Service name",
+	}
+}
+
+func serviceDryRunFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Print what would be written and run instead",
+	}
+}
+
+// InstallServiceCommand returns the install-service command configuration
+func InstallServiceCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "install-service",
+		Usage: "Run FamStack on boot as a systemd service (launchd on macOS); run with sudo",
+		Flags: []cli.Flag{
+			serviceNameFlag(),
+			&cli.StringFlag{
+				Name:  "user",
+				Usage: "User the service runs as (default: a 'famstack' system user on Linux, the sudo user on macOS)",
+			},
+			&cli.StringFlag{
+				Name:  "data-dir",
+				Usage: "Directory for the database, config file and uploads (default: /var/lib/famstack, or /usr/local/var/famstack on macOS)",
+			},
+			&cli.StringFlag{
+				Name:  "binary",
+				Usage: "FamStack binary the service runs (default: this one)",
+			},
+			&cli.StringFlag{
+				Name:  "port",
+				Value: "8080",
+				Usage: "Server port",
+			},
+			&cli.StringSliceFlag{
+				Name:  "env",
+				Usage: "Extra environment variable for the service, as KEY=VALUE (repeatable)",
+			},
+			&cli.StringFlag{
+				Name:  "encryption-key",
+				Usage: "Master key from 'famstack encryption export-key' (default: FAMSTACK_FIXED_KEY_VALUE, or a new key)",
+			},
+			&cli.BoolFlag{
+				Name:  "no-start",
+				Usage: "Write the service without enabling or starting it",
+			},
+			serviceDryRunFlag(),
+		},
+		Action: installService,
+	}
+}
+
+// UninstallServiceCommand returns the uninstall-service command configuration
+func UninstallServiceCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "uninstall-service",
+		Usage:  "Stop FamStack running on boot and remove its service; the data directory is kept",
+		Flags:  []cli.Flag{serviceNameFlag(), serviceDryRunFlag()},
+		Action: uninstallService,
+	}
+}
+
+// installService writes the service definition and its environment, creates
+// the user and data directory it needs, and starts it
+func installService(c *cli.Context) error {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		return fmt.Errorf("install-service supports systemd on Linux and launchd on macOS, not %s", runtime.GOOS)
+	}
+	dryRun := c.Bool("dry-run")
+	if !dryRun && os.Geteuid() != 0 {
+		return fmt.Errorf("install-service writes system files; run it with sudo")
+	}
+
+	opts, err := serviceOptionsFrom(c)
+	if err != nil {
+		return err
+	}
+
+	key, generated, err := serviceEncryptionKey(c, opts.DataDir)
+	if err != nil {
+		return err
+	}
+	// The service has no login keyring to read the master key from
+	opts.Env["FAMSTACK_FIXED_KEY_VALUE"] = key
+
+	keptIn := opts.EnvFile
+	if runtime.GOOS == "darwin" {
+		keptIn = opts.plistPath()
+		err = installLaunchd(opts, dryRun, c.Bool("no-start"))
+	} else {
+		err = installSystemd(opts, dryRun, c.Bool("no-start"))
+	}
+	if err != nil {
+		return err
+	}
+	if generated && !dryRun {
+		fmt.Printf("🔑 Generated a new master key, kept in %s\n", keptIn)
+		fmt.Println("💡 Back it up somewhere safe; without it stored calendar tokens can't be read")
+	}
+	return nil
+}
+
+// serviceOptionsFrom fills in the options from the flags and the platform's
+// defaults
+func serviceOptionsFrom(c *cli.Context) (*serviceOptions, error) {
+	opts := &serviceOptions{
+		Name:    c.String("name"),
+		User:    c.String("user"),
+		Binary:  c.String("binary"),
+		DataDir: c.String("data-dir"),
+		Port:    c.String("port"),
+		Env:     map[string]string{},
+		EnvFile: filepath.Join("/etc", c.String("name"), c.String("name")+".env"),
+	}
+	if strings.ContainsAny(opts.Name, "/ \t") || opts.Name == "" {
+		return nil, fmt.Errorf("service name %q can't be empty or contain slashes or spaces", opts.Name)
+	}
+	if _, err := strconv.Atoi(opts.Port); err != nil {
+		return nil, fmt.Errorf("port %q is not a number", opts.Port)
+	}
+
+	if opts.Binary == "" {
+		execPath, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get executable path: %w", err)
+		}
+		opts.Binary = execPath
+	}
+	binary, err := filepath.Abs(opts.Binary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve binary path: %w", err)
+	}
+	opts.Binary = binary
+
+	if opts.DataDir == "" {
+		opts.DataDir = "/var/lib/" + opts.Name
+		if runtime.GOOS == "darwin" {
+			opts.DataDir = "/usr/local/var/" + opts.Name
+		}
+	}
+	if opts.DataDir, err = filepath.Abs(opts.DataDir); err != nil {
+		return nil, fmt.Errorf("failed to resolve data directory: %w", err)
+	}
+
+	if opts.User == "" {
+		opts.User = "famstack"
+		if runtime.GOOS == "darwin" {
+			// Creating macOS users is best left to System Settings
+			opts.User = os.Getenv("SUDO_USER")
+			if opts.User == "" {
+				return nil, fmt.Errorf("pass --user with the account FamStack should run as")
+			}
+		}
+	}
+	if opts.User == "root" {
+		return nil, fmt.Errorf("don't run FamStack as root; pass --user with an unprivileged account")
+	}
+
+	for _, pair := range c.StringSlice("env") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("--env %q should be KEY=VALUE", pair)
+		}
+		opts.Env[key] = value
+	}
+	return opts, nil
+}
+
+// serviceEncryptionKey finds the master key the service should use: the flag
+// or FAMSTACK_FIXED_KEY_VALUE. Under sudo the keyring is root's, not that of
+// whoever set FamStack up, so it isn't asked. With neither, a new key is made,
+// unless the data directory already has a database encrypted with some other
+// key.
+func serviceEncryptionKey(c *cli.Context, dataDir string) (key string, generated bool, err error) {
+	key = c.String("encryption-key")
+	if key == "" {
+		key = os.Getenv("FAMSTACK_FIXED_KEY_VALUE")
+	}
+	if key != "" {
+		if _, err := encryption.NewFixedProvider(config.FixedKeyConfig{Value: key}); err != nil {
+			return "", false, fmt.Errorf("encryption key: %w", err)
+		}
+		return key, false, nil
+	}
+
+	if _, err := os.Stat(filepath.Join(dataDir, "famstack.db")); err == nil {
+		return "", false, fmt.Errorf("%s already has a database; run 'famstack encryption export-key' as the user who "+
+			"set FamStack up and pass the key with --encryption-key", dataDir)
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", false, fmt.Errorf("failed to generate random key: %w", err)
+	}
+	return hex.EncodeToString(raw), true, nil
+}
+
+var systemdUnitTemplate = template.Must(template.New("unit").Funcs(template.FuncMap{"quote": systemdQuote}).Parse(`[Unit]
+Description=FamStack family organizer
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=simple
+User={{.User}}
+Group={{.Group}}
+WorkingDirectory={{quote .DataDir}}
+EnvironmentFile={{quote .EnvFile}}
+ExecStart={{range $i, $arg := .Args}}{{if $i}} {{end}}{{quote $arg}}{{end}}
+Restart=on-failure
+RestartSec=5
+NoNewPrivileges=true
+ProtectSystem=strict
+ProtectHome=true
+PrivateTmp=true
+ReadWritePaths={{quote .DataDir}}
+{{- if .PrivilegedPort}}
+AmbientCapabilities=CAP_NET_BIND_SERVICE
+{{- end}}
+
+[Install]
+WantedBy=multi-user.target
+`))
+
+// systemdQuote quotes a word for a unit file when it needs it
+func systemdQuote(word string) string {
+	if word != "" && !strings.ContainsAny(word, " \t\"'\\") {
+		return word
+	}
+	return strconv.Quote(word)
+}
+
+// renderSystemdUnit returns the unit file for the service
+func renderSystemdUnit(opts *serviceOptions) (string, error) {
+	var buf bytes.Buffer
+	if err := systemdUnitTemplate.Execute(&buf, opts); err != nil {
+		return "", fmt.Errorf("failed to render unit: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// renderEnvFile returns the EnvironmentFile for the service
+func renderEnvFile(opts *serviceOptions) string {
+	var buf strings.Builder
+	buf.WriteString("# FamStack service environment, written by 'famstack install-service'\n")
+	for _, key := range opts.EnvKeys() {
+		fmt.Fprintf(&buf, "%s=%s\n", key, systemdQuote(opts.Env[key]))
+	}
+	return buf.String()
+}
+
+var launchdPlistTemplate = template.Must(template.New("plist").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{xml .Label}}</string>
+	<key>UserName</key>
+	<string>{{xml .Opts.User}}</string>
+	<key>WorkingDirectory</key>
+	<string>{{xml .Opts.DataDir}}</string>
+	<key>ProgramArguments</key>
+	<array>
+{{- range .Opts.Args}}
+		<string>{{xml .}}</string>
+{{- end}}
+	</array>
+	<key>EnvironmentVariables</key>
+	<dict>
+{{- range .Opts.EnvKeys}}
+		<key>{{xml .}}</key>
+		<string>{{xml (index $.Opts.Env .)}}</string>
+{{- end}}
+	</dict>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardOutPath</key>
+	<string>{{xml .Log}}</string>
+	<key>StandardErrorPath</key>
+	<string>{{xml .Log}}</string>
+</dict>
+</plist>
+`))
+
+func xmlEscape(text string) string {
+	var buf strings.Builder
+	_ = xml.EscapeText(&buf, []byte(text)) // nolint:errcheck
+	return buf.String()
+}
+
+// renderLaunchdPlist returns the launchd daemon definition for the service
+func renderLaunchdPlist(opts *serviceOptions) (string, error) {
+	var buf bytes.Buffer
+	err := launchdPlistTemplate.Execute(&buf, map[string]any{
+		"Label": opts.label(),
+		"Opts":  opts,
+		"Log":   filepath.Join(opts.DataDir, "famstack.log"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render plist: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func installSystemd(opts *serviceOptions, dryRun, noStart bool) error {
+	if _, err := exec.LookPath("systemctl"); err != nil && !dryRun {
+		return fmt.Errorf("systemctl not found; install-service needs systemd")
+	}
+
+	if err := ensureServiceUser(opts, dryRun); err != nil {
+		return err
+	}
+	unit, err := renderSystemdUnit(opts)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Printf("# %s\n%s\n# %s (0600)\n%s\n", opts.unitPath(), unit, opts.EnvFile, renderEnvFile(opts.redacted()))
+		fmt.Printf("# then: systemctl daemon-reload && systemctl enable --now %s\n", opts.Name)
+		return nil
+	}
+
+	if err := prepareDataDir(opts); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(opts.EnvFile), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(opts.EnvFile), err)
+	}
+	if err := os.WriteFile(opts.EnvFile, []byte(renderEnvFile(opts)), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.EnvFile, err)
+	}
+	if err := os.WriteFile(opts.unitPath(), []byte(unit), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.unitPath(), err)
+	}
+	fmt.Printf("📝 Wrote %s and %s\n", opts.unitPath(), opts.EnvFile)
+
+	if err := runServiceCommand("systemctl", "daemon-reload"); err != nil {
+		return err
+	}
+	if noStart {
+		fmt.Printf("💡 Start it with 'sudo systemctl enable --now %s'\n", opts.Name)
+		return nil
+	}
+	if err := runServiceCommand("systemctl", "enable", "--now", opts.Name); err != nil {
+		return err
+	}
+	fmt.Printf("✅ FamStack is running as %s on port %s and will start on boot\n", opts.User, opts.Port)
+	fmt.Printf("💡 Logs: journalctl -u %s -f\n", opts.Name)
+	return nil
+}
+
+func installLaunchd(opts *serviceOptions, dryRun, noStart bool) error {
+	if dryRun {
+		plist, err := renderLaunchdPlist(opts.redacted())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("# %s (0600)\n%s\n", opts.plistPath(), plist)
+		fmt.Printf("# then: launchctl bootstrap system %s\n", opts.plistPath())
+		return nil
+	}
+
+	plist, err := renderLaunchdPlist(opts)
+	if err != nil {
+		return err
+	}
+
+	if err := prepareDataDir(opts); err != nil {
+		return err
+	}
+	// The plist holds the master key, so only root may read it
+	if err := os.WriteFile(opts.plistPath(), []byte(plist), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.plistPath(), err)
+	}
+	fmt.Printf("📝 Wrote %s\n", opts.plistPath())
+
+	if noStart {
+		fmt.Printf("💡 Start it with 'sudo launchctl bootstrap system %s'\n", opts.plistPath())
+		return nil
+	}
+	if err := runServiceCommand("launchctl", "bootstrap", "system", opts.plistPath()); err != nil {
+		return err
+	}
+	fmt.Printf("✅ FamStack is running as %s on port %s and will start on boot\n", opts.User, opts.Port)
+	fmt.Printf("💡 Logs: %s\n", filepath.Join(opts.DataDir, "famstack.log"))
+	return nil
+}
+
+// ensureServiceUser creates the service's system user on Linux if it
+// doesn't exist yet, and fills in its group
+func ensureServiceUser(opts *serviceOptions, dryRun bool) error {
+	account, err := user.Lookup(opts.User)
+	var unknown user.UnknownUserError
+	if errors.As(err, &unknown) {
+		if dryRun {
+			fmt.Printf("# would create system user %s\n", opts.User)
+			opts.Group = opts.User
+			return nil
+		}
+		if err := runServiceCommand("useradd", "--system", "--user-group", "--home-dir", opts.DataDir,
+			"--shell", "/usr/sbin/nologin", opts.User); err != nil {
+			return err
+		}
+		fmt.Printf("👤 Created system user %s\n", opts.User)
+		account, err = user.Lookup(opts.User)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up user %s: %w", opts.User, err)
+	}
+
+	opts.Group = opts.User
+	if group, err := user.LookupGroupId(account.Gid); err == nil {
+		opts.Group = group.Name
+	}
+	return nil
+}
+
+// prepareDataDir creates the data directory and hands it to the service user
+func prepareDataDir(opts *serviceOptions) error {
+	account, err := user.Lookup(opts.User)
+	if err != nil {
+		return fmt.Errorf("failed to look up user %s: %w", opts.User, err)
+	}
+	uid, _ := strconv.Atoi(account.Uid)
+	gid, _ := strconv.Atoi(account.Gid)
+
+	if err := os.MkdirAll(opts.DataDir, 0750); err != nil {
+		return fmt.Errorf("failed to create %s: %w", opts.DataDir, err)
+	}
+	return filepath.Walk(opts.DataDir, func(path string, _ os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, uid, gid)
+	})
+}
+
+// uninstallService stops the service and removes what install-service wrote,
+// leaving the data directory and user in place
+func uninstallService(c *cli.Context) error {
+	name := c.String("name")
+	dryRun := c.Bool("dry-run")
+	if !dryRun && os.Geteuid() != 0 {
+		return fmt.Errorf("uninstall-service removes system files; run it with sudo")
+	}
+	opts := &serviceOptions{Name: name, EnvFile: filepath.Join("/etc", name, name+".env")}
+
+	var commands [][]string
+	var files []string
+	switch runtime.GOOS {
+	case "linux":
+		commands = [][]string{{"systemctl", "disable", "--now", name}}
+		files = []string{opts.unitPath(), opts.EnvFile}
+	case "darwin":
+		commands = [][]string{{"launchctl", "bootout", "system/" + opts.label()}}
+		files = []string{opts.plistPath()}
+	default:
+		return fmt.Errorf("uninstall-service supports systemd on Linux and launchd on macOS, not %s", runtime.GOOS)
+	}
+
+	if _, err := os.Stat(files[0]); err != nil && !dryRun {
+		return fmt.Errorf("no %s service is installed (%s not found)", name, files[0])
+	}
+	if dryRun {
+		for _, command := range commands {
+			fmt.Printf("# would run: %s\n", strings.Join(command, " "))
+		}
+		fmt.Printf("# would remove: %s\n", strings.Join(files, ", "))
+		return nil
+	}
+
+	for _, command := range commands {
+		if err := runServiceCommand(command[0], command[1:]...); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", file, err)
+		}
+	}
+	if runtime.GOOS == "linux" {
+		_ = os.Remove(filepath.Dir(opts.EnvFile)) // nolint:errcheck // only if empty
+		if err := runServiceCommand("systemctl", "daemon-reload"); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("✅ Removed the %s service\n", name)
+	fmt.Println("💡 The data directory and service user are kept; delete them by hand if you no longer need them")
+	return nil
+}
+
+func runServiceCommand(name string, args ...string) error {
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
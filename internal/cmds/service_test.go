@@ -0,0 +1,79 @@
+package cmds
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testServiceOptions() *serviceOptions {
+	return &serviceOptions{
+		Name:    "famstack",
+		User:    "famstack",
+		Group:   "famstack",
+		Binary:  "/usr/local/bin/famstack",
+		DataDir: "/var/lib/fam stack",
+		Port:    "8080",
+		Env:     map[string]string{"FAMSTACK_FIXED_KEY_VALUE": "abc123", "TZ": "America/Chicago"},
+		EnvFile: "/etc/famstack/famstack.env",
+	}
+}
+
+func TestRenderSystemdUnit(t *testing.T) {
+	opts := testServiceOptions()
+	unit, err := renderSystemdUnit(opts)
+	require.NoError(t, err)
+
+	assert.Contains(t, unit, "User=famstack\nGroup=famstack\n")
+	assert.Contains(t, unit, `WorkingDirectory="/var/lib/fam stack"`)
+	assert.Contains(t, unit, `ExecStart=/usr/local/bin/famstack start --port 8080 --db "/var/lib/fam stack/famstack.db"`)
+	assert.Contains(t, unit, "EnvironmentFile=/etc/famstack/famstack.env")
+	assert.NotContains(t, unit, "abc123", "the key stays in the root-only environment file")
+	assert.NotContains(t, unit, "CAP_NET_BIND_SERVICE")
+
+	opts.Port = "443"
+	unit, err = renderSystemdUnit(opts)
+	require.NoError(t, err)
+	assert.Contains(t, unit, "AmbientCapabilities=CAP_NET_BIND_SERVICE\n")
+}
+
+func TestRenderEnvFile(t *testing.T) {
+	opts := testServiceOptions()
+	opts.Env["GREETING"] = "hello world"
+
+	lines := strings.Split(strings.TrimSpace(renderEnvFile(opts)), "\n")
+	assert.Equal(t, []string{
+		"# FamStack service environment, written by 'famstack install-service'",
+		"FAMSTACK_FIXED_KEY_VALUE=abc123",
+		`GREETING="hello world"`,
+		"TZ=America/Chicago",
+	}, lines)
+
+	assert.Contains(t, renderEnvFile(opts.redacted()), `FAMSTACK_FIXED_KEY_VALUE="<master key>"`)
+	assert.Equal(t, "abc123", opts.Env["FAMSTACK_FIXED_KEY_VALUE"], "redacting copies")
+}
+
+func TestRenderLaunchdPlist(t *testing.T) {
+	opts := testServiceOptions()
+	opts.Env["NOTE"] = "<tom & jerry>"
+
+	plist, err := renderLaunchdPlist(opts)
+	require.NoError(t, err)
+
+	// Well-formed, with the arguments and environment escaped
+	decoder := xml.NewDecoder(strings.NewReader(plist))
+	for {
+		if _, err := decoder.Token(); err != nil {
+			assert.ErrorIs(t, err, io.EOF)
+			break
+		}
+	}
+	assert.Contains(t, plist, "<string>com.famstack.famstack</string>")
+	assert.Contains(t, plist, "<string>/var/lib/fam stack/famstack.db</string>")
+	assert.Contains(t, plist, "<string>&lt;tom &amp; jerry&gt;</string>")
+	assert.Contains(t, plist, "<string>/var/lib/fam stack/famstack.log</string>")
+}
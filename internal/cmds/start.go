@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"syscall"
 	"time"
 
@@ -13,14 +15,22 @@ import (
 
 	"famstack/internal/auth"
 	"famstack/internal/calendar"
+	"famstack/internal/chaos"
 	"famstack/internal/config"
 	"famstack/internal/database"
 	"famstack/internal/encryption"
+	"famstack/internal/eventbus"
 	"famstack/internal/jobs"
 	"famstack/internal/jobsystem"
+	"famstack/internal/mqtt"
+	"famstack/internal/notify"
 	"famstack/internal/oauth"
 	"famstack/internal/server"
 	"famstack/internal/services"
+	"famstack/internal/storage"
+	"famstack/internal/templates"
+	"famstack/internal/tracing"
+	"famstack/internal/weather"
 )
 
 // StartCommand returns the start command configuration
@@ -43,12 +53,28 @@ func StartCommand() *cli.Command {
 			&cli.StringFlag{
 				Name:  "db",
 				Value: "famstack.db",
-				Usage: "Database file path",
+				Usage: "Database file path or postgres:// URL",
 			},
 			&cli.BoolFlag{
 				Name:  "dev",
 				Usage: "Enable development mode",
 			},
+			&cli.StringFlag{
+				Name:  "tls-cert",
+				Usage: "TLS certificate file, to serve HTTPS without a reverse proxy",
+			},
+			&cli.StringFlag{
+				Name:  "tls-key",
+				Usage: "TLS private key file for --tls-cert",
+			},
+			&cli.StringSliceFlag{
+				Name:  "autocert-domain",
+				Usage: "Serve HTTPS with certificates from Let's Encrypt for this domain (repeatable)",
+			},
+			&cli.StringFlag{
+				Name:  "rpc-port",
+				Usage: "Serve the JSON-RPC API for companion apps on this port",
+			},
 			&cli.BoolFlag{
 				Name:  "migrate-up",
 				Usage: "Run database migrations up",
@@ -70,6 +96,18 @@ func startServer(ctx *cli.Context) error {
 	migrateDown := ctx.Bool("migrate-down")
 	dev := ctx.Bool("dev")
 
+	// Initialize configuration manager
+	configManager, err := config.NewManager("famstack-config.json")
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+	log.Println("📋 Configuration manager initialized successfully")
+
+	// An explicit --db wins over the database configured in the file
+	if url := configManager.GetConfig().Database.URL; url != "" && !ctx.IsSet("db") {
+		dbPath = url
+	}
+
 	// Initialize database
 	db, err := database.New(dbPath)
 	if err != nil {
@@ -87,24 +125,33 @@ func startServer(ctx *cli.Context) error {
 	}
 
 	if migrateDown {
-		if migErr := db.MigrateDown(); migErr != nil {
+		if _, migErr := db.MigrateDown(); migErr != nil {
 			return fmt.Errorf("failed to run migrations down: %w", migErr)
 		}
 		log.Println("Migrations rolled back successfully")
 		return nil
 	}
 
-	// Run migrations automatically if not explicitly handling them
-	if migErr := db.MigrateUp(); migErr != nil {
+	// Run pre-deploy migrations automatically; post-deploy ones would break an
+	// older instance still serving, so they wait for 'famstack migrate up'
+	if _, migErr := db.MigratePhase(database.PhasePreDeploy); migErr != nil {
 		return fmt.Errorf("failed to run automatic migrations: %w", migErr)
 	}
+	if edited, editErr := db.EditedMigrations(); editErr == nil && len(edited) > 0 {
+		log.Printf("⚠️  %d applied migration(s) have been edited since they ran; see 'famstack migrate status'", len(edited))
+	}
+	if plan, planErr := db.PlanMigrations(database.PhasePostDeploy); planErr == nil && len(plan.Migrations) > 0 {
+		log.Printf("⏳ %d post-deploy migration(s) pending; run 'famstack migrate up --phase post-deploy' once every instance is upgraded", len(plan.Migrations))
+	}
 
-	// Initialize configuration manager
-	configManager, err := config.NewManager("famstack-config.json")
+	tracingConfig := configManager.GetConfig().Tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingConfig, Version)
 	if err != nil {
-		return fmt.Errorf("failed to initialize config manager: %w", err)
+		return fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+	if tracingConfig.Enabled {
+		log.Println("🔭 Tracing enabled - sending spans over OTLP")
 	}
-	log.Println("📋 Configuration manager initialized successfully")
 
 	// Initialize encryption service with default configuration
 	// For now, use keyring with auto-creation
@@ -127,54 +174,190 @@ func startServer(ctx *cli.Context) error {
 	serviceRegistry := services.NewRegistry(db, encryptionService)
 	log.Println("🔧 Service registry initialized successfully")
 
+	// Usage analytics are opt-in; re-read the setting on every count so
+	// turning it off stops collection immediately
+	serviceRegistry.Analytics.SetEnabledFunc(func() bool {
+		return configManager.GetConfig().Features.UsageAnalytics
+	})
+
+	// Attachments go to the backend named in the storage config
+	storageBackend, err := storage.New(configManager.GetConfig().Storage)
+	if err != nil {
+		return fmt.Errorf("storage backend initialization failed: %w", err)
+	}
+	serviceRegistry.Attachments.SetBackend(storageBackend)
+	log.Printf("🗄️  Storage backend initialized: %s", storageBackend.Name())
+
+	// Notification channels come from the config; web push needs a VAPID key
+	// pair, which is made once and saved so browser subscriptions stay valid
+	if notifyConfig := configManager.GetConfig().Notify; notifyConfig.WebPush.Enabled && notifyConfig.WebPush.VAPIDPrivateKey == "" {
+		publicKey, privateKey, keyErr := notify.GenerateVAPIDKeys()
+		if keyErr == nil {
+			keyErr = configManager.UpdateVAPIDKeys(publicKey, privateKey)
+		}
+		if keyErr != nil {
+			log.Printf("Warning: failed to create VAPID keys: %v", keyErr)
+		}
+	}
+	channels, err := notify.NewChannels(configManager.GetConfig())
+	if err != nil {
+		log.Printf("Warning: notifications disabled: %v", err)
+	} else {
+		serviceRegistry.Notifications.SetChannels(channels)
+		log.Printf("🔔 Notification channels enabled: %v", serviceRegistry.Notifications.Channels())
+	}
+	appConfig := configManager.GetConfig()
+	authService.SetMagicLinks(appConfig.MagicLink, appConfig.Server.PublicURL, channels[notify.ChannelEmail])
+
 	// Configure job system
 	jobConfig := jobsystem.DefaultConfig()
 	jobConfig.DatabasePath = dbPath
 	jobConfig.WorkerConcurrency = map[string]int{
 		"default":         3,
 		"task_generation": 2,
+		"reminders":       2,
 	}
 
 	// Create job system
 	jobSystem := jobsystem.NewDBJobSystem(jobConfig, serviceRegistry.Jobs)
 
 	// Initialize OAuth and calendar services for job handlers
-	// Get OAuth configuration from config manager
-	googleConfig, err := configManager.GetOAuthProvider("google")
-	if err != nil {
-		log.Printf("Warning: Google OAuth not configured: %v", err)
-		googleConfig = nil
-	}
-
-	var oauthConfig *oauth.OAuthConfig
-	if googleConfig != nil && googleConfig.Configured {
-		oauthConfig = &oauth.OAuthConfig{
-			Google: &oauth.GoogleConfig{
-				ClientID:     googleConfig.ClientID,
-				ClientSecret: googleConfig.ClientSecret,
-				RedirectURL:  googleConfig.RedirectURL,
-				Scopes:       googleConfig.Scopes,
-			},
-		}
+	oauthConfig := oauth.ConfigFrom(configManager.GetConfig().OAuth)
+	if oauthConfig.Google != nil {
 		log.Println("🔗 Google OAuth configured successfully")
 	} else {
 		log.Println("⚠️  Google OAuth not configured - calendar integration will be unavailable")
-		oauthConfig = &oauth.OAuthConfig{} // Empty config
 	}
 	oauthService := oauth.NewService(serviceRegistry.OAuth, oauthConfig, encryptionService)
+	configManager.Subscribe(func(old, updated *config.Config) {
+		if slices.Contains(config.Changed(old, updated), "oauth") {
+			oauthService.SetConfig(oauth.ConfigFrom(updated.OAuth))
+		}
+	})
 	googleClient := calendar.NewGoogleClient(oauthService)
 
+	// Fault injection is for resilience testing in development only
+	var chaosInjector *chaos.Injector
+	if dev {
+		chaosInjector, err = chaos.NewInjector(configManager.GetConfig().Chaos)
+		if err != nil {
+			return err
+		}
+		if chaosInjector.Enabled() {
+			log.Println("🧪 Chaos mode enabled - injecting latency and failures into API requests and jobs")
+		}
+	}
+	register := func(jobType string, handler jobsystem.JobHandler, opts jobsystem.HandlerOptions) {
+		if chaosInjector != nil {
+			handler = chaosInjector.WrapJob(handler)
+		}
+		jobSystem.Register(jobType, handler, opts)
+	}
+
 	// Register job handlers
-	jobSystem.Register("monthly_task_generation", jobs.NewMonthlyTaskGenerationHandler(serviceRegistry))
-	jobSystem.Register("schedule_maintenance", jobs.NewScheduleMaintenanceHandler(serviceRegistry, jobSystem))
-	jobSystem.Register("delete_schedule", jobs.NewScheduleDeletionHandler(serviceRegistry))
+	register("monthly_task_generation", jobs.NewMonthlyTaskGenerationHandler(serviceRegistry), jobsystem.HandlerOptions{
+		Timeout: 5 * time.Minute,
+	})
+	register("schedule_maintenance", jobs.NewScheduleMaintenanceHandler(serviceRegistry, jobSystem), jobsystem.HandlerOptions{
+		Timeout:        5 * time.Minute,
+		MaxConcurrency: 1,
+	})
+	register("delete_schedule", jobs.NewScheduleDeletionHandler(serviceRegistry), jobsystem.HandlerOptions{
+		Timeout: 2 * time.Minute,
+	})
+	register("suggestion_engine", jobs.NewSuggestionEngineHandler(serviceRegistry), jobsystem.HandlerOptions{
+		Timeout:        5 * time.Minute,
+		MaxConcurrency: 1,
+	})
+	register("suggestion_digest", jobs.NewSuggestionDigestHandler(serviceRegistry), jobsystem.HandlerOptions{
+		Timeout:        5 * time.Minute,
+		MaxConcurrency: 1,
+	})
+	register("display_projection_refresh", jobs.NewDisplayProjectionHandler(serviceRegistry), jobsystem.HandlerOptions{
+		Timeout:        2 * time.Minute,
+		MaxConcurrency: 1,
+	})
+	register("reminder_planner", jobs.NewReminderPlannerHandler(serviceRegistry, jobSystem), jobsystem.HandlerOptions{
+		Timeout:        time.Minute,
+		MaxConcurrency: 1,
+	})
+	register("event_reminder", jobs.NewEventReminderHandler(serviceRegistry), jobsystem.HandlerOptions{
+		Timeout: time.Minute,
+	})
+	register("daily_digest_planner", jobs.NewDailyDigestPlannerHandler(serviceRegistry, jobSystem), jobsystem.HandlerOptions{
+		Timeout:        time.Minute,
+		MaxConcurrency: 1,
+	})
+	register("daily_digest", jobs.NewDailyDigestHandler(serviceRegistry, templates.MustNewRenderer()), jobsystem.HandlerOptions{
+		Timeout: 2 * time.Minute,
+	})
+	register(jobs.PushNotificationJobType, jobs.NewPushNotificationHandler(serviceRegistry), jobsystem.HandlerOptions{
+		Timeout: time.Minute,
+	})
+	serviceRegistry.Events.Subscribe(jobs.NewTaskAssignedNotifier(serviceRegistry, jobSystem), eventbus.TopicTaskAssigned)
+	register(jobs.WebhookDeliveryJobType, jobs.NewWebhookDeliveryHandler(serviceRegistry, &http.Client{Timeout: 15 * time.Second}), jobsystem.HandlerOptions{
+		Timeout: time.Minute,
+	})
+	serviceRegistry.Events.Subscribe(jobs.NewWebhookDispatcher(serviceRegistry, jobSystem), eventbus.TopicTasksChanged, eventbus.TopicEventCreated)
+	register(jobs.FeedPlannerJobType, jobs.NewFeedPlannerHandler(serviceRegistry, jobSystem), jobsystem.HandlerOptions{
+		Timeout:        time.Minute,
+		MaxConcurrency: 1,
+	})
+	register(jobs.FeedSyncJobType, jobs.NewFeedSyncHandler(serviceRegistry, jobs.NewFeedClient(30*time.Second)), jobsystem.HandlerOptions{
+		Timeout:        2 * time.Minute,
+		MaxConcurrency: 2,
+	})
+	weatherClient := weather.NewClient(&http.Client{Timeout: 15 * time.Second}, appConfig.Weather.ForecastURL)
+	register(jobs.WeatherRefreshJobType, jobs.NewWeatherRefreshHandler(serviceRegistry, weatherClient), jobsystem.HandlerOptions{
+		Timeout:        5 * time.Minute,
+		MaxConcurrency: 1,
+	})
+	register(jobs.CelebrationsJobType, jobs.NewCelebrationsHandler(serviceRegistry), jobsystem.HandlerOptions{
+		Timeout:        5 * time.Minute,
+		MaxConcurrency: 1,
+	})
+	register(jobs.TaskRolloverJobType, jobs.NewTaskRolloverHandler(serviceRegistry), jobsystem.HandlerOptions{
+		Timeout:        5 * time.Minute,
+		MaxConcurrency: 1,
+	})
+	register(jobs.AttendeeRepairJobType, jobs.NewAttendeeRepairHandler(serviceRegistry), jobsystem.HandlerOptions{
+		Timeout:        5 * time.Minute,
+		MaxConcurrency: 1,
+	})
 	calendarSyncHandler := jobs.NewCalendarSyncHandler(serviceRegistry, oauthService, googleClient)
-	jobSystem.Register("calendar_sync", calendarSyncHandler.Handle)
+	calendarSyncHandler.SetJobEnqueuer(jobSystem)
+	// Sync talks to external providers, so keep a tight bound on hung HTTP calls
+	register("calendar_sync", calendarSyncHandler.Handle, jobsystem.HandlerOptions{
+		Timeout:        3 * time.Minute,
+		MaxConcurrency: 2,
+	})
+
+	// Flags override the certificate settings in the config file
+	tlsConfig := appConfig.Server.TLS
+	if ctx.IsSet("tls-cert") || ctx.IsSet("tls-key") {
+		tlsConfig.CertFile, tlsConfig.KeyFile = ctx.String("tls-cert"), ctx.String("tls-key")
+		tlsConfig.AutocertDomains = nil
+	}
+	if domains := ctx.StringSlice("autocert-domain"); len(domains) > 0 {
+		tlsConfig.CertFile, tlsConfig.KeyFile = "", ""
+		tlsConfig.AutocertDomains = domains
+	}
+	if (tlsConfig.CertFile == "") != (tlsConfig.KeyFile == "") {
+		return fmt.Errorf("a TLS certificate needs both a certificate and a key file")
+	}
+
+	rpcPort := appConfig.Server.RPCPort
+	if ctx.IsSet("rpc-port") {
+		rpcPort = ctx.String("rpc-port")
+	}
 
 	// Create and start server
 	srv := server.New(serviceRegistry, jobSystem, authService, configManager, &server.Config{
-		Port: port,
-		Dev:  dev,
+		Port:    port,
+		Dev:     dev,
+		Chaos:   chaosInjector,
+		TLS:     tlsConfig,
+		RPCPort: rpcPort,
 	})
 
 	// Set up daily maintenance job scheduling
@@ -192,10 +375,135 @@ func startServer(ctx *cli.Context) error {
 		log.Println("Scheduled daily maintenance job")
 	}
 
+	// Look for nudges every morning and roll them up into a digest each Monday
+	err = jobSystem.Schedule(&jobsystem.ScheduleRequest{
+		Name:      "daily_suggestion_engine",
+		QueueName: "default",
+		JobType:   "suggestion_engine",
+		Payload:   map[string]interface{}{},
+		CronExpr:  "0 6 * * *", // Daily at 6am
+		Enabled:   true,
+	})
+	if err != nil {
+		log.Printf("Failed to schedule suggestion engine job: %v", err)
+	}
+	err = jobSystem.Schedule(&jobsystem.ScheduleRequest{
+		Name:      "weekly_suggestion_digest",
+		QueueName: "default",
+		JobType:   "suggestion_digest",
+		Payload:   map[string]interface{}{},
+		CronExpr:  "0 7 * * 1", // Mondays at 7am, after the engine has run
+		Enabled:   true,
+	})
+	if err != nil {
+		log.Printf("Failed to schedule suggestion digest job: %v", err)
+	}
+
+	// Keep the wall display's projection moving with the clock
+	err = jobSystem.Schedule(&jobsystem.ScheduleRequest{
+		Name:      "display_projection_refresh",
+		QueueName: "default",
+		JobType:   "display_projection_refresh",
+		Payload:   map[string]interface{}{},
+		CronExpr:  "*/5 * * * *", // Every five minutes
+		Enabled:   true,
+	})
+	if err != nil {
+		log.Printf("Failed to schedule display projection refresh job: %v", err)
+	}
+
+	// Plan event reminders every minute; each run looks a few minutes ahead
+	err = jobSystem.Schedule(&jobsystem.ScheduleRequest{
+		Name:      "reminder_planner",
+		QueueName: "reminders",
+		JobType:   "reminder_planner",
+		Payload:   map[string]interface{}{},
+		CronExpr:  "* * * * *", // Every minute
+		Enabled:   true,
+	})
+	if err != nil {
+		log.Printf("Failed to schedule reminder planner job: %v", err)
+	}
+
+	// Daily digests go out at each member's chosen time in their family's
+	// timezone, so the planner checks often rather than at one fixed hour
+	err = jobSystem.Schedule(&jobsystem.ScheduleRequest{
+		Name:      "daily_digest_planner",
+		QueueName: "default",
+		JobType:   "daily_digest_planner",
+		Payload:   map[string]interface{}{},
+		CronExpr:  "*/5 * * * *", // Every five minutes
+		Enabled:   true,
+	})
+	if err != nil {
+		log.Printf("Failed to schedule daily digest planner job: %v", err)
+	}
+
+	// Fetch subscribed calendar feeds; each has its own refresh interval, so
+	// the planner checks which are due
+	err = jobSystem.Schedule(&jobsystem.ScheduleRequest{
+		Name:      "ics_feed_planner",
+		QueueName: "default",
+		JobType:   jobs.FeedPlannerJobType,
+		Payload:   map[string]interface{}{},
+		CronExpr:  "*/15 * * * *", // Every fifteen minutes
+		Enabled:   true,
+	})
+	if err != nil {
+		log.Printf("Failed to schedule calendar feed planner job: %v", err)
+	}
+
+	// Refresh forecasts often enough that a family that just set its
+	// location sees the weather soon; each family is fetched only when its
+	// forecast has gone stale
+	err = jobSystem.Schedule(&jobsystem.ScheduleRequest{
+		Name:      "weather_refresh",
+		QueueName: "default",
+		JobType:   jobs.WeatherRefreshJobType,
+		Payload:   map[string]interface{}{},
+		CronExpr:  "*/15 * * * *", // Every fifteen minutes
+		Enabled:   appConfig.Weather.Enabled,
+	})
+	if err != nil {
+		log.Printf("Failed to schedule weather refresh job: %v", err)
+	}
+
+	// Keep birthdays and anniversaries on the calendar; member edits sync
+	// straight away, so once a day is enough
+	err = jobSystem.Schedule(&jobsystem.ScheduleRequest{
+		Name:      "celebrations_sync",
+		QueueName: "default",
+		JobType:   jobs.CelebrationsJobType,
+		Payload:   map[string]interface{}{},
+		CronExpr:  "30 3 * * *", // Daily at 3:30am
+		Enabled:   true,
+	})
+	if err != nil {
+		log.Printf("Failed to schedule celebrations sync job: %v", err)
+	}
+
+	// Roll over undone tasks; each family's day ends at its own midnight,
+	// so check every hour
+	err = jobSystem.Schedule(&jobsystem.ScheduleRequest{
+		Name:      "task_rollover",
+		QueueName: "default",
+		JobType:   jobs.TaskRolloverJobType,
+		Payload:   map[string]interface{}{},
+		CronExpr:  "5 * * * *", // Hourly at five past
+		Enabled:   true,
+	})
+	if err != nil {
+		log.Printf("Failed to schedule task rollover job: %v", err)
+	}
+
 	// Start job system
 	jobCtx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Edits to the config file take effect without a restart, for the
+	// settings that are read as they are used
+	go configManager.Watch(jobCtx, 5*time.Second)
+
 	go func() {
 		log.Println("Starting job system...")
 		if err := jobSystem.Start(jobCtx); err != nil {
@@ -223,11 +531,35 @@ func startServer(ctx *cli.Context) error {
 		} else {
 			log.Println("Enqueued startup maintenance check")
 		}
+
+		// Bring synced attendees into the form migration 054 describes;
+		// the key keeps later starts from running it again
+		repairKey := "synced_attendee_repair_054"
+		if _, err := jobSystem.EnqueueOnce(&jobsystem.EnqueueRequest{
+			QueueName:      "default",
+			JobType:        jobs.AttendeeRepairJobType,
+			Payload:        map[string]interface{}{},
+			MaxRetries:     3,
+			IdempotencyKey: &repairKey,
+		}); err != nil {
+			log.Printf("Failed to enqueue synced attendee repair job: %v", err)
+		}
 	}()
 
+	if appConfig.MQTT.Enabled {
+		go mqtt.NewBridge(appConfig.MQTT, serviceRegistry).Run(jobCtx)
+	}
+
 	// Start server in a goroutine
 	go func() {
-		log.Printf("Starting server on port %s", port)
+		if tlsConfig.Enabled() {
+			log.Printf("🔒 Starting HTTPS server on port %s", port)
+		} else {
+			log.Printf("Starting server on port %s", port)
+		}
+		if rpcPort != "" {
+			log.Printf("🔌 Serving the JSON-RPC API on port %s", rpcPort)
+		}
 		if err := srv.Start(); err != nil {
 			log.Fatalf("Server failed to start: %v", err)
 		}
@@ -255,5 +587,10 @@ func startServer(ctx *cli.Context) error {
 		log.Println("Server stopped gracefully")
 	}
 
+	// Flush spans from the last requests and jobs
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Printf("Tracing shutdown error: %v", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,95 @@
+package cmds
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"famstack/internal/config"
+	"famstack/internal/storage"
+)
+
+// StorageCommand returns the file storage command configuration
+func StorageCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "storage",
+		Usage: "Attachment storage commands",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "migrate",
+				Usage: "Copy stored files from one backend to another, e.g. local to s3",
+				Description: "Both backends are read from the storage section of the config file. " +
+					"Files already in the destination at the same size are skipped, so an interrupted " +
+					"migration can be run again. Switch storage.backend in the config once it finishes.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "config",
+						Value: "famstack-config.json",
+						Usage: "Config file path",
+					},
+					&cli.StringFlag{
+						Name:     "from",
+						Usage:    "Backend to copy from (local, s3)",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "to",
+						Usage:    "Backend to copy to (local, s3)",
+						Required: true,
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "List the files that would be copied without copying them",
+					},
+					&cli.BoolFlag{
+						Name:  "delete-source",
+						Usage: "Remove each file from the source backend once it is copied",
+					},
+				},
+				Action: migrateStorage,
+			},
+		},
+	}
+}
+
+// migrateStorage copies every object between the two named backends
+func migrateStorage(ctx *cli.Context) error {
+	from, to := ctx.String("from"), ctx.String("to")
+	if from == to {
+		return fmt.Errorf("--from and --to must name different backends")
+	}
+
+	configManager, err := config.NewManager(ctx.String("config"))
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg := configManager.GetConfig().Storage
+
+	src, err := storage.NewBackend(from, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to open source backend: %w", err)
+	}
+	dst, err := storage.NewBackend(to, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to open destination backend: %w", err)
+	}
+
+	fmt.Printf("Copying %s -> %s\n", src.Name(), dst.Name())
+	result, err := storage.Copy(ctx.Context, src, dst, storage.CopyOptions{
+		DryRun:       ctx.Bool("dry-run"),
+		DeleteSource: ctx.Bool("delete-source"),
+		Progress: func(key, action string) {
+			fmt.Printf("  %s: %s\n", key, action)
+		},
+	})
+
+	verb := "Copied"
+	if ctx.Bool("dry-run") {
+		verb = "Would copy"
+	}
+	fmt.Printf("\n%s %d files (%d bytes), skipped %d, failed %d\n", verb, result.Copied, result.Bytes, result.Skipped, result.Failed)
+	if err != nil {
+		return fmt.Errorf("storage migration incomplete: %w", err)
+	}
+	return nil
+}
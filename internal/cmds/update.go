@@ -4,7 +4,9 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"crypto/ed25519"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -20,17 +22,35 @@ import (
 
 // GitHubRelease represents a GitHub release
 type GitHubRelease struct {
-	TagName string `json:"tag_name"`
-	Name    string `json:"name"`
-	Assets  []struct {
-		Name               string `json:"name"`
-		BrowserDownloadURL string `json:"browser_download_url"`
-	} `json:"assets"`
-	PublishedAt time.Time `json:"published_at"`
+	TagName     string         `json:"tag_name"`
+	Name        string         `json:"name"`
+	Prerelease  bool           `json:"prerelease"`
+	Draft       bool           `json:"draft"`
+	Assets      []ReleaseAsset `json:"assets"`
+	PublishedAt time.Time      `json:"published_at"`
 }
 
+// ReleaseAsset is a file attached to a release
+type ReleaseAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// Release channels
+const (
+	ChannelStable = "stable" // full releases only
+	ChannelBeta   = "beta"   // pre-releases too, whichever is newest
+)
+
 // UpdateCommand returns the update command configuration
 func UpdateCommand() *cli.Command {
+	channelFlag := &cli.StringFlag{
+		Name:    "channel",
+		Value:   ChannelStable,
+		Usage:   "Release channel (stable, beta)",
+		EnvVars: []string{"FAMSTACK_UPDATE_CHANNEL"},
+	}
+
 	return &cli.Command{
 		Name:    "update",
 		Aliases: []string{"up"},
@@ -39,17 +59,52 @@ func UpdateCommand() *cli.Command {
 			{
 				Name:   "check",
 				Usage:  "Check for available updates",
+				Flags:  []cli.Flag{channelFlag},
 				Action: checkUpdate,
 			},
 			{
 				Name:   "install",
-				Usage:  "Install the latest version",
+				Usage:  "Back up the database and install the latest version",
 				Action: installUpdate,
 				Flags: []cli.Flag{
+					channelFlag,
 					&cli.BoolFlag{
 						Name:  "force",
 						Usage: "Force update even if already on latest version",
 					},
+					&cli.StringFlag{
+						Name:  "db",
+						Value: "famstack.db",
+						Usage: "Database file path or postgres:// URL to back up",
+					},
+					&cli.StringFlag{
+						Name:  "config",
+						Value: "famstack-config.json",
+						Usage: "Configuration file path, for database.url",
+					},
+					&cli.BoolFlag{
+						Name:  "no-backup",
+						Usage: "Don't back up the database first",
+					},
+					&cli.BoolFlag{
+						Name:  "skip-signature",
+						Usage: "Install without checking the release signature (development builds only)",
+					},
+				},
+			},
+			{
+				Name:   "rollback",
+				Usage:  "Go back to the version before the last update, restoring the database backup taken then",
+				Action: rollbackUpdate,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "keep-database",
+						Usage: "Restore only the binary, keeping the database as it is",
+					},
+					&cli.BoolFlag{
+						Name:  "yes",
+						Usage: "Don't ask for confirmation",
+					},
 				},
 			},
 			{
@@ -64,6 +119,11 @@ func UpdateCommand() *cli.Command {
 // Version is set at build time with -ldflags
 var Version = "development"
 
+// ReleasePublicKey is the base64 Ed25519 key release checksums are signed
+// with, set at build time with -ldflags. Builds without one can only install
+// updates with --skip-signature.
+var ReleasePublicKey = ""
+
 // getCurrentVersion returns the current version
 func getCurrentVersion() string {
 	return Version
@@ -76,12 +136,12 @@ func checkUpdate(c *cli.Context) error {
 	currentVersion := getCurrentVersion()
 	fmt.Printf("Current version: %s\n", currentVersion)
 
-	latest, err := getLatestRelease()
+	latest, err := getChannelRelease(c.String("channel"))
 	if err != nil {
 		return fmt.Errorf("failed to check for updates: %w", err)
 	}
 
-	fmt.Printf("Latest version: %s\n", latest.TagName)
+	fmt.Printf("Latest version: %s (%s)\n", latest.TagName, c.String("channel"))
 	fmt.Printf("Released: %s\n", latest.PublishedAt.Format("2006-01-02 15:04:05"))
 
 	if currentVersion == "development" {
@@ -99,15 +159,17 @@ func checkUpdate(c *cli.Context) error {
 	return nil
 }
 
-// installUpdate installs the latest version
+// installUpdate installs the latest version on the channel, keeping the
+// current binary and a database backup for 'update rollback'
 func installUpdate(c *cli.Context) error {
 	force := c.Bool("force")
+	channel := c.String("channel")
 
 	fmt.Println("Installing latest version...")
 
 	currentVersion := getCurrentVersion()
 
-	latest, err := getLatestRelease()
+	latest, err := getChannelRelease(channel)
 	if err != nil {
 		return fmt.Errorf("failed to get latest release: %w", err)
 	}
@@ -125,29 +187,17 @@ func installUpdate(c *cli.Context) error {
 
 	fmt.Printf("Downloading %s...\n", asset.Name)
 
-	// Download the asset
-	resp, err := http.Get(asset.BrowserDownloadURL)
+	bodyBytes, err := downloadAsset(asset.BrowserDownloadURL)
 	if err != nil {
 		return fmt.Errorf("failed to download: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status: %s", resp.Status)
-	}
 
-	// Read the entire response body
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read download: %w", err)
+	// Verify checksum, and the signature over the checksums
+	fmt.Println("Verifying signature and checksum...")
+	if checksumErr := verifyChecksum(latest, asset.Name, bodyBytes, c.Bool("skip-signature")); checksumErr != nil {
+		return fmt.Errorf("verification failed: %w", checksumErr)
 	}
-
-	// Verify checksum
-	fmt.Println("Verifying checksum...")
-	if checksumErr := verifyChecksum(latest, asset.Name, bodyBytes); checksumErr != nil {
-		return fmt.Errorf("checksum verification failed: %w", checksumErr)
-	}
-	fmt.Println("✅ Checksum verified")
+	fmt.Println("✅ Signature and checksum verified")
 
 	// Get current executable path
 	execPath, err := os.Executable()
@@ -155,9 +205,21 @@ func installUpdate(c *cli.Context) error {
 		return fmt.Errorf("failed to get executable path: %w", err)
 	}
 
-	// Create backup
-	backupPath := execPath + ".backup"
-	if err := copyFile(execPath, backupPath); err != nil {
+	record := &updateRecord{
+		FromVersion:    currentVersion,
+		ToVersion:      latest.TagName,
+		Channel:        channel,
+		PreviousBinary: execPath + ".previous",
+		UpdatedAt:      time.Now().UTC(),
+	}
+	if !c.Bool("no-backup") {
+		if err := backupBeforeUpdate(c, record); err != nil {
+			return err
+		}
+	}
+
+	// Keep the current binary for rollback
+	if err := copyFile(execPath, record.PreviousBinary); err != nil {
 		return fmt.Errorf("failed to create backup: %w", err)
 	}
 
@@ -166,17 +228,19 @@ func installUpdate(c *cli.Context) error {
 	// Extract and install the new binary
 	if err := extractAndInstall(bytes.NewReader(bodyBytes), execPath, asset.Name); err != nil {
 		// Restore backup on failure
-		if restoreErr := os.Rename(backupPath, execPath); restoreErr != nil {
+		if restoreErr := os.Rename(record.PreviousBinary, execPath); restoreErr != nil {
 			return fmt.Errorf("failed to install and failed to restore backup: install error: %w, restore error: %v", err, restoreErr)
 		}
 		return fmt.Errorf("failed to install: %w", err)
 	}
 
-	// Remove backup on success
-	os.Remove(backupPath)
+	if err := record.save(execPath); err != nil {
+		fmt.Printf("⚠️  Installed, but 'update rollback' won't know about it: %v\n", err)
+	}
 
 	fmt.Printf("✅ Successfully updated to %s\n", latest.TagName)
 	fmt.Println("Restart FamStack to use the new version")
+	fmt.Printf("💡 Run 'famstack update rollback' to go back to %s\n", currentVersion)
 
 	return nil
 }
@@ -188,9 +252,41 @@ func showVersion(c *cli.Context) error {
 	return nil
 }
 
-// getLatestRelease fetches the latest release from GitHub
+// getChannelRelease fetches the newest release on a channel
+func getChannelRelease(channel string) (*GitHubRelease, error) {
+	switch channel {
+	case ChannelStable:
+		return getLatestRelease()
+	case ChannelBeta:
+		releases, err := getReleases()
+		if err != nil {
+			return nil, err
+		}
+		return pickRelease(releases, channel)
+	default:
+		return nil, fmt.Errorf("unknown channel %q (expected stable or beta)", channel)
+	}
+}
+
+// pickRelease returns the newest release on the channel from a list GitHub
+// gives newest first
+func pickRelease(releases []GitHubRelease, channel string) (*GitHubRelease, error) {
+	for i := range releases {
+		release := &releases[i]
+		if release.Draft || (release.Prerelease && channel != ChannelBeta) {
+			continue
+		}
+		return release, nil
+	}
+	return nil, fmt.Errorf("no %s releases found", channel)
+}
+
+// releasesURL is where FamStack's releases are published
+const releasesURL = "https://api.github.com/repos/chrisrob11/famstack/releases"
+
+// getLatestRelease fetches the latest full release from GitHub
 func getLatestRelease() (*GitHubRelease, error) {
-	url := "https://api.github.com/repos/chrisrob11/famstack/releases/latest"
+	url := releasesURL + "/latest"
 
 	resp, err := http.Get(url)
 	if err != nil {
@@ -213,11 +309,52 @@ func getLatestRelease() (*GitHubRelease, error) {
 	return &release, nil
 }
 
+// getReleases fetches the most recent releases, pre-releases included,
+// newest first
+func getReleases() ([]GitHubRelease, error) {
+	resp, err := http.Get(releasesURL + "?per_page=20")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API returned status: %s", resp.Status)
+	}
+
+	var releases []GitHubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
+// downloadAsset fetches a release asset
+func downloadAsset(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed with status: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// findReleaseAsset returns the release's asset with the given name
+func findReleaseAsset(release *GitHubRelease, name string) *ReleaseAsset {
+	for i := range release.Assets {
+		if release.Assets[i].Name == name {
+			return &release.Assets[i]
+		}
+	}
+	return nil
+}
+
 // findAssetForPlatform finds the appropriate asset for the current platform
-func findAssetForPlatform(release *GitHubRelease) (*struct {
-	Name               string `json:"name"`
-	BrowserDownloadURL string `json:"browser_download_url"`
-}, error) {
+func findAssetForPlatform(release *GitHubRelease) (*ReleaseAsset, error) {
 	platform := runtime.GOOS
 	arch := runtime.GOARCH
 
@@ -227,10 +364,10 @@ func findAssetForPlatform(release *GitHubRelease) (*struct {
 	}
 
 	// Look for matching asset
-	for _, asset := range release.Assets {
+	for i, asset := range release.Assets {
 		name := strings.ToLower(asset.Name)
 		if strings.Contains(name, platform) && strings.Contains(name, arch) {
-			return &asset, nil
+			return &release.Assets[i], nil
 		}
 	}
 
@@ -341,35 +478,33 @@ func copyFile(src, dst string) error {
 	return os.Chmod(dst, srcInfo.Mode())
 }
 
-// verifyChecksum downloads checksums.txt and verifies the binary checksum
-func verifyChecksum(release *GitHubRelease, assetName string, data []byte) error {
-	// Find checksums.txt in the release assets
-	var checksumsURL string
-	for _, asset := range release.Assets {
-		if asset.Name == "checksums.txt" {
-			checksumsURL = asset.BrowserDownloadURL
-			break
-		}
-	}
-
-	if checksumsURL == "" {
+// verifyChecksum downloads checksums.txt, checks it was signed with the
+// release key, and verifies the binary checksum against it
+func verifyChecksum(release *GitHubRelease, assetName string, data []byte, skipSignature bool) error {
+	checksums := findReleaseAsset(release, "checksums.txt")
+	if checksums == nil {
 		return fmt.Errorf("checksums.txt not found in release assets")
 	}
 
-	// Download checksums.txt
-	resp, err := http.Get(checksumsURL)
+	checksumsContent, err := downloadAsset(checksums.BrowserDownloadURL)
 	if err != nil {
 		return fmt.Errorf("failed to download checksums.txt: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download checksums.txt: %s", resp.Status)
-	}
-
-	checksumsContent, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read checksums.txt: %w", err)
+	if skipSignature {
+		fmt.Println("⚠️  Not checking the release signature")
+	} else {
+		signature := findReleaseAsset(release, "checksums.txt.sig")
+		if signature == nil {
+			return fmt.Errorf("checksums.txt.sig not found in release assets; the release isn't signed")
+		}
+		signatureContent, err := downloadAsset(signature.BrowserDownloadURL)
+		if err != nil {
+			return fmt.Errorf("failed to download checksums.txt.sig: %w", err)
+		}
+		if err := verifySignature(ReleasePublicKey, checksumsContent, signatureContent); err != nil {
+			return err
+		}
 	}
 
 	// Parse checksums.txt to find our asset
@@ -391,6 +526,26 @@ func verifyChecksum(release *GitHubRelease, assetName string, data []byte) error
 	return nil
 }
 
+// verifySignature checks a base64 Ed25519 signature over message against a
+// base64 public key
+func verifySignature(publicKey string, message, signature []byte) error {
+	if publicKey == "" {
+		return fmt.Errorf("this build has no release key to check signatures with; install a release build, or pass --skip-signature")
+	}
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("this build's release key is not a base64 Ed25519 public key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("checksums.txt.sig is not base64: %w", err)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), message, sig) {
+		return fmt.Errorf("checksums.txt was not signed with the release key")
+	}
+	return nil
+}
+
 // parseChecksum extracts the expected checksum for a specific file from checksums.txt
 func parseChecksum(checksumsContent, filename string) (string, error) {
 	lines := strings.Split(checksumsContent, "\n")
@@ -0,0 +1,186 @@
+package cmds
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"famstack/internal/config"
+	"famstack/internal/database"
+)
+
+// updateRecord remembers the last update, next to the binary, so
+// 'update rollback' can undo it
+type updateRecord struct {
+	FromVersion    string    `json:"from_version"`
+	ToVersion      string    `json:"to_version"`
+	Channel        string    `json:"channel"`
+	PreviousBinary string    `json:"previous_binary"`
+	Database       string    `json:"database,omitempty"`
+	DatabaseBackup string    `json:"database_backup,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func updateRecordPath(execPath string) string {
+	return execPath + ".update.json"
+}
+
+func (r *updateRecord) save(execPath string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(updateRecordPath(execPath), data, 0600)
+}
+
+func loadUpdateRecord(execPath string) (*updateRecord, error) {
+	data, err := os.ReadFile(updateRecordPath(execPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no update to roll back; 'update install' records one")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read update record: %w", err)
+	}
+	var record updateRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse update record: %w", err)
+	}
+	return &record, nil
+}
+
+// backupBeforeUpdate copies a SQLite database aside before the new version
+// migrates it, noting the copy in the record. The database is the one in the
+// config file unless --db is given, as for 'famstack start'.
+func backupBeforeUpdate(c *cli.Context, record *updateRecord) error {
+	dbPath := c.String("db")
+	if !c.IsSet("db") {
+		if configManager, err := config.NewManager(c.String("config")); err == nil {
+			if url := configManager.GetConfig().Database.URL; url != "" {
+				dbPath = url
+			}
+		}
+	}
+
+	if database.DialectFor(dbPath) != database.DialectSQLite {
+		fmt.Println("ℹ️  Not backing up a PostgreSQL database; take a pg_dump first if you need one")
+		return nil
+	}
+	if _, err := os.Stat(dbPath); err != nil {
+		return fmt.Errorf("database %s not found; pass --db, or --no-backup to update without a backup", dbPath)
+	}
+
+	absPath, err := filepath.Abs(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve database path: %w", err)
+	}
+	db, err := database.New(absPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer db.Close()
+
+	backupPath := fmt.Sprintf("%s.%s.bak", absPath, time.Now().Format("20060102-150405"))
+	if err := db.Backup(backupPath); err != nil {
+		return err
+	}
+	record.Database, record.DatabaseBackup = absPath, backupPath
+	fmt.Printf("💾 Backed up the database to %s\n", backupPath)
+	return nil
+}
+
+// rollbackUpdate puts back the binary from before the last update and, unless
+// --keep-database, the database backup taken then. The server must be
+// stopped, since the database file is replaced underneath it.
+func rollbackUpdate(c *cli.Context) error {
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+	record, err := loadUpdateRecord(execPath)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(record.PreviousBinary); err != nil {
+		return fmt.Errorf("the previous binary %s is gone; reinstall %s by hand", record.PreviousBinary, record.FromVersion)
+	}
+
+	restoreDB := record.DatabaseBackup != "" && !c.Bool("keep-database")
+	if restoreDB {
+		if _, err := os.Stat(record.DatabaseBackup); err != nil {
+			return fmt.Errorf("the database backup %s is gone; pass --keep-database to restore only the binary", record.DatabaseBackup)
+		}
+	}
+
+	fmt.Printf("Rolling back %s → %s, installed %s\n", record.ToVersion, record.FromVersion, record.UpdatedAt.Local().Format("2006-01-02 15:04"))
+	if restoreDB {
+		fmt.Printf("The database will be restored from %s; changes made since the update are lost.\n", record.DatabaseBackup)
+		fmt.Println("Stop FamStack before going on.")
+	}
+	if !c.Bool("yes") {
+		fmt.Print("Continue? (y/N): ")
+		reader := bufio.NewReader(os.Stdin)
+		response, readErr := reader.ReadString('\n')
+		if readErr != nil {
+			return fmt.Errorf("failed to read confirmation: %w", readErr)
+		}
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Rollback cancelled.")
+			return nil
+		}
+	}
+
+	if restoreDB {
+		if err := restoreDatabaseBackup(record.Database, record.DatabaseBackup); err != nil {
+			return err
+		}
+	} else if record.DatabaseBackup == "" && !c.Bool("keep-database") {
+		fmt.Println("ℹ️  No database backup was taken with the update; if it migrated a PostgreSQL database, restore your pg_dump")
+	}
+
+	if err := os.Rename(record.PreviousBinary, execPath); err != nil {
+		return fmt.Errorf("failed to restore the previous binary: %w", err)
+	}
+	if err := os.Remove(updateRecordPath(execPath)); err != nil {
+		fmt.Printf("⚠️  Rolled back, but couldn't remove the update record: %v\n", err)
+	}
+
+	fmt.Printf("✅ Rolled back to %s\n", record.FromVersion)
+	fmt.Println("Start FamStack again to use it")
+	return nil
+}
+
+// restoreDatabaseBackup replaces a SQLite database with a backup, keeping a
+// copy of what it replaces
+func restoreDatabaseBackup(dbPath, backupPath string) error {
+	db, err := database.New(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	replacedPath := fmt.Sprintf("%s.%s.before-rollback.bak", dbPath, time.Now().Format("20060102-150405"))
+	err = db.Backup(replacedPath)
+	db.Close()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("💾 Kept the current database as %s\n", replacedPath)
+
+	// The write-ahead log belongs to the database being replaced
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s%s: %w", dbPath, suffix, err)
+		}
+	}
+	if err := copyFile(backupPath, dbPath); err != nil {
+		return fmt.Errorf("failed to restore the database: %w", err)
+	}
+	fmt.Printf("💾 Restored the database from %s\n", backupPath)
+	return nil
+}
@@ -0,0 +1,46 @@
+package cmds
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Signed the way the release workflow signs checksums.txt:
+// openssl pkeyutl -sign -rawin ... | base64 -w0
+const (
+	testReleasePublicKey = "EnlzqS8vdowR32TfbM6VmZZfCgvzWk76BdKTfR0bCco="
+	testChecksums        = "abc  file\n"
+	testSignature        = "7UCrnOLDq/ezdRe3lrGToYdi/2cFdscLIA11fXw+ILtsZeO5ruk0Cyivu/YrVfEz6SNKfsZE0gVXh2EEwKTnBw=="
+)
+
+func TestVerifySignature(t *testing.T) {
+	require.NoError(t, verifySignature(testReleasePublicKey, []byte(testChecksums), []byte(testSignature+"\n")))
+
+	assert.ErrorContains(t, verifySignature(testReleasePublicKey, []byte("abd  file\n"), []byte(testSignature)),
+		"not signed with the release key")
+	assert.ErrorContains(t, verifySignature("", []byte(testChecksums), []byte(testSignature)), "--skip-signature")
+	assert.ErrorContains(t, verifySignature("c2hvcnQ=", []byte(testChecksums), []byte(testSignature)), "not a base64 Ed25519 public key")
+	assert.ErrorContains(t, verifySignature(testReleasePublicKey, []byte(testChecksums), []byte("%%%")), "not base64")
+}
+
+func TestPickRelease(t *testing.T) {
+	releases := []GitHubRelease{
+		{TagName: "v1.3.0-draft", Draft: true},
+		{TagName: "v1.3.0-beta.1", Prerelease: true},
+		{TagName: "v1.2.4"},
+		{TagName: "v1.2.3"},
+	}
+
+	stable, err := pickRelease(releases, ChannelStable)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.4", stable.TagName)
+
+	beta, err := pickRelease(releases, ChannelBeta)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.3.0-beta.1", beta.TagName)
+
+	_, err = pickRelease(releases[:2], ChannelStable)
+	assert.EqualError(t, err, "no stable releases found")
+}
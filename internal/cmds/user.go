@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/urfave/cli/v2"
 	"golang.org/x/term"
@@ -57,19 +58,27 @@ func UserCommand() *cli.Command {
 					&cli.StringFlag{
 						Name:  "db",
 						Value: "famstack.db",
-						Usage: "Database file path",
+						Usage: "Database file path or postgres:// URL",
 					},
 				},
 				Action: createUser,
 			},
 			{
 				Name:  "list",
-				Usage: "List all users",
+				Usage: "List the members with a password",
 				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "Also list members without a password, such as children and pets",
+					},
+					&cli.StringFlag{
+						Name:  "family-id",
+						Usage: "Only list this family's members",
+					},
 					&cli.StringFlag{
 						Name:  "db",
 						Value: "famstack.db",
-						Usage: "Database file path",
+						Usage: "Database file path or postgres:// URL",
 					},
 				},
 				Action: listUsers,
@@ -86,7 +95,7 @@ func UserCommand() *cli.Command {
 					&cli.StringFlag{
 						Name:  "db",
 						Value: "famstack.db",
-						Usage: "Database file path",
+						Usage: "Database file path or postgres:// URL",
 					},
 				},
 				Action: deleteUser,
@@ -107,15 +116,95 @@ func UserCommand() *cli.Command {
 					&cli.StringFlag{
 						Name:  "db",
 						Value: "famstack.db",
-						Usage: "Database file path",
+						Usage: "Database file path or postgres:// URL",
 					},
 				},
 				Action: resetPassword,
 			},
+			{
+				Name:  "set-role",
+				Usage: "Promote or demote a member (shared, user, admin)",
+				Flags: []cli.Flag{
+					memberEmailFlag(),
+					memberIDFlag(),
+					&cli.StringFlag{
+						Name:     "role",
+						Usage:    "New role (shared, user, admin)",
+						Required: true,
+					},
+					userDBFlag(),
+				},
+				Action: setUserRole,
+			},
+			{
+				Name:  "deactivate",
+				Usage: "Stop a member signing in and revoke their API tokens, keeping their history",
+				Flags: []cli.Flag{
+					memberEmailFlag(),
+					memberIDFlag(),
+					userDBFlag(),
+				},
+				Action: deactivateUser,
+			},
+			{
+				Name:  "reactivate",
+				Usage: "Let a deactivated member sign in again",
+				Flags: []cli.Flag{
+					memberEmailFlag(),
+					memberIDFlag(),
+					userDBFlag(),
+				},
+				Action: reactivateUser,
+			},
+			{
+				Name:  "link-email",
+				Usage: "Give an existing member an email and password so they can sign in",
+				Flags: []cli.Flag{
+					memberIDFlag(),
+					&cli.StringFlag{
+						Name:     "email",
+						Usage:    "Email address to sign in with",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "role",
+						Usage: "Role if the member doesn't have one yet (shared, user, admin)",
+						Value: string(auth.RoleUser),
+					},
+					&cli.StringFlag{
+						Name:  "password",
+						Usage: "Password (WARNING: visible in process list)",
+					},
+					userDBFlag(),
+				},
+				Action: linkUserEmail,
+			},
 		},
 	}
 }
 
+func userDBFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "db",
+		Value: "famstack.db",
+		Usage: "Database file path or postgres:// URL",
+	}
+}
+
+func memberEmailFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "email",
+		Usage: "Member's email address",
+	}
+}
+
+func memberIDFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "id",
+		Usage: "Member ID, or the start of one as shown by 'user list --all'",
+	}
+}
+
 func createUser(ctx *cli.Context) error {
 	dbPath := ctx.String("db")
 
@@ -176,25 +265,8 @@ func createUser(ctx *cli.Context) error {
 	// Get password
 	password := ctx.String("password")
 	if password == "" {
-		// Get password securely
-		fmt.Print("Enter password: ")
-		passwordBytes, pwdErr := term.ReadPassword(int(syscall.Stdin))
-		if pwdErr != nil {
-			return fmt.Errorf("failed to read password: %w", pwdErr)
-		}
-		password = string(passwordBytes)
-		fmt.Println() // New line after password input
-
-		fmt.Print("Confirm password: ")
-		confirmBytes, confirmErr := term.ReadPassword(int(syscall.Stdin))
-		if confirmErr != nil {
-			return fmt.Errorf("failed to read password confirmation: %w", confirmErr)
-		}
-		confirm := string(confirmBytes)
-		fmt.Println() // New line after password input
-
-		if password != confirm {
-			return fmt.Errorf("passwords do not match")
+		if password, err = readNewPassword("password"); err != nil {
+			return err
 		}
 	}
 
@@ -240,27 +312,35 @@ func listUsers(ctx *cli.Context) error {
 	}
 	defer db.Close()
 
-	// Query family members with auth info
+	// Query family members, by default only those with auth info
+	conditions, args := []string{"password_hash IS NOT NULL"}, []any{}
+	if ctx.Bool("all") {
+		conditions = []string{"1 = 1"}
+	}
+	if familyID := ctx.String("family-id"); familyID != "" {
+		conditions, args = append(conditions, "family_id = ?"), append(args, familyID)
+	}
 	rows, err := db.Query(`
-		SELECT id, email, first_name, last_name, member_type, role, family_id, created_at
+		SELECT id, email, first_name, last_name, member_type, role, family_id, is_active, created_at
 		FROM family_members
-		WHERE password_hash IS NOT NULL
+		WHERE `+strings.Join(conditions, " AND ")+`
 		ORDER BY created_at DESC
-	`)
+	`, args...)
 	if err != nil {
 		return fmt.Errorf("failed to query family members: %w", err)
 	}
 	defer rows.Close()
 
-	fmt.Printf("%-8s %-25s %-20s %-10s %-8s %-8s %-20s\n",
-		"ID", "Email", "Name", "Type", "Role", "Family", "Created At")
-	fmt.Println(strings.Repeat("-", 100))
+	fmt.Printf("%-8s %-25s %-20s %-10s %-8s %-8s %-8s %-20s\n",
+		"ID", "Email", "Name", "Type", "Role", "Family", "Active", "Created At")
+	fmt.Println(strings.Repeat("-", 109))
 
 	for rows.Next() {
 		var id, familyID, createdAt, firstName, lastName, memberType string
 		var email, role sql.NullString
+		var active bool
 
-		err := rows.Scan(&id, &email, &firstName, &lastName, &memberType, &role, &familyID, &createdAt)
+		err := rows.Scan(&id, &email, &firstName, &lastName, &memberType, &role, &familyID, &active, &createdAt)
 		if err != nil {
 			return fmt.Errorf("failed to scan family member: %w", err)
 		}
@@ -290,8 +370,13 @@ func listUsers(ctx *cli.Context) error {
 			fullName = fullName[:17] + "..."
 		}
 
-		fmt.Printf("%-8s %-25s %-20s %-10s %-8s %-8s %-20s\n",
-			id, emailStr, fullName, memberType, roleStr, familyID, createdAt)
+		activeStr := "yes"
+		if !active {
+			activeStr = "no"
+		}
+
+		fmt.Printf("%-8s %-25s %-20s %-10s %-8s %-8s %-8s %-20s\n",
+			id, emailStr, fullName, memberType, roleStr, familyID, activeStr, createdAt)
 	}
 
 	return nil
@@ -366,25 +451,8 @@ func resetPassword(ctx *cli.Context) error {
 	// Get new password
 	password := ctx.String("password")
 	if password == "" {
-		// Get password securely
-		fmt.Print("Enter new password: ")
-		passwordBytes, pwdErr := term.ReadPassword(int(syscall.Stdin))
-		if pwdErr != nil {
-			return fmt.Errorf("failed to read password: %w", pwdErr)
-		}
-		password = string(passwordBytes)
-		fmt.Println() // New line after password input
-
-		fmt.Print("Confirm new password: ")
-		confirmBytes, confirmErr := term.ReadPassword(int(syscall.Stdin))
-		if confirmErr != nil {
-			return fmt.Errorf("failed to read password confirmation: %w", confirmErr)
-		}
-		confirm := string(confirmBytes)
-		fmt.Println() // New line after password input
-
-		if password != confirm {
-			return fmt.Errorf("passwords do not match")
+		if password, err = readNewPassword("new password"); err != nil {
+			return err
 		}
 	}
 
@@ -412,6 +480,297 @@ func resetPassword(ctx *cli.Context) error {
 		return fmt.Errorf("no user found with email '%s'", email)
 	}
 
+	// Lift any lockout from the failed attempts that led here
+	if _, err := db.Exec("DELETE FROM login_lockouts WHERE kind = ? AND subject = ?",
+		auth.LockoutEmail, strings.ToLower(strings.TrimSpace(email))); err != nil {
+		return fmt.Errorf("failed to clear lockout: %w", err)
+	}
+
 	fmt.Printf("✅ Password reset successfully for user '%s'\n", email)
 	return nil
 }
+
+// readNewPassword prompts for a password twice without echoing it
+func readNewPassword(name string) (string, error) {
+	fmt.Printf("Enter %s: ", name)
+	passwordBytes, err := term.ReadPassword(int(syscall.Stdin))
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	fmt.Println() // New line after password input
+
+	fmt.Printf("Confirm %s: ", name)
+	confirmBytes, err := term.ReadPassword(int(syscall.Stdin))
+	if err != nil {
+		return "", fmt.Errorf("failed to read password confirmation: %w", err)
+	}
+	fmt.Println() // New line after password input
+
+	if string(passwordBytes) != string(confirmBytes) {
+		return "", fmt.Errorf("passwords do not match")
+	}
+	return string(passwordBytes), nil
+}
+
+// cliMember is the part of a family member the member commands work with
+type cliMember struct {
+	ID       string
+	FamilyID string
+	Name     string
+	Email    sql.NullString
+	Role     sql.NullString
+	Active   bool
+}
+
+// label names the member in command output
+func (m *cliMember) label() string {
+	if m.Email.Valid {
+		return fmt.Sprintf("%s <%s>", m.Name, m.Email.String)
+	}
+	return fmt.Sprintf("%s (%s)", m.Name, m.ID)
+}
+
+// findMember looks up a member by ID, which may be the shortened ID that
+// 'user list' prints, or else by email
+func findMember(db *database.Fascade, email, id string) (*cliMember, error) {
+	query := `
+		SELECT id, family_id, first_name || ' ' || last_name, email, role, is_active
+		FROM family_members
+		WHERE `
+	var arg string
+	switch {
+	case id != "":
+		query, arg = query+"id LIKE ?", strings.ReplaceAll(id, "%", "")+"%"
+	case email != "":
+		query, arg = query+"lower(email) = ?", strings.ToLower(strings.TrimSpace(email))
+	default:
+		return nil, fmt.Errorf("pass --email or --id to choose the member")
+	}
+
+	rows, err := db.Query(query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query family member: %w", err)
+	}
+	defer rows.Close()
+
+	var members []cliMember
+	for rows.Next() {
+		var member cliMember
+		if err := rows.Scan(&member.ID, &member.FamilyID, &member.Name, &member.Email, &member.Role, &member.Active); err != nil {
+			return nil, fmt.Errorf("failed to scan family member: %w", err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read family members: %w", err)
+	}
+
+	switch len(members) {
+	case 0:
+		return nil, fmt.Errorf("no family member found")
+	case 1:
+		return &members[0], nil
+	default:
+		return nil, fmt.Errorf("%d family members have IDs starting %q; give more of the ID", len(members), id)
+	}
+}
+
+// checkOtherAdmin refuses a change that would leave the member's family
+// without an active admin to manage it
+func checkOtherAdmin(db *database.Fascade, member *cliMember) error {
+	if member.Role.String != string(auth.RoleAdmin) || !member.Active {
+		return nil
+	}
+	var admins int
+	if err := db.QueryRow(`
+		SELECT COUNT(*) FROM family_members
+		WHERE family_id = ? AND id <> ? AND role = ? AND is_active = TRUE AND password_hash IS NOT NULL
+	`, member.FamilyID, member.ID, string(auth.RoleAdmin)).Scan(&admins); err != nil {
+		return fmt.Errorf("failed to count admins: %w", err)
+	}
+	if admins == 0 {
+		return fmt.Errorf("%s is the family's only admin; promote someone else first", member.label())
+	}
+	return nil
+}
+
+func setUserRole(ctx *cli.Context) error {
+	role := auth.Role(ctx.String("role"))
+	if role != auth.RoleShared && role != auth.RoleUser && role != auth.RoleAdmin {
+		return fmt.Errorf("unknown role %q (expected shared, user or admin)", role)
+	}
+
+	db, err := database.New(ctx.String("db"))
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer db.Close()
+
+	member, err := findMember(db, ctx.String("email"), ctx.String("id"))
+	if err != nil {
+		return err
+	}
+	if member.Role.String == string(role) {
+		fmt.Printf("%s is already %s\n", member.label(), role)
+		return nil
+	}
+	if role != auth.RoleAdmin {
+		if err := checkOtherAdmin(db, member); err != nil {
+			return err
+		}
+	}
+
+	if _, err := db.Exec("UPDATE family_members SET role = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", string(role), member.ID); err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+
+	fmt.Printf("✅ %s is now %s (was %s)\n", member.label(), role, stringOr(member.Role, "none"))
+	fmt.Println("💡 Sessions already signed in keep their old role until they sign in again")
+	return nil
+}
+
+func deactivateUser(ctx *cli.Context) error {
+	db, err := database.New(ctx.String("db"))
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer db.Close()
+
+	member, err := findMember(db, ctx.String("email"), ctx.String("id"))
+	if err != nil {
+		return err
+	}
+	if !member.Active {
+		fmt.Printf("%s is already deactivated\n", member.label())
+		return nil
+	}
+	if err := checkOtherAdmin(db, member); err != nil {
+		return err
+	}
+
+	// Confirm deactivation
+	fmt.Printf("Deactivate %s? They won't be able to sign in. (y/N): ", member.label())
+	reader := bufio.NewReader(os.Stdin)
+	response, readErr := reader.ReadString('\n')
+	if readErr != nil {
+		return fmt.Errorf("failed to read confirmation: %w", readErr)
+	}
+	response = strings.TrimSpace(strings.ToLower(response))
+	if response != "y" && response != "yes" {
+		fmt.Println("Deactivation cancelled.")
+		return nil
+	}
+
+	revoked := int64(0)
+	err = db.InTx(ctx.Context, func(tx *sql.Tx) error {
+		if _, err := tx.Exec("UPDATE family_members SET is_active = FALSE, updated_at = CURRENT_TIMESTAMP WHERE id = ?", member.ID); err != nil {
+			return fmt.Errorf("failed to deactivate family member: %w", err)
+		}
+		result, err := tx.Exec("UPDATE api_tokens SET revoked_at = ? WHERE member_id = ? AND revoked_at IS NULL", time.Now().UTC(), member.ID)
+		if err != nil {
+			return fmt.Errorf("failed to revoke API tokens: %w", err)
+		}
+		revoked, _ = result.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ %s deactivated; %d API token(s) revoked\n", member.label(), revoked)
+	fmt.Println("💡 Sessions already signed in end within 4 hours, when they would next refresh")
+	return nil
+}
+
+func reactivateUser(ctx *cli.Context) error {
+	db, err := database.New(ctx.String("db"))
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer db.Close()
+
+	member, err := findMember(db, ctx.String("email"), ctx.String("id"))
+	if err != nil {
+		return err
+	}
+	if member.Active {
+		fmt.Printf("%s is already active\n", member.label())
+		return nil
+	}
+
+	if _, err := db.Exec("UPDATE family_members SET is_active = TRUE, updated_at = CURRENT_TIMESTAMP WHERE id = ?", member.ID); err != nil {
+		return fmt.Errorf("failed to reactivate family member: %w", err)
+	}
+
+	fmt.Printf("✅ %s reactivated\n", member.label())
+	return nil
+}
+
+func linkUserEmail(ctx *cli.Context) error {
+	if ctx.String("id") == "" {
+		return fmt.Errorf("pass --id to choose the member; 'user list --all' shows IDs")
+	}
+	email := strings.ToLower(strings.TrimSpace(ctx.String("email")))
+	if !strings.Contains(email, "@") {
+		return fmt.Errorf("%q is not an email address", ctx.String("email"))
+	}
+
+	db, err := database.New(ctx.String("db"))
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer db.Close()
+
+	member, err := findMember(db, "", ctx.String("id"))
+	if err != nil {
+		return err
+	}
+
+	var taken int
+	if err := db.QueryRow("SELECT COUNT(*) FROM family_members WHERE lower(email) = ? AND id <> ?", email, member.ID).Scan(&taken); err != nil {
+		return fmt.Errorf("failed to check email: %w", err)
+	}
+	if taken > 0 {
+		return fmt.Errorf("another family member already signs in with %s", email)
+	}
+
+	role := member.Role.String
+	if role == "" || ctx.IsSet("role") {
+		role = ctx.String("role")
+	}
+	if r := auth.Role(role); r != auth.RoleShared && r != auth.RoleUser && r != auth.RoleAdmin {
+		return fmt.Errorf("unknown role %q (expected shared, user or admin)", role)
+	}
+
+	password := ctx.String("password")
+	if password == "" {
+		if password, err = readNewPassword("password for " + email); err != nil {
+			return err
+		}
+	}
+	hashedPassword, err := auth.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if _, err := db.Exec(`
+		UPDATE family_members
+		SET email = ?, email_verified = TRUE, password_hash = ?, role = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, email, hashedPassword, role, member.ID); err != nil {
+		return fmt.Errorf("failed to link email: %w", err)
+	}
+
+	fmt.Printf("✅ %s can now sign in as %s (%s)\n", member.Name, email, role)
+	if !member.Active {
+		fmt.Println("💡 The member is deactivated; run 'famstack user reactivate' to let them sign in")
+	}
+	return nil
+}
+
+func stringOr(value sql.NullString, fallback string) string {
+	if value.Valid && value.String != "" {
+		return value.String
+	}
+	return fallback
+}
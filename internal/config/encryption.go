@@ -1,6 +1,9 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+)
 
 // EncryptionSettings holds configuration for encryption providers
 type EncryptionSettings struct {
@@ -71,8 +74,13 @@ func (kc *KeyringConfig) GetAvailableKeys() []string {
 	return available
 }
 
-// DefaultEncryptionSettings returns default configuration
+// DefaultEncryptionSettings returns default configuration: the key in
+// FAMSTACK_FIXED_KEY_VALUE when it is set, for services that run without a
+// login keyring, otherwise the system keyring
 func DefaultEncryptionSettings() *EncryptionSettings {
+	if value := os.Getenv("FAMSTACK_FIXED_KEY_VALUE"); value != "" {
+		return &EncryptionSettings{FixedKey: &FixedKeyConfig{Value: value}}
+	}
 	return &EncryptionSettings{
 		Keyring: &KeyringConfig{
 			Service: "famstack",
@@ -1,27 +1,92 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"sync"
+	"time"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Version  string        `json:"version"`
-	Server   ServerConfig  `json:"server"`
-	OAuth    OAuthConfig   `json:"oauth"`
-	Features FeatureConfig `json:"features"`
-	mu       sync.RWMutex  `json:"-"`
-	path     string        `json:"-"`
+	Version   string          `json:"version"`
+	Server    ServerConfig    `json:"server"`
+	OAuth     OAuthConfig     `json:"oauth"`
+	Features  FeatureConfig   `json:"features"`
+	Chaos     ChaosConfig     `json:"chaos"`
+	Storage   StorageConfig   `json:"storage"`
+	Notify    NotifyConfig    `json:"notifications"`
+	Database  DatabaseConfig  `json:"database"`
+	Tracing   TracingConfig   `json:"tracing"`
+	RateLimit RateLimitConfig `json:"rate_limit"`
+	MagicLink MagicLinkConfig `json:"magic_link"`
+	CORS      CORSConfig      `json:"cors"`
+	MQTT      MQTTConfig      `json:"mqtt"`
+	Voice     VoiceConfig     `json:"voice"`
+	Weather   WeatherConfig   `json:"weather"`
+	mu        sync.RWMutex    `json:"-"`
+	path      string          `json:"-"`
 }
 
 // ServerConfig holds server-specific settings
 type ServerConfig struct {
 	Port    string `json:"port"`
 	DevMode bool   `json:"dev_mode"`
+	// TrustProxy takes each client's address from X-Forwarded-For, for rate
+	// limits and sign-in lockouts. Turn it on only behind a reverse proxy
+	// that sets the header.
+	TrustProxy bool `json:"trust_proxy"`
+	// TrustedProxies lists the addresses or CIDR ranges of the reverse
+	// proxies whose X-Forwarded-For and X-Forwarded-Proto headers are
+	// believed. Left empty with TrustProxy on, any peer is trusted.
+	TrustedProxies []string `json:"trusted_proxies,omitempty"`
+	// PublicURL is the address members reach the server at, e.g.
+	// https://famstack.example.com. Links in emails point here, and cookies
+	// are marked Secure when it is https.
+	PublicURL string `json:"public_url"`
+	// TLS serves HTTPS directly, without a reverse proxy in front
+	TLS TLSConfig `json:"tls"`
+	// RPCPort serves the JSON-RPC API for companion apps on a second port;
+	// empty leaves it off
+	RPCPort string `json:"rpc_port,omitempty"`
+}
+
+// TLSConfig holds the server's certificate: either files, or certificates
+// fetched from Let's Encrypt for AutocertDomains and renewed automatically
+type TLSConfig struct {
+	CertFile string `json:"cert_file,omitempty"`
+	KeyFile  string `json:"key_file,omitempty"`
+	// AutocertDomains are the names to fetch certificates for; the server
+	// must be reachable on port 443, or HTTPPort 80, at each of them
+	AutocertDomains []string `json:"autocert_domains,omitempty"`
+	// AutocertEmail is given to Let's Encrypt for expiry notices
+	AutocertEmail string `json:"autocert_email,omitempty"`
+	// AutocertCacheDir keeps fetched certificates across restarts
+	AutocertCacheDir string `json:"autocert_cache_dir,omitempty"`
+	// HTTPPort, when set, serves plain HTTP there that redirects to HTTPS
+	// and answers Let's Encrypt's HTTP challenges
+	HTTPPort string `json:"http_port,omitempty"`
+}
+
+// Enabled reports whether the server serves HTTPS itself
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" || len(t.AutocertDomains) > 0
+}
+
+// SecureCookies reports whether cookies should be marked Secure: when the
+// public URL is https, or when no public URL is set and the server serves
+// HTTPS itself
+func (s ServerConfig) SecureCookies() bool {
+	if s.PublicURL != "" {
+		return strings.HasPrefix(strings.ToLower(s.PublicURL), "https://")
+	}
+	return s.TLS.Enabled()
 }
 
 // OAuthConfig holds OAuth provider configurations
@@ -42,13 +107,256 @@ type OAuthProvider struct {
 type FeatureConfig struct {
 	CalendarSync       bool `json:"calendar_sync"`
 	EmailNotifications bool `json:"email_notifications"`
+	// UsageAnalytics opts in to counting feature use in the local database.
+	// When false nothing is collected.
+	UsageAnalytics bool `json:"usage_analytics"`
+}
+
+// ChaosConfig controls fault injection for resilience testing. It only takes
+// effect when the server runs in development mode.
+type ChaosConfig struct {
+	Enabled     bool    `json:"enabled"`
+	LatencyRate float64 `json:"latency_rate"` // share of requests and jobs delayed, 0-1
+	LatencyMs   int     `json:"latency_ms"`   // upper bound of an injected delay
+	ErrorRate   float64 `json:"error_rate"`   // share failed with a 500 or job error
+	DBBusyRate  float64 `json:"db_busy_rate"` // share failed with a database busy error
+}
+
+// StorageConfig selects where uploaded files are kept. Both backend sections
+// may be filled in; Backend picks the one in use, and 'famstack storage
+// migrate' copies files from one to the other.
+type StorageConfig struct {
+	Backend string             `json:"backend"` // "local" or "s3"
+	Local   LocalStorageConfig `json:"local"`
+	S3      S3StorageConfig    `json:"s3"`
+}
+
+// LocalStorageConfig keeps files in a directory on the server
+type LocalStorageConfig struct {
+	Path string `json:"path"`
+}
+
+// S3StorageConfig keeps files in an S3-compatible bucket such as MinIO
+type S3StorageConfig struct {
+	Endpoint        string `json:"endpoint"` // e.g. https://s3.us-east-1.amazonaws.com or http://localhost:9000
+	Region          string `json:"region"`
+	Bucket          string `json:"bucket"`
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+	// UsePathStyle addresses the bucket as endpoint/bucket rather than
+	// bucket.endpoint, which MinIO and most self-hosted servers need
+	UsePathStyle bool `json:"use_path_style"`
+	// PresignExpirySeconds is how long download links stay valid; 0 turns
+	// them off so downloads go through the server
+	PresignExpirySeconds int `json:"presign_expiry_seconds"`
+}
+
+// NotifyConfig sets up the channels reminders and other notifications are
+// delivered through. A channel whose section is left empty is off.
+type NotifyConfig struct {
+	Email   EmailNotifyConfig   `json:"email"`
+	WebPush WebPushNotifyConfig `json:"web_push"`
+}
+
+// EmailNotifyConfig sends notifications through an SMTP server. It is used
+// only while the email_notifications feature is on.
+type EmailNotifyConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"` // 587 when unset
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"` // e.g. "FamStack <reminders@example.com>"
+}
+
+// WebPushNotifyConfig sends notifications to browsers that subscribed with
+// the Push API. The VAPID key pair is generated on first start when empty.
+type WebPushNotifyConfig struct {
+	Enabled         bool   `json:"enabled"`
+	Subject         string `json:"subject"`           // contact for push services, e.g. mailto:admin@example.com
+	VAPIDPublicKey  string `json:"vapid_public_key"`  // base64url, uncompressed P-256 point
+	VAPIDPrivateKey string `json:"vapid_private_key"` // base64url, raw P-256 scalar
+}
+
+// DatabaseConfig says where the data lives. URL is a SQLite file path or a
+// postgres:// URL; the --db flag takes precedence when given.
+type DatabaseConfig struct {
+	URL string `json:"url"`
+}
+
+// TracingConfig controls OpenTelemetry tracing. Spans are sent over OTLP/HTTP
+// to Endpoint; when it is empty the standard OTEL_EXPORTER_OTLP_* variables
+// are used, and then localhost:4318.
+type TracingConfig struct {
+	Enabled     bool              `json:"enabled"`
+	Endpoint    string            `json:"endpoint"` // e.g. http://localhost:4318 or https://otlp.example.com
+	Headers     map[string]string `json:"headers,omitempty"`
+	SampleRatio float64           `json:"sample_ratio"` // share of traces kept, 0-1; 0 keeps all
+	ServiceName string            `json:"service_name"` // "famstack" when unset
+}
+
+// RateLimitConfig throttles clients that send too many requests, to keep an
+// instance exposed to the internet responsive and slow down password guessing.
+// Each limit is a token bucket: Burst requests can arrive at once, and the
+// bucket refills at PerMinute. A limit left at zero uses its default.
+type RateLimitConfig struct {
+	Disabled bool      `json:"disabled"`
+	Login    RateLimit `json:"login"` // /auth/login and /auth/upgrade, per client address
+	OAuth    RateLimit `json:"oauth"` // /oauth/..., per client address
+	API      RateLimit `json:"api"`   // /api/..., per session, or per client address without a valid token
+}
+
+// RateLimit is one token bucket's size and refill rate
+type RateLimit struct {
+	PerMinute float64 `json:"per_minute"`
+	Burst     int     `json:"burst"`
+}
+
+// MagicLinkConfig lets members without a password sign in with a link
+// emailed to them or a one-time code a parent creates for them. Emailed
+// links need email notifications and server.public_url set up.
+type MagicLinkConfig struct {
+	Enabled       bool     `json:"enabled"`
+	MemberTypes   []string `json:"member_types"`   // member types allowed to use it, "child" when empty
+	ExpiryMinutes int      `json:"expiry_minutes"` // how long a link or code stays valid, 15 when unset
+}
+
+// CORSConfig lets web apps served from other origins, such as a separately
+// hosted mobile or web client, call the API from the browser. Origins not
+// allowed get no CORS headers, so browsers keep them out. Routes override
+// the default policy for paths under their prefix, the longest prefix
+// winning.
+type CORSConfig struct {
+	CORSPolicy
+	Routes []CORSRoute `json:"routes,omitempty"`
+}
+
+// CORSPolicy says which origins may call a set of routes, and how
+type CORSPolicy struct {
+	AllowedOrigins   []string `json:"allowed_origins"`   // e.g. https://app.example.com, or "*" for any
+	AllowCredentials bool     `json:"allow_credentials"` // let browsers send cookies; not with "*"
+	AllowedMethods   []string `json:"allowed_methods,omitempty"`
+	AllowedHeaders   []string `json:"allowed_headers,omitempty"` // on top of the ones the API reads
+	ExposedHeaders   []string `json:"exposed_headers,omitempty"` // on top of ETag and Retry-After
+	MaxAgeSeconds    int      `json:"max_age_seconds"`           // how long preflights are cached; 600 when unset
+}
+
+// CORSRoute is the policy for paths starting with PathPrefix
+type CORSRoute struct {
+	PathPrefix string `json:"path_prefix"`
+	CORSPolicy
+}
+
+// Validate reports settings browsers would reject
+func (c CORSConfig) Validate() error {
+	if err := c.CORSPolicy.validate(); err != nil {
+		return err
+	}
+	for _, route := range c.Routes {
+		if !strings.HasPrefix(route.PathPrefix, "/") {
+			return fmt.Errorf("CORS route prefix %q must start with /", route.PathPrefix)
+		}
+		if err := route.CORSPolicy.validate(); err != nil {
+			return fmt.Errorf("CORS route %s: %w", route.PathPrefix, err)
+		}
+	}
+	return nil
+}
+
+func (p CORSPolicy) validate() error {
+	for _, origin := range p.AllowedOrigins {
+		if origin == "*" {
+			if p.AllowCredentials {
+				return fmt.Errorf("credentials can't be allowed for every origin")
+			}
+			continue
+		}
+		if !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			return fmt.Errorf("allowed origin %q must be a URL such as https://app.example.com", origin)
+		}
+		if strings.Count(origin, "/") > 2 {
+			return fmt.Errorf("allowed origin %q must not have a path", origin)
+		}
+	}
+	return nil
+}
+
+// PolicyFor returns the policy covering a request path
+func (c CORSConfig) PolicyFor(path string) CORSPolicy {
+	policy, matched := c.CORSPolicy, ""
+	for _, route := range c.Routes {
+		if strings.HasPrefix(path, route.PathPrefix) && len(route.PathPrefix) > len(matched) {
+			policy, matched = route.CORSPolicy, route.PathPrefix
+		}
+	}
+	return policy
+}
+
+// MQTTConfig connects to an MQTT broker so smart-home systems such as Home
+// Assistant can follow task and calendar changes and send commands back.
+// Only families that add an MQTT smart-home integration take part.
+type MQTTConfig struct {
+	Enabled     bool   `json:"enabled"`
+	Broker      string `json:"broker"` // e.g. tcp://homeassistant.local:1883 or tls://broker.example.com:8883
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	ClientID    string `json:"client_id"`    // "famstack" when unset
+	TopicPrefix string `json:"topic_prefix"` // "famstack" when unset
+	// Commands lets messages on the command topics complete tasks and add
+	// shopping items; when false the bridge only publishes
+	Commands bool `json:"commands"`
+}
+
+// VoiceConfig lets an Alexa skill and a Google Action link to members'
+// accounts and answer them. Each is registered in its developer console with
+// famstack's /oauth/authorize and /oauth/token for account linking; an
+// assistant without a client ID is off.
+type VoiceConfig struct {
+	Alexa  AlexaConfig       `json:"alexa"`
+	Google VoiceClientConfig `json:"google"`
+}
+
+// AlexaConfig is the Alexa skill's account linking client, and the skill
+// whose requests are answered
+type AlexaConfig struct {
+	VoiceClientConfig
+	SkillID string `json:"skill_id"` // e.g. amzn1.ask.skill.…; any skill when empty
+}
+
+// VoiceClientConfig is the OAuth client an assistant links accounts with
+type VoiceClientConfig struct {
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	RedirectURIs []string `json:"redirect_uris"` // as listed in the developer console
+}
+
+// WeatherConfig turns on daily forecasts in the calendar for families that
+// set their location. Forecasts come from Open-Meteo, which needs no key;
+// only a family's coordinates are sent.
+type WeatherConfig struct {
+	Enabled     bool   `json:"enabled"`
+	ForecastURL string `json:"forecast_url"` // Open-Meteo's forecast API when unset
 }
 
 // Manager handles configuration file operations
 type Manager struct {
 	config *Config
+
+	// watchMu guards the listeners and the file's last seen modification
+	// time, and serializes reloads
+	watchMu   sync.Mutex
+	listeners []ChangeFunc
+	modTime   time.Time
 }
 
+// ChangeFunc is told about a change to the configuration, with copies of it
+// before and after. It runs on the goroutine that made the change, so
+// anything slow belongs on another.
+type ChangeFunc func(old, updated *Config)
+
+// RestartSections are the sections read only at startup; changes to them
+// are saved but take effect after a restart
+var RestartSections = []string{"server", "chaos", "storage", "notifications", "database", "tracing", "rate_limit", "magic_link", "mqtt", "weather"}
+
 // NewManager creates a new config manager
 func NewManager(configPath string) (*Manager, error) {
 	manager := &Manager{}
@@ -60,6 +368,9 @@ func NewManager(configPath string) (*Manager, error) {
 	}
 
 	manager.config = config
+	if info, err := os.Stat(configPath); err == nil {
+		manager.modTime = info.ModTime()
+	}
 	return manager, nil
 }
 
@@ -101,6 +412,32 @@ func (m *Manager) createDefaultConfig(path string) *Config {
 		Features: FeatureConfig{
 			CalendarSync:       true,
 			EmailNotifications: false,
+			UsageAnalytics:     false,
+		},
+		Chaos: ChaosConfig{
+			Enabled:     false,
+			LatencyRate: 0.2,
+			LatencyMs:   2000,
+			ErrorRate:   0.05,
+			DBBusyRate:  0.05,
+		},
+		Storage: StorageConfig{
+			Backend: "local",
+			Local:   LocalStorageConfig{Path: "data/files"},
+			S3: S3StorageConfig{
+				Region:               "us-east-1",
+				UsePathStyle:         true,
+				PresignExpirySeconds: 300,
+			},
+		},
+		Notify: NotifyConfig{
+			Email:   EmailNotifyConfig{Port: 587},
+			WebPush: WebPushNotifyConfig{Enabled: true},
+		},
+		MagicLink: MagicLinkConfig{
+			Enabled:       true,
+			MemberTypes:   []string{"child"},
+			ExpiryMinutes: 15,
 		},
 	}
 }
@@ -143,6 +480,13 @@ func (m *Manager) saveConfig(config *Config) error {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
+	// The watcher needn't reload what was just written
+	if info, err := os.Stat(config.path); err == nil {
+		m.watchMu.Lock()
+		m.modTime = info.ModTime()
+		m.watchMu.Unlock()
+	}
+
 	return nil
 }
 
@@ -153,11 +497,22 @@ func (m *Manager) GetConfig() *Config {
 
 	// Return a copy without the mutex to prevent external modifications
 	configCopy := Config{
-		Version:  m.config.Version,
-		Server:   m.config.Server,
-		OAuth:    m.config.OAuth,
-		Features: m.config.Features,
-		path:     m.config.path,
+		Version:   m.config.Version,
+		Server:    m.config.Server,
+		OAuth:     m.config.OAuth,
+		Features:  m.config.Features,
+		Chaos:     m.config.Chaos,
+		Storage:   m.config.Storage,
+		Notify:    m.config.Notify,
+		Database:  m.config.Database,
+		Tracing:   m.config.Tracing,
+		RateLimit: m.config.RateLimit,
+		MagicLink: m.config.MagicLink,
+		CORS:      m.config.CORS,
+		MQTT:      m.config.MQTT,
+		Voice:     m.config.Voice,
+		Weather:   m.config.Weather,
+		path:      m.config.path,
 		// Don't copy the mutex
 	}
 	return &configCopy
@@ -165,24 +520,14 @@ func (m *Manager) GetConfig() *Config {
 
 // UpdateOAuthProvider updates OAuth configuration for a provider
 func (m *Manager) UpdateOAuthProvider(provider string, config *OAuthProvider) error {
-	// Update config in memory with proper locking
-	func() {
-		m.config.mu.Lock()
-		defer m.config.mu.Unlock()
-
-		switch provider {
-		case "google":
-			m.config.OAuth.Google = config
-		}
-	}()
-
-	// Validate provider after updating
+	// Validate provider before updating
 	if provider != "google" {
 		return fmt.Errorf("unsupported OAuth provider: %s", provider)
 	}
 
-	// Save to file (this has its own locking)
-	return m.saveConfig(m.config)
+	return m.update(func(c *Config) {
+		c.OAuth.Google = config
+	})
 }
 
 // GetOAuthProvider returns OAuth configuration for a provider
@@ -205,26 +550,179 @@ func (m *Manager) GetOAuthProvider(provider string) (*OAuthProvider, error) {
 
 // UpdateServerConfig updates server configuration
 func (m *Manager) UpdateServerConfig(config ServerConfig) error {
-	// Update config in memory with proper locking
+	return m.update(func(c *Config) {
+		c.Server = config
+	})
+}
+
+// UpdateFeatureConfig updates feature configuration
+func (m *Manager) UpdateFeatureConfig(config FeatureConfig) error {
+	return m.update(func(c *Config) {
+		c.Features = config
+	})
+}
+
+// UpdateCORSConfig replaces the cross-origin settings
+func (m *Manager) UpdateCORSConfig(config CORSConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	return m.update(func(c *Config) {
+		c.CORS = config
+	})
+}
+
+// UpdateVAPIDKeys stores a newly generated web push key pair
+func (m *Manager) UpdateVAPIDKeys(publicKey, privateKey string) error {
+	return m.update(func(c *Config) {
+		c.Notify.WebPush.VAPIDPublicKey = publicKey
+		c.Notify.WebPush.VAPIDPrivateKey = privateKey
+	})
+}
+
+// update changes the configuration in memory with proper locking, saves it
+// and tells the listeners
+func (m *Manager) update(change func(c *Config)) error {
+	old := m.GetConfig()
 	func() {
 		m.config.mu.Lock()
 		defer m.config.mu.Unlock()
-		m.config.Server = config
+		change(m.config)
 	}()
 
 	// Save to file (this has its own locking)
-	return m.saveConfig(m.config)
+	if err := m.saveConfig(m.config); err != nil {
+		return err
+	}
+	m.notify(old, m.GetConfig())
+	return nil
 }
 
-// UpdateFeatureConfig updates feature configuration
-func (m *Manager) UpdateFeatureConfig(config FeatureConfig) error {
-	// Update config in memory with proper locking
+// Subscribe calls fn after every change to the configuration, whether made
+// through the manager or by editing the file and reloading it
+func (m *Manager) Subscribe(fn ChangeFunc) {
+	m.watchMu.Lock()
+	defer m.watchMu.Unlock()
+	m.listeners = append(m.listeners, fn)
+}
+
+func (m *Manager) notify(old, updated *Config) {
+	if len(Changed(old, updated)) == 0 {
+		return
+	}
+	m.watchMu.Lock()
+	listeners := append([]ChangeFunc(nil), m.listeners...)
+	m.watchMu.Unlock()
+	for _, fn := range listeners {
+		fn(old, updated)
+	}
+}
+
+// Reload reads the configuration file again and applies it, telling the
+// listeners when anything changed. It returns the sections that changed; a
+// file that doesn't parse or validate leaves the configuration as it was.
+func (m *Manager) Reload() ([]string, error) {
+	m.watchMu.Lock()
+	path := m.config.path
+	info, statErr := os.Stat(path)
+	loaded, err := m.loadConfig(path)
+	if err == nil {
+		err = loaded.CORS.Validate()
+	}
+	if err != nil {
+		m.watchMu.Unlock()
+		return nil, fmt.Errorf("failed to reload config: %w", err)
+	}
+	if statErr == nil {
+		m.modTime = info.ModTime()
+	}
+	m.watchMu.Unlock()
+
+	old := m.GetConfig()
 	func() {
 		m.config.mu.Lock()
 		defer m.config.mu.Unlock()
-		m.config.Features = config
+		m.config.replace(loaded)
 	}()
+	updated := m.GetConfig()
 
-	// Save to file (this has its own locking)
-	return m.saveConfig(m.config)
+	changed := Changed(old, updated)
+	m.notify(old, updated)
+	return changed, nil
+}
+
+// Watch reloads the configuration whenever the file changes on disk, checking
+// every interval until ctx is done
+func (m *Manager) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(m.config.path)
+		if err != nil {
+			continue
+		}
+		m.watchMu.Lock()
+		modified := !info.ModTime().Equal(m.modTime)
+		m.watchMu.Unlock()
+		if !modified {
+			continue
+		}
+
+		changed, err := m.Reload()
+		if err != nil {
+			// Keep the running configuration; a later save is tried again
+			log.Printf("⚠️  Ignoring edited config file: %v", err)
+			m.watchMu.Lock()
+			m.modTime = info.ModTime()
+			m.watchMu.Unlock()
+			continue
+		}
+		if len(changed) > 0 {
+			log.Printf("📋 Configuration reloaded, changed: %v", changed)
+		}
+	}
+}
+
+// replace takes every section from another configuration, keeping the path
+func (c *Config) replace(from *Config) {
+	c.Version = from.Version
+	c.Server = from.Server
+	c.OAuth = from.OAuth
+	c.Features = from.Features
+	c.Chaos = from.Chaos
+	c.Storage = from.Storage
+	c.Notify = from.Notify
+	c.Database = from.Database
+	c.Tracing = from.Tracing
+	c.RateLimit = from.RateLimit
+	c.MagicLink = from.MagicLink
+	c.CORS = from.CORS
+	c.MQTT = from.MQTT
+	c.Voice = from.Voice
+	c.Weather = from.Weather
+}
+
+// Changed lists the sections, by their JSON names, that differ between two
+// configurations
+func Changed(old, updated *Config) []string {
+	changed := []string{}
+	oldValue, updatedValue := reflect.ValueOf(old).Elem(), reflect.ValueOf(updated).Elem()
+	for i := 0; i < oldValue.NumField(); i++ {
+		field := oldValue.Type().Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if !field.IsExported() || name == "" || name == "-" || name == "version" {
+			continue
+		}
+		if !reflect.DeepEqual(oldValue.Field(i).Interface(), updatedValue.Field(i).Interface()) {
+			changed = append(changed, name)
+		}
+	}
+	return changed
 }
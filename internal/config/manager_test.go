@@ -0,0 +1,102 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// editConfig changes the config file the way someone editing it by hand would
+func editConfig(t *testing.T, path string, edit func(raw map[string]any)) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var raw map[string]any
+	require.NoError(t, json.Unmarshal(data, &raw))
+	edit(raw)
+	data, err = json.Marshal(raw)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+}
+
+func TestManager_ReloadTellsListeners(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "famstack-config.json")
+	manager, err := NewManager(path)
+	require.NoError(t, err)
+
+	var changes [][]string
+	manager.Subscribe(func(old, updated *Config) {
+		changes = append(changes, Changed(old, updated))
+	})
+
+	editConfig(t, path, func(raw map[string]any) {
+		raw["features"].(map[string]any)["usage_analytics"] = true
+		raw["oauth"].(map[string]any)["google"].(map[string]any)["client_id"] = "client"
+	})
+	changed, err := manager.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"oauth", "features"}, changed)
+	assert.True(t, manager.GetConfig().Features.UsageAnalytics)
+	assert.Equal(t, "client", manager.GetConfig().OAuth.Google.ClientID)
+
+	// Reloading an unchanged file tells no one
+	changed, err = manager.Reload()
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+
+	require.NoError(t, manager.UpdateFeatureConfig(FeatureConfig{CalendarSync: true}))
+	assert.Equal(t, [][]string{{"oauth", "features"}, {"features"}}, changes)
+}
+
+func TestManager_ReloadKeepsConfigOnBadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "famstack-config.json")
+	manager, err := NewManager(path)
+	require.NoError(t, err)
+
+	editConfig(t, path, func(raw map[string]any) {
+		raw["cors"] = map[string]any{"allowed_origins": []string{"*"}, "allow_credentials": true}
+	})
+	_, err = manager.Reload()
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+	_, err = manager.Reload()
+	assert.Error(t, err)
+
+	assert.Equal(t, "8080", manager.GetConfig().Server.Port)
+	assert.Empty(t, manager.GetConfig().CORS.AllowedOrigins)
+}
+
+func TestManager_WatchReloadsEditedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "famstack-config.json")
+	manager, err := NewManager(path)
+	require.NoError(t, err)
+
+	reloaded := make(chan *Config, 1)
+	manager.Subscribe(func(old, updated *Config) {
+		reloaded <- updated
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go manager.Watch(ctx, 10*time.Millisecond)
+
+	editConfig(t, path, func(raw map[string]any) {
+		raw["weather"] = map[string]any{"enabled": true}
+	})
+	// Make sure the edit is seen on file systems with coarse timestamps
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	select {
+	case updated := <-reloaded:
+		assert.True(t, updated.Weather.Enabled)
+	case <-time.After(2 * time.Second):
+		t.Fatal("edited config was not reloaded")
+	}
+}
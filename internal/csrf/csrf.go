@@ -0,0 +1,131 @@
+// Package csrf protects cookie-authenticated requests from cross-site
+// request forgery with double-submit tokens.
+//
+// Each browser gets a random token in the csrf_token cookie. The SPA reads it
+// (the cookie is not HTTP-only) and sends it back in the X-CSRF-Token header;
+// server-rendered forms carry it in a csrf_token field. Another site can make
+// a browser send the cookie but cannot read it, so it cannot supply the
+// matching header or field. The token is replaced on login and logout.
+package csrf
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"famstack/internal/apierror"
+)
+
+const (
+	// CookieName is the cookie holding the token
+	CookieName = "csrf_token"
+	// HeaderName is the header API requests send the token in
+	HeaderName = "X-CSRF-Token"
+	// FormField is the form field HTML forms send the token in
+	FormField = "csrf_token"
+
+	cookieMaxAge = 7 * 24 * 60 * 60 // 7 days, as the auth cookie
+)
+
+// secureCookies marks the token cookie HTTPS-only
+var secureCookies atomic.Bool
+
+// SetSecureCookies sets whether the token cookie is sent only over HTTPS,
+// which it should be whenever the server is reached over HTTPS
+func SetSecureCookies(secure bool) {
+	secureCookies.Store(secure)
+}
+
+// Token returns the request's CSRF token, issuing a new one in a cookie when
+// the request has none
+func Token(w http.ResponseWriter, r *http.Request) string {
+	if cookie, err := r.Cookie(CookieName); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+	return Rotate(w)
+}
+
+// Rotate issues a new token in a cookie and returns it
+func Rotate(w http.ResponseWriter) string {
+	buf := make([]byte, 32)
+	_, _ = rand.Read(buf) // crypto/rand.Read never fails
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     CookieName,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: false, // the SPA reads it to send it back
+		Secure:   secureCookies.Load(),
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   cookieMaxAge,
+	})
+	return token
+}
+
+// Protect rejects state-changing requests that don't send back the token in
+// their cookie. Requests carrying a bearer token are let through, as
+// browsers never add one on their own, and so are paths under the exempt
+// prefixes, such as CalDAV, whose clients don't run the SPA.
+func Protect(next http.Handler, exempt ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !needsToken(r, exempt) || validToken(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		apierror.Error(w, "Invalid or missing CSRF token", http.StatusForbidden)
+	})
+}
+
+// HandleToken returns the caller's token, issuing one if needed, so the SPA
+// can pick up a new token without reloading the page
+func HandleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := Token(w, r)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(map[string]string{"csrf_token": token}) // nolint:errcheck
+}
+
+func needsToken(r *http.Request, exempt []string) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return false
+	}
+	if strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		return false
+	}
+	for _, prefix := range exempt {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+func validToken(r *http.Request) bool {
+	cookie, err := r.Cookie(CookieName)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+
+	sent := r.Header.Get(HeaderName)
+	if sent == "" && isForm(r) {
+		sent = r.PostFormValue(FormField)
+	}
+	return subtle.ConstantTimeCompare([]byte(sent), []byte(cookie.Value)) == 1
+}
+
+func isForm(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data"
+}
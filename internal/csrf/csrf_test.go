@@ -0,0 +1,93 @@
+package csrf
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtect(t *testing.T) {
+	handler := Protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), "/caldav/")
+
+	rec := httptest.NewRecorder()
+	token := Token(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	cookie := &http.Cookie{Name: CookieName, Value: token}
+
+	form := url.Values{FormField: {token}}.Encode()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		setup  func(r *http.Request)
+		status int
+	}{
+		{name: "safe method", method: http.MethodGet, path: "/api/v1/tasks", status: http.StatusNoContent},
+		{name: "no token", method: http.MethodPost, path: "/api/v1/tasks", setup: func(r *http.Request) {
+			r.AddCookie(cookie)
+		}, status: http.StatusForbidden},
+		{name: "header matches cookie", method: http.MethodPatch, path: "/api/v1/tasks/1", setup: func(r *http.Request) {
+			r.AddCookie(cookie)
+			r.Header.Set(HeaderName, token)
+		}, status: http.StatusNoContent},
+		{name: "header without cookie", method: http.MethodPost, path: "/api/v1/tasks", setup: func(r *http.Request) {
+			r.Header.Set(HeaderName, token)
+		}, status: http.StatusForbidden},
+		{name: "wrong header", method: http.MethodDelete, path: "/api/v1/tasks/1", setup: func(r *http.Request) {
+			r.AddCookie(cookie)
+			r.Header.Set(HeaderName, "forged")
+		}, status: http.StatusForbidden},
+		{name: "form field", method: http.MethodPost, path: "/oauth/google/connect/configure", body: form, setup: func(r *http.Request) {
+			r.AddCookie(cookie)
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}, status: http.StatusNoContent},
+		{name: "bearer token", method: http.MethodPost, path: "/api/v1/tasks", setup: func(r *http.Request) {
+			r.Header.Set("Authorization", "Bearer abc")
+		}, status: http.StatusNoContent},
+		{name: "basic auth", method: http.MethodPost, path: "/api/v1/tasks", setup: func(r *http.Request) {
+			r.SetBasicAuth("jane@example.com", "secret")
+		}, status: http.StatusForbidden},
+		{name: "exempt prefix", method: http.MethodPut, path: "/caldav/calendars/event.ics", status: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.setup != nil {
+				tt.setup(req)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
+
+func TestToken(t *testing.T) {
+	rec := httptest.NewRecorder()
+	token := Token(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.NotEmpty(t, token)
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, token, cookies[0].Value)
+	assert.False(t, cookies[0].HttpOnly, "the SPA reads the cookie")
+
+	// An existing token is kept
+	req := httptest.NewRequest(http.MethodGet, "/auth/csrf", nil)
+	req.AddCookie(&http.Cookie{Name: CookieName, Value: token})
+	rec = httptest.NewRecorder()
+	HandleToken(rec, req)
+	assert.Empty(t, rec.Result().Cookies())
+	assert.JSONEq(t, `{"csrf_token":"`+token+`"}`, rec.Body.String())
+
+	// Rotating issues a different one
+	assert.NotEqual(t, token, Rotate(httptest.NewRecorder()))
+}
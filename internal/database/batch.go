@@ -0,0 +1,45 @@
+package database
+
+import "strings"
+
+// MaxInParams caps the values bound in one IN list, staying under SQLite's
+// default limit of 999 parameters per statement with room for the query's
+// other arguments
+const MaxInParams = 900
+
+// inBatchSizes are the lengths IN-list batches are padded to, so a query
+// over any number of values is one of a few statements that can be
+// prepared once
+var inBatchSizes = []int{8, 64, 256, MaxInParams}
+
+// InBatches splits values into batches for IN lists of at most MaxInParams.
+// Each batch is padded, by repeating its last value, to one of a few fixed
+// lengths; a repeated value in an IN list matches nothing more.
+func InBatches(values []string) [][]any {
+	var batches [][]any
+	for start := 0; start < len(values); start += MaxInParams {
+		chunk := values[start:min(start+MaxInParams, len(values))]
+		size := MaxInParams
+		for _, candidate := range inBatchSizes {
+			if candidate >= len(chunk) {
+				size = candidate
+				break
+			}
+		}
+
+		batch := make([]any, size)
+		for i := range batch {
+			batch[i] = chunk[min(i, len(chunk)-1)]
+		}
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// InList returns n comma-separated placeholders, for an IN list
+func InList(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return "?" + strings.Repeat(",?", n-1)
+}
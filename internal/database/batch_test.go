@@ -0,0 +1,39 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInBatches(t *testing.T) {
+	assert.Empty(t, InBatches(nil))
+
+	batches := InBatches([]string{"a", "b", "c"})
+	assert.Equal(t, [][]any{{"a", "b", "c", "c", "c", "c", "c", "c"}}, batches, "padded to a fixed length")
+
+	values := make([]string, 2000)
+	for i := range values {
+		values[i] = fmt.Sprintf("evt_%d", i)
+	}
+	batches = InBatches(values)
+	assert.Len(t, batches, 3)
+	assert.Len(t, batches[0], MaxInParams)
+	assert.Len(t, batches[1], MaxInParams)
+	assert.Len(t, batches[2], 256, "the 200 left over pad to the next size up")
+	assert.Equal(t, "evt_1999", batches[2][len(batches[2])-1])
+
+	seen := map[any]bool{}
+	for _, batch := range batches {
+		for _, value := range batch {
+			seen[value] = true
+		}
+	}
+	assert.Len(t, seen, 2000, "every value is in a batch")
+}
+
+func TestInList(t *testing.T) {
+	assert.Equal(t, "?", InList(1))
+	assert.Equal(t, "?,?,?", InList(3))
+}
@@ -1,25 +1,39 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"fmt"
+	"sync"
 
 	goose "github.com/pressly/goose/v3"
 	_ "modernc.org/sqlite" // Pure Go SQLite driver
 )
 
-//go:embed migrations/*.sql
+// Each dialect has its own migrations, kept at the same versions
+//
+//go:embed migrations/sqlite/*.sql migrations/postgres/*.sql
 var embedMigrations embed.FS
 
 // DB wraps the database connection
 type DB struct {
 	*sql.DB
+	Dialect Dialect
 }
 
-// New creates a new database connection
-func New(dbPath string) (*Fascade, error) {
-	db, err := sql.Open("sqlite", dbPath+"?_foreign_keys=on&_journal_mode=WAL&_cache_size=-64000")
+// New creates a new database connection. dsn is a SQLite file path or a
+// postgres:// URL.
+func New(dsn string) (*Fascade, error) {
+	dialect := DialectFor(dsn)
+
+	var db *sql.DB
+	var err error
+	if dialect == DialectPostgres {
+		db, err = openPostgres(dsn)
+	} else {
+		db, err = sql.Open("sqlite", dsn+"?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=cache_size(-64000)")
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -29,19 +43,29 @@ func New(dbPath string) (*Fascade, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return NewFascade(&DB{db}), nil
+	return NewFascade(&DB{DB: db, Dialect: dialect}), nil
 }
 
 type Fascade struct {
 	innerDb *DB
+
+	stmtMu sync.Mutex
+	stmts  map[string]*sql.Stmt
 }
 
 func NewFascade(db *DB) *Fascade {
 	return &Fascade{
 		innerDb: db,
+		stmts:   make(map[string]*sql.Stmt),
 	}
 }
 
+// Dialect reports which database the facade is connected to, for the few
+// queries that have to be written differently for each
+func (df *Fascade) Dialect() Dialect {
+	return df.innerDb.Dialect
+}
+
 func (df *Fascade) QueryRow(query string, args ...any) *sql.Row {
 	return df.innerDb.QueryRow(query, args...)
 }
@@ -54,43 +78,68 @@ func (df *Fascade) Exec(query string, args ...any) (sql.Result, error) {
 	return df.innerDb.Exec(query, args...)
 }
 
-func (df *Fascade) BeginCommit(vFunc func(*sql.Tx) error) error {
-	tx, err := df.innerDb.Begin()
+// InTx runs fn in a transaction, committing it if fn returns nil and
+// rolling it back if fn returns an error or panics. fn must not commit or
+// roll back itself. Cancelling ctx before the commit rolls the transaction
+// back.
+func (df *Fascade) InTx(ctx context.Context, fn func(*sql.Tx) error) (err error) {
+	tx, err := df.innerDb.BeginTx(ctx, nil)
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback() // nolint:errcheck
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback() // nolint:errcheck
+		}
+	}()
+
+	if err := fn(tx); err != nil {
 		return err
 	}
-	return vFunc(tx)
-}
-
-func (df *Fascade) Close() error {
-	return df.innerDb.Close()
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
 }
 
-// MigrateUp runs all available migrations
-func (df *Fascade) MigrateUp() error {
-	goose.SetBaseFS(embedMigrations)
+// Prepared returns a prepared statement for query, preparing it the first
+// time it's asked for and reusing it after. For the queries run on every
+// listing; statements are safe for concurrent use and close with the
+// database.
+func (df *Fascade) Prepared(query string) (*sql.Stmt, error) {
+	df.stmtMu.Lock()
+	defer df.stmtMu.Unlock()
 
-	if err := goose.SetDialect("sqlite3"); err != nil {
-		return fmt.Errorf("failed to set dialect: %w", err)
+	if stmt, ok := df.stmts[query]; ok {
+		return stmt, nil
 	}
-
-	if err := goose.Up(df.innerDb.DB, "migrations"); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
+	stmt, err := df.innerDb.Prepare(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
 	}
-
-	return nil
+	df.stmts[query] = stmt
+	return stmt, nil
 }
 
-// MigrateDown rolls back one migration
-func (df *Fascade) MigrateDown() error {
-	goose.SetBaseFS(embedMigrations)
-
-	if err := goose.SetDialect("sqlite3"); err != nil {
-		return fmt.Errorf("failed to set dialect: %w", err)
+func (df *Fascade) Close() error {
+	df.stmtMu.Lock()
+	for query, stmt := range df.stmts {
+		stmt.Close()
+		delete(df.stmts, query)
 	}
+	df.stmtMu.Unlock()
+
+	return df.innerDb.Close()
+}
 
-	if err := goose.Down(df.innerDb.DB, "migrations"); err != nil {
-		return fmt.Errorf("failed to rollback migration: %w", err)
+// MigrateUp runs all available migrations, both pre- and post-deploy
+func (df *Fascade) MigrateUp() error {
+	if _, err := df.MigratePhase(PhaseAll); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
 	return nil
@@ -98,13 +147,13 @@ func (df *Fascade) MigrateDown() error {
 
 // GetMigrationStatus returns the current migration status
 func (df *Fascade) GetMigrationStatus() error {
-	goose.SetBaseFS(embedMigrations)
+	goose.SetBaseFS(migrationFiles(df.innerDb.Dialect))
 
-	if err := goose.SetDialect("sqlite3"); err != nil {
+	if err := goose.SetDialect(string(df.innerDb.Dialect.gooseDialect())); err != nil {
 		return fmt.Errorf("failed to set dialect: %w", err)
 	}
 
-	if err := goose.Status(df.innerDb.DB, "migrations"); err != nil {
+	if err := goose.Status(df.innerDb.DB, "."); err != nil {
 		return fmt.Errorf("failed to get migration status: %w", err)
 	}
 
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInTx(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "tx.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`CREATE TABLE notes (id TEXT PRIMARY KEY)`)
+	require.NoError(t, err)
+	count := func() int {
+		var n int
+		require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM notes`).Scan(&n))
+		return n
+	}
+	insert := func(tx *sql.Tx, id string) error {
+		_, err := tx.Exec(`INSERT INTO notes (id) VALUES (?)`, id)
+		return err
+	}
+
+	require.NoError(t, db.InTx(context.Background(), func(tx *sql.Tx) error {
+		return insert(tx, "a")
+	}))
+	assert.Equal(t, 1, count(), "committed when fn succeeds")
+
+	failed := errors.New("failed")
+	err = db.InTx(context.Background(), func(tx *sql.Tx) error {
+		require.NoError(t, insert(tx, "b"))
+		return failed
+	})
+	assert.ErrorIs(t, err, failed)
+	assert.Equal(t, 1, count(), "rolled back when fn fails")
+
+	assert.Panics(t, func() {
+		_ = db.InTx(context.Background(), func(tx *sql.Tx) error { // nolint:errcheck
+			require.NoError(t, insert(tx, "c"))
+			panic("boom")
+		})
+	})
+	assert.Equal(t, 1, count(), "rolled back when fn panics")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = db.InTx(ctx, func(tx *sql.Tx) error {
+		require.NoError(t, insert(tx, "d"))
+		cancel()
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, count(), "rolled back when ctx is cancelled before the commit")
+}
+
+func TestNew_EnforcesForeignKeys(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "fk.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	var enabled bool
+	require.NoError(t, db.QueryRow(`PRAGMA foreign_keys`).Scan(&enabled))
+	assert.True(t, enabled, "ON DELETE CASCADE relies on it")
+}
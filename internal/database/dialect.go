@@ -0,0 +1,77 @@
+package database
+
+import (
+	"strconv"
+	"strings"
+
+	goose "github.com/pressly/goose/v3"
+)
+
+// Dialect names the SQL database famstack is running against. Queries are
+// written for SQLite and, where the two differ, in the common subset: ?
+// placeholders, ON CONFLICT upserts and CURRENT_TIMESTAMP. The few that
+// can't be are chosen by dialect in the service that runs them.
+type Dialect string
+
+const (
+	DialectSQLite   Dialect = "sqlite"
+	DialectPostgres Dialect = "postgres"
+)
+
+// DialectFor picks the dialect for a database location: a postgres:// or
+// postgresql:// URL is PostgreSQL, anything else is a SQLite file path
+func DialectFor(dsn string) Dialect {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		return DialectPostgres
+	}
+	return DialectSQLite
+}
+
+// gooseDialect is the dialect goose records migrations with
+func (d Dialect) gooseDialect() goose.Dialect {
+	if d == DialectPostgres {
+		return goose.DialectPostgres
+	}
+	return goose.DialectSQLite3
+}
+
+// Rebind rewrites the ? placeholders in a query into the dialect's form.
+// PostgreSQL numbers them ($1, $2, ...); question marks inside quoted
+// strings, quoted identifiers and comments are left alone.
+func (d Dialect) Rebind(query string) string {
+	if d != DialectPostgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var out strings.Builder
+	out.Grow(len(query) + 8)
+	n := 0
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(query[i+1:], c)
+			if end < 0 {
+				out.WriteString(query[i:])
+				return out.String()
+			}
+			out.WriteString(query[i : i+end+2])
+			i += end + 1
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				out.WriteString(query[i:])
+				return out.String()
+			}
+			out.WriteString(query[i : i+end+1])
+			i += end
+		case c == '?':
+			n++
+			out.WriteByte('$')
+			out.WriteString(strconv.Itoa(n))
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,25 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialectFor(t *testing.T) {
+	assert.Equal(t, DialectSQLite, DialectFor("famstack.db"))
+	assert.Equal(t, DialectSQLite, DialectFor(":memory:"))
+	assert.Equal(t, DialectPostgres, DialectFor("postgres://famstack@localhost/famstack"))
+	assert.Equal(t, DialectPostgres, DialectFor("postgresql://localhost/famstack?sslmode=disable"))
+}
+
+func TestRebind(t *testing.T) {
+	query := `SELECT * FROM tasks WHERE family_id = ? AND title != '?' AND status IN (?, ?) -- why?
+		AND "odd?column" = ?`
+	assert.Equal(t, query, DialectSQLite.Rebind(query))
+	assert.Equal(t, `SELECT * FROM tasks WHERE family_id = $1 AND title != '?' AND status IN ($2, $3) -- why?
+		AND "odd?column" = $4`, DialectPostgres.Rebind(query))
+
+	// Doubled quotes inside a string don't end it early
+	assert.Equal(t, `UPDATE tasks SET title = 'it''s ?' WHERE id = $1`, DialectPostgres.Rebind(`UPDATE tasks SET title = 'it''s ?' WHERE id = ?`))
+}
@@ -0,0 +1,317 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+)
+
+// checksumTable remembers a hash of each migration file as it was applied,
+// so a migration edited after it ran is noticed rather than silently
+// leaving databases with different schemas
+const checksumTable = "famstack_migration_checksums"
+
+// migrationChecksum hashes a migration file
+func migrationChecksum(source []byte) string {
+	sum := sha256.Sum256(source)
+	return hex.EncodeToString(sum[:])
+}
+
+func (df *Fascade) ensureChecksumTable() error {
+	_, err := df.innerDb.Exec(`
+		CREATE TABLE IF NOT EXISTS ` + checksumTable + ` (
+			version BIGINT PRIMARY KEY,
+			checksum TEXT NOT NULL,
+			recorded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create migration checksum table: %w", err)
+	}
+	return nil
+}
+
+// recordedChecksums returns the checksum recorded for each applied migration
+func (df *Fascade) recordedChecksums() (map[int64]string, error) {
+	if err := df.ensureChecksumTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := df.innerDb.Query(`SELECT version, checksum FROM ` + checksumTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration checksums: %w", err)
+	}
+	defer rows.Close()
+
+	checksums := make(map[int64]string)
+	for rows.Next() {
+		var version int64
+		var checksum string
+		if err := rows.Scan(&version, &checksum); err != nil {
+			return nil, fmt.Errorf("failed to scan migration checksum: %w", err)
+		}
+		checksums[version] = checksum
+	}
+	return checksums, rows.Err()
+}
+
+// recordApplied notes the phase and checksum of a migration just applied
+func (df *Fascade) recordApplied(migration MigrationInfo) error {
+	if err := df.ensurePhaseTable(); err != nil {
+		return err
+	}
+	if err := df.ensureChecksumTable(); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if _, err := df.innerDb.Exec(`
+		INSERT INTO `+phaseTable+` (version, phase, applied_at) VALUES (?, ?, ?)
+		ON CONFLICT(version) DO UPDATE SET phase = excluded.phase, applied_at = excluded.applied_at
+	`, migration.Version, migration.Phase, now); err != nil {
+		return fmt.Errorf("failed to record phase for migration %s: %w", migration.Name, err)
+	}
+	if _, err := df.innerDb.Exec(`
+		INSERT INTO `+checksumTable+` (version, checksum, recorded_at) VALUES (?, ?, ?)
+		ON CONFLICT(version) DO UPDATE SET checksum = excluded.checksum, recorded_at = excluded.recorded_at
+	`, migration.Version, migration.Checksum, now); err != nil {
+		return fmt.Errorf("failed to record checksum for migration %s: %w", migration.Name, err)
+	}
+	return nil
+}
+
+// forgetApplied clears what was recorded for a migration rolled back
+func (df *Fascade) forgetApplied(migration MigrationInfo) error {
+	if err := df.ensurePhaseTable(); err != nil {
+		return err
+	}
+	if err := df.ensureChecksumTable(); err != nil {
+		return err
+	}
+
+	if _, err := df.innerDb.Exec(`DELETE FROM `+phaseTable+` WHERE version = ?`, migration.Version); err != nil {
+		return fmt.Errorf("failed to clear migration phase: %w", err)
+	}
+	if _, err := df.innerDb.Exec(`DELETE FROM `+checksumTable+` WHERE version = ?`, migration.Version); err != nil {
+		return fmt.Errorf("failed to clear migration checksum: %w", err)
+	}
+	return nil
+}
+
+// AdoptChecksums records the checksum of applied migrations that have none,
+// ones applied before checksums were kept, and returns how many it recorded
+func (df *Fascade) AdoptChecksums() (int, error) {
+	migrations, err := df.ListMigrations()
+	if err != nil {
+		return 0, err
+	}
+	checksums, err := df.recordedChecksums()
+	if err != nil {
+		return 0, err
+	}
+
+	adopted := 0
+	for _, migration := range migrations {
+		if _, ok := checksums[migration.Version]; ok || !migration.Applied {
+			continue
+		}
+		if _, err := df.innerDb.Exec(`INSERT INTO `+checksumTable+` (version, checksum) VALUES (?, ?)`,
+			migration.Version, migration.Checksum); err != nil {
+			return adopted, fmt.Errorf("failed to record checksum for migration %s: %w", migration.Name, err)
+		}
+		adopted++
+	}
+	return adopted, nil
+}
+
+// EditedMigrations returns the applied migrations whose files have changed
+// since they ran
+func (df *Fascade) EditedMigrations() ([]MigrationInfo, error) {
+	migrations, err := df.ListMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var edited []MigrationInfo
+	for _, migration := range migrations {
+		if migration.Edited {
+			edited = append(edited, migration)
+		}
+	}
+	return edited, nil
+}
+
+// AcceptEditedMigrations records the current checksum of edited migrations,
+// once someone has checked the edit needs nothing rerun
+func (df *Fascade) AcceptEditedMigrations() error {
+	edited, err := df.EditedMigrations()
+	if err != nil {
+		return err
+	}
+	for _, migration := range edited {
+		if err := df.recordApplied(migration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrationsTo returns what moving the schema to version would do: the
+// pending migrations at or below it to apply, of either phase, oldest
+// first, and the applied ones above it to roll back, newest first. Version
+// 0 rolls back everything.
+func (df *Fascade) MigrationsTo(version int64) (up, down []MigrationInfo, err error) {
+	migrations, err := df.ListMigrations()
+	if err != nil {
+		return nil, nil, err
+	}
+	if version != 0 && !slices.ContainsFunc(migrations, func(m MigrationInfo) bool { return m.Version == version }) {
+		return nil, nil, fmt.Errorf("no migration with version %d", version)
+	}
+
+	for _, migration := range migrations {
+		switch {
+		case !migration.Applied && migration.Version <= version:
+			up = append(up, migration)
+		case migration.Applied && migration.Version > version:
+			down = append(down, migration)
+		}
+	}
+	slices.Reverse(down)
+	return up, down, nil
+}
+
+// MigrateTo moves the schema to version, rolling back newer migrations
+// before applying older pending ones. It returns the migrations it changed,
+// with Applied telling which way each went.
+func (df *Fascade) MigrateTo(version int64) ([]MigrationInfo, error) {
+	up, down, err := df.MigrationsTo(version)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := df.migrationProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []MigrationInfo
+	for _, migration := range down {
+		if _, err := provider.ApplyVersion(context.Background(), migration.Version, false); err != nil {
+			return changed, fmt.Errorf("failed to roll back migration %s: %w", migration.Name, err)
+		}
+		if err := df.forgetApplied(migration); err != nil {
+			return changed, err
+		}
+		migration.Applied = false
+		changed = append(changed, migration)
+	}
+	for _, migration := range up {
+		if _, err := provider.ApplyVersion(context.Background(), migration.Version, true); err != nil {
+			return changed, fmt.Errorf("failed to apply migration %s: %w", migration.Name, err)
+		}
+		if err := df.recordApplied(migration); err != nil {
+			return changed, err
+		}
+		migration.Applied = true
+		changed = append(changed, migration)
+	}
+	return changed, nil
+}
+
+// LastAppliedMigration returns the newest applied migration, or nil when
+// none has been
+func (df *Fascade) LastAppliedMigration() (*MigrationInfo, error) {
+	migrations, err := df.ListMigrations()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if migrations[i].Applied {
+			return &migrations[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// MigrateDown rolls back the most recently applied migration and returns it
+func (df *Fascade) MigrateDown() (*MigrationInfo, error) {
+	last, err := df.LastAppliedMigration()
+	if err != nil {
+		return nil, err
+	}
+	if last == nil {
+		return nil, fmt.Errorf("no migrations have been applied")
+	}
+
+	provider, err := df.migrationProvider()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := provider.ApplyVersion(context.Background(), last.Version, false); err != nil {
+		return nil, fmt.Errorf("failed to rollback migration: %w", err)
+	}
+	if err := df.forgetApplied(*last); err != nil {
+		return nil, err
+	}
+	last.Applied = false
+	return last, nil
+}
+
+// Baseline marks every migration up to version as applied without running
+// it, for a database whose schema was created another way, such as restored
+// from a schema dump. Migrations already applied are left alone.
+func (df *Fascade) Baseline(version int64) ([]MigrationInfo, error) {
+	up, _, err := df.MigrationsTo(version)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("baseline needs a migration version")
+	}
+
+	var marked []MigrationInfo
+	for _, migration := range up {
+		if _, err := df.innerDb.Exec(`INSERT INTO goose_db_version (version_id, is_applied) VALUES (?, ?)`,
+			migration.Version, true); err != nil {
+			return marked, fmt.Errorf("failed to mark migration %s applied: %w", migration.Name, err)
+		}
+		if err := df.recordApplied(migration); err != nil {
+			return marked, err
+		}
+		migration.Applied = true
+		marked = append(marked, migration)
+	}
+	return marked, nil
+}
+
+// MigrationSQL returns the statements a migration runs going up, or with up
+// false when rolled back, for a dry run
+func (df *Fascade) MigrationSQL(migration MigrationInfo, up bool) (string, error) {
+	source, err := fs.ReadFile(migrationFiles(df.innerDb.Dialect), migration.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read migration %s: %w", migration.Name, err)
+	}
+
+	statements := upStatements(string(source))
+	if !up {
+		statements = downStatements(string(source))
+	}
+	return strings.Join(statements, "\n"), nil
+}
+
+// Backup writes a consistent copy of a SQLite database to path, which must
+// not exist yet. PostgreSQL databases are backed up with pg_dump instead.
+func (df *Fascade) Backup(path string) error {
+	if df.innerDb.Dialect != DialectSQLite {
+		return fmt.Errorf("backups are only made of SQLite databases; use pg_dump for PostgreSQL")
+	}
+	if _, err := df.innerDb.Exec(`VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,471 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	goose "github.com/pressly/goose/v3"
+)
+
+// Migration phases for expand/contract deploys. Pre-deploy migrations only
+// add to the schema, so the release still serving keeps working while they
+// run. Post-deploy migrations remove or rewrite what that release depends on
+// and run once every instance is on the new release.
+const (
+	PhasePreDeploy  = "pre-deploy"
+	PhasePostDeploy = "post-deploy"
+	PhaseAll        = "all"
+)
+
+// phaseDirective marks a migration file's phase; files without it are pre-deploy
+const phaseDirective = "-- +famstack phase:"
+
+// phaseTable remembers which phase each applied migration belongs to, so an
+// older build can tell whether a newer schema is still compatible with it
+const phaseTable = "famstack_migration_phases"
+
+// Rough per-row costs used to estimate how long a statement holds the write lock
+const (
+	indexRowCost   = 2 * time.Microsecond
+	rewriteRowCost = 5 * time.Microsecond
+	deleteRowCost  = 1 * time.Microsecond
+	statementCost  = time.Millisecond
+)
+
+// MigrationInfo describes one embedded migration
+type MigrationInfo struct {
+	Version    int64           `json:"version"`
+	Name       string          `json:"name"`
+	Phase      string          `json:"phase"`
+	Applied    bool            `json:"applied"`
+	Checksum   string          `json:"checksum"`
+	Edited     bool            `json:"edited,omitempty"` // applied, but the file has changed since
+	Statements []StatementPlan `json:"statements,omitempty"`
+	Estimate   time.Duration   `json:"estimate"`
+}
+
+// StatementPlan is what one migration statement locks and roughly how long for
+type StatementPlan struct {
+	Operation string        `json:"operation"`
+	Table     string        `json:"table,omitempty"`
+	Lock      string        `json:"lock"`
+	Rows      int64         `json:"rows"`
+	Estimate  time.Duration `json:"estimate"`
+}
+
+// MigrationPlan lists the pending migrations a phase would apply
+type MigrationPlan struct {
+	Phase          string          `json:"phase"`
+	CurrentVersion int64           `json:"current_version"`
+	LatestVersion  int64           `json:"latest_version"`
+	Migrations     []MigrationInfo `json:"migrations"`
+	Estimate       time.Duration   `json:"estimate"`
+	Warnings       []string        `json:"warnings"`
+}
+
+// ValidPhase reports whether phase names a migration phase or "all"
+func ValidPhase(phase string) bool {
+	return phase == PhasePreDeploy || phase == PhasePostDeploy || phase == PhaseAll
+}
+
+// ListMigrations returns every embedded migration with its phase and whether it has been applied
+func (df *Fascade) ListMigrations() ([]MigrationInfo, error) {
+	provider, err := df.migrationProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := provider.Status(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration status: %w", err)
+	}
+
+	checksums, err := df.recordedChecksums()
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]MigrationInfo, 0, len(statuses))
+	for _, status := range statuses {
+		source, err := fs.ReadFile(migrationFiles(df.innerDb.Dialect), status.Source.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", status.Source.Path, err)
+		}
+		migration := MigrationInfo{
+			Version:  status.Source.Version,
+			Name:     path.Base(status.Source.Path),
+			Phase:    migrationPhase(string(source)),
+			Applied:  status.State == goose.StateApplied,
+			Checksum: migrationChecksum(source),
+		}
+		if recorded, ok := checksums[migration.Version]; ok && migration.Applied {
+			migration.Edited = recorded != migration.Checksum
+		}
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// CheckSchemaCompatibility refuses a database whose schema is newer than this
+// build unless every newer migration was a pre-deploy one, which this build
+// can run against safely. It is what lets the previous release keep serving
+// while the next one's pre-deploy migrations are applied.
+func (df *Fascade) CheckSchemaCompatibility() error {
+	if err := df.ensurePhaseTable(); err != nil {
+		return err
+	}
+
+	migrations, err := df.ListMigrations()
+	if err != nil {
+		return err
+	}
+	latest := latestVersion(migrations)
+
+	rows, err := df.innerDb.Query(`
+		SELECT v.version_id, COALESCE(p.phase, '')
+		FROM goose_db_version v
+		LEFT JOIN `+phaseTable+` p ON p.version = v.version_id
+		WHERE v.version_id > ? AND v.is_applied
+	`, latest)
+	if err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int64
+		var phase string
+		if err := rows.Scan(&version, &phase); err != nil {
+			return fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		if phase != PhasePreDeploy {
+			return fmt.Errorf("database schema includes migration %d, which this build (schema %d) can't run against; upgrade famstack", version, latest)
+		}
+	}
+
+	return rows.Err()
+}
+
+// MigratePhase applies the pending migrations for a phase in version order and
+// returns the ones it applied. Pre-deploy skips post-deploy migrations, which
+// are picked up later even though newer versions have been applied by then.
+// Post-deploy and all apply everything pending.
+func (df *Fascade) MigratePhase(phase string) ([]MigrationInfo, error) {
+	if !ValidPhase(phase) {
+		return nil, fmt.Errorf("unknown migration phase %q", phase)
+	}
+	if err := df.CheckSchemaCompatibility(); err != nil {
+		return nil, err
+	}
+	if _, err := df.AdoptChecksums(); err != nil {
+		return nil, err
+	}
+
+	migrations, err := df.ListMigrations()
+	if err != nil {
+		return nil, err
+	}
+	provider, err := df.migrationProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []MigrationInfo
+	for _, migration := range migrations {
+		if migration.Applied || (phase == PhasePreDeploy && migration.Phase != PhasePreDeploy) {
+			continue
+		}
+
+		if _, err := provider.ApplyVersion(context.Background(), migration.Version, true); err != nil {
+			return applied, fmt.Errorf("failed to apply migration %s: %w", migration.Name, err)
+		}
+		if err := df.recordApplied(migration); err != nil {
+			return applied, err
+		}
+
+		migration.Applied = true
+		applied = append(applied, migration)
+	}
+
+	return applied, nil
+}
+
+// PlanMigrations describes what MigratePhase would do without applying
+// anything: each statement's lock and an estimate of how long it holds it,
+// from the current row counts of the tables it touches.
+func (df *Fascade) PlanMigrations(phase string) (*MigrationPlan, error) {
+	if !ValidPhase(phase) {
+		return nil, fmt.Errorf("unknown migration phase %q", phase)
+	}
+
+	migrations, err := df.ListMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &MigrationPlan{
+		Phase:         phase,
+		LatestVersion: latestVersion(migrations),
+		Migrations:    []MigrationInfo{},
+		Warnings:      []string{},
+	}
+	err = df.innerDb.QueryRow(`SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied`).Scan(&plan.CurrentVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema version: %w", err)
+	}
+
+	if err := df.CheckSchemaCompatibility(); err != nil {
+		plan.Warnings = append(plan.Warnings, err.Error())
+	}
+
+	rowCounts := make(map[string]int64)
+	skippedPostDeploy := 0
+	for _, migration := range migrations {
+		if migration.Applied {
+			continue
+		}
+		if phase == PhasePreDeploy && migration.Phase != PhasePreDeploy {
+			skippedPostDeploy++
+			continue
+		}
+
+		source, err := fs.ReadFile(migrationFiles(df.innerDb.Dialect), migration.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", migration.Name, err)
+		}
+		for _, statement := range upStatements(string(source)) {
+			step := analyzeStatement(statement)
+			if step.Table != "" {
+				if _, ok := rowCounts[step.Table]; !ok {
+					rowCounts[step.Table] = df.tableRowCount(step.Table)
+				}
+				step.Rows = rowCounts[step.Table]
+			}
+			step.Estimate = estimateStatement(step.Operation, step.Rows)
+			migration.Statements = append(migration.Statements, step)
+			migration.Estimate += step.Estimate
+		}
+
+		if migration.Phase == PhasePostDeploy {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s is post-deploy: run it only after every instance is on the new release", migration.Name))
+		}
+		plan.Migrations = append(plan.Migrations, migration)
+		plan.Estimate += migration.Estimate
+	}
+
+	if skippedPostDeploy > 0 {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("%d post-deploy migration(s) pending for the post-deploy phase", skippedPostDeploy))
+	}
+
+	return plan, nil
+}
+
+// migrationProvider returns a goose provider over the embedded migrations for
+// the database's dialect. Out-of-order application is allowed so post-deploy
+// migrations skipped by an earlier pre-deploy run can still be applied after
+// newer ones.
+func (df *Fascade) migrationProvider() (*goose.Provider, error) {
+	provider, err := goose.NewProvider(df.innerDb.Dialect.gooseDialect(), df.innerDb.DB, migrationFiles(df.innerDb.Dialect),
+		goose.WithAllowOutofOrder(true),
+		goose.WithDisableGlobalRegistry(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration provider: %w", err)
+	}
+	return provider, nil
+}
+
+func (df *Fascade) ensurePhaseTable() error {
+	_, err := df.innerDb.Exec(`
+		CREATE TABLE IF NOT EXISTS ` + phaseTable + ` (
+			version BIGINT PRIMARY KEY,
+			phase TEXT NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create migration phase table: %w", err)
+	}
+
+	return nil
+}
+
+// tableRowCount returns the number of rows in a table, or 0 when it doesn't exist yet
+func (df *Fascade) tableRowCount(table string) int64 {
+	tables := `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`
+	if df.innerDb.Dialect == DialectPostgres {
+		tables = `SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?`
+	}
+	var exists int
+	if err := df.innerDb.QueryRow(tables, table).Scan(&exists); err != nil || exists == 0 {
+		return 0
+	}
+
+	var count int64
+	if err := df.innerDb.QueryRow(`SELECT COUNT(*) FROM "` + table + `"`).Scan(&count); err != nil {
+		return 0
+	}
+	return count
+}
+
+// migrationFiles returns a dialect's embedded migrations
+func migrationFiles(dialect Dialect) fs.FS {
+	files, err := fs.Sub(embedMigrations, "migrations/"+string(dialect))
+	if err != nil {
+		// The embed pattern guarantees the directory exists
+		panic(err)
+	}
+	return files
+}
+
+func latestVersion(migrations []MigrationInfo) int64 {
+	var latest int64
+	for _, migration := range migrations {
+		if migration.Version > latest {
+			latest = migration.Version
+		}
+	}
+	return latest
+}
+
+// migrationPhase reads the phase directive from a migration file. Files
+// without one are pre-deploy.
+func migrationPhase(source string) string {
+	for _, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, phaseDirective) {
+			// Anything unrecognised is treated as the cautious phase
+			if strings.TrimSpace(strings.TrimPrefix(line, phaseDirective)) == PhasePreDeploy {
+				return PhasePreDeploy
+			}
+			return PhasePostDeploy
+		}
+	}
+	return PhasePreDeploy
+}
+
+// upStatements returns the statements in a migration's Up section, with comments removed
+func upStatements(source string) []string {
+	return sectionStatements(source, "Up")
+}
+
+// downStatements returns the statements in a migration's Down section, with comments removed
+func downStatements(source string) []string {
+	return sectionStatements(source, "Down")
+}
+
+// sectionStatements returns the statements in a migration's Up or Down
+// section, with comments removed
+func sectionStatements(source, section string) []string {
+	var statements []string
+	var current strings.Builder
+	inSection, inBlock := false, false
+
+	for _, line := range strings.Split(source, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "-- +goose Up"), strings.HasPrefix(trimmed, "-- +goose Down"):
+			inSection = strings.HasPrefix(trimmed, "-- +goose "+section)
+			continue
+		case strings.HasPrefix(trimmed, "-- +goose StatementBegin"):
+			inBlock = true
+			continue
+		case strings.HasPrefix(trimmed, "-- +goose StatementEnd"):
+			inBlock = false
+			if inSection && strings.TrimSpace(current.String()) != "" {
+				statements = append(statements, strings.TrimSpace(current.String()))
+			}
+			current.Reset()
+			continue
+		}
+		if !inSection {
+			continue
+		}
+
+		if i := strings.Index(line, "--"); i >= 0 {
+			line = line[:i]
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+
+		if !inBlock && strings.HasSuffix(strings.TrimSpace(line), ";") {
+			if statement := strings.TrimSpace(current.String()); statement != ";" {
+				statements = append(statements, statement)
+			}
+			current.Reset()
+		}
+	}
+
+	if statement := strings.TrimSpace(current.String()); statement != "" {
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+var statementPatterns = []struct {
+	operation string
+	pattern   *regexp.Regexp
+}{
+	{"CREATE INDEX", regexp.MustCompile(`(?is)^CREATE\s+(?:UNIQUE\s+)?INDEX\s+(?:IF\s+NOT\s+EXISTS\s+)?\S+\s+ON\s+"?(\w+)`)},
+	{"CREATE TABLE", regexp.MustCompile(`(?is)^CREATE\s+(?:VIRTUAL\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?"?(\w+)`)},
+	{"CREATE TRIGGER", regexp.MustCompile(`(?is)^CREATE\s+TRIGGER\s+(?:IF\s+NOT\s+EXISTS\s+)?\S+\s.*?\sON\s+"?(\w+)`)},
+	{"CREATE FUNCTION", regexp.MustCompile(`(?is)^CREATE\s+(?:OR\s+REPLACE\s+)?FUNCTION\s`)},
+	{"ADD COLUMN", regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+"?(\w+)"?\s+ADD\s`)},
+	{"DROP COLUMN", regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+"?(\w+)"?\s+DROP\s`)},
+	{"RENAME", regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+"?(\w+)"?\s+RENAME\s`)},
+	{"DROP TABLE", regexp.MustCompile(`(?is)^DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?"?(\w+)`)},
+	{"DROP INDEX", regexp.MustCompile(`(?is)^DROP\s+INDEX\s`)},
+	{"UPDATE", regexp.MustCompile(`(?is)^UPDATE\s+"?(\w+)`)},
+	{"DELETE", regexp.MustCompile(`(?is)^DELETE\s+FROM\s+"?(\w+)`)},
+	{"COPY", regexp.MustCompile(`(?is)^INSERT\s+.*?\sFROM\s+"?(\w+)`)},
+	{"INSERT", regexp.MustCompile(`(?is)^INSERT\s+(?:OR\s+\w+\s+)?INTO\s+"?(\w+)`)},
+}
+
+// analyzeStatement classifies a statement and names the table whose size
+// decides how long it takes. SQLite has one write lock for the whole
+// database; in WAL mode readers such as the kitchen display keep going and
+// only other writes wait.
+func analyzeStatement(statement string) StatementPlan {
+	step := StatementPlan{Operation: "OTHER", Lock: "database write lock"}
+	for _, candidate := range statementPatterns {
+		match := candidate.pattern.FindStringSubmatch(statement)
+		if match == nil {
+			continue
+		}
+		step.Operation = candidate.operation
+		if len(match) > 1 {
+			step.Table = match[1]
+		}
+		break
+	}
+
+	switch step.Operation {
+	case "CREATE TABLE", "CREATE TRIGGER", "CREATE FUNCTION", "ADD COLUMN", "RENAME", "DROP INDEX", "INSERT":
+		// Schema-only changes and single-row inserts don't depend on table size
+		step.Table = ""
+		step.Lock = "database write lock (brief)"
+	}
+	return step
+}
+
+// estimateStatement guesses how long a statement holds the write lock
+func estimateStatement(operation string, rows int64) time.Duration {
+	switch operation {
+	case "CREATE INDEX":
+		return statementCost + time.Duration(rows)*indexRowCost
+	case "DROP COLUMN", "UPDATE", "COPY":
+		return statementCost + time.Duration(rows)*rewriteRowCost
+	case "DROP TABLE", "DELETE":
+		return statementCost + time.Duration(rows)*deleteRowCost
+	default:
+		return statementCost
+	}
+}
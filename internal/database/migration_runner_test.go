@@ -0,0 +1,162 @@
+package database
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationPhase(t *testing.T) {
+	assert.Equal(t, PhasePreDeploy, migrationPhase("-- +goose Up\nCREATE TABLE a (id TEXT);"))
+	assert.Equal(t, PhasePostDeploy, migrationPhase("-- +goose Up\n-- +famstack phase: post-deploy\nALTER TABLE a DROP COLUMN b;"))
+	assert.Equal(t, PhasePreDeploy, migrationPhase("-- +famstack phase: pre-deploy\n-- +goose Up\n"))
+	// A typo errs on the side of waiting for the post-deploy phase
+	assert.Equal(t, PhasePostDeploy, migrationPhase("-- +famstack phase: post_deploy\n"))
+}
+
+func TestUpStatementsAndAnalysis(t *testing.T) {
+	source := `-- +goose Up
+-- Migration 099: example
+CREATE TABLE notes (
+    id TEXT PRIMARY KEY, -- note id
+    body TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_tasks_due ON tasks(due_date);
+ALTER TABLE tasks ADD COLUMN notes TEXT;
+UPDATE tasks SET notes = '' WHERE notes IS NULL;
+
+-- +goose Down
+DROP TABLE notes;
+`
+	statements := upStatements(source)
+	require.Len(t, statements, 4)
+
+	steps := make([]StatementPlan, len(statements))
+	for i, statement := range statements {
+		steps[i] = analyzeStatement(statement)
+	}
+
+	assert.Equal(t, "CREATE TABLE", steps[0].Operation)
+	assert.Empty(t, steps[0].Table)
+	assert.Equal(t, "CREATE INDEX", steps[1].Operation)
+	assert.Equal(t, "tasks", steps[1].Table)
+	assert.Equal(t, "ADD COLUMN", steps[2].Operation)
+	assert.Equal(t, "database write lock (brief)", steps[2].Lock)
+	assert.Equal(t, "UPDATE", steps[3].Operation)
+	assert.Equal(t, "tasks", steps[3].Table)
+
+	assert.Equal(t, statementCost, estimateStatement("ADD COLUMN", 1_000_000))
+	assert.Equal(t, statementCost+time.Second, estimateStatement("UPDATE", 200_000))
+}
+
+func TestEmbeddedMigrationsAnalyze(t *testing.T) {
+	for _, dialect := range []Dialect{DialectSQLite, DialectPostgres} {
+		entries, err := fs.ReadDir(migrationFiles(dialect), ".")
+		require.NoError(t, err)
+		require.NotEmpty(t, entries)
+
+		for _, entry := range entries {
+			source, err := fs.ReadFile(migrationFiles(dialect), entry.Name())
+			require.NoError(t, err)
+
+			statements := upStatements(string(source))
+			assert.NotEmpty(t, statements, entry.Name())
+			for _, statement := range statements {
+				assert.NotEqual(t, "OTHER", analyzeStatement(statement).Operation, "%s %s: %s", dialect, entry.Name(), statement)
+			}
+		}
+	}
+}
+
+// Every migration is written once per dialect, as the same version in the same phase
+func TestMigrationsMatchAcrossDialects(t *testing.T) {
+	sqlite, err := fs.ReadDir(migrationFiles(DialectSQLite), ".")
+	require.NoError(t, err)
+	postgres, err := fs.ReadDir(migrationFiles(DialectPostgres), ".")
+	require.NoError(t, err)
+	require.Equal(t, len(sqlite), len(postgres))
+
+	for i, entry := range sqlite {
+		assert.Equal(t, entry.Name(), postgres[i].Name())
+		sqliteSource, err := fs.ReadFile(migrationFiles(DialectSQLite), entry.Name())
+		require.NoError(t, err)
+		postgresSource, err := fs.ReadFile(migrationFiles(DialectPostgres), postgres[i].Name())
+		require.NoError(t, err)
+		assert.Equal(t, migrationPhase(string(sqliteSource)), migrationPhase(string(postgresSource)), entry.Name())
+	}
+}
+
+func TestDownStatements(t *testing.T) {
+	source := `-- +goose Up
+CREATE TABLE notes (id TEXT PRIMARY KEY);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_notes; -- first
+DROP TABLE notes;
+`
+	assert.Equal(t, []string{"DROP INDEX IF EXISTS idx_notes;", "DROP TABLE notes;"}, downStatements(source))
+	assert.Equal(t, []string{"CREATE TABLE notes (id TEXT PRIMARY KEY);"}, upStatements(source))
+}
+
+func TestMigrateToChecksumsAndBaseline(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "migrate.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	require.NoError(t, db.MigrateUp())
+	last, err := db.LastAppliedMigration()
+	require.NoError(t, err)
+	require.NotNil(t, last)
+
+	// Roll back two migrations, then come back up
+	migrations, err := db.ListMigrations()
+	require.NoError(t, err)
+	target := migrations[len(migrations)-3].Version
+	changed, err := db.MigrateTo(target)
+	require.NoError(t, err)
+	require.Len(t, changed, 2)
+	assert.False(t, changed[0].Applied)
+	assert.Equal(t, last.Version, changed[0].Version, "newest first")
+
+	up, down, err := db.MigrationsTo(last.Version)
+	require.NoError(t, err)
+	assert.Len(t, up, 2)
+	assert.Empty(t, down)
+	_, err = db.MigrateTo(last.Version)
+	require.NoError(t, err)
+
+	// A migration whose file changed after it ran shows as edited
+	_, err = db.innerDb.Exec(`UPDATE `+checksumTable+` SET checksum = 'stale' WHERE version = ?`, last.Version)
+	require.NoError(t, err)
+	edited, err := db.EditedMigrations()
+	require.NoError(t, err)
+	require.Len(t, edited, 1)
+	assert.Equal(t, last.Version, edited[0].Version)
+	require.NoError(t, db.AcceptEditedMigrations())
+	edited, err = db.EditedMigrations()
+	require.NoError(t, err)
+	assert.Empty(t, edited)
+
+	rolledBack, err := db.MigrateDown()
+	require.NoError(t, err)
+	assert.Equal(t, last.Version, rolledBack.Version)
+
+	// Baseline marks the migration applied without running it
+	marked, err := db.Baseline(last.Version)
+	require.NoError(t, err)
+	require.Len(t, marked, 1)
+	current, err := db.LastAppliedMigration()
+	require.NoError(t, err)
+	assert.Equal(t, last.Version, current.Version)
+
+	_, _, err = db.MigrationsTo(999999)
+	assert.EqualError(t, err, "no migration with version 999999")
+
+	backup := filepath.Join(t.TempDir(), "backup.db")
+	require.NoError(t, db.Backup(backup))
+	assert.FileExists(t, backup)
+}
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Migration 007: Store log lines emitted by job handlers for debugging from the admin API
+
+CREATE TABLE job_logs (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    job_id TEXT NOT NULL,
+    level TEXT NOT NULL, -- 'debug', 'info', 'warn', 'error'
+    message TEXT NOT NULL,
+    fields TEXT, -- JSON object of structured attributes
+    logged_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (job_id) REFERENCES jobs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_job_logs_job_id ON job_logs(job_id, id);
+CREATE INDEX idx_job_logs_logged_at ON job_logs(logged_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_job_logs_logged_at;
+DROP INDEX IF EXISTS idx_job_logs_job_id;
+DROP TABLE IF EXISTS job_logs CASCADE;
@@ -0,0 +1,49 @@
+-- +goose Up
+-- Migration 011: Extracurricular activity registry with seasons, fees and generated practice events
+
+CREATE TABLE activities (
+    id TEXT PRIMARY KEY DEFAULT replace(gen_random_uuid()::text, '-', ''),
+    family_id TEXT NOT NULL,
+    member_id TEXT NOT NULL, -- the child doing the activity
+    name TEXT NOT NULL,
+    team TEXT DEFAULT '',
+    coach_name TEXT DEFAULT '',
+    coach_phone TEXT DEFAULT '',
+    coach_email TEXT DEFAULT '',
+    season_start TEXT NOT NULL, -- YYYY-MM-DD, inclusive
+    season_end TEXT NOT NULL,   -- YYYY-MM-DD, inclusive
+    practice_days TEXT NOT NULL DEFAULT '[]', -- JSON array: ["tuesday", "thursday"]
+    practice_start_time TEXT,   -- HH:MM in the family's timezone
+    practice_end_time TEXT,     -- HH:MM in the family's timezone
+    location TEXT DEFAULT '',
+    equipment TEXT NOT NULL DEFAULT '[]', -- JSON array of items to have ready for the season
+    fee_cents INTEGER NOT NULL DEFAULT 0,
+    fee_paid BOOLEAN NOT NULL DEFAULT false,
+    color TEXT NOT NULL DEFAULT '#10b981',
+    checklist_task_id TEXT,
+    created_by TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (checklist_task_id) REFERENCES tasks(id) ON DELETE SET NULL,
+    FOREIGN KEY (created_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+-- Practice events generated from an activity, so they can be replaced when the schedule changes
+CREATE TABLE activity_practice_events (
+    activity_id TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+
+    PRIMARY KEY (activity_id, event_id),
+    FOREIGN KEY (activity_id) REFERENCES activities(id) ON DELETE CASCADE,
+    FOREIGN KEY (event_id) REFERENCES unified_calendar_events(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_activities_family_member ON activities(family_id, member_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_activities_family_member;
+DROP TABLE IF EXISTS activity_practice_events CASCADE;
+DROP TABLE IF EXISTS activities CASCADE;
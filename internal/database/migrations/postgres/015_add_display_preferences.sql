@@ -0,0 +1,27 @@
+-- +goose Up
+-- Migration 015: Server-side display preferences for family members and shared devices
+
+CREATE TABLE display_preferences (
+    id TEXT PRIMARY KEY DEFAULT replace(gen_random_uuid()::text, '-', ''),
+    family_id TEXT NOT NULL,
+    member_id TEXT,  -- set for a member's own preferences
+    device_id TEXT,  -- set for a shared tablet/phone, chosen by the client
+    device_name TEXT NOT NULL DEFAULT '',
+    visible_member_ids TEXT NOT NULL DEFAULT '[]', -- JSON array, empty means everyone
+    default_view TEXT NOT NULL DEFAULT 'week' CHECK (default_view IN ('day', 'week', 'month', 'agenda')),
+    range_days INTEGER NOT NULL DEFAULT 7,
+    hidden_categories TEXT NOT NULL DEFAULT '[]', -- JSON array of event categories
+    updated_by TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CHECK ((member_id IS NULL) != (device_id IS NULL)),
+    UNIQUE (family_id, member_id),
+    UNIQUE (family_id, device_id),
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (updated_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS display_preferences CASCADE;
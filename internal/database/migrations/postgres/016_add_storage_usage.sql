@@ -0,0 +1,32 @@
+-- +goose Up
+-- Migration 016: Storage usage ledger and per-family quotas for uploaded files
+
+CREATE TABLE storage_objects (
+    id TEXT PRIMARY KEY DEFAULT replace(gen_random_uuid()::text, '-', ''),
+    family_id TEXT NOT NULL,
+    member_id TEXT, -- uploader, NULL once the member is removed
+    kind TEXT NOT NULL CHECK (kind IN ('attachment', 'avatar', 'photo')),
+    storage_key TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes >= 0),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (family_id, storage_key),
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_storage_objects_family ON storage_objects(family_id, member_id);
+
+CREATE TABLE storage_quotas (
+    family_id TEXT PRIMARY KEY,
+    hard_limit_bytes BIGINT NOT NULL,
+    member_limit_bytes BIGINT, -- NULL means members share the family limit freely
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS storage_quotas CASCADE;
+DROP INDEX IF EXISTS idx_storage_objects_family;
+DROP TABLE IF EXISTS storage_objects CASCADE;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Migration 019: Aggregate daily usage counters for opt-in, local-only analytics
+-- Rows hold counts only: no user, member, address or full URL is stored.
+
+CREATE TABLE usage_counters (
+    family_id TEXT NOT NULL,
+    metric TEXT NOT NULL,
+    dimension TEXT NOT NULL DEFAULT '', -- page name for page views, otherwise empty
+    day DATE NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0 CHECK (count >= 0),
+
+    PRIMARY KEY (family_id, metric, dimension, day),
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_usage_counters_day ON usage_counters(family_id, day);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_usage_counters_day;
+DROP TABLE IF EXISTS usage_counters CASCADE;
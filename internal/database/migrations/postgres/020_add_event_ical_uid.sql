@@ -0,0 +1,11 @@
+-- +goose Up
+-- Migration 020: iCalendar UIDs for unified events written through CalDAV
+-- Events created in FamStack have no UID and export their ID instead.
+
+ALTER TABLE unified_calendar_events ADD COLUMN ical_uid TEXT;
+
+CREATE INDEX idx_unified_calendar_events_ical_uid ON unified_calendar_events(family_id, ical_uid);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_unified_calendar_events_ical_uid;
+ALTER TABLE unified_calendar_events DROP COLUMN ical_uid;
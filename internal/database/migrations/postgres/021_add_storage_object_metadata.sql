@@ -0,0 +1,10 @@
+-- +goose Up
+-- Migration 021: File name and content type for stored files, so downloads
+-- don't depend on what the storage backend remembers
+
+ALTER TABLE storage_objects ADD COLUMN file_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE storage_objects ADD COLUMN content_type TEXT NOT NULL DEFAULT 'application/octet-stream';
+
+-- +goose Down
+ALTER TABLE storage_objects DROP COLUMN content_type;
+ALTER TABLE storage_objects DROP COLUMN file_name;
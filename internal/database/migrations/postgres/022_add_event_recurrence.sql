@@ -0,0 +1,19 @@
+-- +goose Up
+-- Migration 022: recurring unified events
+-- A series is one row with an RRULE and the UTC starts of occurrences it no
+-- longer produces. An occurrence edited on its own is a separate row pointing
+-- back at its series, with the start it replaced.
+
+ALTER TABLE unified_calendar_events ADD COLUMN recurrence_rule TEXT;
+ALTER TABLE unified_calendar_events ADD COLUMN recurrence_exdates TEXT NOT NULL DEFAULT '';
+ALTER TABLE unified_calendar_events ADD COLUMN recurring_event_id TEXT;
+ALTER TABLE unified_calendar_events ADD COLUMN original_start_time TIMESTAMPTZ;
+
+CREATE INDEX idx_unified_calendar_events_recurring ON unified_calendar_events(recurring_event_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_unified_calendar_events_recurring;
+ALTER TABLE unified_calendar_events DROP COLUMN original_start_time;
+ALTER TABLE unified_calendar_events DROP COLUMN recurring_event_id;
+ALTER TABLE unified_calendar_events DROP COLUMN recurrence_exdates;
+ALTER TABLE unified_calendar_events DROP COLUMN recurrence_rule;
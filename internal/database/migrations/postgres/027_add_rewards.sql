@@ -0,0 +1,73 @@
+-- +goose Up
+-- Migration 027: Points, rewards and redemptions
+-- Members earn the points of scheduled tasks they complete. Every change to
+-- a balance is a row in points_ledger, so a balance is the sum of a member's
+-- rows and the history explains it. Parents define rewards; a member asks to
+-- redeem one and the points are spent when a parent approves.
+
+CREATE TABLE points_ledger (
+    id TEXT PRIMARY KEY,
+    family_id TEXT NOT NULL,
+    member_id TEXT NOT NULL,
+    points INTEGER NOT NULL,       -- negative when points are spent or taken back
+    reason TEXT NOT NULL,          -- task_completed, task_reopened, redemption, adjustment
+    task_id TEXT,
+    redemption_id TEXT,
+    note TEXT NOT NULL DEFAULT '',
+    created_by TEXT,
+    created_at TIMESTAMPTZ NOT NULL,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE SET NULL,
+    FOREIGN KEY (created_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_points_ledger_member_created ON points_ledger(member_id, created_at);
+CREATE INDEX idx_points_ledger_task ON points_ledger(task_id);
+
+CREATE TABLE rewards (
+    id TEXT PRIMARY KEY,
+    family_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    cost INTEGER NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by TEXT,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_rewards_family ON rewards(family_id);
+
+CREATE TABLE reward_redemptions (
+    id TEXT PRIMARY KEY,
+    family_id TEXT NOT NULL,
+    reward_id TEXT NOT NULL,
+    member_id TEXT NOT NULL,
+    cost INTEGER NOT NULL,         -- the reward's cost when it was requested
+    status TEXT NOT NULL DEFAULT 'pending', -- pending, approved, rejected
+    requested_at TIMESTAMPTZ NOT NULL,
+    decided_by TEXT,
+    decided_at TIMESTAMPTZ,
+    note TEXT NOT NULL DEFAULT '',
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (reward_id) REFERENCES rewards(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (decided_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_reward_redemptions_family_status ON reward_redemptions(family_id, status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_reward_redemptions_family_status;
+DROP TABLE IF EXISTS reward_redemptions CASCADE;
+DROP INDEX IF EXISTS idx_rewards_family;
+DROP TABLE IF EXISTS rewards CASCADE;
+DROP INDEX IF EXISTS idx_points_ledger_task;
+DROP INDEX IF EXISTS idx_points_ledger_member_created;
+DROP TABLE IF EXISTS points_ledger CASCADE;
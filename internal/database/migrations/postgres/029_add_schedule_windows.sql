@@ -0,0 +1,14 @@
+-- +goose Up
+-- Migration 029: Start and end dates and pauses for task schedules
+-- A schedule only generates tasks between start_date and end_date, and not
+-- before paused_until, so a family can stop chores over a vacation without
+-- deleting the schedule. All three are YYYY-MM-DD in the family's timezone.
+
+ALTER TABLE task_schedules ADD COLUMN start_date TEXT;
+ALTER TABLE task_schedules ADD COLUMN end_date TEXT;
+ALTER TABLE task_schedules ADD COLUMN paused_until TEXT; -- generation resumes on this date
+
+-- +goose Down
+ALTER TABLE task_schedules DROP COLUMN paused_until;
+ALTER TABLE task_schedules DROP COLUMN end_date;
+ALTER TABLE task_schedules DROP COLUMN start_date;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Migration 030: Cron expressions for task schedules
+-- Patterns days_of_week can't express, like the first Saturday of the month,
+-- are stored as a cron expression. When it is set it decides the days and
+-- time_of_day is kept in step with it.
+
+ALTER TABLE task_schedules ADD COLUMN cron_expr TEXT;
+
+-- +goose Down
+ALTER TABLE task_schedules DROP COLUMN cron_expr;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Migration 031: Due times for tasks
+-- has_due_time separates a task due at a particular time from one due at
+-- some point on its day, so overdue can be worked out for both. Generated
+-- tasks only get a due_date when their schedule has a time_of_day.
+
+ALTER TABLE tasks ADD COLUMN has_due_time BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE tasks SET has_due_time = TRUE WHERE schedule_id IS NOT NULL AND due_date IS NOT NULL;
+
+-- +goose Down
+ALTER TABLE tasks DROP COLUMN has_due_time;
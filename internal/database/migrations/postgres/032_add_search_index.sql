@@ -0,0 +1,112 @@
+-- +goose Up
+-- Migration 032: Full-text search over tasks, events and schedules
+-- search_index has a row per task, calendar event and task schedule, kept
+-- current by triggers on those tables. kind and ref_id point back at the row;
+-- family_id scopes every search. Events are searched by title and location,
+-- tasks and schedules by title and description. document is the stemmed text
+-- searched, with title words weighted above body words.
+
+CREATE TABLE search_index (
+    kind TEXT NOT NULL,      -- task, event, schedule
+    ref_id TEXT NOT NULL,
+    family_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    document TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', title), 'A') || setweight(to_tsvector('english', body), 'B')
+    ) STORED,
+    PRIMARY KEY (kind, ref_id)
+);
+
+CREATE INDEX idx_search_index_document ON search_index USING GIN (document);
+CREATE INDEX idx_search_index_family ON search_index(family_id);
+
+INSERT INTO search_index (kind, ref_id, family_id, title, body)
+SELECT 'task', id, family_id, title, COALESCE(description, '') FROM tasks;
+
+INSERT INTO search_index (kind, ref_id, family_id, title, body)
+SELECT 'event', id, family_id, title, COALESCE(location, '') FROM unified_calendar_events;
+
+INSERT INTO search_index (kind, ref_id, family_id, title, body)
+SELECT 'schedule', id, family_id, title, COALESCE(description, '') FROM task_schedules;
+
+-- +goose StatementBegin
+CREATE FUNCTION search_index_task() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        DELETE FROM search_index WHERE kind = 'task' AND ref_id = OLD.id;
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        INSERT INTO search_index (kind, ref_id, family_id, title, body)
+        VALUES ('task', NEW.id, NEW.family_id, NEW.title, COALESCE(NEW.description, ''));
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION search_index_event() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        DELETE FROM search_index WHERE kind = 'event' AND ref_id = OLD.id;
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        INSERT INTO search_index (kind, ref_id, family_id, title, body)
+        VALUES ('event', NEW.id, NEW.family_id, NEW.title, COALESCE(NEW.location, ''));
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION search_index_schedule() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        DELETE FROM search_index WHERE kind = 'schedule' AND ref_id = OLD.id;
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        INSERT INTO search_index (kind, ref_id, family_id, title, body)
+        VALUES ('schedule', NEW.id, NEW.family_id, NEW.title, COALESCE(NEW.description, ''));
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER search_index_task_insert AFTER INSERT ON tasks
+    FOR EACH ROW EXECUTE FUNCTION search_index_task();
+CREATE TRIGGER search_index_task_update AFTER UPDATE OF title, description ON tasks
+    FOR EACH ROW EXECUTE FUNCTION search_index_task();
+CREATE TRIGGER search_index_task_delete AFTER DELETE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION search_index_task();
+
+CREATE TRIGGER search_index_event_insert AFTER INSERT ON unified_calendar_events
+    FOR EACH ROW EXECUTE FUNCTION search_index_event();
+CREATE TRIGGER search_index_event_update AFTER UPDATE OF title, location ON unified_calendar_events
+    FOR EACH ROW EXECUTE FUNCTION search_index_event();
+CREATE TRIGGER search_index_event_delete AFTER DELETE ON unified_calendar_events
+    FOR EACH ROW EXECUTE FUNCTION search_index_event();
+
+CREATE TRIGGER search_index_schedule_insert AFTER INSERT ON task_schedules
+    FOR EACH ROW EXECUTE FUNCTION search_index_schedule();
+CREATE TRIGGER search_index_schedule_update AFTER UPDATE OF title, description ON task_schedules
+    FOR EACH ROW EXECUTE FUNCTION search_index_schedule();
+CREATE TRIGGER search_index_schedule_delete AFTER DELETE ON task_schedules
+    FOR EACH ROW EXECUTE FUNCTION search_index_schedule();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS search_index_schedule_delete ON task_schedules;
+DROP TRIGGER IF EXISTS search_index_schedule_update ON task_schedules;
+DROP TRIGGER IF EXISTS search_index_schedule_insert ON task_schedules;
+DROP TRIGGER IF EXISTS search_index_event_delete ON unified_calendar_events;
+DROP TRIGGER IF EXISTS search_index_event_update ON unified_calendar_events;
+DROP TRIGGER IF EXISTS search_index_event_insert ON unified_calendar_events;
+DROP TRIGGER IF EXISTS search_index_task_delete ON tasks;
+DROP TRIGGER IF EXISTS search_index_task_update ON tasks;
+DROP TRIGGER IF EXISTS search_index_task_insert ON tasks;
+DROP FUNCTION IF EXISTS search_index_schedule();
+DROP FUNCTION IF EXISTS search_index_event();
+DROP FUNCTION IF EXISTS search_index_task();
+DROP TABLE IF EXISTS search_index CASCADE;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Migration 035: Job checkpoints
+-- A long-running job saves its progress as JSON, e.g. the date a calendar sync
+-- has reached, so a run cut short by a shutdown or crash resumes from there.
+
+ALTER TABLE jobs ADD COLUMN checkpoint TEXT;
+ALTER TABLE jobs ADD COLUMN checkpoint_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE jobs DROP COLUMN checkpoint_at;
+ALTER TABLE jobs DROP COLUMN checkpoint;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Migration 037: Passwordless sign-in
+-- Members without a password, usually kids, sign in with a link emailed to
+-- them or a short code a parent creates. Only a hash of each token is kept,
+-- and a token works once.
+
+CREATE TABLE login_tokens (
+    token_hash TEXT PRIMARY KEY,        -- hex SHA-256 of the token or code
+    member_id TEXT NOT NULL,
+    kind TEXT NOT NULL,                 -- link, code
+    created_by TEXT,                    -- the parent who created a code
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,                -- NULL until redeemed
+    created_at TIMESTAMPTZ NOT NULL,
+
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_login_tokens_member ON login_tokens(member_id, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_login_tokens_member;
+DROP TABLE IF EXISTS login_tokens CASCADE;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Migration 041: Account linking codes
+-- Voice assistants link to a member's account with the OAuth authorization
+-- code flow. A code is handed to the assistant's redirect URI once the
+-- member allows it, and traded within minutes for an API token. Only a
+-- hash of each code is kept.
+
+CREATE TABLE account_link_codes (
+    code_hash TEXT PRIMARY KEY,         -- hex SHA-256 of the code
+    client_id TEXT NOT NULL,            -- the assistant it was issued to
+    redirect_uri TEXT NOT NULL,         -- must be sent again to trade it
+    family_id TEXT NOT NULL,
+    member_id TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS account_link_codes CASCADE;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Migration 044: birthdays and anniversaries on the calendar
+-- Members can record a birthdate and a wedding anniversary (YYYY-MM-DD). Each
+-- becomes a yearly all-day series that a job keeps in step with the members,
+-- marked with what it celebrates: the member for a birthday, the date for an
+-- anniversary, which members who share it share.
+
+ALTER TABLE family_members ADD COLUMN birthdate TEXT;
+ALTER TABLE family_members ADD COLUMN anniversary TEXT;
+
+ALTER TABLE unified_calendar_events ADD COLUMN celebration_kind TEXT;
+ALTER TABLE unified_calendar_events ADD COLUMN celebration_key TEXT;
+
+CREATE UNIQUE INDEX idx_unified_calendar_events_celebration
+    ON unified_calendar_events(family_id, celebration_kind, celebration_key);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_unified_calendar_events_celebration;
+ALTER TABLE unified_calendar_events DROP COLUMN celebration_key;
+ALTER TABLE unified_calendar_events DROP COLUMN celebration_kind;
+ALTER TABLE family_members DROP COLUMN anniversary;
+ALTER TABLE family_members DROP COLUMN birthdate;
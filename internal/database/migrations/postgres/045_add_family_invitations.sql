@@ -0,0 +1,30 @@
+-- +goose Up
+-- Migration 045: Family invitations
+-- A parent invites someone to take over a member who has no sign-in yet, with
+-- a link emailed to them or a code handed over. Accepting sets the member's
+-- email and password. Only hashes of the link token and code are kept.
+
+CREATE TABLE family_invitations (
+    id TEXT PRIMARY KEY,
+    family_id TEXT NOT NULL,
+    member_id TEXT NOT NULL,
+    role TEXT NOT NULL,                 -- user, admin
+    email TEXT,                         -- where the link went, if anywhere
+    token_hash TEXT NOT NULL UNIQUE,    -- hex SHA-256 of the link token
+    code_hash TEXT NOT NULL UNIQUE,     -- hex SHA-256 of the code
+    created_by TEXT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_family_invitations_family ON family_invitations(family_id, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_family_invitations_family;
+DROP TABLE IF EXISTS family_invitations CASCADE;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- Migration 046: Guest passes
+-- A parent gives a babysitter or grandparent a link that shows today's
+-- schedule and the tasks the parent picked, and can let them tick those
+-- tasks off. A pass runs out on its own and can be extended or revoked. Only
+-- a hash of the link's token is kept.
+
+CREATE TABLE guest_passes (
+    id TEXT PRIMARY KEY,
+    family_id TEXT NOT NULL,
+    name TEXT NOT NULL,                 -- who the guest is, e.g. "Grandma"
+    access TEXT NOT NULL,               -- read, tasks
+    task_ids TEXT NOT NULL DEFAULT '',  -- comma-separated tasks the guest sees
+    token_hash TEXT NOT NULL UNIQUE,    -- hex SHA-256 of the link's token
+    created_by TEXT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_guest_passes_family ON guest_passes(family_id, expires_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_guest_passes_family;
+DROP TABLE IF EXISTS guest_passes CASCADE;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Migration 050: Color rules can match an event's source, type and attendees
+
+ALTER TABLE event_color_rules DROP CONSTRAINT event_color_rules_field_check;
+ALTER TABLE event_color_rules ADD CONSTRAINT event_color_rules_field_check
+    CHECK (field IN ('title', 'description', 'location', 'organizer', 'source', 'event_type', 'attendee'));
+
+-- +goose Down
+DELETE FROM event_color_rules WHERE field IN ('source', 'event_type', 'attendee');
+ALTER TABLE event_color_rules DROP CONSTRAINT event_color_rules_field_check;
+ALTER TABLE event_color_rules ADD CONSTRAINT event_color_rules_field_check
+    CHECK (field IN ('title', 'description', 'location', 'organizer'));
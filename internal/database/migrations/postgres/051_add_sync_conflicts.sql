@@ -0,0 +1,30 @@
+-- +goose Up
+-- Migration 051: sync conflicts
+-- Synced events remember when the sync last wrote them, so an edit made since
+-- shows up as a later updated_at. When the remote changes such an event too,
+-- the integration's conflict policy decides which side wins; under the manual
+-- policy the remote's version waits here until someone picks one.
+
+ALTER TABLE unified_calendar_events ADD COLUMN source_synced_at TIMESTAMPTZ;
+UPDATE unified_calendar_events SET source_synced_at = updated_at WHERE source_integration_id IS NOT NULL;
+
+CREATE TABLE event_sync_conflicts (
+    id TEXT PRIMARY KEY,
+    family_id TEXT NOT NULL,
+    integration_id TEXT NOT NULL,
+    event_id TEXT NOT NULL UNIQUE,  -- an event has at most one pending conflict
+    remote TEXT NOT NULL,           -- the remote's version of the event, JSON
+    remote_hash TEXT NOT NULL,      -- the source_hash the event gets if the remote wins
+    detected_at TIMESTAMPTZ NOT NULL,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (integration_id) REFERENCES integrations(id) ON DELETE CASCADE,
+    FOREIGN KEY (event_id) REFERENCES unified_calendar_events(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_event_sync_conflicts_integration ON event_sync_conflicts(integration_id, detected_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_event_sync_conflicts_integration;
+DROP TABLE IF EXISTS event_sync_conflicts;
+ALTER TABLE unified_calendar_events DROP COLUMN source_synced_at;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Migration 052: incremental calendar sync
+-- A synced account has several calendars and the provider issues a sync
+-- token for each, listing only what changed since the last sync. Sync
+-- history records whether a run was full or incremental and what it changed.
+
+CREATE TABLE integration_sync_tokens (
+    integration_id TEXT NOT NULL,
+    resource_id TEXT NOT NULL,   -- the provider's calendar the token belongs to
+    sync_token TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (integration_id, resource_id),
+    FOREIGN KEY (integration_id) REFERENCES integrations(id) ON DELETE CASCADE
+);
+
+ALTER TABLE integration_sync_history ADD COLUMN sync_mode TEXT NOT NULL DEFAULT 'full'; -- 'full', 'incremental'
+ALTER TABLE integration_sync_history ADD COLUMN items_changed INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE integration_sync_history ADD COLUMN items_removed INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE integration_sync_history DROP COLUMN items_removed;
+ALTER TABLE integration_sync_history DROP COLUMN items_changed;
+ALTER TABLE integration_sync_history DROP COLUMN sync_mode;
+DROP TABLE IF EXISTS integration_sync_tokens;
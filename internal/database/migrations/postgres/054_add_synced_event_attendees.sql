@@ -0,0 +1,13 @@
+-- +goose Up
+-- Migration 054: attendees of synced events
+-- A JSON list of the people invited to an event synced from a provider, as
+-- [{"email": "...", "name": "...", "member_id": "...", "response": "..."}].
+-- Providers name attendees by email; member_id is set when the email is a
+-- family member's, so an attendee from outside the family has only an email.
+-- The synced_attendee_repair job rewrites rows not in this shape and fills
+-- in member_id for emails that match a member.
+
+ALTER TABLE calendar_events ADD COLUMN attendees TEXT NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE calendar_events DROP COLUMN attendees;
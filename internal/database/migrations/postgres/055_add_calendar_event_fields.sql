@@ -0,0 +1,15 @@
+-- +goose Up
+-- Migration 055: fields the calendar event API reads and writes
+-- calendar_events was created for raw synced events, but events created
+-- through the API, and synced events, also record their type, who they are
+-- assigned to and who created them. Rows from before this migration read as
+-- plain events with no assignee or creator.
+
+ALTER TABLE calendar_events ADD COLUMN event_type TEXT NOT NULL DEFAULT 'event';
+ALTER TABLE calendar_events ADD COLUMN assigned_to TEXT;
+ALTER TABLE calendar_events ADD COLUMN created_by TEXT;
+
+-- +goose Down
+ALTER TABLE calendar_events DROP COLUMN created_by;
+ALTER TABLE calendar_events DROP COLUMN assigned_to;
+ALTER TABLE calendar_events DROP COLUMN event_type;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Migration 056: synced events are unique per family and provider
+-- external_id was unique across every family, so a second family syncing an
+-- event the first already had could not store it.
+
+ALTER TABLE calendar_events DROP CONSTRAINT IF EXISTS calendar_events_external_id_key;
+CREATE UNIQUE INDEX idx_calendar_events_family_source ON calendar_events(family_id, external_source, external_id);
+
+-- +goose Down
+-- Fails while two families hold the same external_id
+DROP INDEX idx_calendar_events_family_source;
+ALTER TABLE calendar_events ADD CONSTRAINT calendar_events_external_id_key UNIQUE (external_id);
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Migration 006: Record why a job failed so timeouts can be told apart from handler errors
+
+ALTER TABLE jobs ADD COLUMN failure_reason TEXT; -- 'error', 'timeout', 'no_handler'
+
+CREATE INDEX idx_jobs_failure_reason ON jobs(failure_reason);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_jobs_failure_reason;
+ALTER TABLE jobs DROP COLUMN failure_reason;
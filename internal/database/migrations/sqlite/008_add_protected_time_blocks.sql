@@ -0,0 +1,25 @@
+-- +goose Up
+-- Migration 008: Family-wide "do not schedule" blocks (e.g. family dinner, Sunday mornings)
+
+CREATE TABLE protected_time_blocks (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    family_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    days_of_week TEXT NOT NULL DEFAULT '[]', -- JSON array: ["sunday"], empty means every day
+    start_time TEXT NOT NULL, -- HH:MM in the family's timezone
+    end_time TEXT NOT NULL,   -- HH:MM in the family's timezone
+    color TEXT NOT NULL DEFAULT '#9ca3af',
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_by TEXT,
+    created_at DATETIME DEFAULT (datetime('now', 'utc')),
+    updated_at DATETIME DEFAULT (datetime('now', 'utc')),
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_protected_time_blocks_family ON protected_time_blocks(family_id, active);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_protected_time_blocks_family;
+DROP TABLE IF EXISTS protected_time_blocks;
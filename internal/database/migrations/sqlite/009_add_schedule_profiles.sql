@@ -0,0 +1,32 @@
+-- +goose Up
+-- Migration 009: Seasonal schedule profiles (e.g. school year vs summer) that switch which schedules generate tasks
+
+CREATE TABLE schedule_profiles (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    family_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    start_date TEXT NOT NULL, -- YYYY-MM-DD, inclusive
+    end_date TEXT NOT NULL,   -- YYYY-MM-DD, inclusive
+    created_at DATETIME DEFAULT (datetime('now', 'utc')),
+    updated_at DATETIME DEFAULT (datetime('now', 'utc')),
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE
+);
+
+CREATE TABLE schedule_profile_members (
+    profile_id TEXT NOT NULL,
+    schedule_id TEXT NOT NULL,
+
+    PRIMARY KEY (profile_id, schedule_id),
+    FOREIGN KEY (profile_id) REFERENCES schedule_profiles(id) ON DELETE CASCADE,
+    FOREIGN KEY (schedule_id) REFERENCES task_schedules(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_schedule_profiles_family ON schedule_profiles(family_id, start_date);
+CREATE INDEX idx_schedule_profile_members_schedule ON schedule_profile_members(schedule_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_schedule_profile_members_schedule;
+DROP INDEX IF EXISTS idx_schedule_profiles_family;
+DROP TABLE IF EXISTS schedule_profile_members;
+DROP TABLE IF EXISTS schedule_profiles;
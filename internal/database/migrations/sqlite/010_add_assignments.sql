@@ -0,0 +1,51 @@
+-- +goose Up
+-- Migration 010: Homework tracker - courses and assignments for school-age kids
+
+CREATE TABLE courses (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    family_id TEXT NOT NULL,
+    member_id TEXT NOT NULL, -- the student taking the course
+    name TEXT NOT NULL,
+    teacher TEXT DEFAULT '',
+    color TEXT NOT NULL DEFAULT '#6366f1',
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at DATETIME DEFAULT (datetime('now', 'utc')),
+    updated_at DATETIME DEFAULT (datetime('now', 'utc')),
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE
+);
+
+CREATE TABLE assignments (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    family_id TEXT NOT NULL,
+    course_id TEXT NOT NULL,
+    member_id TEXT NOT NULL, -- copied from the course so per-child queries skip the join
+    title TEXT NOT NULL,
+    description TEXT DEFAULT '',
+    due_date DATETIME NOT NULL,
+    estimated_minutes INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'not_started' CHECK (status IN ('not_started', 'in_progress', 'completed')),
+    task_id TEXT, -- deadline task shown in the daily view
+    created_by TEXT,
+    created_at DATETIME DEFAULT (datetime('now', 'utc')),
+    updated_at DATETIME DEFAULT (datetime('now', 'utc')),
+    completed_at DATETIME,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE SET NULL,
+    FOREIGN KEY (created_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_courses_family_member ON courses(family_id, member_id);
+CREATE INDEX idx_assignments_family_due ON assignments(family_id, status, due_date);
+CREATE INDEX idx_assignments_course ON assignments(course_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_assignments_course;
+DROP INDEX IF EXISTS idx_assignments_family_due;
+DROP INDEX IF EXISTS idx_courses_family_member;
+DROP TABLE IF EXISTS assignments;
+DROP TABLE IF EXISTS courses;
@@ -0,0 +1,85 @@
+-- +goose Up
+-- Migration 012: Carpool groups that rotate driving for an activity across families
+
+CREATE TABLE carpool_groups (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    family_id TEXT NOT NULL,   -- the organizing family
+    activity_id TEXT NOT NULL, -- practices come from the organizer's activity
+    name TEXT NOT NULL,
+    join_code TEXT NOT NULL UNIQUE, -- shared with other famstack families so they can join
+    created_by TEXT,
+    created_at DATETIME DEFAULT (datetime('now', 'utc')),
+    updated_at DATETIME DEFAULT (datetime('now', 'utc')),
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (activity_id) REFERENCES activities(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+-- Drivers are either famstack family members (family_id/member_id set) or
+-- external contacts identified only by name and contact details
+CREATE TABLE carpool_drivers (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    group_id TEXT NOT NULL,
+    family_id TEXT,
+    member_id TEXT,
+    name TEXT NOT NULL,
+    phone TEXT DEFAULT '',
+    email TEXT DEFAULT '',
+    position INTEGER NOT NULL DEFAULT 0, -- rotation order
+    created_at DATETIME DEFAULT (datetime('now', 'utc')),
+
+    FOREIGN KEY (group_id) REFERENCES carpool_groups(id) ON DELETE CASCADE,
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE,
+    UNIQUE(group_id, member_id)
+);
+
+-- One driver per practice date. task_id is the reminder task in the driver's family.
+CREATE TABLE carpool_assignments (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    group_id TEXT NOT NULL,
+    drive_date TEXT NOT NULL, -- YYYY-MM-DD in the organizer's timezone
+    event_id TEXT,
+    driver_id TEXT NOT NULL,
+    task_id TEXT,
+    created_at DATETIME DEFAULT (datetime('now', 'utc')),
+    updated_at DATETIME DEFAULT (datetime('now', 'utc')),
+
+    FOREIGN KEY (group_id) REFERENCES carpool_groups(id) ON DELETE CASCADE,
+    FOREIGN KEY (event_id) REFERENCES unified_calendar_events(id) ON DELETE SET NULL,
+    FOREIGN KEY (driver_id) REFERENCES carpool_drivers(id) ON DELETE CASCADE,
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE SET NULL,
+    UNIQUE(group_id, drive_date)
+);
+
+CREATE TABLE carpool_swap_requests (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    group_id TEXT NOT NULL,
+    assignment_id TEXT NOT NULL,
+    from_driver_id TEXT NOT NULL,
+    to_driver_id TEXT NOT NULL,
+    requested_by_family_id TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined', 'cancelled')),
+    note TEXT DEFAULT '',
+    created_at DATETIME DEFAULT (datetime('now', 'utc')),
+    resolved_at DATETIME,
+
+    FOREIGN KEY (group_id) REFERENCES carpool_groups(id) ON DELETE CASCADE,
+    FOREIGN KEY (assignment_id) REFERENCES carpool_assignments(id) ON DELETE CASCADE,
+    FOREIGN KEY (from_driver_id) REFERENCES carpool_drivers(id) ON DELETE CASCADE,
+    FOREIGN KEY (to_driver_id) REFERENCES carpool_drivers(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_carpool_drivers_family ON carpool_drivers(family_id);
+CREATE INDEX idx_carpool_assignments_group_date ON carpool_assignments(group_id, drive_date);
+CREATE INDEX idx_carpool_swap_requests_group ON carpool_swap_requests(group_id, status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_carpool_swap_requests_group;
+DROP INDEX IF EXISTS idx_carpool_assignments_group_date;
+DROP INDEX IF EXISTS idx_carpool_drivers_family;
+DROP TABLE IF EXISTS carpool_swap_requests;
+DROP TABLE IF EXISTS carpool_assignments;
+DROP TABLE IF EXISTS carpool_drivers;
+DROP TABLE IF EXISTS carpool_groups;
@@ -0,0 +1,39 @@
+-- +goose Up
+-- Migration 013: Suggestion engine nudges and weekly digests
+
+CREATE TABLE suggestions (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    family_id TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('skipped_schedule', 'idle_member', 'missing_pickup')),
+    title TEXT NOT NULL,
+    detail TEXT DEFAULT '',
+    action_type TEXT NOT NULL CHECK (action_type IN ('pause_schedule', 'assign_task', 'add_attendee')),
+    target_id TEXT NOT NULL,  -- schedule, task or event the action applies to
+    member_id TEXT,           -- member the action assigns, when it assigns someone
+    dedupe_key TEXT NOT NULL, -- one suggestion per pattern occurrence, so dismissals stick
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'applied', 'dismissed')),
+    created_at DATETIME DEFAULT (datetime('now', 'utc')),
+    resolved_at DATETIME,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE,
+    UNIQUE(family_id, dedupe_key)
+);
+
+CREATE TABLE suggestion_digests (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    family_id TEXT NOT NULL,
+    week_start TEXT NOT NULL, -- YYYY-MM-DD, Monday
+    payload TEXT NOT NULL,    -- JSON digest
+    created_at DATETIME DEFAULT (datetime('now', 'utc')),
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    UNIQUE(family_id, week_start)
+);
+
+CREATE INDEX idx_suggestions_family_status ON suggestions(family_id, status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_suggestions_family_status;
+DROP TABLE IF EXISTS suggestion_digests;
+DROP TABLE IF EXISTS suggestions;
@@ -0,0 +1,35 @@
+-- +goose Up
+-- Migration 014: Event auto-coloring rules evaluated when events are created or synced
+
+CREATE TABLE event_color_rules (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    family_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    field TEXT NOT NULL CHECK (field IN ('title', 'description', 'location', 'organizer')),
+    match_type TEXT NOT NULL CHECK (match_type IN ('contains', 'equals', 'domain')),
+    pattern TEXT NOT NULL,
+    category TEXT NOT NULL DEFAULT '',
+    color TEXT NOT NULL DEFAULT '',
+    position INTEGER NOT NULL DEFAULT 0, -- lower runs first, the first match wins
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_by TEXT,
+    created_at DATETIME DEFAULT (datetime('now', 'utc')),
+    updated_at DATETIME DEFAULT (datetime('now', 'utc')),
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_event_color_rules_family ON event_color_rules(family_id, position);
+
+-- Category assigned by the matching rule, alongside the existing color
+ALTER TABLE unified_calendar_events ADD COLUMN category TEXT NOT NULL DEFAULT '';
+ALTER TABLE calendar_events ADD COLUMN color TEXT;
+ALTER TABLE calendar_events ADD COLUMN category TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE calendar_events DROP COLUMN category;
+ALTER TABLE calendar_events DROP COLUMN color;
+ALTER TABLE unified_calendar_events DROP COLUMN category;
+DROP INDEX IF EXISTS idx_event_color_rules_family;
+DROP TABLE IF EXISTS event_color_rules;
@@ -0,0 +1,43 @@
+-- +goose Up
+-- Migration 017: Custody schedules for members who split time between households
+
+CREATE TABLE custody_schedules (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    family_id TEXT NOT NULL,
+    member_id TEXT NOT NULL,
+    label TEXT NOT NULL DEFAULT '',
+    days_of_week TEXT NOT NULL,                -- JSON array of lowercase weekday names
+    interval_weeks INTEGER NOT NULL DEFAULT 1 CHECK (interval_weeks BETWEEN 1 AND 4),
+    anchor_date TEXT NOT NULL,                 -- YYYY-MM-DD; the week containing it is an "on" week
+    start_date TEXT,                           -- YYYY-MM-DD, inclusive; NULL means open-ended
+    end_date TEXT,                             -- YYYY-MM-DD, inclusive; NULL means open-ended
+    created_at DATETIME DEFAULT (datetime('now', 'utc')),
+    updated_at DATETIME DEFAULT (datetime('now', 'utc')),
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE
+);
+
+-- One-off swaps (holidays, trades) that win over the regular schedule
+CREATE TABLE custody_overrides (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    family_id TEXT NOT NULL,
+    member_id TEXT NOT NULL,
+    date TEXT NOT NULL, -- YYYY-MM-DD
+    present BOOLEAN NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT (datetime('now', 'utc')),
+
+    UNIQUE (member_id, date),
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_custody_schedules_family ON custody_schedules(family_id, member_id);
+CREATE INDEX idx_custody_overrides_family ON custody_overrides(family_id, date);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_custody_overrides_family;
+DROP INDEX IF EXISTS idx_custody_schedules_family;
+DROP TABLE IF EXISTS custody_overrides;
+DROP TABLE IF EXISTS custody_schedules;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Migration 018: Clamp stored priorities to the 0-3 range
+-- Status and type columns already have CHECK constraints that keep them
+-- lowercase. Priority had no bound and the task API accepted values up to 10.
+
+UPDATE tasks SET priority = 3 WHERE priority > 3;
+UPDATE tasks SET priority = 0 WHERE priority < 0 OR priority IS NULL;
+
+UPDATE task_schedules SET priority = 3 WHERE priority > 3;
+UPDATE task_schedules SET priority = 0 WHERE priority < 0 OR priority IS NULL;
+
+UPDATE unified_calendar_events SET priority = 3 WHERE priority > 3;
+UPDATE unified_calendar_events SET priority = 0 WHERE priority < 0 OR priority IS NULL;
+
+-- +goose Down
+-- Clamped priorities are not restored
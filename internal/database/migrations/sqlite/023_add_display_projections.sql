@@ -0,0 +1,17 @@
+-- +goose Up
+-- Migration 023: Read model for the wall display
+-- The next 48 hours of each family's events and timelines, rebuilt in the
+-- background so display reads don't compete with writes and sync bursts.
+
+CREATE TABLE display_projections (
+    family_id TEXT PRIMARY KEY,
+    window_start DATETIME NOT NULL,
+    window_end DATETIME NOT NULL,
+    payload TEXT NOT NULL,    -- JSON projection
+    generated_at DATETIME NOT NULL,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS display_projections;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Migration 024: Family focus sessions
+-- Time-boxed quiet time (homework hour) that a parent starts for the whole
+-- family. Finished sessions stay as the family's focus log.
+
+CREATE TABLE focus_sessions (
+    id TEXT PRIMARY KEY,
+    family_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    started_by TEXT,
+    started_at DATETIME NOT NULL,
+    ends_at DATETIME NOT NULL,
+    ended_at DATETIME,           -- set when a parent ends the session early
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (started_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_focus_sessions_family_started ON focus_sessions(family_id, started_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_focus_sessions_family_started;
+DROP TABLE IF EXISTS focus_sessions;
@@ -0,0 +1,55 @@
+-- +goose Up
+-- Migration 025: Event reminders and their delivery
+-- Each reminder fires a set number of minutes before every occurrence of its
+-- event, through one channel, to the event's attendees. Deliveries are logged
+-- per occurrence and member so a retried job never sends twice.
+
+CREATE TABLE event_reminders (
+    id TEXT PRIMARY KEY,
+    family_id TEXT NOT NULL,
+    event_id TEXT NOT NULL,          -- the event, or the series of a recurring event
+    minutes_before INTEGER NOT NULL,
+    channel TEXT NOT NULL,           -- email or web_push
+    created_by TEXT,
+    created_at DATETIME NOT NULL,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (event_id) REFERENCES unified_calendar_events(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES family_members(id) ON DELETE SET NULL,
+    UNIQUE (event_id, minutes_before, channel)
+);
+
+CREATE INDEX idx_event_reminders_family ON event_reminders(family_id);
+
+CREATE TABLE reminder_deliveries (
+    reminder_id TEXT NOT NULL,
+    occurrence_start DATETIME NOT NULL,
+    member_id TEXT NOT NULL,
+    status TEXT NOT NULL,            -- sent, unreachable or failed
+    error TEXT,
+    delivered_at DATETIME NOT NULL,
+
+    PRIMARY KEY (reminder_id, occurrence_start, member_id),
+    FOREIGN KEY (reminder_id) REFERENCES event_reminders(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE
+);
+
+CREATE TABLE push_subscriptions (
+    endpoint TEXT PRIMARY KEY,
+    member_id TEXT NOT NULL,
+    p256dh TEXT NOT NULL,
+    auth TEXT NOT NULL,
+    user_agent TEXT,
+    created_at DATETIME NOT NULL,
+
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_push_subscriptions_member ON push_subscriptions(member_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_push_subscriptions_member;
+DROP TABLE IF EXISTS push_subscriptions;
+DROP TABLE IF EXISTS reminder_deliveries;
+DROP INDEX IF EXISTS idx_event_reminders_family;
+DROP TABLE IF EXISTS event_reminders;
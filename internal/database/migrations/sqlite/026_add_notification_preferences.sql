@@ -0,0 +1,20 @@
+-- +goose Up
+-- Migration 026: Per-member notification preferences
+-- Members opt in to a daily agenda digest, delivered at a local time of their
+-- choosing on one of the server's notification channels. Members without a
+-- row get the defaults: no digest.
+
+CREATE TABLE notification_preferences (
+    member_id TEXT PRIMARY KEY,
+    family_id TEXT NOT NULL,
+    daily_digest BOOLEAN NOT NULL DEFAULT FALSE,
+    digest_time TEXT NOT NULL DEFAULT '07:00',  -- HH:MM in the family's timezone
+    digest_channel TEXT NOT NULL DEFAULT 'email',
+    updated_at DATETIME NOT NULL,
+
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_preferences;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Migration 028: Rotating chore assignment
+-- A schedule can hand its tasks to a list of members in turn instead of one
+-- assignee. daily and weekly rotations count days or weeks from the anchor
+-- date; per_task rotations hand each generated task to the next member and
+-- remember who is next in rotation_index.
+
+ALTER TABLE task_schedules ADD COLUMN rotation_strategy TEXT NOT NULL DEFAULT 'none'; -- none, daily, weekly, per_task
+ALTER TABLE task_schedules ADD COLUMN rotation_members TEXT;                           -- JSON array of member IDs, in order
+ALTER TABLE task_schedules ADD COLUMN rotation_anchor TEXT;                            -- YYYY-MM-DD the first member's turn starts
+ALTER TABLE task_schedules ADD COLUMN rotation_index INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE task_schedules DROP COLUMN rotation_index;
+ALTER TABLE task_schedules DROP COLUMN rotation_anchor;
+ALTER TABLE task_schedules DROP COLUMN rotation_members;
+ALTER TABLE task_schedules DROP COLUMN rotation_strategy;
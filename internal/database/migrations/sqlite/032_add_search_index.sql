@@ -0,0 +1,99 @@
+-- +goose Up
+-- Migration 032: Full-text search over tasks, events and schedules
+-- search_index is an FTS5 table with a row per task, calendar event and task
+-- schedule, kept current by triggers on those tables. kind and ref_id point
+-- back at the row; family_id scopes every search. Events are searched by
+-- title and location, tasks and schedules by title and description.
+
+CREATE VIRTUAL TABLE search_index USING fts5(
+    kind UNINDEXED,      -- task, event, schedule
+    ref_id UNINDEXED,
+    family_id UNINDEXED,
+    title,
+    body,
+    tokenize = 'porter unicode61'
+);
+
+INSERT INTO search_index (kind, ref_id, family_id, title, body)
+SELECT 'task', id, family_id, title, COALESCE(description, '') FROM tasks;
+
+INSERT INTO search_index (kind, ref_id, family_id, title, body)
+SELECT 'event', id, family_id, title, COALESCE(location, '') FROM unified_calendar_events;
+
+INSERT INTO search_index (kind, ref_id, family_id, title, body)
+SELECT 'schedule', id, family_id, title, COALESCE(description, '') FROM task_schedules;
+
+-- +goose StatementBegin
+CREATE TRIGGER search_index_task_insert AFTER INSERT ON tasks BEGIN
+    INSERT INTO search_index (kind, ref_id, family_id, title, body)
+    VALUES ('task', NEW.id, NEW.family_id, NEW.title, COALESCE(NEW.description, ''));
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER search_index_task_update AFTER UPDATE OF title, description ON tasks BEGIN
+    DELETE FROM search_index WHERE kind = 'task' AND ref_id = OLD.id;
+    INSERT INTO search_index (kind, ref_id, family_id, title, body)
+    VALUES ('task', NEW.id, NEW.family_id, NEW.title, COALESCE(NEW.description, ''));
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER search_index_task_delete AFTER DELETE ON tasks BEGIN
+    DELETE FROM search_index WHERE kind = 'task' AND ref_id = OLD.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER search_index_event_insert AFTER INSERT ON unified_calendar_events BEGIN
+    INSERT INTO search_index (kind, ref_id, family_id, title, body)
+    VALUES ('event', NEW.id, NEW.family_id, NEW.title, COALESCE(NEW.location, ''));
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER search_index_event_update AFTER UPDATE OF title, location ON unified_calendar_events BEGIN
+    DELETE FROM search_index WHERE kind = 'event' AND ref_id = OLD.id;
+    INSERT INTO search_index (kind, ref_id, family_id, title, body)
+    VALUES ('event', NEW.id, NEW.family_id, NEW.title, COALESCE(NEW.location, ''));
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER search_index_event_delete AFTER DELETE ON unified_calendar_events BEGIN
+    DELETE FROM search_index WHERE kind = 'event' AND ref_id = OLD.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER search_index_schedule_insert AFTER INSERT ON task_schedules BEGIN
+    INSERT INTO search_index (kind, ref_id, family_id, title, body)
+    VALUES ('schedule', NEW.id, NEW.family_id, NEW.title, COALESCE(NEW.description, ''));
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER search_index_schedule_update AFTER UPDATE OF title, description ON task_schedules BEGIN
+    DELETE FROM search_index WHERE kind = 'schedule' AND ref_id = OLD.id;
+    INSERT INTO search_index (kind, ref_id, family_id, title, body)
+    VALUES ('schedule', NEW.id, NEW.family_id, NEW.title, COALESCE(NEW.description, ''));
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER search_index_schedule_delete AFTER DELETE ON task_schedules BEGIN
+    DELETE FROM search_index WHERE kind = 'schedule' AND ref_id = OLD.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER IF EXISTS search_index_schedule_delete;
+DROP TRIGGER IF EXISTS search_index_schedule_update;
+DROP TRIGGER IF EXISTS search_index_schedule_insert;
+DROP TRIGGER IF EXISTS search_index_event_delete;
+DROP TRIGGER IF EXISTS search_index_event_update;
+DROP TRIGGER IF EXISTS search_index_event_insert;
+DROP TRIGGER IF EXISTS search_index_task_delete;
+DROP TRIGGER IF EXISTS search_index_task_update;
+DROP TRIGGER IF EXISTS search_index_task_insert;
+DROP TABLE IF EXISTS search_index;
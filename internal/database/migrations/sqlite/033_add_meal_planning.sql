@@ -0,0 +1,83 @@
+-- +goose Up
+-- Migration 033: Recipes, a weekly meal plan and a shopping list
+-- Each day has at most one meal of each type. A planned dinner gets a
+-- calendar event, kept in step with the meal through event_id. Shopping list
+-- items are added by hand or generated from the ingredients of planned
+-- meals' recipes.
+
+CREATE TABLE recipes (
+    id TEXT PRIMARY KEY,
+    family_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    instructions TEXT NOT NULL DEFAULT '',
+    servings INTEGER NOT NULL DEFAULT 0,  -- 0 when not given
+    created_by TEXT,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_recipes_family ON recipes(family_id);
+
+CREATE TABLE recipe_ingredients (
+    id TEXT PRIMARY KEY,
+    recipe_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    quantity REAL NOT NULL DEFAULT 0,     -- 0 when not given, e.g. "salt"
+    unit TEXT NOT NULL DEFAULT '',        -- free text: cups, g, cans
+    position INTEGER NOT NULL DEFAULT 0,  -- order in the recipe
+
+    FOREIGN KEY (recipe_id) REFERENCES recipes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_recipe_ingredients_recipe ON recipe_ingredients(recipe_id, position);
+
+CREATE TABLE meal_plans (
+    id TEXT PRIMARY KEY,
+    family_id TEXT NOT NULL,
+    meal_date TEXT NOT NULL,   -- YYYY-MM-DD in the family's timezone
+    meal_type TEXT NOT NULL,   -- breakfast, lunch, dinner, snack
+    recipe_id TEXT,
+    title TEXT NOT NULL,       -- the recipe's title unless given
+    notes TEXT NOT NULL DEFAULT '',
+    meal_time TEXT,            -- HH:MM, used for a dinner's event
+    event_id TEXT,             -- the dinner's calendar event
+    created_by TEXT,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (recipe_id) REFERENCES recipes(id) ON DELETE SET NULL,
+    FOREIGN KEY (event_id) REFERENCES unified_calendar_events(id) ON DELETE SET NULL,
+    FOREIGN KEY (created_by) REFERENCES family_members(id) ON DELETE SET NULL,
+    UNIQUE(family_id, meal_date, meal_type)
+);
+
+CREATE TABLE shopping_list_items (
+    id TEXT PRIMARY KEY,
+    family_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    quantity REAL NOT NULL DEFAULT 0,
+    unit TEXT NOT NULL DEFAULT '',
+    checked BOOLEAN NOT NULL DEFAULT FALSE,
+    generated BOOLEAN NOT NULL DEFAULT FALSE, -- added from the meal plan rather than by hand
+    created_by TEXT,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_shopping_list_items_family ON shopping_list_items(family_id, checked);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_shopping_list_items_family;
+DROP TABLE IF EXISTS shopping_list_items;
+DROP TABLE IF EXISTS meal_plans;
+DROP INDEX IF EXISTS idx_recipe_ingredients_recipe;
+DROP TABLE IF EXISTS recipe_ingredients;
+DROP INDEX IF EXISTS idx_recipes_family;
+DROP TABLE IF EXISTS recipes;
@@ -0,0 +1,38 @@
+-- +goose Up
+-- Migration 034: Family announcements board
+-- Parents post notes for the family, optionally pinned to the top, kept until
+-- an expiry, or shown to adults only. A read receipt is kept per member so
+-- the dashboard can show what each member hasn't seen yet.
+
+CREATE TABLE announcements (
+    id TEXT PRIMARY KEY,
+    family_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    visibility TEXT NOT NULL DEFAULT 'everyone', -- everyone, adults
+    expires_at DATETIME,                         -- NULL never expires
+    created_by TEXT,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_announcements_family ON announcements(family_id, pinned, created_at);
+
+CREATE TABLE announcement_reads (
+    announcement_id TEXT NOT NULL,
+    member_id TEXT NOT NULL,
+    read_at DATETIME NOT NULL,
+
+    PRIMARY KEY (announcement_id, member_id),
+    FOREIGN KEY (announcement_id) REFERENCES announcements(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS announcement_reads;
+DROP INDEX IF EXISTS idx_announcements_family;
+DROP TABLE IF EXISTS announcements;
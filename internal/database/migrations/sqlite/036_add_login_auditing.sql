@@ -0,0 +1,36 @@
+-- +goose Up
+-- Migration 036: Sign-in auditing and lockouts
+-- Every sign-in attempt is recorded so admins can see who tried to get in.
+-- Failed attempts are counted per email and per client address; past the
+-- limit the email or address is locked out for a while, even with the right
+-- password, until the lockout runs out or an admin clears it.
+
+CREATE TABLE login_attempts (
+    id TEXT PRIMARY KEY,
+    email TEXT NOT NULL,                -- trimmed and lowercased
+    ip_address TEXT NOT NULL DEFAULT '',
+    member_id TEXT,                     -- NULL when the email matched nobody
+    outcome TEXT NOT NULL,              -- succeeded, invalid_credentials, locked_out
+    created_at DATETIME NOT NULL,
+
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_login_attempts_created ON login_attempts(created_at);
+CREATE INDEX idx_login_attempts_member ON login_attempts(member_id, created_at);
+
+CREATE TABLE login_lockouts (
+    kind TEXT NOT NULL,                 -- email, ip
+    subject TEXT NOT NULL,              -- the email or client address
+    failures INTEGER NOT NULL,          -- failed attempts since first_failure_at
+    first_failure_at DATETIME NOT NULL,
+    locked_until DATETIME,              -- NULL while under the limit
+
+    PRIMARY KEY (kind, subject)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS login_lockouts;
+DROP INDEX IF EXISTS idx_login_attempts_member;
+DROP INDEX IF EXISTS idx_login_attempts_created;
+DROP TABLE IF EXISTS login_attempts;
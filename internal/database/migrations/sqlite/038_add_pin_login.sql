@@ -0,0 +1,35 @@
+-- +goose Up
+-- Migration 038: PIN sign-in on trusted devices
+-- A parent marks a shared device, like the kitchen tablet, as trusted; on it,
+-- members switch profiles with a 4-digit PIN. Only hashes of PINs and device
+-- tokens are kept.
+
+CREATE TABLE member_pins (
+    member_id TEXT PRIMARY KEY,
+    pin_hash TEXT NOT NULL,             -- Argon2id, like passwords
+    updated_by TEXT,                    -- the parent who set it
+    updated_at DATETIME NOT NULL,
+
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (updated_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+CREATE TABLE trusted_devices (
+    id TEXT PRIMARY KEY,
+    family_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,    -- hex SHA-256 of the device cookie
+    created_by TEXT,
+    created_at DATETIME NOT NULL,
+    last_used_at DATETIME,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_trusted_devices_family ON trusted_devices(family_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_trusted_devices_family;
+DROP TABLE IF EXISTS trusted_devices;
+DROP TABLE IF EXISTS member_pins;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Migration 039: Per-member capability grants
+-- Parents give a member a capability their role lacks, like creating tasks,
+-- or take one away. A member without a row gets what their role allows.
+
+CREATE TABLE member_grants (
+    member_id TEXT NOT NULL,
+    capability TEXT NOT NULL,           -- create_tasks, edit_calendar, manage_schedules, manage_integrations
+    granted BOOLEAN NOT NULL,           -- false takes the capability away
+    granted_by TEXT,
+    updated_at DATETIME NOT NULL,
+
+    PRIMARY KEY (member_id, capability),
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (granted_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS member_grants;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- Migration 040: API tokens
+-- Scripts and home automation call the JSON API with a token in the
+-- Authorization header instead of a browser session. Only a hash of each
+-- token is kept.
+
+CREATE TABLE api_tokens (
+    id TEXT PRIMARY KEY,
+    family_id TEXT NOT NULL,
+    member_id TEXT NOT NULL,            -- who created it; personal tokens act as them
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL,                 -- personal, family
+    scopes TEXT NOT NULL,               -- comma-separated, e.g. task:read,calendar:write
+    token_hash TEXT NOT NULL UNIQUE,    -- hex SHA-256 of the token
+    token_prefix TEXT NOT NULL,         -- first characters, to recognize a token by
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME,                     -- NULL for tokens that don't expire
+    last_used_at DATETIME,
+    revoked_at DATETIME,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_api_tokens_family ON api_tokens(family_id, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_api_tokens_family;
+DROP TABLE IF EXISTS api_tokens;
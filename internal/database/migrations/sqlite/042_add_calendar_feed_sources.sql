@@ -0,0 +1,16 @@
+-- +goose Up
+-- Migration 042: events from subscribed calendar feeds
+-- Events fetched from an ICS subscription remember the integration they came
+-- from, so each sync can find the ones it made, and a hash of what the feed
+-- said, so unchanged events aren't rewritten on every fetch. Deleting the
+-- integration deletes its events.
+
+ALTER TABLE unified_calendar_events ADD COLUMN source_integration_id TEXT;
+ALTER TABLE unified_calendar_events ADD COLUMN source_hash TEXT;
+
+CREATE INDEX idx_unified_calendar_events_source ON unified_calendar_events(source_integration_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_unified_calendar_events_source;
+ALTER TABLE unified_calendar_events DROP COLUMN source_hash;
+ALTER TABLE unified_calendar_events DROP COLUMN source_integration_id;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Migration 043: weather on calendar days
+-- A family that sets its location gets each day's forecast in the calendar.
+-- Forecasts are fetched by a job and kept here, one row per family and
+-- local date, so showing the calendar never waits on the weather service.
+
+ALTER TABLE families ADD COLUMN location_name TEXT;
+ALTER TABLE families ADD COLUMN latitude REAL;
+ALTER TABLE families ADD COLUMN longitude REAL;
+
+CREATE TABLE weather_forecasts (
+    family_id TEXT NOT NULL,
+    forecast_date TEXT NOT NULL,        -- YYYY-MM-DD in the family's timezone
+    weather_code INTEGER NOT NULL,      -- WMO weather interpretation code
+    temperature_max REAL NOT NULL,     -- degrees Celsius
+    temperature_min REAL NOT NULL,
+    precipitation_chance INTEGER NOT NULL DEFAULT 0, -- percent
+    fetched_at DATETIME NOT NULL,
+
+    PRIMARY KEY (family_id, forecast_date),
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS weather_forecasts;
+ALTER TABLE families DROP COLUMN longitude;
+ALTER TABLE families DROP COLUMN latitude;
+ALTER TABLE families DROP COLUMN location_name;
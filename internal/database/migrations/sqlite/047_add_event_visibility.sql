@@ -0,0 +1,10 @@
+-- +goose Up
+-- Migration 047: per-event calendar privacy
+-- An event is public to the whole family, busy (others see only that the
+-- time is taken) or private (others don't see it at all). Its creator and
+-- attendees always see it in full, and adults see their children's events.
+
+ALTER TABLE unified_calendar_events ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public';
+
+-- +goose Down
+ALTER TABLE unified_calendar_events DROP COLUMN visibility;
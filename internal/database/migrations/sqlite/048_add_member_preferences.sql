@@ -0,0 +1,20 @@
+-- +goose Up
+-- Migration 048: Per-member rendering preferences
+-- The day a member's week starts on and whether they read a 12 or 24 hour
+-- clock. Their color stays on the member, their default view with their
+-- display preferences and their notifications with notification_preferences.
+-- Members without a row get the defaults: Sunday and 12 hour.
+
+CREATE TABLE member_preferences (
+    member_id TEXT PRIMARY KEY,
+    family_id TEXT NOT NULL,
+    week_start TEXT NOT NULL DEFAULT 'sunday',  -- sunday, monday
+    clock TEXT NOT NULL DEFAULT '12h',          -- 12h, 24h
+    updated_at DATETIME NOT NULL,
+
+    FOREIGN KEY (member_id) REFERENCES family_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS member_preferences;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Migration 049: family settings
+-- The family's locale and first day of the week are the defaults its members
+-- render with, and its task rollover policy decides what happens to tasks
+-- left undone at the end of their day.
+
+ALTER TABLE families ADD COLUMN locale TEXT NOT NULL DEFAULT 'en-US';
+ALTER TABLE families ADD COLUMN week_start TEXT NOT NULL DEFAULT 'sunday';
+ALTER TABLE families ADD COLUMN task_rollover TEXT NOT NULL DEFAULT 'keep';
+
+-- +goose Down
+ALTER TABLE families DROP COLUMN task_rollover;
+ALTER TABLE families DROP COLUMN week_start;
+ALTER TABLE families DROP COLUMN locale;
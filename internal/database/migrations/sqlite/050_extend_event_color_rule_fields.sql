@@ -0,0 +1,55 @@
+-- +goose Up
+-- Migration 050: Color rules can match an event's source, type and attendees
+-- SQLite can't alter a CHECK constraint, so the table is rebuilt with the
+-- wider field list.
+
+CREATE TABLE event_color_rules_new (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    family_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    field TEXT NOT NULL CHECK (field IN ('title', 'description', 'location', 'organizer', 'source', 'event_type', 'attendee')),
+    match_type TEXT NOT NULL CHECK (match_type IN ('contains', 'equals', 'domain')),
+    pattern TEXT NOT NULL,
+    category TEXT NOT NULL DEFAULT '',
+    color TEXT NOT NULL DEFAULT '',
+    position INTEGER NOT NULL DEFAULT 0, -- lower runs first, the first match wins
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_by TEXT,
+    created_at DATETIME DEFAULT (datetime('now', 'utc')),
+    updated_at DATETIME DEFAULT (datetime('now', 'utc')),
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+INSERT INTO event_color_rules_new SELECT * FROM event_color_rules;
+DROP TABLE event_color_rules;
+ALTER TABLE event_color_rules_new RENAME TO event_color_rules;
+CREATE INDEX idx_event_color_rules_family ON event_color_rules(family_id, position);
+
+-- +goose Down
+DELETE FROM event_color_rules WHERE field IN ('source', 'event_type', 'attendee');
+
+CREATE TABLE event_color_rules_old (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    family_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    field TEXT NOT NULL CHECK (field IN ('title', 'description', 'location', 'organizer')),
+    match_type TEXT NOT NULL CHECK (match_type IN ('contains', 'equals', 'domain')),
+    pattern TEXT NOT NULL,
+    category TEXT NOT NULL DEFAULT '',
+    color TEXT NOT NULL DEFAULT '',
+    position INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_by TEXT,
+    created_at DATETIME DEFAULT (datetime('now', 'utc')),
+    updated_at DATETIME DEFAULT (datetime('now', 'utc')),
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES family_members(id) ON DELETE SET NULL
+);
+
+INSERT INTO event_color_rules_old SELECT * FROM event_color_rules;
+DROP TABLE event_color_rules;
+ALTER TABLE event_color_rules_old RENAME TO event_color_rules;
+CREATE INDEX idx_event_color_rules_family ON event_color_rules(family_id, position);
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Migration 053: feature flag overrides
+-- Feature flags and their defaults are defined in code. An override turns
+-- one on or off for every family (family_id NULL) or for one family, the
+-- family's own winning.
+
+CREATE TABLE feature_flag_overrides (
+    id TEXT PRIMARY KEY,
+    flag TEXT NOT NULL,
+    family_id TEXT,            -- NULL overrides the flag for every family
+    enabled BOOLEAN NOT NULL,
+    updated_by TEXT,
+    updated_at DATETIME NOT NULL,
+
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_feature_flag_overrides_scope ON feature_flag_overrides(flag, COALESCE(family_id, ''));
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_feature_flag_overrides_scope;
+DROP TABLE IF EXISTS feature_flag_overrides;
@@ -0,0 +1,65 @@
+-- +goose Up
+-- Migration 056: synced events are unique per family and provider
+-- external_id was unique across every family, so a second family syncing an
+-- event the first already had could not store it. SQLite can't drop a column
+-- constraint, so the table is rebuilt. Dropping it deletes its links to
+-- unified events, which are kept aside and put back.
+
+CREATE TABLE calendar_event_rels_056 AS SELECT * FROM unified_calendar_to_calendar_event_rel;
+
+CREATE TABLE calendar_events_new (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    external_id TEXT, -- ID from external system (Google Calendar, Outlook, etc.)
+    external_source TEXT NOT NULL, -- 'google', 'outlook', 'apple', 'manual', etc.
+    family_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT DEFAULT '',
+    start_time DATETIME NOT NULL,
+    end_time DATETIME NOT NULL,
+    location TEXT DEFAULT '',
+    all_day BOOLEAN DEFAULT FALSE,
+    status TEXT DEFAULT 'confirmed' CHECK (status IN ('confirmed', 'tentative', 'cancelled')),
+    visibility TEXT DEFAULT 'public' CHECK (visibility IN ('public', 'private')),
+    raw_data TEXT, -- JSON blob of original event data
+    created_at DATETIME DEFAULT (datetime('now', 'utc')),
+    updated_at DATETIME DEFAULT (datetime('now', 'utc')),
+    synced_at DATETIME DEFAULT (datetime('now', 'utc')),
+    is_recurring BOOLEAN DEFAULT FALSE,
+    recurrence_rules TEXT, -- JSON array of RRULE strings
+    recurring_event_id TEXT, -- Parent recurring event ID
+    is_recurring_instance BOOLEAN DEFAULT FALSE,
+    color TEXT,
+    category TEXT NOT NULL DEFAULT '',
+    attendees TEXT NOT NULL DEFAULT '[]',
+    event_type TEXT NOT NULL DEFAULT 'event',
+    assigned_to TEXT,
+    created_by TEXT,
+    FOREIGN KEY (family_id) REFERENCES families(id) ON DELETE CASCADE
+);
+
+INSERT INTO calendar_events_new
+(id, external_id, external_source, family_id, title, description, start_time, end_time, location,
+ all_day, status, visibility, raw_data, created_at, updated_at, synced_at, is_recurring,
+ recurrence_rules, recurring_event_id, is_recurring_instance, color, category, attendees,
+ event_type, assigned_to, created_by)
+SELECT id, external_id, external_source, family_id, title, description, start_time, end_time, location,
+       all_day, status, visibility, raw_data, created_at, updated_at, synced_at, is_recurring,
+       recurrence_rules, recurring_event_id, is_recurring_instance, color, category, attendees,
+       event_type, assigned_to, created_by
+FROM calendar_events;
+DROP TABLE calendar_events;
+ALTER TABLE calendar_events_new RENAME TO calendar_events;
+
+CREATE INDEX idx_calendar_events_family_start ON calendar_events(family_id, start_time);
+CREATE INDEX idx_calendar_events_external ON calendar_events(external_source, external_id);
+CREATE INDEX idx_calendar_events_recurring ON calendar_events(recurring_event_id);
+CREATE INDEX idx_calendar_events_is_recurring ON calendar_events(is_recurring);
+CREATE UNIQUE INDEX idx_calendar_events_family_source ON calendar_events(family_id, external_source, external_id);
+
+INSERT INTO unified_calendar_to_calendar_event_rel SELECT * FROM calendar_event_rels_056;
+DROP TABLE calendar_event_rels_056;
+
+-- +goose Down
+-- Fails while two families hold the same external_id
+DROP INDEX idx_calendar_events_family_source;
+CREATE UNIQUE INDEX idx_calendar_events_external_id ON calendar_events(external_id);
@@ -0,0 +1,172 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// postgresDriver is the database/sql driver PostgreSQL is opened with. It is
+// registered by pgx's stdlib package, which is only linked into builds made
+// with -tags postgres so the default binary stays pure Go SQLite.
+const postgresDriver = "pgx"
+
+// openPostgres connects to a PostgreSQL URL. Sessions run in UTC, as SQLite
+// effectively does, and every query has its placeholders rebound on the way
+// to the driver, so services and their transactions work unchanged.
+func openPostgres(dsn string) (*sql.DB, error) {
+	if !slices.Contains(sql.Drivers(), postgresDriver) {
+		return nil, fmt.Errorf("this build has no PostgreSQL support; rebuild with -tags postgres")
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PostgreSQL URL: %w", err)
+	}
+	query := u.Query()
+	if query.Get("timezone") == "" {
+		query.Set("timezone", "UTC")
+		u.RawQuery = query.Encode()
+	}
+
+	probe, err := sql.Open(postgresDriver, "")
+	if err != nil {
+		return nil, err
+	}
+	inner := probe.Driver()
+	if err := probe.Close(); err != nil {
+		return nil, err
+	}
+
+	connector, err := newRebindConnector(inner, u.String(), DialectPostgres)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(connector), nil
+}
+
+// rebindConnector opens connections whose queries are rebound for a dialect
+type rebindConnector struct {
+	inner   driver.Connector
+	driver  driver.Driver
+	dialect Dialect
+}
+
+func newRebindConnector(inner driver.Driver, dsn string, dialect Dialect) (*rebindConnector, error) {
+	connector := &rebindConnector{driver: inner, dialect: dialect}
+	if withContext, ok := inner.(driver.DriverContext); ok {
+		innerConnector, err := withContext.OpenConnector(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open database connector: %w", err)
+		}
+		connector.inner = innerConnector
+	} else {
+		connector.inner = dsnConnector{driver: inner, dsn: dsn}
+	}
+	return connector, nil
+}
+
+func (c *rebindConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.inner.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &rebindConn{inner: conn, dialect: c.dialect}, nil
+}
+
+func (c *rebindConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// dsnConnector adapts a driver without its own connector
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// rebindConn rewrites queries before the wrapped connection sees them. The
+// optional driver interfaces are passed through, or reported as unsupported
+// with driver.ErrSkip so database/sql falls back the way it would have.
+type rebindConn struct {
+	inner   driver.Conn
+	dialect Dialect
+}
+
+func (c *rebindConn) Prepare(query string) (driver.Stmt, error) {
+	return c.inner.Prepare(c.dialect.Rebind(query))
+}
+
+func (c *rebindConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.inner.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, c.dialect.Rebind(query))
+	}
+	return c.Prepare(query)
+}
+
+func (c *rebindConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if execer, ok := c.inner.(driver.ExecerContext); ok {
+		return execer.ExecContext(ctx, c.dialect.Rebind(query), args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *rebindConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if queryer, ok := c.inner.(driver.QueryerContext); ok {
+		return queryer.QueryContext(ctx, c.dialect.Rebind(query), args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *rebindConn) Begin() (driver.Tx, error) {
+	return c.inner.Begin() // nolint:staticcheck
+}
+
+func (c *rebindConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.inner.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Begin()
+}
+
+func (c *rebindConn) Close() error {
+	return c.inner.Close()
+}
+
+func (c *rebindConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.inner.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *rebindConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.inner.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *rebindConn) IsValid() bool {
+	if validator, ok := c.inner.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *rebindConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.inner.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
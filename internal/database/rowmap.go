@@ -0,0 +1,239 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Row mapping copies result columns onto struct fields by their `db` tag, so
+// services stop hand-writing Scan argument lists that drift from the SELECT.
+//
+//   - Pointer fields receive nil for NULL; other fields receive their zero value.
+//   - time.Time fields accept driver times as well as the text layouts SQLite
+//     stores, which database/sql cannot convert on its own.
+//   - Every selected column must have a field. A column without one is an
+//     error rather than being dropped, so a query and model that disagree
+//     fail loudly instead of leaving fields empty.
+//   - Untagged fields and `db:"-"` are ignored; embedded structs are flattened.
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+
+	fieldCache sync.Map // reflect.Type -> map[string][]int
+)
+
+// timeLayouts are the text encodings a time column may come back as
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// QueryOne runs the query and maps its first row onto a new T. It returns
+// sql.ErrNoRows when the query matches nothing.
+func QueryOne[T any](df *Fascade, query string, args ...any) (*T, error) {
+	rows, err := df.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return ScanOne[T](rows)
+}
+
+// QueryAll runs the query and maps every row onto a T
+func QueryAll[T any](df *Fascade, query string, args ...any) ([]T, error) {
+	rows, err := df.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return ScanAll[T](rows)
+}
+
+// ScanOne maps the next row onto a new T, returning sql.ErrNoRows when there
+// is none. The caller still owns and closes rows.
+func ScanOne[T any](rows *sql.Rows) (*T, error) {
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, sql.ErrNoRows
+	}
+
+	var item T
+	if err := ScanStruct(rows, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// ScanAll maps every remaining row onto a T
+func ScanAll[T any](rows *sql.Rows) ([]T, error) {
+	var items []T
+	for rows.Next() {
+		var item T
+		if err := ScanStruct(rows, &item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ScanStruct maps the current row onto dest, which must point to a struct.
+// It is meant to be called inside a rows.Next loop.
+func ScanStruct(rows *sql.Rows, dest any) error {
+	target := reflect.ValueOf(dest)
+	if target.Kind() != reflect.Pointer || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("scan destination must be a pointer to a struct, got %T", dest)
+	}
+	target = target.Elem()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	fields := structFields(target.Type())
+	holders := make([]columnHolder, len(columns))
+	dests := make([]any, len(columns))
+	for i, column := range columns {
+		index, ok := fields[strings.ToLower(column)]
+		if !ok {
+			return fmt.Errorf("column %q has no db field on %s", column, target.Type())
+		}
+		holders[i] = newColumnHolder(target.FieldByIndex(index))
+		dests[i] = holders[i].dest
+	}
+
+	if err := rows.Scan(dests...); err != nil {
+		return err
+	}
+
+	for _, holder := range holders {
+		holder.assign()
+	}
+	return nil
+}
+
+// structFields returns the field index path for each db column name on t
+func structFields(t reflect.Type) map[string][]int {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.(map[string][]int)
+	}
+
+	fields := make(map[string][]int)
+	collectFields(t, nil, fields)
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+func collectFields(t reflect.Type, parent []int, fields map[string][]int) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := append(append([]int{}, parent...), i)
+
+		tag := field.Tag.Get("db")
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			collectFields(field.Type, index, fields)
+			continue
+		}
+		if tag == "" || tag == "-" || !field.IsExported() {
+			continue
+		}
+
+		name := strings.ToLower(strings.Split(tag, ",")[0])
+		if _, exists := fields[name]; !exists {
+			fields[name] = index
+		}
+	}
+}
+
+// columnHolder is the scan destination for one column and the field it
+// is copied into afterwards
+type columnHolder struct {
+	field reflect.Value
+	dest  any
+}
+
+func newColumnHolder(field reflect.Value) columnHolder {
+	base := field.Type()
+	if base.Kind() == reflect.Pointer {
+		base = base.Elem()
+	}
+
+	if base == timeType {
+		return columnHolder{field: field, dest: &nullTime{}}
+	}
+	// database/sql sets a **T to nil for NULL and allocates otherwise
+	return columnHolder{field: field, dest: reflect.New(reflect.PointerTo(base)).Interface()}
+}
+
+func (h columnHolder) assign() {
+	var value reflect.Value
+	switch dest := h.dest.(type) {
+	case *nullTime:
+		if !dest.valid {
+			h.field.SetZero()
+			return
+		}
+		value = reflect.ValueOf(&dest.time)
+	default:
+		value = reflect.ValueOf(dest).Elem()
+		if value.IsNil() {
+			h.field.SetZero()
+			return
+		}
+	}
+
+	if h.field.Kind() == reflect.Pointer {
+		h.field.Set(value)
+	} else {
+		h.field.Set(value.Elem())
+	}
+}
+
+// nullTime scans time columns whether the driver returns a time.Time or text
+type nullTime struct {
+	time  time.Time
+	valid bool
+}
+
+func (n *nullTime) Scan(src any) error {
+	switch value := src.(type) {
+	case nil:
+		n.valid = false
+		return nil
+	case time.Time:
+		n.time, n.valid = value, true
+		return nil
+	case string:
+		return n.parse(value)
+	case []byte:
+		return n.parse(string(value))
+	default:
+		return fmt.Errorf("cannot scan %T into a time", src)
+	}
+}
+
+func (n *nullTime) parse(text string) error {
+	for _, layout := range timeLayouts {
+		if parsed, err := time.Parse(layout, text); err == nil {
+			n.time, n.valid = parsed, true
+			return nil
+		}
+	}
+	return fmt.Errorf("unrecognized time format %q", text)
+}
@@ -0,0 +1,166 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rowsDriver serves canned result sets keyed by query text, so the mapper can
+// be exercised against real *sql.Rows without a database file
+type rowsDriver struct{}
+
+type cannedResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+var cannedResults = map[string]cannedResult{}
+
+func (rowsDriver) Open(string) (driver.Conn, error) { return rowsConn{}, nil }
+
+type rowsConn struct{}
+
+func (rowsConn) Prepare(query string) (driver.Stmt, error) { return rowsStmt{query: query}, nil }
+func (rowsConn) Close() error                              { return nil }
+func (rowsConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+type rowsStmt struct{ query string }
+
+func (rowsStmt) Close() error                               { return nil }
+func (rowsStmt) NumInput() int                              { return -1 }
+func (rowsStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s rowsStmt) Query([]driver.Value) (driver.Rows, error) {
+	result := cannedResults[s.query]
+	return &cannedRows{result: result}, nil
+}
+
+type cannedRows struct {
+	result cannedResult
+	next   int
+}
+
+func (r *cannedRows) Columns() []string { return r.result.columns }
+func (r *cannedRows) Close() error      { return nil }
+func (r *cannedRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.next])
+	r.next++
+	return nil
+}
+
+func init() {
+	sql.Register("famstack_rows_test", rowsDriver{})
+}
+
+func queryCanned(t *testing.T, result cannedResult) *sql.Rows {
+	t.Helper()
+	query := t.Name()
+	cannedResults[query] = result
+
+	db, err := sql.Open("famstack_rows_test", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	rows, err := db.Query(query)
+	require.NoError(t, err)
+	t.Cleanup(func() { rows.Close() })
+	return rows
+}
+
+type rowTestKind string
+
+type rowTestRecord struct {
+	ID         string      `db:"id"`
+	Kind       rowTestKind `db:"kind"`
+	Note       *string     `db:"note"`
+	Count      int         `db:"count"`
+	Active     bool        `db:"active"`
+	CreatedAt  time.Time   `db:"created_at"`
+	FinishedAt *time.Time  `db:"finished_at"`
+	Computed   []string    // not a column
+}
+
+type rowTestRecordWithTotal struct {
+	rowTestRecord
+	Total int `db:"total"`
+}
+
+func TestScanAll_NullsAndTimes(t *testing.T) {
+	rows := queryCanned(t, cannedResult{
+		columns: []string{"id", "kind", "note", "count", "active", "created_at", "finished_at"},
+		rows: [][]driver.Value{
+			{"a", "chore", "take bins out", int64(3), int64(1), time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC), "2026-03-02 09:30:00"},
+			{"b", "todo", nil, nil, int64(0), "2026-03-02T08:00:00Z", nil},
+		},
+	})
+
+	records, err := ScanAll[rowTestRecord](rows)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	first := records[0]
+	assert.Equal(t, rowTestKind("chore"), first.Kind)
+	require.NotNil(t, first.Note)
+	assert.Equal(t, "take bins out", *first.Note)
+	assert.Equal(t, 3, first.Count)
+	assert.True(t, first.Active)
+	require.NotNil(t, first.FinishedAt)
+	assert.Equal(t, time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC), *first.FinishedAt)
+
+	// NULL leaves pointers nil and everything else at its zero value
+	second := records[1]
+	assert.Nil(t, second.Note)
+	assert.Zero(t, second.Count)
+	assert.False(t, second.Active)
+	assert.Nil(t, second.FinishedAt)
+	assert.Equal(t, time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC), second.CreatedAt)
+}
+
+func TestScanOne_EmbeddedAndNoRows(t *testing.T) {
+	t.Run("embedded", func(t *testing.T) {
+		rows := queryCanned(t, cannedResult{
+			columns: []string{"id", "total"},
+			rows:    [][]driver.Value{{"a", int64(7)}},
+		})
+
+		record, err := ScanOne[rowTestRecordWithTotal](rows)
+		require.NoError(t, err)
+		assert.Equal(t, "a", record.ID)
+		assert.Equal(t, 7, record.Total)
+	})
+
+	t.Run("no rows", func(t *testing.T) {
+		rows := queryCanned(t, cannedResult{columns: []string{"id"}})
+
+		_, err := ScanOne[rowTestRecord](rows)
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+}
+
+func TestScanStruct_RejectsUnmappedColumns(t *testing.T) {
+	rows := queryCanned(t, cannedResult{
+		columns: []string{"id", "colour"},
+		rows:    [][]driver.Value{{"a", "#fff"}},
+	})
+
+	_, err := ScanAll[rowTestRecord](rows)
+	assert.ErrorContains(t, err, `column "colour" has no db field`)
+}
+
+func TestScanStruct_BadTimeText(t *testing.T) {
+	rows := queryCanned(t, cannedResult{
+		columns: []string{"created_at"},
+		rows:    [][]driver.Value{{"next tuesday"}},
+	})
+
+	_, err := ScanAll[rowTestRecord](rows)
+	assert.ErrorContains(t, err, "unrecognized time format")
+}
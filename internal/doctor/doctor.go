@@ -0,0 +1,398 @@
+// Package doctor checks a FamStack installation for the problems
+// self-hosters run into, such as a damaged database, migrations not yet
+// applied, a lost encryption key or a stopped job queue, and says how to fix
+// each one.
+package doctor
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"famstack/internal/config"
+	"famstack/internal/database"
+	"famstack/internal/encryption"
+	"famstack/internal/services"
+)
+
+// Status is how a check came out
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn" // works, but something needs attention
+	StatusFail Status = "fail" // broken, or about to be
+	StatusSkip Status = "skip" // doesn't apply, or couldn't be run
+)
+
+// Finding is the outcome of one check, with what to do about it
+type Finding struct {
+	Check   string `json:"check"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"`
+}
+
+// Thresholds past which the job queue is reported
+const (
+	// QueueStallAfter is how long a due job may wait before the workers are
+	// taken to be stopped or falling behind
+	QueueStallAfter = 15 * time.Minute
+	// MaxClockSkew is how far the database server's clock may be from this
+	// machine's
+	MaxClockSkew = time.Minute
+)
+
+// earliestPlausibleTime is before any FamStack release; a clock behind it
+// was never set
+var earliestPlausibleTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Doctor runs the checks against one installation
+type Doctor struct {
+	DB     *database.Fascade
+	Config *config.Config
+	// NewEncryption opens the encryption service the server would use
+	NewEncryption func() (*encryption.Service, error)
+	Now           func() time.Time
+}
+
+// Run runs every check in turn. A check that can't run, say for a database
+// that won't open, is reported rather than stopping the rest.
+func (d *Doctor) Run() []Finding {
+	var findings []Finding
+	findings = append(findings, d.checkIntegrity()...)
+	findings = append(findings, d.checkMigrations()...)
+	findings = append(findings, d.checkEncryption()...)
+	findings = append(findings, CheckOAuth(d.Config)...)
+	findings = append(findings, d.checkJobQueue()...)
+	findings = append(findings, d.checkClock()...)
+	return findings
+}
+
+// Failed reports whether any finding is a failure
+func Failed(findings []Finding) bool {
+	for _, finding := range findings {
+		if finding.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIntegrity asks SQLite to verify every page and index, and every
+// foreign key. PostgreSQL looks after its own storage.
+func (d *Doctor) checkIntegrity() []Finding {
+	const check = "Database integrity"
+	if d.DB.Dialect() != database.DialectSQLite {
+		return []Finding{{Check: check, Status: StatusSkip, Message: "PostgreSQL checks its own storage"}}
+	}
+
+	rows, err := d.DB.Query(`PRAGMA integrity_check`)
+	if err != nil {
+		return []Finding{{Check: check, Status: StatusFail, Message: fmt.Sprintf("Couldn't run the integrity check: %v", err)}}
+	}
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			rows.Close()
+			return []Finding{{Check: check, Status: StatusFail, Message: fmt.Sprintf("Couldn't read the integrity check: %v", err)}}
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	rows.Close()
+	if len(problems) > 0 {
+		return []Finding{{
+			Check:   check,
+			Status:  StatusFail,
+			Message: fmt.Sprintf("%d problem(s), first: %s", len(problems), problems[0]),
+			Fix:     "Stop the server and restore the latest backup, or recover what's readable with sqlite3's .recover",
+		}}
+	}
+
+	orphans := 0
+	if err := d.DB.QueryRow(`SELECT COUNT(*) FROM pragma_foreign_key_check`).Scan(&orphans); err != nil {
+		return []Finding{{Check: check, Status: StatusWarn, Message: fmt.Sprintf("Pages are sound, but foreign keys couldn't be checked: %v", err)}}
+	}
+	if orphans > 0 {
+		return []Finding{{
+			Check:   check,
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("Pages are sound, but %d row(s) point at rows that no longer exist", orphans),
+			Fix:     "Run PRAGMA foreign_key_check in sqlite3 to see which, and delete them or restore a backup",
+		}}
+	}
+	return []Finding{{Check: check, Status: StatusOK, Message: "Every page, index and foreign key checks out"}}
+}
+
+// checkMigrations reports migrations the server hasn't applied, and applied
+// ones edited since
+func (d *Doctor) checkMigrations() []Finding {
+	const check = "Migrations"
+	pre, err := d.DB.PlanMigrations(database.PhasePreDeploy)
+	if err != nil {
+		return []Finding{{Check: check, Status: StatusFail, Message: fmt.Sprintf("Couldn't read the schema version: %v", err)}}
+	}
+	post, err := d.DB.PlanMigrations(database.PhasePostDeploy)
+	if err != nil {
+		return []Finding{{Check: check, Status: StatusFail, Message: fmt.Sprintf("Couldn't plan post-deploy migrations: %v", err)}}
+	}
+
+	var findings []Finding
+	switch {
+	case len(pre.Migrations) > 0:
+		findings = append(findings, Finding{
+			Check:   check,
+			Status:  StatusFail,
+			Message: fmt.Sprintf("Schema is at version %d; %d migration(s) this build needs haven't run", pre.CurrentVersion, len(pre.Migrations)),
+			Fix:     "Start the server, which applies them, or run 'famstack migrate up'",
+		})
+	case len(post.Migrations) > 0:
+		findings = append(findings, Finding{
+			Check:   check,
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("%d post-deploy migration(s) pending", len(post.Migrations)),
+			Fix:     "Once every instance runs this build, run 'famstack migrate up --phase post-deploy'",
+		})
+	default:
+		findings = append(findings, Finding{Check: check, Status: StatusOK, Message: fmt.Sprintf("Schema is up to date at version %d", pre.CurrentVersion)})
+	}
+
+	if edited, err := d.DB.EditedMigrations(); err == nil && len(edited) > 0 {
+		findings = append(findings, Finding{
+			Check:   check,
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("%d applied migration(s) were edited after they ran", len(edited)),
+			Fix:     "See 'famstack migrate status'; the schema may differ from what this build expects",
+		})
+	}
+	return findings
+}
+
+// checkEncryption opens the encryption key and makes sure it still decrypts
+// the tokens stored with it
+func (d *Doctor) checkEncryption() []Finding {
+	const check = "Encryption key"
+	service, err := d.NewEncryption()
+	if err != nil {
+		return []Finding{{
+			Check:   check,
+			Status:  StatusFail,
+			Message: fmt.Sprintf("The encryption key can't be opened: %v", err),
+			Fix:     "Make sure the keyring is unlocked, or run 'famstack encryption generate-key' on a new install",
+		}}
+	}
+
+	sealed, err := service.Encrypt("famstack doctor")
+	if err == nil {
+		var opened string
+		opened, err = service.Decrypt(sealed)
+		if err == nil && opened != "famstack doctor" {
+			err = fmt.Errorf("decrypted text doesn't match")
+		}
+	}
+	if err != nil {
+		return []Finding{{Check: check, Status: StatusFail, Message: fmt.Sprintf("The key doesn't encrypt and decrypt: %v", err)}}
+	}
+
+	rows, err := d.DB.Query(`SELECT access_token FROM oauth_tokens WHERE access_token != '' ORDER BY updated_at DESC LIMIT 5`)
+	if err != nil {
+		return []Finding{{Check: check, Status: StatusWarn, Message: fmt.Sprintf("The key works, but stored tokens couldn't be read: %v", err)}}
+	}
+	defer rows.Close()
+	checked, unreadable := 0, 0
+	for rows.Next() {
+		var token string
+		if err := rows.Scan(&token); err != nil {
+			return []Finding{{Check: check, Status: StatusWarn, Message: fmt.Sprintf("The key works, but stored tokens couldn't be read: %v", err)}}
+		}
+		checked++
+		if _, err := service.Decrypt(token); err != nil {
+			unreadable++
+		}
+	}
+	if unreadable > 0 {
+		return []Finding{{
+			Check:   check,
+			Status:  StatusFail,
+			Message: fmt.Sprintf("%d of the %d most recent stored tokens can't be decrypted with this key", unreadable, checked),
+			Fix:     "Restore the key the tokens were saved with, or have members reconnect their calendars",
+		}}
+	}
+	return []Finding{{Check: check, Status: StatusOK, Message: fmt.Sprintf("The key works and decrypts the stored tokens checked (%d)", checked)}}
+}
+
+// CheckOAuth reports OAuth providers that are set up only in part
+func CheckOAuth(cfg *config.Config) []Finding {
+	const check = "Google OAuth"
+	google := cfg.OAuth.Google
+	if google == nil || (google.ClientID == "" && google.ClientSecret == "") {
+		return []Finding{{
+			Check:   check,
+			Status:  StatusWarn,
+			Message: "Not set up, so members can't connect Google calendars",
+			Fix:     "Create an OAuth client in the Google Cloud console and save it under Settings, or in oauth.google in the config file",
+		}}
+	}
+
+	var missing []string
+	if google.ClientID == "" {
+		missing = append(missing, "client_id")
+	}
+	if google.ClientSecret == "" {
+		missing = append(missing, "client_secret")
+	}
+	if google.RedirectURL == "" {
+		missing = append(missing, "redirect_url")
+	}
+	if len(google.Scopes) == 0 {
+		missing = append(missing, "scopes")
+	}
+	if len(missing) > 0 {
+		return []Finding{{
+			Check:   check,
+			Status:  StatusFail,
+			Message: "Missing " + strings.Join(missing, ", "),
+			Fix:     "Fill them in under oauth.google in the config file",
+		}}
+	}
+	if !google.Configured {
+		return []Finding{{
+			Check:   check,
+			Status:  StatusWarn,
+			Message: "Credentials are filled in but not marked configured, so the server ignores them",
+			Fix:     "Save them again under Settings, or set oauth.google.configured to true",
+		}}
+	}
+
+	redirect, err := url.Parse(google.RedirectURL)
+	if err != nil || redirect.Host == "" {
+		return []Finding{{Check: check, Status: StatusFail, Message: fmt.Sprintf("redirect_url %q isn't a URL", google.RedirectURL),
+			Fix: "Set it to https://<your host>/oauth/google/callback"}}
+	}
+	if publicURL := cfg.Server.PublicURL; publicURL != "" {
+		if public, err := url.Parse(publicURL); err == nil && public.Host != "" && !strings.EqualFold(public.Host, redirect.Host) {
+			return []Finding{{
+				Check:   check,
+				Status:  StatusWarn,
+				Message: fmt.Sprintf("redirect_url points at %s but the server is reached at %s", redirect.Host, public.Host),
+				Fix:     fmt.Sprintf("Use %s/oauth/google/callback here and in the Google Cloud console", strings.TrimSuffix(publicURL, "/")),
+			}}
+		}
+	}
+	return []Finding{{Check: check, Status: StatusOK, Message: "Client, secret, redirect URL and scopes are set"}}
+}
+
+// checkJobQueue reports queues whose due jobs sit waiting, and failed jobs
+func (d *Doctor) checkJobQueue() []Finding {
+	const check = "Job queue"
+	depths, err := services.NewJobsService(d.DB).GetQueueDepths()
+	if err != nil {
+		return []Finding{{Check: check, Status: StatusFail, Message: fmt.Sprintf("Couldn't read the job queue: %v", err)}}
+	}
+
+	now := d.Now()
+	var findings []Finding
+	failed := 0
+	for _, depth := range depths {
+		failed += depth.Failed
+		if depth.OldestReadyAt != nil && now.Sub(*depth.OldestReadyAt) > QueueStallAfter {
+			findings = append(findings, Finding{
+				Check:  check,
+				Status: StatusWarn,
+				Message: fmt.Sprintf("%d job(s) in %s are due, the oldest for %s", depth.Ready, depth.QueueName,
+					now.Sub(*depth.OldestReadyAt).Round(time.Minute)),
+				Fix: "Make sure the server is running; if it is, its logs say why the workers are stuck",
+			})
+		}
+	}
+	if failed > 0 {
+		findings = append(findings, Finding{
+			Check:   check,
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("%d job(s) failed for good", failed),
+			Fix:     "See GET /api/v1/admin/jobs?status=failed, and retry them once the cause is fixed",
+		})
+	}
+	if len(findings) == 0 {
+		findings = append(findings, Finding{Check: check, Status: StatusOK, Message: "No jobs are overdue or failed"})
+	}
+	return findings
+}
+
+// checkClock makes sure the clock is set, agrees with the database server's,
+// and that the time zone database needed for families' local times is there
+func (d *Doctor) checkClock() []Finding {
+	const check = "Clock and time zones"
+	now := d.Now()
+	var findings []Finding
+
+	if now.Before(earliestPlausibleTime) {
+		findings = append(findings, Finding{
+			Check:   check,
+			Status:  StatusFail,
+			Message: fmt.Sprintf("The clock reads %s, which can't be right", now.Format(time.RFC3339)),
+			Fix:     "Turn on NTP time sync (e.g. timedatectl set-ntp true)",
+		})
+	}
+
+	if d.DB.Dialect() == database.DialectPostgres {
+		var dbNow time.Time
+		if err := d.DB.QueryRow(`SELECT NOW()`).Scan(&dbNow); err == nil {
+			if skew := now.Sub(dbNow); skew > MaxClockSkew || skew < -MaxClockSkew {
+				findings = append(findings, Finding{
+					Check:   check,
+					Status:  StatusWarn,
+					Message: fmt.Sprintf("This machine's clock is %s off the database server's", skew.Round(time.Second)),
+					Fix:     "Turn on NTP time sync on both machines",
+				})
+			}
+		}
+	}
+
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		findings = append(findings, Finding{
+			Check:   check,
+			Status:  StatusFail,
+			Message: "The time zone database is missing, so every family's times show in UTC",
+			Fix:     "Install tzdata (e.g. apk add tzdata or apt install tzdata), or set ZONEINFO",
+		})
+	} else if bad := d.badFamilyTimezones(); len(bad) > 0 {
+		findings = append(findings, Finding{
+			Check:   check,
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("Unknown time zone(s) set on families: %s", strings.Join(bad, ", ")),
+			Fix:     "Pick a tz database name, like America/Chicago, in each family's settings",
+		})
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, Finding{Check: check, Status: StatusOK,
+			Message: fmt.Sprintf("Clock reads %s; every family's time zone loads", now.UTC().Format(time.RFC3339))})
+	}
+	return findings
+}
+
+// badFamilyTimezones lists the families' time zones that don't load
+func (d *Doctor) badFamilyTimezones() []string {
+	rows, err := d.DB.Query(`SELECT DISTINCT timezone FROM families WHERE timezone IS NOT NULL AND timezone != ''`)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var bad []string
+	for rows.Next() {
+		var timezone sql.NullString
+		if err := rows.Scan(&timezone); err != nil {
+			return bad
+		}
+		if _, err := time.LoadLocation(timezone.String); err != nil {
+			bad = append(bad, timezone.String)
+		}
+	}
+	return bad
+}
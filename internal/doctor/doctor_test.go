@@ -0,0 +1,72 @@
+package doctor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"famstack/internal/config"
+)
+
+func googleConfig(publicURL string, google *config.OAuthProvider) *config.Config {
+	cfg := &config.Config{}
+	cfg.Server.PublicURL = publicURL
+	cfg.OAuth.Google = google
+	return cfg
+}
+
+func TestCheckOAuth(t *testing.T) {
+	complete := func() *config.OAuthProvider {
+		return &config.OAuthProvider{
+			ClientID:     "client",
+			ClientSecret: "secret",
+			RedirectURL:  "https://famstack.example.com/oauth/google/callback",
+			Scopes:       []string{"https://www.googleapis.com/auth/calendar"},
+			Configured:   true,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		status  Status
+		message string
+	}{
+		{"not set up", googleConfig("", nil), StatusWarn, "Not set up"},
+		{"complete", googleConfig("https://famstack.example.com", complete()), StatusOK, ""},
+		{"missing secret and scopes", googleConfig("", func() *config.OAuthProvider {
+			google := complete()
+			google.ClientSecret, google.Scopes = "", nil
+			return google
+		}()), StatusFail, "Missing client_secret, scopes"},
+		{"not marked configured", googleConfig("", func() *config.OAuthProvider {
+			google := complete()
+			google.Configured = false
+			return google
+		}()), StatusWarn, "not marked configured"},
+		{"redirect to another host", googleConfig("https://home.example.net/", complete()), StatusWarn, "home.example.net"},
+		{"redirect isn't a URL", googleConfig("", func() *config.OAuthProvider {
+			google := complete()
+			google.RedirectURL = "callback"
+			return google
+		}()), StatusFail, "isn't a URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := CheckOAuth(tt.cfg)
+			require.Len(t, findings, 1)
+			assert.Equal(t, tt.status, findings[0].Status)
+			assert.Contains(t, findings[0].Message, tt.message)
+			if tt.status != StatusOK {
+				assert.NotEmpty(t, findings[0].Fix)
+			}
+		})
+	}
+}
+
+func TestFailed(t *testing.T) {
+	assert.False(t, Failed([]Finding{{Status: StatusOK}, {Status: StatusWarn}, {Status: StatusSkip}}))
+	assert.True(t, Failed([]Finding{{Status: StatusOK}, {Status: StatusFail}}))
+}
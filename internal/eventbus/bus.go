@@ -0,0 +1,112 @@
+// Package eventbus is an in-process publish/subscribe bus that services use
+// to announce that a family's data changed, so caches and read models built
+// from that data can be invalidated without the writer knowing about them.
+package eventbus
+
+import (
+	"log"
+	"slices"
+	"sync"
+)
+
+// Topic names a kind of change
+type Topic string
+
+// Topics published by the services
+const (
+	TopicCalendarChanged Topic = "calendar.changed" // unified or synced events were written
+	TopicTasksChanged    Topic = "tasks.changed"    // tasks were written; see the Detail constants
+	TopicFocusChanged    Topic = "focus.changed"    // a focus session started or ended early
+	TopicTaskAssigned    Topic = "task.assigned"    // a task got a new assignee; SubjectID is the task
+	TopicTaskStatus      Topic = "task.status"      // a task's status was set; SubjectID is the task, Detail the status
+	TopicSyncStatus      Topic = "sync.status"      // a calendar sync started or finished; SubjectID is the member, Detail the status
+	TopicEventCreated    Topic = "event.created"    // a calendar event was created; SubjectID is the event
+)
+
+// Details of a TopicTasksChanged event about one task, named by SubjectID.
+// Changes to many tasks at once, like generating a schedule's tasks, have
+// neither.
+const (
+	DetailCreated   = "created"
+	DetailUpdated   = "updated"
+	DetailCompleted = "completed" // updated, and the update completed it
+	DetailDeleted   = "deleted"
+)
+
+// Event is one published change
+type Event struct {
+	Topic    Topic
+	FamilyID string
+	// SubjectID names the record the change is about, for topics that say so
+	SubjectID string
+	// Detail says more about the change, for topics that say so
+	Detail string
+}
+
+// Handler receives published events. Handlers run on the publisher's
+// goroutine, so they must be quick and must not publish themselves.
+type Handler func(Event)
+
+// Bus delivers events to the handlers subscribed to their topic. A nil *Bus
+// is valid and drops everything, so services work without one.
+type Bus struct {
+	mu       sync.RWMutex
+	nextID   int
+	handlers map[Topic][]subscription
+}
+
+type subscription struct {
+	id      int
+	handler Handler
+}
+
+// New creates an empty bus
+func New() *Bus {
+	return &Bus{handlers: make(map[Topic][]subscription)}
+}
+
+// Subscribe registers a handler for each of the topics. The returned function
+// removes it again, for subscribers that live shorter than the bus.
+func (b *Bus) Subscribe(handler Handler, topics ...Topic) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	id := b.nextID
+	for _, topic := range topics {
+		b.handlers[topic] = append(b.handlers[topic], subscription{id: id, handler: handler})
+	}
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for _, topic := range topics {
+			b.handlers[topic] = slices.DeleteFunc(slices.Clone(b.handlers[topic]), func(sub subscription) bool {
+				return sub.id == id
+			})
+		}
+	}
+}
+
+// Publish delivers an event to its topic's handlers. A panicking handler is
+// logged and doesn't stop the others or fail the write that published.
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	b.mu.RLock()
+	subscriptions := b.handlers[event.Topic]
+	b.mu.RUnlock()
+
+	for _, sub := range subscriptions {
+		deliver(sub.handler, event)
+	}
+}
+
+func deliver(handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("eventbus: handler for %s panicked: %v", event.Topic, r)
+		}
+	}()
+	handler(event)
+}
@@ -0,0 +1,43 @@
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBusDeliversByTopic(t *testing.T) {
+	bus := New()
+
+	var calendar, any []string
+	bus.Subscribe(func(e Event) { calendar = append(calendar, e.FamilyID) }, TopicCalendarChanged)
+	bus.Subscribe(func(e Event) { any = append(any, string(e.Topic)) }, TopicCalendarChanged, TopicTasksChanged)
+	bus.Subscribe(func(Event) { panic("boom") }, TopicTasksChanged)
+
+	bus.Publish(Event{Topic: TopicCalendarChanged, FamilyID: "fam_1"})
+	bus.Publish(Event{Topic: TopicTasksChanged, FamilyID: "fam_2"})
+
+	assert.Equal(t, []string{"fam_1"}, calendar)
+	assert.Equal(t, []string{"calendar.changed", "tasks.changed"}, any)
+}
+
+func TestNilBusDropsEvents(t *testing.T) {
+	var bus *Bus
+	assert.NotPanics(t, func() { bus.Publish(Event{Topic: TopicTasksChanged, FamilyID: "fam_1"}) })
+}
+
+func TestUnsubscribe(t *testing.T) {
+	bus := New()
+
+	var first, second int
+	unsubscribe := bus.Subscribe(func(Event) { first++ }, TopicFocusChanged, TopicTasksChanged)
+	bus.Subscribe(func(Event) { second++ }, TopicFocusChanged)
+
+	bus.Publish(Event{Topic: TopicFocusChanged, FamilyID: "fam_1"})
+	unsubscribe()
+	bus.Publish(Event{Topic: TopicFocusChanged, FamilyID: "fam_1"})
+	bus.Publish(Event{Topic: TopicTasksChanged, FamilyID: "fam_1"})
+
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 2, second)
+}
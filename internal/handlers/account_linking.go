@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"famstack/internal/auth"
+	"famstack/internal/config"
+	"famstack/internal/csrf"
+	"famstack/internal/middleware"
+	"famstack/internal/voice"
+)
+
+// AccountLinkingHandlers link voice assistants to members' accounts. They
+// are the authorization server of OAuth's authorization code flow, with the
+// Alexa skill and Google Action, from the voice config, as its only clients.
+type AccountLinkingHandlers struct {
+	authService *auth.Service
+	config      func() config.VoiceConfig
+}
+
+// NewAccountLinkingHandlers creates account linking handlers; config is read
+// on each request so assistants can be set up without a restart
+func NewAccountLinkingHandlers(authService *auth.Service, config func() config.VoiceConfig) *AccountLinkingHandlers {
+	return &AccountLinkingHandlers{authService: authService, config: config}
+}
+
+// linkClient is an assistant allowed to link accounts
+type linkClient struct {
+	name string // what the member is asked to allow, and the token's name
+	config.VoiceClientConfig
+}
+
+// client returns the assistant with the client ID
+func (h *AccountLinkingHandlers) client(clientID string) (*linkClient, bool) {
+	cfg := h.config()
+	for _, client := range []linkClient{
+		{name: "Alexa", VoiceClientConfig: cfg.Alexa.VoiceClientConfig},
+		{name: "Google Assistant", VoiceClientConfig: cfg.Google},
+	} {
+		if client.ClientID != "" && client.ClientID == clientID {
+			return &client, true
+		}
+	}
+	return nil, false
+}
+
+// HandleAuthorize handles GET /oauth/authorize, which asks the member to
+// allow the assistant, and POST /oauth/authorize, which answers. A member
+// who isn't signed in signs in on the same form. Allowing sends the
+// assistant back to its redirect URI with a code; cancelling, with
+// error=access_denied.
+func (h *AccountLinkingHandlers) HandleAuthorize(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	clientID, redirectURI, state := r.Form.Get("client_id"), r.Form.Get("redirect_uri"), r.Form.Get("state")
+	client, ok := h.client(clientID)
+	if !ok {
+		http.Error(w, "Unknown client", http.StatusBadRequest)
+		return
+	}
+	// Never redirect anywhere not registered: the code would go with it
+	if !slices.Contains(client.RedirectURIs, redirectURI) {
+		http.Error(w, "Unregistered redirect URI", http.StatusBadRequest)
+		return
+	}
+	if r.Method == "GET" && r.Form.Get("response_type") != "code" {
+		redirectLink(w, r, redirectURI, url.Values{"error": {"unsupported_response_type"}, "state": {state}})
+		return
+	}
+
+	user := auth.GetUserFromContext(r.Context())
+	data := map[string]any{
+		"User":        user,
+		"Client":      client.name,
+		"ClientID":    clientID,
+		"RedirectURI": redirectURI,
+		"State":       state,
+		"CSRFToken":   csrf.Token(w, r),
+	}
+	if r.Method == "GET" {
+		RenderTemplate(w, "link-account", data)
+		return
+	}
+
+	if r.Form.Get("action") != "allow" {
+		redirectLink(w, r, redirectURI, url.Values{"error": {"access_denied"}, "state": {state}})
+		return
+	}
+	if user == nil {
+		authResponse, err := h.authService.Login(r.Form.Get("email"), r.Form.Get("password"), middleware.ClientIP(r))
+		if err != nil {
+			data["Error"] = "Invalid email or password"
+			var lockout *auth.LockoutError
+			if errors.As(err, &lockout) {
+				data["Error"] = "Too many failed sign-in attempts, please try again later"
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			RenderTemplate(w, "link-account", data)
+			return
+		}
+		user = authResponse.User
+	}
+
+	code, err := h.authService.CreateLinkCode(user.FamilyID, user.ID, clientID, redirectURI)
+	if err != nil {
+		log.Printf("Failed to link %s for member %s: %v", client.name, user.ID, err)
+		redirectLink(w, r, redirectURI, url.Values{"error": {"server_error"}, "state": {state}})
+		return
+	}
+	redirectLink(w, r, redirectURI, url.Values{"code": {code}, "state": {state}})
+}
+
+// HandleToken handles POST /oauth/token, where the assistant trades a code
+// for the access token it sends with each request
+func (h *AccountLinkingHandlers) HandleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeTokenError(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	client, found := h.client(clientID)
+	if !found || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(client.ClientSecret)) != 1 {
+		writeTokenError(w, "invalid_client", http.StatusUnauthorized)
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		writeTokenError(w, "unsupported_grant_type", http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.authService.ExchangeLinkCode(r.PostForm.Get("code"), clientID, r.PostForm.Get("redirect_uri"), client.name, voice.Scopes)
+	if errors.Is(err, auth.ErrInvalidLinkCode) {
+		writeTokenError(w, "invalid_grant", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to link %s: %v", client.name, err)
+		writeTokenError(w, "server_error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(map[string]string{"access_token": token, "token_type": "Bearer"}); err != nil {
+		log.Printf("Failed to encode token response: %v", err)
+	}
+}
+
+// redirectLink sends the browser back to the assistant with params
+func redirectLink(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values) {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "Invalid redirect URI", http.StatusBadRequest)
+		return
+	}
+	query := target.Query()
+	for name, values := range params {
+		if values[0] != "" {
+			query[name] = values
+		}
+	}
+	target.RawQuery = query.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+func writeTokenError(w http.ResponseWriter, code string, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": code}) // nolint:errcheck // the status says enough
+}
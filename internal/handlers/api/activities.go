@@ -0,0 +1,164 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// ActivitiesAPIHandler handles extracurricular activity registry API requests
+type ActivitiesAPIHandler struct {
+	activitiesService *services.ActivitiesService
+}
+
+// NewActivitiesAPIHandler creates a new activities API handler
+func NewActivitiesAPIHandler(activitiesService *services.ActivitiesService) *ActivitiesAPIHandler {
+	return &ActivitiesAPIHandler{
+		activitiesService: activitiesService,
+	}
+}
+
+// ListActivities handles GET /api/v1/activities?member_id=
+func (h *ActivitiesAPIHandler) ListActivities(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	activities, err := h.activitiesService.ListActivities(session.FamilyID, r.URL.Query().Get("member_id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list activities: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"activities": activities,
+		"count":      len(activities),
+	})
+}
+
+// CreateActivity handles POST /api/v1/activities
+func (h *ActivitiesAPIHandler) CreateActivity(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CreateActivityRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	activity, err := h.activitiesService.CreateActivity(session.FamilyID, session.UserID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to create activity", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, activity)
+}
+
+// GetActivity handles GET /api/v1/activities/{activityID}
+func (h *ActivitiesAPIHandler) GetActivity(w http.ResponseWriter, r *http.Request) {
+	activity, ok := h.loadOwnedActivity(w, r)
+	if !ok {
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, activity)
+}
+
+// UpdateActivity handles PATCH /api/v1/activities/{activityID}
+func (h *ActivitiesAPIHandler) UpdateActivity(w http.ResponseWriter, r *http.Request) {
+	activity, ok := h.loadOwnedActivity(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateActivityRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	session := auth.GetSessionFromContext(r.Context())
+	updated, err := h.activitiesService.UpdateActivity(activity.ID, session.UserID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to update activity", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteActivity handles DELETE /api/v1/activities/{activityID}
+func (h *ActivitiesAPIHandler) DeleteActivity(w http.ResponseWriter, r *http.Request) {
+	activity, ok := h.loadOwnedActivity(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.activitiesService.DeleteActivity(activity.ID); err != nil {
+		h.writeServiceError(w, "Failed to delete activity", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadOwnedActivity fetches the activity named in the URL and checks it belongs to the caller's family
+func (h *ActivitiesAPIHandler) loadOwnedActivity(w http.ResponseWriter, r *http.Request) (*models.Activity, bool) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	activityID := r.PathValue("activityID")
+
+	activity, err := h.activitiesService.GetActivity(activityID)
+	if err != nil || activity.FamilyID != session.FamilyID {
+		if err != nil && err.Error() != "activity not found" {
+			http.Error(w, "Failed to query activity", http.StatusInternalServerError)
+			return nil, false
+		}
+		http.Error(w, "Activity not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return activity, true
+}
+
+func (h *ActivitiesAPIHandler) writeServiceError(w http.ResponseWriter, message string, err error) {
+	var validationErrs validation.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":   "validation_failed",
+			"details": validationErrs,
+		})
+		return
+	}
+	switch err.Error() {
+	case "activity not found":
+		http.Error(w, "Activity not found", http.StatusNotFound)
+	case "family member not found":
+		http.Error(w, "Family member not found", http.StatusBadRequest)
+	default:
+		http.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError)
+	}
+}
+
+func (h *ActivitiesAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
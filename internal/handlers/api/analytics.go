@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+)
+
+// maxBeaconBytes bounds the page view beacon body
+const maxBeaconBytes = 1 << 10
+
+// AnalyticsAPIHandler handles the page view beacon and the admin usage summary
+type AnalyticsAPIHandler struct {
+	analyticsService *services.AnalyticsService
+}
+
+// NewAnalyticsAPIHandler creates a new analytics API handler
+func NewAnalyticsAPIHandler(analyticsService *services.AnalyticsService) *AnalyticsAPIHandler {
+	return &AnalyticsAPIHandler{
+		analyticsService: analyticsService,
+	}
+}
+
+// Beacon handles POST /api/v1/analytics/beacon. It always answers 204 so the
+// client can't tell whether collection is enabled, and records nothing when
+// it isn't.
+func (h *AnalyticsAPIHandler) Beacon(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	// navigator.sendBeacon posts as text/plain, so the content type is not checked
+	var beacon models.PageViewBeacon
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBeaconBytes)).Decode(&beacon); err != nil {
+		http.Error(w, "Invalid JSON data", http.StatusBadRequest)
+		return
+	}
+
+	// Usage counts are best effort and never fail the request
+	_ = h.analyticsService.RecordPageView(session.FamilyID, beacon.Page)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetUsage handles GET /api/v1/admin/analytics?days=, the family's counts
+// for the last days days (default 30)
+func (h *AnalyticsAPIHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	days := models.DefaultUsageSummaryDays
+	if daysParam := r.URL.Query().Get("days"); daysParam != "" {
+		parsed, err := strconv.Atoi(daysParam)
+		if err != nil || parsed < 1 || parsed > models.MaxUsageSummaryDays {
+			http.Error(w, fmt.Sprintf("days must be between 1 and %d", models.MaxUsageSummaryDays), http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	summary, err := h.analyticsService.GetSummary(session.FamilyID, days)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get usage summary: %v", err), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, summary)
+}
+
+// ClearUsage handles DELETE /api/v1/admin/analytics, clearing the family's counts
+func (h *AnalyticsAPIHandler) ClearUsage(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.analyticsService.Clear(session.FamilyID); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to clear usage counts: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AnalyticsAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// AnnouncementsAPIHandler handles announcements board API requests
+type AnnouncementsAPIHandler struct {
+	announcementsService *services.AnnouncementsService
+}
+
+// NewAnnouncementsAPIHandler creates a new announcements API handler
+func NewAnnouncementsAPIHandler(announcementsService *services.AnnouncementsService) *AnnouncementsAPIHandler {
+	return &AnnouncementsAPIHandler{
+		announcementsService: announcementsService,
+	}
+}
+
+// ListAnnouncements handles GET /api/v1/announcements. Parents can pass
+// ?include_expired=true to see announcements that have come down.
+func (h *AnnouncementsAPIHandler) ListAnnouncements(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	includeExpired := r.URL.Query().Get("include_expired") == "true" && session.Role == auth.RoleAdmin
+	announcements, err := h.announcementsService.ListAnnouncements(session.FamilyID, viewerID(session), includeExpired)
+	if err != nil {
+		h.writeServiceError(w, "Failed to list announcements", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, announcements)
+}
+
+// ListUnseen handles GET /api/v1/announcements/unseen, what the member
+// hasn't read yet
+func (h *AnnouncementsAPIHandler) ListUnseen(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	announcements, err := h.announcementsService.ListUnseen(session.FamilyID, viewerID(session))
+	if err != nil {
+		h.writeServiceError(w, "Failed to list unseen announcements", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, announcements)
+}
+
+// GetAnnouncement handles GET /api/v1/announcements/{announcementID}
+func (h *AnnouncementsAPIHandler) GetAnnouncement(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	announcement, err := h.announcementsService.GetAnnouncement(session.FamilyID, viewerID(session), r.PathValue("announcementID"))
+	if err != nil {
+		h.writeServiceError(w, "Failed to get announcement", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, announcement)
+}
+
+// CreateAnnouncement handles POST /api/v1/announcements
+func (h *AnnouncementsAPIHandler) CreateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CreateAnnouncementRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	announcement, err := h.announcementsService.CreateAnnouncement(session.FamilyID, session.UserID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to create announcement", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, announcement)
+}
+
+// UpdateAnnouncement handles PATCH /api/v1/announcements/{announcementID}
+func (h *AnnouncementsAPIHandler) UpdateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.UpdateAnnouncementRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	announcement, err := h.announcementsService.UpdateAnnouncement(session.FamilyID, session.UserID, r.PathValue("announcementID"), &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to update announcement", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, announcement)
+}
+
+// DeleteAnnouncement handles DELETE /api/v1/announcements/{announcementID}
+func (h *AnnouncementsAPIHandler) DeleteAnnouncement(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.announcementsService.DeleteAnnouncement(session.FamilyID, r.PathValue("announcementID")); err != nil {
+		h.writeServiceError(w, "Failed to delete announcement", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MarkRead handles POST /api/v1/announcements/{announcementID}/read
+func (h *AnnouncementsAPIHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	if viewerID(session) == "" {
+		http.Error(w, "The shared display can't mark announcements read", http.StatusForbidden)
+		return
+	}
+
+	announcement, err := h.announcementsService.MarkRead(session.FamilyID, session.UserID, r.PathValue("announcementID"))
+	if err != nil {
+		h.writeServiceError(w, "Failed to mark announcement read", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, announcement)
+}
+
+// GetReads handles GET /api/v1/announcements/{announcementID}/reads, who has read it
+func (h *AnnouncementsAPIHandler) GetReads(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	reads, err := h.announcementsService.GetReads(session.FamilyID, r.PathValue("announcementID"))
+	if err != nil {
+		h.writeServiceError(w, "Failed to get announcement reads", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, reads)
+}
+
+// viewerID is the member viewing the board. The shared display isn't any one
+// member, so it gets no read receipts and sees only what's for everyone.
+func viewerID(session *auth.Session) string {
+	if session.Role == auth.RoleShared {
+		return ""
+	}
+	return session.UserID
+}
+
+func (h *AnnouncementsAPIHandler) writeServiceError(w http.ResponseWriter, message string, err error) {
+	var validationErrs validation.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":   "validation_failed",
+			"details": validationErrs,
+		})
+		return
+	}
+	if err.Error() == "announcement not found" {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError)
+}
+
+func (h *AnnouncementsAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"famstack/internal/apierror"
+	"famstack/internal/auth"
+)
+
+// APITokensAPIHandler handles the API members manage their API tokens with
+type APITokensAPIHandler struct {
+	authService *auth.Service
+}
+
+// NewAPITokensAPIHandler creates a new API tokens API handler
+func NewAPITokensAPIHandler(authService *auth.Service) *APITokensAPIHandler {
+	return &APITokensAPIHandler{
+		authService: authService,
+	}
+}
+
+// CreateAPITokenRequest asks for a new API token
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Kind          string   `json:"kind" validate:"omitempty,oneof=personal family"`
+	Scopes        []string `json:"scopes" validate:"required"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=3650"` // 0 for a token that doesn't expire
+}
+
+// createAPITokenResponse carries the token, shown only this once
+type createAPITokenResponse struct {
+	Token    string         `json:"token"`
+	APIToken *auth.APIToken `json:"api_token"`
+}
+
+// ListTokens handles GET /api/v1/tokens, the caller's tokens, with the
+// family's for admins
+func (h *APITokensAPIHandler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.authService.ListAPITokens(session.FamilyID, session.UserID, canManageFamilyTokens(session))
+	if err != nil {
+		apierror.Error(w, fmt.Sprintf("Failed to list API tokens: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if tokens == nil {
+		tokens = []auth.APIToken{}
+	}
+	h.writeJSON(w, http.StatusOK, map[string]any{"tokens": tokens, "scopes": auth.APIScopes()})
+}
+
+// CreateToken handles POST /api/v1/tokens
+func (h *APITokensAPIHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateAPITokenRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if req.Kind == "" {
+		req.Kind = auth.APITokenPersonal
+	}
+	if req.Kind == auth.APITokenFamily && !canManageFamilyTokens(session) {
+		apierror.Error(w, "Only admins can create family tokens", http.StatusForbidden)
+		return
+	}
+
+	expiresIn := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	apiToken, token, err := h.authService.CreateAPIToken(session.FamilyID, session.UserID, req.Name, req.Kind, req.Scopes, expiresIn)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "unknown scope") {
+			apierror.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		apierror.Error(w, fmt.Sprintf("Failed to create API token: %v", err), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, createAPITokenResponse{Token: token, APIToken: apiToken})
+}
+
+// RevokeToken handles DELETE /api/v1/tokens/{tokenID}
+func (h *APITokensAPIHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.authService.RevokeAPIToken(session.FamilyID, session.UserID, r.PathValue("tokenID"), canManageFamilyTokens(session))
+	if err != nil {
+		if err.Error() == "API token not found" {
+			apierror.Error(w, "API token not found", http.StatusNotFound)
+			return
+		}
+		apierror.Error(w, fmt.Sprintf("Failed to revoke API token: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// session returns the caller's session. Tokens are managed from a signed-in
+// browser, so a token can't be used to make more tokens, and family mode
+// can't manage them either.
+func (h *APITokensAPIHandler) session(w http.ResponseWriter, r *http.Request) (*auth.Session, bool) {
+	session := auth.GetSessionFromContext(r.Context())
+	switch {
+	case session == nil:
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return nil, false
+	case session.APITokenID != "":
+		apierror.Error(w, "API tokens cannot manage API tokens", http.StatusForbidden)
+		return nil, false
+	case session.Role == auth.RoleShared:
+		apierror.Error(w, "Sign in with your password to manage API tokens", http.StatusForbidden)
+		return nil, false
+	}
+	return session, true
+}
+
+// canManageFamilyTokens reports whether the session is an admin's
+func canManageFamilyTokens(session *auth.Session) bool {
+	return auth.NewAuthorizationService(session).HasPermission(auth.EntitySetting, auth.ActionUpdate, nil)
+}
+
+func (h *APITokensAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,301 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// AssignmentsAPIHandler handles course and homework assignment API requests
+type AssignmentsAPIHandler struct {
+	assignmentsService *services.AssignmentsService
+}
+
+// NewAssignmentsAPIHandler creates a new assignments API handler
+func NewAssignmentsAPIHandler(assignmentsService *services.AssignmentsService) *AssignmentsAPIHandler {
+	return &AssignmentsAPIHandler{
+		assignmentsService: assignmentsService,
+	}
+}
+
+// ListCourses handles GET /api/v1/courses?member_id=
+func (h *AssignmentsAPIHandler) ListCourses(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	courses, err := h.assignmentsService.ListCourses(session.FamilyID, r.URL.Query().Get("member_id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list courses: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"courses": courses,
+		"count":   len(courses),
+	})
+}
+
+// CreateCourse handles POST /api/v1/courses
+func (h *AssignmentsAPIHandler) CreateCourse(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CreateCourseRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	course, err := h.assignmentsService.CreateCourse(session.FamilyID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to create course", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, course)
+}
+
+// UpdateCourse handles PATCH /api/v1/courses/{courseID}
+func (h *AssignmentsAPIHandler) UpdateCourse(w http.ResponseWriter, r *http.Request) {
+	course, ok := h.loadOwnedCourse(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCourseRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	updated, err := h.assignmentsService.UpdateCourse(course.ID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to update course", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteCourse handles DELETE /api/v1/courses/{courseID}
+func (h *AssignmentsAPIHandler) DeleteCourse(w http.ResponseWriter, r *http.Request) {
+	course, ok := h.loadOwnedCourse(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.assignmentsService.DeleteCourse(course.ID); err != nil {
+		h.writeServiceError(w, "Failed to delete course", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListAssignments handles GET /api/v1/assignments?member_id=&include_completed=
+func (h *AssignmentsAPIHandler) ListAssignments(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	includeCompleted := query.Get("include_completed") == "true"
+	assignments, err := h.assignmentsService.ListAssignments(session.FamilyID, query.Get("member_id"), includeCompleted)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list assignments: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"assignments": assignments,
+		"count":       len(assignments),
+	})
+}
+
+// CreateAssignment handles POST /api/v1/assignments
+func (h *AssignmentsAPIHandler) CreateAssignment(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CreateAssignmentRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	assignment, err := h.assignmentsService.CreateAssignment(session.FamilyID, session.UserID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to create assignment", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, assignment)
+}
+
+// GetAssignment handles GET /api/v1/assignments/{assignmentID}
+func (h *AssignmentsAPIHandler) GetAssignment(w http.ResponseWriter, r *http.Request) {
+	assignment, ok := h.loadOwnedAssignment(w, r)
+	if !ok {
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, assignment)
+}
+
+// UpdateAssignment handles PATCH /api/v1/assignments/{assignmentID}. Children use this
+// to move their work through not_started, in_progress and completed.
+func (h *AssignmentsAPIHandler) UpdateAssignment(w http.ResponseWriter, r *http.Request) {
+	assignment, ok := h.loadOwnedAssignment(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAssignmentRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	updated, err := h.assignmentsService.UpdateAssignment(assignment.ID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to update assignment", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteAssignment handles DELETE /api/v1/assignments/{assignmentID}
+func (h *AssignmentsAPIHandler) DeleteAssignment(w http.ResponseWriter, r *http.Request) {
+	assignment, ok := h.loadOwnedAssignment(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.assignmentsService.DeleteAssignment(assignment.ID); err != nil {
+		h.writeServiceError(w, "Failed to delete assignment", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UpcomingDeadlines handles GET /api/v1/assignments/upcoming?days=7
+func (h *AssignmentsAPIHandler) UpcomingDeadlines(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	days := 7
+	if daysParam := r.URL.Query().Get("days"); daysParam != "" {
+		parsed, err := strconv.Atoi(daysParam)
+		if err != nil || parsed < 1 || parsed > 90 {
+			http.Error(w, "days must be a number between 1 and 90", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	report, err := h.assignmentsService.UpcomingDeadlines(session.FamilyID, time.Now(), days)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to build deadline report: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"days":     days,
+		"students": report,
+	})
+}
+
+// loadOwnedCourse fetches the course named in the URL and checks it belongs to the caller's family
+func (h *AssignmentsAPIHandler) loadOwnedCourse(w http.ResponseWriter, r *http.Request) (*models.Course, bool) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	courseID := r.PathValue("courseID")
+
+	course, err := h.assignmentsService.GetCourse(courseID)
+	if err != nil || course.FamilyID != session.FamilyID {
+		if err != nil && err.Error() != "course not found" {
+			http.Error(w, "Failed to query course", http.StatusInternalServerError)
+			return nil, false
+		}
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return course, true
+}
+
+// loadOwnedAssignment fetches the assignment named in the URL and checks it belongs to the caller's family
+func (h *AssignmentsAPIHandler) loadOwnedAssignment(w http.ResponseWriter, r *http.Request) (*models.Assignment, bool) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	assignmentID := r.PathValue("assignmentID")
+
+	assignment, err := h.assignmentsService.GetAssignment(assignmentID)
+	if err != nil || assignment.FamilyID != session.FamilyID {
+		if err != nil && err.Error() != "assignment not found" {
+			http.Error(w, "Failed to query assignment", http.StatusInternalServerError)
+			return nil, false
+		}
+		http.Error(w, "Assignment not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return assignment, true
+}
+
+func (h *AssignmentsAPIHandler) writeServiceError(w http.ResponseWriter, message string, err error) {
+	var validationErrs validation.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":   "validation_failed",
+			"details": validationErrs,
+		})
+		return
+	}
+	switch err.Error() {
+	case "course not found":
+		http.Error(w, "Course not found", http.StatusNotFound)
+	case "assignment not found":
+		http.Error(w, "Assignment not found", http.StatusNotFound)
+	case "family member not found":
+		http.Error(w, "Family member not found", http.StatusBadRequest)
+	default:
+		http.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError)
+	}
+}
+
+func (h *AssignmentsAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// AttachmentsAPIHandler handles file upload and download API requests
+type AttachmentsAPIHandler struct {
+	attachmentsService *services.AttachmentsService
+}
+
+// NewAttachmentsAPIHandler creates a new attachments API handler
+func NewAttachmentsAPIHandler(attachmentsService *services.AttachmentsService) *AttachmentsAPIHandler {
+	return &AttachmentsAPIHandler{
+		attachmentsService: attachmentsService,
+	}
+}
+
+// UploadAttachment handles POST /api/v1/attachments?kind=attachment&filename=report.pdf.
+// The request body is the raw file, streamed to storage as it arrives; its
+// Content-Length is required so the quota can be checked before reading it.
+// Its Content-Type is kept only for the types the service allows.
+func (h *AttachmentsAPIHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	if r.ContentLength < 0 {
+		http.Error(w, "Content-Length is required", http.StatusLengthRequired)
+		return
+	}
+
+	req := &models.UploadRequest{
+		Kind:        r.URL.Query().Get("kind"),
+		FileName:    path.Base("/" + r.URL.Query().Get("filename")),
+		ContentType: r.Header.Get("Content-Type"),
+		SizeBytes:   r.ContentLength,
+	}
+	if req.Kind == "" {
+		req.Kind = models.StorageKindAttachment
+	}
+	if req.FileName == "/" {
+		req.FileName = ""
+	}
+	body := http.MaxBytesReader(w, r.Body, r.ContentLength)
+
+	object, err := h.attachmentsService.Upload(r.Context(), session.FamilyID, session.UserID, req, body)
+	if err != nil {
+		if WriteQuotaError(w, err) {
+			return
+		}
+		var validationErrs validation.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			h.writeJSON(w, http.StatusBadRequest, map[string]any{
+				"error":   "validation_failed",
+				"details": validationErrs,
+			})
+			return
+		}
+		http.Error(w, fmt.Sprintf("Failed to upload attachment: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, object)
+}
+
+// GetAttachment handles GET /api/v1/attachments/{objectID}. Backends that can
+// presign downloads redirect the client there; otherwise the file is streamed.
+func (h *AttachmentsAPIHandler) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	objectID := r.PathValue("objectID")
+
+	url, err := h.attachmentsService.PresignedURL(r.Context(), session.FamilyID, objectID)
+	if err != nil {
+		h.writeLookupError(w, err, "Failed to get attachment")
+		return
+	}
+	if url != "" {
+		http.Redirect(w, r, url, http.StatusFound)
+		return
+	}
+
+	object, body, err := h.attachmentsService.Open(r.Context(), session.FamilyID, objectID)
+	if err != nil {
+		h.writeLookupError(w, err, "Failed to get attachment")
+		return
+	}
+	defer body.Close()
+
+	// Always a download, and never sniffed into something the browser runs:
+	// files are served from the app's origin
+	disposition := "attachment"
+	if object.FileName != "" {
+		disposition = mime.FormatMediaType("attachment", map[string]string{"filename": object.FileName})
+	}
+	w.Header().Set("Content-Type", object.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(object.SizeBytes, 10))
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == "HEAD" {
+		return
+	}
+	_, _ = io.Copy(w, body)
+}
+
+// DeleteAttachment handles DELETE /api/v1/attachments/{objectID}
+func (h *AttachmentsAPIHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	objectID := r.PathValue("objectID")
+
+	if err := h.attachmentsService.Delete(r.Context(), session.FamilyID, objectID); err != nil {
+		h.writeLookupError(w, err, "Failed to delete attachment")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AttachmentsAPIHandler) writeLookupError(w http.ResponseWriter, err error, message string) {
+	if err.Error() == "storage object not found" {
+		http.Error(w, "Attachment not found", http.StatusNotFound)
+		return
+	}
+	http.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError)
+}
+
+func (h *AttachmentsAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -1,39 +1,49 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"path"
+	"strconv"
 	"strings"
 	"time"
 
+	"famstack/internal/apierror"
 	"famstack/internal/auth"
 	"famstack/internal/models"
 	"famstack/internal/services"
+	"famstack/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // CalendarAPIHandler handles calendar-related API requests
 type CalendarAPIHandler struct {
-	calendarService *services.CalendarService
+	calendarService        *services.CalendarService
+	protectedBlocksService *services.ProtectedBlocksService
+	custodyService         *services.CustodyService
+	weatherService         *services.WeatherService
+	preferencesService     *services.MemberPreferencesService
 }
 
 // NewCalendarAPIHandler creates a new calendar API handler
-func NewCalendarAPIHandler(calendarService *services.CalendarService) *CalendarAPIHandler {
+func NewCalendarAPIHandler(calendarService *services.CalendarService, protectedBlocksService *services.ProtectedBlocksService, custodyService *services.CustodyService, weatherService *services.WeatherService, preferencesService *services.MemberPreferencesService) *CalendarAPIHandler {
 	return &CalendarAPIHandler{
-		calendarService: calendarService,
+		calendarService:        calendarService,
+		protectedBlocksService: protectedBlocksService,
+		custodyService:         custodyService,
+		weatherService:         weatherService,
+		preferencesService:     preferencesService,
 	}
 }
 
-// GetEvents retrieves unified calendar events for a specific date or date range
+// GetEvents retrieves unified calendar events for a specific date or date range.
+// With ?limit= or ?cursor= they come a page at a time, soonest first.
 func (h *CalendarAPIHandler) GetEvents(w http.ResponseWriter, r *http.Request) {
 	fmt.Printf("🗓️  Calendar API called: %s\n", r.URL.String())
 
-	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Get query parameters
 	date := r.URL.Query().Get("date")
 	startDateStr := r.URL.Query().Get("start_date")
@@ -46,19 +56,24 @@ func (h *CalendarAPIHandler) GetEvents(w http.ResponseWriter, r *http.Request) {
 	if familyID == "" {
 		session := auth.GetSessionFromContext(r.Context())
 		if session == nil {
-			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 			return
 		}
 		familyID = session.FamilyID
 	}
 
+	page, paged, ok := pageRequest(w, r)
+	if !ok {
+		return
+	}
+
 	var startDate, endDate time.Time
 
 	if date != "" {
 		// Single date query - use same date for start and end
 		parsedDate, err := time.ParseInLocation("2006-01-02", date, time.UTC)
 		if err != nil {
-			http.Error(w, "Invalid date format", http.StatusBadRequest)
+			apierror.Error(w, "Invalid date format", http.StatusBadRequest)
 			return
 		}
 		startDate = parsedDate
@@ -68,12 +83,12 @@ func (h *CalendarAPIHandler) GetEvents(w http.ResponseWriter, r *http.Request) {
 		var err error
 		startDate, err = time.ParseInLocation("2006-01-02", startDateStr, time.UTC)
 		if err != nil {
-			http.Error(w, "Invalid start_date format", http.StatusBadRequest)
+			apierror.Error(w, "Invalid start_date format", http.StatusBadRequest)
 			return
 		}
 		endDate, err = time.ParseInLocation("2006-01-02", endDateStr, time.UTC)
 		if err != nil {
-			http.Error(w, "Invalid end_date format", http.StatusBadRequest)
+			apierror.Error(w, "Invalid end_date format", http.StatusBadRequest)
 			return
 		}
 		endDate = endDate.Add(24 * time.Hour) // Include the end date
@@ -86,7 +101,7 @@ func (h *CalendarAPIHandler) GetEvents(w http.ResponseWriter, r *http.Request) {
 
 	// Use the service to get events
 	fmt.Printf("🗓️  Querying events for family %s from %s to %s\n", familyID, startDate.Format(time.RFC3339), endDate.Format(time.RFC3339))
-	events, err := h.calendarService.GetUnifiedCalendarEvents(familyID, startDate, endDate)
+	events, err := h.unifiedEvents(r.Context(), familyID, startDate, endDate)
 	if err != nil {
 		fmt.Printf("❌ Calendar query error: %v\n", err)
 		// Return empty array instead of error to prevent frontend crashes
@@ -101,21 +116,27 @@ func (h *CalendarAPIHandler) GetEvents(w http.ResponseWriter, r *http.Request) {
 	fmt.Printf("✅ Found %d events\n", len(events))
 
 	w.Header().Set("Content-Type", "application/json")
+	if paged {
+		eventsPage, err := services.PageCalendarEvents(events, page)
+		if err != nil {
+			writePageError(w, "events", err)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(eventsPage); err != nil {
+			apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+		return
+	}
 	if err := json.NewEncoder(w).Encode(events); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
 // CreateEvent creates a new unified calendar event
 func (h *CalendarAPIHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	var eventData models.CreateUnifiedCalendarEventRequest
 	if err := json.NewDecoder(r.Body).Decode(&eventData); err != nil {
-		http.Error(w, "Invalid JSON data", http.StatusBadRequest)
+		apierror.Error(w, "Invalid JSON data", http.StatusBadRequest)
 		return
 	}
 
@@ -123,88 +144,297 @@ func (h *CalendarAPIHandler) CreateEvent(w http.ResponseWriter, r *http.Request)
 	if eventData.FamilyID == "" {
 		session := auth.GetSessionFromContext(r.Context())
 		if session == nil {
-			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 			return
 		}
 		eventData.FamilyID = session.FamilyID
 	}
 	// Note: EventType and Color are not part of CreateUnifiedCalendarEventRequest
 	// This is for external integration events
+	switch eventData.ConflictCheck {
+	case "", models.ConflictCheckWarn, models.ConflictCheckReject:
+	default:
+		apierror.Error(w, "conflict_check must be warn or reject", http.StatusBadRequest)
+		return
+	}
+
+	viewer := auth.CalendarViewerFromContext(r.Context())
+	attendeeIDs := eventData.AttendeeIDs()
+	if eventData.ConflictCheck == models.ConflictCheckReject {
+		conflicts, err := h.calendarService.FindEventConflicts(viewer, eventData.FamilyID, attendeeIDs, eventData.StartTime, eventData.EndTime, "")
+		if err != nil {
+			apierror.Error(w, fmt.Sprintf("Failed to check conflicts: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if len(conflicts) > 0 {
+			h.writeConflicts(w, conflicts)
+			return
+		}
+	}
 
 	// Use the service to create the event
-	event, err := h.calendarService.CreateUnifiedCalendarEvent(&eventData)
+	event, err := h.calendarService.CreateUnifiedCalendarEvent(r.Context(), &eventData)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create event: %v", err), http.StatusInternalServerError)
+		apierror.Error(w, fmt.Sprintf("Failed to create event: %v", err), http.StatusInternalServerError)
 		return
 	}
 
+	var response any = event
+	if eventData.ConflictCheck == models.ConflictCheckWarn {
+		conflicts, err := h.calendarService.FindEventConflicts(viewer, eventData.FamilyID, attendeeIDs, eventData.StartTime, eventData.EndTime, event.ID)
+		if err != nil {
+			apierror.Error(w, fmt.Sprintf("Failed to check conflicts: %v", err), http.StatusInternalServerError)
+			return
+		}
+		response = createdEventResponse{UnifiedCalendarEvent: event, Conflicts: conflicts}
+	}
+
+	// Scheduling over a protected block is allowed but flagged to the client
+	for _, warning := range h.protectedBlockWarnings(eventData.FamilyID, eventData.StartTime, eventData.EndTime) {
+		w.Header().Add("Warning", fmt.Sprintf("199 famstack %q", warning))
+	}
+
+	for _, warning := range h.custodyWarnings(eventData.FamilyID, attendeeIDs, eventData.StartTime, eventData.EndTime) {
+		w.Header().Add("Warning", fmt.Sprintf("199 famstack %q", warning))
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(event); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// createdEventResponse is a created event with the events its attendees are
+// already at, when the request asked for a warning
+type createdEventResponse struct {
+	*models.UnifiedCalendarEvent
+	Conflicts []models.EventConflict `json:"conflicts"`
+}
+
+// writeConflicts rejects an event with a 409 listing what it conflicts with
+func (h *CalendarAPIHandler) writeConflicts(w http.ResponseWriter, conflicts []models.EventConflict) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	_ = json.NewEncoder(w).Encode(struct {
+		apierror.Envelope
+		Conflicts []models.EventConflict `json:"conflicts"`
+	}{
+		Envelope:  apierror.Envelope{Error: apierror.Body{Code: apierror.CodeConflict, Message: "Attendees already have events at this time"}},
+		Conflicts: conflicts,
+	})
+}
+
+// BulkCreateEvents handles POST /api/v1/calendar/events/bulk. The batch is
+// validated as a whole; if any item is invalid nothing is written and the
+// response carries per-item statuses with 422.
+func (h *CalendarAPIHandler) BulkCreateEvents(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.BulkCreateCalendarEventsRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	if len(req.Events) > services.MaxBulkCalendarEvents {
+		apierror.Error(w, fmt.Sprintf("A bulk request may contain at most %d events", services.MaxBulkCalendarEvents), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// Events can only be imported into the caller's own family
+	if req.FamilyID != "" && req.FamilyID != user.FamilyID {
+		apierror.Error(w, "Cannot import events into another family", http.StatusForbidden)
+		return
+	}
+
+	response, err := h.calendarService.BulkCreateUnifiedCalendarEvents(r.Context(), user.FamilyID, user.ID, req.Events)
+	if err != nil {
+		apierror.Error(w, fmt.Sprintf("Failed to create events: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusCreated
+	if response.Invalid > 0 {
+		status = http.StatusUnprocessableEntity
+	} else {
+		for i := range response.Results {
+			item := req.Events[response.Results[i].Index]
+			response.Results[i].Warnings = append(
+				h.protectedBlockWarnings(user.FamilyID, item.StartTime, item.EndTime),
+				h.custodyWarnings(user.FamilyID, item.Attendees, item.StartTime, item.EndTime)...)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// unifiedEvents loads a family's events for a range, as the caller may see
+// them, under a span so slow calendar queries show up in the request's trace
+func (h *CalendarAPIHandler) unifiedEvents(ctx context.Context, familyID string, startDate, endDate time.Time) ([]models.UnifiedCalendarEvent, error) {
+	_, span := tracing.Start(ctx, "CalendarService.GetUnifiedCalendarEvents",
+		attribute.String("family.id", familyID),
+		attribute.String("calendar.range_start", startDate.Format(time.RFC3339)),
+		attribute.String("calendar.range_end", endDate.Format(time.RFC3339)),
+	)
+	events, err := h.calendarService.GetUnifiedCalendarEventsFor(auth.CalendarViewerFromContext(ctx), familyID, startDate, endDate)
+	span.SetAttributes(attribute.Int("calendar.events", len(events)))
+	tracing.End(span, err)
+	return events, err
+}
+
+// protectedBlockWarnings describes any protected blocks overlapped by the range.
+// Lookup failures are logged and treated as no warnings so they never block a write.
+func (h *CalendarAPIHandler) protectedBlockWarnings(familyID string, start, end time.Time) []string {
+	if h.protectedBlocksService == nil {
+		return nil
+	}
+
+	conflicts, err := h.protectedBlocksService.FindConflicts(familyID, start, end)
+	if err != nil {
+		fmt.Printf("⚠️  Failed to check protected blocks: %v\n", err)
+		return nil
+	}
+
+	var warnings []string
+	for _, conflict := range conflicts {
+		warnings = append(warnings, conflict.Message())
+	}
+	return warnings
+}
+
+// custodyWarnings describes attendees who are at the other household during
+// the range. Like protected blocks, failures are logged and never block a write.
+func (h *CalendarAPIHandler) custodyWarnings(familyID string, memberIDs []string, start, end time.Time) []string {
+	if h.custodyService == nil || len(memberIDs) == 0 {
+		return nil
+	}
+
+	conflicts, err := h.custodyService.FindConflicts(familyID, memberIDs, start, end)
+	if err != nil {
+		fmt.Printf("⚠️  Failed to check custody schedules: %v\n", err)
+		return nil
 	}
+
+	var warnings []string
+	for _, conflict := range conflicts {
+		warnings = append(warnings, conflict.Message())
+	}
+	return warnings
 }
 
-// UpdateEvent updates a unified calendar event
+// UpdateEvent updates a unified calendar event. For an occurrence of a
+// recurring event, the body's scope picks "this" occurrence (the default),
+// "future" occurrences from this one on, or "all" of the series.
 func (h *CalendarAPIHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement UpdateUnifiedCalendarEvent in CalendarService
-	// For now, return not implemented
-	http.Error(w, "Update unified calendar event not yet implemented", http.StatusNotImplemented)
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	eventID := r.PathValue("eventID")
+
+	var req models.UpdateUnifiedCalendarEventRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if !h.requireEventDetails(w, r, user.FamilyID, eventID) {
+		return
+	}
+
+	event, err := h.calendarService.UpdateUnifiedCalendarEvent(r.Context(), user.FamilyID, eventID, &req)
+	if err != nil {
+		h.writeEventError(w, err, "Failed to update event")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(event); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
 }
 
-// GetEvent retrieves a specific unified calendar event
-func (h *CalendarAPIHandler) GetEvent(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// requireEventDetails fails the request unless the caller sees the event in
+// full, so only those who may see a private or busy event can change it
+func (h *CalendarAPIHandler) requireEventDetails(w http.ResponseWriter, r *http.Request, familyID, eventID string) bool {
+	event, err := h.calendarService.GetUnifiedCalendarEventFor(auth.CalendarViewerFromContext(r.Context()), familyID, eventID)
+	if err != nil {
+		h.writeEventError(w, err, "Failed to get event")
+		return false
+	}
+	if event.Redacted {
+		apierror.Error(w, "Event is private", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// writeEventError maps a calendar service error to a response
+func (h *CalendarAPIHandler) writeEventError(w http.ResponseWriter, err error, message string) {
+	if apierror.WriteValidation(w, err) {
+		return
+	}
+	if errors.Is(err, services.ErrNotFound) {
+		apierror.Error(w, "Event not found", http.StatusNotFound)
 		return
 	}
+	apierror.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError)
+}
 
-	// Extract event ID from URL path
-	eventID := path.Base(r.URL.Path)
-	if eventID == "" || eventID == "/" {
-		http.Error(w, "Event ID is required", http.StatusBadRequest)
+// GetEvent retrieves a specific unified calendar event
+func (h *CalendarAPIHandler) GetEvent(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
+	eventID := r.PathValue("eventID")
+
 	// Use the service to get the event
-	event, err := h.calendarService.GetUnifiedCalendarEvent(eventID)
+	event, err := h.calendarService.GetUnifiedCalendarEventFor(auth.CalendarViewerFromContext(r.Context()), user.FamilyID, eventID)
 	if err != nil {
-		if err.Error() == "event not found" {
-			http.Error(w, "Event not found", http.StatusNotFound)
+		if errors.Is(err, services.ErrNotFound) {
+			apierror.Error(w, "Event not found", http.StatusNotFound)
 		} else {
-			http.Error(w, "Failed to query event", http.StatusInternalServerError)
+			apierror.Error(w, "Failed to query event", http.StatusInternalServerError)
 		}
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(event); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
-// DeleteEvent deletes a unified calendar event
+// DeleteEvent deletes a unified calendar event. For an occurrence of a
+// recurring event, the scope query parameter picks "this" occurrence (the
+// default), "future" occurrences from this one on, or "all" of the series.
 func (h *CalendarAPIHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "DELETE" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
-	// Extract event ID from URL path
-	eventID := path.Base(r.URL.Path)
-	if eventID == "" || eventID == "/" {
-		http.Error(w, "Event ID is required", http.StatusBadRequest)
+	eventID := r.PathValue("eventID")
+
+	if !h.requireEventDetails(w, r, user.FamilyID, eventID) {
 		return
 	}
 
-	// Use the service to delete the event
-	err := h.calendarService.DeleteEvent(eventID)
+	err := h.calendarService.DeleteUnifiedCalendarEvent(user.FamilyID, eventID, r.URL.Query().Get("scope"))
 	if err != nil {
-		if err.Error() == "event not found" {
-			http.Error(w, "Event not found", http.StatusNotFound)
-		} else {
-			http.Error(w, fmt.Sprintf("Failed to delete event: %v", err), http.StatusInternalServerError)
-		}
+		h.writeEventError(w, err, "Failed to delete event")
 		return
 	}
 
@@ -215,11 +445,6 @@ func (h *CalendarAPIHandler) DeleteEvent(w http.ResponseWriter, r *http.Request)
 func (h *CalendarAPIHandler) GetCalendarDays(w http.ResponseWriter, r *http.Request) {
 	fmt.Printf("🗓️  Calendar Days API called: %s\n", r.URL.String())
 
-	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Parse query parameters
 	startDateStr := r.URL.Query().Get("startDate")
 	endDateStr := r.URL.Query().Get("endDate")
@@ -228,63 +453,56 @@ func (h *CalendarAPIHandler) GetCalendarDays(w http.ResponseWriter, r *http.Requ
 
 	// Validate required parameters
 	if startDateStr == "" || endDateStr == "" {
-		http.Error(w, "startDate and endDate are required", http.StatusBadRequest)
+		apierror.Error(w, "startDate and endDate are required", http.StatusBadRequest)
 		return
 	}
 
 	// Parse dates
 	startDate, err := time.Parse("2006-01-02", startDateStr)
 	if err != nil {
-		http.Error(w, "Invalid startDate format (expected YYYY-MM-DD)", http.StatusBadRequest)
+		apierror.Error(w, "Invalid startDate format (expected YYYY-MM-DD)", http.StatusBadRequest)
 		return
 	}
 
 	endDate, err := time.Parse("2006-01-02", endDateStr)
 	if err != nil {
-		http.Error(w, "Invalid endDate format (expected YYYY-MM-DD)", http.StatusBadRequest)
+		apierror.Error(w, "Invalid endDate format (expected YYYY-MM-DD)", http.StatusBadRequest)
 		return
 	}
 
 	// Validate date range (max 31 days)
 	daysDiff := endDate.Sub(startDate).Hours() / 24
 	if daysDiff < 0 {
-		http.Error(w, "endDate must be after startDate", http.StatusBadRequest)
+		apierror.Error(w, "endDate must be after startDate", http.StatusBadRequest)
 		return
 	}
 	if daysDiff > 31 {
-		http.Error(w, "Date range cannot exceed 31 days", http.StatusBadRequest)
+		apierror.Error(w, "Date range cannot exceed 31 days", http.StatusBadRequest)
 		return
 	}
 
 	// Parse people filter
-	var requestedPeople []string
-	if peopleParam != "" {
-		requestedPeople = strings.Split(peopleParam, ",")
-		// Trim whitespace
-		for i, person := range requestedPeople {
-			requestedPeople[i] = strings.TrimSpace(person)
-		}
-	}
+	requestedPeople := parsePeople(peopleParam)
 
 	// Get family ID from session
 	session := auth.GetSessionFromContext(r.Context())
 	if session == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 	familyID := session.FamilyID
 
-	// Set timezone (default to family timezone or UTC)
-	timezone := "UTC"
-	if timezoneParam != "" {
-		timezone = timezoneParam
+	// Days are laid out in the requested timezone, or the family's
+	loc, ok := h.viewLocation(w, familyID, timezoneParam)
+	if !ok {
+		return
 	}
 
 	fmt.Printf("🗓️  Querying layered calendar: family=%s, start=%s, end=%s, people=%v, timezone=%s\n",
-		familyID, startDateStr, endDateStr, requestedPeople, timezone)
+		familyID, startDateStr, endDateStr, requestedPeople, loc)
 
 	// Get events using existing service
-	events, err := h.calendarService.GetUnifiedCalendarEvents(familyID, startDate, endDate.Add(24*time.Hour))
+	events, err := h.viewEvents(r, familyID, startDate, endDate)
 	if err != nil {
 		fmt.Printf("❌ Calendar days query error: %v\n", err)
 		events = []models.UnifiedCalendarEvent{}
@@ -296,13 +514,317 @@ func (h *CalendarAPIHandler) GetCalendarDays(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Convert to layered format
-	response := h.convertToLayeredResponse(events, startDate, endDate, requestedPeople, timezone)
+	response := h.convertToLayeredResponse(familyID, events, startDate, endDate, requestedPeople, loc)
+
+	// Protected blocks are drawn as background bands behind the event layers
+	if h.protectedBlocksService != nil {
+		blocks, err := h.protectedBlocksService.ListBlocks(familyID, true)
+		if err != nil {
+			fmt.Printf("❌ Protected blocks query error: %v\n", err)
+		} else {
+			h.addProtectedBlockBands(response.Days, blocks)
+		}
+	}
+
+	// Each day carries its forecast, for families that set their location
+	if h.weatherService != nil {
+		forecasts, err := h.weatherService.Forecasts(familyID, startDateStr, endDateStr)
+		if err != nil {
+			fmt.Printf("❌ Weather query error: %v\n", err)
+		}
+		for i := range response.Days {
+			if forecast, ok := forecasts[response.Days[i].Date]; ok {
+				response.Days[i].Weather = &forecast
+			}
+		}
+	}
+
+	// The caller's preferences come along so every client renders the days alike
+	response.Preferences = renderPreferences(r, h.preferencesService)
 
 	fmt.Printf("✅ Returning %d days with %d total events\n", len(response.Days), response.Metadata.TotalEvents)
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// GetMonth handles GET /api/v1/calendar/month?month=YYYY-MM. It returns the
+// month as whole weeks from the caller's week start, with each day's event
+// counts and first few events, instead of every event's layout. Optional
+// parameters: people (comma separated) and timezone, the family's by default.
+func (h *CalendarAPIHandler) GetMonth(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+	loc, ok := h.viewLocation(w, session.FamilyID, params.Get("timezone"))
+	if !ok {
+		return
+	}
+
+	month := time.Now().In(loc)
+	if monthParam := params.Get("month"); monthParam != "" {
+		parsed, err := time.Parse("2006-01", monthParam)
+		if err != nil {
+			apierror.Error(w, "Invalid month format (expected YYYY-MM)", http.StatusBadRequest)
+			return
+		}
+		month = parsed
+	}
+
+	preferences := renderPreferences(r, h.preferencesService)
+	weekStart := models.WeekStartSunday
+	if preferences != nil && preferences.WeekStart != "" {
+		weekStart = preferences.WeekStart
+	}
+
+	gridStart, gridEnd := services.MonthGridRange(month.Year(), month.Month(), weekStart)
+	events, err := h.viewEvents(r, session.FamilyID, gridStart, gridEnd)
+	if err != nil {
+		apierror.Error(w, fmt.Sprintf("Failed to get events: %v", err), http.StatusInternalServerError)
+		return
+	}
+	requestedPeople := parsePeople(params.Get("people"))
+	if len(requestedPeople) > 0 {
+		events = h.filterEventsByPeople(events, requestedPeople)
+	}
+
+	response := services.BuildMonthView(events, month.Year(), month.Month(), loc, weekStart)
+	response.RequestedPeople = requestedPeople
+	response.Preferences = preferences
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// GetAgenda handles GET /api/v1/calendar/agenda?startDate=YYYY-MM-DD&endDate=YYYY-MM-DD.
+// It returns the events as one chronological list, grouped by day, starting
+// today and covering two weeks by default. Optional parameters: people
+// (comma separated) and timezone, the family's by default.
+func (h *CalendarAPIHandler) GetAgenda(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+	loc, ok := h.viewLocation(w, session.FamilyID, params.Get("timezone"))
+	if !ok {
+		return
+	}
+
+	now := time.Now().In(loc)
+	startDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if value := params.Get("startDate"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			apierror.Error(w, "Invalid startDate format (expected YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		startDate = parsed
+	}
+	endDate := startDate.AddDate(0, 0, models.DefaultAgendaDays-1)
+	if value := params.Get("endDate"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			apierror.Error(w, "Invalid endDate format (expected YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		endDate = parsed
+	}
+	if endDate.Before(startDate) {
+		apierror.Error(w, "endDate must not be before startDate", http.StatusBadRequest)
+		return
+	}
+	if endDate.After(startDate.AddDate(0, 0, models.MaxAgendaDays-1)) {
+		apierror.Error(w, fmt.Sprintf("Date range cannot exceed %d days", models.MaxAgendaDays), http.StatusBadRequest)
+		return
+	}
+
+	events, err := h.viewEvents(r, session.FamilyID, startDate, endDate)
+	if err != nil {
+		apierror.Error(w, fmt.Sprintf("Failed to get events: %v", err), http.StatusInternalServerError)
+		return
+	}
+	requestedPeople := parsePeople(params.Get("people"))
+	if len(requestedPeople) > 0 {
+		events = h.filterEventsByPeople(events, requestedPeople)
+	}
+
+	response := models.AgendaResponse{
+		StartDate:       startDate.Format("2006-01-02"),
+		EndDate:         endDate.Format("2006-01-02"),
+		Timezone:        loc.String(),
+		RequestedPeople: requestedPeople,
+		Items:           services.BuildAgenda(events, startDate, endDate, loc),
+		Preferences:     renderPreferences(r, h.preferencesService),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// viewLocation returns the timezone a view is shown in: the requested one,
+// or the family's. It writes a 400 for a timezone that isn't a tz name.
+func (h *CalendarAPIHandler) viewLocation(w http.ResponseWriter, familyID, timezone string) (*time.Location, bool) {
+	if timezone == "" {
+		var err error
+		timezone, err = h.calendarService.FamilyTimezone(familyID)
+		if err != nil {
+			fmt.Printf("❌ Family timezone query error: %v\n", err)
+			timezone = "UTC"
+		}
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "Local" {
+		apierror.Error(w, "Invalid timezone (expected a tz database name like America/Chicago)", http.StatusBadRequest)
+		return nil, false
+	}
+	return loc, true
+}
+
+// viewEvents loads the events a view of the days from startDate through
+// endDate needs. The listing reads the range in family time, so a day
+// either side covers the days in any other timezone.
+func (h *CalendarAPIHandler) viewEvents(r *http.Request, familyID string, startDate, endDate time.Time) ([]models.UnifiedCalendarEvent, error) {
+	return h.unifiedEvents(r.Context(), familyID, startDate.AddDate(0, 0, -1), endDate.AddDate(0, 0, 2))
+}
+
+// parsePeople splits a comma-separated people filter, dropping blanks
+func parsePeople(param string) []string {
+	var people []string
+	for _, person := range strings.Split(param, ",") {
+		if person = strings.TrimSpace(person); person != "" {
+			people = append(people, person)
+		}
+	}
+	return people
+}
+
+// GetFreeBusy handles GET /api/v1/calendar/freebusy?members=a,b&date=YYYY-MM-DD.
+// It returns when any of the members is busy that day, in the calendar's
+// 15-minute slots, and the slots they are all free. Free slots shorter than
+// min_duration minutes (15 by default, rounded up to whole slots) are left out.
+func (h *CalendarAPIHandler) GetFreeBusy(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+	memberIDs := parsePeople(params.Get("members"))
+	if len(memberIDs) == 0 {
+		apierror.Error(w, "members is required", http.StatusBadRequest)
+		return
+	}
+
+	date := time.Now()
+	if dateParam := params.Get("date"); dateParam != "" {
+		parsed, err := time.Parse("2006-01-02", dateParam)
+		if err != nil {
+			apierror.Error(w, "Invalid date format (expected YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		date = parsed
+	}
+
+	minDuration := models.DefaultFreeBusyMinDuration
+	if value := params.Get("min_duration"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > models.MaxFreeBusyMinDuration {
+			apierror.Error(w, fmt.Sprintf("min_duration must be between 1 and %d minutes", models.MaxFreeBusyMinDuration), http.StatusBadRequest)
+			return
+		}
+		minDuration = parsed
+	}
+
+	freeBusy, err := h.calendarService.FreeBusy(auth.CalendarViewerFromContext(r.Context()), session.FamilyID, memberIDs, date)
+	if err != nil {
+		apierror.Error(w, fmt.Sprintf("Failed to look up free/busy: %v", err), http.StatusInternalServerError)
+		return
+	}
+	h.placeFreeBusy(freeBusy, minDuration)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(freeBusy); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// placeFreeBusy positions the busy blocks in the day's slots, clipped to the
+// day, and fills in the free runs of slots lasting at least minDuration
+// minutes. A block that takes part of a slot takes all of it.
+func (h *CalendarAPIHandler) placeFreeBusy(freeBusy *models.FreeBusy, minDuration int) {
+	freeBusy.MinDuration = minDuration
+	loc, err := time.LoadLocation(freeBusy.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	day, err := time.ParseInLocation("2006-01-02", freeBusy.Date, loc)
+	if err != nil {
+		return
+	}
+
+	var taken [services.SlotsPerDay]bool
+	for i := range freeBusy.Busy {
+		block := &freeBusy.Busy[i]
+
+		block.StartSlot = 0
+		if block.StartTime.Format("2006-01-02") == freeBusy.Date {
+			block.StartSlot = services.TimeToSlot(block.StartTime)
+		}
+		block.EndSlot = services.SlotsPerDay
+		if block.EndTime.Format("2006-01-02") == freeBusy.Date {
+			block.EndSlot = services.TimeToSlot(block.EndTime)
+			if block.EndTime.Minute()%services.SlotMinutes != 0 {
+				block.EndSlot++
+			}
+		}
+		if block.EndSlot <= block.StartSlot {
+			block.EndSlot = block.StartSlot + 1
+		}
+
+		for slot := block.StartSlot; slot < block.EndSlot && slot < services.SlotsPerDay; slot++ {
+			taken[slot] = true
+		}
+	}
+
+	slotTime := func(slot int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), 0, slot*services.SlotMinutes, 0, 0, loc)
+	}
+	minSlots := (minDuration + services.SlotMinutes - 1) / services.SlotMinutes
+	freeBusy.Free = []models.FreeSlot{}
+	for start := 0; start < services.SlotsPerDay; {
+		if taken[start] {
+			start++
+			continue
+		}
+		end := start
+		for end < services.SlotsPerDay && !taken[end] {
+			end++
+		}
+		if end-start >= minSlots {
+			startTime, endTime := slotTime(start), slotTime(end)
+			freeBusy.Free = append(freeBusy.Free, models.FreeSlot{
+				StartTime:       startTime,
+				EndTime:         endTime,
+				StartSlot:       start,
+				EndSlot:         end,
+				DurationMinutes: int(endTime.Sub(startTime) / time.Minute),
+			})
+		}
+		start = end
 	}
 }
 
@@ -349,23 +871,25 @@ func (h *CalendarAPIHandler) filterEventsByPeople(events []models.UnifiedCalenda
 
 // convertToLayeredResponse converts unified events to layered calendar format
 func (h *CalendarAPIHandler) convertToLayeredResponse(
+	familyID string,
 	events []models.UnifiedCalendarEvent,
 	startDate, endDate time.Time,
 	requestedPeople []string,
-	timezone string,
+	loc *time.Location,
 ) models.DaysResponse {
 	days := make([]models.DayView, 0)
 	totalEvents := 0
 
 	// Process each day in the range
-	for d := startDate; !d.After(endDate); d = d.Add(24 * time.Hour) {
+	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
 		dayStr := d.Format("2006-01-02")
+		dayStart := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, loc)
 
 		// Filter events for this day
-		dayEvents := h.filterEventsForDay(events, d)
+		dayEvents := h.filterEventsForDay(events, dayStart)
 
 		// Convert to layered format
-		layers := h.calculateEventLayers(dayEvents, timezone)
+		layers := h.calendarService.LayoutDay(familyID, dayStart, dayEvents)
 
 		dayView := models.DayView{
 			Date:   dayStr,
@@ -385,7 +909,7 @@ func (h *CalendarAPIHandler) convertToLayeredResponse(
 	return models.DaysResponse{
 		StartDate:       startDate.Format("2006-01-02"),
 		EndDate:         endDate.Format("2006-01-02"),
-		Timezone:        timezone,
+		Timezone:        loc.String(),
 		RequestedPeople: requestedPeople,
 		Days:            days,
 		Metadata: models.DaysResponseMetadata{
@@ -396,211 +920,55 @@ func (h *CalendarAPIHandler) convertToLayeredResponse(
 	}
 }
 
-// filterEventsForDay returns events that occur on the specified day
-func (h *CalendarAPIHandler) filterEventsForDay(events []models.UnifiedCalendarEvent, day time.Time) []models.UnifiedCalendarEvent {
-	dayStart := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
-	dayEnd := dayStart.Add(24 * time.Hour)
-
-	var dayEvents []models.UnifiedCalendarEvent
-	for _, event := range events {
-		// Check if event overlaps with this day
-		if event.StartTime.Before(dayEnd) && event.EndTime.After(dayStart) {
-			dayEvents = append(dayEvents, event)
-		}
-	}
+// addProtectedBlockBands places each protected block on the days it recurs
+func (h *CalendarAPIHandler) addProtectedBlockBands(days []models.DayView, blocks []models.ProtectedTimeBlock) {
+	for i := range days {
+		days[i].ProtectedBlocks = []models.ProtectedBlockBand{}
 
-	return dayEvents
-}
-
-// calculateEventLayers implements the layer assignment algorithm
-func (h *CalendarAPIHandler) calculateEventLayers(events []models.UnifiedCalendarEvent, timezone string) []models.CalendarLayer {
-	if len(events) == 0 {
-		return []models.CalendarLayer{}
-	}
-
-	// Convert events to slot-based format
-	viewEvents := make([]models.CalendarViewEvent, 0, len(events))
-	for _, event := range events {
-		viewEvent := h.convertToViewEvent(event, timezone)
-		viewEvents = append(viewEvents, viewEvent)
-	}
-
-	// First pass: assign events to layers
-	layers := []models.CalendarLayer{}
-
-	for i, event := range viewEvents {
-		// Find the first layer where this event can fit
-		layerIndex := h.findAvailableLayer(layers, event)
-
-		// Ensure we have enough layers
-		for len(layers) <= layerIndex {
-			layers = append(layers, models.CalendarLayer{
-				LayerIndex: len(layers),
-				Events:     []models.CalendarViewEvent{},
-			})
+		date, err := time.Parse("2006-01-02", days[i].Date)
+		if err != nil {
+			continue
 		}
 
-		// Set initial overlap info (will be updated in second pass)
-		viewEvents[i].OverlapGroup = 1
-		viewEvents[i].OverlapIndex = layerIndex
-
-		// Add event to the layer
-		layers[layerIndex].Events = append(layers[layerIndex].Events, viewEvents[i])
-	}
-
-	// Second pass: calculate overlap groups based on actual layer usage
-	h.calculateOverlapGroupsFromLayers(layers)
-
-	return layers
-}
-
-// findAvailableLayer finds the first layer where an event can be placed without conflicts
-func (h *CalendarAPIHandler) findAvailableLayer(layers []models.CalendarLayer, newEvent models.CalendarViewEvent) int {
-	for layerIndex, layer := range layers {
-		hasConflict := false
-
-		for _, existingEvent := range layer.Events {
-			if h.eventsOverlap(newEvent, existingEvent) {
-				hasConflict = true
-				break
+		for j := range blocks {
+			block := &blocks[j]
+			if !block.OccursOn(date.Weekday()) {
+				continue
 			}
-		}
-
-		if !hasConflict {
-			return layerIndex
-		}
-	}
-
-	// No available layer found, create a new one
-	return len(layers)
-}
 
-// eventsOverlap checks if two events overlap in time
-func (h *CalendarAPIHandler) eventsOverlap(event1, event2 models.CalendarViewEvent) bool {
-	return event1.StartSlot < event2.EndSlot && event1.EndSlot > event2.StartSlot
-}
-
-// calculateOverlapGroupsFromLayers determines overlap groups based on layer assignment
-func (h *CalendarAPIHandler) calculateOverlapGroupsFromLayers(layers []models.CalendarLayer) {
-	// Build a map of all events for easier lookup
-	allEvents := make(map[string]models.CalendarViewEvent)
-	eventToLayer := make(map[string]int)
-
-	for layerIndex, layer := range layers {
-		for _, event := range layer.Events {
-			allEvents[event.ID] = event
-			eventToLayer[event.ID] = layerIndex
-		}
-	}
-
-	// For each event, find its "overlap group" - all events that are transitively connected
-	for currentLayerIndex, currentLayer := range layers {
-		for currentEventIndex, currentEvent := range currentLayer.Events {
-			// Find all events that are transitively connected to this event
-			connectedEvents := h.findConnectedEvents(currentEvent, allEvents)
-
-			// Find all layers that contain connected events
-			layersUsed := make(map[int]bool)
-			for _, connectedEvent := range connectedEvents {
-				layerIndex := eventToLayer[connectedEvent.ID]
-				layersUsed[layerIndex] = true
+			blockStart, blockEnd, err := block.WindowOn(date, time.UTC)
+			if err != nil {
+				continue
 			}
 
-			// The overlap group size is the number of layers used by connected events
-			overlapGroup := len(layersUsed)
-
-			// Update the event
-			layers[currentLayerIndex].Events[currentEventIndex].OverlapGroup = overlapGroup
-			layers[currentLayerIndex].Events[currentEventIndex].OverlapIndex = currentLayerIndex
+			endSlot := services.TimeToSlot(blockEnd)
+			startSlot := services.TimeToSlot(blockStart)
+			if endSlot <= startSlot {
+				endSlot = startSlot + 1
+			}
 
-			// Debug logging (commented out for production)
-			// fmt.Printf("🔍 Event '%s' (layer %d): overlapGroup=%d, overlapIndex=%d, layers=%v, connected=%d\n",
-			//	currentEvent.Title, currentLayerIndex, overlapGroup, currentLayerIndex, layersUsed, len(connectedEvents))
+			days[i].ProtectedBlocks = append(days[i].ProtectedBlocks, models.ProtectedBlockBand{
+				BlockID:   block.ID,
+				Title:     block.Title,
+				StartSlot: startSlot,
+				EndSlot:   endSlot,
+				Color:     block.Color,
+			})
 		}
 	}
 }
 
-// findConnectedEvents finds all events that are transitively connected through overlaps
-func (h *CalendarAPIHandler) findConnectedEvents(startEvent models.CalendarViewEvent, allEvents map[string]models.CalendarViewEvent) []models.CalendarViewEvent {
-	visited := make(map[string]bool)
-	var result []models.CalendarViewEvent
+// filterEventsForDay returns events that occur on the day starting at
+// dayStart, in its timezone
+func (h *CalendarAPIHandler) filterEventsForDay(events []models.UnifiedCalendarEvent, dayStart time.Time) []models.UnifiedCalendarEvent {
+	dayEnd := services.NextDay(dayStart)
 
-	// DFS to find all connected events
-	var dfs func(models.CalendarViewEvent)
-	dfs = func(event models.CalendarViewEvent) {
-		if visited[event.ID] {
-			return
-		}
-		visited[event.ID] = true
-		result = append(result, event)
-
-		// Find all events that overlap with this one
-		for _, otherEvent := range allEvents {
-			if !visited[otherEvent.ID] && h.eventsOverlap(event, otherEvent) {
-				dfs(otherEvent)
-			}
+	var dayEvents []models.UnifiedCalendarEvent
+	for _, event := range events {
+		if services.EventOverlaps(&event, dayStart, dayEnd) {
+			dayEvents = append(dayEvents, event)
 		}
 	}
 
-	dfs(startEvent)
-	return result
-}
-
-// convertToViewEvent converts a UnifiedCalendarEvent to CalendarViewEvent with slot calculation
-func (h *CalendarAPIHandler) convertToViewEvent(event models.UnifiedCalendarEvent, timezone string) models.CalendarViewEvent {
-	// Convert times to slots (15-minute intervals, 0-359)
-	startSlot := h.timeToSlot(event.StartTime)
-	endSlot := h.timeToSlot(event.EndTime)
-
-	// Ensure endSlot is at least startSlot + 1
-	if endSlot <= startSlot {
-		endSlot = startSlot + 1
-	}
-
-	// Extract attendee IDs
-	attendeeIDs := make([]string, len(event.Attendees))
-	for i, attendee := range event.Attendees {
-		attendeeIDs[i] = attendee.ID
-	}
-
-	// Determine owner ID
-	ownerID := ""
-	if event.CreatedBy != nil {
-		ownerID = *event.CreatedBy
-	}
-
-	return models.CalendarViewEvent{
-		ID:           event.ID,
-		Title:        event.Title,
-		StartSlot:    startSlot,
-		EndSlot:      endSlot,
-		Color:        event.Color,
-		OwnerID:      ownerID,
-		AttendeeIDs:  attendeeIDs,
-		OverlapGroup: 1, // Default to 1, will be updated in calculateOverlapInfo
-		OverlapIndex: 0, // Default to 0, will be updated in calculateOverlapInfo
-		Attendees:    event.Attendees,
-		IsPrivate:    false, // TODO: Implement privacy logic
-		Location:     event.Location,
-		Description:  event.Description,
-	}
-}
-
-// timeToSlot converts a time to a slot number (0-359 for 24 hours in 15-minute intervals)
-func (h *CalendarAPIHandler) timeToSlot(t time.Time) int {
-	// Get minutes since midnight
-	minutesSinceMidnight := t.Hour()*60 + t.Minute()
-
-	// Convert to 15-minute slots
-	slot := minutesSinceMidnight / 15
-
-	// Ensure slot is within valid range (0-359)
-	if slot < 0 {
-		slot = 0
-	}
-	if slot >= 360 {
-		slot = 359
-	}
-
-	return slot
+	return dayEvents
 }
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+	"famstack/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertToLayeredResponse_UsesRequestedTimezone(t *testing.T) {
+	handler := &CalendarAPIHandler{calendarService: services.NewCalendarService(nil)}
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+
+	// 03:00 UTC on the 10th is still the evening of the 9th in Chicago
+	events := []models.UnifiedCalendarEvent{
+		{ID: "dinner", StartTime: time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), EndTime: time.Date(2026, 3, 10, 1, 0, 0, 0, time.UTC)},
+		{ID: "movie", StartTime: time.Date(2026, 3, 10, 3, 0, 0, 0, time.UTC), EndTime: time.Date(2026, 3, 10, 5, 0, 0, 0, time.UTC)},
+	}
+	start := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+
+	response := handler.convertToLayeredResponse("fam_1", events, start, start.AddDate(0, 0, 1), nil, chicago)
+
+	assert.Equal(t, "America/Chicago", response.Timezone)
+	require.Len(t, response.Days, 2)
+	require.Len(t, response.Days[0].Layers, 1)
+	assert.Empty(t, response.Days[1].Layers)
+
+	placed := response.Days[0].Layers[0].Events
+	require.Len(t, placed, 2)
+	assert.Equal(t, 76, placed[0].StartSlot, "dinner at 19:00 CDT")
+	assert.Equal(t, 88, placed[1].StartSlot, "movie at 22:00 CDT")
+	assert.Equal(t, services.SlotsPerDay, placed[1].EndSlot, "the movie runs past midnight")
+}
+
+func TestFilterEventsForDay_DaylightSavingDays(t *testing.T) {
+	handler := &CalendarAPIHandler{}
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	event := func(id string, start time.Time) models.UnifiedCalendarEvent {
+		return models.UnifiedCalendarEvent{ID: id, StartTime: start, EndTime: start.Add(15 * time.Minute)}
+	}
+	ids := func(events []models.UnifiedCalendarEvent) []string {
+		var ids []string
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+		return ids
+	}
+
+	// A 25-hour day keeps its last hour
+	fallDay := time.Date(2026, 11, 1, 0, 0, 0, 0, chicago)
+	events := []models.UnifiedCalendarEvent{
+		event("last-hour", time.Date(2026, 11, 1, 23, 30, 0, 0, chicago)),
+		event("next-day", time.Date(2026, 11, 2, 0, 15, 0, 0, chicago)),
+	}
+	assert.Equal(t, []string{"last-hour"}, ids(handler.filterEventsForDay(events, fallDay)))
+
+	// A 23-hour day doesn't take the first hour of the next
+	springDay := time.Date(2026, 3, 8, 0, 0, 0, 0, chicago)
+	events = []models.UnifiedCalendarEvent{
+		event("last-hour", time.Date(2026, 3, 8, 23, 30, 0, 0, chicago)),
+		event("next-day", time.Date(2026, 3, 9, 0, 15, 0, 0, chicago)),
+	}
+	assert.Equal(t, []string{"last-hour"}, ids(handler.filterEventsForDay(events, springDay)))
+}
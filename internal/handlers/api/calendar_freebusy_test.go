@@ -0,0 +1,80 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+	"famstack/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func busyBlock(id, start, end string) models.BusyBlock {
+	loc, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		panic(err)
+	}
+	parse := func(value string) time.Time {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+		if err != nil {
+			panic(err)
+		}
+		return parsed
+	}
+	return models.BusyBlock{EventID: id, StartTime: parse(start), EndTime: parse(end)}
+}
+
+func TestPlaceFreeBusy_FindsSharedFreeSlots(t *testing.T) {
+	handler := &CalendarAPIHandler{}
+	freeBusy := &models.FreeBusy{
+		Date:     "2026-03-10",
+		Timezone: "America/Chicago",
+		Busy: []models.BusyBlock{
+			busyBlock("sleepover", "2026-03-09 20:00", "2026-03-10 09:00"),
+			busyBlock("dentist", "2026-03-10 10:30", "2026-03-10 11:40"),
+			busyBlock("practice", "2026-03-10 13:00", "2026-03-10 17:00"),
+			busyBlock("late-flight", "2026-03-10 22:00", "2026-03-11 01:00"),
+		},
+	}
+
+	handler.placeFreeBusy(freeBusy, 120)
+
+	assert.Equal(t, 120, freeBusy.MinDuration)
+	assert.Equal(t, 0, freeBusy.Busy[0].StartSlot)
+	assert.Equal(t, 36, freeBusy.Busy[0].EndSlot)
+	// Part of a slot counts as the whole slot
+	assert.Equal(t, 42, freeBusy.Busy[1].StartSlot)
+	assert.Equal(t, 47, freeBusy.Busy[1].EndSlot)
+	assert.Equal(t, services.SlotsPerDay, freeBusy.Busy[3].EndSlot)
+
+	// 9:00-10:30 and 11:45-13:00 are too short; 17:00-22:00 is the only window
+	require.Len(t, freeBusy.Free, 1)
+	free := freeBusy.Free[0]
+	assert.Equal(t, 68, free.StartSlot)
+	assert.Equal(t, 88, free.EndSlot)
+	assert.Equal(t, 300, free.DurationMinutes)
+	assert.Equal(t, "2026-03-10T17:00:00-05:00", free.StartTime.Format(time.RFC3339))
+	assert.Equal(t, "2026-03-10T22:00:00-05:00", free.EndTime.Format(time.RFC3339))
+}
+
+func TestPlaceFreeBusy_EmptyDayIsFree(t *testing.T) {
+	handler := &CalendarAPIHandler{}
+	freeBusy := &models.FreeBusy{Date: "2026-03-10", Timezone: "UTC", Busy: []models.BusyBlock{}}
+
+	handler.placeFreeBusy(freeBusy, 45)
+
+	require.Len(t, freeBusy.Free, 1)
+	assert.Equal(t, 0, freeBusy.Free[0].StartSlot)
+	assert.Equal(t, services.SlotsPerDay, freeBusy.Free[0].EndSlot)
+	assert.Equal(t, 24*60, freeBusy.Free[0].DurationMinutes)
+
+	// Minimum durations are rounded up to whole slots
+	freeBusy.Busy = []models.BusyBlock{
+		{StartTime: time.Date(2026, 3, 10, 0, 30, 0, 0, time.UTC), EndTime: time.Date(2026, 3, 10, 23, 0, 0, 0, time.UTC)},
+	}
+	handler.placeFreeBusy(freeBusy, 31)
+	require.Len(t, freeBusy.Free, 1)
+	assert.Equal(t, 92, freeBusy.Free[0].StartSlot)
+}
@@ -0,0 +1,361 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// CarpoolsAPIHandler handles carpool group, rotation and swap API requests.
+// Groups are shared between the organizing family and every driving family.
+type CarpoolsAPIHandler struct {
+	carpoolService *services.CarpoolService
+}
+
+// NewCarpoolsAPIHandler creates a new carpools API handler
+func NewCarpoolsAPIHandler(carpoolService *services.CarpoolService) *CarpoolsAPIHandler {
+	return &CarpoolsAPIHandler{
+		carpoolService: carpoolService,
+	}
+}
+
+// ListGroups handles GET /api/v1/carpools
+func (h *CarpoolsAPIHandler) ListGroups(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	groups, err := h.carpoolService.ListGroups(session.FamilyID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list carpools: %v", err), http.StatusInternalServerError)
+		return
+	}
+	for i := range groups {
+		hideJoinCode(&groups[i], session.FamilyID)
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"carpools": groups,
+		"count":    len(groups),
+	})
+}
+
+// CreateGroup handles POST /api/v1/carpools
+func (h *CarpoolsAPIHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CreateCarpoolGroupRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	group, err := h.carpoolService.CreateGroup(session.FamilyID, session.UserID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to create carpool", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, group)
+}
+
+// JoinGroup handles POST /api/v1/carpools/join
+func (h *CarpoolsAPIHandler) JoinGroup(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.JoinCarpoolRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	group, err := h.carpoolService.JoinGroup(session.FamilyID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to join carpool", err)
+		return
+	}
+	hideJoinCode(group, session.FamilyID)
+
+	h.writeJSON(w, http.StatusOK, group)
+}
+
+// GetGroup handles GET /api/v1/carpools/{groupID}
+func (h *CarpoolsAPIHandler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.loadGroup(w, r, false)
+	if !ok {
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, group)
+}
+
+// DeleteGroup handles DELETE /api/v1/carpools/{groupID}. Only the organizer can delete.
+func (h *CarpoolsAPIHandler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.loadGroup(w, r, true)
+	if !ok {
+		return
+	}
+
+	if err := h.carpoolService.DeleteGroup(group.ID); err != nil {
+		h.writeServiceError(w, "Failed to delete carpool", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddDriver handles POST /api/v1/carpools/{groupID}/drivers. The organizer adds its
+// own members or external contacts; other families join with the join code.
+func (h *CarpoolsAPIHandler) AddDriver(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.loadGroup(w, r, true)
+	if !ok {
+		return
+	}
+
+	var req models.AddCarpoolDriverRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	driver, err := h.carpoolService.AddDriver(group.ID, group.FamilyID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to add driver", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, driver)
+}
+
+// RemoveDriver handles DELETE /api/v1/carpools/{groupID}/drivers/{driverID}. The
+// organizer can remove anyone; a driving family can remove its own drivers.
+func (h *CarpoolsAPIHandler) RemoveDriver(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.loadGroup(w, r, false)
+	if !ok {
+		return
+	}
+
+	session := auth.GetSessionFromContext(r.Context())
+	driver := group.Driver(r.PathValue("driverID"))
+	if driver == nil {
+		http.Error(w, "Driver not found", http.StatusNotFound)
+		return
+	}
+	if group.FamilyID != session.FamilyID && !group.CanActFor(session.FamilyID, driver) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	if err := h.carpoolService.RemoveDriver(group.ID, driver.ID); err != nil {
+		h.writeServiceError(w, "Failed to remove driver", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListRotation handles GET /api/v1/carpools/{groupID}/rotation?from=&to=
+func (h *CarpoolsAPIHandler) ListRotation(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.loadGroup(w, r, false)
+	if !ok {
+		return
+	}
+
+	// Default to the next 30 days
+	query := r.URL.Query()
+	from := query.Get("from")
+	if from == "" {
+		from = time.Now().Format("2006-01-02")
+	}
+	to := query.Get("to")
+	if to == "" {
+		to = time.Now().AddDate(0, 0, 30).Format("2006-01-02")
+	}
+
+	assignments, err := h.carpoolService.ListAssignments(group.ID, from, to)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list rotation: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"from":        from,
+		"to":          to,
+		"assignments": assignments,
+	})
+}
+
+// GenerateRotation handles POST /api/v1/carpools/{groupID}/rotation
+func (h *CarpoolsAPIHandler) GenerateRotation(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.loadGroup(w, r, true)
+	if !ok {
+		return
+	}
+
+	var req models.GenerateCarpoolRotationRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	assignments, err := h.carpoolService.GenerateRotation(group.ID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to generate rotation", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"from":        req.From,
+		"to":          req.To,
+		"assignments": assignments,
+	})
+}
+
+// ListSwaps handles GET /api/v1/carpools/{groupID}/swaps?status=
+func (h *CarpoolsAPIHandler) ListSwaps(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.loadGroup(w, r, false)
+	if !ok {
+		return
+	}
+
+	swaps, err := h.carpoolService.ListSwapRequests(group.ID, r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list swap requests: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"swaps": swaps,
+		"count": len(swaps),
+	})
+}
+
+// RequestSwap handles POST /api/v1/carpools/{groupID}/swaps
+func (h *CarpoolsAPIHandler) RequestSwap(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.loadGroup(w, r, false)
+	if !ok {
+		return
+	}
+
+	var req models.CreateCarpoolSwapRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	session := auth.GetSessionFromContext(r.Context())
+	swap, err := h.carpoolService.RequestSwap(group, session.FamilyID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to request swap", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, swap)
+}
+
+// RespondToSwap handles PATCH /api/v1/carpool-swaps/{swapID}
+func (h *CarpoolsAPIHandler) RespondToSwap(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	swapID := r.PathValue("swapID")
+
+	var req models.RespondCarpoolSwapRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	swap, err := h.carpoolService.RespondToSwap(swapID, session.FamilyID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to respond to swap", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, swap)
+}
+
+// loadGroup fetches the group named in the URL (/api/v1/carpools/{groupID}/...) and
+// checks the caller's family takes part in it, or organizes it when organizerOnly is set
+func (h *CarpoolsAPIHandler) loadGroup(w http.ResponseWriter, r *http.Request, organizerOnly bool) (*models.CarpoolGroup, bool) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	group, err := h.carpoolService.GetGroup(r.PathValue("groupID"))
+	if err != nil || !group.HasFamily(session.FamilyID) {
+		if err != nil && err.Error() != "carpool group not found" {
+			http.Error(w, "Failed to query carpool", http.StatusInternalServerError)
+			return nil, false
+		}
+		http.Error(w, "Carpool not found", http.StatusNotFound)
+		return nil, false
+	}
+	if organizerOnly && group.FamilyID != session.FamilyID {
+		http.Error(w, "Only the organizing family can do that", http.StatusForbidden)
+		return nil, false
+	}
+
+	hideJoinCode(group, session.FamilyID)
+	return group, true
+}
+
+// hideJoinCode keeps the join code with the organizing family, who decides who to share it with
+func hideJoinCode(group *models.CarpoolGroup, familyID string) {
+	if group.FamilyID != familyID {
+		group.JoinCode = ""
+	}
+}
+
+func (h *CarpoolsAPIHandler) writeServiceError(w http.ResponseWriter, message string, err error) {
+	var validationErrs validation.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":   "validation_failed",
+			"details": validationErrs,
+		})
+		return
+	}
+	switch err.Error() {
+	case "carpool group not found":
+		http.Error(w, "Carpool not found", http.StatusNotFound)
+	case "carpool driver not found":
+		http.Error(w, "Driver not found", http.StatusNotFound)
+	case "carpool assignment not found":
+		http.Error(w, "Carpool assignment not found", http.StatusNotFound)
+	case "swap request not found":
+		http.Error(w, "Swap request not found", http.StatusNotFound)
+	case "activity not found":
+		http.Error(w, "Activity not found", http.StatusBadRequest)
+	case "family member not found":
+		http.Error(w, "Family member not found", http.StatusBadRequest)
+	case "not allowed to swap this assignment", "not allowed to respond to this swap":
+		http.Error(w, "Access denied", http.StatusForbidden)
+	default:
+		http.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError)
+	}
+}
+
+func (h *CarpoolsAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
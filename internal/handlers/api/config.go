@@ -4,9 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strings"
+	"slices"
 
 	"famstack/internal/config"
+	"famstack/internal/middleware"
 )
 
 // ConfigAPIHandler handles configuration API requests
@@ -23,11 +24,6 @@ func NewConfigAPIHandler(configManager *config.Manager) *ConfigAPIHandler {
 
 // GetConfig returns the current configuration
 func (h *ConfigAPIHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	cfg := h.configManager.GetConfig()
 
 	// Create response without copying the config struct (to avoid mutex copy)
@@ -36,6 +32,7 @@ func (h *ConfigAPIHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
 		"server":   cfg.Server,
 		"oauth":    cfg.OAuth,
 		"features": cfg.Features,
+		"cors":     cfg.CORS,
 	}
 
 	// Remove sensitive data from response
@@ -55,18 +52,7 @@ func (h *ConfigAPIHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
 
 // UpdateOAuthProvider updates OAuth provider configuration
 func (h *ConfigAPIHandler) UpdateOAuthProvider(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "PUT" && r.Method != "PATCH" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// Extract provider from URL path
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 6 {
-		http.Error(w, "Invalid provider", http.StatusBadRequest)
-		return
-	}
-	provider := pathParts[5] // /api/v1/config/oauth/{provider}
+	provider := r.PathValue("provider")
 
 	var req config.OAuthProvider
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -103,18 +89,7 @@ func (h *ConfigAPIHandler) UpdateOAuthProvider(w http.ResponseWriter, r *http.Re
 
 // GetOAuthProvider returns OAuth provider configuration
 func (h *ConfigAPIHandler) GetOAuthProvider(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// Extract provider from URL path
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 6 {
-		http.Error(w, "Invalid provider", http.StatusBadRequest)
-		return
-	}
-	provider := pathParts[5] // /api/v1/config/oauth/{provider}
+	provider := r.PathValue("provider")
 
 	providerConfig, err := h.configManager.GetOAuthProvider(provider)
 	if err != nil {
@@ -135,11 +110,6 @@ func (h *ConfigAPIHandler) GetOAuthProvider(w http.ResponseWriter, r *http.Reque
 
 // UpdateServerConfig updates server configuration
 func (h *ConfigAPIHandler) UpdateServerConfig(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "PUT" && r.Method != "PATCH" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	var req config.ServerConfig
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -151,6 +121,10 @@ func (h *ConfigAPIHandler) UpdateServerConfig(w http.ResponseWriter, r *http.Req
 		http.Error(w, "port is required", http.StatusBadRequest)
 		return
 	}
+	if _, err := middleware.ParseProxies(req.TrustedProxies); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	if err := h.configManager.UpdateServerConfig(req); err != nil {
 		http.Error(w, fmt.Sprintf("Failed to update server config: %v", err), http.StatusInternalServerError)
@@ -170,11 +144,6 @@ func (h *ConfigAPIHandler) UpdateServerConfig(w http.ResponseWriter, r *http.Req
 
 // UpdateFeatureConfig updates feature configuration
 func (h *ConfigAPIHandler) UpdateFeatureConfig(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "PUT" && r.Method != "PATCH" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	var req config.FeatureConfig
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -196,3 +165,70 @@ func (h *ConfigAPIHandler) UpdateFeatureConfig(w http.ResponseWriter, r *http.Re
 		return
 	}
 }
+
+// GetCORSConfig returns the cross-origin settings
+func (h *ConfigAPIHandler) GetCORSConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.configManager.GetConfig().CORS); err != nil {
+		http.Error(w, "Failed to encode config", http.StatusInternalServerError)
+		return
+	}
+}
+
+// UpdateCORSConfig replaces the cross-origin settings, which apply to the
+// next request
+func (h *ConfigAPIHandler) UpdateCORSConfig(w http.ResponseWriter, r *http.Request) {
+	var req config.CORSConfig
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.configManager.UpdateCORSConfig(req); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to update CORS config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "CORS configuration updated",
+		"config":  req,
+	}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// ReloadConfig re-reads the configuration file and applies it, for edits
+// made to the file by hand. Sections read only at startup are listed as
+// needing a restart.
+func (h *ConfigAPIHandler) ReloadConfig(w http.ResponseWriter, r *http.Request) {
+	changed, err := h.configManager.Reload()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	restartRequired := []string{}
+	for _, section := range changed {
+		if slices.Contains(config.RestartSections, section) {
+			restartRequired = append(restartRequired, section)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":           "success",
+		"message":          "Configuration reloaded",
+		"changed":          changed,
+		"restart_required": restartRequired,
+	}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
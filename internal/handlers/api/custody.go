@@ -0,0 +1,334 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// defaultCustodyCalendarDays is how far the custody calendar looks ahead without an end date
+const defaultCustodyCalendarDays = 28
+
+// CustodyAPIHandler handles custody schedule API requests
+type CustodyAPIHandler struct {
+	custodyService *services.CustodyService
+}
+
+// NewCustodyAPIHandler creates a new custody API handler
+func NewCustodyAPIHandler(custodyService *services.CustodyService) *CustodyAPIHandler {
+	return &CustodyAPIHandler{
+		custodyService: custodyService,
+	}
+}
+
+// GetCalendar handles GET /api/v1/custody?start=YYYY-MM-DD&end=YYYY-MM-DD,
+// listing who is at this household each day. Members without a custody
+// schedule are here every day and are left out.
+func (h *CustodyAPIHandler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	start, end, ok := h.parseRange(w, r)
+	if !ok {
+		return
+	}
+
+	calendar, err := h.custodyService.BuildCalendar(session.FamilyID, start, end)
+	if err != nil {
+		h.writeServiceError(w, "Failed to build custody calendar", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, calendar)
+}
+
+// CheckConflicts handles POST /api/v1/custody/check, warning about members
+// who would be at the other household during a proposed event
+func (h *CustodyAPIHandler) CheckConflicts(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CheckCustodyRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	conflicts, err := h.custodyService.FindConflicts(session.FamilyID, req.MemberIDs, req.StartTime, req.EndTime)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to check custody: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	warnings := make([]string, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		warnings = append(warnings, conflict.Message())
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"conflicts": conflicts,
+		"warnings":  warnings,
+	})
+}
+
+// ListSchedules handles GET /api/v1/custody/schedules
+func (h *CustodyAPIHandler) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	schedules, err := h.custodyService.ListSchedules(session.FamilyID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list custody schedules: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"custody_schedules": schedules,
+		"count":             len(schedules),
+	})
+}
+
+// CreateSchedule handles POST /api/v1/custody/schedules
+func (h *CustodyAPIHandler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CreateCustodyScheduleRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	schedule, err := h.custodyService.CreateSchedule(session.FamilyID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to create custody schedule", err)
+		return
+	}
+
+	h.removeAwayDayChores(session.FamilyID, schedule.MemberID)
+	h.writeJSON(w, http.StatusCreated, schedule)
+}
+
+// UpdateSchedule handles PATCH /api/v1/custody/schedules/{scheduleID}
+func (h *CustodyAPIHandler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := h.loadOwnedSchedule(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCustodyScheduleRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	updated, err := h.custodyService.UpdateSchedule(schedule.ID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to update custody schedule", err)
+		return
+	}
+
+	h.removeAwayDayChores(updated.FamilyID, updated.MemberID)
+	h.writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteSchedule handles DELETE /api/v1/custody/schedules/{scheduleID}
+func (h *CustodyAPIHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := h.loadOwnedSchedule(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.custodyService.DeleteSchedule(schedule.ID); err != nil {
+		h.writeServiceError(w, "Failed to delete custody schedule", err)
+		return
+	}
+
+	// Removing one of several schedules can leave the member away on more days
+	h.removeAwayDayChores(schedule.FamilyID, schedule.MemberID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListOverrides handles GET /api/v1/custody/overrides?start=YYYY-MM-DD&end=YYYY-MM-DD
+func (h *CustodyAPIHandler) ListOverrides(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	start, end, ok := h.parseRange(w, r)
+	if !ok {
+		return
+	}
+
+	overrides, err := h.custodyService.ListOverrides(session.FamilyID, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list custody overrides: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"custody_overrides": overrides,
+		"count":             len(overrides),
+	})
+}
+
+// SetOverride handles PUT /api/v1/custody/overrides
+func (h *CustodyAPIHandler) SetOverride(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.SetCustodyOverrideRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	override, err := h.custodyService.SetOverride(session.FamilyID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to set custody override", err)
+		return
+	}
+
+	if !override.Present {
+		h.removeAwayDayChores(session.FamilyID, override.MemberID)
+	}
+	h.writeJSON(w, http.StatusOK, override)
+}
+
+// DeleteOverride handles DELETE /api/v1/custody/overrides/{overrideID}
+func (h *CustodyAPIHandler) DeleteOverride(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	overrideID := r.PathValue("overrideID")
+
+	override, err := h.custodyService.GetOverride(overrideID)
+	if err != nil || override.FamilyID != session.FamilyID {
+		if err != nil && !errors.Is(err, services.ErrNotFound) {
+			http.Error(w, "Failed to query custody override", http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, "Custody override not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.custodyService.DeleteOverride(override.ID); err != nil {
+		h.writeServiceError(w, "Failed to delete custody override", err)
+		return
+	}
+
+	// Dropping a swapped-in day puts the member back on their regular schedule
+	if override.Present {
+		h.removeAwayDayChores(override.FamilyID, override.MemberID)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// removeAwayDayChores clears already-generated chores from days the member is
+// now away. Failures are logged; the custody change itself has been saved.
+func (h *CustodyAPIHandler) removeAwayDayChores(familyID, memberID string) {
+	removed, err := h.custodyService.RemoveAwayDayChores(familyID, memberID, time.Now().UTC())
+	if err != nil {
+		fmt.Printf("⚠️  Failed to remove chores on away days for %s: %v\n", memberID, err)
+		return
+	}
+	if removed > 0 {
+		fmt.Printf("🗑️  Removed %d chores on away days for %s\n", removed, memberID)
+	}
+}
+
+// parseRange reads start and end dates, defaulting to the next four weeks
+func (h *CustodyAPIHandler) parseRange(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if startParam := r.URL.Query().Get("start"); startParam != "" {
+		parsed, err := time.Parse("2006-01-02", startParam)
+		if err != nil {
+			http.Error(w, "Invalid start date format (expected YYYY-MM-DD)", http.StatusBadRequest)
+			return time.Time{}, time.Time{}, false
+		}
+		start = parsed
+	}
+
+	end := start.AddDate(0, 0, defaultCustodyCalendarDays-1)
+	if endParam := r.URL.Query().Get("end"); endParam != "" {
+		parsed, err := time.Parse("2006-01-02", endParam)
+		if err != nil {
+			http.Error(w, "Invalid end date format (expected YYYY-MM-DD)", http.StatusBadRequest)
+			return time.Time{}, time.Time{}, false
+		}
+		end = parsed
+	}
+
+	return start, end, true
+}
+
+// loadOwnedSchedule fetches the custody schedule named in the URL and checks it belongs to the caller's family
+func (h *CustodyAPIHandler) loadOwnedSchedule(w http.ResponseWriter, r *http.Request) (*models.CustodySchedule, bool) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	scheduleID := r.PathValue("scheduleID")
+
+	schedule, err := h.custodyService.GetSchedule(scheduleID)
+	if err != nil || schedule.FamilyID != session.FamilyID {
+		if err != nil && !errors.Is(err, services.ErrNotFound) {
+			http.Error(w, "Failed to query custody schedule", http.StatusInternalServerError)
+			return nil, false
+		}
+		http.Error(w, "Custody schedule not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return schedule, true
+}
+
+func (h *CustodyAPIHandler) writeServiceError(w http.ResponseWriter, message string, err error) {
+	var validationErrs validation.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":   "validation_failed",
+			"details": validationErrs,
+		})
+		return
+	}
+	if errors.Is(err, services.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError)
+}
+
+func (h *CustodyAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/services"
+)
+
+// DashboardAPIHandler handles the home screen dashboard API request
+type DashboardAPIHandler struct {
+	dashboardService *services.DashboardService
+}
+
+// NewDashboardAPIHandler creates a new dashboard API handler
+func NewDashboardAPIHandler(dashboardService *services.DashboardService) *DashboardAPIHandler {
+	return &DashboardAPIHandler{
+		dashboardService: dashboardService,
+	}
+}
+
+// GetDashboard handles GET /api/v1/dashboard. Sections that fail to load are
+// marked with an error status while the rest are still returned.
+func (h *DashboardAPIHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	dashboard, err := h.dashboardService.BuildDashboard(session.FamilyID, time.Now())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to build dashboard: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(dashboard); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// DisplayAPIHandler handles display preference and bootstrap API requests
+type DisplayAPIHandler struct {
+	displayService *services.DisplayPreferencesService
+	projections    *services.DisplayProjectionService
+}
+
+// NewDisplayAPIHandler creates a new display API handler
+func NewDisplayAPIHandler(displayService *services.DisplayPreferencesService, projections *services.DisplayProjectionService) *DisplayAPIHandler {
+	return &DisplayAPIHandler{
+		displayService: displayService,
+		projections:    projections,
+	}
+}
+
+// GetBootstrap handles GET /api/v1/display/bootstrap?device_id=kitchen-tablet.
+// Without a device_id the caller's own preferences are used.
+func (h *DisplayAPIHandler) GetBootstrap(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	bootstrap, err := h.displayService.Bootstrap(session.FamilyID, session.UserID, r.URL.Query().Get("device_id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load display bootstrap: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, bootstrap)
+}
+
+// GetAgenda handles GET /api/v1/display/agenda, returning the family's
+// projected next 48 hours. The projection is built in the background, so this
+// stays fast while syncs and bulk writes hold the database; "stale" tells the
+// display a newer one is on its way.
+func (h *DisplayAPIHandler) GetAgenda(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	projection, err := h.projections.Get(session.FamilyID, time.Now())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load display agenda: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, projection)
+}
+
+// GetMyPreferences handles GET /api/v1/display/preferences, returning the
+// defaults when the caller hasn't saved anything yet
+func (h *DisplayAPIHandler) GetMyPreferences(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	prefs, err := h.displayService.GetMemberPreferences(session.FamilyID, session.UserID)
+	if err != nil {
+		if err.Error() != "display preferences not found" {
+			http.Error(w, fmt.Sprintf("Failed to get display preferences: %v", err), http.StatusInternalServerError)
+			return
+		}
+		prefs = models.DefaultDisplayPreferences(session.FamilyID)
+		prefs.MemberID = &session.UserID
+	}
+
+	h.writeJSON(w, http.StatusOK, prefs)
+}
+
+// SaveMyPreferences handles PUT /api/v1/display/preferences
+func (h *DisplayAPIHandler) SaveMyPreferences(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.SaveDisplayPreferencesRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	prefs, err := h.displayService.SaveMemberPreferences(session.FamilyID, session.UserID, session.UserID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to save display preferences", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, prefs)
+}
+
+// ListDevices handles GET /api/v1/display/devices
+func (h *DisplayAPIHandler) ListDevices(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	devices, err := h.displayService.ListDevicePreferences(session.FamilyID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list devices: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"devices": devices,
+		"count":   len(devices),
+	})
+}
+
+// SaveDevice handles PUT /api/v1/display/devices/{deviceID}
+func (h *DisplayAPIHandler) SaveDevice(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	deviceID, ok := h.deviceIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.SaveDisplayPreferencesRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	prefs, err := h.displayService.SaveDevicePreferences(session.FamilyID, deviceID, session.UserID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to save device preferences", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, prefs)
+}
+
+// DeleteDevice handles DELETE /api/v1/display/devices/{deviceID}
+func (h *DisplayAPIHandler) DeleteDevice(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	deviceID, ok := h.deviceIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.displayService.DeleteDevicePreferences(session.FamilyID, deviceID); err != nil {
+		h.writeServiceError(w, "Failed to delete device preferences", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *DisplayAPIHandler) deviceIDFromPath(w http.ResponseWriter, r *http.Request) (string, bool) {
+	deviceID := r.PathValue("deviceID")
+	return deviceID, true
+}
+
+func (h *DisplayAPIHandler) writeServiceError(w http.ResponseWriter, message string, err error) {
+	var validationErrs validation.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":   "validation_failed",
+			"details": validationErrs,
+		})
+		return
+	}
+	if err.Error() == "display preferences not found" {
+		http.Error(w, "Display preferences not found", http.StatusNotFound)
+		return
+	}
+	http.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError)
+}
+
+func (h *DisplayAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
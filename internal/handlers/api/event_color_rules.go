@@ -0,0 +1,210 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// maxRuleTestEvents limits how many sample events one test run may evaluate
+const maxRuleTestEvents = 100
+
+// EventColorRulesAPIHandler handles event auto-coloring rule API requests
+type EventColorRulesAPIHandler struct {
+	colorRulesService *services.EventColorRulesService
+}
+
+// NewEventColorRulesAPIHandler creates a new event color rules API handler
+func NewEventColorRulesAPIHandler(colorRulesService *services.EventColorRulesService) *EventColorRulesAPIHandler {
+	return &EventColorRulesAPIHandler{
+		colorRulesService: colorRulesService,
+	}
+}
+
+// ListRules handles GET /api/v1/calendar/rules
+func (h *EventColorRulesAPIHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	rules, err := h.colorRulesService.ListRules(session.FamilyID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list color rules: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"rules": rules,
+		"count": len(rules),
+	})
+}
+
+// CreateRule handles POST /api/v1/calendar/rules. New rules go last.
+func (h *EventColorRulesAPIHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CreateEventColorRuleRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	rule, err := h.colorRulesService.CreateRule(user.FamilyID, user.ID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to create color rule", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, rule)
+}
+
+// UpdateRule handles PATCH /api/v1/calendar/rules/{ruleID}
+func (h *EventColorRulesAPIHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	rule, ok := h.loadOwnedRule(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateEventColorRuleRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	updated, err := h.colorRulesService.UpdateRule(rule.ID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to update color rule", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteRule handles DELETE /api/v1/calendar/rules/{ruleID}
+func (h *EventColorRulesAPIHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	rule, ok := h.loadOwnedRule(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.colorRulesService.DeleteRule(rule.ID); err != nil {
+		h.writeServiceError(w, "Failed to delete color rule", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReorderRules handles PUT /api/v1/calendar/rules/order
+func (h *EventColorRulesAPIHandler) ReorderRules(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.ReorderEventColorRulesRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	rules, err := h.colorRulesService.ReorderRules(session.FamilyID, req.RuleIDs)
+	if err != nil {
+		h.writeServiceError(w, "Failed to reorder color rules", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"rules": rules,
+		"count": len(rules),
+	})
+}
+
+// TestRules handles POST /api/v1/calendar/rules/test, showing which rules
+// would match the sample events without changing anything
+func (h *EventColorRulesAPIHandler) TestRules(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.TestEventColorRulesRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if len(req.Events) == 0 || len(req.Events) > maxRuleTestEvents {
+		h.writeServiceError(w, "Failed to test color rules", validation.ValidationErrors{{
+			Field:   "events",
+			Message: fmt.Sprintf("between 1 and %d sample events are required", maxRuleTestEvents),
+		}})
+		return
+	}
+
+	results, err := h.colorRulesService.TestRules(session.FamilyID, req.Events)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to test color rules: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"results": results,
+	})
+}
+
+// loadOwnedRule fetches the rule named in the URL and checks it belongs to the caller's family
+func (h *EventColorRulesAPIHandler) loadOwnedRule(w http.ResponseWriter, r *http.Request) (*models.EventColorRule, bool) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	ruleID := r.PathValue("ruleID")
+
+	rule, err := h.colorRulesService.GetRule(ruleID)
+	if err != nil || rule.FamilyID != session.FamilyID {
+		if err != nil && err.Error() != "event color rule not found" {
+			http.Error(w, "Failed to query color rule", http.StatusInternalServerError)
+			return nil, false
+		}
+		http.Error(w, "Color rule not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return rule, true
+}
+
+func (h *EventColorRulesAPIHandler) writeServiceError(w http.ResponseWriter, message string, err error) {
+	var validationErrs validation.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":   "validation_failed",
+			"details": validationErrs,
+		})
+		return
+	}
+	if err.Error() == "event color rule not found" {
+		http.Error(w, "Color rule not found", http.StatusNotFound)
+		return
+	}
+	http.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError)
+}
+
+func (h *EventColorRulesAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -4,9 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"path"
 	"strings"
 
+	"famstack/internal/apierror"
+	"famstack/internal/auth"
 	"famstack/internal/models"
 	"famstack/internal/services"
 )
@@ -25,11 +26,6 @@ func NewFamilyAPIHandler(familiesService *services.FamiliesService) *FamilyAPIHa
 
 // CreateFamily creates a new family
 func (h *FamilyAPIHandler) CreateFamily(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Parse JSON data
 	var requestData struct {
 		Name string `json:"name"`
@@ -63,11 +59,6 @@ func (h *FamilyAPIHandler) CreateFamily(w http.ResponseWriter, r *http.Request)
 
 // ListFamilies lists all families
 func (h *FamilyAPIHandler) ListFamilies(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Use the service to list families
 	families, err := h.familiesService.ListFamilies()
 	if err != nil {
@@ -84,17 +75,7 @@ func (h *FamilyAPIHandler) ListFamilies(w http.ResponseWriter, r *http.Request)
 
 // GetFamily retrieves a specific family by ID
 func (h *FamilyAPIHandler) GetFamily(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// Extract family ID from URL path
-	familyID := path.Base(r.URL.Path)
-	if familyID == "" || familyID == "/" {
-		http.Error(w, "Family ID is required", http.StatusBadRequest)
-		return
-	}
+	familyID := r.PathValue("familyID")
 
 	// Use the service to get the family
 	family, err := h.familiesService.GetFamily(familyID)
@@ -116,22 +97,16 @@ func (h *FamilyAPIHandler) GetFamily(w http.ResponseWriter, r *http.Request) {
 
 // UpdateFamily updates a family's information
 func (h *FamilyAPIHandler) UpdateFamily(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "PUT" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// Extract family ID from URL path
-	familyID := path.Base(r.URL.Path)
-	if familyID == "" || familyID == "/" {
-		http.Error(w, "Family ID is required", http.StatusBadRequest)
-		return
-	}
+	familyID := r.PathValue("familyID")
 
 	// Parse request body
 	var req struct {
-		Name     string `json:"name"`
-		Timezone string `json:"timezone"`
+		Name          string   `json:"name"`
+		Timezone      string   `json:"timezone"`
+		LocationName  *string  `json:"location_name"`
+		Latitude      *float64 `json:"latitude"`
+		Longitude     *float64 `json:"longitude"`
+		ClearLocation bool     `json:"clear_location"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -139,7 +114,12 @@ func (h *FamilyAPIHandler) UpdateFamily(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Build update request with provided fields
-	updateReq := &models.UpdateFamilyRequest{}
+	updateReq := &models.UpdateFamilyRequest{
+		LocationName:  req.LocationName,
+		Latitude:      req.Latitude,
+		Longitude:     req.Longitude,
+		ClearLocation: req.ClearLocation,
+	}
 
 	if req.Name != "" {
 		updateReq.Name = &req.Name
@@ -150,14 +130,17 @@ func (h *FamilyAPIHandler) UpdateFamily(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Validate that at least one field is provided
-	if updateReq.Name == nil && updateReq.Timezone == nil {
-		http.Error(w, "At least one field (name or timezone) is required", http.StatusBadRequest)
+	if updateReq.Name == nil && updateReq.Timezone == nil && updateReq.LocationName == nil &&
+		updateReq.Latitude == nil && updateReq.Longitude == nil && !updateReq.ClearLocation {
+		http.Error(w, "At least one field (name, timezone or location) is required", http.StatusBadRequest)
 		return
 	}
 	family, err := h.familiesService.UpdateFamily(familyID, updateReq)
 	if err != nil {
 		if err.Error() == "family not found" {
 			http.Error(w, "Family not found", http.StatusNotFound)
+		} else if strings.HasPrefix(err.Error(), "invalid ") {
+			http.Error(w, err.Error(), http.StatusBadRequest)
 		} else {
 			http.Error(w, fmt.Sprintf("Failed to update family: %v", err), http.StatusInternalServerError)
 		}
@@ -167,6 +150,71 @@ func (h *FamilyAPIHandler) UpdateFamily(w http.ResponseWriter, r *http.Request)
 	h.writeJSON(w, family)
 }
 
+// GetSettings handles GET /api/v1/families/{familyID}/settings
+func (h *FamilyAPIHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	familyID, ok := h.ownFamilyID(w, r)
+	if !ok {
+		return
+	}
+
+	settings, err := h.familiesService.GetSettings(familyID)
+	if err != nil {
+		if err.Error() == "family not found" {
+			apierror.Error(w, "Family not found", http.StatusNotFound)
+			return
+		}
+		apierror.Error(w, fmt.Sprintf("Failed to get family settings: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, settings)
+}
+
+// UpdateSettings handles PATCH /api/v1/families/{familyID}/settings
+func (h *FamilyAPIHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	familyID, ok := h.ownFamilyID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateFamilySettingsRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	settings, err := h.familiesService.UpdateSettings(familyID, &req)
+	if err != nil {
+		if apierror.WriteValidation(w, err) {
+			return
+		}
+		if err.Error() == "family not found" {
+			apierror.Error(w, "Family not found", http.StatusNotFound)
+			return
+		}
+		apierror.Error(w, fmt.Sprintf("Failed to update family settings: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, settings)
+}
+
+// ownFamilyID returns the family in a /api/v1/families/{familyID}/... path when it
+// is the caller's own
+func (h *FamilyAPIHandler) ownFamilyID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return "", false
+	}
+
+	familyID := r.PathValue("familyID")
+	if familyID != session.FamilyID {
+		apierror.Error(w, "Only your own family's settings can be used", http.StatusForbidden)
+		return "", false
+	}
+	return familyID, true
+}
+
 func (h *FamilyAPIHandler) writeJSON(w http.ResponseWriter, data any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"famstack/internal/apierror"
 	"famstack/internal/auth"
 	"famstack/internal/models"
 	"famstack/internal/services"
@@ -13,25 +15,23 @@ import (
 
 // FamilyMemberAPIHandler handles HTTP requests for family member management
 type FamilyMemberAPIHandler struct {
-	service *services.FamilyMemberService
+	service           *services.FamilyMemberService
+	activitiesService *services.ActivitiesService
+	calendarService   *services.CalendarService
 }
 
 // NewFamilyMemberAPIHandler creates a new family member API handler
-func NewFamilyMemberAPIHandler(service *services.FamilyMemberService) *FamilyMemberAPIHandler {
+func NewFamilyMemberAPIHandler(service *services.FamilyMemberService, activitiesService *services.ActivitiesService, calendarService *services.CalendarService) *FamilyMemberAPIHandler {
 	return &FamilyMemberAPIHandler{
-		service: service,
+		service:           service,
+		activitiesService: activitiesService,
+		calendarService:   calendarService,
 	}
 }
 
-// ListFamilyMembers handles GET /api/families/{family_id}/members
+// ListFamilyMembers handles GET /api/v1/families/{familyID}/members, and
+// GET /api/v1/families/members for the session's own family
 func (h *FamilyMemberAPIHandler) ListFamilyMembers(w http.ResponseWriter, r *http.Request) {
-	// Extract family ID from URL path
-	familyID := h.extractFamilyIDFromPath(r.URL.Path)
-	if familyID == "" {
-		http.Error(w, "Family ID is required", http.StatusBadRequest)
-		return
-	}
-
 	// Verify user has access to this family
 	session := auth.GetSessionFromContext(r.Context())
 	if session == nil {
@@ -39,6 +39,11 @@ func (h *FamilyMemberAPIHandler) ListFamilyMembers(w http.ResponseWriter, r *htt
 		return
 	}
 
+	familyID := r.PathValue("familyID")
+	if familyID == "" {
+		familyID = session.FamilyID
+	}
+
 	// For now, ensure user can only access their own family
 	if session.FamilyID != familyID {
 		http.Error(w, "Access denied to this family", http.StatusForbidden)
@@ -57,14 +62,9 @@ func (h *FamilyMemberAPIHandler) ListFamilyMembers(w http.ResponseWriter, r *htt
 	})
 }
 
-// GetFamilyMember handles GET /api/v1/families/members/{member_id}
+// GetFamilyMember handles GET /api/v1/families/{familyID}/members/{memberID}
 func (h *FamilyMemberAPIHandler) GetFamilyMember(w http.ResponseWriter, r *http.Request) {
-	// Extract member ID from URL path
-	memberID := h.extractIDFromPath(r.URL.Path, "/api/v1/families/members/")
-	if memberID == "" {
-		http.Error(w, "Invalid member ID", http.StatusBadRequest)
-		return
-	}
+	memberID := r.PathValue("memberID")
 
 	// Get family member
 	member, err := h.service.GetFamilyMember(memberID)
@@ -84,12 +84,21 @@ func (h *FamilyMemberAPIHandler) GetFamilyMember(w http.ResponseWriter, r *http.
 		return
 	}
 
+	// Include the member's activity registry in their profile
+	activities, err := h.activitiesService.ListActivities(member.FamilyID, member.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get activities: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	h.writeJSON(w, map[string]interface{}{
 		"family_member": member,
+		"activities":    activities,
 	})
 }
 
-// CreateFamilyMember handles POST /api/v1/families/members
+// CreateFamilyMember handles POST /api/v1/families/{familyID}/members and
+// POST /api/v1/families/members; the member joins the session's family
 func (h *FamilyMemberAPIHandler) CreateFamilyMember(w http.ResponseWriter, r *http.Request) {
 	// Extract family ID from session context
 	session := auth.GetSessionFromContext(r.Context())
@@ -100,17 +109,21 @@ func (h *FamilyMemberAPIHandler) CreateFamilyMember(w http.ResponseWriter, r *ht
 
 	// Parse request body
 	var req models.CreateFamilyMemberRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
 	// Create family member
 	member, err := h.service.CreateFamilyMember(session.FamilyID, &req)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create family member: %v", err), http.StatusInternalServerError)
+		if !apierror.WriteValidation(w, err) {
+			http.Error(w, fmt.Sprintf("Failed to create family member: %v", err), http.StatusInternalServerError)
+		}
 		return
 	}
+	if req.Birthdate != nil || req.Anniversary != nil {
+		h.syncCelebrations(member.FamilyID)
+	}
 
 	h.writeJSON(w, map[string]interface{}{
 		"family_member": member,
@@ -118,14 +131,9 @@ func (h *FamilyMemberAPIHandler) CreateFamilyMember(w http.ResponseWriter, r *ht
 	})
 }
 
-// UpdateFamilyMember handles PATCH /api/v1/families/members/{member_id}
+// UpdateFamilyMember handles PATCH /api/v1/families/{familyID}/members/{memberID}
 func (h *FamilyMemberAPIHandler) UpdateFamilyMember(w http.ResponseWriter, r *http.Request) {
-	// Extract member ID from URL path
-	memberID := h.extractIDFromPath(r.URL.Path, "/api/v1/families/members/")
-	if memberID == "" {
-		http.Error(w, "Invalid member ID", http.StatusBadRequest)
-		return
-	}
+	memberID := r.PathValue("memberID")
 
 	// Verify family access
 	member, err := h.service.GetFamilyMember(memberID)
@@ -146,17 +154,22 @@ func (h *FamilyMemberAPIHandler) UpdateFamilyMember(w http.ResponseWriter, r *ht
 
 	// Parse request body
 	var req models.UpdateFamilyMemberRequest
-	if decodeErr := json.NewDecoder(r.Body).Decode(&req); decodeErr != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
 	// Update family member
 	updatedMember, err := h.service.UpdateFamilyMember(memberID, &req)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to update family member: %v", err), http.StatusInternalServerError)
+		if !apierror.WriteValidation(w, err) {
+			http.Error(w, fmt.Sprintf("Failed to update family member: %v", err), http.StatusInternalServerError)
+		}
 		return
 	}
+	// Celebrations are titled after members and follow their dates
+	if req.Birthdate != nil || req.Anniversary != nil || req.FirstName != nil || req.IsActive != nil {
+		h.syncCelebrations(member.FamilyID)
+	}
 
 	h.writeJSON(w, map[string]interface{}{
 		"family_member": updatedMember,
@@ -164,14 +177,9 @@ func (h *FamilyMemberAPIHandler) UpdateFamilyMember(w http.ResponseWriter, r *ht
 	})
 }
 
-// DeleteFamilyMember handles DELETE /api/v1/families/members/{member_id}
+// DeleteFamilyMember handles DELETE /api/v1/families/{familyID}/members/{memberID}
 func (h *FamilyMemberAPIHandler) DeleteFamilyMember(w http.ResponseWriter, r *http.Request) {
-	// Extract member ID from URL path
-	memberID := h.extractIDFromPath(r.URL.Path, "/api/v1/families/members/")
-	if memberID == "" {
-		http.Error(w, "Invalid member ID", http.StatusBadRequest)
-		return
-	}
+	memberID := r.PathValue("memberID")
 
 	// Verify family access
 	member, err := h.service.GetFamilyMember(memberID)
@@ -195,6 +203,7 @@ func (h *FamilyMemberAPIHandler) DeleteFamilyMember(w http.ResponseWriter, r *ht
 		http.Error(w, fmt.Sprintf("Failed to delete family member: %v", err), http.StatusInternalServerError)
 		return
 	}
+	h.syncCelebrations(member.FamilyID)
 
 	h.writeJSON(w, map[string]interface{}{
 		"message": "Family member deleted successfully",
@@ -228,14 +237,9 @@ func (h *FamilyMemberAPIHandler) GetFamilyMembersWithStats(w http.ResponseWriter
 	})
 }
 
-// LinkUserToMember handles POST /api/v1/families/members/{member_id}/link-user
+// LinkUserToMember handles POST /api/v1/families/{familyID}/members/{memberID}/link-user
 func (h *FamilyMemberAPIHandler) LinkUserToMember(w http.ResponseWriter, r *http.Request) {
-	// Extract member ID from URL path
-	memberID := h.extractIDFromPath(r.URL.Path, "/api/v1/families/members/")
-	if memberID == "" {
-		http.Error(w, "Invalid member ID", http.StatusBadRequest)
-		return
-	}
+	memberID := r.PathValue("memberID")
 
 	// Parse request body
 	var req struct {
@@ -270,14 +274,9 @@ func (h *FamilyMemberAPIHandler) LinkUserToMember(w http.ResponseWriter, r *http
 	})
 }
 
-// UnlinkUserFromMember handles POST /api/v1/families/members/{member_id}/unlink-user
+// UnlinkUserFromMember handles POST /api/v1/families/{familyID}/members/{memberID}/unlink-user
 func (h *FamilyMemberAPIHandler) UnlinkUserFromMember(w http.ResponseWriter, r *http.Request) {
-	// Extract member ID from URL path
-	memberID := h.extractIDFromPath(r.URL.Path, "/api/v1/families/members/")
-	if memberID == "" {
-		http.Error(w, "Invalid member ID", http.StatusBadRequest)
-		return
-	}
+	memberID := r.PathValue("memberID")
 
 	// Verify family access
 	member, err := h.service.GetFamilyMember(memberID)
@@ -303,54 +302,82 @@ func (h *FamilyMemberAPIHandler) UnlinkUserFromMember(w http.ResponseWriter, r *
 	})
 }
 
-// Helper methods
+// ListCelebrations handles GET /api/v1/families/{familyID}/members/{memberID}/celebrations
+func (h *FamilyMemberAPIHandler) ListCelebrations(w http.ResponseWriter, r *http.Request) {
+	memberID := r.PathValue("memberID")
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
 
-func (h *FamilyMemberAPIHandler) extractIDFromPath(path, prefix string) string {
-	// Handle both v1 and non-v1 paths for member ID extraction
-	var actualPrefix string
-	if strings.Contains(path, "/api/v1/families/") && !strings.HasPrefix(prefix, "/api/v1/") {
-		// Convert non-v1 prefix to v1 prefix for compatibility
-		actualPrefix = strings.Replace(prefix, "/api/families/", "/api/v1/families/", 1)
-	} else {
-		actualPrefix = prefix
+	celebrations, err := h.calendarService.ListCelebrations(session.FamilyID, memberID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			http.Error(w, "Family member not found", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Failed to list celebrations: %v", err), http.StatusInternalServerError)
+		}
+		return
 	}
 
-	if !strings.HasPrefix(path, actualPrefix) {
-		return ""
+	h.writeJSON(w, map[string]interface{}{
+		"celebrations": celebrations,
+	})
+}
+
+// SetCelebrationSkipped handles PUT
+// /api/v1/families/{familyID}/members/{memberID}/celebrations/{kind}/{year}, which
+// skips that year's birthday or anniversary with {"skipped": true} and
+// brings it back with {"skipped": false}
+func (h *FamilyMemberAPIHandler) SetCelebrationSkipped(w http.ResponseWriter, r *http.Request) {
+	memberID := r.PathValue("memberID")
+	kind := r.PathValue("kind")
+	year, err := strconv.Atoi(r.PathValue("year"))
+	if err != nil {
+		http.Error(w, "Invalid year", http.StatusBadRequest)
+		return
 	}
 
-	id := strings.TrimPrefix(path, actualPrefix)
-	// Remove any trailing slashes or path segments
-	if slashIndex := strings.Index(id, "/"); slashIndex != -1 {
-		id = id[:slashIndex]
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
 	}
 
-	return id
-}
+	var req struct {
+		Skipped bool `json:"skipped"`
+	}
+	if !decodeRequest(w, r, &req) {
+		return
+	}
 
-func (h *FamilyMemberAPIHandler) extractFamilyIDFromPath(path string) string {
-	// Handle both formats:
-	// /api/families/{family_id}/members
-	// /api/v1/families/{family_id}/members
-	var prefix string
-	if strings.HasPrefix(path, "/api/v1/families/") {
-		prefix = "/api/v1/families/"
-	} else if strings.HasPrefix(path, "/api/families/") {
-		prefix = "/api/families/"
-	} else {
-		return ""
+	celebration, err := h.calendarService.SetCelebrationSkipped(session.FamilyID, memberID, kind, year, req.Skipped)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case strings.HasPrefix(err.Error(), "invalid ") || strings.HasPrefix(err.Error(), "member has no "):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, fmt.Sprintf("Failed to update celebration: %v", err), http.StatusInternalServerError)
+		}
+		return
 	}
 
-	// Remove the prefix
-	remaining := strings.TrimPrefix(path, prefix)
+	h.writeJSON(w, map[string]interface{}{
+		"celebration": celebration,
+	})
+}
+
+// Helper methods
 
-	// Extract family ID (everything before the next slash)
-	if slashIndex := strings.Index(remaining, "/"); slashIndex != -1 {
-		return remaining[:slashIndex]
+// syncCelebrations brings the family's birthdays and anniversaries in step
+// after a member edit. A failure is logged; the daily sync catches up.
+func (h *FamilyMemberAPIHandler) syncCelebrations(familyID string) {
+	if _, err := h.calendarService.SyncCelebrations(familyID); err != nil {
+		fmt.Printf("❌ Celebration sync error: %v\n", err)
 	}
-
-	// If no slash found, the entire remaining string is the family ID
-	return remaining
 }
 
 func (h *FamilyMemberAPIHandler) writeJSON(w http.ResponseWriter, data interface{}) {
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"famstack/internal/apierror"
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+)
+
+// FeaturesAPIHandler handles feature flags and gates the routes of the
+// modules behind them
+type FeaturesAPIHandler struct {
+	featuresService *services.FeatureFlagsService
+}
+
+// NewFeaturesAPIHandler creates a new features API handler
+func NewFeaturesAPIHandler(featuresService *services.FeatureFlagsService) *FeaturesAPIHandler {
+	return &FeaturesAPIHandler{featuresService: featuresService}
+}
+
+// ListFeatures handles GET /api/v1/features, which the app reads at boot:
+// "features" maps each flag to whether it is on for the family, and "flags"
+// says why
+func (h *FeaturesAPIHandler) ListFeatures(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	flags, err := h.featuresService.Flags(user.FamilyID)
+	if err != nil {
+		apierror.Error(w, fmt.Sprintf("Failed to get features: %v", err), http.StatusInternalServerError)
+		return
+	}
+	h.writeFlags(w, flags)
+}
+
+// SetFeature handles PUT /api/v1/features/{key} with {"enabled": true},
+// false, or null to clear the override. The override is the family's unless
+// "scope" is "global", which only admins who can change the server
+// configuration may set.
+func (h *FeaturesAPIHandler) SetFeature(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	key := r.PathValue("key")
+	if _, ok := models.LookupFeatureFlag(key); !ok {
+		apierror.Error(w, "Feature not found", http.StatusNotFound)
+		return
+	}
+
+	var req models.SetFeatureFlagRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	familyID := user.FamilyID
+	if req.Scope == models.FeatureScopeGlobal {
+		session := auth.GetSessionFromContext(r.Context())
+		if !auth.NewAuthorizationService(session).HasPermission(auth.EntityUser, auth.ActionUpdate, nil) {
+			apierror.Error(w, "Only administrators can change a feature for every family", http.StatusForbidden)
+			return
+		}
+		familyID = ""
+	}
+
+	if err := h.featuresService.SetOverride(familyID, key, req.Enabled, user.ID); err != nil {
+		if apierror.WriteValidation(w, err) {
+			return
+		}
+		apierror.Error(w, fmt.Sprintf("Failed to set feature: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	flags, err := h.featuresService.Flags(user.FamilyID)
+	if err != nil {
+		apierror.Error(w, fmt.Sprintf("Failed to get features: %v", err), http.StatusInternalServerError)
+		return
+	}
+	h.writeFlags(w, flags)
+}
+
+// Require lets requests through to next only while the feature is on for
+// the signed-in member's family; it goes inside the auth middleware
+func (h *FeaturesAPIHandler) Require(key string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := auth.GetUserFromContext(r.Context())
+			if user == nil {
+				apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+				return
+			}
+			enabled, err := h.featuresService.Enabled(user.FamilyID, key)
+			if err != nil {
+				apierror.Error(w, fmt.Sprintf("Failed to check feature: %v", err), http.StatusInternalServerError)
+				return
+			}
+			if !enabled {
+				apierror.Error(w, fmt.Sprintf("The %s feature is turned off", key), http.StatusNotFound)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (h *FeaturesAPIHandler) writeFlags(w http.ResponseWriter, flags []models.FeatureFlagState) {
+	features := make(map[string]bool, len(flags))
+	for _, flag := range flags {
+		features[flag.Key] = flag.Enabled
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"features": features,
+		"flags":    flags,
+	}); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// focusKeepAlive is how often an idle focus stream sends a comment, so
+// proxies don't close it
+const focusKeepAlive = 30 * time.Second
+
+// FocusAPIHandler handles family focus session API requests
+type FocusAPIHandler struct {
+	focusService *services.FocusService
+}
+
+// NewFocusAPIHandler creates a new focus API handler
+func NewFocusAPIHandler(focusService *services.FocusService) *FocusAPIHandler {
+	return &FocusAPIHandler{
+		focusService: focusService,
+	}
+}
+
+// GetStatus handles GET /api/v1/focus
+func (h *FocusAPIHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	now := time.Now()
+	active, err := h.focusService.GetActiveSession(session.FamilyID, now)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get focus status: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, models.NewFocusStatus(active, now))
+}
+
+// StartSession handles POST /api/v1/focus
+func (h *FocusAPIHandler) StartSession(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.StartFocusSessionRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	started, err := h.focusService.StartSession(user.FamilyID, user.ID, &req, time.Now())
+	if err != nil {
+		h.writeServiceError(w, "Failed to start focus session", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, started)
+}
+
+// EndSession handles DELETE /api/v1/focus, ending the running session early
+func (h *FocusAPIHandler) EndSession(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	ended, err := h.focusService.EndActiveSession(session.FamilyID, time.Now())
+	if err != nil {
+		h.writeServiceError(w, "Failed to end focus session", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, ended)
+}
+
+// ListSessions handles GET /api/v1/focus/sessions?limit=20, the family's focus log
+func (h *FocusAPIHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	limit := 20
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed < 1 || parsed > 100 {
+			http.Error(w, "limit must be between 1 and 100", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	sessions, err := h.focusService.ListSessions(session.FamilyID, limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list focus sessions: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"sessions": sessions,
+		"count":    len(sessions),
+	})
+}
+
+// Stream handles GET /api/v1/focus/stream, a server-sent event stream of the
+// family's focus status. Every device gets a "focus" event when a session
+// starts or ends, and one a second while it counts down.
+func (h *FocusAPIHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	familyID := session.FamilyID
+
+	// Subscribe before the first read so a change in between isn't missed
+	changed := make(chan struct{}, 1)
+	unsubscribe := h.focusService.Subscribe(func(changedFamilyID string) {
+		if changedFamilyID != familyID {
+			return
+		}
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	defer unsubscribe()
+
+	current, err := h.focusService.GetActiveSession(familyID, time.Now())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get focus status: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// The server's write timeout is meant for ordinary requests, not streams
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if err := writeFocusEvent(w, rc, models.NewFocusStatus(current, time.Now())); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	lastWrite := time.Now()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+			now := time.Now()
+			current, err = h.focusService.GetActiveSession(familyID, now)
+			if err != nil {
+				// Keep the stream; the next change or reconnect will catch up
+				log.Printf("Failed to reload focus status for family %s: %v", familyID, err)
+				continue
+			}
+			err = writeFocusEvent(w, rc, models.NewFocusStatus(current, now))
+			lastWrite = now
+		case now := <-ticker.C:
+			switch {
+			case current != nil:
+				status := models.NewFocusStatus(current, now)
+				if !status.Active {
+					// The session ran out; this is its final event
+					current = nil
+				}
+				err = writeFocusEvent(w, rc, status)
+				lastWrite = now
+			case now.Sub(lastWrite) >= focusKeepAlive:
+				_, err = fmt.Fprint(w, ": keep-alive\n\n")
+				if err == nil {
+					err = rc.Flush()
+				}
+				lastWrite = now
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// writeFocusEvent sends one "focus" server-sent event and flushes it
+func writeFocusEvent(w http.ResponseWriter, rc *http.ResponseController, status models.FocusStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: focus\ndata: %s\n\n", data); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
+
+func (h *FocusAPIHandler) writeServiceError(w http.ResponseWriter, message string, err error) {
+	var validationErrs validation.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":   "validation_failed",
+			"details": validationErrs,
+		})
+		return
+	}
+	switch err.Error() {
+	case "focus session already active":
+		http.Error(w, "A focus session is already running", http.StatusConflict)
+		return
+	case "no active focus session":
+		http.Error(w, "No focus session is running", http.StatusNotFound)
+		return
+	}
+	http.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError)
+}
+
+func (h *FocusAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFocusEvent(t *testing.T) {
+	start := time.Date(2025, 10, 1, 16, 0, 0, 0, time.UTC)
+	session := &models.FocusSession{ID: "focus_1", Title: "Homework", StartedAt: start, EndsAt: start.Add(time.Hour)}
+
+	recorder := httptest.NewRecorder()
+	err := writeFocusEvent(recorder, http.NewResponseController(recorder), models.NewFocusStatus(session, start.Add(59*time.Minute+30*time.Second)))
+	require.NoError(t, err)
+	assert.True(t, recorder.Flushed)
+	assert.Regexp(t, `^event: focus\ndata: \{"active":true,.*"remaining_seconds":30\}\n\n$`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	err = writeFocusEvent(recorder, http.NewResponseController(recorder), models.NewFocusStatus(session, start.Add(time.Hour)))
+	require.NoError(t, err)
+	assert.Equal(t, "event: focus\ndata: {\"active\":false,\"remaining_seconds\":0}\n\n", recorder.Body.String())
+}
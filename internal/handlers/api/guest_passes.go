@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"famstack/internal/apierror"
+	"famstack/internal/auth"
+)
+
+// GuestPassesAPIHandler handles the API parents give babysitters and
+// grandparents time-boxed access with
+type GuestPassesAPIHandler struct {
+	authService *auth.Service
+}
+
+// NewGuestPassesAPIHandler creates a new guest passes API handler
+func NewGuestPassesAPIHandler(authService *auth.Service) *GuestPassesAPIHandler {
+	return &GuestPassesAPIHandler{
+		authService: authService,
+	}
+}
+
+// ListPasses handles GET /api/v1/guest-passes, the passes that still work
+func (h *GuestPassesAPIHandler) ListPasses(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	passes, err := h.authService.ListGuestPasses(session.FamilyID)
+	if err != nil {
+		apierror.Error(w, fmt.Sprintf("Failed to list guest passes: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if passes == nil {
+		passes = []auth.GuestPass{}
+	}
+	h.writeJSON(w, http.StatusOK, passes)
+}
+
+// CreatePass handles POST /api/v1/guest-passes, returning the link for the
+// guest once
+func (h *GuestPassesAPIHandler) CreatePass(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req auth.CreateGuestPassRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	pass, err := h.authService.CreateGuestPass(session.FamilyID, session.UserID, &req)
+	if err != nil {
+		if apierror.WriteValidation(w, err) {
+			return
+		}
+		apierror.Error(w, fmt.Sprintf("Failed to create guest pass: %v", err), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, pass)
+}
+
+// RevokePass handles DELETE /api/v1/guest-passes/{passID}
+func (h *GuestPassesAPIHandler) RevokePass(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.authService.RevokeGuestPass(session.FamilyID, r.PathValue("passID")); err != nil {
+		if err.Error() == "guest pass not found" {
+			apierror.Error(w, "Guest pass not found", http.StatusNotFound)
+			return
+		}
+		apierror.Error(w, fmt.Sprintf("Failed to revoke guest pass: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ExtendPass handles POST /api/v1/guest-passes/{passID}/extend, adding
+// hours to the pass
+func (h *GuestPassesAPIHandler) ExtendPass(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req auth.ExtendGuestPassRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	pass, err := h.authService.ExtendGuestPass(session.FamilyID, r.PathValue("passID"), req.Hours)
+	if err != nil {
+		if apierror.WriteValidation(w, err) {
+			return
+		}
+		if err.Error() == "guest pass not found" {
+			apierror.Error(w, "Guest pass not found", http.StatusNotFound)
+			return
+		}
+		apierror.Error(w, fmt.Sprintf("Failed to extend guest pass: %v", err), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, pass)
+}
+
+func (h *GuestPassesAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
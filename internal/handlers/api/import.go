@@ -0,0 +1,97 @@
+package api
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// ImportAPIHandler handles family archive import requests
+type ImportAPIHandler struct {
+	importService *services.ImportService
+}
+
+// NewImportAPIHandler creates a new import API handler
+func NewImportAPIHandler(importService *services.ImportService) *ImportAPIHandler {
+	return &ImportAPIHandler{
+		importService: importService,
+	}
+}
+
+// ImportArchive handles POST /api/v1/families/{familyID}/import. The body is a
+// family archive as JSON, gzipped when sent as application/gzip. With
+// ?dry_run=true the archive is checked and the report returned without
+// writing anything.
+func (h *ImportAPIHandler) ImportArchive(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	familyID := r.PathValue("familyID")
+	if familyID != session.FamilyID {
+		http.Error(w, "Archives can only be imported into your own family", http.StatusForbidden)
+		return
+	}
+
+	var body io.Reader = http.MaxBytesReader(w, r.Body, models.MaxImportArchiveBytes)
+	if r.Header.Get("Content-Type") == "application/gzip" {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			http.Error(w, "Invalid gzip archive", http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		body = io.LimitReader(gz, models.MaxImportArchiveBytes)
+	}
+
+	var archive models.FamilyArchive
+	if err := json.NewDecoder(body).Decode(&archive); err != nil {
+		http.Error(w, "Invalid archive", http.StatusBadRequest)
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	report, err := h.importService.ImportArchive(familyID, session.UserID, &archive, dryRun)
+	if err != nil {
+		h.writeServiceError(w, "Failed to import archive", err)
+		return
+	}
+
+	status := http.StatusOK
+	if report.Invalid > 0 {
+		status = http.StatusUnprocessableEntity
+	} else if report.Created > 0 {
+		status = http.StatusCreated
+	}
+	h.writeJSON(w, status, report)
+}
+
+func (h *ImportAPIHandler) writeServiceError(w http.ResponseWriter, message string, err error) {
+	var validationErrs validation.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":   "validation_failed",
+			"details": validationErrs,
+		})
+		return
+	}
+	http.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError)
+}
+
+func (h *ImportAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
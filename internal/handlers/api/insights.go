@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// InsightsAPIHandler handles calendar insight API requests
+type InsightsAPIHandler struct {
+	insightsService *services.InsightsService
+}
+
+// NewInsightsAPIHandler creates a new insights API handler
+func NewInsightsAPIHandler(insightsService *services.InsightsService) *InsightsAPIHandler {
+	return &InsightsAPIHandler{
+		insightsService: insightsService,
+	}
+}
+
+// GetAvailability handles GET /api/v1/insights/availability. It returns each
+// member's busy-hours heatmap and the recurring slots most often free.
+// Optional parameters: weeks of history, member_ids (comma separated),
+// duration in hours, from_hour and to_hour bounding the slots, and limit.
+func (h *InsightsAPIHandler) GetAvailability(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	query := models.AvailabilityQuery{
+		Weeks:         models.DefaultAvailabilityWeeks,
+		DurationHours: 1,
+		FromHour:      models.DefaultSlotFromHour,
+		ToHour:        models.DefaultSlotToHour,
+		Limit:         models.DefaultSlotSuggestions,
+	}
+	params := r.URL.Query()
+	for name, target := range map[string]*int{
+		"weeks":     &query.Weeks,
+		"duration":  &query.DurationHours,
+		"from_hour": &query.FromHour,
+		"to_hour":   &query.ToHour,
+		"limit":     &query.Limit,
+	} {
+		if value := params.Get(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("%s must be a whole number", name), http.StatusBadRequest)
+				return
+			}
+			*target = parsed
+		}
+	}
+	for _, memberID := range strings.Split(params.Get("member_ids"), ",") {
+		if memberID = strings.TrimSpace(memberID); memberID != "" {
+			query.MemberIDs = append(query.MemberIDs, memberID)
+		}
+	}
+
+	insights, err := h.insightsService.GetAvailability(session.FamilyID, query)
+	if err != nil {
+		var validationErrs validation.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			h.writeJSON(w, http.StatusBadRequest, map[string]any{
+				"error":   "validation_failed",
+				"details": validationErrs,
+			})
+			return
+		}
+		http.Error(w, fmt.Sprintf("Failed to get availability insights: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, insights)
+}
+
+func (h *InsightsAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -5,15 +5,20 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
-	"strings"
+	"time"
 
+	"famstack/internal/apierror"
 	"famstack/internal/auth"
+	"famstack/internal/jobs"
+	"famstack/internal/jobsystem"
+	"famstack/internal/middleware"
 	"famstack/internal/services"
 )
 
 // IntegrationsAPIHandler handles integration API requests
 type IntegrationsAPIHandler struct {
 	integrationsService *services.IntegrationsService
+	jobSystem           *jobsystem.DBJobSystem
 }
 
 // NewIntegrationsAPIHandler creates a new integrations API handler
@@ -23,17 +28,21 @@ func NewIntegrationsAPIHandler(integrationsService *services.IntegrationsService
 	}
 }
 
-// ListIntegrations handles GET /api/v1/integrations
-func (h *IntegrationsAPIHandler) ListIntegrations(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+// NewIntegrationsAPIHandlerWithJobSystem creates an integrations API handler
+// that can queue syncs
+func NewIntegrationsAPIHandlerWithJobSystem(integrationsService *services.IntegrationsService, jobSystem *jobsystem.DBJobSystem) *IntegrationsAPIHandler {
+	return &IntegrationsAPIHandler{
+		integrationsService: integrationsService,
+		jobSystem:           jobSystem,
 	}
+}
 
+// ListIntegrations handles GET /api/v1/integrations
+func (h *IntegrationsAPIHandler) ListIntegrations(w http.ResponseWriter, r *http.Request) {
 	// Get user from context
 	user := auth.GetUserFromContext(r.Context())
 	if user == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
@@ -64,22 +73,44 @@ func (h *IntegrationsAPIHandler) ListIntegrations(w http.ResponseWriter, r *http
 		query.CreatedBy = &createdBy
 	}
 
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if limit, err := strconv.Atoi(limitStr); err == nil {
-			query.Limit = limit
+	// ?offset= keeps the older offset paging; otherwise ?limit= and ?cursor=
+	// page by cursor
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr == "" {
+		page, paged, ok := pageRequest(w, r)
+		if !ok {
+			return
+		}
+		if paged {
+			integrationsPage, err := h.integrationsService.ListIntegrationsPage(user.FamilyID, query, page)
+			if err != nil {
+				writePageError(w, "integrations", err)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(map[string]any{
+				"integrations": integrationsPage.Items,
+				"count":        len(integrationsPage.Items),
+				"next_cursor":  integrationsPage.NextCursor,
+				"total":        integrationsPage.Total,
+			}); err != nil {
+				apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			}
+			return
 		}
+	} else if offset, err := strconv.Atoi(offsetStr); err == nil {
+		query.Offset = offset
 	}
 
-	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
-		if offset, err := strconv.Atoi(offsetStr); err == nil {
-			query.Offset = offset
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil {
+			query.Limit = limit
 		}
 	}
 
 	// Get integrations
 	integrationsList, err := h.integrationsService.ListIntegrations(user.FamilyID, query)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to list integrations: %v", err), http.StatusInternalServerError)
+		apierror.Error(w, fmt.Sprintf("Failed to list integrations: %v", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -88,66 +119,82 @@ func (h *IntegrationsAPIHandler) ListIntegrations(w http.ResponseWriter, r *http
 		"integrations": integrationsList,
 		"count":        len(integrationsList),
 	}); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
 }
 
-// CreateIntegration handles POST /api/v1/integrations
-func (h *IntegrationsAPIHandler) CreateIntegration(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// ListSyncHistory handles GET /api/v1/integrations/{integrationID}/sync-history, a
+// page at a time with ?limit= and ?cursor=
+func (h *IntegrationsAPIHandler) ListSyncHistory(w http.ResponseWriter, r *http.Request) {
+	integrationID := r.PathValue("integrationID")
+
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	integration, err := h.integrationsService.GetIntegration(integrationID)
+	if err != nil || integration.FamilyID != user.FamilyID {
+		apierror.Error(w, "Integration not found", http.StatusNotFound)
 		return
 	}
 
+	page, _, ok := pageRequest(w, r)
+	if !ok {
+		return
+	}
+	history, err := h.integrationsService.ListSyncHistory(integrationID, page)
+	if err != nil {
+		writePageError(w, "sync history", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(history); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// CreateIntegration handles POST /api/v1/integrations
+func (h *IntegrationsAPIHandler) CreateIntegration(w http.ResponseWriter, r *http.Request) {
 	// Get user from context
 	user := auth.GetUserFromContext(r.Context())
 	if user == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
 	// Parse request body
 	var req services.CreateIntegrationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
 	// Create integration
 	integration, err := h.integrationsService.CreateIntegration(user.FamilyID, user.ID, &req)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create integration: %v", err), http.StatusInternalServerError)
+		apierror.Error(w, fmt.Sprintf("Failed to create integration: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(integration); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
 }
 
-// GetIntegration handles GET /api/v1/integrations/{id}
+// GetIntegration handles GET /api/v1/integrations/{integrationID}
 func (h *IntegrationsAPIHandler) GetIntegration(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// Extract integration ID from URL
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 5 {
-		http.Error(w, "Invalid integration ID", http.StatusBadRequest)
-		return
-	}
-	integrationID := pathParts[4]
+	integrationID := r.PathValue("integrationID")
 
 	// Get user from context
 	user := auth.GetUserFromContext(r.Context())
 	if user == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
@@ -158,133 +205,110 @@ func (h *IntegrationsAPIHandler) GetIntegration(w http.ResponseWriter, r *http.R
 		// Get integration with credentials
 		integrationWithCreds, err := h.integrationsService.GetIntegrationWithCredentials(integrationID)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to get integration: %v", err), http.StatusInternalServerError)
+			apierror.Error(w, fmt.Sprintf("Failed to get integration: %v", err), http.StatusInternalServerError)
 			return
 		}
 
 		// Verify user has access to this integration
 		if integrationWithCreds.Integration.FamilyID != user.FamilyID {
-			http.Error(w, "Access denied", http.StatusForbidden)
+			apierror.Error(w, "Access denied", http.StatusForbidden)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(integrationWithCreds); err != nil {
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
 			return
 		}
 	} else {
 		// Get basic integration info
 		integration, err := h.integrationsService.GetIntegration(integrationID)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to get integration: %v", err), http.StatusInternalServerError)
+			apierror.Error(w, fmt.Sprintf("Failed to get integration: %v", err), http.StatusInternalServerError)
 			return
 		}
 
 		// Verify user has access to this integration
 		if integration.FamilyID != user.FamilyID {
-			http.Error(w, "Access denied", http.StatusForbidden)
+			apierror.Error(w, "Access denied", http.StatusForbidden)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(integration); err != nil {
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
 			return
 		}
 	}
 }
 
-// UpdateIntegration handles PATCH /api/v1/integrations/{id}
+// UpdateIntegration handles PATCH /api/v1/integrations/{integrationID}
 func (h *IntegrationsAPIHandler) UpdateIntegration(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "PATCH" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// Extract integration ID from URL
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 5 {
-		http.Error(w, "Invalid integration ID", http.StatusBadRequest)
-		return
-	}
-	integrationID := pathParts[4]
+	integrationID := r.PathValue("integrationID")
 
 	// Get user from context
 	user := auth.GetUserFromContext(r.Context())
 	if user == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
 	// Verify user has access to this integration
 	integration, err := h.integrationsService.GetIntegration(integrationID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get integration: %v", err), http.StatusInternalServerError)
+		apierror.Error(w, fmt.Sprintf("Failed to get integration: %v", err), http.StatusInternalServerError)
 		return
 	}
 	if integration.FamilyID != user.FamilyID {
-		http.Error(w, "Access denied", http.StatusForbidden)
+		apierror.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
 
 	// Parse request body
 	var req services.UpdateIntegrationRequest
-	if decodeErr := json.NewDecoder(r.Body).Decode(&req); decodeErr != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
 	// Update integration
 	updatedIntegration, err := h.integrationsService.UpdateIntegration(integrationID, &req)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to update integration: %v", err), http.StatusInternalServerError)
+		apierror.Error(w, fmt.Sprintf("Failed to update integration: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(updatedIntegration); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
 }
 
-// DeleteIntegration handles DELETE /api/v1/integrations/{id}
+// DeleteIntegration handles DELETE /api/v1/integrations/{integrationID}
 func (h *IntegrationsAPIHandler) DeleteIntegration(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "DELETE" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// Extract integration ID from URL
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 5 {
-		http.Error(w, "Invalid integration ID", http.StatusBadRequest)
-		return
-	}
-	integrationID := pathParts[4]
+	integrationID := r.PathValue("integrationID")
 
 	// Get user from context
 	user := auth.GetUserFromContext(r.Context())
 	if user == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
 	// Verify user has access to this integration
 	integration, err := h.integrationsService.GetIntegration(integrationID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get integration: %v", err), http.StatusInternalServerError)
+		apierror.Error(w, fmt.Sprintf("Failed to get integration: %v", err), http.StatusInternalServerError)
 		return
 	}
 	if integration.FamilyID != user.FamilyID {
-		http.Error(w, "Access denied", http.StatusForbidden)
+		apierror.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
 
 	// Delete integration
 	if err := h.integrationsService.DeleteIntegration(integrationID); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to delete integration: %v", err), http.StatusInternalServerError)
+		apierror.Error(w, fmt.Sprintf("Failed to delete integration: %v", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -293,87 +317,85 @@ func (h *IntegrationsAPIHandler) DeleteIntegration(w http.ResponseWriter, r *htt
 		"status":  "success",
 		"message": "Integration deleted successfully",
 	}); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
 }
 
-// SyncIntegration handles POST /api/v1/integrations/{id}/sync
+// SyncIntegration handles POST /api/v1/integrations/{integrationID}/sync
 func (h *IntegrationsAPIHandler) SyncIntegration(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// Extract integration ID from URL
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 6 {
-		http.Error(w, "Invalid integration ID", http.StatusBadRequest)
-		return
-	}
-	integrationID := pathParts[4]
+	integrationID := r.PathValue("integrationID")
 
 	// Get user from context
 	user := auth.GetUserFromContext(r.Context())
 	if user == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
 	// Verify user has access to this integration
 	integration, err := h.integrationsService.GetIntegration(integrationID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get integration: %v", err), http.StatusInternalServerError)
+		apierror.Error(w, fmt.Sprintf("Failed to get integration: %v", err), http.StatusInternalServerError)
 		return
 	}
 	if integration.FamilyID != user.FamilyID {
-		http.Error(w, "Access denied", http.StatusForbidden)
+		apierror.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
 
-	// TODO: Implement sync logic based on integration type
-	// For now, just return success
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(map[string]string{
+	response := map[string]string{
 		"status":         "success",
 		"message":        "Sync initiated",
 		"integration_id": integrationID,
-	}); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		return
 	}
-}
 
-// TestIntegration handles POST /api/v1/integrations/{id}/test
-func (h *IntegrationsAPIHandler) TestIntegration(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+	// Calendar feeds are fetched now rather than at their next refresh;
+	// repeated clicks within a sync window find the job already queued
+	// TODO: Implement sync logic for the other integration types
+	if integration.Provider == services.ProviderICS && h.jobSystem != nil {
+		idempotencyKey := jobs.FeedSyncIdempotencyKey(integrationID, time.Now())
+		result, err := h.jobSystem.EnqueueOnce(&jobsystem.EnqueueRequest{
+			QueueName:      "default",
+			JobType:        jobs.FeedSyncJobType,
+			Payload:        map[string]any{"integration_id": integrationID, "manual": true},
+			Priority:       2, // Higher priority for manual sync
+			MaxRetries:     2,
+			IdempotencyKey: &idempotencyKey,
+		})
+		if err != nil {
+			apierror.Error(w, fmt.Sprintf("Failed to start sync: %v", err), http.StatusInternalServerError)
+			return
+		}
+		response["job_id"] = result.JobID
 	}
 
-	// Extract integration ID from URL
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 6 {
-		http.Error(w, "Invalid integration ID", http.StatusBadRequest)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
-	integrationID := pathParts[4]
+}
+
+// TestIntegration handles POST /api/v1/integrations/{integrationID}/test
+func (h *IntegrationsAPIHandler) TestIntegration(w http.ResponseWriter, r *http.Request) {
+	integrationID := r.PathValue("integrationID")
 
 	// Get user from context
 	user := auth.GetUserFromContext(r.Context())
 	if user == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
 	// Verify user has access to this integration
 	integration, err := h.integrationsService.GetIntegration(integrationID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get integration: %v", err), http.StatusInternalServerError)
+		apierror.Error(w, fmt.Sprintf("Failed to get integration: %v", err), http.StatusInternalServerError)
 		return
 	}
 	if integration.FamilyID != user.FamilyID {
-		http.Error(w, "Access denied", http.StatusForbidden)
+		apierror.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
 
@@ -385,50 +407,39 @@ func (h *IntegrationsAPIHandler) TestIntegration(w http.ResponseWriter, r *http.
 		"message":        "Connection test successful",
 		"integration_id": integrationID,
 	}); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
 }
 
-// InitiateOAuth handles POST /api/v1/integrations/{id}/oauth/initiate
+// InitiateOAuth handles POST /api/v1/integrations/{integrationID}/oauth/initiate
 func (h *IntegrationsAPIHandler) InitiateOAuth(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// Extract integration ID from URL
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 7 {
-		http.Error(w, "Invalid integration ID", http.StatusBadRequest)
-		return
-	}
-	integrationID := pathParts[4]
+	integrationID := r.PathValue("integrationID")
 
 	// Get user from context
 	user := auth.GetUserFromContext(r.Context())
 	if user == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
 	// Get integration to verify access
 	integration, err := h.integrationsService.GetIntegration(integrationID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get integration: %v", err), http.StatusInternalServerError)
+		apierror.Error(w, fmt.Sprintf("Failed to get integration: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	// Verify user has access to this integration
 	if integration.FamilyID != user.FamilyID {
-		http.Error(w, "Access denied", http.StatusForbidden)
+		apierror.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
 
 	// Generate authorization URL using service layer
-	authURL, err := h.integrationsService.InitiateOAuth(integrationID, r.Host)
+	authURL, err := h.integrationsService.InitiateOAuth(integrationID, middleware.Origin(r))
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to initiate OAuth: %v", err), http.StatusBadRequest)
+		apierror.Error(w, fmt.Sprintf("Failed to initiate OAuth: %v", err), http.StatusBadRequest)
 		return
 	}
 
@@ -436,7 +447,59 @@ func (h *IntegrationsAPIHandler) InitiateOAuth(w http.ResponseWriter, r *http.Re
 	if err := json.NewEncoder(w).Encode(map[string]string{
 		"authorization_url": authURL,
 	}); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
 }
+
+// GetWebhook handles GET /api/v1/integrations/{integrationID}/webhook, the secrets of an
+// IFTTT or Zapier integration and the URLs its triggers are POSTed to
+func (h *IntegrationsAPIHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	h.writeWebhook(w, r, h.integrationsService.WebhookSecrets)
+}
+
+// RotateWebhook handles POST /api/v1/integrations/{integrationID}/webhook/rotate,
+// replacing the integration's webhook secrets
+func (h *IntegrationsAPIHandler) RotateWebhook(w http.ResponseWriter, r *http.Request) {
+	h.writeWebhook(w, r, h.integrationsService.RotateWebhookSecrets)
+}
+
+func (h *IntegrationsAPIHandler) writeWebhook(w http.ResponseWriter, r *http.Request, secrets func(integrationID string) (*services.WebhookSecrets, error)) {
+	integrationID := r.PathValue("integrationID")
+
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	integration, err := h.integrationsService.GetIntegration(integrationID)
+	if err != nil || integration.FamilyID != user.FamilyID {
+		apierror.Error(w, "Integration not found", http.StatusNotFound)
+		return
+	}
+	if !services.IsWebhookProvider(integration.Provider) {
+		apierror.Error(w, "Integration does not use webhooks", http.StatusBadRequest)
+		return
+	}
+
+	webhookSecrets, err := secrets(integrationID)
+	if err != nil {
+		apierror.Error(w, fmt.Sprintf("Failed to get webhook secrets: %v", err), http.StatusInternalServerError)
+		return
+	}
+	inbound := middleware.Origin(r) + "/api/v1/webhooks/" + integrationID + "/" + webhookSecrets.InboundToken
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"signing_secret":    webhookSecrets.SigningSecret,
+		"inbound_token":     webhookSecrets.InboundToken,
+		"task_trigger_url":  inbound + "/tasks",
+		"event_trigger_url": inbound + "/events",
+		"events":            services.WebhookEvents,
+		"settings":          integration.WebhookSettings(),
+	}); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// defaultJobLogLimit caps how many log lines are returned when no limit is given
+const defaultJobLogLimit = 500
+
+// queueMetricsWindow is how far back the queue overview's job metrics reach
+const queueMetricsWindow = time.Hour
+
+// jobStatuses are the statuses a job list can be filtered by
+var jobStatuses = map[string]bool{
+	"pending": true, "running": true, "completed": true, "failed": true, "cancelled": true,
+}
+
+// JobsAPIHandler handles admin job API requests
+type JobsAPIHandler struct {
+	jobsService *services.JobsService
+}
+
+// NewJobsAPIHandler creates a new jobs API handler
+func NewJobsAPIHandler(jobsService *services.JobsService) *JobsAPIHandler {
+	return &JobsAPIHandler{
+		jobsService: jobsService,
+	}
+}
+
+// GetJobLogs handles GET /api/v1/admin/jobs/{jobID}/logs
+func (h *JobsAPIHandler) GetJobLogs(w http.ResponseWriter, r *http.Request) {
+	jobID := r.PathValue("jobID")
+
+	limit := defaultJobLogLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	logs, err := h.jobsService.GetJobLogs(jobID, limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get job logs: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"job_id": jobID,
+		"logs":   logs,
+		"count":  len(logs),
+	}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// ListJobs handles GET /api/v1/admin/jobs, newest first. It filters by
+// ?queue=, ?status= and ?type= and pages with ?limit= and ?offset=.
+func (h *JobsAPIHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := services.JobFilter{
+		QueueName: query.Get("queue"),
+		Status:    query.Get("status"),
+		JobType:   query.Get("type"),
+	}
+
+	validator := validation.NewValidator()
+	if filter.Status != "" && !jobStatuses[filter.Status] {
+		validator.AddErrorf("status", "unknown job status %q", filter.Status)
+	}
+	filter.Limit = h.queryInt(validator, query.Get("limit"), "limit")
+	filter.Offset = h.queryInt(validator, query.Get("offset"), "offset")
+	if err := validator.ToError(); err != nil {
+		h.writeServiceError(w, "Invalid job filter", err)
+		return
+	}
+
+	jobs, err := h.jobsService.ListJobs(filter)
+	if err != nil {
+		h.writeServiceError(w, "Failed to list jobs", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"jobs":  jobs,
+		"count": len(jobs),
+	})
+}
+
+// queryInt parses an optional non-negative query parameter
+func (h *JobsAPIHandler) queryInt(validator *validation.Validator, value, field string) int {
+	if value == "" {
+		return 0
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		validator.AddErrorf(field, "%s must be a non-negative integer", field)
+		return 0
+	}
+	return parsed
+}
+
+// GetJob handles GET /api/v1/admin/jobs/{jobID}, including the payload and
+// last error
+func (h *JobsAPIHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.jobsService.GetJob(r.PathValue("jobID"))
+	if err != nil {
+		h.writeServiceError(w, "Failed to get job", err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, job)
+}
+
+// RetryJob handles POST /api/v1/admin/jobs/{jobID}/retry
+func (h *JobsAPIHandler) RetryJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.jobsService.RetryJob(r.PathValue("jobID"))
+	if err != nil {
+		h.writeServiceError(w, "Failed to retry job", err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, job)
+}
+
+// CancelJob handles POST /api/v1/admin/jobs/{jobID}/cancel
+func (h *JobsAPIHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.jobsService.CancelJob(r.PathValue("jobID"))
+	if err != nil {
+		h.writeServiceError(w, "Failed to cancel job", err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, job)
+}
+
+// GetQueues handles GET /api/v1/admin/queues: how many jobs each queue holds
+// and how the last hour of job runs went
+func (h *JobsAPIHandler) GetQueues(w http.ResponseWriter, r *http.Request) {
+	depths, err := h.jobsService.GetQueueDepths()
+	if err != nil {
+		h.writeServiceError(w, "Failed to get queue depths", err)
+		return
+	}
+	metrics, err := h.jobsService.GetJobMetrics("", "", queueMetricsWindow)
+	if err != nil {
+		h.writeServiceError(w, "Failed to get job metrics", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"queues":         depths,
+		"metrics":        metrics,
+		"metrics_window": queueMetricsWindow.String(),
+	})
+}
+
+func (h *JobsAPIHandler) writeServiceError(w http.ResponseWriter, message string, err error) {
+	var validationErrs validation.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":   "validation_failed",
+			"details": validationErrs,
+		})
+		return
+	}
+	switch err.Error() {
+	case "job not found":
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case "only failed or cancelled jobs can be retried", "only pending jobs can be cancelled":
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	http.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError)
+}
+
+func (h *JobsAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"famstack/internal/apierror"
+	"famstack/internal/auth"
+)
+
+// defaultLoginAttemptLimit caps how many attempts are returned when no limit is given
+const defaultLoginAttemptLimit = 100
+
+// LoginSecurityAPIHandler handles the admin API for sign-in attempts and lockouts
+type LoginSecurityAPIHandler struct {
+	authService *auth.Service
+}
+
+// NewLoginSecurityAPIHandler creates a new login security API handler
+func NewLoginSecurityAPIHandler(authService *auth.Service) *LoginSecurityAPIHandler {
+	return &LoginSecurityAPIHandler{
+		authService: authService,
+	}
+}
+
+// ListLoginAttempts handles GET /api/v1/admin/login-attempts?limit=
+func (h *LoginSecurityAPIHandler) ListLoginAttempts(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	limit := defaultLoginAttemptLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			apierror.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	attempts, err := h.authService.ListLoginAttempts(session.FamilyID, limit)
+	if err != nil {
+		apierror.Error(w, fmt.Sprintf("Failed to list login attempts: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if attempts == nil {
+		attempts = []auth.LoginAttempt{}
+	}
+
+	h.writeJSON(w, http.StatusOK, attempts)
+}
+
+// ListLockouts handles GET /api/v1/admin/lockouts, the family's members'
+// emails and PINs locked out now. Address lockouts are for the server
+// operator, see famstack lockouts.
+func (h *LoginSecurityAPIHandler) ListLockouts(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	lockouts, err := h.authService.ListLockouts(session.FamilyID)
+	if err != nil {
+		apierror.Error(w, fmt.Sprintf("Failed to list lockouts: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if lockouts == nil {
+		lockouts = []auth.Lockout{}
+	}
+	h.writeJSON(w, http.StatusOK, lockouts)
+}
+
+// ClearLockout handles DELETE /api/v1/admin/lockouts?kind=email|pin&subject=
+func (h *LoginSecurityAPIHandler) ClearLockout(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	kind := r.URL.Query().Get("kind")
+	subject := r.URL.Query().Get("subject")
+	if (kind != auth.LockoutEmail && kind != auth.LockoutPIN) || subject == "" {
+		apierror.Error(w, "kind must be email or pin, and subject is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authService.ClearLockout(session.FamilyID, kind, subject); err != nil {
+		if err.Error() == "lockout not found" {
+			apierror.Error(w, "Lockout not found", http.StatusNotFound)
+			return
+		}
+		apierror.Error(w, fmt.Sprintf("Failed to clear lockout: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *LoginSecurityAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,318 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// MealsAPIHandler handles recipe, meal plan and shopping list API requests
+type MealsAPIHandler struct {
+	mealsService *services.MealsService
+}
+
+// NewMealsAPIHandler creates a new meals API handler
+func NewMealsAPIHandler(mealsService *services.MealsService) *MealsAPIHandler {
+	return &MealsAPIHandler{
+		mealsService: mealsService,
+	}
+}
+
+// ListRecipes handles GET /api/v1/meals/recipes
+func (h *MealsAPIHandler) ListRecipes(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	recipes, err := h.mealsService.ListRecipes(session.FamilyID)
+	if err != nil {
+		h.writeServiceError(w, "Failed to list recipes", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, recipes)
+}
+
+// GetRecipe handles GET /api/v1/meals/recipes/{recipeID}
+func (h *MealsAPIHandler) GetRecipe(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	recipe, err := h.mealsService.GetRecipe(session.FamilyID, r.PathValue("recipeID"))
+	if err != nil {
+		h.writeServiceError(w, "Failed to get recipe", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, recipe)
+}
+
+// CreateRecipe handles POST /api/v1/meals/recipes
+func (h *MealsAPIHandler) CreateRecipe(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CreateRecipeRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	recipe, err := h.mealsService.CreateRecipe(session.FamilyID, session.UserID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to create recipe", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, recipe)
+}
+
+// UpdateRecipe handles PATCH /api/v1/meals/recipes/{recipeID}
+func (h *MealsAPIHandler) UpdateRecipe(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.UpdateRecipeRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	recipe, err := h.mealsService.UpdateRecipe(session.FamilyID, r.PathValue("recipeID"), &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to update recipe", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, recipe)
+}
+
+// DeleteRecipe handles DELETE /api/v1/meals/recipes/{recipeID}
+func (h *MealsAPIHandler) DeleteRecipe(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.mealsService.DeleteRecipe(session.FamilyID, r.PathValue("recipeID")); err != nil {
+		h.writeServiceError(w, "Failed to delete recipe", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetMealPlan handles GET /api/v1/meals/plan?start=2025-10-06&days=7. The
+// range defaults to the seven days from the family's today.
+func (h *MealsAPIHandler) GetMealPlan(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	start := r.URL.Query().Get("start")
+	if _, err := time.Parse("2006-01-02", start); start != "" && err != nil {
+		http.Error(w, "start must be YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	days := 7
+	if daysParam := r.URL.Query().Get("days"); daysParam != "" {
+		parsed, err := strconv.Atoi(daysParam)
+		if err != nil || parsed < 1 || parsed > models.MaxShoppingListDays {
+			http.Error(w, fmt.Sprintf("days must be between 1 and %d", models.MaxShoppingListDays), http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	plan, err := h.mealsService.GetMealPlan(session.FamilyID, start, days)
+	if err != nil {
+		h.writeServiceError(w, "Failed to get meal plan", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, plan)
+}
+
+// PlanMeal handles PUT /api/v1/meals/plan, setting a day's meal of a type
+func (h *MealsAPIHandler) PlanMeal(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.PlanMealRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	meal, err := h.mealsService.PlanMeal(session.FamilyID, session.UserID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to plan meal", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, meal)
+}
+
+// RemoveMeal handles DELETE /api/v1/meals/plan/{mealID}
+func (h *MealsAPIHandler) RemoveMeal(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.mealsService.RemoveMeal(session.FamilyID, r.PathValue("mealID")); err != nil {
+		h.writeServiceError(w, "Failed to remove meal", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListShoppingList handles GET /api/v1/meals/shopping-list
+func (h *MealsAPIHandler) ListShoppingList(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	items, err := h.mealsService.ListShoppingList(session.FamilyID)
+	if err != nil {
+		h.writeServiceError(w, "Failed to list shopping list", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, items)
+}
+
+// AddShoppingListItem handles POST /api/v1/meals/shopping-list
+func (h *MealsAPIHandler) AddShoppingListItem(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CreateShoppingListItemRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	item, err := h.mealsService.AddShoppingListItem(session.FamilyID, session.UserID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to add shopping list item", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, item)
+}
+
+// UpdateShoppingListItem handles PATCH /api/v1/meals/shopping-list/{itemID}
+func (h *MealsAPIHandler) UpdateShoppingListItem(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.UpdateShoppingListItemRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	item, err := h.mealsService.UpdateShoppingListItem(session.FamilyID, r.PathValue("itemID"), &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to update shopping list item", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, item)
+}
+
+// DeleteShoppingListItem handles DELETE /api/v1/meals/shopping-list/{itemID}
+func (h *MealsAPIHandler) DeleteShoppingListItem(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.mealsService.DeleteShoppingListItem(session.FamilyID, r.PathValue("itemID")); err != nil {
+		h.writeServiceError(w, "Failed to delete shopping list item", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GenerateShoppingList handles POST /api/v1/meals/shopping-list/generate,
+// filling the list from the recipes planned between start and end
+func (h *MealsAPIHandler) GenerateShoppingList(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.GenerateShoppingListRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	items, err := h.mealsService.GenerateShoppingList(session.FamilyID, session.UserID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to generate shopping list", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, items)
+}
+
+func (h *MealsAPIHandler) writeServiceError(w http.ResponseWriter, message string, err error) {
+	var validationErrs validation.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":   "validation_failed",
+			"details": validationErrs,
+		})
+		return
+	}
+	switch err.Error() {
+	case "recipe not found", "meal not found", "shopping list item not found":
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError)
+}
+
+func (h *MealsAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
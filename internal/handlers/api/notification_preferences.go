@@ -0,0 +1,81 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// NotificationPreferencesAPIHandler handles the current member's notification
+// preferences
+type NotificationPreferencesAPIHandler struct {
+	notificationsService *services.NotificationsService
+}
+
+// NewNotificationPreferencesAPIHandler creates a new notification preferences API handler
+func NewNotificationPreferencesAPIHandler(notificationsService *services.NotificationsService) *NotificationPreferencesAPIHandler {
+	return &NotificationPreferencesAPIHandler{
+		notificationsService: notificationsService,
+	}
+}
+
+// GetPreferences handles GET /api/v1/notification-preferences
+func (h *NotificationPreferencesAPIHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	prefs, err := h.notificationsService.GetPreferences(user.FamilyID, user.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get notification preferences: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, prefs)
+}
+
+// UpdatePreferences handles PATCH /api/v1/notification-preferences
+func (h *NotificationPreferencesAPIHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.UpdateNotificationPreferencesRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	prefs, err := h.notificationsService.UpdatePreferences(user.FamilyID, user.ID, &req)
+	if err != nil {
+		var validationErrs validation.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			h.writeJSON(w, http.StatusBadRequest, map[string]any{
+				"error":   "validation_failed",
+				"details": validationErrs,
+			})
+			return
+		}
+		http.Error(w, fmt.Sprintf("Failed to update notification preferences: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, prefs)
+}
+
+func (h *NotificationPreferencesAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
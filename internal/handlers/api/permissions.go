@@ -0,0 +1,134 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"famstack/internal/apierror"
+	"famstack/internal/auth"
+)
+
+// PermissionsAPIHandler handles the API parents use to give members
+// capabilities beyond their role, or take them away
+type PermissionsAPIHandler struct {
+	authService *auth.Service
+}
+
+// NewPermissionsAPIHandler creates a new permissions API handler
+func NewPermissionsAPIHandler(authService *auth.Service) *PermissionsAPIHandler {
+	return &PermissionsAPIHandler{
+		authService: authService,
+	}
+}
+
+// permissionsResponse lists the capabilities there are and the family's grants
+type permissionsResponse struct {
+	Capabilities []auth.CapabilityInfo `json:"capabilities"`
+	Grants       []auth.MemberGrant    `json:"grants"`
+}
+
+// SetGrantRequest gives a member a capability, or with granted false takes
+// it away
+type SetGrantRequest struct {
+	MemberID   string          `json:"member_id" validate:"required"`
+	Capability auth.Capability `json:"capability" validate:"required,oneof=create_tasks edit_calendar manage_schedules manage_integrations"`
+	Granted    bool            `json:"granted"`
+}
+
+// ListPermissions handles GET /api/v1/families/{familyID}/permissions, the
+// capabilities there are and the family's grants
+func (h *PermissionsAPIHandler) ListPermissions(w http.ResponseWriter, r *http.Request) {
+	familyID, _, ok := h.ownFamily(w, r)
+	if !ok {
+		return
+	}
+
+	grants, err := h.authService.ListGrants(familyID)
+	if err != nil {
+		apierror.Error(w, fmt.Sprintf("Failed to list grants: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if grants == nil {
+		grants = []auth.MemberGrant{}
+	}
+	h.writeJSON(w, http.StatusOK, permissionsResponse{Capabilities: auth.Capabilities(), Grants: grants})
+}
+
+// SetGrant handles PUT /api/v1/families/{familyID}/permissions
+func (h *PermissionsAPIHandler) SetGrant(w http.ResponseWriter, r *http.Request) {
+	familyID, session, ok := h.ownFamily(w, r)
+	if !ok {
+		return
+	}
+
+	var req SetGrantRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	err := h.authService.SetGrant(familyID, session.UserID, req.MemberID, req.Capability, req.Granted)
+	if err != nil {
+		switch err.Error() {
+		case "member not found":
+			apierror.Error(w, "Member not found", http.StatusNotFound)
+		case "admins have every capability", "unknown capability":
+			apierror.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			apierror.Error(w, fmt.Sprintf("Failed to save grant: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ClearGrant handles DELETE
+// /api/v1/families/{familyID}/permissions?member_id=&capability=
+func (h *PermissionsAPIHandler) ClearGrant(w http.ResponseWriter, r *http.Request) {
+	familyID, _, ok := h.ownFamily(w, r)
+	if !ok {
+		return
+	}
+
+	memberID := r.URL.Query().Get("member_id")
+	capability := auth.Capability(r.URL.Query().Get("capability"))
+	if memberID == "" || !capability.IsValid() {
+		apierror.Error(w, "member_id and a known capability are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authService.ClearGrant(familyID, memberID, capability); err != nil {
+		if err.Error() == "grant not found" {
+			apierror.Error(w, "Grant not found", http.StatusNotFound)
+			return
+		}
+		apierror.Error(w, fmt.Sprintf("Failed to clear grant: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ownFamily returns the family in the path with the session, which must
+// belong to it
+func (h *PermissionsAPIHandler) ownFamily(w http.ResponseWriter, r *http.Request) (string, *auth.Session, bool) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return "", nil, false
+	}
+
+	familyID := r.PathValue("familyID")
+	if familyID != session.FamilyID {
+		apierror.Error(w, "Permissions can only be managed in your own family", http.StatusForbidden)
+		return "", nil, false
+	}
+	return familyID, session, true
+}
+
+func (h *PermissionsAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"famstack/internal/apierror"
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+)
+
+// PreferencesAPIHandler handles the current member's preferences
+type PreferencesAPIHandler struct {
+	preferencesService *services.MemberPreferencesService
+}
+
+// NewPreferencesAPIHandler creates a new preferences API handler
+func NewPreferencesAPIHandler(preferencesService *services.MemberPreferencesService) *PreferencesAPIHandler {
+	return &PreferencesAPIHandler{
+		preferencesService: preferencesService,
+	}
+}
+
+// GetPreferences handles GET /api/v1/me/preferences
+func (h *PreferencesAPIHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	prefs, err := h.preferencesService.GetPreferences(user.FamilyID, user.ID)
+	if err != nil {
+		apierror.Error(w, fmt.Sprintf("Failed to get preferences: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, prefs)
+}
+
+// UpdatePreferences handles PATCH /api/v1/me/preferences
+func (h *PreferencesAPIHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.UpdateMemberPreferencesRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	prefs, err := h.preferencesService.UpdatePreferences(user.FamilyID, user.ID, &req)
+	if err != nil {
+		if apierror.WriteValidation(w, err) {
+			return
+		}
+		apierror.Error(w, fmt.Sprintf("Failed to update preferences: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, prefs)
+}
+
+// renderPreferences returns how the caller wants responses rendered, or nil
+// when there is no member to ask or their preferences can't be loaded
+func renderPreferences(r *http.Request, preferencesService *services.MemberPreferencesService) *models.RenderPreferences {
+	user := auth.GetUserFromContext(r.Context())
+	if preferencesService == nil || user == nil {
+		return nil
+	}
+	prefs, err := preferencesService.RenderPreferences(user.FamilyID, user.ID)
+	if err != nil {
+		fmt.Printf("❌ Preferences query error: %v\n", err)
+		return nil
+	}
+	return prefs
+}
+
+func (h *PreferencesAPIHandler) writeJSON(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
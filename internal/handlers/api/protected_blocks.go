@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// ProtectedBlocksAPIHandler handles "do not schedule" block API requests
+type ProtectedBlocksAPIHandler struct {
+	protectedBlocksService *services.ProtectedBlocksService
+}
+
+// NewProtectedBlocksAPIHandler creates a new protected blocks API handler
+func NewProtectedBlocksAPIHandler(protectedBlocksService *services.ProtectedBlocksService) *ProtectedBlocksAPIHandler {
+	return &ProtectedBlocksAPIHandler{
+		protectedBlocksService: protectedBlocksService,
+	}
+}
+
+// ListBlocks handles GET /api/v1/calendar/protected-blocks
+func (h *ProtectedBlocksAPIHandler) ListBlocks(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	activeOnly := r.URL.Query().Get("include_inactive") != "true"
+	blocks, err := h.protectedBlocksService.ListBlocks(session.FamilyID, activeOnly)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list protected blocks: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"protected_blocks": blocks,
+		"count":            len(blocks),
+	})
+}
+
+// CreateBlock handles POST /api/v1/calendar/protected-blocks
+func (h *ProtectedBlocksAPIHandler) CreateBlock(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CreateProtectedTimeBlockRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	block, err := h.protectedBlocksService.CreateBlock(user.FamilyID, user.ID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to create protected block", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, block)
+}
+
+// UpdateBlock handles PATCH /api/v1/calendar/protected-blocks/{blockID}
+func (h *ProtectedBlocksAPIHandler) UpdateBlock(w http.ResponseWriter, r *http.Request) {
+	block, ok := h.loadOwnedBlock(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateProtectedTimeBlockRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	updated, err := h.protectedBlocksService.UpdateBlock(block.ID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to update protected block", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteBlock handles DELETE /api/v1/calendar/protected-blocks/{blockID}
+func (h *ProtectedBlocksAPIHandler) DeleteBlock(w http.ResponseWriter, r *http.Request) {
+	block, ok := h.loadOwnedBlock(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.protectedBlocksService.DeleteBlock(block.ID); err != nil {
+		h.writeServiceError(w, "Failed to delete protected block", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadOwnedBlock fetches the block named in the URL and checks it belongs to the caller's family
+func (h *ProtectedBlocksAPIHandler) loadOwnedBlock(w http.ResponseWriter, r *http.Request) (*models.ProtectedTimeBlock, bool) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	blockID := r.PathValue("blockID")
+
+	block, err := h.protectedBlocksService.GetBlock(blockID)
+	if err != nil || block.FamilyID != session.FamilyID {
+		if err != nil && err.Error() != "protected block not found" {
+			http.Error(w, "Failed to query protected block", http.StatusInternalServerError)
+			return nil, false
+		}
+		http.Error(w, "Protected block not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return block, true
+}
+
+func (h *ProtectedBlocksAPIHandler) writeServiceError(w http.ResponseWriter, message string, err error) {
+	var validationErrs validation.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":   "validation_failed",
+			"details": validationErrs,
+		})
+		return
+	}
+	if err.Error() == "protected block not found" {
+		http.Error(w, "Protected block not found", http.StatusNotFound)
+		return
+	}
+	http.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError)
+}
+
+func (h *ProtectedBlocksAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"famstack/internal/apierror"
+	"famstack/internal/ratelimit"
+)
+
+// RateLimitsAPIHandler reports how the rate limits are holding up
+type RateLimitsAPIHandler struct {
+	limiter *ratelimit.Middleware
+}
+
+// NewRateLimitsAPIHandler creates a new rate limits API handler. limiter is
+// nil when rate limiting is turned off.
+func NewRateLimitsAPIHandler(limiter *ratelimit.Middleware) *RateLimitsAPIHandler {
+	return &RateLimitsAPIHandler{
+		limiter: limiter,
+	}
+}
+
+// GetRateLimits handles GET /api/v1/admin/rate-limits: each limit and how
+// many requests it let through and turned away since the server started
+func (h *RateLimitsAPIHandler) GetRateLimits(w http.ResponseWriter, r *http.Request) {
+	response := map[string]any{"enabled": h.limiter != nil}
+	if h.limiter != nil {
+		response["limits"] = h.limiter.Stats()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
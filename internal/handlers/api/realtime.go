@@ -0,0 +1,45 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"famstack/internal/auth"
+	"famstack/internal/realtime"
+)
+
+// RealtimeAPIHandler connects dashboards to the family's realtime room
+type RealtimeAPIHandler struct {
+	hub *realtime.Hub
+}
+
+// NewRealtimeAPIHandler creates a new realtime API handler
+func NewRealtimeAPIHandler(hub *realtime.Hub) *RealtimeAPIHandler {
+	return &RealtimeAPIHandler{
+		hub: hub,
+	}
+}
+
+// Connect handles GET /api/v1/ws, upgrading to a WebSocket in the session's
+// family room. It carries the same updates as /api/v1/stream, plus task
+// drag and drop between members editing the board; see the realtime
+// package for the message types.
+func (h *RealtimeAPIHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	if len(realtime.ReadableTopics(session)) == 0 {
+		http.Error(w, "Insufficient permissions", http.StatusForbidden)
+		return
+	}
+
+	conn, err := realtime.Upgrade(w, r)
+	if err != nil {
+		// Upgrade has answered the request
+		log.Printf("Failed to open WebSocket for %s: %v", session.UserID, err)
+		return
+	}
+	h.hub.Serve(conn, session)
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// RemindersAPIHandler handles event reminder and notification API requests
+type RemindersAPIHandler struct {
+	remindersService     *services.RemindersService
+	notificationsService *services.NotificationsService
+}
+
+// NewRemindersAPIHandler creates a new reminders API handler
+func NewRemindersAPIHandler(remindersService *services.RemindersService, notificationsService *services.NotificationsService) *RemindersAPIHandler {
+	return &RemindersAPIHandler{
+		remindersService:     remindersService,
+		notificationsService: notificationsService,
+	}
+}
+
+// ListReminders handles GET /api/v1/reminders?event_id=...
+func (h *RemindersAPIHandler) ListReminders(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	eventID := r.URL.Query().Get("event_id")
+	if eventID == "" {
+		http.Error(w, "event_id is required", http.StatusBadRequest)
+		return
+	}
+
+	reminders, err := h.remindersService.ListReminders(session.FamilyID, eventID)
+	if err != nil {
+		h.writeServiceError(w, "Failed to list reminders", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, reminders)
+}
+
+// CreateReminder handles POST /api/v1/reminders
+func (h *RemindersAPIHandler) CreateReminder(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CreateEventReminderRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	reminder, err := h.remindersService.CreateReminder(user.FamilyID, user.ID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to create reminder", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, reminder)
+}
+
+// DeleteReminder handles DELETE /api/v1/reminders/{reminderID}
+func (h *RemindersAPIHandler) DeleteReminder(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	reminderID := r.PathValue("reminderID")
+
+	if err := h.remindersService.DeleteReminder(session.FamilyID, reminderID); err != nil {
+		h.writeServiceError(w, "Failed to delete reminder", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetChannels handles GET /api/v1/notifications/channels. It lists the
+// channels reminders can use and the key browsers subscribe to web push with.
+func (h *RemindersAPIHandler) GetChannels(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"channels":         h.notificationsService.Channels(),
+		"vapid_public_key": h.notificationsService.WebPushKey(),
+	})
+}
+
+// ListPushSubscriptions handles GET /api/v1/notifications/push-subscriptions,
+// the current member's subscribed browsers
+func (h *RemindersAPIHandler) ListPushSubscriptions(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	subscriptions, err := h.notificationsService.ListPushSubscriptions(user.ID)
+	if err != nil {
+		h.writeServiceError(w, "Failed to list push subscriptions", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, subscriptions)
+}
+
+// SavePushSubscription handles POST /api/v1/notifications/push-subscriptions
+func (h *RemindersAPIHandler) SavePushSubscription(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.SavePushSubscriptionRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	if err := h.notificationsService.SavePushSubscription(user.ID, r.UserAgent(), &req); err != nil {
+		h.writeServiceError(w, "Failed to save push subscription", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeletePushSubscription handles DELETE /api/v1/notifications/push-subscriptions
+// with the subscription's endpoint in the body
+func (h *RemindersAPIHandler) DeletePushSubscription(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Endpoint string `json:"endpoint"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Endpoint == "" {
+		http.Error(w, "endpoint is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.notificationsService.DeletePushSubscription(user.ID, req.Endpoint); err != nil {
+		h.writeServiceError(w, "Failed to delete push subscription", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RemindersAPIHandler) writeServiceError(w http.ResponseWriter, message string, err error) {
+	var validationErrs validation.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":   "validation_failed",
+			"details": validationErrs,
+		})
+		return
+	}
+	switch err.Error() {
+	case "unified calendar event not found":
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	case "event reminder not found":
+		http.Error(w, "Reminder not found", http.StatusNotFound)
+		return
+	case "push subscription not found":
+		http.Error(w, "Push subscription not found", http.StatusNotFound)
+		return
+	case "event reminder already exists":
+		http.Error(w, "The event already has this reminder", http.StatusConflict)
+		return
+	}
+	http.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError)
+}
+
+func (h *RemindersAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"famstack/internal/apierror"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// maxRequestBytes bounds a JSON request body: a bulk import of
+// services.MaxBulkCalendarEvents events with long descriptions fits
+const maxRequestBytes = 4 << 20
+
+// normalizer is a request that canonicalizes its own values, like task types
+// given in any case, and reports the ones it cannot
+type normalizer interface {
+	Normalize() error
+}
+
+// decodeRequest reads the JSON body of r into req, a pointer to the
+// endpoint's request struct, and checks it against the struct's validate
+// tags. When the body is not valid it writes a 400 listing the fields at
+// fault and returns false; a body over maxRequestBytes gets a 413.
+func decodeRequest(w http.ResponseWriter, r *http.Request, req any) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			apierror.Error(w, fmt.Sprintf("Request body is larger than %d MB", maxRequestBytes>>20), http.StatusRequestEntityTooLarge)
+			return false
+		}
+		apierror.Error(w, "Invalid request body", http.StatusBadRequest)
+		return false
+	}
+
+	return validateRequest(w, req)
+}
+
+// validateRequest normalizes req when it has a Normalize method and checks
+// it against its validate tags, writing a 400 and returning false when it
+// is not valid
+func validateRequest(w http.ResponseWriter, req any) bool {
+	if n, ok := req.(normalizer); ok {
+		if err := n.Normalize(); err != nil {
+			if !apierror.WriteValidation(w, err) {
+				apierror.Error(w, err.Error(), http.StatusBadRequest)
+			}
+			return false
+		}
+	}
+
+	if err := validation.Struct(req); err != nil {
+		apierror.WriteValidation(w, err)
+		return false
+	}
+	return true
+}
+
+// pageRequest reads ?limit= and ?cursor= for a cursor-paginated list. paged
+// is false when the request has neither, for endpoints that still answer
+// with the whole list then. A bad limit writes a 400 and returns ok false.
+func pageRequest(w http.ResponseWriter, r *http.Request) (page services.PageRequest, paged, ok bool) {
+	query := r.URL.Query()
+	page.Cursor = query.Get("cursor")
+	if limitParam := query.Get("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > services.MaxPageLimit {
+			apierror.Error(w, fmt.Sprintf("limit must be between 1 and %d", services.MaxPageLimit), http.StatusBadRequest)
+			return page, false, false
+		}
+		page.Limit = limit
+	}
+	return page, query.Has("limit") || query.Has("cursor"), true
+}
+
+// writePageError writes the error from listing a page: a 400 for a cursor
+// that can't be read, otherwise a 500 naming what failed to load
+func writePageError(w http.ResponseWriter, what string, err error) {
+	if err.Error() == "invalid cursor" {
+		apierror.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+	apierror.Error(w, fmt.Sprintf("Failed to list %s: %v", what, err), http.StatusInternalServerError)
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeRequest_LimitsBodySize(t *testing.T) {
+	var req struct {
+		Title string `json:"title"`
+	}
+
+	recorder := httptest.NewRecorder()
+	assert.True(t, decodeRequest(recorder, httptest.NewRequest("POST", "/api/v1/tasks", strings.NewReader(`{"title":"Dishes"}`)), &req))
+	assert.Equal(t, "Dishes", req.Title)
+
+	huge := `{"title":"` + strings.Repeat("x", maxRequestBytes) + `"}`
+	recorder = httptest.NewRecorder()
+	assert.False(t, decodeRequest(recorder, httptest.NewRequest("POST", "/api/v1/tasks", strings.NewReader(huge)), &req))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"code":"payload_too_large"`)
+
+	recorder = httptest.NewRecorder()
+	assert.False(t, decodeRequest(recorder, httptest.NewRequest("POST", "/api/v1/tasks", strings.NewReader(`{"title":`)), &req))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
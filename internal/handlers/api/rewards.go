@@ -0,0 +1,303 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// RewardsAPIHandler handles points, rewards and redemption API requests
+type RewardsAPIHandler struct {
+	rewardsService *services.RewardsService
+}
+
+// NewRewardsAPIHandler creates a new rewards API handler
+func NewRewardsAPIHandler(rewardsService *services.RewardsService) *RewardsAPIHandler {
+	return &RewardsAPIHandler{
+		rewardsService: rewardsService,
+	}
+}
+
+// GetBalances handles GET /api/v1/points, every member's balance
+func (h *RewardsAPIHandler) GetBalances(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	balances, err := h.rewardsService.GetBalances(session.FamilyID)
+	if err != nil {
+		h.writeServiceError(w, "Failed to get points", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, balances)
+}
+
+// GetHistory handles GET /api/v1/points/history?member_id=...&limit=50
+func (h *RewardsAPIHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	memberID := r.URL.Query().Get("member_id")
+	if memberID == "" {
+		memberID = session.UserID
+	}
+
+	limit := 50
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed < 1 || parsed > 500 {
+			http.Error(w, "limit must be between 1 and 500", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	balance, err := h.rewardsService.GetBalance(session.FamilyID, memberID)
+	if err != nil {
+		h.writeServiceError(w, "Failed to get points", err)
+		return
+	}
+	entries, err := h.rewardsService.ListHistory(session.FamilyID, memberID, limit)
+	if err != nil {
+		h.writeServiceError(w, "Failed to get points history", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"balance": balance,
+		"entries": entries,
+	})
+}
+
+// AdjustPoints handles POST /api/v1/points/adjustments
+func (h *RewardsAPIHandler) AdjustPoints(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.PointsAdjustmentRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	entry, err := h.rewardsService.AdjustPoints(session.FamilyID, session.UserID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to adjust points", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, entry)
+}
+
+// ListRewards handles GET /api/v1/rewards?include_archived=true
+func (h *RewardsAPIHandler) ListRewards(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	rewards, err := h.rewardsService.ListRewards(session.FamilyID, r.URL.Query().Get("include_archived") == "true")
+	if err != nil {
+		h.writeServiceError(w, "Failed to list rewards", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, rewards)
+}
+
+// CreateReward handles POST /api/v1/rewards
+func (h *RewardsAPIHandler) CreateReward(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CreateRewardRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	reward, err := h.rewardsService.CreateReward(session.FamilyID, session.UserID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to create reward", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, reward)
+}
+
+// UpdateReward handles PATCH /api/v1/rewards/{rewardID}
+func (h *RewardsAPIHandler) UpdateReward(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.UpdateRewardRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	reward, err := h.rewardsService.UpdateReward(session.FamilyID, r.PathValue("rewardID"), &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to update reward", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, reward)
+}
+
+// ArchiveReward handles DELETE /api/v1/rewards/{rewardID}. The reward is archived
+// so the history of what was redeemed keeps its name.
+func (h *RewardsAPIHandler) ArchiveReward(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.rewardsService.ArchiveReward(session.FamilyID, r.PathValue("rewardID")); err != nil {
+		h.writeServiceError(w, "Failed to archive reward", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RedeemReward handles POST /api/v1/rewards/{rewardID}/redeem. Members redeem for
+// themselves; parents may redeem on a child's behalf.
+func (h *RewardsAPIHandler) RedeemReward(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.RedeemRewardRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON data", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.MemberID == "" {
+		req.MemberID = session.UserID
+	}
+	if req.MemberID != session.UserID && session.Role != auth.RoleAdmin {
+		http.Error(w, "Only parents can redeem for another member", http.StatusForbidden)
+		return
+	}
+
+	redemption, err := h.rewardsService.RequestRedemption(session.FamilyID, req.MemberID, r.PathValue("rewardID"))
+	if err != nil {
+		h.writeServiceError(w, "Failed to redeem reward", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, redemption)
+}
+
+// ListRedemptions handles GET /api/v1/redemptions?status=pending
+func (h *RewardsAPIHandler) ListRedemptions(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.RedemptionPending, models.RedemptionApproved, models.RedemptionRejected:
+	default:
+		http.Error(w, "status must be pending, approved or rejected", http.StatusBadRequest)
+		return
+	}
+
+	redemptions, err := h.rewardsService.ListRedemptions(session.FamilyID, status)
+	if err != nil {
+		h.writeServiceError(w, "Failed to list redemptions", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, redemptions)
+}
+
+// ApproveRedemption handles POST /api/v1/redemptions/{redemptionID}/approve
+func (h *RewardsAPIHandler) ApproveRedemption(w http.ResponseWriter, r *http.Request) {
+	h.decideRedemption(w, r, h.rewardsService.ApproveRedemption, "Failed to approve redemption")
+}
+
+// RejectRedemption handles POST /api/v1/redemptions/{redemptionID}/reject
+func (h *RewardsAPIHandler) RejectRedemption(w http.ResponseWriter, r *http.Request) {
+	h.decideRedemption(w, r, h.rewardsService.RejectRedemption, "Failed to reject redemption")
+}
+
+func (h *RewardsAPIHandler) decideRedemption(w http.ResponseWriter, r *http.Request, decide func(familyID, redemptionID, decidedBy string, req *models.DecideRedemptionRequest) (*models.Redemption, error), message string) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.DecideRedemptionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON data", http.StatusBadRequest)
+			return
+		}
+	}
+
+	redemption, err := decide(session.FamilyID, r.PathValue("redemptionID"), session.UserID, &req)
+	if err != nil {
+		h.writeServiceError(w, message, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, redemption)
+}
+
+func (h *RewardsAPIHandler) writeServiceError(w http.ResponseWriter, message string, err error) {
+	var validationErrs validation.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":   "validation_failed",
+			"details": validationErrs,
+		})
+		return
+	}
+	switch err.Error() {
+	case "reward not found", "redemption not found", "family member not found":
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case "not enough points", "redemption already decided":
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	http.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError)
+}
+
+func (h *RewardsAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
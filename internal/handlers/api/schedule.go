@@ -2,10 +2,12 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"path"
+	"strconv"
 
+	"famstack/internal/apierror"
 	"famstack/internal/auth"
 	"famstack/internal/jobsystem"
 	"famstack/internal/models"
@@ -32,15 +34,10 @@ func NewScheduleHandlerWithJobSystem(schedulesService *services.SchedulesService
 
 // ListSchedules returns all active task schedules for a family
 func (h *ScheduleHandler) ListSchedules(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Get family ID from session context
 	session := auth.GetSessionFromContext(r.Context())
 	if session == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 	familyID := session.FamilyID
@@ -48,33 +45,27 @@ func (h *ScheduleHandler) ListSchedules(w http.ResponseWriter, r *http.Request)
 	// Use the service to get schedules
 	schedules, err := h.schedulesService.ListSchedules(familyID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to query schedules: %v", err), http.StatusInternalServerError)
+		apierror.Error(w, fmt.Sprintf("Failed to query schedules: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(schedules); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
 // CreateSchedule creates a new task schedule
 func (h *ScheduleHandler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	var req models.CreateTaskScheduleRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON data", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
 	// Get family ID and user ID from session context
 	session := auth.GetSessionFromContext(r.Context())
 	if session == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 	familyID := session.FamilyID
@@ -83,76 +74,69 @@ func (h *ScheduleHandler) CreateSchedule(w http.ResponseWriter, r *http.Request)
 	// Use the service to create the schedule
 	schedule, err := h.schedulesService.CreateSchedule(familyID, createdBy, &req)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create schedule: %v", err), http.StatusInternalServerError)
+		if apierror.WriteValidation(w, err) {
+			return
+		}
+		if err.Error() == "family member not found" {
+			apierror.Error(w, "Rotation member not found", http.StatusBadRequest)
+			return
+		}
+		apierror.Error(w, fmt.Sprintf("Failed to create schedule: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(schedule); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
 // GetSchedule retrieves a specific task schedule
 func (h *ScheduleHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
+	scheduleID := r.PathValue("scheduleID")
 
-	// Extract schedule ID from URL path
-	scheduleID := path.Base(r.URL.Path)
-	if scheduleID == "" || scheduleID == "/" {
-		http.Error(w, "Schedule ID is required", http.StatusBadRequest)
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
 	// Use the service to get the schedule
-	schedule, err := h.schedulesService.GetSchedule(scheduleID)
+	schedule, err := h.schedulesService.GetFamilySchedule(session.FamilyID, scheduleID)
 	if err != nil {
-		if err.Error() == "schedule not found" {
-			http.Error(w, "Schedule not found", http.StatusNotFound)
+		if errors.Is(err, services.ErrNotFound) {
+			apierror.Error(w, "Schedule not found", http.StatusNotFound)
 		} else {
-			http.Error(w, "Failed to query schedule", http.StatusInternalServerError)
+			apierror.Error(w, "Failed to query schedule", http.StatusInternalServerError)
 		}
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(schedule); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
 // UpdateSchedule updates a task schedule
 func (h *ScheduleHandler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "PATCH" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// Extract schedule ID from URL path
-	scheduleID := path.Base(r.URL.Path)
-	if scheduleID == "" || scheduleID == "/" {
-		http.Error(w, "Schedule ID is required", http.StatusBadRequest)
-		return
-	}
+	scheduleID := r.PathValue("scheduleID")
 
 	// Get session to check authorization
 	session := auth.GetSessionFromContext(r.Context())
 	if session == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
 	// Get the schedule to check ownership
-	schedule, getErr := h.schedulesService.GetSchedule(scheduleID)
+	schedule, getErr := h.schedulesService.GetFamilySchedule(session.FamilyID, scheduleID)
 	if getErr != nil {
-		if getErr.Error() == "schedule not found" {
-			http.Error(w, "Schedule not found", http.StatusNotFound)
+		if errors.Is(getErr, services.ErrNotFound) {
+			apierror.Error(w, "Schedule not found", http.StatusNotFound)
 		} else {
-			http.Error(w, "Failed to query schedule", http.StatusInternalServerError)
+			apierror.Error(w, "Failed to query schedule", http.StatusInternalServerError)
 		}
 		return
 	}
@@ -163,61 +147,56 @@ func (h *ScheduleHandler) UpdateSchedule(w http.ResponseWriter, r *http.Request)
 	canUpdate := session.Role == auth.RoleAdmin || session.UserID == schedule.CreatedBy
 
 	if !canUpdate {
-		http.Error(w, "Insufficient permissions: only admins or schedule creators can update schedules", http.StatusForbidden)
+		apierror.Error(w, "Insufficient permissions: only admins or schedule creators can update schedules", http.StatusForbidden)
 		return
 	}
 
 	var req models.UpdateTaskScheduleRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON data", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
 	// Use the service to update the schedule
-	updatedSchedule, err := h.schedulesService.UpdateSchedule(scheduleID, &req)
+	updatedSchedule, err := h.schedulesService.UpdateSchedule(session.FamilyID, scheduleID, &req)
 	if err != nil {
-		if err.Error() == "schedule not found" {
-			http.Error(w, "Schedule not found", http.StatusNotFound)
-		} else {
-			http.Error(w, fmt.Sprintf("Failed to update schedule: %v", err), http.StatusInternalServerError)
+		if apierror.WriteValidation(w, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			apierror.Error(w, "Schedule not found", http.StatusNotFound)
+		case err.Error() == "family member not found":
+			apierror.Error(w, "Rotation member not found", http.StatusBadRequest)
+		default:
+			apierror.Error(w, fmt.Sprintf("Failed to update schedule: %v", err), http.StatusInternalServerError)
 		}
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(updatedSchedule); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
 // DeleteSchedule deletes a task schedule
 func (h *ScheduleHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "DELETE" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// Extract schedule ID from URL path
-	scheduleID := path.Base(r.URL.Path)
-	if scheduleID == "" || scheduleID == "/" {
-		http.Error(w, "Schedule ID is required", http.StatusBadRequest)
-		return
-	}
+	scheduleID := r.PathValue("scheduleID")
 
 	// Get session to check authorization
 	session := auth.GetSessionFromContext(r.Context())
 	if session == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
 	// Get the schedule to check ownership
-	schedule, getErr := h.schedulesService.GetSchedule(scheduleID)
+	schedule, getErr := h.schedulesService.GetFamilySchedule(session.FamilyID, scheduleID)
 	if getErr != nil {
-		if getErr.Error() == "schedule not found" {
-			http.Error(w, "Schedule not found", http.StatusNotFound)
+		if errors.Is(getErr, services.ErrNotFound) {
+			apierror.Error(w, "Schedule not found", http.StatusNotFound)
 		} else {
-			http.Error(w, "Failed to query schedule", http.StatusInternalServerError)
+			apierror.Error(w, "Failed to query schedule", http.StatusInternalServerError)
 		}
 		return
 	}
@@ -228,20 +207,54 @@ func (h *ScheduleHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request)
 	canDelete := session.Role == auth.RoleAdmin || session.UserID == schedule.CreatedBy
 
 	if !canDelete {
-		http.Error(w, "Insufficient permissions: only admins or schedule creators can delete schedules", http.StatusForbidden)
+		apierror.Error(w, "Insufficient permissions: only admins or schedule creators can delete schedules", http.StatusForbidden)
 		return
 	}
 
 	// Use the service to delete the schedule
-	deleteErr := h.schedulesService.DeleteSchedule(scheduleID)
+	deleteErr := h.schedulesService.DeleteSchedule(session.FamilyID, scheduleID)
 	if deleteErr != nil {
-		if deleteErr.Error() == "schedule not found" {
-			http.Error(w, "Schedule not found", http.StatusNotFound)
+		if errors.Is(deleteErr, services.ErrNotFound) {
+			apierror.Error(w, "Schedule not found", http.StatusNotFound)
 		} else {
-			http.Error(w, fmt.Sprintf("Failed to delete schedule: %v", deleteErr), http.StatusInternalServerError)
+			apierror.Error(w, fmt.Sprintf("Failed to delete schedule: %v", deleteErr), http.StatusInternalServerError)
 		}
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// PreviewSchedule handles GET /api/v1/schedules/preview?cron_expr=...&count=5,
+// listing when a cron expression would make tasks before it is saved
+func (h *ScheduleHandler) PreviewSchedule(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	count := 5
+	if countParam := r.URL.Query().Get("count"); countParam != "" {
+		parsed, err := strconv.Atoi(countParam)
+		if err != nil || parsed < 1 || parsed > 50 {
+			apierror.Error(w, "count must be between 1 and 50", http.StatusBadRequest)
+			return
+		}
+		count = parsed
+	}
+
+	preview, err := h.schedulesService.PreviewCron(session.FamilyID, r.URL.Query().Get("cron_expr"), count)
+	if err != nil {
+		if apierror.WriteValidation(w, err) {
+			return
+		}
+		apierror.Error(w, fmt.Sprintf("Failed to preview schedule: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(preview); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
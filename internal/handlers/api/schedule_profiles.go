@@ -0,0 +1,234 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// ScheduleProfilesAPIHandler handles seasonal schedule profile API requests
+type ScheduleProfilesAPIHandler struct {
+	profilesService  *services.ScheduleProfilesService
+	schedulesService *services.SchedulesService
+}
+
+// NewScheduleProfilesAPIHandler creates a new schedule profiles API handler
+func NewScheduleProfilesAPIHandler(profilesService *services.ScheduleProfilesService, schedulesService *services.SchedulesService) *ScheduleProfilesAPIHandler {
+	return &ScheduleProfilesAPIHandler{
+		profilesService:  profilesService,
+		schedulesService: schedulesService,
+	}
+}
+
+// ListProfiles handles GET /api/v1/schedule-profiles
+func (h *ScheduleProfilesAPIHandler) ListProfiles(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	profiles, err := h.profilesService.ListProfiles(session.FamilyID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list schedule profiles: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"schedule_profiles": profiles,
+		"count":             len(profiles),
+	})
+}
+
+// CreateProfile handles POST /api/v1/schedule-profiles
+func (h *ScheduleProfilesAPIHandler) CreateProfile(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CreateScheduleProfileRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	profile, err := h.profilesService.CreateProfile(session.FamilyID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to create schedule profile", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, profile)
+}
+
+// UpdateProfile handles PATCH /api/v1/schedule-profiles/{profileID}
+func (h *ScheduleProfilesAPIHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	profile, ok := h.loadOwnedProfile(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateScheduleProfileRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	updated, err := h.profilesService.UpdateProfile(profile.ID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to update schedule profile", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteProfile handles DELETE /api/v1/schedule-profiles/{profileID}
+func (h *ScheduleProfilesAPIHandler) DeleteProfile(w http.ResponseWriter, r *http.Request) {
+	profile, ok := h.loadOwnedProfile(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.profilesService.DeleteProfile(profile.ID); err != nil {
+		h.writeServiceError(w, "Failed to delete schedule profile", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetScheduleProfiles handles GET /api/v1/schedules/{scheduleID}/profiles
+func (h *ScheduleProfilesAPIHandler) GetScheduleProfiles(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	schedule, ok := h.loadFamilySchedule(w, r, session)
+	if !ok {
+		return
+	}
+
+	profileIDs, err := h.profilesService.ListProfileIDsForSchedule(schedule.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list schedule profiles: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"schedule_id": schedule.ID,
+		"profile_ids": profileIDs,
+	})
+}
+
+// SetScheduleProfiles handles PUT /api/v1/schedules/{scheduleID}/profiles. An empty
+// list takes the schedule out of every profile so it runs year-round.
+func (h *ScheduleProfilesAPIHandler) SetScheduleProfiles(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	schedule, ok := h.loadFamilySchedule(w, r, session)
+	if !ok {
+		return
+	}
+
+	// Same rule as editing the schedule itself: admins or the schedule's creator
+	if session.Role != auth.RoleAdmin && session.UserID != schedule.CreatedBy {
+		http.Error(w, "Insufficient permissions: only admins or schedule creators can change schedule profiles", http.StatusForbidden)
+		return
+	}
+
+	var req models.SetScheduleProfilesRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	if err := h.profilesService.SetScheduleProfiles(session.FamilyID, schedule.ID, req.ProfileIDs); err != nil {
+		h.writeServiceError(w, "Failed to set schedule profiles", err)
+		return
+	}
+
+	profileIDs, err := h.profilesService.ListProfileIDsForSchedule(schedule.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list schedule profiles: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"schedule_id": schedule.ID,
+		"profile_ids": profileIDs,
+	})
+}
+
+// loadOwnedProfile fetches the profile named in the URL and checks it belongs to the caller's family
+func (h *ScheduleProfilesAPIHandler) loadOwnedProfile(w http.ResponseWriter, r *http.Request) (*models.ScheduleProfile, bool) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	profileID := r.PathValue("profileID")
+
+	profile, err := h.profilesService.GetProfile(profileID)
+	if err != nil || profile.FamilyID != session.FamilyID {
+		if err != nil && err.Error() != "schedule profile not found" {
+			http.Error(w, "Failed to query schedule profile", http.StatusInternalServerError)
+			return nil, false
+		}
+		http.Error(w, "Schedule profile not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return profile, true
+}
+
+// loadFamilySchedule fetches the schedule from /api/v1/schedules/{scheduleID}/profiles within the caller's family
+func (h *ScheduleProfilesAPIHandler) loadFamilySchedule(w http.ResponseWriter, r *http.Request, session *auth.Session) (*models.TaskSchedule, bool) {
+	schedule, err := h.schedulesService.GetFamilySchedule(session.FamilyID, r.PathValue("scheduleID"))
+	if err != nil {
+		if !errors.Is(err, services.ErrNotFound) {
+			http.Error(w, "Failed to query schedule", http.StatusInternalServerError)
+			return nil, false
+		}
+		http.Error(w, "Schedule not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return schedule, true
+}
+
+func (h *ScheduleProfilesAPIHandler) writeServiceError(w http.ResponseWriter, message string, err error) {
+	var validationErrs validation.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":   "validation_failed",
+			"details": validationErrs,
+		})
+		return
+	}
+	if err.Error() == "schedule profile not found" {
+		http.Error(w, "Schedule profile not found", http.StatusNotFound)
+		return
+	}
+	http.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError)
+}
+
+func (h *ScheduleProfilesAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
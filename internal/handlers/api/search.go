@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+)
+
+// searchKindEntities maps each kind of search result to the entity a member
+// needs read access to for it to appear
+var searchKindEntities = map[string]auth.Entity{
+	models.SearchKindTask:     auth.EntityTask,
+	models.SearchKindEvent:    auth.EntityCalendar,
+	models.SearchKindSchedule: auth.EntitySchedule,
+}
+
+// SearchAPIHandler handles search API requests
+type SearchAPIHandler struct {
+	searchService *services.SearchService
+}
+
+// NewSearchAPIHandler creates a new search API handler
+func NewSearchAPIHandler(searchService *services.SearchService) *SearchAPIHandler {
+	return &SearchAPIHandler{
+		searchService: searchService,
+	}
+}
+
+// Search handles GET /api/v1/search?q=...&types=task,event,schedule&limit=20.
+// Kinds the session can't read are left out rather than refused, so a shared
+// display searching everything gets tasks and events only.
+func (h *SearchAPIHandler) Search(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "q is required", http.StatusBadRequest)
+		return
+	}
+	if len(query) > 200 {
+		http.Error(w, "q must be at most 200 characters", http.StatusBadRequest)
+		return
+	}
+
+	requested := []string{models.SearchKindTask, models.SearchKindEvent, models.SearchKindSchedule}
+	if typesParam := r.URL.Query().Get("types"); typesParam != "" {
+		requested = strings.Split(typesParam, ",")
+	}
+
+	authorization := auth.NewAuthorizationService(session)
+	kinds := []string{}
+	for _, kind := range requested {
+		kind = strings.TrimSpace(kind)
+		entity, ok := searchKindEntities[kind]
+		if !ok {
+			http.Error(w, "types must be task, event or schedule", http.StatusBadRequest)
+			return
+		}
+		if authorization.HasPermission(entity, auth.ActionRead, nil) {
+			kinds = append(kinds, kind)
+		}
+	}
+
+	limit := 20
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed < 1 || parsed > models.MaxSearchResults {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", models.MaxSearchResults), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := h.searchService.Search(session.FamilyID, query, kinds, limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to search: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"query":   query,
+		"results": results,
+	}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/eventbus"
+	"famstack/internal/realtime"
+)
+
+// streamKeepAlive is how often an idle update stream sends a comment, so
+// proxies don't close it
+const streamKeepAlive = 30 * time.Second
+
+// streamBuffer is how many updates a client may fall behind by before it's
+// told to reload instead
+const streamBuffer = 32
+
+// StreamAPIHandler serves the real-time update stream
+type StreamAPIHandler struct {
+	events *eventbus.Bus
+}
+
+// NewStreamAPIHandler creates a new stream API handler
+func NewStreamAPIHandler(events *eventbus.Bus) *StreamAPIHandler {
+	return &StreamAPIHandler{
+		events: events,
+	}
+}
+
+// Stream handles GET /api/v1/stream, a server-sent event stream of changes to
+// the family's tasks, calendar and calendar syncs, so open dashboards update
+// without polling. Events are named by their type: task.created,
+// task.updated, task.completed, task.deleted, tasks.changed,
+// calendar.changed and sync.status. A client that falls too far behind gets
+// a "resync" event and should reload everything. Topics the session can't
+// read aren't sent.
+func (h *StreamAPIHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	familyID := session.FamilyID
+
+	topics := realtime.ReadableTopics(session)
+	if len(topics) == 0 {
+		http.Error(w, "Insufficient permissions", http.StatusForbidden)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// The server's write timeout is meant for ordinary requests, not streams
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// Bus handlers run on the publisher's goroutine, so they only queue
+	updates := make(chan realtime.Message, streamBuffer)
+	var missed atomic.Bool
+	unsubscribe := h.events.Subscribe(func(event eventbus.Event) {
+		if event.FamilyID != familyID {
+			return
+		}
+		select {
+		case updates <- realtime.MessageFor(event):
+		default:
+			missed.Store(true)
+		}
+	}, topics...)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	// Lets the client know the stream is live before anything changes
+	if _, err := fmt.Fprint(w, ": connected\n\n"); err != nil || rc.Flush() != nil {
+		return
+	}
+
+	ticker := time.NewTicker(streamKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			return
+		case update := <-updates:
+			err = writeStreamUpdate(w, rc, update)
+			if err == nil && missed.Swap(false) {
+				err = writeStreamUpdate(w, rc, realtime.Message{Type: realtime.TypeResync})
+			}
+			ticker.Reset(streamKeepAlive)
+		case <-ticker.C:
+			_, err = fmt.Fprint(w, ": keep-alive\n\n")
+			if err == nil {
+				err = rc.Flush()
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// writeStreamUpdate sends one server-sent event, named by the update's type,
+// and flushes it
+func writeStreamUpdate(w http.ResponseWriter, rc *http.ResponseController, update realtime.Message) error {
+	data, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", update.Type, data); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
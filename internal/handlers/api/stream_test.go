@@ -0,0 +1,52 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"famstack/internal/auth"
+	"famstack/internal/eventbus"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamSendsTheFamilysUpdates(t *testing.T) {
+	bus := eventbus.New()
+	handler := NewStreamAPIHandler(bus)
+	session := &auth.Session{UserID: "member_1", FamilyID: "fam_1", Role: auth.RoleAdmin}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.Stream(w, r.WithContext(context.WithValue(r.Context(), auth.SessionContextKey, session)))
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close() // nolint:errcheck
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		var lines []string
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			if line == "\n" {
+				return strings.Join(lines, "")
+			}
+			lines = append(lines, line)
+		}
+	}
+	// Subscribed once the stream says it's connected
+	assert.Equal(t, ": connected\n", readEvent())
+
+	bus.Publish(eventbus.Event{Topic: eventbus.TopicTasksChanged, FamilyID: "fam_2", SubjectID: "task_2", Detail: eventbus.DetailCreated})
+	bus.Publish(eventbus.Event{Topic: eventbus.TopicFocusChanged, FamilyID: "fam_1"})
+	bus.Publish(eventbus.Event{Topic: eventbus.TopicTasksChanged, FamilyID: "fam_1", SubjectID: "task_1", Detail: eventbus.DetailCreated})
+
+	assert.Equal(t, "event: task.created\ndata: {\"type\":\"task.created\",\"id\":\"task_1\"}\n", readEvent())
+}
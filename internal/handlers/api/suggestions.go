@@ -0,0 +1,154 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// SuggestionsAPIHandler handles suggestion engine API requests
+type SuggestionsAPIHandler struct {
+	suggestionsService *services.SuggestionsService
+}
+
+// NewSuggestionsAPIHandler creates a new suggestions API handler
+func NewSuggestionsAPIHandler(suggestionsService *services.SuggestionsService) *SuggestionsAPIHandler {
+	return &SuggestionsAPIHandler{
+		suggestionsService: suggestionsService,
+	}
+}
+
+// ListSuggestions handles GET /api/v1/suggestions?status=open. Pass status=all for every suggestion.
+func (h *SuggestionsAPIHandler) ListSuggestions(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = models.SuggestionStatusOpen
+	case "all":
+		status = ""
+	}
+
+	suggestions, err := h.suggestionsService.ListSuggestions(session.FamilyID, status)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list suggestions: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"suggestions": suggestions,
+		"count":       len(suggestions),
+	})
+}
+
+// RefreshSuggestions handles POST /api/v1/suggestions/refresh, running the
+// detectors now instead of waiting for the daily job
+func (h *SuggestionsAPIHandler) RefreshSuggestions(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	created, err := h.suggestionsService.GenerateSuggestions(session.FamilyID, time.Now())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to generate suggestions: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"created": created,
+	})
+}
+
+// GetDigest handles GET /api/v1/suggestions/digest. It returns the stored
+// weekly digest, or builds one for the current week if none has been sent yet.
+func (h *SuggestionsAPIHandler) GetDigest(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	digest, err := h.suggestionsService.LatestDigest(session.FamilyID)
+	if err != nil && err.Error() == "suggestion digest not found" {
+		digest, err = h.suggestionsService.BuildDigest(session.FamilyID, time.Now())
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get digest: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, digest)
+}
+
+// ApplySuggestion handles POST /api/v1/suggestions/{suggestionID}/apply
+func (h *SuggestionsAPIHandler) ApplySuggestion(w http.ResponseWriter, r *http.Request) {
+	h.resolveSuggestion(w, r, h.suggestionsService.ApplySuggestion, "Failed to apply suggestion")
+}
+
+// DismissSuggestion handles POST /api/v1/suggestions/{suggestionID}/dismiss
+func (h *SuggestionsAPIHandler) DismissSuggestion(w http.ResponseWriter, r *http.Request) {
+	h.resolveSuggestion(w, r, h.suggestionsService.DismissSuggestion, "Failed to dismiss suggestion")
+}
+
+func (h *SuggestionsAPIHandler) resolveSuggestion(w http.ResponseWriter, r *http.Request, resolve func(string) (*models.Suggestion, error), message string) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	suggestionID := r.PathValue("suggestionID")
+
+	suggestion, err := h.suggestionsService.GetSuggestion(suggestionID)
+	if err != nil || suggestion.FamilyID != session.FamilyID {
+		if err != nil && err.Error() != "suggestion not found" {
+			http.Error(w, "Failed to query suggestion", http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, "Suggestion not found", http.StatusNotFound)
+		return
+	}
+
+	resolved, err := resolve(suggestion.ID)
+	if err != nil {
+		var validationErrs validation.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			h.writeJSON(w, http.StatusConflict, map[string]any{
+				"error":   "suggestion_stale",
+				"details": validationErrs,
+			})
+			return
+		}
+		if strings.HasSuffix(err.Error(), "not found") {
+			http.Error(w, "The suggested item no longer exists", http.StatusConflict)
+			return
+		}
+		http.Error(w, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, resolved)
+}
+
+func (h *SuggestionsAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"famstack/internal/apierror"
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+)
+
+// SyncConflictsAPIHandler handles the synced events awaiting review under an
+// integration's manual conflict policy
+type SyncConflictsAPIHandler struct {
+	integrationsService *services.IntegrationsService
+	calendarService     *services.CalendarService
+}
+
+// NewSyncConflictsAPIHandler creates a new sync conflicts API handler
+func NewSyncConflictsAPIHandler(integrationsService *services.IntegrationsService, calendarService *services.CalendarService) *SyncConflictsAPIHandler {
+	return &SyncConflictsAPIHandler{
+		integrationsService: integrationsService,
+		calendarService:     calendarService,
+	}
+}
+
+// ListConflicts handles GET /api/v1/integrations/{integrationID}/conflicts
+func (h *SyncConflictsAPIHandler) ListConflicts(w http.ResponseWriter, r *http.Request) {
+	integration, ok := h.integrationFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	conflicts, err := h.calendarService.ListSyncConflicts(integration.FamilyID, integration.ID)
+	if err != nil {
+		apierror.Error(w, fmt.Sprintf("Failed to list sync conflicts: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"conflicts": conflicts,
+		"count":     len(conflicts),
+	}); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// ResolveConflict handles POST /api/v1/integrations/{integrationID}/conflicts/{conflictID}/resolve
+// with {"keep": "local"} or {"keep": "remote"}, and returns the event as it now is
+func (h *SyncConflictsAPIHandler) ResolveConflict(w http.ResponseWriter, r *http.Request) {
+	integration, ok := h.integrationFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ResolveSyncConflictRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	event, err := h.calendarService.ResolveSyncConflict(integration.FamilyID, r.PathValue("conflictID"), req.Keep)
+	if err != nil {
+		if apierror.WriteValidation(w, err) {
+			return
+		}
+		if err.Error() == "sync conflict not found" {
+			apierror.Error(w, "Sync conflict not found", http.StatusNotFound)
+			return
+		}
+		apierror.Error(w, fmt.Sprintf("Failed to resolve sync conflict: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(event); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// integrationFromPath returns the signed-in member's integration named in the
+// path, writing an error when there is none
+func (h *SyncConflictsAPIHandler) integrationFromPath(w http.ResponseWriter, r *http.Request) (*services.Integration, bool) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	integration, err := h.integrationsService.GetIntegration(r.PathValue("integrationID"))
+	if err != nil || integration.FamilyID != user.FamilyID {
+		apierror.Error(w, "Integration not found", http.StatusNotFound)
+		return nil, false
+	}
+	return integration, true
+}
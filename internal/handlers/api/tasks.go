@@ -2,44 +2,71 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"strings"
 	"time"
 
+	"famstack/internal/apierror"
 	"famstack/internal/auth"
 	"famstack/internal/models"
 	"famstack/internal/services"
+	"famstack/internal/validation"
 )
 
 // TaskAPIHandler handles JSON API requests for tasks
 type TaskAPIHandler struct {
-	tasksService *services.TasksService
+	tasksService       *services.TasksService
+	assignmentsService *services.AssignmentsService
+	analyticsService   *services.AnalyticsService
+	preferencesService *services.MemberPreferencesService
 }
 
 // NewTaskAPIHandler creates a new task API handler
-func NewTaskAPIHandler(tasksService *services.TasksService) *TaskAPIHandler {
-	return &TaskAPIHandler{tasksService: tasksService}
+func NewTaskAPIHandler(tasksService *services.TasksService, assignmentsService *services.AssignmentsService, analyticsService *services.AnalyticsService, preferencesService *services.MemberPreferencesService) *TaskAPIHandler {
+	return &TaskAPIHandler{
+		tasksService:       tasksService,
+		assignmentsService: assignmentsService,
+		analyticsService:   analyticsService,
+		preferencesService: preferencesService,
+	}
 }
 
+// assignmentLookahead is how far ahead the daily view shows open homework
+const assignmentLookahead = 7 * 24 * time.Hour
+
 // These types are now in services.TasksService, so we use those directly
 
-// ListTasks returns all tasks as JSON
+// ListTasks returns one day's tasks grouped by member as JSON, or with
+// ?limit= or ?cursor= a page of all the family's tasks, newest first
 func (h *TaskAPIHandler) ListTasks(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Get user from context
 	user := auth.GetUserFromContext(r.Context())
 	if user == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 
+	// With ?limit= or ?cursor=, page through every task in the family
+	// instead of grouping one day's by member
+	page, paged, ok := pageRequest(w, r)
+	if !ok {
+		return
+	}
+	if paged {
+		tasks, err := h.tasksService.ListTasksPage(user.FamilyID, page)
+		if err != nil {
+			writePageError(w, "tasks", err)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(tasks); err != nil {
+			apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+		return
+	}
+
 	// Get date parameter from query string, default to today
 	dateParam := r.URL.Query().Get("dueDate")
 	var dateFilter string
@@ -51,10 +78,16 @@ func (h *TaskAPIHandler) ListTasks(w http.ResponseWriter, r *http.Request) {
 		dateFilter = time.Now().Format("2006-01-02")
 	}
 
+	sortBy, err := models.ParseTaskSort(r.URL.Query().Get("sort"))
+	if err != nil {
+		apierror.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Use the service to get tasks by family
-	tasksResponse, err := h.tasksService.ListTasksByFamily(user.FamilyID, dateFilter)
+	tasksResponse, err := h.tasksService.ListTasksByFamily(user.FamilyID, dateFilter, sortBy)
 	if err != nil {
-		http.Error(w, "Failed to load tasks", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to load tasks", http.StatusInternalServerError)
 		return
 	}
 
@@ -64,31 +97,47 @@ func (h *TaskAPIHandler) ListTasks(w http.ResponseWriter, r *http.Request) {
 		"dueDate":         time.Now().Format("Monday, January 2"),
 	}
 
+	// The caller's preferences come along so every client renders tasks alike
+	if prefs := renderPreferences(r, h.preferencesService); prefs != nil {
+		response["preferences"] = prefs
+	}
+
+	// Open homework due within the next week (or already overdue), keyed by child
+	if h.assignmentsService != nil {
+		if day, parseErr := time.Parse("2006-01-02", dateFilter); parseErr == nil {
+			assignmentsDue, err := h.assignmentsService.ListOpenAssignmentsDue(user.FamilyID, day.Add(assignmentLookahead))
+			if err != nil {
+				apierror.Error(w, "Failed to load assignments", http.StatusInternalServerError)
+				return
+			}
+			response["assignments_by_member"] = assignmentsDue
+		}
+	}
+
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
 }
 
 // CreateTask creates a new task
 func (h *TaskAPIHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	w.Header().Set("Content-Type", "application/json")
 
-	var task models.Task
-	if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+	var body struct {
+		models.Task
+		DueTime *string `json:"due_time"` // HH:MM on due_date's day
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apierror.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
+	task := body.Task
 
 	// Get user from context
 	user := auth.GetUserFromContext(r.Context())
 	if user == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
@@ -99,13 +148,9 @@ func (h *TaskAPIHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		today := time.Now().Truncate(24 * time.Hour)
 		dueDate := task.DueDate.Truncate(24 * time.Hour)
 		if dueDate.Before(today) {
-			w.WriteHeader(http.StatusBadRequest)
-			if encErr := json.NewEncoder(w).Encode(map[string]any{
-				"error":   "Validation failed",
-				"details": "Cannot create tasks for past dates",
-			}); encErr != nil {
-				http.Error(w, "Failed to encode error response", http.StatusInternalServerError)
-			}
+			apierror.Validation(w, validation.ValidationErrors{
+				{Field: "due_date", Message: "Cannot create tasks for past dates"},
+			})
 			return
 		}
 	}
@@ -118,54 +163,45 @@ func (h *TaskAPIHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		AssignedTo:  task.AssignedTo,
 		Priority:    task.Priority,
 		DueDate:     task.DueDate,
+		DueTime:     body.DueTime,
 		Points:      0, // Default value since not provided in this API
 	}
+	if !validateRequest(w, createReq) {
+		return
+	}
 
 	// Use the service to create the task
 	createdTask, err := h.tasksService.CreateTask(user.FamilyID, user.ID, createReq)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		if encErr := json.NewEncoder(w).Encode(map[string]any{
-			"error":   "Database error",
-			"details": fmt.Sprintf("Failed to create task: %v", err),
-		}); encErr != nil {
-			http.Error(w, "Failed to encode error response", http.StatusInternalServerError)
-		}
+		apierror.Error(w, fmt.Sprintf("Failed to create task: %v", err), http.StatusInternalServerError)
 		return
 	}
 
+	// Usage counts are best effort and never fail the request
+	_ = h.analyticsService.Record(user.FamilyID, models.UsageMetricTaskCreated)
+
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(createdTask); err != nil {
-		http.Error(w, "Failed to encode task", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to encode task", http.StatusInternalServerError)
 		return
 	}
 }
 
 // UpdateTask updates a task (complete/reopen)
 func (h *TaskAPIHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "PATCH" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	w.Header().Set("Content-Type", "application/json")
 
-	// Extract task ID from URL path
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 5 {
-		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
-	taskID := pathParts[4]
-	if taskID == "" {
-		http.Error(w, "Invalid task ID", http.StatusBadRequest)
-		return
-	}
+	taskID := r.PathValue("taskID")
 
 	var updateData map[string]any
 	if err := json.NewDecoder(r.Body).Decode(&updateData); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		apierror.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
@@ -176,16 +212,15 @@ func (h *TaskAPIHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	if status, exists := updateData["status"]; exists {
 		statusStr, ok := status.(string)
 		if !ok {
-			http.Error(w, "Invalid status format", http.StatusBadRequest)
+			apierror.Error(w, "Invalid status format", http.StatusBadRequest)
 			return
 		}
-		switch statusStr {
-		case "completed", "pending":
-			updateReq.Status = &statusStr
-		default:
-			http.Error(w, "Invalid status", http.StatusBadRequest)
+		parsed, err := models.ParseTaskStatus(statusStr)
+		if err != nil {
+			apierror.Error(w, "Invalid status", http.StatusBadRequest)
 			return
 		}
+		updateReq.Status = &parsed
 	}
 
 	// Handle assignment updates
@@ -196,7 +231,7 @@ func (h *TaskAPIHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 		} else {
 			assignedToStr, ok := assignedTo.(string)
 			if !ok {
-				http.Error(w, "Invalid assigned_to format", http.StatusBadRequest)
+				apierror.Error(w, "Invalid assigned_to format", http.StatusBadRequest)
 				return
 			}
 			updateReq.AssignedTo = &assignedToStr
@@ -207,60 +242,66 @@ func (h *TaskAPIHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	if title, exists := updateData["title"]; exists {
 		titleStr, ok := title.(string)
 		if !ok {
-			http.Error(w, "Invalid title format", http.StatusBadRequest)
+			apierror.Error(w, "Invalid title format", http.StatusBadRequest)
 			return
 		}
 		if titleStr == "" {
-			http.Error(w, "Title cannot be empty", http.StatusBadRequest)
+			apierror.Error(w, "Title cannot be empty", http.StatusBadRequest)
 			return
 		}
 		updateReq.Title = &titleStr
 	}
 
+	// Handle due time updates; null or empty makes the task due any time on its day
+	if dueTime, exists := updateData["due_time"]; exists {
+		dueTimeStr := ""
+		if dueTime != nil {
+			var ok bool
+			if dueTimeStr, ok = dueTime.(string); !ok {
+				apierror.Error(w, "Invalid due_time format", http.StatusBadRequest)
+				return
+			}
+		}
+		updateReq.DueTime = &dueTimeStr
+	}
+
 	// Use the service to update the task
-	task, err := h.tasksService.UpdateTask(taskID, updateReq)
+	task, err := h.tasksService.UpdateTask(user.FamilyID, taskID, updateReq)
 	if err != nil {
-		if err.Error() == "task not found" {
-			http.Error(w, "Task not found", http.StatusNotFound)
+		if apierror.WriteValidation(w, err) {
+			return
+		}
+		if errors.Is(err, services.ErrNotFound) {
+			apierror.Error(w, "Task not found", http.StatusNotFound)
 		} else {
-			http.Error(w, fmt.Sprintf("Failed to update task: %v", err), http.StatusInternalServerError)
+			apierror.Error(w, fmt.Sprintf("Failed to update task: %v", err), http.StatusInternalServerError)
 		}
 		return
 	}
 
 	if err := json.NewEncoder(w).Encode(task); err != nil {
-		http.Error(w, "Failed to encode task", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to encode task", http.StatusInternalServerError)
 		return
 	}
 }
 
 // DeleteTask deletes a task
 func (h *TaskAPIHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "DELETE" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// Extract task ID from URL path
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 5 {
-		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
-	taskID := pathParts[4]
-	if taskID == "" {
-		http.Error(w, "Invalid task ID", http.StatusBadRequest)
-		return
-	}
+	taskID := r.PathValue("taskID")
 
 	// Use the service to delete the task
-	err := h.tasksService.DeleteTask(taskID)
+	err := h.tasksService.DeleteTask(user.FamilyID, taskID)
 	if err != nil {
-		if err.Error() == "task not found" {
-			http.Error(w, "Task not found", http.StatusNotFound)
+		if errors.Is(err, services.ErrNotFound) {
+			apierror.Error(w, "Task not found", http.StatusNotFound)
 		} else {
-			http.Error(w, fmt.Sprintf("Failed to delete task: %v", err), http.StatusInternalServerError)
+			apierror.Error(w, fmt.Sprintf("Failed to delete task: %v", err), http.StatusInternalServerError)
 		}
 		return
 	}
@@ -270,19 +311,25 @@ func (h *TaskAPIHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 
 // GetTask retrieves a single task
 func (h *TaskAPIHandler) GetTask(w http.ResponseWriter, r *http.Request, taskID string) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
 	// Use the service to get the task
-	task, err := h.tasksService.GetTask(taskID)
+	task, err := h.tasksService.GetFamilyTask(user.FamilyID, taskID)
 	if err != nil {
-		if err.Error() == "task not found" {
-			http.Error(w, "Task not found", http.StatusNotFound)
+		if errors.Is(err, services.ErrNotFound) {
+			apierror.Error(w, "Task not found", http.StatusNotFound)
 		} else {
-			http.Error(w, "Failed to get task", http.StatusInternalServerError)
+			apierror.Error(w, "Failed to get task", http.StatusInternalServerError)
 		}
 		return
 	}
 
 	if err := json.NewEncoder(w).Encode(task); err != nil {
-		http.Error(w, "Failed to encode task", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to encode task", http.StatusInternalServerError)
 		return
 	}
 }
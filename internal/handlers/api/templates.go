@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/templates"
+)
+
+// digestTemplateDays is how many days each digest template covers
+var digestTemplateDays = map[string]int{
+	models.DigestTemplateWeek:   7,
+	models.DigestTemplateAgenda: 1,
+}
+
+// TemplatesAPIHandler lets admins list the digest templates and preview them
+// against their own family's schedule
+type TemplatesAPIHandler struct {
+	renderer      *templates.Renderer
+	digestService *services.DigestService
+}
+
+// NewTemplatesAPIHandler creates a new templates API handler
+func NewTemplatesAPIHandler(renderer *templates.Renderer, digestService *services.DigestService) *TemplatesAPIHandler {
+	return &TemplatesAPIHandler{
+		renderer:      renderer,
+		digestService: digestService,
+	}
+}
+
+// ListTemplates handles GET /api/v1/admin/templates
+func (h *TemplatesAPIHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"templates": h.renderer.Templates(),
+		"locales":   h.renderer.Locales(),
+		"channels":  templates.Channels(),
+	})
+}
+
+// Preview handles GET /api/v1/admin/templates/preview?template=week&channel=text.
+// The locale comes from the locale parameter or the Accept-Language header, and
+// date (YYYY-MM-DD) defaults to today. With raw=true the rendered body is
+// returned as-is instead of wrapped in JSON.
+func (h *TemplatesAPIHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	name := query.Get("template")
+	days, ok := digestTemplateDays[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("Unknown template %q", name), http.StatusBadRequest)
+		return
+	}
+
+	channel := query.Get("channel")
+	if channel == "" {
+		channel = templates.ChannelEmailHTML
+	}
+	if !isKnownChannel(channel) {
+		http.Error(w, fmt.Sprintf("Unknown channel %q", channel), http.StatusBadRequest)
+		return
+	}
+
+	locale := query.Get("locale")
+	if locale == "" {
+		locale = preferredLocale(r.Header.Get("Accept-Language"))
+	}
+
+	date := time.Now()
+	if dateParam := query.Get("date"); dateParam != "" {
+		parsed, err := time.Parse("2006-01-02", dateParam)
+		if err != nil {
+			http.Error(w, "Invalid date format (expected YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		date = parsed
+	}
+
+	digest, err := h.digestService.BuildAgenda(session.FamilyID, date, days)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to build digest: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	rendered, err := h.renderer.Render(name, locale, channel, digest)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to render template: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if query.Get("raw") == "true" {
+		w.Header().Set("Content-Type", rendered.ContentType)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(rendered.Body))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, rendered)
+}
+
+func isKnownChannel(channel string) bool {
+	for _, known := range templates.Channels() {
+		if channel == known {
+			return true
+		}
+	}
+	return false
+}
+
+// preferredLocale returns the first language tag of an Accept-Language header,
+// ignoring quality values; the renderer handles any fallback
+func preferredLocale(header string) string {
+	first, _, _ := strings.Cut(header, ",")
+	tag, _, _ := strings.Cut(first, ";")
+	tag = strings.TrimSpace(tag)
+	if tag == "" || tag == "*" {
+		return templates.DefaultLocale
+	}
+	return tag
+}
+
+func (h *TemplatesAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
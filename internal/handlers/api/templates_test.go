@@ -0,0 +1,20 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreferredLocale(t *testing.T) {
+	tests := map[string]string{
+		"":                        "en",
+		"*":                       "en",
+		"es-MX,es;q=0.9,en;q=0.8": "es-MX",
+		" fr-CA ; q=1":            "fr-CA",
+		"de":                      "de",
+	}
+	for header, want := range tests {
+		assert.Equal(t, want, preferredLocale(header), "Accept-Language %q", header)
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+)
+
+// TimelineAPIHandler serves the wall display's horizontal day timeline
+type TimelineAPIHandler struct {
+	timelineService *services.TimelineService
+	// projections serves days inside the display window without a rebuild
+	projections *services.DisplayProjectionService
+}
+
+// NewTimelineAPIHandler creates a new timeline API handler
+func NewTimelineAPIHandler(timelineService *services.TimelineService, projections *services.DisplayProjectionService) *TimelineAPIHandler {
+	return &TimelineAPIHandler{
+		timelineService: timelineService,
+		projections:     projections,
+	}
+}
+
+// GetTimeline handles GET /api/v1/timeline?date=YYYY-MM-DD. It returns one lane
+// per member with events, task due times and routine slots in chronological
+// order, laid out with the same overlap layering as the calendar days view.
+func (h *TimelineAPIHandler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	date := time.Now()
+	if dateParam := r.URL.Query().Get("date"); dateParam != "" {
+		parsed, err := time.Parse("2006-01-02", dateParam)
+		if err != nil {
+			http.Error(w, "Invalid date format (expected YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		date = parsed
+	}
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	timeline, err := h.projectedTimeline(session.FamilyID, date)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to build timeline: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	for i := range timeline.Lanes {
+		h.layoutLane(&timeline.Lanes[i], timeline.Date)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(timeline); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// projectedTimeline serves the day from the display projection when the
+// window covers it, and builds it live otherwise
+func (h *TimelineAPIHandler) projectedTimeline(familyID string, date time.Time) (*models.Timeline, error) {
+	if h.projections != nil {
+		projection, err := h.projections.Get(familyID, time.Now())
+		if err == nil {
+			if timeline, ok := projection.Timeline(date.Format("2006-01-02")); ok {
+				return timeline, nil
+			}
+		}
+	}
+	return h.timelineService.BuildTimeline(familyID, date)
+}
+
+// layoutLane positions a lane's timed items in slots and assigns overlap layers.
+// Items spilling into the previous or next day are clipped to this day.
+func (h *TimelineAPIHandler) layoutLane(lane *models.TimelineLane, date string) {
+	if len(lane.Items) == 0 {
+		return
+	}
+
+	viewEvents := make([]models.CalendarViewEvent, 0, len(lane.Items))
+	for i := range lane.Items {
+		item := &lane.Items[i]
+
+		item.StartSlot = 0
+		if item.Start.Format("2006-01-02") == date {
+			item.StartSlot = services.TimeToSlot(item.Start)
+		}
+		item.EndSlot = services.SlotsPerDay
+		if item.End.Format("2006-01-02") == date {
+			item.EndSlot = services.TimeToSlot(item.End)
+		}
+		if item.EndSlot <= item.StartSlot {
+			item.EndSlot = item.StartSlot + 1
+		}
+
+		viewEvents = append(viewEvents, models.CalendarViewEvent{
+			ID:        item.ID,
+			StartSlot: item.StartSlot,
+			EndSlot:   item.EndSlot,
+		})
+	}
+
+	layers := services.LayoutViewEvents(viewEvents)
+	placed := make(map[string]models.CalendarViewEvent, len(viewEvents))
+	for _, layer := range layers {
+		for _, event := range layer.Events {
+			placed[event.ID] = event
+		}
+	}
+
+	for i := range lane.Items {
+		event := placed[lane.Items[i].ID]
+		lane.Items[i].OverlapGroup = event.OverlapGroup
+		lane.Items[i].OverlapIndex = event.OverlapIndex
+	}
+	lane.LayerCount = len(layers)
+}
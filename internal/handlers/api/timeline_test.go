@@ -0,0 +1,68 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+	"famstack/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func timelineItem(id, start, end string) models.TimelineItem {
+	parse := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			panic(err)
+		}
+		return parsed
+	}
+	return models.TimelineItem{ID: id, Start: parse(start), End: parse(end)}
+}
+
+func TestTimelineLayoutLane_ReusesCalendarLayering(t *testing.T) {
+	handler := NewTimelineAPIHandler(nil, nil)
+	lane := &models.TimelineLane{
+		Items: []models.TimelineItem{
+			timelineItem("event:practice", "2026-03-10 17:00", "2026-03-10 18:30"),
+			timelineItem("task:homework", "2026-03-10 17:30", "2026-03-10 17:45"),
+			timelineItem("routine:dinner", "2026-03-10 19:00", "2026-03-10 19:15"),
+		},
+	}
+
+	handler.layoutLane(lane, "2026-03-10")
+
+	assert.Equal(t, 2, lane.LayerCount)
+	assert.Equal(t, 68, lane.Items[0].StartSlot)
+	assert.Equal(t, 74, lane.Items[0].EndSlot)
+
+	assert.Equal(t, 2, lane.Items[0].OverlapGroup)
+	assert.Equal(t, 0, lane.Items[0].OverlapIndex)
+	assert.Equal(t, 2, lane.Items[1].OverlapGroup)
+	assert.Equal(t, 1, lane.Items[1].OverlapIndex)
+
+	// Dinner overlaps nothing and sits alone in the first layer
+	assert.Equal(t, 1, lane.Items[2].OverlapGroup)
+	assert.Equal(t, 0, lane.Items[2].OverlapIndex)
+}
+
+func TestTimelineLayoutLane_ClipsItemsToTheDay(t *testing.T) {
+	handler := NewTimelineAPIHandler(nil, nil)
+	lane := &models.TimelineLane{
+		Items: []models.TimelineItem{
+			timelineItem("event:sleepover", "2026-03-09 20:00", "2026-03-10 09:00"),
+			timelineItem("event:late-flight", "2026-03-10 22:00", "2026-03-11 01:00"),
+		},
+	}
+
+	handler.layoutLane(lane, "2026-03-10")
+
+	require.Len(t, lane.Items, 2)
+	assert.Equal(t, 0, lane.Items[0].StartSlot)
+	assert.Equal(t, 36, lane.Items[0].EndSlot)
+	assert.Equal(t, 88, lane.Items[1].StartSlot)
+	assert.Equal(t, services.SlotsPerDay, lane.Items[1].EndSlot)
+	assert.Equal(t, 1, lane.LayerCount)
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// UsageAPIHandler handles storage usage and quota API requests
+type UsageAPIHandler struct {
+	storageService *services.StorageService
+}
+
+// NewUsageAPIHandler creates a new usage API handler
+func NewUsageAPIHandler(storageService *services.StorageService) *UsageAPIHandler {
+	return &UsageAPIHandler{
+		storageService: storageService,
+	}
+}
+
+// GetUsage handles GET /api/v1/usage
+func (h *UsageAPIHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	usage, err := h.storageService.GetUsage(session.FamilyID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get storage usage: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, usage)
+}
+
+// UpdateQuota handles PUT /api/v1/usage/quota
+func (h *UsageAPIHandler) UpdateQuota(w http.ResponseWriter, r *http.Request) {
+	session := auth.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.UpdateStorageQuotaRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	quota, err := h.storageService.UpdateQuota(session.FamilyID, &req)
+	if err != nil {
+		var validationErrs validation.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			h.writeJSON(w, http.StatusBadRequest, map[string]any{
+				"error":   "validation_failed",
+				"details": validationErrs,
+			})
+			return
+		}
+		http.Error(w, fmt.Sprintf("Failed to update storage quota: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, quota)
+}
+
+// WriteQuotaError writes a 413 with the quota error code when err is a
+// *models.QuotaError, for upload handlers. It reports whether it wrote a response.
+func WriteQuotaError(w http.ResponseWriter, err error) bool {
+	var quotaErr *models.QuotaError
+	if !errors.As(err, &quotaErr) {
+		return false
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	if err := json.NewEncoder(w).Encode(quotaErr); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+	return true
+}
+
+func (h *UsageAPIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"famstack/internal/apierror"
+	"famstack/internal/models"
+	"famstack/internal/services"
+)
+
+// WebhooksAPIHandler takes triggers from IFTTT and Zapier. Callers have no
+// session: the inbound token in the URL says which integration they are,
+// and whatever they create is created by the member who added it.
+type WebhooksAPIHandler struct {
+	registry *services.Registry
+}
+
+// NewWebhooksAPIHandler creates a new webhooks API handler
+func NewWebhooksAPIHandler(registry *services.Registry) *WebhooksAPIHandler {
+	return &WebhooksAPIHandler{registry: registry}
+}
+
+// HandleTrigger handles POST /api/v1/webhooks/{integrationID}/{token}/tasks,
+// which takes a task like POST /api/v1/tasks, and
+// POST /api/v1/webhooks/{integrationID}/{token}/events, which takes a
+// calendar event
+func (h *WebhooksAPIHandler) HandleTrigger(w http.ResponseWriter, r *http.Request) {
+	kind := r.PathValue("kind")
+	integration, err := h.registry.Integrations.VerifyInboundToken(r.PathValue("integrationID"), r.PathValue("token"))
+	if err != nil {
+		apierror.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	startedAt := time.Now()
+	var created any
+	switch kind {
+	case "tasks":
+		var req models.CreateTaskRequest
+		if !decodeRequest(w, r, &req) {
+			return
+		}
+		if req.AssignedTo != nil && *req.AssignedTo != "" {
+			member, err := h.registry.FamilyMembers.GetFamilyMember(*req.AssignedTo)
+			if err != nil || member.FamilyID != integration.FamilyID {
+				apierror.Error(w, "Assignee not found", http.StatusBadRequest)
+				return
+			}
+		}
+		created, err = h.registry.Tasks.CreateTask(integration.FamilyID, integration.CreatedBy, &req)
+	case "events":
+		var req models.CreateCalendarEventRequest
+		if !decodeRequest(w, r, &req) {
+			return
+		}
+		created, err = h.registry.Calendar.CreateEvent(integration.FamilyID, integration.CreatedBy, &req)
+	default:
+		apierror.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	_ = h.registry.Integrations.RecordWebhook(integration.ID, startedAt, err) // nolint:errcheck // history is best effort
+	if err != nil {
+		apierror.Error(w, fmt.Sprintf("Failed to create %s: %v", strings.TrimSuffix(kind, "s"), err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(created); err != nil {
+		apierror.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
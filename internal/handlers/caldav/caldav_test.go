@@ -0,0 +1,223 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"famstack/internal/ical"
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeParseRoundTrip(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	description := "Bring snacks; and chairs,\nplease"
+	event := models.UnifiedCalendarEvent{
+		ID:          "evt-1",
+		Title:       "Soccer, practice",
+		Description: &description,
+		StartTime:   time.Date(2025, 3, 8, 14, 0, 0, 0, time.UTC),
+		EndTime:     time.Date(2025, 3, 8, 15, 30, 0, 0, time.UTC),
+		Status:      models.UnifiedEventStatusActive,
+		UpdatedAt:   time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC),
+	}
+
+	data := encodeCalendar([]models.UnifiedCalendarEvent{event}, loc)
+	assert.Contains(t, data, "DTSTART:20250308T140000Z\r\n")
+	assert.Contains(t, data, `SUMMARY:Soccer\, practice`)
+
+	parsed, err := parseEvent(data, loc)
+	require.NoError(t, err)
+	assert.Equal(t, "evt-1", parsed.UID)
+	assert.Equal(t, event.Title, parsed.Title)
+	assert.Equal(t, description, parsed.Description)
+	assert.True(t, parsed.Start.Equal(event.StartTime))
+	assert.True(t, parsed.End.Equal(event.EndTime))
+	assert.False(t, parsed.AllDay)
+	assert.False(t, parsed.Cancelled)
+}
+
+func TestEncodeAllDay(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	event := models.UnifiedCalendarEvent{
+		ID:        "evt-2",
+		Title:     "Holiday",
+		StartTime: time.Date(2025, 7, 4, 0, 0, 0, 0, loc),
+		EndTime:   time.Date(2025, 7, 4, 23, 59, 59, 0, loc),
+		AllDay:    true,
+	}
+
+	data := encodeCalendar([]models.UnifiedCalendarEvent{event}, loc)
+	assert.Contains(t, data, "DTSTART;VALUE=DATE:20250704\r\n")
+	assert.Contains(t, data, "DTEND;VALUE=DATE:20250705\r\n")
+}
+
+func TestEncodeParseRecurring(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	rule := "FREQ=WEEKLY;BYDAY=WE"
+	event := models.UnifiedCalendarEvent{
+		ID:                "evt-3",
+		Title:             "Piano",
+		StartTime:         time.Date(2025, 3, 5, 17, 0, 0, 0, loc),
+		EndTime:           time.Date(2025, 3, 5, 18, 0, 0, 0, loc),
+		RecurrenceRule:    &rule,
+		RecurrenceExdates: "20250319T210000Z",
+	}
+
+	data := encodeCalendar([]models.UnifiedCalendarEvent{event}, loc)
+	assert.Contains(t, data, "DTSTART;TZID=America/New_York:20250305T170000\r\n")
+	assert.Contains(t, data, "RRULE:FREQ=WEEKLY;BYDAY=WE\r\n")
+	assert.Contains(t, data, "EXDATE;TZID=America/New_York:20250319T170000\r\n")
+
+	parsed, err := parseEvent(data, loc)
+	require.NoError(t, err)
+	assert.Equal(t, rule, parsed.RecurrenceRule)
+	assert.True(t, parsed.Start.Equal(event.StartTime))
+	require.Len(t, parsed.ExceptionDates, 1)
+	assert.Equal(t, time.Date(2025, 3, 19, 21, 0, 0, 0, time.UTC), parsed.ExceptionDates[0].UTC())
+}
+
+func TestWriteLineFolds(t *testing.T) {
+	var b strings.Builder
+	writeLine(&b, "SUMMARY:"+strings.Repeat("é", 60))
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.LessOrEqual(t, len(line), icalLineLimit+1)
+		assert.True(t, strings.ToValidUTF8(line, "?") == line, "line split a UTF-8 sequence")
+	}
+
+	unfolded, err := ical.Unfold(b.String())
+	require.NoError(t, err)
+	require.Len(t, unfolded, 1)
+	assert.Equal(t, strings.Repeat("é", 60), unfolded[0].Value)
+}
+
+func TestParseEvent(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	wrap := func(lines ...string) string {
+		return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + strings.Join(lines, "\r\n") + "\r\nEND:VCALENDAR\r\n"
+	}
+
+	t.Run("duration and TZID", func(t *testing.T) {
+		parsed, err := parseEvent(wrap(
+			"BEGIN:VEVENT",
+			"UID:abc@example.com",
+			`DTSTART;TZID="America/Chicago":20250310T090000`,
+			"DURATION:PT1H30M",
+			"SUMMARY:Dentist",
+			"BEGIN:VALARM",
+			"SUMMARY:Reminder",
+			"TRIGGER:-PT15M",
+			"END:VALARM",
+			"STATUS:CANCELLED",
+			"END:VEVENT",
+		), loc)
+		require.NoError(t, err)
+		assert.Equal(t, "Dentist", parsed.Title)
+		assert.True(t, parsed.Cancelled)
+		assert.Equal(t, time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC), parsed.Start.UTC())
+		assert.Equal(t, 90*time.Minute, parsed.End.Sub(parsed.Start))
+	})
+
+	t.Run("floating time and all-day default end", func(t *testing.T) {
+		parsed, err := parseEvent(wrap("BEGIN:VEVENT", "UID:a", "DTSTART:20250310T090000", "END:VEVENT"), loc)
+		require.NoError(t, err)
+		assert.Equal(t, loc, parsed.Start.Location())
+		assert.True(t, parsed.End.Equal(parsed.Start))
+
+		parsed, err = parseEvent(wrap("BEGIN:VEVENT", "UID:b", "DTSTART;VALUE=DATE:20250310", "END:VEVENT"), loc)
+		require.NoError(t, err)
+		assert.True(t, parsed.AllDay)
+		assert.Equal(t, parsed.Start.AddDate(0, 0, 1), parsed.End)
+	})
+
+	errorCases := map[string]string{
+		"no VEVENT":  wrap("BEGIN:VTODO", "UID:a", "END:VTODO"),
+		"no UID":     wrap("BEGIN:VEVENT", "DTSTART:20250310T090000Z", "END:VEVENT"),
+		"no DTSTART": wrap("BEGIN:VEVENT", "UID:a", "END:VEVENT"),
+		"overridden": wrap("BEGIN:VEVENT", "UID:a", "DTSTART:20250310T090000Z", "RECURRENCE-ID:20250317T090000Z", "END:VEVENT"),
+		"rdate":      wrap("BEGIN:VEVENT", "UID:a", "DTSTART:20250310T090000Z", "RDATE:20250311T090000Z", "END:VEVENT"),
+		"two events": wrap("BEGIN:VEVENT", "UID:a", "END:VEVENT", "BEGIN:VEVENT", "UID:b", "END:VEVENT"),
+		"malformed":  wrap("BEGIN:VEVENT", "UID", "END:VEVENT"),
+	}
+	for name, data := range errorCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseEvent(data, loc)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	cases := map[string]resource{
+		"/caldav":                            {kind: kindRoot},
+		"/caldav/":                           {kind: kindRoot},
+		"/caldav/principals/m1/":             {kind: kindPrincipal, memberID: "m1"},
+		"/caldav/calendars/":                 {kind: kindHome},
+		"/caldav/calendars/family/":          {kind: kindCalendar, calendarID: "family"},
+		"/caldav/calendars/m1/evt-1.ics":     {kind: kindEvent, calendarID: "m1", eventID: "evt-1"},
+		"/caldav/calendars/m1/abc@host.ics/": {kind: kindEvent, calendarID: "m1", eventID: "abc@host"},
+	}
+	for path, want := range cases {
+		got, ok := parsePath(path)
+		assert.True(t, ok, path)
+		assert.Equal(t, want, got, path)
+	}
+
+	for _, path := range []string{"/caldav/other/", "/caldav/calendars/m1/evt-1", "/caldav/calendars/m1/.ics"} {
+		_, ok := parsePath(path)
+		assert.False(t, ok, path)
+	}
+}
+
+func TestReportRequest(t *testing.T) {
+	body := `<?xml version="1.0" encoding="utf-8"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop><D:getetag/><C:calendar-data/></D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VEVENT">
+        <C:time-range start="20250301T000000Z" end="20250401T000000Z"/>
+      </C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`
+
+	var req reportRequest
+	found, err := decodeBody(strings.NewReader(body), &req)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, xml.Name{Space: nsCalDAV, Local: "calendar-query"}, req.XMLName)
+	assert.Equal(t, []xml.Name{propGetETag, propCalendarData}, req.Prop.names())
+
+	start, end, err := req.Filter.eventRange()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), end)
+}
+
+func TestSelectProps(t *testing.T) {
+	props := propValues{propGetETag: `"1"`, propCalendarData: "BEGIN:VCALENDAR"}
+
+	all := selectProps("/e.ics", props, nil)
+	assert.Equal(t, propValues{propGetETag: `"1"`}, all.found)
+	assert.Empty(t, all.absent)
+
+	some := selectProps("/e.ics", props, []xml.Name{propCalendarData, propDisplayName})
+	assert.Equal(t, propValues{propCalendarData: "BEGIN:VCALENDAR"}, some.found)
+	assert.Equal(t, []xml.Name{propDisplayName}, some.absent)
+}
@@ -0,0 +1,698 @@
+// Package caldav serves each family's unified calendar over CalDAV (RFC 4791)
+// so Apple Calendar, Thunderbird and other clients can subscribe to and edit
+// events.
+//
+// The layout under Prefix is:
+//
+//	/caldav/                               service root
+//	/caldav/principals/{member_id}/        the signed-in member
+//	/caldav/calendars/                     calendar home
+//	/caldav/calendars/family/              events with no attendees
+//	/caldav/calendars/{member_id}/         events a member attends
+//	/caldav/calendars/{calendar}/{id}.ics  one event
+//
+// An event attended by several members appears in each of their calendars
+// under the same name. Recurring events are not supported.
+package caldav
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+	"famstack/internal/validation"
+)
+
+// Prefix is the path the CalDAV tree is served under
+const Prefix = "/caldav/"
+
+// FamilyCalendarID names the calendar of events that have no attendees
+const FamilyCalendarID = "family"
+
+const (
+	icsContentType = "text/calendar; charset=utf-8"
+	icsExtension   = ".ics"
+	maxEventBytes  = 256 << 10
+)
+
+// allEventsFrom and allEventsUntil bound "every event" when listing a calendar
+var (
+	allEventsFrom  = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	allEventsUntil = time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// resourceKind is the kind of thing a CalDAV path names
+type resourceKind int
+
+const (
+	kindRoot resourceKind = iota
+	kindPrincipal
+	kindHome
+	kindCalendar
+	kindEvent
+)
+
+type resource struct {
+	kind       resourceKind
+	memberID   string // principal
+	calendarID string // calendar and event
+	eventID    string // event
+}
+
+// parsePath maps a request path onto a resource
+func parsePath(path string) (resource, bool) {
+	rest, ok := strings.CutPrefix(path, Prefix)
+	if !ok {
+		if path+"/" == Prefix {
+			return resource{kind: kindRoot}, true
+		}
+		return resource{}, false
+	}
+
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	switch {
+	case rest == "" || rest == "/":
+		return resource{kind: kindRoot}, true
+	case parts[0] == "principals" && len(parts) == 2:
+		return resource{kind: kindPrincipal, memberID: parts[1]}, true
+	case parts[0] == "calendars" && len(parts) == 1:
+		return resource{kind: kindHome}, true
+	case parts[0] == "calendars" && len(parts) == 2:
+		return resource{kind: kindCalendar, calendarID: parts[1]}, true
+	case parts[0] == "calendars" && len(parts) == 3 && strings.HasSuffix(parts[2], icsExtension):
+		eventID := strings.TrimSuffix(parts[2], icsExtension)
+		if eventID == "" {
+			return resource{}, false
+		}
+		return resource{kind: kindEvent, calendarID: parts[1], eventID: eventID}, true
+	}
+	return resource{}, false
+}
+
+func principalPath(memberID string) string { return Prefix + "principals/" + memberID + "/" }
+func homePath() string                     { return Prefix + "calendars/" }
+func calendarPath(calendarID string) string {
+	return Prefix + "calendars/" + calendarID + "/"
+}
+func eventPath(calendarID, eventID string) string {
+	return calendarPath(calendarID) + eventID + icsExtension
+}
+
+// Handler serves the CalDAV tree. It expects the session and user that
+// auth.Middleware.RequireBasicAuth puts in the request context.
+type Handler struct {
+	calendarService *services.CalendarService
+	familiesService *services.FamiliesService
+	membersService  *services.FamilyMemberService
+}
+
+// NewHandler creates a new CalDAV handler
+func NewHandler(calendarService *services.CalendarService, familiesService *services.FamiliesService, membersService *services.FamilyMemberService) *Handler {
+	return &Handler{
+		calendarService: calendarService,
+		familiesService: familiesService,
+		membersService:  membersService,
+	}
+}
+
+// requestContext is what every CalDAV method needs to know about the caller
+type requestContext struct {
+	session   *auth.Session
+	user      *models.FamilyMember
+	family    *models.Family
+	loc       *time.Location
+	calendars []calendarInfo
+	authz     *auth.AuthorizationService
+}
+
+type calendarInfo struct {
+	id    string
+	name  string
+	color string
+}
+
+func (rc *requestContext) calendar(id string) (calendarInfo, bool) {
+	for _, cal := range rc.calendars {
+		if cal.id == id {
+			return cal, true
+		}
+	}
+	return calendarInfo{}, false
+}
+
+// viewer is who the caller's events are shown to
+func (rc *requestContext) viewer() *models.CalendarViewer {
+	return auth.CalendarViewerFor(rc.session, rc.user)
+}
+
+// ServeHTTP dispatches on the WebDAV method
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("DAV", "1, 3, calendar-access")
+
+	if r.Method == "OPTIONS" {
+		w.Header().Set("Allow", "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	res, ok := parsePath(r.URL.Path)
+	if !ok {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	rc, err := h.newRequestContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if !rc.authz.HasPermission(auth.EntityCalendar, auth.ActionRead, nil) {
+		http.Error(w, "Insufficient permissions", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case "PROPFIND":
+		h.propfind(w, r, rc, res)
+	case "REPORT":
+		h.report(w, r, rc, res)
+	case "GET", "HEAD":
+		h.get(w, r, rc, res)
+	case "PUT":
+		h.put(w, r, rc, res)
+	case "DELETE":
+		h.delete(w, r, rc, res)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) newRequestContext(r *http.Request) (*requestContext, error) {
+	session := auth.GetSessionFromContext(r.Context())
+	user := auth.GetUserFromContext(r.Context())
+	if session == nil || user == nil {
+		return nil, fmt.Errorf("Authentication required")
+	}
+
+	family, err := h.familiesService.GetFamily(session.FamilyID)
+	if err != nil {
+		return nil, fmt.Errorf("Family not found")
+	}
+	loc, err := time.LoadLocation(family.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	members, err := h.membersService.ListFamilyMembers(session.FamilyID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list family members")
+	}
+	calendars := []calendarInfo{{id: FamilyCalendarID, name: family.Name, color: "#3b82f6"}}
+	for _, member := range members {
+		if member.IsActive {
+			calendars = append(calendars, calendarInfo{id: member.ID, name: member.DisplayName(), color: member.Color})
+		}
+	}
+
+	return &requestContext{
+		session:   session,
+		user:      user,
+		family:    family,
+		loc:       loc,
+		calendars: calendars,
+		authz:     auth.NewAuthorizationService(session),
+	}, nil
+}
+
+// inCalendar reports whether an event belongs to a calendar
+func inCalendar(event *models.UnifiedCalendarEvent, calendarID string) bool {
+	if calendarID == FamilyCalendarID {
+		return len(event.Attendees) == 0
+	}
+	for _, attendee := range event.Attendees {
+		if attendee.ID == calendarID {
+			return true
+		}
+	}
+	return false
+}
+
+// calendarEvents lists a calendar's events overlapping [start, end).
+// Recurring events are listed once, as a series, the way clients store them.
+func (h *Handler) calendarEvents(rc *requestContext, calendarID string, start, end time.Time) ([]models.UnifiedCalendarEvent, error) {
+	if start.IsZero() {
+		start = allEventsFrom
+	}
+	if end.IsZero() {
+		end = allEventsUntil
+	}
+
+	events, err := h.calendarService.GetStoredUnifiedCalendarEvents(rc.family.ID, start.In(rc.loc), end.In(rc.loc))
+	if err != nil {
+		return nil, err
+	}
+	events, err = h.calendarService.ApplyEventPrivacy(rc.viewer(), rc.family.ID, events)
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]models.UnifiedCalendarEvent, 0, len(events))
+	for i := range events {
+		if inCalendar(&events[i], calendarID) {
+			filtered = append(filtered, events[i])
+		}
+	}
+	return filtered, nil
+}
+
+// findEvent returns the family's event if it is in the calendar
+func (h *Handler) findEvent(rc *requestContext, calendarID, eventID string) (*models.UnifiedCalendarEvent, error) {
+	event, err := h.calendarService.GetStoredUnifiedCalendarEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.FamilyID != rc.family.ID || !inCalendar(event, calendarID) {
+		return nil, fmt.Errorf("unified calendar event not found")
+	}
+	visible, err := h.calendarService.ApplyEventPrivacy(rc.viewer(), rc.family.ID, []models.UnifiedCalendarEvent{*event})
+	if err != nil {
+		return nil, err
+	}
+	if len(visible) == 0 {
+		return nil, fmt.Errorf("unified calendar event not found")
+	}
+	return &visible[0], nil
+}
+
+func etag(event *models.UnifiedCalendarEvent) string {
+	return `"` + strconv.FormatInt(event.UpdatedAt.UnixNano(), 36) + `"`
+}
+
+// ctag changes whenever an event in the calendar is added, changed or removed
+func ctag(events []models.UnifiedCalendarEvent) string {
+	var latest time.Time
+	for _, event := range events {
+		if event.UpdatedAt.After(latest) {
+			latest = event.UpdatedAt
+		}
+	}
+	return fmt.Sprintf("%d-%s", len(events), strconv.FormatInt(latest.UnixNano(), 36))
+}
+
+// privilegesXML lists what the caller may do with a calendar's events
+func privilegesXML(rc *requestContext) string {
+	privileges := "<D:privilege><D:read/></D:privilege>"
+	if rc.authz.HasPermission(auth.EntityCalendar, auth.ActionCreate, nil) {
+		privileges += "<D:privilege><D:bind/></D:privilege><D:privilege><D:write-content/></D:privilege>"
+	}
+	if rc.authz.HasPermission(auth.EntityCalendar, auth.ActionDelete, &rc.session.UserID) {
+		privileges += "<D:privilege><D:unbind/></D:privilege>"
+	}
+	return privileges
+}
+
+func (h *Handler) principalProps(rc *requestContext) propValues {
+	props := propValues{
+		propResourceType:         "<D:principal/>",
+		propDisplayName:          escapeXML(rc.user.DisplayName()),
+		propCurrentUserPrincipal: hrefXML(principalPath(rc.user.ID)),
+		propPrincipalURL:         hrefXML(principalPath(rc.user.ID)),
+		propCalendarHomeSet:      hrefXML(homePath()),
+	}
+	if rc.user.Email != nil && *rc.user.Email != "" {
+		props[propCalendarUserAddresses] = hrefXML("mailto:" + *rc.user.Email)
+	}
+	return props
+}
+
+func (h *Handler) calendarProps(rc *requestContext, cal calendarInfo, events []models.UnifiedCalendarEvent) propValues {
+	return propValues{
+		propResourceType:          "<D:collection/><C:calendar/>",
+		propDisplayName:           escapeXML(cal.name),
+		propCurrentUserPrincipal:  hrefXML(principalPath(rc.user.ID)),
+		propCurrentUserPrivileges: privilegesXML(rc),
+		propSupportedComponents:   `<C:comp name="VEVENT"/>`,
+		propSupportedReportSet: "<D:supported-report><D:report><C:calendar-query/></D:report></D:supported-report>" +
+			"<D:supported-report><D:report><C:calendar-multiget/></D:report></D:supported-report>",
+		propGetCTag:       escapeXML(ctag(events)),
+		propCalendarColor: escapeXML(cal.color),
+	}
+}
+
+func (h *Handler) eventProps(rc *requestContext, event *models.UnifiedCalendarEvent) propValues {
+	return propValues{
+		propResourceType:    "",
+		propGetETag:         escapeXML(etag(event)),
+		propGetContentType:  escapeXML(icsContentType + "; component=VEVENT"),
+		propGetLastModified: event.UpdatedAt.UTC().Format(http.TimeFormat),
+		propCalendarData:    escapeXML(encodeCalendar([]models.UnifiedCalendarEvent{*event}, rc.loc)),
+	}
+}
+
+// propfind handles PROPFIND at depth 0 or 1; infinity is treated as 1
+func (h *Handler) propfind(w http.ResponseWriter, r *http.Request, rc *requestContext, res resource) {
+	var req propfindRequest
+	if _, err := decodeBody(http.MaxBytesReader(w, r.Body, maxEventBytes), &req); err != nil {
+		http.Error(w, "Invalid PROPFIND body", http.StatusBadRequest)
+		return
+	}
+	var names []xml.Name
+	if req.Prop != nil {
+		names = req.Prop.names()
+	}
+	children := r.Header.Get("Depth") != "0"
+
+	var responses []response
+	switch res.kind {
+	case kindRoot:
+		responses = append(responses, selectProps(Prefix, propValues{
+			propResourceType:         "<D:collection/>",
+			propDisplayName:          "FamStack",
+			propCurrentUserPrincipal: hrefXML(principalPath(rc.user.ID)),
+		}, names))
+
+	case kindPrincipal:
+		if res.memberID != rc.user.ID {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		responses = append(responses, selectProps(principalPath(rc.user.ID), h.principalProps(rc), names))
+
+	case kindHome:
+		responses = append(responses, selectProps(homePath(), propValues{
+			propResourceType:         "<D:collection/>",
+			propDisplayName:          escapeXML(rc.family.Name),
+			propCurrentUserPrincipal: hrefXML(principalPath(rc.user.ID)),
+		}, names))
+		if children {
+			for _, cal := range rc.calendars {
+				events, err := h.calendarEvents(rc, cal.id, time.Time{}, time.Time{})
+				if err != nil {
+					http.Error(w, fmt.Sprintf("Failed to list events: %v", err), http.StatusInternalServerError)
+					return
+				}
+				responses = append(responses, selectProps(calendarPath(cal.id), h.calendarProps(rc, cal, events), names))
+			}
+		}
+
+	case kindCalendar:
+		cal, ok := rc.calendar(res.calendarID)
+		if !ok {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		events, err := h.calendarEvents(rc, cal.id, time.Time{}, time.Time{})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to list events: %v", err), http.StatusInternalServerError)
+			return
+		}
+		responses = append(responses, selectProps(calendarPath(cal.id), h.calendarProps(rc, cal, events), names))
+		if children {
+			for i := range events {
+				responses = append(responses, selectProps(eventPath(cal.id, events[i].ID), h.eventProps(rc, &events[i]), names))
+			}
+		}
+
+	case kindEvent:
+		event, err := h.findEvent(rc, res.calendarID, res.eventID)
+		if err != nil {
+			h.writeEventError(w, err)
+			return
+		}
+		responses = append(responses, selectProps(eventPath(res.calendarID, event.ID), h.eventProps(rc, event), names))
+	}
+
+	writeMultistatus(w, responses)
+}
+
+// report handles calendar-query and calendar-multiget on a calendar
+func (h *Handler) report(w http.ResponseWriter, r *http.Request, rc *requestContext, res resource) {
+	cal, ok := rc.calendar(res.calendarID)
+	if res.kind != kindCalendar || !ok {
+		http.Error(w, "Reports are only supported on calendars", http.StatusForbidden)
+		return
+	}
+
+	var req reportRequest
+	if found, err := decodeBody(http.MaxBytesReader(w, r.Body, maxEventBytes), &req); err != nil || !found {
+		http.Error(w, "Invalid REPORT body", http.StatusBadRequest)
+		return
+	}
+	names := req.Prop.names()
+
+	var responses []response
+	switch {
+	case req.XMLName.Space == nsCalDAV && req.XMLName.Local == "calendar-query":
+		start, end, err := req.Filter.eventRange()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		events, err := h.calendarEvents(rc, cal.id, start, end)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to list events: %v", err), http.StatusInternalServerError)
+			return
+		}
+		for i := range events {
+			responses = append(responses, selectProps(eventPath(cal.id, events[i].ID), h.eventProps(rc, &events[i]), names))
+		}
+
+	case req.XMLName.Space == nsCalDAV && req.XMLName.Local == "calendar-multiget":
+		for _, href := range req.Hrefs {
+			target, ok := parsePath(href)
+			if !ok || target.kind != kindEvent || target.calendarID != cal.id {
+				responses = append(responses, response{href: href, absent: names})
+				continue
+			}
+			event, err := h.findEvent(rc, cal.id, target.eventID)
+			if err != nil {
+				responses = append(responses, response{href: href, absent: names})
+				continue
+			}
+			responses = append(responses, selectProps(href, h.eventProps(rc, event), names))
+		}
+
+	default:
+		http.Error(w, fmt.Sprintf("Unsupported report %s", req.XMLName.Local), http.StatusForbidden)
+		return
+	}
+
+	writeMultistatus(w, responses)
+}
+
+// get returns one event, or a whole calendar for clients that subscribe to a
+// read-only .ics feed
+func (h *Handler) get(w http.ResponseWriter, r *http.Request, rc *requestContext, res resource) {
+	var body string
+	switch res.kind {
+	case kindCalendar:
+		if _, ok := rc.calendar(res.calendarID); !ok {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		events, err := h.calendarEvents(rc, res.calendarID, time.Time{}, time.Time{})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to list events: %v", err), http.StatusInternalServerError)
+			return
+		}
+		body = encodeCalendar(events, rc.loc)
+
+	case kindEvent:
+		event, err := h.findEvent(rc, res.calendarID, res.eventID)
+		if err != nil {
+			h.writeEventError(w, err)
+			return
+		}
+		w.Header().Set("ETag", etag(event))
+		w.Header().Set("Last-Modified", event.UpdatedAt.UTC().Format(http.TimeFormat))
+		body = encodeCalendar([]models.UnifiedCalendarEvent{*event}, rc.loc)
+
+	default:
+		http.Error(w, "Not a calendar resource", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", icsContentType)
+	w.WriteHeader(http.StatusOK)
+	if r.Method != "HEAD" {
+		_, _ = io.WriteString(w, body)
+	}
+}
+
+// put creates or replaces an event. The server rewrites what it stores, so
+// no ETag is returned and clients fetch the event again (RFC 4791 5.3.4).
+func (h *Handler) put(w http.ResponseWriter, r *http.Request, rc *requestContext, res resource) {
+	if res.kind != kindEvent {
+		http.Error(w, "Events can only be written inside a calendar", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := rc.calendar(res.calendarID); !ok {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	existing, err := h.calendarService.GetStoredUnifiedCalendarEvent(res.eventID)
+	if err != nil && err.Error() != "unified calendar event not found" {
+		http.Error(w, fmt.Sprintf("Failed to get event: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if existing != nil && existing.FamilyID != rc.family.ID {
+		http.Error(w, "Event name is already in use", http.StatusConflict)
+		return
+	}
+
+	if existing == nil {
+		if r.Header.Get("If-Match") != "" {
+			http.Error(w, "Event does not exist", http.StatusPreconditionFailed)
+			return
+		}
+		if !rc.authz.HasPermission(auth.EntityCalendar, auth.ActionCreate, nil) {
+			http.Error(w, "Insufficient permissions", http.StatusForbidden)
+			return
+		}
+	} else {
+		if r.Header.Get("If-None-Match") == "*" {
+			http.Error(w, "Event already exists", http.StatusPreconditionFailed)
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && match != "*" && match != etag(existing) {
+			http.Error(w, "Event has changed", http.StatusPreconditionFailed)
+			return
+		}
+		if !rc.authz.HasPermission(auth.EntityCalendar, auth.ActionUpdate, existing.CreatedBy) {
+			http.Error(w, "Insufficient permissions", http.StatusForbidden)
+			return
+		}
+		seen, err := h.calendarService.CanSeeEventDetails(rc.viewer(), rc.family.ID, existing)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to get event: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if !seen {
+			http.Error(w, "Event is private", http.StatusForbidden)
+			return
+		}
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxEventBytes))
+	if err != nil {
+		http.Error(w, "Event is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	parsed, err := parseEvent(string(data), rc.loc)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Unsupported calendar data: %v", err), http.StatusForbidden)
+		return
+	}
+
+	req := &models.PutUnifiedCalendarEventRequest{
+		ID:          res.eventID,
+		ICalUID:     parsed.UID,
+		Title:       parsed.Title,
+		Description: optionalText(parsed.Description),
+		Location:    optionalText(parsed.Location),
+		StartTime:   parsed.Start,
+		EndTime:     parsed.End,
+		AllDay:      parsed.AllDay,
+		Status:      models.UnifiedEventStatusActive,
+
+		RecurrenceRule: optionalText(parsed.RecurrenceRule),
+		ExceptionDates: parsed.ExceptionDates,
+	}
+	if parsed.Cancelled {
+		req.Status = models.UnifiedEventStatusCancelled
+	} else if existing != nil && existing.Status == models.UnifiedEventStatusCompleted {
+		req.Status = models.UnifiedEventStatusCompleted
+	}
+	if res.calendarID != FamilyCalendarID {
+		req.Attendees = []string{res.calendarID}
+	}
+
+	_, created, err := h.calendarService.PutUnifiedCalendarEvent(r.Context(), rc.family.ID, rc.user.ID, req)
+	if err != nil {
+		var validationErrs validation.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			http.Error(w, fmt.Sprintf("Invalid event: %v", validationErrs), http.StatusForbidden)
+			return
+		}
+		if err.Error() == "event ID is already in use" {
+			http.Error(w, "Event name is already in use", http.StatusConflict)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Failed to save event: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if created {
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// delete removes an event. From a member's calendar an event other members
+// still attend only loses that member, which is also how a client moving an
+// event between calendars ends up with the right attendees.
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request, rc *requestContext, res resource) {
+	if res.kind != kindEvent {
+		http.Error(w, "Calendars cannot be deleted", http.StatusForbidden)
+		return
+	}
+
+	event, err := h.findEvent(rc, res.calendarID, res.eventID)
+	if err != nil {
+		h.writeEventError(w, err)
+		return
+	}
+	if match := r.Header.Get("If-Match"); match != "" && match != "*" && match != etag(event) {
+		http.Error(w, "Event has changed", http.StatusPreconditionFailed)
+		return
+	}
+	if !rc.authz.HasPermission(auth.EntityCalendar, auth.ActionDelete, event.CreatedBy) {
+		http.Error(w, "Insufficient permissions", http.StatusForbidden)
+		return
+	}
+	if event.Redacted {
+		http.Error(w, "Event is private", http.StatusForbidden)
+		return
+	}
+
+	if res.calendarID != FamilyCalendarID && len(event.Attendees) > 1 {
+		err = h.calendarService.RemoveUnifiedEventAttendee(r.Context(), rc.family.ID, event.ID, res.calendarID)
+	} else {
+		err = h.calendarService.DeleteUnifiedCalendarEvent(rc.family.ID, event.ID, models.RecurrenceScopeAll)
+	}
+	if err != nil {
+		h.writeEventError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) writeEventError(w http.ResponseWriter, err error) {
+	if err.Error() == "unified calendar event not found" || err.Error() == "event attendee not found" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	http.Error(w, fmt.Sprintf("Failed to access event: %v", err), http.StatusInternalServerError)
+}
+
+func optionalText(value string) *string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	return &value
+}
+
+// WellKnown redirects /.well-known/caldav to the service root (RFC 6764)
+func WellKnown(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, Prefix, http.StatusMovedPermanently)
+}
@@ -0,0 +1,157 @@
+package caldav
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"famstack/internal/ical"
+	"famstack/internal/models"
+	"famstack/internal/recurrence"
+)
+
+const (
+	icalProdID     = "-//FamStack//CalDAV//EN"
+	icalDateLayout = "20060102"
+	icalUTCLayout  = "20060102T150405Z"
+	icalTimeLayout = "20060102T150405"
+	icalLineLimit  = 75 // octets per line before folding, RFC 5545 3.1
+)
+
+// encodeCalendar renders events as one VCALENDAR. Timed events are written in
+// UTC so no VTIMEZONE is needed; all-day events use dates in loc.
+func encodeCalendar(events []models.UnifiedCalendarEvent, loc *time.Location) string {
+	var b strings.Builder
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+icalProdID)
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	for i := range events {
+		encodeEvent(&b, &events[i], loc)
+	}
+	writeLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+func encodeEvent(b *strings.Builder, event *models.UnifiedCalendarEvent, loc *time.Location) {
+	writeLine(b, "BEGIN:VEVENT")
+	writeLine(b, "UID:"+escapeText(eventUID(event)))
+	writeLine(b, "DTSTAMP:"+event.UpdatedAt.UTC().Format(icalUTCLayout))
+	writeLine(b, "CREATED:"+event.CreatedAt.UTC().Format(icalUTCLayout))
+	writeLine(b, "LAST-MODIFIED:"+event.UpdatedAt.UTC().Format(icalUTCLayout))
+	recurring := event.RecurrenceRule != nil
+	switch {
+	case event.AllDay:
+		writeLine(b, "DTSTART;VALUE=DATE:"+event.StartTime.In(loc).Format(icalDateLayout))
+		writeLine(b, "DTEND;VALUE=DATE:"+allDayEnd(event, loc).Format(icalDateLayout))
+	case recurring && loc != time.UTC:
+		// A series repeats at the same local time, so its times carry the
+		// family's zone rather than UTC
+		writeLine(b, "DTSTART;TZID="+loc.String()+":"+event.StartTime.In(loc).Format(icalTimeLayout))
+		writeLine(b, "DTEND;TZID="+loc.String()+":"+event.EndTime.In(loc).Format(icalTimeLayout))
+	default:
+		writeLine(b, "DTSTART:"+event.StartTime.UTC().Format(icalUTCLayout))
+		writeLine(b, "DTEND:"+event.EndTime.UTC().Format(icalUTCLayout))
+	}
+	if recurring {
+		writeLine(b, "RRULE:"+*event.RecurrenceRule)
+		exdates, _ := recurrence.ParseTimes(event.RecurrenceExdates) // nolint:errcheck // validated when stored
+		for _, exdate := range exdates {
+			switch {
+			case event.AllDay:
+				writeLine(b, "EXDATE;VALUE=DATE:"+exdate.In(loc).Format(icalDateLayout))
+			case loc != time.UTC:
+				writeLine(b, "EXDATE;TZID="+loc.String()+":"+exdate.In(loc).Format(icalTimeLayout))
+			default:
+				writeLine(b, "EXDATE:"+exdate.UTC().Format(icalUTCLayout))
+			}
+		}
+	}
+	writeLine(b, "SUMMARY:"+escapeText(event.Title))
+	if event.Description != nil && *event.Description != "" {
+		writeLine(b, "DESCRIPTION:"+escapeText(*event.Description))
+	}
+	if event.Location != nil && *event.Location != "" {
+		writeLine(b, "LOCATION:"+escapeText(*event.Location))
+	}
+	if event.Category != "" {
+		writeLine(b, "CATEGORIES:"+escapeText(event.Category))
+	}
+	if event.Status == models.UnifiedEventStatusCancelled {
+		writeLine(b, "STATUS:CANCELLED")
+	} else {
+		writeLine(b, "STATUS:CONFIRMED")
+	}
+	writeLine(b, "END:VEVENT")
+}
+
+// eventUID is the UID the event was written with, or its ID for events that
+// came from FamStack itself
+func eventUID(event *models.UnifiedCalendarEvent) string {
+	if event.ICalUID != nil && *event.ICalUID != "" {
+		return *event.ICalUID
+	}
+	return event.ID
+}
+
+// allDayEnd returns the exclusive end date of an all-day event, at least the
+// day after it starts
+func allDayEnd(event *models.UnifiedCalendarEvent, loc *time.Location) time.Time {
+	start := event.StartTime.In(loc)
+	end := event.EndTime.In(loc)
+	if end.Hour() != 0 || end.Minute() != 0 || end.Second() != 0 {
+		end = end.AddDate(0, 0, 1) // stored as an inclusive end of day
+	}
+	if !end.After(start) {
+		end = start.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// writeLine writes a content line, folding it at the octet limit without
+// splitting a UTF-8 sequence
+func writeLine(b *strings.Builder, line string) {
+	for len(line) > icalLineLimit {
+		cut := icalLineLimit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// parseEvent reads the single VEVENT of an iCalendar object. Times without a
+// zone, and zones Go doesn't know, are read in loc. Recurring events may have
+// an RRULE and EXDATEs; RDATE and overridden occurrences (RECURRENCE-ID) are
+// refused.
+func parseEvent(data string, loc *time.Location) (*ical.Event, error) {
+	events, err := ical.ParseEvents(data, loc)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(events) == 0:
+		return nil, fmt.Errorf("no VEVENT found")
+	case len(events) > 1:
+		return nil, fmt.Errorf("only one VEVENT per resource is supported")
+	}
+	event := &events[0]
+	if len(event.ExtraDates) > 0 || !event.RecurrenceID.IsZero() {
+		return nil, fmt.Errorf("extra or overridden occurrences are not supported")
+	}
+	return event, nil
+}
@@ -0,0 +1,243 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// XML namespaces spoken by CalDAV clients
+const (
+	nsDAV    = "DAV:"
+	nsCalDAV = "urn:ietf:params:xml:ns:caldav"
+	nsCS     = "http://calendarserver.org/ns/"
+	nsApple  = "http://apple.com/ns/ical/"
+)
+
+// nsPrefixes are declared once on every multistatus response
+var nsPrefixes = map[string]string{
+	nsDAV:    "D",
+	nsCalDAV: "C",
+	nsCS:     "CS",
+	nsApple:  "A",
+}
+
+// Properties served by this package
+var (
+	propResourceType          = xml.Name{Space: nsDAV, Local: "resourcetype"}
+	propDisplayName           = xml.Name{Space: nsDAV, Local: "displayname"}
+	propCurrentUserPrincipal  = xml.Name{Space: nsDAV, Local: "current-user-principal"}
+	propPrincipalURL          = xml.Name{Space: nsDAV, Local: "principal-URL"}
+	propCurrentUserPrivileges = xml.Name{Space: nsDAV, Local: "current-user-privilege-set"}
+	propSupportedReportSet    = xml.Name{Space: nsDAV, Local: "supported-report-set"}
+	propGetETag               = xml.Name{Space: nsDAV, Local: "getetag"}
+	propGetContentType        = xml.Name{Space: nsDAV, Local: "getcontenttype"}
+	propGetLastModified       = xml.Name{Space: nsDAV, Local: "getlastmodified"}
+	propCalendarHomeSet       = xml.Name{Space: nsCalDAV, Local: "calendar-home-set"}
+	propCalendarUserAddresses = xml.Name{Space: nsCalDAV, Local: "calendar-user-address-set"}
+	propSupportedComponents   = xml.Name{Space: nsCalDAV, Local: "supported-calendar-component-set"}
+	propCalendarData          = xml.Name{Space: nsCalDAV, Local: "calendar-data"}
+	propGetCTag               = xml.Name{Space: nsCS, Local: "getctag"}
+	propCalendarColor         = xml.Name{Space: nsApple, Local: "calendar-color"}
+)
+
+// propList is a DAV:prop element naming the properties a client wants
+type propList struct {
+	Names []anyElement `xml:",any"`
+}
+
+type anyElement struct {
+	XMLName xml.Name
+}
+
+func (p *propList) names() []xml.Name {
+	if p == nil {
+		return nil
+	}
+	names := make([]xml.Name, len(p.Names))
+	for i, element := range p.Names {
+		names[i] = element.XMLName
+	}
+	return names
+}
+
+// propfindRequest is a PROPFIND body. An empty body means allprop.
+type propfindRequest struct {
+	XMLName  xml.Name  `xml:"DAV: propfind"`
+	AllProp  *struct{} `xml:"DAV: allprop"`
+	PropName *struct{} `xml:"DAV: propname"`
+	Prop     *propList `xml:"DAV: prop"`
+}
+
+// reportRequest covers calendar-query and calendar-multiget bodies
+type reportRequest struct {
+	XMLName xml.Name
+	Prop    *propList       `xml:"DAV: prop"`
+	Hrefs   []string        `xml:"DAV: href"`
+	Filter  *calendarFilter `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+type calendarFilter struct {
+	CompFilter compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+type compFilter struct {
+	Name        string       `xml:"name,attr"`
+	TimeRange   *timeRange   `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	CompFilters []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+type timeRange struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
+
+// eventRange returns the VEVENT time-range of a calendar-query filter. Open
+// ends come back as zero times.
+func (f *calendarFilter) eventRange() (start, end time.Time, err error) {
+	if f == nil {
+		return time.Time{}, time.Time{}, nil
+	}
+	for _, component := range f.CompFilter.CompFilters {
+		if !strings.EqualFold(component.Name, "VEVENT") || component.TimeRange == nil {
+			continue
+		}
+		if component.TimeRange.Start != "" {
+			if start, err = time.Parse(icalUTCLayout, component.TimeRange.Start); err != nil {
+				return time.Time{}, time.Time{}, fmt.Errorf("invalid time-range start: %w", err)
+			}
+		}
+		if component.TimeRange.End != "" {
+			if end, err = time.Parse(icalUTCLayout, component.TimeRange.End); err != nil {
+				return time.Time{}, time.Time{}, fmt.Errorf("invalid time-range end: %w", err)
+			}
+		}
+	}
+	return start, end, nil
+}
+
+// decodeBody reads an XML request body into v. It reports false for an
+// empty body.
+func decodeBody(r io.Reader, v any) (bool, error) {
+	err := xml.NewDecoder(r).Decode(v)
+	if err == io.EOF {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// propValues maps each property a resource has to its inner XML
+type propValues map[xml.Name]string
+
+// response is one DAV:response of a multistatus
+type response struct {
+	href   string
+	found  propValues
+	absent []xml.Name
+}
+
+// selectProps picks the requested properties of a resource. With no names
+// every property is returned except calendar-data, which clients only get
+// when they ask for it.
+func selectProps(href string, props propValues, names []xml.Name) response {
+	resp := response{href: href, found: propValues{}}
+	if names == nil {
+		for name, value := range props {
+			if name != propCalendarData {
+				resp.found[name] = value
+			}
+		}
+		return resp
+	}
+	for _, name := range names {
+		if value, ok := props[name]; ok {
+			resp.found[name] = value
+		} else {
+			resp.absent = append(resp.absent, name)
+		}
+	}
+	return resp
+}
+
+// writeMultistatus writes a 207 response
+func writeMultistatus(w http.ResponseWriter, responses []response) {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	b.WriteString(`<D:multistatus`)
+	for _, ns := range []string{nsDAV, nsCalDAV, nsCS, nsApple} {
+		fmt.Fprintf(&b, ` xmlns:%s="%s"`, nsPrefixes[ns], ns)
+	}
+	b.WriteString(">\n")
+
+	for _, resp := range responses {
+		b.WriteString("<D:response><D:href>")
+		b.WriteString(escapeXML(resp.href))
+		b.WriteString("</D:href>")
+		if len(resp.found) > 0 || len(resp.absent) == 0 {
+			b.WriteString("<D:propstat><D:prop>")
+			for _, name := range sortedNames(resp.found) {
+				writeProp(&b, name, resp.found[name])
+			}
+			b.WriteString("</D:prop><D:status>HTTP/1.1 200 OK</D:status></D:propstat>")
+		}
+		if len(resp.absent) > 0 {
+			b.WriteString("<D:propstat><D:prop>")
+			for _, name := range resp.absent {
+				writeProp(&b, name, "")
+			}
+			b.WriteString("</D:prop><D:status>HTTP/1.1 404 Not Found</D:status></D:propstat>")
+		}
+		b.WriteString("</D:response>\n")
+	}
+	b.WriteString("</D:multistatus>\n")
+
+	w.Header().Set("Content-Type", `application/xml; charset="utf-8"`)
+	w.WriteHeader(http.StatusMultiStatus)
+	_, _ = io.WriteString(w, b.String())
+}
+
+// writeProp writes one property element. Names in namespaces without a
+// declared prefix carry their own declaration.
+func writeProp(b *strings.Builder, name xml.Name, inner string) {
+	tag, declaration := name.Local, ""
+	if prefix, ok := nsPrefixes[name.Space]; ok {
+		tag = prefix + ":" + name.Local
+	} else if name.Space != "" {
+		tag = "X:" + name.Local
+		declaration = fmt.Sprintf(` xmlns:X="%s"`, escapeXML(name.Space))
+	}
+	if inner == "" {
+		fmt.Fprintf(b, "<%s%s/>", tag, declaration)
+		return
+	}
+	fmt.Fprintf(b, "<%s%s>%s</%s>", tag, declaration, inner, tag)
+}
+
+func sortedNames(props propValues) []xml.Name {
+	names := make([]xml.Name, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if names[i].Space != names[j].Space {
+			return names[i].Space < names[j].Space
+		}
+		return names[i].Local < names[j].Local
+	})
+	return names
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// hrefXML wraps a path in a DAV:href element
+func hrefXML(path string) string {
+	return "<D:href>" + escapeXML(path) + "</D:href>"
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/csrf"
+	"famstack/internal/services"
+)
+
+// GuestHandlers serve the page a guest pass opens: today's schedule and the
+// tasks a parent picked, which the guest can tick off if the pass allows.
+// The guest has no account; the pass's token rides in a cookie of its own.
+type GuestHandlers struct {
+	authService   *auth.Service
+	guests        *services.GuestService
+	secureCookies bool
+}
+
+// NewGuestHandlers creates guest pass handlers
+func NewGuestHandlers(authService *auth.Service, guests *services.GuestService, secureCookies bool) *GuestHandlers {
+	return &GuestHandlers{authService: authService, guests: guests, secureCookies: secureCookies}
+}
+
+// HandleGuest handles GET /guest/{token}, the link a parent shares, which
+// stores the token and moves on to GET /guest, the page itself. POST /guest
+// with task_id and done marks a task done or not done.
+func (h *GuestHandlers) HandleGuest(w http.ResponseWriter, r *http.Request) {
+	if token := strings.TrimPrefix(r.URL.Path, "/guest/"); token != r.URL.Path && token != "" {
+		h.handleLink(w, r, token)
+		return
+	}
+	if r.Method != "GET" && r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var token string
+	if cookie, err := r.Cookie(auth.GuestCookieName); err == nil {
+		token = cookie.Value
+	}
+	pass, err := h.authService.GuestPassByToken(token)
+	if err != nil {
+		h.renderExpired(w, err)
+		return
+	}
+
+	if r.Method == "POST" {
+		if !pass.CanCompleteTasks() {
+			http.Error(w, "This guest pass can't change tasks", http.StatusForbidden)
+			return
+		}
+		err := h.guests.SetTaskDone(pass.FamilyID, pass.TaskIDs, r.PostFormValue("task_id"), r.PostFormValue("done") == "true")
+		if err != nil {
+			if err.Error() == "task not found" {
+				http.Error(w, "Task not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("Failed to update task for guest pass %s: %v", pass.ID, err)
+			http.Error(w, "Failed to update task", http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, "/guest", http.StatusSeeOther)
+		return
+	}
+
+	day, err := h.guests.Day(pass.FamilyID, pass.TaskIDs, time.Now())
+	if err != nil {
+		log.Printf("Failed to build day for guest pass %s: %v", pass.ID, err)
+		http.Error(w, "Failed to load today's schedule", http.StatusInternalServerError)
+		return
+	}
+	RenderTemplate(w, "guest", map[string]any{
+		"Pass":      pass,
+		"Day":       day,
+		"CSRFToken": csrf.Token(w, r),
+	})
+}
+
+// handleLink keeps the token in the guest cookie until the pass runs out and
+// takes it out of the address bar
+func (h *GuestHandlers) handleLink(w http.ResponseWriter, r *http.Request, token string) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	pass, err := h.authService.GuestPassByToken(token)
+	if err != nil {
+		h.renderExpired(w, err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.GuestCookieName,
+		Value:    token,
+		Path:     "/guest",
+		HttpOnly: true,
+		Secure:   h.secureCookies,
+		SameSite: http.SameSiteLaxMode,
+		Expires:  pass.ExpiresAt,
+	})
+	http.Redirect(w, r, "/guest", http.StatusSeeOther)
+}
+
+func (h *GuestHandlers) renderExpired(w http.ResponseWriter, err error) {
+	if !auth.IsInvalidGuestPass(err) {
+		log.Printf("Failed to check guest pass: %v", err)
+		http.Error(w, "Failed to check guest pass", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNotFound)
+	RenderTemplate(w, "guest", map[string]any{"Expired": true})
+}
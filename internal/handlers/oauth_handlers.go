@@ -5,12 +5,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"famstack/internal/auth"
+	"famstack/internal/csrf"
 	"famstack/internal/integrations"
+	"famstack/internal/jobs"
 	"famstack/internal/jobsystem"
 	"famstack/internal/oauth"
 	"famstack/internal/services"
@@ -22,49 +26,65 @@ type OAuthHandlers struct {
 	authService         *auth.Service
 	jobSystem           *jobsystem.DBJobSystem
 	integrationsService *services.IntegrationsService
+	renderer            *Renderer
+	configurePage       *PageTemplate[OAuthConfigurePage]
+	settingsPage        *PageTemplate[CalendarSettingsPage]
+}
+
+// OAuthConfigurePage is the data for the form shown before connecting a
+// provider
+type OAuthConfigurePage struct {
+	PageData
+	Provider      string
+	DefaultConfig *integrations.CalendarSyncConfig
+}
+
+// CalendarSettingsPage is the data for the calendar settings page
+type CalendarSettingsPage struct {
+	PageData
+	GoogleConnected bool
+	LastSync        *time.Time
+	EventsSynced    int
+	SyncStatus      string
+	SyncStatusClass string
 }
 
 // NewOAuthHandlers creates new OAuth handlers
-func NewOAuthHandlers(oauthService *oauth.Service, authService *auth.Service, jobSystem *jobsystem.DBJobSystem, integrationsService *services.IntegrationsService) *OAuthHandlers {
+func NewOAuthHandlers(oauthService *oauth.Service, authService *auth.Service, jobSystem *jobsystem.DBJobSystem, integrationsService *services.IntegrationsService, renderer *Renderer) *OAuthHandlers {
 	return &OAuthHandlers{
 		oauthService:        oauthService,
 		authService:         authService,
 		jobSystem:           jobSystem,
 		integrationsService: integrationsService,
+		renderer:            renderer,
+		configurePage:       NewPageTemplate[OAuthConfigurePage](renderer, "oauth-configure"),
+		settingsPage:        NewPageTemplate[CalendarSettingsPage](renderer, "calendar-settings"),
 	}
 }
 
 // HandleGoogleConnect shows configuration form before OAuth
 func (h *OAuthHandlers) HandleGoogleConnect(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Get current user from context
 	user := auth.GetUserFromContext(r.Context())
 	if user == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		h.renderer.Error(w, http.StatusUnauthorized, "Sign in to connect a calendar.")
 		return
 	}
 
 	// Show Google Calendar configuration form
-	data := map[string]any{
-		"User":          user,
-		"Provider":      "Google Calendar",
-		"DefaultConfig": integrations.DefaultCalendarSyncConfig(),
-	}
-
-	RenderTemplate(w, "oauth-configure", data)
+	h.configurePage.Render(w, http.StatusOK, OAuthConfigurePage{
+		PageData: PageData{
+			CSRFToken: csrf.Token(w, r),
+			PageTitle: "Configure Google Calendar",
+			PageType:  "integrations",
+		},
+		Provider:      "Google Calendar",
+		DefaultConfig: integrations.DefaultCalendarSyncConfig(),
+	})
 }
 
 // HandleGoogleConnectWithConfig processes configuration and starts OAuth
 func (h *OAuthHandlers) HandleGoogleConnectWithConfig(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Get current user from context
 	user := auth.GetUserFromContext(r.Context())
 	if user == nil {
@@ -101,12 +121,6 @@ func (h *OAuthHandlers) HandleGoogleConnectWithConfig(w http.ResponseWriter, r *
 func (h *OAuthHandlers) HandleGoogleCallback(w http.ResponseWriter, r *http.Request) {
 	fmt.Printf("🔄 OAuth callback started - URL: %s\n", r.URL.String())
 
-	if r.Method != "GET" {
-		fmt.Printf("❌ OAuth callback failed: invalid method %s\n", r.Method)
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Get authorization code and state from query parameters
 	code := r.URL.Query().Get("code")
 	state := r.URL.Query().Get("state")
@@ -224,11 +238,6 @@ func (h *OAuthHandlers) HandleGoogleCallback(w http.ResponseWriter, r *http.Requ
 
 // HandleDisconnectProvider disconnects OAuth provider
 func (h *OAuthHandlers) HandleDisconnectProvider(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Get current user from context
 	user := auth.GetUserFromContext(r.Context())
 	if user == nil {
@@ -236,18 +245,9 @@ func (h *OAuthHandlers) HandleDisconnectProvider(w http.ResponseWriter, r *http.
 		return
 	}
 
-	// Extract provider from URL path
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 5 {
-		http.Error(w, "Invalid provider", http.StatusBadRequest)
-		return
-	}
-
-	providerStr := pathParts[4]
-
 	// Validate provider against allowed values
 	var provider oauth.Provider
-	switch providerStr {
+	switch r.PathValue("provider") {
 	case "google":
 		provider = oauth.ProviderGoogle
 	default:
@@ -275,11 +275,6 @@ func (h *OAuthHandlers) HandleDisconnectProvider(w http.ResponseWriter, r *http.
 
 // HandleCalendarSettings displays calendar settings page
 func (h *OAuthHandlers) HandleCalendarSettings(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Get current user from context
 	user := auth.GetUserFromContext(r.Context())
 	if user == nil {
@@ -287,33 +282,51 @@ func (h *OAuthHandlers) HandleCalendarSettings(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	page := CalendarSettingsPage{
+		PageData: PageData{
+			CSRFToken: csrf.Token(w, r),
+			PageTitle: "Calendar Settings",
+			PageType:  "integrations",
+		},
+		SyncStatus:      "Never synced",
+		SyncStatusClass: "sync-pending",
+	}
+
 	// Check if Google is connected
-	googleConnected := false
 	if _, err := h.oauthService.GetToken(user.ID, oauth.ProviderGoogle); err == nil {
-		googleConnected = true
+		page.GoogleConnected = true
+	}
+
+	// Show how the last sync went
+	integration, err := h.integrationsService.CalendarIntegration(user.ID, services.ProviderGoogle)
+	if err != nil {
+		log.Printf("❌ Failed to load calendar integration: %v", err)
+		h.renderer.Error(w, http.StatusInternalServerError, "Calendar settings couldn't be loaded. Try again in a moment.")
+		return
 	}
+	if integration != nil && integration.LastSyncAt != nil {
+		page.LastSync = integration.LastSyncAt
+		page.SyncStatus, page.SyncStatusClass = "Up to date", "sync-ok"
+		if integration.Status == services.StatusError {
+			page.SyncStatus, page.SyncStatusClass = "Last sync failed", "sync-error"
+			if integration.LastError != nil {
+				page.SyncStatus += ": " + *integration.LastError
+			}
+		}
 
-	// Prepare template data
-	data := map[string]any{
-		"User":            user,
-		"GoogleConnected": googleConnected,
-		"LastSync":        nil, // TODO: Get from database
-		"EventsSynced":    0,   // TODO: Get from database
-		"SyncStatus":      "Never synced",
-		"SyncStatusClass": "sync-pending",
+		history, err := h.integrationsService.ListSyncHistory(integration.ID, services.PageRequest{Limit: 1})
+		if err != nil {
+			log.Printf("⚠️  Failed to load sync history: %v", err)
+		} else if len(history.Items) > 0 {
+			page.EventsSynced = history.Items[0].ItemsSynced
+		}
 	}
 
-	// Render template
-	RenderTemplate(w, "calendar-settings", data)
+	h.settingsPage.Render(w, http.StatusOK, page)
 }
 
 // HandleSyncNow triggers immediate calendar sync
 func (h *OAuthHandlers) HandleSyncNow(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Get current user from context
 	user := auth.GetUserFromContext(r.Context())
 	if user == nil {
@@ -328,20 +341,23 @@ func (h *OAuthHandlers) HandleSyncNow(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Enqueue calendar sync job
+	// Enqueue calendar sync job; repeated clicks within a sync window find
+	// the job already queued instead of adding another
 	payload := map[string]any{
 		"user_id":    user.ID,
 		"family_id":  user.FamilyID,
 		"provider":   "google",
 		"force_sync": true,
 	}
+	idempotencyKey := jobs.CalendarSyncIdempotencyKey(user.ID, "google", time.Now())
 
-	_, err = h.jobSystem.Enqueue(&jobsystem.EnqueueRequest{
-		QueueName:  "calendar-sync",
-		JobType:    "calendar_sync",
-		Payload:    payload,
-		Priority:   2, // Higher priority for manual sync
-		MaxRetries: 3,
+	result, err := h.jobSystem.EnqueueOnce(&jobsystem.EnqueueRequest{
+		QueueName:      "calendar-sync",
+		JobType:        jobs.CalendarSyncJobType,
+		Payload:        payload,
+		Priority:       2, // Higher priority for manual sync
+		MaxRetries:     3,
+		IdempotencyKey: &idempotencyKey,
 	})
 
 	if err != nil {
@@ -349,11 +365,27 @@ func (h *OAuthHandlers) HandleSyncNow(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(map[string]string{
+	response := map[string]string{
 		"status":  "success",
 		"message": "Calendar sync started",
-	}); err != nil {
+		"job_id":  result.JobID,
+	}
+	switch {
+	case !result.Existing:
+		// Show the sync on the integration before a worker picks it up
+		if err := h.integrationsService.SetCalendarSyncStatus(user.ID, services.ProviderGoogle, services.StatusSyncing); err != nil {
+			log.Printf("Failed to mark integration as syncing: %v", err)
+		}
+	case result.Status == jobsystem.JobStatusPending || result.Status == jobsystem.JobStatusRunning:
+		response["status"] = "in_progress"
+		response["message"] = "Sync already in progress"
+	default:
+		response["status"] = "recently_synced"
+		response["message"] = "Calendar was synced in the last few minutes"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
@@ -499,12 +531,12 @@ func (h *OAuthHandlers) decodeConfigFromState(state string) (string, *integratio
 	return userID, &config, nil
 }
 
+// RenderTemplate renders a stand-alone page from web/templates, one that
+// isn't drawn on the shared layout, such as the guest and link-account pages
 func RenderTemplate(w http.ResponseWriter, templateName string, data any) {
-	// All templates are in the web/templates directory
-	templatePath := fmt.Sprintf("web/templates/%s.html.tmpl", templateName)
+	templatePath := fmt.Sprintf("%s/%s.html.tmpl", TemplatesDir, templateName)
 
-	// Parse the specific page template and the navigation template it includes
-	tmpl, err := template.ParseFiles(templatePath, "web/templates/navigation.html.tmpl")
+	tmpl, err := template.ParseFiles(templatePath)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to parse template: %v", err), http.StatusInternalServerError)
 		return
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"famstack/internal/auth"
+	"famstack/internal/csrf"
 	"famstack/internal/database"
 )
 
@@ -38,11 +39,6 @@ type SPAConfig struct {
 
 // ServePage serves the SPA for all routes
 func (h *PageHandler) ServePage(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Debug: Log that we're serving SPA
 	println("ServePage called for:", r.URL.Path)
 
@@ -50,7 +46,7 @@ func (h *PageHandler) ServePage(w http.ResponseWriter, r *http.Request) {
 	// The client-side router will handle the actual routing
 
 	// Create config data for the SPA
-	config := h.getSPAConfig(r)
+	config := h.getSPAConfig(w, r)
 
 	// Parse the SPA template
 	tmpl, err := template.ParseFiles("web/app.html")
@@ -68,10 +64,10 @@ func (h *PageHandler) ServePage(w http.ResponseWriter, r *http.Request) {
 }
 
 // getSPAConfig returns configuration data for the SPA
-func (h *PageHandler) getSPAConfig(r *http.Request) SPAConfig {
+func (h *PageHandler) getSPAConfig(w http.ResponseWriter, r *http.Request) SPAConfig {
 	return SPAConfig{
 		APIBaseURL: "/api/v1",
-		CSRFToken:  "dummy-csrf-token", // TODO: Implement proper CSRF token generation
+		CSRFToken:  csrf.Token(w, r),
 		Features: map[string]bool{
 			"tasks":        true,
 			"calendar":     true,
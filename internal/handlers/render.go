@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// TemplatesDir holds the server-rendered pages, relative to the working
+// directory the server runs in
+const TemplatesDir = "web/templates"
+
+// Renderer renders pages onto the shared layout. A page template in
+// pages/<name>.html.tmpl defines "title" and "content", and optionally
+// "head" for page styles; the layouts and partials are parsed with it.
+// Parsed pages are cached unless reload is set, which picks up template
+// edits without restarting the server.
+type Renderer struct {
+	fsys   fs.FS
+	reload bool
+
+	mu    sync.RWMutex
+	cache map[string]*template.Template
+}
+
+// NewRenderer creates a renderer reading templates from fsys
+func NewRenderer(fsys fs.FS, reload bool) *Renderer {
+	return &Renderer{
+		fsys:   fsys,
+		reload: reload,
+		cache:  make(map[string]*template.Template),
+	}
+}
+
+// template returns the parsed page, from the cache when it can
+func (r *Renderer) template(name string) (*template.Template, error) {
+	if !r.reload {
+		r.mu.RLock()
+		tmpl, ok := r.cache[name]
+		r.mu.RUnlock()
+		if ok {
+			return tmpl, nil
+		}
+	}
+
+	tmpl, err := template.ParseFS(r.fsys, "layouts/*.html.tmpl", "partials/*.html.tmpl", "pages/"+name+".html.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	for _, block := range []string{"layout", "title", "content"} {
+		if tmpl.Lookup(block) == nil {
+			return nil, fmt.Errorf("template %s doesn't define %q", name, block)
+		}
+	}
+
+	if !r.reload {
+		r.mu.Lock()
+		r.cache[name] = tmpl
+		r.mu.Unlock()
+	}
+	return tmpl, nil
+}
+
+// render writes the page with status. The page is rendered in full before
+// anything is written, so a template error still gets an error page.
+func (r *Renderer) render(w http.ResponseWriter, status int, name string, data any) error {
+	tmpl, err := r.template(name)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "layout", data); err != nil {
+		return fmt.Errorf("failed to execute template %s: %w", name, err)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+	return nil
+}
+
+// ErrorPage is the data for the error page
+type ErrorPage struct {
+	PageData
+	Status  int
+	Message string
+}
+
+// StatusText names the status, as the page heading
+func (p ErrorPage) StatusText() string {
+	return http.StatusText(p.Status)
+}
+
+// Error renders the error page with status and a message for the member.
+// If the error page itself fails, it falls back to plain text.
+func (r *Renderer) Error(w http.ResponseWriter, status int, message string) {
+	page := ErrorPage{
+		PageData: PageData{PageTitle: http.StatusText(status)},
+		Status:   status,
+		Message:  message,
+	}
+	if err := r.render(w, status, "error", page); err != nil {
+		log.Printf("❌ Failed to render error page: %v", err)
+		http.Error(w, message, status)
+	}
+}
+
+// PageTemplate renders one page with its data struct, so a handler can't
+// pass the page data it doesn't expect
+type PageTemplate[T any] struct {
+	renderer *Renderer
+	name     string
+}
+
+// NewPageTemplate binds the page pages/<name>.html.tmpl to its data type
+func NewPageTemplate[T any](renderer *Renderer, name string) *PageTemplate[T] {
+	return &PageTemplate[T]{renderer: renderer, name: name}
+}
+
+// Render writes the page with status, or the error page if it can't be
+// rendered
+func (p *PageTemplate[T]) Render(w http.ResponseWriter, status int, data T) {
+	if err := p.renderer.render(w, status, p.name, data); err != nil {
+		log.Printf("❌ Failed to render page: %v", err)
+		p.renderer.Error(w, http.StatusInternalServerError, "Something went wrong showing this page.")
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"famstack/internal/integrations"
+)
+
+func TestRenderer_Pages(t *testing.T) {
+	renderer := NewRenderer(os.DirFS("../../web/templates"), false)
+	lastSync := time.Date(2026, 3, 4, 15, 30, 0, 0, time.UTC)
+
+	w := httptest.NewRecorder()
+	NewPageTemplate[CalendarSettingsPage](renderer, "calendar-settings").Render(w, http.StatusOK, CalendarSettingsPage{
+		PageData:        PageData{CSRFToken: "token-123", PageTitle: "Calendar Settings", PageType: "integrations"},
+		GoogleConnected: true,
+		LastSync:        &lastSync,
+		EventsSynced:    42,
+		SyncStatus:      "Up to date",
+		SyncStatusClass: "sync-ok",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	body := w.Body.String()
+	assert.Contains(t, body, "<title>Calendar Settings - FamStack</title>")
+	assert.Contains(t, body, "Mar 4, 2026 3:30 PM")
+	assert.Contains(t, body, "42 events")
+	assert.Contains(t, body, `<span class="sync-ok">Up to date</span>`)
+	assert.Contains(t, body, `<a href="/integrations" class="active">`, "the navigation marks the page")
+
+	w = httptest.NewRecorder()
+	NewPageTemplate[OAuthConfigurePage](renderer, "oauth-configure").Render(w, http.StatusOK, OAuthConfigurePage{
+		PageData:      PageData{CSRFToken: "token-123", PageType: "integrations"},
+		Provider:      "Google Calendar",
+		DefaultConfig: integrations.DefaultCalendarSyncConfig(),
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<title>Configure Google Calendar - FamStack</title>")
+	assert.Contains(t, w.Body.String(), `value="token-123"`)
+
+	w = httptest.NewRecorder()
+	renderer.Error(w, http.StatusNotFound, "That <page> is gone.")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "<title>Not Found - FamStack</title>")
+	assert.Contains(t, w.Body.String(), "That &lt;page&gt; is gone.")
+}
+
+func testTemplates(content string) fstest.MapFS {
+	return fstest.MapFS{
+		"layouts/base.html.tmpl":   {Data: []byte(`{{define "layout"}}[{{template "title" .}}] {{template "content" .}}{{end}}`)},
+		"partials/empty.html.tmpl": {Data: []byte(`{{define "partial"}}{{end}}`)},
+		"pages/hello.html.tmpl":    {Data: []byte(`{{define "title"}}Hello{{end}}` + content)},
+		"pages/error.html.tmpl":    {Data: []byte(`{{define "title"}}{{.StatusText}}{{end}}{{define "content"}}{{.Message}}{{end}}`)},
+	}
+}
+
+func TestRenderer_CachesUnlessReloading(t *testing.T) {
+	for _, reload := range []bool{false, true} {
+		fsys := testTemplates(`{{define "content"}}{{.}}{{end}}`)
+		page := NewPageTemplate[string](NewRenderer(fsys, reload), "hello")
+
+		w := httptest.NewRecorder()
+		page.Render(w, http.StatusOK, "world")
+		assert.Equal(t, "[Hello] world", w.Body.String())
+
+		fsys["pages/hello.html.tmpl"].Data = []byte(`{{define "title"}}Hi{{end}}{{define "content"}}{{.}}!{{end}}`)
+		w = httptest.NewRecorder()
+		page.Render(w, http.StatusOK, "world")
+		if reload {
+			assert.Equal(t, "[Hi] world!", w.Body.String())
+		} else {
+			assert.Equal(t, "[Hello] world", w.Body.String())
+		}
+	}
+}
+
+func TestRenderer_FailuresShowErrorPage(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing content", ``},
+		{"parse error", `{{define "content"}}{{.Missing{{end}}`},
+		{"execute error", `{{define "content"}}{{.Missing}}{{end}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := NewPageTemplate[struct{}](NewRenderer(testTemplates(tt.content), false), "hello")
+
+			w := httptest.NewRecorder()
+			page.Render(w, http.StatusOK, struct{}{})
+			require.Equal(t, http.StatusInternalServerError, w.Code)
+			assert.Equal(t, "[Internal Server Error] Something went wrong showing this page.", w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,317 @@
+// Package httpfixture records HTTP exchanges with external providers to JSON
+// files and replays them, so provider clients can be tested against real
+// responses without network access or credentials.
+//
+// A fixture is an ordered list of interactions. Replay hands out the first
+// unused interaction whose method and URL match the request, so a request
+// that is retried, such as after a rate limit, is answered by successive
+// recorded responses. Only the method and URL of a request are kept, and only
+// the response headers in keptHeaders, so tokens sent in headers or bodies
+// never reach a fixture; token values in token endpoint responses are
+// redacted.
+package httpfixture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// ignoredParams are query parameters client libraries add that say nothing
+// about the request itself
+var ignoredParams = map[string]bool{"alt": true, "prettyPrint": true}
+
+// keptHeaders are the response headers written to fixtures
+var keptHeaders = []string{"Content-Type", "Retry-After", "Www-Authenticate"}
+
+// redactedFields are JSON fields whose values never reach a fixture
+var redactedFields = map[string]bool{"access_token": true, "refresh_token": true, "id_token": true}
+
+// Fixture is the file format
+type Fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one request and the response it got
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request identifies a request by method and URL
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+// Response is a recorded response. JSON bodies are stored as JSON so
+// fixtures stay readable and easy to edit; anything else is stored as text.
+type Response struct {
+	Status  int                 `json:"status"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	JSON    json.RawMessage     `json:"json,omitempty"`
+	Body    string              `json:"body,omitempty"`
+}
+
+// Load reads a fixture file
+func Load(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	return &fixture, nil
+}
+
+// Save writes a fixture file, creating its directory
+func (f *Fixture) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// New returns a transport for a test. With record set, requests go through
+// real and the exchanges are written to path when the test ends; otherwise
+// they are answered from path, and the test fails if any recorded
+// interaction is left unused.
+func New(t testing.TB, path string, record bool, real http.RoundTripper) http.RoundTripper {
+	t.Helper()
+
+	if record {
+		recorder := NewRecorder(real)
+		t.Cleanup(func() {
+			if err := recorder.Fixture().Save(path); err != nil {
+				t.Errorf("failed to save fixture %s: %v", path, err)
+			}
+		})
+		return recorder
+	}
+
+	fixture, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load fixture: %v", err)
+	}
+	replayer := NewReplayer(fixture)
+	t.Cleanup(func() {
+		if unused := replayer.Unused(); len(unused) > 0 {
+			t.Errorf("fixture %s has %d unused interactions, first %s %s", path, len(unused), unused[0].Method, unused[0].URL)
+		}
+	})
+	return replayer
+}
+
+// Recorder is a transport that records every exchange
+type Recorder struct {
+	base http.RoundTripper
+
+	mu      sync.Mutex
+	fixture Fixture
+}
+
+// NewRecorder records exchanges made through base, or http.DefaultTransport
+func NewRecorder(base http.RoundTripper) *Recorder {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Recorder{base: base}
+}
+
+// RoundTrip sends the request and records the response
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	recorded := Response{Status: resp.StatusCode, Headers: map[string][]string{}}
+	for _, name := range keptHeaders {
+		if values := resp.Header.Values(name); len(values) > 0 {
+			recorded.Headers[name] = values
+		}
+	}
+	if redacted, ok := redactJSON(body); ok {
+		recorded.JSON = redacted
+	} else {
+		recorded.Body = string(body)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fixture.Interactions = append(r.fixture.Interactions, Interaction{
+		Request:  Request{Method: req.Method, URL: normalizeURL(req.URL)},
+		Response: recorded,
+	})
+	return resp, nil
+}
+
+// Fixture returns what has been recorded so far
+func (r *Recorder) Fixture() *Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fixture := Fixture{Interactions: append([]Interaction(nil), r.fixture.Interactions...)}
+	return &fixture
+}
+
+// Replayer is a transport that answers requests from a fixture
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer replays a fixture
+func NewReplayer(fixture *Fixture) *Replayer {
+	return &Replayer{
+		interactions: fixture.Interactions,
+		used:         make([]bool, len(fixture.Interactions)),
+	}
+}
+
+// RoundTrip answers the request with the next matching interaction. A request
+// the fixture doesn't cover is an error, never a network call.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	target := normalizeURL(req.URL)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.interactions {
+		if r.used[i] || interaction.Request.Method != req.Method || interaction.Request.URL != target {
+			continue
+		}
+		r.used[i] = true
+		return interaction.Response.toHTTP(req), nil
+	}
+	return nil, fmt.Errorf("httpfixture: no recorded response for %s %s", req.Method, target)
+}
+
+// Unused returns the requests of interactions not yet replayed
+func (r *Replayer) Unused() []Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []Request
+	for i, interaction := range r.interactions {
+		if !r.used[i] {
+			unused = append(unused, interaction.Request)
+		}
+	}
+	return unused
+}
+
+func (resp Response) toHTTP(req *http.Request) *http.Response {
+	header := http.Header{}
+	for name, values := range resp.Headers {
+		for _, value := range values {
+			header.Add(name, value)
+		}
+	}
+
+	body := []byte(resp.Body)
+	if len(resp.JSON) > 0 {
+		body = resp.JSON
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", "application/json; charset=UTF-8")
+		}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.Status, http.StatusText(resp.Status)),
+		StatusCode:    resp.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// normalizeURL drops ignoredParams and sorts the query so the same request
+// always has the same URL
+func normalizeURL(u *url.URL) string {
+	query := u.Query()
+	for param := range ignoredParams {
+		query.Del(param)
+	}
+
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(u.Scheme + "://" + u.Host + u.EscapedPath())
+	for i, key := range keys {
+		for j, value := range query[key] {
+			if i == 0 && j == 0 {
+				b.WriteByte('?')
+			} else {
+				b.WriteByte('&')
+			}
+			b.WriteString(url.QueryEscape(key) + "=" + url.QueryEscape(value))
+		}
+	}
+	return b.String()
+}
+
+// redactJSON re-indents a JSON body with token values replaced. It reports
+// false for bodies that are not JSON.
+func redactJSON(body []byte) (json.RawMessage, bool) {
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber() // keep numbers exactly as sent
+	if len(bytes.TrimSpace(body)) == 0 || decoder.Decode(&value) != nil {
+		return nil, false
+	}
+	redacted, err := json.Marshal(redact(value))
+	if err != nil {
+		return nil, false
+	}
+	return redacted, true
+}
+
+func redact(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if redactedFields[key] {
+				v[key] = "REDACTED"
+			} else {
+				v[key] = redact(field)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redact(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,101 @@
+package httpfixture
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordThenReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/token":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"access_token":"secret","refresh_token":"secret","expires_in":3599}`)
+		case "/events":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Set-Cookie", "session=secret")
+			_, _ = io.WriteString(w, `{"items":[{"id":"a","sequence":12345678901234567890}],"nextPageToken":"p2"}`)
+		default:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = io.WriteString(w, "slow down")
+		}
+	}))
+	defer server.Close()
+
+	recorder := NewRecorder(nil)
+	client := &http.Client{Transport: recorder}
+	for _, path := range []string{"/token", "/events?timeMin=1&alt=json&maxResults=10", "/busy"} {
+		resp, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	require.NoError(t, recorder.Fixture().Save(path))
+	fixture, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, fixture.Interactions, 3)
+
+	token := fixture.Interactions[0].Response
+	assert.JSONEq(t, `{"access_token":"REDACTED","refresh_token":"REDACTED","expires_in":3599}`, string(token.JSON))
+
+	events := fixture.Interactions[1]
+	assert.Equal(t, server.URL+"/events?maxResults=10&timeMin=1", events.Request.URL, "query is sorted without alt")
+	assert.Contains(t, string(events.Response.JSON), "12345678901234567890", "numbers are kept exactly")
+	assert.NotContains(t, events.Response.Headers, "Set-Cookie")
+
+	busy := fixture.Interactions[2].Response
+	assert.Equal(t, http.StatusTooManyRequests, busy.Status)
+	assert.Equal(t, "slow down", busy.Body)
+	assert.Equal(t, []string{"1"}, busy.Headers["Retry-After"])
+
+	// Replay answers without the server
+	calls = 0
+	replay := &http.Client{Transport: NewReplayer(fixture)}
+	resp, err := replay.Get(server.URL + "/events?maxResults=10&timeMin=1&prettyPrint=false")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"items":[{"id":"a","sequence":12345678901234567890}],"nextPageToken":"p2"}`, string(body))
+	assert.Equal(t, 0, calls)
+}
+
+func TestReplayerOrderAndMisses(t *testing.T) {
+	fixture := &Fixture{Interactions: []Interaction{
+		{Request: Request{Method: "GET", URL: "https://api.test/x"}, Response: Response{Status: 429, Headers: map[string][]string{"Retry-After": {"2"}}}},
+		{Request: Request{Method: "GET", URL: "https://api.test/x"}, Response: Response{Status: 200, JSON: []byte(`{"ok":true}`)}},
+		{Request: Request{Method: "GET", URL: "https://api.test/never"}, Response: Response{Status: 200}},
+	}}
+	replayer := NewReplayer(fixture)
+	client := &http.Client{Transport: replayer}
+
+	first, err := client.Get("https://api.test/x")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, first.StatusCode)
+	assert.Equal(t, "2", first.Header.Get("Retry-After"))
+
+	second, err := client.Get("https://api.test/x")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, second.StatusCode)
+	assert.True(t, strings.HasPrefix(second.Header.Get("Content-Type"), "application/json"))
+
+	_, err = client.Get("https://api.test/x")
+	assert.ErrorContains(t, err, "no recorded response")
+
+	_, err = client.Post("https://api.test/never", "text/plain", nil)
+	assert.ErrorContains(t, err, "no recorded response", "method must match")
+
+	assert.Equal(t, []Request{{Method: "GET", URL: "https://api.test/never"}}, replayer.Unused())
+}
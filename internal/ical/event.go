@@ -0,0 +1,160 @@
+package ical
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Event is the part of a VEVENT that maps onto a unified event
+type Event struct {
+	UID         string
+	Title       string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Cancelled   bool
+
+	RecurrenceRule string
+	ExceptionDates []time.Time
+	// RecurrenceID is set on an event that overrides one occurrence of the
+	// series with the same UID: the occurrence's original start
+	RecurrenceID time.Time
+	// ExtraDates are the series' RDATEs, occurrences its rule doesn't make
+	ExtraDates []time.Time
+	// LastModified is when the publisher last changed the event; zero when
+	// the feed doesn't say
+	LastModified time.Time
+}
+
+// ParseEvents reads every VEVENT of an iCalendar object, in order. Times
+// without a zone, and zones Go doesn't know, are read in loc.
+func ParseEvents(data string, loc *time.Location) ([]Event, error) {
+	lines, err := Unfold(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var event *eventBuilder
+	var depth []string
+	for _, line := range lines {
+		switch line.Name {
+		case "BEGIN":
+			depth = append(depth, strings.ToUpper(line.Value))
+			if strings.EqualFold(line.Value, "VEVENT") {
+				event = &eventBuilder{}
+			}
+			continue
+		case "END":
+			if len(depth) > 0 {
+				depth = depth[:len(depth)-1]
+			}
+			if strings.EqualFold(line.Value, "VEVENT") && event != nil {
+				built, err := event.build()
+				if err != nil {
+					return nil, err
+				}
+				events = append(events, *built)
+				event = nil
+			}
+			continue
+		}
+
+		// Only the event's own properties, not those of a nested VALARM
+		if event == nil || depth[len(depth)-1] != "VEVENT" {
+			continue
+		}
+		if err := event.add(line, loc); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// eventBuilder collects a VEVENT's properties
+type eventBuilder struct {
+	Event
+	hasEnd   bool
+	duration time.Duration
+}
+
+func (e *eventBuilder) add(line Line, loc *time.Location) error {
+	var err error
+	switch line.Name {
+	case "UID":
+		e.UID = line.Value
+	case "SUMMARY":
+		e.Title = Unescape(line.Value)
+	case "DESCRIPTION":
+		e.Description = Unescape(line.Value)
+	case "LOCATION":
+		e.Location = Unescape(line.Value)
+	case "STATUS":
+		e.Cancelled = strings.EqualFold(line.Value, "CANCELLED")
+	case "DTSTART":
+		e.Start, e.AllDay, err = ParseDateTime(line, loc)
+		if err != nil {
+			return fmt.Errorf("invalid DTSTART: %w", err)
+		}
+	case "DTEND":
+		e.End, _, err = ParseDateTime(line, loc)
+		if err != nil {
+			return fmt.Errorf("invalid DTEND: %w", err)
+		}
+		e.hasEnd = true
+	case "DURATION":
+		e.duration, err = ParseDuration(line.Value)
+		if err != nil {
+			return fmt.Errorf("invalid DURATION: %w", err)
+		}
+	case "RRULE":
+		if e.RecurrenceRule != "" {
+			return fmt.Errorf("only one RRULE per event is supported")
+		}
+		e.RecurrenceRule = line.Value
+	case "EXDATE", "RDATE":
+		for _, value := range strings.Split(line.Value, ",") {
+			t, _, err := ParseDateTime(Line{Name: line.Name, Params: line.Params, Value: value}, loc)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", line.Name, err)
+			}
+			if line.Name == "EXDATE" {
+				e.ExceptionDates = append(e.ExceptionDates, t)
+			} else {
+				e.ExtraDates = append(e.ExtraDates, t)
+			}
+		}
+	case "LAST-MODIFIED":
+		e.LastModified, _, err = ParseDateTime(line, loc)
+		if err != nil {
+			return fmt.Errorf("invalid LAST-MODIFIED: %w", err)
+		}
+	case "RECURRENCE-ID":
+		e.RecurrenceID, _, err = ParseDateTime(line, loc)
+		if err != nil {
+			return fmt.Errorf("invalid RECURRENCE-ID: %w", err)
+		}
+	}
+	return nil
+}
+
+func (e *eventBuilder) build() (*Event, error) {
+	if e.UID == "" {
+		return nil, fmt.Errorf("VEVENT has no UID")
+	}
+	if e.Start.IsZero() {
+		return nil, fmt.Errorf("VEVENT has no DTSTART")
+	}
+	if !e.hasEnd {
+		// RFC 5545 3.6.1: without DTEND or DURATION a date lasts one day and
+		// a date-time lasts no time at all
+		e.End = e.Start.Add(e.duration)
+		if e.AllDay && e.duration == 0 {
+			e.End = e.Start.AddDate(0, 0, 1)
+		}
+	}
+	return &e.Event, nil
+}
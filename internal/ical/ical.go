@@ -0,0 +1,146 @@
+// Package ical reads iCalendar (RFC 5545) data: the events CalDAV clients
+// write and the feeds families subscribe to.
+package ical
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout = "20060102"
+	utcLayout  = "20060102T150405Z"
+	timeLayout = "20060102T150405"
+)
+
+// Line is one unfolded property of an iCalendar object
+type Line struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Unescape reads a TEXT value
+func Unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// Unfold splits an iCalendar object into content lines, joining folded ones
+func Unfold(data string) ([]Line, error) {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	var raw []string
+	for _, line := range strings.Split(data, "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(raw) > 0 {
+			raw[len(raw)-1] += line[1:]
+			continue
+		}
+		if strings.TrimSpace(line) != "" {
+			raw = append(raw, line)
+		}
+	}
+
+	lines := make([]Line, 0, len(raw))
+	for _, text := range raw {
+		line, err := parseLine(text)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// parseLine splits "NAME;PARAM=value:text", honoring quoted parameter values
+// that contain ':' or ';'
+func parseLine(text string) (Line, error) {
+	line := Line{Params: map[string]string{}}
+	inQuotes := false
+	colon := -1
+	for i, c := range text {
+		if c == '"' {
+			inQuotes = !inQuotes
+		} else if c == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return Line{}, fmt.Errorf("malformed content line %q", text)
+	}
+
+	parts := strings.Split(text[:colon], ";")
+	line.Name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		line.Params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	line.Value = text[colon+1:]
+	return line, nil
+}
+
+// ParseDateTime reads a DATE or DATE-TIME value and reports whether it was a
+// date. Times without a zone, and zones Go doesn't know, are read in loc.
+func ParseDateTime(line Line, loc *time.Location) (time.Time, bool, error) {
+	value := strings.TrimSpace(line.Value)
+	if strings.EqualFold(line.Params["VALUE"], "DATE") || len(value) == len(dateLayout) {
+		t, err := time.ParseInLocation(dateLayout, value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(utcLayout, value)
+		return t, false, err
+	}
+
+	zone := loc
+	if tzid := line.Params["TZID"]; tzid != "" {
+		if named, err := time.LoadLocation(tzid); err == nil {
+			zone = named
+		}
+	}
+	t, err := time.ParseInLocation(timeLayout, value, zone)
+	return t, false, err
+}
+
+var durationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// ParseDuration reads an RFC 5545 duration such as P1D or PT1H30M
+func ParseDuration(value string) (time.Duration, error) {
+	match := durationPattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(value)))
+	if match == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return 0, fmt.Errorf("malformed duration %q", value)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var total time.Duration
+	for i, unit := range units {
+		if match[i+2] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(match[i+2])
+		if err != nil {
+			return 0, err
+		}
+		total += time.Duration(n) * unit
+	}
+	if match[1] == "-" {
+		total = -total
+	}
+	return total, nil
+}
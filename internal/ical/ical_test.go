@@ -0,0 +1,98 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"P1D":       24 * time.Hour,
+		"PT45M":     45 * time.Minute,
+		"P1W":       7 * 24 * time.Hour,
+		"-PT15M":    -15 * time.Minute,
+		"P1DT2H30S": 26*time.Hour + 30*time.Second,
+	}
+	for value, want := range cases {
+		got, err := ParseDuration(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, got, value)
+	}
+
+	for _, value := range []string{"P", "PT", "1H", "P1H"} {
+		_, err := ParseDuration(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestParseEvents(t *testing.T) {
+	loc, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+
+	data := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VTIMEZONE",
+		"TZID:America/Chicago",
+		"BEGIN:STANDARD",
+		"DTSTART:19701101T020000",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"UID:practice@school.example",
+		"DTSTART;TZID=America/Chicago:20250903T160000",
+		"DTEND;TZID=America/Chicago:20250903T173000",
+		"RRULE:FREQ=WEEKLY;BYDAY=WE",
+		"EXDATE;TZID=America/Chicago:20250910T160000,20250917T160000",
+		"SUMMARY:Soccer practice",
+		"BEGIN:VALARM",
+		"SUMMARY:Reminder",
+		"TRIGGER:-PT15M",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:practice@school.example",
+		"RECURRENCE-ID;TZID=America/Chicago:20250924T160000",
+		"DTSTART;TZID=America/Chicago:20250924T170000",
+		"DTEND;TZID=America/Chicago:20250924T183000",
+		"SUMMARY:Soccer practice (late)",
+		"LAST-MODIFIED:20250920T143000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:holiday@school.example",
+		"DTSTART;VALUE=DATE:20251013",
+		`SUMMARY:No school\, teacher workday`,
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	events, err := ParseEvents(data, loc)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	series := events[0]
+	assert.Equal(t, "Soccer practice", series.Title)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=WE", series.RecurrenceRule)
+	assert.Len(t, series.ExceptionDates, 2)
+	assert.True(t, series.RecurrenceID.IsZero())
+	assert.Equal(t, 90*time.Minute, series.End.Sub(series.Start))
+
+	override := events[1]
+	assert.Equal(t, series.UID, override.UID)
+	assert.Equal(t, time.Date(2025, 9, 24, 16, 0, 0, 0, loc), override.RecurrenceID)
+	assert.Equal(t, "Soccer practice (late)", override.Title)
+	assert.Equal(t, time.Date(2025, 9, 20, 14, 30, 0, 0, time.UTC), override.LastModified.UTC())
+	assert.True(t, series.LastModified.IsZero())
+
+	holiday := events[2]
+	assert.True(t, holiday.AllDay)
+	assert.Equal(t, "No school, teacher workday", holiday.Title)
+	assert.Equal(t, holiday.Start.AddDate(0, 0, 1), holiday.End)
+
+	_, err = ParseEvents("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:20250903T160000Z\r\nEND:VEVENT\r\nEND:VCALENDAR", loc)
+	assert.Error(t, err, "an event without a UID")
+}
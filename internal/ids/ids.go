@@ -0,0 +1,46 @@
+// Package ids generates the IDs famstack stores rows under.
+//
+// An ID is a short prefix naming the kind of row followed by a ULID, as in
+// task_01J9ZQ3K8M4X7B2C5D6E7F8G9H. ULIDs are unique across goroutines and
+// processes and sort by creation time. Rows created before the switch to
+// ULIDs keep their old IDs, a prefix followed by the creation time in
+// nanoseconds (task_1712345678901234567), and every column stays TEXT, so no
+// migration is needed; code that has to recognise an ID uses Is, which
+// accepts both forms.
+package ids
+
+import (
+	"strings"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// New returns a new ID for a row of the kind named by prefix
+func New(prefix string) string {
+	return prefix + "_" + ulid.Make().String()
+}
+
+// Is reports whether s looks like an ID, or the generated part of one: a
+// ULID, a legacy run of eight or more digits as in task_1712345678901234567,
+// or a long hex string like the random IDs integrations use
+func Is(s string) bool {
+	suffix := s[strings.LastIndexAny(s, "_-")+1:]
+	if _, err := ulid.ParseStrict(suffix); err == nil {
+		return true
+	}
+
+	digits, run, hex := 0, 0, len(s) >= 16
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			run++
+			digits = max(digits, run)
+			continue
+		case (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F'):
+		default:
+			hex = false
+		}
+		run = 0
+	}
+	return digits >= 8 || hex
+}
@@ -0,0 +1,59 @@
+package ids
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_UniqueAcrossGoroutines(t *testing.T) {
+	const goroutines, perGoroutine = 8, 500
+
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			batch := make([]string, 0, perGoroutine)
+			for range perGoroutine {
+				batch = append(batch, New("task"))
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, id := range batch {
+				seen[id] = true
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, seen, goroutines*perGoroutine)
+	for id := range seen {
+		assert.True(t, strings.HasPrefix(id, "task_"), id)
+		assert.True(t, Is(id), id)
+		break
+	}
+}
+
+func TestIs(t *testing.T) {
+	tests := []struct {
+		segment string
+		want    bool
+	}{
+		{"task_01J9ZQ3K8M4X7B2C5D6E7F8G9H", true},
+		{"unified_event_01J9ZQ3K8M4X7B2C5D6E7F8G9H", true},
+		{"task_1712345678901234567", true},
+		{"member-1712345678901234567", true},
+		{"9f86d081884c7d659a2feaa0c55ad015", true},
+		{"tasks", false},
+		{"2026", false},
+		{"complete", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Is(tt.segment), tt.segment)
+	}
+}
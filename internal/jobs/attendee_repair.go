@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"famstack/internal/jobsystem"
+	"famstack/internal/services"
+)
+
+// AttendeeRepairJobType is the job that rewrites the attendees of synced
+// events into the form migration 054 describes
+const AttendeeRepairJobType = "synced_attendee_repair"
+
+// NewAttendeeRepairHandler rewrites the attendees of synced events stored
+// in an older form and matches their emails to members. It changes nothing
+// the second time, so it is safe to run again.
+func NewAttendeeRepairHandler(serviceRegistry *services.Registry) jobsystem.JobHandler {
+	return func(ctx context.Context, job *jobsystem.Job) error {
+		logger := jobsystem.LoggerFromContext(ctx)
+
+		repaired, err := serviceRegistry.Calendar.RepairSyncedAttendees()
+		if err != nil {
+			return fmt.Errorf("failed to repair synced attendees: %w", err)
+		}
+
+		logger.Info("Synced attendees repaired", "events", repaired)
+		return nil
+	}
+}
@@ -0,0 +1,227 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"famstack/internal/ical"
+	"famstack/internal/jobsystem"
+	"famstack/internal/services"
+)
+
+// Calendar feed jobs: the planner finds ICS subscriptions due a fetch and
+// queues a sync of each
+const (
+	FeedPlannerJobType = "ics_feed_planner"
+	FeedSyncJobType    = "ics_feed_sync"
+)
+
+// FeedSyncWindow is how long a manual feed sync request stands, as with
+// CalendarSyncWindow
+const FeedSyncWindow = 5 * time.Minute
+
+// maxFeedSize bounds how much of a feed is read; a school year of events is
+// well under a megabyte
+const maxFeedSize = 10 << 20
+
+type FeedSyncPayload struct {
+	IntegrationID string `json:"integration_id"`
+	Manual        bool   `json:"manual,omitempty"`
+}
+
+// FeedSyncIdempotencyKey is the idempotency key for a manual sync of the
+// feed requested at the given time, so every request in one FeedSyncWindow
+// maps to a single job
+func FeedSyncIdempotencyKey(integrationID string, at time.Time) string {
+	window := at.UTC().Truncate(FeedSyncWindow)
+	return fmt.Sprintf("%s:manual:%s:%d", FeedSyncJobType, integrationID, window.Unix())
+}
+
+// NewFeedPlannerHandler queues a sync of every ICS subscription due a fetch.
+// The idempotency key, one per feed and refresh interval, keeps a slow sync
+// from being queued again by the next run.
+func NewFeedPlannerHandler(serviceRegistry *services.Registry, jobSystem JobEnqueuer) jobsystem.JobHandler {
+	return func(ctx context.Context, job *jobsystem.Job) error {
+		logger := jobsystem.LoggerFromContext(ctx)
+
+		now := time.Now()
+		due, err := serviceRegistry.Integrations.FeedsDue(now)
+		if err != nil {
+			return fmt.Errorf("failed to plan calendar feed syncs: %w", err)
+		}
+
+		for _, feed := range due {
+			window := now.UTC().Truncate(feed.FeedSettings().Refresh())
+			idempotencyKey := fmt.Sprintf("%s:%s:%d", FeedSyncJobType, feed.ID, window.Unix())
+			_, err := jobSystem.Enqueue(&jobsystem.EnqueueRequest{
+				QueueName: "default",
+				JobType:   FeedSyncJobType,
+				Payload: map[string]interface{}{
+					"integration_id": feed.ID,
+				},
+				Priority:       1,
+				MaxRetries:     2,
+				IdempotencyKey: &idempotencyKey,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to enqueue sync of calendar feed %s: %w", feed.ID, err)
+			}
+		}
+
+		logger.Info("Calendar feed planning completed", "due", len(due))
+		return nil
+	}
+}
+
+// NewFeedSyncHandler fetches an ICS subscription's feed and brings the
+// family calendar in step with it. Every attempt is recorded in the
+// integration's sync history; syncs of feeds since removed or disabled are
+// dropped.
+func NewFeedSyncHandler(serviceRegistry *services.Registry, client *http.Client) jobsystem.JobHandler {
+	return func(ctx context.Context, job *jobsystem.Job) error {
+		var payload FeedSyncPayload
+
+		payloadBytes, err := json.Marshal(job.Payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal job payload: %w", err)
+		}
+
+		if err := json.Unmarshal(payloadBytes, &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal feed sync payload: %w", err)
+		}
+
+		logger := jobsystem.LoggerFromContext(ctx)
+		integration, err := serviceRegistry.Integrations.GetIntegration(payload.IntegrationID)
+		if err != nil || !integration.Enabled || integration.Provider != services.ProviderICS {
+			logger.Info("Calendar feed gone or disabled, dropping sync", "integration_id", payload.IntegrationID)
+			return nil
+		}
+
+		startedAt := time.Now()
+		result, syncErr := syncFeed(ctx, serviceRegistry, client, integration)
+		syncType := "scheduled"
+		if payload.Manual {
+			syncType = "manual"
+		}
+		if err := serviceRegistry.Integrations.RecordFeedSync(integration.ID, syncType, startedAt, result, syncErr); err != nil {
+			log.Printf("Failed to record sync of calendar feed %s: %v", integration.ID, err)
+		}
+		if syncErr != nil {
+			return fmt.Errorf("failed to sync calendar feed %s: %w", integration.ID, syncErr)
+		}
+
+		logger.Info("Calendar feed synced", "integration_id", integration.ID,
+			"created", result.Created, "updated", result.Updated, "unchanged", result.Unchanged,
+			"removed", result.Removed, "skipped", result.Skipped)
+		return nil
+	}
+}
+
+// syncFeed fetches and parses the feed, reading floating times in the
+// family's timezone, and stores its events
+func syncFeed(ctx context.Context, serviceRegistry *services.Registry, client *http.Client, integration *services.Integration) (*services.FeedSyncResult, error) {
+	data, err := fetchFeed(ctx, client, integration.FeedSettings().URL)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := time.UTC
+	if family, err := serviceRegistry.Families.GetFamily(integration.FamilyID); err == nil {
+		if familyLoc, err := time.LoadLocation(family.Timezone); err == nil {
+			loc = familyLoc
+		}
+	}
+	events, err := ical.ParseEvents(data, loc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %w", err)
+	}
+	return serviceRegistry.Calendar.SyncFeedEvents(integration, events)
+}
+
+// NewFeedClient returns the client feed syncs fetch with. Feed URLs come from
+// members, so it only connects to public addresses: the check runs as each
+// connection is dialed, after DNS, which covers redirects and names that
+// resolve to a private address. It ignores proxy settings, since a proxy
+// would make the connection on its behalf.
+func NewFeedClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: publicAddressOnly}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// sharedAddressSpace is carrier-grade NAT (RFC 6598), which like the private
+// ranges is never a public server
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// publicAddressOnly is a net.Dialer Control hook refusing connections to
+// loopback, private, link-local (cloud metadata services among them) and
+// other non-public addresses
+func publicAddressOnly(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("feed address %s: %w", address, err)
+	}
+	addr := addrPort.Addr().Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || sharedAddressSpace.Contains(addr) {
+		return fmt.Errorf("feed address %s is not a public address", addr)
+	}
+	return nil
+}
+
+// fetchFeed downloads a feed. webcal:// links, the form calendar sites hand
+// out, are fetched over https.
+func fetchFeed(ctx context.Context, client *http.Client, feedURL string) (string, error) {
+	target, err := url.Parse(strings.TrimSpace(feedURL))
+	if err != nil || target.Host == "" {
+		return "", fmt.Errorf("invalid feed URL")
+	}
+	switch strings.ToLower(target.Scheme) {
+	case "webcal", "webcals":
+		target.Scheme = "https"
+	case "http", "https":
+	default:
+		return "", fmt.Errorf("invalid feed URL")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/calendar")
+	req.Header.Set("User-Agent", "FamStack-Calendar/1.0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("feed answered %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read feed: %w", err)
+	}
+	if len(body) > maxFeedSize {
+		return "", fmt.Errorf("feed is larger than %d MB", maxFeedSize>>20)
+	}
+	return string(body), nil
+}
@@ -0,0 +1,76 @@
+package jobs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchFeed(t *testing.T) {
+	var accept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		switch r.URL.Path {
+		case "/calendar.ics":
+			_, _ = w.Write([]byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+		case "/huge.ics":
+			_, _ = w.Write([]byte(strings.Repeat("x", maxFeedSize+1)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	data, err := fetchFeed(context.Background(), server.Client(), server.URL+"/calendar.ics")
+	require.NoError(t, err)
+	assert.Contains(t, data, "BEGIN:VCALENDAR")
+	assert.Equal(t, "text/calendar", accept)
+
+	_, err = fetchFeed(context.Background(), server.Client(), server.URL+"/missing.ics")
+	assert.ErrorContains(t, err, "404")
+	_, err = fetchFeed(context.Background(), server.Client(), server.URL+"/huge.ics")
+	assert.ErrorContains(t, err, "larger than")
+	_, err = fetchFeed(context.Background(), server.Client(), "ftp://school.example.org/calendar.ics")
+	assert.ErrorContains(t, err, "invalid feed URL")
+}
+
+func TestFetchFeedWebcal(t *testing.T) {
+	var requested string
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		requested = r.URL.String()
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+	})}
+
+	_, err := fetchFeed(context.Background(), client, "webcal://school.example.org/calendar.ics")
+	require.NoError(t, err)
+	assert.Equal(t, "https://school.example.org/calendar.ics", requested)
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestFeedClientRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+	}))
+	defer server.Close()
+
+	_, err := fetchFeed(context.Background(), NewFeedClient(5*time.Second), server.URL+"/calendar.ics")
+	assert.ErrorContains(t, err, "is not a public address")
+
+	for _, address := range []string{
+		"127.0.0.1:80", "[::1]:443", "10.1.2.3:80", "192.168.1.10:443", "172.16.0.1:80",
+		"169.254.169.254:80", "[fe80::1]:80", "100.64.0.1:80", "0.0.0.0:80", "[::ffff:127.0.0.1]:80", "[fd00::1]:443",
+	} {
+		assert.Error(t, publicAddressOnly("tcp", address, nil), address)
+	}
+	assert.NoError(t, publicAddressOnly("tcp", "93.184.216.34:443", nil))
+	assert.NoError(t, publicAddressOnly("tcp6", "[2606:2800:220:1::1]:443", nil))
+}
@@ -3,19 +3,39 @@ package jobs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"slices"
 	"time"
 
 	"famstack/internal/calendar"
 	"famstack/internal/jobsystem"
+	"famstack/internal/models"
+	"famstack/internal/notify"
 	"famstack/internal/oauth"
 	"famstack/internal/services"
+	"famstack/internal/templates"
+	"famstack/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // CalendarSyncJobType represents the job type for calendar synchronization
 const CalendarSyncJobType = "calendar_sync"
 
+// CalendarSyncWindow is how long a manual sync request stands. Asking again
+// within the same window finds the sync already queued instead of adding
+// another.
+const CalendarSyncWindow = 5 * time.Minute
+
+// CalendarSyncIdempotencyKey is the idempotency key for a sync of userID's
+// provider calendars requested at the given time, so every request in one
+// CalendarSyncWindow maps to a single job
+func CalendarSyncIdempotencyKey(userID, provider string, at time.Time) string {
+	window := at.UTC().Truncate(CalendarSyncWindow)
+	return fmt.Sprintf("%s:%s:%s:%d", CalendarSyncJobType, userID, provider, window.Unix())
+}
+
 // CalendarSyncPayload represents the payload for calendar sync jobs
 type CalendarSyncPayload struct {
 	UserID     string `json:"user_id"`
@@ -23,6 +43,9 @@ type CalendarSyncPayload struct {
 	Provider   string `json:"provider"`
 	CalendarID string `json:"calendar_id,omitempty"`
 	ForceSync  bool   `json:"force_sync,omitempty"`
+	// FullSync fetches every event in the sync range instead of only the
+	// changes since the last sync
+	FullSync bool `json:"full_sync,omitempty"`
 }
 
 // CalendarSyncHandler handles calendar synchronization jobs
@@ -30,6 +53,7 @@ type CalendarSyncHandler struct {
 	serviceRegistry *services.Registry
 	oauthService    *oauth.Service
 	googleClient    *calendar.GoogleClient
+	jobSystem       JobEnqueuer
 }
 
 // NewCalendarSyncHandler creates a new calendar sync handler
@@ -41,6 +65,12 @@ func NewCalendarSyncHandler(serviceRegistry *services.Registry, oauthService *oa
 	}
 }
 
+// SetJobEnqueuer lets the handler queue a push notification to the member
+// whose sync failed for good
+func (h *CalendarSyncHandler) SetJobEnqueuer(jobSystem JobEnqueuer) {
+	h.jobSystem = jobSystem
+}
+
 // Handle processes calendar sync jobs
 func (h *CalendarSyncHandler) Handle(ctx context.Context, job *jobsystem.Job) error {
 	var payload CalendarSyncPayload
@@ -52,23 +82,96 @@ func (h *CalendarSyncHandler) Handle(ctx context.Context, job *jobsystem.Job) er
 		return fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
 
-	log.Printf("Starting calendar sync for user %s, provider %s", payload.UserID, payload.Provider)
+	logger := jobsystem.LoggerFromContext(ctx)
+	logger.Info("starting calendar sync", "user_id", payload.UserID, "provider", payload.Provider)
 
 	// Update sync status to 'syncing'
 	if err := h.updateSyncStatus(payload.UserID, "syncing", "", 0); err != nil {
-		log.Printf("Failed to update sync status: %v", err)
+		logger.Warn("failed to update sync status", "error", err)
 	}
+	h.setIntegrationStatus(ctx, payload, services.StatusSyncing)
 
 	switch payload.Provider {
 	case "google":
-		return h.syncGoogleCalendar(ctx, payload)
+		err = h.syncGoogleCalendar(ctx, payload)
 	default:
-		return fmt.Errorf("unsupported provider: %s", payload.Provider)
+		err = fmt.Errorf("unsupported provider: %s", payload.Provider)
+	}
+
+	// Only the last attempt is worth telling anyone about; until then the
+	// integration stays syncing while the retry waits
+	switch {
+	case err == nil:
+		h.setIntegrationStatus(ctx, payload, services.StatusConnected)
+	case job.RetryCount >= job.MaxRetries:
+		h.setIntegrationStatus(ctx, payload, services.StatusError)
+		h.notifySyncFailure(ctx, payload)
+	}
+	return err
+}
+
+// setIntegrationStatus shows the sync's progress on the member's integration
+func (h *CalendarSyncHandler) setIntegrationStatus(ctx context.Context, payload CalendarSyncPayload, status services.Status) {
+	if err := h.serviceRegistry.Integrations.SetCalendarSyncStatus(payload.UserID, services.Provider(payload.Provider), status); err != nil {
+		jobsystem.LoggerFromContext(ctx).Warn("failed to update integration status", "error", err)
 	}
 }
 
+// notifySyncFailure pushes a notification to the member whose calendar
+// stopped syncing
+func (h *CalendarSyncHandler) notifySyncFailure(ctx context.Context, payload CalendarSyncPayload) {
+	if h.jobSystem == nil || h.serviceRegistry.Notifications.WebPushKey() == "" {
+		return
+	}
+	locale, err := h.serviceRegistry.Families.Locale(payload.FamilyID)
+	if err != nil {
+		jobsystem.LoggerFromContext(ctx).Warn("failed to get family locale", "error", err)
+		locale = templates.DefaultLocale
+	}
+	printer := templates.NewPrinter(locale)
+	err = EnqueuePushNotification(h.jobSystem, payload.UserID, notify.Message{
+		Title: printer.Sprintf("calendar_sync.failed.title"),
+		Body:  printer.Sprintf("calendar_sync.failed.body"),
+		URL:   "/integrations",
+		Tag:   "calendar-sync-" + payload.Provider,
+	})
+	if err != nil {
+		jobsystem.LoggerFromContext(ctx).Warn("failed to queue sync failure notification", "error", err)
+	}
+}
+
+// calendarSyncCheckpoint is how far a sync of all a user's calendars got
+type calendarSyncCheckpoint struct {
+	TimeMin       time.Time `json:"time_min"`
+	TimeMax       time.Time `json:"time_max"`
+	Calendars     []string  `json:"calendars"` // IDs of the calendars already synced
+	EventsSynced  int       `json:"events_synced"`
+	EventsRemoved int       `json:"events_removed"`
+	FullSync      bool      `json:"full_sync"` // whether any calendar was synced in full
+}
+
+func (c *calendarSyncCheckpoint) add(delta calendarDelta) {
+	c.EventsSynced += delta.synced
+	c.EventsRemoved += delta.removed
+	c.FullSync = c.FullSync || delta.full
+}
+
+// calendarDelta is what syncing one calendar changed
+type calendarDelta struct {
+	synced  int  // events added or updated
+	removed int  // events cancelled since the last sync
+	full    bool // whether every event in the range was fetched
+}
+
+func (c *calendarSyncCheckpoint) done(calendarID string) bool {
+	return slices.Contains(c.Calendars, calendarID)
+}
+
 // syncGoogleCalendar synchronizes Google Calendar events
-func (h *CalendarSyncHandler) syncGoogleCalendar(ctx context.Context, payload CalendarSyncPayload) error {
+func (h *CalendarSyncHandler) syncGoogleCalendar(ctx context.Context, payload CalendarSyncPayload) (err error) {
+	logger := jobsystem.LoggerFromContext(ctx)
+	startedAt := time.Now()
+
 	// Get sync settings for user
 	settings, err := h.getSyncSettings(payload.UserID)
 	if err != nil {
@@ -80,84 +183,213 @@ func (h *CalendarSyncHandler) syncGoogleCalendar(ctx context.Context, payload Ca
 	timeMin := now.Truncate(24 * time.Hour) // Start of today
 	timeMax := timeMin.AddDate(0, 0, settings.SyncRangeDays)
 
-	var totalEventsSynced int
+	// A sync cut short by a shutdown or crash resumes with the calendars it
+	// had not finished, over the range it started with
+	checkpoints := jobsystem.CheckpointsFromContext(ctx)
+	var progress calendarSyncCheckpoint
+	if resumed, err := checkpoints.Load(&progress); err != nil {
+		logger.Warn("failed to load sync checkpoint, starting over", "error", err)
+		progress = calendarSyncCheckpoint{}
+	} else if resumed {
+		timeMin, timeMax = progress.TimeMin, progress.TimeMax
+		logger.Info("resuming calendar sync", "calendars_done", len(progress.Calendars))
+	}
+	progress.TimeMin, progress.TimeMax = timeMin, timeMax
+
+	// The integration keeps the calendars' sync tokens and the run's history
+	integrationID := ""
+	integration, err := h.serviceRegistry.Integrations.CalendarIntegration(payload.UserID, services.ProviderGoogle)
+	if err != nil {
+		logger.Warn("failed to find calendar integration, syncing in full", "error", err)
+	} else if integration != nil {
+		integrationID = integration.ID
+		defer func() {
+			// A run stopped by a shutdown resumes later and is recorded then
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			h.recordSyncRun(ctx, payload, integrationID, startedAt, progress, err)
+		}()
+	}
 
 	// If no specific calendar ID, get all calendars for user
 	if payload.CalendarID == "" {
-		calendars, err := h.googleClient.GetCalendars(payload.UserID)
+		calendars, err := h.googleClient.GetCalendars(ctx, payload.UserID)
 		if err != nil {
 			if updateErr := h.updateSyncStatus(payload.UserID, "error", fmt.Sprintf("Failed to get calendars: %v", err), 0); updateErr != nil {
-				log.Printf("Failed to update sync status: %v", updateErr)
+				logger.Warn("failed to update sync status", "error", updateErr)
 			}
 			return fmt.Errorf("failed to get calendars: %w", err)
 		}
 
 		// Sync each calendar
 		for _, cal := range calendars {
+			if progress.done(cal.ID) {
+				continue
+			}
 			if cal.AccessRole == "reader" || cal.AccessRole == "writer" || cal.AccessRole == "owner" {
-				eventsSynced, err := h.syncCalendarEvents(payload.UserID, payload.FamilyID, cal.ID, timeMin, timeMax)
+				// Stop between calendars when the job system is shutting down
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				delta, err := h.syncCalendarEvents(ctx, payload, integrationID, cal.ID, timeMin, timeMax)
 				if err != nil {
-					log.Printf("Failed to sync calendar %s: %v", cal.ID, err)
+					logger.Error("failed to sync calendar", "calendar_id", cal.ID, "error", err)
 					continue
 				}
-				totalEventsSynced += eventsSynced
+
+				progress.Calendars = append(progress.Calendars, cal.ID)
+				progress.add(delta)
+				if err := checkpoints.Save(progress); err != nil {
+					logger.Warn("failed to save sync checkpoint", "error", err)
+				}
 			}
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 	} else {
 		// Sync specific calendar
-		eventsSynced, err := h.syncCalendarEvents(payload.UserID, payload.FamilyID, payload.CalendarID, timeMin, timeMax)
+		delta, err := h.syncCalendarEvents(ctx, payload, integrationID, payload.CalendarID, timeMin, timeMax)
 		if err != nil {
 			if updateErr := h.updateSyncStatus(payload.UserID, "error", fmt.Sprintf("Failed to sync calendar: %v", err), 0); updateErr != nil {
-				log.Printf("Failed to update sync status: %v", updateErr)
+				logger.Warn("failed to update sync status", "error", updateErr)
 			}
 			return fmt.Errorf("failed to sync calendar events: %w", err)
 		}
-		totalEventsSynced = eventsSynced
+		progress.add(delta)
+	}
+
+	if err := checkpoints.Clear(); err != nil {
+		logger.Warn("failed to clear sync checkpoint", "error", err)
 	}
 
 	// Update sync status to success
-	if err := h.updateSyncStatus(payload.UserID, "success", "", totalEventsSynced); err != nil {
-		log.Printf("Failed to update sync status: %v", err)
+	if err := h.updateSyncStatus(payload.UserID, "success", "", progress.EventsSynced); err != nil {
+		logger.Warn("failed to update sync status", "error", err)
+	}
+
+	if err := h.serviceRegistry.Analytics.Record(payload.FamilyID, models.UsageMetricCalendarSync); err != nil {
+		logger.Warn("failed to record sync usage", "error", err)
 	}
 
-	log.Printf("Calendar sync completed for user %s. Synced %d events", payload.UserID, totalEventsSynced)
+	logger.Info("calendar sync completed", "user_id", payload.UserID,
+		"events_synced", progress.EventsSynced, "events_removed", progress.EventsRemoved, "full_sync", progress.FullSync)
 	return nil
 }
 
-// syncCalendarEvents syncs events from a specific calendar
-func (h *CalendarSyncHandler) syncCalendarEvents(userID, familyID, calendarID string, timeMin, timeMax time.Time) (int, error) {
-	// Get events from Google Calendar
-	events, err := h.googleClient.GetEvents(userID, calendarID, timeMin, timeMax)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get events: %w", err)
+// recordSyncRun adds the run to the integration's sync history
+func (h *CalendarSyncHandler) recordSyncRun(ctx context.Context, payload CalendarSyncPayload, integrationID string, startedAt time.Time, progress calendarSyncCheckpoint, syncErr error) {
+	run := services.SyncRun{
+		SyncType:  "scheduled",
+		Mode:      services.SyncModeIncremental,
+		StartedAt: startedAt,
+		Changed:   progress.EventsSynced,
+		Removed:   progress.EventsRemoved,
+		Err:       syncErr,
+	}
+	if payload.ForceSync {
+		run.SyncType = "manual"
+	}
+	if progress.FullSync {
+		run.Mode = services.SyncModeFull
 	}
+	if err := h.serviceRegistry.Integrations.RecordSyncRun(integrationID, run); err != nil {
+		jobsystem.LoggerFromContext(ctx).Warn("failed to record sync history", "error", err)
+	}
+}
 
-	eventsSynced := 0
+// syncCalendarEvents syncs events from a specific calendar. With a sync
+// token saved from the last run only the changes since are fetched; without
+// one, or once Google stops accepting it, every event from timeMin to
+// timeMax is.
+func (h *CalendarSyncHandler) syncCalendarEvents(ctx context.Context, payload CalendarSyncPayload, integrationID, calendarID string, timeMin, timeMax time.Time) (delta calendarDelta, err error) {
+	ctx, span := tracing.Start(ctx, "calendar.sync_calendar", attribute.String("calendar.id", calendarID))
+	defer func() {
+		span.SetAttributes(
+			attribute.Int("calendar.events_synced", delta.synced),
+			attribute.Int("calendar.events_removed", delta.removed),
+			attribute.Bool("calendar.full_sync", delta.full),
+		)
+		tracing.End(span, err)
+	}()
+
+	logger := jobsystem.LoggerFromContext(ctx).With("calendar_id", calendarID)
+
+	syncToken := ""
+	if integrationID != "" && !payload.FullSync {
+		syncToken, err = h.serviceRegistry.Integrations.SyncToken(integrationID, calendarID)
+		if err != nil {
+			logger.Warn("failed to load sync token, syncing in full", "error", err)
+			syncToken = ""
+		}
+	}
 
-	// Process each event
-	for _, event := range events {
-		// Skip cancelled events
+	// Get events from Google Calendar
+	changes, err := h.googleClient.GetEventChanges(ctx, payload.UserID, calendarID, timeMin, timeMax, syncToken)
+	if errors.Is(err, calendar.ErrSyncTokenExpired) {
+		logger.Info("sync token expired, syncing in full")
+		changes, err = h.googleClient.GetEventChanges(ctx, payload.UserID, calendarID, timeMin, timeMax, "")
+	}
+	if err != nil {
+		return calendarDelta{}, fmt.Errorf("failed to get events: %w", err)
+	}
+	delta.full = changes.Full
+
+	// Convert every event first so the whole calendar is written in one batch
+	batch := make([]*services.CalendarEventForSync, 0, len(changes.Events))
+	var cancelled []string
+	for _, event := range changes.Events {
+		// A full sync leaves cancelled events out; an incremental one removes
+		// the copies earlier syncs stored
 		if event.Status == "cancelled" {
+			if !changes.Full {
+				cancelled = append(cancelled, event.ID)
+			}
 			continue
 		}
 
 		// Convert Google event to our calendar event format
-		calEvent, err := h.convertGoogleEvent(event, familyID, userID)
+		calEvent, err := h.convertGoogleEvent(event, payload.FamilyID, payload.UserID)
 		if err != nil {
-			log.Printf("Failed to convert event %s: %v", event.ID, err)
+			logger.Warn("failed to convert event", "event_id", event.ID, "error", err)
 			continue
 		}
 
-		// Insert or update event in database
-		if err := h.upsertCalendarEvent(calEvent); err != nil {
-			log.Printf("Failed to upsert event %s: %v", event.ID, err)
-			continue
+		batch = append(batch, toSyncEvent(calEvent))
+	}
+
+	if len(batch) > 0 {
+		_, upsertSpan := tracing.Start(ctx, "CalendarService.UpsertCalendarEvents", attribute.Int("calendar.events", len(batch)))
+		results, err := h.serviceRegistry.Calendar.UpsertCalendarEvents(batch)
+		tracing.End(upsertSpan, err)
+		if err != nil {
+			return calendarDelta{}, fmt.Errorf("failed to store events: %w", err)
 		}
 
-		eventsSynced++
+		for _, result := range results {
+			if result.Status != models.BulkEventStatusCreated {
+				logger.Warn("failed to upsert event", "event_id", batch[result.Index].SourceID, "error", result.Error)
+				continue
+			}
+			delta.synced++
+		}
 	}
 
-	return eventsSynced, nil
+	delta.removed, err = h.serviceRegistry.Calendar.DeleteSyncedCalendarEvents(payload.FamilyID, "google", cancelled)
+	if err != nil {
+		return calendarDelta{}, fmt.Errorf("failed to remove cancelled events: %w", err)
+	}
+
+	// The token is only kept once the changes it follows are stored, so a
+	// failed run fetches them again
+	if integrationID != "" && changes.NextSyncToken != "" {
+		if err := h.serviceRegistry.Integrations.SaveSyncToken(integrationID, calendarID, changes.NextSyncToken); err != nil {
+			logger.Warn("failed to save sync token", "error", err)
+		}
+	}
+
+	return delta, nil
 }
 
 // convertGoogleEvent converts a Google Calendar event to our internal format
@@ -174,10 +406,19 @@ func (h *CalendarSyncHandler) convertGoogleEvent(googleEvent calendar.GoogleEven
 		return nil, fmt.Errorf("failed to parse end time: %w", err)
 	}
 
-	// Convert attendees
-	var attendees []string
+	// Convert attendees; the service matches their emails to members
+	var attendees []services.SyncedAttendee
 	for _, attendee := range googleEvent.Attendees {
-		attendees = append(attendees, attendee.Email)
+		attendees = append(attendees, services.SyncedAttendee{
+			Email:    attendee.Email,
+			Name:     attendee.DisplayName,
+			Response: attendee.ResponseStatus,
+		})
+	}
+
+	var organizer string
+	if googleEvent.Organizer != nil {
+		organizer = googleEvent.Organizer.Email
 	}
 
 	// Determine if this is a recurring event
@@ -195,6 +436,7 @@ func (h *CalendarSyncHandler) convertGoogleEvent(googleEvent calendar.GoogleEven
 		EndTime:             &endTime,
 		AllDay:              googleEvent.Start.Date != "", // All-day if date instead of dateTime
 		Attendees:           attendees,
+		Organizer:           organizer,
 		SourceType:          "google",
 		SourceID:            googleEvent.ID,
 		IsRecurring:         isRecurring,
@@ -219,18 +461,19 @@ func (h *CalendarSyncHandler) parseGoogleDateTime(dt calendar.GoogleDateTime) (t
 
 // CalendarEvent represents our internal calendar event structure
 type CalendarEvent struct {
-	ID          string     `json:"id"`
-	FamilyID    string     `json:"family_id"`
-	CreatedBy   string     `json:"created_by"`
-	Title       string     `json:"title"`
-	Description string     `json:"description"`
-	Location    string     `json:"location"`
-	StartTime   time.Time  `json:"start_time"`
-	EndTime     *time.Time `json:"end_time"`
-	AllDay      bool       `json:"all_day"`
-	Attendees   []string   `json:"attendees"`
-	SourceType  string     `json:"source_type"`
-	SourceID    string     `json:"source_id"`
+	ID          string                    `json:"id"`
+	FamilyID    string                    `json:"family_id"`
+	CreatedBy   string                    `json:"created_by"`
+	Title       string                    `json:"title"`
+	Description string                    `json:"description"`
+	Location    string                    `json:"location"`
+	StartTime   time.Time                 `json:"start_time"`
+	EndTime     *time.Time                `json:"end_time"`
+	AllDay      bool                      `json:"all_day"`
+	Attendees   []services.SyncedAttendee `json:"attendees"`
+	Organizer   string                    `json:"organizer"`
+	SourceType  string                    `json:"source_type"`
+	SourceID    string                    `json:"source_id"`
 	// Recurring event fields
 	IsRecurring         bool      `json:"is_recurring"`
 	RecurrenceRules     []string  `json:"recurrence_rules,omitempty"`
@@ -240,10 +483,9 @@ type CalendarEvent struct {
 	UpdatedAt           time.Time `json:"updated_at"`
 }
 
-// upsertCalendarEvent inserts or updates a calendar event
-func (h *CalendarSyncHandler) upsertCalendarEvent(event *CalendarEvent) error {
-	serviceEvent := &services.CalendarEventForSync{
-		ID:          event.ID,
+// toSyncEvent maps a converted provider event onto the service's sync representation
+func toSyncEvent(event *CalendarEvent) *services.CalendarEventForSync {
+	return &services.CalendarEventForSync{
 		FamilyID:    event.FamilyID,
 		CreatedBy:   event.CreatedBy,
 		Title:       event.Title,
@@ -253,13 +495,12 @@ func (h *CalendarSyncHandler) upsertCalendarEvent(event *CalendarEvent) error {
 		EndTime:     event.EndTime,
 		AllDay:      event.AllDay,
 		Attendees:   event.Attendees,
+		Organizer:   event.Organizer,
 		SourceType:  event.SourceType,
 		SourceID:    event.SourceID,
 		CreatedAt:   event.CreatedAt,
 		UpdatedAt:   event.UpdatedAt,
 	}
-
-	return h.serviceRegistry.Calendar.UpsertCalendarEvent(serviceEvent)
 }
 
 // getSyncSettings retrieves sync settings for a user
@@ -0,0 +1,187 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"famstack/internal/calendar"
+	"famstack/internal/database"
+	"famstack/internal/httpfixture"
+	"famstack/internal/services"
+)
+
+// fixtureClient replays a recorded Google exchange from the calendar package
+func fixtureClient(t *testing.T, name string) *calendar.GoogleClient {
+	t.Helper()
+	transport := httpfixture.New(t, filepath.Join("..", "calendar", "testdata", "google", name+".json"), false, nil)
+	return calendar.NewGoogleClientWithTransport(func(ctx context.Context, userID string) (oauth2.TokenSource, error) {
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "fixture-access-token", TokenType: "Bearer"}), nil
+	}, transport)
+}
+
+// syncPayload is the sync job of the member setupSyncHandler's family
+var syncPayload = CalendarSyncPayload{UserID: "user_sync", FamilyID: "fam_sync", Provider: "google"}
+
+func setupSyncHandler(t *testing.T, fixture string) (*CalendarSyncHandler, *database.Fascade) {
+	db := setupSyncDB(t)
+	return NewCalendarSyncHandler(services.NewRegistry(db, nil), nil, fixtureClient(t, fixture)), db
+}
+
+// setupSyncDB creates a database with the family whose calendars are synced
+func setupSyncDB(t *testing.T) *database.Fascade {
+	dbFile := fmt.Sprintf("test_db_%d.db", time.Now().UnixNano())
+	db, err := database.New(dbFile)
+	require.NoError(t, err)
+	require.NoError(t, db.MigrateUp())
+	t.Cleanup(func() {
+		db.Close()
+		os.Remove(dbFile)
+	})
+
+	_, err = db.Exec(`INSERT INTO families (id, name, timezone) VALUES (?, ?, ?)`, "fam_sync", "Sync Family", "America/New_York")
+	require.NoError(t, err)
+	return db
+}
+
+func TestSyncCalendarEvents_StoresEveryPage(t *testing.T) {
+	handler, db := setupSyncHandler(t, "events_paginated")
+
+	timeMin := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	delta, err := handler.syncCalendarEvents(context.Background(), syncPayload, "", "primary", timeMin, timeMin.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	assert.Equal(t, calendarDelta{synced: 4, full: true}, delta)
+
+	var allDay bool
+	require.NoError(t, db.QueryRow(`SELECT all_day FROM calendar_events WHERE external_id = ?`, "0c2springbreak").Scan(&allDay))
+	assert.True(t, allDay)
+}
+
+func TestSyncCalendarEvents_SkipsMalformedAndCancelledEvents(t *testing.T) {
+	handler, db := setupSyncHandler(t, "events_malformed")
+
+	timeMin := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	delta, err := handler.syncCalendarEvents(context.Background(), syncPayload, "", "primary", timeMin, timeMin.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	assert.Equal(t, 2, delta.synced)
+	assert.Zero(t, delta.removed, "a full sync has nothing to remove")
+
+	rows, err := db.Query(`SELECT external_id FROM calendar_events WHERE family_id = ? ORDER BY external_id`, "fam_sync")
+	require.NoError(t, err)
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		require.NoError(t, rows.Scan(&id))
+		ids = append(ids, id)
+	}
+	assert.Equal(t, []string{"bare1", "ok1"}, ids)
+}
+
+func TestSyncCalendarEvents_SurfacesAuthorizationErrors(t *testing.T) {
+	handler, _ := setupSyncHandler(t, "events_unauthorized")
+
+	timeMin := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	_, err := handler.syncCalendarEvents(context.Background(), syncPayload, "", "primary", timeMin, timeMin.AddDate(0, 1, 0))
+	assert.ErrorIs(t, err, calendar.ErrUnauthorized)
+}
+
+// seedSyncIntegration adds the member's Google integration, which keeps the
+// sync tokens
+func seedSyncIntegration(t *testing.T, db *database.Fascade) string {
+	t.Helper()
+	now := time.Now()
+	_, err := db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, is_active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, "user_sync", "fam_sync", "Sync", "Parent", "adult", true, now, now)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO integrations (id, family_id, created_by, integration_type, provider, auth_method, status,
+								 display_name, description, settings, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, "integration_sync", "fam_sync", "user_sync", services.TypeCalendar, services.ProviderGoogle, services.AuthOAuth2,
+		services.StatusConnected, "Google Calendar", "", "{}", true, now, now)
+	require.NoError(t, err)
+	return "integration_sync"
+}
+
+func TestSyncCalendarEvents_AppliesChangesSinceLastSync(t *testing.T) {
+	handler, db := setupSyncHandler(t, "events_incremental")
+	integrationID := seedSyncIntegration(t, db)
+	ctx := context.Background()
+	timeMin := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	delta, err := handler.syncCalendarEvents(ctx, syncPayload, integrationID, "primary", timeMin, timeMin.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	assert.Equal(t, calendarDelta{synced: 2, full: true}, delta)
+
+	token, err := handler.serviceRegistry.Integrations.SyncToken(integrationID, "primary")
+	require.NoError(t, err)
+	assert.Equal(t, "CPDAlvWDx70CEPDAlvWDx70CGAU=", token)
+
+	// The next run fetches only the rename and the cancellation
+	delta, err = handler.syncCalendarEvents(ctx, syncPayload, integrationID, "primary", timeMin, timeMin.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	assert.Equal(t, calendarDelta{synced: 1, removed: 1}, delta)
+
+	var title string
+	require.NoError(t, db.QueryRow(`SELECT title FROM calendar_events WHERE external_id = ?`, "4kq1n2soccer").Scan(&title))
+	assert.Equal(t, "Soccer practice (field 2)", title)
+	var remaining int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM calendar_events WHERE external_id = ?`, "0c2springbreak").Scan(&remaining))
+	assert.Zero(t, remaining)
+
+	token, err = handler.serviceRegistry.Integrations.SyncToken(integrationID, "primary")
+	require.NoError(t, err)
+	assert.Equal(t, "CKiP-Nf2x70CEKiP-Nf2x70CGAU=", token)
+}
+
+func TestSyncCalendarEvents_ResyncsInFullWhenTokenExpires(t *testing.T) {
+	handler, db := setupSyncHandler(t, "events_sync_token_expired")
+	integrationID := seedSyncIntegration(t, db)
+	require.NoError(t, handler.serviceRegistry.Integrations.SaveSyncToken(integrationID, "primary", "CPDAlvWDx70CEPDAlvWDx70CGAU="))
+
+	timeMin := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	delta, err := handler.syncCalendarEvents(context.Background(), syncPayload, integrationID, "primary", timeMin, timeMin.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	assert.Equal(t, calendarDelta{synced: 1, full: true}, delta)
+
+	token, err := handler.serviceRegistry.Integrations.SyncToken(integrationID, "primary")
+	require.NoError(t, err)
+	assert.Equal(t, "CKiP-Nf2x70CEKiP-Nf2x70CGAU=", token)
+}
+
+func TestRecordSyncRun(t *testing.T) {
+	db := setupSyncDB(t)
+	integrationID := seedSyncIntegration(t, db)
+	handler := NewCalendarSyncHandler(services.NewRegistry(db, nil), nil, nil)
+
+	handler.recordSyncRun(context.Background(), CalendarSyncPayload{ForceSync: true}, integrationID, time.Now(),
+		calendarSyncCheckpoint{EventsSynced: 3, EventsRemoved: 2}, nil)
+
+	history, err := handler.serviceRegistry.Integrations.ListSyncHistory(integrationID, services.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, history.Items, 1)
+	run := history.Items[0]
+	assert.Equal(t, "manual", run.SyncType)
+	assert.Equal(t, services.SyncModeIncremental, run.SyncMode)
+	assert.Equal(t, 5, run.ItemsSynced)
+	assert.Equal(t, 3, run.ItemsChanged)
+	assert.Equal(t, 2, run.ItemsRemoved)
+}
+
+func TestCalendarSyncIdempotencyKey(t *testing.T) {
+	at := time.Date(2026, 3, 14, 9, 1, 0, 0, time.UTC)
+	key := CalendarSyncIdempotencyKey("user_1", "google", at)
+
+	assert.Equal(t, key, CalendarSyncIdempotencyKey("user_1", "google", at.Add(3*time.Minute)), "same window")
+	assert.NotEqual(t, key, CalendarSyncIdempotencyKey("user_1", "google", at.Add(CalendarSyncWindow)), "next window")
+	assert.NotEqual(t, key, CalendarSyncIdempotencyKey("user_2", "google", at))
+	assert.NotEqual(t, key, CalendarSyncIdempotencyKey("user_1", "microsoft", at))
+}
@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"famstack/internal/jobsystem"
+	"famstack/internal/services"
+)
+
+// CelebrationsJobType is the job that keeps every family's birthday and
+// anniversary series in step with its members
+const CelebrationsJobType = "celebrations_sync"
+
+// NewCelebrationsHandler syncs the celebrations of every family. Member
+// edits sync their family straight away; this catches series deleted or
+// edited on the calendar, and members whose edits failed to sync. A family
+// that fails is tried again on the next run; the job fails only when every
+// family did.
+func NewCelebrationsHandler(serviceRegistry *services.Registry) jobsystem.JobHandler {
+	return func(ctx context.Context, job *jobsystem.Job) error {
+		logger := jobsystem.LoggerFromContext(ctx)
+
+		families, err := serviceRegistry.Families.ListFamilies()
+		if err != nil {
+			return fmt.Errorf("failed to list families for celebrations: %w", err)
+		}
+
+		var failed, changed int
+		var lastErr error
+		for _, family := range families {
+			result, err := serviceRegistry.Calendar.SyncCelebrations(family.ID)
+			if err != nil {
+				logger.Error("Failed to sync celebrations", "family_id", family.ID, "error", err)
+				failed, lastErr = failed+1, err
+				continue
+			}
+			changed += result.Created + result.Updated + result.Removed
+		}
+		if failed > 0 && failed == len(families) {
+			return fmt.Errorf("failed to sync celebrations for %d families: %w", failed, lastErr)
+		}
+
+		logger.Info("Celebrations synced", "families", len(families), "changed", changed, "failed", failed)
+		return nil
+	}
+}
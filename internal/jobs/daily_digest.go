@@ -0,0 +1,129 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"famstack/internal/jobsystem"
+	"famstack/internal/models"
+	"famstack/internal/notify"
+	"famstack/internal/services"
+	"famstack/internal/templates"
+)
+
+// DailyDigestPayload names the member and the family-local day a digest covers
+type DailyDigestPayload struct {
+	MemberID string `json:"member_id"`
+	FamilyID string `json:"family_id"`
+	Date     string `json:"date"`
+	Channel  string `json:"channel"`
+}
+
+// NewDailyDigestPlannerHandler enqueues the daily digests whose time has come.
+// It runs every few minutes; the idempotency key sends each member one digest
+// per day however many runs see it due.
+func NewDailyDigestPlannerHandler(serviceRegistry *services.Registry, jobSystem JobEnqueuer) jobsystem.JobHandler {
+	return func(ctx context.Context, job *jobsystem.Job) error {
+		logger := jobsystem.LoggerFromContext(ctx)
+
+		due, err := serviceRegistry.Notifications.DigestsDue(time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to find due digests: %w", err)
+		}
+
+		for _, digest := range due {
+			idempotencyKey := fmt.Sprintf("daily_digest:%s:%s", digest.MemberID, digest.Date)
+			_, err := jobSystem.Enqueue(&jobsystem.EnqueueRequest{
+				QueueName: "default",
+				JobType:   "daily_digest",
+				Payload: map[string]interface{}{
+					"member_id": digest.MemberID,
+					"family_id": digest.FamilyID,
+					"date":      digest.Date,
+					"channel":   digest.Channel,
+				},
+				Priority:       1,
+				MaxRetries:     3,
+				IdempotencyKey: &idempotencyKey,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to enqueue digest for member %s: %w", digest.MemberID, err)
+			}
+		}
+
+		logger.Info("Daily digest planning completed", "due", len(due))
+		return nil
+	}
+}
+
+// NewDailyDigestHandler sends one member their agenda for the day. A day with
+// nothing on it sends nothing.
+func NewDailyDigestHandler(serviceRegistry *services.Registry, renderer *templates.Renderer) jobsystem.JobHandler {
+	return func(ctx context.Context, job *jobsystem.Job) error {
+		var payload DailyDigestPayload
+
+		payloadBytes, err := json.Marshal(job.Payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal job payload: %w", err)
+		}
+
+		if err := json.Unmarshal(payloadBytes, &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal daily digest payload: %w", err)
+		}
+
+		date, err := time.Parse("2006-01-02", payload.Date)
+		if err != nil {
+			return fmt.Errorf("invalid digest date %q: %w", payload.Date, err)
+		}
+
+		digest, err := serviceRegistry.Digests.BuildMemberAgenda(payload.FamilyID, payload.MemberID, date)
+		if err != nil {
+			return fmt.Errorf("failed to build digest: %w", err)
+		}
+		if digest.TotalItems == 0 {
+			return nil
+		}
+
+		locale, err := serviceRegistry.Families.Locale(payload.FamilyID)
+		if err != nil {
+			return err
+		}
+		msg, err := digestMessage(renderer, payload.Channel, locale, digest)
+		if err != nil {
+			return err
+		}
+
+		status, err := serviceRegistry.Notifications.Send(ctx, payload.Channel, payload.MemberID, msg)
+		if err != nil {
+			return fmt.Errorf("failed to send digest to %s: %w", payload.MemberID, err)
+		}
+
+		jobsystem.LoggerFromContext(ctx).Info("Daily digest processed", "member_id", payload.MemberID, "status", status)
+		return nil
+	}
+}
+
+// digestMessage words a digest for a channel in the family's locale. Email
+// carries the full agenda; a push notification only has room for a summary.
+func digestMessage(renderer *templates.Renderer, channel, locale string, digest *models.AgendaDigest) (notify.Message, error) {
+	rendered, err := renderer.Render("agenda", locale, templates.ChannelText, digest)
+	if err != nil {
+		return notify.Message{}, fmt.Errorf("failed to render digest: %w", err)
+	}
+
+	msg := notify.Message{
+		Title: rendered.Subject,
+		Body:  rendered.Body,
+		URL:   "/daily",
+		Tag:   "daily-digest",
+	}
+	if channel == notify.ChannelWebPush {
+		day := digest.Days[0]
+		printer := templates.NewPrinter(locale)
+		msg.Body = printer.Sprintf("digest.summary",
+			printer.Plural("digest.events", len(day.Events)), printer.Plural("digest.tasks", len(day.Tasks)))
+	}
+	return msg, nil
+}
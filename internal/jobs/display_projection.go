@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"famstack/internal/jobsystem"
+	"famstack/internal/services"
+)
+
+// NewDisplayProjectionHandler rebuilds display projections that were
+// invalidated or have aged out. Event-bus invalidation covers most changes;
+// this catches the clock moving the window and rebuilds lost to a restart.
+func NewDisplayProjectionHandler(serviceRegistry *services.Registry) jobsystem.JobHandler {
+	return func(ctx context.Context, job *jobsystem.Job) error {
+		logger := jobsystem.LoggerFromContext(ctx)
+
+		refreshed, err := serviceRegistry.DisplayProjections.RefreshStale(time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to refresh display projections: %w", err)
+		}
+
+		logger.Info("Display projection refresh completed", "refreshed", refreshed)
+		return nil
+	}
+}
@@ -0,0 +1,115 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"famstack/internal/eventbus"
+	"famstack/internal/jobsystem"
+	"famstack/internal/models"
+	"famstack/internal/notify"
+	"famstack/internal/services"
+	"famstack/internal/templates"
+)
+
+// PushNotificationJobType is the job that sends one web push message
+const PushNotificationJobType = "push_notification"
+
+type PushNotificationPayload struct {
+	MemberID string `json:"member_id"`
+	Title    string `json:"title"`
+	Body     string `json:"body"`
+	URL      string `json:"url"`
+	Tag      string `json:"tag"`
+}
+
+// EnqueuePushNotification queues a web push message to a member. Sending
+// happens on a worker so the caller isn't held up by the push service.
+func EnqueuePushNotification(jobSystem JobEnqueuer, memberID string, msg notify.Message) error {
+	_, err := jobSystem.Enqueue(&jobsystem.EnqueueRequest{
+		QueueName: "default",
+		JobType:   PushNotificationJobType,
+		Payload: map[string]interface{}{
+			"member_id": memberID,
+			"title":     msg.Title,
+			"body":      msg.Body,
+			"url":       msg.URL,
+			"tag":       msg.Tag,
+		},
+		Priority:   2,
+		MaxRetries: 3,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enqueue push notification: %w", err)
+	}
+	return nil
+}
+
+// NewPushNotificationHandler sends a queued web push message. A member with
+// no subscribed browser is skipped; push service errors are retried.
+func NewPushNotificationHandler(serviceRegistry *services.Registry) jobsystem.JobHandler {
+	return func(ctx context.Context, job *jobsystem.Job) error {
+		var payload PushNotificationPayload
+
+		payloadBytes, err := json.Marshal(job.Payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal job payload: %w", err)
+		}
+
+		if err := json.Unmarshal(payloadBytes, &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal push notification payload: %w", err)
+		}
+
+		status, err := serviceRegistry.Notifications.Send(ctx, notify.ChannelWebPush, payload.MemberID, notify.Message{
+			Title: payload.Title,
+			Body:  payload.Body,
+			URL:   payload.URL,
+			Tag:   payload.Tag,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to push notification to %s: %w", payload.MemberID, err)
+		}
+
+		jobsystem.LoggerFromContext(ctx).Info("Push notification processed", "member_id", payload.MemberID, "status", status)
+		return nil
+	}
+}
+
+// NewTaskAssignedNotifier returns an event bus handler that pushes a
+// notification to a task's new assignee
+func NewTaskAssignedNotifier(serviceRegistry *services.Registry, jobSystem JobEnqueuer) eventbus.Handler {
+	return func(event eventbus.Event) {
+		if serviceRegistry.Notifications.WebPushKey() == "" {
+			return // web push is off
+		}
+		task, err := serviceRegistry.Tasks.GetTask(event.SubjectID)
+		if err != nil || task.AssignedTo == nil || *task.AssignedTo == "" {
+			return
+		}
+
+		locale, err := serviceRegistry.Families.Locale(task.FamilyID)
+		if err != nil {
+			log.Printf("Failed to get locale for task %s: %v", task.ID, err)
+			return
+		}
+		msg := taskAssignedMessage(templates.NewPrinter(locale), task)
+		if err := EnqueuePushNotification(jobSystem, *task.AssignedTo, msg); err != nil {
+			log.Printf("Failed to queue assignment notification for task %s: %v", task.ID, err)
+		}
+	}
+}
+
+func taskAssignedMessage(printer *templates.Printer, task *models.Task) notify.Message {
+	body := printer.Sprintf("task.assigned")
+	if task.DueDate != nil {
+		body = printer.Sprintf("task.assigned_due", printer.DayMonth(*task.DueDate))
+	}
+	return notify.Message{
+		Title: task.Title,
+		Body:  body,
+		URL:   "/tasks",
+		Tag:   "task-" + task.ID,
+	}
+}
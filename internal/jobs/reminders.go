@@ -0,0 +1,80 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"famstack/internal/jobsystem"
+	"famstack/internal/services"
+)
+
+// ReminderPlanHorizon is how far ahead each planner run enqueues reminders.
+// It spans a few runs so one missed run doesn't make reminders late.
+const ReminderPlanHorizon = 5 * time.Minute
+
+type EventReminderPayload struct {
+	ReminderID      string `json:"reminder_id"`
+	OccurrenceStart string `json:"occurrence_start"` // RFC 3339, UTC
+}
+
+// NewReminderPlannerHandler enqueues a delivery job, set to run at the fire
+// time, for every reminder coming due. The idempotency key makes repeated
+// planning of the same occurrence harmless.
+func NewReminderPlannerHandler(serviceRegistry *services.Registry, jobSystem JobEnqueuer) jobsystem.JobHandler {
+	return func(ctx context.Context, job *jobsystem.Job) error {
+		logger := jobsystem.LoggerFromContext(ctx)
+
+		due, err := serviceRegistry.Reminders.PlanDue(time.Now(), ReminderPlanHorizon)
+		if err != nil {
+			return fmt.Errorf("failed to plan reminders: %w", err)
+		}
+
+		for _, reminder := range due {
+			runAt := reminder.FireAt.UTC()
+			idempotencyKey := fmt.Sprintf("reminder:%s:%d", reminder.ReminderID, reminder.OccurrenceStart.Unix())
+			_, err := jobSystem.Enqueue(&jobsystem.EnqueueRequest{
+				QueueName: "reminders",
+				JobType:   "event_reminder",
+				Payload: map[string]interface{}{
+					"reminder_id":      reminder.ReminderID,
+					"occurrence_start": reminder.OccurrenceStart.UTC().Format(time.RFC3339),
+				},
+				Priority:       2,
+				MaxRetries:     3,
+				RunAt:          &runAt,
+				IdempotencyKey: &idempotencyKey,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to enqueue reminder %s: %w", reminder.ReminderID, err)
+			}
+		}
+
+		logger.Info("Reminder planning completed", "due", len(due))
+		return nil
+	}
+}
+
+// NewEventReminderHandler delivers one reminder for one occurrence
+func NewEventReminderHandler(serviceRegistry *services.Registry) jobsystem.JobHandler {
+	return func(ctx context.Context, job *jobsystem.Job) error {
+		var payload EventReminderPayload
+
+		payloadBytes, err := json.Marshal(job.Payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal job payload: %w", err)
+		}
+
+		if err := json.Unmarshal(payloadBytes, &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal event reminder payload: %w", err)
+		}
+
+		occurrenceStart, err := time.Parse(time.RFC3339, payload.OccurrenceStart)
+		if err != nil {
+			return fmt.Errorf("invalid occurrence start %q: %w", payload.OccurrenceStart, err)
+		}
+
+		return serviceRegistry.Reminders.Deliver(ctx, payload.ReminderID, occurrenceStart.UTC(), time.Now())
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"famstack/internal/jobsystem"
+	"famstack/internal/models"
 	"famstack/internal/services"
 )
 
@@ -36,12 +37,12 @@ func NewScheduleMaintenanceHandler(serviceRegistry *services.Registry, jobSystem
 
 		// Enqueue 3 monthly generation jobs for each schedule that needs it
 		for _, schedule := range schedules {
-			err := enqueueMonthlyGenerationJobs(jobSystem, schedule.ID)
+			err := enqueueMonthlyGenerationJobs(jobSystem, &schedule)
 			if err != nil {
 				log.Printf("Failed to enqueue generation jobs for schedule %s: %v", schedule.ID, err)
 				continue
 			}
-			log.Printf("Enqueued 3 monthly generation jobs for schedule %s", schedule.ID)
+			log.Printf("Enqueued monthly generation jobs for schedule %s", schedule.ID)
 		}
 
 		log.Printf("Schedule maintenance completed - processed %d schedules", len(schedules))
@@ -49,14 +50,20 @@ func NewScheduleMaintenanceHandler(serviceRegistry *services.Registry, jobSystem
 	}
 }
 
-func enqueueMonthlyGenerationJobs(jobSystem JobEnqueuer, scheduleID string) error {
+func enqueueMonthlyGenerationJobs(jobSystem JobEnqueuer, schedule *models.TaskSchedule) error {
 	now := time.Now()
+	scheduleID := schedule.ID
 
 	// Enqueue 3 monthly generation jobs starting from next month
 	for i := 1; i <= 3; i++ {
 		startDate := time.Date(now.Year(), now.Month()+time.Month(i), 1, 0, 0, 0, 0, now.Location())
 		endDate := startDate.AddDate(0, 1, -1) // Last day of the month
 
+		// Skip months the schedule's dates or a pause leave without tasks
+		if !windowOverlaps(schedule, startDate, endDate) {
+			continue
+		}
+
 		payload := MonthlyTaskGenerationPayload{
 			ScheduleID: scheduleID,
 			StartDate:  startDate.Format("2006-01-02"),
@@ -87,3 +94,19 @@ func enqueueMonthlyGenerationJobs(jobSystem JobEnqueuer, scheduleID string) erro
 
 	return nil
 }
+
+// windowOverlaps reports whether any day from startDate to endDate is inside
+// the schedule's window
+func windowOverlaps(schedule *models.TaskSchedule, startDate, endDate time.Time) bool {
+	first, last := startDate.Format("2006-01-02"), endDate.Format("2006-01-02")
+	if schedule.EndDate != nil && first > *schedule.EndDate {
+		return false
+	}
+	if schedule.StartDate != nil && last < *schedule.StartDate {
+		return false
+	}
+	if schedule.PausedUntil != nil && last < *schedule.PausedUntil {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"famstack/internal/jobsystem"
+	"famstack/internal/models"
+	"famstack/internal/services"
+)
+
+// NewSuggestionEngineHandler runs the suggestion detectors for every family
+func NewSuggestionEngineHandler(serviceRegistry *services.Registry) jobsystem.JobHandler {
+	return func(ctx context.Context, job *jobsystem.Job) error {
+		logger := jobsystem.LoggerFromContext(ctx)
+
+		families, err := serviceRegistry.Families.ListFamilies()
+		if err != nil {
+			return fmt.Errorf("failed to list families: %w", err)
+		}
+
+		now := time.Now()
+		total := 0
+		for _, family := range families {
+			if !suggestionsEnabled(ctx, serviceRegistry, family.ID) {
+				continue
+			}
+			created, err := serviceRegistry.Suggestions.GenerateSuggestions(family.ID, now)
+			if err != nil {
+				// One family's bad data shouldn't block everyone else's suggestions
+				logger.Error("Failed to generate suggestions", "family_id", family.ID, "error", err)
+				continue
+			}
+			total += created
+		}
+
+		logger.Info("Suggestion engine completed", "families", len(families), "created", total)
+		return nil
+	}
+}
+
+// NewSuggestionDigestHandler stores each family's weekly suggestion digest
+func NewSuggestionDigestHandler(serviceRegistry *services.Registry) jobsystem.JobHandler {
+	return func(ctx context.Context, job *jobsystem.Job) error {
+		logger := jobsystem.LoggerFromContext(ctx)
+
+		families, err := serviceRegistry.Families.ListFamilies()
+		if err != nil {
+			return fmt.Errorf("failed to list families: %w", err)
+		}
+
+		now := time.Now()
+		for _, family := range families {
+			if !suggestionsEnabled(ctx, serviceRegistry, family.ID) {
+				continue
+			}
+			digest, err := serviceRegistry.Suggestions.BuildDigest(family.ID, now)
+			if err != nil {
+				logger.Error("Failed to build suggestion digest", "family_id", family.ID, "error", err)
+				continue
+			}
+			if err := serviceRegistry.Suggestions.SaveDigest(digest); err != nil {
+				logger.Error("Failed to save suggestion digest", "family_id", family.ID, "error", err)
+				continue
+			}
+			logger.Info("Suggestion digest saved", "family_id", family.ID, "open", len(digest.Open),
+				"applied", digest.Applied, "dismissed", digest.Dismissed)
+		}
+
+		return nil
+	}
+}
+
+// suggestionsEnabled reports whether the family has the suggestion engine
+// on; a family whose flags can't be read is left out this run
+func suggestionsEnabled(ctx context.Context, serviceRegistry *services.Registry, familyID string) bool {
+	enabled, err := serviceRegistry.Features.Enabled(familyID, models.FeatureSuggestions)
+	if err != nil {
+		jobsystem.LoggerFromContext(ctx).Error("Failed to check suggestions feature", "family_id", familyID, "error", err)
+		return false
+	}
+	return enabled
+}
@@ -50,6 +50,17 @@ func convertScheduleToLegacyFormat(schedule *models.TaskSchedule) *TaskSchedule
 		}
 	}
 
+	var rotationMembers []string
+	if schedule.RotationMembers != nil {
+		if err := json.Unmarshal([]byte(*schedule.RotationMembers), &rotationMembers); err != nil {
+			log.Printf("Failed to unmarshal rotation members for schedule %s: %v", schedule.ID, err)
+		}
+	}
+	rotationAnchor := ""
+	if schedule.RotationAnchor != nil {
+		rotationAnchor = *schedule.RotationAnchor
+	}
+
 	return &TaskSchedule{
 		ID:        schedule.ID,
 		FamilyID:  schedule.FamilyID,
@@ -68,6 +79,11 @@ func convertScheduleToLegacyFormat(schedule *models.TaskSchedule) *TaskSchedule
 		TimeOfDay:  schedule.TimeOfDay,
 		Priority:   schedule.Priority,
 		Points:     schedule.Points,
+
+		RotationStrategy: schedule.RotationStrategy,
+		RotationMembers:  rotationMembers,
+		RotationAnchor:   rotationAnchor,
+		RotationIndex:    schedule.RotationIndex,
 	}
 }
 
@@ -77,12 +93,45 @@ type TaskSchedule struct {
 	CreatedBy   string
 	Title       string
 	Description string
-	TaskType    string
+	TaskType    models.TaskType
 	AssignedTo  *string
 	DaysOfWeek  []string
 	TimeOfDay   *string
-	Priority    int
+	Priority    models.Priority
 	Points      int
+
+	RotationStrategy string
+	RotationMembers  []string
+	RotationAnchor   string
+	RotationIndex    int
+}
+
+// rotates reports whether the schedule takes turns between members
+func (s *TaskSchedule) rotates() bool {
+	return s.RotationStrategy != "" && s.RotationStrategy != models.RotationNone && len(s.RotationMembers) > 0
+}
+
+// rotationAssignee picks whose turn a task on date is. A member who is away
+// that day passes the task to the next member in the list who is here, and
+// per_task rotations move on past whoever got it.
+func (s *TaskSchedule) rotationAssignee(date time.Time, isPresent func(memberID string, date time.Time) bool) (string, bool, error) {
+	turn, err := models.RotationTurn(s.RotationStrategy, len(s.RotationMembers), s.RotationAnchor, s.RotationIndex, date)
+	if err != nil {
+		return "", false, err
+	}
+
+	for offset := range s.RotationMembers {
+		position := (turn + offset) % len(s.RotationMembers)
+		memberID := s.RotationMembers[position]
+		if !isPresent(memberID, date) {
+			continue
+		}
+		if s.RotationStrategy == models.RotationPerTask {
+			s.RotationIndex = (position + 1) % len(s.RotationMembers)
+		}
+		return memberID, true, nil
+	}
+	return "", false, nil
 }
 
 func generateMonthlyTasks(serviceRegistry *services.Registry, scheduleID, startDateStr, endDateStr string) error {
@@ -103,6 +152,29 @@ func generateMonthlyTasks(serviceRegistry *services.Registry, scheduleID, startD
 
 	schedule := convertScheduleToLegacyFormat(scheduleModel)
 
+	// A cron expression decides the days and time instead of days_of_week
+	var scheduleCron *models.ScheduleCron
+	if scheduleModel.CronExpr != nil {
+		scheduleCron, err = models.ParseScheduleCron(*scheduleModel.CronExpr)
+		if err != nil {
+			return fmt.Errorf("invalid cron expression for schedule %s: %w", scheduleID, err)
+		}
+		timeOfDay := scheduleCron.TimeOfDay()
+		schedule.TimeOfDay = &timeOfDay
+	}
+
+	// Seasonal profiles decide which dates this schedule is in effect
+	profiles, err := serviceRegistry.ScheduleProfiles.ListProfiles(schedule.FamilyID)
+	if err != nil {
+		return fmt.Errorf("failed to get schedule profiles: %w", err)
+	}
+
+	// Children who split time between households only get chores on their days here
+	isPresent, err := serviceRegistry.Custody.PresenceChecker(schedule.FamilyID, startDate, endDate)
+	if err != nil {
+		return fmt.Errorf("failed to get custody schedules: %w", err)
+	}
+
 	// Find existing tasks in the date range to avoid duplicates
 	existingTasks, err := serviceRegistry.Tasks.GetExistingTasksInRange(scheduleID, startDate, endDate)
 	if err != nil {
@@ -124,12 +196,16 @@ func generateMonthlyTasks(serviceRegistry *services.Registry, scheduleID, startD
 			continue
 		}
 
-		weekday := strings.ToLower(current.Weekday().String())
 		dayMatches := false
-		for _, day := range schedule.DaysOfWeek {
-			if weekday == strings.ToLower(day) {
-				dayMatches = true
-				break
+		if scheduleCron != nil {
+			dayMatches = scheduleCron.RunsOn(current)
+		} else {
+			weekday := strings.ToLower(current.Weekday().String())
+			for _, day := range schedule.DaysOfWeek {
+				if weekday == strings.ToLower(day) {
+					dayMatches = true
+					break
+				}
 			}
 		}
 
@@ -137,12 +213,35 @@ func generateMonthlyTasks(serviceRegistry *services.Registry, scheduleID, startD
 			continue
 		}
 
+		if !models.ScheduleRunsOn(profiles, schedule.ID, current) {
+			continue
+		}
+
+		if !scheduleModel.InWindow(current) {
+			continue
+		}
+
 		dateStr := current.Format("2006-01-02")
 		if existingDates[dateStr] {
 			log.Printf("Task already exists for schedule %s on %s, skipping", scheduleID, dateStr)
 			continue
 		}
 
+		assignedTo := schedule.AssignedTo
+		if schedule.rotates() {
+			memberID, found, turnErr := schedule.rotationAssignee(current, isPresent)
+			if turnErr != nil {
+				return fmt.Errorf("failed to pick rotation member: %w", turnErr)
+			}
+			if !found {
+				continue
+			}
+			assignedTo = &memberID
+		} else if assignedTo != nil && !isPresent(*assignedTo, current) {
+			continue
+		}
+
+		// The time of day is the family's; BulkCreateTasks stores it in UTC
 		var dueDate *time.Time
 		if schedule.TimeOfDay != nil {
 			timeStr := *schedule.TimeOfDay
@@ -159,7 +258,7 @@ func generateMonthlyTasks(serviceRegistry *services.Registry, scheduleID, startD
 			Title:       schedule.Title,
 			Description: schedule.Description,
 			TaskType:    schedule.TaskType,
-			AssignedTo:  schedule.AssignedTo,
+			AssignedTo:  assignedTo,
 			Priority:    schedule.Priority,
 			Points:      schedule.Points,
 			DueDate:     dueDate,
@@ -179,6 +278,12 @@ func generateMonthlyTasks(serviceRegistry *services.Registry, scheduleID, startD
 		return fmt.Errorf("failed to bulk create tasks: %w", err)
 	}
 
+	if schedule.RotationStrategy == models.RotationPerTask && schedule.RotationIndex != scheduleModel.RotationIndex {
+		if err := serviceRegistry.Schedules.SetRotationIndex(schedule.FamilyID, scheduleID, schedule.RotationIndex); err != nil {
+			return fmt.Errorf("failed to update rotation index: %w", err)
+		}
+	}
+
 	// Update last_generated_date if this range extends it
 	err = serviceRegistry.Schedules.UpdateLastGeneratedDate(scheduleID, endDate)
 	if err != nil {
@@ -0,0 +1,72 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"famstack/internal/models"
+)
+
+func TestTaskSchedule_RotationAssignee(t *testing.T) {
+	everyoneHome := func(memberID string, date time.Time) bool { return true }
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	weekly := &TaskSchedule{
+		RotationStrategy: models.RotationWeekly,
+		RotationMembers:  []string{"ava", "ben", "cal"},
+		RotationAnchor:   "2026-03-04", // a Wednesday; the turn runs Monday to Sunday
+	}
+	for days, want := range map[int]string{0: "ava", 6: "ava", 7: "ben", 14: "cal", 21: "ava", -1: "cal"} {
+		got, found, err := weekly.rotationAssignee(monday.AddDate(0, 0, days), everyoneHome)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, want, got, "%d days from the anchor week", days)
+	}
+
+	// Someone away hands their turn to the next member who is here
+	benAway := func(memberID string, date time.Time) bool { return memberID != "ben" }
+	got, found, err := weekly.rotationAssignee(monday.AddDate(0, 0, 7), benAway)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "cal", got)
+
+	perTask := &TaskSchedule{
+		RotationStrategy: models.RotationPerTask,
+		RotationMembers:  []string{"ava", "ben"},
+		RotationIndex:    1,
+	}
+	var order []string
+	for i := 0; i < 3; i++ {
+		got, _, err := perTask.rotationAssignee(monday, everyoneHome)
+		require.NoError(t, err)
+		order = append(order, got)
+	}
+	assert.Equal(t, []string{"ben", "ava", "ben"}, order)
+	assert.Equal(t, 0, perTask.RotationIndex)
+
+	nobodyHome := func(memberID string, date time.Time) bool { return false }
+	_, found, err = perTask.rotationAssignee(monday, nobodyHome)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestWindowOverlaps(t *testing.T) {
+	endDate, pausedUntil := "2026-05-10", "2026-04-15"
+	schedule := &models.TaskSchedule{EndDate: &endDate, PausedUntil: &pausedUntil}
+
+	month := func(m time.Month) (time.Time, time.Time) {
+		start := time.Date(2026, m, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1)
+	}
+	for m, want := range map[time.Month]bool{time.March: false, time.April: true, time.May: true, time.June: false} {
+		start, end := month(m)
+		assert.Equal(t, want, windowOverlaps(schedule, start, end), m.String())
+	}
+
+	assert.False(t, schedule.InWindow(time.Date(2026, 4, 14, 0, 0, 0, 0, time.UTC)), "paused")
+	assert.True(t, schedule.InWindow(time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC)), "resumes")
+	assert.False(t, schedule.InWindow(time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC)), "ended")
+}
@@ -0,0 +1,48 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"famstack/internal/jobsystem"
+	"famstack/internal/services"
+)
+
+// TaskRolloverJobType is the job that applies each family's task rollover
+// policy once its day is over
+const TaskRolloverJobType = "task_rollover"
+
+// NewTaskRolloverHandler rolls over the overdue tasks of every family.
+// Families' days end at different times, so it runs hourly and each family
+// is rolled over once its midnight has passed. A family that fails is tried
+// again on the next run; the job fails only when every family did.
+func NewTaskRolloverHandler(serviceRegistry *services.Registry) jobsystem.JobHandler {
+	return func(ctx context.Context, job *jobsystem.Job) error {
+		logger := jobsystem.LoggerFromContext(ctx)
+
+		families, err := serviceRegistry.Families.ListFamilies()
+		if err != nil {
+			return fmt.Errorf("failed to list families for task rollover: %w", err)
+		}
+
+		now := time.Now()
+		var failed, changed int
+		var lastErr error
+		for _, family := range families {
+			count, err := serviceRegistry.Tasks.RollOverTasks(family.ID, now)
+			if err != nil {
+				logger.Error("Failed to roll over tasks", "family_id", family.ID, "error", err)
+				failed, lastErr = failed+1, err
+				continue
+			}
+			changed += count
+		}
+		if failed > 0 && failed == len(families) {
+			return fmt.Errorf("failed to roll over tasks for %d families: %w", failed, lastErr)
+		}
+
+		logger.Info("Tasks rolled over", "families", len(families), "changed", changed, "failed", failed)
+		return nil
+	}
+}
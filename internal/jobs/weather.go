@@ -0,0 +1,48 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"famstack/internal/jobsystem"
+	"famstack/internal/services"
+	"famstack/internal/weather"
+)
+
+// WeatherRefreshJobType is the job that fetches forecasts for families whose
+// forecast is missing or stale
+const WeatherRefreshJobType = "weather_refresh"
+
+// NewWeatherRefreshHandler fetches a forecast for every family due one. A
+// family whose fetch fails is tried again on the next run; the job fails
+// only when every fetch did.
+func NewWeatherRefreshHandler(serviceRegistry *services.Registry, client *weather.Client) jobsystem.JobHandler {
+	return func(ctx context.Context, job *jobsystem.Job) error {
+		logger := jobsystem.LoggerFromContext(ctx)
+
+		families, err := serviceRegistry.Weather.FamiliesDue(time.Now())
+		if err != nil {
+			return err
+		}
+
+		var failed int
+		var lastErr error
+		for _, family := range families {
+			days, err := client.Forecast(ctx, *family.Latitude, *family.Longitude, family.Timezone)
+			if err == nil {
+				err = serviceRegistry.Weather.StoreForecast(family.ID, days)
+			}
+			if err != nil {
+				logger.Error("Failed to refresh weather forecast", "family_id", family.ID, "error", err)
+				failed, lastErr = failed+1, err
+			}
+		}
+		if failed > 0 && failed == len(families) {
+			return fmt.Errorf("failed to refresh weather for %d families: %w", failed, lastErr)
+		}
+
+		logger.Info("Weather refresh completed", "families", len(families), "failed", failed)
+		return nil
+	}
+}
@@ -0,0 +1,202 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"famstack/internal/eventbus"
+	"famstack/internal/jobsystem"
+	"famstack/internal/services"
+)
+
+// WebhookDeliveryJobType is the job that delivers one event to one IFTTT or
+// Zapier integration
+const WebhookDeliveryJobType = "webhook_delivery"
+
+type WebhookDeliveryPayload struct {
+	IntegrationID string    `json:"integration_id"`
+	Event         string    `json:"event"`
+	SubjectID     string    `json:"subject_id"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+// webhookBody is what a delivery POSTs
+type webhookBody struct {
+	ID         string    `json:"id"` // the same across retries, for receivers to drop duplicates
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	FamilyID   string    `json:"family_id"`
+	Data       any       `json:"data"`
+	// IFTTT's Webhooks service passes on only these three values
+	Value1 string `json:"value1,omitempty"`
+	Value2 string `json:"value2,omitempty"`
+	Value3 string `json:"value3,omitempty"`
+}
+
+// NewWebhookDispatcher returns an event bus handler that queues a delivery
+// for each IFTTT and Zapier integration subscribed to the change
+func NewWebhookDispatcher(serviceRegistry *services.Registry, jobSystem JobEnqueuer) eventbus.Handler {
+	return func(event eventbus.Event) {
+		name := webhookEvent(event)
+		if name == "" {
+			return
+		}
+		targets, err := serviceRegistry.Integrations.WebhookTargets(event.FamilyID, name)
+		if err != nil {
+			log.Printf("Failed to find webhooks for %s: %v", name, err)
+			return
+		}
+		for _, target := range targets {
+			_, err := jobSystem.Enqueue(&jobsystem.EnqueueRequest{
+				QueueName: "default",
+				JobType:   WebhookDeliveryJobType,
+				Payload: map[string]interface{}{
+					"integration_id": target.ID,
+					"event":          name,
+					"subject_id":     event.SubjectID,
+					"occurred_at":    time.Now().UTC().Format(time.RFC3339),
+				},
+				Priority:   2,
+				MaxRetries: 5,
+			})
+			if err != nil {
+				log.Printf("Failed to queue %s webhook for integration %s: %v", name, target.ID, err)
+			}
+		}
+	}
+}
+
+// webhookEvent names the webhook event a bus event is, or returns "" when
+// webhooks aren't sent for it
+func webhookEvent(event eventbus.Event) string {
+	switch {
+	case event.Topic == eventbus.TopicEventCreated:
+		return services.WebhookEventCreated
+	case event.Topic == eventbus.TopicTasksChanged && event.Detail == eventbus.DetailCreated:
+		return services.WebhookTaskCreated
+	case event.Topic == eventbus.TopicTasksChanged && event.Detail == eventbus.DetailCompleted:
+		return services.WebhookTaskCompleted
+	}
+	return ""
+}
+
+// NewWebhookDeliveryHandler delivers a queued webhook. Deliveries to
+// integrations since removed or disabled, and about records since deleted,
+// are dropped; receiver errors are retried.
+func NewWebhookDeliveryHandler(serviceRegistry *services.Registry, client *http.Client) jobsystem.JobHandler {
+	return func(ctx context.Context, job *jobsystem.Job) error {
+		var payload WebhookDeliveryPayload
+
+		payloadBytes, err := json.Marshal(job.Payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal job payload: %w", err)
+		}
+
+		if err := json.Unmarshal(payloadBytes, &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal webhook payload: %w", err)
+		}
+
+		logger := jobsystem.LoggerFromContext(ctx)
+		integration, err := serviceRegistry.Integrations.GetIntegration(payload.IntegrationID)
+		if err != nil || !integration.Enabled {
+			logger.Info("Webhook integration gone or disabled, dropping delivery", "integration_id", payload.IntegrationID)
+			return nil
+		}
+		settings := integration.WebhookSettings()
+		if target, err := url.Parse(settings.URL); err != nil || (target.Scheme != "https" && target.Scheme != "http") {
+			recordWebhook(serviceRegistry, integration.ID, time.Now(), fmt.Errorf("invalid webhook URL %q", settings.URL))
+			return nil
+		}
+
+		body, err := buildWebhookBody(serviceRegistry, integration, job.ID, payload)
+		if err != nil {
+			logger.Info("Webhook subject gone, dropping delivery", "event", payload.Event, "subject_id", payload.SubjectID)
+			return nil
+		}
+		secrets, err := serviceRegistry.Integrations.WebhookSecrets(integration.ID)
+		if err != nil {
+			return err
+		}
+
+		startedAt := time.Now()
+		deliveryErr := postWebhook(ctx, client, settings.URL, payload.Event, secrets.SigningSecret, body)
+		recordWebhook(serviceRegistry, integration.ID, startedAt, deliveryErr)
+		if deliveryErr != nil {
+			return fmt.Errorf("failed to deliver %s webhook to integration %s: %w", payload.Event, integration.ID, deliveryErr)
+		}
+
+		logger.Info("Webhook delivered", "integration_id", integration.ID, "event", payload.Event)
+		return nil
+	}
+}
+
+// buildWebhookBody describes the delivery's subject as it is now
+func buildWebhookBody(serviceRegistry *services.Registry, integration *services.Integration, deliveryID string, payload WebhookDeliveryPayload) ([]byte, error) {
+	body := webhookBody{
+		ID:         deliveryID,
+		Event:      payload.Event,
+		OccurredAt: payload.OccurredAt,
+		FamilyID:   integration.FamilyID,
+	}
+
+	var title string
+	switch payload.Event {
+	case services.WebhookEventCreated:
+		event, err := serviceRegistry.Calendar.GetFamilyEvent(integration.FamilyID, payload.SubjectID)
+		if err != nil {
+			return nil, fmt.Errorf("event %s not found", payload.SubjectID)
+		}
+		body.Data, title = event, event.Title
+	default:
+		task, err := serviceRegistry.Tasks.GetFamilyTask(integration.FamilyID, payload.SubjectID)
+		if err != nil {
+			return nil, fmt.Errorf("task %s not found", payload.SubjectID)
+		}
+		body.Data, title = task, task.Title
+	}
+
+	if integration.Provider == services.ProviderIFTTT {
+		body.Value1, body.Value2, body.Value3 = title, payload.Event, payload.SubjectID
+	}
+	return json.Marshal(body)
+}
+
+// postWebhook sends a signed delivery, failing unless the receiver answers
+// with a 2xx status
+func postWebhook(ctx context.Context, client *http.Client, target, event, secret string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "FamStack-Webhooks/1.0")
+	req.Header.Set("X-FamStack-Event", event)
+	req.Header.Set("X-FamStack-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set("X-FamStack-Signature", services.SignWebhook(secret, now, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("receiver answered %s", resp.Status)
+	}
+	return nil
+}
+
+func recordWebhook(serviceRegistry *services.Registry, integrationID string, startedAt time.Time, deliveryErr error) {
+	if err := serviceRegistry.Integrations.RecordWebhook(integrationID, startedAt, deliveryErr); err != nil {
+		log.Printf("Failed to record webhook for integration %s: %v", integrationID, err)
+	}
+}
@@ -0,0 +1,111 @@
+package jobs
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"famstack/internal/config"
+	"famstack/internal/encryption"
+	"famstack/internal/eventbus"
+	"famstack/internal/jobsystem"
+	"famstack/internal/models"
+	"famstack/internal/services"
+)
+
+func TestWebhookEvent(t *testing.T) {
+	assert.Equal(t, services.WebhookTaskCompleted, webhookEvent(eventbus.Event{Topic: eventbus.TopicTasksChanged, Detail: eventbus.DetailCompleted}))
+	assert.Equal(t, services.WebhookTaskCreated, webhookEvent(eventbus.Event{Topic: eventbus.TopicTasksChanged, Detail: eventbus.DetailCreated}))
+	assert.Equal(t, services.WebhookEventCreated, webhookEvent(eventbus.Event{Topic: eventbus.TopicEventCreated}))
+	assert.Empty(t, webhookEvent(eventbus.Event{Topic: eventbus.TopicTasksChanged, Detail: eventbus.DetailUpdated}))
+	assert.Empty(t, webhookEvent(eventbus.Event{Topic: eventbus.TopicCalendarChanged}))
+}
+
+// recordingEnqueuer keeps the jobs it is asked to enqueue
+type recordingEnqueuer struct {
+	requests []*jobsystem.EnqueueRequest
+}
+
+func (e *recordingEnqueuer) Enqueue(req *jobsystem.EnqueueRequest) (string, error) {
+	e.requests = append(e.requests, req)
+	return "job_" + strconv.Itoa(len(e.requests)), nil
+}
+
+// TestWebhookDelivery_IntegrationSavedThroughService goes from a saved
+// integration to a delivery without touching the integrations table, so the
+// enabled flag has to survive the service's own queries
+func TestWebhookDelivery_IntegrationSavedThroughService(t *testing.T) {
+	var deliveries []string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deliveries = append(deliveries, r.Header.Get("X-FamStack-Event"))
+	}))
+	defer receiver.Close()
+
+	db := setupSyncDB(t)
+	_, err := db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, is_active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"member_hook", "fam_sync", "Hook", "Parent", "adult", true, time.Now(), time.Now())
+	require.NoError(t, err)
+	encryptionSvc, err := encryption.NewService(config.EncryptionSettings{
+		FixedKey: &config.FixedKeyConfig{Value: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+	})
+	require.NoError(t, err)
+	serviceRegistry := services.NewRegistry(db, encryptionSvc)
+
+	_, err = serviceRegistry.Integrations.CreateIntegration("fam_sync", "member_hook", &services.CreateIntegrationRequest{
+		IntegrationType: services.TypeAutomation,
+		Provider:        services.ProviderZapier,
+		AuthMethod:      services.AuthWebhook,
+		DisplayName:     "Zapier",
+		Settings:        map[string]any{"webhook_url": receiver.URL, "events": []string{services.WebhookTaskCreated}},
+	})
+	require.NoError(t, err)
+	task, err := serviceRegistry.Tasks.CreateTask("fam_sync", "member_hook", &models.CreateTaskRequest{Title: "Dishes", TaskType: models.TaskTypeChore})
+	require.NoError(t, err)
+
+	enqueuer := &recordingEnqueuer{}
+	NewWebhookDispatcher(serviceRegistry, enqueuer)(eventbus.Event{
+		Topic: eventbus.TopicTasksChanged, FamilyID: "fam_sync", SubjectID: task.ID, Detail: eventbus.DetailCreated,
+	})
+	require.Len(t, enqueuer.requests, 1, "the saved integration is enabled and subscribed")
+
+	deliver := NewWebhookDeliveryHandler(serviceRegistry, receiver.Client())
+	require.NoError(t, deliver(context.Background(), &jobsystem.Job{ID: "job_1", Payload: enqueuer.requests[0].Payload}))
+	assert.Equal(t, []string{services.WebhookTaskCreated}, deliveries)
+}
+
+func TestPostWebhookSigns(t *testing.T) {
+	body := []byte(`{"event":"task.completed"}`)
+	var got *http.Request
+	var gotBody []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer receiver.Close()
+
+	require.NoError(t, postWebhook(context.Background(), receiver.Client(), receiver.URL, "task.completed", "secret", body))
+
+	assert.Equal(t, body, gotBody)
+	assert.Equal(t, "task.completed", got.Header.Get("X-FamStack-Event"))
+	timestamp, err := strconv.ParseInt(got.Header.Get("X-FamStack-Timestamp"), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, services.SignWebhook("secret", time.Unix(timestamp, 0), body), got.Header.Get("X-FamStack-Signature"))
+	assert.NotEqual(t, services.SignWebhook("other", time.Unix(timestamp, 0), body), got.Header.Get("X-FamStack-Signature"))
+}
+
+func TestPostWebhookFailsOnReceiverError(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	err := postWebhook(context.Background(), receiver.Client(), receiver.URL, "task.created", "secret", []byte(`{}`))
+	assert.ErrorContains(t, err, "503")
+}
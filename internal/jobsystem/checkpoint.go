@@ -0,0 +1,85 @@
+package jobsystem
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// CheckpointStore keeps a running job's progress. A handler that works
+// through something long, like a calendar sync, saves how far it got as it
+// goes; when the run is cut short by a shutdown, a timeout or a crash, the
+// job's next run loads the checkpoint and carries on from there instead of
+// starting over. Checkpoints survive retries and are the handler's to clear
+// once the work is done.
+type CheckpointStore interface {
+	// Load decodes the saved checkpoint into v. It reports false, leaving v
+	// alone, when nothing has been saved.
+	Load(v any) (bool, error)
+	// Save replaces the checkpoint with v encoded as JSON
+	Save(v any) error
+	// Clear removes the checkpoint
+	Clear() error
+}
+
+// jobCheckpointSink persists checkpoints for a job
+type jobCheckpointSink interface {
+	GetJobCheckpoint(jobID string) (*string, error)
+	SaveJobCheckpoint(jobID string, checkpoint *string) error
+}
+
+type checkpointContextKey struct{}
+
+// CheckpointsFromContext returns the checkpoint store the job system placed in
+// a job handler's context. Outside a job it returns a store that saves
+// nothing, so code shared with request handlers can checkpoint freely.
+func CheckpointsFromContext(ctx context.Context) CheckpointStore {
+	if store, ok := ctx.Value(checkpointContextKey{}).(CheckpointStore); ok {
+		return store
+	}
+	return noCheckpoints{}
+}
+
+func contextWithCheckpoints(ctx context.Context, store CheckpointStore) context.Context {
+	return context.WithValue(ctx, checkpointContextKey{}, store)
+}
+
+// jobCheckpoints stores checkpoints on the job's row
+type jobCheckpoints struct {
+	sink  jobCheckpointSink
+	jobID string
+}
+
+func (c *jobCheckpoints) Load(v any) (bool, error) {
+	checkpoint, err := c.sink.GetJobCheckpoint(c.jobID)
+	if err != nil {
+		return false, err
+	}
+	if checkpoint == nil {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(*checkpoint), v); err != nil {
+		return false, fmt.Errorf("failed to decode checkpoint: %w", err)
+	}
+	return true, nil
+}
+
+func (c *jobCheckpoints) Save(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	checkpoint := string(data)
+	return c.sink.SaveJobCheckpoint(c.jobID, &checkpoint)
+}
+
+func (c *jobCheckpoints) Clear() error {
+	return c.sink.SaveJobCheckpoint(c.jobID, nil)
+}
+
+// noCheckpoints is the store used outside a job
+type noCheckpoints struct{}
+
+func (noCheckpoints) Load(any) (bool, error) { return false, nil }
+func (noCheckpoints) Save(any) error         { return nil }
+func (noCheckpoints) Clear() error           { return nil }
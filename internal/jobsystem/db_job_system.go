@@ -3,27 +3,64 @@ package jobsystem
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 
 	"famstack/internal/services"
+	"famstack/internal/tracing"
 
 	cron "github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type DBJobSystem struct {
 	jobsService    *services.JobsService
 	config         *Config
-	handlers       map[string]JobHandler
+	handlers       map[string]*registeredHandler
 	workers        map[string]*dbWorkerPool
 	scheduler      *dbScheduler
 	metricsCleanup *time.Ticker
 	shutdownCh     chan struct{}
+	execCtx        context.Context
+	execCancel     context.CancelFunc
 	wg             sync.WaitGroup
 	mu             sync.RWMutex
 	running        bool
+
+	// inFlight holds the IDs of jobs whose handler is still running, which
+	// can outlast processJob when a handler ignores its timeout
+	inFlightMu sync.Mutex
+	inFlight   map[string]bool
+}
+
+// registeredHandler pairs a handler with its execution options. slots is a
+// semaphore enforcing MaxConcurrency and is nil when the type is unlimited.
+type registeredHandler struct {
+	handler JobHandler
+	opts    HandlerOptions
+	slots   chan struct{}
+}
+
+// tryAcquire reserves an execution slot without blocking
+func (h *registeredHandler) tryAcquire() bool {
+	if h.slots == nil {
+		return true
+	}
+	select {
+	case h.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (h *registeredHandler) release() {
+	if h.slots != nil {
+		<-h.slots
+	}
 }
 
 type dbWorkerPool struct {
@@ -53,12 +90,19 @@ func NewDBJobSystem(config *Config, jobsService *services.JobsService) *DBJobSys
 		config = DefaultConfig()
 	}
 
+	// execCtx is the parent of every handler context so in-flight jobs are
+	// cancelled when the job system stops
+	execCtx, execCancel := context.WithCancel(context.Background())
+
 	return &DBJobSystem{
 		jobsService: jobsService,
 		config:      config,
-		handlers:    make(map[string]JobHandler),
+		handlers:    make(map[string]*registeredHandler),
+		inFlight:    make(map[string]bool),
 		workers:     make(map[string]*dbWorkerPool),
 		shutdownCh:  make(chan struct{}),
+		execCtx:     execCtx,
+		execCancel:  execCancel,
 	}
 }
 
@@ -98,6 +142,31 @@ func (js *DBJobSystem) Enqueue(req *EnqueueRequest) (string, error) {
 	)
 }
 
+// EnqueueOnce enqueues req unless a job with its idempotency key already
+// exists, and says which job the caller got, so a caller can tell the user a
+// job they asked for again is already under way.
+func (js *DBJobSystem) EnqueueOnce(req *EnqueueRequest) (*EnqueueResult, error) {
+	if req == nil || req.IdempotencyKey == nil {
+		return nil, fmt.Errorf("enqueue once needs an idempotency key")
+	}
+
+	existing, err := js.jobsService.FindJobByIdempotencyKey(*req.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return &EnqueueResult{JobID: existing.ID, Status: JobStatus(existing.Status), Existing: true}, nil
+	}
+
+	// A request racing this one for the same key gets this job's ID back from
+	// the unique index rather than a second job
+	jobID, err := js.Enqueue(req)
+	if err != nil {
+		return nil, err
+	}
+	return &EnqueueResult{JobID: jobID, Status: JobStatusPending}, nil
+}
+
 func (js *DBJobSystem) Schedule(req *ScheduleRequest) error {
 	if req == nil {
 		return fmt.Errorf("schedule request cannot be nil")
@@ -128,10 +197,20 @@ func (js *DBJobSystem) Schedule(req *ScheduleRequest) error {
 	)
 }
 
-func (js *DBJobSystem) Register(jobType string, handler JobHandler) {
+// Register adds the handler for a job type. The options set the execution
+// timeout and an optional cap on concurrently running jobs of that type.
+func (js *DBJobSystem) Register(jobType string, handler JobHandler, opts HandlerOptions) {
+	registered := &registeredHandler{
+		handler: handler,
+		opts:    opts,
+	}
+	if opts.MaxConcurrency > 0 {
+		registered.slots = make(chan struct{}, opts.MaxConcurrency)
+	}
+
 	js.mu.Lock()
 	defer js.mu.Unlock()
-	js.handlers[jobType] = handler
+	js.handlers[jobType] = registered
 }
 
 func (js *DBJobSystem) Start(ctx context.Context) error {
@@ -144,6 +223,8 @@ func (js *DBJobSystem) Start(ctx context.Context) error {
 
 	log.Println("Starting DB job system...")
 
+	js.resetStaleJobs()
+
 	for queueName, concurrency := range js.config.WorkerConcurrency {
 		js.startWorkerPool(queueName, concurrency)
 	}
@@ -165,22 +246,24 @@ func (js *DBJobSystem) Start(ctx context.Context) error {
 	return nil
 }
 
+// Stop drains the job system. No new jobs are started; jobs already running
+// get Config.ShutdownGracePeriod to finish before their contexts are
+// cancelled. An interrupted job goes back on its queue with any checkpoint it
+// saved, and so do claimed jobs no worker had picked up yet.
 func (js *DBJobSystem) Stop() {
 	js.mu.Lock()
-	defer js.mu.Unlock()
-
 	if !js.running {
+		js.mu.Unlock()
 		return
 	}
+	// Workers look up handlers under the lock, so it is not held while they drain
+	js.running = false
+	js.mu.Unlock()
 
 	log.Println("Stopping DB job system...")
 
 	close(js.shutdownCh)
 
-	for _, pool := range js.workers {
-		js.stopWorkerPool(pool)
-	}
-
 	if js.scheduler != nil {
 		js.stopScheduler()
 	}
@@ -189,12 +272,67 @@ func (js *DBJobSystem) Stop() {
 		js.metricsCleanup.Stop()
 	}
 
+	drained := make(chan struct{})
+	go func() {
+		for _, pool := range js.workers {
+			js.stopWorkerPool(pool)
+		}
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(js.config.ShutdownGracePeriod):
+		log.Printf("Jobs still running after %v, interrupting them", js.config.ShutdownGracePeriod)
+		js.execCancel()
+		<-drained
+	}
+	js.execCancel()
+
 	js.wg.Wait()
-	js.running = false
+
+	for _, pool := range js.workers {
+		js.requeueUnstartedJobs(pool)
+	}
 
 	log.Println("DB job system stopped")
 }
 
+// requeueUnstartedJobs hands back jobs a stopped pool claimed but never ran.
+// It must run after the pool's poller has closed the job channel.
+func (js *DBJobSystem) requeueUnstartedJobs(pool *dbWorkerPool) {
+	for job := range pool.jobCh {
+		if err := js.jobsService.ResetJobToPending(job.ID); err != nil {
+			log.Printf("Failed to reset job %s to pending: %v", job.ID, err)
+		}
+	}
+}
+
+// resetStaleJobs requeues jobs still marked running long after any handler
+// would have timed out; their worker died without finishing them
+func (js *DBJobSystem) resetStaleJobs() {
+	cutoff := time.Now().Add(-js.staleJobAge())
+	reset, err := js.jobsService.ResetStaleRunningJobs(cutoff)
+	if err != nil {
+		log.Printf("Failed to reset stale jobs: %v", err)
+		return
+	}
+	if reset > 0 {
+		log.Printf("Requeued %d job(s) left running since before %v", reset, cutoff)
+	}
+}
+
+// staleJobAge is how long a job can be running before it is taken to have
+// lost its worker: longer than any handler's timeout plus the shutdown grace
+// period
+func (js *DBJobSystem) staleJobAge() time.Duration {
+	longest := js.config.DefaultJobTimeout
+	for _, registered := range js.handlers {
+		longest = max(longest, registered.opts.Timeout)
+	}
+	return longest + js.config.ShutdownGracePeriod + time.Minute
+}
+
 func (js *DBJobSystem) GetMetrics(queueName, jobType string) (*REDMetrics, error) {
 	timeWindow := 1 * time.Hour
 	metrics, err := js.jobsService.GetJobMetrics(queueName, jobType, timeWindow)
@@ -210,6 +348,7 @@ func (js *DBJobSystem) GetMetrics(queueName, jobType string) (*REDMetrics, error
 		P99LatencyMs:     metrics.AverageLatencyMs, // Using average as approximation
 		TotalJobs:        metrics.TotalJobs,
 		FailedJobs:       metrics.FailedJobs,
+		TimedOutJobs:     metrics.TimedOutJobs,
 		AverageLatencyMs: metrics.AverageLatencyMs,
 	}, nil
 }
@@ -244,11 +383,15 @@ func (js *DBJobSystem) startWorkerPool(queueName string, concurrency int) {
 	log.Printf("Started worker pool for queue '%s' with %d workers", queueName, concurrency)
 }
 
+// stopWorkerPool stops the pool's poller and workers and waits for the jobs
+// its workers are running to finish
 func (js *DBJobSystem) stopWorkerPool(pool *dbWorkerPool) {
 	close(pool.stopCh)
 
 	for _, worker := range pool.workers {
 		close(worker.stopCh)
+	}
+	for _, worker := range pool.workers {
 		<-worker.doneCh
 	}
 }
@@ -339,10 +482,28 @@ func (w *dbWorker) start() {
 
 	for {
 		select {
-		case job := <-w.pool.jobCh:
-			if job != nil {
-				w.processJob(job)
+		case <-w.stopCh:
+			// Jobs still queued for the pool are handed back by Stop
+			return
+		default:
+		}
+
+		select {
+		case job, ok := <-w.pool.jobCh:
+			if !ok {
+				<-w.stopCh
+				return
+			}
+			select {
+			case <-w.stopCh:
+				// Stopped while this job was handed over; it has not started
+				if err := w.jobSys.jobsService.ResetJobToPending(job.ID); err != nil {
+					log.Printf("Failed to reset job %s to pending: %v", job.ID, err)
+				}
+				return
+			default:
 			}
+			w.processJob(job)
 		case <-w.stopCh:
 			return
 		}
@@ -351,49 +512,153 @@ func (w *dbWorker) start() {
 
 func (w *dbWorker) processJob(job *Job) {
 	w.jobSys.mu.RLock()
-	handler, exists := w.jobSys.handlers[job.JobType]
+	registered, exists := w.jobSys.handlers[job.JobType]
 	w.jobSys.mu.RUnlock()
 
 	if !exists {
-		w.markJobFailed(job, fmt.Sprintf("no handler registered for job type: %s", job.JobType))
+		w.markJobFailed(job, FailureReasonNoHandler, fmt.Sprintf("no handler registered for job type: %s", job.JobType))
 		return
 	}
 
+	if !w.jobSys.claimRun(job.ID) {
+		// An earlier run timed out but its handler hasn't returned; hand the
+		// job back so it runs again only once that one has
+		if err := w.jobSys.jobsService.ResetJobToPending(job.ID); err != nil {
+			log.Printf("Failed to reset job %s to pending: %v", job.ID, err)
+		}
+		return
+	}
+	if !registered.tryAcquire() {
+		w.jobSys.finishRun(job.ID)
+		// The job type is at its concurrency limit; hand the job back so a later poll picks it up
+		if err := w.jobSys.jobsService.ResetJobToPending(job.ID); err != nil {
+			log.Printf("Failed to reset job %s to pending: %v", job.ID, err)
+		}
+		return
+	}
+	// The slot and the claim are held until the handler returns, not just
+	// until it times out
+	finished := func() {
+		registered.release()
+		w.jobSys.finishRun(job.ID)
+	}
+
+	timeout := registered.opts.Timeout
+	if timeout <= 0 {
+		timeout = w.jobSys.config.DefaultJobTimeout
+	}
+
+	logger := w.jobSys.newJobLogger(job)
+	ctx := contextWithCheckpoints(contextWithLogger(w.jobSys.execCtx, logger),
+		&jobCheckpoints{sink: w.jobSys.jobsService, jobID: job.ID})
+	ctx, span := tracing.Start(ctx, "job "+job.JobType,
+		attribute.String("job.id", job.ID),
+		attribute.String("job.type", job.JobType),
+		attribute.String("job.queue", job.QueueName),
+		attribute.Int("job.attempt", job.RetryCount+1),
+	)
+
 	startTime := time.Now()
-	ctx := context.Background()
-	err := handler(ctx, job)
+	err := runHandler(ctx, registered.handler, job, timeout, finished)
 	duration := time.Since(startTime)
+	tracing.End(span, err)
+
+	if err != nil && w.jobSys.execCtx.Err() != nil && !errors.Is(err, ErrJobTimeout) {
+		// Interrupted by shutdown rather than failing on its own; leave it for the next run
+		log.Printf("Job %s interrupted by shutdown, returning to queue", job.ID)
+		if resetErr := w.jobSys.jobsService.ResetJobToPending(job.ID); resetErr != nil {
+			log.Printf("Failed to reset job %s to pending: %v", job.ID, resetErr)
+		}
+		return
+	}
 
 	w.recordMetric(job, duration, err)
 
 	if err != nil {
+		reason := FailureReasonError
+		if errors.Is(err, ErrJobTimeout) {
+			reason = FailureReasonTimeout
+		}
+		logger.Error("job failed", "reason", reason, "error", err, "attempt", job.RetryCount+1, "duration_ms", duration.Milliseconds())
+
 		if job.RetryCount < job.MaxRetries {
-			w.scheduleRetry(job, err)
+			w.scheduleRetry(job, reason, err)
 		} else {
-			w.markJobFailed(job, err.Error())
+			w.markJobFailed(job, reason, err.Error())
 		}
 	} else {
+		logger.Debug("job completed", "duration_ms", duration.Milliseconds())
 		w.markJobCompleted(job)
 	}
 }
 
+// runHandler executes the handler under a timeout derived from parent. The
+// handler runs in its own goroutine so one that ignores its context cannot
+// hold the worker past the deadline. finished is called when the handler
+// returns: before runHandler does when it returns in time, after when not.
+func runHandler(parent context.Context, handler JobHandler, job *Job, timeout time.Duration, finished func()) error {
+	ctx, cancel := parent, context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, timeout)
+	}
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		err := handler(ctx, job)
+		finished()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %s: %v", ErrJobTimeout, timeout, err)
+		}
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %s", ErrJobTimeout, timeout)
+		}
+		return ctx.Err()
+	}
+}
+
+// claimRun marks the job as running, failing if a run of it already is
+func (js *DBJobSystem) claimRun(jobID string) bool {
+	js.inFlightMu.Lock()
+	defer js.inFlightMu.Unlock()
+	if js.inFlight[jobID] {
+		return false
+	}
+	js.inFlight[jobID] = true
+	return true
+}
+
+// finishRun clears the mark claimRun set once the job's handler returns
+func (js *DBJobSystem) finishRun(jobID string) {
+	js.inFlightMu.Lock()
+	defer js.inFlightMu.Unlock()
+	delete(js.inFlight, jobID)
+}
+
 func (w *dbWorker) markJobCompleted(job *Job) {
 	if err := w.jobSys.jobsService.MarkJobCompleted(job.ID); err != nil {
 		log.Printf("Failed to mark job %s as completed: %v", job.ID, err)
 	}
 }
 
-func (w *dbWorker) markJobFailed(job *Job, errorMsg string) {
-	if err := w.jobSys.jobsService.MarkJobFailed(job.ID, errorMsg); err != nil {
+func (w *dbWorker) markJobFailed(job *Job, reason, errorMsg string) {
+	if err := w.jobSys.jobsService.MarkJobFailed(job.ID, reason, errorMsg); err != nil {
 		log.Printf("Failed to mark job %s as failed: %v", job.ID, err)
 	}
 }
 
-func (w *dbWorker) scheduleRetry(job *Job, err error) {
+func (w *dbWorker) scheduleRetry(job *Job, reason string, err error) {
 	backoff := w.calculateBackoff(job.RetryCount)
 	retryAt := time.Now().Add(backoff)
 
-	if dbErr := w.jobSys.jobsService.ScheduleJobRetry(job.ID, retryAt, err.Error()); dbErr != nil {
+	if dbErr := w.jobSys.jobsService.ScheduleJobRetry(job.ID, retryAt, reason, err.Error()); dbErr != nil {
 		log.Printf("Failed to schedule retry for job %s: %v", job.ID, dbErr)
 	}
 }
@@ -410,22 +675,27 @@ func (w *dbWorker) calculateBackoff(retryCount int) time.Duration {
 }
 
 func (w *dbWorker) recordMetric(job *Job, duration time.Duration, err error) {
-	status := JobStatusCompleted
-	if err != nil {
-		status = JobStatusFailed
+	status := string(JobStatusCompleted)
+	if errors.Is(err, ErrJobTimeout) {
+		status = metricStatusTimedOut
+	} else if err != nil {
+		status = string(JobStatusFailed)
 	}
 
 	durationMs := duration.Milliseconds()
 
-	if dbErr := w.jobSys.jobsService.RecordJobMetric(job.QueueName, job.JobType, string(status), durationMs); dbErr != nil {
+	if dbErr := w.jobSys.jobsService.RecordJobMetric(job.QueueName, job.JobType, status, durationMs); dbErr != nil {
 		log.Printf("Failed to record metrics for job %s: %v", job.ID, dbErr)
 	}
 }
 
+// cronParser reads the five-field cron expressions scheduled jobs use. They
+// are evaluated in the server's local time.
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
 // calculateNextRun calculates the next execution time for a cron expression
 func (js *DBJobSystem) calculateNextRun(cronExpr string) (time.Time, error) {
-	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-	schedule, err := parser.Parse(cronExpr)
+	schedule, err := cronParser.Parse(cronExpr)
 	if err != nil {
 		return time.Time{}, err
 	}
@@ -467,6 +737,10 @@ func (js *DBJobSystem) startMetricsCleanup() {
 			select {
 			case <-js.metricsCleanup.C:
 				js.cleanupOldMetrics()
+				js.cleanupOldJobLogs()
+				js.mu.RLock()
+				js.resetStaleJobs()
+				js.mu.RUnlock()
 			case <-js.shutdownCh:
 				return
 			}
@@ -484,6 +758,23 @@ func (js *DBJobSystem) cleanupOldMetrics() {
 	log.Printf("Would clean up metrics older than: %v", cutoff)
 }
 
+// cleanupOldJobLogs removes captured job logs past the retention window
+func (js *DBJobSystem) cleanupOldJobLogs() {
+	if js.config.JobLogRetention <= 0 {
+		return
+	}
+
+	cutoff := time.Now().Add(-js.config.JobLogRetention)
+	deleted, err := js.jobsService.DeleteJobLogsBefore(cutoff)
+	if err != nil {
+		log.Printf("Failed to clean up job logs: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("Removed %d job log lines older than %v", deleted, cutoff)
+	}
+}
+
 // Scheduler methods for DBJobSystem
 
 func (s *dbScheduler) run() {
@@ -499,8 +790,85 @@ func (s *dbScheduler) run() {
 	}
 }
 
+// processScheduledJobs enqueues a job for each due scheduled job and moves
+// the schedule on to its next run
 func (s *dbScheduler) processScheduledJobs() {
-	// This would need to be implemented using JobsService methods
-	// For now, we'll log that scheduled job processing would happen here
-	log.Println("Processing scheduled jobs...")
+	now := time.Now()
+	due, err := s.jobSys.jobsService.GetDueScheduledJobs(now)
+	if err != nil {
+		log.Printf("Failed to load due scheduled jobs: %v", err)
+		return
+	}
+
+	for _, scheduled := range due {
+		s.runScheduledJob(scheduled, now)
+	}
+}
+
+// runScheduledJob enqueues the runs of one due scheduled job the catch-up
+// policy allows. Each run gets an idempotency key from the job's name and
+// run time, so a run enqueued twice - by a retry after a failed advance, or
+// by a second instance - still runs once.
+func (s *dbScheduler) runScheduledJob(scheduled services.ScheduledJob, now time.Time) {
+	schedule, err := cronParser.Parse(scheduled.CronExpr)
+	if err != nil {
+		log.Printf("Scheduled job %s has an invalid cron expression %q: %v", scheduled.Name, scheduled.CronExpr, err)
+		return
+	}
+
+	config := s.jobSys.config
+	runs, missed, next := dueRuns(schedule, scheduled.NextRunAt.In(time.Local), now,
+		config.ScheduleCatchUp, config.MaxCatchUpRuns, 2*config.SchedulerInterval)
+	if missed > 0 {
+		log.Printf("Scheduled job %s missed %d run(s); catching up with %d (policy %q)", scheduled.Name, missed, len(runs), config.ScheduleCatchUp)
+	}
+
+	for _, runAt := range runs {
+		key := fmt.Sprintf("scheduled:%s:%s", scheduled.Name, runAt.UTC().Format(time.RFC3339))
+		if _, err := s.jobSys.jobsService.EnqueueJob(scheduled.QueueName, scheduled.JobType, scheduled.Payload, 0, config.DefaultMaxRetries, runAt, &key); err != nil {
+			// Leave the schedule due; the next tick tries again and the keys stop duplicates
+			log.Printf("Failed to enqueue scheduled job %s: %v", scheduled.Name, err)
+			return
+		}
+	}
+
+	if _, err := s.jobSys.jobsService.AdvanceScheduledJob(scheduled.ID, now, next); err != nil {
+		log.Printf("Failed to advance scheduled job %s: %v", scheduled.Name, err)
+	}
+}
+
+// dueRuns lists the runs of a schedule from nextRunAt up to now and picks the
+// ones to enqueue under policy. A run more than grace overdue counts as
+// missed. It also returns how many runs were missed and the first run after
+// now.
+func dueRuns(schedule cron.Schedule, nextRunAt, now time.Time, policy CatchUpPolicy, maxRuns int, grace time.Duration) ([]time.Time, int, time.Time) {
+	var runs []time.Time
+	missed := 0
+	next := nextRunAt
+	for !next.After(now) {
+		if now.Sub(next) > grace {
+			missed++
+		}
+		runs = append(runs, next)
+		if policy == CatchUpAll && maxRuns > 0 && len(runs) > maxRuns {
+			runs = runs[1:]
+		}
+		next = schedule.Next(next)
+	}
+	if len(runs) == 0 {
+		return nil, 0, next
+	}
+
+	latest := runs[len(runs)-1]
+	switch policy {
+	case CatchUpAll:
+		return runs, missed, next
+	case CatchUpSkip:
+		if now.Sub(latest) > grace {
+			return nil, missed, next
+		}
+		return []time.Time{latest}, missed, next
+	default:
+		return []time.Time{latest}, missed, next
+	}
 }
@@ -0,0 +1,326 @@
+package jobsystem
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestJobSystem(t *testing.T) (*DBJobSystem, *database.Fascade) {
+	dbFile := fmt.Sprintf("test_jobsystem_%d.db", time.Now().UnixNano())
+	db, err := database.New(dbFile)
+	require.NoError(t, err)
+
+	err = db.MigrateUp()
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		db.Close()
+		os.Remove(dbFile)
+	})
+
+	return NewDBJobSystem(DefaultConfig(), services.NewJobsService(db)), db
+}
+
+func enqueueTestJob(t *testing.T, js *DBJobSystem, jobType string) *Job {
+	jobID, err := js.Enqueue(&EnqueueRequest{JobType: jobType, MaxRetries: 1})
+	require.NoError(t, err)
+	// Retries already exhausted so failures are final
+	return &Job{ID: jobID, QueueName: "default", JobType: jobType, MaxRetries: 1, RetryCount: 1}
+}
+
+func TestProcessJob_TimeoutRecordedAsDistinctFailure(t *testing.T) {
+	js, db := setupTestJobSystem(t)
+
+	js.Register("hangs", func(ctx context.Context, job *Job) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, HandlerOptions{Timeout: 20 * time.Millisecond})
+
+	job := enqueueTestJob(t, js, "hangs")
+	worker := &dbWorker{jobSys: js}
+	worker.processJob(job)
+
+	var status, reason, errMsg string
+	err := db.QueryRow(`SELECT status, failure_reason, error FROM jobs WHERE id = ?`, job.ID).Scan(&status, &reason, &errMsg)
+	require.NoError(t, err)
+	assert.Equal(t, "failed", status)
+	assert.Equal(t, FailureReasonTimeout, reason)
+	assert.Contains(t, errMsg, ErrJobTimeout.Error())
+
+	metrics, err := js.GetMetrics("default", "hangs")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), metrics.TimedOutJobs)
+	assert.Equal(t, int64(1), metrics.FailedJobs)
+}
+
+func TestProcessJob_HandlerIgnoringContextDoesNotBlockWorker(t *testing.T) {
+	js, _ := setupTestJobSystem(t)
+
+	release := make(chan struct{})
+	defer close(release)
+	js.Register("stuck", func(ctx context.Context, job *Job) error {
+		<-release
+		return nil
+	}, HandlerOptions{Timeout: 20 * time.Millisecond})
+
+	job := enqueueTestJob(t, js, "stuck")
+	worker := &dbWorker{jobSys: js}
+
+	done := make(chan struct{})
+	go func() {
+		worker.processJob(job)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("worker stayed blocked on a handler that ignored its context")
+	}
+}
+
+func TestProcessJob_TimedOutHandlerHoldsItsRunUntilItReturns(t *testing.T) {
+	js, db := setupTestJobSystem(t)
+
+	release := make(chan struct{})
+	started := make(chan string, 4)
+	js.Register("stuck", func(ctx context.Context, job *Job) error {
+		started <- job.ID
+		<-release
+		return nil
+	}, HandlerOptions{Timeout: 20 * time.Millisecond, MaxConcurrency: 1})
+
+	job := enqueueTestJob(t, js, "stuck")
+	worker := &dbWorker{jobSys: js}
+	worker.processJob(job)
+	require.Equal(t, job.ID, <-started)
+
+	// The first run timed out but its handler is still going
+	worker.processJob(job)
+	other := enqueueTestJob(t, js, "stuck")
+	worker.processJob(other)
+	assert.Empty(t, started, "no second run starts while the first handler runs")
+
+	var status string
+	require.NoError(t, db.QueryRow(`SELECT status FROM jobs WHERE id = ?`, other.ID).Scan(&status))
+	assert.Equal(t, "pending", status, "the held-back job waits for a later poll")
+
+	close(release)
+	require.Eventually(t, func() bool {
+		worker.processJob(other)
+		return len(started) == 1
+	}, 2*time.Second, 10*time.Millisecond, "the slot frees once the first handler returns")
+	assert.Equal(t, other.ID, <-started)
+}
+
+func TestProcessJob_HandlerErrorUsesErrorReason(t *testing.T) {
+	js, db := setupTestJobSystem(t)
+
+	js.Register("broken", func(ctx context.Context, job *Job) error {
+		return fmt.Errorf("boom")
+	}, HandlerOptions{})
+
+	job := enqueueTestJob(t, js, "broken")
+	worker := &dbWorker{jobSys: js}
+	worker.processJob(job)
+
+	var reason, errMsg string
+	err := db.QueryRow(`SELECT failure_reason, error FROM jobs WHERE id = ?`, job.ID).Scan(&reason, &errMsg)
+	require.NoError(t, err)
+	assert.Equal(t, FailureReasonError, reason)
+	assert.Equal(t, "boom", errMsg)
+}
+
+func TestRegisteredHandler_MaxConcurrency(t *testing.T) {
+	h := &registeredHandler{slots: make(chan struct{}, 1)}
+
+	assert.True(t, h.tryAcquire())
+	assert.False(t, h.tryAcquire(), "second acquire should fail at the limit")
+
+	h.release()
+	assert.True(t, h.tryAcquire())
+
+	unlimited := &registeredHandler{}
+	assert.True(t, unlimited.tryAcquire())
+	assert.True(t, unlimited.tryAcquire())
+}
+
+func TestProcessJob_CapturesJobScopedLogs(t *testing.T) {
+	js, _ := setupTestJobSystem(t)
+	js.config.JobLogMaxLines = 3
+
+	js.Register("chatty", func(ctx context.Context, job *Job) error {
+		logger := LoggerFromContext(ctx)
+		logger.Info("fetching calendars", "count", 2)
+		logger.WithGroup("google").Warn("slow response", "latency_ms", 900)
+		for i := 0; i < 10; i++ {
+			logger.Debug("event synced", "index", i)
+		}
+		return nil
+	}, HandlerOptions{})
+
+	job := enqueueTestJob(t, js, "chatty")
+	worker := &dbWorker{jobSys: js}
+	worker.processJob(job)
+
+	logs, err := js.jobsService.GetJobLogs(job.ID, 100)
+	require.NoError(t, err)
+	require.Len(t, logs, 4, "three captured lines plus the truncation marker")
+
+	assert.Equal(t, "info", logs[0].Level)
+	assert.Equal(t, "fetching calendars", logs[0].Message)
+	assert.Equal(t, float64(2), logs[0].Fields["count"])
+	assert.NotContains(t, logs[0].Fields, "job_id")
+
+	assert.Equal(t, "warn", logs[1].Level)
+	assert.Equal(t, float64(900), logs[1].Fields["google.latency_ms"])
+
+	assert.Contains(t, logs[3].Message, "truncated")
+}
+
+func TestLoggerFromContext_FallsBackToDefault(t *testing.T) {
+	assert.NotNil(t, LoggerFromContext(context.Background()))
+}
+
+func TestDueRuns_CatchUpPolicies(t *testing.T) {
+	hourly, err := cronParser.Parse("0 * * * *")
+	require.NoError(t, err)
+
+	// Down from 09:00 until just after 13:00: the 09-13 runs are due, 09-12 missed
+	nextRunAt := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 3, 2, 13, 0, 30, 0, time.UTC)
+	grace := 2 * time.Minute
+
+	runs, missed, next := dueRuns(hourly, nextRunAt, now, CatchUpOnce, 0, grace)
+	assert.Equal(t, []time.Time{now.Truncate(time.Hour)}, runs)
+	assert.Equal(t, 4, missed)
+	assert.Equal(t, time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC), next)
+
+	runs, _, _ = dueRuns(hourly, nextRunAt, now, CatchUpAll, 3, grace)
+	require.Len(t, runs, 3, "capped to the latest runs")
+	assert.Equal(t, 11, runs[0].Hour())
+	assert.Equal(t, 13, runs[2].Hour())
+
+	runs, _, _ = dueRuns(hourly, nextRunAt, now, CatchUpSkip, 0, grace)
+	assert.Equal(t, []time.Time{now.Truncate(time.Hour)}, runs, "the current run is on time, so it still runs")
+
+	runs, _, next = dueRuns(hourly, nextRunAt, now.Add(10*time.Minute), CatchUpSkip, 0, grace)
+	assert.Empty(t, runs)
+	assert.Equal(t, 14, next.Hour())
+}
+
+func TestScheduler_EnqueuesDueScheduledJobOnce(t *testing.T) {
+	js, db := setupTestJobSystem(t)
+	scheduler := &dbScheduler{jobSys: js}
+
+	require.NoError(t, js.jobsService.ScheduleJob("nightly", "default", "maintenance", `{}`, "0 0 * * *", true,
+		time.Now().Add(-3*24*time.Hour)))
+	// Registering again at startup keeps the overdue run
+	require.NoError(t, js.Schedule(&ScheduleRequest{Name: "nightly", JobType: "maintenance", CronExpr: "0 0 * * *", Enabled: true}))
+
+	scheduler.processScheduledJobs()
+	scheduler.processScheduledJobs()
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM jobs WHERE job_type = 'maintenance'`).Scan(&count))
+	assert.Equal(t, 1, count, "three missed nights run once")
+
+	due, err := js.jobsService.GetDueScheduledJobs(time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, due, "the schedule moved on to its next run")
+}
+
+func TestEnqueueOnce_ReportsExistingJob(t *testing.T) {
+	js, _ := setupTestJobSystem(t)
+	key := "calendar_sync:user_1:google:1760000000"
+	req := &EnqueueRequest{QueueName: "calendar-sync", JobType: "calendar_sync", IdempotencyKey: &key}
+
+	first, err := js.EnqueueOnce(req)
+	require.NoError(t, err)
+	assert.False(t, first.Existing)
+	assert.Equal(t, JobStatusPending, first.Status)
+
+	again, err := js.EnqueueOnce(req)
+	require.NoError(t, err)
+	assert.True(t, again.Existing)
+	assert.Equal(t, first.JobID, again.JobID)
+
+	require.NoError(t, js.jobsService.MarkJobCompleted(first.JobID))
+	again, err = js.EnqueueOnce(req)
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusCompleted, again.Status)
+
+	_, err = js.EnqueueOnce(&EnqueueRequest{JobType: "calendar_sync"})
+	assert.Error(t, err, "a key is required")
+}
+
+func TestProcessJob_InterruptedJobResumesFromCheckpoint(t *testing.T) {
+	js, db := setupTestJobSystem(t)
+
+	type progress struct{ SyncedThrough string }
+	var resumedFrom string
+	js.Register("long_sync", func(ctx context.Context, job *Job) error {
+		checkpoints := CheckpointsFromContext(ctx)
+		var saved progress
+		if ok, err := checkpoints.Load(&saved); err != nil {
+			return err
+		} else if ok {
+			resumedFrom = saved.SyncedThrough
+			return checkpoints.Clear()
+		}
+		if err := checkpoints.Save(progress{SyncedThrough: "2025-06-01"}); err != nil {
+			return err
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}, HandlerOptions{})
+
+	job := enqueueTestJob(t, js, "long_sync")
+	worker := &dbWorker{jobSys: js}
+	done := make(chan struct{})
+	go func() {
+		worker.processJob(job)
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		checkpoint, err := js.jobsService.GetJobCheckpoint(job.ID)
+		return err == nil && checkpoint != nil
+	}, time.Second, 5*time.Millisecond)
+
+	// Shutting down interrupts the job and puts it back on the queue
+	js.execCancel()
+	<-done
+	var status string
+	require.NoError(t, db.QueryRow(`SELECT status FROM jobs WHERE id = ?`, job.ID).Scan(&status))
+	assert.Equal(t, "pending", status)
+	require.Eventually(t, func() bool {
+		js.inFlightMu.Lock()
+		defer js.inFlightMu.Unlock()
+		return !js.inFlight[job.ID]
+	}, time.Second, 5*time.Millisecond, "the interrupted handler returns")
+
+	js.execCtx, js.execCancel = context.WithCancel(context.Background())
+	worker.processJob(job)
+	assert.Equal(t, "2025-06-01", resumedFrom)
+	checkpoint, err := js.jobsService.GetJobCheckpoint(job.ID)
+	require.NoError(t, err)
+	assert.Nil(t, checkpoint)
+}
+
+func TestCheckpointsFromContext_OutsideJob(t *testing.T) {
+	checkpoints := CheckpointsFromContext(context.Background())
+	require.NoError(t, checkpoints.Save(map[string]string{"a": "b"}))
+	var v map[string]string
+	ok, err := checkpoints.Load(&v)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,179 @@
+package jobsystem
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// maxJobLogMessageBytes truncates any single log message before it is stored
+const maxJobLogMessageBytes = 4096
+
+// jobLogSink persists captured log lines for a job
+type jobLogSink interface {
+	AppendJobLog(jobID, level, message, fields string) error
+}
+
+type loggerContextKey struct{}
+
+// LoggerFromContext returns the job-scoped logger placed in the context by the
+// job system. Lines written to it are stored against the running job as well
+// as going to the server log. Outside a job it falls back to slog.Default().
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+func contextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// newJobLogger builds a logger that captures output for a single job run
+func (js *DBJobSystem) newJobLogger(job *Job) *slog.Logger {
+	handler := &jobLogHandler{
+		sink:     js.jobsService,
+		jobID:    job.ID,
+		maxLines: js.config.JobLogMaxLines,
+		maxBytes: js.config.JobLogMaxBytes,
+		state:    &jobLogState{},
+		next:     slog.Default().Handler(),
+	}
+	return slog.New(handler).With("job_id", job.ID, "job_type", job.JobType)
+}
+
+// jobLogState tracks how much has been written for a job. It is shared by
+// every handler derived through WithAttrs/WithGroup so caps apply per job.
+type jobLogState struct {
+	mu        sync.Mutex
+	lines     int
+	bytes     int
+	truncated bool
+}
+
+// jobLogHandler is a slog.Handler that writes records to the job_logs table
+// and forwards them to the regular server log handler.
+type jobLogHandler struct {
+	sink     jobLogSink
+	jobID    string
+	maxLines int
+	maxBytes int
+	state    *jobLogState
+	attrs    []slog.Attr
+	groups   []string
+	next     slog.Handler
+}
+
+func (h *jobLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	// Capture everything from Debug up; the server log applies its own level
+	return level >= slog.LevelDebug
+}
+
+func (h *jobLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if h.next.Enabled(ctx, record.Level) {
+		if err := h.next.Handle(ctx, record); err != nil {
+			log.Printf("Failed to forward job log line: %v", err)
+		}
+	}
+
+	fields := make(map[string]any)
+	for _, attr := range h.attrs {
+		addLogAttr(fields, "", attr)
+	}
+	prefix := strings.Join(h.groups, ".")
+	record.Attrs(func(attr slog.Attr) bool {
+		addLogAttr(fields, prefix, attr)
+		return true
+	})
+
+	// job_id and job_type are columns on the job itself, no need to repeat them per line
+	delete(fields, "job_id")
+	delete(fields, "job_type")
+
+	fieldsJSON := ""
+	if len(fields) > 0 {
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		fieldsJSON = string(data)
+	}
+
+	message := record.Message
+	if len(message) > maxJobLogMessageBytes {
+		message = message[:maxJobLogMessageBytes] + "…"
+	}
+
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+
+	if h.state.truncated {
+		return nil
+	}
+
+	size := len(message) + len(fieldsJSON)
+	if (h.maxLines > 0 && h.state.lines >= h.maxLines) || (h.maxBytes > 0 && h.state.bytes+size > h.maxBytes) {
+		h.state.truncated = true
+		return h.sink.AppendJobLog(h.jobID, "warn", "log output truncated: job exceeded its log size limit", "")
+	}
+
+	h.state.lines++
+	h.state.bytes += size
+	return h.sink.AppendJobLog(h.jobID, strings.ToLower(record.Level.String()), message, fieldsJSON)
+}
+
+func (h *jobLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	prefix := strings.Join(h.groups, ".")
+	clone.attrs = append([]slog.Attr{}, h.attrs...)
+	for _, attr := range attrs {
+		if prefix != "" {
+			attr.Key = prefix + "." + attr.Key
+		}
+		clone.attrs = append(clone.attrs, attr)
+	}
+	clone.next = h.next.WithAttrs(attrs)
+	return &clone
+}
+
+func (h *jobLogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.groups = append(append([]string{}, h.groups...), name)
+	clone.next = h.next.WithGroup(name)
+	return &clone
+}
+
+// addLogAttr flattens an attribute into fields, joining group names with dots
+func addLogAttr(fields map[string]any, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	key := attr.Key
+	if prefix != "" && key != "" {
+		key = prefix + "." + key
+	} else if key == "" {
+		key = prefix
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		for _, child := range attr.Value.Group() {
+			addLogAttr(fields, key, child)
+		}
+		return
+	}
+
+	value := attr.Value.Any()
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+	fields[key] = value
+}
@@ -2,6 +2,7 @@ package jobsystem
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -64,6 +65,15 @@ type EnqueueRequest struct {
 	IdempotencyKey *string                `json:"idempotency_key"` // Optional key for preventing duplicate jobs
 }
 
+// EnqueueResult is the job an EnqueueOnce request ended up with
+type EnqueueResult struct {
+	JobID  string    `json:"job_id"`
+	Status JobStatus `json:"status"`
+	// Existing is set when a job with the request's idempotency key had
+	// already been enqueued; Status is then that job's current status
+	Existing bool `json:"existing"`
+}
+
 // ScheduleRequest represents a request to schedule a recurring job
 type ScheduleRequest struct {
 	Name      string                 `json:"name"`
@@ -74,9 +84,43 @@ type ScheduleRequest struct {
 	Enabled   bool                   `json:"enabled"`
 }
 
+// CatchUpPolicy says what the scheduler does with the runs of a scheduled job
+// that fell due while the job system was down. A run counts as missed once it
+// is more than two scheduler intervals overdue.
+type CatchUpPolicy string
+
+const (
+	CatchUpOnce CatchUpPolicy = "once" // run once in place of all the missed runs
+	CatchUpAll  CatchUpPolicy = "all"  // run each missed run, the latest MaxCatchUpRuns of them
+	CatchUpSkip CatchUpPolicy = "skip" // drop missed runs and wait for the next one
+)
+
 // JobHandler is a function that processes a job
 type JobHandler func(ctx context.Context, job *Job) error
 
+// HandlerOptions controls how jobs of a registered type are executed
+type HandlerOptions struct {
+	// Timeout bounds a single execution of the handler. Zero uses Config.DefaultJobTimeout.
+	Timeout time.Duration
+
+	// MaxConcurrency caps how many jobs of this type may run at once across
+	// all queues. Zero means no per-type limit beyond the queue's worker count.
+	MaxConcurrency int
+}
+
+// Failure reasons recorded against failed or retried jobs
+const (
+	FailureReasonError     = "error"
+	FailureReasonTimeout   = "timeout"
+	FailureReasonNoHandler = "no_handler"
+)
+
+// metricStatusTimedOut is recorded in job_metrics when a handler exceeds its timeout
+const metricStatusTimedOut = "timed_out"
+
+// ErrJobTimeout is returned when a handler does not finish within its timeout
+var ErrJobTimeout = errors.New("job execution timed out")
+
 // JobMetric represents metrics for a job execution
 type JobMetric struct {
 	ID         string    `json:"id" db:"id"`
@@ -103,6 +147,7 @@ type REDMetrics struct {
 	// Additional useful metrics
 	TotalJobs        int64   `json:"total_jobs"`
 	FailedJobs       int64   `json:"failed_jobs"`
+	TimedOutJobs     int64   `json:"timed_out_jobs"`
 	AverageLatencyMs float64 `json:"average_latency_ms"`
 }
 
@@ -121,12 +166,23 @@ type Config struct {
 	RetryBackoffBase  time.Duration `json:"retry_backoff_base"`
 	RetryBackoffMax   time.Duration `json:"retry_backoff_max"`
 
+	// Execution configuration
+	DefaultJobTimeout   time.Duration `json:"default_job_timeout"`   // applies to handlers registered without a timeout
+	ShutdownGracePeriod time.Duration `json:"shutdown_grace_period"` // how long Stop waits for running jobs before interrupting them
+
 	// Scheduler configuration
 	SchedulerEnabled  bool          `json:"scheduler_enabled"`
 	SchedulerInterval time.Duration `json:"scheduler_interval"`
+	ScheduleCatchUp   CatchUpPolicy `json:"schedule_catch_up"` // runs missed while the job system was down
+	MaxCatchUpRuns    int           `json:"max_catch_up_runs"` // cap for CatchUpAll, 0 = unlimited
 
 	// Metrics configuration
 	MetricsRetention time.Duration `json:"metrics_retention"`
+
+	// Job log capture configuration
+	JobLogMaxLines  int           `json:"job_log_max_lines"` // per job run, 0 = unlimited
+	JobLogMaxBytes  int           `json:"job_log_max_bytes"` // per job run, 0 = unlimited
+	JobLogRetention time.Duration `json:"job_log_retention"`
 }
 
 // DefaultConfig returns a default configuration
@@ -136,13 +192,20 @@ func DefaultConfig() *Config {
 			"default":         5,
 			"task_generation": 3,
 		},
-		DefaultConcurrency: 5,
-		PollInterval:       5 * time.Second,
-		DefaultMaxRetries:  3,
-		RetryBackoffBase:   1 * time.Second,
-		RetryBackoffMax:    5 * time.Minute,
-		SchedulerEnabled:   true,
-		SchedulerInterval:  1 * time.Minute,
-		MetricsRetention:   24 * time.Hour,
+		DefaultConcurrency:  5,
+		PollInterval:        5 * time.Second,
+		DefaultMaxRetries:   3,
+		RetryBackoffBase:    1 * time.Second,
+		RetryBackoffMax:     5 * time.Minute,
+		DefaultJobTimeout:   10 * time.Minute,
+		ShutdownGracePeriod: 20 * time.Second,
+		SchedulerEnabled:    true,
+		SchedulerInterval:   1 * time.Minute,
+		ScheduleCatchUp:     CatchUpOnce,
+		MaxCatchUpRuns:      10,
+		MetricsRetention:    24 * time.Hour,
+		JobLogMaxLines:      500,
+		JobLogMaxBytes:      256 * 1024,
+		JobLogRetention:     7 * 24 * time.Hour,
 	}
 }
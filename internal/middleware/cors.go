@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"famstack/internal/config"
+)
+
+// Defaults for CORS policies that leave them unset
+var (
+	corsMethods        = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+	corsHeaders        = []string{"Authorization", "Content-Type", "If-None-Match", "X-CSRF-Token"}
+	corsExposedHeaders = []string{"ETag", "Retry-After"}
+)
+
+const corsMaxAgeSeconds = 600
+
+// CORS answers preflight requests and adds CORS headers to responses for
+// origins the policy covering the path allows. The policy is read on every
+// request, so changes made through the config API apply straight away.
+func CORS(next http.Handler, current func() config.CORSConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		policy := current().PolicyFor(r.URL.Path)
+		w.Header().Add("Vary", "Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		allowed := corsAllows(policy, origin)
+
+		if allowed {
+			if slices.Contains(policy.AllowedOrigins, "*") && !policy.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			if policy.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
+
+		if !preflight {
+			if allowed {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(slices.Concat(corsExposedHeaders, policy.ExposedHeaders), ", "))
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Preflights are answered here, allowed or not: a refusal is the
+		// missing headers, which the browser acts on
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		if allowed {
+			methods := policy.AllowedMethods
+			if len(methods) == 0 {
+				methods = corsMethods
+			}
+			maxAge := policy.MaxAgeSeconds
+			if maxAge == 0 {
+				maxAge = corsMaxAgeSeconds
+			}
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(slices.Concat(corsHeaders, policy.AllowedHeaders), ", "))
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(maxAge))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// corsAllows reports whether the policy lets origin call its routes
+func corsAllows(policy config.CORSPolicy, origin string) bool {
+	for _, allowed := range policy.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"famstack/internal/config"
+)
+
+func corsHandler(cfg config.CORSConfig) http.Handler {
+	return CORS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), func() config.CORSConfig { return cfg })
+}
+
+func TestCORS_AllowsListedOrigins(t *testing.T) {
+	handler := corsHandler(config.CORSConfig{CORSPolicy: config.CORSPolicy{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowCredentials: true,
+	}})
+
+	r := httptest.NewRequest("GET", "/api/v1/tasks", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "ETag")
+
+	r = httptest.NewRequest("GET", "/api/v1/tasks", nil)
+	r.Header.Set("Origin", "https://evil.example.com")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORS_AnswersPreflights(t *testing.T) {
+	handler := corsHandler(config.CORSConfig{CORSPolicy: config.CORSPolicy{
+		AllowedOrigins: []string{"*"},
+		AllowedHeaders: []string{"X-Client-Version"},
+	}})
+
+	r := httptest.NewRequest("OPTIONS", "/api/v1/tasks", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	r.Header.Set("Access-Control-Request-Method", "POST")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "POST")
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "X-Client-Version")
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+}
+
+func TestCORS_RouteOverridesDefaultPolicy(t *testing.T) {
+	handler := corsHandler(config.CORSConfig{
+		CORSPolicy: config.CORSPolicy{AllowedOrigins: []string{"https://app.example.com"}},
+		Routes: []config.CORSRoute{
+			{PathPrefix: "/api/v1/config", CORSPolicy: config.CORSPolicy{}},
+			{PathPrefix: "/api/v1/display", CORSPolicy: config.CORSPolicy{AllowedOrigins: []string{"*"}}},
+		},
+	})
+
+	for path, want := range map[string]string{
+		"/api/v1/tasks":        "https://app.example.com",
+		"/api/v1/config/cors":  "",
+		"/api/v1/display/feed": "*",
+	} {
+		r := httptest.NewRequest("OPTIONS", path, nil)
+		r.Header.Set("Origin", "https://app.example.com")
+		r.Header.Set("Access-Control-Request-Method", "GET")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		assert.Equal(t, want, w.Header().Get("Access-Control-Allow-Origin"), path)
+	}
+}
+
+func TestCORSConfig_Validate(t *testing.T) {
+	assert.NoError(t, config.CORSConfig{CORSPolicy: config.CORSPolicy{AllowedOrigins: []string{"https://app.example.com"}}}.Validate())
+	assert.Error(t, config.CORSConfig{CORSPolicy: config.CORSPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true}}.Validate())
+	assert.Error(t, config.CORSConfig{CORSPolicy: config.CORSPolicy{AllowedOrigins: []string{"https://app.example.com/path"}}}.Validate())
+	assert.Error(t, config.CORSConfig{Routes: []config.CORSRoute{{PathPrefix: "api"}}}.Validate())
+}
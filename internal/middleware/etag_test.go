@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionalGET(t *testing.T) {
+	body := `{"tasks":[]}`
+	handler := ConditionalGET(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, httptest.NewRequest("GET", "/api/v1/tasks", nil))
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, body, first.Body.String())
+	etag := first.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	request := httptest.NewRequest("GET", "/api/v1/tasks", nil)
+	request.Header.Set("If-None-Match", etag)
+	second := httptest.NewRecorder()
+	handler.ServeHTTP(second, request)
+	assert.Equal(t, http.StatusNotModified, second.Code)
+	assert.Empty(t, second.Body.String())
+
+	body = `{"tasks":[{"id":"task_1"}]}`
+	third := httptest.NewRecorder()
+	handler.ServeHTTP(third, request)
+	assert.Equal(t, http.StatusOK, third.Code, "a changed list gets a new ETag")
+	assert.NotEqual(t, etag, third.Header().Get("ETag"))
+}
+
+func TestConditionalGET_LeavesErrorsAlone(t *testing.T) {
+	handler := ConditionalGET(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/v1/tasks", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("ETag"))
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Proxies are the reverse proxies whose X-Forwarded-For and
+// X-Forwarded-Proto headers are believed
+type Proxies struct {
+	nets []*net.IPNet // nil trusts any peer
+}
+
+// ParseProxies reads a list of proxy addresses and CIDR ranges. An empty
+// list trusts any peer, for a server reachable only through its proxy.
+func ParseProxies(proxies []string) (*Proxies, error) {
+	p := &Proxies{}
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			p.nets = append(p.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+		}
+		p.nets = append(p.nets, ipNet)
+	}
+	return p, nil
+}
+
+// trusts reports whether ip is one of the proxies
+func (p *Proxies) trusts(ip net.IP) bool {
+	if p.nets == nil {
+		return true
+	}
+	for _, ipNet := range p.nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+type forwardedHTTPSKey struct{}
+
+// Handler takes each request's client address and scheme from the proxy
+// headers when the request comes from a trusted proxy, so rate limits and
+// sign-in lockouts see clients rather than the proxy. X-Forwarded-For is
+// read from the right, skipping the proxies' own entries: earlier entries
+// come from the client and can be forged.
+func (p *Proxies) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peer := net.ParseIP(ClientIP(r))
+		if peer == nil || !p.trusts(peer) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			entries := strings.Split(forwarded, ",")
+			for i := len(entries) - 1; i >= 0; i-- {
+				ip := net.ParseIP(strings.TrimSpace(entries[i]))
+				if ip == nil {
+					break
+				}
+				r.RemoteAddr = net.JoinHostPort(ip.String(), "0")
+				if p.nets == nil || !p.trusts(ip) {
+					break
+				}
+			}
+		}
+
+		if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+			entries := strings.Split(proto, ",")
+			if strings.EqualFold(strings.TrimSpace(entries[len(entries)-1]), "https") {
+				r = r.WithContext(context.WithValue(r.Context(), forwardedHTTPSKey{}, true))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RealIP trusts the proxy headers of any peer. Install it only behind a
+// proxy that sets them.
+func RealIP(next http.Handler) http.Handler {
+	return (&Proxies{}).Handler(next)
+}
+
+// IsHTTPS reports whether the client reached the server over HTTPS, directly
+// or through a trusted proxy
+func IsHTTPS(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	forwarded, _ := r.Context().Value(forwardedHTTPSKey{}).(bool)
+	return forwarded
+}
+
+// Origin returns the scheme and host the client addressed the request to,
+// such as https://famstack.example.com
+func Origin(r *http.Request) string {
+	if IsHTTPS(r) {
+		return "https://" + r.Host
+	}
+	return "http://" + r.Host
+}
+
+// ClientIP returns the address a request came from, without the port
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seenBy runs a request from peer with the given proxy headers through
+// proxies and returns the client address and origin the handler saw
+func seenBy(t *testing.T, proxies *Proxies, peer, forwardedFor, forwardedProto string) (string, string) {
+	var clientIP, origin string
+	handler := proxies.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientIP, origin = ClientIP(r), Origin(r)
+	}))
+
+	r := httptest.NewRequest("GET", "http://famstack.example.com/", nil)
+	r.RemoteAddr = peer + ":41000"
+	if forwardedFor != "" {
+		r.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	if forwardedProto != "" {
+		r.Header.Set("X-Forwarded-Proto", forwardedProto)
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	return clientIP, origin
+}
+
+func TestProxies_TrustsOnlyListedProxies(t *testing.T) {
+	proxies, err := ParseProxies([]string{"10.0.0.0/8", "192.168.1.5"})
+	require.NoError(t, err)
+
+	ip, origin := seenBy(t, proxies, "10.1.2.3", "203.0.113.9", "https")
+	assert.Equal(t, "203.0.113.9", ip)
+	assert.Equal(t, "https://famstack.example.com", origin)
+
+	// Headers from anyone else are ignored
+	ip, origin = seenBy(t, proxies, "198.51.100.7", "203.0.113.9", "https")
+	assert.Equal(t, "198.51.100.7", ip)
+	assert.Equal(t, "http://famstack.example.com", origin)
+}
+
+func TestProxies_SkipsChainedProxies(t *testing.T) {
+	proxies, err := ParseProxies([]string{"10.0.0.0/8", "192.168.1.5"})
+	require.NoError(t, err)
+
+	// The client forged the first entry; the rest were added by proxies
+	ip, _ := seenBy(t, proxies, "10.1.2.3", "1.1.1.1, 203.0.113.9, 192.168.1.5", "")
+	assert.Equal(t, "203.0.113.9", ip)
+}
+
+func TestRealIP_TrustsAnyPeerButOnlyTheLastEntry(t *testing.T) {
+	proxies, err := ParseProxies(nil)
+	require.NoError(t, err)
+
+	ip, origin := seenBy(t, proxies, "198.51.100.7", "1.1.1.1, 203.0.113.9", "http")
+	assert.Equal(t, "203.0.113.9", ip)
+	assert.Equal(t, "http://famstack.example.com", origin)
+}
+
+func TestParseProxies_RejectsBadEntries(t *testing.T) {
+	_, err := ParseProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+	_, err = ParseProxies([]string{"proxy.local"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,174 @@
+package models
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// Activity is a child's extracurricular (team sport, lessons, club) for one season
+type Activity struct {
+	ID                string    `json:"id" db:"id"`
+	FamilyID          string    `json:"family_id" db:"family_id"`
+	MemberID          string    `json:"member_id" db:"member_id"`
+	Name              string    `json:"name" db:"name"`
+	Team              string    `json:"team" db:"team"`
+	CoachName         string    `json:"coach_name" db:"coach_name"`
+	CoachPhone        string    `json:"coach_phone" db:"coach_phone"`
+	CoachEmail        string    `json:"coach_email" db:"coach_email"`
+	SeasonStart       string    `json:"season_start" db:"season_start"` // YYYY-MM-DD, inclusive
+	SeasonEnd         string    `json:"season_end" db:"season_end"`     // YYYY-MM-DD, inclusive
+	PracticeDays      []string  `json:"practice_days" db:"practice_days"`
+	PracticeStartTime *string   `json:"practice_start_time" db:"practice_start_time"` // HH:MM in family timezone
+	PracticeEndTime   *string   `json:"practice_end_time" db:"practice_end_time"`     // HH:MM in family timezone
+	Location          string    `json:"location" db:"location"`
+	Equipment         []string  `json:"equipment" db:"equipment"`
+	FeeCents          int       `json:"fee_cents" db:"fee_cents"`
+	FeePaid           bool      `json:"fee_paid" db:"fee_paid"`
+	Color             string    `json:"color" db:"color"`
+	ChecklistTaskID   *string   `json:"checklist_task_id" db:"checklist_task_id"`
+	PracticeCount     int       `json:"practice_count"`
+	CreatedBy         *string   `json:"created_by" db:"created_by"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateActivityRequest registers an activity for a child
+type CreateActivityRequest struct {
+	MemberID          string   `json:"member_id" validate:"required"`
+	Name              string   `json:"name" validate:"required,min=1,max=255"`
+	Team              string   `json:"team,omitempty"`
+	CoachName         string   `json:"coach_name,omitempty"`
+	CoachPhone        string   `json:"coach_phone,omitempty"`
+	CoachEmail        string   `json:"coach_email,omitempty"`
+	SeasonStart       string   `json:"season_start" validate:"required"`
+	SeasonEnd         string   `json:"season_end" validate:"required"`
+	PracticeDays      []string `json:"practice_days,omitempty"`
+	PracticeStartTime *string  `json:"practice_start_time,omitempty"`
+	PracticeEndTime   *string  `json:"practice_end_time,omitempty"`
+	Location          string   `json:"location,omitempty"`
+	Equipment         []string `json:"equipment,omitempty"`
+	FeeCents          int      `json:"fee_cents" validate:"min=0"`
+	FeePaid           bool     `json:"fee_paid"`
+	Color             string   `json:"color,omitempty"`
+}
+
+// UpdateActivityRequest changes fields on an activity. Changing the season or
+// practice schedule regenerates the practice events.
+type UpdateActivityRequest struct {
+	Name              *string   `json:"name,omitempty"`
+	Team              *string   `json:"team,omitempty"`
+	CoachName         *string   `json:"coach_name,omitempty"`
+	CoachPhone        *string   `json:"coach_phone,omitempty"`
+	CoachEmail        *string   `json:"coach_email,omitempty"`
+	SeasonStart       *string   `json:"season_start,omitempty"`
+	SeasonEnd         *string   `json:"season_end,omitempty"`
+	PracticeDays      *[]string `json:"practice_days,omitempty"`
+	PracticeStartTime *string   `json:"practice_start_time,omitempty"`
+	PracticeEndTime   *string   `json:"practice_end_time,omitempty"`
+	Location          *string   `json:"location,omitempty"`
+	Equipment         *[]string `json:"equipment,omitempty"`
+	FeeCents          *int      `json:"fee_cents,omitempty"`
+	FeePaid           *bool     `json:"fee_paid,omitempty"`
+	Color             *string   `json:"color,omitempty"`
+}
+
+// HasPracticeSchedule reports whether practices can be generated for the activity
+func (a *Activity) HasPracticeSchedule() bool {
+	return len(a.PracticeDays) > 0 && a.PracticeStartTime != nil && a.PracticeEndTime != nil
+}
+
+// PracticesOn returns every practice date in the season, in loc
+func (a *Activity) PracticesOn(loc *time.Location) []time.Time {
+	if !a.HasPracticeSchedule() {
+		return nil
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", a.SeasonStart, loc)
+	if err != nil {
+		return nil
+	}
+	end, err := time.ParseInLocation("2006-01-02", a.SeasonEnd, loc)
+	if err != nil {
+		return nil
+	}
+
+	days := make(map[string]bool, len(a.PracticeDays))
+	for _, day := range a.PracticeDays {
+		days[strings.ToLower(day)] = true
+	}
+
+	var dates []time.Time
+	for current := start; !current.After(end); current = current.AddDate(0, 0, 1) {
+		if days[strings.ToLower(current.Weekday().String())] {
+			dates = append(dates, current)
+		}
+	}
+	return dates
+}
+
+// ValidateActivity checks an activity after create or update fields are applied
+func ValidateActivity(a *Activity) error {
+	validator := validation.NewValidator()
+
+	validator.Required("name", a.Name)
+	validator.MaxLength("name", a.Name, 255)
+	validator.MaxLength("team", a.Team, 255)
+	validator.MaxLength("coach_name", a.CoachName, 255)
+	validator.MaxLength("location", a.Location, 255)
+
+	if a.CoachEmail != "" {
+		if _, err := mail.ParseAddress(a.CoachEmail); err != nil {
+			validator.AddError("coach_email", "coach_email must be a valid email address")
+		}
+	}
+
+	start, startErr := time.Parse("2006-01-02", a.SeasonStart)
+	if startErr != nil {
+		validator.AddError("season_start", "season_start must be in YYYY-MM-DD format")
+	}
+	end, endErr := time.Parse("2006-01-02", a.SeasonEnd)
+	if endErr != nil {
+		validator.AddError("season_end", "season_end must be in YYYY-MM-DD format")
+	}
+	if startErr == nil && endErr == nil {
+		if end.Before(start) {
+			validator.AddError("season_end", "season_end must not be before season_start")
+		} else if end.Sub(start) > 366*24*time.Hour {
+			validator.AddError("season_end", "a season cannot be longer than a year")
+		}
+	}
+
+	validDays := []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+	for _, day := range a.PracticeDays {
+		validator.OneOf("practice_days", day, validDays)
+	}
+
+	if (a.PracticeStartTime == nil) != (a.PracticeEndTime == nil) {
+		validator.AddError("practice_end_time", "practice_start_time and practice_end_time must be set together")
+	}
+	if a.PracticeStartTime != nil && a.PracticeEndTime != nil {
+		practiceStart, startErr := time.Parse("15:04", *a.PracticeStartTime)
+		if startErr != nil {
+			validator.AddError("practice_start_time", "practice_start_time must be in HH:MM format")
+		}
+		practiceEnd, endErr := time.Parse("15:04", *a.PracticeEndTime)
+		if endErr != nil {
+			validator.AddError("practice_end_time", "practice_end_time must be in HH:MM format")
+		}
+		if startErr == nil && endErr == nil && !practiceEnd.After(practiceStart) {
+			validator.AddError("practice_end_time", "practice_end_time must be after practice_start_time")
+		}
+	}
+
+	if a.FeeCents < 0 {
+		validator.AddError("fee_cents", "fee_cents cannot be negative")
+	}
+	if a.Color != "" && !hexColorPattern.MatchString(a.Color) {
+		validator.AddError("color", "color must be a hex value like #10b981")
+	}
+
+	return validator.ToError()
+}
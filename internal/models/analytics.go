@@ -0,0 +1,43 @@
+package models
+
+// Usage metrics counted when analytics are enabled
+const (
+	UsageMetricTaskCreated  = "task_created"
+	UsageMetricCalendarSync = "calendar_sync"
+	UsageMetricPageView     = "page_view"
+)
+
+// Page names recorded for paths that don't name a page themselves
+const (
+	UsagePageHome  = "home"  // the root path
+	UsagePageOther = "other" // paths that aren't a plain page name
+)
+
+// Analytics summary windows
+const (
+	DefaultUsageSummaryDays = 30
+	MaxUsageSummaryDays     = 365
+)
+
+// UsageCounter is one day's count of a metric
+type UsageCounter struct {
+	Day       string `json:"day" db:"day"` // YYYY-MM-DD in UTC
+	Metric    string `json:"metric" db:"metric"`
+	Dimension string `json:"dimension,omitempty" db:"dimension"`
+	Count     int    `json:"count" db:"count"`
+}
+
+// UsageSummary is the admin dashboard view of a family's usage counts
+type UsageSummary struct {
+	Enabled bool           `json:"enabled"`
+	From    string         `json:"from"` // first day covered, YYYY-MM-DD
+	To      string         `json:"to"`
+	Totals  map[string]int `json:"totals"` // by metric
+	Pages   map[string]int `json:"pages"`  // page views by page name
+	Daily   []UsageCounter `json:"daily"`
+}
+
+// PageViewBeacon is the body the web client sends when a page is shown
+type PageViewBeacon struct {
+	Page string `json:"page"`
+}
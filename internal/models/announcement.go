@@ -0,0 +1,102 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// Who an announcement is shown to
+const (
+	AnnouncementVisibilityEveryone = "everyone"
+	AnnouncementVisibilityAdults   = "adults"
+)
+
+// AnnouncementVisibilities lists the valid visibilities
+var AnnouncementVisibilities = []string{AnnouncementVisibilityEveryone, AnnouncementVisibilityAdults}
+
+// Announcement is a note posted to the family's board
+type Announcement struct {
+	ID         string     `json:"id" db:"id"`
+	FamilyID   string     `json:"family_id" db:"family_id"`
+	Title      string     `json:"title" db:"title"`
+	Body       string     `json:"body" db:"body"`
+	Pinned     bool       `json:"pinned" db:"pinned"`
+	Visibility string     `json:"visibility" db:"visibility"`
+	ExpiresAt  *time.Time `json:"expires_at" db:"expires_at"`
+	CreatedBy  *string    `json:"created_by" db:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+
+	// For the member viewing the board
+	ReadAt *time.Time `json:"read_at" db:"read_at"`
+	// How many members have read it
+	ReadCount int `json:"read_count" db:"read_count"`
+}
+
+// AnnouncementRead is a member's read receipt for an announcement
+type AnnouncementRead struct {
+	MemberID   string    `json:"member_id" db:"member_id"`
+	MemberName string    `json:"member_name" db:"member_name"`
+	ReadAt     time.Time `json:"read_at" db:"read_at"`
+}
+
+// CreateAnnouncementRequest posts an announcement
+type CreateAnnouncementRequest struct {
+	Title      string     `json:"title"`
+	Body       string     `json:"body"`
+	Pinned     bool       `json:"pinned"`
+	Visibility string     `json:"visibility"` // defaults to everyone
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// Validate trims and checks the request
+func (r *CreateAnnouncementRequest) Validate() error {
+	r.Title = strings.TrimSpace(r.Title)
+	if r.Visibility == "" {
+		r.Visibility = AnnouncementVisibilityEveryone
+	}
+
+	validator := validation.NewValidator()
+	validator.Required("title", r.Title)
+	validator.MaxLength("title", r.Title, 255)
+	validator.MaxLength("body", r.Body, 5000)
+	validator.OneOf("visibility", r.Visibility, AnnouncementVisibilities)
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(time.Now()) {
+		validator.AddError("expires_at", "expires_at must be in the future")
+	}
+	return validator.ToError()
+}
+
+// UpdateAnnouncementRequest changes the fields that are set. ClearExpiry
+// removes the expiry so the announcement stays up.
+type UpdateAnnouncementRequest struct {
+	Title       *string    `json:"title,omitempty"`
+	Body        *string    `json:"body,omitempty"`
+	Pinned      *bool      `json:"pinned,omitempty"`
+	Visibility  *string    `json:"visibility,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	ClearExpiry bool       `json:"clear_expiry,omitempty"`
+}
+
+// Validate trims and checks the fields that are set
+func (r *UpdateAnnouncementRequest) Validate() error {
+	validator := validation.NewValidator()
+	if r.Title != nil {
+		title := strings.TrimSpace(*r.Title)
+		r.Title = &title
+		validator.Required("title", title)
+		validator.MaxLength("title", title, 255)
+	}
+	if r.Body != nil {
+		validator.MaxLength("body", *r.Body, 5000)
+	}
+	if r.Visibility != nil {
+		validator.OneOf("visibility", *r.Visibility, AnnouncementVisibilities)
+	}
+	if r.ExpiresAt != nil && r.ClearExpiry {
+		validator.AddError("expires_at", "expires_at can't be set and cleared at once")
+	}
+	return validator.ToError()
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// FamilyArchiveVersion is the archive format this build reads and writes
+const FamilyArchiveVersion = 1
+
+// MaxImportArchiveBytes caps the size of an uploaded archive
+const MaxImportArchiveBytes = 10 << 20
+
+// FamilyArchive is a family's data exported as JSON. Entries carry the IDs
+// they had in the exporting family; members are listed so the tasks, events
+// and schedules that point at them can be matched to members of the family
+// the archive is imported into.
+type FamilyArchive struct {
+	Version    int                    `json:"version"`
+	ExportedAt time.Time              `json:"exported_at"`
+	Family     Family                 `json:"family"`
+	Members    []FamilyMember         `json:"members"`
+	Tasks      []Task                 `json:"tasks"`
+	Schedules  []TaskSchedule         `json:"schedules"`
+	Events     []UnifiedCalendarEvent `json:"events"`
+}
+
+// Validate checks the archive is one this build can read
+func (a *FamilyArchive) Validate() error {
+	validator := validation.NewValidator()
+	if a.Version != FamilyArchiveVersion {
+		validator.AddErrorf("version", "unsupported archive version %d, expected %d", a.Version, FamilyArchiveVersion)
+	}
+	if len(a.Tasks)+len(a.Schedules)+len(a.Events) == 0 {
+		validator.AddError("archive", "archive has no tasks, schedules or events")
+	}
+	return validator.ToError()
+}
+
+// Kinds of archive entry
+const (
+	ImportKindTask     = "task"
+	ImportKindSchedule = "schedule"
+	ImportKindEvent    = "event"
+)
+
+// Import result statuses
+const (
+	ImportStatusCreated  = "created"
+	ImportStatusValid    = "valid"    // would be created; the import was a dry run
+	ImportStatusConflict = "conflict" // the family already has it, so it was left out
+	ImportStatusInvalid  = "invalid"
+)
+
+// ImportResult reports what happened to one archive entry
+type ImportResult struct {
+	Kind     string                      `json:"kind"`
+	Index    int                         `json:"index"`
+	SourceID string                      `json:"source_id"`
+	Status   string                      `json:"status"`
+	ID       string                      `json:"id,omitempty"`       // the new ID, once created
+	Conflict string                      `json:"conflict,omitempty"` // ID of the existing row it duplicates
+	Errors   validation.ValidationErrors `json:"errors,omitempty"`
+	Warnings []string                    `json:"warnings,omitempty"`
+}
+
+// ImportReport summarises an import. Nothing is written on a dry run, or
+// when any entry is invalid.
+type ImportReport struct {
+	DryRun    bool           `json:"dry_run"`
+	Created   int            `json:"created"`
+	Valid     int            `json:"valid"`
+	Conflicts int            `json:"conflicts"`
+	Invalid   int            `json:"invalid"`
+	Results   []ImportResult `json:"results"`
+}
+
+// ValidateImport checks a task from an archive
+func (t *Task) ValidateImport() validation.ValidationErrors {
+	validator := validation.NewValidator()
+	validator.Required("title", t.Title)
+	if !t.TaskType.Valid() {
+		validator.AddErrorf("task_type", "unknown task type %q", t.TaskType)
+	}
+	if !t.Status.Valid() {
+		validator.AddErrorf("status", "unknown status %q", t.Status)
+	}
+	return validator.Errors()
+}
+
+// ValidateImport checks a task schedule from an archive
+func (s *TaskSchedule) ValidateImport() validation.ValidationErrors {
+	validator := validation.NewValidator()
+	validator.Required("title", s.Title)
+	if !s.TaskType.Valid() {
+		validator.AddErrorf("task_type", "unknown task type %q", s.TaskType)
+	}
+	if (s.DaysOfWeek == nil || *s.DaysOfWeek == "") && (s.CronExpr == nil || *s.CronExpr == "") {
+		validator.AddError("days_of_week", "days_of_week or cron_expr is required")
+	}
+	return validator.Errors()
+}
+
+// ValidateImport checks a calendar event from an archive
+func (e *UnifiedCalendarEvent) ValidateImport() validation.ValidationErrors {
+	validator := validation.NewValidator()
+	validator.Required("title", e.Title)
+	if e.StartTime.IsZero() || e.EndTime.IsZero() {
+		validator.AddError("start_time", "start_time and end_time are required")
+	} else if e.EndTime.Before(e.StartTime) {
+		validator.AddError("end_time", "end_time must not be before start_time")
+	}
+	if e.EventType != "" && !e.EventType.Valid() {
+		validator.AddErrorf("event_type", "unknown event type %q", e.EventType)
+	}
+	if e.Color != "" && !IsHexColor(e.Color) {
+		validator.AddError("color", "color must be a #rrggbb value")
+	}
+	return validator.Errors()
+}
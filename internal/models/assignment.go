@@ -0,0 +1,136 @@
+package models
+
+import (
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// AssignmentStatus constants
+const (
+	AssignmentStatusNotStarted = "not_started"
+	AssignmentStatusInProgress = "in_progress"
+	AssignmentStatusCompleted  = "completed"
+)
+
+// Course is a class a child is taking, used to group their assignments
+type Course struct {
+	ID        string    `json:"id" db:"id"`
+	FamilyID  string    `json:"family_id" db:"family_id"`
+	MemberID  string    `json:"member_id" db:"member_id"`
+	Name      string    `json:"name" db:"name"`
+	Teacher   string    `json:"teacher" db:"teacher"`
+	Color     string    `json:"color" db:"color"`
+	Active    bool      `json:"active" db:"active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Assignment is a piece of homework with a deadline
+type Assignment struct {
+	ID               string     `json:"id" db:"id"`
+	FamilyID         string     `json:"family_id" db:"family_id"`
+	CourseID         string     `json:"course_id" db:"course_id"`
+	CourseName       string     `json:"course_name"`
+	MemberID         string     `json:"member_id" db:"member_id"`
+	Title            string     `json:"title" db:"title"`
+	Description      string     `json:"description" db:"description"`
+	DueDate          time.Time  `json:"due_date" db:"due_date"`
+	EstimatedMinutes int        `json:"estimated_minutes" db:"estimated_minutes"`
+	Status           string     `json:"status" db:"status"` // 'not_started', 'in_progress', 'completed'
+	TaskID           *string    `json:"task_id" db:"task_id"`
+	CreatedBy        *string    `json:"created_by" db:"created_by"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+	CompletedAt      *time.Time `json:"completed_at" db:"completed_at"`
+}
+
+// CreateCourseRequest creates a course for a child
+type CreateCourseRequest struct {
+	MemberID string `json:"member_id" validate:"required"`
+	Name     string `json:"name" validate:"required,min=1,max=255"`
+	Teacher  string `json:"teacher,omitempty" validate:"omitempty,max=255"`
+	Color    string `json:"color,omitempty"`
+}
+
+// UpdateCourseRequest changes fields on a course
+type UpdateCourseRequest struct {
+	Name    *string `json:"name,omitempty"`
+	Teacher *string `json:"teacher,omitempty"`
+	Color   *string `json:"color,omitempty"`
+	Active  *bool   `json:"active,omitempty"`
+}
+
+// CreateAssignmentRequest adds an assignment to a course. A due date without
+// an offset is read in the family's timezone.
+type CreateAssignmentRequest struct {
+	CourseID         string    `json:"course_id" validate:"required"`
+	Title            string    `json:"title" validate:"required,min=1,max=255"`
+	Description      string    `json:"description,omitempty" validate:"omitempty,max=1000"`
+	DueDate          time.Time `json:"due_date" validate:"required"`
+	EstimatedMinutes int       `json:"estimated_minutes" validate:"min=0"`
+}
+
+// UpdateAssignmentRequest changes fields on an assignment
+type UpdateAssignmentRequest struct {
+	Title            *string    `json:"title,omitempty"`
+	Description      *string    `json:"description,omitempty"`
+	DueDate          *time.Time `json:"due_date,omitempty"`
+	EstimatedMinutes *int       `json:"estimated_minutes,omitempty"`
+	Status           *string    `json:"status,omitempty"`
+}
+
+// UpcomingDeadlines is the per-child section of the parent deadline report
+type UpcomingDeadlines struct {
+	MemberID         string       `json:"member_id"`
+	MemberName       string       `json:"member_name"`
+	Overdue          []Assignment `json:"overdue"`
+	Upcoming         []Assignment `json:"upcoming"`
+	EstimatedMinutes int          `json:"estimated_minutes"` // remaining effort across overdue and upcoming work
+}
+
+// IsValidAssignmentStatus checks if an assignment status is valid
+func IsValidAssignmentStatus(status string) bool {
+	switch status {
+	case AssignmentStatusNotStarted, AssignmentStatusInProgress, AssignmentStatusCompleted:
+		return true
+	default:
+		return false
+	}
+}
+
+// ValidateCourse checks the fields shared by course create and update
+func ValidateCourse(name, teacher, color string) error {
+	validator := validation.NewValidator()
+
+	validator.Required("name", name)
+	validator.MaxLength("name", name, 255)
+	validator.MaxLength("teacher", teacher, 255)
+
+	if color != "" && !hexColorPattern.MatchString(color) {
+		validator.AddError("color", "color must be a hex value like #6366f1")
+	}
+
+	return validator.ToError()
+}
+
+// ValidateAssignment checks the fields shared by assignment create and update
+func ValidateAssignment(title, description string, dueDate time.Time, estimatedMinutes int, status string) error {
+	validator := validation.NewValidator()
+
+	validator.Required("title", title)
+	validator.MaxLength("title", title, 255)
+	validator.MaxLength("description", description, 1000)
+
+	if dueDate.IsZero() {
+		validator.AddError("due_date", "due_date is required")
+	}
+	if estimatedMinutes < 0 {
+		validator.AddError("estimated_minutes", "estimated_minutes cannot be negative")
+	}
+	if !IsValidAssignmentStatus(status) {
+		validator.AddError("status", "status must be one of: not_started, in_progress, completed")
+	}
+
+	return validator.ToError()
+}
@@ -1,6 +1,12 @@
 package models
 
-import "time"
+import (
+	"regexp"
+	"time"
+
+	"famstack/internal/recurrence"
+	"famstack/internal/validation"
+)
 
 // CalendarEvent represents a calendar event
 type CalendarEvent struct {
@@ -11,7 +17,7 @@ type CalendarEvent struct {
 	StartTime   time.Time `json:"start_time" db:"start_time"`
 	EndTime     time.Time `json:"end_time" db:"end_time"`
 	Location    *string   `json:"location" db:"location"`
-	EventType   string    `json:"event_type" db:"event_type"` // 'appointment', 'event', 'reminder'
+	EventType   EventType `json:"event_type" db:"event_type"` // 'appointment', 'event', 'reminder'
 	AssignedTo  *string   `json:"assigned_to" db:"assigned_to"`
 	CreatedBy   string    `json:"created_by" db:"created_by"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
@@ -37,34 +43,231 @@ type UnifiedCalendarEvent struct {
 	EndTime     time.Time `json:"end_time" db:"end_time"`
 	Location    *string   `json:"location" db:"location"`
 	AllDay      bool      `json:"all_day" db:"all_day"`
-	EventType   string    `json:"event_type" db:"event_type"`
+	EventType   EventType `json:"event_type" db:"event_type"`
 	Color       string    `json:"color" db:"color"`
+	Category    string    `json:"category" db:"category"` // set by the family's color rules
 	CreatedBy   *string   `json:"created_by" db:"created_by"`
-	Priority    int       `json:"priority" db:"priority"`
+	Priority    Priority  `json:"priority" db:"priority"`
 	Status      string    `json:"status" db:"status"`
+	ICalUID     *string   `json:"ical_uid,omitempty" db:"ical_uid"` // set for events written by CalDAV clients
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 
+	// RecurrenceRule is the RRULE of a recurring series. Listings return each
+	// occurrence as its own event carrying the series' rule.
+	RecurrenceRule *string `json:"recurrence_rule,omitempty" db:"recurrence_rule"`
+	// RecurrenceExdates lists the UTC starts of occurrences the series skips,
+	// in the form recurrence.FormatTimes writes
+	RecurrenceExdates string `json:"-" db:"recurrence_exdates"`
+	// RecurringEventID is set on occurrences: the ID of the series they belong to
+	RecurringEventID *string `json:"recurring_event_id,omitempty" db:"recurring_event_id"`
+	// OriginalStartTime is when an occurrence was scheduled by the series,
+	// before any edit moved it
+	OriginalStartTime *time.Time `json:"original_start_time,omitempty" db:"original_start_time"`
+	// SourceIntegrationID is set on events fetched from a subscribed
+	// calendar feed: the ICS integration that keeps them in step
+	SourceIntegrationID *string `json:"source_integration_id,omitempty" db:"source_integration_id"`
+	// CelebrationKind is set on the yearly series made from members'
+	// birthdays and anniversaries, see CelebrationBirthday
+	CelebrationKind *string `json:"celebration_kind,omitempty" db:"celebration_kind"`
+	// Age is set on occurrences of a celebration: the years since the date
+	// it celebrates
+	Age *int `json:"age,omitempty" db:"-"`
+	// Visibility is who sees the event's details, see EventVisibilityPublic
+	Visibility string `json:"visibility" db:"visibility"`
+	// Redacted is set when the viewer sees only that the time is taken
+	Redacted bool `json:"redacted,omitempty" db:"-"`
+
 	// Attendees is a constructed field with full family member display data.
 	// This replaces the previous []string approach to provide richer UI data.
 	Attendees []EventAttendee `json:"attendees"`
 }
 
-// EventType constants
+// Unified calendar event statuses
+const (
+	UnifiedEventStatusActive    = "active"
+	UnifiedEventStatusCancelled = "cancelled"
+	UnifiedEventStatusCompleted = "completed"
+)
+
+// Kinds of celebration kept on the calendar from family members' dates
+const (
+	CelebrationBirthday    = "birthday"
+	CelebrationAnniversary = "anniversary"
+)
+
+// Who an event's details are shown to. The event's creator and attendees
+// always see it in full, and adults see their children's events.
+const (
+	EventVisibilityPublic  = "public"  // the whole family
+	EventVisibilityBusy    = "busy"    // others see only that the time is taken
+	EventVisibilityPrivate = "private" // others don't see it at all
+)
+
+// EventVisibilities lists the valid event visibilities
+var EventVisibilities = []string{EventVisibilityPublic, EventVisibilityBusy, EventVisibilityPrivate}
+
+// BusyEventTitle stands in for the title of an event the viewer sees only as
+// busy time, in English; families with another locale see it in theirs
+const BusyEventTitle = "Busy"
+
+// CalendarViewer is the member an event listing is for. A nil viewer is a
+// shared screen or a guest, and sees only public events in full.
+type CalendarViewer struct {
+	MemberID string
+	Adult    bool
+}
+
+// How creating an event treats other events its attendees are already at.
+// Without a check the event is created regardless.
+const (
+	ConflictCheckWarn   = "warn"   // create it and list the conflicts
+	ConflictCheckReject = "reject" // don't create it while there are any
+)
+
+// EventConflict is an event that overlaps one being created and shares some
+// of its attendees, seen the way the creator may see it
+type EventConflict struct {
+	EventID   string    `json:"event_id"`
+	Title     string    `json:"title"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	MemberIDs []string  `json:"member_ids"` // the attendees it shares
+}
+
+// Free/busy lookup limits, in minutes
+const (
+	DefaultFreeBusyMinDuration = 15
+	MaxFreeBusyMinDuration     = 24 * 60
+)
+
+// BusyBlock is an event that takes up some of the members' day, placed in
+// the day's 15-minute slots like the calendar days view
+type BusyBlock struct {
+	EventID   string    `json:"event_id"`
+	Title     string    `json:"title"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	StartSlot int       `json:"start_slot"`
+	EndSlot   int       `json:"end_slot"`
+	MemberIDs []string  `json:"member_ids"` // the requested members attending
+}
+
+// FreeSlot is a stretch of the day when every requested member is free
+type FreeSlot struct {
+	StartTime       time.Time `json:"start_time"`
+	EndTime         time.Time `json:"end_time"`
+	StartSlot       int       `json:"start_slot"`
+	EndSlot         int       `json:"end_slot"`
+	DurationMinutes int       `json:"duration_minutes"`
+}
+
+// FreeBusy is one family day for a set of members: when any of them is busy,
+// and the free slots they share that last at least MinDuration minutes
+type FreeBusy struct {
+	Date        string      `json:"date"`
+	Timezone    string      `json:"timezone"`
+	MemberIDs   []string    `json:"member_ids"`
+	MinDuration int         `json:"min_duration_minutes"`
+	Busy        []BusyBlock `json:"busy"`
+	Free        []FreeSlot  `json:"free"`
+}
+
+// Celebration is one of a member's birthdays or anniversaries as it stands
+// on the calendar
+type Celebration struct {
+	Kind         string `json:"kind"`
+	Date         string `json:"date"` // YYYY-MM-DD, the date celebrated
+	EventID      string `json:"event_id"`
+	SkippedYears []int  `json:"skipped_years"`
+}
+
+// Scopes for editing or deleting an occurrence of a recurring event
+const (
+	RecurrenceScopeThis   = "this"   // only the chosen occurrence
+	RecurrenceScopeFuture = "future" // the chosen occurrence and every later one
+	RecurrenceScopeAll    = "all"    // the whole series
+)
+
+// Bulk import result statuses
 const (
-	EventTypeAppointment = "appointment"
-	EventTypeEvent       = "event"
-	EventTypeReminder    = "reminder"
+	BulkEventStatusCreated = "created"
+	BulkEventStatusInvalid = "invalid"
+	BulkEventStatusSkipped = "skipped" // valid, but not written because other items failed
+	BulkEventStatusFailed  = "failed"  // rejected by the database
 )
 
-// IsValidEventType checks if an event type is valid
-func IsValidEventType(eventType string) bool {
-	switch eventType {
-	case EventTypeAppointment, EventTypeEvent, EventTypeReminder:
-		return true
-	default:
-		return false
+var hexColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// IsHexColor reports whether color is a #rrggbb value
+func IsHexColor(color string) bool {
+	return hexColorPattern.MatchString(color)
+}
+
+// BulkEventResult reports the outcome for a single item of a bulk import
+type BulkEventResult struct {
+	Index    int                         `json:"index"`
+	Status   string                      `json:"status"`
+	EventID  string                      `json:"event_id,omitempty"`
+	Errors   validation.ValidationErrors `json:"errors,omitempty"`
+	Error    string                      `json:"error,omitempty"`
+	Warnings []string                    `json:"warnings,omitempty"`
+}
+
+// BulkEventsResponse summarises a bulk import
+type BulkEventsResponse struct {
+	Created int               `json:"created"`
+	Invalid int               `json:"invalid"`
+	Failed  int               `json:"failed"`
+	Results []BulkEventResult `json:"results"`
+}
+
+// Validate checks a bulk import item and returns field-level errors
+func (i *BulkCalendarEventItem) Validate() validation.ValidationErrors {
+	validator := validation.NewValidator()
+
+	validator.Required("title", i.Title)
+	validator.MaxLength("title", i.Title, 255)
+	if i.Description != nil {
+		validator.MaxLength("description", *i.Description, 1000)
+	}
+	if i.Location != nil {
+		validator.MaxLength("location", *i.Location, 255)
+	}
+
+	if i.StartTime.IsZero() {
+		validator.AddError("start_time", "start_time is required")
+	}
+	if i.EndTime.IsZero() {
+		validator.AddError("end_time", "end_time is required")
+	}
+	if !i.StartTime.IsZero() && !i.EndTime.IsZero() && i.EndTime.Before(i.StartTime) {
+		validator.AddError("end_time", "end_time must not be before start_time")
+	}
+
+	if i.EventType != "" {
+		if eventType, err := ParseEventType(string(i.EventType)); err != nil {
+			validator.AddError("event_type", err.Error())
+		} else {
+			i.EventType = eventType
+		}
+	}
+	if i.Color != "" && !hexColorPattern.MatchString(i.Color) {
+		validator.AddError("color", "color must be a hex value like #3b82f6")
 	}
+	if _, err := ParsePriority(int(i.Priority)); err != nil {
+		validator.AddError("priority", err.Error())
+	}
+	if i.Visibility != "" {
+		validator.OneOf("visibility", i.Visibility, EventVisibilities)
+	}
+	if i.RecurrenceRule != nil {
+		if _, err := recurrence.Parse(*i.RecurrenceRule); err != nil {
+			validator.AddError("recurrence_rule", err.Error())
+		}
+	}
+
+	return validator.Errors()
 }
 
 // Layered Calendar Data Structures for /api/calendar/days
@@ -77,6 +280,7 @@ type DaysResponse struct {
 	RequestedPeople []string             `json:"requestedPeople"`
 	Days            []DayView            `json:"days"`
 	Metadata        DaysResponseMetadata `json:"metadata"`
+	Preferences     *RenderPreferences   `json:"preferences,omitempty"` // the caller's
 }
 
 // DaysResponseMetadata contains summary information about the response
@@ -88,8 +292,10 @@ type DaysResponseMetadata struct {
 
 // DayView represents calendar view data for a single day with layered layout
 type DayView struct {
-	Date   string          `json:"date"`
-	Layers []CalendarLayer `json:"layers"`
+	Date            string               `json:"date"`
+	Layers          []CalendarLayer      `json:"layers"`
+	ProtectedBlocks []ProtectedBlockBand `json:"protectedBlocks,omitempty"`
+	Weather         *DayForecast         `json:"weather,omitempty"` // when the family set its location
 }
 
 // CalendarLayer represents a column of non-overlapping events
@@ -114,3 +320,71 @@ type CalendarViewEvent struct {
 	Location     *string         `json:"location"`
 	Description  *string         `json:"description"`
 }
+
+// Month and agenda view limits
+const (
+	MaxMonthDayEvents = 3 // event summaries per month day; the rest are only counted
+	DefaultAgendaDays = 14
+	MaxAgendaDays     = 62
+)
+
+// MonthResponse is a month as whole weeks, from the caller's week start, with
+// just enough about each day's events to fill the grid
+type MonthResponse struct {
+	Month           string             `json:"month"` // YYYY-MM
+	Timezone        string             `json:"timezone"`
+	WeekStart       string             `json:"weekStart"`
+	RequestedPeople []string           `json:"requestedPeople"`
+	Days            []MonthDay         `json:"days"`
+	TotalEvents     int                `json:"totalEvents"`           // events on days in the month
+	Preferences     *RenderPreferences `json:"preferences,omitempty"` // the caller's
+}
+
+// MonthDay is one cell of the month grid. An event spanning days counts on
+// each of them.
+type MonthDay struct {
+	Date        string              `json:"date"`
+	InMonth     bool                `json:"inMonth"` // false for the days filling out the first and last weeks
+	EventCount  int                 `json:"eventCount"`
+	AllDayCount int                 `json:"allDayCount"`
+	Events      []MonthEventSummary `json:"events"` // the first few, all-day events first
+	MoreCount   int                 `json:"moreCount"`
+}
+
+// MonthEventSummary is an event as a month grid cell shows it
+type MonthEventSummary struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Color     string    `json:"color"`
+	AllDay    bool      `json:"allDay"`
+	StartTime time.Time `json:"startTime"`
+	IsPrivate bool      `json:"isPrivate"`
+}
+
+// AgendaResponse is the events over a range of days as one chronological list
+type AgendaResponse struct {
+	StartDate       string             `json:"startDate"`
+	EndDate         string             `json:"endDate"`
+	Timezone        string             `json:"timezone"`
+	RequestedPeople []string           `json:"requestedPeople"`
+	Items           []AgendaItem       `json:"items"`
+	Preferences     *RenderPreferences `json:"preferences,omitempty"` // the caller's
+}
+
+// AgendaItem is an event listed under one day of the agenda; an event spanning
+// days is listed under each. The grouping hints let a client draw day headers
+// without regrouping the list.
+type AgendaItem struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	StartTime   time.Time `json:"startTime"`
+	EndTime     time.Time `json:"endTime"`
+	AllDay      bool      `json:"allDay"`
+	Color       string    `json:"color"`
+	Location    *string   `json:"location"`
+	AttendeeIDs []string  `json:"attendeeIds"`
+	IsPrivate   bool      `json:"isPrivate"`
+	Date        string    `json:"date"`       // the day it is listed under
+	FirstOfDay  bool      `json:"firstOfDay"` // the first item under its day
+	Continued   bool      `json:"continued"`  // began on an earlier day
+}
@@ -0,0 +1,204 @@
+package models
+
+import (
+	"net/mail"
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// Carpool swap request statuses
+const (
+	CarpoolSwapPending   = "pending"
+	CarpoolSwapAccepted  = "accepted"
+	CarpoolSwapDeclined  = "declined"
+	CarpoolSwapCancelled = "cancelled"
+)
+
+// CarpoolGroup rotates driving to an activity's practices between families.
+// The organizing family owns the activity; other families join with the join code.
+type CarpoolGroup struct {
+	ID         string          `json:"id" db:"id"`
+	FamilyID   string          `json:"family_id" db:"family_id"`
+	ActivityID string          `json:"activity_id" db:"activity_id"`
+	Name       string          `json:"name" db:"name"`
+	JoinCode   string          `json:"join_code,omitempty" db:"join_code"`
+	CreatedBy  *string         `json:"created_by" db:"created_by"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at" db:"updated_at"`
+	Drivers    []CarpoolDriver `json:"drivers"`
+}
+
+// CarpoolDriver is one driver in a group's rotation. External drivers have no
+// family or member and are reached through their phone or email.
+type CarpoolDriver struct {
+	ID        string    `json:"id" db:"id"`
+	GroupID   string    `json:"group_id" db:"group_id"`
+	FamilyID  *string   `json:"family_id" db:"family_id"`
+	MemberID  *string   `json:"member_id" db:"member_id"`
+	Name      string    `json:"name" db:"name"`
+	Phone     string    `json:"phone" db:"phone"`
+	Email     string    `json:"email" db:"email"`
+	Position  int       `json:"position" db:"position"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// CarpoolAssignment is the driver of the day for one practice
+type CarpoolAssignment struct {
+	ID         string    `json:"id" db:"id"`
+	GroupID    string    `json:"group_id" db:"group_id"`
+	DriveDate  string    `json:"drive_date" db:"drive_date"` // YYYY-MM-DD
+	EventID    *string   `json:"event_id" db:"event_id"`
+	DriverID   string    `json:"driver_id" db:"driver_id"`
+	DriverName string    `json:"driver_name"`
+	TaskID     *string   `json:"task_id" db:"task_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CarpoolSwapRequest asks another driver to take over an assignment
+type CarpoolSwapRequest struct {
+	ID                  string     `json:"id" db:"id"`
+	GroupID             string     `json:"group_id" db:"group_id"`
+	AssignmentID        string     `json:"assignment_id" db:"assignment_id"`
+	FromDriverID        string     `json:"from_driver_id" db:"from_driver_id"`
+	ToDriverID          string     `json:"to_driver_id" db:"to_driver_id"`
+	RequestedByFamilyID string     `json:"requested_by_family_id" db:"requested_by_family_id"`
+	Status              string     `json:"status" db:"status"`
+	Note                string     `json:"note" db:"note"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	ResolvedAt          *time.Time `json:"resolved_at" db:"resolved_at"`
+}
+
+// CreateCarpoolGroupRequest starts a carpool for one of the family's activities
+type CreateCarpoolGroupRequest struct {
+	ActivityID string `json:"activity_id" validate:"required"`
+	Name       string `json:"name" validate:"required,min=1,max=255"`
+	// DriverMemberID optionally adds one of the organizer's own members as the first driver
+	DriverMemberID *string `json:"driver_member_id,omitempty"`
+}
+
+// AddCarpoolDriverRequest adds a driver. Set MemberID for a member of the
+// caller's family, or Name plus Phone/Email for an external contact.
+type AddCarpoolDriverRequest struct {
+	MemberID *string `json:"member_id,omitempty"`
+	Name     string  `json:"name,omitempty"`
+	Phone    string  `json:"phone,omitempty"`
+	Email    string  `json:"email,omitempty"`
+}
+
+// JoinCarpoolRequest lets another famstack family join a group as a driver
+type JoinCarpoolRequest struct {
+	JoinCode string `json:"join_code" validate:"required"`
+	MemberID string `json:"member_id" validate:"required"`
+}
+
+// GenerateCarpoolRotationRequest assigns drivers to practices between two dates
+type GenerateCarpoolRotationRequest struct {
+	From string `json:"from" validate:"required"` // YYYY-MM-DD, inclusive
+	To   string `json:"to" validate:"required"`   // YYYY-MM-DD, inclusive
+}
+
+// CreateCarpoolSwapRequest asks ToDriverID to take over an assignment
+type CreateCarpoolSwapRequest struct {
+	AssignmentID string `json:"assignment_id" validate:"required"`
+	ToDriverID   string `json:"to_driver_id" validate:"required"`
+	Note         string `json:"note,omitempty"`
+}
+
+// RespondCarpoolSwapRequest accepts, declines or cancels a swap request
+type RespondCarpoolSwapRequest struct {
+	Status string `json:"status" validate:"required,oneof=accepted declined cancelled"`
+}
+
+// IsExternal reports whether the driver is outside famstack
+func (d *CarpoolDriver) IsExternal() bool {
+	return d.MemberID == nil
+}
+
+// Driver returns the group's driver with the given ID
+func (g *CarpoolGroup) Driver(driverID string) *CarpoolDriver {
+	for i := range g.Drivers {
+		if g.Drivers[i].ID == driverID {
+			return &g.Drivers[i]
+		}
+	}
+	return nil
+}
+
+// HasFamily reports whether the family organizes or drives in the group
+func (g *CarpoolGroup) HasFamily(familyID string) bool {
+	if g.FamilyID == familyID {
+		return true
+	}
+	for _, driver := range g.Drivers {
+		if driver.FamilyID != nil && *driver.FamilyID == familyID {
+			return true
+		}
+	}
+	return false
+}
+
+// CanActFor reports whether the family may act on the driver's behalf. Famstack
+// drivers act for themselves; the organizer acts for external drivers.
+func (g *CarpoolGroup) CanActFor(familyID string, driver *CarpoolDriver) bool {
+	if driver.IsExternal() {
+		return g.FamilyID == familyID
+	}
+	return driver.FamilyID != nil && *driver.FamilyID == familyID
+}
+
+// RotateDrivers assigns drivers, in the given order, to each date round-robin.
+// The rotation continues after lastDriverID so consecutive runs stay fair.
+func RotateDrivers(drivers []CarpoolDriver, dates []string, lastDriverID string) map[string]string {
+	assigned := make(map[string]string, len(dates))
+	if len(drivers) == 0 {
+		return assigned
+	}
+
+	next := 0
+	for i, driver := range drivers {
+		if driver.ID == lastDriverID {
+			next = (i + 1) % len(drivers)
+			break
+		}
+	}
+
+	for _, date := range dates {
+		assigned[date] = drivers[next].ID
+		next = (next + 1) % len(drivers)
+	}
+	return assigned
+}
+
+// ValidateCarpoolGroup checks a carpool group's fields
+func ValidateCarpoolGroup(req *CreateCarpoolGroupRequest) error {
+	validator := validation.NewValidator()
+
+	validator.Required("activity_id", req.ActivityID)
+	validator.Required("name", req.Name)
+	validator.MaxLength("name", req.Name, 255)
+
+	return validator.ToError()
+}
+
+// ValidateCarpoolDriver checks an external driver's contact details
+func ValidateCarpoolDriver(req *AddCarpoolDriverRequest) error {
+	validator := validation.NewValidator()
+
+	if req.MemberID == nil {
+		validator.Required("name", req.Name)
+		if req.Phone == "" && req.Email == "" {
+			validator.AddError("phone", "external drivers need a phone number or email")
+		}
+	}
+	validator.MaxLength("name", req.Name, 255)
+	validator.MaxLength("phone", req.Phone, 50)
+	if req.Email != "" {
+		if _, err := mail.ParseAddress(req.Email); err != nil {
+			validator.AddError("email", "email must be a valid email address")
+		}
+	}
+
+	return validator.ToError()
+}
@@ -0,0 +1,226 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// Where a member's presence on a day comes from
+const (
+	CustodySourceFullTime = "full_time" // the member has no custody schedule
+	CustodySourceSchedule = "schedule"
+	CustodySourceOverride = "override"
+)
+
+// CustodySchedule is a recurring pattern of days a member spends at this
+// household, e.g. Mon–Wed every week or Sat–Sun every other week. A member
+// with no custody schedules lives here full time.
+type CustodySchedule struct {
+	ID            string    `json:"id" db:"id"`
+	FamilyID      string    `json:"family_id" db:"family_id"`
+	MemberID      string    `json:"member_id" db:"member_id"`
+	Label         string    `json:"label" db:"label"`
+	DaysOfWeek    []string  `json:"days_of_week" db:"days_of_week"`     // lowercase weekday names
+	IntervalWeeks int       `json:"interval_weeks" db:"interval_weeks"` // 2 for alternate weeks
+	AnchorDate    string    `json:"anchor_date" db:"anchor_date"`       // YYYY-MM-DD in an "on" week
+	StartDate     *string   `json:"start_date" db:"start_date"`         // YYYY-MM-DD, inclusive
+	EndDate       *string   `json:"end_date" db:"end_date"`             // YYYY-MM-DD, inclusive
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateCustodyScheduleRequest creates a custody schedule
+type CreateCustodyScheduleRequest struct {
+	MemberID      string   `json:"member_id" validate:"required"`
+	Label         string   `json:"label,omitempty"`
+	DaysOfWeek    []string `json:"days_of_week" validate:"required,min=1"`
+	IntervalWeeks int      `json:"interval_weeks,omitempty"` // defaults to 1
+	AnchorDate    string   `json:"anchor_date,omitempty"`    // defaults to today
+	StartDate     *string  `json:"start_date,omitempty"`
+	EndDate       *string  `json:"end_date,omitempty"`
+}
+
+// UpdateCustodyScheduleRequest changes fields on a custody schedule. An empty
+// start_date or end_date clears it.
+type UpdateCustodyScheduleRequest struct {
+	Label         *string   `json:"label,omitempty"`
+	DaysOfWeek    *[]string `json:"days_of_week,omitempty"`
+	IntervalWeeks *int      `json:"interval_weeks,omitempty"`
+	AnchorDate    *string   `json:"anchor_date,omitempty"`
+	StartDate     *string   `json:"start_date,omitempty"`
+	EndDate       *string   `json:"end_date,omitempty"`
+}
+
+// CustodyOverride marks a member present or away on one date regardless of
+// their schedule, for holidays and swapped weekends
+type CustodyOverride struct {
+	ID        string    `json:"id" db:"id"`
+	FamilyID  string    `json:"family_id" db:"family_id"`
+	MemberID  string    `json:"member_id" db:"member_id"`
+	Date      string    `json:"date" db:"date"`
+	Present   bool      `json:"present" db:"present"`
+	Note      string    `json:"note" db:"note"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// SetCustodyOverrideRequest creates or replaces the override for a member's date
+type SetCustodyOverrideRequest struct {
+	MemberID string `json:"member_id" validate:"required"`
+	Date     string `json:"date" validate:"required"`
+	Present  bool   `json:"present"`
+	Note     string `json:"note,omitempty"`
+}
+
+// CustodyPresence is whether one member is at this household on a day
+type CustodyPresence struct {
+	MemberID string `json:"member_id"`
+	Name     string `json:"name"`
+	Present  bool   `json:"present"`
+	Source   string `json:"source"`
+}
+
+// CustodyDay lists presence for each member with a custody schedule
+type CustodyDay struct {
+	Date    string            `json:"date"`
+	Members []CustodyPresence `json:"members"`
+}
+
+// CustodyCalendar is the custody view over a date range
+type CustodyCalendar struct {
+	StartDate string       `json:"start_date"`
+	EndDate   string       `json:"end_date"`
+	Days      []CustodyDay `json:"days"`
+}
+
+// CheckCustodyRequest asks whether members are present for a proposed event
+type CheckCustodyRequest struct {
+	MemberIDs []string  `json:"member_ids"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// CustodyConflict describes a member scheduled on a day they're away
+type CustodyConflict struct {
+	MemberID string `json:"member_id"`
+	Name     string `json:"name"`
+	Date     string `json:"date"`
+}
+
+// Message returns a human-readable warning for the conflict
+func (c CustodyConflict) Message() string {
+	day := c.Date
+	if date, err := time.Parse("2006-01-02", c.Date); err == nil {
+		day = date.Format("Mon Jan 2")
+	}
+	return c.Name + " is not at this household on " + day
+}
+
+// Covers reports whether the schedule puts the member here on date
+func (s *CustodySchedule) Covers(date time.Time) bool {
+	day := date.Format("2006-01-02")
+	if s.StartDate != nil && day < *s.StartDate {
+		return false
+	}
+	if s.EndDate != nil && day > *s.EndDate {
+		return false
+	}
+
+	weekday := strings.ToLower(date.Weekday().String())
+	onDay := false
+	for _, d := range s.DaysOfWeek {
+		if d == weekday {
+			onDay = true
+			break
+		}
+	}
+	if !onDay {
+		return false
+	}
+
+	if s.IntervalWeeks <= 1 {
+		return true
+	}
+	anchor, err := time.Parse("2006-01-02", s.AnchorDate)
+	if err != nil {
+		return false
+	}
+	weeks := int(custodyWeekStart(date).Sub(custodyWeekStart(anchor)).Hours()) / (24 * 7)
+	return weeks%s.IntervalWeeks == 0
+}
+
+// custodyWeekStart returns the Monday starting date's week, so a weekend
+// falls in the same week as the days before it
+func custodyWeekStart(date time.Time) time.Time {
+	offset := (int(date.Weekday()) + 6) % 7
+	return time.Date(date.Year(), date.Month(), date.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
+
+// MemberPresentOn reports whether a member is at this household on date and
+// why. Overrides win; otherwise a member with schedules must be covered by
+// one, and a member without any is always here.
+func MemberPresentOn(schedules []CustodySchedule, overrides []CustodyOverride, memberID string, date time.Time) (bool, string) {
+	day := date.Format("2006-01-02")
+	for _, override := range overrides {
+		if override.MemberID == memberID && override.Date == day {
+			return override.Present, CustodySourceOverride
+		}
+	}
+
+	hasSchedule := false
+	for i := range schedules {
+		if schedules[i].MemberID != memberID {
+			continue
+		}
+		hasSchedule = true
+		if schedules[i].Covers(date) {
+			return true, CustodySourceSchedule
+		}
+	}
+	if !hasSchedule {
+		return true, CustodySourceFullTime
+	}
+	return false, CustodySourceSchedule
+}
+
+// ValidateCustodySchedule checks the fields shared by create and update
+func ValidateCustodySchedule(label string, daysOfWeek []string, intervalWeeks int, anchorDate string, startDate, endDate *string) error {
+	validator := validation.NewValidator()
+
+	validator.MaxLength("label", label, 255)
+
+	if len(daysOfWeek) == 0 {
+		validator.AddError("days_of_week", "at least one day is required")
+	}
+	validDays := []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+	for _, day := range daysOfWeek {
+		validator.OneOf("days_of_week", day, validDays)
+	}
+
+	if intervalWeeks < 1 || intervalWeeks > 4 {
+		validator.AddError("interval_weeks", "interval_weeks must be between 1 and 4")
+	}
+	if _, err := time.Parse("2006-01-02", anchorDate); err != nil {
+		validator.AddError("anchor_date", "anchor_date must be in YYYY-MM-DD format")
+	}
+
+	startOK, endOK := true, true
+	if startDate != nil {
+		if _, err := time.Parse("2006-01-02", *startDate); err != nil {
+			validator.AddError("start_date", "start_date must be in YYYY-MM-DD format")
+			startOK = false
+		}
+	}
+	if endDate != nil {
+		if _, err := time.Parse("2006-01-02", *endDate); err != nil {
+			validator.AddError("end_date", "end_date must be in YYYY-MM-DD format")
+			endOK = false
+		}
+	}
+	if startDate != nil && endDate != nil && startOK && endOK && *endDate < *startDate {
+		validator.AddError("end_date", "end_date must not be before start_date")
+	}
+
+	return validator.ToError()
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Dashboard section names
+const (
+	DashboardSectionAgenda        = "agenda"
+	DashboardSectionOverdueTasks  = "overdue_tasks"
+	DashboardSectionNotifications = "notifications"
+	DashboardSectionIntegrations  = "integrations"
+	DashboardSectionWeather       = "weather"
+	DashboardSectionAnnouncements = "announcements"
+	DashboardSectionPoints        = "points"
+)
+
+// Dashboard section statuses
+const (
+	DashboardStatusOK          = "ok"
+	DashboardStatusError       = "error"
+	DashboardStatusUnavailable = "unavailable" // nothing in this install provides the section
+)
+
+// DashboardSection is one independently loaded part of the dashboard. A failed
+// section reports its error without failing the others.
+type DashboardSection struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Data   any    `json:"data,omitempty"`
+}
+
+// Dashboard is the single payload behind the family home screen
+type Dashboard struct {
+	FamilyID    string                      `json:"family_id"`
+	Date        string                      `json:"date"` // YYYY-MM-DD in the family timezone
+	GeneratedAt time.Time                   `json:"generated_at"`
+	Sections    map[string]DashboardSection `json:"sections"`
+}
+
+// IntegrationHealth summarizes whether an integration is working
+type IntegrationHealth struct {
+	ID          string     `json:"id"`
+	DisplayName string     `json:"display_name"`
+	Provider    string     `json:"provider"`
+	Status      string     `json:"status"`
+	Healthy     bool       `json:"healthy"`
+	LastSyncAt  *time.Time `json:"last_sync_at"`
+	LastError   *string    `json:"last_error"`
+}
+
+// MemberPoints is a member's points from completed scheduled tasks this week
+type MemberPoints struct {
+	MemberID  string `json:"member_id"`
+	Name      string `json:"name"`
+	Points    int    `json:"points"`
+	Completed int    `json:"completed"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Digest template names
+const (
+	DigestTemplateWeek   = "week"   // seven-day overview
+	DigestTemplateAgenda = "agenda" // a single day in detail
+)
+
+// AgendaDigest is a family's schedule over one or more days, in the family
+// timezone. It is the data the digest templates render.
+type AgendaDigest struct {
+	FamilyID   string      `json:"family_id"`
+	FamilyName string      `json:"family_name"`
+	Timezone   string      `json:"timezone"`
+	Start      time.Time   `json:"start"`
+	Days       []AgendaDay `json:"days"`
+	TotalItems int         `json:"total_items"`
+}
+
+// AgendaDay lists one day's events and tasks. All-day entries come first,
+// then timed entries in order.
+type AgendaDay struct {
+	Date   time.Time     `json:"date"`
+	Events []AgendaEntry `json:"events"`
+	Tasks  []AgendaEntry `json:"tasks"` // tasks and routines
+}
+
+// AgendaEntry is one event or task, listed once however many members it involves
+type AgendaEntry struct {
+	Kind    string    `json:"kind"` // a TimelineItem kind
+	Title   string    `json:"title"`
+	Start   time.Time `json:"start"`
+	AllDay  bool      `json:"all_day"`
+	Status  string    `json:"status,omitempty"`
+	Members []string  `json:"members"` // display names; empty for family-wide items
+}
+
+// Done reports whether the entry is a completed task
+func (e AgendaEntry) Done() bool {
+	return TaskStatus(e.Status) == TaskStatusCompleted
+}
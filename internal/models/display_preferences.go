@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// Display views a tablet or phone can open on
+const (
+	DisplayViewDay    = "day"
+	DisplayViewWeek   = "week"
+	DisplayViewMonth  = "month"
+	DisplayViewAgenda = "agenda"
+)
+
+// Where resolved display preferences came from
+const (
+	DisplayPreferencesSourceDevice  = "device"
+	DisplayPreferencesSourceMember  = "member"
+	DisplayPreferencesSourceDefault = "default"
+)
+
+// DisplayPreferences controls which slice of family data a display shows.
+// Exactly one of MemberID and DeviceID is set.
+type DisplayPreferences struct {
+	ID               string    `json:"id,omitempty" db:"id"`
+	FamilyID         string    `json:"family_id" db:"family_id"`
+	MemberID         *string   `json:"member_id" db:"member_id"`
+	DeviceID         *string   `json:"device_id" db:"device_id"`
+	DeviceName       string    `json:"device_name" db:"device_name"`
+	VisibleMemberIDs []string  `json:"visible_member_ids" db:"visible_member_ids"` // empty means everyone
+	DefaultView      string    `json:"default_view" db:"default_view"`
+	RangeDays        int       `json:"range_days" db:"range_days"`
+	HiddenCategories []string  `json:"hidden_categories" db:"hidden_categories"`
+	UpdatedBy        *string   `json:"updated_by" db:"updated_by"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SaveDisplayPreferencesRequest changes display preferences. Omitted fields keep
+// their current value, or the default when nothing was saved yet.
+type SaveDisplayPreferencesRequest struct {
+	DeviceName       *string   `json:"device_name,omitempty"`
+	VisibleMemberIDs *[]string `json:"visible_member_ids,omitempty"`
+	DefaultView      *string   `json:"default_view,omitempty"`
+	RangeDays        *int      `json:"range_days,omitempty"`
+	HiddenCategories *[]string `json:"hidden_categories,omitempty"`
+}
+
+// DisplayBootstrap is everything a display needs to render on startup
+type DisplayBootstrap struct {
+	Family      *Family             `json:"family"`
+	MemberID    string              `json:"member_id"`
+	Members     []*FamilyMember     `json:"members"` // the visible columns, in display order
+	Preferences *DisplayPreferences `json:"preferences"`
+	Source      string              `json:"source"`
+}
+
+// DefaultDisplayPreferences returns the preferences used before anything is saved
+func DefaultDisplayPreferences(familyID string) *DisplayPreferences {
+	return &DisplayPreferences{
+		FamilyID:         familyID,
+		VisibleMemberIDs: []string{},
+		DefaultView:      DisplayViewWeek,
+		RangeDays:        7,
+		HiddenCategories: []string{},
+	}
+}
+
+// ShowsMember reports whether the member's column appears on the display
+func (p *DisplayPreferences) ShowsMember(memberID string) bool {
+	if len(p.VisibleMemberIDs) == 0 {
+		return true
+	}
+	for _, id := range p.VisibleMemberIDs {
+		if id == memberID {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the preference values. Member IDs are checked against the
+// family by the service.
+func (p *DisplayPreferences) Validate() error {
+	validator := validation.NewValidator()
+
+	validator.MaxLength("device_name", p.DeviceName, 100)
+	validator.OneOf("default_view", p.DefaultView, []string{DisplayViewDay, DisplayViewWeek, DisplayViewMonth, DisplayViewAgenda})
+	if p.RangeDays < 1 || p.RangeDays > 31 {
+		validator.AddError("range_days", "range_days must be between 1 and 31")
+	}
+	for _, category := range p.HiddenCategories {
+		validator.Required("hidden_categories", category)
+		validator.MaxLength("hidden_categories", category, 50)
+	}
+
+	return validator.ToError()
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// DisplayProjectionWindow is how far ahead the wall display projection reaches
+const DisplayProjectionWindow = 48 * time.Hour
+
+// DisplayProjection is a family's next 48 hours as the wall display shows it,
+// built ahead of time so display reads are a single row lookup. Times are in
+// the family timezone.
+type DisplayProjection struct {
+	FamilyID    string                 `json:"family_id"`
+	Timezone    string                 `json:"timezone"`
+	WindowStart time.Time              `json:"window_start"`
+	WindowEnd   time.Time              `json:"window_end"`
+	Events      []UnifiedCalendarEvent `json:"events"`
+	Timelines   []Timeline             `json:"timelines"` // one per day the window touches
+	GeneratedAt time.Time              `json:"generated_at"`
+
+	// Stale is set when the family's data has changed, or the projection has
+	// aged, since it was built. A refresh is already on its way.
+	Stale bool `json:"stale"`
+}
+
+// Timeline returns a copy of the projected timeline for a date (YYYY-MM-DD),
+// if the window covers it. Callers may lay the copy out without changing the
+// shared projection.
+func (p *DisplayProjection) Timeline(date string) (*Timeline, bool) {
+	for i := range p.Timelines {
+		if p.Timelines[i].Date != date {
+			continue
+		}
+		timeline := p.Timelines[i]
+		timeline.Lanes = make([]TimelineLane, len(p.Timelines[i].Lanes))
+		for j, lane := range p.Timelines[i].Lanes {
+			lane.Items = slices.Clone(lane.Items)
+			lane.AllDay = slices.Clone(lane.AllDay)
+			timeline.Lanes[j] = lane
+		}
+		return &timeline, true
+	}
+	return nil, false
+}
@@ -0,0 +1,240 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// TaskStatus is the lifecycle state of a task
+type TaskStatus string
+
+// TaskType distinguishes todos, chores and appointments
+type TaskType string
+
+// EventType classifies a calendar event
+type EventType string
+
+// Priority orders tasks, schedules and events from low to urgent
+type Priority int
+
+// TaskType constants
+const (
+	TaskTypeTodo        TaskType = "todo"
+	TaskTypeChore       TaskType = "chore"
+	TaskTypeAppointment TaskType = "appointment"
+)
+
+// TaskStatus constants
+const (
+	TaskStatusPending   TaskStatus = "pending"
+	TaskStatusCompleted TaskStatus = "completed"
+)
+
+// EventType constants
+const (
+	EventTypeAppointment EventType = "appointment"
+	EventTypeEvent       EventType = "event"
+	EventTypeReminder    EventType = "reminder"
+)
+
+// Priority constants, matching the Low/Normal/High/Urgent options in the UI
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+	PriorityUrgent
+)
+
+// ParseTaskStatus accepts any casing or surrounding whitespace and returns
+// the canonical status
+func ParseTaskStatus(value string) (TaskStatus, error) {
+	status := TaskStatus(normalizeEnum(value))
+	if !status.Valid() {
+		return "", fmt.Errorf("status must be 'pending' or 'completed'")
+	}
+	return status, nil
+}
+
+// Valid reports whether s is a canonical task status
+func (s TaskStatus) Valid() bool {
+	switch s {
+	case TaskStatusPending, TaskStatusCompleted:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseTaskType accepts any casing or surrounding whitespace and returns the
+// canonical task type
+func ParseTaskType(value string) (TaskType, error) {
+	taskType := TaskType(normalizeEnum(value))
+	if !taskType.Valid() {
+		return "", fmt.Errorf("task_type must be 'todo', 'chore', or 'appointment'")
+	}
+	return taskType, nil
+}
+
+// Valid reports whether t is a canonical task type
+func (t TaskType) Valid() bool {
+	switch t {
+	case TaskTypeTodo, TaskTypeChore, TaskTypeAppointment:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseEventType accepts any casing or surrounding whitespace and returns the
+// canonical event type
+func ParseEventType(value string) (EventType, error) {
+	eventType := EventType(normalizeEnum(value))
+	if !eventType.Valid() {
+		return "", fmt.Errorf("event_type must be 'appointment', 'event', or 'reminder'")
+	}
+	return eventType, nil
+}
+
+// Valid reports whether t is a canonical event type
+func (t EventType) Valid() bool {
+	switch t {
+	case EventTypeAppointment, EventTypeEvent, EventTypeReminder:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParsePriority checks that value is one of the four priority levels
+func ParsePriority(value int) (Priority, error) {
+	priority := Priority(value)
+	if !priority.Valid() {
+		return 0, fmt.Errorf("priority must be between %d and %d", PriorityLow, PriorityUrgent)
+	}
+	return priority, nil
+}
+
+// Valid reports whether p is a known priority level
+func (p Priority) Valid() bool {
+	return p >= PriorityLow && p <= PriorityUrgent
+}
+
+func normalizeEnum(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// Normalize canonicalizes the request's enum fields, returning validation
+// errors for any that are not recognized. Services call it before writing,
+// so "Chore" is stored as "chore" rather than rejected by the schema.
+func (r *CreateTaskRequest) Normalize() error {
+	validator := validation.NewValidator()
+	if taskType, err := ParseTaskType(string(r.TaskType)); err != nil {
+		validator.AddError("task_type", err.Error())
+	} else {
+		r.TaskType = taskType
+	}
+	normalizePriority(validator, &r.Priority)
+	if r.DueTime != nil {
+		if r.DueDate == nil {
+			validator.AddError("due_time", "due_time needs a due_date")
+		}
+		validateDueTime(validator, *r.DueTime)
+	}
+	return validator.ToError()
+}
+
+// Normalize canonicalizes status and priority when they are being changed
+func (r *UpdateTaskRequest) Normalize() error {
+	validator := validation.NewValidator()
+	if r.Status != nil {
+		if status, err := ParseTaskStatus(string(*r.Status)); err != nil {
+			validator.AddError("status", err.Error())
+		} else {
+			r.Status = &status
+		}
+	}
+	if r.Priority != nil {
+		normalizePriority(validator, r.Priority)
+	}
+	if r.DueTime != nil && *r.DueTime != "" {
+		validateDueTime(validator, *r.DueTime)
+	}
+	return validator.ToError()
+}
+
+// validateDueTime checks a task's HH:MM due time
+func validateDueTime(validator *validation.Validator, dueTime string) {
+	if _, err := time.Parse("15:04", dueTime); err != nil {
+		validator.AddError("due_time", "due_time must be in HH:MM format")
+	}
+}
+
+// Normalize canonicalizes task_type, priority and rotation_strategy
+func (r *CreateTaskScheduleRequest) Normalize() error {
+	validator := validation.NewValidator()
+	if taskType, err := ParseTaskType(string(r.TaskType)); err != nil {
+		validator.AddError("task_type", err.Error())
+	} else {
+		r.TaskType = taskType
+	}
+	normalizePriority(validator, &r.Priority)
+	r.RotationStrategy = normalizeEnum(r.RotationStrategy)
+	if r.RotationStrategy == "" {
+		r.RotationStrategy = RotationNone
+	}
+	return validator.ToError()
+}
+
+// Normalize canonicalizes task_type, priority and rotation_strategy when they
+// are being changed
+func (r *UpdateTaskScheduleRequest) Normalize() error {
+	validator := validation.NewValidator()
+	if r.TaskType != nil {
+		if taskType, err := ParseTaskType(string(*r.TaskType)); err != nil {
+			validator.AddError("task_type", err.Error())
+		} else {
+			r.TaskType = &taskType
+		}
+	}
+	if r.Priority != nil {
+		normalizePriority(validator, r.Priority)
+	}
+	if r.RotationStrategy != nil {
+		strategy := normalizeEnum(*r.RotationStrategy)
+		r.RotationStrategy = &strategy
+	}
+	return validator.ToError()
+}
+
+// Normalize canonicalizes event_type
+func (r *CreateCalendarEventRequest) Normalize() error {
+	validator := validation.NewValidator()
+	if eventType, err := ParseEventType(string(r.EventType)); err != nil {
+		validator.AddError("event_type", err.Error())
+	} else {
+		r.EventType = eventType
+	}
+	return validator.ToError()
+}
+
+// Normalize canonicalizes event_type when it is being changed
+func (r *UpdateCalendarEventRequest) Normalize() error {
+	validator := validation.NewValidator()
+	if r.EventType != nil {
+		if eventType, err := ParseEventType(string(*r.EventType)); err != nil {
+			validator.AddError("event_type", err.Error())
+		} else {
+			r.EventType = &eventType
+		}
+	}
+	return validator.ToError()
+}
+
+func normalizePriority(validator *validation.Validator, priority *Priority) {
+	if _, err := ParsePriority(int(*priority)); err != nil {
+		validator.AddError("priority", err.Error())
+	}
+}
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// Event fields a color rule can look at
+const (
+	ColorRuleFieldTitle       = "title"
+	ColorRuleFieldDescription = "description"
+	ColorRuleFieldLocation    = "location"
+	ColorRuleFieldOrganizer   = "organizer"
+	ColorRuleFieldSource      = "source"     // the provider, such as "google", or the integration ID
+	ColorRuleFieldEventType   = "event_type" // appointment, event or reminder
+	ColorRuleFieldAttendee    = "attendee"   // any attendee: a member ID or, for synced events, an email
+)
+
+// EventSourceFamstack is the source of events made in famstack itself rather
+// than brought in by an integration
+const EventSourceFamstack = "famstack"
+
+// Color rule match types
+const (
+	ColorRuleMatchContains = "contains" // case-insensitive substring
+	ColorRuleMatchEquals   = "equals"   // case-insensitive, whole value
+	ColorRuleMatchDomain   = "domain"   // email domain or any subdomain of it
+)
+
+// EventColorRule assigns a category and color to events whose field matches
+// the pattern. Rules run in position order and the first match wins.
+type EventColorRule struct {
+	ID        string    `json:"id" db:"id"`
+	FamilyID  string    `json:"family_id" db:"family_id"`
+	Name      string    `json:"name" db:"name"`
+	Field     string    `json:"field" db:"field"`
+	MatchType string    `json:"match_type" db:"match_type"`
+	Pattern   string    `json:"pattern" db:"pattern"`
+	Category  string    `json:"category" db:"category"`
+	Color     string    `json:"color" db:"color"`
+	Position  int       `json:"position" db:"position"`
+	Enabled   bool      `json:"enabled" db:"enabled"`
+	CreatedBy *string   `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateEventColorRuleRequest creates a color rule at the end of the family's list
+type CreateEventColorRuleRequest struct {
+	Name      string `json:"name" validate:"required,min=1,max=100"`
+	Field     string `json:"field" validate:"required,oneof=title description location organizer source event_type attendee"`
+	MatchType string `json:"match_type" validate:"required,oneof=contains equals domain"`
+	Pattern   string `json:"pattern" validate:"required,max=255"`
+	Category  string `json:"category,omitempty" validate:"omitempty,max=50"`
+	Color     string `json:"color,omitempty"`
+	Enabled   *bool  `json:"enabled,omitempty"`
+}
+
+// UpdateEventColorRuleRequest changes fields on a color rule
+type UpdateEventColorRuleRequest struct {
+	Name      *string `json:"name,omitempty"`
+	Field     *string `json:"field,omitempty"`
+	MatchType *string `json:"match_type,omitempty"`
+	Pattern   *string `json:"pattern,omitempty"`
+	Category  *string `json:"category,omitempty"`
+	Color     *string `json:"color,omitempty"`
+	Enabled   *bool   `json:"enabled,omitempty"`
+}
+
+// ReorderEventColorRulesRequest lists every rule ID of the family in its new order
+type ReorderEventColorRulesRequest struct {
+	RuleIDs []string `json:"rule_ids" validate:"required,min=1"`
+}
+
+// EventRuleSample holds the event fields rules are evaluated against
+type EventRuleSample struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Organizer   string `json:"organizer,omitempty"` // email address, optionally "Name <email>"
+
+	Source        string   `json:"source,omitempty"` // provider, or EventSourceFamstack
+	IntegrationID string   `json:"integration_id,omitempty"`
+	EventType     string   `json:"event_type,omitempty"`
+	Attendees     []string `json:"attendees,omitempty"`
+}
+
+// TestEventColorRulesRequest asks which rules would match the sample events
+type TestEventColorRulesRequest struct {
+	Events []EventRuleSample `json:"events" validate:"required,min=1"`
+}
+
+// EventRuleTestResult reports the rules matching one sample event
+type EventRuleTestResult struct {
+	Event        EventRuleSample  `json:"event"`
+	MatchedRules []EventColorRule `json:"matched_rules"`
+	AppliedRule  *EventColorRule  `json:"applied_rule"`
+	Category     string           `json:"category"`
+	Color        string           `json:"color"`
+}
+
+// Matches reports whether the rule applies to the sample. Disabled rules never match.
+func (r *EventColorRule) Matches(sample EventRuleSample) bool {
+	if !r.Enabled {
+		return false
+	}
+
+	var values []string
+	switch r.Field {
+	case ColorRuleFieldTitle:
+		values = []string{sample.Title}
+	case ColorRuleFieldDescription:
+		values = []string{sample.Description}
+	case ColorRuleFieldLocation:
+		values = []string{sample.Location}
+	case ColorRuleFieldOrganizer:
+		values = []string{sample.Organizer}
+	case ColorRuleFieldSource:
+		values = []string{sample.Source, sample.IntegrationID}
+	case ColorRuleFieldEventType:
+		values = []string{sample.EventType}
+	case ColorRuleFieldAttendee:
+		values = sample.Attendees
+	}
+
+	pattern := strings.ToLower(strings.TrimSpace(r.Pattern))
+	if pattern == "" {
+		return false
+	}
+	for _, value := range values {
+		if r.matchesValue(strings.ToLower(strings.TrimSpace(value)), pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesValue compares one lowercased field value with the lowercased pattern
+func (r *EventColorRule) matchesValue(value, pattern string) bool {
+	if value == "" {
+		return false
+	}
+
+	switch r.MatchType {
+	case ColorRuleMatchContains:
+		return strings.Contains(value, pattern)
+	case ColorRuleMatchEquals:
+		return value == pattern
+	case ColorRuleMatchDomain:
+		domain := emailDomain(value)
+		pattern = strings.TrimPrefix(pattern, "@")
+		return domain == pattern || strings.HasSuffix(domain, "."+pattern)
+	default:
+		return false
+	}
+}
+
+// MatchEventColorRule returns the first rule in rules that matches the sample, or nil
+func MatchEventColorRule(rules []EventColorRule, sample EventRuleSample) *EventColorRule {
+	for i := range rules {
+		if rules[i].Matches(sample) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// emailDomain extracts the domain from "user@example.org" or "Name <user@example.org>"
+func emailDomain(value string) string {
+	if start := strings.LastIndex(value, "<"); start >= 0 {
+		value = strings.TrimSuffix(value[start+1:], ">")
+	}
+	if at := strings.LastIndex(value, "@"); at >= 0 {
+		value = value[at+1:]
+	}
+	return strings.TrimSpace(value)
+}
+
+// ValidateEventColorRule checks the fields shared by create and update
+func ValidateEventColorRule(name, field, matchType, pattern, category, color string) error {
+	validator := validation.NewValidator()
+
+	validator.Required("name", name)
+	validator.MaxLength("name", name, 100)
+	validator.OneOf("field", field, []string{
+		ColorRuleFieldTitle, ColorRuleFieldDescription, ColorRuleFieldLocation, ColorRuleFieldOrganizer,
+		ColorRuleFieldSource, ColorRuleFieldEventType, ColorRuleFieldAttendee,
+	})
+	validator.OneOf("match_type", matchType, []string{ColorRuleMatchContains, ColorRuleMatchEquals, ColorRuleMatchDomain})
+	validator.Required("pattern", pattern)
+	validator.MaxLength("pattern", pattern, 255)
+	validator.MaxLength("category", category, 50)
+
+	if matchType == ColorRuleMatchDomain && field != ColorRuleFieldOrganizer && field != ColorRuleFieldAttendee {
+		validator.AddError("match_type", "domain matching only applies to the organizer and attendee fields")
+	}
+	if color != "" && !hexColorPattern.MatchString(color) {
+		validator.AddError("color", "color must be a hex value like #ef4444")
+	}
+	if category == "" && color == "" {
+		validator.AddError("color", "a rule must set a category, a color, or both")
+	}
+
+	return validator.ToError()
+}
@@ -30,6 +30,8 @@ type FamilyMember struct {
 	LastLoginAt   *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
 	DisplayOrder  int        `json:"display_order" db:"display_order"`
 	IsActive      bool       `json:"is_active" db:"is_active"`
+	Birthdate     *string    `json:"birthdate,omitempty" db:"birthdate"`     // YYYY-MM-DD
+	Anniversary   *string    `json:"anniversary,omitempty" db:"anniversary"` // YYYY-MM-DD, a wedding anniversary
 	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
 }
@@ -78,6 +80,8 @@ type CreateFamilyMemberRequest struct {
 	Color        *string    `json:"color,omitempty" validate:"omitempty,hexcolor"`
 	Initial      *string    `json:"initial,omitempty" validate:"omitempty,len=1"`
 	DisplayOrder *int       `json:"display_order,omitempty"`
+	Birthdate    *string    `json:"birthdate,omitempty"`
+	Anniversary  *string    `json:"anniversary,omitempty"`
 }
 
 // UpdateFamilyMemberRequest represents a request to update a family member
@@ -90,6 +94,9 @@ type UpdateFamilyMemberRequest struct {
 	Initial      *string     `json:"initial,omitempty" validate:"omitempty,len=1"`
 	DisplayOrder *int        `json:"display_order,omitempty"`
 	IsActive     *bool       `json:"is_active,omitempty"`
+	// Birthdate and Anniversary are YYYY-MM-DD; an empty string clears them
+	Birthdate   *string `json:"birthdate,omitempty"`
+	Anniversary *string `json:"anniversary,omitempty"`
 }
 
 // FamilyMemberWithStats represents a family member with additional statistics
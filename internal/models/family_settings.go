@@ -0,0 +1,67 @@
+package models
+
+import (
+	"regexp"
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// What happens to a pending task once the day it was due on is over
+const (
+	// TaskRolloverKeep leaves it on its day, overdue
+	TaskRolloverKeep = "keep"
+	// TaskRolloverRollForward moves it to today, at the same time
+	TaskRolloverRollForward = "roll_forward"
+	// TaskRolloverSkipScheduled removes it when a schedule made it, as the
+	// chore comes round again; other tasks stay overdue
+	TaskRolloverSkipScheduled = "skip_scheduled"
+)
+
+// TaskRolloverPolicies are the rollover policies a family can choose
+var TaskRolloverPolicies = []string{TaskRolloverKeep, TaskRolloverRollForward, TaskRolloverSkipScheduled}
+
+// DefaultLocale is the locale of a family that never chose one
+const DefaultLocale = "en-US"
+
+// localePattern matches BCP 47 tags like "en", "en-GB" or "zh-Hant-TW"
+var localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// FamilySettings are how a family keeps time. Members render with the
+// family's locale and week start unless they chose their own.
+type FamilySettings struct {
+	FamilyID     string `json:"family_id" db:"id"`
+	Timezone     string `json:"timezone" db:"timezone"`
+	Locale       string `json:"locale" db:"locale"`
+	WeekStart    string `json:"week_start" db:"week_start"`
+	TaskRollover string `json:"task_rollover" db:"task_rollover"`
+}
+
+// UpdateFamilySettingsRequest changes the settings that are set
+type UpdateFamilySettingsRequest struct {
+	Timezone     *string `json:"timezone,omitempty"`
+	Locale       *string `json:"locale,omitempty"`
+	WeekStart    *string `json:"week_start,omitempty"`
+	TaskRollover *string `json:"task_rollover,omitempty"`
+}
+
+// Validate checks the request; a timezone must be in the tz database
+func (r *UpdateFamilySettingsRequest) Validate() error {
+	validator := validation.NewValidator()
+	if r.Timezone != nil {
+		// LoadLocation also takes "" and "Local", which aren't tz names
+		if _, err := time.LoadLocation(*r.Timezone); err != nil || *r.Timezone == "" || *r.Timezone == "Local" {
+			validator.AddError("timezone", "timezone must be a tz database name like America/Chicago")
+		}
+	}
+	if r.Locale != nil && !localePattern.MatchString(*r.Locale) {
+		validator.AddError("locale", "locale must be a language tag like en-US")
+	}
+	if r.WeekStart != nil {
+		validator.OneOf("week_start", *r.WeekStart, []string{WeekStartSunday, WeekStartMonday})
+	}
+	if r.TaskRollover != nil {
+		validator.OneOf("task_rollover", *r.TaskRollover, TaskRolloverPolicies)
+	}
+	return validator.ToError()
+}
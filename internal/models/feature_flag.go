@@ -0,0 +1,92 @@
+package models
+
+// Feature flags gate modules that are still being tried out, so a family
+// can turn one off, or an administrator can hold one back from everyone
+const (
+	FeatureCarpools    = "carpools"
+	FeatureFocus       = "focus"
+	FeatureInsights    = "insights"
+	FeatureMeals       = "meals"
+	FeatureRewards     = "rewards"
+	FeatureSuggestions = "suggestions"
+)
+
+// Where a flag's value came from
+const (
+	FeatureSourceDefault = "default"
+	FeatureSourceGlobal  = "global"
+	FeatureSourceFamily  = "family"
+)
+
+// FeatureFlag is a flag as defined in code
+type FeatureFlag struct {
+	Key          string `json:"key"`
+	Description  string `json:"description"`
+	Default      bool   `json:"default"`
+	Experimental bool   `json:"experimental"`
+}
+
+// FeatureFlags lists every flag with its default
+var FeatureFlags = []FeatureFlag{
+	{Key: FeatureCarpools, Description: "Carpool groups shared with other families", Default: true, Experimental: true},
+	{Key: FeatureFocus, Description: "Focus sessions that quiet notifications", Default: true, Experimental: true},
+	{Key: FeatureInsights, Description: "Busy-hours heatmap and usually free slots", Default: true, Experimental: true},
+	{Key: FeatureMeals, Description: "Recipes, meal plans and the shopping list", Default: true},
+	{Key: FeatureRewards, Description: "Points and rewards for finished chores", Default: true},
+	{Key: FeatureSuggestions, Description: "Nudges from the suggestion engine", Default: true, Experimental: true},
+}
+
+// FeatureFlagKeys lists the keys of every flag
+func FeatureFlagKeys() []string {
+	keys := make([]string, len(FeatureFlags))
+	for i, flag := range FeatureFlags {
+		keys[i] = flag.Key
+	}
+	return keys
+}
+
+// LookupFeatureFlag returns the flag with the given key
+func LookupFeatureFlag(key string) (FeatureFlag, bool) {
+	for _, flag := range FeatureFlags {
+		if flag.Key == key {
+			return flag, true
+		}
+	}
+	return FeatureFlag{}, false
+}
+
+// FeatureFlagState is a flag as it stands for a family: its value, where
+// the value came from, and the overrides set
+type FeatureFlagState struct {
+	FeatureFlag
+	Enabled        bool   `json:"enabled"`
+	Source         string `json:"source"`
+	GlobalOverride *bool  `json:"global_override"`
+	FamilyOverride *bool  `json:"family_override"`
+}
+
+// Resolve works out the flag's value: the family's override, else the
+// global one, else the default
+func (s *FeatureFlagState) Resolve() {
+	switch {
+	case s.FamilyOverride != nil:
+		s.Enabled, s.Source = *s.FamilyOverride, FeatureSourceFamily
+	case s.GlobalOverride != nil:
+		s.Enabled, s.Source = *s.GlobalOverride, FeatureSourceGlobal
+	default:
+		s.Enabled, s.Source = s.Default, FeatureSourceDefault
+	}
+}
+
+// Feature flag override scopes
+const (
+	FeatureScopeFamily = "family"
+	FeatureScopeGlobal = "global"
+)
+
+// SetFeatureFlagRequest overrides a flag for the family, or for every
+// family. A null enabled clears the override.
+type SetFeatureFlagRequest struct {
+	Enabled *bool  `json:"enabled"`
+	Scope   string `json:"scope" validate:"omitempty,oneof=family global"`
+}
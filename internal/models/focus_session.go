@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// Focus session length limits, in minutes
+const (
+	FocusSessionMinMinutes     = 5
+	FocusSessionMaxMinutes     = 240
+	FocusSessionDefaultMinutes = 60
+)
+
+// FocusSession is a family-wide quiet time, such as a homework hour. While one
+// is running, non-urgent notifications are held back and every device shows
+// its countdown.
+type FocusSession struct {
+	ID        string     `json:"id" db:"id"`
+	FamilyID  string     `json:"family_id" db:"family_id"`
+	Title     string     `json:"title" db:"title"`
+	StartedBy *string    `json:"started_by" db:"started_by"`
+	StartedAt time.Time  `json:"started_at" db:"started_at"`
+	EndsAt    time.Time  `json:"ends_at" db:"ends_at"`
+	EndedAt   *time.Time `json:"ended_at" db:"ended_at"` // set when ended early
+}
+
+// ActiveAt reports whether the session is running at t
+func (s *FocusSession) ActiveAt(t time.Time) bool {
+	return s.EndedAt == nil && !t.Before(s.StartedAt) && t.Before(s.EndsAt)
+}
+
+// FocusStatus is what devices show for the family's focus state. It is sent
+// on the focus stream whenever it changes and once a second while a session
+// counts down.
+type FocusStatus struct {
+	Active           bool          `json:"active"`
+	Session          *FocusSession `json:"session,omitempty"`
+	RemainingSeconds int           `json:"remaining_seconds"`
+}
+
+// NewFocusStatus describes the session at t; a nil or finished session is inactive
+func NewFocusStatus(session *FocusSession, t time.Time) FocusStatus {
+	if session == nil || !session.ActiveAt(t) {
+		return FocusStatus{}
+	}
+	// Round up so the countdown shows 1 until the very end, not 0
+	remaining := session.EndsAt.Sub(t)
+	return FocusStatus{
+		Active:           true,
+		Session:          session,
+		RemainingSeconds: int((remaining + time.Second - 1) / time.Second),
+	}
+}
+
+// StartFocusSessionRequest starts a focus session
+type StartFocusSessionRequest struct {
+	Title           string `json:"title,omitempty"`            // defaults to "Quiet time"
+	DurationMinutes int    `json:"duration_minutes,omitempty"` // defaults to an hour
+}
+
+// Validate checks the request after defaults are applied
+func (r *StartFocusSessionRequest) Validate() error {
+	validator := validation.NewValidator()
+	validator.MaxLength("title", r.Title, 255)
+	if r.DurationMinutes < FocusSessionMinMinutes || r.DurationMinutes > FocusSessionMaxMinutes {
+		validator.AddErrorf("duration_minutes", "duration_minutes must be between %d and %d", FocusSessionMinMinutes, FocusSessionMaxMinutes)
+	}
+	return validator.ToError()
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// GuestDay is what a guest pass shows a babysitter or grandparent: the
+// family's events for the day and the tasks a parent picked for them
+type GuestDay struct {
+	FamilyName string                 `json:"family_name"`
+	Date       time.Time              `json:"date"` // midnight in the family's timezone
+	Events     []UnifiedCalendarEvent `json:"events"`
+	Tasks      []Task                 `json:"tasks"`
+}
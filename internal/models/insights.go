@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// Availability insight limits and defaults
+const (
+	DefaultAvailabilityWeeks = 8
+	MaxAvailabilityWeeks     = 26
+	DefaultSlotFromHour      = 7  // earliest suggested start, family time
+	DefaultSlotToHour        = 21 // suggested slots end by this hour
+	MaxSlotDurationHours     = 4
+	DefaultSlotSuggestions   = 5
+	MaxSlotSuggestions       = 20
+)
+
+// UsuallyFreePercent is the share of weeks a member must be free in an hour
+// for it to count as usually free
+const UsuallyFreePercent = 75
+
+// MemberHeatmap counts, for each weekday (0 = Sunday) and hour of the day in
+// the family's timezone, how many of the covered weeks the member was busy
+type MemberHeatmap struct {
+	MemberID string     `json:"member_id"`
+	Name     string     `json:"name"`
+	Busy     [7][24]int `json:"busy"`
+	Total    int        `json:"total_busy_hours"`
+}
+
+// AvailabilityHeatmap is every member's busy hours over the last weeks weeks
+type AvailabilityHeatmap struct {
+	FamilyID    string          `json:"family_id"`
+	Timezone    string          `json:"timezone"`
+	Weeks       int             `json:"weeks"`
+	From        time.Time       `json:"from"`
+	To          time.Time       `json:"to"`
+	Members     []MemberHeatmap `json:"members"`
+	GeneratedAt time.Time       `json:"generated_at"`
+}
+
+// AvailabilityQuery picks the members and window for slot suggestions
+type AvailabilityQuery struct {
+	Weeks         int
+	MemberIDs     []string // empty means every member in the heatmap
+	DurationHours int
+	FromHour      int
+	ToHour        int
+	Limit         int
+}
+
+// SlotSuggestion is a recurring weekly slot that has tended to be free
+type SlotSuggestion struct {
+	Weekday       int      `json:"weekday"`
+	WeekdayName   string   `json:"weekday_name"`
+	Hour          int      `json:"hour"`
+	DurationHours int      `json:"duration_hours"`
+	FreePercent   int      `json:"free_percent"` // average share of weeks each member was free
+	FreeForAll    bool     `json:"free_for_all"`
+	UsuallyBusy   []string `json:"usually_busy"` // names of members who usually aren't free
+	Summary       string   `json:"summary"`
+}
+
+// AvailabilityInsights is the heatmap with the best recurring slots it suggests
+type AvailabilityInsights struct {
+	Heatmap     *AvailabilityHeatmap `json:"heatmap"`
+	Suggestions []SlotSuggestion     `json:"suggestions"`
+}
@@ -0,0 +1,289 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// Meal types, one of each per day
+const (
+	MealBreakfast = "breakfast"
+	MealLunch     = "lunch"
+	MealDinner    = "dinner"
+	MealSnack     = "snack"
+)
+
+// MealTypes lists the meal types in the order they're eaten
+var MealTypes = []string{MealBreakfast, MealLunch, MealDinner, MealSnack}
+
+// DefaultDinnerTime is when a dinner's calendar event starts unless the meal
+// says otherwise
+const DefaultDinnerTime = "18:00"
+
+// DinnerEventDuration is how long a dinner's calendar event lasts
+const DinnerEventDuration = time.Hour
+
+// MaxShoppingListDays bounds the meal plan range a shopping list is
+// generated from
+const MaxShoppingListDays = 31
+
+// Recipe is a dish the family makes, with what goes into it
+type Recipe struct {
+	ID           string             `json:"id" db:"id"`
+	FamilyID     string             `json:"family_id" db:"family_id"`
+	Title        string             `json:"title" db:"title"`
+	Instructions string             `json:"instructions" db:"instructions"`
+	Servings     int                `json:"servings" db:"servings"`
+	Ingredients  []RecipeIngredient `json:"ingredients" db:"-"`
+	CreatedBy    *string            `json:"created_by" db:"created_by"`
+	CreatedAt    time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// RecipeIngredient is one line of a recipe's ingredients
+type RecipeIngredient struct {
+	ID       string  `json:"id" db:"id"`
+	RecipeID string  `json:"-" db:"recipe_id"`
+	Name     string  `json:"name" db:"name"`
+	Quantity float64 `json:"quantity" db:"quantity"` // 0 when not given
+	Unit     string  `json:"unit" db:"unit"`
+	Position int     `json:"-" db:"position"`
+}
+
+// IngredientInput is an ingredient as a recipe request gives it
+type IngredientInput struct {
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+}
+
+// CreateRecipeRequest defines a recipe
+type CreateRecipeRequest struct {
+	Title        string            `json:"title"`
+	Instructions string            `json:"instructions"`
+	Servings     int               `json:"servings"`
+	Ingredients  []IngredientInput `json:"ingredients"`
+}
+
+// Validate trims and checks the request
+func (r *CreateRecipeRequest) Validate() error {
+	r.Title = strings.TrimSpace(r.Title)
+	validator := validation.NewValidator()
+	validator.Required("title", r.Title)
+	validator.MaxLength("title", r.Title, 255)
+	validator.MaxLength("instructions", r.Instructions, 10000)
+	validateServings(validator, r.Servings)
+	validateIngredients(validator, r.Ingredients)
+	return validator.ToError()
+}
+
+// UpdateRecipeRequest changes the fields that are set. Ingredients, when
+// set, replace the recipe's list.
+type UpdateRecipeRequest struct {
+	Title        *string            `json:"title,omitempty"`
+	Instructions *string            `json:"instructions,omitempty"`
+	Servings     *int               `json:"servings,omitempty"`
+	Ingredients  *[]IngredientInput `json:"ingredients,omitempty"`
+}
+
+// Validate trims and checks the fields that are set
+func (r *UpdateRecipeRequest) Validate() error {
+	validator := validation.NewValidator()
+	if r.Title != nil {
+		title := strings.TrimSpace(*r.Title)
+		r.Title = &title
+		validator.Required("title", title)
+		validator.MaxLength("title", title, 255)
+	}
+	if r.Instructions != nil {
+		validator.MaxLength("instructions", *r.Instructions, 10000)
+	}
+	if r.Servings != nil {
+		validateServings(validator, *r.Servings)
+	}
+	if r.Ingredients != nil {
+		validateIngredients(validator, *r.Ingredients)
+	}
+	return validator.ToError()
+}
+
+func validateServings(validator *validation.Validator, servings int) {
+	if servings < 0 || servings > 100 {
+		validator.AddError("servings", "servings must be between 0 and 100")
+	}
+}
+
+func validateIngredients(validator *validation.Validator, ingredients []IngredientInput) {
+	if len(ingredients) > 100 {
+		validator.AddError("ingredients", "a recipe can have at most 100 ingredients")
+	}
+	for i := range ingredients {
+		ingredients[i].Name = strings.TrimSpace(ingredients[i].Name)
+		ingredients[i].Unit = strings.TrimSpace(ingredients[i].Unit)
+		validateShoppingQuantity(validator, "ingredients", ingredients[i].Name, ingredients[i].Quantity, ingredients[i].Unit)
+	}
+}
+
+// MealPlan is a meal planned for a day
+type MealPlan struct {
+	ID        string    `json:"id" db:"id"`
+	FamilyID  string    `json:"family_id" db:"family_id"`
+	Date      string    `json:"date" db:"meal_date"` // YYYY-MM-DD in the family's timezone
+	MealType  string    `json:"meal_type" db:"meal_type"`
+	RecipeID  *string   `json:"recipe_id" db:"recipe_id"`
+	Title     string    `json:"title" db:"title"`
+	Notes     string    `json:"notes" db:"notes"`
+	MealTime  *string   `json:"meal_time" db:"meal_time"` // HH:MM
+	EventID   *string   `json:"event_id" db:"event_id"`   // a dinner's calendar event
+	CreatedBy *string   `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// MealPlanResponse is the family's meals for a range of days
+type MealPlanResponse struct {
+	Start string     `json:"start"`
+	End   string     `json:"end"`
+	Meals []MealPlan `json:"meals"`
+}
+
+// PlanMealRequest sets the meal of a type on a day, replacing any planned
+// already. Title defaults to the recipe's.
+type PlanMealRequest struct {
+	Date     string  `json:"date"`
+	MealType string  `json:"meal_type"`
+	RecipeID *string `json:"recipe_id,omitempty"`
+	Title    string  `json:"title"`
+	Notes    string  `json:"notes"`
+	MealTime *string `json:"meal_time,omitempty"`
+}
+
+// Validate trims and checks the request
+func (r *PlanMealRequest) Validate() error {
+	r.Title = strings.TrimSpace(r.Title)
+	r.MealType = strings.ToLower(strings.TrimSpace(r.MealType))
+	if r.RecipeID != nil && *r.RecipeID == "" {
+		r.RecipeID = nil
+	}
+	if r.MealTime != nil && *r.MealTime == "" {
+		r.MealTime = nil
+	}
+
+	validator := validation.NewValidator()
+	if _, err := time.Parse("2006-01-02", r.Date); err != nil {
+		validator.AddError("date", "date must be YYYY-MM-DD")
+	}
+	validator.OneOf("meal_type", r.MealType, MealTypes)
+	if r.Title == "" && r.RecipeID == nil {
+		validator.AddError("title", "title is required without a recipe")
+	}
+	validator.MaxLength("title", r.Title, 255)
+	validator.MaxLength("notes", r.Notes, 1000)
+	if r.MealTime != nil {
+		if _, err := time.Parse("15:04", *r.MealTime); err != nil || len(*r.MealTime) != 5 {
+			validator.AddError("meal_time", "meal_time must be HH:MM")
+		}
+	}
+	return validator.ToError()
+}
+
+// ShoppingListItem is something to buy
+type ShoppingListItem struct {
+	ID        string    `json:"id" db:"id"`
+	FamilyID  string    `json:"family_id" db:"family_id"`
+	Name      string    `json:"name" db:"name"`
+	Quantity  float64   `json:"quantity" db:"quantity"` // 0 when not given
+	Unit      string    `json:"unit" db:"unit"`
+	Checked   bool      `json:"checked" db:"checked"`
+	Generated bool      `json:"generated" db:"generated"` // added from the meal plan
+	CreatedBy *string   `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateShoppingListItemRequest adds an item by hand
+type CreateShoppingListItemRequest struct {
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+}
+
+// Validate trims and checks the request
+func (r *CreateShoppingListItemRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Unit = strings.TrimSpace(r.Unit)
+	validator := validation.NewValidator()
+	validateShoppingQuantity(validator, "name", r.Name, r.Quantity, r.Unit)
+	return validator.ToError()
+}
+
+// UpdateShoppingListItemRequest changes the fields that are set
+type UpdateShoppingListItemRequest struct {
+	Name     *string  `json:"name,omitempty"`
+	Quantity *float64 `json:"quantity,omitempty"`
+	Unit     *string  `json:"unit,omitempty"`
+	Checked  *bool    `json:"checked,omitempty"`
+}
+
+// Validate trims and checks the fields that are set
+func (r *UpdateShoppingListItemRequest) Validate() error {
+	validator := validation.NewValidator()
+	if r.Name != nil {
+		name := strings.TrimSpace(*r.Name)
+		r.Name = &name
+		validator.Required("name", name)
+		validator.MaxLength("name", name, 255)
+	}
+	if r.Quantity != nil && (*r.Quantity < 0 || *r.Quantity > 10000) {
+		validator.AddError("quantity", "quantity must be between 0 and 10000")
+	}
+	if r.Unit != nil {
+		unit := strings.TrimSpace(*r.Unit)
+		r.Unit = &unit
+		validator.MaxLength("unit", unit, 50)
+	}
+	return validator.ToError()
+}
+
+// validateShoppingQuantity checks a name, quantity and unit as ingredients
+// and shopping list items have them
+func validateShoppingQuantity(validator *validation.Validator, field, name string, quantity float64, unit string) {
+	if name == "" || len(name) > 255 {
+		validator.AddError(field, "name is required and must be at most 255 characters")
+	}
+	if quantity < 0 || quantity > 10000 {
+		validator.AddError(field, "quantity must be between 0 and 10000")
+	}
+	if len(unit) > 50 {
+		validator.AddError(field, "unit must be at most 50 characters")
+	}
+}
+
+// GenerateShoppingListRequest names the days of the meal plan to shop for
+type GenerateShoppingListRequest struct {
+	Start string `json:"start"` // YYYY-MM-DD
+	End   string `json:"end"`   // YYYY-MM-DD, inclusive
+}
+
+// Validate checks the range is real and not too long
+func (r *GenerateShoppingListRequest) Validate() error {
+	validator := validation.NewValidator()
+	start, startErr := time.Parse("2006-01-02", r.Start)
+	if startErr != nil {
+		validator.AddError("start", "start must be YYYY-MM-DD")
+	}
+	end, endErr := time.Parse("2006-01-02", r.End)
+	if endErr != nil {
+		validator.AddError("end", "end must be YYYY-MM-DD")
+	}
+	if startErr == nil && endErr == nil {
+		if end.Before(start) {
+			validator.AddError("end", "end must not be before start")
+		} else if end.Sub(start) >= MaxShoppingListDays*24*time.Hour {
+			validator.AddErrorf("end", "a shopping list covers at most %d days", MaxShoppingListDays)
+		}
+	}
+	return validator.ToError()
+}
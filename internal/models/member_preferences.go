@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// Days a member's week can start on
+const (
+	WeekStartSunday = "sunday"
+	WeekStartMonday = "monday"
+)
+
+// Clocks a member can read times on
+const (
+	Clock12Hour = "12h"
+	Clock24Hour = "24h"
+)
+
+// RenderPreferences are how a member wants dates, times and themselves drawn.
+// Calendar and task responses carry them so every client renders alike.
+type RenderPreferences struct {
+	Color       string `json:"color"`
+	DefaultView string `json:"default_view"`
+	WeekStart   string `json:"week_start" db:"week_start"`
+	Clock       string `json:"clock" db:"clock"`
+}
+
+// MemberPreferences are all of a member's preferences in one place. Only the
+// week start and clock are stored with them: the color is the member's, the
+// default view comes from their display preferences and notifications from
+// their notification preferences.
+type MemberPreferences struct {
+	MemberID string `json:"member_id"`
+	RenderPreferences
+	Notifications *NotificationPreferences `json:"notifications"`
+	UpdatedAt     *time.Time               `json:"updated_at,omitempty"`
+}
+
+// UpdateMemberPreferencesRequest changes the fields that are set
+type UpdateMemberPreferencesRequest struct {
+	Color         *string                               `json:"color,omitempty"`
+	DefaultView   *string                               `json:"default_view,omitempty"`
+	WeekStart     *string                               `json:"week_start,omitempty"`
+	Clock         *string                               `json:"clock,omitempty"`
+	Notifications *UpdateNotificationPreferencesRequest `json:"notifications,omitempty"`
+}
+
+// Validate checks the request; which notification channels exist is up to
+// the server
+func (r *UpdateMemberPreferencesRequest) Validate(channels []string) error {
+	validator := validation.NewValidator()
+	if r.Color != nil && !IsHexColor(*r.Color) {
+		validator.AddError("color", "color must be a hex value like #3b82f6")
+	}
+	if r.DefaultView != nil {
+		validator.OneOf("default_view", *r.DefaultView, []string{DisplayViewDay, DisplayViewWeek, DisplayViewMonth, DisplayViewAgenda})
+	}
+	if r.WeekStart != nil {
+		validator.OneOf("week_start", *r.WeekStart, []string{WeekStartSunday, WeekStartMonday})
+	}
+	if r.Clock != nil {
+		validator.OneOf("clock", *r.Clock, []string{Clock12Hour, Clock24Hour})
+	}
+	if err := validator.ToError(); err != nil {
+		return err
+	}
+	if r.Notifications != nil {
+		return r.Notifications.Validate(channels)
+	}
+	return nil
+}
@@ -14,6 +14,11 @@ type Family struct {
 	Name      string    `json:"name" db:"name"`
 	Timezone  string    `json:"timezone" db:"timezone"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// Where the family lives, for weather forecasts; unset until chosen
+	LocationName *string  `json:"location_name,omitempty" db:"location_name"`
+	Latitude     *float64 `json:"latitude,omitempty" db:"latitude"`
+	Longitude    *float64 `json:"longitude,omitempty" db:"longitude"`
 }
 
 // User represents a family member
@@ -34,14 +39,17 @@ type Task struct {
 	AssignedTo  *string    `json:"assigned_to" db:"assigned_to"`
 	Title       string     `json:"title" db:"title"`
 	Description string     `json:"description" db:"description"`
-	TaskType    string     `json:"task_type" db:"task_type"` // 'todo', 'chore', 'appointment'
-	Status      string     `json:"status" db:"status"`       // 'pending', 'completed'
-	Priority    int        `json:"priority" db:"priority"`
+	TaskType    TaskType   `json:"task_type" db:"task_type"` // 'todo', 'chore', 'appointment'
+	Status      TaskStatus `json:"status" db:"status"`       // 'pending', 'completed'
+	Priority    Priority   `json:"priority" db:"priority"`
 	DueDate     *time.Time `json:"due_date" db:"due_date"`
+	HasDueTime  bool       `json:"has_due_time" db:"has_due_time"` // false when the task is due any time on its day
 	CreatedBy   string     `json:"created_by" db:"created_by"`
 	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
 	CompletedAt *time.Time `json:"completed_at" db:"completed_at"`
+	DueSoon     bool       `json:"due_soon" db:"-"`
+	Overdue     bool       `json:"overdue" db:"-"`
 }
 
 // Session represents a user session
@@ -53,19 +61,6 @@ type Session struct {
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
-// TaskType constants
-const (
-	TaskTypeTodo        = "todo"
-	TaskTypeChore       = "chore"
-	TaskTypeAppointment = "appointment"
-)
-
-// TaskStatus constants
-const (
-	TaskStatusPending   = "pending"
-	TaskStatusCompleted = "completed"
-)
-
 // UserRole constants
 const (
 	RoleParent = "parent"
@@ -73,26 +68,6 @@ const (
 	RoleAdmin  = "admin"
 )
 
-// IsValidTaskType checks if a task type is valid
-func IsValidTaskType(taskType string) bool {
-	switch taskType {
-	case TaskTypeTodo, TaskTypeChore, TaskTypeAppointment:
-		return true
-	default:
-		return false
-	}
-}
-
-// IsValidTaskStatus checks if a task status is valid
-func IsValidTaskStatus(status string) bool {
-	switch status {
-	case TaskStatusPending, TaskStatusCompleted:
-		return true
-	default:
-		return false
-	}
-}
-
 // IsValidUserRole checks if a user role is valid
 func IsValidUserRole(role string) bool {
 	switch role {
@@ -125,14 +100,14 @@ func (t *Task) Validate() error {
 	validator.MaxLength("description", t.Description, 1000)
 
 	// Validate status
-	validator.Required("status", t.Status)
-	if !IsValidTaskStatus(t.Status) {
+	validator.Required("status", string(t.Status))
+	if !t.Status.Valid() {
 		validator.AddError("status", "Status must be 'pending' or 'completed'")
 	}
 
 	// Validate task type
-	validator.Required("task_type", t.TaskType)
-	if !IsValidTaskType(t.TaskType) {
+	validator.Required("task_type", string(t.TaskType))
+	if !t.TaskType.Valid() {
 		validator.AddError("task_type", "Task type must be 'todo', 'chore', or 'appointment'")
 	}
 
@@ -153,8 +128,8 @@ func (t *Task) Sanitize() {
 	t.Description = html.EscapeString(strings.TrimSpace(t.Description))
 
 	// Normalize status and task type to lowercase
-	t.Status = strings.ToLower(strings.TrimSpace(t.Status))
-	t.TaskType = strings.ToLower(strings.TrimSpace(t.TaskType))
+	t.Status = TaskStatus(normalizeEnum(string(t.Status)))
+	t.TaskType = TaskType(normalizeEnum(string(t.TaskType)))
 
 	// Trim other string fields
 	t.FamilyID = strings.TrimSpace(t.FamilyID)
@@ -185,8 +160,8 @@ func (t *Task) SetDefaults() {
 		t.UpdatedAt = time.Now().UTC()
 	}
 
-	if t.Priority == 0 {
-		t.Priority = 1 // Default priority
+	if t.Priority == PriorityLow {
+		t.Priority = PriorityNormal // Default priority
 	}
 }
 
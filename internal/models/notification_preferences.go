@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// DefaultDigestTime is when the daily digest goes out unless a member picks
+// another time, in the family's timezone
+const DefaultDigestTime = "07:00"
+
+// NotificationPreferences are one member's choices about what they are sent
+type NotificationPreferences struct {
+	MemberID      string    `json:"member_id" db:"member_id"`
+	FamilyID      string    `json:"family_id" db:"family_id"`
+	DailyDigest   bool      `json:"daily_digest" db:"daily_digest"`
+	DigestTime    string    `json:"digest_time" db:"digest_time"` // HH:MM in the family's timezone
+	DigestChannel string    `json:"digest_channel" db:"digest_channel"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UpdateNotificationPreferencesRequest changes the fields that are set
+type UpdateNotificationPreferencesRequest struct {
+	DailyDigest   *bool   `json:"daily_digest,omitempty"`
+	DigestTime    *string `json:"digest_time,omitempty"`
+	DigestChannel *string `json:"digest_channel,omitempty"`
+}
+
+// Validate checks the request; which channels exist is up to the server
+func (r *UpdateNotificationPreferencesRequest) Validate(channels []string) error {
+	validator := validation.NewValidator()
+	if r.DigestTime != nil {
+		if _, err := time.Parse("15:04", *r.DigestTime); err != nil || len(*r.DigestTime) != 5 {
+			validator.AddError("digest_time", "digest_time must be HH:MM")
+		}
+	}
+	if r.DigestChannel != nil {
+		validator.OneOf("digest_channel", *r.DigestChannel, channels)
+	}
+	return validator.ToError()
+}
+
+// DueDigest is a member's daily digest that should go out now
+type DueDigest struct {
+	MemberID string
+	FamilyID string
+	Date     string // the family-local day it covers, YYYY-MM-DD
+	Channel  string
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// ProtectedTimeBlock is a recurring family-wide window where nothing should be scheduled
+type ProtectedTimeBlock struct {
+	ID         string    `json:"id" db:"id"`
+	FamilyID   string    `json:"family_id" db:"family_id"`
+	Title      string    `json:"title" db:"title"`
+	DaysOfWeek []string  `json:"days_of_week" db:"days_of_week"` // lowercase weekday names, empty means every day
+	StartTime  string    `json:"start_time" db:"start_time"`     // HH:MM in family timezone
+	EndTime    string    `json:"end_time" db:"end_time"`         // HH:MM in family timezone
+	Color      string    `json:"color" db:"color"`
+	Active     bool      `json:"active" db:"active"`
+	CreatedBy  *string   `json:"created_by" db:"created_by"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateProtectedTimeBlockRequest creates a protected block
+type CreateProtectedTimeBlockRequest struct {
+	Title      string   `json:"title" validate:"required,min=1,max=255"`
+	DaysOfWeek []string `json:"days_of_week"`
+	StartTime  string   `json:"start_time" validate:"required"`
+	EndTime    string   `json:"end_time" validate:"required"`
+	Color      string   `json:"color,omitempty"`
+}
+
+// UpdateProtectedTimeBlockRequest changes fields on a protected block
+type UpdateProtectedTimeBlockRequest struct {
+	Title      *string   `json:"title,omitempty"`
+	DaysOfWeek *[]string `json:"days_of_week,omitempty"`
+	StartTime  *string   `json:"start_time,omitempty"`
+	EndTime    *string   `json:"end_time,omitempty"`
+	Color      *string   `json:"color,omitempty"`
+	Active     *bool     `json:"active,omitempty"`
+}
+
+// ProtectedBlockBand is a protected block placed on a specific day of the days view
+type ProtectedBlockBand struct {
+	BlockID   string `json:"blockId"`
+	Title     string `json:"title"`
+	StartSlot int    `json:"startSlot"`
+	EndSlot   int    `json:"endSlot"`
+	Color     string `json:"color"`
+}
+
+// ProtectedBlockConflict describes a proposed time range overlapping a protected block
+type ProtectedBlockConflict struct {
+	BlockID string    `json:"block_id"`
+	Title   string    `json:"title"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+}
+
+// Message returns a human-readable warning for the conflict
+func (c ProtectedBlockConflict) Message() string {
+	return "overlaps protected time \"" + c.Title + "\" (" + c.Start.Format("Mon 15:04") + "–" + c.End.Format("15:04") + ")"
+}
+
+// OccursOn reports whether the block applies on the given weekday
+func (b *ProtectedTimeBlock) OccursOn(weekday time.Weekday) bool {
+	if len(b.DaysOfWeek) == 0 {
+		return true
+	}
+	day := strings.ToLower(weekday.String())
+	for _, d := range b.DaysOfWeek {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// WindowOn returns the block's start and end on the given date in loc
+func (b *ProtectedTimeBlock) WindowOn(date time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	start, err := time.Parse("15:04", b.StartTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := time.Parse("15:04", b.EndTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	year, month, day := date.Date()
+	return time.Date(year, month, day, start.Hour(), start.Minute(), 0, 0, loc),
+		time.Date(year, month, day, end.Hour(), end.Minute(), 0, 0, loc), nil
+}
+
+// ValidateProtectedTimeBlock checks the fields shared by create and update
+func ValidateProtectedTimeBlock(title string, daysOfWeek []string, startTime, endTime, color string) error {
+	validator := validation.NewValidator()
+
+	validator.Required("title", title)
+	validator.MaxLength("title", title, 255)
+
+	validDays := []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+	for _, day := range daysOfWeek {
+		validator.OneOf("days_of_week", day, validDays)
+	}
+
+	start, startErr := time.Parse("15:04", startTime)
+	if startErr != nil {
+		validator.AddError("start_time", "start_time must be in HH:MM format")
+	}
+	end, endErr := time.Parse("15:04", endTime)
+	if endErr != nil {
+		validator.AddError("end_time", "end_time must be in HH:MM format")
+	}
+	if startErr == nil && endErr == nil && !end.After(start) {
+		validator.AddError("end_time", "end_time must be after start_time")
+	}
+
+	if color != "" && !hexColorPattern.MatchString(color) {
+		validator.AddError("color", "color must be a hex value like #9ca3af")
+	}
+
+	return validator.ToError()
+}
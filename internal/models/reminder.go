@@ -0,0 +1,91 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// MaxReminderMinutes is the furthest ahead a reminder may fire: one week
+const MaxReminderMinutes = 7 * 24 * 60
+
+// Reminder delivery statuses
+const (
+	ReminderDeliverySent        = "sent"
+	ReminderDeliveryUnreachable = "unreachable" // the member has no address on the channel
+	ReminderDeliveryFailed      = "failed"
+)
+
+// EventReminder notifies an event's attendees a set time before it starts.
+// On a recurring series it fires before every occurrence.
+type EventReminder struct {
+	ID            string    `json:"id" db:"id"`
+	FamilyID      string    `json:"family_id" db:"family_id"`
+	EventID       string    `json:"event_id" db:"event_id"`
+	MinutesBefore int       `json:"minutes_before" db:"minutes_before"`
+	Channel       string    `json:"channel" db:"channel"`
+	CreatedBy     *string   `json:"created_by" db:"created_by"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// CreateEventReminderRequest adds a reminder to an event
+type CreateEventReminderRequest struct {
+	EventID       string `json:"event_id"`
+	MinutesBefore int    `json:"minutes_before"`
+	Channel       string `json:"channel"` // one of the enabled notification channels
+}
+
+// Validate checks the request; which channels exist is up to the server
+func (r *CreateEventReminderRequest) Validate(channels []string) error {
+	validator := validation.NewValidator()
+	validator.Required("event_id", r.EventID)
+	if r.MinutesBefore < 0 || r.MinutesBefore > MaxReminderMinutes {
+		validator.AddErrorf("minutes_before", "minutes_before must be between 0 and %d", MaxReminderMinutes)
+	}
+	validator.Required("channel", r.Channel)
+	if r.Channel != "" {
+		validator.OneOf("channel", r.Channel, channels)
+	}
+	return validator.ToError()
+}
+
+// DueReminder is one occurrence a reminder is about to fire for
+type DueReminder struct {
+	ReminderID      string
+	EventID         string
+	OccurrenceStart time.Time
+	FireAt          time.Time
+}
+
+// PushSubscription is a browser a member gets web push notifications on. The
+// keys stay server-side.
+type PushSubscription struct {
+	Endpoint  string    `json:"endpoint" db:"endpoint"`
+	MemberID  string    `json:"member_id" db:"member_id"`
+	UserAgent string    `json:"user_agent" db:"user_agent"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// SavePushSubscriptionRequest is a browser PushSubscription as its toJSON()
+// serializes it
+type SavePushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// Validate checks that the subscription can be pushed to
+func (r *SavePushSubscriptionRequest) Validate() error {
+	validator := validation.NewValidator()
+	validator.Required("endpoint", r.Endpoint)
+	validator.MaxLength("endpoint", r.Endpoint, 2048)
+	if r.Endpoint != "" && !strings.HasPrefix(r.Endpoint, "https://") {
+		validator.AddError("endpoint", "endpoint must be an https URL")
+	}
+	validator.Required("keys.p256dh", r.Keys.P256dh)
+	validator.Required("keys.auth", r.Keys.Auth)
+	return validator.ToError()
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Request/Response models for APIs
 
@@ -8,26 +11,35 @@ import "time"
 type CreateTaskRequest struct {
 	Title       string     `json:"title" validate:"required,min=1,max=255"`
 	Description string     `json:"description" validate:"max=1000"`
-	TaskType    string     `json:"task_type" validate:"required,oneof=todo chore appointment"`
+	TaskType    TaskType   `json:"task_type" validate:"required,oneof=todo chore appointment"`
 	AssignedTo  *string    `json:"assigned_to"`
-	Priority    int        `json:"priority" validate:"min=0,max=10"`
+	Priority    Priority   `json:"priority" validate:"min=0,max=3"`
 	DueDate     *time.Time `json:"due_date"`
+	DueTime     *string    `json:"due_time,omitempty"` // HH:MM on due_date's day in family time
 	Points      int        `json:"points" validate:"min=0"`
 }
 
 type UpdateTaskRequest struct {
-	Title       *string    `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
-	Description *string    `json:"description,omitempty" validate:"omitempty,max=1000"`
-	Status      *string    `json:"status,omitempty" validate:"omitempty,oneof=pending completed"`
-	AssignedTo  *string    `json:"assigned_to,omitempty"`
-	Priority    *int       `json:"priority,omitempty" validate:"omitempty,min=0,max=10"`
-	DueDate     *time.Time `json:"due_date,omitempty"`
+	Title       *string     `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
+	Description *string     `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Status      *TaskStatus `json:"status,omitempty" validate:"omitempty,oneof=pending completed"`
+	AssignedTo  *string     `json:"assigned_to,omitempty"`
+	Priority    *Priority   `json:"priority,omitempty" validate:"omitempty,min=0,max=3"`
+	DueDate     *time.Time  `json:"due_date,omitempty"`
+	DueTime     *string     `json:"due_time,omitempty"` // HH:MM, or empty for any time on the day
 }
 
 // Family request models
 type UpdateFamilyRequest struct {
 	Name     *string `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
 	Timezone *string `json:"timezone,omitempty" validate:"omitempty"`
+
+	// Latitude and Longitude are set together; ClearLocation unsets both
+	// and the name
+	LocationName  *string  `json:"location_name,omitempty"`
+	Latitude      *float64 `json:"latitude,omitempty"`
+	Longitude     *float64 `json:"longitude,omitempty"`
+	ClearLocation bool     `json:"clear_location,omitempty"`
 }
 
 // Calendar event request models
@@ -37,7 +49,7 @@ type CreateCalendarEventRequest struct {
 	StartTime   time.Time `json:"start_time" validate:"required"`
 	EndTime     time.Time `json:"end_time" validate:"required"`
 	Location    *string   `json:"location,omitempty" validate:"omitempty,max=255"`
-	EventType   string    `json:"event_type" validate:"required,oneof=appointment event reminder"`
+	EventType   EventType `json:"event_type" validate:"required,oneof=appointment event reminder"`
 	AssignedTo  *string   `json:"assigned_to,omitempty"`
 }
 
@@ -47,7 +59,7 @@ type UpdateCalendarEventRequest struct {
 	StartTime   *time.Time `json:"start_time,omitempty"`
 	EndTime     *time.Time `json:"end_time,omitempty"`
 	Location    *string    `json:"location,omitempty" validate:"omitempty,max=255"`
-	EventType   *string    `json:"event_type,omitempty" validate:"omitempty,oneof=appointment event reminder"`
+	EventType   *EventType `json:"event_type,omitempty" validate:"omitempty,oneof=appointment event reminder"`
 	AssignedTo  *string    `json:"assigned_to,omitempty"`
 }
 
@@ -63,27 +75,134 @@ type CreateUnifiedCalendarEventRequest struct {
 	Location        *string   `json:"location,omitempty" validate:"omitempty,max=255"`
 	Organizer       *string   `json:"organizer,omitempty" validate:"omitempty,max=255"`
 	Attendees       *string   `json:"attendees,omitempty" validate:"omitempty,max=1000"`
+
+	// ConflictCheck looks for other events the attendees are already at:
+	// "warn" or "reject"; empty skips it
+	ConflictCheck string `json:"conflict_check,omitempty" validate:"omitempty,oneof=warn reject"`
+}
+
+// AttendeeIDs returns the member IDs in Attendees. They arrive as a JSON
+// array; anything else has no members in it.
+func (r *CreateUnifiedCalendarEventRequest) AttendeeIDs() []string {
+	var ids []string
+	if r.Attendees == nil || json.Unmarshal([]byte(*r.Attendees), &ids) != nil {
+		return nil
+	}
+	return ids
+}
+
+// PutUnifiedCalendarEventRequest creates or replaces a unified event under an
+// ID the caller chooses, the way CalDAV clients name the resources they write.
+// Times are absolute instants; all-day events run from midnight to midnight in
+// the family's timezone.
+type PutUnifiedCalendarEventRequest struct {
+	ID          string
+	ICalUID     string
+	Title       string
+	Description *string
+	Location    *string
+	StartTime   time.Time
+	EndTime     time.Time
+	AllDay      bool
+	Status      string
+	Attendees   []string // family member IDs, applied only when the event is created
+
+	// RecurrenceRule makes the event a recurring series; nil for a single event
+	RecurrenceRule *string
+	// ExceptionDates are occurrences of the series that don't happen
+	ExceptionDates []time.Time
+}
+
+// UpdateUnifiedCalendarEventRequest changes the fields that are set. When the
+// event is an occurrence of a recurring series, Scope picks whether the change
+// applies to this occurrence, this and later ones, or the whole series.
+type UpdateUnifiedCalendarEventRequest struct {
+	Title          *string    `json:"title,omitempty"`
+	Description    *string    `json:"description,omitempty"`
+	Location       *string    `json:"location,omitempty"`
+	StartTime      *time.Time `json:"start_time,omitempty"`
+	EndTime        *time.Time `json:"end_time,omitempty"`
+	AllDay         *bool      `json:"all_day,omitempty"`
+	Color          *string    `json:"color,omitempty"`
+	Attendees      *[]string  `json:"attendees,omitempty"`       // family member IDs; replaces the attendee list
+	RecurrenceRule *string    `json:"recurrence_rule,omitempty"` // "" stops the event recurring
+	Visibility     *string    `json:"visibility,omitempty"`      // public, busy or private
+	Scope          string     `json:"scope,omitempty"`           // this, future or all; defaults to this
+}
+
+// BulkCalendarEventItem is one event within a bulk import request. Times
+// without an offset are interpreted in the family's timezone.
+type BulkCalendarEventItem struct {
+	Title       string    `json:"title" validate:"required,min=1,max=255"`
+	Description *string   `json:"description,omitempty" validate:"omitempty,max=1000"`
+	StartTime   time.Time `json:"start_time" validate:"required"`
+	EndTime     time.Time `json:"end_time" validate:"required"`
+	Location    *string   `json:"location,omitempty" validate:"omitempty,max=255"`
+	AllDay      bool      `json:"all_day"`
+	EventType   EventType `json:"event_type,omitempty" validate:"omitempty,oneof=appointment event reminder"`
+	Color       string    `json:"color,omitempty"`
+	Priority    Priority  `json:"priority" validate:"min=0,max=3"`
+	Attendees   []string  `json:"attendees,omitempty"`  // family member IDs
+	Visibility  string    `json:"visibility,omitempty"` // public (the default), busy or private
+
+	RecurrenceRule *string `json:"recurrence_rule,omitempty"` // e.g. FREQ=WEEKLY;BYDAY=WE
+}
+
+// BulkCreateCalendarEventsRequest imports many events in one request
+type BulkCreateCalendarEventsRequest struct {
+	FamilyID string                  `json:"family_id,omitempty"`
+	Events   []BulkCalendarEventItem `json:"events" validate:"required,min=1"`
 }
 
 // Task schedule request models
 type CreateTaskScheduleRequest struct {
 	Title       string   `json:"title" validate:"required,min=1,max=255"`
 	Description *string  `json:"description,omitempty" validate:"omitempty,max=1000"`
-	TaskType    string   `json:"task_type" validate:"required,oneof=todo chore appointment"`
+	TaskType    TaskType `json:"task_type" validate:"required,oneof=todo chore appointment"`
 	AssignedTo  *string  `json:"assigned_to,omitempty"`
 	DaysOfWeek  []string `json:"days_of_week" validate:"required,min=1"`
 	TimeOfDay   *string  `json:"time_of_day,omitempty"`
-	Priority    int      `json:"priority" validate:"min=0,max=3"`
+	Priority    Priority `json:"priority" validate:"min=0,max=3"`
 	FamilyID    *string  `json:"family_id,omitempty"`
+
+	// Rotation hands the schedule's tasks to members in turn instead of assigned_to
+	RotationStrategy string   `json:"rotation_strategy,omitempty"` // none, daily, weekly, per_task
+	RotationMembers  []string `json:"rotation_members,omitempty"`
+	RotationAnchor   *string  `json:"rotation_anchor,omitempty"` // YYYY-MM-DD, defaults to today
+
+	StartDate   *string `json:"start_date,omitempty"` // YYYY-MM-DD
+	EndDate     *string `json:"end_date,omitempty"`
+	PausedUntil *string `json:"paused_until,omitempty"`
+
+	// CronExpr replaces days_of_week and time_of_day, e.g. "0 9 1-7 * SAT"
+	CronExpr *string `json:"cron_expr,omitempty"`
 }
 
 type UpdateTaskScheduleRequest struct {
 	Title       *string   `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
 	Description *string   `json:"description,omitempty" validate:"omitempty,max=1000"`
-	TaskType    *string   `json:"task_type,omitempty" validate:"omitempty,oneof=todo chore appointment"`
+	TaskType    *TaskType `json:"task_type,omitempty" validate:"omitempty,oneof=todo chore appointment"`
 	AssignedTo  *string   `json:"assigned_to,omitempty"`
 	DaysOfWeek  *[]string `json:"days_of_week,omitempty"`
 	TimeOfDay   *string   `json:"time_of_day,omitempty"`
-	Priority    *int      `json:"priority,omitempty" validate:"omitempty,min=0,max=3"`
+	Priority    *Priority `json:"priority,omitempty" validate:"omitempty,min=0,max=3"`
 	Active      *bool     `json:"active,omitempty"`
+
+	RotationStrategy *string   `json:"rotation_strategy,omitempty"`
+	RotationMembers  *[]string `json:"rotation_members,omitempty"` // changing the members restarts the rotation
+	RotationAnchor   *string   `json:"rotation_anchor,omitempty"`
+
+	// An empty start_date, end_date or paused_until clears it
+	StartDate   *string `json:"start_date,omitempty"`
+	EndDate     *string `json:"end_date,omitempty"`
+	PausedUntil *string `json:"paused_until,omitempty"`
+
+	CronExpr *string `json:"cron_expr,omitempty"` // empty goes back to days_of_week
+}
+
+// ScheduleOccurrencesResponse previews when a cron expression will run
+type ScheduleOccurrencesResponse struct {
+	CronExpr    string      `json:"cron_expr"`
+	TimeOfDay   string      `json:"time_of_day"`
+	Occurrences []time.Time `json:"occurrences"`
 }
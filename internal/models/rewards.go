@@ -0,0 +1,157 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// Reasons a member's points change
+const (
+	PointsReasonTaskCompleted = "task_completed"
+	PointsReasonTaskReopened  = "task_reopened" // takes back a completed task's points
+	PointsReasonRedemption    = "redemption"
+	PointsReasonAdjustment    = "adjustment" // added or taken by a parent, e.g. allowance
+)
+
+// Redemption statuses
+const (
+	RedemptionPending  = "pending"
+	RedemptionApproved = "approved"
+	RedemptionRejected = "rejected"
+)
+
+// MaxRewardCost bounds reward costs and manual adjustments
+const MaxRewardCost = 100000
+
+// PointsEntry is one change to a member's points
+type PointsEntry struct {
+	ID           string    `json:"id" db:"id"`
+	FamilyID     string    `json:"family_id" db:"family_id"`
+	MemberID     string    `json:"member_id" db:"member_id"`
+	Points       int       `json:"points" db:"points"`
+	Reason       string    `json:"reason" db:"reason"`
+	TaskID       *string   `json:"task_id,omitempty" db:"task_id"`
+	RedemptionID *string   `json:"redemption_id,omitempty" db:"redemption_id"`
+	Note         string    `json:"note" db:"note"`
+	CreatedBy    *string   `json:"created_by" db:"created_by"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// PointsBalance is what a member has earned, and how much of it isn't held
+// by redemptions waiting for approval
+type PointsBalance struct {
+	MemberID  string `json:"member_id" db:"member_id"`
+	Name      string `json:"name" db:"name"`
+	Balance   int    `json:"balance" db:"balance"`
+	Pending   int    `json:"pending" db:"pending"`
+	Available int    `json:"available" db:"-"` // balance less pending redemptions
+}
+
+// Reward is something a parent lets members spend points on
+type Reward struct {
+	ID          string    `json:"id" db:"id"`
+	FamilyID    string    `json:"family_id" db:"family_id"`
+	Title       string    `json:"title" db:"title"`
+	Description string    `json:"description" db:"description"`
+	Cost        int       `json:"cost" db:"cost"`
+	Active      bool      `json:"active" db:"active"`
+	CreatedBy   *string   `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Redemption is a member's request to spend points on a reward
+type Redemption struct {
+	ID          string     `json:"id" db:"id"`
+	FamilyID    string     `json:"family_id" db:"family_id"`
+	RewardID    string     `json:"reward_id" db:"reward_id"`
+	RewardTitle string     `json:"reward_title" db:"reward_title"`
+	MemberID    string     `json:"member_id" db:"member_id"`
+	Cost        int        `json:"cost" db:"cost"`
+	Status      string     `json:"status" db:"status"`
+	RequestedAt time.Time  `json:"requested_at" db:"requested_at"`
+	DecidedBy   *string    `json:"decided_by" db:"decided_by"`
+	DecidedAt   *time.Time `json:"decided_at" db:"decided_at"`
+	Note        string     `json:"note" db:"note"`
+}
+
+// CreateRewardRequest defines a reward
+type CreateRewardRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Cost        int    `json:"cost"`
+}
+
+// Validate checks the request
+func (r *CreateRewardRequest) Validate() error {
+	r.Title = strings.TrimSpace(r.Title)
+	validator := validation.NewValidator()
+	validator.Required("title", r.Title)
+	validator.MaxLength("title", r.Title, 255)
+	validator.MaxLength("description", r.Description, 1000)
+	validateRewardCost(validator, r.Cost)
+	return validator.ToError()
+}
+
+// UpdateRewardRequest changes the fields that are set
+type UpdateRewardRequest struct {
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Cost        *int    `json:"cost,omitempty"`
+	Active      *bool   `json:"active,omitempty"`
+}
+
+// Validate checks the fields that are set
+func (r *UpdateRewardRequest) Validate() error {
+	validator := validation.NewValidator()
+	if r.Title != nil {
+		title := strings.TrimSpace(*r.Title)
+		r.Title = &title
+		validator.Required("title", title)
+		validator.MaxLength("title", title, 255)
+	}
+	if r.Description != nil {
+		validator.MaxLength("description", *r.Description, 1000)
+	}
+	if r.Cost != nil {
+		validateRewardCost(validator, *r.Cost)
+	}
+	return validator.ToError()
+}
+
+func validateRewardCost(validator *validation.Validator, cost int) {
+	if cost < 1 || cost > MaxRewardCost {
+		validator.AddErrorf("cost", "cost must be between 1 and %d", MaxRewardCost)
+	}
+}
+
+// RedeemRewardRequest asks to spend a member's points on a reward
+type RedeemRewardRequest struct {
+	MemberID string `json:"member_id,omitempty"` // defaults to the signed-in member
+}
+
+// DecideRedemptionRequest approves or rejects a redemption
+type DecideRedemptionRequest struct {
+	Note string `json:"note,omitempty"`
+}
+
+// PointsAdjustmentRequest adds points to a member, or takes them with a
+// negative amount
+type PointsAdjustmentRequest struct {
+	MemberID string `json:"member_id"`
+	Points   int    `json:"points"`
+	Note     string `json:"note"`
+}
+
+// Validate checks the request
+func (r *PointsAdjustmentRequest) Validate() error {
+	validator := validation.NewValidator()
+	validator.Required("member_id", r.MemberID)
+	if r.Points == 0 || r.Points > MaxRewardCost || r.Points < -MaxRewardCost {
+		validator.AddErrorf("points", "points must be non-zero and at most %d either way", MaxRewardCost)
+	}
+	validator.MaxLength("note", r.Note, 500)
+	return validator.ToError()
+}
@@ -1,6 +1,19 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// Rotation strategies for schedules that take turns between members
+const (
+	RotationNone    = "none"
+	RotationDaily   = "daily"    // the next member takes over each day
+	RotationWeekly  = "weekly"   // the next member takes over each Monday
+	RotationPerTask = "per_task" // each generated task goes to the next member
+)
 
 // TaskSchedule represents a recurring task schedule
 type TaskSchedule struct {
@@ -9,13 +22,117 @@ type TaskSchedule struct {
 	CreatedBy         string     `json:"created_by" db:"created_by"`
 	Title             string     `json:"title" db:"title"`
 	Description       *string    `json:"description" db:"description"`
-	TaskType          string     `json:"task_type" db:"task_type"` // 'todo', 'chore', 'appointment'
+	TaskType          TaskType   `json:"task_type" db:"task_type"` // 'todo', 'chore', 'appointment'
 	AssignedTo        *string    `json:"assigned_to" db:"assigned_to"`
 	DaysOfWeek        *string    `json:"days_of_week" db:"days_of_week"` // JSON array: ["tuesday", "thursday"]
 	TimeOfDay         *string    `json:"time_of_day" db:"time_of_day"`   // HH:MM format, optional specific time
-	Priority          int        `json:"priority" db:"priority"`
+	Priority          Priority   `json:"priority" db:"priority"`
 	Points            int        `json:"points" db:"points"`
 	Active            bool       `json:"active" db:"active"`
 	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
 	LastGeneratedDate *time.Time `json:"last_generated_date" db:"last_generated_date"`
+	RotationStrategy  string     `json:"rotation_strategy" db:"rotation_strategy"` // overrides assigned_to unless 'none'
+	RotationMembers   *string    `json:"rotation_members" db:"rotation_members"`   // JSON array of member IDs, in turn order
+	RotationAnchor    *string    `json:"rotation_anchor" db:"rotation_anchor"`     // YYYY-MM-DD the first member's turn starts
+	RotationIndex     int        `json:"rotation_index" db:"rotation_index"`       // next member for per_task rotations
+	StartDate         *string    `json:"start_date" db:"start_date"`               // YYYY-MM-DD, first day tasks are generated
+	EndDate           *string    `json:"end_date" db:"end_date"`                   // YYYY-MM-DD, last day tasks are generated
+	PausedUntil       *string    `json:"paused_until" db:"paused_until"`           // YYYY-MM-DD generation resumes on
+	CronExpr          *string    `json:"cron_expr" db:"cron_expr"`                 // replaces days_of_week when set
+}
+
+// InWindow reports whether the schedule generates tasks on date: within its
+// start and end dates and not while it is paused
+func (s *TaskSchedule) InWindow(date time.Time) bool {
+	day := date.Format("2006-01-02")
+	if s.StartDate != nil && day < *s.StartDate {
+		return false
+	}
+	if s.EndDate != nil && day > *s.EndDate {
+		return false
+	}
+	if s.PausedUntil != nil && day < *s.PausedUntil {
+		return false
+	}
+	return true
+}
+
+// ValidateScheduleWindow checks a schedule's start, end and pause dates
+func ValidateScheduleWindow(startDate, endDate, pausedUntil *string) error {
+	validator := validation.NewValidator()
+
+	dates := []struct {
+		field string
+		value *string
+	}{{"start_date", startDate}, {"end_date", endDate}, {"paused_until", pausedUntil}}
+	valid := true
+	for _, date := range dates {
+		if date.value == nil {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", *date.value); err != nil {
+			validator.AddErrorf(date.field, "%s must be in YYYY-MM-DD format", date.field)
+			valid = false
+		}
+	}
+	if valid && startDate != nil && endDate != nil && *endDate < *startDate {
+		validator.AddError("end_date", "end_date must not be before start_date")
+	}
+
+	return validator.ToError()
+}
+
+// ValidateScheduleRotation checks a schedule's rotation settings
+func ValidateScheduleRotation(strategy string, members []string, anchor *string) error {
+	validator := validation.NewValidator()
+	validator.OneOf("rotation_strategy", strategy, []string{RotationNone, RotationDaily, RotationWeekly, RotationPerTask})
+	if strategy != RotationNone && len(members) < 2 {
+		validator.AddError("rotation_members", "a rotation needs at least two members")
+	}
+	for _, member := range members {
+		if member == "" {
+			validator.AddError("rotation_members", "rotation members must not be empty")
+			break
+		}
+	}
+	if anchor != nil {
+		if _, err := time.Parse("2006-01-02", *anchor); err != nil {
+			validator.AddError("rotation_anchor", "rotation_anchor must be in YYYY-MM-DD format")
+		}
+	}
+	return validator.ToError()
+}
+
+// RotationTurn returns the position in members whose turn it is on date.
+// per_task rotations don't depend on the date, so they return index.
+func RotationTurn(strategy string, memberCount int, anchor string, index int, date time.Time) (int, error) {
+	if memberCount == 0 {
+		return 0, fmt.Errorf("rotation has no members")
+	}
+
+	var turn int
+	switch strategy {
+	case RotationPerTask:
+		turn = index
+	case RotationDaily, RotationWeekly:
+		anchorDate, err := time.Parse("2006-01-02", anchor)
+		if err != nil {
+			return 0, fmt.Errorf("invalid rotation anchor: %w", err)
+		}
+		day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		if strategy == RotationDaily {
+			turn = int(day.Sub(anchorDate).Hours()) / 24
+		} else {
+			turn = int(custodyWeekStart(day).Sub(custodyWeekStart(anchorDate)).Hours()) / (24 * 7)
+		}
+	default:
+		return 0, fmt.Errorf("schedule does not rotate")
+	}
+
+	// Dates before the anchor count backwards through the list
+	turn %= memberCount
+	if turn < 0 {
+		turn += memberCount
+	}
+	return turn, nil
 }
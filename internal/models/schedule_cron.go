@@ -0,0 +1,93 @@
+package models
+
+import (
+	"fmt"
+	"math/bits"
+	"time"
+
+	cron "github.com/robfig/cron/v3"
+
+	"famstack/internal/validation"
+)
+
+// scheduleCronParser accepts the same five-field expressions as the job
+// system's scheduler, plus descriptors like @weekly
+var scheduleCronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// cronStarBit marks a field written as "*" in robfig/cron's bit sets
+const cronStarBit = 1 << 63
+
+// cronSearchDays bounds how far ahead occurrences are searched, long enough
+// to reach the next February 29th
+const cronSearchDays = 5 * 366
+
+// ScheduleCron is a schedule's cron expression. A schedule makes at most one
+// task a day, so the expression must name a single time of day.
+//
+// Unlike classic cron, a day has to match both the day of month and the day
+// of week when both are given, so "0 9 1-7 * SAT" is the first Saturday of
+// the month rather than the first seven days and every Saturday.
+type ScheduleCron struct {
+	spec *cron.SpecSchedule
+}
+
+// ParseScheduleCron parses and checks a schedule's cron expression
+func ParseScheduleCron(expr string) (*ScheduleCron, error) {
+	parsed, err := scheduleCronParser.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("cron_expr is not a valid cron expression: %v", err)
+	}
+	spec, ok := parsed.(*cron.SpecSchedule)
+	if !ok {
+		return nil, fmt.Errorf("cron_expr must name days and a time, not an interval")
+	}
+	if bits.OnesCount64(spec.Minute&^cronStarBit) != 1 || bits.OnesCount64(spec.Hour&^cronStarBit) != 1 {
+		return nil, fmt.Errorf("cron_expr must run at a single time of day")
+	}
+
+	schedule := &ScheduleCron{spec: spec}
+	if len(schedule.Next(time.Now().UTC(), 1)) == 0 {
+		return nil, fmt.Errorf("cron_expr never runs")
+	}
+	return schedule, nil
+}
+
+// RunsOn reports whether the expression matches date's day
+func (c *ScheduleCron) RunsOn(date time.Time) bool {
+	return c.spec.Dom&(1<<uint(date.Day())) != 0 &&
+		c.spec.Month&(1<<uint(date.Month())) != 0 &&
+		c.spec.Dow&(1<<uint(date.Weekday())) != 0
+}
+
+// TimeOfDay returns the expression's time in HH:MM format
+func (c *ScheduleCron) TimeOfDay() string {
+	hour := bits.TrailingZeros64(c.spec.Hour &^ cronStarBit)
+	minute := bits.TrailingZeros64(c.spec.Minute &^ cronStarBit)
+	return fmt.Sprintf("%02d:%02d", hour, minute)
+}
+
+// Next returns up to count occurrences after after, in after's location
+func (c *ScheduleCron) Next(after time.Time, count int) []time.Time {
+	hour := bits.TrailingZeros64(c.spec.Hour &^ cronStarBit)
+	minute := bits.TrailingZeros64(c.spec.Minute &^ cronStarBit)
+
+	occurrences := make([]time.Time, 0, count)
+	for day := 0; day <= cronSearchDays && len(occurrences) < count; day++ {
+		date := time.Date(after.Year(), after.Month(), after.Day()+day, hour, minute, 0, 0, after.Location())
+		if date.After(after) && c.RunsOn(date) {
+			occurrences = append(occurrences, date)
+		}
+	}
+	return occurrences
+}
+
+// ValidateScheduleCron parses expr, reporting problems as validation errors
+func ValidateScheduleCron(expr string) (*ScheduleCron, error) {
+	schedule, err := ParseScheduleCron(expr)
+	if err != nil {
+		validator := validation.NewValidator()
+		validator.AddError("cron_expr", err.Error())
+		return nil, validator.ToError()
+	}
+	return schedule, nil
+}
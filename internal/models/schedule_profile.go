@@ -0,0 +1,113 @@
+package models
+
+import (
+	"time"
+
+	"famstack/internal/validation"
+)
+
+// ScheduleProfile is a named date range (e.g. "School year", "Summer") that
+// decides which task schedules generate tasks on a given date
+type ScheduleProfile struct {
+	ID          string    `json:"id" db:"id"`
+	FamilyID    string    `json:"family_id" db:"family_id"`
+	Name        string    `json:"name" db:"name"`
+	StartDate   string    `json:"start_date" db:"start_date"` // YYYY-MM-DD, inclusive
+	EndDate     string    `json:"end_date" db:"end_date"`     // YYYY-MM-DD, inclusive
+	ScheduleIDs []string  `json:"schedule_ids"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateScheduleProfileRequest creates a schedule profile
+type CreateScheduleProfileRequest struct {
+	Name      string `json:"name" validate:"required,min=1,max=255"`
+	StartDate string `json:"start_date" validate:"required"`
+	EndDate   string `json:"end_date" validate:"required"`
+}
+
+// UpdateScheduleProfileRequest changes fields on a schedule profile
+type UpdateScheduleProfileRequest struct {
+	Name      *string `json:"name,omitempty"`
+	StartDate *string `json:"start_date,omitempty"`
+	EndDate   *string `json:"end_date,omitempty"`
+}
+
+// SetScheduleProfilesRequest replaces the profiles a schedule belongs to
+type SetScheduleProfilesRequest struct {
+	ProfileIDs []string `json:"profile_ids"`
+}
+
+// Contains reports whether the profile's date range includes the given date
+func (p *ScheduleProfile) Contains(date time.Time) bool {
+	day := date.Format("2006-01-02")
+	return day >= p.StartDate && day <= p.EndDate
+}
+
+// HasSchedule reports whether the schedule is a member of the profile
+func (p *ScheduleProfile) HasSchedule(scheduleID string) bool {
+	for _, id := range p.ScheduleIDs {
+		if id == scheduleID {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveScheduleProfile returns the profile in effect on date, or nil when no
+// profile covers it. Overlapping ranges resolve to the one that started last,
+// so a short override (e.g. "Winter break") wins over the season around it.
+func ActiveScheduleProfile(profiles []ScheduleProfile, date time.Time) *ScheduleProfile {
+	var active *ScheduleProfile
+	for i := range profiles {
+		profile := &profiles[i]
+		if !profile.Contains(date) {
+			continue
+		}
+		if active == nil || profile.StartDate > active.StartDate {
+			active = profile
+		}
+	}
+	return active
+}
+
+// ScheduleRunsOn reports whether a schedule should generate tasks on date.
+// Schedules outside every profile always run; otherwise the schedule must
+// belong to the profile active on that date.
+func ScheduleRunsOn(profiles []ScheduleProfile, scheduleID string, date time.Time) bool {
+	member := false
+	for i := range profiles {
+		if profiles[i].HasSchedule(scheduleID) {
+			member = true
+			break
+		}
+	}
+	if !member {
+		return true
+	}
+
+	active := ActiveScheduleProfile(profiles, date)
+	return active != nil && active.HasSchedule(scheduleID)
+}
+
+// ValidateScheduleProfile checks the fields shared by create and update
+func ValidateScheduleProfile(name, startDate, endDate string) error {
+	validator := validation.NewValidator()
+
+	validator.Required("name", name)
+	validator.MaxLength("name", name, 255)
+
+	start, startErr := time.Parse("2006-01-02", startDate)
+	if startErr != nil {
+		validator.AddError("start_date", "start_date must be in YYYY-MM-DD format")
+	}
+	end, endErr := time.Parse("2006-01-02", endDate)
+	if endErr != nil {
+		validator.AddError("end_date", "end_date must be in YYYY-MM-DD format")
+	}
+	if startErr == nil && endErr == nil && end.Before(start) {
+		validator.AddError("end_date", "end_date must not be before start_date")
+	}
+
+	return validator.ToError()
+}
@@ -0,0 +1,19 @@
+package models
+
+// Kinds of search result
+const (
+	SearchKindTask     = "task"
+	SearchKindEvent    = "event"
+	SearchKindSchedule = "schedule"
+)
+
+// MaxSearchResults bounds how many results one search returns
+const MaxSearchResults = 100
+
+// SearchResult is a task, event or schedule matching a search
+type SearchResult struct {
+	Kind    string `json:"kind" db:"kind"`
+	ID      string `json:"id" db:"ref_id"`
+	Title   string `json:"title" db:"title"`
+	Snippet string `json:"snippet" db:"snippet"` // matching text around the search terms
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Kinds of stored files counted against a family's quota
+const (
+	StorageKindAttachment = "attachment"
+	StorageKindAvatar     = "avatar"
+	StorageKindPhoto      = "photo"
+)
+
+// Storage limits used until a family sets its own quota
+const (
+	DefaultStorageHardLimitBytes int64 = 5 << 30  // 5 GiB per family
+	MaxStorageObjectBytes        int64 = 25 << 20 // 25 MiB per file
+)
+
+// StorageWarningThresholds are the usage percentages that raise a warning
+var StorageWarningThresholds = []int{80, 95}
+
+// Quota error codes returned to upload clients
+const (
+	QuotaCodeFileTooLarge        = "file_too_large"
+	QuotaCodeFamilyLimitExceeded = "family_quota_exceeded"
+	QuotaCodeMemberLimitExceeded = "member_quota_exceeded"
+)
+
+// StorageObject is one stored file counted against the quota
+type StorageObject struct {
+	ID          string    `json:"id" db:"id"`
+	FamilyID    string    `json:"family_id" db:"family_id"`
+	MemberID    *string   `json:"member_id" db:"member_id"`
+	Kind        string    `json:"kind" db:"kind"`
+	StorageKey  string    `json:"storage_key" db:"storage_key"`
+	FileName    string    `json:"file_name" db:"file_name"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	SizeBytes   int64     `json:"size_bytes" db:"size_bytes"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// UploadRequest describes a file streamed to the attachments API
+type UploadRequest struct {
+	Kind        string
+	FileName    string
+	ContentType string
+	SizeBytes   int64
+}
+
+// StorageQuota holds a family's storage limits
+type StorageQuota struct {
+	FamilyID         string `json:"family_id" db:"family_id"`
+	HardLimitBytes   int64  `json:"hard_limit_bytes" db:"hard_limit_bytes"`
+	MemberLimitBytes *int64 `json:"member_limit_bytes" db:"member_limit_bytes"`
+}
+
+// UpdateStorageQuotaRequest changes a family's storage limits
+type UpdateStorageQuotaRequest struct {
+	HardLimitBytes   *int64 `json:"hard_limit_bytes,omitempty"`
+	MemberLimitBytes *int64 `json:"member_limit_bytes,omitempty"` // 0 removes the member limit
+}
+
+// MemberStorageUsage is one member's share of the family's storage
+type MemberStorageUsage struct {
+	MemberID   string           `json:"member_id"`
+	Name       string           `json:"name"`
+	TotalBytes int64            `json:"total_bytes"`
+	ByKind     map[string]int64 `json:"by_kind"`
+}
+
+// StorageUsage reports a family's storage against its quota
+type StorageUsage struct {
+	FamilyID    string               `json:"family_id"`
+	TotalBytes  int64                `json:"total_bytes"`
+	ByKind      map[string]int64     `json:"by_kind"`
+	Members     []MemberStorageUsage `json:"members"`
+	Quota       StorageQuota         `json:"quota"`
+	PercentUsed int                  `json:"percent_used"`
+	Warnings    []string             `json:"warnings"`
+}
+
+// QuotaError is returned when an upload would go over a hard cap
+type QuotaError struct {
+	Code           string `json:"error"`
+	Message        string `json:"message"`
+	LimitBytes     int64  `json:"limit_bytes"`
+	UsedBytes      int64  `json:"used_bytes"`
+	RequestedBytes int64  `json:"requested_bytes"`
+}
+
+// Error implements the error interface
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// PercentOf returns used as a whole percentage of limit
+func PercentOf(used, limit int64) int {
+	if limit <= 0 {
+		return 0
+	}
+	return int(used * 100 / limit)
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// Suggestion kinds
+const (
+	SuggestionSkippedSchedule = "skipped_schedule"
+	SuggestionIdleMember      = "idle_member"
+	SuggestionMissingPickup   = "missing_pickup"
+)
+
+// Suggestion actions
+const (
+	SuggestionActionPauseSchedule = "pause_schedule"
+	SuggestionActionAssignTask    = "assign_task"
+	SuggestionActionAddAttendee   = "add_attendee"
+)
+
+// Suggestion statuses
+const (
+	SuggestionStatusOpen      = "open"
+	SuggestionStatusApplied   = "applied"
+	SuggestionStatusDismissed = "dismissed"
+)
+
+// Suggestion is a nudge produced by the suggestion engine. Each one carries a
+// single action that the apply endpoint performs.
+type Suggestion struct {
+	ID         string     `json:"id" db:"id"`
+	FamilyID   string     `json:"family_id" db:"family_id"`
+	Kind       string     `json:"kind" db:"kind"`
+	Title      string     `json:"title" db:"title"`
+	Detail     string     `json:"detail" db:"detail"`
+	ActionType string     `json:"action_type" db:"action_type"`
+	TargetID   string     `json:"target_id" db:"target_id"`
+	MemberID   *string    `json:"member_id" db:"member_id"`
+	DedupeKey  string     `json:"-" db:"dedupe_key"`
+	Status     string     `json:"status" db:"status"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at" db:"resolved_at"`
+}
+
+// SuggestionDigest summarizes a family's suggestions for one week
+type SuggestionDigest struct {
+	FamilyID    string         `json:"family_id"`
+	WeekStart   string         `json:"week_start"` // YYYY-MM-DD, Monday
+	GeneratedAt time.Time      `json:"generated_at"`
+	Open        []Suggestion   `json:"open"`
+	OpenByKind  map[string]int `json:"open_by_kind"`
+	Applied     int            `json:"applied"`
+	Dismissed   int            `json:"dismissed"`
+}
+
+// WeekStart returns the Monday starting t's week, as a date
+func WeekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
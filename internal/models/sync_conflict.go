@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Sync conflict policies decide what a sync does with an event the family
+// edited since the integration last wrote it, when the remote changed it too
+const (
+	SyncConflictRemoteWins = "remote_wins" // the remote's change overwrites the edit
+	SyncConflictLocalWins  = "local_wins"  // the edit stays and the remote's change is dropped
+	SyncConflictNewestWins = "newest_wins" // whichever side changed last
+	SyncConflictManual     = "manual"      // the edit stays until someone picks a side
+)
+
+// SyncConflictPolicies lists the valid conflict policies
+var SyncConflictPolicies = []string{SyncConflictRemoteWins, SyncConflictLocalWins, SyncConflictNewestWins, SyncConflictManual}
+
+// Sides of a sync conflict to keep
+const (
+	SyncConflictKeepLocal  = "local"
+	SyncConflictKeepRemote = "remote"
+)
+
+// SyncedEventVersion is the remote's version of a synced event, as the
+// event is written if the remote wins
+type SyncedEventVersion struct {
+	Title             string     `json:"title"`
+	Description       *string    `json:"description,omitempty"`
+	Location          *string    `json:"location,omitempty"`
+	StartTime         time.Time  `json:"start_time"`
+	EndTime           time.Time  `json:"end_time"`
+	AllDay            bool       `json:"all_day"`
+	Color             string     `json:"color"`
+	Category          string     `json:"category,omitempty"`
+	RecurrenceRule    *string    `json:"recurrence_rule,omitempty"`
+	RecurrenceExdates string     `json:"recurrence_exdates,omitempty"`
+	RecurringEventID  *string    `json:"recurring_event_id,omitempty"`
+	AttendeeIDs       []string   `json:"attendee_ids"`
+	ModifiedAt        *time.Time `json:"modified_at,omitempty"` // when the remote changed it, if it says
+}
+
+// SyncConflict is a synced event awaiting manual review: the family's edited
+// event as it stands, and the remote's version it was kept from
+type SyncConflict struct {
+	ID            string               `json:"id"`
+	IntegrationID string               `json:"integration_id"`
+	EventID       string               `json:"event_id"`
+	Local         UnifiedCalendarEvent `json:"local"`
+	Remote        SyncedEventVersion   `json:"remote"`
+	DetectedAt    time.Time            `json:"detected_at"`
+}
+
+// ResolveSyncConflictRequest picks the side of a conflict to keep
+type ResolveSyncConflictRequest struct {
+	Keep string `json:"keep" validate:"required,oneof=local remote"`
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DueSoonWindow is how close a task's due time has to be for it to count as
+// due soon
+const DueSoonWindow = 2 * time.Hour
+
+// Orders for a member's column of tasks
+const (
+	TaskSortCreated  = "created"  // newest first
+	TaskSortDue      = "due"      // earliest due first, tasks without a due date last
+	TaskSortPriority = "priority" // most urgent first, then earliest due
+)
+
+// ParseTaskSort accepts any casing and defaults to newest first
+func ParseTaskSort(value string) (string, error) {
+	switch sortBy := normalizeEnum(value); sortBy {
+	case "":
+		return TaskSortCreated, nil
+	case TaskSortCreated, TaskSortDue, TaskSortPriority:
+		return sortBy, nil
+	default:
+		return "", fmt.Errorf("sort must be 'created', 'due', or 'priority'")
+	}
+}
+
+// Deadline returns when the task stops being on time: its due time, or the
+// end of its due day when it has no time. DueDate must already be in the
+// family's timezone.
+func (t *Task) Deadline() (time.Time, bool) {
+	if t.DueDate == nil {
+		return time.Time{}, false
+	}
+	if t.HasDueTime {
+		return *t.DueDate, true
+	}
+	due := *t.DueDate
+	return time.Date(due.Year(), due.Month(), due.Day()+1, 0, 0, 0, 0, due.Location()), true
+}
+
+// SetDueState fills in DueSoon and Overdue for a pending task as of now
+func (t *Task) SetDueState(now time.Time) {
+	t.DueSoon, t.Overdue = false, false
+	deadline, ok := t.Deadline()
+	if !ok || t.Status != TaskStatusPending {
+		return
+	}
+
+	if !now.Before(deadline) {
+		t.Overdue = true
+		return
+	}
+	if t.HasDueTime {
+		t.DueSoon = deadline.Sub(now) <= DueSoonWindow
+	} else {
+		// A task due some time today is due soon all day
+		t.DueSoon = deadline.Sub(now) <= 24*time.Hour
+	}
+}
+
+// SortTasks orders tasks in place
+func SortTasks(tasks []Task, sortBy string) {
+	byDue := func(a, b *Task) (bool, bool) {
+		aDeadline, aOK := a.Deadline()
+		bDeadline, bOK := b.Deadline()
+		switch {
+		case aOK != bOK:
+			return aOK, true
+		case aOK && !aDeadline.Equal(bDeadline):
+			return aDeadline.Before(bDeadline), true
+		}
+		return false, false
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := &tasks[i], &tasks[j]
+		switch sortBy {
+		case TaskSortDue:
+			if less, decided := byDue(a, b); decided {
+				return less
+			}
+			if a.Priority != b.Priority {
+				return a.Priority > b.Priority
+			}
+		case TaskSortPriority:
+			if a.Priority != b.Priority {
+				return a.Priority > b.Priority
+			}
+			if less, decided := byDue(a, b); decided {
+				return less
+			}
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+}
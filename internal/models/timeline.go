@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Timeline item kinds
+const (
+	TimelineItemEvent   = "event"
+	TimelineItemTask    = "task"
+	TimelineItemRoutine = "routine"
+)
+
+// TimelineItem is one entry in a member's day, positioned in 15-minute slots
+type TimelineItem struct {
+	ID           string    `json:"id"` // kind-prefixed so events and tasks never collide, e.g. "task:abc"
+	Kind         string    `json:"kind"`
+	SourceID     string    `json:"source_id"`
+	Title        string    `json:"title"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	AllDay       bool      `json:"all_day"`
+	Color        string    `json:"color,omitempty"`
+	Status       string    `json:"status,omitempty"`
+	StartSlot    int       `json:"start_slot"`
+	EndSlot      int       `json:"end_slot"`
+	OverlapGroup int       `json:"overlap_group"` // number of layers used by the items it overlaps
+	OverlapIndex int       `json:"overlap_index"` // the layer this item sits in
+}
+
+// TimelineLane is one member's row on the timeline. Timed items are in
+// chronological order; all-day items are listed separately.
+type TimelineLane struct {
+	MemberID   string         `json:"member_id"`
+	Name       string         `json:"name"`
+	MemberType string         `json:"member_type"`
+	Away       bool           `json:"away"` // at the other household per the custody schedule
+	Items      []TimelineItem `json:"items"`
+	AllDay     []TimelineItem `json:"all_day"`
+	LayerCount int            `json:"layer_count"`
+}
+
+// Timeline is the merged view of one family day across all members
+type Timeline struct {
+	Date       string         `json:"date"`
+	Timezone   string         `json:"timezone"`
+	Lanes      []TimelineLane `json:"lanes"`
+	TotalItems int            `json:"total_items"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// DayForecast is the weather forecast for one of a family's days
+type DayForecast struct {
+	Date                string    `json:"date" db:"forecast_date"`        // YYYY-MM-DD in the family's timezone
+	WeatherCode         int       `json:"weather_code" db:"weather_code"` // WMO weather interpretation code
+	Summary             string    `json:"summary"`                        // e.g. "Light rain"
+	Icon                string    `json:"icon"`
+	TemperatureMax      float64   `json:"temperature_max" db:"temperature_max"` // degrees Celsius
+	TemperatureMin      float64   `json:"temperature_min" db:"temperature_min"`
+	PrecipitationChance int       `json:"precipitation_chance" db:"precipitation_chance"` // percent
+	FetchedAt           time.Time `json:"fetched_at" db:"fetched_at"`
+}
@@ -0,0 +1,258 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"famstack/internal/config"
+	"famstack/internal/eventbus"
+	"famstack/internal/models"
+	"famstack/internal/services"
+)
+
+// Commands accepted on {prefix}/family/{family id}/commands/{command}
+const (
+	CommandCompleteTask    = "complete_task"     // {"task_id": "..."}
+	CommandAddShoppingItem = "add_shopping_item" // {"name": "...", "quantity": 2, "unit": "l"}
+)
+
+// queueSize bounds the changes waiting to be published; more are dropped
+// while the broker is unreachable rather than held without limit
+const queueSize = 256
+
+// Bridge publishes each opted-in family's task and calendar changes to the
+// broker and carries out the commands it receives. A family opts in by
+// adding an enabled MQTT smart-home integration; commands act as the member
+// who added it.
+//
+// Changes are published to:
+//
+//	{prefix}/family/{family id}/tasks/{created|updated|completed|deleted|changed}
+//	{prefix}/family/{family id}/calendar/changed
+type Bridge struct {
+	cfg      config.MQTTConfig
+	registry *services.Registry
+	events   chan eventbus.Event
+}
+
+// NewBridge makes a bridge to the broker in cfg; Run connects it
+func NewBridge(cfg config.MQTTConfig, registry *services.Registry) *Bridge {
+	if cfg.TopicPrefix == "" {
+		cfg.TopicPrefix = "famstack"
+	}
+	cfg.TopicPrefix = strings.TrimSuffix(cfg.TopicPrefix, "/")
+	if cfg.ClientID == "" {
+		cfg.ClientID = "famstack"
+	}
+	return &Bridge{
+		cfg:      cfg,
+		registry: registry,
+		events:   make(chan eventbus.Event, queueSize),
+	}
+}
+
+// Run keeps the bridge connected, reconnecting with backoff, until ctx ends
+func (b *Bridge) Run(ctx context.Context) {
+	unsubscribe := b.registry.Events.Subscribe(b.enqueue, eventbus.TopicTasksChanged, eventbus.TopicCalendarChanged)
+	defer unsubscribe()
+
+	backoff := time.Second
+	for {
+		client, err := Dial(ctx, Options{
+			Broker:   b.cfg.Broker,
+			ClientID: b.cfg.ClientID,
+			Username: b.cfg.Username,
+			Password: b.cfg.Password,
+		}, b.handleMessage)
+		if err != nil {
+			log.Printf("mqtt: failed to connect to %s, retrying in %s: %v", b.cfg.Broker, backoff, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, time.Minute)
+			continue
+		}
+		backoff = time.Second
+		log.Printf("mqtt: connected to %s", b.cfg.Broker)
+
+		if b.cfg.Commands {
+			if err := client.Subscribe(b.cfg.TopicPrefix + "/family/+/commands/+"); err != nil {
+				log.Printf("mqtt: failed to subscribe to commands: %v", err)
+			}
+		}
+		b.publishUntilDone(ctx, client)
+		if ctx.Err() != nil {
+			client.Close()
+			return
+		}
+		log.Printf("mqtt: %v", client.Err())
+	}
+}
+
+// enqueue is the event bus handler. It only queues, since handlers run on
+// the writer's goroutine.
+func (b *Bridge) enqueue(event eventbus.Event) {
+	select {
+	case b.events <- event:
+	default:
+	}
+}
+
+// publishUntilDone publishes queued changes until the connection ends
+func (b *Bridge) publishUntilDone(ctx context.Context, client *Client) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-client.Done():
+			return
+		case event := <-b.events:
+			if _, ok := b.integration(event.FamilyID); !ok {
+				continue
+			}
+			payload, err := b.payload(event)
+			if err != nil {
+				log.Printf("mqtt: failed to describe %s for family %s: %v", event.Topic, event.FamilyID, err)
+				continue
+			}
+			if err := client.Publish(eventTopic(b.cfg.TopicPrefix, event), payload, 1, false); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// eventTopic is the topic a change is published on
+func eventTopic(prefix string, event eventbus.Event) string {
+	family := prefix + "/family/" + event.FamilyID
+	if event.Topic == eventbus.TopicCalendarChanged {
+		return family + "/calendar/changed"
+	}
+	if event.Detail == "" {
+		return family + "/tasks/changed"
+	}
+	return family + "/tasks/" + event.Detail
+}
+
+// taskMessage is the payload of a message about one task
+type taskMessage struct {
+	TaskID     string            `json:"task_id"`
+	Title      string            `json:"title,omitempty"`
+	Status     models.TaskStatus `json:"status,omitempty"`
+	AssignedTo *string           `json:"assigned_to,omitempty"`
+	DueDate    *time.Time        `json:"due_date,omitempty"`
+}
+
+// payload describes a change. Changes to many tasks at once, and calendar
+// changes, say only that something changed.
+func (b *Bridge) payload(event eventbus.Event) ([]byte, error) {
+	if event.Topic != eventbus.TopicTasksChanged || event.SubjectID == "" {
+		return []byte("{}"), nil
+	}
+	if event.Detail == eventbus.DetailDeleted {
+		return json.Marshal(taskMessage{TaskID: event.SubjectID})
+	}
+	task, err := b.registry.Tasks.GetTask(event.SubjectID)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(taskMessage{
+		TaskID:     task.ID,
+		Title:      task.Title,
+		Status:     task.Status,
+		AssignedTo: task.AssignedTo,
+		DueDate:    task.DueDate,
+	})
+}
+
+// integration returns the family's enabled MQTT integration, if it has one
+func (b *Bridge) integration(familyID string) (*services.Integration, bool) {
+	integrationType, provider := services.TypeSmartHome, services.ProviderMQTT
+	integrations, err := b.registry.Integrations.ListIntegrations(familyID, &services.ListIntegrationsQuery{
+		IntegrationType: &integrationType,
+		Provider:        &provider,
+	})
+	if err != nil {
+		log.Printf("mqtt: failed to look up integrations for family %s: %v", familyID, err)
+		return nil, false
+	}
+	for i := range integrations {
+		if integrations[i].Enabled {
+			return &integrations[i], true
+		}
+	}
+	return nil, false
+}
+
+// handleMessage carries out a command received from the broker
+func (b *Bridge) handleMessage(msg Message) {
+	familyID, command, ok := parseCommandTopic(b.cfg.TopicPrefix, msg.Topic)
+	if !ok {
+		return
+	}
+	integration, ok := b.integration(familyID)
+	if !ok {
+		log.Printf("mqtt: ignoring %s for family %s, which has no MQTT integration", command, familyID)
+		return
+	}
+	if err := b.runCommand(familyID, integration.CreatedBy, command, msg.Payload); err != nil {
+		log.Printf("mqtt: %s for family %s failed: %v", command, familyID, err)
+	}
+}
+
+// parseCommandTopic splits {prefix}/family/{family id}/commands/{command}
+func parseCommandTopic(prefix, topic string) (familyID, command string, ok bool) {
+	rest, ok := strings.CutPrefix(topic, prefix+"/family/")
+	if !ok {
+		return "", "", false
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] != "commands" || parts[2] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[2], true
+}
+
+func (b *Bridge) runCommand(familyID, memberID, command string, payload []byte) error {
+	switch command {
+	case CommandCompleteTask:
+		var params struct {
+			TaskID string `json:"task_id"`
+		}
+		if err := json.Unmarshal(payload, &params); err != nil {
+			return fmt.Errorf("invalid payload: %w", err)
+		}
+		status := models.TaskStatusCompleted
+		_, err := b.registry.Tasks.UpdateTask(familyID, params.TaskID, &models.UpdateTaskRequest{Status: &status})
+		if errors.Is(err, services.ErrNotFound) {
+			return fmt.Errorf("task %q not found", params.TaskID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to complete task: %w", err)
+		}
+		return nil
+
+	case CommandAddShoppingItem:
+		var req models.CreateShoppingListItemRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return fmt.Errorf("invalid payload: %w", err)
+		}
+		if err := req.Validate(); err != nil {
+			return err
+		}
+		if _, err := b.registry.Meals.AddShoppingListItem(familyID, memberID, &req); err != nil {
+			return fmt.Errorf("failed to add shopping item: %w", err)
+		}
+		return nil
+
+	default:
+		return errors.New("unknown command")
+	}
+}
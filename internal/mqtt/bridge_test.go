@@ -0,0 +1,48 @@
+package mqtt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"famstack/internal/config"
+	"famstack/internal/eventbus"
+)
+
+func TestEventTopic(t *testing.T) {
+	tests := []struct {
+		event eventbus.Event
+		want  string
+	}{
+		{eventbus.Event{Topic: eventbus.TopicTasksChanged, FamilyID: "f1", SubjectID: "t1", Detail: eventbus.DetailCompleted}, "home/family/f1/tasks/completed"},
+		{eventbus.Event{Topic: eventbus.TopicTasksChanged, FamilyID: "f1", SubjectID: "t1", Detail: eventbus.DetailDeleted}, "home/family/f1/tasks/deleted"},
+		{eventbus.Event{Topic: eventbus.TopicTasksChanged, FamilyID: "f1"}, "home/family/f1/tasks/changed"},
+		{eventbus.Event{Topic: eventbus.TopicCalendarChanged, FamilyID: "f1"}, "home/family/f1/calendar/changed"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, eventTopic("home", tt.event))
+	}
+}
+
+func TestParseCommandTopic(t *testing.T) {
+	familyID, command, ok := parseCommandTopic("famstack", "famstack/family/f1/commands/complete_task")
+	assert.True(t, ok)
+	assert.Equal(t, "f1", familyID)
+	assert.Equal(t, CommandCompleteTask, command)
+
+	for _, topic := range []string{
+		"famstack/family/f1/tasks/completed",
+		"famstack/family//commands/complete_task",
+		"famstack/family/f1/commands/complete_task/extra",
+		"other/family/f1/commands/complete_task",
+	} {
+		_, _, ok := parseCommandTopic("famstack", topic)
+		assert.False(t, ok, topic)
+	}
+}
+
+func TestNewBridgeDefaults(t *testing.T) {
+	b := NewBridge(config.MQTTConfig{TopicPrefix: "home/"}, nil)
+	assert.Equal(t, "home", b.cfg.TopicPrefix)
+	assert.Equal(t, "famstack", b.cfg.ClientID)
+}
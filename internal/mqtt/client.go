@@ -0,0 +1,249 @@
+// Package mqtt bridges famstack to an MQTT broker for smart-home systems. It
+// holds a small MQTT 3.1.1 client, enough to publish, subscribe and stay
+// connected, and the Bridge that turns family changes into messages and
+// command messages into changes.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrClosed is returned by Publish and Subscribe once the connection is gone
+var ErrClosed = errors.New("mqtt connection closed")
+
+// Options say where and how to connect
+type Options struct {
+	// Broker is tcp://host:port, or tls://host:port for TLS; the port
+	// defaults to 1883 and 8883
+	Broker    string
+	ClientID  string
+	Username  string
+	Password  string
+	KeepAlive time.Duration // 60s when unset
+}
+
+// Client is a connection to a broker. Messages on subscribed topics are
+// handed to the handler given to Dial, one at a time on the goroutine that
+// reads the connection.
+type Client struct {
+	conn      net.Conn
+	keepAlive time.Duration
+	onMessage func(Message)
+
+	writeMu  sync.Mutex
+	nextID   atomic.Uint32
+	lastRead atomic.Int64 // unix nanoseconds
+
+	closeOnce sync.Once
+	done      chan struct{}
+	err       error
+}
+
+// Dial connects to the broker and starts a clean session
+func Dial(ctx context.Context, opts Options, onMessage func(Message)) (*Client, error) {
+	address, useTLS, err := brokerAddress(opts.Broker)
+	if err != nil {
+		return nil, err
+	}
+	keepAlive := opts.KeepAlive
+	if keepAlive == 0 {
+		keepAlive = time.Minute
+	}
+
+	var conn net.Conn
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if useTLS {
+		host, _, _ := net.SplitHostPort(address)
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach broker: %w", err)
+	}
+
+	// The handshake gets the dial's deadline, the read loop none
+	reader := bufio.NewReader(conn)
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	if _, err := conn.Write(connectPacket(opts.ClientID, opts.Username, opts.Password, uint16(keepAlive/time.Second)).encode()); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send CONNECT: %w", err)
+	}
+	ack, err := readPacket(reader)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read CONNACK: %w", err)
+	}
+	if err := connAckError(ack); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	c := &Client{
+		conn:      conn,
+		keepAlive: keepAlive,
+		onMessage: onMessage,
+		done:      make(chan struct{}),
+	}
+	c.lastRead.Store(time.Now().UnixNano())
+	go c.readLoop(reader)
+	go c.pingLoop()
+	return c, nil
+}
+
+// brokerAddress turns a broker URL into host:port and whether to use TLS
+func brokerAddress(broker string) (string, bool, error) {
+	u, err := url.Parse(broker)
+	if err != nil || u.Host == "" {
+		return "", false, fmt.Errorf("invalid broker %q: expected tcp://host:port or tls://host:port", broker)
+	}
+	var useTLS bool
+	port := "1883"
+	switch u.Scheme {
+	case "tcp", "mqtt":
+	case "tls", "ssl", "mqtts":
+		useTLS, port = true, "8883"
+	default:
+		return "", false, fmt.Errorf("invalid broker %q: unsupported scheme %q", broker, u.Scheme)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	return net.JoinHostPort(u.Hostname(), port), useTLS, nil
+}
+
+// Publish sends a message. At QoS 1 the broker acknowledges it, but Publish
+// doesn't wait for that.
+func (c *Client) Publish(topic string, payload []byte, qos byte, retain bool) error {
+	return c.write(publishPacket(topic, payload, qos, retain, c.packetID()))
+}
+
+// Subscribe asks for the messages on topics matching the filters, which may
+// use the + and # wildcards
+func (c *Client) Subscribe(filters ...string) error {
+	return c.write(subscribePacket(c.packetID(), filters))
+}
+
+// Done is closed when the connection is lost or closed; Err then says why
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err reports why the connection ended, nil while it is open or after Close
+func (c *Client) Err() error {
+	select {
+	case <-c.done:
+		return c.err
+	default:
+		return nil
+	}
+}
+
+// Close says goodbye to the broker and closes the connection
+func (c *Client) Close() error {
+	_ = c.write(packet{kind: packetDisconnect})
+	c.shutdown(nil)
+	return nil
+}
+
+// packetID returns the next non-zero packet identifier
+func (c *Client) packetID() uint16 {
+	for {
+		if id := uint16(c.nextID.Add(1)); id != 0 {
+			return id
+		}
+	}
+}
+
+func (c *Client) write(p packet) error {
+	select {
+	case <-c.done:
+		return ErrClosed
+	default:
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	if _, err := c.conn.Write(p.encode()); err != nil {
+		c.shutdown(fmt.Errorf("failed to write to broker: %w", err))
+		return err
+	}
+	return nil
+}
+
+// shutdown closes the connection once, recording why
+func (c *Client) shutdown(err error) {
+	c.closeOnce.Do(func() {
+		c.err = err
+		c.conn.Close()
+		close(c.done)
+	})
+}
+
+func (c *Client) readLoop(reader *bufio.Reader) {
+	for {
+		p, err := readPacket(reader)
+		if err != nil {
+			c.shutdown(fmt.Errorf("connection to broker lost: %w", err))
+			return
+		}
+		c.lastRead.Store(time.Now().UnixNano())
+
+		switch p.kind {
+		case packetPublish:
+			msg, qos, id, err := parsePublish(p)
+			if err != nil {
+				c.shutdown(err)
+				return
+			}
+			if qos > 0 {
+				// Subscriptions ask for QoS 1, so QoS 2 doesn't arrive
+				_ = c.write(packet{kind: packetPubAck, body: []byte{byte(id >> 8), byte(id)}})
+			}
+			if c.onMessage != nil {
+				c.onMessage(msg)
+			}
+		case packetSubAck:
+			for _, code := range p.body[min(2, len(p.body)):] {
+				if code == 0x80 {
+					log.Printf("mqtt: broker refused a subscription")
+				}
+			}
+		case packetPubAck, packetPingResp:
+		default:
+			c.shutdown(fmt.Errorf("unexpected packet type %d from broker", p.kind))
+			return
+		}
+	}
+}
+
+// pingLoop keeps the connection alive while nothing else is sent, and drops
+// it when the broker stops answering
+func (c *Client) pingLoop() {
+	ticker := time.NewTicker(c.keepAlive / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if time.Since(time.Unix(0, c.lastRead.Load())) > c.keepAlive*3/2 {
+				c.shutdown(errors.New("broker stopped responding"))
+				return
+			}
+			_ = c.write(packet{kind: packetPingReq})
+		}
+	}
+}
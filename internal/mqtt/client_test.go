@@ -0,0 +1,136 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemainingLength(t *testing.T) {
+	for _, n := range []int{0, 127, 128, 16383, 16384, 200000} {
+		p := packet{kind: packetPublish, body: make([]byte, n)}
+		got, err := readPacket(bufio.NewReader(bytes.NewReader(p.encode())))
+		require.NoError(t, err)
+		assert.Len(t, got.body, n)
+	}
+	assert.Equal(t, []byte{0xc1, 0x02}, appendLength(nil, 321))
+}
+
+func TestPublishRoundTrip(t *testing.T) {
+	p := publishPacket("famstack/family/f1/tasks/completed", []byte(`{"task_id":"t1"}`), 1, true, 7)
+	assert.Equal(t, byte(0x03), p.flags)
+
+	msg, qos, id, err := parsePublish(p)
+	require.NoError(t, err)
+	assert.Equal(t, "famstack/family/f1/tasks/completed", msg.Topic)
+	assert.Equal(t, `{"task_id":"t1"}`, string(msg.Payload))
+	assert.Equal(t, byte(1), qos)
+	assert.Equal(t, uint16(7), id)
+
+	_, _, _, err = parsePublish(packet{kind: packetPublish, body: []byte{0, 9, 'a'}})
+	assert.Error(t, err)
+}
+
+func TestBrokerAddress(t *testing.T) {
+	address, useTLS, err := brokerAddress("tcp://broker.local")
+	require.NoError(t, err)
+	assert.Equal(t, "broker.local:1883", address)
+	assert.False(t, useTLS)
+
+	address, useTLS, err = brokerAddress("tls://broker.example.com:9883")
+	require.NoError(t, err)
+	assert.Equal(t, "broker.example.com:9883", address)
+	assert.True(t, useTLS)
+
+	_, _, err = brokerAddress("http://broker.local")
+	assert.Error(t, err)
+	_, _, err = brokerAddress("broker.local:1883")
+	assert.Error(t, err)
+}
+
+// fakeBroker accepts one client and lets the test script the conversation
+func fakeBroker(t *testing.T, script func(r *bufio.Reader, conn net.Conn)) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		script(bufio.NewReader(conn), conn)
+	}()
+	return "tcp://" + listener.Addr().String()
+}
+
+func TestClientConnectSubscribeAndReceive(t *testing.T) {
+	received := make(chan packet, 4)
+	broker := fakeBroker(t, func(r *bufio.Reader, conn net.Conn) {
+		connect, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		received <- connect
+		_, _ = conn.Write(packet{kind: packetConnAck, body: []byte{0, 0}}.encode())
+
+		subscribe, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		received <- subscribe
+		_, _ = conn.Write(publishPacket("famstack/family/f1/commands/complete_task", []byte(`{"task_id":"t1"}`), 1, false, 42).encode())
+
+		ack, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		received <- ack
+	})
+
+	messages := make(chan Message, 1)
+	client, err := Dial(context.Background(), Options{Broker: broker, ClientID: "test", Username: "user", Password: "secret"}, func(msg Message) {
+		messages <- msg
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	connect := <-received
+	assert.Equal(t, byte(packetConnect), connect.kind)
+	assert.True(t, bytes.Contains(connect.body, []byte("secret")))
+
+	require.NoError(t, client.Subscribe("famstack/family/+/commands/+"))
+	subscribe := <-received
+	assert.Equal(t, byte(packetSubscribe), subscribe.kind)
+	assert.Equal(t, byte(0x02), subscribe.flags)
+
+	select {
+	case msg := <-messages:
+		assert.Equal(t, "famstack/family/f1/commands/complete_task", msg.Topic)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message delivered")
+	}
+
+	ack := <-received
+	assert.Equal(t, byte(packetPubAck), ack.kind)
+	assert.Equal(t, []byte{0, 42}, ack.body)
+}
+
+func TestClientRefusedConnection(t *testing.T) {
+	broker := fakeBroker(t, func(r *bufio.Reader, conn net.Conn) {
+		if _, err := readPacket(r); err == nil {
+			_, _ = conn.Write(packet{kind: packetConnAck, body: []byte{0, 4}}.encode())
+		}
+	})
+
+	_, err := Dial(context.Background(), Options{Broker: broker, ClientID: "test"}, nil)
+	assert.EqualError(t, err, "bad username or password")
+}
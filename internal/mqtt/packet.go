@@ -0,0 +1,190 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Packet types (MQTT 3.1.1 section 2.2.1)
+const (
+	packetConnect    = 1
+	packetConnAck    = 2
+	packetPublish    = 3
+	packetPubAck     = 4
+	packetSubscribe  = 8
+	packetSubAck     = 9
+	packetPingReq    = 12
+	packetPingResp   = 13
+	packetDisconnect = 14
+)
+
+// maxPacketSize bounds a packet the broker may send; commands are small
+const maxPacketSize = 256 * 1024
+
+// packet is one control packet: its type, the flags in the low bits of the
+// first byte, and everything after the remaining length
+type packet struct {
+	kind  byte
+	flags byte
+	body  []byte
+}
+
+// encode returns the packet as sent on the wire
+func (p packet) encode() []byte {
+	out := []byte{p.kind<<4 | p.flags}
+	out = appendLength(out, len(p.body))
+	return append(out, p.body...)
+}
+
+// appendLength appends the variable-length remaining length (2.2.3)
+func appendLength(b []byte, n int) []byte {
+	for {
+		digit := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			digit |= 0x80
+		}
+		b = append(b, digit)
+		if n == 0 {
+			return b
+		}
+	}
+}
+
+// appendString appends a UTF-8 string prefixed by its length (1.5.3)
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// readPacket reads the next packet from r
+func readPacket(r *bufio.Reader) (packet, error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return packet{}, errors.New("malformed remaining length")
+		}
+		digit, err := r.ReadByte()
+		if err != nil {
+			return packet{}, err
+		}
+		length += int(digit&0x7f) * multiplier
+		if digit&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+	if length > maxPacketSize {
+		return packet{}, fmt.Errorf("packet of %d bytes is too large", length)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return packet{}, err
+	}
+	return packet{kind: first >> 4, flags: first & 0x0f, body: body}, nil
+}
+
+// connectPacket opens a clean session
+func connectPacket(clientID, username, password string, keepAliveSeconds uint16) packet {
+	var flags byte = 0x02 // clean session
+	if username != "" {
+		flags |= 0x80
+		if password != "" {
+			flags |= 0x40
+		}
+	}
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, flags) // protocol level 4 is 3.1.1
+	body = binary.BigEndian.AppendUint16(body, keepAliveSeconds)
+	body = appendString(body, clientID)
+	if username != "" {
+		body = appendString(body, username)
+		if password != "" {
+			body = appendString(body, password)
+		}
+	}
+	return packet{kind: packetConnect, body: body}
+}
+
+// publishPacket sends a message at QoS 0 or 1; packetID is used only at 1
+func publishPacket(topic string, payload []byte, qos byte, retain bool, packetID uint16) packet {
+	flags := qos << 1
+	if retain {
+		flags |= 0x01
+	}
+	body := appendString(nil, topic)
+	if qos > 0 {
+		body = binary.BigEndian.AppendUint16(body, packetID)
+	}
+	return packet{kind: packetPublish, flags: flags, body: append(body, payload...)}
+}
+
+// subscribePacket asks for the messages matching the filters at QoS 1
+func subscribePacket(packetID uint16, filters []string) packet {
+	body := binary.BigEndian.AppendUint16(nil, packetID)
+	for _, filter := range filters {
+		body = appendString(body, filter)
+		body = append(body, 1)
+	}
+	// The flags of SUBSCRIBE are fixed (3.8.1)
+	return packet{kind: packetSubscribe, flags: 0x02, body: body}
+}
+
+// Message is an application message received from the broker
+type Message struct {
+	Topic   string
+	Payload []byte
+}
+
+// parsePublish reads a received PUBLISH. packetID is set for QoS 1 and 2,
+// which the broker expects acknowledged.
+func parsePublish(p packet) (msg Message, qos byte, packetID uint16, err error) {
+	qos = (p.flags >> 1) & 0x03
+	if len(p.body) < 2 {
+		return Message{}, 0, 0, errors.New("malformed PUBLISH")
+	}
+	topicLength := int(binary.BigEndian.Uint16(p.body))
+	rest := p.body[2:]
+	if len(rest) < topicLength {
+		return Message{}, 0, 0, errors.New("malformed PUBLISH")
+	}
+	msg.Topic, rest = string(rest[:topicLength]), rest[topicLength:]
+	if qos > 0 {
+		if len(rest) < 2 {
+			return Message{}, 0, 0, errors.New("malformed PUBLISH")
+		}
+		packetID, rest = binary.BigEndian.Uint16(rest), rest[2:]
+	}
+	msg.Payload = rest
+	return msg, qos, packetID, nil
+}
+
+// connAckError explains a CONNACK that refused the connection
+func connAckError(p packet) error {
+	if p.kind != packetConnAck || len(p.body) != 2 {
+		return errors.New("broker did not acknowledge the connection")
+	}
+	switch p.body[1] {
+	case 0:
+		return nil
+	case 1:
+		return errors.New("broker does not support MQTT 3.1.1")
+	case 2:
+		return errors.New("broker rejected the client ID")
+	case 3:
+		return errors.New("broker unavailable")
+	case 4:
+		return errors.New("bad username or password")
+	case 5:
+		return errors.New("not authorized")
+	default:
+		return fmt.Errorf("broker refused the connection (code %d)", p.body[1])
+	}
+}
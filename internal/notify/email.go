@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"famstack/internal/config"
+)
+
+// Email sends notifications through an SMTP server
+type Email struct {
+	cfg  config.EmailNotifyConfig
+	send func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmail creates an email channel
+func NewEmail(cfg config.EmailNotifyConfig) *Email {
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return &Email{cfg: cfg, send: smtp.SendMail}
+}
+
+// Name identifies the channel
+func (e *Email) Name() string {
+	return ChannelEmail
+}
+
+// Send emails the message to the recipient's address
+func (e *Email) Send(ctx context.Context, to Recipient, msg Message) (*SendResult, error) {
+	if to.Email == "" {
+		return nil, ErrUnreachable
+	}
+	from, err := mail.ParseAddress(e.cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address %q: %w", e.cfg.From, err)
+	}
+	recipient := mail.Address{Name: to.Name, Address: to.Email}
+
+	var auth smtp.Auth
+	if e.cfg.Username != "" {
+		auth = smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port))
+	body := buildEmail(from, &recipient, msg, time.Now())
+
+	// net/smtp takes no context, so honor cancellation around the call
+	done := make(chan error, 1)
+	go func() {
+		done <- e.send(addr, auth, from.Address, []string{to.Email}, body)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return nil, fmt.Errorf("failed to send email: %w", err)
+		}
+		return &SendResult{}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// buildEmail renders a plain-text message with encoded headers
+func buildEmail(from, to *mail.Address, msg Message, now time.Time) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from.String() + "\r\n")
+	b.WriteString("To: " + to.String() + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Title) + "\r\n")
+	b.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+
+	text := msg.Body
+	if msg.URL != "" {
+		text += "\n\n" + msg.URL
+	}
+	// net/smtp escapes leading dots; line endings are ours to fix
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		b.WriteString(line + "\r\n")
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,44 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"famstack/internal/config"
+)
+
+func TestBuildEmail(t *testing.T) {
+	from := &mail.Address{Name: "Famstack", Address: "home@example.com"}
+	to := &mail.Address{Name: "Sam Rivera", Address: "sam@example.com"}
+	now := time.Date(2025, 10, 1, 8, 45, 0, 0, time.UTC)
+
+	body := string(buildEmail(from, to, Message{Title: "Dentist ☺", Body: "Starts at 9:00 AM\nBring forms", URL: "https://home.example.com/calendar"}, now))
+
+	for _, want := range []string{
+		"To: \"Sam Rivera\" <sam@example.com>\r\n",
+		"Subject: =?utf-8?q?Dentist_=E2=98=BA?=\r\n",
+		"Date: Wed, 01 Oct 2025 08:45:00 +0000\r\n",
+		"\r\n\r\nStarts at 9:00 AM\r\nBring forms\r\n\r\nhttps://home.example.com/calendar\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("email missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestEmailSend_UnreachableWithoutAddress(t *testing.T) {
+	channel := NewEmail(config.EmailNotifyConfig{Host: "smtp.example.com", From: "home@example.com"})
+	channel.send = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		t.Fatal("nothing should be sent")
+		return nil
+	}
+
+	if _, err := channel.Send(context.Background(), Recipient{MemberID: "m1"}, Message{Title: "Hi"}); !errors.Is(err, ErrUnreachable) {
+		t.Fatalf("expected ErrUnreachable, got %v", err)
+	}
+}
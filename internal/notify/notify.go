@@ -0,0 +1,81 @@
+// Package notify delivers notifications to family members through pluggable
+// channels: email over SMTP, and web push to browsers that subscribed with
+// the Push API. Which channels are on is set by the notifications section of
+// the config file. What to send, and to whom, is decided by the services; a
+// channel only carries one message to one member.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"famstack/internal/config"
+)
+
+// Channel names, as stored with each reminder
+const (
+	ChannelEmail   = "email"
+	ChannelWebPush = "web_push"
+)
+
+// ErrUnreachable is returned when a member has no address on the channel,
+// such as no email address or no push subscription
+var ErrUnreachable = errors.New("member is not reachable on this channel")
+
+// Message is one notification
+type Message struct {
+	Title string
+	Body  string
+	URL   string // page to open, relative to the app
+	Tag   string // collapses repeated notifications on the device
+}
+
+// Recipient is a family member with the addresses channels deliver to
+type Recipient struct {
+	MemberID          string
+	Name              string
+	Email             string
+	PushSubscriptions []PushSubscription
+}
+
+// PushSubscription is a browser's Push API subscription
+type PushSubscription struct {
+	Endpoint string
+	P256dh   string // base64url client public key
+	Auth     string // base64url auth secret
+}
+
+// SendResult reports subscriptions the push service says no longer exist, so
+// the caller can forget them
+type SendResult struct {
+	GoneEndpoints []string
+}
+
+// Channel delivers messages one way
+type Channel interface {
+	// Name is the channel's constant, e.g. ChannelEmail
+	Name() string
+	// Send delivers the message to the recipient. It returns ErrUnreachable
+	// when the recipient has no address on this channel.
+	Send(ctx context.Context, to Recipient, msg Message) (*SendResult, error)
+}
+
+// NewChannels creates the channels the config turns on, keyed by name
+func NewChannels(cfg *config.Config) (map[string]Channel, error) {
+	channels := make(map[string]Channel)
+
+	if cfg.Features.EmailNotifications && cfg.Notify.Email.Host != "" {
+		channels[ChannelEmail] = NewEmail(cfg.Notify.Email)
+	}
+
+	if cfg.Notify.WebPush.Enabled {
+		push, err := NewWebPush(cfg.Notify.WebPush)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up web push: %w", err)
+		}
+		channels[ChannelWebPush] = push
+	}
+
+	return channels, nil
+}
@@ -0,0 +1,272 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"time"
+
+	"famstack/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// webPushTTL is how long a push service holds a message for an offline
+// browser. Reminders are worthless once the event has started.
+const webPushTTL = 30 * time.Minute
+
+// webPushRecordSize is the aes128gcm record size; messages fit in one record
+const webPushRecordSize = 4096
+
+// WebPush sends notifications to Push API subscriptions, encrypted per RFC
+// 8291 and signed with the server's VAPID key (RFC 8292)
+type WebPush struct {
+	subject    string
+	publicKey  []byte // uncompressed P-256 point
+	privateKey *ecdsa.PrivateKey
+	client     *http.Client
+}
+
+// NewWebPush creates a web push channel from a configured VAPID key pair
+func NewWebPush(cfg config.WebPushNotifyConfig) (*WebPush, error) {
+	if cfg.VAPIDPrivateKey == "" {
+		return nil, fmt.Errorf("no VAPID key configured")
+	}
+	privateKey, publicKey, err := parseVAPIDKey(cfg.VAPIDPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	subject := cfg.Subject
+	if subject == "" {
+		subject = "mailto:admin@localhost"
+	}
+	return &WebPush{
+		subject:    subject,
+		publicKey:  publicKey,
+		privateKey: privateKey,
+		client:     &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+// GenerateVAPIDKeys creates a new VAPID key pair, base64url encoded as the
+// config stores them
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate VAPID key: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()), nil
+}
+
+func parseVAPIDKey(encoded string) (*ecdsa.PrivateKey, []byte, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	// Signing needs the key in crypto/ecdsa form
+	public := key.PublicKey().Bytes()
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(public[1:33]),
+			Y:     new(big.Int).SetBytes(public[33:]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}, public, nil
+}
+
+// Name identifies the channel
+func (p *WebPush) Name() string {
+	return ChannelWebPush
+}
+
+// PublicKey returns the VAPID public key browsers subscribe with
+func (p *WebPush) PublicKey() string {
+	return base64.RawURLEncoding.EncodeToString(p.publicKey)
+}
+
+// Send pushes the message to each of the recipient's subscriptions. It
+// succeeds if any subscription took it, and reports the ones that are gone.
+func (p *WebPush) Send(ctx context.Context, to Recipient, msg Message) (*SendResult, error) {
+	if len(to.PushSubscriptions) == 0 {
+		return nil, ErrUnreachable
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"title": msg.Title,
+		"body":  msg.Body,
+		"url":   msg.URL,
+		"tag":   msg.Tag,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode push payload: %w", err)
+	}
+
+	result := &SendResult{}
+	delivered := false
+	var errs []error
+	for _, sub := range to.PushSubscriptions {
+		err := p.push(ctx, sub, payload)
+		switch {
+		case err == nil:
+			delivered = true
+		case errors.Is(err, errSubscriptionGone):
+			result.GoneEndpoints = append(result.GoneEndpoints, sub.Endpoint)
+		default:
+			errs = append(errs, err)
+		}
+	}
+
+	if !delivered {
+		if len(errs) == 0 {
+			// Every subscription has expired
+			return result, ErrUnreachable
+		}
+		return result, errors.Join(errs...)
+	}
+	return result, nil
+}
+
+var errSubscriptionGone = errors.New("push subscription is gone")
+
+func (p *WebPush) push(ctx context.Context, sub PushSubscription, payload []byte) error {
+	serverKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate push key: %w", err)
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate push salt: %w", err)
+	}
+	body, err := encryptPushPayload(sub, payload, serverKey, salt)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil || endpoint.Scheme != "https" {
+		return fmt.Errorf("invalid push endpoint %q", sub.Endpoint)
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": endpoint.Scheme + "://" + endpoint.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": p.subject,
+	}).SignedString(p.privateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create push request: %w", err)
+	}
+	req.Header.Set("Authorization", "vapid t="+token+", k="+p.PublicKey())
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", fmt.Sprint(int(webPushTTL.Seconds())))
+	req.Header.Set("Urgency", "high")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send push: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096)) // nolint:errcheck
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return errSubscriptionGone
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("push service returned %s", resp.Status)
+	}
+	return nil
+}
+
+// encryptPushPayload encrypts a payload for a subscription with the
+// aes128gcm content coding (RFC 8188), keyed as RFC 8291 describes. serverKey
+// and salt must be fresh for every message.
+func encryptPushPayload(sub PushSubscription, plaintext []byte, serverKey *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	clientPublic, err := base64.RawURLEncoding.DecodeString(trimPadding(sub.P256dh))
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription key: %w", err)
+	}
+	authSecret, err := base64.RawURLEncoding.DecodeString(trimPadding(sub.Auth))
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription auth secret: %w", err)
+	}
+	clientKey, err := ecdh.P256().NewPublicKey(clientPublic)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription key: %w", err)
+	}
+
+	sharedSecret, err := serverKey.ECDH(clientKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to agree push key: %w", err)
+	}
+	serverPublic := serverKey.PublicKey().Bytes()
+
+	keyInfo := "WebPush: info\x00" + string(clientPublic) + string(serverPublic)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	contentKey, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// A single record ends with the 0x02 delimiter and needs no padding
+	if len(plaintext)+1+gcm.Overhead() > webPushRecordSize {
+		return nil, fmt.Errorf("push payload of %d bytes is too large", len(plaintext))
+	}
+	record := gcm.Seal(nil, nonce, append(bytes.Clone(plaintext), 0x02), nil)
+
+	header := make([]byte, 0, 16+4+1+len(serverPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(serverPublic)))
+	header = append(header, serverPublic...)
+	return append(header, record...), nil
+}
+
+// trimPadding accepts keys from browsers that base64url encode with padding
+func trimPadding(s string) string {
+	for len(s) > 0 && s[len(s)-1] == '=' {
+		s = s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,46 @@
+package notify
+
+import (
+	"crypto/ecdh"
+	"encoding/base64"
+	"testing"
+
+	"famstack/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+// The example from RFC 8291, Appendix A
+func TestEncryptPushPayloadMatchesRFC8291(t *testing.T) {
+	serverKey, err := ecdh.P256().NewPrivateKey(decode(t, "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	require.NoError(t, err)
+	sub := PushSubscription{
+		P256dh: "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",
+		Auth:   "BTBZMqHH6r4Tts7J_aSIgg==",
+	}
+
+	body, err := encryptPushPayload(sub, []byte("When I grow up, I want to be a watermelon"), serverKey, decode(t, "DGv6ra1nlYgDCS1FRnbzlw"))
+	require.NoError(t, err)
+	assert.Equal(t, "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN",
+		base64.RawURLEncoding.EncodeToString(body))
+}
+
+func TestVAPIDKeysRoundTrip(t *testing.T) {
+	public, private, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+
+	push, err := NewWebPush(config.WebPushNotifyConfig{Enabled: true, VAPIDPublicKey: public, VAPIDPrivateKey: private})
+	require.NoError(t, err)
+	assert.Equal(t, public, push.PublicKey())
+
+	_, err = NewWebPush(config.WebPushNotifyConfig{Enabled: true, VAPIDPrivateKey: "not-a-key"})
+	assert.Error(t, err)
+}
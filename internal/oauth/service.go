@@ -7,26 +7,57 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/calendar/v3"
 
+	"famstack/internal/config"
 	"famstack/internal/encryption"
+	"famstack/internal/ids"
 	"famstack/internal/services"
 )
 
 // Service handles OAuth operations
 type Service struct {
 	oauthService  *services.OAuthService
-	config        *OAuthConfig
-	googleConfig  *oauth2.Config
 	encryptionSvc *encryption.Service
+
+	mu           sync.RWMutex // guards the provider config, which can be reloaded
+	config       *OAuthConfig
+	googleConfig *oauth2.Config
 }
 
 // NewService creates a new OAuth service
 func NewService(oauthService *services.OAuthService, config *OAuthConfig, encryptionSvc *encryption.Service) *Service {
+	s := &Service{
+		oauthService:  oauthService,
+		encryptionSvc: encryptionSvc,
+	}
+	s.SetConfig(config)
+	return s
+}
+
+// ConfigFrom builds the provider config from the application's OAuth
+// settings, leaving out providers that aren't fully configured
+func ConfigFrom(cfg config.OAuthConfig) *OAuthConfig {
+	oauthConfig := &OAuthConfig{}
+	if google := cfg.Google; google != nil && google.Configured {
+		oauthConfig.Google = &GoogleConfig{
+			ClientID:     google.ClientID,
+			ClientSecret: google.ClientSecret,
+			RedirectURL:  google.RedirectURL,
+			Scopes:       google.Scopes,
+		}
+	}
+	return oauthConfig
+}
+
+// SetConfig replaces the provider config, as when the OAuth settings are
+// changed without a restart. Flows already started finish with the new one.
+func (s *Service) SetConfig(config *OAuthConfig) {
 	var googleConfig *oauth2.Config
 	if config.Google != nil {
 		googleConfig = &oauth2.Config{
@@ -38,12 +69,18 @@ func NewService(oauthService *services.OAuthService, config *OAuthConfig, encryp
 		}
 	}
 
-	return &Service{
-		oauthService:  oauthService,
-		config:        config,
-		googleConfig:  googleConfig,
-		encryptionSvc: encryptionSvc,
-	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = config
+	s.googleConfig = googleConfig
+}
+
+// google returns the current Google provider config, or nil when Google
+// isn't configured
+func (s *Service) google() *oauth2.Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.googleConfig
 }
 
 // GetAuthURL generates OAuth authorization URL for provider
@@ -223,15 +260,17 @@ func (s *Service) verifyState(state string) bool {
 }
 
 func (s *Service) getGoogleAuthURL(state string) (string, error) {
-	if s.googleConfig == nil {
+	googleConfig := s.google()
+	if googleConfig == nil {
 		return "", fmt.Errorf("google OAuth not configured")
 	}
 
-	return s.googleConfig.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce), nil
+	return googleConfig.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce), nil
 }
 
 func (s *Service) handleGoogleCallback(code, state string) (*OAuthToken, error) {
-	if s.googleConfig == nil {
+	googleConfig := s.google()
+	if googleConfig == nil {
 		return nil, fmt.Errorf("google OAuth not configured")
 	}
 
@@ -249,7 +288,7 @@ func (s *Service) handleGoogleCallback(code, state string) (*OAuthToken, error)
 
 	// Exchange authorization code for token
 	ctx := context.Background()
-	token, err := s.googleConfig.Exchange(ctx, code)
+	token, err := googleConfig.Exchange(ctx, code)
 	if err != nil {
 		fmt.Printf("Error exchanging code for token: %v\n", err)
 		return nil, fmt.Errorf("failed to exchange code for token: %w", err)
@@ -285,7 +324,8 @@ func (s *Service) handleGoogleCallback(code, state string) (*OAuthToken, error)
 }
 
 func (s *Service) refreshGoogleToken(token *OAuthToken) (*OAuthToken, error) {
-	if s.googleConfig == nil {
+	googleConfig := s.google()
+	if googleConfig == nil {
 		return nil, fmt.Errorf("google OAuth not configured")
 	}
 
@@ -299,7 +339,7 @@ func (s *Service) refreshGoogleToken(token *OAuthToken) (*OAuthToken, error) {
 
 	// Create token source that will refresh automatically
 	ctx := context.Background()
-	tokenSource := s.googleConfig.TokenSource(ctx, oauth2Token)
+	tokenSource := googleConfig.TokenSource(ctx, oauth2Token)
 
 	// Get fresh token
 	freshToken, err := tokenSource.Token()
@@ -365,19 +405,13 @@ func (s *Service) saveTokenWithEncryption(token *OAuthToken) error {
 	return s.oauthService.SaveToken(serviceToken)
 }
 
-// generateID creates a new cryptographically secure random ID
 func generateID() string {
-	bytes := make([]byte, 16)
-	if _, err := rand.Read(bytes); err != nil {
-		// Fallback to timestamp if crypto/rand fails (should be extremely rare)
-		return fmt.Sprintf("oauth_fallback_%d", time.Now().UTC().UnixNano())
-	}
-	return fmt.Sprintf("oauth_%s", hex.EncodeToString(bytes))
+	return ids.New("oauth")
 }
 
 // GetOAuth2Config returns the oauth2.Config for external use
 func (s *Service) GetOAuth2Config() *oauth2.Config {
-	return s.googleConfig
+	return s.google()
 }
 
 // GetOAuth2Token converts OAuthToken to oauth2.Token
@@ -0,0 +1,242 @@
+// Package ratelimit throttles clients that send too many requests. Each
+// client gets a token bucket per class of endpoint: sign-in attempts and
+// OAuth flows are counted per client address, API calls per session. A
+// request that finds its bucket empty gets a 429 with a Retry-After header.
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"famstack/internal/apierror"
+	"famstack/internal/config"
+	"famstack/internal/middleware"
+)
+
+// Defaults for the limits left at zero in the config file
+var (
+	DefaultLogin = config.RateLimit{PerMinute: 10, Burst: 5}
+	DefaultOAuth = config.RateLimit{PerMinute: 30, Burst: 10}
+	DefaultAPI   = config.RateLimit{PerMinute: 600, Burst: 120}
+)
+
+// sweepInterval is how often a limiter drops the buckets of idle clients
+const sweepInterval = time.Minute
+
+// Class names the endpoints a limit applies to
+type Class string
+
+const (
+	ClassLogin Class = "login"
+	ClassOAuth Class = "oauth"
+	ClassAPI   Class = "api"
+)
+
+// Limiter keeps a token bucket for each key
+type Limiter struct {
+	limit config.RateLimit
+	rate  float64 // tokens per second
+	burst float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter creates a limiter whose buckets hold limit.Burst tokens and
+// refill at limit.PerMinute
+func NewLimiter(limit config.RateLimit) *Limiter {
+	return &Limiter{
+		limit:   limit,
+		rate:    limit.PerMinute / 60,
+		burst:   float64(limit.Burst),
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from key's bucket. When the bucket is empty it reports
+// false and how long until the next token.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+}
+
+// Clients returns how many keys have a bucket
+func (l *Limiter) Clients() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// sweep drops buckets that have filled back up, as a new bucket would be the
+// same
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Stats counts the requests a class of endpoints let through and turned away
+// since the server started
+type Stats struct {
+	PerMinute float64 `json:"per_minute"`
+	Burst     int     `json:"burst"`
+	Allowed   int64   `json:"allowed"`
+	Limited   int64   `json:"limited"`
+	Clients   int     `json:"clients"` // clients with a bucket, idle ones are dropped
+}
+
+// Middleware applies the limits to requests
+type Middleware struct {
+	classes map[Class]*classLimit
+	// issued reports whether a token is one the server handed out; API
+	// calls with any other token count against the client address
+	issued func(token string) bool
+}
+
+type classLimit struct {
+	limiter *Limiter
+	allowed atomic.Int64
+	limited atomic.Int64
+}
+
+// New creates the middleware from the rate_limit section of the config file
+func New(cfg config.RateLimitConfig) *Middleware {
+	withDefault := func(limit, fallback config.RateLimit) config.RateLimit {
+		if limit.PerMinute <= 0 {
+			limit.PerMinute = fallback.PerMinute
+		}
+		if limit.Burst <= 0 {
+			limit.Burst = fallback.Burst
+		}
+		return limit
+	}
+
+	return &Middleware{
+		classes: map[Class]*classLimit{
+			ClassLogin: {limiter: NewLimiter(withDefault(cfg.Login, DefaultLogin))},
+			ClassOAuth: {limiter: NewLimiter(withDefault(cfg.OAuth, DefaultOAuth))},
+			ClassAPI:   {limiter: NewLimiter(withDefault(cfg.API, DefaultAPI))},
+		},
+	}
+}
+
+// SetTokenVerifier sets how API calls' tokens are checked before they get a
+// bucket of their own. Until it is set every API call counts against its
+// client address.
+func (m *Middleware) SetTokenVerifier(issued func(token string) bool) {
+	m.issued = issued
+}
+
+// Handler wraps next with the limits
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		class := classify(r.URL.Path)
+		if class == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limit := m.classes[class]
+		allowed, wait := limit.limiter.Allow(m.key(class, r))
+		if !allowed {
+			limit.limited.Add(1)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			apierror.Error(w, "Too many requests, please try again later", http.StatusTooManyRequests)
+			return
+		}
+		limit.allowed.Add(1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Stats returns the counts for each class
+func (m *Middleware) Stats() map[Class]Stats {
+	stats := make(map[Class]Stats, len(m.classes))
+	for class, limit := range m.classes {
+		stats[class] = Stats{
+			PerMinute: limit.limiter.limit.PerMinute,
+			Burst:     limit.limiter.limit.Burst,
+			Allowed:   limit.allowed.Load(),
+			Limited:   limit.limited.Load(),
+			Clients:   limit.limiter.Clients(),
+		}
+	}
+	return stats
+}
+
+func classify(path string) Class {
+	switch {
+	case path == "/auth/login" || path == "/auth/upgrade" || path == "/auth/pin" || strings.HasPrefix(path, "/auth/magic"):
+		return ClassLogin
+	case strings.HasPrefix(path, "/oauth/"):
+		return ClassOAuth
+	case strings.HasPrefix(path, "/api/"):
+		return ClassAPI
+	}
+	return ""
+}
+
+// key picks whose bucket a request draws from. API calls count against the
+// session when there is one, so a household behind one address doesn't share
+// a bucket; the token is hashed rather than kept. A token the server didn't
+// issue counts against the address, or a new one on every request would
+// never run out.
+func (m *Middleware) key(class Class, r *http.Request) string {
+	if class == ClassAPI && m.issued != nil {
+		if token := sessionToken(r); token != "" && m.issued(token) {
+			sum := sha256.Sum256([]byte(token))
+			return "session:" + hex.EncodeToString(sum[:16])
+		}
+	}
+	return "ip:" + middleware.ClientIP(r)
+}
+
+func sessionToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	if cookie, err := r.Cookie("auth_token"); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"famstack/internal/config"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewLimiter(config.RateLimit{PerMinute: 6, Burst: 2})
+	limiter.now = func() time.Time { return now }
+
+	for range 2 {
+		allowed, _ := limiter.Allow("a")
+		assert.True(t, allowed)
+	}
+	allowed, wait := limiter.Allow("a")
+	assert.False(t, allowed)
+	assert.Equal(t, 10*time.Second, wait)
+
+	// Other keys have their own bucket
+	allowed, _ = limiter.Allow("b")
+	assert.True(t, allowed)
+
+	now = now.Add(10 * time.Second)
+	allowed, _ = limiter.Allow("a")
+	assert.True(t, allowed, "a token is back after 10s")
+
+	// Buckets that have filled back up are dropped
+	now = now.Add(2 * time.Minute)
+	limiter.Allow("c")
+	assert.Equal(t, 1, limiter.Clients())
+}
+
+func TestMiddleware(t *testing.T) {
+	limits := New(config.RateLimitConfig{
+		Login: config.RateLimit{PerMinute: 1, Burst: 1},
+		API:   config.RateLimit{PerMinute: 1, Burst: 1},
+	})
+	handler := limits.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func(path string, setup func(r *http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.RemoteAddr = "10.0.0.1:5000"
+		if setup != nil {
+			setup(req)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusNoContent, serve("/auth/login", nil).Code)
+	rec := serve("/auth/login", nil)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+	assert.Contains(t, rec.Body.String(), `"code":"rate_limited"`)
+
+	// Each client address has its own bucket
+	otherClient := func(r *http.Request) { r.RemoteAddr = "192.0.2.7:5000" }
+	assert.Equal(t, http.StatusNoContent, serve("/auth/login", otherClient).Code)
+
+	// API calls count per session
+	limits.SetTokenVerifier(func(token string) bool { return token == "one" || token == "two" })
+	session := func(token string) func(r *http.Request) {
+		return func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "auth_token", Value: token}) }
+	}
+	assert.Equal(t, http.StatusNoContent, serve("/api/v1/tasks", session("one")).Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve("/api/v1/tasks", session("one")).Code)
+	assert.Equal(t, http.StatusNoContent, serve("/api/v1/tasks", session("two")).Code)
+
+	// Made-up tokens share the address's bucket instead of getting one each
+	assert.Equal(t, http.StatusNoContent, serve("/api/v1/tasks", session("forged-1")).Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve("/api/v1/tasks", session("forged-2")).Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve("/api/v1/tasks", nil).Code)
+
+	// Pages aren't limited
+	for range 3 {
+		assert.Equal(t, http.StatusNoContent, serve("/tasks", nil).Code)
+	}
+
+	stats := limits.Stats()
+	require.Contains(t, stats, ClassLogin)
+	assert.Equal(t, Stats{PerMinute: 1, Burst: 1, Allowed: 2, Limited: 1, Clients: 2}, stats[ClassLogin])
+	assert.Equal(t, int64(3), stats[ClassAPI].Limited)
+	assert.Equal(t, DefaultOAuth.PerMinute, stats[ClassOAuth].PerMinute, "zero limits use the default")
+}
@@ -0,0 +1,243 @@
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/eventbus"
+	"famstack/internal/models"
+)
+
+// pingInterval is how often the hub pings an idle client, so proxies don't
+// close the connection and dead clients are noticed
+const pingInterval = 30 * time.Second
+
+// sendBuffer is how many messages a client may fall behind by before it's
+// told to resync instead
+const sendBuffer = 32
+
+// TaskStore saves task moves; *services.TasksService is one
+type TaskStore interface {
+	GetFamilyTask(familyID, taskID string) (*models.Task, error)
+	UpdateTask(familyID, taskID string, req *models.UpdateTaskRequest) (*models.Task, error)
+}
+
+// Hub keeps a room of WebSocket clients per family. Changes published on the
+// event bus go to the family's room, and task moves from one client go to
+// the rest of it.
+type Hub struct {
+	tasks TaskStore
+
+	mu    sync.RWMutex
+	rooms map[string]map[*client]struct{}
+}
+
+type client struct {
+	conn    *Conn
+	session *auth.Session
+	topics  map[eventbus.Topic]bool // readable topics; board messages need tasks
+	canMove bool
+	send    chan Message
+	missed  atomic.Bool
+	done    chan struct{}
+}
+
+// NewHub creates a hub forwarding the bus's changes to connected clients
+func NewHub(events *eventbus.Bus, tasks TaskStore) *Hub {
+	h := &Hub{
+		tasks: tasks,
+		rooms: make(map[string]map[*client]struct{}),
+	}
+	topics := make([]eventbus.Topic, 0, len(TopicEntities))
+	for topic := range TopicEntities {
+		topics = append(topics, topic)
+	}
+	events.Subscribe(func(event eventbus.Event) {
+		h.broadcast(event.FamilyID, event.Topic, MessageFor(event), nil)
+	}, topics...)
+	return h
+}
+
+// Serve runs a client's connection in its family's room until it closes
+func (h *Hub) Serve(conn *Conn, session *auth.Session) {
+	c := &client{
+		conn:    conn,
+		session: session,
+		topics:  make(map[eventbus.Topic]bool),
+		canMove: auth.NewAuthorizationService(session).HasPermission(auth.EntityTask, auth.ActionUpdate, nil),
+		send:    make(chan Message, sendBuffer),
+		done:    make(chan struct{}),
+	}
+	for _, topic := range ReadableTopics(session) {
+		c.topics[topic] = true
+	}
+
+	h.join(c)
+	defer func() {
+		h.leave(c)
+		close(c.done)
+		conn.Close() // nolint:errcheck
+	}()
+	go c.writeLoop()
+
+	for {
+		data, err := conn.ReadMessage()
+		if err != nil {
+			if !errors.Is(err, ErrClosed) {
+				log.Printf("realtime: dropping client %s: %v", session.UserID, err)
+			}
+			return
+		}
+
+		var message Message
+		if err := json.Unmarshal(data, &message); err != nil {
+			c.queue(Message{Type: TypeError, Error: "invalid JSON"})
+			continue
+		}
+		h.handle(c, message)
+	}
+}
+
+// handle acts on a message from a client
+func (h *Hub) handle(c *client, message Message) {
+	familyID, memberID := c.session.FamilyID, c.session.UserID
+
+	switch message.Type {
+	case TypeTaskDrag, TypeTaskCancel:
+		if message.ID == "" {
+			c.queue(Message{Type: TypeError, Error: "id is required"})
+			return
+		}
+		h.broadcast(familyID, eventbus.TopicTasksChanged, Message{Type: message.Type, ID: message.ID, MemberID: memberID}, c)
+	case TypeTaskDrop:
+		moved, err := h.moveTask(c, message)
+		if err != nil {
+			c.queue(Message{Type: TypeError, ID: message.ID, Error: err.Error()})
+			return
+		}
+		h.broadcast(familyID, eventbus.TopicTasksChanged, Message{
+			Type:       TypeTaskMoved,
+			ID:         moved.ID,
+			MemberID:   memberID,
+			AssignedTo: message.AssignedTo,
+			Status:     string(moved.Status),
+		}, c)
+	default:
+		c.queue(Message{Type: TypeError, Error: "unknown message type"})
+	}
+}
+
+// moveTask saves a dropped task's new assignee and status. Either may be
+// left out; an empty assignee unassigns the task.
+func (h *Hub) moveTask(c *client, message Message) (*models.Task, error) {
+	if !c.canMove {
+		return nil, errors.New("insufficient permissions")
+	}
+	if message.ID == "" {
+		return nil, errors.New("id is required")
+	}
+
+	if _, err := h.tasks.GetFamilyTask(c.session.FamilyID, message.ID); err != nil {
+		return nil, errors.New("task not found")
+	}
+
+	req := &models.UpdateTaskRequest{AssignedTo: message.AssignedTo}
+	if message.Status != "" {
+		status, err := models.ParseTaskStatus(message.Status)
+		if err != nil {
+			return nil, err
+		}
+		req.Status = &status
+	}
+	if req.AssignedTo == nil && req.Status == nil {
+		return nil, errors.New("assigned_to or status is required")
+	}
+
+	moved, err := h.tasks.UpdateTask(c.session.FamilyID, message.ID, req)
+	if err != nil {
+		log.Printf("realtime: failed to move task %s: %v", message.ID, err)
+		return nil, errors.New("failed to move task")
+	}
+	return moved, nil
+}
+
+func (h *Hub) join(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	room := h.rooms[c.session.FamilyID]
+	if room == nil {
+		room = make(map[*client]struct{})
+		h.rooms[c.session.FamilyID] = room
+	}
+	room[c] = struct{}{}
+}
+
+func (h *Hub) leave(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	room := h.rooms[c.session.FamilyID]
+	delete(room, c)
+	if len(room) == 0 {
+		delete(h.rooms, c.session.FamilyID)
+	}
+}
+
+// broadcast queues a message for the family's clients that can read the
+// topic, except the one it came from
+func (h *Hub) broadcast(familyID string, topic eventbus.Topic, message Message, except *client) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for c := range h.rooms[familyID] {
+		if c != except && c.topics[topic] {
+			c.queue(message)
+		}
+	}
+}
+
+// queue hands a message to the client's writer without waiting on it
+func (c *client) queue(message Message) {
+	select {
+	case c.send <- message:
+	default:
+		c.missed.Store(true)
+	}
+}
+
+// writeLoop sends queued messages and pings until the client leaves. A
+// failed write closes the connection, which ends Serve's read.
+func (c *client) writeLoop() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		var err error
+		select {
+		case <-c.done:
+			return
+		case message := <-c.send:
+			err = c.write(message)
+			if err == nil && c.missed.Swap(false) {
+				err = c.write(Message{Type: TypeResync})
+			}
+		case <-ticker.C:
+			err = c.conn.Ping()
+		}
+		if err != nil {
+			c.conn.Close() // nolint:errcheck
+			return
+		}
+	}
+}
+
+func (c *client) write(message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return c.conn.WriteMessage(data)
+}
@@ -0,0 +1,218 @@
+package realtime
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/eventbus"
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// The example from RFC 6455 1.3
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", acceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+func TestMessageFor(t *testing.T) {
+	assert.Equal(t, Message{Type: "task.completed", ID: "task_1"},
+		MessageFor(eventbus.Event{Topic: eventbus.TopicTasksChanged, FamilyID: "fam_1", SubjectID: "task_1", Detail: eventbus.DetailCompleted}))
+	assert.Equal(t, Message{Type: TypeTasksChanged},
+		MessageFor(eventbus.Event{Topic: eventbus.TopicTasksChanged, FamilyID: "fam_1"}))
+	assert.Equal(t, Message{Type: TypeSyncStatus, MemberID: "member_1", Status: "success"},
+		MessageFor(eventbus.Event{Topic: eventbus.TopicSyncStatus, FamilyID: "fam_1", SubjectID: "member_1", Detail: "success"}))
+	assert.Equal(t, Message{Type: TypeCalendarChanged},
+		MessageFor(eventbus.Event{Topic: eventbus.TopicCalendarChanged, FamilyID: "fam_1"}))
+}
+
+func TestUpgradeRefusesOtherSites(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = Upgrade(w, r) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Origin", "https://elsewhere.example")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+// fakeTasks is a TaskStore holding tasks in memory
+type fakeTasks map[string]*models.Task
+
+func (f fakeTasks) GetFamilyTask(familyID, taskID string) (*models.Task, error) {
+	task, ok := f[taskID]
+	if !ok || task.FamilyID != familyID {
+		return nil, errors.New("task not found")
+	}
+	return task, nil
+}
+
+func (f fakeTasks) UpdateTask(familyID, taskID string, req *models.UpdateTaskRequest) (*models.Task, error) {
+	task := f[taskID]
+	if req.AssignedTo != nil {
+		task.AssignedTo = req.AssignedTo
+	}
+	if req.Status != nil {
+		task.Status = *req.Status
+	}
+	return task, nil
+}
+
+func TestHubRelaysTaskMovesWithinAFamily(t *testing.T) {
+	bus := eventbus.New()
+	tasks := fakeTasks{
+		"task_1": {ID: "task_1", FamilyID: "fam_1", Status: models.TaskStatusPending},
+		"task_2": {ID: "task_2", FamilyID: "fam_2", Status: models.TaskStatusPending},
+	}
+	hub := NewHub(bus, tasks)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := &auth.Session{UserID: r.URL.Query().Get("member"), FamilyID: r.URL.Query().Get("family"), Role: auth.RoleAdmin}
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		hub.Serve(conn, session)
+	}))
+	defer server.Close()
+
+	mom := dialTestClient(t, server, "fam_1", "mom")
+	dad := dialTestClient(t, server, "fam_1", "dad")
+	other := dialTestClient(t, server, "fam_2", "other")
+	waitForRoom(t, hub, "fam_1", 2)
+	waitForRoom(t, hub, "fam_2", 1)
+
+	mom.send(t, `{"type":"task.drag","id":"task_1"}`)
+	assert.Equal(t, Message{Type: TypeTaskDrag, ID: "task_1", MemberID: "mom"}, dad.receive(t))
+
+	mom.send(t, `{"type":"task.drop","id":"task_1","assigned_to":"dad","status":"completed"}`)
+	assignee := "dad"
+	assert.Equal(t, Message{Type: TypeTaskMoved, ID: "task_1", MemberID: "mom", AssignedTo: &assignee, Status: "completed"}, dad.receive(t))
+	assert.Equal(t, "dad", *tasks["task_1"].AssignedTo)
+
+	// Another family's task can't be moved
+	mom.send(t, `{"type":"task.drop","id":"task_2","status":"completed"}`)
+	assert.Equal(t, Message{Type: TypeError, ID: "task_2", Error: "task not found"}, mom.receive(t))
+	assert.Equal(t, models.TaskStatusPending, tasks["task_2"].Status)
+
+	// Bus changes reach everyone in the family, and no one else
+	bus.Publish(eventbus.Event{Topic: eventbus.TopicCalendarChanged, FamilyID: "fam_1"})
+	assert.Equal(t, Message{Type: TypeCalendarChanged}, mom.receive(t))
+	assert.Equal(t, Message{Type: TypeCalendarChanged}, dad.receive(t))
+	bus.Publish(eventbus.Event{Topic: eventbus.TopicCalendarChanged, FamilyID: "fam_2"})
+	assert.Equal(t, Message{Type: TypeCalendarChanged}, other.receive(t), "other saw nothing of fam_1")
+
+	mom.close(t)
+	waitForRoom(t, hub, "fam_1", 1)
+}
+
+// waitForRoom waits until the family's room has the number of clients
+func waitForRoom(t *testing.T, hub *Hub, familyID string, clients int) {
+	require.Eventually(t, func() bool {
+		hub.mu.RLock()
+		defer hub.mu.RUnlock()
+		return len(hub.rooms[familyID]) == clients
+	}, time.Second, 5*time.Millisecond)
+}
+
+// testClient is the browser side of a WebSocket, as much as the tests need
+type testClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialTestClient(t *testing.T, server *httptest.Server, familyID, memberID string) *testClient {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() }) // nolint:errcheck
+
+	var nonce [16]byte
+	_, err = rand.Read(nonce[:])
+	require.NoError(t, err)
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+
+	req, err := http.NewRequestWithContext(context.Background(), "GET", server.URL+"/?family="+familyID+"&member="+memberID, nil)
+	require.NoError(t, err)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	require.NoError(t, req.Write(conn))
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	require.Equal(t, acceptKey(key), resp.Header.Get("Sec-WebSocket-Accept"))
+
+	return &testClient{conn: conn, reader: reader}
+}
+
+// send writes a masked text frame
+func (c *testClient) send(t *testing.T, text string) {
+	c.writeFrame(t, opText, []byte(text))
+}
+
+func (c *testClient) writeFrame(t *testing.T, opcode byte, payload []byte) {
+	mask := [4]byte{1, 2, 3, 4}
+	frame := []byte{0x80 | opcode, 0x80 | byte(len(payload))}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := c.conn.Write(frame)
+	require.NoError(t, err)
+}
+
+// receive reads the next text message, skipping pings
+func (c *testClient) receive(t *testing.T) Message {
+	require.NoError(t, c.conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	for {
+		var header [2]byte
+		_, err := io.ReadFull(c.reader, header[:])
+		require.NoError(t, err)
+		length := int(header[1] & 0x7F)
+		if length == 126 {
+			var extended [2]byte
+			_, err = io.ReadFull(c.reader, extended[:])
+			require.NoError(t, err)
+			length = int(binary.BigEndian.Uint16(extended[:]))
+		}
+		payload := make([]byte, length)
+		_, err = io.ReadFull(c.reader, payload)
+		require.NoError(t, err)
+		if header[0]&0x0F != opText {
+			continue
+		}
+
+		var message Message
+		require.NoError(t, json.Unmarshal(payload, &message))
+		return message
+	}
+}
+
+func (c *testClient) close(t *testing.T) {
+	c.writeFrame(t, opClose, binary.BigEndian.AppendUint16(nil, closeNormal))
+}
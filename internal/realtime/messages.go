@@ -0,0 +1,77 @@
+// Package realtime pushes changes to open family dashboards as they happen,
+// over server-sent events or a WebSocket, and relays task moves between
+// members editing the board at the same time.
+package realtime
+
+import (
+	"famstack/internal/auth"
+	"famstack/internal/eventbus"
+)
+
+// Message types sent to clients when data changes. Task types are "task."
+// followed by one of the eventbus Detail constants.
+const (
+	TypeTasksChanged    = "tasks.changed" // many tasks at once; reload them all
+	TypeCalendarChanged = "calendar.changed"
+	TypeSyncStatus      = "sync.status"
+	TypeResync          = "resync" // the client fell behind and missed some; reload everything
+)
+
+// Message types for moving tasks on the board over a WebSocket. A client
+// sends task.drag when a member picks a task up, then task.drop to move it or
+// task.cancel to put it back. Drags and cancels are passed on to the rest of
+// the family; a drop is saved and passed on as task.moved, or answered with
+// an error.
+const (
+	TypeTaskDrag   = "task.drag"
+	TypeTaskDrop   = "task.drop"
+	TypeTaskCancel = "task.cancel"
+	TypeTaskMoved  = "task.moved"
+	TypeError      = "error"
+)
+
+// TopicEntities maps each topic forwarded to clients to the entity a member
+// needs read access to for it
+var TopicEntities = map[eventbus.Topic]auth.Entity{
+	eventbus.TopicTasksChanged:    auth.EntityTask,
+	eventbus.TopicCalendarChanged: auth.EntityCalendar,
+	eventbus.TopicSyncStatus:      auth.EntityCalendar,
+}
+
+// ReadableTopics returns the forwarded topics the session may read
+func ReadableTopics(session *auth.Session) []eventbus.Topic {
+	authorization := auth.NewAuthorizationService(session)
+	var topics []eventbus.Topic
+	for topic, entity := range TopicEntities {
+		if authorization.HasPermission(entity, auth.ActionRead, nil) {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+// Message is one update sent to or from a client. It says what changed, not
+// what it changed to; clients fetch what they show.
+type Message struct {
+	Type       string  `json:"type"`
+	ID         string  `json:"id,omitempty"`          // the task, for task messages
+	MemberID   string  `json:"member_id,omitempty"`   // who is syncing, or moving a task
+	Status     string  `json:"status,omitempty"`      // a sync's status, or where a task was dropped
+	AssignedTo *string `json:"assigned_to,omitempty"` // who a task was dropped on; empty for no one
+	Error      string  `json:"error,omitempty"`
+}
+
+// MessageFor describes a published event for clients
+func MessageFor(event eventbus.Event) Message {
+	switch event.Topic {
+	case eventbus.TopicTasksChanged:
+		if event.Detail == "" {
+			return Message{Type: TypeTasksChanged}
+		}
+		return Message{Type: "task." + event.Detail, ID: event.SubjectID}
+	case eventbus.TopicSyncStatus:
+		return Message{Type: TypeSyncStatus, MemberID: event.SubjectID, Status: event.Detail}
+	default:
+		return Message{Type: string(event.Topic)}
+	}
+}
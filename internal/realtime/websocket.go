@@ -0,0 +1,295 @@
+package realtime
+
+import (
+	"bufio"
+	"crypto/sha1" // nolint:gosec // required by the WebSocket handshake, not used for security
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxMessageSize bounds a message a client may send; board edits are small
+const MaxMessageSize = 64 * 1024
+
+// handshakeGUID is appended to the client's key in the handshake (RFC 6455 1.3)
+const handshakeGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Frame opcodes
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// Close status codes
+const (
+	closeNormal      = 1000
+	closeGoingAway   = 1001
+	closeProtocol    = 1002
+	closeUnsupported = 1003
+	closeTooBig      = 1009
+)
+
+// ErrClosed is returned by ReadMessage once the client closed the connection
+var ErrClosed = errors.New("websocket closed")
+
+// Conn is the server side of a WebSocket connection carrying text messages.
+// One goroutine may read while others write.
+type Conn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+
+	writeMu sync.Mutex
+	closed  bool
+}
+
+// Upgrade completes the WebSocket handshake for a request. Requests from a
+// page on another site are refused, so a session cookie can't be borrowed
+// by one.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return nil, errors.New("websocket: method not GET")
+	}
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "WebSocket upgrade required", http.StatusUpgradeRequired)
+		return nil, errors.New("websocket: not an upgrade request")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "Unsupported WebSocket version", http.StatusBadRequest)
+		return nil, errors.New("websocket: unsupported version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		http.Error(w, "Invalid Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errors.New("websocket: invalid key")
+	}
+	if !sameOrigin(r) {
+		http.Error(w, "Cross-origin WebSocket requests are not allowed", http.StatusForbidden)
+		return nil, errors.New("websocket: cross-origin request")
+	}
+
+	netConn, buffered, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "WebSocket not supported", http.StatusInternalServerError)
+		return nil, fmt.Errorf("websocket: failed to hijack connection: %w", err)
+	}
+	// The server's timeouts are meant for ordinary requests
+	if err := netConn.SetDeadline(time.Time{}); err != nil {
+		netConn.Close() // nolint:errcheck
+		return nil, fmt.Errorf("websocket: failed to clear deadline: %w", err)
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	if _, err := buffered.WriteString(response); err != nil {
+		netConn.Close() // nolint:errcheck
+		return nil, fmt.Errorf("websocket: failed to write handshake: %w", err)
+	}
+	if err := buffered.Flush(); err != nil {
+		netConn.Close() // nolint:errcheck
+		return nil, fmt.Errorf("websocket: failed to write handshake: %w", err)
+	}
+
+	return &Conn{conn: netConn, reader: buffered.Reader}, nil
+}
+
+// acceptKey is the Sec-WebSocket-Accept value for a client's key
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + handshakeGUID)) // nolint:gosec
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// sameOrigin reports whether the request has no Origin, as from a native
+// client, or one on the host it was sent to
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(parsed.Host, r.Host)
+}
+
+// headerContains reports whether a comma-separated header has the token
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadMessage returns the next text message, answering pings as it goes.
+// It returns ErrClosed once the client closes the connection.
+func (c *Conn) ReadMessage() ([]byte, error) {
+	var message []byte
+	inMessage := false
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+		case opPong:
+		case opClose:
+			c.closeWith(closeNormal)
+			return nil, ErrClosed
+		case opBinary:
+			c.closeWith(closeUnsupported)
+			return nil, errors.New("websocket: binary messages are not supported")
+		case opText, opContinuation:
+			if (opcode == opText) == inMessage {
+				c.closeWith(closeProtocol)
+				return nil, errors.New("websocket: unexpected continuation frame")
+			}
+			inMessage = true
+			if len(message)+len(payload) > MaxMessageSize {
+				c.closeWith(closeTooBig)
+				return nil, errors.New("websocket: message too big")
+			}
+			message = append(message, payload...)
+			if fin {
+				return message, nil
+			}
+		default:
+			c.closeWith(closeProtocol)
+			return nil, fmt.Errorf("websocket: unknown opcode %d", opcode)
+		}
+	}
+}
+
+// readFrame reads one frame and unmasks its payload. Clients must mask
+// every frame (RFC 6455 5.1).
+func (c *Conn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(c.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	if header[0]&0x70 != 0 {
+		c.closeWith(closeProtocol)
+		return false, 0, nil, errors.New("websocket: reserved bits set")
+	}
+	if header[1]&0x80 == 0 {
+		c.closeWith(closeProtocol)
+		return false, 0, nil, errors.New("websocket: client frame not masked")
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err = io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err = io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	if opcode >= opClose && (length > 125 || !fin) {
+		c.closeWith(closeProtocol)
+		return false, 0, nil, errors.New("websocket: invalid control frame")
+	}
+	if length > MaxMessageSize {
+		c.closeWith(closeTooBig)
+		return false, 0, nil, errors.New("websocket: frame too big")
+	}
+
+	var mask [4]byte
+	if _, err = io.ReadFull(c.reader, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, opcode, payload, nil
+}
+
+// WriteMessage sends a text message
+func (c *Conn) WriteMessage(data []byte) error {
+	return c.writeFrame(opText, data)
+}
+
+// Ping sends a ping, which browsers answer without involving the page
+func (c *Conn) Ping() error {
+	return c.writeFrame(opPing, nil)
+}
+
+// writeFrame sends one unfragmented, unmasked frame
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+
+	frame := make([]byte, 0, len(payload)+10)
+	frame = append(frame, 0x80|opcode)
+	switch length := len(payload); {
+	case length <= 125:
+		frame = append(frame, byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(length))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+	}
+	frame = append(frame, payload...)
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// closeWith sends a close frame with the status code, ignoring failures
+// since the connection is going away regardless
+func (c *Conn) closeWith(code uint16) {
+	c.writeFrame(opClose, binary.BigEndian.AppendUint16(nil, code)) // nolint:errcheck
+	c.writeMu.Lock()
+	c.closed = true
+	c.writeMu.Unlock()
+}
+
+// Close tells the client the server is going away and closes the connection
+func (c *Conn) Close() error {
+	c.closeWith(closeGoingAway)
+	return c.conn.Close()
+}
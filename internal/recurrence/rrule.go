@@ -0,0 +1,512 @@
+// Package recurrence parses and expands the subset of RFC 5545 recurrence
+// rules FamStack supports for calendar events: FREQ=DAILY, WEEKLY, MONTHLY or
+// YEARLY with INTERVAL, COUNT or UNTIL, BYDAY and BYMONTHDAY. Occurrences keep the
+// wall-clock time of the series start in its location, so a 5pm practice
+// stays at 5pm across daylight saving changes.
+//
+// A series is stored once, with its rule and a list of exception dates
+// (occurrences that were deleted, or replaced by an edited copy). Expanded
+// occurrences are addressed by instance IDs that combine the series ID with
+// the occurrence's original start, see InstanceID.
+package recurrence
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is how often a rule repeats
+type Frequency string
+
+// Supported frequencies
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// Limits on rules, so a single series can't expand without bound
+const (
+	MaxInterval = 999
+	MaxCount    = 1000
+	// maxPeriods bounds how many days, weeks, months or years an expansion walks
+	maxPeriods = 20000
+)
+
+// UTCLayout is the iCalendar UTC date-time form used for exception dates and
+// instance IDs
+const UTCLayout = "20060102T150405Z"
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// WeekdayNum is a BYDAY entry: a weekday, and for monthly rules an optional
+// position within the month (1 = first, -1 = last, 0 = every)
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+// Rule is a parsed recurrence rule
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int       // 0 means no count limit
+	Until      time.Time // zero means no end date; inclusive
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+}
+
+// Parse reads an RRULE value such as "FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20251220T000000Z".
+// A leading "RRULE:" is accepted.
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimSpace(value)
+	if len(value) >= 6 && strings.EqualFold(value[:6], "RRULE:") {
+		value = value[6:]
+	}
+	if value == "" {
+		return nil, fmt.Errorf("recurrence rule is empty")
+	}
+
+	rule := &Rule{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		name, arg, ok := strings.Cut(part, "=")
+		if !ok || arg == "" {
+			return nil, fmt.Errorf("malformed recurrence rule part %q", part)
+		}
+		name = strings.ToUpper(name)
+		arg = strings.ToUpper(arg)
+
+		switch name {
+		case "FREQ":
+			rule.Freq = Frequency(arg)
+			if rule.Freq != Daily && rule.Freq != Weekly && rule.Freq != Monthly && rule.Freq != Yearly {
+				return nil, fmt.Errorf("unsupported frequency %s (expected DAILY, WEEKLY, MONTHLY or YEARLY)", arg)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(arg)
+			if err != nil || interval < 1 || interval > MaxInterval {
+				return nil, fmt.Errorf("INTERVAL must be between 1 and %d", MaxInterval)
+			}
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(arg)
+			if err != nil || count < 1 || count > MaxCount {
+				return nil, fmt.Errorf("COUNT must be between 1 and %d", MaxCount)
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, err := parseUntil(arg)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = until
+		case "BYDAY":
+			for _, code := range strings.Split(arg, ",") {
+				day, err := parseWeekdayNum(code)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			for _, text := range strings.Split(arg, ",") {
+				day, err := strconv.Atoi(text)
+				if err != nil || day == 0 || day < -31 || day > 31 {
+					return nil, fmt.Errorf("invalid BYMONTHDAY %q", text)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, day)
+			}
+		case "WKST":
+			if _, ok := weekdayCodes[arg]; !ok {
+				return nil, fmt.Errorf("invalid WKST %q", arg)
+			}
+			// Weeks always start on Monday here; WKST only changes the result
+			// of rare biweekly rules, so it is accepted and ignored
+		default:
+			return nil, fmt.Errorf("unsupported recurrence rule part %s", name)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("recurrence rule has no FREQ")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, fmt.Errorf("recurrence rule can't have both COUNT and UNTIL")
+	}
+	if len(rule.ByMonthDay) > 0 && rule.Freq != Monthly {
+		return nil, fmt.Errorf("BYMONTHDAY is only supported for MONTHLY rules")
+	}
+	if len(rule.ByDay) > 0 && rule.Freq == Yearly {
+		return nil, fmt.Errorf("BYDAY is not supported for YEARLY rules")
+	}
+	for _, day := range rule.ByDay {
+		if day.N != 0 && rule.Freq != Monthly {
+			return nil, fmt.Errorf("numbered BYDAY values are only supported for MONTHLY rules")
+		}
+	}
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	if len(value) == len("20060102") {
+		t, err := time.Parse("20060102", value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid UNTIL %q", value)
+		}
+		// A date includes the whole day
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	t, err := time.Parse(UTCLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid UNTIL %q (expected a date or a UTC date-time)", value)
+	}
+	return t, nil
+}
+
+func parseWeekdayNum(code string) (WeekdayNum, error) {
+	if len(code) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", code)
+	}
+	day, ok := weekdayCodes[code[len(code)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", code)
+	}
+	n := 0
+	if prefix := code[:len(code)-2]; prefix != "" {
+		var err error
+		n, err = strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", code)
+		}
+	}
+	return WeekdayNum{N: n, Day: day}, nil
+}
+
+// String formats the rule as an RRULE value, parts in a fixed order
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(UTCLayout))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			codes[i] = weekdayCode(day.Day)
+			if day.N != 0 {
+				codes[i] = strconv.Itoa(day.N) + codes[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+func weekdayCode(day time.Weekday) string {
+	for code, weekday := range weekdayCodes {
+		if weekday == day {
+			return code
+		}
+	}
+	return ""
+}
+
+// EndingBefore returns a copy of the rule that stops before t, for splitting
+// a series in two. A COUNT becomes the equivalent UNTIL.
+func (r *Rule) EndingBefore(t time.Time) *Rule {
+	ended := *r
+	ended.Count = 0
+	ended.Until = t.Add(-time.Second).UTC()
+	return &ended
+}
+
+// Remaining returns a copy of a rule for the part of its series from t on,
+// whose first occurrence is t. COUNT is reduced by the occurrences before t.
+func (r *Rule) Remaining(dtstart, t time.Time) *Rule {
+	rest := *r
+	if r.Count > 0 {
+		rest.Count = max(r.Count-len(r.Between(dtstart, dtstart, t)), 1)
+	}
+	return &rest
+}
+
+// Shifted returns a copy of the rule for a series whose start moved from
+// `from` to `to`. Weekdays and days of the month the rule names move with the
+// start, so a weekly Wednesday series moved to a Thursday repeats on Thursdays.
+func (r *Rule) Shifted(from, to time.Time) *Rule {
+	shifted := *r
+	days := civilDays(from, to)
+	if days == 0 {
+		return &shifted
+	}
+
+	if len(r.ByDay) > 0 {
+		shifted.ByDay = make([]WeekdayNum, len(r.ByDay))
+		for i, day := range r.ByDay {
+			shifted.ByDay[i] = WeekdayNum{N: day.N, Day: time.Weekday(((int(day.Day)+days)%7 + 7) % 7)}
+		}
+	}
+	if len(r.ByMonthDay) > 0 {
+		shifted.ByMonthDay = make([]int, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			if day == from.Day() {
+				day = to.Day()
+			}
+			shifted.ByMonthDay[i] = day
+		}
+	}
+	return &shifted
+}
+
+// civilDays counts the calendar days from a's date to b's, each in its own location
+func civilDays(a, b time.Time) int {
+	dayA := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	dayB := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(dayB.Sub(dayA) / (24 * time.Hour))
+}
+
+// Between returns the start of every occurrence of the series starting at
+// dtstart that falls in [from, to), in dtstart's location. dtstart itself is
+// always the first occurrence, whether or not it matches the rule.
+func (r *Rule) Between(dtstart, from, to time.Time) []time.Time {
+	var occurrences []time.Time
+	r.each(dtstart, to, func(t time.Time) {
+		if !t.Before(from) {
+			occurrences = append(occurrences, t)
+		}
+	})
+	return occurrences
+}
+
+// Includes reports whether t is an occurrence of the series starting at dtstart
+func (r *Rule) Includes(dtstart, t time.Time) bool {
+	for _, occurrence := range r.Between(dtstart, t, t.Add(time.Second)) {
+		if occurrence.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// each calls fn with every occurrence before to, in order
+func (r *Rule) each(dtstart, to time.Time, fn func(time.Time)) {
+	interval := max(r.Interval, 1)
+	emitted := 0
+	emit := func(t time.Time) bool {
+		if !t.Before(to) || (!r.Until.IsZero() && t.After(r.Until)) || (r.Count > 0 && emitted >= r.Count) {
+			return false
+		}
+		emitted++
+		fn(t)
+		return true
+	}
+
+	if !emit(dtstart) {
+		return
+	}
+
+	for period := 0; period < maxPeriods; period++ {
+		candidates := r.candidates(dtstart, period*interval)
+		if len(candidates) == 0 {
+			continue
+		}
+		for _, t := range candidates {
+			if !t.After(dtstart) {
+				continue
+			}
+			if !emit(t) {
+				return
+			}
+		}
+	}
+}
+
+// candidates returns the occurrences the rule gives in the period offset
+// days, weeks, months or years after dtstart's, in order
+func (r *Rule) candidates(dtstart time.Time, offset int) []time.Time {
+	loc := dtstart.Location()
+	hour, minute, second := dtstart.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, minute, second, dtstart.Nanosecond(), loc)
+	}
+
+	switch r.Freq {
+	case Daily:
+		day := at(dtstart.Year(), dtstart.Month(), dtstart.Day()+offset)
+		if len(r.ByDay) > 0 && !r.hasWeekday(day.Weekday()) {
+			return nil
+		}
+		return []time.Time{day}
+
+	case Weekly:
+		// Weeks run Monday to Sunday
+		fromMonday := (int(dtstart.Weekday()) + 6) % 7
+		monday := at(dtstart.Year(), dtstart.Month(), dtstart.Day()-fromMonday+7*offset)
+		days := []time.Weekday{dtstart.Weekday()}
+		if len(r.ByDay) > 0 {
+			days = days[:0]
+			for _, day := range r.ByDay {
+				days = append(days, day.Day)
+			}
+		}
+		var out []time.Time
+		for _, day := range days {
+			out = append(out, at(monday.Year(), monday.Month(), monday.Day()+(int(day)+6)%7))
+		}
+		sortTimes(out)
+		return dedupe(out)
+
+	case Monthly:
+		first := time.Date(dtstart.Year(), dtstart.Month()+time.Month(offset), 1, 0, 0, 0, 0, loc)
+		year, month := first.Year(), first.Month()
+		daysIn := time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+
+		var days []int
+		switch {
+		case len(r.ByMonthDay) > 0:
+			for _, day := range r.ByMonthDay {
+				if day < 0 {
+					day = daysIn + day + 1
+				}
+				if day >= 1 && day <= daysIn && (len(r.ByDay) == 0 || r.matchesByDay(year, month, day, daysIn)) {
+					days = append(days, day)
+				}
+			}
+		case len(r.ByDay) > 0:
+			for day := 1; day <= daysIn; day++ {
+				if r.matchesByDay(year, month, day, daysIn) {
+					days = append(days, day)
+				}
+			}
+		default:
+			// Months without the start's day are skipped, as RFC 5545 requires
+			if dtstart.Day() <= daysIn {
+				days = append(days, dtstart.Day())
+			}
+		}
+
+		out := make([]time.Time, 0, len(days))
+		for _, day := range days {
+			out = append(out, at(year, month, day))
+		}
+		sortTimes(out)
+		return dedupe(out)
+
+	case Yearly:
+		// A February 29 start only recurs in leap years, as RFC 5545 requires
+		day := at(dtstart.Year()+offset, dtstart.Month(), dtstart.Day())
+		if day.Day() != dtstart.Day() {
+			return nil
+		}
+		return []time.Time{day}
+	}
+	return nil
+}
+
+func (r *Rule) hasWeekday(day time.Weekday) bool {
+	for _, byDay := range r.ByDay {
+		if byDay.Day == day {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesByDay reports whether a day of the month matches a BYDAY entry,
+// taking positions like 2TU (second Tuesday) and -1FR (last Friday) into account
+func (r *Rule) matchesByDay(year int, month time.Month, day, daysIn int) bool {
+	weekday := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday()
+	for _, byDay := range r.ByDay {
+		if byDay.Day != weekday {
+			continue
+		}
+		switch {
+		case byDay.N == 0:
+			return true
+		case byDay.N > 0 && (day-1)/7+1 == byDay.N:
+			return true
+		case byDay.N < 0 && (daysIn-day)/7+1 == -byDay.N:
+			return true
+		}
+	}
+	return false
+}
+
+func sortTimes(times []time.Time) {
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+}
+
+func dedupe(sorted []time.Time) []time.Time {
+	out := sorted[:0]
+	for i, t := range sorted {
+		if i == 0 || !t.Equal(sorted[i-1]) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// FormatTimes writes exception dates as a comma-separated list of UTC
+// date-times, the form of an iCalendar EXDATE value
+func FormatTimes(times []time.Time) string {
+	values := make([]string, len(times))
+	for i, t := range times {
+		values[i] = t.UTC().Format(UTCLayout)
+	}
+	return strings.Join(values, ",")
+}
+
+// ParseTimes reads a list written by FormatTimes
+func ParseTimes(value string) ([]time.Time, error) {
+	var times []time.Time
+	for _, text := range strings.Split(value, ",") {
+		if text = strings.TrimSpace(text); text == "" {
+			continue
+		}
+		t, err := time.Parse(UTCLayout, text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exception date %q", text)
+		}
+		times = append(times, t)
+	}
+	return times, nil
+}
+
+// InstanceID names one occurrence of a series by the series ID and the
+// occurrence's original start, e.g. "event_1_20250305T170000Z"
+func InstanceID(seriesID string, start time.Time) string {
+	return seriesID + "_" + start.UTC().Format(UTCLayout)
+}
+
+// ParseInstanceID splits an ID made by InstanceID. It reports false for any
+// other ID.
+func ParseInstanceID(id string) (string, time.Time, bool) {
+	cut := strings.LastIndex(id, "_")
+	if cut <= 0 || len(id)-cut-1 != len(UTCLayout) {
+		return "", time.Time{}, false
+	}
+	start, err := time.Parse(UTCLayout, id[cut+1:])
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return id[:cut], start, true
+}
@@ -0,0 +1,141 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustParse(t *testing.T, value string) *Rule {
+	t.Helper()
+	rule, err := Parse(value)
+	require.NoError(t, err, value)
+	return rule
+}
+
+func dates(times []time.Time) []string {
+	out := make([]string, len(times))
+	for i, t := range times {
+		out[i] = t.Format("2006-01-02 15:04")
+	}
+	return out
+}
+
+func TestParse(t *testing.T) {
+	rule := mustParse(t, "RRULE:freq=monthly;interval=2;byday=2TU,-1FR;until=20251231")
+	assert.Equal(t, Monthly, rule.Freq)
+	assert.Equal(t, 2, rule.Interval)
+	assert.Equal(t, []WeekdayNum{{N: 2, Day: time.Tuesday}, {N: -1, Day: time.Friday}}, rule.ByDay)
+	assert.Equal(t, time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC), rule.Until)
+	assert.Equal(t, "FREQ=MONTHLY;INTERVAL=2;UNTIL=20251231T235959Z;BYDAY=2TU,-1FR", rule.String())
+
+	for _, value := range []string{
+		"",
+		"FREQ=HOURLY",
+		"FREQ=YEARLY;BYDAY=MO",
+		"INTERVAL=2",
+		"FREQ=DAILY;COUNT=3;UNTIL=20250101T000000Z",
+		"FREQ=WEEKLY;BYDAY=2MO",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;BYHOUR=9",
+		"FREQ=DAILY;INTERVAL",
+	} {
+		_, err := Parse(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestBetween_Weekly(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// Tuesdays and Thursdays at 5pm across the March daylight saving change
+	rule := mustParse(t, "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=5")
+	start := time.Date(2025, 3, 4, 17, 0, 0, 0, loc)
+	got := rule.Between(start, start, start.AddDate(1, 0, 0))
+	assert.Equal(t, []string{
+		"2025-03-04 17:00", "2025-03-06 17:00", "2025-03-11 17:00", "2025-03-13 17:00", "2025-03-18 17:00",
+	}, dates(got))
+
+	// The count is spent from the series start, not the window
+	got = rule.Between(start, time.Date(2025, 3, 12, 0, 0, 0, 0, loc), start.AddDate(1, 0, 0))
+	assert.Equal(t, []string{"2025-03-13 17:00", "2025-03-18 17:00"}, dates(got))
+
+	biweekly := mustParse(t, "FREQ=WEEKLY;INTERVAL=2")
+	got = biweekly.Between(start, start, start.AddDate(0, 0, 35))
+	assert.Equal(t, []string{"2025-03-04 17:00", "2025-03-18 17:00", "2025-04-01 17:00"}, dates(got))
+}
+
+func TestBetween_DailyUntil(t *testing.T) {
+	rule := mustParse(t, "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;UNTIL=20250310T080000Z")
+	start := time.Date(2025, 3, 6, 8, 0, 0, 0, time.UTC)
+	got := rule.Between(start, start, start.AddDate(0, 1, 0))
+	assert.Equal(t, []string{"2025-03-06 08:00", "2025-03-07 08:00", "2025-03-10 08:00"}, dates(got))
+}
+
+func TestBetween_Monthly(t *testing.T) {
+	start := time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// Months without a 31st are skipped
+	got := mustParse(t, "FREQ=MONTHLY").Between(start, start, end)
+	assert.Equal(t, []string{"2025-01-31 09:00", "2025-03-31 09:00", "2025-05-31 09:00"}, dates(got))
+
+	lastDay := mustParse(t, "FREQ=MONTHLY;BYMONTHDAY=-1").Between(start, start, end)
+	assert.Equal(t, []string{"2025-01-31 09:00", "2025-02-28 09:00", "2025-03-31 09:00", "2025-04-30 09:00", "2025-05-31 09:00"}, dates(lastDay))
+
+	start = time.Date(2025, 1, 14, 19, 0, 0, 0, time.UTC)
+	secondTuesday := mustParse(t, "FREQ=MONTHLY;BYDAY=2TU;COUNT=3").Between(start, start, end)
+	assert.Equal(t, []string{"2025-01-14 19:00", "2025-02-11 19:00", "2025-03-11 19:00"}, dates(secondTuesday))
+}
+
+func TestBetween_Yearly(t *testing.T) {
+	start := time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// February 29 comes round in leap years only
+	got := mustParse(t, "FREQ=YEARLY").Between(start, start, end)
+	assert.Equal(t, []string{"2020-02-29 00:00", "2024-02-29 00:00", "2028-02-29 00:00"}, dates(got))
+
+	start = time.Date(2015, 6, 12, 0, 0, 0, 0, time.UTC)
+	got = mustParse(t, "FREQ=YEARLY;INTERVAL=5").Between(start, time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), end)
+	assert.Equal(t, []string{"2020-06-12 00:00", "2025-06-12 00:00"}, dates(got))
+}
+
+func TestSplitAndShift(t *testing.T) {
+	start := time.Date(2025, 3, 5, 17, 0, 0, 0, time.UTC)
+	rule := mustParse(t, "FREQ=WEEKLY;BYDAY=WE;COUNT=6")
+	split := time.Date(2025, 3, 19, 17, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "FREQ=WEEKLY;UNTIL=20250319T165959Z;BYDAY=WE", rule.EndingBefore(split).String())
+	assert.Equal(t, 4, rule.Remaining(start, split).Count)
+
+	assert.True(t, rule.Includes(start, split))
+	assert.False(t, rule.Includes(start, split.Add(time.Hour)))
+
+	shifted := rule.Shifted(start, start.AddDate(0, 0, 1))
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=6;BYDAY=TH", shifted.String())
+}
+
+func TestInstanceIDsAndTimes(t *testing.T) {
+	occurrence := time.Date(2025, 3, 5, 17, 0, 0, 0, time.UTC)
+	id := InstanceID("unified_event_1_0", occurrence)
+	assert.Equal(t, "unified_event_1_0_20250305T170000Z", id)
+
+	seriesID, start, ok := ParseInstanceID(id)
+	require.True(t, ok)
+	assert.Equal(t, "unified_event_1_0", seriesID)
+	assert.True(t, start.Equal(occurrence))
+
+	_, _, ok = ParseInstanceID("unified_event_1700000000000000000")
+	assert.False(t, ok)
+
+	times, err := ParseTimes(FormatTimes([]time.Time{occurrence, occurrence.AddDate(0, 0, 7)}))
+	require.NoError(t, err)
+	assert.Len(t, times, 2)
+	_, err = ParseTimes("tomorrow")
+	assert.Error(t, err)
+}
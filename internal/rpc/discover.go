@@ -0,0 +1,128 @@
+package rpc
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// MethodDescription describes a method for rpc.discover. Params and Result
+// are JSON Schemas of the values sent and returned.
+type MethodDescription struct {
+	Name         string `json:"name"`
+	Summary      string `json:"summary"`
+	RequiresAuth bool   `json:"requires_auth"`
+	Params       Schema `json:"params"`
+	Result       Schema `json:"result"`
+}
+
+// Schema is a JSON Schema
+type Schema map[string]any
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// describe lists the registered methods
+func (s *Server) describe() []MethodDescription {
+	var descriptions []MethodDescription
+	for _, m := range s.methodList() {
+		descriptions = append(descriptions, MethodDescription{
+			Name:         m.Name,
+			Summary:      m.Summary,
+			RequiresAuth: !m.Public,
+			Params:       schemaOf(m.Params, nil),
+			Result:       schemaOf(m.Result, nil),
+		})
+	}
+	return descriptions
+}
+
+// schemaOf describes the JSON a value of type t encodes to. seen holds the
+// structs being described, so types that contain themselves end rather than
+// recurse forever.
+func schemaOf(t reflect.Type, seen map[reflect.Type]bool) Schema {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t, nullable = t.Elem(), true
+	}
+
+	var schema Schema
+	switch {
+	case t == timeType:
+		schema = Schema{"type": "string", "format": "date-time"}
+	case t == rawMessageType || t.Kind() == reflect.Interface:
+		schema = Schema{}
+	case t.Kind() == reflect.Bool:
+		schema = Schema{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		schema = Schema{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		schema = Schema{"type": "number"}
+	case t.Kind() == reflect.String:
+		schema = Schema{"type": "string"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		schema = Schema{"type": "array", "items": schemaOf(t.Elem(), seen)}
+		nullable = nullable || t.Kind() == reflect.Slice
+	case t.Kind() == reflect.Map:
+		schema = Schema{"type": "object", "additionalProperties": schemaOf(t.Elem(), seen)}
+	case t.Kind() == reflect.Struct:
+		schema = structSchema(t, seen)
+	default:
+		schema = Schema{}
+	}
+	if nullable {
+		schema["nullable"] = true
+	}
+	return schema
+}
+
+// structSchema describes a struct by its JSON fields, embedded structs'
+// fields included
+func structSchema(t reflect.Type, seen map[reflect.Type]bool) Schema {
+	// Generic types are titled without their type arguments
+	title, _, _ := strings.Cut(t.Name(), "[")
+	schema := Schema{"type": "object", "title": title}
+	if seen[t] {
+		return schema
+	}
+	if seen == nil {
+		seen = make(map[reflect.Type]bool)
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	properties := Schema{}
+	var required []string
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := range t.NumField() {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+				continue
+			}
+			name, options, _ := strings.Cut(tag, ",")
+			if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+				addFields(field.Type)
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = schemaOf(field.Type, seen)
+			if strings.Contains(field.Tag.Get("validate"), "required") && !strings.Contains(options, "omitempty") {
+				required = append(required, name)
+			}
+		}
+	}
+	addFields(t)
+
+	schema["properties"] = properties
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
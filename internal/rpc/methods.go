@@ -0,0 +1,161 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+)
+
+// IDParams names one record
+type IDParams struct {
+	ID string `json:"id" validate:"required"`
+}
+
+// PageParams asks for one page of a list; Cursor is the next_cursor of the
+// page before
+type PageParams struct {
+	Limit  int    `json:"limit,omitempty" validate:"omitempty,min=1,max=200"`
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// EventsListParams asks for the events between two times, a page at a time
+type EventsListParams struct {
+	Start time.Time `json:"start" validate:"required"`
+	End   time.Time `json:"end" validate:"required"`
+	PageParams
+}
+
+// TaskUpdateParams changes the fields given of a task
+type TaskUpdateParams struct {
+	ID string `json:"id" validate:"required"`
+	models.UpdateTaskRequest
+}
+
+// NoParams is taken by methods without parameters
+type NoParams struct{}
+
+// NewHandler returns the JSON-RPC API, its methods backed by the services
+func NewHandler(registry *services.Registry, authService *auth.Service) *Server {
+	s := NewServer(authService)
+
+	Register(s, "members.list", "The members of the caller's family",
+		func(ctx context.Context, caller *Caller, _ NoParams) ([]*models.FamilyMember, error) {
+			if err := caller.Require(auth.EntityFamily, auth.ActionRead); err != nil {
+				return nil, err
+			}
+			return registry.FamilyMembers.ListFamilyMembers(caller.FamilyID())
+		})
+
+	Register(s, "tasks.list", "A page of the family's tasks, newest first",
+		func(ctx context.Context, caller *Caller, params PageParams) (*services.Page[models.Task], error) {
+			if err := caller.Require(auth.EntityTask, auth.ActionRead); err != nil {
+				return nil, err
+			}
+			page, err := registry.Tasks.ListTasksPage(caller.FamilyID(), services.PageRequest{Limit: params.Limit, Cursor: params.Cursor})
+			return page, pageError(err)
+		})
+
+	Register(s, "tasks.get", "One task",
+		func(ctx context.Context, caller *Caller, params IDParams) (*models.Task, error) {
+			if err := caller.Require(auth.EntityTask, auth.ActionRead); err != nil {
+				return nil, err
+			}
+			task, err := registry.Tasks.GetFamilyTask(caller.FamilyID(), params.ID)
+			return task, notFound("task", err)
+		})
+
+	Register(s, "tasks.create", "Creates a task",
+		func(ctx context.Context, caller *Caller, params models.CreateTaskRequest) (*models.Task, error) {
+			if err := caller.Require(auth.EntityTask, auth.ActionCreate); err != nil {
+				return nil, err
+			}
+			return registry.Tasks.CreateTask(caller.FamilyID(), caller.Member.ID, &params)
+		})
+
+	Register(s, "tasks.update", "Changes a task, such as marking it completed",
+		func(ctx context.Context, caller *Caller, params TaskUpdateParams) (*models.Task, error) {
+			if err := caller.Require(auth.EntityTask, auth.ActionUpdate); err != nil {
+				return nil, err
+			}
+			task, err := registry.Tasks.UpdateTask(caller.FamilyID(), params.ID, &params.UpdateTaskRequest)
+			return task, notFound("task", err)
+		})
+
+	Register(s, "events.list", "A page of the family's calendar between two times, recurring events expanded, soonest first",
+		func(ctx context.Context, caller *Caller, params EventsListParams) (*services.Page[models.UnifiedCalendarEvent], error) {
+			if err := caller.Require(auth.EntityCalendar, auth.ActionRead); err != nil {
+				return nil, err
+			}
+			if !params.End.After(params.Start) {
+				return nil, Errorf(CodeInvalidParams, "end must be after start")
+			}
+			events, err := registry.Calendar.GetUnifiedCalendarEventsFor(auth.CalendarViewerFor(caller.Session, caller.Member), caller.FamilyID(), params.Start, params.End)
+			if err != nil {
+				return nil, err
+			}
+			page, err := services.PageCalendarEvents(events, services.PageRequest{Limit: params.Limit, Cursor: params.Cursor})
+			return page, pageError(err)
+		})
+
+	Register(s, "events.get", "One calendar event",
+		func(ctx context.Context, caller *Caller, params IDParams) (*models.CalendarEvent, error) {
+			if err := caller.Require(auth.EntityCalendar, auth.ActionRead); err != nil {
+				return nil, err
+			}
+			event, err := registry.Calendar.GetFamilyEvent(caller.FamilyID(), params.ID)
+			return event, notFound("event", err)
+		})
+
+	Register(s, "events.create", "Creates a calendar event",
+		func(ctx context.Context, caller *Caller, params models.CreateCalendarEventRequest) (*models.CalendarEvent, error) {
+			if err := caller.Require(auth.EntityCalendar, auth.ActionCreate); err != nil {
+				return nil, err
+			}
+			return registry.Calendar.CreateEvent(caller.FamilyID(), caller.Member.ID, &params)
+		})
+
+	Register(s, "schedules.list", "The family's recurring task schedules",
+		func(ctx context.Context, caller *Caller, _ NoParams) ([]models.TaskSchedule, error) {
+			if err := caller.Require(auth.EntitySchedule, auth.ActionRead); err != nil {
+				return nil, err
+			}
+			return registry.Schedules.ListSchedules(caller.FamilyID())
+		})
+
+	Register(s, "schedules.get", "One recurring task schedule",
+		func(ctx context.Context, caller *Caller, params IDParams) (*models.TaskSchedule, error) {
+			if err := caller.Require(auth.EntitySchedule, auth.ActionRead); err != nil {
+				return nil, err
+			}
+			schedule, err := registry.Schedules.GetFamilySchedule(caller.FamilyID(), params.ID)
+			return schedule, notFound("schedule", err)
+		})
+
+	RegisterPublic(s, "rpc.discover", "The methods of this API with the shapes of their params and results",
+		func(ctx context.Context, _ *Caller, _ NoParams) ([]MethodDescription, error) {
+			return s.describe(), nil
+		})
+
+	return s
+}
+
+// notFound reports a record missing, or in another family, as not found,
+// and passes other failures on
+func notFound(what string, err error) error {
+	if errors.Is(err, services.ErrNotFound) {
+		return Errorf(CodeNotFound, "%s not found", what)
+	}
+	return err
+}
+
+// pageError reports a cursor that can't be read as bad params
+func pageError(err error) error {
+	if err != nil && err.Error() == "invalid cursor" {
+		return Errorf(CodeInvalidParams, "Invalid cursor")
+	}
+	return err
+}
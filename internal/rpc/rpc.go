@@ -0,0 +1,325 @@
+// Package rpc is a JSON-RPC 2.0 API for companion apps, such as native
+// mobile or kiosk clients, served on its own port beside the REST API.
+//
+// Every call is a POST of one request object, or an array of them for a
+// batch, and every method takes its parameters as a single object. Methods
+// and the shapes of their parameters and results are listed by
+// rpc.discover, so clients can generate typed bindings instead of
+// hand-writing REST calls. Callers authenticate with the same bearer tokens
+// as the REST API, including API tokens.
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/validation"
+)
+
+// Version is the JSON-RPC version spoken
+const Version = "2.0"
+
+// maxBodyBytes bounds a request body, batches included
+const maxBodyBytes = 1 << 20
+
+// Error codes. Those from -32768 to -32000 are defined by JSON-RPC; the
+// ones above are famstack's.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+
+	CodeUnauthorized = -32001
+	CodeForbidden    = -32003
+	CodeNotFound     = -32004
+)
+
+// Error is a JSON-RPC error object. Methods return one to choose the code
+// the caller sees; any other error is reported as an internal error.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Errorf makes an Error with the given code
+func Errorf(code int, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// request is one call
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// response answers one call
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// Caller is who made a call
+type Caller struct {
+	Session *auth.Session
+	Member  *models.FamilyMember
+}
+
+// FamilyID is the caller's family, the only one their calls can reach
+func (c *Caller) FamilyID() string {
+	return c.Session.FamilyID
+}
+
+// Require fails unless the caller may take action on entity
+func (c *Caller) Require(entity auth.Entity, action auth.Action) error {
+	if !auth.NewAuthorizationService(c.Session).HasPermission(entity, action, nil) {
+		return Errorf(CodeForbidden, "Insufficient permissions to %s %s", action, entity)
+	}
+	return nil
+}
+
+// method is a registered method
+type method struct {
+	Name    string
+	Summary string
+	Public  bool // callable without signing in
+	Params  reflect.Type
+	Result  reflect.Type
+	call    func(ctx context.Context, caller *Caller, params json.RawMessage) (any, error)
+}
+
+// Server dispatches calls to registered methods
+type Server struct {
+	authService *auth.Service
+	methods     map[string]*method
+}
+
+// NewServer makes a server with no methods; see Register
+func NewServer(authService *auth.Service) *Server {
+	return &Server{
+		authService: authService,
+		methods:     make(map[string]*method),
+	}
+}
+
+// Register adds a method taking parameters P and returning R
+func Register[P, R any](s *Server, name, summary string, fn func(ctx context.Context, caller *Caller, params P) (R, error)) {
+	s.register(name, summary, false, fn)
+}
+
+// RegisterPublic adds a method that can be called without signing in; its
+// caller is nil
+func RegisterPublic[P, R any](s *Server, name, summary string, fn func(ctx context.Context, caller *Caller, params P) (R, error)) {
+	s.register(name, summary, true, fn)
+}
+
+func (s *Server) register(name, summary string, public bool, fn any) {
+	fnValue := reflect.ValueOf(fn)
+	fnType := fnValue.Type()
+	paramsType := fnType.In(2)
+
+	s.methods[name] = &method{
+		Name:    name,
+		Summary: summary,
+		Public:  public,
+		Params:  paramsType,
+		Result:  fnType.Out(0),
+		call: func(ctx context.Context, caller *Caller, raw json.RawMessage) (any, error) {
+			params := reflect.New(paramsType)
+			if len(raw) > 0 && !bytes.Equal(raw, []byte("null")) {
+				decoder := json.NewDecoder(bytes.NewReader(raw))
+				decoder.DisallowUnknownFields()
+				if err := decoder.Decode(params.Interface()); err != nil {
+					return nil, Errorf(CodeInvalidParams, "Invalid params: %v", err)
+				}
+			}
+			if err := checkParams(params.Interface()); err != nil {
+				return nil, err
+			}
+
+			out := fnValue.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(caller), params.Elem()})
+			if err, _ := out[1].Interface().(error); err != nil {
+				return nil, err
+			}
+			return out[0].Interface(), nil
+		},
+	}
+}
+
+// normalizer is params that canonicalize their own values, as the REST
+// API's request structs do
+type normalizer interface {
+	Normalize() error
+}
+
+// checkParams normalizes and validates params against their validate tags
+func checkParams(params any) error {
+	if n, ok := params.(normalizer); ok {
+		if err := n.Normalize(); err != nil {
+			return Errorf(CodeInvalidParams, "Invalid params: %v", err)
+		}
+	}
+	if err := validation.Struct(params); err != nil {
+		var fields validation.ValidationErrors
+		if errors.As(err, &fields) {
+			return &Error{Code: CodeInvalidParams, Message: "Invalid params", Data: fields}
+		}
+		return Errorf(CodeInvalidParams, "Invalid params: %v", err)
+	}
+	return nil
+}
+
+// ServeHTTP answers a call or a batch of calls
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "JSON-RPC calls are POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		writeResponse(w, response{JSONRPC: Version, Error: Errorf(CodeParseError, "Request body too large or unreadable"), ID: json.RawMessage("null")})
+		return
+	}
+	caller, authErr := s.authenticate(r)
+
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			writeResponse(w, response{JSONRPC: Version, Error: Errorf(CodeParseError, "Parse error"), ID: json.RawMessage("null")})
+			return
+		}
+		if len(batch) == 0 {
+			writeResponse(w, response{JSONRPC: Version, Error: Errorf(CodeInvalidRequest, "Empty batch"), ID: json.RawMessage("null")})
+			return
+		}
+		responses := make([]response, 0, len(batch))
+		for _, raw := range batch {
+			if resp, ok := s.handle(r.Context(), caller, authErr, raw); ok {
+				responses = append(responses, resp)
+			}
+		}
+		if len(responses) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeResponse(w, responses)
+		return
+	}
+
+	resp, ok := s.handle(r.Context(), caller, authErr, body)
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeResponse(w, resp)
+}
+
+// handle runs one call. ok is false for a notification, a call without an
+// id, which gets no response.
+func (s *Server) handle(ctx context.Context, caller *Caller, authErr *Error, raw json.RawMessage) (response, bool) {
+	var req request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return response{JSONRPC: Version, Error: Errorf(CodeParseError, "Parse error"), ID: json.RawMessage("null")}, true
+		}
+		return response{JSONRPC: Version, Error: Errorf(CodeInvalidRequest, "Invalid request"), ID: json.RawMessage("null")}, true
+	}
+
+	resp := response{JSONRPC: Version, ID: req.ID}
+	if resp.ID == nil {
+		resp.ID = json.RawMessage("null")
+	}
+	notification := req.ID == nil
+
+	if req.JSONRPC != Version || req.Method == "" {
+		resp.Error = Errorf(CodeInvalidRequest, "Invalid request")
+		return resp, true
+	}
+	if len(req.Params) > 0 && req.Params[0] != '{' && !bytes.Equal(req.Params, []byte("null")) {
+		resp.Error = Errorf(CodeInvalidParams, "Params must be an object")
+		return resp, !notification
+	}
+
+	m, ok := s.methods[req.Method]
+	if !ok {
+		resp.Error = Errorf(CodeMethodNotFound, "Method not found: %s", req.Method)
+		return resp, !notification
+	}
+	if !m.Public && caller == nil {
+		resp.Error = authErr
+		return resp, !notification
+	}
+
+	result, err := m.call(ctx, caller, req.Params)
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			log.Printf("rpc: %s failed: %v", req.Method, err)
+			rpcErr = Errorf(CodeInternalError, "Internal error")
+		}
+		resp.Error = rpcErr
+		return resp, !notification
+	}
+	resp.Result = result
+	return resp, !notification
+}
+
+// authenticate finds the caller from the request's bearer token. Calls to
+// methods that need a caller fail with the error when there is none.
+func (s *Server) authenticate(r *http.Request) (*Caller, *Error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return nil, Errorf(CodeUnauthorized, "Authentication required")
+	}
+	session, err := s.authService.ValidateToken(token)
+	if err != nil {
+		return nil, Errorf(CodeUnauthorized, "Invalid or expired token")
+	}
+	member, err := s.authService.GetFamilyMemberByToken(token)
+	if err != nil {
+		return nil, Errorf(CodeUnauthorized, "User not found")
+	}
+	return &Caller{Session: session, Member: member}, nil
+}
+
+// methodList returns the registered methods sorted by name
+func (s *Server) methodList() []*method {
+	methods := make([]*method, 0, len(s.methods))
+	for _, m := range s.methods {
+		methods = append(methods, m)
+	}
+	slices.SortFunc(methods, func(a, b *method) int { return strings.Compare(a.Name, b.Name) })
+	return methods
+}
+
+func writeResponse(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("rpc: failed to write response: %v", err)
+	}
+}
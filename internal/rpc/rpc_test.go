@@ -0,0 +1,138 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"famstack/internal/services"
+)
+
+type echoParams struct {
+	Text string `json:"text" validate:"required"`
+}
+
+type echoResult struct {
+	Text string `json:"text"`
+}
+
+func testServer() *Server {
+	s := NewServer(nil)
+	RegisterPublic(s, "echo", "Says it back",
+		func(ctx context.Context, _ *Caller, params echoParams) (echoResult, error) {
+			return echoResult{Text: params.Text}, nil
+		})
+	RegisterPublic(s, "missing", "Never finds anything",
+		func(ctx context.Context, _ *Caller, _ NoParams) (*echoResult, error) {
+			return nil, Errorf(CodeNotFound, "nothing here")
+		})
+	Register(s, "private", "Needs a caller",
+		func(ctx context.Context, caller *Caller, _ NoParams) (string, error) {
+			return caller.FamilyID(), nil
+		})
+	return s
+}
+
+func call(t *testing.T, s *Server, body string) (int, string) {
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	return w.Code, w.Body.String()
+}
+
+func TestServer_Call(t *testing.T) {
+	code, body := call(t, testServer(), `{"jsonrpc":"2.0","method":"echo","params":{"text":"hi"},"id":1}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","result":{"text":"hi"},"id":1}`, body)
+}
+
+func TestServer_Errors(t *testing.T) {
+	s := testServer()
+	for name, tc := range map[string]struct {
+		body string
+		code int
+	}{
+		"parse error":       {`{"jsonrpc":`, CodeParseError},
+		"wrong version":     {`{"jsonrpc":"1.0","method":"echo","id":1}`, CodeInvalidRequest},
+		"unknown method":    {`{"jsonrpc":"2.0","method":"nope","id":1}`, CodeMethodNotFound},
+		"positional params": {`{"jsonrpc":"2.0","method":"echo","params":["hi"],"id":1}`, CodeInvalidParams},
+		"unknown field":     {`{"jsonrpc":"2.0","method":"echo","params":{"txt":"hi"},"id":1}`, CodeInvalidParams},
+		"failed validation": {`{"jsonrpc":"2.0","method":"echo","params":{},"id":1}`, CodeInvalidParams},
+		"method error":      {`{"jsonrpc":"2.0","method":"missing","id":1}`, CodeNotFound},
+		"not signed in":     {`{"jsonrpc":"2.0","method":"private","id":1}`, CodeUnauthorized},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, body := call(t, s, tc.body)
+			var resp struct {
+				Result any    `json:"result"`
+				Error  *Error `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(body), &resp))
+			require.NotNil(t, resp.Error, body)
+			assert.Equal(t, tc.code, resp.Error.Code)
+			assert.Nil(t, resp.Result)
+		})
+	}
+}
+
+func TestServer_BatchAndNotifications(t *testing.T) {
+	s := testServer()
+
+	code, body := call(t, s, `[
+		{"jsonrpc":"2.0","method":"echo","params":{"text":"a"},"id":"a"},
+		{"jsonrpc":"2.0","method":"echo","params":{"text":"ignored"}},
+		{"jsonrpc":"2.0","method":"nope","id":"b"}
+	]`)
+	assert.Equal(t, http.StatusOK, code)
+	var responses []struct {
+		ID     string      `json:"id"`
+		Result *echoResult `json:"result"`
+		Error  *Error      `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &responses))
+	require.Len(t, responses, 2)
+	assert.Equal(t, "a", responses[0].ID)
+	assert.Equal(t, "a", responses[0].Result.Text)
+	assert.Equal(t, CodeMethodNotFound, responses[1].Error.Code)
+
+	code, body = call(t, s, `{"jsonrpc":"2.0","method":"echo","params":{"text":"quiet"}}`)
+	assert.Equal(t, http.StatusNoContent, code)
+	assert.Empty(t, body)
+}
+
+func TestServer_Discover(t *testing.T) {
+	s := testServer()
+	methods := s.describe()
+	require.Len(t, methods, 3)
+
+	echo := methods[0]
+	assert.Equal(t, "echo", echo.Name)
+	assert.False(t, echo.RequiresAuth)
+	assert.Equal(t, []string{"text"}, echo.Params["required"])
+	assert.Equal(t, Schema{"type": "string"}, echo.Params["properties"].(Schema)["text"])
+	assert.True(t, methods[2].RequiresAuth)
+}
+
+func TestNewHandler_DescribesEveryMethod(t *testing.T) {
+	s := NewHandler(&services.Registry{}, nil)
+
+	_, body := call(t, s, `{"jsonrpc":"2.0","method":"rpc.discover","id":1}`)
+	var resp struct {
+		Result []MethodDescription `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &resp))
+	names := make([]string, 0, len(resp.Result))
+	for _, m := range resp.Result {
+		names = append(names, m.Name)
+		assert.NotEmpty(t, m.Summary, m.Name)
+	}
+	assert.Contains(t, names, "tasks.list")
+	assert.Contains(t, names, "events.list")
+	assert.Contains(t, names, "schedules.get")
+	assert.Contains(t, names, "members.list")
+}
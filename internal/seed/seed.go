@@ -0,0 +1,284 @@
+// Package seed fills a database with a demo family, so contributors and
+// people trying famstack out have something to look at straight away.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/database"
+	"famstack/internal/ids"
+	"famstack/internal/models"
+	"famstack/internal/services"
+)
+
+// The demo family and the parent account to sign in with
+const (
+	DemoFamilyID = "fam_demo"
+	DemoEmail    = "parent@demo.famstack.local"
+	DemoPassword = "famstack-demo"
+)
+
+// Options shape the demo data
+type Options struct {
+	// Seed picks the details that vary, like which child gets a task; the
+	// same seed and start give the same data
+	Seed int64
+	// Start is the first day seeded; a month follows it. Defaults to today.
+	Start time.Time
+	// Timezone is the family's; defaults to America/Chicago
+	Timezone string
+	// Reset deletes an existing demo family first instead of refusing
+	Reset bool
+}
+
+// Summary counts what was seeded
+type Summary struct {
+	FamilyID      string
+	Members       int
+	Schedules     int
+	Tasks         int
+	Events        int
+	IntegrationID string
+}
+
+// demoMember is a member to create; the first is the parent who signs in
+type demoMember struct {
+	firstName  string
+	memberType models.MemberType
+	color      string
+}
+
+var demoMembers = []demoMember{
+	{"Alex", models.MemberTypeAdult, "#3b82f6"},
+	{"Jordan", models.MemberTypeAdult, "#10b981"},
+	{"Maya", models.MemberTypeChild, "#f59e0b"},
+	{"Leo", models.MemberTypeChild, "#ef4444"},
+	{"Biscuit", models.MemberTypePet, "#a16207"},
+}
+
+// Run seeds the demo family
+func Run(db *database.Fascade, authService *auth.Service, opts Options) (*Summary, error) {
+	if opts.Timezone == "" {
+		opts.Timezone = "America/Chicago"
+	}
+	if opts.Start.IsZero() {
+		opts.Start = time.Now()
+	}
+	// Times without a zone are read in the family's timezone
+	start := time.Date(opts.Start.Year(), opts.Start.Month(), opts.Start.Day(), 0, 0, 0, 0, time.UTC)
+	rng := rand.New(rand.NewPCG(uint64(opts.Seed), 0x5eed))
+
+	var exists int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM families WHERE id = ?`, DemoFamilyID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check for the demo family: %w", err)
+	}
+	if exists > 0 {
+		if !opts.Reset {
+			return nil, fmt.Errorf("the demo family already exists; pass --reset to replace it")
+		}
+		if err := services.NewFamiliesService(db).DeleteFamily(DemoFamilyID); err != nil {
+			return nil, fmt.Errorf("failed to delete the demo family: %w", err)
+		}
+	}
+
+	if _, err := db.Exec(`INSERT INTO families (id, name, timezone, created_at) VALUES (?, ?, ?, ?)`,
+		DemoFamilyID, "The Demo Family", opts.Timezone, time.Now().UTC()); err != nil {
+		return nil, fmt.Errorf("failed to create the demo family: %w", err)
+	}
+	summary := &Summary{FamilyID: DemoFamilyID}
+
+	memberIDs, err := seedMembers(db, authService)
+	if err != nil {
+		return nil, err
+	}
+	summary.Members = len(memberIDs)
+	parent, kids := memberIDs[0], memberIDs[2:4]
+
+	if summary.Schedules, err = seedSchedules(db, parent, kids, start); err != nil {
+		return nil, err
+	}
+	if summary.Tasks, err = seedTasks(db, rng, parent, memberIDs[:4], start); err != nil {
+		return nil, err
+	}
+	if summary.Events, err = seedEvents(db, rng, parent, memberIDs, start); err != nil {
+		return nil, err
+	}
+	if summary.IntegrationID, err = seedIntegration(db, parent, start); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// seedMembers adds the family, the first as an admin who can sign in, and
+// returns their IDs in demoMembers order
+func seedMembers(db *database.Fascade, authService *auth.Service) ([]string, error) {
+	memberService := services.NewFamilyMemberService(db)
+	memberIDs := make([]string, 0, len(demoMembers))
+	for i, demo := range demoMembers {
+		var memberID string
+		if i == 0 {
+			parent, err := authService.CreateFamilyMember(&auth.CreateUserRequest{
+				Email: DemoEmail, Password: DemoPassword, FirstName: demo.firstName, LastName: "Demo",
+				Role: auth.RoleAdmin, FamilyID: DemoFamilyID,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create the demo parent: %w", err)
+			}
+			memberID = parent.ID
+		} else {
+			order := i
+			member, err := memberService.CreateFamilyMember(DemoFamilyID, &models.CreateFamilyMemberRequest{
+				FirstName: demo.firstName, LastName: "Demo", MemberType: demo.memberType, DisplayOrder: &order,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create %s: %w", demo.firstName, err)
+			}
+			memberID = member.ID
+		}
+
+		if _, err := db.Exec(`UPDATE family_members SET color = ?, initial = ?, display_order = ? WHERE id = ?`,
+			demo.color, demo.firstName[:1], i, memberID); err != nil {
+			return nil, fmt.Errorf("failed to style %s: %w", demo.firstName, err)
+		}
+		memberIDs = append(memberIDs, memberID)
+	}
+	return memberIDs, nil
+}
+
+// seedSchedules adds recurring chores, two of them rotating between the kids
+func seedSchedules(db *database.Fascade, parent string, kids []string, start time.Time) (int, error) {
+	schedulesService := services.NewSchedulesService(db)
+	anchor := start.Format("2006-01-02")
+	morning, evening := "07:30", "19:00"
+	weekdays := []string{"monday", "tuesday", "wednesday", "thursday", "friday"}
+
+	schedules := []models.CreateTaskScheduleRequest{
+		{Title: "Make bed", TaskType: models.TaskTypeChore, AssignedTo: &kids[0], DaysOfWeek: weekdays, TimeOfDay: &morning},
+		{Title: "Feed Biscuit", TaskType: models.TaskTypeChore, DaysOfWeek: weekdays, TimeOfDay: &morning,
+			RotationStrategy: models.RotationDaily, RotationMembers: kids, RotationAnchor: &anchor},
+		{Title: "Dishes", TaskType: models.TaskTypeChore, DaysOfWeek: append(weekdays, "saturday", "sunday"), TimeOfDay: &evening,
+			RotationStrategy: models.RotationWeekly, RotationMembers: kids, RotationAnchor: &anchor},
+		{Title: "Take out the trash", TaskType: models.TaskTypeChore, AssignedTo: &parent, DaysOfWeek: []string{"wednesday"},
+			TimeOfDay: &evening, Priority: models.PriorityHigh},
+		{Title: "Plan the week's meals", TaskType: models.TaskTypeTodo, AssignedTo: &parent, DaysOfWeek: []string{"sunday"}},
+	}
+	for i := range schedules {
+		if _, err := schedulesService.CreateSchedule(DemoFamilyID, parent, &schedules[i]); err != nil {
+			return i, fmt.Errorf("failed to create schedule %q: %w", schedules[i].Title, err)
+		}
+	}
+	return len(schedules), nil
+}
+
+var demoTasks = []string{
+	"Return library books", "Sign permission slip", "Buy birthday present for Sam", "Call the plumber",
+	"Pack swim bag", "Renew car registration", "Clean out the fridge", "Practice spelling words",
+	"Order school photos", "Fix the bike chain", "Book summer camp", "Water the garden",
+}
+
+// seedTasks adds one-off tasks spread over the month, the first few done
+func seedTasks(db *database.Fascade, rng *rand.Rand, parent string, assignees []string, start time.Time) (int, error) {
+	tasksService := services.NewTasksService(db)
+	completed := models.TaskStatusCompleted
+	for i, title := range demoTasks {
+		assignee := assignees[rng.IntN(len(assignees))]
+		due := start.AddDate(0, 0, rng.IntN(28))
+		task, err := tasksService.CreateTask(DemoFamilyID, parent, &models.CreateTaskRequest{
+			Title: title, TaskType: models.TaskTypeTodo, AssignedTo: &assignee, DueDate: &due,
+			Priority: models.Priority(rng.IntN(int(models.PriorityUrgent) + 1)), Points: 5 * rng.IntN(4),
+		})
+		if err != nil {
+			return i, fmt.Errorf("failed to create task %q: %w", title, err)
+		}
+		if i < 3 {
+			if _, err := tasksService.UpdateTask(DemoFamilyID, task.ID, &models.UpdateTaskRequest{Status: &completed}); err != nil {
+				return i, fmt.Errorf("failed to complete task %q: %w", title, err)
+			}
+		}
+	}
+	return len(demoTasks), nil
+}
+
+// seedEvents adds a month of calendar: weekly activities, some of which
+// overlap, one-off appointments and a no-school day
+func seedEvents(db *database.Fascade, rng *rand.Rand, parent string, members []string, start time.Time) (int, error) {
+	at := func(day, hour, minute int) time.Time {
+		return start.AddDate(0, 0, day).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	weekly := func(byDay string, count int) *string {
+		rule := fmt.Sprintf("FREQ=WEEKLY;BYDAY=%s;COUNT=%d", byDay, count)
+		return &rule
+	}
+	maya, leo := members[2], members[3]
+
+	items := []models.BulkCalendarEventItem{
+		// Soccer and piano overlap on Tuesdays, so someone has to drive twice
+		{Title: "Soccer practice", StartTime: at(1, 17, 0), EndTime: at(1, 18, 30), EventType: models.EventTypeEvent,
+			Attendees: []string{maya}, Color: "#f59e0b", RecurrenceRule: weekly("TU,TH", 8)},
+		{Title: "Piano lesson", StartTime: at(1, 17, 30), EndTime: at(1, 18, 15), EventType: models.EventTypeAppointment,
+			Attendees: []string{leo}, Color: "#ef4444", RecurrenceRule: weekly("TU", 4)},
+		{Title: "Family dinner at Grandma's", StartTime: at(6, 17, 0), EndTime: at(6, 19, 30), EventType: models.EventTypeEvent,
+			Attendees: members[:4], RecurrenceRule: weekly("SU", 4)},
+		{Title: "Dentist", StartTime: at(3, 9, 0), EndTime: at(3, 10, 0), EventType: models.EventTypeAppointment,
+			Attendees: []string{parent, leo}},
+		{Title: "Parent-teacher conference", StartTime: at(10, 15, 30), EndTime: at(10, 16, 0), EventType: models.EventTypeAppointment,
+			Attendees: []string{parent}},
+		{Title: "No school - teacher planning day", StartTime: at(12, 0, 0), EndTime: at(13, 0, 0), AllDay: true,
+			EventType: models.EventTypeEvent, Attendees: []string{maya, leo}},
+		{Title: "Vet checkup", StartTime: at(16, 11, 0), EndTime: at(16, 11, 45), EventType: models.EventTypeAppointment,
+			Attendees: []string{parent, members[4]}},
+	}
+
+	// A few birthday parties at varying times, some overlapping the rest
+	for i, host := range []string{"Sam", "Priya", "Noah"} {
+		day := 5 + 7*i + rng.IntN(2)
+		hour := 13 + rng.IntN(4)
+		items = append(items, models.BulkCalendarEventItem{
+			Title: host + "'s birthday party", StartTime: at(day, hour, 0), EndTime: at(day, hour+2, 0),
+			EventType: models.EventTypeEvent, Attendees: []string{members[2+rng.IntN(2)]},
+		})
+	}
+
+	response, err := services.NewCalendarService(db).BulkCreateUnifiedCalendarEvents(context.Background(), DemoFamilyID, parent, items)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create events: %w", err)
+	}
+	return response.Created, nil
+}
+
+// seedIntegration adds a calendar integration that looks connected and has
+// synced before, without any real account behind it
+func seedIntegration(db *database.Fascade, parent string, start time.Time) (string, error) {
+	integrationsService := services.NewIntegrationsService(db, nil)
+	integration, err := integrationsService.CreateIntegration(DemoFamilyID, parent, &services.CreateIntegrationRequest{
+		IntegrationType: services.TypeCalendar,
+		Provider:        services.ProviderGoogle,
+		AuthMethod:      services.AuthOAuth2,
+		DisplayName:     "School calendar (demo)",
+		Description:     "A stand-in integration from 'famstack seed'; it isn't connected to anything",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the demo integration: %w", err)
+	}
+	if _, err := integrationsService.UpdateIntegration(integration.ID, &services.UpdateIntegrationRequest{
+		Status: services.StatusConnected,
+	}); err != nil {
+		return "", fmt.Errorf("failed to connect the demo integration: %w", err)
+	}
+
+	for i, items := range []int{42, 3, 0} {
+		startedAt := start.Add(-time.Duration(3-i) * 24 * time.Hour).Add(6 * time.Hour)
+		completedAt := startedAt.Add(4 * time.Second)
+		if _, err := db.Exec(`
+			INSERT INTO integration_sync_history (id, integration_id, sync_type, status, items_synced, error_message, started_at, completed_at, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, ids.New("sync"), integration.ID, "scheduled", "success", items, "", startedAt, completedAt, startedAt); err != nil {
+			return "", fmt.Errorf("failed to record demo sync history: %w", err)
+		}
+	}
+	return integration.ID, nil
+}
@@ -0,0 +1,90 @@
+package seed
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"famstack/internal/auth"
+	"famstack/internal/config"
+	"famstack/internal/database"
+	"famstack/internal/encryption"
+)
+
+func setupSeedDB(t *testing.T) (*database.Fascade, *auth.Service) {
+	dbFile := fmt.Sprintf("test_seed_%d.db", time.Now().UnixNano())
+	db, err := database.New(dbFile)
+	require.NoError(t, err)
+	require.NoError(t, db.MigrateUp())
+	t.Cleanup(func() {
+		db.Close()
+		os.Remove(dbFile)
+	})
+
+	encryptionService, err := encryption.NewService(config.EncryptionSettings{
+		FixedKey: &config.FixedKeyConfig{Value: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+	})
+	require.NoError(t, err)
+	return db, auth.NewService(db, encryptionService, "famstack")
+}
+
+// seededTasks lists the seeded tasks by what doesn't depend on generated IDs
+func seededTasks(t *testing.T, db *database.Fascade) []string {
+	rows, err := db.Query(`
+		SELECT t.title, COALESCE(fm.first_name, ''), COALESCE(t.due_date, '')
+		FROM tasks t LEFT JOIN family_members fm ON fm.id = t.assigned_to
+		WHERE t.family_id = ? ORDER BY t.title, t.due_date`, DemoFamilyID)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var tasks []string
+	for rows.Next() {
+		var title, assignee, due string
+		require.NoError(t, rows.Scan(&title, &assignee, &due))
+		tasks = append(tasks, title+"|"+assignee+"|"+due)
+	}
+	require.NoError(t, rows.Err())
+	return tasks
+}
+
+func TestRun_SameSeedGivesSameData(t *testing.T) {
+	opts := Options{Seed: 7, Start: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)}
+
+	first, firstAuth := setupSeedDB(t)
+	summary, err := Run(first, firstAuth, opts)
+	require.NoError(t, err)
+	require.Equal(t, len(demoMembers), summary.Members)
+	require.Positive(t, summary.Schedules)
+	require.Positive(t, summary.Tasks)
+	require.Positive(t, summary.Events)
+	require.NotEmpty(t, summary.IntegrationID)
+
+	second, secondAuth := setupSeedDB(t)
+	_, err = Run(second, secondAuth, opts)
+	require.NoError(t, err)
+
+	require.Len(t, seededTasks(t, first), summary.Tasks)
+	require.Equal(t, seededTasks(t, first), seededTasks(t, second))
+}
+
+func TestRun_RefusesToReseedWithoutReset(t *testing.T) {
+	db, authService := setupSeedDB(t)
+	opts := Options{Seed: 1, Start: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)}
+
+	_, err := Run(db, authService, opts)
+	require.NoError(t, err)
+
+	_, err = Run(db, authService, opts)
+	require.ErrorContains(t, err, "already exists")
+
+	opts.Reset = true
+	summary, err := Run(db, authService, opts)
+	require.NoError(t, err)
+
+	var members int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM family_members WHERE family_id = ?`, DemoFamilyID).Scan(&members))
+	require.Equal(t, summary.Members, members)
+}
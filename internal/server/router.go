@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"famstack/internal/apierror"
+)
+
+// router sends requests through a ServeMux of method and wildcard patterns,
+// like "PATCH /api/v1/tasks/{taskID}", so handlers read their path
+// parameters with r.PathValue and only see the methods they serve.
+//
+// Requests no pattern serves are answered in one place: API paths get the
+// JSON error envelope, a 405 keeping the Allow header, and other GETs go to
+// the fallback, the single page app, whose client-side router owns the rest
+// of the paths.
+type router struct {
+	mux      *http.ServeMux
+	fallback http.Handler // nil answers unmatched pages with a plain 404
+}
+
+func newRouter() *router {
+	return &router{mux: http.NewServeMux()}
+}
+
+// handle registers h on pattern, wrapped in middleware, the first outermost
+func (rt *router) handle(pattern string, h http.Handler, middleware ...func(http.Handler) http.Handler) {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	rt.mux.Handle(pattern, h)
+}
+
+// handleFunc is handle for a handler function
+func (rt *router) handleFunc(pattern string, h http.HandlerFunc, middleware ...func(http.Handler) http.Handler) {
+	rt.handle(pattern, h, middleware...)
+}
+
+func (rt *router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h, pattern := rt.mux.Handler(r)
+	if pattern != "" {
+		rt.mux.ServeHTTP(w, r)
+		return
+	}
+
+	// No pattern matched: h is the mux's 404 or 405, or a redirect to the
+	// cleaned path. Find out which without writing it.
+	probe := &statusProbe{header: make(http.Header), status: http.StatusOK}
+	h.ServeHTTP(probe, r)
+	unmatched := probe.status == http.StatusNotFound || probe.status == http.StatusMethodNotAllowed
+
+	switch {
+	case unmatched && strings.HasPrefix(r.URL.Path, "/api/"):
+		if allow := probe.header.Get("Allow"); allow != "" {
+			w.Header().Set("Allow", allow)
+		}
+		message := "Not found"
+		if probe.status == http.StatusMethodNotAllowed {
+			message = "Method not allowed"
+		}
+		apierror.Error(w, message, probe.status)
+	case probe.status == http.StatusNotFound && r.Method == http.MethodGet && rt.fallback != nil:
+		rt.fallback.ServeHTTP(w, r)
+	default:
+		h.ServeHTTP(w, r)
+	}
+}
+
+// statusProbe is a ResponseWriter that keeps only the status and headers
+type statusProbe struct {
+	header http.Header
+	status int
+}
+
+func (p *statusProbe) Header() http.Header         { return p.header }
+func (p *statusProbe) Write(b []byte) (int, error) { return len(b), nil }
+func (p *statusProbe) WriteHeader(status int)      { p.status = status }
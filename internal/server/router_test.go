@@ -0,0 +1,84 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"famstack/internal/apierror"
+)
+
+func testRouter() *router {
+	rt := newRouter()
+	rt.fallback = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("app"))
+	})
+	rt.handleFunc("GET /api/v1/tasks/{taskID}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("task " + r.PathValue("taskID")))
+	})
+	rt.handleFunc("DELETE /api/v1/tasks/{taskID}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return rt
+}
+
+func serve(rt *router, method, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+	return w
+}
+
+func TestRouter_PathParams(t *testing.T) {
+	w := serve(testRouter(), "GET", "/api/v1/tasks/abc-123")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "task abc-123", w.Body.String())
+
+	w = serve(testRouter(), "HEAD", "/api/v1/tasks/abc-123")
+	assert.Equal(t, http.StatusOK, w.Code, "GET patterns serve HEAD")
+}
+
+func TestRouter_APIErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		status int
+		code   apierror.Code
+		allow  string
+	}{
+		{"unknown path", "GET", "/api/v1/nope", http.StatusNotFound, apierror.CodeNotFound, ""},
+		{"extra segment", "GET", "/api/v1/tasks/abc/extra", http.StatusNotFound, apierror.CodeNotFound, ""},
+		{"wrong method", "PUT", "/api/v1/tasks/abc", http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "DELETE, GET, HEAD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(testRouter(), tt.method, tt.target)
+			require.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.allow, w.Header().Get("Allow"))
+
+			var envelope apierror.Envelope
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+			assert.Equal(t, tt.code, envelope.Error.Code)
+		})
+	}
+}
+
+func TestRouter_FallbackServesPages(t *testing.T) {
+	rt := testRouter()
+
+	w := serve(rt, "GET", "/family/setup")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "app", w.Body.String())
+
+	w = serve(rt, "POST", "/family/setup")
+	assert.Equal(t, http.StatusNotFound, w.Code, "only GETs reach the app")
+
+	rt.fallback = nil
+	w = serve(rt, "GET", "/family/setup")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
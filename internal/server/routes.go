@@ -0,0 +1,501 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"famstack/internal/auth"
+	"famstack/internal/handlers"
+	"famstack/internal/handlers/api"
+	"famstack/internal/middleware"
+	"famstack/internal/models"
+)
+
+// route is one entry in the route table: a pattern, its handler and the
+// access it takes. Every route declares its access, so an endpoint can't be
+// left unprotected by forgetting a middleware.
+type route struct {
+	pattern string // "METHOD /path/{param}"
+	handler http.HandlerFunc
+	access  access
+}
+
+// permission is one entity action a route requires
+type permission struct {
+	entity auth.Entity
+	action auth.Action
+}
+
+// access is what a route requires of a request: the entity actions it
+// checks, all of them in order, or an explicit exemption from them
+type access struct {
+	perms []permission
+	// signedIn lets any session through; the handler limits the member to
+	// their own data or family
+	signedIn bool
+	// public lets anyone through; the handler authenticates the request itself
+	public bool
+
+	feature string // the feature flag the route sits behind, "" for none
+	etag    bool   // answers repeat GETs with 304 Not Modified
+}
+
+// can requires the entity action of the session
+func can(entity auth.Entity, action auth.Action) access {
+	return access{perms: []permission{{entity, action}}}
+}
+
+// and requires another entity action after the ones before it
+func (a access) and(entity auth.Entity, action auth.Action) access {
+	a.perms = append(slices.Clone(a.perms), permission{entity, action})
+	return a
+}
+
+// behind puts the route behind a feature flag, checked after the permissions
+func (a access) behind(feature string) access {
+	a.feature = feature
+	return a
+}
+
+// withETag answers repeat GETs of an unchanged response with 304s
+func (a access) withETag() access {
+	a.etag = true
+	return a
+}
+
+var (
+	// signedIn is the access of routes any session may use
+	signedIn = access{signedIn: true}
+	// public is the access of routes that check their own credentials
+	public = access{public: true}
+)
+
+// validate reports a route whose access is missing or contradictory
+func (r route) validate() error {
+	declared := 0
+	for _, set := range []bool{len(r.access.perms) > 0, r.access.signedIn, r.access.public} {
+		if set {
+			declared++
+		}
+	}
+	switch {
+	case declared == 0:
+		return fmt.Errorf("route %q declares no access", r.pattern)
+	case declared > 1:
+		return fmt.Errorf("route %q declares more than one kind of access", r.pattern)
+	case r.access.public && r.access.feature != "":
+		return fmt.Errorf("route %q is public but behind a feature flag, which needs a session", r.pattern)
+	}
+	return nil
+}
+
+// middleware returns the checks the route's access declares, outermost first
+func (a access) middleware(authMiddleware *auth.Middleware, features *api.FeaturesAPIHandler) []func(http.Handler) http.Handler {
+	var chain []func(http.Handler) http.Handler
+	if a.signedIn {
+		chain = append(chain, authMiddleware.RequireAuth)
+	}
+	for _, p := range a.perms {
+		chain = append(chain, authMiddleware.RequireEntityAction(p.entity, p.action))
+	}
+	if a.feature != "" {
+		chain = append(chain, features.Require(a.feature))
+	}
+	if a.etag {
+		chain = append(chain, middleware.ConditionalGET)
+	}
+	return chain
+}
+
+// routeHandlers are the handlers the route table points at
+type routeHandlers struct {
+	tasks                   *api.TaskAPIHandler
+	assignments             *api.AssignmentsAPIHandler
+	activities              *api.ActivitiesAPIHandler
+	carpools                *api.CarpoolsAPIHandler
+	families                *api.FamilyAPIHandler
+	familyMembers           *api.FamilyMemberAPIHandler
+	schedules               *api.ScheduleHandler
+	scheduleProfiles        *api.ScheduleProfilesAPIHandler
+	calendar                *api.CalendarAPIHandler
+	protectedBlocks         *api.ProtectedBlocksAPIHandler
+	eventColorRules         *api.EventColorRulesAPIHandler
+	display                 *api.DisplayAPIHandler
+	dashboard               *api.DashboardAPIHandler
+	focus                   *api.FocusAPIHandler
+	reminders               *api.RemindersAPIHandler
+	notificationPreferences *api.NotificationPreferencesAPIHandler
+	preferences             *api.PreferencesAPIHandler
+	rewards                 *api.RewardsAPIHandler
+	meals                   *api.MealsAPIHandler
+	announcements           *api.AnnouncementsAPIHandler
+	imports                 *api.ImportAPIHandler
+	search                  *api.SearchAPIHandler
+	stream                  *api.StreamAPIHandler
+	realtime                *api.RealtimeAPIHandler
+	timeline                *api.TimelineAPIHandler
+	suggestions             *api.SuggestionsAPIHandler
+	usage                   *api.UsageAPIHandler
+	attachments             *api.AttachmentsAPIHandler
+	insights                *api.InsightsAPIHandler
+	custody                 *api.CustodyAPIHandler
+	integrations            *api.IntegrationsAPIHandler
+	syncConflicts           *api.SyncConflictsAPIHandler
+	webhooks                *api.WebhooksAPIHandler
+	config                  *api.ConfigAPIHandler
+	features                *api.FeaturesAPIHandler
+	jobs                    *api.JobsAPIHandler
+	templates               *api.TemplatesAPIHandler
+	analytics               *api.AnalyticsAPIHandler
+	rateLimits              *api.RateLimitsAPIHandler
+	permissions             *api.PermissionsAPIHandler
+	apiTokens               *api.APITokensAPIHandler
+	guestPasses             *api.GuestPassesAPIHandler
+	loginSecurity           *api.LoginSecurityAPIHandler
+	oauth                   *handlers.OAuthHandlers
+}
+
+// routeTable lists the API routes and the signed-in pages outside the single
+// page app, with the access each takes
+func routeTable(h routeHandlers) []route {
+	routes := []route{
+		// Tasks
+		{"GET /api/v1/tasks", h.tasks.ListTasks, can(auth.EntityTask, auth.ActionRead).withETag()},
+		{"POST /api/v1/tasks", h.tasks.CreateTask, can(auth.EntityTask, auth.ActionRead).and(auth.EntityTask, auth.ActionCreate)},
+		{"PATCH /api/v1/tasks/{taskID}", h.tasks.UpdateTask, can(auth.EntityTask, auth.ActionUpdate)},
+		{"DELETE /api/v1/tasks/{taskID}", h.tasks.DeleteTask, can(auth.EntityTask, auth.ActionDelete)},
+
+		// Families; reading a family is open to the shared displays, and the
+		// handlers keep members to their own
+		{"GET /api/v1/families", h.families.ListFamilies, can(auth.EntityFamily, auth.ActionRead)},
+		{"POST /api/v1/families", h.families.CreateFamily, can(auth.EntityFamily, auth.ActionCreate)},
+		{"GET /api/v1/families/{familyID}", h.families.GetFamily, signedIn},
+		{"PUT /api/v1/families/{familyID}", h.families.UpdateFamily, can(auth.EntityFamily, auth.ActionUpdate)},
+		{"GET /api/v1/families/{familyID}/settings", h.families.GetSettings, signedIn},
+		{"PATCH /api/v1/families/{familyID}/settings", h.families.UpdateSettings, can(auth.EntityFamily, auth.ActionUpdate)},
+		{"POST /api/v1/families/{familyID}/import", h.imports.ImportArchive, can(auth.EntityFamily, auth.ActionUpdate)},
+		{"GET /api/v1/families/{familyID}/permissions", h.permissions.ListPermissions, can(auth.EntityUser, auth.ActionUpdate)},
+		{"PUT /api/v1/families/{familyID}/permissions", h.permissions.SetGrant, can(auth.EntityUser, auth.ActionUpdate)},
+		{"DELETE /api/v1/families/{familyID}/permissions", h.permissions.ClearGrant, can(auth.EntityUser, auth.ActionUpdate)},
+	}
+
+	// Family members, under their family; /api/families is the older prefix
+	for _, prefix := range []string{"/api/v1/families/{familyID}/members", "/api/families/{familyID}/members"} {
+		routes = append(routes,
+			route{"GET " + prefix, h.familyMembers.ListFamilyMembers, signedIn},
+			route{"POST " + prefix, h.familyMembers.CreateFamilyMember, can(auth.EntityFamily, auth.ActionUpdate)},
+			route{"GET " + prefix + "/{memberID}", h.familyMembers.GetFamilyMember, signedIn},
+			route{"PATCH " + prefix + "/{memberID}", h.familyMembers.UpdateFamilyMember, can(auth.EntityFamily, auth.ActionUpdate)},
+			route{"DELETE " + prefix + "/{memberID}", h.familyMembers.DeleteFamilyMember, can(auth.EntityFamily, auth.ActionUpdate)},
+		)
+	}
+
+	carpoolRead := can(auth.EntityCalendar, auth.ActionRead).behind(models.FeatureCarpools)
+	carpoolManage := carpoolRead.and(auth.EntityFamily, auth.ActionUpdate)
+	focusRead := can(auth.EntityCalendar, auth.ActionRead).behind(models.FeatureFocus)
+	focusManage := focusRead.and(auth.EntityFamily, auth.ActionUpdate)
+	rewardsRead := can(auth.EntityFamily, auth.ActionRead).behind(models.FeatureRewards)
+	rewardsManage := rewardsRead.and(auth.EntityFamily, auth.ActionUpdate)
+	rewardsApprove := can(auth.EntityFamily, auth.ActionUpdate).behind(models.FeatureRewards)
+	mealsRead := can(auth.EntityFamily, auth.ActionRead).behind(models.FeatureMeals)
+	mealsPlan := mealsRead.and(auth.EntityCalendar, auth.ActionCreate)
+	shoppingList := can(auth.EntityTask, auth.ActionUpdate).behind(models.FeatureMeals)
+	suggestionsRead := can(auth.EntityFamily, auth.ActionRead).behind(models.FeatureSuggestions)
+	suggestionsManage := can(auth.EntityFamily, auth.ActionUpdate).behind(models.FeatureSuggestions)
+	integrationRead := can(auth.EntityIntegration, auth.ActionRead)
+	integrationUpdate := can(auth.EntityIntegration, auth.ActionUpdate)
+	settingsRead := can(auth.EntitySetting, auth.ActionRead)
+	settingsUpdate := settingsRead.and(auth.EntitySetting, auth.ActionUpdate)
+
+	return append(routes, []route{
+		{"GET /api/v1/families/{familyID}/members/{memberID}/celebrations", h.familyMembers.ListCelebrations, signedIn},
+		{"PUT /api/v1/families/{familyID}/members/{memberID}/celebrations/{kind}/{year}", h.familyMembers.SetCelebrationSkipped, can(auth.EntityCalendar, auth.ActionUpdate)},
+
+		// The session's own family, without its ID in the path
+		{"GET /api/v1/families/members", h.familyMembers.GetFamilyMembersWithStats, signedIn},
+		{"POST /api/v1/families/members", h.familyMembers.CreateFamilyMember, can(auth.EntityFamily, auth.ActionUpdate)},
+
+		// Schedules; members change their own, which the handlers check
+		{"GET /api/v1/schedules", h.schedules.ListSchedules, can(auth.EntitySchedule, auth.ActionRead)},
+		{"POST /api/v1/schedules", h.schedules.CreateSchedule, can(auth.EntitySchedule, auth.ActionRead).and(auth.EntitySchedule, auth.ActionCreate)},
+		{"GET /api/v1/schedules/preview", h.schedules.PreviewSchedule, can(auth.EntitySchedule, auth.ActionRead)},
+		{"GET /api/v1/schedules/{scheduleID}", h.schedules.GetSchedule, can(auth.EntitySchedule, auth.ActionRead)},
+		{"PATCH /api/v1/schedules/{scheduleID}", h.schedules.UpdateSchedule, can(auth.EntitySchedule, auth.ActionUpdate)},
+		{"DELETE /api/v1/schedules/{scheduleID}", h.schedules.DeleteSchedule, can(auth.EntitySchedule, auth.ActionDelete)},
+
+		// Seasonal profile membership of a schedule
+		{"GET /api/v1/schedules/{scheduleID}/profiles", h.scheduleProfiles.GetScheduleProfiles, can(auth.EntitySchedule, auth.ActionRead)},
+		{"PUT /api/v1/schedules/{scheduleID}/profiles", h.scheduleProfiles.SetScheduleProfiles, can(auth.EntitySchedule, auth.ActionUpdate)},
+
+		// Homework tracker - courses are managed by parents, kids update their own assignments
+		{"GET /api/v1/courses", h.assignments.ListCourses, can(auth.EntityTask, auth.ActionRead)},
+		{"POST /api/v1/courses", h.assignments.CreateCourse, can(auth.EntityTask, auth.ActionRead).and(auth.EntityFamily, auth.ActionUpdate)},
+		{"PATCH /api/v1/courses/{courseID}", h.assignments.UpdateCourse, can(auth.EntityFamily, auth.ActionUpdate)},
+		{"DELETE /api/v1/courses/{courseID}", h.assignments.DeleteCourse, can(auth.EntityFamily, auth.ActionUpdate)},
+		{"GET /api/v1/assignments", h.assignments.ListAssignments, can(auth.EntityTask, auth.ActionRead)},
+		{"POST /api/v1/assignments", h.assignments.CreateAssignment, can(auth.EntityTask, auth.ActionRead).and(auth.EntityTask, auth.ActionCreate)},
+		{"GET /api/v1/assignments/upcoming", h.assignments.UpcomingDeadlines, can(auth.EntityFamily, auth.ActionRead)},
+		{"GET /api/v1/assignments/{assignmentID}", h.assignments.GetAssignment, can(auth.EntityTask, auth.ActionRead)},
+		{"PATCH /api/v1/assignments/{assignmentID}", h.assignments.UpdateAssignment, can(auth.EntityTask, auth.ActionRead).and(auth.EntityTask, auth.ActionUpdate)},
+		{"DELETE /api/v1/assignments/{assignmentID}", h.assignments.DeleteAssignment, can(auth.EntityTask, auth.ActionRead).and(auth.EntityTask, auth.ActionDelete)},
+
+		// Extracurricular activity registry - readable by the family, managed by parents
+		{"GET /api/v1/activities", h.activities.ListActivities, can(auth.EntityTask, auth.ActionRead)},
+		{"POST /api/v1/activities", h.activities.CreateActivity, can(auth.EntityTask, auth.ActionRead).and(auth.EntityFamily, auth.ActionUpdate)},
+		{"GET /api/v1/activities/{activityID}", h.activities.GetActivity, can(auth.EntityTask, auth.ActionRead)},
+		{"PATCH /api/v1/activities/{activityID}", h.activities.UpdateActivity, can(auth.EntityTask, auth.ActionRead).and(auth.EntityFamily, auth.ActionUpdate)},
+		{"DELETE /api/v1/activities/{activityID}", h.activities.DeleteActivity, can(auth.EntityTask, auth.ActionRead).and(auth.EntityFamily, auth.ActionUpdate)},
+
+		// Carpools - shared between the organizing family and every family that drives
+		{"GET /api/v1/carpools", h.carpools.ListGroups, carpoolRead},
+		{"POST /api/v1/carpools", h.carpools.CreateGroup, carpoolManage},
+		{"POST /api/v1/carpools/join", h.carpools.JoinGroup, can(auth.EntityFamily, auth.ActionUpdate).behind(models.FeatureCarpools)},
+		{"GET /api/v1/carpools/{groupID}", h.carpools.GetGroup, carpoolRead},
+		{"DELETE /api/v1/carpools/{groupID}", h.carpools.DeleteGroup, carpoolManage},
+		{"POST /api/v1/carpools/{groupID}/drivers", h.carpools.AddDriver, carpoolManage},
+		{"DELETE /api/v1/carpools/{groupID}/drivers/{driverID}", h.carpools.RemoveDriver, carpoolManage},
+		{"GET /api/v1/carpools/{groupID}/rotation", h.carpools.ListRotation, carpoolRead},
+		{"POST /api/v1/carpools/{groupID}/rotation", h.carpools.GenerateRotation, carpoolManage},
+		{"GET /api/v1/carpools/{groupID}/swaps", h.carpools.ListSwaps, carpoolRead},
+		{"POST /api/v1/carpools/{groupID}/swaps", h.carpools.RequestSwap, carpoolManage},
+		{"PATCH /api/v1/carpool-swaps/{swapID}", h.carpools.RespondToSwap, can(auth.EntityFamily, auth.ActionUpdate)},
+
+		// Seasonal schedule profiles - readable by the family, managed by parents
+		{"GET /api/v1/schedule-profiles", h.scheduleProfiles.ListProfiles, can(auth.EntitySchedule, auth.ActionRead)},
+		{"POST /api/v1/schedule-profiles", h.scheduleProfiles.CreateProfile, can(auth.EntitySchedule, auth.ActionRead).and(auth.EntityFamily, auth.ActionUpdate)},
+		{"PATCH /api/v1/schedule-profiles/{profileID}", h.scheduleProfiles.UpdateProfile, can(auth.EntityFamily, auth.ActionUpdate)},
+		{"DELETE /api/v1/schedule-profiles/{profileID}", h.scheduleProfiles.DeleteProfile, can(auth.EntityFamily, auth.ActionUpdate)},
+
+		// Calendar events
+		{"GET /api/v1/calendar/events", h.calendar.GetEvents, can(auth.EntityCalendar, auth.ActionRead).withETag()},
+		{"POST /api/v1/calendar/events", h.calendar.CreateEvent, can(auth.EntityCalendar, auth.ActionRead).and(auth.EntityCalendar, auth.ActionCreate)},
+		{"POST /api/v1/calendar/events/bulk", h.calendar.BulkCreateEvents, can(auth.EntityCalendar, auth.ActionCreate)},
+		{"GET /api/v1/calendar/events/{eventID}", h.calendar.GetEvent, can(auth.EntityCalendar, auth.ActionRead)},
+		{"PATCH /api/v1/calendar/events/{eventID}", h.calendar.UpdateEvent, can(auth.EntityCalendar, auth.ActionUpdate)},
+		{"DELETE /api/v1/calendar/events/{eventID}", h.calendar.DeleteEvent, can(auth.EntityCalendar, auth.ActionDelete)},
+
+		// Protected "do not schedule" blocks - readable by the family, managed by parents
+		{"GET /api/v1/calendar/protected-blocks", h.protectedBlocks.ListBlocks, can(auth.EntityCalendar, auth.ActionRead)},
+		{"POST /api/v1/calendar/protected-blocks", h.protectedBlocks.CreateBlock, can(auth.EntityCalendar, auth.ActionRead).and(auth.EntityFamily, auth.ActionUpdate)},
+		{"PATCH /api/v1/calendar/protected-blocks/{blockID}", h.protectedBlocks.UpdateBlock, can(auth.EntityFamily, auth.ActionUpdate)},
+		{"DELETE /api/v1/calendar/protected-blocks/{blockID}", h.protectedBlocks.DeleteBlock, can(auth.EntityFamily, auth.ActionUpdate)},
+
+		// Event auto-coloring rules - readable by the family, managed by parents
+		{"GET /api/v1/calendar/rules", h.eventColorRules.ListRules, can(auth.EntityCalendar, auth.ActionRead)},
+		{"POST /api/v1/calendar/rules", h.eventColorRules.CreateRule, can(auth.EntityCalendar, auth.ActionRead).and(auth.EntityFamily, auth.ActionUpdate)},
+		{"POST /api/v1/calendar/rules/test", h.eventColorRules.TestRules, can(auth.EntityCalendar, auth.ActionRead)},
+		{"PUT /api/v1/calendar/rules/order", h.eventColorRules.ReorderRules, can(auth.EntityCalendar, auth.ActionRead).and(auth.EntityFamily, auth.ActionUpdate)},
+		{"PATCH /api/v1/calendar/rules/{ruleID}", h.eventColorRules.UpdateRule, can(auth.EntityCalendar, auth.ActionRead).and(auth.EntityFamily, auth.ActionUpdate)},
+		{"DELETE /api/v1/calendar/rules/{ruleID}", h.eventColorRules.DeleteRule, can(auth.EntityCalendar, auth.ActionRead).and(auth.EntityFamily, auth.ActionUpdate)},
+
+		// Calendar views: layered days, and the month grid and agenda list
+		// that are lighter for views that don't lay out hours
+		{"GET /api/v1/calendar/days", h.calendar.GetCalendarDays, can(auth.EntityCalendar, auth.ActionRead).withETag()},
+		{"GET /api/v1/calendar/month", h.calendar.GetMonth, can(auth.EntityCalendar, auth.ActionRead).withETag()},
+		{"GET /api/v1/calendar/agenda", h.calendar.GetAgenda, can(auth.EntityCalendar, auth.ActionRead).withETag()},
+
+		// Free/busy lookup - when a set of members is free together on a day
+		{"GET /api/v1/calendar/freebusy", h.calendar.GetFreeBusy, can(auth.EntityCalendar, auth.ActionRead)},
+
+		// Dashboard - everything the home screen needs in one call
+		{"GET /api/v1/dashboard", h.dashboard.GetDashboard, can(auth.EntityCalendar, auth.ActionRead)},
+
+		// Display preferences - what each tablet/phone shows, readable in shared mode
+		{"GET /api/v1/display/bootstrap", h.display.GetBootstrap, can(auth.EntityCalendar, auth.ActionRead)},
+		{"GET /api/v1/display/agenda", h.display.GetAgenda, can(auth.EntityCalendar, auth.ActionRead)},
+		{"GET /api/v1/display/preferences", h.display.GetMyPreferences, can(auth.EntityCalendar, auth.ActionRead)},
+		{"PUT /api/v1/display/preferences", h.display.SaveMyPreferences, can(auth.EntityCalendar, auth.ActionRead).and(auth.EntityFamily, auth.ActionRead)},
+		{"GET /api/v1/display/devices", h.display.ListDevices, can(auth.EntityFamily, auth.ActionRead)},
+		{"PUT /api/v1/display/devices/{deviceID}", h.display.SaveDevice, can(auth.EntityFamily, auth.ActionUpdate)},
+		{"DELETE /api/v1/display/devices/{deviceID}", h.display.DeleteDevice, can(auth.EntityFamily, auth.ActionUpdate)},
+
+		// Timeline - per-member day stream for the wall display
+		{"GET /api/v1/timeline", h.timeline.GetTimeline, can(auth.EntityCalendar, auth.ActionRead)},
+
+		// Focus sessions - quiet time for the whole family, started and ended by parents
+		{"GET /api/v1/focus", h.focus.GetStatus, focusRead},
+		{"POST /api/v1/focus", h.focus.StartSession, focusManage},
+		{"DELETE /api/v1/focus", h.focus.EndSession, focusManage},
+		{"GET /api/v1/focus/sessions", h.focus.ListSessions, focusRead},
+		{"GET /api/v1/focus/stream", h.focus.Stream, focusRead},
+
+		// Event reminders and each member's own delivery channels
+		{"GET /api/v1/reminders", h.reminders.ListReminders, can(auth.EntityCalendar, auth.ActionRead)},
+		{"POST /api/v1/reminders", h.reminders.CreateReminder, can(auth.EntityCalendar, auth.ActionRead).and(auth.EntityCalendar, auth.ActionCreate)},
+		{"DELETE /api/v1/reminders/{reminderID}", h.reminders.DeleteReminder, can(auth.EntityCalendar, auth.ActionDelete)},
+		{"GET /api/v1/notifications/channels", h.reminders.GetChannels, signedIn},
+		{"GET /api/v1/notifications/push-subscriptions", h.reminders.ListPushSubscriptions, signedIn},
+		{"POST /api/v1/notifications/push-subscriptions", h.reminders.SavePushSubscription, signedIn},
+		{"DELETE /api/v1/notifications/push-subscriptions", h.reminders.DeletePushSubscription, signedIn},
+
+		// Member preferences - color, default view, week start, clock and
+		// notifications, which calendar and task responses also carry
+		{"GET /api/v1/me/preferences", h.preferences.GetPreferences, signedIn},
+		{"PATCH /api/v1/me/preferences", h.preferences.UpdatePreferences, signedIn},
+
+		// Notification preferences - each member's own daily digest settings
+		{"GET /api/v1/notification-preferences", h.notificationPreferences.GetPreferences, signedIn},
+		{"PATCH /api/v1/notification-preferences", h.notificationPreferences.UpdatePreferences, signedIn},
+
+		// Points and rewards - points are earned on scheduled tasks and spent on
+		// rewards that parents define and approve
+		{"GET /api/v1/points", h.rewards.GetBalances, rewardsRead},
+		{"GET /api/v1/points/history", h.rewards.GetHistory, rewardsRead},
+		{"POST /api/v1/points/adjustments", h.rewards.AdjustPoints, rewardsApprove},
+		{"GET /api/v1/rewards", h.rewards.ListRewards, rewardsRead},
+		{"POST /api/v1/rewards", h.rewards.CreateReward, rewardsManage},
+		{"PATCH /api/v1/rewards/{rewardID}", h.rewards.UpdateReward, rewardsManage},
+		{"DELETE /api/v1/rewards/{rewardID}", h.rewards.ArchiveReward, rewardsManage},
+		{"POST /api/v1/rewards/{rewardID}/redeem", h.rewards.RedeemReward, rewardsRead},
+		{"GET /api/v1/redemptions", h.rewards.ListRedemptions, rewardsRead},
+		{"POST /api/v1/redemptions/{redemptionID}/approve", h.rewards.ApproveRedemption, rewardsApprove},
+		{"POST /api/v1/redemptions/{redemptionID}/reject", h.rewards.RejectRedemption, rewardsApprove},
+
+		// Meal planning - recipes, the week's meals (dinners go on the calendar) and
+		// a shopping list that anyone, the kitchen display included, can tick off
+		{"GET /api/v1/meals/recipes", h.meals.ListRecipes, mealsRead},
+		{"POST /api/v1/meals/recipes", h.meals.CreateRecipe, mealsPlan},
+		{"GET /api/v1/meals/recipes/{recipeID}", h.meals.GetRecipe, mealsRead},
+		{"PATCH /api/v1/meals/recipes/{recipeID}", h.meals.UpdateRecipe, mealsPlan},
+		{"DELETE /api/v1/meals/recipes/{recipeID}", h.meals.DeleteRecipe, mealsPlan},
+		{"GET /api/v1/meals/plan", h.meals.GetMealPlan, mealsRead},
+		{"PUT /api/v1/meals/plan", h.meals.PlanMeal, mealsPlan},
+		{"DELETE /api/v1/meals/plan/{mealID}", h.meals.RemoveMeal, can(auth.EntityCalendar, auth.ActionCreate).behind(models.FeatureMeals)},
+		{"GET /api/v1/meals/shopping-list", h.meals.ListShoppingList, mealsRead},
+		{"POST /api/v1/meals/shopping-list", h.meals.AddShoppingListItem, mealsRead.and(auth.EntityTask, auth.ActionUpdate)},
+		{"POST /api/v1/meals/shopping-list/generate", h.meals.GenerateShoppingList, shoppingList},
+		{"PATCH /api/v1/meals/shopping-list/{itemID}", h.meals.UpdateShoppingListItem, shoppingList},
+		{"DELETE /api/v1/meals/shopping-list/{itemID}", h.meals.DeleteShoppingListItem, shoppingList},
+
+		// Announcements board - parents post, everyone reads and leaves a receipt
+		{"GET /api/v1/announcements", h.announcements.ListAnnouncements, can(auth.EntityFamily, auth.ActionRead)},
+		{"POST /api/v1/announcements", h.announcements.CreateAnnouncement, can(auth.EntityFamily, auth.ActionRead).and(auth.EntityFamily, auth.ActionUpdate)},
+		{"GET /api/v1/announcements/unseen", h.announcements.ListUnseen, can(auth.EntityFamily, auth.ActionRead)},
+		{"GET /api/v1/announcements/{announcementID}", h.announcements.GetAnnouncement, can(auth.EntityFamily, auth.ActionRead)},
+		{"PATCH /api/v1/announcements/{announcementID}", h.announcements.UpdateAnnouncement, can(auth.EntityFamily, auth.ActionRead).and(auth.EntityFamily, auth.ActionUpdate)},
+		{"DELETE /api/v1/announcements/{announcementID}", h.announcements.DeleteAnnouncement, can(auth.EntityFamily, auth.ActionRead).and(auth.EntityFamily, auth.ActionUpdate)},
+		{"POST /api/v1/announcements/{announcementID}/read", h.announcements.MarkRead, can(auth.EntityFamily, auth.ActionRead)},
+		{"GET /api/v1/announcements/{announcementID}/reads", h.announcements.GetReads, can(auth.EntityFamily, auth.ActionRead).and(auth.EntityFamily, auth.ActionUpdate)},
+
+		// Search across tasks, events and schedules; results are limited to what the session can read
+		{"GET /api/v1/search", h.search.Search, signedIn},
+
+		// Real-time updates for the session's family, so open dashboards stay
+		// in sync without polling; the WebSocket room adds live task moves
+		{"GET /api/v1/stream", h.stream.Stream, signedIn},
+		{"GET /api/v1/ws", h.realtime.Connect, signedIn},
+
+		// Suggestions - nudges from the suggestion engine, applied or dismissed by parents
+		{"GET /api/v1/suggestions", h.suggestions.ListSuggestions, suggestionsRead},
+		{"GET /api/v1/suggestions/digest", h.suggestions.GetDigest, suggestionsRead},
+		{"POST /api/v1/suggestions/refresh", h.suggestions.RefreshSuggestions, suggestionsManage},
+		{"POST /api/v1/suggestions/{suggestionID}/apply", h.suggestions.ApplySuggestion, suggestionsManage},
+		{"POST /api/v1/suggestions/{suggestionID}/dismiss", h.suggestions.DismissSuggestion, suggestionsManage},
+
+		// Insights - busy-hours heatmap and recurring slots that are usually free
+		{"GET /api/v1/insights/availability", h.insights.GetAvailability, can(auth.EntityCalendar, auth.ActionRead).behind(models.FeatureInsights)},
+
+		// Storage usage - per-family and per-member totals against the quota
+		{"GET /api/v1/usage", h.usage.GetUsage, can(auth.EntityFamily, auth.ActionRead)},
+		{"PUT /api/v1/usage/quota", h.usage.UpdateQuota, can(auth.EntityFamily, auth.ActionUpdate)},
+
+		// Attachments - files streamed to the configured storage backend
+		{"POST /api/v1/attachments", h.attachments.UploadAttachment, can(auth.EntityTask, auth.ActionCreate)},
+		{"GET /api/v1/attachments/{objectID}", h.attachments.GetAttachment, can(auth.EntityTask, auth.ActionRead)},
+		{"DELETE /api/v1/attachments/{objectID}", h.attachments.DeleteAttachment, can(auth.EntityTask, auth.ActionUpdate)},
+
+		// Custody - which household members split their time with, readable in shared mode
+		{"GET /api/v1/custody", h.custody.GetCalendar, can(auth.EntityCalendar, auth.ActionRead)},
+		{"POST /api/v1/custody/check", h.custody.CheckConflicts, can(auth.EntityCalendar, auth.ActionRead)},
+		{"GET /api/v1/custody/schedules", h.custody.ListSchedules, can(auth.EntityFamily, auth.ActionRead)},
+		{"POST /api/v1/custody/schedules", h.custody.CreateSchedule, can(auth.EntityFamily, auth.ActionRead).and(auth.EntityFamily, auth.ActionUpdate)},
+		{"PATCH /api/v1/custody/schedules/{scheduleID}", h.custody.UpdateSchedule, can(auth.EntityFamily, auth.ActionUpdate)},
+		{"DELETE /api/v1/custody/schedules/{scheduleID}", h.custody.DeleteSchedule, can(auth.EntityFamily, auth.ActionUpdate)},
+		{"GET /api/v1/custody/overrides", h.custody.ListOverrides, can(auth.EntityFamily, auth.ActionRead)},
+		{"PUT /api/v1/custody/overrides", h.custody.SetOverride, can(auth.EntityFamily, auth.ActionRead).and(auth.EntityFamily, auth.ActionUpdate)},
+		{"DELETE /api/v1/custody/overrides/{overrideID}", h.custody.DeleteOverride, can(auth.EntityFamily, auth.ActionUpdate)},
+
+		// Google Calendar - connecting and disconnecting takes the integration permissions
+		{"POST /oauth/google/connect/configure", h.oauth.HandleGoogleConnectWithConfig, can(auth.EntityIntegration, auth.ActionCreate)},
+		{"GET /oauth/google/connect", h.oauth.HandleGoogleConnect, can(auth.EntityIntegration, auth.ActionCreate)},
+		{"GET /oauth/google/callback", h.oauth.HandleGoogleCallback, public}, // checked against the OAuth state
+		{"POST /oauth/disconnect/{provider}", h.oauth.HandleDisconnectProvider, can(auth.EntityIntegration, auth.ActionDelete)},
+		{"GET /calendar-settings", h.oauth.HandleCalendarSettings, signedIn},
+		{"POST /api/calendar/sync-now", h.oauth.HandleSyncNow, integrationUpdate},
+
+		// Integrations - reading them takes integration:read, changing them
+		// the create, update or delete permissions
+		{"GET /api/v1/integrations", h.integrations.ListIntegrations, integrationRead},
+		{"POST /api/v1/integrations", h.integrations.CreateIntegration, can(auth.EntityIntegration, auth.ActionCreate)},
+		{"GET /api/v1/integrations/{integrationID}", h.integrations.GetIntegration, integrationRead},
+		{"PATCH /api/v1/integrations/{integrationID}", h.integrations.UpdateIntegration, integrationUpdate},
+		{"DELETE /api/v1/integrations/{integrationID}", h.integrations.DeleteIntegration, can(auth.EntityIntegration, auth.ActionDelete)},
+		{"POST /api/v1/integrations/{integrationID}/sync", h.integrations.SyncIntegration, integrationUpdate},
+		{"GET /api/v1/integrations/{integrationID}/sync-history", h.integrations.ListSyncHistory, integrationRead},
+		{"POST /api/v1/integrations/{integrationID}/test", h.integrations.TestIntegration, integrationUpdate},
+		{"POST /api/v1/integrations/{integrationID}/oauth/initiate", h.integrations.InitiateOAuth, integrationUpdate},
+		{"GET /api/v1/integrations/{integrationID}/conflicts", h.syncConflicts.ListConflicts, integrationRead},
+		{"POST /api/v1/integrations/{integrationID}/conflicts/{conflictID}/resolve", h.syncConflicts.ResolveConflict, integrationUpdate},
+		// The secrets let anyone act as the integration, so seeing them takes
+		// the update permission
+		{"GET /api/v1/integrations/{integrationID}/webhook", h.integrations.GetWebhook, integrationUpdate},
+		{"POST /api/v1/integrations/{integrationID}/webhook/rotate", h.integrations.RotateWebhook, integrationUpdate},
+
+		// Inbound webhooks from IFTTT and Zapier authenticate with the token in
+		// their URL rather than a session
+		{"POST /api/v1/webhooks/{integrationID}/{token}/{kind}", h.webhooks.HandleTrigger, public},
+
+		// Feature flags - read by the app at boot; families switch modules on and
+		// off for themselves, admins for everyone
+		{"GET /api/v1/features", h.features.ListFeatures, signedIn},
+		{"PUT /api/v1/features/{key}", h.features.SetFeature, can(auth.EntitySetting, auth.ActionUpdate)},
+
+		// Configuration - reading it takes user:read, changing it user:update
+		{"GET /api/v1/config", h.config.GetConfig, can(auth.EntityUser, auth.ActionRead)},
+		{"GET /api/v1/config/oauth/{provider}", h.config.GetOAuthProvider, can(auth.EntityUser, auth.ActionRead)},
+		{"PUT /api/v1/config/oauth/{provider}", h.config.UpdateOAuthProvider, can(auth.EntityUser, auth.ActionUpdate)},
+		{"PATCH /api/v1/config/oauth/{provider}", h.config.UpdateOAuthProvider, can(auth.EntityUser, auth.ActionUpdate)},
+		{"PUT /api/v1/config/server", h.config.UpdateServerConfig, can(auth.EntityUser, auth.ActionUpdate)},
+		{"PATCH /api/v1/config/server", h.config.UpdateServerConfig, can(auth.EntityUser, auth.ActionUpdate)},
+		{"PUT /api/v1/config/features", h.config.UpdateFeatureConfig, can(auth.EntityUser, auth.ActionUpdate)},
+		{"PATCH /api/v1/config/features", h.config.UpdateFeatureConfig, can(auth.EntityUser, auth.ActionUpdate)},
+		{"POST /api/v1/config/reload", h.config.ReloadConfig, can(auth.EntityUser, auth.ActionUpdate)},
+		{"GET /api/v1/config/cors", h.config.GetCORSConfig, can(auth.EntityUser, auth.ActionRead)},
+		{"PUT /api/v1/config/cors", h.config.UpdateCORSConfig, can(auth.EntityUser, auth.ActionUpdate)},
+
+		// Admin jobs - settings access is limited to admins
+		{"GET /api/v1/admin/jobs", h.jobs.ListJobs, settingsRead},
+		{"GET /api/v1/admin/jobs/{jobID}", h.jobs.GetJob, settingsRead},
+		{"GET /api/v1/admin/jobs/{jobID}/logs", h.jobs.GetJobLogs, settingsRead},
+		{"POST /api/v1/admin/jobs/{jobID}/retry", h.jobs.RetryJob, settingsUpdate},
+		{"POST /api/v1/admin/jobs/{jobID}/cancel", h.jobs.CancelJob, settingsUpdate},
+		{"GET /api/v1/admin/queues", h.jobs.GetQueues, settingsRead},
+		{"GET /api/v1/admin/rate-limits", h.rateLimits.GetRateLimits, settingsRead},
+
+		// Guest passes for babysitters and grandparents
+		{"GET /api/v1/guest-passes", h.guestPasses.ListPasses, can(auth.EntityFamily, auth.ActionUpdate)},
+		{"POST /api/v1/guest-passes", h.guestPasses.CreatePass, can(auth.EntityFamily, auth.ActionUpdate)},
+		{"DELETE /api/v1/guest-passes/{passID}", h.guestPasses.RevokePass, can(auth.EntityFamily, auth.ActionUpdate)},
+		{"POST /api/v1/guest-passes/{passID}/extend", h.guestPasses.ExtendPass, can(auth.EntityFamily, auth.ActionUpdate)},
+
+		// API tokens for scripts and home automation, each member's own
+		{"GET /api/v1/tokens", h.apiTokens.ListTokens, signedIn},
+		{"POST /api/v1/tokens", h.apiTokens.CreateToken, signedIn},
+		{"DELETE /api/v1/tokens/{tokenID}", h.apiTokens.RevokeToken, signedIn},
+
+		// Sign-in audit log and lockouts
+		{"GET /api/v1/admin/login-attempts", h.loginSecurity.ListLoginAttempts, settingsRead},
+		{"GET /api/v1/admin/lockouts", h.loginSecurity.ListLockouts, settingsRead},
+		{"DELETE /api/v1/admin/lockouts", h.loginSecurity.ClearLockout, settingsUpdate},
+
+		// Admin digest templates
+		{"GET /api/v1/admin/templates", h.templates.ListTemplates, settingsRead},
+		{"GET /api/v1/admin/templates/preview", h.templates.Preview, settingsRead},
+
+		// Usage analytics - opt-in, aggregate counts kept in the local database only
+		{"POST /api/v1/analytics/beacon", h.analytics.Beacon, signedIn},
+		{"GET /api/v1/admin/analytics", h.analytics.GetUsage, settingsRead},
+		{"DELETE /api/v1/admin/analytics", h.analytics.ClearUsage, settingsUpdate},
+	}...)
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"famstack/internal/auth"
+)
+
+// sessionOnly lists the API routes that don't check an entity permission,
+// and why. Adding a route here is a decision to review, not a way to make
+// this test pass.
+var sessionOnly = map[string]string{
+	"GET /api/v1/families/{familyID}":                                 "shared displays read the family; the handler keeps members to their own",
+	"GET /api/v1/families/{familyID}/settings":                        "shared displays read the family settings",
+	"GET /api/v1/families/{familyID}/members":                         "shared displays list the members",
+	"GET /api/v1/families/{familyID}/members/{memberID}":              "shared displays show a member",
+	"GET /api/families/{familyID}/members":                            "older prefix of the members list",
+	"GET /api/families/{familyID}/members/{memberID}":                 "older prefix of a member",
+	"GET /api/v1/families/{familyID}/members/{memberID}/celebrations": "birthdays on the shared displays",
+	"GET /api/v1/families/members":                                    "the session's own family",
+	"GET /api/v1/notifications/channels":                              "the member's own delivery channels",
+	"GET /api/v1/notifications/push-subscriptions":                    "the member's own devices",
+	"POST /api/v1/notifications/push-subscriptions":                   "the member's own devices",
+	"DELETE /api/v1/notifications/push-subscriptions":                 "the member's own devices",
+	"GET /api/v1/me/preferences":                                      "the member's own preferences",
+	"PATCH /api/v1/me/preferences":                                    "the member's own preferences",
+	"GET /api/v1/notification-preferences":                            "the member's own digest settings",
+	"PATCH /api/v1/notification-preferences":                          "the member's own digest settings",
+	"GET /api/v1/search":                                              "results are limited to what the session can read",
+	"GET /api/v1/stream":                                              "updates for the session's family",
+	"GET /api/v1/ws":                                                  "updates for the session's family",
+	"GET /api/v1/features":                                            "read by the app at boot",
+	"GET /api/v1/tokens":                                              "the member's own tokens",
+	"POST /api/v1/tokens":                                             "the member's own tokens",
+	"DELETE /api/v1/tokens/{tokenID}":                                 "the member's own tokens",
+	"POST /api/v1/analytics/beacon":                                   "page views of any member",
+	"POST /api/v1/webhooks/{integrationID}/{token}/{kind}":            "public: the token in the path authenticates it",
+}
+
+func isAPIRoute(r route) bool {
+	_, path, _ := strings.Cut(r.pattern, " ")
+	return strings.HasPrefix(path, "/api/")
+}
+
+func TestRouteTable_Valid(t *testing.T) {
+	rt := newRouter()
+	for _, r := range routeTable(routeHandlers{}) {
+		assert.NoError(t, r.validate())
+		assert.NotPanics(t, func() { rt.handleFunc(r.pattern, r.handler) }, "%s conflicts with another route", r.pattern)
+	}
+}
+
+func TestRouteTable_APIRoutesRequirePermissions(t *testing.T) {
+	declared := make(map[string]bool)
+	for _, r := range routeTable(routeHandlers{}) {
+		if !isAPIRoute(r) {
+			continue
+		}
+		declared[r.pattern] = true
+		if _, exempt := sessionOnly[r.pattern]; exempt {
+			assert.Empty(t, r.access.perms, "%s checks permissions, so it doesn't need to be exempt", r.pattern)
+			continue
+		}
+		assert.NotEmpty(t, r.access.perms, "%s checks no entity permission", r.pattern)
+	}
+
+	for pattern := range sessionOnly {
+		assert.True(t, declared[pattern], "exempt route %s isn't in the route table", pattern)
+	}
+}
+
+func TestRouteTable_Permissions(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []permission
+	}{
+		{"PATCH /api/v1/schedules/{scheduleID}", []permission{{auth.EntitySchedule, auth.ActionUpdate}}},
+		{"DELETE /api/v1/schedules/{scheduleID}", []permission{{auth.EntitySchedule, auth.ActionDelete}}},
+		{"PUT /api/v1/schedules/{scheduleID}/profiles", []permission{{auth.EntitySchedule, auth.ActionUpdate}}},
+		{"GET /api/v1/calendar/events/{eventID}", []permission{{auth.EntityCalendar, auth.ActionRead}}},
+		{"PUT /api/v1/config/oauth/{provider}", []permission{{auth.EntityUser, auth.ActionUpdate}}},
+		{"POST /api/calendar/sync-now", []permission{{auth.EntityIntegration, auth.ActionUpdate}}},
+		{"DELETE /api/v1/admin/analytics", []permission{{auth.EntitySetting, auth.ActionRead}, {auth.EntitySetting, auth.ActionUpdate}}},
+		{"POST /api/v1/tasks", []permission{{auth.EntityTask, auth.ActionRead}, {auth.EntityTask, auth.ActionCreate}}},
+		{"DELETE /api/v1/assignments/{assignmentID}", []permission{{auth.EntityTask, auth.ActionRead}, {auth.EntityTask, auth.ActionDelete}}},
+		{"DELETE /api/v1/reminders/{reminderID}", []permission{{auth.EntityCalendar, auth.ActionDelete}}},
+	}
+
+	routes := make(map[string]route)
+	for _, r := range routeTable(routeHandlers{}) {
+		routes[r.pattern] = r
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			r, ok := routes[tt.pattern]
+			require.True(t, ok, "route not found")
+			assert.Equal(t, tt.want, r.access.perms)
+		})
+	}
+}
+
+var pathParam = regexp.MustCompile(`\{[^}]+\}`)
+
+func TestRouteTable_RejectsRequestsWithoutSession(t *testing.T) {
+	rt := newRouter()
+	authMiddleware := auth.NewMiddleware(nil)
+	reached := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	routes := routeTable(routeHandlers{})
+	for _, r := range routes {
+		rt.handleFunc(r.pattern, reached, r.access.middleware(authMiddleware, nil)...)
+	}
+
+	for _, r := range routes {
+		if !isAPIRoute(r) {
+			continue
+		}
+		method, path, _ := strings.Cut(r.pattern, " ")
+		w := serve(rt, method, pathParam.ReplaceAllString(path, "x1"))
+		if r.access.public {
+			assert.Equal(t, http.StatusTeapot, w.Code, "%s is public", r.pattern)
+		} else {
+			assert.Equal(t, http.StatusUnauthorized, w.Code, "%s lets a request without a session through", r.pattern)
+			assert.JSONEq(t, `{"error":{"code":"unauthorized","message":"Authentication required"}}`, w.Body.String(), r.pattern)
+		}
+	}
+}
@@ -3,24 +3,41 @@ package server
 import (
 	"context"
 	"fmt"
+	"log"
 	"net/http"
-	"strings"
+	"os"
+	"slices"
 	"time"
 
+	"golang.org/x/crypto/acme/autocert"
+
 	"famstack/internal/auth"
+	"famstack/internal/chaos"
 	"famstack/internal/config"
+	"famstack/internal/csrf"
 	"famstack/internal/handlers"
 	"famstack/internal/handlers/api"
+	"famstack/internal/handlers/caldav"
 	"famstack/internal/jobsystem"
 	"famstack/internal/middleware"
 	"famstack/internal/oauth"
+	"famstack/internal/ratelimit"
+	"famstack/internal/realtime"
+	"famstack/internal/rpc"
 	"famstack/internal/services"
+	"famstack/internal/templates"
+	"famstack/internal/tracing"
+	"famstack/internal/voice"
 )
 
 // Config holds server configuration
 type Config struct {
-	Port string
-	Dev  bool
+	Port  string
+	Dev   bool
+	Chaos *chaos.Injector // fault injection for API routes, dev mode only
+	TLS   config.TLSConfig
+	// RPCPort serves the JSON-RPC API for companion apps; "" leaves it off
+	RPCPort string
 }
 
 // Server represents the HTTP server
@@ -30,7 +47,12 @@ type Server struct {
 	authService     *auth.Service
 	configManager   *config.Manager
 	config          *Config
+	rateLimiter     *ratelimit.Middleware // nil when rate limiting is off
+	secureCookies   bool
+	certManager     *autocert.Manager // nil unless certificates come from Let's Encrypt
 	server          *http.Server
+	redirectServer  *http.Server // nil unless TLS.HTTPPort is set
+	rpcServer       *http.Server // nil unless Config.RPCPort is set
 }
 
 // New creates a new server instance
@@ -42,84 +64,176 @@ func New(serviceRegistry *services.Registry, jobSystem *jobsystem.DBJobSystem, a
 		configManager:   configManager,
 		config:          config,
 	}
+	if rateLimit := configManager.GetConfig().RateLimit; !rateLimit.Disabled {
+		s.rateLimiter = ratelimit.New(rateLimit)
+		s.rateLimiter.SetTokenVerifier(authService.IssuedToken)
+	}
+	serverConfig := configManager.GetConfig().Server
+	serverConfig.TLS = config.TLS
+	s.secureCookies = serverConfig.SecureCookies()
+	csrf.SetSecureCookies(s.secureCookies)
 
 	// Set up routes
-	mux := http.NewServeMux()
-	s.setupRoutes(mux)
-
-	// Wrap with logging middleware
-	loggedHandler := middleware.LoggingMiddleware(mux)
+	rt := newRouter()
+	s.setupRoutes(rt)
+
+	// CalDAV apps authenticate with HTTP Basic and page view beacons can't
+	// set headers, so neither can send a CSRF token; nor can voice assistants,
+	// which call with client credentials or their access token
+	var handler http.Handler = csrf.Protect(rt, caldav.Prefix, "/api/v1/analytics/beacon", "/api/v1/webhooks/",
+		"/oauth/token", "/voice/")
+	if s.rateLimiter != nil {
+		handler = s.rateLimiter.Handler(handler)
+	}
+	if config.Dev && config.Chaos != nil {
+		handler = config.Chaos.Middleware(handler)
+	}
+	// Preflights from other origins carry no credentials or CSRF token, so
+	// they are answered before either is checked
+	handler = middleware.CORS(handler, s.corsConfig)
+
+	// Wrap with logging middleware so injected faults are logged too
+	loggedHandler := middleware.LoggingMiddleware(handler)
+	if serverConfig.TrustProxy {
+		if proxies, err := middleware.ParseProxies(serverConfig.TrustedProxies); err != nil {
+			log.Printf("⚠️  Ignoring proxy headers: %v", err)
+		} else {
+			loggedHandler = proxies.Handler(loggedHandler)
+		}
+	}
 
 	s.server = &http.Server{
 		Addr:         ":" + config.Port,
-		Handler:      loggedHandler,
+		Handler:      tracing.Middleware(loggedHandler),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	s.setupTLS()
+
+	if config.RPCPort != "" {
+		// Companion apps send bearer tokens, never cookies, so the JSON-RPC
+		// API needs no CSRF protection
+		var rpcHandler http.Handler = rpc.NewHandler(serviceRegistry, authService)
+		if s.rateLimiter != nil {
+			rpcHandler = s.rateLimiter.Handler(rpcHandler)
+		}
+		s.rpcServer = &http.Server{
+			Addr:         ":" + config.RPCPort,
+			Handler:      tracing.Middleware(middleware.LoggingMiddleware(rpcHandler)),
+			ReadTimeout:  15 * time.Second,
+			WriteTimeout: 15 * time.Second,
+			IdleTimeout:  60 * time.Second,
+			TLSConfig:    s.server.TLSConfig,
+		}
+	}
 
 	return s
 }
 
-// Start starts the HTTP server
-func (s *Server) Start() error {
-	return s.server.ListenAndServe()
+// corsConfig returns the current cross-origin settings
+func (s *Server) corsConfig() config.CORSConfig {
+	return s.configManager.GetConfig().CORS
+}
+
+// voiceConfig returns the current voice assistant settings
+func (s *Server) voiceConfig() config.VoiceConfig {
+	return s.configManager.GetConfig().Voice
 }
 
 // Shutdown gracefully shuts down the server
 func (s *Server) Shutdown(ctx context.Context) error {
+	if s.redirectServer != nil {
+		_ = s.redirectServer.Shutdown(ctx) // nolint:errcheck
+	}
+	if s.rpcServer != nil {
+		_ = s.rpcServer.Shutdown(ctx) // nolint:errcheck
+	}
 	return s.server.Shutdown(ctx)
 }
 
 // setupRoutes configures the HTTP routes
-func (s *Server) setupRoutes(mux *http.ServeMux) {
+func (s *Server) setupRoutes(rt *router) {
+	// OAuth and Calendar integration; the provider config follows changes
+	// to the OAuth settings
+	oauthService := oauth.NewService(s.serviceRegistry.OAuth, oauth.ConfigFrom(s.configManager.GetConfig().OAuth), s.serviceRegistry.GetEncryptionService())
+	s.configManager.Subscribe(func(old, updated *config.Config) {
+		if slices.Contains(config.Changed(old, updated), "oauth") {
+			oauthService.SetConfig(oauth.ConfigFrom(updated.OAuth))
+		}
+	})
+	// Templates are re-read on every request in development
+	renderer := handlers.NewRenderer(os.DirFS(handlers.TemplatesDir), s.config.Dev)
+
 	// Initialize handlers with services from the registry
+	h := routeHandlers{
+		tasks:                   api.NewTaskAPIHandler(s.serviceRegistry.Tasks, s.serviceRegistry.Assignments, s.serviceRegistry.Analytics, s.serviceRegistry.Preferences),
+		assignments:             api.NewAssignmentsAPIHandler(s.serviceRegistry.Assignments),
+		activities:              api.NewActivitiesAPIHandler(s.serviceRegistry.Activities),
+		carpools:                api.NewCarpoolsAPIHandler(s.serviceRegistry.Carpools),
+		families:                api.NewFamilyAPIHandler(s.serviceRegistry.Families),
+		familyMembers:           api.NewFamilyMemberAPIHandler(s.serviceRegistry.FamilyMembers, s.serviceRegistry.Activities, s.serviceRegistry.Calendar),
+		schedules:               api.NewScheduleHandlerWithJobSystem(s.serviceRegistry.Schedules, s.jobSystem),
+		scheduleProfiles:        api.NewScheduleProfilesAPIHandler(s.serviceRegistry.ScheduleProfiles, s.serviceRegistry.Schedules),
+		calendar:                api.NewCalendarAPIHandler(s.serviceRegistry.Calendar, s.serviceRegistry.ProtectedBlocks, s.serviceRegistry.Custody, s.serviceRegistry.Weather, s.serviceRegistry.Preferences),
+		protectedBlocks:         api.NewProtectedBlocksAPIHandler(s.serviceRegistry.ProtectedBlocks),
+		eventColorRules:         api.NewEventColorRulesAPIHandler(s.serviceRegistry.EventColorRules),
+		display:                 api.NewDisplayAPIHandler(s.serviceRegistry.Display, s.serviceRegistry.DisplayProjections),
+		dashboard:               api.NewDashboardAPIHandler(s.serviceRegistry.Dashboard),
+		focus:                   api.NewFocusAPIHandler(s.serviceRegistry.Focus),
+		reminders:               api.NewRemindersAPIHandler(s.serviceRegistry.Reminders, s.serviceRegistry.Notifications),
+		notificationPreferences: api.NewNotificationPreferencesAPIHandler(s.serviceRegistry.Notifications),
+		preferences:             api.NewPreferencesAPIHandler(s.serviceRegistry.Preferences),
+		rewards:                 api.NewRewardsAPIHandler(s.serviceRegistry.Rewards),
+		meals:                   api.NewMealsAPIHandler(s.serviceRegistry.Meals),
+		announcements:           api.NewAnnouncementsAPIHandler(s.serviceRegistry.Announcements),
+		imports:                 api.NewImportAPIHandler(s.serviceRegistry.Import),
+		search:                  api.NewSearchAPIHandler(s.serviceRegistry.Search),
+		stream:                  api.NewStreamAPIHandler(s.serviceRegistry.Events),
+		realtime:                api.NewRealtimeAPIHandler(realtime.NewHub(s.serviceRegistry.Events, s.serviceRegistry.Tasks)),
+		timeline:                api.NewTimelineAPIHandler(s.serviceRegistry.Timeline, s.serviceRegistry.DisplayProjections),
+		suggestions:             api.NewSuggestionsAPIHandler(s.serviceRegistry.Suggestions),
+		usage:                   api.NewUsageAPIHandler(s.serviceRegistry.Storage),
+		attachments:             api.NewAttachmentsAPIHandler(s.serviceRegistry.Attachments),
+		insights:                api.NewInsightsAPIHandler(s.serviceRegistry.Insights),
+		custody:                 api.NewCustodyAPIHandler(s.serviceRegistry.Custody),
+		integrations:            api.NewIntegrationsAPIHandlerWithJobSystem(s.serviceRegistry.Integrations, s.jobSystem),
+		syncConflicts:           api.NewSyncConflictsAPIHandler(s.serviceRegistry.Integrations, s.serviceRegistry.Calendar),
+		webhooks:                api.NewWebhooksAPIHandler(s.serviceRegistry),
+		config:                  api.NewConfigAPIHandler(s.configManager),
+		features:                api.NewFeaturesAPIHandler(s.serviceRegistry.Features),
+		jobs:                    api.NewJobsAPIHandler(s.serviceRegistry.Jobs),
+		templates:               api.NewTemplatesAPIHandler(templates.MustNewRenderer(), s.serviceRegistry.Digests),
+		analytics:               api.NewAnalyticsAPIHandler(s.serviceRegistry.Analytics),
+		rateLimits:              api.NewRateLimitsAPIHandler(s.rateLimiter),
+		permissions:             api.NewPermissionsAPIHandler(s.authService),
+		apiTokens:               api.NewAPITokensAPIHandler(s.authService),
+		guestPasses:             api.NewGuestPassesAPIHandler(s.authService),
+		loginSecurity:           api.NewLoginSecurityAPIHandler(s.authService),
+		oauth:                   handlers.NewOAuthHandlers(oauthService, s.authService, s.jobSystem, s.serviceRegistry.Integrations, renderer),
+	}
 	pageHandler := handlers.NewPageHandler(s.serviceRegistry.GetDB(), s.authService)
-	taskAPIHandler := api.NewTaskAPIHandler(s.serviceRegistry.Tasks)
-	familyAPIHandler := api.NewFamilyAPIHandler(s.serviceRegistry.Families)
-	familyMemberAPIHandler := api.NewFamilyMemberAPIHandler(s.serviceRegistry.FamilyMembers)
-	scheduleAPIHandler := api.NewScheduleHandlerWithJobSystem(s.serviceRegistry.Schedules, s.jobSystem)
-	calendarAPIHandler := api.NewCalendarAPIHandler(s.serviceRegistry.Calendar)
-	integrationsAPIHandler := api.NewIntegrationsAPIHandler(s.serviceRegistry.Integrations)
-	configAPIHandler := api.NewConfigAPIHandler(s.configManager)
+	caldavHandler := caldav.NewHandler(s.serviceRegistry.Calendar, s.serviceRegistry.Families, s.serviceRegistry.FamilyMembers)
 	authHandler := auth.NewHandlers(s.authService)
+	authHandler.SetSecureCookies(s.secureCookies)
 	authMiddleware := auth.NewMiddleware(s.authService)
 
-	// OAuth and Calendar integration
-	// Get OAuth configuration from config manager
-	googleConfig, err := s.configManager.GetOAuthProvider("google")
-	if err != nil {
-		googleConfig = nil
-	}
-
-	var oauthConfig *oauth.OAuthConfig
-	if googleConfig != nil && googleConfig.Configured {
-		oauthConfig = &oauth.OAuthConfig{
-			Google: &oauth.GoogleConfig{
-				ClientID:     googleConfig.ClientID,
-				ClientSecret: googleConfig.ClientSecret,
-				RedirectURL:  googleConfig.RedirectURL,
-				Scopes:       googleConfig.Scopes,
-			},
-		}
-	} else {
-		oauthConfig = &oauth.OAuthConfig{} // Empty config
-	}
-	oauthService := oauth.NewService(s.serviceRegistry.OAuth, oauthConfig, s.serviceRegistry.GetEncryptionService())
-	oauthHandler := handlers.NewOAuthHandlers(oauthService, s.authService, s.jobSystem, s.serviceRegistry.Integrations)
+	// Pages the single page app routes on the client, the root included,
+	// are served by the fallback rather than listed here
+	rt.fallback = http.HandlerFunc(pageHandler.ServePage)
 
 	// Static file serving
-	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("web/static/"))))
+	rt.handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("web/static/"))))
 
 	// Health check endpoint
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+	rt.handleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		fmt.Fprintf(w, `{"status":"ok","message":"Fam-Stack is running"}`)
 	})
 
 	// Debug endpoint to test task data server-side
-	mux.HandleFunc("/debug/tasks", func(w http.ResponseWriter, r *http.Request) {
+	rt.handleFunc("GET /debug/tasks", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html")
 
 		// We'll create a simple HTML page showing the task data
@@ -147,328 +261,50 @@ func (s *Server) setupRoutes(mux *http.ServeMux) {
 		`)
 	})
 
-	// SPA routes - serve the same HTML file for all page routes
-	// The client-side router will handle authentication and routing
-	pageRoutes := []string{"/", "/login", "/tasks", "/daily", "/family", "/family/setup", "/schedules", "/integrations", "/calendar-dev"}
-	for _, route := range pageRoutes {
-		mux.HandleFunc(route, pageHandler.ServePage)
+	// The API, checked against the access each route declares
+	for _, route := range routeTable(h) {
+		if err := route.validate(); err != nil {
+			panic(err)
+		}
+		rt.handleFunc(route.pattern, route.handler, route.access.middleware(authMiddleware, h.features)...)
 	}
 
-	// JSON API routes - protected with authentication
-	mux.Handle("/api/v1/tasks", authMiddleware.RequireEntityAction(auth.EntityTask, auth.ActionRead)(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			switch r.Method {
-			case "GET":
-				taskAPIHandler.ListTasks(w, r)
-			case "POST":
-				authMiddleware.RequireEntityAction(auth.EntityTask, auth.ActionCreate)(
-					http.HandlerFunc(taskAPIHandler.CreateTask)).ServeHTTP(w, r)
-			default:
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			}
-		})))
-
-	mux.Handle("/api/v1/tasks/", authMiddleware.RequireAuth(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			switch r.Method {
-			case "PATCH":
-				authMiddleware.RequireEntityAction(auth.EntityTask, auth.ActionUpdate)(
-					http.HandlerFunc(taskAPIHandler.UpdateTask)).ServeHTTP(w, r)
-			case "DELETE":
-				authMiddleware.RequireEntityAction(auth.EntityTask, auth.ActionDelete)(
-					http.HandlerFunc(taskAPIHandler.DeleteTask)).ServeHTTP(w, r)
-			default:
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			}
-		})))
-
-	// Family collection API routes
-	mux.Handle("/api/v1/families", authMiddleware.RequireAuth(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Only handle exact path match for collection operations
-			if r.URL.Path != "/api/v1/families" {
-				http.Error(w, "Not found", http.StatusNotFound)
-				return
-			}
-
-			switch r.Method {
-			case "GET":
-				authMiddleware.RequireEntityAction(auth.EntityFamily, auth.ActionRead)(
-					http.HandlerFunc(familyAPIHandler.ListFamilies)).ServeHTTP(w, r)
-			case "POST":
-				authMiddleware.RequireEntityAction(auth.EntityFamily, auth.ActionCreate)(
-					http.HandlerFunc(familyAPIHandler.CreateFamily)).ServeHTTP(w, r)
-			default:
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			}
-		})))
-
-	// Individual family and family member API routes
-	mux.Handle("/api/v1/families/", authMiddleware.RequireAuth(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Handle family member routes
-			if strings.Contains(r.URL.Path, "/members") {
-				if strings.HasSuffix(r.URL.Path, "/members") {
-					// /api/v1/families/{family_id}/members
-					switch r.Method {
-					case "GET":
-						familyMemberAPIHandler.ListFamilyMembers(w, r)
-					case "POST":
-						authMiddleware.RequireEntityAction(auth.EntityFamily, auth.ActionUpdate)(
-							http.HandlerFunc(familyMemberAPIHandler.CreateFamilyMember)).ServeHTTP(w, r)
-					default:
-						http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-					}
-				} else {
-					// /api/v1/families/{family_id}/members/{member_id}
-					switch r.Method {
-					case "GET":
-						familyMemberAPIHandler.GetFamilyMember(w, r)
-					case "PATCH":
-						authMiddleware.RequireEntityAction(auth.EntityFamily, auth.ActionUpdate)(
-							http.HandlerFunc(familyMemberAPIHandler.UpdateFamilyMember)).ServeHTTP(w, r)
-					case "DELETE":
-						authMiddleware.RequireEntityAction(auth.EntityFamily, auth.ActionUpdate)(
-							http.HandlerFunc(familyMemberAPIHandler.DeleteFamilyMember)).ServeHTTP(w, r)
-					default:
-						http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-					}
-				}
-				return
-			}
-
-			// Handle specific family by ID (not member routes)
-			switch r.Method {
-			case "GET":
-				familyAPIHandler.GetFamily(w, r)
-			case "PUT":
-				authMiddleware.RequireEntityAction(auth.EntityFamily, auth.ActionUpdate)(
-					http.HandlerFunc(familyAPIHandler.UpdateFamily)).ServeHTTP(w, r)
-			default:
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			}
-		})))
-
-	// NOTE: User routes have been removed as the users table is gone.
-	// User functionality is now handled through family members at hierarchical API
-
-	// Hierarchical Family Member API routes - /api/families/{family_id}/members
-	mux.Handle("/api/families/", authMiddleware.RequireAuth(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Handle hierarchical family member routes
-			if strings.Contains(r.URL.Path, "/members") {
-				if strings.HasSuffix(r.URL.Path, "/members") {
-					// /api/families/{family_id}/members
-					switch r.Method {
-					case "GET":
-						familyMemberAPIHandler.ListFamilyMembers(w, r)
-					case "POST":
-						authMiddleware.RequireEntityAction(auth.EntityFamily, auth.ActionUpdate)(
-							http.HandlerFunc(familyMemberAPIHandler.CreateFamilyMember)).ServeHTTP(w, r)
-					default:
-						http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-					}
-				} else {
-					// /api/families/{family_id}/members/{member_id}
-					switch r.Method {
-					case "GET":
-						familyMemberAPIHandler.GetFamilyMember(w, r)
-					case "PATCH":
-						authMiddleware.RequireEntityAction(auth.EntityFamily, auth.ActionUpdate)(
-							http.HandlerFunc(familyMemberAPIHandler.UpdateFamilyMember)).ServeHTTP(w, r)
-					case "DELETE":
-						authMiddleware.RequireEntityAction(auth.EntityFamily, auth.ActionUpdate)(
-							http.HandlerFunc(familyMemberAPIHandler.DeleteFamilyMember)).ServeHTTP(w, r)
-					default:
-						http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-					}
-				}
-			} else {
-				http.Error(w, "Not found", http.StatusNotFound)
-			}
-		})))
-
-	// Legacy Family Member API routes for backward compatibility - will be deprecated
-	mux.Handle("/api/v1/families/members", authMiddleware.RequireAuth(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			switch r.Method {
-			case "GET":
-				familyMemberAPIHandler.GetFamilyMembersWithStats(w, r)
-			case "POST":
-				authMiddleware.RequireEntityAction(auth.EntityFamily, auth.ActionUpdate)(
-					http.HandlerFunc(familyMemberAPIHandler.CreateFamilyMember)).ServeHTTP(w, r)
-			default:
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			}
-		})))
-
-	mux.Handle("/api/v1/families/members/", authMiddleware.RequireAuth(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			switch r.Method {
-			case "GET":
-				familyMemberAPIHandler.GetFamilyMember(w, r)
-			case "PATCH":
-				authMiddleware.RequireEntityAction(auth.EntityFamily, auth.ActionUpdate)(
-					http.HandlerFunc(familyMemberAPIHandler.UpdateFamilyMember)).ServeHTTP(w, r)
-			case "DELETE":
-				authMiddleware.RequireEntityAction(auth.EntityFamily, auth.ActionUpdate)(
-					http.HandlerFunc(familyMemberAPIHandler.DeleteFamilyMember)).ServeHTTP(w, r)
-			default:
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			}
-		})))
-
-	// Schedule API routes - protected with authentication
-	mux.Handle("/api/v1/schedules", authMiddleware.RequireEntityAction(auth.EntitySchedule, auth.ActionRead)(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			switch r.Method {
-			case "GET":
-				scheduleAPIHandler.ListSchedules(w, r)
-			case "POST":
-				authMiddleware.RequireEntityAction(auth.EntitySchedule, auth.ActionCreate)(
-					http.HandlerFunc(scheduleAPIHandler.CreateSchedule)).ServeHTTP(w, r)
-			default:
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			}
-		})))
-
-	mux.Handle("/api/v1/schedules/", authMiddleware.RequireAuth(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			switch r.Method {
-			case "GET":
-				scheduleAPIHandler.GetSchedule(w, r)
-			case "PATCH":
-				authMiddleware.RequireAuth(
-					http.HandlerFunc(scheduleAPIHandler.UpdateSchedule)).ServeHTTP(w, r)
-			case "DELETE":
-				authMiddleware.RequireAuth(
-					http.HandlerFunc(scheduleAPIHandler.DeleteSchedule)).ServeHTTP(w, r)
-			default:
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			}
-		})))
-
-	// Calendar API routes - protected with authentication
-	mux.Handle("/api/v1/calendar/events", authMiddleware.RequireEntityAction(auth.EntityCalendar, auth.ActionRead)(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			switch r.Method {
-			case "GET":
-				calendarAPIHandler.GetEvents(w, r)
-			case "POST":
-				authMiddleware.RequireEntityAction(auth.EntityCalendar, auth.ActionCreate)(
-					http.HandlerFunc(calendarAPIHandler.CreateEvent)).ServeHTTP(w, r)
-			default:
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			}
-		})))
-
-	mux.Handle("/api/v1/calendar/events/", authMiddleware.RequireAuth(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			switch r.Method {
-			case "GET":
-				calendarAPIHandler.GetEvent(w, r)
-			case "PATCH":
-				authMiddleware.RequireEntityAction(auth.EntityCalendar, auth.ActionUpdate)(
-					http.HandlerFunc(calendarAPIHandler.UpdateEvent)).ServeHTTP(w, r)
-			case "DELETE":
-				authMiddleware.RequireEntityAction(auth.EntityCalendar, auth.ActionDelete)(
-					http.HandlerFunc(calendarAPIHandler.DeleteEvent)).ServeHTTP(w, r)
-			default:
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			}
-		})))
-
-	// Calendar Days API route - new layered calendar endpoint
-	mux.Handle("/api/v1/calendar/days", authMiddleware.RequireEntityAction(auth.EntityCalendar, auth.ActionRead)(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if r.Method == "GET" {
-				calendarAPIHandler.GetCalendarDays(w, r)
-			} else {
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			}
-		})))
-
-	// Authentication API routes
-	mux.HandleFunc("/auth/login", authHandler.HandleLogin)
-	mux.HandleFunc("/auth/logout", authHandler.HandleLogout)
-	mux.HandleFunc("/auth/downgrade", authHandler.HandleDowngrade)
-	mux.HandleFunc("/auth/upgrade", authHandler.HandleUpgrade)
-	mux.HandleFunc("/auth/refresh", authHandler.HandleRefresh)
-	mux.HandleFunc("/auth/me", authHandler.HandleMe)
-
-	// OAuth integration routes - require authentication
-	mux.Handle("/oauth/google/connect/configure", authMiddleware.RequireAuth(http.HandlerFunc(oauthHandler.HandleGoogleConnectWithConfig)))
-	mux.Handle("/oauth/google/connect", authMiddleware.RequireAuth(http.HandlerFunc(oauthHandler.HandleGoogleConnect)))
-	mux.HandleFunc("/oauth/google/callback", oauthHandler.HandleGoogleCallback) // No auth required for callback
-	mux.Handle("/oauth/disconnect/", authMiddleware.RequireAuth(http.HandlerFunc(oauthHandler.HandleDisconnectProvider)))
-	mux.Handle("/calendar-settings", authMiddleware.RequireAuth(http.HandlerFunc(oauthHandler.HandleCalendarSettings)))
-	mux.Handle("/api/calendar/sync-now", authMiddleware.RequireAuth(http.HandlerFunc(oauthHandler.HandleSyncNow)))
-
-	// Integrations API routes - protected with authentication
-	mux.Handle("/api/v1/integrations", authMiddleware.RequireAuth(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			switch r.Method {
-			case "GET":
-				integrationsAPIHandler.ListIntegrations(w, r)
-			case "POST":
-				integrationsAPIHandler.CreateIntegration(w, r)
-			default:
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			}
-		})))
-
-	mux.Handle("/api/v1/integrations/", authMiddleware.RequireAuth(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Check if this is a sub-route like /sync, /test, or /oauth/initiate
-			if r.Method == "POST" {
-				if strings.Contains(r.URL.Path, "/sync") {
-					integrationsAPIHandler.SyncIntegration(w, r)
-					return
-				}
-				if strings.Contains(r.URL.Path, "/test") {
-					integrationsAPIHandler.TestIntegration(w, r)
-					return
-				}
-				if strings.Contains(r.URL.Path, "/oauth/initiate") {
-					integrationsAPIHandler.InitiateOAuth(w, r)
-					return
-				}
-			}
-
-			switch r.Method {
-			case "GET":
-				integrationsAPIHandler.GetIntegration(w, r)
-			case "PATCH":
-				integrationsAPIHandler.UpdateIntegration(w, r)
-			case "DELETE":
-				integrationsAPIHandler.DeleteIntegration(w, r)
-			default:
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			}
-		})))
-
-	// Configuration API routes - protected with authentication (admin only)
-	mux.Handle("/api/v1/config", authMiddleware.RequireEntityAction(auth.EntityUser, auth.ActionRead)(
-		http.HandlerFunc(configAPIHandler.GetConfig)))
-
-	// OAuth provider routes - handle both GET and PUT/PATCH for specific providers
-	mux.Handle("/api/v1/config/oauth/", authMiddleware.RequireAuth(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			switch r.Method {
-			case "GET":
-				configAPIHandler.GetOAuthProvider(w, r)
-			case "PUT", "PATCH":
-				configAPIHandler.UpdateOAuthProvider(w, r)
-			default:
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			}
-		})))
-
-	mux.Handle("/api/v1/config/server", authMiddleware.RequireEntityAction(auth.EntityUser, auth.ActionUpdate)(
-		http.HandlerFunc(configAPIHandler.UpdateServerConfig)))
-
-	mux.Handle("/api/v1/config/features", authMiddleware.RequireEntityAction(auth.EntityUser, auth.ActionUpdate)(
-		http.HandlerFunc(configAPIHandler.UpdateFeatureConfig)))
-
-	// No catch-all route needed - SPA routes are handled above
+	// Authentication API routes, which check their own credentials
+	familyUpdate := authMiddleware.RequireEntityAction(auth.EntityFamily, auth.ActionUpdate)
+	rt.handleFunc("/auth/login", authHandler.HandleLogin)
+	rt.handleFunc("/auth/logout", authHandler.HandleLogout)
+	rt.handleFunc("/auth/downgrade", authHandler.HandleDowngrade)
+	rt.handleFunc("/auth/upgrade", authHandler.HandleUpgrade)
+	rt.handleFunc("/auth/refresh", authHandler.HandleRefresh)
+	rt.handleFunc("/auth/me", authHandler.HandleMe)
+	rt.handleFunc("/auth/magic", authHandler.HandleMagicLogin)
+	rt.handleFunc("/auth/magic/request", authHandler.HandleMagicLinkRequest)
+	rt.handleFunc("/auth/magic/code", authHandler.HandleCreateLoginCode, familyUpdate)
+	rt.handleFunc("/auth/pin", authHandler.HandlePINLogin)
+	rt.handleFunc("/auth/pins", authHandler.HandlePINs, familyUpdate)
+	rt.handleFunc("/auth/devices", authHandler.HandleTrustedDevices, familyUpdate)
+	rt.handleFunc("/auth/invitations", authHandler.HandleInvitations, familyUpdate)
+	rt.handleFunc("/auth/invite/", authHandler.HandleInvite)
+	rt.handleFunc("/auth/csrf", csrf.HandleToken)
+
+	// Guest passes: the page a babysitter's or grandparent's link opens,
+	// checked against the pass rather than a sign-in
+	guestHandler := handlers.NewGuestHandlers(s.authService, s.serviceRegistry.Guests, s.secureCookies)
+	rt.handleFunc("/guest", guestHandler.HandleGuest)
+	rt.handleFunc("/guest/", guestHandler.HandleGuest)
+
+	// Voice assistants: account linking, where a signed-out member signs in
+	// on the consent form, and fulfillment, authenticated by the linked token
+	accountLinkingHandler := handlers.NewAccountLinkingHandlers(s.authService, s.voiceConfig)
+	rt.handleFunc("/oauth/authorize", accountLinkingHandler.HandleAuthorize, authMiddleware.OptionalAuth)
+	rt.handleFunc("/oauth/token", accountLinkingHandler.HandleToken)
+	assistant := voice.NewAssistant(s.serviceRegistry)
+	rt.handle("/voice/alexa", voice.NewAlexaHandler(s.authService, assistant,
+		func() config.AlexaConfig { return s.voiceConfig().Alexa }))
+	rt.handle("/voice/google", voice.NewGoogleHandler(s.authService, assistant,
+		func() config.VoiceClientConfig { return s.voiceConfig().Google }))
+
+	// CalDAV - calendar clients sign in with HTTP Basic auth
+	rt.handleFunc("/.well-known/caldav", caldav.WellKnown)
+	rt.handle(caldav.Prefix, caldavHandler, authMiddleware.RequireBasicAuth("FamStack"))
 }
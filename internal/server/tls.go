@@ -0,0 +1,102 @@
+package server
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// defaultCertCacheDir is where certificates from Let's Encrypt are kept
+// when no cache directory is configured
+const defaultCertCacheDir = "data/certs"
+
+// setupTLS prepares the server to serve HTTPS, when it is configured to,
+// and the plain HTTP listener that sends browsers over to it
+func (s *Server) setupTLS() {
+	tlsConfig := s.config.TLS
+	if !tlsConfig.Enabled() {
+		return
+	}
+
+	if tlsConfig.CertFile == "" {
+		cacheDir := tlsConfig.AutocertCacheDir
+		if cacheDir == "" {
+			cacheDir = defaultCertCacheDir
+		}
+		s.certManager = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(tlsConfig.AutocertDomains...),
+			Cache:      autocert.DirCache(cacheDir),
+			Email:      tlsConfig.AutocertEmail,
+		}
+		s.server.TLSConfig = s.certManager.TLSConfig()
+	}
+
+	if tlsConfig.HTTPPort != "" {
+		var handler http.Handler = http.HandlerFunc(s.redirectToHTTPS)
+		if s.certManager != nil {
+			// Answers HTTP challenges and hands everything else on
+			handler = s.certManager.HTTPHandler(handler)
+		}
+		s.redirectServer = &http.Server{
+			Addr:         ":" + tlsConfig.HTTPPort,
+			Handler:      handler,
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 5 * time.Second,
+		}
+	}
+}
+
+// Start starts the HTTP server, or the HTTPS server when TLS is configured,
+// and the JSON-RPC server beside it
+func (s *Server) Start() error {
+	tlsConfig := s.config.TLS
+	if s.rpcServer != nil {
+		go func() {
+			var err error
+			if tlsConfig.Enabled() {
+				err = s.rpcServer.ListenAndServeTLS(tlsConfig.CertFile, tlsConfig.KeyFile)
+			} else {
+				err = s.rpcServer.ListenAndServe()
+			}
+			if err != nil && err != http.ErrServerClosed {
+				log.Printf("JSON-RPC server failed: %v", err)
+			}
+		}()
+	}
+	if !tlsConfig.Enabled() {
+		return s.server.ListenAndServe()
+	}
+
+	if s.redirectServer != nil {
+		go func() {
+			if err := s.redirectServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("HTTP redirect server failed: %v", err)
+			}
+		}()
+	}
+	// Certificate files are empty when the certificate manager supplies them
+	return s.server.ListenAndServeTLS(tlsConfig.CertFile, tlsConfig.KeyFile)
+}
+
+// redirectToHTTPS sends a plain HTTP request to the same page over HTTPS, at
+// the public URL when one is set
+func (s *Server) redirectToHTTPS(w http.ResponseWriter, r *http.Request) {
+	if publicURL := s.configManager.GetConfig().Server.PublicURL; strings.HasPrefix(publicURL, "https://") {
+		http.Redirect(w, r, strings.TrimSuffix(publicURL, "/")+r.URL.RequestURI(), http.StatusMovedPermanently)
+		return
+	}
+
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if s.config.Port != "443" {
+		host = net.JoinHostPort(host, s.config.Port)
+	}
+	http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+}
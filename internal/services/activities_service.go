@@ -0,0 +1,529 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/ids"
+	"famstack/internal/models"
+)
+
+// ActivitiesService manages the extracurricular activity registry. Activities
+// own their generated practice events and an equipment checklist task.
+type ActivitiesService struct {
+	db    *database.Fascade
+	tasks *TasksService
+}
+
+// NewActivitiesService creates a new activities service
+func NewActivitiesService(db *database.Fascade, tasks *TasksService) *ActivitiesService {
+	return &ActivitiesService{db: db, tasks: tasks}
+}
+
+const activityColumns = `a.id, a.family_id, a.member_id, a.name, a.team, a.coach_name, a.coach_phone, a.coach_email,
+	a.season_start, a.season_end, a.practice_days, a.practice_start_time, a.practice_end_time, a.location,
+	a.equipment, a.fee_cents, a.fee_paid, a.color, a.checklist_task_id, a.created_by, a.created_at, a.updated_at,
+	(SELECT COUNT(*) FROM activity_practice_events pe WHERE pe.activity_id = a.id)`
+
+// ListActivities returns a family's activities, optionally limited to one child
+func (s *ActivitiesService) ListActivities(familyID, memberID string) ([]models.Activity, error) {
+	query := `SELECT ` + activityColumns + ` FROM activities a WHERE a.family_id = ?`
+	args := []any{familyID}
+	if memberID != "" {
+		query += " AND a.member_id = ?"
+		args = append(args, memberID)
+	}
+	query += " ORDER BY a.season_start DESC, a.name"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query activities: %w", err)
+	}
+	defer rows.Close()
+
+	activities := []models.Activity{}
+	for rows.Next() {
+		activity, err := scanActivity(rows)
+		if err != nil {
+			return nil, err
+		}
+		activities = append(activities, *activity)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating activities: %w", err)
+	}
+
+	return activities, nil
+}
+
+// GetActivity returns an activity by ID
+func (s *ActivitiesService) GetActivity(activityID string) (*models.Activity, error) {
+	row := s.db.QueryRow(`SELECT `+activityColumns+` FROM activities a WHERE a.id = ?`, activityID)
+	activity, err := scanActivity(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("activity not found")
+	}
+	return activity, err
+}
+
+// CreateActivity registers an activity, generates its practice events and,
+// when equipment is listed, an equipment checklist task for the child
+func (s *ActivitiesService) CreateActivity(familyID, createdBy string, req *models.CreateActivityRequest) (*models.Activity, error) {
+	activity := &models.Activity{
+		FamilyID:          familyID,
+		MemberID:          req.MemberID,
+		Name:              strings.TrimSpace(req.Name),
+		Team:              strings.TrimSpace(req.Team),
+		CoachName:         strings.TrimSpace(req.CoachName),
+		CoachPhone:        strings.TrimSpace(req.CoachPhone),
+		CoachEmail:        strings.TrimSpace(req.CoachEmail),
+		SeasonStart:       req.SeasonStart,
+		SeasonEnd:         req.SeasonEnd,
+		PracticeDays:      normalizeWeekdays(req.PracticeDays),
+		PracticeStartTime: req.PracticeStartTime,
+		PracticeEndTime:   req.PracticeEndTime,
+		Location:          strings.TrimSpace(req.Location),
+		Equipment:         normalizeEquipment(req.Equipment),
+		FeeCents:          req.FeeCents,
+		FeePaid:           req.FeePaid,
+		Color:             req.Color,
+	}
+	if activity.Color == "" {
+		activity.Color = "#10b981"
+	}
+
+	if err := models.ValidateActivity(activity); err != nil {
+		return nil, err
+	}
+
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM family_members WHERE id = ? AND family_id = ?`, req.MemberID, familyID).Scan(&count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check family member: %w", err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("family member not found")
+	}
+
+	daysJSON, equipmentJSON, err := marshalActivityLists(activity)
+	if err != nil {
+		return nil, err
+	}
+
+	var createdByValue any
+	if createdBy != "" {
+		createdByValue = createdBy
+	}
+
+	activity.ID = ids.New("activity")
+	now := time.Now().UTC()
+
+	_, err = s.db.Exec(`
+		INSERT INTO activities (id, family_id, member_id, name, team, coach_name, coach_phone, coach_email,
+		                        season_start, season_end, practice_days, practice_start_time, practice_end_time,
+		                        location, equipment, fee_cents, fee_paid, color, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, activity.ID, familyID, activity.MemberID, activity.Name, activity.Team, activity.CoachName,
+		activity.CoachPhone, activity.CoachEmail, activity.SeasonStart, activity.SeasonEnd, daysJSON,
+		activity.PracticeStartTime, activity.PracticeEndTime, activity.Location, equipmentJSON,
+		activity.FeeCents, activity.FeePaid, activity.Color, createdByValue, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create activity: %w", err)
+	}
+
+	if err := s.regeneratePracticeEvents(activity, createdBy); err != nil {
+		return nil, err
+	}
+	if err := s.syncChecklistTask(activity, createdBy); err != nil {
+		return nil, err
+	}
+
+	return s.GetActivity(activity.ID)
+}
+
+// UpdateActivity applies the provided fields. Practice events are rebuilt when
+// the season or practice schedule changes, and the checklist task follows the equipment list.
+func (s *ActivitiesService) UpdateActivity(activityID, updatedBy string, req *models.UpdateActivityRequest) (*models.Activity, error) {
+	existing, err := s.GetActivity(activityID)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := *existing
+	if req.Name != nil {
+		merged.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Team != nil {
+		merged.Team = strings.TrimSpace(*req.Team)
+	}
+	if req.CoachName != nil {
+		merged.CoachName = strings.TrimSpace(*req.CoachName)
+	}
+	if req.CoachPhone != nil {
+		merged.CoachPhone = strings.TrimSpace(*req.CoachPhone)
+	}
+	if req.CoachEmail != nil {
+		merged.CoachEmail = strings.TrimSpace(*req.CoachEmail)
+	}
+	if req.SeasonStart != nil {
+		merged.SeasonStart = *req.SeasonStart
+	}
+	if req.SeasonEnd != nil {
+		merged.SeasonEnd = *req.SeasonEnd
+	}
+	if req.PracticeDays != nil {
+		merged.PracticeDays = normalizeWeekdays(*req.PracticeDays)
+	}
+	// An empty string clears the practice time
+	if req.PracticeStartTime != nil {
+		merged.PracticeStartTime = optionalString(*req.PracticeStartTime)
+	}
+	if req.PracticeEndTime != nil {
+		merged.PracticeEndTime = optionalString(*req.PracticeEndTime)
+	}
+	if req.Location != nil {
+		merged.Location = strings.TrimSpace(*req.Location)
+	}
+	if req.Equipment != nil {
+		merged.Equipment = normalizeEquipment(*req.Equipment)
+	}
+	if req.FeeCents != nil {
+		merged.FeeCents = *req.FeeCents
+	}
+	if req.FeePaid != nil {
+		merged.FeePaid = *req.FeePaid
+	}
+	if req.Color != nil {
+		merged.Color = *req.Color
+	}
+
+	if err := models.ValidateActivity(&merged); err != nil {
+		return nil, err
+	}
+
+	daysJSON, equipmentJSON, err := marshalActivityLists(&merged)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE activities
+		SET name = ?, team = ?, coach_name = ?, coach_phone = ?, coach_email = ?, season_start = ?, season_end = ?,
+		    practice_days = ?, practice_start_time = ?, practice_end_time = ?, location = ?, equipment = ?,
+		    fee_cents = ?, fee_paid = ?, color = ?, updated_at = ?
+		WHERE id = ?
+	`, merged.Name, merged.Team, merged.CoachName, merged.CoachPhone, merged.CoachEmail, merged.SeasonStart,
+		merged.SeasonEnd, daysJSON, merged.PracticeStartTime, merged.PracticeEndTime, merged.Location,
+		equipmentJSON, merged.FeeCents, merged.FeePaid, merged.Color, time.Now().UTC(), activityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update activity: %w", err)
+	}
+
+	if practiceScheduleChanged(existing, &merged) {
+		if err := s.regeneratePracticeEvents(&merged, updatedBy); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.syncChecklistTask(&merged, updatedBy); err != nil {
+		return nil, err
+	}
+
+	return s.GetActivity(activityID)
+}
+
+// DeleteActivity removes an activity with its practice events and checklist task
+func (s *ActivitiesService) DeleteActivity(activityID string) error {
+	return s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			DELETE FROM unified_calendar_events
+			WHERE id IN (SELECT event_id FROM activity_practice_events WHERE activity_id = ?)
+		`, activityID)
+		if err != nil {
+			return fmt.Errorf("failed to delete practice events: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM activity_practice_events WHERE activity_id = ?`, activityID); err != nil {
+			return fmt.Errorf("failed to unlink practice events: %w", err)
+		}
+
+		_, err = tx.Exec(`
+			DELETE FROM tasks
+			WHERE id = (SELECT checklist_task_id FROM activities WHERE id = ? AND checklist_task_id IS NOT NULL)
+		`, activityID)
+		if err != nil {
+			return fmt.Errorf("failed to delete checklist task: %w", err)
+		}
+
+		result, err := tx.Exec(`DELETE FROM activities WHERE id = ?`, activityID)
+		if err != nil {
+			return fmt.Errorf("failed to delete activity: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("activity not found")
+		}
+
+		return nil
+	})
+}
+
+// regeneratePracticeEvents replaces the activity's practice events with one
+// event per practice day in the season, attended by the child
+func (s *ActivitiesService) regeneratePracticeEvents(activity *models.Activity, createdBy string) error {
+	familyTimezone, err := GetFamilyTimezone(s.db, activity.FamilyID)
+	if err != nil {
+		return fmt.Errorf("failed to get family timezone for practice events: %w", err)
+	}
+	loc, err := loadLocation(familyTimezone)
+	if err != nil {
+		return fmt.Errorf("invalid family timezone %s: %w", familyTimezone, err)
+	}
+
+	var createdByValue any
+	if createdBy != "" {
+		createdByValue = createdBy
+	}
+
+	return s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			DELETE FROM unified_calendar_events
+			WHERE id IN (SELECT event_id FROM activity_practice_events WHERE activity_id = ?)
+		`, activity.ID)
+		if err != nil {
+			return fmt.Errorf("failed to clear practice events: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM activity_practice_events WHERE activity_id = ?`, activity.ID); err != nil {
+			return fmt.Errorf("failed to unlink practice events: %w", err)
+		}
+
+		dates := activity.PracticesOn(loc)
+		if len(dates) == 0 {
+			return nil
+		}
+
+		practiceStart, err := time.Parse("15:04", *activity.PracticeStartTime)
+		if err != nil {
+			return fmt.Errorf("invalid practice start time: %w", err)
+		}
+		practiceEnd, err := time.Parse("15:04", *activity.PracticeEndTime)
+		if err != nil {
+			return fmt.Errorf("invalid practice end time: %w", err)
+		}
+
+		title := activity.Name + " practice"
+		if activity.Team != "" {
+			title = activity.Name + " practice (" + activity.Team + ")"
+		}
+
+		now := time.Now().UTC()
+		for _, date := range dates {
+			start := time.Date(date.Year(), date.Month(), date.Day(), practiceStart.Hour(), practiceStart.Minute(), 0, 0, loc)
+			end := time.Date(date.Year(), date.Month(), date.Day(), practiceEnd.Hour(), practiceEnd.Minute(), 0, 0, loc)
+
+			eventID := generateUnifiedEventID()
+			_, err := tx.Exec(`
+				INSERT INTO unified_calendar_events (id, family_id, title, description, start_time, end_time,
+				                                     location, all_day, event_type, color, created_by, priority,
+				                                     created_at, updated_at)
+				VALUES (?, ?, ?, '', ?, ?, ?, false, ?, ?, ?, 0, ?, ?)
+			`, eventID, activity.FamilyID, title, start.UTC(), end.UTC(), activity.Location,
+				models.EventTypeEvent, activity.Color, createdByValue, now, now)
+			if err != nil {
+				return fmt.Errorf("failed to create practice event: %w", err)
+			}
+
+			if _, err := tx.Exec(`INSERT INTO unified_calendar_event_attendees (event_id, user_id) VALUES (?, ?)`, eventID, activity.MemberID); err != nil {
+				return fmt.Errorf("failed to add practice attendee: %w", err)
+			}
+			if _, err := tx.Exec(`INSERT INTO activity_practice_events (activity_id, event_id) VALUES (?, ?)`, activity.ID, eventID); err != nil {
+				return fmt.Errorf("failed to link practice event: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// syncChecklistTask keeps a single "get equipment ready" todo, due on the
+// first day of the season, in step with the equipment list
+func (s *ActivitiesService) syncChecklistTask(activity *models.Activity, createdBy string) error {
+	if len(activity.Equipment) == 0 {
+		if activity.ChecklistTaskID != nil {
+			if err := s.tasks.DeleteTask(activity.FamilyID, *activity.ChecklistTaskID); err != nil && !errors.Is(err, ErrNotFound) {
+				return fmt.Errorf("failed to delete checklist task: %w", err)
+			}
+			if _, err := s.db.Exec(`UPDATE activities SET checklist_task_id = NULL WHERE id = ?`, activity.ID); err != nil {
+				return fmt.Errorf("failed to unlink checklist task: %w", err)
+			}
+		}
+		return nil
+	}
+
+	familyTimezone, err := GetFamilyTimezone(s.db, activity.FamilyID)
+	if err != nil {
+		return fmt.Errorf("failed to get family timezone for checklist task: %w", err)
+	}
+	loc, err := loadLocation(familyTimezone)
+	if err != nil {
+		return fmt.Errorf("invalid family timezone %s: %w", familyTimezone, err)
+	}
+	dueDate, err := time.ParseInLocation("2006-01-02", activity.SeasonStart, loc)
+	if err != nil {
+		return fmt.Errorf("invalid season start: %w", err)
+	}
+
+	title := "Equipment for " + activity.Name
+	description := "- " + strings.Join(activity.Equipment, "\n- ")
+
+	if activity.ChecklistTaskID != nil {
+		_, err := s.tasks.GetFamilyTask(activity.FamilyID, *activity.ChecklistTaskID)
+		if err == nil {
+			_, err = s.tasks.UpdateTask(activity.FamilyID, *activity.ChecklistTaskID, &models.UpdateTaskRequest{
+				Title:       &title,
+				Description: &description,
+				DueDate:     &dueDate,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update checklist task: %w", err)
+			}
+			return nil
+		}
+		if err.Error() != "task not found" {
+			return fmt.Errorf("failed to load checklist task: %w", err)
+		}
+		// The task was deleted from the task board; fall through and recreate it
+	}
+
+	// Tasks require a creator, so without one the checklist is skipped
+	if createdBy == "" {
+		return nil
+	}
+
+	memberID := activity.MemberID
+	task, err := s.tasks.CreateTask(activity.FamilyID, createdBy, &models.CreateTaskRequest{
+		Title:       title,
+		Description: description,
+		TaskType:    models.TaskTypeTodo,
+		AssignedTo:  &memberID,
+		DueDate:     &dueDate,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create checklist task: %w", err)
+	}
+
+	if _, err := s.db.Exec(`UPDATE activities SET checklist_task_id = ? WHERE id = ?`, task.ID, activity.ID); err != nil {
+		return fmt.Errorf("failed to link checklist task: %w", err)
+	}
+	return nil
+}
+
+// practiceScheduleChanged reports whether generated practice events are stale
+func practiceScheduleChanged(before, after *models.Activity) bool {
+	return before.SeasonStart != after.SeasonStart ||
+		before.SeasonEnd != after.SeasonEnd ||
+		strings.Join(before.PracticeDays, ",") != strings.Join(after.PracticeDays, ",") ||
+		stringValue(before.PracticeStartTime) != stringValue(after.PracticeStartTime) ||
+		stringValue(before.PracticeEndTime) != stringValue(after.PracticeEndTime) ||
+		before.Name != after.Name ||
+		before.Team != after.Team ||
+		before.Location != after.Location ||
+		before.Color != after.Color
+}
+
+// normalizeEquipment trims items and drops blanks
+func normalizeEquipment(items []string) []string {
+	normalized := make([]string, 0, len(items))
+	for _, item := range items {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			normalized = append(normalized, trimmed)
+		}
+	}
+	return normalized
+}
+
+func marshalActivityLists(activity *models.Activity) (string, string, error) {
+	daysJSON, err := json.Marshal(activity.PracticeDays)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal practice_days: %w", err)
+	}
+	equipmentJSON, err := json.Marshal(activity.Equipment)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal equipment: %w", err)
+	}
+	return string(daysJSON), string(equipmentJSON), nil
+}
+
+func optionalString(value string) *string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	return &value
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// scanActivity scans an activity row
+func scanActivity(scanner interface{ Scan(dest ...any) error }) (*models.Activity, error) {
+	var activity models.Activity
+	var team, coachName, coachPhone, coachEmail, location sql.NullString
+	var practiceStart, practiceEnd, checklistTaskID, createdBy sql.NullString
+	var daysJSON, equipmentJSON string
+
+	err := scanner.Scan(
+		&activity.ID, &activity.FamilyID, &activity.MemberID, &activity.Name, &team, &coachName,
+		&coachPhone, &coachEmail, &activity.SeasonStart, &activity.SeasonEnd, &daysJSON,
+		&practiceStart, &practiceEnd, &location, &equipmentJSON, &activity.FeeCents,
+		&activity.FeePaid, &activity.Color, &checklistTaskID, &createdBy, &activity.CreatedAt,
+		&activity.UpdatedAt, &activity.PracticeCount,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan activity: %w", err)
+	}
+
+	activity.Team = team.String
+	activity.CoachName = coachName.String
+	activity.CoachPhone = coachPhone.String
+	activity.CoachEmail = coachEmail.String
+	activity.Location = location.String
+	if practiceStart.Valid {
+		activity.PracticeStartTime = &practiceStart.String
+	}
+	if practiceEnd.Valid {
+		activity.PracticeEndTime = &practiceEnd.String
+	}
+	if checklistTaskID.Valid {
+		activity.ChecklistTaskID = &checklistTaskID.String
+	}
+	if createdBy.Valid {
+		activity.CreatedBy = &createdBy.String
+	}
+
+	activity.PracticeDays = []string{}
+	if err := json.Unmarshal([]byte(daysJSON), &activity.PracticeDays); err != nil {
+		return nil, fmt.Errorf("failed to parse practice_days: %w", err)
+	}
+	activity.Equipment = []string{}
+	if err := json.Unmarshal([]byte(equipmentJSON), &activity.Equipment); err != nil {
+		return nil, fmt.Errorf("failed to parse equipment: %w", err)
+	}
+
+	return &activity, nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedActivitiesFamily(t *testing.T, service *ActivitiesService) (familyID, parentID, childID string) {
+	familyID = "fam_activities"
+	parentID = "member_parent"
+	childID = "member_child"
+
+	_, err := service.db.Exec(`INSERT INTO families (id, name, timezone) VALUES (?, ?, ?)`, familyID, "Activity Family", "UTC")
+	require.NoError(t, err)
+	for _, member := range [][]string{{parentID, "Pat", "adult"}, {childID, "Sam", "child"}} {
+		_, err = service.db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, is_active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			member[0], familyID, member[1], "Activity", member[2], true, time.Now(), time.Now())
+		require.NoError(t, err)
+	}
+
+	return familyID, parentID, childID
+}
+
+func TestActivitiesService_CreateGeneratesPracticesAndChecklist(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewActivitiesService(db, NewTasksService(db))
+	familyID, parentID, childID := seedActivitiesFamily(t, service)
+
+	startTime := "17:00"
+	endTime := "18:30"
+	// 2026-03-02 is a Monday; the two weeks hold four Tuesday/Thursday practices
+	activity, err := service.CreateActivity(familyID, parentID, &models.CreateActivityRequest{
+		MemberID:          childID,
+		Name:              "Soccer",
+		Team:              "Tigers",
+		CoachEmail:        "coach@example.com",
+		SeasonStart:       "2026-03-02",
+		SeasonEnd:         "2026-03-15",
+		PracticeDays:      []string{"Tuesday", "thursday"},
+		PracticeStartTime: &startTime,
+		PracticeEndTime:   &endTime,
+		Equipment:         []string{"cleats", " shin guards ", ""},
+		FeeCents:          12000,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tuesday", "thursday"}, activity.PracticeDays)
+	assert.Equal(t, []string{"cleats", "shin guards"}, activity.Equipment)
+	assert.Equal(t, 4, activity.PracticeCount)
+	assert.False(t, activity.FeePaid)
+
+	var attendees int
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM unified_calendar_event_attendees a
+		JOIN activity_practice_events pe ON pe.event_id = a.event_id
+		WHERE pe.activity_id = ? AND a.user_id = ?
+	`, activity.ID, childID).Scan(&attendees)
+	require.NoError(t, err)
+	assert.Equal(t, 4, attendees)
+
+	require.NotNil(t, activity.ChecklistTaskID)
+	task, err := service.tasks.GetTask(*activity.ChecklistTaskID)
+	require.NoError(t, err)
+	assert.Equal(t, "Equipment for Soccer", task.Title)
+	assert.Equal(t, "- cleats\n- shin guards", task.Description)
+	require.NotNil(t, task.AssignedTo)
+	assert.Equal(t, childID, *task.AssignedTo)
+
+	// Dropping Thursday regenerates the practices
+	days := []string{"tuesday"}
+	paid := true
+	updated, err := service.UpdateActivity(activity.ID, parentID, &models.UpdateActivityRequest{
+		PracticeDays: &days,
+		FeePaid:      &paid,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.PracticeCount)
+	assert.True(t, updated.FeePaid)
+
+	// Deleting the activity removes its events and checklist task
+	require.NoError(t, service.DeleteActivity(activity.ID))
+	var events int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM unified_calendar_events WHERE family_id = ?`, familyID).Scan(&events))
+	assert.Equal(t, 0, events)
+	_, err = service.tasks.GetTask(*activity.ChecklistTaskID)
+	require.Error(t, err)
+}
+
+func TestActivitiesService_RejectsInvalidInput(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewActivitiesService(db, NewTasksService(db))
+	familyID, parentID, childID := seedActivitiesFamily(t, service)
+
+	startTime := "18:00"
+	_, err := service.CreateActivity(familyID, parentID, &models.CreateActivityRequest{
+		MemberID:          childID,
+		Name:              "Swim",
+		CoachEmail:        "not-an-email",
+		SeasonStart:       "2026-06-01",
+		SeasonEnd:         "2026-05-01",
+		PracticeDays:      []string{"funday"},
+		PracticeStartTime: &startTime,
+		FeeCents:          -1,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "coach_email")
+	assert.Contains(t, err.Error(), "season_end")
+	assert.Contains(t, err.Error(), "practice_days")
+	assert.Contains(t, err.Error(), "practice_end_time")
+	assert.Contains(t, err.Error(), "fee_cents")
+
+	_, err = service.CreateActivity(familyID, parentID, &models.CreateActivityRequest{
+		MemberID:    "someone_else",
+		Name:        "Art club",
+		SeasonStart: "2026-01-05",
+		SeasonEnd:   "2026-05-29",
+	})
+	require.Error(t, err)
+	assert.Equal(t, "family member not found", err.Error())
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/models"
+)
+
+// pageNamePattern matches page names worth keeping. Anything else, such as a
+// path segment carrying an ID, is counted as models.UsagePageOther.
+var pageNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,31}$`)
+
+// AnalyticsService keeps opt-in usage counts in the local database. Only
+// per-day aggregates are stored and nothing is sent anywhere; when collection
+// is disabled every Record call is a no-op.
+type AnalyticsService struct {
+	db      *database.Fascade
+	enabled func() bool
+}
+
+// NewAnalyticsService creates a new analytics service. Collection starts
+// disabled until SetEnabledFunc wires it to the analytics setting.
+func NewAnalyticsService(db *database.Fascade) *AnalyticsService {
+	return &AnalyticsService{
+		db:      db,
+		enabled: func() bool { return false },
+	}
+}
+
+// SetEnabledFunc sets the check consulted before each count is recorded, so a
+// settings change takes effect without a restart. Call it before serving.
+func (s *AnalyticsService) SetEnabledFunc(enabled func() bool) {
+	s.enabled = enabled
+}
+
+// Enabled reports whether usage is being collected
+func (s *AnalyticsService) Enabled() bool {
+	return s.enabled()
+}
+
+// Record adds one to today's count of a metric for the family
+func (s *AnalyticsService) Record(familyID, metric string) error {
+	return s.record(familyID, metric, "")
+}
+
+// RecordPageView counts a view of the page at path. Only the first path
+// segment is kept, so IDs and query strings never reach the database.
+func (s *AnalyticsService) RecordPageView(familyID, path string) error {
+	return s.record(familyID, models.UsageMetricPageView, pageName(path))
+}
+
+func (s *AnalyticsService) record(familyID, metric, dimension string) error {
+	if !s.enabled() {
+		return nil
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO usage_counters (family_id, metric, dimension, day, count)
+		VALUES (?, ?, ?, ?, 1)
+		ON CONFLICT(family_id, metric, dimension, day) DO UPDATE SET count = usage_counters.count + 1
+	`, familyID, metric, dimension, time.Now().UTC().Format("2006-01-02"))
+	if err != nil {
+		return fmt.Errorf("failed to record usage: %w", err)
+	}
+	return nil
+}
+
+// GetSummary returns the family's counts for the last days days, today included
+func (s *AnalyticsService) GetSummary(familyID string, days int) (*models.UsageSummary, error) {
+	if days < 1 || days > models.MaxUsageSummaryDays {
+		return nil, fmt.Errorf("summary must cover between 1 and %d days", models.MaxUsageSummaryDays)
+	}
+
+	today := time.Now().UTC()
+	summary := &models.UsageSummary{
+		Enabled: s.enabled(),
+		From:    today.AddDate(0, 0, 1-days).Format("2006-01-02"),
+		To:      today.Format("2006-01-02"),
+		Totals:  map[string]int{},
+		Pages:   map[string]int{},
+	}
+
+	counters, err := database.QueryAll[models.UsageCounter](s.db, `
+		SELECT CAST(day AS TEXT) AS day, metric, dimension, count
+		FROM usage_counters
+		WHERE family_id = ? AND day >= ? AND day <= ?
+		ORDER BY day, metric, dimension
+	`, familyID, summary.From, summary.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage counters: %w", err)
+	}
+
+	summary.Daily = counters
+	if summary.Daily == nil {
+		summary.Daily = []models.UsageCounter{}
+	}
+	for _, counter := range counters {
+		summary.Totals[counter.Metric] += counter.Count
+		if counter.Metric == models.UsageMetricPageView {
+			summary.Pages[counter.Dimension] += counter.Count
+		}
+	}
+
+	return summary, nil
+}
+
+// Clear deletes every usage count kept for the family
+func (s *AnalyticsService) Clear(familyID string) error {
+	if _, err := s.db.Exec(`DELETE FROM usage_counters WHERE family_id = ?`, familyID); err != nil {
+		return fmt.Errorf("failed to clear usage counters: %w", err)
+	}
+	return nil
+}
+
+// pageName reduces a client path such as "/tasks/abc123?tab=done" to "tasks"
+func pageName(path string) string {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "/")
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	first, _, _ := strings.Cut(path, "/")
+	first = strings.ToLower(first)
+	if first == "" {
+		return models.UsagePageHome
+	}
+	if !pageNamePattern.MatchString(first) {
+		return models.UsagePageOther
+	}
+	return first
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"testing"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyticsService_RecordsOnlyWhenEnabled(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewAnalyticsService(db)
+	familyID, _ := seedBulkEventFamily(t, db)
+
+	// Collection is off until wired to the setting
+	require.NoError(t, service.Record(familyID, models.UsageMetricTaskCreated))
+	summary, err := service.GetSummary(familyID, 7)
+	require.NoError(t, err)
+	assert.False(t, summary.Enabled)
+	assert.Empty(t, summary.Daily)
+
+	enabled := true
+	service.SetEnabledFunc(func() bool { return enabled })
+	require.NoError(t, service.Record(familyID, models.UsageMetricTaskCreated))
+	require.NoError(t, service.Record(familyID, models.UsageMetricTaskCreated))
+	require.NoError(t, service.RecordPageView(familyID, "/tasks/abc123?tab=done"))
+	require.NoError(t, service.RecordPageView(familyID, "/"))
+	require.NoError(t, service.RecordPageView(familyID, "/1f3a9c77e0"))
+
+	enabled = false
+	require.NoError(t, service.Record(familyID, models.UsageMetricCalendarSync))
+
+	summary, err = service.GetSummary(familyID, 7)
+	require.NoError(t, err)
+	assert.False(t, summary.Enabled)
+	assert.Equal(t, map[string]int{models.UsageMetricTaskCreated: 2, models.UsageMetricPageView: 3}, summary.Totals)
+	assert.Equal(t, map[string]int{"tasks": 1, models.UsagePageHome: 1, models.UsagePageOther: 1}, summary.Pages)
+
+	require.NoError(t, service.Clear(familyID))
+	summary, err = service.GetSummary(familyID, 7)
+	require.NoError(t, err)
+	assert.Empty(t, summary.Daily)
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/ids"
+	"famstack/internal/models"
+)
+
+// announcementQuery selects announcements with the viewing member's read
+// receipt, which is bound as the first parameter
+const announcementQuery = `
+	SELECT a.id, a.family_id, a.title, a.body, a.pinned, a.visibility, a.expires_at,
+	       a.created_by, a.created_at, a.updated_at, ar.read_at AS read_at,
+	       (SELECT COUNT(*) FROM announcement_reads r WHERE r.announcement_id = a.id) AS read_count
+	FROM announcements a
+	LEFT JOIN announcement_reads ar ON ar.announcement_id = a.id AND ar.member_id = ?
+`
+
+// AnnouncementsService manages the family's announcements board. Parents
+// post; every member sees what's meant for them and leaves a read receipt.
+type AnnouncementsService struct {
+	db *database.Fascade
+}
+
+// NewAnnouncementsService creates a new announcements service
+func NewAnnouncementsService(db *database.Fascade) *AnnouncementsService {
+	return &AnnouncementsService{db: db}
+}
+
+// ListAnnouncements returns the announcements the member can see, pinned
+// first and then newest first. An empty memberID is the shared display, which
+// sees only what's meant for everyone. Expired announcements are left out
+// unless includeExpired is set.
+func (s *AnnouncementsService) ListAnnouncements(familyID, memberID string, includeExpired bool) ([]models.Announcement, error) {
+	adult, err := s.isAdult(familyID, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	announcements, err := database.QueryAll[models.Announcement](s.db, announcementQuery+`
+		WHERE a.family_id = ?
+		  AND (a.visibility = ? OR ?)
+		  AND (? OR a.expires_at IS NULL OR a.expires_at > ?)
+		ORDER BY a.pinned DESC, a.created_at DESC
+	`, memberID, familyID, models.AnnouncementVisibilityEveryone, adult, includeExpired, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list announcements: %w", err)
+	}
+	if announcements == nil {
+		return []models.Announcement{}, nil
+	}
+	return announcements, nil
+}
+
+// ListUnseen returns the current announcements the member hasn't read
+func (s *AnnouncementsService) ListUnseen(familyID, memberID string) ([]models.Announcement, error) {
+	announcements, err := s.ListAnnouncements(familyID, memberID, false)
+	if err != nil {
+		return nil, err
+	}
+	unseen := []models.Announcement{}
+	for _, announcement := range announcements {
+		if announcement.ReadAt == nil {
+			unseen = append(unseen, announcement)
+		}
+	}
+	return unseen, nil
+}
+
+// GetAnnouncement returns an announcement the member can see
+func (s *AnnouncementsService) GetAnnouncement(familyID, memberID, announcementID string) (*models.Announcement, error) {
+	adult, err := s.isAdult(familyID, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	announcement, err := database.QueryOne[models.Announcement](s.db, announcementQuery+`
+		WHERE a.id = ? AND a.family_id = ? AND (a.visibility = ? OR ?)
+	`, memberID, announcementID, familyID, models.AnnouncementVisibilityEveryone, adult)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("announcement not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get announcement: %w", err)
+	}
+	return announcement, nil
+}
+
+// CreateAnnouncement posts an announcement. The poster has read it.
+func (s *AnnouncementsService) CreateAnnouncement(familyID, createdBy string, req *models.CreateAnnouncementRequest) (*models.Announcement, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	announcementID := ids.New("announcement")
+	now := time.Now().UTC()
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil {
+		utc := req.ExpiresAt.UTC()
+		expiresAt = &utc
+	}
+
+	err := s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`
+			INSERT INTO announcements (id, family_id, title, body, pinned, visibility, expires_at, created_by, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, announcementID, familyID, req.Title, req.Body, req.Pinned, req.Visibility, expiresAt,
+			optionalString(createdBy), now, now); err != nil {
+			return fmt.Errorf("failed to insert announcement: %w", err)
+		}
+		if createdBy != "" {
+			if _, err := tx.Exec(`INSERT INTO announcement_reads (announcement_id, member_id, read_at) VALUES (?, ?, ?)`,
+				announcementID, createdBy, now); err != nil {
+				return fmt.Errorf("failed to record poster's read: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create announcement: %w", err)
+	}
+	return s.GetAnnouncement(familyID, createdBy, announcementID)
+}
+
+// UpdateAnnouncement changes an announcement. Read receipts are kept, so a
+// correction doesn't show up as unseen again.
+func (s *AnnouncementsService) UpdateAnnouncement(familyID, memberID, announcementID string, req *models.UpdateAnnouncementRequest) (*models.Announcement, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	announcement, err := s.GetAnnouncement(familyID, memberID, announcementID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil {
+		announcement.Title = *req.Title
+	}
+	if req.Body != nil {
+		announcement.Body = *req.Body
+	}
+	if req.Pinned != nil {
+		announcement.Pinned = *req.Pinned
+	}
+	if req.Visibility != nil {
+		announcement.Visibility = *req.Visibility
+	}
+	if req.ExpiresAt != nil {
+		utc := req.ExpiresAt.UTC()
+		announcement.ExpiresAt = &utc
+	}
+	if req.ClearExpiry {
+		announcement.ExpiresAt = nil
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE announcements SET title = ?, body = ?, pinned = ?, visibility = ?, expires_at = ?, updated_at = ?
+		WHERE id = ? AND family_id = ?
+	`, announcement.Title, announcement.Body, announcement.Pinned, announcement.Visibility, announcement.ExpiresAt,
+		time.Now().UTC(), announcementID, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update announcement: %w", err)
+	}
+	return s.GetAnnouncement(familyID, memberID, announcementID)
+}
+
+// DeleteAnnouncement takes an announcement down
+func (s *AnnouncementsService) DeleteAnnouncement(familyID, announcementID string) error {
+	result, err := s.db.Exec(`DELETE FROM announcements WHERE id = ? AND family_id = ?`, announcementID, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete announcement: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("announcement not found")
+	}
+	return nil
+}
+
+// MarkRead records that the member has read an announcement. Reading it
+// again keeps the first receipt.
+func (s *AnnouncementsService) MarkRead(familyID, memberID, announcementID string) (*models.Announcement, error) {
+	if memberID == "" {
+		return nil, fmt.Errorf("a member is required to mark an announcement read")
+	}
+	if _, err := s.GetAnnouncement(familyID, memberID, announcementID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.db.Exec(`INSERT INTO announcement_reads (announcement_id, member_id, read_at) VALUES (?, ?, ?) ON CONFLICT DO NOTHING`,
+		announcementID, memberID, time.Now().UTC()); err != nil {
+		return nil, fmt.Errorf("failed to mark announcement read: %w", err)
+	}
+	return s.GetAnnouncement(familyID, memberID, announcementID)
+}
+
+// GetReads returns who has read an announcement, earliest first
+func (s *AnnouncementsService) GetReads(familyID, announcementID string) ([]models.AnnouncementRead, error) {
+	var exists bool
+	if err := s.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM announcements WHERE id = ? AND family_id = ?)`,
+		announcementID, familyID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up announcement: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("announcement not found")
+	}
+
+	reads, err := database.QueryAll[models.AnnouncementRead](s.db, `
+		SELECT ar.member_id, fm.first_name AS member_name, ar.read_at
+		FROM announcement_reads ar
+		JOIN family_members fm ON fm.id = ar.member_id
+		WHERE ar.announcement_id = ?
+		ORDER BY ar.read_at
+	`, announcementID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get announcement reads: %w", err)
+	}
+	if reads == nil {
+		return []models.AnnouncementRead{}, nil
+	}
+	return reads, nil
+}
+
+// isAdult reports whether the member sees adults-only announcements
+func (s *AnnouncementsService) isAdult(familyID, memberID string) (bool, error) {
+	if memberID == "" {
+		return false, nil
+	}
+	var memberType string
+	err := s.db.QueryRow(`SELECT member_type FROM family_members WHERE id = ? AND family_id = ?`, memberID, familyID).Scan(&memberType)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get member type: %w", err)
+	}
+	return memberType == string(models.MemberTypeAdult), nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnouncementsService_VisibilityAndReads(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewAnnouncementsService(db)
+	familyID, parentID := seedBulkEventFamily(t, db)
+	childID := "member_bulk_child"
+	_, err := db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, is_active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		childID, familyID, "Kid", "Member", "child", true, time.Now(), time.Now())
+	require.NoError(t, err)
+
+	everyone, err := service.CreateAnnouncement(familyID, parentID, &models.CreateAnnouncementRequest{Title: "Pizza Friday"})
+	require.NoError(t, err)
+	assert.Equal(t, models.AnnouncementVisibilityEveryone, everyone.Visibility)
+	assert.NotNil(t, everyone.ReadAt, "the poster has read it")
+	pinned, err := service.CreateAnnouncement(familyID, parentID, &models.CreateAnnouncementRequest{
+		Title: "Birthday surprise", Pinned: true, Visibility: models.AnnouncementVisibilityAdults,
+	})
+	require.NoError(t, err)
+
+	announcements, err := service.ListAnnouncements(familyID, parentID, false)
+	require.NoError(t, err)
+	require.Len(t, announcements, 2)
+	assert.Equal(t, pinned.ID, announcements[0].ID, "pinned first")
+
+	// The child and the shared display see only what's for everyone
+	for _, memberID := range []string{childID, ""} {
+		announcements, err = service.ListAnnouncements(familyID, memberID, false)
+		require.NoError(t, err)
+		require.Len(t, announcements, 1)
+		assert.Equal(t, everyone.ID, announcements[0].ID)
+	}
+	_, err = service.GetAnnouncement(familyID, childID, pinned.ID)
+	assert.EqualError(t, err, "announcement not found")
+
+	unseen, err := service.ListUnseen(familyID, childID)
+	require.NoError(t, err)
+	require.Len(t, unseen, 1)
+	read, err := service.MarkRead(familyID, childID, everyone.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, read.ReadAt)
+	assert.Equal(t, 2, read.ReadCount)
+	unseen, err = service.ListUnseen(familyID, childID)
+	require.NoError(t, err)
+	assert.Empty(t, unseen)
+
+	reads, err := service.GetReads(familyID, everyone.ID)
+	require.NoError(t, err)
+	require.Len(t, reads, 2)
+	assert.Equal(t, "Bulk", reads[0].MemberName)
+	assert.Equal(t, "Kid", reads[1].MemberName)
+}
+
+func TestAnnouncementsService_ExpiredAreHidden(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewAnnouncementsService(db)
+	familyID, parentID := seedBulkEventFamily(t, db)
+
+	announcement, err := service.CreateAnnouncement(familyID, parentID, &models.CreateAnnouncementRequest{Title: "Water off today"})
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE announcements SET expires_at = ? WHERE id = ?`, time.Now().UTC().Add(-time.Hour), announcement.ID)
+	require.NoError(t, err)
+
+	announcements, err := service.ListAnnouncements(familyID, parentID, false)
+	require.NoError(t, err)
+	assert.Empty(t, announcements)
+	announcements, err = service.ListAnnouncements(familyID, parentID, true)
+	require.NoError(t, err)
+	assert.Len(t, announcements, 1)
+
+	// Clearing the expiry puts it back up
+	_, err = service.UpdateAnnouncement(familyID, parentID, announcement.ID, &models.UpdateAnnouncementRequest{ClearExpiry: true})
+	require.NoError(t, err)
+	announcements, err = service.ListAnnouncements(familyID, parentID, false)
+	require.NoError(t, err)
+	assert.Len(t, announcements, 1)
+}
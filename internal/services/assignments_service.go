@@ -0,0 +1,609 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/ids"
+	"famstack/internal/models"
+)
+
+// AssignmentsService manages courses and homework assignments. Each open
+// assignment keeps a linked deadline task so it shows up in the daily view.
+type AssignmentsService struct {
+	db    *database.Fascade
+	tasks *TasksService
+}
+
+// NewAssignmentsService creates a new assignments service
+func NewAssignmentsService(db *database.Fascade, tasks *TasksService) *AssignmentsService {
+	return &AssignmentsService{db: db, tasks: tasks}
+}
+
+const courseColumns = `id, family_id, member_id, name, teacher, color, active, created_at, updated_at`
+
+const assignmentColumns = `a.id, a.family_id, a.course_id, c.name, a.member_id, a.title, a.description, a.due_date,
+	a.estimated_minutes, a.status, a.task_id, a.created_by, a.created_at, a.updated_at, a.completed_at`
+
+// ListCourses returns a family's courses, optionally limited to one child
+func (s *AssignmentsService) ListCourses(familyID, memberID string) ([]models.Course, error) {
+	query := `SELECT ` + courseColumns + ` FROM courses WHERE family_id = ?`
+	args := []any{familyID}
+	if memberID != "" {
+		query += " AND member_id = ?"
+		args = append(args, memberID)
+	}
+	query += " ORDER BY name"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query courses: %w", err)
+	}
+	defer rows.Close()
+
+	courses := []models.Course{}
+	for rows.Next() {
+		course, err := scanCourse(rows)
+		if err != nil {
+			return nil, err
+		}
+		courses = append(courses, *course)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating courses: %w", err)
+	}
+
+	return courses, nil
+}
+
+// GetCourse returns a course by ID
+func (s *AssignmentsService) GetCourse(courseID string) (*models.Course, error) {
+	row := s.db.QueryRow(`SELECT `+courseColumns+` FROM courses WHERE id = ?`, courseID)
+	course, err := scanCourse(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("course not found")
+	}
+	return course, err
+}
+
+// CreateCourse adds a course for a child in the family
+func (s *AssignmentsService) CreateCourse(familyID string, req *models.CreateCourseRequest) (*models.Course, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := models.ValidateCourse(name, req.Teacher, req.Color); err != nil {
+		return nil, err
+	}
+
+	if err := s.requireFamilyMember(familyID, req.MemberID); err != nil {
+		return nil, err
+	}
+
+	color := req.Color
+	if color == "" {
+		color = "#6366f1"
+	}
+
+	courseID := ids.New("course")
+	now := time.Now().UTC()
+
+	_, err := s.db.Exec(`
+		INSERT INTO courses (id, family_id, member_id, name, teacher, color, active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, true, ?, ?)
+	`, courseID, familyID, req.MemberID, name, strings.TrimSpace(req.Teacher), color, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create course: %w", err)
+	}
+
+	return s.GetCourse(courseID)
+}
+
+// UpdateCourse applies the provided fields to a course
+func (s *AssignmentsService) UpdateCourse(courseID string, req *models.UpdateCourseRequest) (*models.Course, error) {
+	existing, err := s.GetCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := *existing
+	if req.Name != nil {
+		merged.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Teacher != nil {
+		merged.Teacher = strings.TrimSpace(*req.Teacher)
+	}
+	if req.Color != nil {
+		merged.Color = *req.Color
+	}
+	if req.Active != nil {
+		merged.Active = *req.Active
+	}
+
+	if err := models.ValidateCourse(merged.Name, merged.Teacher, merged.Color); err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE courses
+		SET name = ?, teacher = ?, color = ?, active = ?, updated_at = ?
+		WHERE id = ?
+	`, merged.Name, merged.Teacher, merged.Color, merged.Active, time.Now().UTC(), courseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update course: %w", err)
+	}
+
+	return s.GetCourse(courseID)
+}
+
+// DeleteCourse removes a course along with its assignments and their deadline tasks
+func (s *AssignmentsService) DeleteCourse(courseID string) error {
+	return s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			DELETE FROM tasks
+			WHERE id IN (SELECT task_id FROM assignments WHERE course_id = ? AND task_id IS NOT NULL)
+		`, courseID)
+		if err != nil {
+			return fmt.Errorf("failed to delete deadline tasks for course: %w", err)
+		}
+
+		if _, err := tx.Exec(`DELETE FROM assignments WHERE course_id = ?`, courseID); err != nil {
+			return fmt.Errorf("failed to delete assignments for course: %w", err)
+		}
+
+		result, err := tx.Exec(`DELETE FROM courses WHERE id = ?`, courseID)
+		if err != nil {
+			return fmt.Errorf("failed to delete course: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("course not found")
+		}
+
+		return nil
+	})
+}
+
+// ListAssignments returns a family's assignments ordered by due date. An empty
+// memberID returns every child's work; completed work is left out unless asked for.
+func (s *AssignmentsService) ListAssignments(familyID, memberID string, includeCompleted bool) ([]models.Assignment, error) {
+	query := `
+		SELECT ` + assignmentColumns + `
+		FROM assignments a
+		JOIN courses c ON c.id = a.course_id
+		WHERE a.family_id = ?`
+	args := []any{familyID}
+	if memberID != "" {
+		query += " AND a.member_id = ?"
+		args = append(args, memberID)
+	}
+	if !includeCompleted {
+		query += " AND a.status != 'completed'"
+	}
+	query += " ORDER BY a.due_date, a.title"
+
+	return s.queryAssignments(familyID, query, args...)
+}
+
+// ListOpenAssignmentsDue returns unfinished assignments due before the given
+// time, grouped by child. Used to surface upcoming homework in the daily view.
+func (s *AssignmentsService) ListOpenAssignmentsDue(familyID string, before time.Time) (map[string][]models.Assignment, error) {
+	assignments, err := s.queryAssignments(familyID, `
+		SELECT `+assignmentColumns+`
+		FROM assignments a
+		JOIN courses c ON c.id = a.course_id
+		WHERE a.family_id = ? AND a.status != 'completed' AND a.due_date < ?
+		ORDER BY a.due_date, a.title
+	`, familyID, before.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	byMember := make(map[string][]models.Assignment)
+	for _, assignment := range assignments {
+		byMember[assignment.MemberID] = append(byMember[assignment.MemberID], assignment)
+	}
+	return byMember, nil
+}
+
+// GetAssignment returns an assignment by ID
+func (s *AssignmentsService) GetAssignment(assignmentID string) (*models.Assignment, error) {
+	var familyID string
+	err := s.db.QueryRow(`SELECT family_id FROM assignments WHERE id = ?`, assignmentID).Scan(&familyID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("assignment not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignment: %w", err)
+	}
+
+	assignments, err := s.queryAssignments(familyID, `
+		SELECT `+assignmentColumns+`
+		FROM assignments a
+		JOIN courses c ON c.id = a.course_id
+		WHERE a.id = ?
+	`, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(assignments) == 0 {
+		return nil, fmt.Errorf("assignment not found")
+	}
+
+	return &assignments[0], nil
+}
+
+// CreateAssignment adds an assignment to a course and creates its deadline task
+func (s *AssignmentsService) CreateAssignment(familyID, createdBy string, req *models.CreateAssignmentRequest) (*models.Assignment, error) {
+	title := strings.TrimSpace(req.Title)
+	if err := models.ValidateAssignment(title, req.Description, req.DueDate, req.EstimatedMinutes, models.AssignmentStatusNotStarted); err != nil {
+		return nil, err
+	}
+
+	course, err := s.GetCourse(req.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	if course.FamilyID != familyID {
+		return nil, fmt.Errorf("course not found")
+	}
+
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for assignment: %w", err)
+	}
+	dueDateUTC, err := ConvertToUTC(req.DueDate, familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert due date to UTC: %w", err)
+	}
+
+	var createdByValue any
+	if createdBy != "" {
+		createdByValue = createdBy
+	}
+
+	assignmentID := ids.New("assignment")
+	now := time.Now().UTC()
+
+	_, err = s.db.Exec(`
+		INSERT INTO assignments (id, family_id, course_id, member_id, title, description, due_date,
+		                         estimated_minutes, status, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, assignmentID, familyID, course.ID, course.MemberID, title, req.Description, dueDateUTC,
+		req.EstimatedMinutes, models.AssignmentStatusNotStarted, createdByValue, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create assignment: %w", err)
+	}
+
+	assignment, err := s.GetAssignment(assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if createdBy != "" {
+		if err := s.createDeadlineTask(assignment, createdBy); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetAssignment(assignmentID)
+}
+
+// UpdateAssignment applies the provided fields and keeps the deadline task in step
+func (s *AssignmentsService) UpdateAssignment(assignmentID string, req *models.UpdateAssignmentRequest) (*models.Assignment, error) {
+	existing, err := s.GetAssignment(assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := *existing
+	if req.Title != nil {
+		merged.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Description != nil {
+		merged.Description = *req.Description
+	}
+	if req.DueDate != nil {
+		familyTimezone, err := GetFamilyTimezone(s.db, existing.FamilyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get family timezone for assignment: %w", err)
+		}
+		merged.DueDate, err = ConvertToUTC(*req.DueDate, familyTimezone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert due date to UTC: %w", err)
+		}
+	}
+	if req.EstimatedMinutes != nil {
+		merged.EstimatedMinutes = *req.EstimatedMinutes
+	}
+	if req.Status != nil {
+		merged.Status = *req.Status
+	}
+
+	if err := models.ValidateAssignment(merged.Title, merged.Description, merged.DueDate, merged.EstimatedMinutes, merged.Status); err != nil {
+		return nil, err
+	}
+
+	var completedAt any
+	if merged.Status == models.AssignmentStatusCompleted {
+		completedAt = time.Now().UTC()
+		if existing.CompletedAt != nil {
+			completedAt = existing.CompletedAt.UTC()
+		}
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE assignments
+		SET title = ?, description = ?, due_date = ?, estimated_minutes = ?, status = ?,
+		    completed_at = ?, updated_at = ?
+		WHERE id = ?
+	`, merged.Title, merged.Description, merged.DueDate.UTC(), merged.EstimatedMinutes, merged.Status,
+		completedAt, time.Now().UTC(), assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update assignment: %w", err)
+	}
+
+	updated, err := s.GetAssignment(assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.syncDeadlineTask(updated); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// DeleteAssignment removes an assignment and its deadline task
+func (s *AssignmentsService) DeleteAssignment(assignmentID string) error {
+	return s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			DELETE FROM tasks
+			WHERE id = (SELECT task_id FROM assignments WHERE id = ? AND task_id IS NOT NULL)
+		`, assignmentID)
+		if err != nil {
+			return fmt.Errorf("failed to delete deadline task: %w", err)
+		}
+
+		result, err := tx.Exec(`DELETE FROM assignments WHERE id = ?`, assignmentID)
+		if err != nil {
+			return fmt.Errorf("failed to delete assignment: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("assignment not found")
+		}
+
+		return nil
+	})
+}
+
+// UpcomingDeadlines builds the parent report: for every child with courses,
+// overdue work plus anything due within the next `days` days.
+func (s *AssignmentsService) UpcomingDeadlines(familyID string, now time.Time, days int) ([]models.UpcomingDeadlines, error) {
+	rows, err := s.db.Query(`
+		SELECT DISTINCT fm.id, fm.first_name, fm.last_name
+		FROM family_members fm
+		JOIN courses c ON c.member_id = fm.id
+		WHERE fm.family_id = ? AND fm.is_active = TRUE
+		ORDER BY fm.first_name, fm.last_name
+	`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query students: %w", err)
+	}
+	defer rows.Close()
+
+	report := []models.UpcomingDeadlines{}
+	index := make(map[string]int)
+	for rows.Next() {
+		var memberID, firstName, lastName string
+		if err := rows.Scan(&memberID, &firstName, &lastName); err != nil {
+			return nil, fmt.Errorf("failed to scan student: %w", err)
+		}
+		index[memberID] = len(report)
+		report = append(report, models.UpcomingDeadlines{
+			MemberID:   memberID,
+			MemberName: strings.TrimSpace(firstName + " " + lastName),
+			Overdue:    []models.Assignment{},
+			Upcoming:   []models.Assignment{},
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating students: %w", err)
+	}
+
+	byMember, err := s.ListOpenAssignmentsDue(familyID, now.AddDate(0, 0, days))
+	if err != nil {
+		return nil, err
+	}
+
+	for memberID, assignments := range byMember {
+		i, ok := index[memberID]
+		if !ok {
+			continue
+		}
+		for _, assignment := range assignments {
+			if assignment.DueDate.Before(now) {
+				report[i].Overdue = append(report[i].Overdue, assignment)
+			} else {
+				report[i].Upcoming = append(report[i].Upcoming, assignment)
+			}
+			report[i].EstimatedMinutes += assignment.EstimatedMinutes
+		}
+	}
+
+	return report, nil
+}
+
+// createDeadlineTask adds the todo that represents the assignment in the daily view
+func (s *AssignmentsService) createDeadlineTask(assignment *models.Assignment, createdBy string) error {
+	memberID := assignment.MemberID
+	dueDate := assignment.DueDate
+
+	task, err := s.tasks.CreateTask(assignment.FamilyID, createdBy, &models.CreateTaskRequest{
+		Title:       deadlineTaskTitle(assignment),
+		Description: assignment.Description,
+		TaskType:    models.TaskTypeTodo,
+		AssignedTo:  &memberID,
+		DueDate:     &dueDate,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create deadline task: %w", err)
+	}
+
+	if _, err := s.db.Exec(`UPDATE assignments SET task_id = ? WHERE id = ?`, task.ID, assignment.ID); err != nil {
+		return fmt.Errorf("failed to link deadline task: %w", err)
+	}
+	return nil
+}
+
+// syncDeadlineTask copies title, due date and completion onto the linked task
+func (s *AssignmentsService) syncDeadlineTask(assignment *models.Assignment) error {
+	if assignment.TaskID == nil {
+		return nil
+	}
+
+	title := deadlineTaskTitle(assignment)
+	dueDate := assignment.DueDate
+	status := models.TaskStatusPending
+	if assignment.Status == models.AssignmentStatusCompleted {
+		status = models.TaskStatusCompleted
+	}
+
+	_, err := s.tasks.UpdateTask(assignment.FamilyID, *assignment.TaskID, &models.UpdateTaskRequest{
+		Title:       &title,
+		Description: &assignment.Description,
+		Status:      &status,
+		DueDate:     &dueDate,
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to update deadline task: %w", err)
+	}
+	return nil
+}
+
+// queryAssignments runs an assignment query and converts due dates to the family timezone
+func (s *AssignmentsService) queryAssignments(familyID, query string, args ...any) ([]models.Assignment, error) {
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for assignments: %w", err)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query assignments: %w", err)
+	}
+	defer rows.Close()
+
+	assignments := []models.Assignment{}
+	for rows.Next() {
+		assignment, err := scanAssignment(rows, familyTimezone)
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, *assignment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating assignments: %w", err)
+	}
+
+	return assignments, nil
+}
+
+// requireFamilyMember checks that a member belongs to the family
+func (s *AssignmentsService) requireFamilyMember(familyID, memberID string) error {
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM family_members WHERE id = ? AND family_id = ?`, memberID, familyID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check family member: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("family member not found")
+	}
+	return nil
+}
+
+// deadlineTaskTitle names the deadline task after the course and assignment
+func deadlineTaskTitle(assignment *models.Assignment) string {
+	if assignment.CourseName == "" {
+		return "Due: " + assignment.Title
+	}
+	return "Due: " + assignment.CourseName + " - " + assignment.Title
+}
+
+// scanCourse scans a course row
+func scanCourse(scanner interface{ Scan(dest ...any) error }) (*models.Course, error) {
+	var course models.Course
+	var teacher sql.NullString
+
+	err := scanner.Scan(
+		&course.ID, &course.FamilyID, &course.MemberID, &course.Name, &teacher,
+		&course.Color, &course.Active, &course.CreatedAt, &course.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan course: %w", err)
+	}
+
+	if teacher.Valid {
+		course.Teacher = teacher.String
+	}
+
+	return &course, nil
+}
+
+// scanAssignment scans an assignment row joined with its course name
+func scanAssignment(scanner interface{ Scan(dest ...any) error }, familyTimezone string) (*models.Assignment, error) {
+	var assignment models.Assignment
+	var description, taskID, createdBy sql.NullString
+	var completedAt sql.NullTime
+
+	err := scanner.Scan(
+		&assignment.ID, &assignment.FamilyID, &assignment.CourseID, &assignment.CourseName,
+		&assignment.MemberID, &assignment.Title, &description, &assignment.DueDate,
+		&assignment.EstimatedMinutes, &assignment.Status, &taskID, &createdBy,
+		&assignment.CreatedAt, &assignment.UpdatedAt, &completedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan assignment: %w", err)
+	}
+
+	if description.Valid {
+		assignment.Description = description.String
+	}
+	if taskID.Valid {
+		assignment.TaskID = &taskID.String
+	}
+	if createdBy.Valid {
+		assignment.CreatedBy = &createdBy.String
+	}
+
+	assignment.DueDate, err = ConvertFromUTC(assignment.DueDate, familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert due date from UTC: %w", err)
+	}
+	if completedAt.Valid {
+		converted, convErr := ConvertFromUTC(completedAt.Time, familyTimezone)
+		if convErr != nil {
+			return nil, fmt.Errorf("failed to convert completed at from UTC: %w", convErr)
+		}
+		assignment.CompletedAt = &converted
+	}
+
+	return &assignment, nil
+}
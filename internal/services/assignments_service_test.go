@@ -0,0 +1,132 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedAssignmentsFamily(t *testing.T, service *AssignmentsService) (familyID, parentID, childID string, course *models.Course) {
+	familyID = "fam_homework"
+	parentID = "member_parent"
+	childID = "member_child"
+
+	_, err := service.db.Exec(`INSERT INTO families (id, name, timezone) VALUES (?, ?, ?)`, familyID, "Homework Family", "UTC")
+	require.NoError(t, err)
+	for _, member := range [][]string{{parentID, "Pat", "adult"}, {childID, "Sam", "child"}} {
+		_, err = service.db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, is_active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			member[0], familyID, member[1], "Homework", member[2], true, time.Now(), time.Now())
+		require.NoError(t, err)
+	}
+
+	course, err = service.CreateCourse(familyID, &models.CreateCourseRequest{
+		MemberID: childID,
+		Name:     "Math",
+		Teacher:  "Ms. Frizzle",
+	})
+	require.NoError(t, err)
+
+	return familyID, parentID, childID, course
+}
+
+func TestAssignmentsService_CreateLinksDeadlineTask(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewAssignmentsService(db, NewTasksService(db))
+	familyID, parentID, childID, course := seedAssignmentsFamily(t, service)
+
+	dueDate := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	assignment, err := service.CreateAssignment(familyID, parentID, &models.CreateAssignmentRequest{
+		CourseID:         course.ID,
+		Title:            "Fractions worksheet",
+		DueDate:          dueDate,
+		EstimatedMinutes: 45,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, childID, assignment.MemberID)
+	assert.Equal(t, "Math", assignment.CourseName)
+	assert.Equal(t, models.AssignmentStatusNotStarted, assignment.Status)
+	require.NotNil(t, assignment.TaskID)
+
+	task, err := service.tasks.GetTask(*assignment.TaskID)
+	require.NoError(t, err)
+	assert.Equal(t, "Due: Math - Fractions worksheet", task.Title)
+	require.NotNil(t, task.AssignedTo)
+	assert.Equal(t, childID, *task.AssignedTo)
+
+	// Completing the assignment completes its deadline task
+	completed := models.AssignmentStatusCompleted
+	updated, err := service.UpdateAssignment(assignment.ID, &models.UpdateAssignmentRequest{Status: &completed})
+	require.NoError(t, err)
+	assert.NotNil(t, updated.CompletedAt)
+
+	task, err = service.tasks.GetTask(*assignment.TaskID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusCompleted, task.Status)
+
+	// Deleting the assignment removes the deadline task too
+	require.NoError(t, service.DeleteAssignment(assignment.ID))
+	_, err = service.tasks.GetTask(*assignment.TaskID)
+	require.Error(t, err)
+}
+
+func TestAssignmentsService_UpcomingDeadlines(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewAssignmentsService(db, NewTasksService(db))
+	familyID, parentID, childID, course := seedAssignmentsFamily(t, service)
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	create := func(title string, due time.Time, minutes int) *models.Assignment {
+		assignment, err := service.CreateAssignment(familyID, parentID, &models.CreateAssignmentRequest{
+			CourseID:         course.ID,
+			Title:            title,
+			DueDate:          due,
+			EstimatedMinutes: minutes,
+		})
+		require.NoError(t, err)
+		return assignment
+	}
+
+	create("Overdue reading log", now.AddDate(0, 0, -1), 15)
+	create("Quiz review", now.AddDate(0, 0, 2), 30)
+	create("Science fair", now.AddDate(0, 0, 20), 120)
+	done := create("Done already", now.AddDate(0, 0, 1), 10)
+	completed := models.AssignmentStatusCompleted
+	_, err := service.UpdateAssignment(done.ID, &models.UpdateAssignmentRequest{Status: &completed})
+	require.NoError(t, err)
+
+	report, err := service.UpcomingDeadlines(familyID, now, 7)
+	require.NoError(t, err)
+	require.Len(t, report, 1)
+
+	student := report[0]
+	assert.Equal(t, childID, student.MemberID)
+	require.Len(t, student.Overdue, 1)
+	assert.Equal(t, "Overdue reading log", student.Overdue[0].Title)
+	require.Len(t, student.Upcoming, 1)
+	assert.Equal(t, "Quiz review", student.Upcoming[0].Title)
+	assert.Equal(t, 45, student.EstimatedMinutes)
+}
+
+func TestAssignmentsService_RejectsInvalidInput(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewAssignmentsService(db, NewTasksService(db))
+	familyID, parentID, _, course := seedAssignmentsFamily(t, service)
+
+	_, err := service.CreateAssignment(familyID, parentID, &models.CreateAssignmentRequest{
+		CourseID:         course.ID,
+		Title:            "",
+		EstimatedMinutes: -5,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "title")
+	assert.Contains(t, err.Error(), "due_date")
+	assert.Contains(t, err.Error(), "estimated_minutes")
+
+	_, err = service.CreateCourse(familyID, &models.CreateCourseRequest{MemberID: "someone_else", Name: "Art"})
+	require.Error(t, err)
+	assert.Equal(t, "family member not found", err.Error())
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"famstack/internal/database"
+	"famstack/internal/models"
+	"famstack/internal/storage"
+	"famstack/internal/validation"
+)
+
+// AttachmentsService stores uploaded files in the configured storage backend
+// and records each one through the StorageService, so every upload is counted
+// against the family's quota
+type AttachmentsService struct {
+	db      *database.Fascade
+	ledger  *StorageService
+	backend storage.Storage
+}
+
+// NewAttachmentsService creates a new attachments service. It has no backend
+// until SetBackend is called with the one named in the config.
+func NewAttachmentsService(db *database.Fascade, ledger *StorageService) *AttachmentsService {
+	return &AttachmentsService{
+		db:     db,
+		ledger: ledger,
+	}
+}
+
+// SetBackend sets where file contents are kept
+func (s *AttachmentsService) SetBackend(backend storage.Storage) {
+	s.backend = backend
+}
+
+// Upload streams body into the backend and records it. The quota is checked
+// before any bytes are read; if the file can't be recorded afterwards, the
+// stored copy is removed again.
+func (s *AttachmentsService) Upload(ctx context.Context, familyID, memberID string, req *models.UploadRequest, body io.Reader) (*models.StorageObject, error) {
+	if s.backend == nil {
+		return nil, fmt.Errorf("no storage backend configured")
+	}
+
+	validator := validation.NewValidator()
+	validator.OneOf("kind", req.Kind, []string{models.StorageKindAttachment, models.StorageKindAvatar, models.StorageKindPhoto})
+	validator.MaxLength("file_name", req.FileName, 255)
+	validator.MaxLength("content_type", req.ContentType, 255)
+	if req.SizeBytes <= 0 {
+		validator.AddError("size_bytes", "size_bytes must be greater than 0")
+	}
+	if err := validator.ToError(); err != nil {
+		return nil, err
+	}
+	req.ContentType = attachmentContentType(req.ContentType)
+
+	if err := s.ledger.CheckUpload(familyID, memberID, req.SizeBytes); err != nil {
+		return nil, err
+	}
+
+	key, err := attachmentKey(familyID, req.Kind, req.FileName)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.backend.Put(ctx, key, body, req.SizeBytes, req.ContentType); err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+
+	object, err := s.ledger.recordObject(familyID, memberID, key, req)
+	if err != nil {
+		if deleteErr := s.backend.Delete(context.WithoutCancel(ctx), key); deleteErr != nil {
+			return nil, fmt.Errorf("%w (and failed to remove stored file: %v)", err, deleteErr)
+		}
+		return nil, err
+	}
+	return object, nil
+}
+
+// attachmentContentTypes are the types uploads keep. Downloads are served
+// from the app's origin, so anything a browser might render as a page or run,
+// like HTML or SVG, is stored as a plain download instead.
+var attachmentContentTypes = []string{
+	"application/pdf",
+	"application/msword",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"application/vnd.ms-excel",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"application/zip",
+	"audio/mpeg",
+	"image/gif", "image/heic", "image/jpeg", "image/png", "image/webp",
+	"text/calendar", "text/csv", "text/plain",
+	"video/mp4", "video/quicktime",
+}
+
+// attachmentContentType returns the media type an upload is stored with:
+// the one sent when it is allowed, application/octet-stream otherwise
+func attachmentContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !slices.Contains(attachmentContentTypes, mediaType) {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+// Open returns a stored file's record and its contents; the caller closes them
+func (s *AttachmentsService) Open(ctx context.Context, familyID, objectID string) (*models.StorageObject, io.ReadCloser, error) {
+	if s.backend == nil {
+		return nil, nil, fmt.Errorf("no storage backend configured")
+	}
+
+	object, err := s.ledger.GetObject(familyID, objectID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, _, err := s.backend.Get(ctx, object.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, fmt.Errorf("storage object not found")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return object, body, nil
+}
+
+// PresignedURL returns a temporary direct download URL for a stored file, or
+// "" when the backend can't hand one out and the file must be streamed
+func (s *AttachmentsService) PresignedURL(ctx context.Context, familyID, objectID string) (string, error) {
+	presigner, ok := s.backend.(storage.Presigner)
+	if !ok {
+		return "", nil
+	}
+
+	object, err := s.ledger.GetObject(familyID, objectID)
+	if err != nil {
+		return "", err
+	}
+
+	url, err := presigner.PresignGet(ctx, object.StorageKey)
+	if errors.Is(err, storage.ErrPresignDisabled) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to presign download: %w", err)
+	}
+	return url, nil
+}
+
+// Delete removes a stored file and stops counting it
+func (s *AttachmentsService) Delete(ctx context.Context, familyID, objectID string) error {
+	if s.backend == nil {
+		return fmt.Errorf("no storage backend configured")
+	}
+
+	object, err := s.ledger.GetObject(familyID, objectID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.backend.Delete(ctx, object.StorageKey); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return s.ledger.DeleteObject(familyID, object.StorageKey)
+}
+
+var attachmentExtPattern = regexp.MustCompile(`^\.[a-z0-9]{1,10}$`)
+
+// attachmentKey builds a new object's key, familyID/kind/random.ext. The
+// file name only contributes its extension, so keys stay valid in every backend.
+func attachmentKey(familyID, kind, fileName string) (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate storage key: %w", err)
+	}
+
+	ext := strings.ToLower(path.Ext(fileName))
+	if !attachmentExtPattern.MatchString(ext) {
+		ext = ""
+	}
+
+	key := familyID + "/" + kind + "/" + hex.EncodeToString(bytes) + ext
+	if err := storage.ValidateKey(key); err != nil {
+		return "", err
+	}
+	return key, nil
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"famstack/internal/models"
+	"famstack/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachmentsService_UploadOpenDelete(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	ledger := NewStorageService(db)
+	service := NewAttachmentsService(db, ledger)
+	backend, err := storage.NewLocal(t.TempDir())
+	require.NoError(t, err)
+	service.SetBackend(backend)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	object, err := service.Upload(ctx, familyID, memberID, &models.UploadRequest{
+		Kind:        models.StorageKindAttachment,
+		FileName:    "Permission Slip.PDF",
+		ContentType: "application/pdf",
+		SizeBytes:   7,
+	}, strings.NewReader("%PDF-1."))
+	require.NoError(t, err)
+	assert.Equal(t, "Permission Slip.PDF", object.FileName)
+	assert.True(t, strings.HasPrefix(object.StorageKey, familyID+"/attachment/"))
+	assert.True(t, strings.HasSuffix(object.StorageKey, ".pdf"))
+
+	opened, body, err := service.Open(ctx, familyID, object.ID)
+	require.NoError(t, err)
+	data, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "%PDF-1.", string(data))
+	assert.Equal(t, "application/pdf", opened.ContentType)
+
+	// Local storage can't presign, so downloads are streamed
+	url, err := service.PresignedURL(ctx, familyID, object.ID)
+	require.NoError(t, err)
+	assert.Empty(t, url)
+
+	// Another family can't see the file
+	_, _, err = service.Open(ctx, "other-family", object.ID)
+	assert.EqualError(t, err, "storage object not found")
+
+	require.NoError(t, service.Delete(ctx, familyID, object.ID))
+	_, err = backend.Stat(ctx, object.StorageKey)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	usage, err := ledger.GetUsage(familyID)
+	require.NoError(t, err)
+	assert.Zero(t, usage.TotalBytes)
+}
+
+func TestAttachmentsService_UploadOverQuotaStoresNothing(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	ledger := NewStorageService(db)
+	service := NewAttachmentsService(db, ledger)
+	dir := t.TempDir()
+	backend, err := storage.NewLocal(dir)
+	require.NoError(t, err)
+	service.SetBackend(backend)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	_, err = ledger.UpdateQuota(familyID, &models.UpdateStorageQuotaRequest{HardLimitBytes: int64Ptr(5)})
+	require.NoError(t, err)
+
+	_, err = service.Upload(ctx, familyID, memberID, &models.UploadRequest{
+		Kind:      models.StorageKindPhoto,
+		FileName:  "beach.jpg",
+		SizeBytes: 10,
+	}, strings.NewReader("0123456789"))
+	var quotaErr *models.QuotaError
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, models.QuotaCodeFamilyLimitExceeded, quotaErr.Code)
+
+	count := 0
+	require.NoError(t, backend.Walk(ctx, func(storage.ObjectInfo) error {
+		count++
+		return nil
+	}))
+	assert.Zero(t, count)
+}
+
+func TestAttachmentContentType(t *testing.T) {
+	assert.Equal(t, "application/pdf", attachmentContentType("application/pdf"))
+	assert.Equal(t, "text/plain", attachmentContentType("Text/Plain; charset=utf-8"))
+	assert.Equal(t, "application/octet-stream", attachmentContentType("text/html"))
+	assert.Equal(t, "application/octet-stream", attachmentContentType("image/svg+xml"))
+	assert.Equal(t, "application/octet-stream", attachmentContentType(""))
+	assert.Equal(t, "application/octet-stream", attachmentContentType("not a type;;"))
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SyncedAttendee is someone invited to a synced event. Providers name
+// attendees by email; MemberID is set when the email is a family member's,
+// so an attendee outside the family has only an email.
+type SyncedAttendee struct {
+	Email    string `json:"email,omitempty"`
+	Name     string `json:"name,omitempty"`
+	MemberID string `json:"member_id,omitempty"`
+	Response string `json:"response,omitempty"`
+}
+
+// attendeeEmails returns the attendees' emails, for matching color rules
+func attendeeEmails(attendees []SyncedAttendee) []string {
+	emails := make([]string, 0, len(attendees))
+	for _, attendee := range attendees {
+		if attendee.Email != "" {
+			emails = append(emails, attendee.Email)
+		}
+	}
+	return emails
+}
+
+// marshalSyncedAttendees returns the JSON stored in calendar_events.attendees
+func marshalSyncedAttendees(attendees []SyncedAttendee) (string, error) {
+	if len(attendees) == 0 {
+		return "[]", nil
+	}
+	data, err := json.Marshal(attendees)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode attendees: %w", err)
+	}
+	return string(data), nil
+}
+
+// parseStoredAttendees reads calendar_events.attendees as it may have been
+// written: the current list of attendee objects, an older list of emails,
+// or that list joined by hand, which broke on emails with quotes in them.
+func parseStoredAttendees(raw string) []SyncedAttendee {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+
+	var attendees []SyncedAttendee
+	if json.Unmarshal([]byte(raw), &attendees) == nil {
+		return attendees
+	}
+
+	var emails []string
+	if json.Unmarshal([]byte(raw), &emails) != nil {
+		// The hand-joined form is ["a","b"] with nothing escaped, so split
+		// it on the separators it was joined with
+		inner := strings.TrimSuffix(strings.TrimPrefix(raw, `["`), `"]`)
+		emails = strings.Split(inner, `","`)
+	}
+
+	attendees = make([]SyncedAttendee, 0, len(emails))
+	for _, email := range emails {
+		if email = strings.TrimSpace(email); email != "" && email != "[]" {
+			attendees = append(attendees, SyncedAttendee{Email: email})
+		}
+	}
+	return attendees
+}
+
+// matchAttendeeMembers sets the MemberID of each attendee whose email is a
+// member of the event's family, and clears it for those that no longer are
+func (s *CalendarService) matchAttendeeMembers(events []*CalendarEventForSync) error {
+	membersByFamily := make(map[string]map[string]string)
+	for _, event := range events {
+		if len(event.Attendees) == 0 {
+			continue
+		}
+		members, ok := membersByFamily[event.FamilyID]
+		if !ok {
+			var err error
+			if members, err = s.memberIDsByEmail(event.FamilyID); err != nil {
+				return err
+			}
+			membersByFamily[event.FamilyID] = members
+		}
+		for i := range event.Attendees {
+			event.Attendees[i].MemberID = members[strings.ToLower(event.Attendees[i].Email)]
+		}
+	}
+	return nil
+}
+
+// memberIDsByEmail maps the lowercased email of each of the family's
+// members to their ID
+func (s *CalendarService) memberIDsByEmail(familyID string) (map[string]string, error) {
+	rows, err := s.db.Query(`SELECT id, email FROM family_members WHERE family_id = ? AND email IS NOT NULL`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query member emails: %w", err)
+	}
+	defer rows.Close()
+
+	members := make(map[string]string)
+	for rows.Next() {
+		var id, email string
+		if err := rows.Scan(&id, &email); err != nil {
+			return nil, fmt.Errorf("failed to scan member email: %w", err)
+		}
+		members[strings.ToLower(email)] = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating member emails: %w", err)
+	}
+	return members, nil
+}
+
+// RepairSyncedAttendees rewrites the attendees of synced events stored in
+// an older form, or whose member matches are out of date, and returns how
+// many events it changed. Events are read back in the form they are written,
+// so running it again changes nothing.
+func (s *CalendarService) RepairSyncedAttendees() (int, error) {
+	rows, err := s.db.Query(`SELECT id, family_id, attendees FROM calendar_events WHERE attendees <> '[]'`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query event attendees: %w", err)
+	}
+
+	var events []*CalendarEventForSync
+	var stored []string
+	for rows.Next() {
+		var event CalendarEventForSync
+		var raw sql.NullString
+		if err := rows.Scan(&event.ID, &event.FamilyID, &raw); err != nil {
+			rows.Close() // nolint:errcheck
+			return 0, fmt.Errorf("failed to scan event attendees: %w", err)
+		}
+		event.Attendees = parseStoredAttendees(raw.String)
+		events = append(events, &event)
+		stored = append(stored, raw.String)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close() // nolint:errcheck
+		return 0, fmt.Errorf("error iterating event attendees: %w", err)
+	}
+	rows.Close() // nolint:errcheck
+
+	if err := s.matchAttendeeMembers(events); err != nil {
+		return 0, err
+	}
+
+	repaired := 0
+	families := make(map[string]bool)
+	err = s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		for i, event := range events {
+			attendees, err := marshalSyncedAttendees(event.Attendees)
+			if err != nil {
+				return err
+			}
+			if attendees == stored[i] {
+				continue
+			}
+			if _, err := tx.Exec(`UPDATE calendar_events SET attendees = ? WHERE id = ?`, attendees, event.ID); err != nil {
+				return fmt.Errorf("failed to repair attendees of event %s: %w", event.ID, err)
+			}
+			repaired++
+			families[event.FamilyID] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for familyID := range families {
+		s.publishChange(familyID)
+	}
+	return repaired, nil
+}
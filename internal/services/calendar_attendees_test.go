@@ -0,0 +1,154 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStoredAttendees(t *testing.T) {
+	tests := map[string]struct {
+		raw  string
+		want []SyncedAttendee
+	}{
+		"current":    {`[{"email":"dana@example.com","member_id":"member_dana"}]`, []SyncedAttendee{{Email: "dana@example.com", MemberID: "member_dana"}}},
+		"emails":     {`["dana@example.com","sam@example.com"]`, []SyncedAttendee{{Email: "dana@example.com"}, {Email: "sam@example.com"}}},
+		"hand-made":  {`["dana@example.com","o"brien@example.com"]`, []SyncedAttendee{{Email: "dana@example.com"}, {Email: `o"brien@example.com`}}},
+		"empty list": {`[]`, []SyncedAttendee{}},
+		"blank":      {``, nil},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseStoredAttendees(tt.raw))
+		})
+	}
+}
+
+func TestMarshalSyncedAttendees_EscapesQuotes(t *testing.T) {
+	raw, err := marshalSyncedAttendees([]SyncedAttendee{{Email: `o"brien@example.com`, Name: `Pat "Coach" O'Brien`}})
+	require.NoError(t, err)
+	assert.Equal(t, []SyncedAttendee{{Email: `o"brien@example.com`, Name: `Pat "Coach" O'Brien`}}, parseStoredAttendees(raw))
+
+	raw, err = marshalSyncedAttendees(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "[]", raw)
+}
+
+func TestRepairSyncedAttendees(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+	_, err := db.Exec(`UPDATE family_members SET email = ? WHERE id = ?`, "Bulk@Example.com", memberID)
+	require.NoError(t, err)
+
+	start := time.Date(2025, 3, 4, 17, 0, 0, 0, time.UTC)
+	for id, attendees := range map[string]string{
+		"event_broken":  `["bulk@example.com","o"brien@example.com"]`,
+		"event_current": `[{"email":"coach@example.com"}]`,
+	} {
+		_, err := db.Exec(`INSERT INTO calendar_events (id, external_source, family_id, title, start_time, end_time, attendees) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			id, "google", familyID, "Soccer", start, start.Add(time.Hour), attendees)
+		require.NoError(t, err)
+	}
+
+	repaired, err := service.RepairSyncedAttendees()
+	require.NoError(t, err)
+	assert.Equal(t, 1, repaired)
+
+	var raw string
+	require.NoError(t, db.QueryRow(`SELECT attendees FROM calendar_events WHERE id = ?`, "event_broken").Scan(&raw))
+	assert.Equal(t, []SyncedAttendee{{Email: "bulk@example.com", MemberID: memberID}, {Email: `o"brien@example.com`}}, parseStoredAttendees(raw))
+
+	repaired, err = service.RepairSyncedAttendees()
+	require.NoError(t, err)
+	assert.Zero(t, repaired, "a second run changes nothing")
+}
+
+func TestUpsertCalendarEvent_StoresAttendeesAndUpdatesInPlace(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+	_, err := db.Exec(`UPDATE family_members SET email = ? WHERE id = ?`, "bulk@example.com", memberID)
+	require.NoError(t, err)
+
+	start := time.Date(2025, 3, 8, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	event := &CalendarEventForSync{
+		FamilyID: familyID, CreatedBy: memberID, Title: "Swim",
+		StartTime: start, EndTime: &end, SourceType: "google", SourceID: "g_swim",
+		Attendees: []SyncedAttendee{{Email: "Bulk@Example.com", Response: "accepted"}},
+		CreatedAt: start, UpdatedAt: start,
+	}
+	require.NoError(t, service.UpsertCalendarEvent(event))
+	eventID := event.ID
+	require.NotEmpty(t, eventID)
+
+	event.Title = "Swim meet"
+	event.Attendees = append(event.Attendees, SyncedAttendee{Email: `o"brien@example.com`, Name: "Pat"})
+	require.NoError(t, service.UpsertCalendarEvent(event))
+	assert.Equal(t, eventID, event.ID, "the row keeps its ID")
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM calendar_events WHERE external_id = ?`, "g_swim").Scan(&count))
+	assert.Equal(t, 1, count, "the second sync updates the first row")
+
+	var title, source, raw string
+	require.NoError(t, db.QueryRow(`SELECT title, external_source, attendees FROM calendar_events WHERE id = ?`, eventID).Scan(&title, &source, &raw))
+	assert.Equal(t, "Swim meet", title)
+	assert.Equal(t, "google", source)
+	assert.JSONEq(t, `[
+		{"email": "Bulk@Example.com", "member_id": "`+memberID+`", "response": "accepted"},
+		{"email": "o\"brien@example.com", "name": "Pat"}
+	]`, raw)
+
+	removed, err := service.DeleteSyncedCalendarEvents(familyID, "google", []string{"g_swim"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+}
+
+func TestUpsertCalendarEvents_KeysOnFamilyAndProvider(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, _ := seedBulkEventFamily(t, db)
+	otherFamilyID := seedOtherFamily(t, db)
+
+	start := time.Date(2025, 3, 8, 10, 0, 0, 0, time.UTC)
+	event := func(familyID, title string) *CalendarEventForSync {
+		return &CalendarEventForSync{
+			FamilyID: familyID, Title: title, StartTime: start,
+			SourceType: "google", SourceID: "g_shared", CreatedAt: start, UpdatedAt: start,
+		}
+	}
+
+	// Both families were invited to the same event; the row in between
+	// names a family that doesn't exist and is rejected on its own
+	results, err := service.UpsertCalendarEvents([]*CalendarEventForSync{
+		event(familyID, "Block party"),
+		event("fam_missing", "Nobody's"),
+		event(otherFamilyID, "Block party (theirs)"),
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, models.BulkEventStatusCreated, results[0].Status)
+	assert.Equal(t, models.BulkEventStatusFailed, results[1].Status)
+	assert.Equal(t, models.BulkEventStatusCreated, results[2].Status)
+	assert.NotEqual(t, results[0].EventID, results[2].EventID)
+
+	var title string
+	require.NoError(t, db.QueryRow(`SELECT title FROM calendar_events WHERE family_id = ? AND external_id = ?`, otherFamilyID, "g_shared").Scan(&title))
+	assert.Equal(t, "Block party (theirs)", title)
+
+	// A resync updates each family's own row
+	results, err = service.UpsertCalendarEvents([]*CalendarEventForSync{event(familyID, "Block party, moved")})
+	require.NoError(t, err)
+	require.Equal(t, models.BulkEventStatusCreated, results[0].Status)
+	require.NoError(t, db.QueryRow(`SELECT title FROM calendar_events WHERE id = ?`, results[0].EventID).Scan(&title))
+	assert.Equal(t, "Block party, moved", title)
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM calendar_events WHERE external_id = ?`, "g_shared").Scan(&count))
+	assert.Equal(t, 2, count)
+}
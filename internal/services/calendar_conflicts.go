@@ -0,0 +1,116 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"famstack/internal/models"
+)
+
+// EventOverlaps reports whether an event takes up any of the time from
+// start to end. Events that only touch, one ending as the other starts,
+// don't overlap.
+func EventOverlaps(event *models.UnifiedCalendarEvent, start, end time.Time) bool {
+	return event.StartTime.Before(end) && event.EndTime.After(start)
+}
+
+// FindEventConflicts returns the family's events, as viewer may see them,
+// that overlap start to end and have any of memberIDs attending. Times
+// without a zone are in family time. All-day events don't block time, and
+// an attendee who declined isn't there; excludeEventID leaves out the event
+// being checked.
+func (s *CalendarService) FindEventConflicts(viewer *models.CalendarViewer, familyID string, memberIDs []string, start, end time.Time, excludeEventID string) ([]models.EventConflict, error) {
+	if len(memberIDs) == 0 {
+		return nil, nil
+	}
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for conflicts: %w", err)
+	}
+	startUTC, err := ConvertToUTC(start, familyTimezone)
+	if err != nil {
+		return nil, err
+	}
+	endUTC, err := ConvertToUTC(end, familyTimezone)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.GetUnifiedCalendarEventsFor(viewer, familyID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(memberIDs))
+	for _, memberID := range memberIDs {
+		wanted[memberID] = true
+	}
+
+	conflicts := []models.EventConflict{}
+	for i := range events {
+		event := &events[i]
+		if event.AllDay || event.ID == excludeEventID || !EventOverlaps(event, startUTC, endUTC) {
+			continue
+		}
+		var shared []string
+		for _, attendee := range event.Attendees {
+			if wanted[attendee.ID] && attendee.Response != "declined" {
+				shared = append(shared, attendee.ID)
+			}
+		}
+		if len(shared) == 0 {
+			continue
+		}
+		conflicts = append(conflicts, models.EventConflict{
+			EventID:   event.ID,
+			Title:     event.Title,
+			StartTime: event.StartTime,
+			EndTime:   event.EndTime,
+			MemberIDs: shared,
+		})
+	}
+	return conflicts, nil
+}
+
+// FreeBusy returns the events that keep any of memberIDs busy on date's day
+// in family time, with their times in the family timezone. Placing them in
+// slots and finding the free time between them is left for the caller.
+func (s *CalendarService) FreeBusy(viewer *models.CalendarViewer, familyID string, memberIDs []string, date time.Time) (*models.FreeBusy, error) {
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for free/busy: %w", err)
+	}
+	loc, err := loadLocation(familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid family timezone %s: %w", familyTimezone, err)
+	}
+
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	conflicts, err := s.FindEventConflicts(viewer, familyID, memberIDs, day, day.AddDate(0, 0, 1), "")
+	if err != nil {
+		return nil, err
+	}
+
+	busy := make([]models.BusyBlock, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		busy = append(busy, models.BusyBlock{
+			EventID:   conflict.EventID,
+			Title:     conflict.Title,
+			StartTime: conflict.StartTime.In(loc),
+			EndTime:   conflict.EndTime.In(loc),
+			MemberIDs: conflict.MemberIDs,
+		})
+	}
+	sort.SliceStable(busy, func(i, j int) bool {
+		return busy[i].StartTime.Before(busy[j].StartTime)
+	})
+
+	return &models.FreeBusy{
+		Date:      day.Format("2006-01-02"),
+		Timezone:  familyTimezone,
+		MemberIDs: memberIDs,
+		Busy:      busy,
+		Free:      []models.FreeSlot{},
+	}, nil
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventOverlaps(t *testing.T) {
+	start := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	event := &models.UnifiedCalendarEvent{StartTime: start, EndTime: start.Add(time.Hour)}
+
+	assert.True(t, EventOverlaps(event, start.Add(30*time.Minute), start.Add(2*time.Hour)))
+	assert.True(t, EventOverlaps(event, start.Add(-time.Hour), start.Add(3*time.Hour)))
+	assert.False(t, EventOverlaps(event, start.Add(time.Hour), start.Add(2*time.Hour)), "back to back isn't a conflict")
+	assert.False(t, EventOverlaps(event, start.Add(-time.Hour), start))
+}
+
+func TestFindEventConflicts(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, parentID := seedBulkEventFamily(t, db)
+	_, err := db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, is_active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"member_kid", familyID, "Kid", "Member", "child", true, time.Now(), time.Now())
+	require.NoError(t, err)
+
+	start := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	day := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	response, err := service.BulkCreateUnifiedCalendarEvents(context.Background(), familyID, parentID, []models.BulkCalendarEventItem{
+		{Title: "Dentist", StartTime: start, EndTime: start.Add(time.Hour), Attendees: []string{"member_kid"}},
+		{Title: "Standup", StartTime: start, EndTime: start.Add(30 * time.Minute), Attendees: []string{parentID}},
+		{Title: "Field trip", StartTime: day, EndTime: day.AddDate(0, 0, 1), AllDay: true, Attendees: []string{"member_kid"}},
+		{Title: "Lunch", StartTime: start.Add(3 * time.Hour), EndTime: start.Add(4 * time.Hour), Attendees: []string{"member_kid"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 4, response.Created)
+
+	viewer := &models.CalendarViewer{MemberID: parentID, Adult: true}
+	conflicts, err := service.FindEventConflicts(viewer, familyID, []string{"member_kid"}, start.Add(30*time.Minute), start.Add(90*time.Minute), "")
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1, "only timed events the attendee is at conflict")
+	assert.Equal(t, "Dentist", conflicts[0].Title)
+	assert.Equal(t, []string{"member_kid"}, conflicts[0].MemberIDs)
+
+	conflicts, err = service.FindEventConflicts(viewer, familyID, []string{"member_kid"}, start, start.Add(time.Hour), response.Results[0].EventID)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts, "an event doesn't conflict with itself")
+
+	conflicts, err = service.FindEventConflicts(viewer, familyID, nil, start, start.Add(time.Hour), "")
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+}
+
+func TestCalendarService_FreeBusy(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, parentID := seedBulkEventFamily(t, db)
+
+	start := time.Date(2025, 10, 1, 15, 0, 0, 0, time.UTC)
+	_, err := service.BulkCreateUnifiedCalendarEvents(context.Background(), familyID, parentID, []models.BulkCalendarEventItem{
+		{Title: "Soccer", StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour), Attendees: []string{parentID}},
+		{Title: "Standup", StartTime: start, EndTime: start.Add(30 * time.Minute), Attendees: []string{parentID}},
+		{Title: "Tomorrow", StartTime: start.AddDate(0, 0, 1), EndTime: start.AddDate(0, 0, 1).Add(time.Hour), Attendees: []string{parentID}},
+	})
+	require.NoError(t, err)
+
+	viewer := &models.CalendarViewer{MemberID: parentID, Adult: true}
+	freeBusy, err := service.FreeBusy(viewer, familyID, []string{parentID}, start)
+	require.NoError(t, err)
+	assert.Equal(t, "2025-10-01", freeBusy.Date)
+	require.Len(t, freeBusy.Busy, 2)
+	assert.Equal(t, "Standup", freeBusy.Busy[0].Title, "busy blocks come in start order")
+	assert.Equal(t, "Soccer", freeBusy.Busy[1].Title)
+	assert.NotNil(t, freeBusy.Free)
+}
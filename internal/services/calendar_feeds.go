@@ -0,0 +1,463 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"famstack/internal/ical"
+	"famstack/internal/models"
+	"famstack/internal/recurrence"
+)
+
+// How often ICS subscriptions are fetched: when their settings don't say,
+// and at most
+const (
+	DefaultFeedRefresh = time.Hour
+	MinFeedRefresh     = 15 * time.Minute
+)
+
+// FeedSettings are the settings of an ICS subscription: the feed, and how
+// its events appear on the family calendar
+type FeedSettings struct {
+	URL            string   `json:"url"`
+	Color          string   `json:"color"`           // every event's color; the family's color rules decide when empty
+	Assignees      []string `json:"assignees"`       // members every event is put on
+	RefreshMinutes int      `json:"refresh_minutes"` // how often the feed is fetched
+	ConflictPolicy string   `json:"conflict_policy"` // what a change to an event the family edited does
+}
+
+// FeedSettings reads the integration's ICS subscription settings
+func (i *Integration) FeedSettings() FeedSettings {
+	var settings FeedSettings
+	if i.Settings != "" {
+		_ = json.Unmarshal([]byte(i.Settings), &settings) // nolint:errcheck // settings of other shapes have none
+	}
+	return settings
+}
+
+// Refresh is how often the feed is fetched
+func (f FeedSettings) Refresh() time.Duration {
+	if f.RefreshMinutes <= 0 {
+		return DefaultFeedRefresh
+	}
+	return max(time.Duration(f.RefreshMinutes)*time.Minute, MinFeedRefresh)
+}
+
+// Policy is the subscription's conflict policy, remote_wins unless set
+func (f FeedSettings) Policy() string {
+	if slices.Contains(models.SyncConflictPolicies, f.ConflictPolicy) {
+		return f.ConflictPolicy
+	}
+	return models.SyncConflictRemoteWins
+}
+
+// FeedsDue returns the enabled ICS subscriptions, of every family, not
+// fetched within their refresh interval as of now
+func (s *IntegrationsService) FeedsDue(now time.Time) ([]Integration, error) {
+	integrations, err := s.queryIntegrations(integrationColumns+" WHERE provider = ? AND enabled = ?", ProviderICS, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendar feeds: %w", err)
+	}
+
+	var due []Integration
+	for _, integration := range integrations {
+		// A minute's grace, so a feed fetched a little after one planner
+		// run isn't left until the run after next
+		next := now.Add(time.Minute)
+		if integration.LastSyncAt == nil || !integration.LastSyncAt.Add(integration.FeedSettings().Refresh()).After(next) {
+			due = append(due, integration)
+		}
+	}
+	return due, nil
+}
+
+// RecordFeedSync adds a fetch of an ICS subscription to its sync history and
+// stamps the integration: connected after a good fetch, in error with the
+// reason after a bad one. syncType is manual or scheduled; result is nil
+// when the fetch failed before anything was stored.
+func (s *IntegrationsService) RecordFeedSync(integrationID, syncType string, startedAt time.Time, result *FeedSyncResult, syncErr error) error {
+	status, message := StatusConnected, ""
+	if syncErr != nil {
+		status, message = StatusError, syncErr.Error()
+	}
+	run := SyncRun{SyncType: syncType, Mode: SyncModeFull, StartedAt: startedAt, Err: syncErr}
+	if result != nil {
+		run.Changed, run.Removed = result.Created+result.Updated, result.Removed
+	}
+	now := time.Now().UTC()
+
+	return s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		if err := insertSyncHistory(tx, integrationID, run, now); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`
+			UPDATE integrations SET status = ?, last_sync_at = ?, last_error = ?, updated_at = ? WHERE id = ?
+		`, status, now, optionalString(message), now, integrationID); err != nil {
+			return fmt.Errorf("failed to update feed status: %w", err)
+		}
+		return nil
+	})
+}
+
+// FeedSyncResult counts what a feed sync did to the family calendar
+type FeedSyncResult struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Removed   int `json:"removed"`
+	Skipped   int `json:"skipped"`   // events with a recurrence rule famstack can't follow
+	Kept      int `json:"kept"`      // edited events whose edit won over the feed's change
+	Conflicts int `json:"conflicts"` // edited events the feed changed, awaiting review
+}
+
+// feedEntry is one event row a feed's events become
+type feedEntry struct {
+	uid string
+	// original is the occurrence an override or RDATE stands for; zero for
+	// a series or a single event
+	original time.Time
+	// series is whether the entry overrides an occurrence of the series
+	// with its UID, which then skips it
+	series bool
+	event  ical.Event
+	hash   string
+}
+
+// feedKey identifies a feed's event across fetches
+func feedKey(uid string, original time.Time) string {
+	if original.IsZero() {
+		return uid
+	}
+	return uid + "@" + original.UTC().Format(recurrence.UTCLayout)
+}
+
+// feedEntries turns a feed's events into the rows they are stored as, series
+// first. Overrides become rows of their own that their series skips; RDATEs,
+// which rules here can't hold, become single events; cancelled events and
+// occurrences are left out.
+func feedEntries(events []ical.Event, settings FeedSettings) []feedEntry {
+	masters := make(map[string]*ical.Event)
+	var order []string
+	for _, event := range events {
+		if !event.RecurrenceID.IsZero() {
+			continue
+		}
+		if _, seen := masters[event.UID]; seen {
+			continue // a feed that repeats a UID keeps its first event
+		}
+		event.ExceptionDates = slices.Clone(event.ExceptionDates)
+		masters[event.UID] = &event
+		order = append(order, event.UID)
+	}
+
+	var overrides []feedEntry
+	overridden := make(map[string]bool)
+	for _, event := range events {
+		if event.RecurrenceID.IsZero() {
+			continue
+		}
+		master := masters[event.UID]
+		series := master != nil && master.RecurrenceRule != ""
+		if series {
+			master.ExceptionDates = append(master.ExceptionDates, event.RecurrenceID)
+		}
+		overridden[feedKey(event.UID, event.RecurrenceID)] = true
+		if !event.Cancelled {
+			overrides = append(overrides, feedEntry{uid: event.UID, original: event.RecurrenceID, series: series, event: event})
+		}
+	}
+
+	var entries []feedEntry
+	for _, uid := range order {
+		master := masters[uid]
+		if master.Cancelled {
+			continue
+		}
+		entries = append(entries, feedEntry{uid: uid, event: *master})
+
+		length := master.End.Sub(master.Start)
+		for _, date := range master.ExtraDates {
+			if overridden[feedKey(uid, date)] {
+				continue
+			}
+			single := *master
+			single.Start, single.End = date, date.Add(length)
+			single.RecurrenceRule, single.ExceptionDates, single.ExtraDates = "", nil, nil
+			entries = append(entries, feedEntry{uid: uid, original: date, event: single})
+		}
+	}
+	entries = append(entries, overrides...)
+
+	// Each key is stored once; a feed that repeats one keeps the first
+	seen := make(map[string]bool, len(entries))
+	unique := entries[:0]
+	for _, entry := range entries {
+		key := feedKey(entry.uid, entry.original)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		entry.hash = feedHash(&entry, settings)
+		unique = append(unique, entry)
+	}
+	return unique
+}
+
+// feedHash fingerprints what an entry stores, the subscription's color and
+// assignees included, so a sync rewrites only what the feed or its settings
+// changed. Feeds restamp DTSTAMP on every fetch, so the raw text won't do.
+func feedHash(entry *feedEntry, settings FeedSettings) string {
+	event := entry.event
+	exdates := slices.SortedFunc(slices.Values(event.ExceptionDates), time.Time.Compare)
+	assignees := slices.Sorted(slices.Values(settings.Assignees))
+
+	h := sha256.New()
+	for _, field := range []string{
+		event.Title, event.Description, event.Location,
+		event.Start.UTC().Format(time.RFC3339), event.End.UTC().Format(time.RFC3339),
+		strconv.FormatBool(event.AllDay), event.RecurrenceRule, recurrence.FormatTimes(exdates),
+		strconv.FormatBool(entry.series), settings.Color, strings.Join(assignees, ","),
+	} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// feedRow is an event a feed brought in before
+type feedRow struct {
+	id   string
+	hash string
+	// edited is whether the event changed since the feed last wrote it,
+	// which it last did at updatedAt
+	edited    bool
+	updatedAt time.Time
+}
+
+// feedConflictWinner picks the side that wins when the feed changed an event
+// the family edited at localUpdated, or "" to leave it for review. A feed
+// that doesn't say when it changed an event changed it since the last fetch,
+// which is counted as after the edit.
+func feedConflictWinner(policy string, localUpdated, remoteModified time.Time) string {
+	switch policy {
+	case models.SyncConflictLocalWins:
+		return models.SyncConflictKeepLocal
+	case models.SyncConflictManual:
+		return ""
+	case models.SyncConflictNewestWins:
+		if !remoteModified.IsZero() && !remoteModified.After(localUpdated) {
+			return models.SyncConflictKeepLocal
+		}
+	}
+	return models.SyncConflictKeepRemote
+}
+
+// SyncFeedEvents brings the family calendar in step with the events an ICS
+// subscription's feed has now: new events are added, changed ones rewritten
+// and ones the feed dropped removed. Events the feed hasn't changed are left
+// alone, so fetching an unchanged feed writes nothing. A change to an event
+// the family edited goes by the subscription's conflict policy.
+func (s *CalendarService) SyncFeedEvents(integration *Integration, events []ical.Event) (*FeedSyncResult, error) {
+	familyID := integration.FamilyID
+	settings := integration.FeedSettings()
+	policy := settings.Policy()
+
+	// Members who have since left the family are dropped from the mapping
+	memberIDs, err := s.getFamilyMemberIDs(familyID)
+	if err != nil {
+		return nil, err
+	}
+	settings.Assignees = slices.DeleteFunc(slices.Clone(settings.Assignees), func(id string) bool { return !memberIDs[id] })
+
+	rules, err := s.colorRules.ListRules(familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event color rules: %w", err)
+	}
+	existing, err := s.feedRows(integration.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := feedEntries(events, settings)
+	result := &FeedSyncResult{}
+	now := time.Now().UTC()
+	err = s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		seriesIDs := make(map[string]string) // UID -> the series' row
+		for i := range entries {
+			entry := &entries[i]
+			key := feedKey(entry.uid, entry.original)
+			row, found := existing[key]
+			delete(existing, key)
+			if !found {
+				row.id = generateUnifiedEventID()
+			}
+			if entry.original.IsZero() {
+				seriesIDs[entry.uid] = row.id
+			}
+			if found && row.hash == entry.hash {
+				result.Unchanged++
+				continue
+			}
+
+			event := entry.event
+			rule, err := parseRecurrenceRule(optionalString(event.RecurrenceRule))
+			if err != nil {
+				if !found {
+					delete(seriesIDs, entry.uid) // its overrides stand alone
+				}
+				result.Skipped++
+				continue
+			}
+			exdates := ""
+			if rule != nil {
+				exdates = recurrence.FormatTimes(event.ExceptionDates)
+			}
+			var seriesID *string
+			if id := seriesIDs[entry.uid]; entry.series && id != "" {
+				seriesID = &id
+			}
+			var originalStart any
+			if !entry.original.IsZero() {
+				originalStart = entry.original.UTC()
+			}
+			title := strings.TrimSpace(event.Title)
+			if title == "" {
+				title = "Busy"
+			}
+			colorRule := models.MatchEventColorRule(rules, models.EventRuleSample{
+				Title:         title,
+				Description:   event.Description,
+				Location:      event.Location,
+				Source:        string(integration.Provider),
+				IntegrationID: integration.ID,
+				EventType:     string(models.EventTypeEvent),
+			})
+			color, category := ruleColorAndCategory(colorRule, settings.Color)
+
+			if found && row.edited {
+				winner := feedConflictWinner(policy, row.updatedAt, event.LastModified)
+				switch winner {
+				case models.SyncConflictKeepLocal:
+					// The feed's version counts as seen, so only its next
+					// change comes up again
+					if _, err := tx.Exec(`UPDATE unified_calendar_events SET source_hash = ? WHERE id = ?`, entry.hash, row.id); err != nil {
+						return fmt.Errorf("failed to keep edited feed event %s: %w", key, err)
+					}
+					result.Kept++
+				case "":
+					remote := models.SyncedEventVersion{
+						Title: title, Description: optionalString(event.Description), Location: optionalString(event.Location),
+						StartTime: event.Start.UTC(), EndTime: event.End.UTC(), AllDay: event.AllDay,
+						Color: color, Category: category, RecurrenceRule: rule, RecurrenceExdates: exdates,
+						RecurringEventID: seriesID, AttendeeIDs: settings.Assignees,
+					}
+					if !event.LastModified.IsZero() {
+						modified := event.LastModified.UTC()
+						remote.ModifiedAt = &modified
+					}
+					if err := saveSyncConflict(tx, familyID, integration.ID, row.id, &remote, entry.hash, now); err != nil {
+						return err
+					}
+					result.Conflicts++
+				}
+				if winner != models.SyncConflictKeepRemote {
+					continue
+				}
+				if _, err := tx.Exec(`DELETE FROM event_sync_conflicts WHERE event_id = ?`, row.id); err != nil {
+					return fmt.Errorf("failed to clear conflict of feed event %s: %w", key, err)
+				}
+			}
+
+			if found {
+				if _, err := tx.Exec(`
+					UPDATE unified_calendar_events
+					SET title = ?, description = ?, location = ?, start_time = ?, end_time = ?, all_day = ?,
+						color = ?, category = ?, recurrence_rule = ?, recurrence_exdates = ?,
+						recurring_event_id = ?, source_hash = ?, source_synced_at = ?, updated_at = ?
+					WHERE id = ?
+				`, title, optionalString(event.Description), optionalString(event.Location), event.Start.UTC(), event.End.UTC(),
+					event.AllDay, color, category, rule, exdates, seriesID, entry.hash, now, now, row.id); err != nil {
+					return fmt.Errorf("failed to update feed event %s: %w", key, err)
+				}
+				result.Updated++
+			} else {
+				if _, err := tx.Exec(`
+					INSERT INTO unified_calendar_events (id, family_id, title, description, start_time, end_time,
+														location, all_day, event_type, color, category, created_by,
+														status, ical_uid, recurrence_rule, recurrence_exdates,
+														recurring_event_id, original_start_time, source_integration_id,
+														source_hash, source_synced_at, created_at, updated_at)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				`, row.id, familyID, title, optionalString(event.Description), event.Start.UTC(), event.End.UTC(),
+					optionalString(event.Location), event.AllDay, models.EventTypeEvent, color, category,
+					optionalString(integration.CreatedBy), models.UnifiedEventStatusActive, entry.uid, rule, exdates,
+					seriesID, originalStart, integration.ID, entry.hash, now, now, now); err != nil {
+					return fmt.Errorf("failed to insert feed event %s: %w", key, err)
+				}
+				result.Created++
+			}
+			if err := syncEventAttendees(tx, row.id, settings.Assignees); err != nil {
+				return err
+			}
+		}
+
+		for key, row := range existing {
+			if _, err := tx.Exec(`DELETE FROM unified_calendar_events WHERE id = ?`, row.id); err != nil {
+				return fmt.Errorf("failed to remove feed event %s: %w", key, err)
+			}
+			result.Removed++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync feed events: %w", err)
+	}
+
+	if result.Created+result.Updated+result.Removed > 0 {
+		s.publishChange(familyID)
+	}
+	return result, nil
+}
+
+// feedRows returns the events the integration brought in, by feed key
+func (s *CalendarService) feedRows(integrationID string) (map[string]feedRow, error) {
+	rows, err := s.db.Query(`
+		SELECT id, ical_uid, original_start_time, source_hash, updated_at, source_synced_at
+		FROM unified_calendar_events
+		WHERE source_integration_id = ?
+	`, integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feed events: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]feedRow)
+	for rows.Next() {
+		var id string
+		var uid, hash sql.NullString
+		var original, syncedAt *time.Time
+		var updatedAt time.Time
+		if err := rows.Scan(&id, &uid, &original, &hash, &updatedAt, &syncedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan feed event: %w", err)
+		}
+		var originalStart time.Time
+		if original != nil {
+			originalStart = *original
+		}
+		existing[feedKey(uid.String, originalStart)] = feedRow{
+			id:        id,
+			hash:      hash.String,
+			edited:    syncedAt != nil && updatedAt.After(*syncedAt),
+			updatedAt: updatedAt,
+		}
+	}
+	return existing, rows.Err()
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/ical"
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedSettingsRefresh(t *testing.T) {
+	assert.Equal(t, DefaultFeedRefresh, FeedSettings{}.Refresh())
+	assert.Equal(t, MinFeedRefresh, FeedSettings{RefreshMinutes: 1}.Refresh())
+	assert.Equal(t, 6*time.Hour, FeedSettings{RefreshMinutes: 360}.Refresh())
+}
+
+func TestFeedEntries(t *testing.T) {
+	start := time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+	events := []ical.Event{
+		{UID: "override", Title: "Late start", Start: start.Add(week + time.Hour), End: start.Add(week + 2*time.Hour), RecurrenceID: start.Add(week)},
+		{UID: "override", Title: "No school", Start: start.Add(2 * week), End: start.Add(2*week + time.Hour), RecurrenceID: start.Add(2 * week), Cancelled: true},
+		{UID: "override", Title: "Assembly", Start: start, End: start.Add(time.Hour), RecurrenceRule: "FREQ=WEEKLY",
+			ExtraDates: []time.Time{start.Add(3 * 24 * time.Hour)}},
+		{UID: "cancelled", Title: "Picture day", Start: start, End: start.Add(time.Hour), Cancelled: true},
+		{UID: "override", Title: "Duplicate", Start: start, End: start.Add(time.Hour)},
+	}
+
+	entries := feedEntries(events, FeedSettings{})
+	require.Len(t, entries, 3)
+
+	series := entries[0]
+	assert.Equal(t, "Assembly", series.event.Title)
+	assert.True(t, series.original.IsZero())
+	assert.Equal(t, []time.Time{start.Add(week), start.Add(2 * week)}, series.event.ExceptionDates,
+		"the series skips both the moved and the cancelled occurrence")
+
+	extra := entries[1]
+	assert.Equal(t, start.Add(3*24*time.Hour), extra.original)
+	assert.Equal(t, start.Add(3*24*time.Hour+time.Hour), extra.event.End)
+	assert.Empty(t, extra.event.RecurrenceRule)
+	assert.False(t, extra.series, "an RDATE is stored as a single event")
+
+	moved := entries[2]
+	assert.Equal(t, "Late start", moved.event.Title)
+	assert.True(t, moved.series)
+	assert.Equal(t, "override@20250908T080000Z", feedKey(moved.uid, moved.original))
+
+	assert.Empty(t, events[2].ExceptionDates, "the feed's events are left as they were")
+}
+
+func TestFeedHashFollowsSettings(t *testing.T) {
+	start := time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)
+	events := []ical.Event{{UID: "game", Title: "Game", Start: start, End: start.Add(time.Hour)}}
+
+	plain := feedEntries(events, FeedSettings{})[0].hash
+	assert.Equal(t, plain, feedEntries(events, FeedSettings{RefreshMinutes: 30})[0].hash)
+	assert.NotEqual(t, plain, feedEntries(events, FeedSettings{Color: "#ff0000"})[0].hash)
+	assert.Equal(t,
+		feedEntries(events, FeedSettings{Assignees: []string{"a", "b"}})[0].hash,
+		feedEntries(events, FeedSettings{Assignees: []string{"b", "a"}})[0].hash)
+
+	events[0].Location = "Field 2"
+	assert.NotEqual(t, plain, feedEntries(events, FeedSettings{})[0].hash)
+}
+
+func TestSyncFeedEvents(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+	integration := &Integration{
+		ID:       "integration_feed",
+		FamilyID: familyID,
+		Settings: `{"url": "https://school.example.org/calendar.ics", "color": "#22aa55", "assignees": ["` + memberID + `", "member_gone"]}`,
+	}
+	_, err := db.Exec(`
+		INSERT INTO integrations (id, family_id, created_by, integration_type, provider, auth_method, status,
+								 display_name, description, settings, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, integration.ID, familyID, memberID, TypeCalendar, ProviderICS, AuthToken, StatusPending,
+		"School", "", integration.Settings, true, time.Now(), time.Now())
+	require.NoError(t, err)
+
+	start := time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)
+	feed := []ical.Event{
+		{UID: "assembly", Title: "Assembly", Start: start, End: start.Add(time.Hour), RecurrenceRule: "FREQ=WEEKLY"},
+		{UID: "assembly", Title: "Assembly (gym)", Start: start.Add(7*24*time.Hour + time.Hour), End: start.Add(7*24*time.Hour + 2*time.Hour),
+			RecurrenceID: start.Add(7 * 24 * time.Hour)},
+		{UID: "fair", Title: "Book fair", Start: start, End: start.Add(24 * time.Hour), AllDay: true},
+	}
+
+	result, err := service.SyncFeedEvents(integration, feed)
+	require.NoError(t, err)
+	assert.Equal(t, FeedSyncResult{Created: 3}, *result)
+
+	result, err = service.SyncFeedEvents(integration, feed)
+	require.NoError(t, err)
+	assert.Equal(t, FeedSyncResult{Unchanged: 3}, *result, "an unchanged feed writes nothing")
+
+	// Dropping the series leaves its moved occurrence standing on its own
+	feed[2].Title = "Book fair (library)"
+	result, err = service.SyncFeedEvents(integration, feed[1:])
+	require.NoError(t, err)
+	assert.Equal(t, FeedSyncResult{Updated: 2, Removed: 1}, *result)
+
+	rows, err := service.feedRows(integration.ID)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	fair, err := service.GetStoredUnifiedCalendarEvent(rows["fair"].id)
+	require.NoError(t, err)
+	assert.Equal(t, "Book fair (library)", fair.Title)
+	assert.Equal(t, "#22aa55", fair.Color)
+	require.NotNil(t, fair.SourceIntegrationID)
+	assert.Equal(t, integration.ID, *fair.SourceIntegrationID)
+
+	var attendees int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM unified_calendar_event_attendees WHERE event_id = ?`, fair.ID).Scan(&attendees))
+	assert.Equal(t, 1, attendees, "members no longer in the family are dropped")
+
+	integrations := NewIntegrationsService(db, nil)
+	require.NoError(t, integrations.DeleteIntegration(integration.ID))
+	rows, err = service.feedRows(integration.ID)
+	require.NoError(t, err)
+	assert.Empty(t, rows, "deleting the subscription deletes its events")
+}
+
+func TestFeedConflictWinner(t *testing.T) {
+	edited := time.Date(2025, 9, 2, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, models.SyncConflictRemoteWins, FeedSettings{}.Policy())
+	assert.Equal(t, models.SyncConflictRemoteWins, FeedSettings{ConflictPolicy: "coin_toss"}.Policy())
+	assert.Equal(t, models.SyncConflictManual, FeedSettings{ConflictPolicy: "manual"}.Policy())
+
+	assert.Equal(t, models.SyncConflictKeepRemote, feedConflictWinner(models.SyncConflictRemoteWins, edited, time.Time{}))
+	assert.Equal(t, models.SyncConflictKeepLocal, feedConflictWinner(models.SyncConflictLocalWins, edited, edited.Add(time.Hour)))
+	assert.Equal(t, "", feedConflictWinner(models.SyncConflictManual, edited, time.Time{}))
+
+	assert.Equal(t, models.SyncConflictKeepRemote, feedConflictWinner(models.SyncConflictNewestWins, edited, edited.Add(time.Hour)))
+	assert.Equal(t, models.SyncConflictKeepLocal, feedConflictWinner(models.SyncConflictNewestWins, edited, edited.Add(-time.Hour)))
+	assert.Equal(t, models.SyncConflictKeepRemote, feedConflictWinner(models.SyncConflictNewestWins, edited, time.Time{}),
+		"a feed that doesn't say changed it since the last fetch")
+}
+
+func TestSyncFeedEvents_ManualConflicts(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+	integration := &Integration{
+		ID:       "integration_feed",
+		FamilyID: familyID,
+		Provider: ProviderICS,
+		Settings: `{"url": "https://team.example.org/calendar.ics", "conflict_policy": "manual"}`,
+	}
+	_, err := db.Exec(`
+		INSERT INTO integrations (id, family_id, created_by, integration_type, provider, auth_method, status,
+								 display_name, description, settings, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, integration.ID, familyID, memberID, TypeCalendar, ProviderICS, AuthToken, StatusPending,
+		"Team", "", integration.Settings, true, time.Now(), time.Now())
+	require.NoError(t, err)
+
+	start := time.Date(2025, 9, 6, 9, 0, 0, 0, time.UTC)
+	feed := []ical.Event{
+		{UID: "game", Title: "Game", Start: start, End: start.Add(time.Hour)},
+		{UID: "practice", Title: "Practice", Start: start.Add(24 * time.Hour), End: start.Add(25 * time.Hour)},
+	}
+	_, err = service.SyncFeedEvents(integration, feed)
+	require.NoError(t, err)
+	rows, err := service.feedRows(integration.ID)
+	require.NoError(t, err)
+	gameID := rows["game"].id
+
+	// The family moves the game, then the feed renames it
+	_, err = db.Exec(`UPDATE unified_calendar_events SET location = ?, updated_at = ? WHERE id = ?`,
+		"Field 3", time.Now().UTC().Add(time.Minute), gameID)
+	require.NoError(t, err)
+	feed[0].Title = "Home game"
+	feed[1].Title = "Practice (gym)"
+
+	result, err := service.SyncFeedEvents(integration, feed)
+	require.NoError(t, err)
+	assert.Equal(t, FeedSyncResult{Updated: 1, Conflicts: 1}, *result, "the unedited event follows the feed")
+
+	conflicts, err := service.ListSyncConflicts(familyID, integration.ID)
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, gameID, conflicts[0].EventID)
+	assert.Equal(t, "Game", conflicts[0].Local.Title)
+	assert.Equal(t, "Home game", conflicts[0].Remote.Title)
+
+	// Until it is resolved, later fetches update the same conflict
+	_, err = service.SyncFeedEvents(integration, feed)
+	require.NoError(t, err)
+	conflicts, err = service.ListSyncConflicts(familyID, integration.ID)
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1)
+
+	event, err := service.ResolveSyncConflict(familyID, conflicts[0].ID, models.SyncConflictKeepRemote)
+	require.NoError(t, err)
+	assert.Equal(t, "Home game", event.Title)
+	assert.Nil(t, event.Location)
+
+	conflicts, err = service.ListSyncConflicts(familyID, integration.ID)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+	result, err = service.SyncFeedEvents(integration, feed)
+	require.NoError(t, err)
+	assert.Equal(t, FeedSyncResult{Unchanged: 2}, *result)
+
+	_, err = service.ResolveSyncConflict(familyID, "conflict_gone", models.SyncConflictKeepLocal)
+	assert.EqualError(t, err, "sync conflict not found")
+}
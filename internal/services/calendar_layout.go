@@ -0,0 +1,277 @@
+package services
+
+import (
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"famstack/internal/eventbus"
+	"famstack/internal/models"
+)
+
+// Calendar days are laid out in 15-minute slots
+const (
+	SlotMinutes = 15
+	SlotsPerDay = 24 * 60 / SlotMinutes
+)
+
+// maxCachedDayLayouts bounds how many day layouts a family keeps between
+// changes to its calendar
+const maxCachedDayLayouts = 256
+
+// dayLayoutCache remembers laid-out days per family. A layout is keyed by a
+// hash of the view events it was built from, so it is only reused for the
+// very same events, as the same viewer sees them; a family's layouts are
+// dropped whenever its calendar changes.
+type dayLayoutCache struct {
+	mu       sync.Mutex
+	families map[string]map[uint64][]models.CalendarLayer
+}
+
+func newDayLayoutCache() *dayLayoutCache {
+	return &dayLayoutCache{families: make(map[string]map[uint64][]models.CalendarLayer)}
+}
+
+func (c *dayLayoutCache) get(familyID string, key uint64) ([]models.CalendarLayer, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	layers, ok := c.families[familyID][key]
+	return layers, ok
+}
+
+func (c *dayLayoutCache) put(familyID string, key uint64, layers []models.CalendarLayer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	days := c.families[familyID]
+	if days == nil || len(days) >= maxCachedDayLayouts {
+		days = make(map[uint64][]models.CalendarLayer)
+		c.families[familyID] = days
+	}
+	days[key] = layers
+}
+
+func (c *dayLayoutCache) invalidate(familyID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.families, familyID)
+}
+
+// subscribe drops a family's layouts whenever its calendar changes
+func (c *dayLayoutCache) subscribe(bus *eventbus.Bus) {
+	if bus == nil {
+		return
+	}
+	bus.Subscribe(func(event eventbus.Event) {
+		c.invalidate(event.FamilyID)
+	}, eventbus.TopicCalendarChanged)
+}
+
+// LayoutDay places one day's events in slots and assigns them to layers, so
+// overlapping events sit side by side. The day starts at dayStart and is
+// laid out in its timezone. Layouts are remembered until the family's
+// calendar changes; the returned layers are shared with later callers and
+// must not be modified.
+func (s *CalendarService) LayoutDay(familyID string, dayStart time.Time, events []models.UnifiedCalendarEvent) []models.CalendarLayer {
+	if len(events) == 0 {
+		return []models.CalendarLayer{}
+	}
+
+	dayEnd := NextDay(dayStart)
+	viewEvents := make([]models.CalendarViewEvent, 0, len(events))
+	for _, event := range events {
+		viewEvents = append(viewEvents, toViewEvent(event, dayStart, dayEnd))
+	}
+
+	key := viewEventsKey(viewEvents)
+	if layers, ok := s.layouts.get(familyID, key); ok {
+		return layers
+	}
+	layers := LayoutViewEvents(viewEvents)
+	s.layouts.put(familyID, key, layers)
+	return layers
+}
+
+// NextDay returns the midnight after dayStart in its timezone, which is 23 or
+// 25 hours later on the days daylight saving starts or ends
+func NextDay(dayStart time.Time) time.Time {
+	return time.Date(dayStart.Year(), dayStart.Month(), dayStart.Day()+1, 0, 0, 0, 0, dayStart.Location())
+}
+
+// viewEventsKey hashes everything a layout is built from: every field of
+// every view event, so it must change when CalendarViewEvent does
+func viewEventsKey(viewEvents []models.CalendarViewEvent) uint64 {
+	hash := fnv.New64a()
+	write := func(values ...string) {
+		for _, value := range values {
+			hash.Write([]byte(value))
+			hash.Write([]byte{0})
+		}
+	}
+	optional := func(value *string) string {
+		if value == nil {
+			return "\x01"
+		}
+		return *value
+	}
+
+	for _, event := range viewEvents {
+		write(event.ID, event.Title, strconv.Itoa(event.StartSlot), strconv.Itoa(event.EndSlot),
+			event.Color, event.OwnerID, strconv.FormatBool(event.IsPrivate),
+			optional(event.Location), optional(event.Description))
+		write(event.AttendeeIDs...)
+		for _, attendee := range event.Attendees {
+			write(attendee.ID, attendee.Name, attendee.Initial, attendee.Color, attendee.Response)
+		}
+		write("\x02")
+	}
+	return hash.Sum64()
+}
+
+// LayoutViewEvents assigns slot-placed events to layers, each in the first
+// layer it fits without overlapping another, and fills in their overlap info
+func LayoutViewEvents(viewEvents []models.CalendarViewEvent) []models.CalendarLayer {
+	layers := []models.CalendarLayer{}
+	layerOf := make([]int, len(viewEvents))
+	positionOf := make([]int, len(viewEvents))
+
+	for i, event := range viewEvents {
+		layerIndex := findAvailableLayer(layers, event)
+		for len(layers) <= layerIndex {
+			layers = append(layers, models.CalendarLayer{
+				LayerIndex: len(layers),
+				Events:     []models.CalendarViewEvent{},
+			})
+		}
+
+		event.OverlapIndex = layerIndex
+		layerOf[i] = layerIndex
+		positionOf[i] = len(layers[layerIndex].Events)
+		layers[layerIndex].Events = append(layers[layerIndex].Events, event)
+	}
+
+	// An event's overlap group is the number of layers used by the events it
+	// is transitively connected to through overlaps
+	for i, group := range overlapGroups(viewEvents, layerOf) {
+		layers[layerOf[i]].Events[positionOf[i]].OverlapGroup = group
+	}
+
+	return layers
+}
+
+// findAvailableLayer finds the first layer where an event can be placed
+// without conflicts, or the index of a new layer
+func findAvailableLayer(layers []models.CalendarLayer, newEvent models.CalendarViewEvent) int {
+	for layerIndex, layer := range layers {
+		hasConflict := false
+		for _, existingEvent := range layer.Events {
+			if eventsOverlap(newEvent, existingEvent) {
+				hasConflict = true
+				break
+			}
+		}
+		if !hasConflict {
+			return layerIndex
+		}
+	}
+	return len(layers)
+}
+
+// overlapGroups finds each event's connected events once per group of
+// overlapping events, rather than once per event
+func overlapGroups(viewEvents []models.CalendarViewEvent, layerOf []int) []int {
+	groups := make([]int, len(viewEvents))
+	visited := make([]bool, len(viewEvents))
+
+	for start := range viewEvents {
+		if visited[start] {
+			continue
+		}
+
+		// Walk everything connected to this event
+		connected := []int{start}
+		visited[start] = true
+		for next := 0; next < len(connected); next++ {
+			current := viewEvents[connected[next]]
+			for other := range viewEvents {
+				if !visited[other] && eventsOverlap(current, viewEvents[other]) {
+					visited[other] = true
+					connected = append(connected, other)
+				}
+			}
+		}
+
+		layersUsed := make(map[int]bool)
+		for _, i := range connected {
+			layersUsed[layerOf[i]] = true
+		}
+		for _, i := range connected {
+			groups[i] = len(layersUsed)
+		}
+	}
+
+	return groups
+}
+
+// eventsOverlap checks if two events share any slot
+func eventsOverlap(event1, event2 models.CalendarViewEvent) bool {
+	return event1.StartSlot < event2.EndSlot && event1.EndSlot > event2.StartSlot
+}
+
+// toViewEvent places an event in the slots of the day from dayStart to
+// dayEnd, in dayStart's timezone. Slots follow the wall clock, so a day has
+// SlotsPerDay of them however long daylight saving makes it. An event running
+// into the day before or after is clipped to this one.
+func toViewEvent(event models.UnifiedCalendarEvent, dayStart, dayEnd time.Time) models.CalendarViewEvent {
+	startSlot := 0
+	if event.StartTime.After(dayStart) {
+		startSlot = TimeToSlot(event.StartTime.In(dayStart.Location()))
+	}
+	endSlot := SlotsPerDay
+	if event.EndTime.Before(dayEnd) {
+		endSlot = TimeToSlot(event.EndTime.In(dayStart.Location()))
+	}
+
+	// Ensure endSlot is at least startSlot + 1
+	if endSlot <= startSlot {
+		endSlot = startSlot + 1
+	}
+
+	attendeeIDs := make([]string, len(event.Attendees))
+	for i, attendee := range event.Attendees {
+		attendeeIDs[i] = attendee.ID
+	}
+
+	ownerID := ""
+	if event.CreatedBy != nil {
+		ownerID = *event.CreatedBy
+	}
+
+	return models.CalendarViewEvent{
+		ID:           event.ID,
+		Title:        event.Title,
+		StartSlot:    startSlot,
+		EndSlot:      endSlot,
+		Color:        event.Color,
+		OwnerID:      ownerID,
+		AttendeeIDs:  attendeeIDs,
+		OverlapGroup: 1,
+		OverlapIndex: 0,
+		Attendees:    event.Attendees,
+		IsPrivate:    isPrivateEvent(event),
+		Location:     event.Location,
+		Description:  event.Description,
+	}
+}
+
+// TimeToSlot converts a time of day to its 15-minute slot
+func TimeToSlot(t time.Time) int {
+	slot := (t.Hour()*60 + t.Minute()) / SlotMinutes
+	if slot < 0 {
+		slot = 0
+	}
+	if slot >= SlotsPerDay {
+		slot = SlotsPerDay - 1
+	}
+	return slot
+}
@@ -1,9 +1,11 @@
-package api
+package services
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
+	"famstack/internal/eventbus"
 	"famstack/internal/models"
 
 	"github.com/stretchr/testify/assert"
@@ -12,8 +14,6 @@ import (
 
 // Test the layer assignment algorithm with various overlap scenarios
 func TestCalculateEventLayers(t *testing.T) {
-	handler := &CalendarAPIHandler{}
-
 	tests := []struct {
 		name           string
 		events         []models.UnifiedCalendarEvent
@@ -96,7 +96,7 @@ func TestCalculateEventLayers(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			layers := handler.calculateEventLayers(tt.events, "UTC")
+			layers := layoutEvents(tt.events)
 
 			assert.Equal(t, tt.expectedLayers, len(layers), tt.description)
 
@@ -109,7 +109,7 @@ func TestCalculateEventLayers(t *testing.T) {
 					for j, event1 := range layer.Events {
 						for k, event2 := range layer.Events {
 							if j != k {
-								assert.False(t, handler.eventsOverlap(event1, event2),
+								assert.False(t, eventsOverlap(event1, event2),
 									"Events %s and %s should not overlap in same layer %d", event1.ID, event2.ID, i)
 							}
 						}
@@ -124,8 +124,6 @@ func TestCalculateEventLayers(t *testing.T) {
 
 // Test time to slot conversion
 func TestTimeToSlot(t *testing.T) {
-	handler := &CalendarAPIHandler{}
-
 	tests := []struct {
 		time         string
 		expectedSlot int
@@ -150,7 +148,7 @@ func TestTimeToSlot(t *testing.T) {
 			parsedTime, err := time.Parse("15:04", tt.time)
 			require.NoError(t, err)
 
-			slot := handler.timeToSlot(parsedTime)
+			slot := TimeToSlot(parsedTime)
 			assert.Equal(t, tt.expectedSlot, slot, tt.description)
 		})
 	}
@@ -158,8 +156,6 @@ func TestTimeToSlot(t *testing.T) {
 
 // Test events overlap detection
 func TestEventsOverlap(t *testing.T) {
-	handler := &CalendarAPIHandler{}
-
 	tests := []struct {
 		name          string
 		event1        models.CalendarViewEvent
@@ -213,11 +209,11 @@ func TestEventsOverlap(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			overlap := handler.eventsOverlap(tt.event1, tt.event2)
+			overlap := eventsOverlap(tt.event1, tt.event2)
 			assert.Equal(t, tt.shouldOverlap, overlap, tt.description)
 
 			// Test symmetry - overlap should be the same regardless of order
-			reverseOverlap := handler.eventsOverlap(tt.event2, tt.event1)
+			reverseOverlap := eventsOverlap(tt.event2, tt.event1)
 			assert.Equal(t, overlap, reverseOverlap, "Overlap detection should be symmetric")
 		})
 	}
@@ -225,8 +221,6 @@ func TestEventsOverlap(t *testing.T) {
 
 // Test the full DayView conversion with real scenarios
 func TestDayViewIntegration(t *testing.T) {
-	handler := &CalendarAPIHandler{}
-
 	tests := []struct {
 		name           string
 		events         []models.UnifiedCalendarEvent
@@ -267,7 +261,7 @@ func TestDayViewIntegration(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Calculate layers
-			layers := handler.calculateEventLayers(tt.events, "UTC")
+			layers := layoutEvents(tt.events)
 			assert.Equal(t, tt.expectedLayers, len(layers), tt.description+" - layer count")
 
 			// Verify all events are placed somewhere
@@ -287,8 +281,6 @@ func TestDayViewIntegration(t *testing.T) {
 
 // Test overlap group functionality - the new feature
 func TestOverlapGroupCalculation(t *testing.T) {
-	handler := &CalendarAPIHandler{}
-
 	tests := []struct {
 		name           string
 		events         []models.UnifiedCalendarEvent
@@ -377,7 +369,7 @@ func TestOverlapGroupCalculation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			layers := handler.calculateEventLayers(tt.events, "UTC")
+			layers := layoutEvents(tt.events)
 			require.Greater(t, len(layers), 0, "Should create at least one layer")
 
 			// Collect all events from all layers
@@ -415,8 +407,6 @@ func TestOverlapGroupCalculation(t *testing.T) {
 
 // Test the specific Quick Check-in scenario that was failing
 func TestQuickCheckinScenario(t *testing.T) {
-	handler := &CalendarAPIHandler{}
-
 	// Recreate the exact scenario from the screenshot
 	events := []models.UnifiedCalendarEvent{
 		// 12:00-12:30 PM: Quick Check-in (slots 48-50)
@@ -429,7 +419,7 @@ func TestQuickCheckinScenario(t *testing.T) {
 		createTestEventWithSlots("sarah", "1:1 with Sarah", 52, 54),
 	}
 
-	layers := handler.calculateEventLayers(events, "UTC")
+	layers := layoutEvents(events)
 
 	// Collect all events from layers
 	allEvents := make(map[string]models.CalendarViewEvent)
@@ -468,8 +458,6 @@ func TestQuickCheckinScenario(t *testing.T) {
 
 // Test that overlap calculation gives consistent results for client rendering
 func TestOverlapGroupClientCompatibility(t *testing.T) {
-	handler := &CalendarAPIHandler{}
-
 	// Create scenario with various overlap patterns
 	events := []models.UnifiedCalendarEvent{
 		createTestEvent("standalone", "Standalone", "08:00", "09:00"),
@@ -480,7 +468,7 @@ func TestOverlapGroupClientCompatibility(t *testing.T) {
 		createTestEvent("triple3", "Triple 3", "14:40", "16:00"),
 	}
 
-	layers := handler.calculateEventLayers(events, "UTC")
+	layers := layoutEvents(events)
 	allEvents := make(map[string]models.CalendarViewEvent)
 	for _, layer := range layers {
 		for _, event := range layer.Events {
@@ -515,6 +503,17 @@ func TestOverlapGroupClientCompatibility(t *testing.T) {
 	t.Log("✅ Client rendering calculations validated")
 }
 
+// layoutEvents lays out one day's events, on the day the first one starts,
+// through the service
+func layoutEvents(events []models.UnifiedCalendarEvent) []models.CalendarLayer {
+	if len(events) == 0 {
+		return NewCalendarService(nil).LayoutDay("fam_test", time.Time{}, events)
+	}
+	first := events[0].StartTime
+	day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, time.UTC)
+	return NewCalendarService(nil).LayoutDay("fam_test", day, events)
+}
+
 // Helper function to create test events
 func createTestEvent(id, title, startTime, endTime string) models.UnifiedCalendarEvent {
 	start, _ := time.Parse("15:04", startTime)
@@ -550,3 +549,113 @@ func createTestEventWithSlots(id, title string, startSlot, endSlot int) models.U
 		Attendees: []models.EventAttendee{},
 	}
 }
+
+func TestLayoutDay_CachesUntilCalendarChanges(t *testing.T) {
+	service := NewCalendarService(nil)
+	bus := eventbus.New()
+	service.SetEventBus(bus)
+	events := []models.UnifiedCalendarEvent{
+		createTestEvent("1", "Breakfast", "08:00", "09:00"),
+		createTestEvent("2", "School run", "08:30", "09:00"),
+	}
+
+	day := time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := service.LayoutDay("fam_1", day, events)
+	require.Len(t, first, 2)
+	assert.Same(t, &first[0], &service.LayoutDay("fam_1", day, events)[0], "the same events reuse the layout")
+
+	// Different events, or the same events seen differently, are laid out afresh
+	private := append([]models.UnifiedCalendarEvent{}, events...)
+	private[1].Title = "Busy"
+	assert.NotSame(t, &first[0], &service.LayoutDay("fam_1", day, private)[0])
+
+	bus.Publish(eventbus.Event{Topic: eventbus.TopicCalendarChanged, FamilyID: "fam_1"})
+	again := service.LayoutDay("fam_1", day, events)
+	assert.NotSame(t, &first[0], &again[0], "a calendar change drops the family's layouts")
+	assert.Equal(t, first, again)
+}
+
+func TestLayoutDay_DaylightSavingDays(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, chicago)
+	}
+	slots := func(layers []models.CalendarLayer) map[string][2]int {
+		placed := make(map[string][2]int)
+		for _, layer := range layers {
+			for _, event := range layer.Events {
+				placed[event.ID] = [2]int{event.StartSlot, event.EndSlot}
+			}
+		}
+		return placed
+	}
+	service := NewCalendarService(nil)
+
+	// Clocks jump from 2:00 to 3:00, so the day is 23 hours long
+	springDay := at(2026, 3, 8, 0, 0)
+	require.Equal(t, 23*time.Hour, NextDay(springDay).Sub(springDay))
+	placed := slots(service.LayoutDay("fam_1", springDay, []models.UnifiedCalendarEvent{
+		{ID: "overnight", StartTime: at(2026, 3, 7, 22, 0), EndTime: at(2026, 3, 8, 1, 30)},
+		{ID: "across-the-jump", StartTime: at(2026, 3, 8, 1, 0), EndTime: at(2026, 3, 8, 4, 0)},
+		{ID: "late", StartTime: at(2026, 3, 8, 23, 0), EndTime: at(2026, 3, 9, 1, 0)},
+	}))
+	assert.Equal(t, [2]int{0, 6}, placed["overnight"], "clipped to the start of the day")
+	assert.Equal(t, [2]int{4, 16}, placed["across-the-jump"], "slots follow the wall clock")
+	assert.Equal(t, [2]int{92, SlotsPerDay}, placed["late"], "clipped to the end of the day")
+
+	// Clocks go back from 2:00 to 1:00, so the day is 25 hours long
+	fallDay := at(2026, 11, 1, 0, 0)
+	require.Equal(t, 25*time.Hour, NextDay(fallDay).Sub(fallDay))
+	placed = slots(service.LayoutDay("fam_1", fallDay, []models.UnifiedCalendarEvent{
+		{ID: "across-the-repeat", StartTime: at(2026, 11, 1, 0, 30), EndTime: at(2026, 11, 1, 0, 30).Add(3 * time.Hour)},
+		{ID: "late", StartTime: at(2026, 11, 1, 23, 30), EndTime: at(2026, 11, 2, 0, 30)},
+	}))
+	assert.Equal(t, [2]int{2, 10}, placed["across-the-repeat"], "three hours from 0:30 ends at 2:30 on the wall clock")
+	assert.Equal(t, [2]int{94, SlotsPerDay}, placed["late"])
+
+	// The same instants are laid out in whichever timezone the day is in
+	utcDay := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	placed = slots(service.LayoutDay("fam_1", utcDay, []models.UnifiedCalendarEvent{
+		{ID: "late", StartTime: at(2026, 10, 31, 20, 0), EndTime: at(2026, 10, 31, 21, 0)},
+	}))
+	assert.Equal(t, [2]int{4, 8}, placed["late"])
+}
+
+func BenchmarkLayoutDay(b *testing.B) {
+	for _, count := range []int{50, 200, 500} {
+		// A busy day: events start every few minutes and run up to two hours
+		events := make([]models.UnifiedCalendarEvent, count)
+		day := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+		for i := range events {
+			start := day.Add(6*time.Hour + time.Duration(i*7%(14*60))*time.Minute)
+			events[i] = models.UnifiedCalendarEvent{
+				ID:        fmt.Sprintf("event_%d", i),
+				Title:     fmt.Sprintf("Event %d", i),
+				StartTime: start,
+				EndTime:   start.Add(time.Duration(15+i%8*15) * time.Minute),
+				Attendees: []models.EventAttendee{},
+			}
+		}
+
+		b.Run(fmt.Sprintf("events=%d/uncached", count), func(b *testing.B) {
+			viewEvents := make([]models.CalendarViewEvent, len(events))
+			for i, event := range events {
+				viewEvents[i] = toViewEvent(event, day, NextDay(day))
+			}
+			b.ResetTimer()
+			for range b.N {
+				LayoutViewEvents(viewEvents)
+			}
+		})
+
+		b.Run(fmt.Sprintf("events=%d/cached", count), func(b *testing.B) {
+			service := NewCalendarService(nil)
+			service.LayoutDay("fam_bench", day, events)
+			b.ResetTimer()
+			for range b.N {
+				service.LayoutDay("fam_bench", day, events)
+			}
+		})
+	}
+}
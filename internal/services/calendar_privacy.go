@@ -0,0 +1,168 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"famstack/internal/models"
+	"famstack/internal/templates"
+)
+
+// GetUnifiedCalendarEventsFor lists events the way viewer may see them:
+// private events they can't see are left out, and busy ones are reduced to
+// the time they take
+func (s *CalendarService) GetUnifiedCalendarEventsFor(viewer *models.CalendarViewer, familyID string, startDate, endDate time.Time) ([]models.UnifiedCalendarEvent, error) {
+	events, err := s.GetUnifiedCalendarEvents(familyID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	return s.ApplyEventPrivacy(viewer, familyID, events)
+}
+
+// GetUnifiedCalendarEventFor returns one of the family's events the way
+// viewer may see it. Another family's event, or a private event they can't
+// see, is not found.
+func (s *CalendarService) GetUnifiedCalendarEventFor(viewer *models.CalendarViewer, familyID, eventID string) (*models.UnifiedCalendarEvent, error) {
+	event, err := s.GetUnifiedCalendarEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := inFamily("unified calendar event", event.FamilyID, familyID); err != nil {
+		return nil, err
+	}
+	events, err := s.ApplyEventPrivacy(viewer, familyID, []models.UnifiedCalendarEvent{*event})
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, notFound("unified calendar event")
+	}
+	return &events[0], nil
+}
+
+// ApplyEventPrivacy drops the private events viewer can't see from a
+// family's events and redacts the busy ones
+func (s *CalendarService) ApplyEventPrivacy(viewer *models.CalendarViewer, familyID string, events []models.UnifiedCalendarEvent) ([]models.UnifiedCalendarEvent, error) {
+	children, err := s.childrenFor(viewer, familyID, events)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]models.UnifiedCalendarEvent, 0, len(events))
+	busyTitle := ""
+	for _, event := range events {
+		if canSeeEventDetails(viewer, children, &event) {
+			visible = append(visible, event)
+			continue
+		}
+		if event.Visibility == models.EventVisibilityPrivate {
+			continue
+		}
+		if busyTitle == "" {
+			locale, err := GetFamilyLocale(s.db, familyID)
+			if err != nil {
+				return nil, err
+			}
+			busyTitle = templates.NewPrinter(locale).Sprintf("calendar.busy")
+		}
+		visible = append(visible, redactEvent(event, busyTitle))
+	}
+	return visible, nil
+}
+
+// CanSeeEventDetails reports whether viewer sees the family's event in full
+func (s *CalendarService) CanSeeEventDetails(viewer *models.CalendarViewer, familyID string, event *models.UnifiedCalendarEvent) (bool, error) {
+	children, err := s.childrenFor(viewer, familyID, []models.UnifiedCalendarEvent{*event})
+	if err != nil {
+		return false, err
+	}
+	return canSeeEventDetails(viewer, children, event), nil
+}
+
+// childrenFor returns the family's children when viewer is an adult who may
+// need them to see an event, and nil otherwise
+func (s *CalendarService) childrenFor(viewer *models.CalendarViewer, familyID string, events []models.UnifiedCalendarEvent) (map[string]bool, error) {
+	if viewer == nil || !viewer.Adult {
+		return nil, nil
+	}
+	needed := false
+	for i := range events {
+		if !isPublicEvent(&events[i]) {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return nil, nil
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id FROM family_members WHERE family_id = ? AND member_type = ?
+	`, familyID, models.MemberTypeChild)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query family children: %w", err)
+	}
+	defer rows.Close()
+
+	children := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan family child: %w", err)
+		}
+		children[id] = true
+	}
+	return children, rows.Err()
+}
+
+// canSeeEventDetails is the visibility rule: public events are seen by
+// everyone, others by their creator and attendees, and by adults when a
+// child created them or every attendee is a child
+func canSeeEventDetails(viewer *models.CalendarViewer, children map[string]bool, event *models.UnifiedCalendarEvent) bool {
+	if isPublicEvent(event) {
+		return true
+	}
+	if viewer == nil {
+		return false
+	}
+	if event.CreatedBy != nil && *event.CreatedBy == viewer.MemberID {
+		return true
+	}
+	for _, attendee := range event.Attendees {
+		if attendee.ID == viewer.MemberID {
+			return true
+		}
+	}
+	if !viewer.Adult {
+		return false
+	}
+	if event.CreatedBy != nil {
+		return children[*event.CreatedBy]
+	}
+	if len(event.Attendees) == 0 {
+		return false
+	}
+	for _, attendee := range event.Attendees {
+		if !children[attendee.ID] {
+			return false
+		}
+	}
+	return true
+}
+
+func isPublicEvent(event *models.UnifiedCalendarEvent) bool {
+	return event.Visibility == "" || event.Visibility == models.EventVisibilityPublic
+}
+
+// redactEvent keeps only when an event is and who it takes up, titling it
+// "Busy" in the family's language
+func redactEvent(event models.UnifiedCalendarEvent, busyTitle string) models.UnifiedCalendarEvent {
+	event.Title = busyTitle
+	event.Description = nil
+	event.Location = nil
+	event.Category = ""
+	event.CelebrationKind = nil
+	event.Age = nil
+	event.Redacted = true
+	return event
+}
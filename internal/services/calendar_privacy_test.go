@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanSeeEventDetails(t *testing.T) {
+	parent, kid := "member_parent", "member_kid"
+	children := map[string]bool{kid: true}
+	adult := &models.CalendarViewer{MemberID: parent, Adult: true}
+	child := &models.CalendarViewer{MemberID: kid}
+
+	public := models.UnifiedCalendarEvent{Visibility: models.EventVisibilityPublic}
+	assert.True(t, canSeeEventDetails(nil, nil, &public))
+
+	parentsOwn := models.UnifiedCalendarEvent{Visibility: models.EventVisibilityBusy, CreatedBy: &parent}
+	assert.True(t, canSeeEventDetails(adult, children, &parentsOwn))
+	assert.False(t, canSeeEventDetails(child, nil, &parentsOwn))
+	assert.False(t, canSeeEventDetails(nil, nil, &parentsOwn))
+
+	kidsOwn := models.UnifiedCalendarEvent{Visibility: models.EventVisibilityPrivate, CreatedBy: &kid}
+	assert.True(t, canSeeEventDetails(adult, children, &kidsOwn), "adults see their children's events")
+
+	attended := models.UnifiedCalendarEvent{Visibility: models.EventVisibilityPrivate, CreatedBy: &parent,
+		Attendees: []models.EventAttendee{{ID: kid}}}
+	assert.True(t, canSeeEventDetails(child, nil, &attended), "attendees see the events they're in")
+
+	imported := models.UnifiedCalendarEvent{Visibility: models.EventVisibilityBusy, Attendees: []models.EventAttendee{{ID: kid}}}
+	assert.True(t, canSeeEventDetails(adult, children, &imported))
+	assert.False(t, canSeeEventDetails(&models.CalendarViewer{MemberID: "member_other", Adult: true}, nil, &imported))
+}
+
+func TestGetUnifiedCalendarEventsFor(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, parentID := seedBulkEventFamily(t, db)
+	_, err := db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, is_active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"member_kid", familyID, "Kid", "Member", "child", true, time.Now(), time.Now())
+	require.NoError(t, err)
+
+	start := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	location := "Clinic"
+	response, err := service.BulkCreateUnifiedCalendarEvents(context.Background(), familyID, parentID, []models.BulkCalendarEventItem{
+		{Title: "Dentist", Location: &location, StartTime: start, EndTime: start.Add(time.Hour), Visibility: models.EventVisibilityBusy},
+		{Title: "Gift shopping", StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour), Visibility: models.EventVisibilityPrivate},
+		{Title: "Soccer", StartTime: start.Add(4 * time.Hour), EndTime: start.Add(5 * time.Hour)},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, response.Created)
+
+	invalid, err := service.BulkCreateUnifiedCalendarEvents(context.Background(), familyID, parentID, []models.BulkCalendarEventItem{
+		{Title: "Secret", StartTime: start, EndTime: start.Add(time.Hour), Visibility: "hidden"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, invalid.Invalid)
+
+	end := start.AddDate(0, 0, 1)
+	events, err := service.GetUnifiedCalendarEventsFor(&models.CalendarViewer{MemberID: parentID, Adult: true}, familyID, start, end)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, "Dentist", events[0].Title)
+	assert.False(t, events[0].Redacted)
+
+	events, err = service.GetUnifiedCalendarEventsFor(&models.CalendarViewer{MemberID: "member_kid"}, familyID, start, end)
+	require.NoError(t, err)
+	require.Len(t, events, 2, "private events are left out")
+	assert.Equal(t, models.BusyEventTitle, events[0].Title)
+	assert.Nil(t, events[0].Location)
+	assert.True(t, events[0].Redacted)
+	assert.Equal(t, "Soccer", events[1].Title)
+
+	_, err = service.GetUnifiedCalendarEventFor(nil, familyID, response.Results[1].EventID)
+	assert.EqualError(t, err, "unified calendar event not found")
+
+	public := models.EventVisibilityPublic
+	_, err = service.UpdateUnifiedCalendarEvent(context.Background(), familyID, response.Results[1].EventID, &models.UpdateUnifiedCalendarEventRequest{Visibility: &public})
+	require.NoError(t, err)
+	event, err := service.GetUnifiedCalendarEventFor(nil, familyID, response.Results[1].EventID)
+	require.NoError(t, err)
+	assert.Equal(t, "Gift shopping", event.Title)
+}
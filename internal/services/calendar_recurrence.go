@@ -0,0 +1,622 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"famstack/internal/models"
+	"famstack/internal/recurrence"
+	"famstack/internal/validation"
+)
+
+// parseRecurrenceRule validates a rule and returns it in canonical form, or
+// nil when there is no rule
+func parseRecurrenceRule(rule *string) (*string, error) {
+	if rule == nil || strings.TrimSpace(*rule) == "" {
+		return nil, nil
+	}
+	parsed, err := recurrence.Parse(*rule)
+	if err != nil {
+		return nil, err
+	}
+	value := parsed.String()
+	return &value, nil
+}
+
+// normalizedRule returns an already validated rule in canonical form
+func normalizedRule(rule *string) *string {
+	normalized, _ := parseRecurrenceRule(rule) // nolint:errcheck
+	return normalized
+}
+
+// recurrenceColumns validates a rule and its exception dates and returns the
+// values to store for them
+func recurrenceColumns(validator *validation.Validator, rule *string, exceptionDates []time.Time) (*string, string) {
+	normalized, err := parseRecurrenceRule(rule)
+	if err != nil {
+		validator.AddError("recurrence_rule", err.Error())
+		return nil, ""
+	}
+	if normalized == nil {
+		return nil, ""
+	}
+	return normalized, recurrence.FormatTimes(exceptionDates)
+}
+
+// seriesRule parses a series' rule and the starts of the occurrences it skips
+func seriesRule(series *models.UnifiedCalendarEvent) (*recurrence.Rule, map[int64]bool, error) {
+	rule, err := recurrence.Parse(stringValue(series.RecurrenceRule))
+	if err != nil {
+		return nil, nil, fmt.Errorf("event %s has an invalid recurrence rule: %w", series.ID, err)
+	}
+	exdates, err := recurrence.ParseTimes(series.RecurrenceExdates)
+	if err != nil {
+		return nil, nil, fmt.Errorf("event %s has invalid exception dates: %w", series.ID, err)
+	}
+	skipped := make(map[int64]bool, len(exdates))
+	for _, exdate := range exdates {
+		skipped[exdate.Unix()] = true
+	}
+	return rule, skipped, nil
+}
+
+// expandRecurringEvents replaces each series with its occurrences overlapping
+// [from, to) and returns the events in start order
+func expandRecurringEvents(events []models.UnifiedCalendarEvent, from, to time.Time) ([]models.UnifiedCalendarEvent, error) {
+	expanded := make([]models.UnifiedCalendarEvent, 0, len(events))
+	for i := range events {
+		series := &events[i]
+		if series.RecurrenceRule == nil {
+			expanded = append(expanded, *series)
+			continue
+		}
+
+		rule, skipped, err := seriesRule(series)
+		if err != nil {
+			return nil, err
+		}
+		// All-day occurrences can be an hour longer than the series' first
+		// across a daylight saving change, so look back an extra day
+		length := series.EndTime.Sub(series.StartTime)
+		for _, start := range rule.Between(series.StartTime, from.Add(-length).AddDate(0, 0, -1), to) {
+			if skipped[start.Unix()] {
+				continue
+			}
+			occurrence := occurrenceAt(series, start)
+			if occurrence.EndTime.After(from) {
+				expanded = append(expanded, occurrence)
+			}
+		}
+	}
+
+	sort.SliceStable(expanded, func(i, j int) bool {
+		return expanded[i].StartTime.Before(expanded[j].StartTime)
+	})
+	return expanded, nil
+}
+
+// occurrenceAt returns the occurrence of a series starting at start. Timed
+// occurrences last as long as the first; all-day ones span as many days.
+func occurrenceAt(series *models.UnifiedCalendarEvent, start time.Time) models.UnifiedCalendarEvent {
+	occurrence := *series
+	occurrence.ID = recurrence.InstanceID(series.ID, start)
+	occurrence.StartTime = start
+	occurrence.EndTime = start.Add(series.EndTime.Sub(series.StartTime))
+	if series.AllDay {
+		end := series.EndTime.In(series.StartTime.Location())
+		days := int(time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC).
+			Sub(time.Date(series.StartTime.Year(), series.StartTime.Month(), series.StartTime.Day(), 0, 0, 0, 0, time.UTC)) / (24 * time.Hour))
+		hour, minute, second := end.Clock()
+		occurrence.EndTime = time.Date(start.Year(), start.Month(), start.Day()+days, hour, minute, second, 0, start.Location())
+	}
+	occurrence.RecurrenceExdates = ""
+	seriesID := series.ID
+	occurrence.RecurringEventID = &seriesID
+	originalStart := start
+	occurrence.OriginalStartTime = &originalStart
+	if series.CelebrationKind != nil {
+		celebrateOccurrence(series, &occurrence)
+	}
+	return occurrence
+}
+
+// occurrenceOf returns the occurrence of a series that originally started at
+// the given time, if the series has one there
+func occurrenceOf(series *models.UnifiedCalendarEvent, originalStart time.Time) (*models.UnifiedCalendarEvent, error) {
+	if series.RecurrenceRule == nil {
+		return nil, notFound("unified calendar event")
+	}
+	rule, skipped, err := seriesRule(series)
+	if err != nil {
+		return nil, err
+	}
+
+	start := originalStart.In(series.StartTime.Location())
+	if skipped[start.Unix()] || !rule.Includes(series.StartTime, start) {
+		return nil, notFound("unified calendar event")
+	}
+	occurrence := occurrenceAt(series, start)
+	return &occurrence, nil
+}
+
+// recurrenceTarget is the event an edit or delete names. For events that
+// belong to a series it also holds the series and the occurrence's original
+// start.
+type recurrenceTarget struct {
+	event      *models.UnifiedCalendarEvent
+	series     *models.UnifiedCalendarEvent
+	occurrence time.Time
+	stored     bool // the event is its own row, not generated from the series
+}
+
+// isSeries reports whether the target is the series itself rather than one
+// of its occurrences
+func (t *recurrenceTarget) isSeries() bool {
+	return t.series != nil && t.series.ID == t.event.ID
+}
+
+func (s *CalendarService) findRecurrenceTarget(familyID, eventID string) (*recurrenceTarget, error) {
+	event, err := s.GetUnifiedCalendarEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := inFamily("unified calendar event", event.FamilyID, familyID); err != nil {
+		return nil, err
+	}
+
+	target := &recurrenceTarget{event: event, stored: true}
+	switch {
+	case event.RecurrenceRule != nil && event.RecurringEventID != nil:
+		// An occurrence generated from its series
+		target.stored = false
+		target.occurrence = *event.OriginalStartTime
+		target.series, err = s.GetStoredUnifiedCalendarEvent(*event.RecurringEventID)
+		if err != nil {
+			return nil, err
+		}
+	case event.RecurrenceRule != nil:
+		target.series = event
+		target.occurrence = event.StartTime
+	case event.RecurringEventID != nil && event.OriginalStartTime != nil:
+		// An occurrence edited on its own. Once its series is gone it is an
+		// ordinary event.
+		series, err := s.GetStoredUnifiedCalendarEvent(*event.RecurringEventID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		if err == nil && series.RecurrenceRule != nil {
+			target.series = series
+			target.occurrence = *event.OriginalStartTime
+		}
+	}
+	return target, nil
+}
+
+func validateRecurrenceScope(validator *validation.Validator, scope string) {
+	validator.OneOf("scope", scope, []string{models.RecurrenceScopeThis, models.RecurrenceScopeFuture, models.RecurrenceScopeAll})
+}
+
+// UpdateUnifiedCalendarEvent changes the fields of one of the family's events
+// that the request sets. For an occurrence of a recurring series, req.Scope
+// picks what changes: "this" detaches the occurrence into an event of its
+// own, "future" ends the series before the occurrence and starts a new series
+// from it, and "all" changes the whole series, moving every occurrence by as
+// much as this one moved. Updating a series by its own ID changes all of it.
+// For a series, the series is returned. Attendees, when set, replace the
+// attendee list of the event that results.
+func (s *CalendarService) UpdateUnifiedCalendarEvent(ctx context.Context, familyID, eventID string, req *models.UpdateUnifiedCalendarEventRequest) (*models.UnifiedCalendarEvent, error) {
+	if req.Scope == "" {
+		req.Scope = models.RecurrenceScopeThis
+	}
+
+	validator := validation.NewValidator()
+	validateRecurrenceScope(validator, req.Scope)
+	if req.Title != nil {
+		validator.Required("title", strings.TrimSpace(*req.Title))
+		validator.MaxLength("title", *req.Title, 255)
+	}
+	if req.Description != nil {
+		validator.MaxLength("description", *req.Description, 1000)
+	}
+	if req.Location != nil {
+		validator.MaxLength("location", *req.Location, 255)
+	}
+	if req.Color != nil && !models.IsHexColor(*req.Color) {
+		validator.AddError("color", "color must be a hex value like #3b82f6")
+	}
+	if req.Visibility != nil {
+		validator.OneOf("visibility", *req.Visibility, models.EventVisibilities)
+	}
+	rule, err := parseRecurrenceRule(req.RecurrenceRule)
+	if err != nil {
+		validator.AddError("recurrence_rule", err.Error())
+	}
+	if err := validator.ToError(); err != nil {
+		return nil, err
+	}
+
+	target, err := s.findRecurrenceTarget(familyID, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	var attendees []string
+	if req.Attendees != nil {
+		memberIDs, err := s.getFamilyMemberIDs(familyID)
+		if err != nil {
+			return nil, err
+		}
+		attendees = []string{}
+		for _, attendeeID := range *req.Attendees {
+			if !memberIDs[attendeeID] {
+				validator.AddErrorf("attendees", "attendee %s is not a member of this family", attendeeID)
+			} else if !slices.Contains(attendees, attendeeID) {
+				attendees = append(attendees, attendeeID)
+			}
+		}
+	}
+	scope := req.Scope
+	if target.isSeries() {
+		scope = models.RecurrenceScopeAll
+	}
+
+	updated := *target.event
+	if req.Title != nil {
+		updated.Title = *req.Title
+	}
+	if req.Description != nil {
+		updated.Description = req.Description
+	}
+	if req.Location != nil {
+		updated.Location = req.Location
+	}
+	if req.AllDay != nil {
+		updated.AllDay = *req.AllDay
+	}
+	if req.Color != nil {
+		updated.Color = *req.Color
+	}
+	if req.Visibility != nil {
+		updated.Visibility = *req.Visibility
+	}
+	if req.StartTime != nil {
+		updated.StartTime = *req.StartTime
+		if req.EndTime == nil {
+			updated.EndTime = req.StartTime.Add(target.event.EndTime.Sub(target.event.StartTime))
+		}
+	}
+	if req.EndTime != nil {
+		updated.EndTime = *req.EndTime
+	}
+	if updated.EndTime.Before(updated.StartTime) {
+		validator.AddError("end_time", "end_time must not be before start_time")
+	}
+	if req.RecurrenceRule != nil && target.series != nil && scope == models.RecurrenceScopeThis {
+		validator.AddError("recurrence_rule", "recurrence_rule can only change for future occurrences or the whole series")
+	}
+	if err := validator.ToError(); err != nil {
+		return nil, err
+	}
+	// How far this edit moves the event, and how long it now lasts
+	delta := updated.StartTime.Sub(target.event.StartTime)
+	length := updated.EndTime.Sub(updated.StartTime)
+
+	now := time.Now().UTC()
+	resultID := eventID
+	err = s.db.InTx(ctx, func(tx *sql.Tx) error {
+		switch {
+		case target.series == nil:
+			if req.RecurrenceRule != nil {
+				updated.RecurrenceRule = rule
+				updated.RecurrenceExdates = ""
+			}
+			if err := updateUnifiedEventRow(tx, &updated, now); err != nil {
+				return err
+			}
+
+		case scope == models.RecurrenceScopeThis && target.stored:
+			if err := updateUnifiedEventRow(tx, &updated, now); err != nil {
+				return err
+			}
+
+		case scope == models.RecurrenceScopeThis:
+			// The occurrence becomes its own row under the same ID, and the
+			// series stops generating it
+			series := *target.series
+			series.RecurrenceExdates = withExdate(series.RecurrenceExdates, target.occurrence)
+			if err := updateUnifiedEventRow(tx, &series, now); err != nil {
+				return err
+			}
+			updated.RecurrenceRule = nil
+			updated.RecurrenceExdates = ""
+			updated.ICalUID = nil
+			if err := insertUnifiedEventRow(tx, &updated, series.ID, now); err != nil {
+				return err
+			}
+
+		case scope == models.RecurrenceScopeAll || !target.occurrence.After(target.series.StartTime):
+			series := *target.series
+			series.Title, series.Description, series.Location, series.AllDay, series.Color, series.Visibility =
+				updated.Title, updated.Description, updated.Location, updated.AllDay, updated.Color, updated.Visibility
+			series.StartTime = target.series.StartTime.Add(delta)
+			series.EndTime = series.StartTime.Add(length)
+			if err := moveSeries(&series, target.series.StartTime, delta, req.RecurrenceRule != nil, rule); err != nil {
+				return err
+			}
+			if err := updateUnifiedEventRow(tx, &series, now); err != nil {
+				return err
+			}
+			resultID = series.ID
+
+		default:
+			// End the series before the occurrence, and continue it with the
+			// changes as a new series
+			series := *target.series
+			current, _, err := seriesRule(&series)
+			if err != nil {
+				return err
+			}
+			rest := *target.series
+			rest.ID = generateUnifiedEventID()
+			rest.ICalUID = nil
+			rest.Title, rest.Description, rest.Location, rest.AllDay, rest.Color, rest.Visibility =
+				updated.Title, updated.Description, updated.Location, updated.AllDay, updated.Color, updated.Visibility
+			rest.StartTime = target.occurrence.In(series.StartTime.Location())
+			remaining := current.Remaining(series.StartTime, rest.StartTime).String()
+			rest.RecurrenceRule = &remaining
+			rest.RecurrenceExdates = exdatesFrom(series.RecurrenceExdates, target.occurrence)
+			rest.StartTime = rest.StartTime.Add(delta)
+			rest.EndTime = rest.StartTime.Add(length)
+			if err := moveSeries(&rest, target.occurrence, delta, req.RecurrenceRule != nil, rule); err != nil {
+				return err
+			}
+
+			ended := current.EndingBefore(target.occurrence).String()
+			series.RecurrenceRule = &ended
+			series.RecurrenceExdates = exdatesBefore(series.RecurrenceExdates, target.occurrence)
+			if err := updateUnifiedEventRow(tx, &series, now); err != nil {
+				return err
+			}
+			if err := insertUnifiedEventRow(tx, &rest, series.ID, now); err != nil {
+				return err
+			}
+			resultID = rest.ID
+		}
+
+		if attendees != nil {
+			if err := syncEventAttendees(tx, resultID, attendees); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update unified calendar event: %w", err)
+	}
+	s.publishChange(familyID)
+
+	return s.GetUnifiedCalendarEvent(resultID)
+}
+
+// moveSeries applies a move of delta to a series that used to start at from,
+// shifting its exception dates and the days its rule names. A replaced rule
+// drops the exception dates, since they belonged to the old rule.
+func moveSeries(series *models.UnifiedCalendarEvent, from time.Time, delta time.Duration, replaceRule bool, rule *string) error {
+	if replaceRule {
+		series.RecurrenceRule = rule
+		series.RecurrenceExdates = ""
+		return nil
+	}
+	if delta == 0 {
+		return nil
+	}
+
+	parsed, _, err := seriesRule(series)
+	if err != nil {
+		return err
+	}
+	shifted := parsed.Shifted(from.In(series.StartTime.Location()), from.Add(delta).In(series.StartTime.Location())).String()
+	series.RecurrenceRule = &shifted
+
+	exdates, err := recurrence.ParseTimes(series.RecurrenceExdates)
+	if err != nil {
+		return fmt.Errorf("event %s has invalid exception dates: %w", series.ID, err)
+	}
+	for i := range exdates {
+		exdates[i] = exdates[i].Add(delta)
+	}
+	series.RecurrenceExdates = recurrence.FormatTimes(exdates)
+	return nil
+}
+
+// DeleteUnifiedCalendarEvent deletes one of the family's unified events. For
+// an occurrence of a recurring series, scope picks whether only this
+// occurrence, this and later ones, or the whole series goes. Deleting a series
+// by its own ID deletes all of it, with occurrences edited on their own.
+func (s *CalendarService) DeleteUnifiedCalendarEvent(familyID, eventID, scope string) error {
+	if scope == "" {
+		scope = models.RecurrenceScopeThis
+	}
+	validator := validation.NewValidator()
+	validateRecurrenceScope(validator, scope)
+	if err := validator.ToError(); err != nil {
+		return err
+	}
+
+	target, err := s.findRecurrenceTarget(familyID, eventID)
+	if err != nil {
+		return err
+	}
+	if target.isSeries() {
+		scope = models.RecurrenceScopeAll
+	}
+
+	now := time.Now().UTC()
+	err = s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		switch {
+		case target.series == nil || (scope == models.RecurrenceScopeThis && target.stored):
+			// The series already skips an occurrence edited on its own
+			if _, err := tx.Exec(`DELETE FROM unified_calendar_events WHERE id = ? AND family_id = ?`, eventID, familyID); err != nil {
+				return fmt.Errorf("failed to delete event: %w", err)
+			}
+
+		case scope == models.RecurrenceScopeThis:
+			series := *target.series
+			series.RecurrenceExdates = withExdate(series.RecurrenceExdates, target.occurrence)
+			if err := updateUnifiedEventRow(tx, &series, now); err != nil {
+				return err
+			}
+
+		case scope == models.RecurrenceScopeAll || !target.occurrence.After(target.series.StartTime):
+			if _, err := tx.Exec(`DELETE FROM unified_calendar_events WHERE recurring_event_id = ? AND family_id = ?`, target.series.ID, familyID); err != nil {
+				return fmt.Errorf("failed to delete edited occurrences: %w", err)
+			}
+			if _, err := tx.Exec(`DELETE FROM unified_calendar_events WHERE id = ? AND family_id = ?`, target.series.ID, familyID); err != nil {
+				return fmt.Errorf("failed to delete event: %w", err)
+			}
+
+		default:
+			series := *target.series
+			rule, _, err := seriesRule(&series)
+			if err != nil {
+				return err
+			}
+			ended := rule.EndingBefore(target.occurrence).String()
+			series.RecurrenceRule = &ended
+			series.RecurrenceExdates = exdatesBefore(series.RecurrenceExdates, target.occurrence)
+			if err := updateUnifiedEventRow(tx, &series, now); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(`
+				DELETE FROM unified_calendar_events
+				WHERE recurring_event_id = ? AND family_id = ? AND original_start_time >= ?
+			`, series.ID, familyID, target.occurrence.UTC()); err != nil {
+				return fmt.Errorf("failed to delete edited occurrences: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete unified calendar event: %w", err)
+	}
+	s.publishChange(familyID)
+	return nil
+}
+
+// updateUnifiedEventRow writes the editable fields of a stored event
+func updateUnifiedEventRow(tx *sql.Tx, event *models.UnifiedCalendarEvent, now time.Time) error {
+	if _, err := tx.Exec(`
+		UPDATE unified_calendar_events
+		SET title = ?, description = ?, location = ?, start_time = ?, end_time = ?, all_day = ?, color = ?,
+			recurrence_rule = ?, recurrence_exdates = ?, visibility = ?, updated_at = ?
+		WHERE id = ? AND family_id = ?
+	`, event.Title, event.Description, event.Location, event.StartTime.UTC(), event.EndTime.UTC(), event.AllDay, event.Color,
+		event.RecurrenceRule, event.RecurrenceExdates, event.Visibility, now, event.ID, event.FamilyID); err != nil {
+		return fmt.Errorf("failed to update event %s: %w", event.ID, err)
+	}
+	return nil
+}
+
+// insertUnifiedEventRow stores a new event split off a series, with the
+// series' attendees
+func insertUnifiedEventRow(tx *sql.Tx, event *models.UnifiedCalendarEvent, attendeesFrom string, now time.Time) error {
+	var originalStart any
+	if event.OriginalStartTime != nil {
+		originalStart = event.OriginalStartTime.UTC()
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO unified_calendar_events (id, family_id, title, description, start_time, end_time,
+											location, all_day, event_type, color, category, created_by, priority,
+											status, ical_uid, recurrence_rule, recurrence_exdates, recurring_event_id,
+											original_start_time, visibility, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, event.ID, event.FamilyID, event.Title, event.Description, event.StartTime.UTC(), event.EndTime.UTC(),
+		event.Location, event.AllDay, event.EventType, event.Color, event.Category, event.CreatedBy, event.Priority,
+		event.Status, event.ICalUID, event.RecurrenceRule, event.RecurrenceExdates, event.RecurringEventID,
+		originalStart, event.Visibility, now, now); err != nil {
+		return fmt.Errorf("failed to insert event %s: %w", event.ID, err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO unified_calendar_event_attendees (event_id, user_id, response_status)
+		SELECT ?, user_id, response_status FROM unified_calendar_event_attendees WHERE event_id = ?
+	`, event.ID, attendeesFrom); err != nil {
+		return fmt.Errorf("failed to copy attendees to event %s: %w", event.ID, err)
+	}
+	return nil
+}
+
+// syncEventAttendees makes the event's attendees exactly memberIDs. Members
+// who stay keep their response status.
+func syncEventAttendees(tx *sql.Tx, eventID string, memberIDs []string) error {
+	rows, err := tx.Query(`SELECT user_id FROM unified_calendar_event_attendees WHERE event_id = ?`, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to query attendees of event %s: %w", eventID, err)
+	}
+	current := make(map[string]bool)
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan attendee: %w", err)
+		}
+		current[userID] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating attendees: %w", err)
+	}
+
+	for _, memberID := range memberIDs {
+		if current[memberID] {
+			delete(current, memberID)
+			continue
+		}
+		if _, err := tx.Exec(`INSERT INTO unified_calendar_event_attendees (event_id, user_id) VALUES (?, ?)`, eventID, memberID); err != nil {
+			return fmt.Errorf("failed to add attendee %s: %w", memberID, err)
+		}
+	}
+	for memberID := range current {
+		if _, err := tx.Exec(`DELETE FROM unified_calendar_event_attendees WHERE event_id = ? AND user_id = ?`, eventID, memberID); err != nil {
+			return fmt.Errorf("failed to remove attendee %s: %w", memberID, err)
+		}
+	}
+	return nil
+}
+
+// withExdate adds an occurrence start to a series' exception dates
+func withExdate(exdates string, occurrence time.Time) string {
+	if exdates == "" {
+		return recurrence.FormatTimes([]time.Time{occurrence})
+	}
+	return exdates + "," + recurrence.FormatTimes([]time.Time{occurrence})
+}
+
+// exdatesBefore keeps the exception dates before t
+func exdatesBefore(exdates string, t time.Time) string {
+	return filterExdates(exdates, func(exdate time.Time) bool { return exdate.Before(t) })
+}
+
+// exdatesFrom keeps the exception dates at or after t
+func exdatesFrom(exdates string, t time.Time) string {
+	return filterExdates(exdates, func(exdate time.Time) bool { return !exdate.Before(t) })
+}
+
+func filterExdates(exdates string, keep func(time.Time) bool) string {
+	times, _ := recurrence.ParseTimes(exdates) // nolint:errcheck // validated when stored
+	kept := times[:0]
+	for _, t := range times {
+		if keep(t) {
+			kept = append(kept, t)
+		}
+	}
+	return recurrence.FormatTimes(kept)
+}
@@ -1,41 +1,72 @@
 package services
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"famstack/internal/database"
+	"famstack/internal/eventbus"
+	"famstack/internal/ids"
 	"famstack/internal/models"
+	"famstack/internal/recurrence"
+	"famstack/internal/validation"
 )
 
 // CalendarService handles all calendar and event database operations
 type CalendarService struct {
-	db *database.Fascade
+	db         *database.Fascade
+	colorRules *EventColorRulesService
+	events     *eventbus.Bus
+	layouts    *dayLayoutCache
 }
 
 // CalendarEventForSync represents a calendar event for sync operations
 type CalendarEventForSync struct {
-	ID          string     `json:"id"`
-	FamilyID    string     `json:"family_id"`
-	CreatedBy   string     `json:"created_by"`
-	Title       string     `json:"title"`
-	Description string     `json:"description"`
-	Location    string     `json:"location"`
-	StartTime   time.Time  `json:"start_time"`
-	EndTime     *time.Time `json:"end_time"`
-	AllDay      bool       `json:"all_day"`
-	Attendees   []string   `json:"attendees"`
-	SourceType  string     `json:"source_type"`
-	SourceID    string     `json:"source_id"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID          string           `json:"id"` // set to the stored row's ID by the upsert
+	FamilyID    string           `json:"family_id"`
+	CreatedBy   string           `json:"created_by"`
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	Location    string           `json:"location"`
+	StartTime   time.Time        `json:"start_time"`
+	EndTime     *time.Time       `json:"end_time"`
+	AllDay      bool             `json:"all_day"`
+	Attendees   []SyncedAttendee `json:"attendees"`
+	Organizer   string           `json:"organizer"`
+	SourceType  string           `json:"source_type"` // the provider, stored as external_source
+	SourceID    string           `json:"source_id"`   // the provider's ID, stored as external_id
+	Color       string           `json:"color"`       // set by the family's color rules on upsert
+	Category    string           `json:"category"`    // set by the family's color rules on upsert
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
 }
 
 // NewCalendarService creates a new calendar service
 func NewCalendarService(db *database.Fascade) *CalendarService {
-	return &CalendarService{db: db}
+	return &CalendarService{db: db, colorRules: NewEventColorRulesService(db), layouts: newDayLayoutCache()}
+}
+
+// SetEventBus sets the bus that writes announce calendar changes on. Day
+// layouts are dropped on any calendar change it carries, including imports.
+func (s *CalendarService) SetEventBus(bus *eventbus.Bus) {
+	s.events = bus
+	s.layouts.subscribe(bus)
+}
+
+// FamilyTimezone returns the timezone the family's calendar is kept in
+func (s *CalendarService) FamilyTimezone(familyID string) (string, error) {
+	return GetFamilyTimezone(s.db, familyID)
+}
+
+// publishChange tells subscribers the family's calendar changed
+func (s *CalendarService) publishChange(familyID string) {
+	s.events.Publish(eventbus.Event{Topic: eventbus.TopicCalendarChanged, FamilyID: familyID})
 }
 
 // GetEvent returns a calendar event by ID
@@ -47,33 +78,14 @@ func (s *CalendarService) GetEvent(eventID string) (*models.CalendarEvent, error
 		WHERE id = ?
 	`
 
-	var event models.CalendarEvent
-	var description, location, assignedTo sql.NullString
-
-	err := s.db.QueryRow(query, eventID).Scan(
-		&event.ID, &event.FamilyID, &event.Title, &description,
-		&event.StartTime, &event.EndTime, &location, &event.EventType,
-		&assignedTo, &event.CreatedBy, &event.CreatedAt, &event.UpdatedAt,
-	)
-
+	event, err := database.QueryOne[models.CalendarEvent](s.db, query, eventID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("calendar event not found")
+			return nil, notFound("calendar event")
 		}
 		return nil, fmt.Errorf("failed to get calendar event: %w", err)
 	}
 
-	// Handle nullable fields
-	if description.Valid {
-		event.Description = &description.String
-	}
-	if location.Valid {
-		event.Location = &location.String
-	}
-	if assignedTo.Valid {
-		event.AssignedTo = &assignedTo.String
-	}
-
 	familyTimezone, err := GetFamilyTimezone(s.db, event.FamilyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get family timezone for event conversion: %w", err)
@@ -99,7 +111,20 @@ func (s *CalendarService) GetEvent(eventID string) (*models.CalendarEvent, error
 		return nil, fmt.Errorf("failed to convert updated_at from UTC: %w", err)
 	}
 
-	return &event, nil
+	return event, nil
+}
+
+// GetFamilyEvent returns one of the family's calendar events; another
+// family's event is not found
+func (s *CalendarService) GetFamilyEvent(familyID, eventID string) (*models.CalendarEvent, error) {
+	event, err := s.GetEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := inFamily("calendar event", event.FamilyID, familyID); err != nil {
+		return nil, err
+	}
+	return event, nil
 }
 
 // ListEvents returns calendar events for a family within a date range
@@ -251,8 +276,16 @@ func (s *CalendarService) ListEventsByMember(memberID string, startDate, endDate
 	return events, nil
 }
 
+// manualEventSource is the external_source of calendar events created
+// through the API rather than synced from a provider
+const manualEventSource = "manual"
+
 // CreateEvent creates a new calendar event
 func (s *CalendarService) CreateEvent(familyID, createdBy string, req *models.CreateCalendarEventRequest) (*models.CalendarEvent, error) {
+	if err := req.Normalize(); err != nil {
+		return nil, err
+	}
+
 	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get family timezone for event creation: %w", err)
@@ -272,28 +305,35 @@ func (s *CalendarService) CreateEvent(familyID, createdBy string, req *models.Cr
 	now := time.Now().UTC()
 
 	query := `
-		INSERT INTO calendar_events (id, family_id, title, description, start_time, end_time,
+		INSERT INTO calendar_events (id, external_source, family_id, title, description, start_time, end_time,
 									location, event_type, assigned_to, created_by, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err = s.db.Exec(query,
-		eventID, familyID, req.Title, req.Description, startTimeUTC, endTimeUTC,
+		eventID, manualEventSource, familyID, req.Title, req.Description, startTimeUTC, endTimeUTC,
 		req.Location, req.EventType, req.AssignedTo, createdBy, now, now,
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create calendar event: %w", err)
 	}
+	s.publishChange(familyID)
+	s.events.Publish(eventbus.Event{Topic: eventbus.TopicEventCreated, FamilyID: familyID, SubjectID: eventID})
 
 	return s.GetEvent(eventID)
 }
 
-// UpdateEvent updates an existing calendar event
-func (s *CalendarService) UpdateEvent(eventID string, req *models.UpdateCalendarEventRequest) (*models.CalendarEvent, error) {
-	familyID, err := s.getFamilyIDForEvent(eventID)
+// UpdateEvent updates one of the family's calendar events
+func (s *CalendarService) UpdateEvent(familyID, eventID string, req *models.UpdateCalendarEventRequest) (*models.CalendarEvent, error) {
+	if err := req.Normalize(); err != nil {
+		return nil, err
+	}
+
+	// Another family's event is not found
+	current, err := s.GetFamilyEvent(familyID, eventID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get family for event %s: %w", eventID, err)
+		return nil, err
 	}
 
 	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
@@ -343,16 +383,16 @@ func (s *CalendarService) UpdateEvent(eventID string, req *models.UpdateCalendar
 	}
 
 	if len(setParts) == 1 { // Only updated_at
-		return s.GetEvent(eventID) // No changes, return current
+		return current, nil // No changes, return current
 	}
 
-	// Add eventID to args for WHERE clause
-	args = append(args, eventID)
+	// Add eventID and familyID to args for WHERE clause
+	args = append(args, eventID, familyID)
 
 	query := fmt.Sprintf(`
 		UPDATE calendar_events
 		SET %s
-		WHERE id = ?
+		WHERE id = ? AND family_id = ?
 	`, strings.Join(setParts, ", "))
 
 	result, err := s.db.Exec(query, args...)
@@ -366,17 +406,18 @@ func (s *CalendarService) UpdateEvent(eventID string, req *models.UpdateCalendar
 	}
 
 	if rowsAffected == 0 {
-		return nil, fmt.Errorf("calendar event not found")
+		return nil, notFound("calendar event")
 	}
+	s.publishChange(familyID)
 
 	return s.GetEvent(eventID)
 }
 
-// DeleteEvent deletes a calendar event
-func (s *CalendarService) DeleteEvent(eventID string) error {
-	query := `DELETE FROM calendar_events WHERE id = ?`
+// DeleteEvent deletes one of the family's calendar events
+func (s *CalendarService) DeleteEvent(familyID, eventID string) error {
+	query := `DELETE FROM calendar_events WHERE id = ? AND family_id = ?`
 
-	result, err := s.db.Exec(query, eventID)
+	result, err := s.db.Exec(query, eventID, familyID)
 	if err != nil {
 		return fmt.Errorf("failed to delete calendar event: %w", err)
 	}
@@ -387,37 +428,86 @@ func (s *CalendarService) DeleteEvent(eventID string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("calendar event not found")
+		return notFound("calendar event")
 	}
 
 	return nil
 }
 
-// GetUnifiedCalendarEvents returns unified calendar events (from external integrations)
+// GetUnifiedCalendarEvents returns unified calendar events (from external integrations).
+// Recurring series are expanded, so each occurrence in the range is returned
+// as its own event.
 func (s *CalendarService) GetUnifiedCalendarEvents(familyID string, startDate, endDate time.Time) ([]models.UnifiedCalendarEvent, error) {
+	startUTC, endUTC, err := s.familyRangeToUTC(familyID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.GetStoredUnifiedCalendarEvents(familyID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	return expandRecurringEvents(events, startUTC, endUTC)
+}
+
+// PageCalendarEvents returns one page of listed events, soonest first.
+// Recurring series are expanded in memory, so events are paged after
+// listing rather than in the query.
+func PageCalendarEvents(events []models.UnifiedCalendarEvent, request PageRequest) (*Page[models.UnifiedCalendarEvent], error) {
+	return pageAscending(events, request, func(event models.UnifiedCalendarEvent) (time.Time, string) {
+		return event.StartTime, event.ID
+	})
+}
+
+// familyRangeToUTC converts a listing range to UTC, reading times without a
+// zone in the family's timezone
+func (s *CalendarService) familyRangeToUTC(familyID string, startDate, endDate time.Time) (time.Time, time.Time, error) {
 	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get family timezone for event listing: %w", err)
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to get family timezone for event listing: %w", err)
 	}
 
 	startUTC, err := ConvertToUTC(startDate, familyTimezone)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert start date to UTC: %w", err)
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to convert start date to UTC: %w", err)
 	}
 	endUTC, err := ConvertToUTC(endDate, familyTimezone)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert end date to UTC: %w", err)
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to convert end date to UTC: %w", err)
+	}
+	return startUTC, endUTC, nil
+}
+
+// GetStoredUnifiedCalendarEvents returns the event rows overlapping the range
+// without expanding recurring series: each series is returned once, with its
+// rule, along with any occurrences that were edited on their own. Series are
+// included if they start before the range ends.
+func (s *CalendarService) GetStoredUnifiedCalendarEvents(familyID string, startDate, endDate time.Time) ([]models.UnifiedCalendarEvent, error) {
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for event listing: %w", err)
 	}
 
-	query := `
+	startUTC, endUTC, err := s.familyRangeToUTC(familyID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := s.db.Prepared(`
 		SELECT id, family_id, title, description, start_time, end_time, location,
-			   all_day, event_type, color, created_by, priority, status, created_at, updated_at
+			   all_day, event_type, color, category, created_by, priority, status, ical_uid,
+			   created_at, updated_at, recurrence_rule, recurrence_exdates, recurring_event_id,
+			   original_start_time, source_integration_id, celebration_kind, visibility
 		FROM unified_calendar_events
-		WHERE family_id = ? AND start_time < ? AND end_time > ?
+		WHERE family_id = ? AND start_time < ?
+		  AND (end_time > ? OR recurrence_rule IS NOT NULL)
 		ORDER BY start_time ASC
-	`
+	`)
+	if err != nil {
+		return []models.UnifiedCalendarEvent{}, err
+	}
 
-	rows, err := s.db.Query(query, familyID, endUTC, startUTC) // Note: endDate and startDate are intentionally swapped for the query logic
+	rows, err := stmt.Query(familyID, endUTC, startUTC) // Note: endDate and startDate are intentionally swapped for the query logic
 	if err != nil {
 		return []models.UnifiedCalendarEvent{}, fmt.Errorf("failed to list unified calendar events: %w", err)
 	}
@@ -461,38 +551,398 @@ func (s *CalendarService) GetUnifiedCalendarEvents(familyID string, startDate, e
 		}
 	}
 
-	// Step 2: Collect all event IDs
+	if err := s.attachAttendees(events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// CreateUnifiedCalendarEvent creates a unified calendar event (from external
+// integration). The event and its attendees are written together, so a
+// failure leaves neither behind.
+func (s *CalendarService) CreateUnifiedCalendarEvent(ctx context.Context, req *models.CreateUnifiedCalendarEventRequest) (*models.UnifiedCalendarEvent, error) {
+	attendeeIDs, err := s.validateCreateAttendees(req)
+	if err != nil {
+		return nil, err
+	}
+
+	familyTimezone, err := GetFamilyTimezone(s.db, req.FamilyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for unified event creation: %w", err)
+	}
+
+	startTimeUTC, err := ConvertToUTC(req.StartTime, familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert start time to UTC: %w", err)
+	}
+
+	endTimeUTC, err := ConvertToUTC(req.EndTime, familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert end time to UTC: %w", err)
+	}
+
+	provider, err := s.integrationProvider(req.FamilyID, req.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := s.colorRules.MatchRule(req.FamilyID, models.EventRuleSample{
+		Title:         req.Title,
+		Description:   stringValue(req.Description),
+		Location:      stringValue(req.Location),
+		Organizer:     stringValue(req.Organizer),
+		Source:        provider,
+		IntegrationID: req.IntegrationID,
+		EventType:     string(models.EventTypeEvent),
+		Attendees:     attendeeIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate event color rules: %w", err)
+	}
+	color, category := ruleColorAndCategory(rule, "")
+
+	eventID := generateUnifiedEventID()
+	now := time.Now().UTC()
+
+	err = s.db.InTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`
+			INSERT INTO unified_calendar_events (id, family_id, title, description, start_time, end_time,
+												location, event_type, color, category, status,
+												source_integration_id, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, eventID, req.FamilyID, req.Title, req.Description, startTimeUTC, endTimeUTC,
+			req.Location, models.EventTypeEvent, color, category, models.UnifiedEventStatusActive,
+			req.IntegrationID, now, now); err != nil {
+			return fmt.Errorf("failed to insert event: %w", err)
+		}
+		return syncEventAttendees(tx, eventID, attendeeIDs)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create unified calendar event: %w", err)
+	}
+	s.publishChange(req.FamilyID)
+
+	return s.GetUnifiedCalendarEvent(eventID)
+}
+
+// validateCreateAttendees returns the member IDs a new event's attendees
+// name, failing validation unless each is a member of the event's family
+func (s *CalendarService) validateCreateAttendees(req *models.CreateUnifiedCalendarEventRequest) ([]string, error) {
+	ids := req.AttendeeIDs()
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	memberIDs, err := s.getFamilyMemberIDs(req.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	validator := validation.NewValidator()
+	attendees := []string{}
+	for _, attendeeID := range ids {
+		if !memberIDs[attendeeID] {
+			validator.AddErrorf("attendees", "attendee %s is not a member of this family", attendeeID)
+		} else if !slices.Contains(attendees, attendeeID) {
+			attendees = append(attendees, attendeeID)
+		}
+	}
+	if err := validator.ToError(); err != nil {
+		return nil, err
+	}
+	return attendees, nil
+}
+
+// GetUnifiedCalendarEvent returns a unified calendar event by ID. The ID may
+// name one occurrence of a recurring series, as listings return them.
+func (s *CalendarService) GetUnifiedCalendarEvent(eventID string) (*models.UnifiedCalendarEvent, error) {
+	event, err := s.GetStoredUnifiedCalendarEvent(eventID)
+	if err == nil || !errors.Is(err, ErrNotFound) {
+		return event, err
+	}
+
+	seriesID, occurrence, ok := recurrence.ParseInstanceID(eventID)
+	if !ok {
+		return nil, err
+	}
+	series, err := s.GetStoredUnifiedCalendarEvent(seriesID)
+	if err != nil {
+		return nil, err
+	}
+	return occurrenceOf(series, occurrence)
+}
+
+// GetStoredUnifiedCalendarEvent returns the event row with the given ID,
+// without resolving occurrence IDs
+func (s *CalendarService) GetStoredUnifiedCalendarEvent(eventID string) (*models.UnifiedCalendarEvent, error) {
+	query := `
+		SELECT id, family_id, title, description, start_time, end_time, location,
+			   all_day, event_type, color, category, created_by, priority, status, ical_uid,
+			   created_at, updated_at, recurrence_rule, recurrence_exdates, recurring_event_id,
+			   original_start_time, source_integration_id, celebration_kind, visibility
+		FROM unified_calendar_events
+		WHERE id = ?
+	`
+
+	event, err := database.QueryOne[models.UnifiedCalendarEvent](s.db, query, eventID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFound("unified calendar event")
+		}
+		return nil, fmt.Errorf("failed to get unified calendar event: %w", err)
+	}
+
+	familyTimezone, err := GetFamilyTimezone(s.db, event.FamilyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for event conversion: %w", err)
+	}
+
+	event.StartTime, err = ConvertFromUTC(event.StartTime, familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert start time from UTC: %w", err)
+	}
+
+	event.EndTime, err = ConvertFromUTC(event.EndTime, familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert end time from UTC: %w", err)
+	}
+
+	event.CreatedAt, err = ConvertFromUTC(event.CreatedAt, familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert created_at from UTC: %w", err)
+	}
+
+	event.UpdatedAt, err = ConvertFromUTC(event.UpdatedAt, familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert updated_at from UTC: %w", err)
+	}
+
+	events := []models.UnifiedCalendarEvent{*event}
+	if err := s.attachAttendees(events); err != nil {
+		return nil, err
+	}
+
+	return &events[0], nil
+}
+
+// unifiedEventIDPattern limits caller-chosen event IDs to characters that are
+// safe in a URL path segment
+var unifiedEventIDPattern = regexp.MustCompile(`^[A-Za-z0-9_.@-]{1,128}$`)
+
+// PutUnifiedCalendarEvent creates the event with the given ID, or replaces the
+// fields of the family's existing event with that ID. It reports whether the
+// event was created. The listed attendees are added to any the event already
+// has. Color rules apply to new events the same way they do to imports.
+func (s *CalendarService) PutUnifiedCalendarEvent(ctx context.Context, familyID, createdBy string, req *models.PutUnifiedCalendarEventRequest) (*models.UnifiedCalendarEvent, bool, error) {
+	validator := validation.NewValidator()
+	if !unifiedEventIDPattern.MatchString(req.ID) {
+		validator.AddError("id", "id must be 1-128 letters, digits or . _ @ -")
+	}
+	if strings.TrimSpace(req.Title) == "" || len(req.Title) > 255 {
+		validator.AddError("title", "title is required and must be at most 255 characters")
+	}
+	if req.EndTime.Before(req.StartTime) {
+		validator.AddError("end_time", "end_time must not be before start_time")
+	}
+	if req.Status == "" {
+		req.Status = models.UnifiedEventStatusActive
+	}
+	switch req.Status {
+	case models.UnifiedEventStatusActive, models.UnifiedEventStatusCancelled, models.UnifiedEventStatusCompleted:
+	default:
+		validator.AddError("status", "status must be active, cancelled or completed")
+	}
+	rule, exdates := recurrenceColumns(validator, req.RecurrenceRule, req.ExceptionDates)
+	if err := validator.ToError(); err != nil {
+		return nil, false, err
+	}
+
+	var existingFamilyID string
+	err := s.db.QueryRow(`SELECT family_id FROM unified_calendar_events WHERE id = ?`, req.ID).Scan(&existingFamilyID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("failed to look up unified calendar event: %w", err)
+	}
+	if err == nil && existingFamilyID != familyID {
+		return nil, false, fmt.Errorf("event ID is already in use")
+	}
+	created := err == sql.ErrNoRows
+
+	memberIDs, err := s.getFamilyMemberIDs(familyID)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, attendeeID := range req.Attendees {
+		if !memberIDs[attendeeID] {
+			return nil, false, fmt.Errorf("attendee %s is not a member of this family", attendeeID)
+		}
+	}
+
+	now := time.Now().UTC()
+	if !created {
+		err = s.db.InTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`
+				UPDATE unified_calendar_events
+				SET title = ?, description = ?, location = ?, start_time = ?, end_time = ?,
+					all_day = ?, status = ?, ical_uid = ?, recurrence_rule = ?, recurrence_exdates = ?,
+					updated_at = ?
+				WHERE id = ? AND family_id = ?
+			`, req.Title, req.Description, req.Location, req.StartTime.UTC(), req.EndTime.UTC(),
+				req.AllDay, req.Status, optionalString(req.ICalUID), rule, exdates, now, req.ID, familyID); err != nil {
+				return fmt.Errorf("failed to update event: %w", err)
+			}
+
+			for _, attendeeID := range req.Attendees {
+				if _, err := tx.Exec(`INSERT INTO unified_calendar_event_attendees (event_id, user_id) VALUES (?, ?) ON CONFLICT DO NOTHING`, req.ID, attendeeID); err != nil {
+					return fmt.Errorf("failed to add attendee %s: %w", attendeeID, err)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to update unified calendar event: %w", err)
+		}
+		s.publishChange(familyID)
+
+		event, err := s.GetUnifiedCalendarEvent(req.ID)
+		return event, false, err
+	}
+
+	colorRule, err := s.colorRules.MatchRule(familyID, models.EventRuleSample{
+		Title:       req.Title,
+		Description: stringValue(req.Description),
+		Location:    stringValue(req.Location),
+		Source:      models.EventSourceFamstack,
+		EventType:   string(models.EventTypeEvent),
+		Attendees:   req.Attendees,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to evaluate event color rules: %w", err)
+	}
+	color, category := ruleColorAndCategory(colorRule, "")
+
+	err = s.db.InTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`
+			INSERT INTO unified_calendar_events (id, family_id, title, description, start_time, end_time,
+												location, all_day, event_type, color, category, created_by,
+												status, ical_uid, recurrence_rule, recurrence_exdates,
+												created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, req.ID, familyID, req.Title, req.Description, req.StartTime.UTC(), req.EndTime.UTC(),
+			req.Location, req.AllDay, models.EventTypeEvent, color, category, optionalString(createdBy),
+			req.Status, optionalString(req.ICalUID), rule, exdates, now, now); err != nil {
+			return fmt.Errorf("failed to insert event: %w", err)
+		}
+
+		for _, attendeeID := range req.Attendees {
+			if _, err := tx.Exec(`INSERT INTO unified_calendar_event_attendees (event_id, user_id) VALUES (?, ?)`, req.ID, attendeeID); err != nil {
+				return fmt.Errorf("failed to add attendee %s: %w", attendeeID, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create unified calendar event: %w", err)
+	}
+	s.publishChange(familyID)
+
+	event, err := s.GetUnifiedCalendarEvent(req.ID)
+	return event, true, err
+}
+
+// RemoveUnifiedEventAttendee takes a member off one of the family's events
+// without deleting the event
+func (s *CalendarService) RemoveUnifiedEventAttendee(ctx context.Context, familyID, eventID, memberID string) error {
+	err := s.db.InTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			DELETE FROM unified_calendar_event_attendees
+			WHERE event_id = ? AND user_id = ?
+			  AND event_id IN (SELECT id FROM unified_calendar_events WHERE family_id = ?)
+		`, eventID, memberID, familyID)
+		if err != nil {
+			return fmt.Errorf("failed to remove event attendee: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check affected rows: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("event attendee not found")
+		}
+
+		// The event changed for everyone still syncing it
+		if _, err := tx.Exec(`UPDATE unified_calendar_events SET updated_at = ? WHERE id = ?`, time.Now().UTC(), eventID); err != nil {
+			return fmt.Errorf("failed to touch unified calendar event: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.publishChange(familyID)
+	return nil
+}
+
+// attachAttendees loads the attendees of each event, with the family member
+// display data the calendar shows
+func (s *CalendarService) attachAttendees(events []models.UnifiedCalendarEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	// Collect all event IDs
 	eventIDs := make([]string, len(events))
 	for i, event := range events {
 		eventIDs[i] = event.ID
 	}
 
-	// Step 3: Fetch all attendees with full family member data for these events
-	attendeeQuery := `
+	// Fetch all attendees with full family member data for these events, in
+	// batches that stay under SQLite's parameter limit
+	attendeeMap := make(map[string][]models.EventAttendee)
+	for _, batch := range database.InBatches(eventIDs) {
+		if err := s.loadAttendees(batch, attendeeMap); err != nil {
+			return err
+		}
+	}
+
+	// Attach attendees to the events
+	for i, event := range events {
+		if attendees, ok := attendeeMap[event.ID]; ok {
+			events[i].Attendees = attendees
+		} else {
+			events[i].Attendees = []models.EventAttendee{} // Ensure it's an empty slice, not nil
+		}
+	}
+
+	return nil
+}
+
+// loadAttendees adds the attendees of one batch of events to attendeeMap,
+// keyed by event ID
+func (s *CalendarService) loadAttendees(eventIDs []any, attendeeMap map[string][]models.EventAttendee) error {
+	stmt, err := s.db.Prepared(`
 		SELECT a.event_id, a.user_id, a.response_status,
 		       fm.first_name, fm.last_name, fm.initial, fm.color
 		FROM unified_calendar_event_attendees a
 		JOIN family_members fm ON a.user_id = fm.id
-		WHERE a.event_id IN (?` + strings.Repeat(",?", len(eventIDs)-1) + `)
+		WHERE a.event_id IN (` + database.InList(len(eventIDs)) + `)
 		ORDER BY a.event_id, fm.display_order, fm.first_name
-	`
-	args := make([]interface{}, len(eventIDs))
-	for i, id := range eventIDs {
-		args[i] = id
+	`)
+	if err != nil {
+		return err
 	}
 
-	attendeeRows, err := s.db.Query(attendeeQuery, args...)
+	attendeeRows, err := stmt.Query(eventIDs...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query for attendees: %w", err)
+		return fmt.Errorf("failed to query for attendees: %w", err)
 	}
 	defer attendeeRows.Close()
 
-	// Step 4: Map attendees to their event ID
-	attendeeMap := make(map[string][]models.EventAttendee)
 	for attendeeRows.Next() {
 		var eventID, userID, responseStatus, firstName, lastName, initial, color string
 		if err = attendeeRows.Scan(&eventID, &userID, &responseStatus, &firstName, &lastName, &initial, &color); err != nil {
-			return nil, fmt.Errorf("failed to scan attendee: %w", err)
+			return fmt.Errorf("failed to scan attendee: %w", err)
 		}
 
 		attendee := models.EventAttendee{
@@ -506,149 +956,378 @@ func (s *CalendarService) GetUnifiedCalendarEvents(familyID string, startDate, e
 		attendeeMap[eventID] = append(attendeeMap[eventID], attendee)
 	}
 	if err = attendeeRows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating attendee rows: %w", err)
+		return fmt.Errorf("error iterating attendee rows: %w", err)
 	}
+	return nil
+}
 
-	// Step 5: Attach attendees to the events
-	for i, event := range events {
-		if attendees, ok := attendeeMap[event.ID]; ok {
-			events[i].Attendees = attendees
-		} else {
-			events[i].Attendees = []models.EventAttendee{} // Ensure it's an empty slice, not nil
-		}
+// upsertCalendarEventQuery writes a synced event, keyed on the family, the
+// provider and the provider's ID for it, and returns the row's ID. A new row
+// gets the generated ID; an existing one keeps its own.
+const upsertCalendarEventQuery = `
+	INSERT INTO calendar_events
+	(id, external_source, external_id, family_id, created_by, title, description, location,
+	 start_time, end_time, all_day, attendees, color, category, created_at, updated_at, synced_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(family_id, external_source, external_id) DO UPDATE SET
+		title = excluded.title, description = excluded.description, location = excluded.location,
+		start_time = excluded.start_time, end_time = excluded.end_time, all_day = excluded.all_day,
+		attendees = excluded.attendees, color = excluded.color, category = excluded.category,
+		updated_at = excluded.updated_at, synced_at = excluded.synced_at
+	RETURNING id
+`
+
+// upsertCalendarEventArgs returns the query arguments for upsertCalendarEventQuery
+func upsertCalendarEventArgs(event *CalendarEventForSync) ([]any, error) {
+	attendeesJSON, err := marshalSyncedAttendees(event.Attendees)
+	if err != nil {
+		return nil, err
+	}
+	// end_time is required; an event without one ends when it starts
+	endTime := event.StartTime
+	if event.EndTime != nil {
+		endTime = *event.EndTime
 	}
 
-	return events, nil
+	return []any{
+		generateEventID(), event.SourceType, event.SourceID, event.FamilyID, optionalString(event.CreatedBy),
+		event.Title, event.Description, event.Location, event.StartTime, endTime, event.AllDay,
+		attendeesJSON, optionalString(event.Color), event.Category,
+		event.CreatedAt, event.UpdatedAt, time.Now().UTC(),
+	}, nil
 }
 
-// CreateUnifiedCalendarEvent creates a unified calendar event (from external integration)
-func (s *CalendarService) CreateUnifiedCalendarEvent(req *models.CreateUnifiedCalendarEventRequest) (*models.UnifiedCalendarEvent, error) {
-	familyTimezone, err := GetFamilyTimezone(s.db, req.FamilyID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get family timezone for unified event creation: %w", err)
+// UpsertCalendarEvent inserts or updates a calendar event from external sync
+func (s *CalendarService) UpsertCalendarEvent(event *CalendarEventForSync) error {
+	events := []*CalendarEventForSync{event}
+	if err := s.matchAttendeeMembers(events); err != nil {
+		return err
 	}
-
-	startTimeUTC, err := ConvertToUTC(req.StartTime, familyTimezone)
-	if err != nil {
-		return nil, fmt.Errorf("failed to convert start time to UTC: %w", err)
+	if err := s.applyColorRules(events); err != nil {
+		return err
 	}
 
-	endTimeUTC, err := ConvertToUTC(req.EndTime, familyTimezone)
+	args, err := upsertCalendarEventArgs(event)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert end time to UTC: %w", err)
+		return err
+	}
+	if err := s.db.QueryRow(upsertCalendarEventQuery, args...).Scan(&event.ID); err != nil {
+		return fmt.Errorf("failed to upsert calendar event: %w", err)
 	}
+	s.publishChange(event.FamilyID)
+	return nil
+}
 
-	eventID := generateUnifiedEventID()
-	now := time.Now().UTC()
+// UpsertCalendarEvents writes a batch of synced events in a single transaction.
+// A row the database rejects is rolled back to its savepoint and reported as
+// failed without aborting the rest of the batch, which PostgreSQL would do
+// otherwise; only a transaction-level error is returned as err.
+func (s *CalendarService) UpsertCalendarEvents(events []*CalendarEventForSync) ([]models.BulkEventResult, error) {
+	if err := s.matchAttendeeMembers(events); err != nil {
+		return nil, err
+	}
+	if err := s.applyColorRules(events); err != nil {
+		return nil, err
+	}
 
-	query := `
-		INSERT INTO unified_calendar_events (id, family_id, integration_id, external_event_id,
-											title, description, start_time, end_time, location,
-											organizer, attendees, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`
+	results := make([]models.BulkEventResult, len(events))
 
-	_, err = s.db.Exec(query,
-		eventID, req.FamilyID, req.IntegrationID, req.ExternalEventID,
-		req.Title, req.Description, startTimeUTC, endTimeUTC, req.Location,
-		req.Organizer, req.Attendees, now, now,
-	)
+	err := s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(upsertCalendarEventQuery)
+		if err != nil {
+			return fmt.Errorf("failed to prepare statement: %w", err)
+		}
+		defer stmt.Close()
+
+		for i, event := range events {
+			if _, err := tx.Exec(`SAVEPOINT upsert_event`); err != nil {
+				return fmt.Errorf("failed to set savepoint: %w", err)
+			}
+			args, err := upsertCalendarEventArgs(event)
+			if err == nil {
+				err = stmt.QueryRow(args...).Scan(&event.ID)
+			}
+			if err != nil {
+				if _, rollbackErr := tx.Exec(`ROLLBACK TO SAVEPOINT upsert_event`); rollbackErr != nil {
+					return fmt.Errorf("failed to roll back to savepoint: %w", rollbackErr)
+				}
+				results[i] = models.BulkEventResult{Index: i, Status: models.BulkEventStatusFailed, Error: err.Error()}
+				continue
+			}
+			if _, err := tx.Exec(`RELEASE SAVEPOINT upsert_event`); err != nil {
+				return fmt.Errorf("failed to release savepoint: %w", err)
+			}
+			results[i] = models.BulkEventResult{Index: i, EventID: event.ID, Status: models.BulkEventStatusCreated}
+		}
 
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create unified calendar event: %w", err)
+		return nil, fmt.Errorf("failed to upsert calendar events: %w", err)
 	}
 
-	return s.GetUnifiedCalendarEvent(eventID)
-}
-
-// GetUnifiedCalendarEvent returns a unified calendar event by ID
-func (s *CalendarService) GetUnifiedCalendarEvent(eventID string) (*models.UnifiedCalendarEvent, error) {
-	query := `
-		SELECT id, family_id, title, description, start_time, end_time, location,
-			   all_day, event_type, color, created_by, priority, status, created_at, updated_at
-		FROM unified_calendar_events
-		WHERE id = ?
-	`
-
-	var event models.UnifiedCalendarEvent
-	var description, location, createdBy sql.NullString
+	// One announcement per family, however big the batch
+	published := make(map[string]bool)
+	for _, event := range events {
+		if !published[event.FamilyID] {
+			published[event.FamilyID] = true
+			s.publishChange(event.FamilyID)
+		}
+	}
 
-	err := s.db.QueryRow(query, eventID).Scan(
-		&event.ID, &event.FamilyID, &event.Title, &description,
-		&event.StartTime, &event.EndTime, &location, &event.AllDay,
-		&event.EventType, &event.Color, &createdBy, &event.Priority,
-		&event.Status, &event.CreatedAt, &event.UpdatedAt,
-	)
+	return results, nil
+}
 
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("unified calendar event not found")
+// DeleteSyncedCalendarEvents removes the family's events that a sync from
+// sourceType brought in with the given source IDs, such as events cancelled
+// since the last sync, and returns how many it removed
+func (s *CalendarService) DeleteSyncedCalendarEvents(familyID, sourceType string, sourceIDs []string) (int, error) {
+	if len(sourceIDs) == 0 {
+		return 0, nil
+	}
+
+	removed := 0
+	err := s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		for _, sourceID := range sourceIDs {
+			result, err := tx.Exec(`DELETE FROM calendar_events WHERE family_id = ? AND external_source = ? AND external_id = ?`,
+				familyID, sourceType, sourceID)
+			if err != nil {
+				return fmt.Errorf("failed to delete synced event %s: %w", sourceID, err)
+			}
+			rows, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to check affected rows: %w", err)
+			}
+			removed += int(rows)
 		}
-		return nil, fmt.Errorf("failed to get unified calendar event: %w", err)
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	// Handle nullable fields
-	if description.Valid {
-		event.Description = &description.String
+	if removed > 0 {
+		s.publishChange(familyID)
 	}
-	if location.Valid {
-		event.Location = &location.String
+	return removed, nil
+}
+
+// MaxBulkCalendarEvents limits how many events one bulk import may contain
+const MaxBulkCalendarEvents = 500
+
+// BulkCreateUnifiedCalendarEvents validates every item up front and, only if
+// all of them pass, inserts the whole batch in a single transaction. When any
+// item is invalid nothing is written and the response marks the remaining
+// items as skipped.
+func (s *CalendarService) BulkCreateUnifiedCalendarEvents(ctx context.Context, familyID, createdBy string, items []models.BulkCalendarEventItem) (*models.BulkEventsResponse, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("at least one event is required")
 	}
-	if createdBy.Valid {
-		event.CreatedBy = &createdBy.String
+	if len(items) > MaxBulkCalendarEvents {
+		return nil, fmt.Errorf("too many events: %d exceeds the limit of %d", len(items), MaxBulkCalendarEvents)
 	}
 
-	familyTimezone, err := GetFamilyTimezone(s.db, event.FamilyID)
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get family timezone for event conversion: %w", err)
+		return nil, fmt.Errorf("failed to get family timezone for bulk event creation: %w", err)
 	}
 
-	event.StartTime, err = ConvertFromUTC(event.StartTime, familyTimezone)
+	memberIDs, err := s.getFamilyMemberIDs(familyID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert start time from UTC: %w", err)
+		return nil, err
 	}
 
-	event.EndTime, err = ConvertFromUTC(event.EndTime, familyTimezone)
+	rules, err := s.colorRules.ListRules(familyID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert end time from UTC: %w", err)
+		return nil, fmt.Errorf("failed to load event color rules: %w", err)
+	}
+
+	response := &models.BulkEventsResponse{Results: make([]models.BulkEventResult, len(items))}
+	for i := range items {
+		errs := items[i].Validate()
+		for _, attendeeID := range items[i].Attendees {
+			if !memberIDs[attendeeID] {
+				errs = append(errs, validation.ValidationError{
+					Field:   "attendees",
+					Message: fmt.Sprintf("attendee %s is not a member of this family", attendeeID),
+				})
+			}
+		}
+
+		response.Results[i] = models.BulkEventResult{Index: i, Status: models.BulkEventStatusSkipped}
+		if len(errs) > 0 {
+			response.Results[i].Status = models.BulkEventStatusInvalid
+			response.Results[i].Errors = errs
+			response.Invalid++
+		}
 	}
 
-	event.CreatedAt, err = ConvertFromUTC(event.CreatedAt, familyTimezone)
-	if err != nil {
-		return nil, fmt.Errorf("failed to convert created_at from UTC: %w", err)
+	if response.Invalid > 0 {
+		return response, nil
 	}
 
-	event.UpdatedAt, err = ConvertFromUTC(event.UpdatedAt, familyTimezone)
+	err = s.db.InTx(ctx, func(tx *sql.Tx) error {
+		eventStmt, err := tx.Prepare(`
+			INSERT INTO unified_calendar_events (id, family_id, title, description, start_time, end_time,
+												location, all_day, event_type, color, category, created_by, priority,
+												recurrence_rule, visibility, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare event statement: %w", err)
+		}
+		defer eventStmt.Close()
+
+		attendeeStmt, err := tx.Prepare(`INSERT INTO unified_calendar_event_attendees (event_id, user_id) VALUES (?, ?)`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare attendee statement: %w", err)
+		}
+		defer attendeeStmt.Close()
+
+		now := time.Now().UTC()
+		for i, item := range items {
+			startTimeUTC, err := ConvertToUTC(item.StartTime, familyTimezone)
+			if err != nil {
+				return fmt.Errorf("event %d: failed to convert start time to UTC: %w", i, err)
+			}
+			endTimeUTC, err := ConvertToUTC(item.EndTime, familyTimezone)
+			if err != nil {
+				return fmt.Errorf("event %d: failed to convert end time to UTC: %w", i, err)
+			}
+
+			eventType := item.EventType
+			if eventType == "" {
+				eventType = models.EventTypeEvent
+			}
+			// An explicit color on the item wins over the family's rules
+			rule := models.MatchEventColorRule(rules, models.EventRuleSample{
+				Title:       item.Title,
+				Description: stringValue(item.Description),
+				Location:    stringValue(item.Location),
+				Source:      models.EventSourceFamstack,
+				EventType:   string(eventType),
+				Attendees:   item.Attendees,
+			})
+			color, category := ruleColorAndCategory(rule, item.Color)
+			visibility := item.Visibility
+			if visibility == "" {
+				visibility = models.EventVisibilityPublic
+			}
+
+			eventID := generateUnifiedEventID()
+			if _, err := eventStmt.Exec(
+				eventID, familyID, item.Title, item.Description, startTimeUTC, endTimeUTC,
+				item.Location, item.AllDay, eventType, color, category, createdBy, item.Priority,
+				normalizedRule(item.RecurrenceRule), visibility, now, now,
+			); err != nil {
+				return fmt.Errorf("event %d: failed to insert: %w", i, err)
+			}
+
+			for _, attendeeID := range item.Attendees {
+				if _, err := attendeeStmt.Exec(eventID, attendeeID); err != nil {
+					return fmt.Errorf("event %d: failed to add attendee %s: %w", i, attendeeID, err)
+				}
+			}
+
+			response.Results[i].Status = models.BulkEventStatusCreated
+			response.Results[i].EventID = eventID
+		}
+
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert updated_at from UTC: %w", err)
+		return nil, fmt.Errorf("failed to create calendar events: %w", err)
 	}
+	s.publishChange(familyID)
 
-	return &event, nil
+	response.Created = len(items)
+	return response, nil
 }
 
-// UpsertCalendarEvent inserts or updates a calendar event from external sync
-func (s *CalendarService) UpsertCalendarEvent(event *CalendarEventForSync) error {
-	query := `
-		INSERT OR REPLACE INTO calendar_events
-		(id, family_id, created_by, title, description, location, start_time, end_time,
-		 all_day, attendees, source_type, source_id, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`
+// defaultEventColor is used when neither the event nor a rule picks a color
+const defaultEventColor = "#3b82f6"
+
+// ruleColorAndCategory resolves an event's color and category from the matched
+// rule. An explicit color is kept; the rule still supplies the category.
+func ruleColorAndCategory(rule *models.EventColorRule, explicitColor string) (string, string) {
+	color := explicitColor
+	category := ""
+	if rule != nil {
+		if color == "" {
+			color = rule.Color
+		}
+		category = rule.Category
+	}
+	if color == "" {
+		color = defaultEventColor
+	}
+	return color, category
+}
 
-	attendeesJSON := "[]"
-	if len(event.Attendees) > 0 {
-		// Simple JSON encoding for attendees
-		attendeesJSON = `["` + strings.Join(event.Attendees, `","`) + `"]`
+// integrationProvider returns the provider of one of the family's
+// integrations, or "" when there is no such integration
+func (s *CalendarService) integrationProvider(familyID, integrationID string) (string, error) {
+	var provider string
+	err := s.db.QueryRow(`SELECT provider FROM integrations WHERE id = ? AND family_id = ?`, integrationID, familyID).Scan(&provider)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up integration %s: %w", integrationID, err)
 	}
+	return provider, nil
+}
 
-	_, err := s.db.Exec(query,
-		event.ID, event.FamilyID, event.CreatedBy, event.Title, event.Description,
-		event.Location, event.StartTime, event.EndTime, event.AllDay,
-		attendeesJSON, event.SourceType, event.SourceID,
-		event.CreatedAt, event.UpdatedAt,
-	)
+// applyColorRules sets Color and Category on synced events from each family's
+// rules, loading every family's rules once per batch
+func (s *CalendarService) applyColorRules(events []*CalendarEventForSync) error {
+	rulesByFamily := make(map[string][]models.EventColorRule)
+	for _, event := range events {
+		rules, ok := rulesByFamily[event.FamilyID]
+		if !ok {
+			var err error
+			rules, err = s.colorRules.ListRules(event.FamilyID)
+			if err != nil {
+				return fmt.Errorf("failed to load event color rules: %w", err)
+			}
+			rulesByFamily[event.FamilyID] = rules
+		}
 
-	return err
+		rule := models.MatchEventColorRule(rules, models.EventRuleSample{
+			Title:       event.Title,
+			Description: event.Description,
+			Location:    event.Location,
+			Organizer:   event.Organizer,
+			Source:      event.SourceType,
+			EventType:   string(models.EventTypeEvent),
+			Attendees:   attendeeEmails(event.Attendees),
+		})
+		if rule != nil {
+			event.Color = rule.Color
+			event.Category = rule.Category
+		}
+	}
+	return nil
+}
+
+// getFamilyMemberIDs returns the set of active member IDs for a family
+func (s *CalendarService) getFamilyMemberIDs(familyID string) (map[string]bool, error) {
+	rows, err := s.db.Query(`SELECT id FROM family_members WHERE family_id = ? AND is_active = TRUE`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load family members: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan family member: %w", err)
+		}
+		ids[id] = true
+	}
+
+	return ids, rows.Err()
 }
 
 // GetSyncSettings retrieves sync settings for a user
@@ -675,13 +1354,26 @@ func (s *CalendarService) GetSyncSettings(userID string) (*SyncSettings, error)
 // UpdateSyncStatus updates the sync status for a user
 func (s *CalendarService) UpdateSyncStatus(userID, status, errorMsg string, eventsSynced int) error {
 	query := `
-		INSERT OR REPLACE INTO calendar_sync_settings
+		INSERT INTO calendar_sync_settings
 		(created_by, last_sync_at, sync_status, sync_error, events_synced, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(created_by) DO UPDATE SET
+			last_sync_at = excluded.last_sync_at, sync_status = excluded.sync_status,
+			sync_error = excluded.sync_error, events_synced = excluded.events_synced,
+			updated_at = excluded.updated_at
 	`
 
 	_, err := s.db.Exec(query, userID, time.Now().UTC(), status, errorMsg, eventsSynced, time.Now().UTC())
-	return err
+	if err != nil {
+		return err
+	}
+
+	// Only needed to announce the status to the member's family
+	var familyID string
+	if s.db.QueryRow(`SELECT family_id FROM family_members WHERE id = ?`, userID).Scan(&familyID) == nil {
+		s.events.Publish(eventbus.Event{Topic: eventbus.TopicSyncStatus, FamilyID: familyID, SubjectID: userID, Detail: status})
+	}
+	return nil
 }
 
 // SyncSettings represents calendar sync configuration
@@ -692,71 +1384,28 @@ type SyncSettings struct {
 
 // Helper functions
 
-func (s *CalendarService) scanCalendarEvent(scanner interface {
-	Scan(dest ...interface{}) error
-}) (*models.CalendarEvent, error) {
+func (s *CalendarService) scanCalendarEvent(rows *sql.Rows) (*models.CalendarEvent, error) {
 	var event models.CalendarEvent
-	var description, location, assignedTo sql.NullString
-
-	err := scanner.Scan(
-		&event.ID, &event.FamilyID, &event.Title, &description,
-		&event.StartTime, &event.EndTime, &location, &event.EventType,
-		&assignedTo, &event.CreatedBy, &event.CreatedAt, &event.UpdatedAt,
-	)
-	if err != nil {
+	if err := database.ScanStruct(rows, &event); err != nil {
 		return nil, err
 	}
-
-	// Handle nullable fields
-	if description.Valid {
-		event.Description = &description.String
-	}
-	if location.Valid {
-		event.Location = &location.String
-	}
-	if assignedTo.Valid {
-		event.AssignedTo = &assignedTo.String
-	}
-
 	return &event, nil
 }
 
-func (s *CalendarService) scanUnifiedCalendarEvent(scanner interface {
-	Scan(dest ...interface{}) error
-}) (*models.UnifiedCalendarEvent, error) {
+func (s *CalendarService) scanUnifiedCalendarEvent(rows *sql.Rows) (*models.UnifiedCalendarEvent, error) {
 	var event models.UnifiedCalendarEvent
-	var description, location, createdBy sql.NullString
-
-	err := scanner.Scan(
-		&event.ID, &event.FamilyID, &event.Title, &description,
-		&event.StartTime, &event.EndTime, &location, &event.AllDay,
-		&event.EventType, &event.Color, &createdBy, &event.Priority,
-		&event.Status, &event.CreatedAt, &event.UpdatedAt,
-	)
-	if err != nil {
+	if err := database.ScanStruct(rows, &event); err != nil {
 		return nil, err
 	}
-
-	// Handle nullable fields
-	if description.Valid {
-		event.Description = &description.String
-	}
-	if location.Valid {
-		event.Location = &location.String
-	}
-	if createdBy.Valid {
-		event.CreatedBy = &createdBy.String
-	}
-
 	return &event, nil
 }
 
 func generateEventID() string {
-	return fmt.Sprintf("event_%d", time.Now().UTC().UnixNano())
+	return ids.New("event")
 }
 
 func generateUnifiedEventID() string {
-	return fmt.Sprintf("unified_event_%d", time.Now().UTC().UnixNano())
+	return ids.New("unified_event")
 }
 
 // getFamilyIDForMember retrieves the family ID for a given member ID
@@ -772,17 +1421,3 @@ func (s *CalendarService) getFamilyIDForMember(memberID string) (string, error)
 	}
 	return familyID, nil
 }
-
-// getFamilyIDForEvent retrieves the family ID for a given event ID
-func (s *CalendarService) getFamilyIDForEvent(eventID string) (string, error) {
-	query := `SELECT family_id FROM calendar_events WHERE id = ?`
-	var familyID string
-	err := s.db.QueryRow(query, eventID).Scan(&familyID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return "", fmt.Errorf("event not found")
-		}
-		return "", fmt.Errorf("failed to get family ID for event: %w", err)
-	}
-	return familyID, nil
-}
@@ -1,18 +1,21 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
 	"time"
 
 	"famstack/internal/database"
+	"famstack/internal/models"
+	"famstack/internal/validation"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func setupTestDB(t *testing.T) *database.Fascade {
+func setupTestDB(t testing.TB) *database.Fascade {
 	dbFile := fmt.Sprintf("test_db_%d.db", time.Now().UnixNano())
 	db, err := database.New(dbFile)
 	require.NoError(t, err)
@@ -37,6 +40,9 @@ func TestGetUnifiedCalendarEvents_TimezoneConversion(t *testing.T) {
 	timezone := "America/New_York"
 	_, err := db.Exec(`INSERT INTO families (id, name, timezone) VALUES (?, ?, ?)`, familyID, "Timezone Test Family", timezone)
 	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, is_active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"user_test", familyID, "Test", "User", "adult", true, time.Now(), time.Now())
+	require.NoError(t, err)
 
 	eventID := "event_tz_test"
 	// This time is 1:00 PM UTC on the test date.
@@ -285,3 +291,351 @@ func TestTimezoneEdgeCases(t *testing.T) {
 			utcTime.Format("15:04:05 MST"), displayTime.Format("15:04:05 MST"))
 	})
 }
+
+func seedBulkEventFamily(t testing.TB, db *database.Fascade) (string, string) {
+	familyID := "fam_bulk"
+	memberID := "member_bulk"
+	_, err := db.Exec(`INSERT INTO families (id, name, timezone) VALUES (?, ?, ?)`, familyID, "Bulk Family", "UTC")
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, is_active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		memberID, familyID, "Bulk", "Member", "adult", true, time.Now(), time.Now())
+	require.NoError(t, err)
+	return familyID, memberID
+}
+
+func TestBulkCreateUnifiedCalendarEvents_InsertsAllInOneBatch(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	start := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	items := []models.BulkCalendarEventItem{
+		{Title: "Soccer", StartTime: start, EndTime: start.Add(time.Hour), Attendees: []string{memberID}},
+		{Title: "Piano", StartTime: start.Add(3 * time.Hour), EndTime: start.Add(4 * time.Hour), EventType: "appointment", Color: "#ff0000"},
+	}
+
+	response, err := service.BulkCreateUnifiedCalendarEvents(context.Background(), familyID, memberID, items)
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.Created)
+	assert.Equal(t, 0, response.Invalid)
+	require.Len(t, response.Results, 2)
+
+	for _, result := range response.Results {
+		assert.Equal(t, models.BulkEventStatusCreated, result.Status)
+		assert.NotEmpty(t, result.EventID)
+	}
+	assert.NotEqual(t, response.Results[0].EventID, response.Results[1].EventID)
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM unified_calendar_events WHERE family_id = ?`, familyID).Scan(&count))
+	assert.Equal(t, 2, count)
+
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM unified_calendar_event_attendees WHERE event_id = ?`, response.Results[0].EventID).Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
+func TestBulkCreateUnifiedCalendarEvents_InvalidItemRejectsBatch(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	start := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	items := []models.BulkCalendarEventItem{
+		{Title: "Valid", StartTime: start, EndTime: start.Add(time.Hour)},
+		{Title: "", StartTime: start, EndTime: start.Add(-time.Hour)},
+		{Title: "Stranger", StartTime: start, EndTime: start.Add(time.Hour), Attendees: []string{"someone_else"}},
+	}
+
+	response, err := service.BulkCreateUnifiedCalendarEvents(context.Background(), familyID, memberID, items)
+	require.NoError(t, err)
+	assert.Equal(t, 0, response.Created)
+	assert.Equal(t, 2, response.Invalid)
+	assert.Equal(t, models.BulkEventStatusSkipped, response.Results[0].Status)
+	assert.Equal(t, models.BulkEventStatusInvalid, response.Results[1].Status)
+	assert.Len(t, response.Results[1].Errors, 2)
+	assert.Equal(t, "attendees", response.Results[2].Errors[0].Field)
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM unified_calendar_events WHERE family_id = ?`, familyID).Scan(&count))
+	assert.Equal(t, 0, count)
+}
+
+func TestBulkCreateUnifiedCalendarEvents_EnforcesLimit(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	items := make([]models.BulkCalendarEventItem, MaxBulkCalendarEvents+1)
+	_, err := service.BulkCreateUnifiedCalendarEvents(context.Background(), familyID, memberID, items)
+	assert.Error(t, err)
+}
+
+func TestPutUnifiedCalendarEvent_CreatesThenUpdates(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	start := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	req := &models.PutUnifiedCalendarEventRequest{
+		ID:        "ABC-123@example.com",
+		ICalUID:   "abc-123@example.com",
+		Title:     "Dentist",
+		StartTime: start,
+		EndTime:   start.Add(time.Hour),
+		Attendees: []string{memberID},
+	}
+
+	event, created, err := service.PutUnifiedCalendarEvent(context.Background(), familyID, memberID, req)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, models.UnifiedEventStatusActive, event.Status)
+	require.Len(t, event.Attendees, 1)
+
+	req.Title = "Dentist (moved)"
+	req.StartTime = start.Add(2 * time.Hour)
+	req.EndTime = start.Add(3 * time.Hour)
+	event, created, err = service.PutUnifiedCalendarEvent(context.Background(), familyID, memberID, req)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "Dentist (moved)", event.Title)
+	require.NotNil(t, event.ICalUID)
+	assert.Equal(t, "abc-123@example.com", *event.ICalUID)
+
+	_, _, err = service.PutUnifiedCalendarEvent(context.Background(), "another_family", memberID, req)
+	assert.EqualError(t, err, "event ID is already in use")
+
+	require.NoError(t, service.RemoveUnifiedEventAttendee(context.Background(), familyID, req.ID, memberID))
+	assert.Error(t, service.RemoveUnifiedEventAttendee(context.Background(), familyID, req.ID, memberID))
+
+	require.NoError(t, service.DeleteUnifiedCalendarEvent(familyID, req.ID, ""))
+	assert.EqualError(t, service.DeleteUnifiedCalendarEvent(familyID, req.ID, ""), "unified calendar event not found")
+}
+
+func TestPutUnifiedCalendarEvent_Validates(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	start := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	_, _, err := service.PutUnifiedCalendarEvent(context.Background(), familyID, memberID, &models.PutUnifiedCalendarEventRequest{
+		ID:        "bad/id",
+		StartTime: start,
+		EndTime:   start.Add(-time.Hour),
+		Attendees: []string{"someone_else"},
+	})
+	assert.Error(t, err)
+}
+
+func TestCreateUnifiedCalendarEvent_WritesAttendeesWithEvent(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	start := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	attendees := `["` + memberID + `","` + memberID + `"]`
+	event, err := service.CreateUnifiedCalendarEvent(context.Background(), &models.CreateUnifiedCalendarEventRequest{
+		FamilyID: familyID, IntegrationID: "int_google", ExternalEventID: "ext_1",
+		Title: "Swim meet", StartTime: start, EndTime: start.Add(time.Hour), Attendees: &attendees,
+	})
+	require.NoError(t, err)
+	require.Len(t, event.Attendees, 1)
+	assert.Equal(t, memberID, event.Attendees[0].ID)
+
+	stranger := `["` + memberID + `","someone_else"]`
+	_, err = service.CreateUnifiedCalendarEvent(context.Background(), &models.CreateUnifiedCalendarEventRequest{
+		FamilyID: familyID, IntegrationID: "int_google", ExternalEventID: "ext_2",
+		Title: "Gala", StartTime: start, EndTime: start.Add(time.Hour), Attendees: &stranger,
+	})
+	var validationErrs validation.ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
+
+	var events, rows int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM unified_calendar_events WHERE family_id = ?`, familyID).Scan(&events))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM unified_calendar_event_attendees`).Scan(&rows))
+	assert.Equal(t, 1, events, "the rejected event was not written")
+	assert.Equal(t, 1, rows, "nor were any of its attendees")
+}
+
+func TestUpdateUnifiedCalendarEvent_PatchesFieldsAndAttendees(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+	_, err := db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, is_active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"member_second", familyID, "Second", "Member", "child", true, time.Now(), time.Now())
+	require.NoError(t, err)
+
+	start := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	event, _, err := service.PutUnifiedCalendarEvent(context.Background(), familyID, memberID, &models.PutUnifiedCalendarEventRequest{
+		ID:        "swim",
+		Title:     "Swim",
+		StartTime: start,
+		EndTime:   start.Add(time.Hour),
+		Attendees: []string{memberID},
+	})
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE unified_calendar_event_attendees SET response_status = 'accepted' WHERE event_id = ? AND user_id = ?`, event.ID, memberID)
+	require.NoError(t, err)
+
+	// Only the fields sent change
+	color := "#22c55e"
+	location := "Rec center"
+	attendees := []string{memberID, "member_second", "member_second"}
+	updated, err := service.UpdateUnifiedCalendarEvent(context.Background(), familyID, event.ID, &models.UpdateUnifiedCalendarEventRequest{
+		Color:     &color,
+		Location:  &location,
+		Attendees: &attendees,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Swim", updated.Title)
+	assert.Equal(t, color, updated.Color)
+	require.NotNil(t, updated.Location)
+	assert.Equal(t, location, *updated.Location)
+	assert.True(t, updated.StartTime.Equal(start))
+	require.Len(t, updated.Attendees, 2)
+
+	var status string
+	require.NoError(t, db.QueryRow(`SELECT response_status FROM unified_calendar_event_attendees WHERE event_id = ? AND user_id = ?`, event.ID, memberID).Scan(&status))
+	assert.Equal(t, "accepted", status, "kept attendees keep their response")
+
+	attendees = []string{"member_second"}
+	updated, err = service.UpdateUnifiedCalendarEvent(context.Background(), familyID, event.ID, &models.UpdateUnifiedCalendarEventRequest{Attendees: &attendees})
+	require.NoError(t, err)
+	require.Len(t, updated.Attendees, 1)
+	assert.Equal(t, "member_second", updated.Attendees[0].ID)
+
+	badColor := "green"
+	strangers := []string{"someone_else"}
+	_, err = service.UpdateUnifiedCalendarEvent(context.Background(), familyID, event.ID, &models.UpdateUnifiedCalendarEventRequest{Color: &badColor})
+	assert.Error(t, err)
+	_, err = service.UpdateUnifiedCalendarEvent(context.Background(), familyID, event.ID, &models.UpdateUnifiedCalendarEventRequest{Attendees: &strangers})
+	assert.Error(t, err)
+	_, err = service.UpdateUnifiedCalendarEvent(context.Background(), "another_family", event.ID, &models.UpdateUnifiedCalendarEventRequest{Color: &color})
+	assert.EqualError(t, err, "unified calendar event not found")
+}
+
+func TestUnifiedCalendarEvents_RecurringSeries(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	// Piano every Wednesday at 5pm, starting 2025-03-05
+	rule := "FREQ=WEEKLY;BYDAY=WE"
+	start := time.Date(2025, 3, 5, 17, 0, 0, 0, time.UTC)
+	_, _, err := service.PutUnifiedCalendarEvent(context.Background(), familyID, memberID, &models.PutUnifiedCalendarEventRequest{
+		ID: "piano", Title: "Piano", StartTime: start, EndTime: start.Add(time.Hour),
+		Attendees: []string{memberID}, RecurrenceRule: &rule,
+	})
+	require.NoError(t, err)
+
+	march := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	april := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	events, err := service.GetUnifiedCalendarEvents(familyID, march, april)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	assert.Equal(t, "piano_20250312T170000Z", events[1].ID)
+	require.NotNil(t, events[1].RecurringEventID)
+	assert.Equal(t, "piano", *events[1].RecurringEventID)
+	assert.Len(t, events[1].Attendees, 1)
+
+	// Move one occurrence
+	moved := time.Date(2025, 3, 12, 18, 0, 0, 0, time.UTC)
+	event, err := service.UpdateUnifiedCalendarEvent(context.Background(), familyID, "piano_20250312T170000Z", &models.UpdateUnifiedCalendarEventRequest{StartTime: &moved})
+	require.NoError(t, err)
+	assert.Equal(t, "piano_20250312T170000Z", event.ID)
+	assert.True(t, event.StartTime.Equal(moved))
+	assert.Nil(t, event.RecurrenceRule)
+	assert.Len(t, event.Attendees, 1)
+
+	// Delete another
+	require.NoError(t, service.DeleteUnifiedCalendarEvent(familyID, "piano_20250319T170000Z", models.RecurrenceScopeThis))
+
+	events, err = service.GetUnifiedCalendarEvents(familyID, march, april)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.True(t, events[1].StartTime.Equal(moved))
+
+	// Rename from the 26th on
+	title := "Piano lesson"
+	renamed, err := service.UpdateUnifiedCalendarEvent(context.Background(), familyID, "piano_20250326T170000Z", &models.UpdateUnifiedCalendarEventRequest{
+		Title: &title, Scope: models.RecurrenceScopeFuture,
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, "piano", renamed.ID)
+
+	events, err = service.GetUnifiedCalendarEvents(familyID, march, april)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, "Piano", events[0].Title)
+	assert.Equal(t, "Piano lesson", events[2].Title)
+
+	series, err := service.GetStoredUnifiedCalendarEvent("piano")
+	require.NoError(t, err)
+	require.NotNil(t, series.RecurrenceRule)
+	assert.Equal(t, "FREQ=WEEKLY;UNTIL=20250326T165959Z;BYDAY=WE", *series.RecurrenceRule)
+
+	// Deleting the original series takes its edited occurrence with it
+	require.NoError(t, service.DeleteUnifiedCalendarEvent(familyID, "piano_20250305T170000Z", models.RecurrenceScopeAll))
+	events, err = service.GetUnifiedCalendarEvents(familyID, march, april)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "Piano lesson", events[0].Title)
+
+	_, err = service.UpdateUnifiedCalendarEvent(context.Background(), familyID, renamed.ID, &models.UpdateUnifiedCalendarEventRequest{Scope: "sometimes"})
+	var validationErrs validation.ValidationErrors
+	assert.ErrorAs(t, err, &validationErrs)
+}
+
+// seedManyEvents adds count hour-long events, one every 30 minutes from
+// start, each attended by memberID
+func seedManyEvents(t testing.TB, service *CalendarService, familyID, memberID string, start time.Time, count int) {
+	items := make([]models.BulkCalendarEventItem, 0, MaxBulkCalendarEvents)
+	for i := range count {
+		eventStart := start.Add(time.Duration(i) * 30 * time.Minute)
+		items = append(items, models.BulkCalendarEventItem{
+			Title: fmt.Sprintf("Event %d", i), StartTime: eventStart, EndTime: eventStart.Add(time.Hour), Attendees: []string{memberID},
+		})
+		if len(items) == MaxBulkCalendarEvents || i == count-1 {
+			_, err := service.BulkCreateUnifiedCalendarEvents(context.Background(), familyID, memberID, items)
+			require.NoError(t, err)
+			items = items[:0]
+		}
+	}
+}
+
+func TestGetUnifiedCalendarEvents_LoadsAttendeesPastParameterLimit(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	start := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	seedManyEvents(t, service, familyID, memberID, start, 2500)
+
+	events, err := service.GetUnifiedCalendarEvents(familyID, start, start.AddDate(0, 2, 0))
+	require.NoError(t, err)
+	require.Len(t, events, 2500)
+	for _, event := range events {
+		require.Len(t, event.Attendees, 1, "event %s", event.ID)
+		assert.Equal(t, memberID, event.Attendees[0].ID)
+	}
+}
+
+func BenchmarkGetUnifiedCalendarEvents(b *testing.B) {
+	for _, count := range []int{100, 1000, 5000} {
+		b.Run(fmt.Sprintf("events=%d", count), func(b *testing.B) {
+			db := setupTestDB(b)
+			service := NewCalendarService(db)
+			familyID, memberID := seedBulkEventFamily(b, db)
+			start := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+			seedManyEvents(b, service, familyID, memberID, start, count)
+			end := start.AddDate(1, 0, 0)
+
+			b.ResetTimer()
+			for range b.N {
+				events, err := service.GetUnifiedCalendarEvents(familyID, start, end)
+				if err != nil || len(events) != count {
+					b.Fatalf("listed %d events: %v", len(events), err)
+				}
+			}
+		})
+	}
+}
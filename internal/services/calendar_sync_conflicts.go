@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"famstack/internal/models"
+	"famstack/internal/validation"
+)
+
+// saveSyncConflict records the remote's version of an edited event for
+// review, replacing any version recorded before
+func saveSyncConflict(tx *sql.Tx, familyID, integrationID, eventID string, remote *models.SyncedEventVersion, remoteHash string, now time.Time) error {
+	remoteJSON, err := json.Marshal(remote)
+	if err != nil {
+		return fmt.Errorf("failed to encode remote version of event %s: %w", eventID, err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO event_sync_conflicts (id, family_id, integration_id, event_id, remote, remote_hash, detected_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(event_id) DO UPDATE SET remote = excluded.remote, remote_hash = excluded.remote_hash
+	`, generateID(), familyID, integrationID, eventID, string(remoteJSON), remoteHash, now); err != nil {
+		return fmt.Errorf("failed to record conflict of event %s: %w", eventID, err)
+	}
+	return nil
+}
+
+// storedSyncConflict is a conflict row with the hash the event takes when
+// the remote's version is seen
+type storedSyncConflict struct {
+	models.SyncConflict
+	remoteHash string
+}
+
+// ListSyncConflicts returns the integration's events awaiting review, oldest first
+func (s *CalendarService) ListSyncConflicts(familyID, integrationID string) ([]models.SyncConflict, error) {
+	rows, err := s.db.Query(`
+		SELECT id, integration_id, event_id, remote, remote_hash, detected_at
+		FROM event_sync_conflicts
+		WHERE family_id = ? AND integration_id = ?
+		ORDER BY detected_at, id
+	`, familyID, integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sync conflicts: %w", err)
+	}
+	var stored []storedSyncConflict
+	for rows.Next() {
+		conflict, err := scanSyncConflict(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stored = append(stored, *conflict)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read sync conflicts: %w", err)
+	}
+
+	conflicts := []models.SyncConflict{}
+	for _, conflict := range stored {
+		local, err := s.GetStoredUnifiedCalendarEvent(conflict.EventID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load event %s: %w", conflict.EventID, err)
+		}
+		conflict.Local = *local
+		conflicts = append(conflicts, conflict.SyncConflict)
+	}
+	return conflicts, nil
+}
+
+// ResolveSyncConflict settles a conflict by keeping one side: the family's
+// edit, or the remote's version, which is written over it. Either way the
+// remote's version counts as seen, so only its next change comes up again.
+func (s *CalendarService) ResolveSyncConflict(familyID, conflictID, keep string) (*models.UnifiedCalendarEvent, error) {
+	validator := validation.NewValidator()
+	validator.OneOf("keep", keep, []string{models.SyncConflictKeepLocal, models.SyncConflictKeepRemote})
+	if err := validator.ToError(); err != nil {
+		return nil, err
+	}
+
+	conflict, err := scanSyncConflict(s.db.QueryRow(`
+		SELECT id, integration_id, event_id, remote, remote_hash, detected_at
+		FROM event_sync_conflicts
+		WHERE id = ? AND family_id = ?
+	`, conflictID, familyID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("sync conflict not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	err = s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		if keep == models.SyncConflictKeepRemote {
+			remote := conflict.Remote
+			if _, err := tx.Exec(`
+				UPDATE unified_calendar_events
+				SET title = ?, description = ?, location = ?, start_time = ?, end_time = ?, all_day = ?,
+					color = ?, category = ?, recurrence_rule = ?, recurrence_exdates = ?,
+					recurring_event_id = ?, source_hash = ?, source_synced_at = ?, updated_at = ?
+				WHERE id = ?
+			`, remote.Title, remote.Description, remote.Location, remote.StartTime, remote.EndTime, remote.AllDay,
+				remote.Color, remote.Category, remote.RecurrenceRule, remote.RecurrenceExdates,
+				remote.RecurringEventID, conflict.remoteHash, now, now, conflict.EventID); err != nil {
+				return fmt.Errorf("failed to apply remote version of event %s: %w", conflict.EventID, err)
+			}
+			if err := syncEventAttendees(tx, conflict.EventID, remote.AttendeeIDs); err != nil {
+				return err
+			}
+		} else if _, err := tx.Exec(`UPDATE unified_calendar_events SET source_hash = ? WHERE id = ?`, conflict.remoteHash, conflict.EventID); err != nil {
+			return fmt.Errorf("failed to keep event %s: %w", conflict.EventID, err)
+		}
+
+		if _, err := tx.Exec(`DELETE FROM event_sync_conflicts WHERE id = ?`, conflict.ID); err != nil {
+			return fmt.Errorf("failed to clear sync conflict: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve sync conflict: %w", err)
+	}
+	if keep == models.SyncConflictKeepRemote {
+		s.publishChange(familyID)
+	}
+
+	return s.GetStoredUnifiedCalendarEvent(conflict.EventID)
+}
+
+// scanSyncConflict scans a conflict row, its remote version decoded
+func scanSyncConflict(scanner interface{ Scan(dest ...any) error }) (*storedSyncConflict, error) {
+	var conflict storedSyncConflict
+	var remoteJSON string
+	if err := scanner.Scan(&conflict.ID, &conflict.IntegrationID, &conflict.EventID, &remoteJSON,
+		&conflict.remoteHash, &conflict.DetectedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan sync conflict: %w", err)
+	}
+	if err := json.Unmarshal([]byte(remoteJSON), &conflict.Remote); err != nil {
+		return nil, fmt.Errorf("failed to decode sync conflict %s: %w", conflict.ID, err)
+	}
+	return &conflict, nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"famstack/internal/models"
+)
+
+// MonthGridRange returns the first and last dates of the grid for a month:
+// whole weeks starting on weekStart, "sunday" or "monday", that cover it.
+// Dates are midnights in UTC, standing for days in any timezone.
+func MonthGridRange(year int, month time.Month, weekStart string) (time.Time, time.Time) {
+	firstWeekday := time.Sunday
+	if weekStart == models.WeekStartMonday {
+		firstWeekday = time.Monday
+	}
+
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1)
+	lead := (int(first.Weekday()) - int(firstWeekday) + 7) % 7
+	trail := (int(firstWeekday) + 6 - int(last.Weekday())) % 7
+	return first.AddDate(0, 0, -lead), last.AddDate(0, 0, trail)
+}
+
+// BuildMonthView counts and summarizes the events on each day of a month's
+// grid, with days in loc
+func BuildMonthView(events []models.UnifiedCalendarEvent, year int, month time.Month, loc *time.Location, weekStart string) models.MonthResponse {
+	gridStart, gridEnd := MonthGridRange(year, month, weekStart)
+	view := models.MonthResponse{
+		Month:     time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Format("2006-01"),
+		Timezone:  loc.String(),
+		WeekStart: weekStart,
+		Days:      []models.MonthDay{},
+	}
+
+	for d := gridStart; !d.After(gridEnd); d = d.AddDate(0, 0, 1) {
+		dayEvents := eventsOnDay(events, time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, loc))
+		day := models.MonthDay{
+			Date:       d.Format("2006-01-02"),
+			InMonth:    d.Month() == month,
+			EventCount: len(dayEvents),
+			Events:     []models.MonthEventSummary{},
+		}
+		for i, event := range dayEvents {
+			if event.AllDay {
+				day.AllDayCount++
+			}
+			if i < models.MaxMonthDayEvents {
+				day.Events = append(day.Events, models.MonthEventSummary{
+					ID:        event.ID,
+					Title:     event.Title,
+					Color:     event.Color,
+					AllDay:    event.AllDay,
+					StartTime: event.StartTime.In(loc),
+					IsPrivate: isPrivateEvent(event),
+				})
+			}
+		}
+		day.MoreCount = day.EventCount - len(day.Events)
+
+		if day.InMonth {
+			view.TotalEvents += day.EventCount
+		}
+		view.Days = append(view.Days, day)
+	}
+
+	return view
+}
+
+// BuildAgenda lists the events on each day from startDate through endDate,
+// days in loc, in the order an agenda shows them
+func BuildAgenda(events []models.UnifiedCalendarEvent, startDate, endDate time.Time, loc *time.Location) []models.AgendaItem {
+	items := []models.AgendaItem{}
+	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
+		dayStart := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, loc)
+		for i, event := range eventsOnDay(events, dayStart) {
+			attendeeIDs := make([]string, len(event.Attendees))
+			for j, attendee := range event.Attendees {
+				attendeeIDs[j] = attendee.ID
+			}
+			items = append(items, models.AgendaItem{
+				ID:          event.ID,
+				Title:       event.Title,
+				StartTime:   event.StartTime.In(loc),
+				EndTime:     event.EndTime.In(loc),
+				AllDay:      event.AllDay,
+				Color:       event.Color,
+				Location:    event.Location,
+				AttendeeIDs: attendeeIDs,
+				IsPrivate:   isPrivateEvent(event),
+				Date:        d.Format("2006-01-02"),
+				FirstOfDay:  i == 0,
+				Continued:   event.StartTime.Before(dayStart),
+			})
+		}
+	}
+	return items
+}
+
+// eventsOnDay returns the events overlapping the day starting at dayStart,
+// all-day events first and the rest by start time
+func eventsOnDay(events []models.UnifiedCalendarEvent, dayStart time.Time) []models.UnifiedCalendarEvent {
+	dayEnd := NextDay(dayStart)
+	var dayEvents []models.UnifiedCalendarEvent
+	for i := range events {
+		if EventOverlaps(&events[i], dayStart, dayEnd) {
+			dayEvents = append(dayEvents, events[i])
+		}
+	}
+
+	sort.SliceStable(dayEvents, func(i, j int) bool {
+		if dayEvents[i].AllDay != dayEvents[j].AllDay {
+			return dayEvents[i].AllDay
+		}
+		if !dayEvents[i].StartTime.Equal(dayEvents[j].StartTime) {
+			return dayEvents[i].StartTime.Before(dayEvents[j].StartTime)
+		}
+		return dayEvents[i].Title < dayEvents[j].Title
+	})
+	return dayEvents
+}
+
+// isPrivateEvent reports whether an event shows others less than all of it
+func isPrivateEvent(event models.UnifiedCalendarEvent) bool {
+	return event.Visibility != "" && event.Visibility != models.EventVisibilityPublic
+}
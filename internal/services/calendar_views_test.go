@@ -0,0 +1,92 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonthGridRange(t *testing.T) {
+	// March 2026 starts on a Sunday and ends on a Tuesday
+	first, last := MonthGridRange(2026, time.March, models.WeekStartSunday)
+	assert.Equal(t, "2026-03-01", first.Format("2006-01-02"))
+	assert.Equal(t, "2026-04-04", last.Format("2006-01-02"))
+
+	first, last = MonthGridRange(2026, time.March, models.WeekStartMonday)
+	assert.Equal(t, "2026-02-23", first.Format("2006-01-02"))
+	assert.Equal(t, "2026-04-05", last.Format("2006-01-02"))
+	assert.Equal(t, 0, int(last.Sub(first).Hours()/24+1)%7, "whole weeks")
+}
+
+func TestBuildMonthView(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, 3, day, hour, 0, 0, 0, chicago)
+	}
+	events := []models.UnifiedCalendarEvent{
+		{ID: "swim", Title: "Swim", StartTime: at(10, 17), EndTime: at(10, 18)},
+		{ID: "piano", Title: "Piano", StartTime: at(10, 15), EndTime: at(10, 16)},
+		{ID: "dentist", Title: "Dentist", StartTime: at(10, 9), EndTime: at(10, 10), Visibility: models.EventVisibilityBusy},
+		{ID: "holiday", Title: "Spring break", StartTime: at(10, 0), EndTime: at(12, 0), AllDay: true},
+		{ID: "late", Title: "Late", StartTime: at(31, 20), EndTime: at(31, 21)},
+	}
+
+	view := BuildMonthView(events, 2026, time.March, chicago, models.WeekStartSunday)
+
+	assert.Equal(t, "2026-03", view.Month)
+	assert.Equal(t, "America/Chicago", view.Timezone)
+	require.Len(t, view.Days, 35)
+
+	tenth := view.Days[9]
+	assert.Equal(t, "2026-03-10", tenth.Date)
+	assert.True(t, tenth.InMonth)
+	assert.Equal(t, 4, tenth.EventCount)
+	assert.Equal(t, 1, tenth.AllDayCount)
+	require.Len(t, tenth.Events, models.MaxMonthDayEvents)
+	assert.Equal(t, []string{"holiday", "dentist", "piano"}, []string{tenth.Events[0].ID, tenth.Events[1].ID, tenth.Events[2].ID})
+	assert.True(t, tenth.Events[1].IsPrivate)
+	assert.Equal(t, 1, tenth.MoreCount)
+
+	// Spanning days counts on each
+	assert.Equal(t, 1, view.Days[10].EventCount)
+	assert.Equal(t, 0, view.Days[11].EventCount)
+
+	// The trailing days of April are in the grid but not the month
+	assert.False(t, view.Days[31].InMonth)
+	assert.Equal(t, 6, view.TotalEvents)
+}
+
+func TestBuildAgenda(t *testing.T) {
+	loc := time.UTC
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, 3, day, hour, 0, 0, 0, loc)
+	}
+	events := []models.UnifiedCalendarEvent{
+		{ID: "camp", Title: "Camp", StartTime: at(9, 18), EndTime: at(11, 10)},
+		{ID: "soccer", Title: "Soccer", StartTime: at(10, 17), EndTime: at(10, 18)},
+		{ID: "outside", Title: "Outside", StartTime: at(14, 9), EndTime: at(14, 10)},
+	}
+
+	items := BuildAgenda(events, at(10, 0), at(12, 0), loc)
+
+	type listed struct {
+		id, date         string
+		first, continued bool
+	}
+	var got []listed
+	for _, item := range items {
+		got = append(got, listed{item.ID, item.Date, item.FirstOfDay, item.Continued})
+	}
+	assert.Equal(t, []listed{
+		{"camp", "2026-03-10", true, true},
+		{"soccer", "2026-03-10", false, false},
+		{"camp", "2026-03-11", true, true},
+	}, got)
+
+	assert.NotNil(t, BuildAgenda(nil, at(10, 0), at(10, 0), loc), "an empty agenda is an empty list")
+}
@@ -0,0 +1,800 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/ids"
+	"famstack/internal/models"
+	"famstack/internal/validation"
+)
+
+// CarpoolService manages carpool groups, their driving rotation and swap requests.
+// Drivers in other famstack families are reminded through a task in their own family.
+type CarpoolService struct {
+	db         *database.Fascade
+	tasks      *TasksService
+	activities *ActivitiesService
+}
+
+// NewCarpoolService creates a new carpool service
+func NewCarpoolService(db *database.Fascade, tasks *TasksService, activities *ActivitiesService) *CarpoolService {
+	return &CarpoolService{db: db, tasks: tasks, activities: activities}
+}
+
+// ListGroups returns the groups a family organizes or drives in
+func (s *CarpoolService) ListGroups(familyID string) ([]models.CarpoolGroup, error) {
+	rows, err := s.db.Query(`
+		SELECT id, family_id, activity_id, name, join_code, created_by, created_at, updated_at
+		FROM carpool_groups
+		WHERE family_id = ? OR id IN (SELECT group_id FROM carpool_drivers WHERE family_id = ?)
+		ORDER BY name
+	`, familyID, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query carpool groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []models.CarpoolGroup{}
+	for rows.Next() {
+		group, err := scanCarpoolGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, *group)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating carpool groups: %w", err)
+	}
+
+	for i := range groups {
+		if groups[i].Drivers, err = s.listDrivers(groups[i].ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return groups, nil
+}
+
+// GetGroup returns a carpool group with its drivers in rotation order
+func (s *CarpoolService) GetGroup(groupID string) (*models.CarpoolGroup, error) {
+	row := s.db.QueryRow(`
+		SELECT id, family_id, activity_id, name, join_code, created_by, created_at, updated_at
+		FROM carpool_groups WHERE id = ?
+	`, groupID)
+	group, err := scanCarpoolGroup(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("carpool group not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if group.Drivers, err = s.listDrivers(group.ID); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// CreateGroup starts a carpool for one of the family's activities
+func (s *CarpoolService) CreateGroup(familyID, createdBy string, req *models.CreateCarpoolGroupRequest) (*models.CarpoolGroup, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if err := models.ValidateCarpoolGroup(req); err != nil {
+		return nil, err
+	}
+
+	activity, err := s.activities.GetActivity(req.ActivityID)
+	if err != nil || activity.FamilyID != familyID {
+		if err != nil && err.Error() != "activity not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("activity not found")
+	}
+
+	joinCode, err := generateJoinCode()
+	if err != nil {
+		return nil, err
+	}
+
+	var createdByValue any
+	if createdBy != "" {
+		createdByValue = createdBy
+	}
+
+	groupID := ids.New("carpool")
+	now := time.Now().UTC()
+	_, err = s.db.Exec(`
+		INSERT INTO carpool_groups (id, family_id, activity_id, name, join_code, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, groupID, familyID, req.ActivityID, req.Name, joinCode, createdByValue, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create carpool group: %w", err)
+	}
+
+	if req.DriverMemberID != nil {
+		if _, err := s.AddDriver(groupID, familyID, &models.AddCarpoolDriverRequest{MemberID: req.DriverMemberID}); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetGroup(groupID)
+}
+
+// DeleteGroup removes a group along with the reminder tasks it created
+func (s *CarpoolService) DeleteGroup(groupID string) error {
+	return s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			DELETE FROM tasks
+			WHERE id IN (SELECT task_id FROM carpool_assignments WHERE group_id = ? AND task_id IS NOT NULL)
+		`, groupID)
+		if err != nil {
+			return fmt.Errorf("failed to delete carpool reminder tasks: %w", err)
+		}
+
+		result, err := tx.Exec(`DELETE FROM carpool_groups WHERE id = ?`, groupID)
+		if err != nil {
+			return fmt.Errorf("failed to delete carpool group: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("carpool group not found")
+		}
+
+		return nil
+	})
+}
+
+// AddDriver adds a member of familyID, or an external contact, to the end of the rotation
+func (s *CarpoolService) AddDriver(groupID, familyID string, req *models.AddCarpoolDriverRequest) (*models.CarpoolDriver, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Phone = strings.TrimSpace(req.Phone)
+	req.Email = strings.TrimSpace(req.Email)
+	if err := models.ValidateCarpoolDriver(req); err != nil {
+		return nil, err
+	}
+
+	var driverFamilyID any
+	name := req.Name
+	if req.MemberID != nil {
+		var firstName, lastName string
+		err := s.db.QueryRow(`SELECT first_name, last_name FROM family_members WHERE id = ? AND family_id = ?`, *req.MemberID, familyID).Scan(&firstName, &lastName)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("family member not found")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to check family member: %w", err)
+		}
+		driverFamilyID = familyID
+		if name == "" {
+			name = strings.TrimSpace(firstName + " " + lastName)
+		}
+	}
+
+	var count int
+	if req.MemberID != nil {
+		err := s.db.QueryRow(`SELECT COUNT(*) FROM carpool_drivers WHERE group_id = ? AND member_id = ?`, groupID, *req.MemberID).Scan(&count)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check carpool drivers: %w", err)
+		}
+		if count > 0 {
+			return nil, validation.ValidationErrors{{Field: "member_id", Message: "member is already a driver in this carpool"}}
+		}
+	}
+
+	var position int
+	if err := s.db.QueryRow(`SELECT COALESCE(MAX(position), -1) + 1 FROM carpool_drivers WHERE group_id = ?`, groupID).Scan(&position); err != nil {
+		return nil, fmt.Errorf("failed to get rotation position: %w", err)
+	}
+
+	driverID := ids.New("driver")
+	_, err := s.db.Exec(`
+		INSERT INTO carpool_drivers (id, group_id, family_id, member_id, name, phone, email, position, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, driverID, groupID, driverFamilyID, req.MemberID, name, req.Phone, req.Email, position, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to add carpool driver: %w", err)
+	}
+
+	row := s.db.QueryRow(`
+		SELECT id, group_id, family_id, member_id, name, phone, email, position, created_at
+		FROM carpool_drivers WHERE id = ?
+	`, driverID)
+	return scanCarpoolDriver(row)
+}
+
+// JoinGroup adds a member of another famstack family to the group named by the join code
+func (s *CarpoolService) JoinGroup(familyID string, req *models.JoinCarpoolRequest) (*models.CarpoolGroup, error) {
+	var groupID string
+	err := s.db.QueryRow(`SELECT id FROM carpool_groups WHERE join_code = ?`, strings.TrimSpace(req.JoinCode)).Scan(&groupID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("carpool group not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find carpool group: %w", err)
+	}
+
+	memberID := req.MemberID
+	if _, err := s.AddDriver(groupID, familyID, &models.AddCarpoolDriverRequest{MemberID: &memberID}); err != nil {
+		return nil, err
+	}
+
+	return s.GetGroup(groupID)
+}
+
+// RemoveDriver takes a driver out of the rotation. Their assignments and
+// reminder tasks are removed; regenerate the rotation to fill the gaps.
+func (s *CarpoolService) RemoveDriver(groupID, driverID string) error {
+	return s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			DELETE FROM tasks
+			WHERE id IN (SELECT task_id FROM carpool_assignments WHERE driver_id = ? AND task_id IS NOT NULL)
+		`, driverID)
+		if err != nil {
+			return fmt.Errorf("failed to delete driver reminder tasks: %w", err)
+		}
+
+		result, err := tx.Exec(`DELETE FROM carpool_drivers WHERE id = ? AND group_id = ?`, driverID, groupID)
+		if err != nil {
+			return fmt.Errorf("failed to remove carpool driver: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("carpool driver not found")
+		}
+
+		return nil
+	})
+}
+
+// ListAssignments returns the driving rotation between two YYYY-MM-DD dates
+func (s *CarpoolService) ListAssignments(groupID, from, to string) ([]models.CarpoolAssignment, error) {
+	rows, err := s.db.Query(`
+		SELECT a.id, a.group_id, a.drive_date, a.event_id, a.driver_id, d.name, a.task_id, a.created_at, a.updated_at
+		FROM carpool_assignments a
+		JOIN carpool_drivers d ON d.id = a.driver_id
+		WHERE a.group_id = ? AND a.drive_date >= ? AND a.drive_date <= ?
+		ORDER BY a.drive_date
+	`, groupID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query carpool assignments: %w", err)
+	}
+	defer rows.Close()
+
+	assignments := []models.CarpoolAssignment{}
+	for rows.Next() {
+		assignment, err := scanCarpoolAssignment(rows)
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, *assignment)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating carpool assignments: %w", err)
+	}
+
+	return assignments, nil
+}
+
+// GenerateRotation assigns drivers round-robin to every practice of the
+// group's activity between from and to, replacing any existing assignments in
+// that range, and gives each famstack driver a reminder task for their day.
+func (s *CarpoolService) GenerateRotation(groupID string, req *models.GenerateCarpoolRotationRequest) ([]models.CarpoolAssignment, error) {
+	validator := validation.NewValidator()
+	from, fromErr := time.Parse("2006-01-02", req.From)
+	if fromErr != nil {
+		validator.AddError("from", "from must be in YYYY-MM-DD format")
+	}
+	to, toErr := time.Parse("2006-01-02", req.To)
+	if toErr != nil {
+		validator.AddError("to", "to must be in YYYY-MM-DD format")
+	}
+	if fromErr == nil && toErr == nil && to.Before(from) {
+		validator.AddError("to", "to must not be before from")
+	}
+	if err := validator.ToError(); err != nil {
+		return nil, err
+	}
+
+	group, err := s.GetGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if len(group.Drivers) == 0 {
+		return nil, validation.ValidationErrors{{Field: "drivers", Message: "add at least one driver before generating a rotation"}}
+	}
+
+	activity, err := s.activities.GetActivity(group.ActivityID)
+	if err != nil {
+		return nil, err
+	}
+
+	practices, err := s.practicesByDate(activity, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	dates := make([]string, 0, len(practices))
+	for date := range practices {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	// Continue the rotation from whoever drove last before this range
+	var lastDriverID string
+	err = s.db.QueryRow(`
+		SELECT driver_id FROM carpool_assignments
+		WHERE group_id = ? AND drive_date < ?
+		ORDER BY drive_date DESC LIMIT 1
+	`, groupID, req.From).Scan(&lastDriverID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to find previous driver: %w", err)
+	}
+
+	rotation := models.RotateDrivers(group.Drivers, dates, lastDriverID)
+
+	err = s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			DELETE FROM tasks
+			WHERE id IN (
+				SELECT task_id FROM carpool_assignments
+				WHERE group_id = ? AND drive_date >= ? AND drive_date <= ? AND task_id IS NOT NULL
+			)
+		`, groupID, req.From, req.To)
+		if err != nil {
+			return fmt.Errorf("failed to delete carpool reminder tasks: %w", err)
+		}
+
+		_, err = tx.Exec(`DELETE FROM carpool_assignments WHERE group_id = ? AND drive_date >= ? AND drive_date <= ?`, groupID, req.From, req.To)
+		if err != nil {
+			return fmt.Errorf("failed to clear carpool assignments: %w", err)
+		}
+
+		now := time.Now().UTC()
+		for _, date := range dates {
+			eventID := practices[date].eventID
+			_, err := tx.Exec(`
+				INSERT INTO carpool_assignments (id, group_id, drive_date, event_id, driver_id, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?)
+			`, ids.New("carpool_assignment"), groupID, date, eventID, rotation[date], now, now)
+			if err != nil {
+				return fmt.Errorf("failed to create carpool assignment: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.ListAssignments(groupID, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	for i := range assignments {
+		driver := group.Driver(assignments[i].DriverID)
+		taskID, err := s.createReminder(activity, driver, practices[assignments[i].DriveDate].start)
+		if err != nil {
+			return nil, err
+		}
+		if taskID != nil {
+			if _, err := s.db.Exec(`UPDATE carpool_assignments SET task_id = ? WHERE id = ?`, *taskID, assignments[i].ID); err != nil {
+				return nil, fmt.Errorf("failed to link carpool reminder: %w", err)
+			}
+			assignments[i].TaskID = taskID
+		}
+	}
+
+	return assignments, nil
+}
+
+// ListSwapRequests returns a group's swap requests, optionally filtered by status
+func (s *CarpoolService) ListSwapRequests(groupID, status string) ([]models.CarpoolSwapRequest, error) {
+	query := `
+		SELECT id, group_id, assignment_id, from_driver_id, to_driver_id, requested_by_family_id,
+		       status, note, created_at, resolved_at
+		FROM carpool_swap_requests WHERE group_id = ?`
+	args := []any{groupID}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY created_at DESC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query swap requests: %w", err)
+	}
+	defer rows.Close()
+
+	swaps := []models.CarpoolSwapRequest{}
+	for rows.Next() {
+		swap, err := scanCarpoolSwap(rows)
+		if err != nil {
+			return nil, err
+		}
+		swaps = append(swaps, *swap)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating swap requests: %w", err)
+	}
+
+	return swaps, nil
+}
+
+// GetSwapRequest returns a swap request by ID
+func (s *CarpoolService) GetSwapRequest(swapID string) (*models.CarpoolSwapRequest, error) {
+	row := s.db.QueryRow(`
+		SELECT id, group_id, assignment_id, from_driver_id, to_driver_id, requested_by_family_id,
+		       status, note, created_at, resolved_at
+		FROM carpool_swap_requests WHERE id = ?
+	`, swapID)
+	swap, err := scanCarpoolSwap(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("swap request not found")
+	}
+	return swap, err
+}
+
+// RequestSwap asks another driver to take over an assignment. The caller must
+// be able to act for the assigned driver.
+func (s *CarpoolService) RequestSwap(group *models.CarpoolGroup, familyID string, req *models.CreateCarpoolSwapRequest) (*models.CarpoolSwapRequest, error) {
+	assignment, err := s.getAssignment(req.AssignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if assignment.GroupID != group.ID {
+		return nil, fmt.Errorf("carpool assignment not found")
+	}
+
+	fromDriver := group.Driver(assignment.DriverID)
+	if fromDriver == nil || !group.CanActFor(familyID, fromDriver) {
+		return nil, fmt.Errorf("not allowed to swap this assignment")
+	}
+
+	validator := validation.NewValidator()
+	if group.Driver(req.ToDriverID) == nil {
+		validator.AddError("to_driver_id", "to_driver_id must be a driver in this carpool")
+	} else if req.ToDriverID == fromDriver.ID {
+		validator.AddError("to_driver_id", "to_driver_id must be a different driver")
+	}
+	validator.MaxLength("note", req.Note, 500)
+	if err := validator.ToError(); err != nil {
+		return nil, err
+	}
+
+	swapID := ids.New("swap")
+	_, err = s.db.Exec(`
+		INSERT INTO carpool_swap_requests (id, group_id, assignment_id, from_driver_id, to_driver_id,
+		                                   requested_by_family_id, status, note, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, swapID, group.ID, assignment.ID, fromDriver.ID, req.ToDriverID, familyID,
+		models.CarpoolSwapPending, strings.TrimSpace(req.Note), time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create swap request: %w", err)
+	}
+
+	return s.GetSwapRequest(swapID)
+}
+
+// RespondToSwap resolves a pending swap. The target driver's side accepts or
+// declines; the requesting family cancels. Accepting hands over the
+// assignment and moves the reminder task to the new driver.
+func (s *CarpoolService) RespondToSwap(swapID, familyID string, req *models.RespondCarpoolSwapRequest) (*models.CarpoolSwapRequest, error) {
+	validator := validation.NewValidator()
+	validator.OneOf("status", req.Status, []string{models.CarpoolSwapAccepted, models.CarpoolSwapDeclined, models.CarpoolSwapCancelled})
+	if err := validator.ToError(); err != nil {
+		return nil, err
+	}
+
+	swap, err := s.GetSwapRequest(swapID)
+	if err != nil {
+		return nil, err
+	}
+	if swap.Status != models.CarpoolSwapPending {
+		return nil, validation.ValidationErrors{{Field: "status", Message: "swap request has already been resolved"}}
+	}
+
+	group, err := s.GetGroup(swap.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	toDriver := group.Driver(swap.ToDriverID)
+	if toDriver == nil {
+		return nil, fmt.Errorf("carpool driver not found")
+	}
+
+	switch req.Status {
+	case models.CarpoolSwapCancelled:
+		if swap.RequestedByFamilyID != familyID {
+			return nil, fmt.Errorf("not allowed to respond to this swap")
+		}
+	default:
+		if !group.CanActFor(familyID, toDriver) {
+			return nil, fmt.Errorf("not allowed to respond to this swap")
+		}
+	}
+
+	_, err = s.db.Exec(`UPDATE carpool_swap_requests SET status = ?, resolved_at = ? WHERE id = ?`, req.Status, time.Now().UTC(), swapID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update swap request: %w", err)
+	}
+
+	if req.Status == models.CarpoolSwapAccepted {
+		if err := s.reassign(group, swap.AssignmentID, toDriver); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetSwapRequest(swapID)
+}
+
+// reassign hands an assignment to a new driver and moves the reminder task
+func (s *CarpoolService) reassign(group *models.CarpoolGroup, assignmentID string, driver *models.CarpoolDriver) error {
+	assignment, err := s.getAssignment(assignmentID)
+	if err != nil {
+		return err
+	}
+
+	// The reminder is a task in the previous driver's family
+	if previous := group.Driver(assignment.DriverID); assignment.TaskID != nil && previous != nil && previous.FamilyID != nil {
+		if err := s.tasks.DeleteTask(*previous.FamilyID, *assignment.TaskID); err != nil && !errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("failed to delete carpool reminder: %w", err)
+		}
+	}
+
+	activity, err := s.activities.GetActivity(group.ActivityID)
+	if err != nil {
+		return err
+	}
+	practices, err := s.practicesByDate(activity, assignment.DriveDate, assignment.DriveDate)
+	if err != nil {
+		return err
+	}
+
+	taskID, err := s.createReminder(activity, driver, practices[assignment.DriveDate].start)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`UPDATE carpool_assignments SET driver_id = ?, task_id = ?, updated_at = ? WHERE id = ?`,
+		driver.ID, taskID, time.Now().UTC(), assignment.ID)
+	if err != nil {
+		return fmt.Errorf("failed to reassign carpool: %w", err)
+	}
+	return nil
+}
+
+type carpoolPractice struct {
+	eventID *string
+	start   *time.Time
+}
+
+// practicesByDate maps each practice date in the range, in the organizer's
+// timezone, to its generated practice event
+func (s *CarpoolService) practicesByDate(activity *models.Activity, from, to string) (map[string]carpoolPractice, error) {
+	familyTimezone, err := GetFamilyTimezone(s.db, activity.FamilyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for carpool: %w", err)
+	}
+	loc, err := loadLocation(familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid family timezone %s: %w", familyTimezone, err)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT e.id, e.start_time
+		FROM unified_calendar_events e
+		JOIN activity_practice_events pe ON pe.event_id = e.id
+		WHERE pe.activity_id = ?
+	`, activity.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query practice events: %w", err)
+	}
+	defer rows.Close()
+
+	practices := make(map[string]carpoolPractice)
+	for rows.Next() {
+		var eventID string
+		var start time.Time
+		if err := rows.Scan(&eventID, &start); err != nil {
+			return nil, fmt.Errorf("failed to scan practice event: %w", err)
+		}
+		date := start.In(loc).Format("2006-01-02")
+		if date < from || date > to {
+			continue
+		}
+		practices[date] = carpoolPractice{eventID: &eventID, start: &start}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating practice events: %w", err)
+	}
+
+	return practices, nil
+}
+
+// createReminder gives a famstack driver a task in their own family for their
+// driving day. External drivers are contacted outside famstack and get none.
+func (s *CarpoolService) createReminder(activity *models.Activity, driver *models.CarpoolDriver, practiceStart *time.Time) (*string, error) {
+	if driver == nil || driver.IsExternal() || driver.FamilyID == nil {
+		return nil, nil
+	}
+
+	var dueDate *time.Time
+	if practiceStart != nil {
+		driverTimezone, err := GetFamilyTimezone(s.db, *driver.FamilyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get driver timezone: %w", err)
+		}
+		driverLoc, err := loadLocation(driverTimezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid family timezone %s: %w", driverTimezone, err)
+		}
+		local := practiceStart.In(driverLoc)
+		dueDate = &local
+	}
+
+	memberID := *driver.MemberID
+	task, err := s.tasks.CreateTask(*driver.FamilyID, memberID, &models.CreateTaskRequest{
+		Title:       "Carpool: drive to " + activity.Name,
+		Description: "You're the carpool driver for " + activity.Name + " practice.",
+		TaskType:    models.TaskTypeTodo,
+		AssignedTo:  &memberID,
+		DueDate:     dueDate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create carpool reminder: %w", err)
+	}
+	return &task.ID, nil
+}
+
+func (s *CarpoolService) getAssignment(assignmentID string) (*models.CarpoolAssignment, error) {
+	row := s.db.QueryRow(`
+		SELECT a.id, a.group_id, a.drive_date, a.event_id, a.driver_id, d.name, a.task_id, a.created_at, a.updated_at
+		FROM carpool_assignments a
+		JOIN carpool_drivers d ON d.id = a.driver_id
+		WHERE a.id = ?
+	`, assignmentID)
+	assignment, err := scanCarpoolAssignment(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("carpool assignment not found")
+	}
+	return assignment, err
+}
+
+func (s *CarpoolService) listDrivers(groupID string) ([]models.CarpoolDriver, error) {
+	rows, err := s.db.Query(`
+		SELECT id, group_id, family_id, member_id, name, phone, email, position, created_at
+		FROM carpool_drivers WHERE group_id = ?
+		ORDER BY position, created_at
+	`, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query carpool drivers: %w", err)
+	}
+	defer rows.Close()
+
+	drivers := []models.CarpoolDriver{}
+	for rows.Next() {
+		driver, err := scanCarpoolDriver(rows)
+		if err != nil {
+			return nil, err
+		}
+		drivers = append(drivers, *driver)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating carpool drivers: %w", err)
+	}
+
+	return drivers, nil
+}
+
+// generateJoinCode returns a short random code other families use to join a group
+func generateJoinCode() (string, error) {
+	bytes := make([]byte, 5)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate join code: %w", err)
+	}
+	return strings.ToUpper(hex.EncodeToString(bytes)), nil
+}
+
+func scanCarpoolGroup(scanner interface{ Scan(dest ...any) error }) (*models.CarpoolGroup, error) {
+	var group models.CarpoolGroup
+	var createdBy sql.NullString
+
+	err := scanner.Scan(&group.ID, &group.FamilyID, &group.ActivityID, &group.Name, &group.JoinCode,
+		&createdBy, &group.CreatedAt, &group.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan carpool group: %w", err)
+	}
+
+	if createdBy.Valid {
+		group.CreatedBy = &createdBy.String
+	}
+	group.Drivers = []models.CarpoolDriver{}
+	return &group, nil
+}
+
+func scanCarpoolDriver(scanner interface{ Scan(dest ...any) error }) (*models.CarpoolDriver, error) {
+	var driver models.CarpoolDriver
+	var familyID, memberID, phone, email sql.NullString
+
+	err := scanner.Scan(&driver.ID, &driver.GroupID, &familyID, &memberID, &driver.Name, &phone, &email,
+		&driver.Position, &driver.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan carpool driver: %w", err)
+	}
+
+	if familyID.Valid {
+		driver.FamilyID = &familyID.String
+	}
+	if memberID.Valid {
+		driver.MemberID = &memberID.String
+	}
+	driver.Phone = phone.String
+	driver.Email = email.String
+	return &driver, nil
+}
+
+func scanCarpoolAssignment(scanner interface{ Scan(dest ...any) error }) (*models.CarpoolAssignment, error) {
+	var assignment models.CarpoolAssignment
+	var eventID, taskID sql.NullString
+
+	err := scanner.Scan(&assignment.ID, &assignment.GroupID, &assignment.DriveDate, &eventID, &assignment.DriverID,
+		&assignment.DriverName, &taskID, &assignment.CreatedAt, &assignment.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan carpool assignment: %w", err)
+	}
+
+	if eventID.Valid {
+		assignment.EventID = &eventID.String
+	}
+	if taskID.Valid {
+		assignment.TaskID = &taskID.String
+	}
+	return &assignment, nil
+}
+
+func scanCarpoolSwap(scanner interface{ Scan(dest ...any) error }) (*models.CarpoolSwapRequest, error) {
+	var swap models.CarpoolSwapRequest
+	var note sql.NullString
+	var resolvedAt sql.NullTime
+
+	err := scanner.Scan(&swap.ID, &swap.GroupID, &swap.AssignmentID, &swap.FromDriverID, &swap.ToDriverID,
+		&swap.RequestedByFamilyID, &swap.Status, &note, &swap.CreatedAt, &resolvedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan swap request: %w", err)
+	}
+
+	swap.Note = note.String
+	if resolvedAt.Valid {
+		swap.ResolvedAt = &resolvedAt.Time
+	}
+	return &swap, nil
+}
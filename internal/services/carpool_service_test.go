@@ -0,0 +1,112 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateDrivers_ContinuesAfterLastDriver(t *testing.T) {
+	drivers := []models.CarpoolDriver{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	dates := []string{"2026-03-03", "2026-03-05", "2026-03-10", "2026-03-12"}
+
+	rotation := models.RotateDrivers(drivers, dates, "")
+	assert.Equal(t, "a", rotation["2026-03-03"])
+	assert.Equal(t, "b", rotation["2026-03-05"])
+	assert.Equal(t, "c", rotation["2026-03-10"])
+	assert.Equal(t, "a", rotation["2026-03-12"])
+
+	rotation = models.RotateDrivers(drivers, dates[:2], "b")
+	assert.Equal(t, "c", rotation["2026-03-03"])
+	assert.Equal(t, "a", rotation["2026-03-05"])
+}
+
+func TestCarpoolService_RotationAndSwapAcrossFamilies(t *testing.T) {
+	db := setupTestDB(t)
+	tasks := NewTasksService(db)
+	activities := NewActivitiesService(db, tasks)
+	service := NewCarpoolService(db, tasks, activities)
+	familyID, parentID, childID := seedActivitiesFamily(t, activities)
+
+	otherFamilyID := "fam_neighbors"
+	otherParentID := "member_neighbor"
+	_, err := db.Exec(`INSERT INTO families (id, name, timezone) VALUES (?, ?, ?)`, otherFamilyID, "Neighbors", "UTC")
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, is_active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		otherParentID, otherFamilyID, "Nia", "Neighbor", "adult", true, time.Now(), time.Now())
+	require.NoError(t, err)
+
+	startTime := "17:00"
+	endTime := "18:00"
+	activity, err := activities.CreateActivity(familyID, parentID, &models.CreateActivityRequest{
+		MemberID:          childID,
+		Name:              "Soccer",
+		SeasonStart:       "2026-03-02",
+		SeasonEnd:         "2026-03-15",
+		PracticeDays:      []string{"tuesday", "thursday"},
+		PracticeStartTime: &startTime,
+		PracticeEndTime:   &endTime,
+	})
+	require.NoError(t, err)
+
+	group, err := service.CreateGroup(familyID, parentID, &models.CreateCarpoolGroupRequest{
+		ActivityID:     activity.ID,
+		Name:           "Tigers carpool",
+		DriverMemberID: &parentID,
+	})
+	require.NoError(t, err)
+	require.Len(t, group.Drivers, 1)
+	assert.NotEmpty(t, group.JoinCode)
+
+	// The neighbors join with the code and show up in the rotation
+	group, err = service.JoinGroup(otherFamilyID, &models.JoinCarpoolRequest{JoinCode: group.JoinCode, MemberID: otherParentID})
+	require.NoError(t, err)
+	require.Len(t, group.Drivers, 2)
+	assert.True(t, group.HasFamily(otherFamilyID))
+
+	neighborGroups, err := service.ListGroups(otherFamilyID)
+	require.NoError(t, err)
+	require.Len(t, neighborGroups, 1)
+
+	assignments, err := service.GenerateRotation(group.ID, &models.GenerateCarpoolRotationRequest{From: "2026-03-01", To: "2026-03-15"})
+	require.NoError(t, err)
+	require.Len(t, assignments, 4)
+	assert.Equal(t, group.Drivers[0].ID, assignments[0].DriverID)
+	assert.Equal(t, group.Drivers[1].ID, assignments[1].DriverID)
+	require.NotNil(t, assignments[1].TaskID)
+
+	// The neighbor's reminder lives in their own family
+	reminder, err := tasks.GetTask(*assignments[1].TaskID)
+	require.NoError(t, err)
+	assert.Equal(t, otherFamilyID, reminder.FamilyID)
+	assert.Equal(t, "Carpool: drive to Soccer", reminder.Title)
+
+	// The neighbors ask the organizer to take their day, and the organizer accepts
+	swap, err := service.RequestSwap(group, otherFamilyID, &models.CreateCarpoolSwapRequest{
+		AssignmentID: assignments[1].ID,
+		ToDriverID:   group.Drivers[0].ID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.CarpoolSwapPending, swap.Status)
+
+	_, err = service.RespondToSwap(swap.ID, otherFamilyID, &models.RespondCarpoolSwapRequest{Status: models.CarpoolSwapAccepted})
+	require.Error(t, err)
+
+	swap, err = service.RespondToSwap(swap.ID, familyID, &models.RespondCarpoolSwapRequest{Status: models.CarpoolSwapAccepted})
+	require.NoError(t, err)
+	assert.Equal(t, models.CarpoolSwapAccepted, swap.Status)
+
+	updated, err := service.ListAssignments(group.ID, "2026-03-01", "2026-03-15")
+	require.NoError(t, err)
+	assert.Equal(t, group.Drivers[0].ID, updated[1].DriverID)
+	require.NotNil(t, updated[1].TaskID)
+	reminder, err = tasks.GetTask(*updated[1].TaskID)
+	require.NoError(t, err)
+	assert.Equal(t, familyID, reminder.FamilyID)
+	_, err = tasks.GetTask(*assignments[1].TaskID)
+	require.Error(t, err)
+}
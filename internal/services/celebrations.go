@@ -0,0 +1,416 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"famstack/internal/models"
+	"famstack/internal/recurrence"
+)
+
+// celebrationRule is the rule every celebration series repeats by
+const celebrationRule = "FREQ=YEARLY"
+
+// celebrationColor is the color of celebrations no color rule matches
+const celebrationColor = "#ec4899"
+
+// celebration is a yearly series the family's members' dates call for
+type celebration struct {
+	kind      string
+	key       string    // the member for a birthday, the date for an anniversary
+	date      time.Time // the date celebrated, at midnight in the family's timezone
+	title     string
+	memberIDs []string
+}
+
+// celebrationRow is a celebration series already on the calendar
+type celebrationRow struct {
+	id      string
+	title   string
+	start   time.Time
+	rule    string
+	exdates string
+}
+
+// CelebrationSyncResult counts what a celebration sync changed
+type CelebrationSyncResult struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Removed   int `json:"removed"`
+}
+
+// celebrationDate reads a member's YYYY-MM-DD date as midnight in loc. A
+// February 29 is celebrated on February 28, so it comes round every year.
+func celebrationDate(value string, loc *time.Location) (time.Time, bool) {
+	date, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if date.Month() == time.February && date.Day() == 29 {
+		date = date.AddDate(0, 0, -1)
+	}
+	return date, true
+}
+
+// familyCelebrations returns the celebrations the family's active members'
+// birthdates and anniversaries call for. Members who share an anniversary
+// share its series.
+func (s *CalendarService) familyCelebrations(familyID string, loc *time.Location) ([]celebration, error) {
+	rows, err := s.db.Query(`
+		SELECT id, first_name, birthdate, anniversary
+		FROM family_members
+		WHERE family_id = ? AND is_active = true AND (birthdate IS NOT NULL OR anniversary IS NOT NULL)
+		ORDER BY display_order ASC, created_at ASC
+	`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query member dates: %w", err)
+	}
+	defer rows.Close()
+
+	var celebrations []celebration
+	anniversaries := make(map[string]*celebration)
+	anniversaryNames := make(map[string][]string)
+	var anniversaryKeys []string
+	for rows.Next() {
+		var memberID, firstName string
+		var birthdate, anniversary sql.NullString
+		if err := rows.Scan(&memberID, &firstName, &birthdate, &anniversary); err != nil {
+			return nil, fmt.Errorf("failed to scan member dates: %w", err)
+		}
+
+		if date, ok := celebrationDate(birthdate.String, loc); birthdate.Valid && ok {
+			celebrations = append(celebrations, celebration{
+				kind:      models.CelebrationBirthday,
+				key:       memberID,
+				date:      date,
+				title:     firstName + "'s birthday",
+				memberIDs: []string{memberID},
+			})
+		}
+		if date, ok := celebrationDate(anniversary.String, loc); anniversary.Valid && ok {
+			key := anniversary.String
+			if anniversaries[key] == nil {
+				anniversaries[key] = &celebration{kind: models.CelebrationAnniversary, key: key, date: date}
+				anniversaryKeys = append(anniversaryKeys, key)
+			}
+			anniversaries[key].memberIDs = append(anniversaries[key].memberIDs, memberID)
+			anniversaryNames[key] = append(anniversaryNames[key], firstName)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating member dates: %w", err)
+	}
+
+	for _, key := range anniversaryKeys {
+		anniversary := anniversaries[key]
+		anniversary.title = joinNames(anniversaryNames[key]) + "'s anniversary"
+		celebrations = append(celebrations, *anniversary)
+	}
+	return celebrations, nil
+}
+
+// celebrationRows returns the family's celebration series, by kind and key
+func (s *CalendarService) celebrationRows(familyID string) (map[string]celebrationRow, error) {
+	rows, err := s.db.Query(`
+		SELECT id, celebration_kind, celebration_key, title, start_time, recurrence_rule, recurrence_exdates
+		FROM unified_calendar_events
+		WHERE family_id = ? AND celebration_kind IS NOT NULL
+	`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query celebration events: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]celebrationRow)
+	for rows.Next() {
+		var row celebrationRow
+		var kind, key string
+		var rule sql.NullString
+		if err := rows.Scan(&row.id, &kind, &key, &row.title, &row.start, &rule, &row.exdates); err != nil {
+			return nil, fmt.Errorf("failed to scan celebration event: %w", err)
+		}
+		row.rule = rule.String
+		existing[kind+":"+key] = row
+	}
+	return existing, rows.Err()
+}
+
+// SyncCelebrations brings the family's birthday and anniversary series in
+// step with its members: a series for each date set, titled after the
+// members it celebrates. Occurrences skipped from a series stay skipped
+// unless its date changes.
+func (s *CalendarService) SyncCelebrations(familyID string) (*CelebrationSyncResult, error) {
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for celebrations: %w", err)
+	}
+	loc, err := time.LoadLocation(familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load family timezone: %w", err)
+	}
+
+	wanted, err := s.familyCelebrations(familyID, loc)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.celebrationRows(familyID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.colorRules.ListRules(familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event color rules: %w", err)
+	}
+
+	result := &CelebrationSyncResult{}
+	now := time.Now().UTC()
+	err = s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		for _, c := range wanted {
+			start := c.date.UTC()
+			end := c.date.AddDate(0, 0, 1).UTC()
+			row, found := existing[c.kind+":"+c.key]
+			delete(existing, c.kind+":"+c.key)
+
+			switch {
+			case found && row.title == c.title && row.start.Equal(start) && row.rule == celebrationRule:
+				result.Unchanged++
+			case found:
+				// Skipped occurrences belong to the old date
+				exdates := row.exdates
+				if !row.start.Equal(start) {
+					exdates = ""
+				}
+				if _, err := tx.Exec(`
+					UPDATE unified_calendar_events
+					SET title = ?, start_time = ?, end_time = ?, all_day = ?, recurrence_rule = ?,
+						recurrence_exdates = ?, status = ?, updated_at = ?
+					WHERE id = ?
+				`, c.title, start, end, true, celebrationRule, exdates, models.UnifiedEventStatusActive, now, row.id); err != nil {
+					return fmt.Errorf("failed to update %s %s: %w", c.kind, c.key, err)
+				}
+				result.Updated++
+			default:
+				row.id = generateUnifiedEventID()
+				color, category := celebrationColor, ""
+				if rule := models.MatchEventColorRule(rules, models.EventRuleSample{
+					Title: c.title, Source: models.EventSourceFamstack, EventType: string(models.EventTypeEvent),
+				}); rule != nil {
+					color, category = ruleColorAndCategory(rule, "")
+				}
+				if _, err := tx.Exec(`
+					INSERT INTO unified_calendar_events (id, family_id, title, start_time, end_time, all_day,
+														event_type, color, category, status, recurrence_rule,
+														recurrence_exdates, celebration_kind, celebration_key,
+														created_at, updated_at)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				`, row.id, familyID, c.title, start, end, true, models.EventTypeEvent, color, category,
+					models.UnifiedEventStatusActive, celebrationRule, "", c.kind, c.key, now, now); err != nil {
+					return fmt.Errorf("failed to insert %s %s: %w", c.kind, c.key, err)
+				}
+				result.Created++
+			}
+			if err := syncEventAttendees(tx, row.id, c.memberIDs); err != nil {
+				return err
+			}
+		}
+
+		for key, row := range existing {
+			if _, err := tx.Exec(`DELETE FROM unified_calendar_events WHERE id = ?`, row.id); err != nil {
+				return fmt.Errorf("failed to remove celebration %s: %w", key, err)
+			}
+			result.Removed++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync celebrations: %w", err)
+	}
+
+	if result.Created+result.Updated+result.Removed > 0 {
+		s.publishChange(familyID)
+	}
+	return result, nil
+}
+
+// memberCelebrations returns the series of a member's birthday and
+// anniversary, with the dates they celebrate, by kind
+func (s *CalendarService) memberCelebrations(familyID, memberID string) (map[string]string, map[string]string, error) {
+	var birthdate, anniversary sql.NullString
+	err := s.db.QueryRow(`SELECT birthdate, anniversary FROM family_members WHERE id = ? AND family_id = ?`,
+		memberID, familyID).Scan(&birthdate, &anniversary)
+	if err == sql.ErrNoRows {
+		return nil, nil, fmt.Errorf("family member not found")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get member dates: %w", err)
+	}
+
+	dates := make(map[string]string)
+	keys := make(map[string]string)
+	if birthdate.Valid {
+		dates[models.CelebrationBirthday] = birthdate.String
+		keys[models.CelebrationBirthday] = memberID
+	}
+	if anniversary.Valid {
+		dates[models.CelebrationAnniversary] = anniversary.String
+		keys[models.CelebrationAnniversary] = anniversary.String
+	}
+
+	eventIDs := make(map[string]string)
+	for kind, key := range keys {
+		var eventID string
+		err := s.db.QueryRow(`
+			SELECT id FROM unified_calendar_events
+			WHERE family_id = ? AND celebration_kind = ? AND celebration_key = ?
+		`, familyID, kind, key).Scan(&eventID)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get celebration event: %w", err)
+		}
+		eventIDs[kind] = eventID
+	}
+	return dates, eventIDs, nil
+}
+
+// ListCelebrations returns a member's birthday and anniversary as they stand
+// on the calendar, with the years skipped
+func (s *CalendarService) ListCelebrations(familyID, memberID string) ([]models.Celebration, error) {
+	dates, eventIDs, err := s.memberCelebrations(familyID, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	celebrations := []models.Celebration{}
+	for _, kind := range []string{models.CelebrationBirthday, models.CelebrationAnniversary} {
+		eventID, ok := eventIDs[kind]
+		if !ok {
+			continue
+		}
+		series, err := s.GetStoredUnifiedCalendarEvent(eventID)
+		if err != nil {
+			return nil, err
+		}
+		celebration, err := celebrationOf(series, dates[kind])
+		if err != nil {
+			return nil, err
+		}
+		celebrations = append(celebrations, *celebration)
+	}
+	return celebrations, nil
+}
+
+// SetCelebrationSkipped skips the occurrence of a member's birthday or
+// anniversary in the given year, or brings it back. Skipping an anniversary
+// skips it for every member who shares it.
+func (s *CalendarService) SetCelebrationSkipped(familyID, memberID, kind string, year int, skipped bool) (*models.Celebration, error) {
+	if kind != models.CelebrationBirthday && kind != models.CelebrationAnniversary {
+		return nil, fmt.Errorf("invalid celebration %q (expected birthday or anniversary)", kind)
+	}
+	dates, eventIDs, err := s.memberCelebrations(familyID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := dates[kind]; !ok {
+		return nil, fmt.Errorf("member has no %s", kind)
+	}
+	if _, ok := eventIDs[kind]; !ok {
+		// The date was set since the last sync
+		if _, err := s.SyncCelebrations(familyID); err != nil {
+			return nil, err
+		}
+		if _, eventIDs, err = s.memberCelebrations(familyID, memberID); err != nil {
+			return nil, err
+		}
+		if _, ok := eventIDs[kind]; !ok {
+			return nil, fmt.Errorf("celebration not found")
+		}
+	}
+
+	series, err := s.GetStoredUnifiedCalendarEvent(eventIDs[kind])
+	if err != nil {
+		return nil, err
+	}
+	rule, skippedStarts, err := seriesRule(series)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Date(year, series.StartTime.Month(), series.StartTime.Day(), 0, 0, 0, 0, series.StartTime.Location())
+	if start.Year() != year || !rule.Includes(series.StartTime, start) {
+		return nil, fmt.Errorf("invalid year: there is no %s in %d", kind, year)
+	}
+
+	if skippedStarts[start.Unix()] != skipped {
+		if skipped {
+			series.RecurrenceExdates = withExdate(series.RecurrenceExdates, start)
+		} else {
+			series.RecurrenceExdates = filterExdates(series.RecurrenceExdates, func(t time.Time) bool { return !t.Equal(start) })
+		}
+		err = s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+			if err := updateUnifiedEventRow(tx, series, time.Now().UTC()); err != nil {
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update celebration: %w", err)
+		}
+		s.publishChange(familyID)
+	}
+
+	return celebrationOf(series, dates[kind])
+}
+
+// celebrationOf describes a celebration series for the member whose date it
+// celebrates
+func celebrationOf(series *models.UnifiedCalendarEvent, date string) (*models.Celebration, error) {
+	exdates, err := recurrence.ParseTimes(series.RecurrenceExdates)
+	if err != nil {
+		return nil, fmt.Errorf("event %s has invalid exception dates: %w", series.ID, err)
+	}
+	years := make([]int, 0, len(exdates))
+	for _, exdate := range exdates {
+		years = append(years, exdate.In(series.StartTime.Location()).Year())
+	}
+	sort.Ints(years)
+	return &models.Celebration{
+		Kind:         stringValue(series.CelebrationKind),
+		Date:         date,
+		EventID:      series.ID,
+		SkippedYears: years,
+	}, nil
+}
+
+// celebrateOccurrence sets an occurrence's age, counted from the year its
+// series starts, and puts the age in its title: "Riley's 9th birthday"
+func celebrateOccurrence(series, occurrence *models.UnifiedCalendarEvent) {
+	age := occurrence.StartTime.Year() - series.StartTime.Year()
+	occurrence.Age = &age
+
+	suffix := " " + stringValue(series.CelebrationKind)
+	if age > 0 && strings.HasSuffix(series.Title, suffix) {
+		occurrence.Title = strings.TrimSuffix(series.Title, suffix) + " " + ordinal(age) + suffix
+	}
+}
+
+// ordinal writes n as 1st, 2nd, 3rd, 4th, ... 11th, 12th, 13th, ... 21st
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrdinal(t *testing.T) {
+	for n, want := range map[int]string{1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 13: "13th", 21: "21st", 102: "102nd", 111: "111th"} {
+		assert.Equal(t, want, ordinal(n))
+	}
+}
+
+func TestCelebrationOccurrences(t *testing.T) {
+	kind := models.CelebrationBirthday
+	rule := celebrationRule
+	series := models.UnifiedCalendarEvent{
+		ID:              "unified_event_birthday",
+		Title:           "Riley's birthday",
+		StartTime:       time.Date(2016, 6, 12, 0, 0, 0, 0, time.UTC),
+		EndTime:         time.Date(2016, 6, 13, 0, 0, 0, 0, time.UTC),
+		AllDay:          true,
+		RecurrenceRule:  &rule,
+		CelebrationKind: &kind,
+	}
+
+	events, err := expandRecurringEvents([]models.UnifiedCalendarEvent{series},
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "Riley's 9th birthday", events[0].Title)
+	require.NotNil(t, events[0].Age)
+	assert.Equal(t, 9, *events[0].Age)
+	assert.Equal(t, "Riley's 10th birthday", events[1].Title)
+
+	birth, err := occurrenceOf(&series, series.StartTime)
+	require.NoError(t, err)
+	assert.Equal(t, "Riley's birthday", birth.Title, "the day itself gets no ordinal")
+
+	date, ok := celebrationDate("2012-02-29", time.UTC)
+	require.True(t, ok)
+	assert.Equal(t, "2012-02-28", date.Format("2006-01-02"), "leap day birthdays come round every year")
+}
+
+func TestSyncCelebrations(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	members := NewFamilyMemberService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	second := 1
+	partner, err := members.CreateFamilyMember(familyID, &models.CreateFamilyMemberRequest{
+		FirstName: "Alex", LastName: "Member", MemberType: models.MemberTypeAdult, DisplayOrder: &second,
+	})
+	require.NoError(t, err)
+
+	future := time.Now().AddDate(1, 0, 0).Format("2006-01-02")
+	_, err = members.UpdateFamilyMember(memberID, &models.UpdateFamilyMemberRequest{Birthdate: &future})
+	assert.Error(t, err, "a birthdate can't be in the future")
+
+	birthdate, wedding := "1985-03-14", "2010-09-04"
+	_, err = members.UpdateFamilyMember(memberID, &models.UpdateFamilyMemberRequest{Birthdate: &birthdate, Anniversary: &wedding})
+	require.NoError(t, err)
+	_, err = members.UpdateFamilyMember(partner.ID, &models.UpdateFamilyMemberRequest{Anniversary: &wedding})
+	require.NoError(t, err)
+
+	result, err := service.SyncCelebrations(familyID)
+	require.NoError(t, err)
+	assert.Equal(t, CelebrationSyncResult{Created: 2}, *result, "the shared anniversary is one series")
+
+	result, err = service.SyncCelebrations(familyID)
+	require.NoError(t, err)
+	assert.Equal(t, CelebrationSyncResult{Unchanged: 2}, *result)
+
+	events, err := service.GetUnifiedCalendarEvents(familyID,
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "Bulk's 40th birthday", events[0].Title)
+	assert.True(t, events[0].AllDay)
+	assert.Equal(t, "Bulk and Alex's 15th anniversary", events[1].Title)
+	assert.Len(t, events[1].Attendees, 2)
+
+	celebration, err := service.SetCelebrationSkipped(familyID, partner.ID, models.CelebrationAnniversary, 2025, true)
+	require.NoError(t, err)
+	assert.Equal(t, []int{2025}, celebration.SkippedYears)
+	_, err = service.SetCelebrationSkipped(familyID, partner.ID, models.CelebrationBirthday, 2025, true)
+	assert.ErrorContains(t, err, "member has no birthday")
+
+	// Skips survive a sync that leaves the date alone
+	name := "Sam"
+	_, err = members.UpdateFamilyMember(memberID, &models.UpdateFamilyMemberRequest{FirstName: &name})
+	require.NoError(t, err)
+	result, err = service.SyncCelebrations(familyID)
+	require.NoError(t, err)
+	assert.Equal(t, CelebrationSyncResult{Updated: 2}, *result)
+
+	celebrations, err := service.ListCelebrations(familyID, memberID)
+	require.NoError(t, err)
+	require.Len(t, celebrations, 2)
+	assert.Equal(t, models.CelebrationBirthday, celebrations[0].Kind)
+	assert.Equal(t, wedding, celebrations[1].Date)
+	assert.Equal(t, []int{2025}, celebrations[1].SkippedYears)
+
+	events, err = service.GetUnifiedCalendarEvents(familyID,
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "Sam's 40th birthday", events[0].Title)
+
+	celebration, err = service.SetCelebrationSkipped(familyID, memberID, models.CelebrationAnniversary, 2025, false)
+	require.NoError(t, err)
+	assert.Empty(t, celebration.SkippedYears)
+
+	// Clearing the dates removes the series
+	cleared := ""
+	_, err = members.UpdateFamilyMember(memberID, &models.UpdateFamilyMemberRequest{Birthdate: &cleared, Anniversary: &cleared})
+	require.NoError(t, err)
+	require.NoError(t, members.DeleteFamilyMember(partner.ID))
+	result, err = service.SyncCelebrations(familyID)
+	require.NoError(t, err)
+	assert.Equal(t, CelebrationSyncResult{Removed: 2}, *result)
+}
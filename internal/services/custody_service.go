@@ -0,0 +1,572 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/ids"
+	"famstack/internal/models"
+	"famstack/internal/validation"
+)
+
+// maxCustodyCalendarDays caps the range of a custody calendar request
+const maxCustodyCalendarDays = 92
+
+// CustodyService manages custody schedules and overrides for members who
+// split time between households, and answers who is here on a given day
+type CustodyService struct {
+	db *database.Fascade
+}
+
+// NewCustodyService creates a new custody service
+func NewCustodyService(db *database.Fascade) *CustodyService {
+	return &CustodyService{db: db}
+}
+
+const custodyScheduleColumns = `id, family_id, member_id, label, days_of_week, interval_weeks, anchor_date, start_date, end_date, created_at, updated_at`
+
+// ListSchedules returns a family's custody schedules
+func (s *CustodyService) ListSchedules(familyID string) ([]models.CustodySchedule, error) {
+	rows, err := s.db.Query(`
+		SELECT `+custodyScheduleColumns+`
+		FROM custody_schedules
+		WHERE family_id = ?
+		ORDER BY member_id, created_at
+	`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query custody schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []models.CustodySchedule{}
+	for rows.Next() {
+		schedule, err := scanCustodySchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating custody schedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// GetSchedule returns a custody schedule by ID
+func (s *CustodyService) GetSchedule(scheduleID string) (*models.CustodySchedule, error) {
+	row := s.db.QueryRow(`SELECT `+custodyScheduleColumns+` FROM custody_schedules WHERE id = ?`, scheduleID)
+	schedule, err := scanCustodySchedule(row)
+	if err == sql.ErrNoRows {
+		return nil, notFound("custody schedule")
+	}
+	return schedule, err
+}
+
+// CreateSchedule adds a custody schedule for a family member
+func (s *CustodyService) CreateSchedule(familyID string, req *models.CreateCustodyScheduleRequest) (*models.CustodySchedule, error) {
+	schedule := models.CustodySchedule{
+		Label:         strings.TrimSpace(req.Label),
+		DaysOfWeek:    normalizeWeekdays(req.DaysOfWeek),
+		IntervalWeeks: req.IntervalWeeks,
+		AnchorDate:    req.AnchorDate,
+	}
+	if req.StartDate != nil {
+		schedule.StartDate = optionalString(*req.StartDate)
+	}
+	if req.EndDate != nil {
+		schedule.EndDate = optionalString(*req.EndDate)
+	}
+	if schedule.IntervalWeeks == 0 {
+		schedule.IntervalWeeks = 1
+	}
+	if schedule.AnchorDate == "" {
+		schedule.AnchorDate = time.Now().UTC().Format("2006-01-02")
+	}
+
+	if err := models.ValidateCustodySchedule(schedule.Label, schedule.DaysOfWeek, schedule.IntervalWeeks, schedule.AnchorDate, schedule.StartDate, schedule.EndDate); err != nil {
+		return nil, err
+	}
+	if err := s.requireFamilyMember(familyID, req.MemberID); err != nil {
+		return nil, err
+	}
+
+	daysJSON, err := json.Marshal(schedule.DaysOfWeek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal days_of_week: %w", err)
+	}
+
+	scheduleID := ids.New("custody")
+	now := time.Now().UTC()
+
+	_, err = s.db.Exec(`
+		INSERT INTO custody_schedules (id, family_id, member_id, label, days_of_week, interval_weeks, anchor_date, start_date, end_date, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, scheduleID, familyID, req.MemberID, schedule.Label, string(daysJSON), schedule.IntervalWeeks, schedule.AnchorDate,
+		schedule.StartDate, schedule.EndDate, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create custody schedule: %w", err)
+	}
+
+	return s.GetSchedule(scheduleID)
+}
+
+// UpdateSchedule applies the provided fields to a custody schedule
+func (s *CustodyService) UpdateSchedule(scheduleID string, req *models.UpdateCustodyScheduleRequest) (*models.CustodySchedule, error) {
+	existing, err := s.GetSchedule(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := *existing
+	if req.Label != nil {
+		merged.Label = strings.TrimSpace(*req.Label)
+	}
+	if req.DaysOfWeek != nil {
+		merged.DaysOfWeek = normalizeWeekdays(*req.DaysOfWeek)
+	}
+	if req.IntervalWeeks != nil {
+		merged.IntervalWeeks = *req.IntervalWeeks
+	}
+	if req.AnchorDate != nil {
+		merged.AnchorDate = *req.AnchorDate
+	}
+	if req.StartDate != nil {
+		merged.StartDate = optionalString(*req.StartDate)
+	}
+	if req.EndDate != nil {
+		merged.EndDate = optionalString(*req.EndDate)
+	}
+
+	if err := models.ValidateCustodySchedule(merged.Label, merged.DaysOfWeek, merged.IntervalWeeks, merged.AnchorDate, merged.StartDate, merged.EndDate); err != nil {
+		return nil, err
+	}
+
+	daysJSON, err := json.Marshal(merged.DaysOfWeek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal days_of_week: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE custody_schedules
+		SET label = ?, days_of_week = ?, interval_weeks = ?, anchor_date = ?, start_date = ?, end_date = ?, updated_at = ?
+		WHERE id = ?
+	`, merged.Label, string(daysJSON), merged.IntervalWeeks, merged.AnchorDate, merged.StartDate, merged.EndDate, time.Now().UTC(), scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update custody schedule: %w", err)
+	}
+
+	return s.GetSchedule(scheduleID)
+}
+
+// DeleteSchedule removes a custody schedule
+func (s *CustodyService) DeleteSchedule(scheduleID string) error {
+	result, err := s.db.Exec(`DELETE FROM custody_schedules WHERE id = ?`, scheduleID)
+	if err != nil {
+		return fmt.Errorf("failed to delete custody schedule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFound("custody schedule")
+	}
+
+	return nil
+}
+
+// ListOverrides returns a family's custody overrides between two YYYY-MM-DD dates, inclusive
+func (s *CustodyService) ListOverrides(familyID, startDate, endDate string) ([]models.CustodyOverride, error) {
+	rows, err := s.db.Query(`
+		SELECT id, family_id, member_id, date, present, note, created_at
+		FROM custody_overrides
+		WHERE family_id = ? AND date >= ? AND date <= ?
+		ORDER BY date, member_id
+	`, familyID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query custody overrides: %w", err)
+	}
+	defer rows.Close()
+
+	overrides := []models.CustodyOverride{}
+	for rows.Next() {
+		var override models.CustodyOverride
+		if err := rows.Scan(&override.ID, &override.FamilyID, &override.MemberID, &override.Date, &override.Present, &override.Note, &override.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan custody override: %w", err)
+		}
+		overrides = append(overrides, override)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating custody overrides: %w", err)
+	}
+
+	return overrides, nil
+}
+
+// SetOverride creates or replaces the override for a member's date
+func (s *CustodyService) SetOverride(familyID string, req *models.SetCustodyOverrideRequest) (*models.CustodyOverride, error) {
+	validator := validation.NewValidator()
+	validator.Required("member_id", req.MemberID)
+	if _, err := time.Parse("2006-01-02", req.Date); err != nil {
+		validator.AddError("date", "date must be in YYYY-MM-DD format")
+	}
+	validator.MaxLength("note", req.Note, 255)
+	if err := validator.ToError(); err != nil {
+		return nil, err
+	}
+	if err := s.requireFamilyMember(familyID, req.MemberID); err != nil {
+		return nil, err
+	}
+
+	overrideID := ids.New("custody_override")
+	_, err := s.db.Exec(`
+		INSERT INTO custody_overrides (id, family_id, member_id, date, present, note, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(member_id, date) DO UPDATE SET
+			present = excluded.present,
+			note = excluded.note
+	`, overrideID, familyID, req.MemberID, req.Date, req.Present, strings.TrimSpace(req.Note), time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to set custody override: %w", err)
+	}
+
+	var override models.CustodyOverride
+	err = s.db.QueryRow(`
+		SELECT id, family_id, member_id, date, present, note, created_at
+		FROM custody_overrides WHERE member_id = ? AND date = ?
+	`, req.MemberID, req.Date).Scan(&override.ID, &override.FamilyID, &override.MemberID, &override.Date, &override.Present, &override.Note, &override.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custody override: %w", err)
+	}
+
+	return &override, nil
+}
+
+// GetOverride returns a custody override by ID
+func (s *CustodyService) GetOverride(overrideID string) (*models.CustodyOverride, error) {
+	var override models.CustodyOverride
+	err := s.db.QueryRow(`
+		SELECT id, family_id, member_id, date, present, note, created_at
+		FROM custody_overrides WHERE id = ?
+	`, overrideID).Scan(&override.ID, &override.FamilyID, &override.MemberID, &override.Date, &override.Present, &override.Note, &override.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, notFound("custody override")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custody override: %w", err)
+	}
+	return &override, nil
+}
+
+// DeleteOverride removes a custody override
+func (s *CustodyService) DeleteOverride(overrideID string) error {
+	result, err := s.db.Exec(`DELETE FROM custody_overrides WHERE id = ?`, overrideID)
+	if err != nil {
+		return fmt.Errorf("failed to delete custody override: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFound("custody override")
+	}
+
+	return nil
+}
+
+// PresenceChecker loads the family's custody data for [startDate, endDate]
+// once and returns a function answering whether a member is here on a date.
+// Task generation uses it to skip days a child is at the other household.
+func (s *CustodyService) PresenceChecker(familyID string, startDate, endDate time.Time) (func(memberID string, date time.Time) bool, error) {
+	schedules, err := s.ListSchedules(familyID)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.ListOverrides(familyID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(memberID string, date time.Time) bool {
+		present, _ := models.MemberPresentOn(schedules, overrides, memberID, date)
+		return present
+	}, nil
+}
+
+// RemoveAwayDayChores deletes a member's pending, schedule-generated tasks due
+// from the given day onward on days they're now at the other household. Tasks
+// are generated ahead of time, so custody changes call this to catch up; days
+// the member gains are filled in by the next generation run.
+func (s *CustodyService) RemoveAwayDayChores(familyID, memberID string, from time.Time) (int, error) {
+	fromDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get family timezone: %w", err)
+	}
+	// Due dates are stored in UTC; presence goes by the family's days
+	fromUTC, err := ConvertToUTC(fromDay, familyTimezone)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := s.db.Query(`
+		SELECT id, due_date FROM tasks
+		WHERE family_id = ? AND assigned_to = ? AND schedule_id IS NOT NULL
+		  AND status = 'pending' AND due_date >= ?
+	`, familyID, memberID, fromUTC.Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to query scheduled tasks: %w", err)
+	}
+
+	type dueTask struct {
+		id  string
+		due time.Time
+	}
+	var tasks []dueTask
+	lastDue := fromDay
+	for rows.Next() {
+		var task dueTask
+		if err := rows.Scan(&task.id, &task.due); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan scheduled task: %w", err)
+		}
+		if task.due, err = ConvertFromUTC(task.due, familyTimezone); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		tasks = append(tasks, task)
+		if task.due.After(lastDue) {
+			lastDue = task.due
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating scheduled tasks: %w", err)
+	}
+	if len(tasks) == 0 {
+		return 0, nil
+	}
+
+	isPresent, err := s.PresenceChecker(familyID, fromDay, lastDue)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, task := range tasks {
+		if isPresent(memberID, task.due) {
+			continue
+		}
+		if _, err := s.db.Exec(`DELETE FROM tasks WHERE id = ?`, task.id); err != nil {
+			return removed, fmt.Errorf("failed to delete task %s: %w", task.id, err)
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// BuildCalendar returns, for each day in [startDate, endDate], the presence of
+// every member who has a custody schedule or override
+func (s *CustodyService) BuildCalendar(familyID string, startDate, endDate time.Time) (*models.CustodyCalendar, error) {
+	if endDate.Before(startDate) {
+		return nil, validation.ValidationErrors{{Field: "end", Message: "end must not be before start"}}
+	}
+	if endDate.Sub(startDate) > maxCustodyCalendarDays*24*time.Hour {
+		return nil, validation.ValidationErrors{{Field: "end", Message: fmt.Sprintf("range may cover at most %d days", maxCustodyCalendarDays)}}
+	}
+
+	schedules, err := s.ListSchedules(familyID)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.ListOverrides(familyID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.memberNames(familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Members without any custody data are here every day and are left out
+	var memberIDs []string
+	seen := make(map[string]bool)
+	for _, schedule := range schedules {
+		if !seen[schedule.MemberID] {
+			seen[schedule.MemberID] = true
+			memberIDs = append(memberIDs, schedule.MemberID)
+		}
+	}
+	for _, override := range overrides {
+		if !seen[override.MemberID] {
+			seen[override.MemberID] = true
+			memberIDs = append(memberIDs, override.MemberID)
+		}
+	}
+
+	calendar := &models.CustodyCalendar{
+		StartDate: startDate.Format("2006-01-02"),
+		EndDate:   endDate.Format("2006-01-02"),
+		Days:      []models.CustodyDay{},
+	}
+	for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		custodyDay := models.CustodyDay{Date: day.Format("2006-01-02"), Members: []models.CustodyPresence{}}
+		for _, memberID := range memberIDs {
+			present, source := models.MemberPresentOn(schedules, overrides, memberID, day)
+			custodyDay.Members = append(custodyDay.Members, models.CustodyPresence{
+				MemberID: memberID,
+				Name:     names[memberID],
+				Present:  present,
+				Source:   source,
+			})
+		}
+		calendar.Days = append(calendar.Days, custodyDay)
+	}
+
+	return calendar, nil
+}
+
+// FindConflicts returns a conflict for each member and family-local day in
+// [start, end) on which the member is at the other household. Times without an
+// offset are read as family-local like event creation does.
+func (s *CustodyService) FindConflicts(familyID string, memberIDs []string, start, end time.Time) ([]models.CustodyConflict, error) {
+	conflicts := []models.CustodyConflict{}
+	if len(memberIDs) == 0 || end.Before(start) {
+		return conflicts, nil
+	}
+
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for custody: %w", err)
+	}
+	loc, err := loadLocation(familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid family timezone %s: %w", familyTimezone, err)
+	}
+	startUTC, err := ConvertToUTC(start, familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert start time to UTC: %w", err)
+	}
+	endUTC, err := ConvertToUTC(end, familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert end time to UTC: %w", err)
+	}
+
+	localStart := startUTC.In(loc)
+	localEnd := endUTC.In(loc)
+	firstDay := time.Date(localStart.Year(), localStart.Month(), localStart.Day(), 0, 0, 0, 0, loc)
+	lastDay := firstDay
+	for lastDay.AddDate(0, 0, 1).Before(localEnd) {
+		lastDay = lastDay.AddDate(0, 0, 1)
+	}
+
+	schedules, err := s.ListSchedules(familyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(schedules) == 0 {
+		return conflicts, nil
+	}
+	overrides, err := s.ListOverrides(familyID, firstDay.Format("2006-01-02"), lastDay.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.memberNames(familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	for day := firstDay; !day.After(lastDay); day = day.AddDate(0, 0, 1) {
+		for _, memberID := range memberIDs {
+			if present, _ := models.MemberPresentOn(schedules, overrides, memberID, day); present {
+				continue
+			}
+			conflicts = append(conflicts, models.CustodyConflict{
+				MemberID: memberID,
+				Name:     names[memberID],
+				Date:     day.Format("2006-01-02"),
+			})
+		}
+	}
+
+	return conflicts, nil
+}
+
+// memberNames maps the family's member IDs to display names
+func (s *CustodyService) memberNames(familyID string) (map[string]string, error) {
+	rows, err := s.db.Query(`SELECT id, first_name, last_name FROM family_members WHERE family_id = ?`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query family members: %w", err)
+	}
+	defer rows.Close()
+
+	names := make(map[string]string)
+	for rows.Next() {
+		var id, firstName, lastName string
+		if err := rows.Scan(&id, &firstName, &lastName); err != nil {
+			return nil, fmt.Errorf("failed to scan family member: %w", err)
+		}
+		names[id] = strings.TrimSpace(firstName + " " + lastName)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating family members: %w", err)
+	}
+
+	return names, nil
+}
+
+// requireFamilyMember checks that a member belongs to the family
+func (s *CustodyService) requireFamilyMember(familyID, memberID string) error {
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM family_members WHERE id = ? AND family_id = ?`, memberID, familyID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check family member: %w", err)
+	}
+	if count == 0 {
+		return notFound("family member")
+	}
+	return nil
+}
+
+// scanCustodySchedule scans a custody schedule row
+func scanCustodySchedule(scanner interface{ Scan(dest ...any) error }) (*models.CustodySchedule, error) {
+	var schedule models.CustodySchedule
+	var daysJSON string
+	var startDate, endDate sql.NullString
+
+	err := scanner.Scan(
+		&schedule.ID, &schedule.FamilyID, &schedule.MemberID, &schedule.Label, &daysJSON, &schedule.IntervalWeeks,
+		&schedule.AnchorDate, &startDate, &endDate, &schedule.CreatedAt, &schedule.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan custody schedule: %w", err)
+	}
+
+	if startDate.Valid {
+		schedule.StartDate = &startDate.String
+	}
+	if endDate.Valid {
+		schedule.EndDate = &endDate.String
+	}
+
+	schedule.DaysOfWeek = []string{}
+	if err := json.Unmarshal([]byte(daysJSON), &schedule.DaysOfWeek); err != nil {
+		return nil, fmt.Errorf("failed to parse days_of_week: %w", err)
+	}
+
+	return &schedule, nil
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+	"famstack/internal/validation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustodySchedule_AlternatingWeekends(t *testing.T) {
+	// Mon–Wed every week, plus every other weekend starting Sat 2026-03-07
+	schedules := []models.CustodySchedule{
+		{MemberID: "kid", DaysOfWeek: []string{"monday", "tuesday", "wednesday"}, IntervalWeeks: 1, AnchorDate: "2026-03-02"},
+		{MemberID: "kid", DaysOfWeek: []string{"saturday", "sunday"}, IntervalWeeks: 2, AnchorDate: "2026-03-07"},
+	}
+
+	cases := map[string]bool{
+		"2026-03-02": true,  // Monday
+		"2026-03-05": false, // Thursday
+		"2026-03-07": true,  // on weekend
+		"2026-03-08": true,  // Sunday belongs to the same week as the Saturday
+		"2026-03-14": false, // off weekend
+		"2026-03-21": true,  // on again
+		"2026-02-21": true,  // before the anchor, two weeks back
+		"2026-02-28": false,
+	}
+	for day, want := range cases {
+		date, err := time.Parse("2006-01-02", day)
+		require.NoError(t, err)
+		present, _ := models.MemberPresentOn(schedules, nil, "kid", date)
+		assert.Equal(t, want, present, day)
+	}
+
+	// Overrides win over the schedule, and members without schedules are always here
+	overrides := []models.CustodyOverride{{MemberID: "kid", Date: "2026-03-14", Present: true}}
+	present, source := models.MemberPresentOn(schedules, overrides, "kid", time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC))
+	assert.True(t, present)
+	assert.Equal(t, models.CustodySourceOverride, source)
+
+	present, source = models.MemberPresentOn(schedules, overrides, "parent", time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC))
+	assert.True(t, present)
+	assert.Equal(t, models.CustodySourceFullTime, source)
+}
+
+func TestCustodyService_CalendarAndConflicts(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCustodyService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	_, err := service.CreateSchedule(familyID, &models.CreateCustodyScheduleRequest{
+		MemberID:   memberID,
+		DaysOfWeek: []string{"Monday", "Tuesday", "Wednesday"},
+		AnchorDate: "2026-03-02",
+	})
+	require.NoError(t, err)
+
+	_, err = service.SetOverride(familyID, &models.SetCustodyOverrideRequest{MemberID: memberID, Date: "2026-03-05", Present: true, Note: "swap"})
+	require.NoError(t, err)
+
+	calendar, err := service.BuildCalendar(familyID, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, calendar.Days, 5)
+	require.Len(t, calendar.Days[0].Members, 1)
+	assert.True(t, calendar.Days[0].Members[0].Present)
+	assert.True(t, calendar.Days[3].Members[0].Present) // Thursday override
+	assert.Equal(t, models.CustodySourceOverride, calendar.Days[3].Members[0].Source)
+	assert.False(t, calendar.Days[4].Members[0].Present)
+
+	// An event spanning Wednesday night into Friday conflicts only on Friday
+	conflicts, err := service.FindConflicts(familyID, []string{memberID},
+		time.Date(2026, 3, 4, 18, 0, 0, 0, time.UTC), time.Date(2026, 3, 6, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "2026-03-06", conflicts[0].Date)
+	assert.Equal(t, "Bulk Member is not at this household on Fri Mar 6", conflicts[0].Message())
+}
+
+func TestCustodyService_RemoveAwayDayChores(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCustodyService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	_, err := db.Exec(`INSERT INTO task_schedules (id, family_id, created_by, title, task_type, assigned_to, days_of_week, points, active) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		"sched_feed", familyID, memberID, "Feed the cat", "chore", memberID, `["monday","thursday"]`, 1, true)
+	require.NoError(t, err)
+
+	monday := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	thursday := time.Date(2026, 3, 5, 8, 0, 0, 0, time.UTC)
+	for id, due := range map[string]time.Time{"task_monday": monday, "task_thursday": thursday} {
+		_, err = db.Exec(`INSERT INTO tasks (id, family_id, assigned_to, title, task_type, status, due_date, created_by, schedule_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, familyID, memberID, "Feed the cat", "chore", "pending", due, memberID, "sched_feed", monday, monday)
+		require.NoError(t, err)
+	}
+
+	_, err = service.CreateSchedule(familyID, &models.CreateCustodyScheduleRequest{MemberID: memberID, DaysOfWeek: []string{"monday"}})
+	require.NoError(t, err)
+
+	removed, err := service.RemoveAwayDayChores(familyID, memberID, monday)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	var remaining []string
+	rows, err := db.Query(`SELECT id FROM tasks WHERE family_id = ?`, familyID)
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		require.NoError(t, rows.Scan(&id))
+		remaining = append(remaining, id)
+	}
+	assert.Equal(t, []string{"task_monday"}, remaining)
+}
+
+func TestCustodyService_Validation(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCustodyService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	var validationErrs validation.ValidationErrors
+	_, err := service.CreateSchedule(familyID, &models.CreateCustodyScheduleRequest{MemberID: memberID, DaysOfWeek: []string{"funday"}})
+	require.ErrorAs(t, err, &validationErrs)
+
+	_, err = service.CreateSchedule(familyID, &models.CreateCustodyScheduleRequest{MemberID: memberID, DaysOfWeek: []string{"monday"}, IntervalWeeks: 5})
+	require.ErrorAs(t, err, &validationErrs)
+
+	_, err = service.CreateSchedule(familyID, &models.CreateCustodyScheduleRequest{MemberID: "someone_else", DaysOfWeek: []string{"monday"}})
+	assert.EqualError(t, err, "family member not found")
+
+	_, err = service.SetOverride(familyID, &models.SetCustodyOverrideRequest{MemberID: memberID, Date: "next week"})
+	require.ErrorAs(t, err, &validationErrs)
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/models"
+)
+
+// dashboardSectionFunc loads the data for one dashboard section. today is the
+// current date in the family timezone.
+type dashboardSectionFunc func(familyID string, today time.Time) (any, error)
+
+// DashboardService assembles the home screen payload from the other services
+type DashboardService struct {
+	db           *database.Fascade
+	tasks        *TasksService
+	timeline     *TimelineService
+	integrations *IntegrationsService
+}
+
+// NewDashboardService creates a new dashboard service
+func NewDashboardService(db *database.Fascade, tasks *TasksService, timeline *TimelineService, integrations *IntegrationsService) *DashboardService {
+	return &DashboardService{
+		db:           db,
+		tasks:        tasks,
+		timeline:     timeline,
+		integrations: integrations,
+	}
+}
+
+// unavailableDashboardSections have no backing feature in this install yet.
+// They're still listed so the home screen can lay itself out consistently.
+var unavailableDashboardSections = map[string]string{
+	models.DashboardSectionNotifications: "notifications are not supported yet",
+	models.DashboardSectionWeather:       "no weather provider is configured",
+	models.DashboardSectionAnnouncements: "announcements are not supported yet",
+}
+
+// BuildDashboard loads every section concurrently. A section that fails is
+// reported in its own status, so only a problem resolving the family itself
+// fails the whole dashboard.
+func (s *DashboardService) BuildDashboard(familyID string, now time.Time) (*models.Dashboard, error) {
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for dashboard: %w", err)
+	}
+	loc, err := loadLocation(familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid family timezone %s: %w", familyTimezone, err)
+	}
+	today := now.In(loc)
+
+	sections := map[string]dashboardSectionFunc{
+		models.DashboardSectionAgenda:       s.agenda,
+		models.DashboardSectionOverdueTasks: s.overdueTasks,
+		models.DashboardSectionIntegrations: s.integrationHealth,
+		models.DashboardSectionPoints:       s.weeklyPoints,
+	}
+
+	dashboard := &models.Dashboard{
+		FamilyID:    familyID,
+		Date:        today.Format("2006-01-02"),
+		GeneratedAt: now.UTC(),
+		Sections:    make(map[string]models.DashboardSection, len(sections)+len(unavailableDashboardSections)),
+	}
+	for name, reason := range unavailableDashboardSections {
+		dashboard.Sections[name] = models.DashboardSection{Status: models.DashboardStatusUnavailable, Error: reason}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, load := range sections {
+		wg.Add(1)
+		go func(name string, load dashboardSectionFunc) {
+			defer wg.Done()
+
+			section := models.DashboardSection{Status: models.DashboardStatusOK}
+			data, err := loadDashboardSection(load, familyID, today)
+			if err != nil {
+				section = models.DashboardSection{Status: models.DashboardStatusError, Error: err.Error()}
+			} else {
+				section.Data = data
+			}
+
+			mu.Lock()
+			dashboard.Sections[name] = section
+			mu.Unlock()
+		}(name, load)
+	}
+	wg.Wait()
+
+	return dashboard, nil
+}
+
+// loadDashboardSection runs one section, turning a panic into an error so a
+// bug in one section can't take down the request
+func loadDashboardSection(load dashboardSectionFunc, familyID string, today time.Time) (data any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("section panicked: %v", r)
+		}
+	}()
+	return load(familyID, today)
+}
+
+func (s *DashboardService) agenda(familyID string, today time.Time) (any, error) {
+	return s.timeline.BuildTimeline(familyID, today)
+}
+
+func (s *DashboardService) overdueTasks(familyID string, today time.Time) (any, error) {
+	return s.tasks.ListOverdueTasks(familyID, today.Format("2006-01-02"))
+}
+
+func (s *DashboardService) integrationHealth(familyID string, today time.Time) (any, error) {
+	integrations, err := s.integrations.ListIntegrations(familyID, &ListIntegrationsQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	health := make([]models.IntegrationHealth, 0, len(integrations))
+	for _, integration := range integrations {
+		healthy := (integration.Status == StatusConnected || integration.Status == StatusSyncing) &&
+			(integration.LastError == nil || *integration.LastError == "")
+		health = append(health, models.IntegrationHealth{
+			ID:          integration.ID,
+			DisplayName: integration.DisplayName,
+			Provider:    string(integration.Provider),
+			Status:      string(integration.Status),
+			Healthy:     healthy,
+			LastSyncAt:  integration.LastSyncAt,
+			LastError:   integration.LastError,
+		})
+	}
+
+	return health, nil
+}
+
+// weeklyPoints totals the points of scheduled tasks each member completed
+// since Monday, including members who haven't earned any yet
+func (s *DashboardService) weeklyPoints(familyID string, today time.Time) (any, error) {
+	weekStart := models.WeekStart(today).UTC()
+
+	rows, err := s.db.Query(`
+		SELECT m.id, m.first_name, m.last_name,
+			   COALESCE(SUM(ts.points), 0), COUNT(ts.id)
+		FROM family_members m
+		LEFT JOIN tasks t ON t.assigned_to = m.id AND t.status = 'completed' AND t.completed_at >= ?
+		LEFT JOIN task_schedules ts ON ts.id = t.schedule_id
+		WHERE m.family_id = ? AND m.is_active = TRUE
+		GROUP BY m.id, m.first_name, m.last_name, m.display_order
+		ORDER BY m.display_order, m.first_name
+	`, weekStart, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query points: %w", err)
+	}
+	defer rows.Close()
+
+	points := []models.MemberPoints{}
+	for rows.Next() {
+		var entry models.MemberPoints
+		var firstName, lastName string
+		if err := rows.Scan(&entry.MemberID, &firstName, &lastName, &entry.Points, &entry.Completed); err != nil {
+			return nil, fmt.Errorf("failed to scan points: %w", err)
+		}
+		entry.Name = firstName + " " + lastName
+		points = append(points, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating points: %w", err)
+	}
+
+	return points, nil
+}
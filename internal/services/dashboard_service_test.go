@@ -0,0 +1,69 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDashboardService(db *database.Fascade) *DashboardService {
+	tasks := NewTasksService(db)
+	timeline := NewTimelineService(db, NewCalendarService(db), tasks, NewSchedulesService(db), NewScheduleProfilesService(db), NewCustodyService(db))
+	return NewDashboardService(db, tasks, timeline, NewIntegrationsService(db, nil))
+}
+
+func TestDashboardService_BuildsSectionsAndToleratesFailures(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestDashboardService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	now := time.Date(2026, 3, 18, 15, 0, 0, 0, time.UTC) // a Wednesday
+
+	_, err := db.Exec(`INSERT INTO task_schedules (id, family_id, created_by, title, task_type, assigned_to, days_of_week, points, active) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		"sched_dishes", familyID, memberID, "Dishes", "chore", memberID, `["monday"]`, 5, true)
+	require.NoError(t, err)
+	monday := time.Date(2026, 3, 16, 18, 0, 0, 0, time.UTC)
+	_, err = db.Exec(`INSERT INTO tasks (id, family_id, assigned_to, title, task_type, status, due_date, created_by, schedule_id, created_at, updated_at, completed_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		"task_dishes", familyID, memberID, "Dishes", "chore", "completed", monday, memberID, "sched_dishes", monday, monday, monday)
+	require.NoError(t, err)
+
+	lastWeek := now.AddDate(0, 0, -7)
+	_, err = db.Exec(`INSERT INTO tasks (id, family_id, assigned_to, title, task_type, status, due_date, created_by, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		"task_overdue", familyID, memberID, "Return library books", "todo", "pending", lastWeek, memberID, lastWeek, lastWeek)
+	require.NoError(t, err)
+
+	dashboard, err := service.BuildDashboard(familyID, now)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-03-18", dashboard.Date)
+
+	for _, name := range []string{models.DashboardSectionAgenda, models.DashboardSectionOverdueTasks, models.DashboardSectionIntegrations, models.DashboardSectionPoints} {
+		assert.Equal(t, models.DashboardStatusOK, dashboard.Sections[name].Status, name)
+	}
+	assert.Equal(t, models.DashboardStatusUnavailable, dashboard.Sections[models.DashboardSectionWeather].Status)
+
+	overdue, ok := dashboard.Sections[models.DashboardSectionOverdueTasks].Data.([]models.Task)
+	require.True(t, ok)
+	require.Len(t, overdue, 1)
+	assert.Equal(t, "task_overdue", overdue[0].ID)
+
+	points, ok := dashboard.Sections[models.DashboardSectionPoints].Data.([]models.MemberPoints)
+	require.True(t, ok)
+	require.Len(t, points, 1)
+	assert.Equal(t, 5, points[0].Points)
+	assert.Equal(t, 1, points[0].Completed)
+
+	// A broken section is reported without failing the others
+	_, err = db.Exec(`DROP TABLE integrations`)
+	require.NoError(t, err)
+
+	dashboard, err = service.BuildDashboard(familyID, now)
+	require.NoError(t, err)
+	assert.Equal(t, models.DashboardStatusError, dashboard.Sections[models.DashboardSectionIntegrations].Status)
+	assert.NotEmpty(t, dashboard.Sections[models.DashboardSectionIntegrations].Error)
+	assert.Equal(t, models.DashboardStatusOK, dashboard.Sections[models.DashboardSectionOverdueTasks].Status)
+}
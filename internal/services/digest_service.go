@@ -0,0 +1,171 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/models"
+)
+
+// maxDigestDays bounds how far ahead a digest looks
+const maxDigestDays = 14
+
+// DigestService gathers the data behind week and agenda digests
+type DigestService struct {
+	db       *database.Fascade
+	families *FamiliesService
+	timeline *TimelineService
+}
+
+// NewDigestService creates a new digest service
+func NewDigestService(db *database.Fascade, families *FamiliesService, timeline *TimelineService) *DigestService {
+	return &DigestService{db: db, families: families, timeline: timeline}
+}
+
+// BuildAgenda collects the family's events and tasks for the given number of
+// days starting on start's date. Each day comes from the family timeline, so
+// digests show the same items as the display.
+func (s *DigestService) BuildAgenda(familyID string, start time.Time, days int) (*models.AgendaDigest, error) {
+	if days < 1 || days > maxDigestDays {
+		return nil, fmt.Errorf("digest must cover between 1 and %d days", maxDigestDays)
+	}
+
+	family, err := s.families.GetFamily(familyID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := loadLocation(family.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid family timezone %s: %w", family.Timezone, err)
+	}
+
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	digest := &models.AgendaDigest{
+		FamilyID:   family.ID,
+		FamilyName: family.Name,
+		Timezone:   family.Timezone,
+		Start:      first,
+		Days:       make([]models.AgendaDay, 0, days),
+	}
+
+	for i := 0; i < days; i++ {
+		date := first.AddDate(0, 0, i)
+		timeline, err := s.timeline.BuildTimeline(familyID, date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build timeline for %s: %w", date.Format("2006-01-02"), err)
+		}
+
+		day := agendaDay(date, timeline)
+		digest.TotalItems += len(day.Events) + len(day.Tasks)
+		digest.Days = append(digest.Days, day)
+	}
+
+	return digest, nil
+}
+
+// BuildMemberAgenda collects one member's day: their own events and tasks,
+// plus the family-wide events everyone is part of
+func (s *DigestService) BuildMemberAgenda(familyID, memberID string, date time.Time) (*models.AgendaDigest, error) {
+	family, err := s.families.GetFamily(familyID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := loadLocation(family.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid family timezone %s: %w", family.Timezone, err)
+	}
+
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+	timeline, err := s.timeline.BuildTimeline(familyID, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build timeline for %s: %w", day.Format("2006-01-02"), err)
+	}
+
+	memberTimeline := &models.Timeline{Date: timeline.Date, Timezone: timeline.Timezone}
+	for _, lane := range timeline.Lanes {
+		switch lane.MemberID {
+		case memberID:
+			memberTimeline.Lanes = append(memberTimeline.Lanes, lane)
+		case "unassigned":
+			lane.Items = onlyEvents(lane.Items)
+			lane.AllDay = onlyEvents(lane.AllDay)
+			memberTimeline.Lanes = append(memberTimeline.Lanes, lane)
+		}
+	}
+
+	agenda := agendaDay(day, memberTimeline)
+	return &models.AgendaDigest{
+		FamilyID:   family.ID,
+		FamilyName: family.Name,
+		Timezone:   family.Timezone,
+		Start:      day,
+		Days:       []models.AgendaDay{agenda},
+		TotalItems: len(agenda.Events) + len(agenda.Tasks),
+	}, nil
+}
+
+func onlyEvents(items []models.TimelineItem) []models.TimelineItem {
+	events := []models.TimelineItem{}
+	for _, item := range items {
+		if item.Kind == models.TimelineItemEvent {
+			events = append(events, item)
+		}
+	}
+	return events
+}
+
+// agendaDay flattens a timeline's lanes into one list per kind, listing
+// shared items once with every member they appear under
+func agendaDay(date time.Time, timeline *models.Timeline) models.AgendaDay {
+	entries := make(map[string]*models.AgendaEntry)
+	var order []string
+
+	for _, lane := range timeline.Lanes {
+		items := append(append([]models.TimelineItem{}, lane.AllDay...), lane.Items...)
+		for _, item := range items {
+			entry, ok := entries[item.ID]
+			if !ok {
+				entry = &models.AgendaEntry{
+					Kind:    item.Kind,
+					Title:   item.Title,
+					Start:   item.Start,
+					AllDay:  item.AllDay,
+					Status:  item.Status,
+					Members: []string{},
+				}
+				entries[item.ID] = entry
+				order = append(order, item.ID)
+			}
+			if lane.MemberID != "unassigned" {
+				entry.Members = append(entry.Members, lane.Name)
+			}
+		}
+	}
+
+	day := models.AgendaDay{Date: date, Events: []models.AgendaEntry{}, Tasks: []models.AgendaEntry{}}
+	for _, id := range order {
+		entry := entries[id]
+		if entry.Kind == models.TimelineItemEvent {
+			day.Events = append(day.Events, *entry)
+		} else {
+			day.Tasks = append(day.Tasks, *entry)
+		}
+	}
+	sortAgendaEntries(day.Events)
+	sortAgendaEntries(day.Tasks)
+	return day
+}
+
+func sortAgendaEntries(entries []models.AgendaEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].AllDay != entries[j].AllDay {
+			return entries[i].AllDay
+		}
+		if !entries[i].Start.Equal(entries[j].Start) {
+			return entries[i].Start.Before(entries[j].Start)
+		}
+		return entries[i].Title < entries[j].Title
+	})
+}
@@ -0,0 +1,339 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/ids"
+	"famstack/internal/models"
+	"famstack/internal/validation"
+)
+
+// DisplayPreferencesService stores per-member and per-device display settings
+// so shared tablets and phones don't depend on local-only state
+type DisplayPreferencesService struct {
+	db       *database.Fascade
+	families *FamiliesService
+	members  *FamilyMemberService
+}
+
+// NewDisplayPreferencesService creates a new display preferences service
+func NewDisplayPreferencesService(db *database.Fascade, families *FamiliesService, members *FamilyMemberService) *DisplayPreferencesService {
+	return &DisplayPreferencesService{
+		db:       db,
+		families: families,
+		members:  members,
+	}
+}
+
+const displayPreferencesColumns = `id, family_id, member_id, device_id, device_name, visible_member_ids, default_view, range_days, hidden_categories, updated_by, created_at, updated_at`
+
+// GetMemberPreferences returns a member's own display preferences
+func (s *DisplayPreferencesService) GetMemberPreferences(familyID, memberID string) (*models.DisplayPreferences, error) {
+	row := s.db.QueryRow(`SELECT `+displayPreferencesColumns+` FROM display_preferences WHERE family_id = ? AND member_id = ?`, familyID, memberID)
+	prefs, err := scanDisplayPreferences(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("display preferences not found")
+	}
+	return prefs, err
+}
+
+// GetDevicePreferences returns the display preferences saved for a device
+func (s *DisplayPreferencesService) GetDevicePreferences(familyID, deviceID string) (*models.DisplayPreferences, error) {
+	row := s.db.QueryRow(`SELECT `+displayPreferencesColumns+` FROM display_preferences WHERE family_id = ? AND device_id = ?`, familyID, deviceID)
+	prefs, err := scanDisplayPreferences(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("display preferences not found")
+	}
+	return prefs, err
+}
+
+// ListDevicePreferences returns every device configured for a family
+func (s *DisplayPreferencesService) ListDevicePreferences(familyID string) ([]models.DisplayPreferences, error) {
+	rows, err := s.db.Query(`SELECT `+displayPreferencesColumns+` FROM display_preferences WHERE family_id = ? AND device_id IS NOT NULL ORDER BY device_name, device_id`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query device preferences: %w", err)
+	}
+	defer rows.Close()
+
+	devices := []models.DisplayPreferences{}
+	for rows.Next() {
+		prefs, err := scanDisplayPreferences(rows)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, *prefs)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating device preferences: %w", err)
+	}
+
+	return devices, nil
+}
+
+// SaveMemberPreferences creates or updates a member's own display preferences
+func (s *DisplayPreferencesService) SaveMemberPreferences(familyID, memberID, updatedBy string, req *models.SaveDisplayPreferencesRequest) (*models.DisplayPreferences, error) {
+	existing, err := s.GetMemberPreferences(familyID, memberID)
+	if err != nil && err.Error() != "display preferences not found" {
+		return nil, err
+	}
+	if existing == nil {
+		existing = models.DefaultDisplayPreferences(familyID)
+		existing.MemberID = &memberID
+	}
+
+	return s.save(existing, updatedBy, req)
+}
+
+// SaveDevicePreferences creates or updates the display preferences of a device
+func (s *DisplayPreferencesService) SaveDevicePreferences(familyID, deviceID, updatedBy string, req *models.SaveDisplayPreferencesRequest) (*models.DisplayPreferences, error) {
+	deviceID = strings.TrimSpace(deviceID)
+	if deviceID == "" || len(deviceID) > 100 {
+		return nil, validation.ValidationErrors{{Field: "device_id", Message: "device_id must be between 1 and 100 characters"}}
+	}
+
+	existing, err := s.GetDevicePreferences(familyID, deviceID)
+	if err != nil && err.Error() != "display preferences not found" {
+		return nil, err
+	}
+	if existing == nil {
+		existing = models.DefaultDisplayPreferences(familyID)
+		existing.DeviceID = &deviceID
+	}
+
+	return s.save(existing, updatedBy, req)
+}
+
+// DeleteDevicePreferences forgets a device, which then falls back to its
+// member's preferences
+func (s *DisplayPreferencesService) DeleteDevicePreferences(familyID, deviceID string) error {
+	result, err := s.db.Exec(`DELETE FROM display_preferences WHERE family_id = ? AND device_id = ?`, familyID, deviceID)
+	if err != nil {
+		return fmt.Errorf("failed to delete device preferences: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("display preferences not found")
+	}
+
+	return nil
+}
+
+// ResolvePreferences picks the preferences a display should use: the device's
+// when deviceID is known, else the member's own, else the defaults. The chosen
+// record is used as a whole rather than merged field by field.
+func (s *DisplayPreferencesService) ResolvePreferences(familyID, memberID, deviceID string) (*models.DisplayPreferences, string, error) {
+	if deviceID != "" {
+		prefs, err := s.GetDevicePreferences(familyID, deviceID)
+		if err == nil {
+			return prefs, models.DisplayPreferencesSourceDevice, nil
+		}
+		if err.Error() != "display preferences not found" {
+			return nil, "", err
+		}
+	}
+
+	if memberID != "" {
+		prefs, err := s.GetMemberPreferences(familyID, memberID)
+		if err == nil {
+			return prefs, models.DisplayPreferencesSourceMember, nil
+		}
+		if err.Error() != "display preferences not found" {
+			return nil, "", err
+		}
+	}
+
+	return models.DefaultDisplayPreferences(familyID), models.DisplayPreferencesSourceDefault, nil
+}
+
+// Bootstrap returns the family, the member columns to show and the resolved
+// preferences for a display starting up
+func (s *DisplayPreferencesService) Bootstrap(familyID, memberID, deviceID string) (*models.DisplayBootstrap, error) {
+	family, err := s.families.GetFamily(familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, source, err := s.ResolvePreferences(familyID, memberID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.members.ListFamilyMembers(familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]*models.FamilyMember, 0, len(members))
+	for _, member := range members {
+		if prefs.ShowsMember(member.ID) {
+			visible = append(visible, member)
+		}
+	}
+
+	return &models.DisplayBootstrap{
+		Family:      family,
+		MemberID:    memberID,
+		Members:     visible,
+		Preferences: prefs,
+		Source:      source,
+	}, nil
+}
+
+// save merges the request onto prefs, validates it and writes the row
+func (s *DisplayPreferencesService) save(prefs *models.DisplayPreferences, updatedBy string, req *models.SaveDisplayPreferencesRequest) (*models.DisplayPreferences, error) {
+	if req.DeviceName != nil {
+		prefs.DeviceName = strings.TrimSpace(*req.DeviceName)
+	}
+	if req.VisibleMemberIDs != nil {
+		prefs.VisibleMemberIDs = *req.VisibleMemberIDs
+	}
+	if req.DefaultView != nil {
+		prefs.DefaultView = *req.DefaultView
+	}
+	if req.RangeDays != nil {
+		prefs.RangeDays = *req.RangeDays
+	}
+	if req.HiddenCategories != nil {
+		prefs.HiddenCategories = normalizeCategories(*req.HiddenCategories)
+	}
+
+	if err := prefs.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkVisibleMembers(prefs); err != nil {
+		return nil, err
+	}
+
+	visibleJSON, err := json.Marshal(prefs.VisibleMemberIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal visible_member_ids: %w", err)
+	}
+	hiddenJSON, err := json.Marshal(prefs.HiddenCategories)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal hidden_categories: %w", err)
+	}
+
+	var updatedByValue any
+	if updatedBy != "" {
+		updatedByValue = updatedBy
+	}
+
+	now := time.Now().UTC()
+	if prefs.ID == "" {
+		prefs.ID = ids.New("display")
+		_, err = s.db.Exec(`
+			INSERT INTO display_preferences (id, family_id, member_id, device_id, device_name, visible_member_ids,
+											 default_view, range_days, hidden_categories, updated_by, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, prefs.ID, prefs.FamilyID, prefs.MemberID, prefs.DeviceID, prefs.DeviceName, string(visibleJSON),
+			prefs.DefaultView, prefs.RangeDays, string(hiddenJSON), updatedByValue, now, now)
+	} else {
+		_, err = s.db.Exec(`
+			UPDATE display_preferences
+			SET device_name = ?, visible_member_ids = ?, default_view = ?, range_days = ?, hidden_categories = ?,
+				updated_by = ?, updated_at = ?
+			WHERE id = ?
+		`, prefs.DeviceName, string(visibleJSON), prefs.DefaultView, prefs.RangeDays, string(hiddenJSON),
+			updatedByValue, now, prefs.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save display preferences: %w", err)
+	}
+
+	if prefs.DeviceID != nil {
+		return s.GetDevicePreferences(prefs.FamilyID, *prefs.DeviceID)
+	}
+	return s.GetMemberPreferences(prefs.FamilyID, *prefs.MemberID)
+}
+
+// checkVisibleMembers makes sure every visible member is an active member of the family
+func (s *DisplayPreferencesService) checkVisibleMembers(prefs *models.DisplayPreferences) error {
+	if len(prefs.VisibleMemberIDs) == 0 {
+		return nil
+	}
+
+	members, err := s.members.ListFamilyMembers(prefs.FamilyID)
+	if err != nil {
+		return err
+	}
+	active := make(map[string]bool, len(members))
+	for _, member := range members {
+		active[member.ID] = true
+	}
+
+	var errs validation.ValidationErrors
+	for _, memberID := range prefs.VisibleMemberIDs {
+		if !active[memberID] {
+			errs = append(errs, validation.ValidationError{
+				Field:   "visible_member_ids",
+				Message: fmt.Sprintf("member %s is not an active member of this family", memberID),
+			})
+		}
+	}
+	return errs.ToError()
+}
+
+// normalizeCategories lowercases, trims and de-duplicates category names to
+// match how color rules store them
+func normalizeCategories(categories []string) []string {
+	normalized := make([]string, 0, len(categories))
+	seen := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		category = strings.ToLower(strings.TrimSpace(category))
+		if seen[category] {
+			continue
+		}
+		seen[category] = true
+		normalized = append(normalized, category)
+	}
+	return normalized
+}
+
+// scanDisplayPreferences scans a display preferences row
+func scanDisplayPreferences(scanner interface{ Scan(dest ...any) error }) (*models.DisplayPreferences, error) {
+	var prefs models.DisplayPreferences
+	var memberID, deviceID, updatedBy sql.NullString
+	var visibleJSON, hiddenJSON string
+
+	err := scanner.Scan(
+		&prefs.ID, &prefs.FamilyID, &memberID, &deviceID, &prefs.DeviceName, &visibleJSON,
+		&prefs.DefaultView, &prefs.RangeDays, &hiddenJSON, &updatedBy, &prefs.CreatedAt, &prefs.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan display preferences: %w", err)
+	}
+
+	if memberID.Valid {
+		prefs.MemberID = &memberID.String
+	}
+	if deviceID.Valid {
+		prefs.DeviceID = &deviceID.String
+	}
+	if updatedBy.Valid {
+		prefs.UpdatedBy = &updatedBy.String
+	}
+
+	prefs.VisibleMemberIDs = []string{}
+	if err := json.Unmarshal([]byte(visibleJSON), &prefs.VisibleMemberIDs); err != nil {
+		return nil, fmt.Errorf("failed to parse visible_member_ids: %w", err)
+	}
+	prefs.HiddenCategories = []string{}
+	if err := json.Unmarshal([]byte(hiddenJSON), &prefs.HiddenCategories); err != nil {
+		return nil, fmt.Errorf("failed to parse hidden_categories: %w", err)
+	}
+
+	return &prefs, nil
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+	"famstack/internal/validation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDisplayService(t *testing.T) (*DisplayPreferencesService, string, string, string) {
+	db := setupTestDB(t)
+	service := NewDisplayPreferencesService(db, NewFamiliesService(db), NewFamilyMemberService(db))
+
+	familyID := "fam_display"
+	_, err := db.Exec(`INSERT INTO families (id, name, timezone) VALUES (?, ?, ?)`, familyID, "Display Family", "UTC")
+	require.NoError(t, err)
+	for i, member := range [][]string{{"member_parent", "Pat", "adult"}, {"member_child", "Sam", "child"}} {
+		_, err = db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, display_order, is_active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			member[0], familyID, member[1], "Display", member[2], i, true, time.Now(), time.Now())
+		require.NoError(t, err)
+	}
+
+	return service, familyID, "member_parent", "member_child"
+}
+
+func TestDisplayPreferencesService_BootstrapResolution(t *testing.T) {
+	service, familyID, parentID, childID := newTestDisplayService(t)
+
+	// Nothing saved: defaults and every member
+	bootstrap, err := service.Bootstrap(familyID, parentID, "kitchen-tablet")
+	require.NoError(t, err)
+	assert.Equal(t, models.DisplayPreferencesSourceDefault, bootstrap.Source)
+	assert.Len(t, bootstrap.Members, 2)
+	assert.Equal(t, models.DisplayViewWeek, bootstrap.Preferences.DefaultView)
+
+	view := models.DisplayViewDay
+	_, err = service.SaveMemberPreferences(familyID, parentID, parentID, &models.SaveDisplayPreferencesRequest{
+		DefaultView:      &view,
+		HiddenCategories: &[]string{" Health ", "health"},
+	})
+	require.NoError(t, err)
+
+	bootstrap, err = service.Bootstrap(familyID, parentID, "kitchen-tablet")
+	require.NoError(t, err)
+	assert.Equal(t, models.DisplayPreferencesSourceMember, bootstrap.Source)
+	assert.Equal(t, models.DisplayViewDay, bootstrap.Preferences.DefaultView)
+	assert.Equal(t, []string{"health"}, bootstrap.Preferences.HiddenCategories)
+
+	// The kitchen tablet only shows the child's column
+	name := "Kitchen"
+	rangeDays := 3
+	device, err := service.SaveDevicePreferences(familyID, "kitchen-tablet", parentID, &models.SaveDisplayPreferencesRequest{
+		DeviceName:       &name,
+		VisibleMemberIDs: &[]string{childID},
+		RangeDays:        &rangeDays,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, device.DeviceID)
+	assert.Equal(t, models.DisplayViewWeek, device.DefaultView)
+
+	bootstrap, err = service.Bootstrap(familyID, parentID, "kitchen-tablet")
+	require.NoError(t, err)
+	assert.Equal(t, models.DisplayPreferencesSourceDevice, bootstrap.Source)
+	require.Len(t, bootstrap.Members, 1)
+	assert.Equal(t, childID, bootstrap.Members[0].ID)
+	assert.Equal(t, 3, bootstrap.Preferences.RangeDays)
+
+	// Saving again updates the same device rather than adding another
+	rangeDays = 5
+	_, err = service.SaveDevicePreferences(familyID, "kitchen-tablet", parentID, &models.SaveDisplayPreferencesRequest{RangeDays: &rangeDays})
+	require.NoError(t, err)
+	devices, err := service.ListDevicePreferences(familyID)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, 5, devices[0].RangeDays)
+	assert.Equal(t, []string{childID}, devices[0].VisibleMemberIDs)
+
+	require.NoError(t, service.DeleteDevicePreferences(familyID, "kitchen-tablet"))
+	bootstrap, err = service.Bootstrap(familyID, parentID, "kitchen-tablet")
+	require.NoError(t, err)
+	assert.Equal(t, models.DisplayPreferencesSourceMember, bootstrap.Source)
+}
+
+func TestDisplayPreferencesService_Validates(t *testing.T) {
+	service, familyID, parentID, _ := newTestDisplayService(t)
+	var validationErrs validation.ValidationErrors
+
+	rangeDays := 60
+	_, err := service.SaveMemberPreferences(familyID, parentID, parentID, &models.SaveDisplayPreferencesRequest{RangeDays: &rangeDays})
+	require.ErrorAs(t, err, &validationErrs)
+
+	_, err = service.SaveDevicePreferences(familyID, "hall-phone", parentID, &models.SaveDisplayPreferencesRequest{
+		VisibleMemberIDs: &[]string{"member_elsewhere"},
+	})
+	require.ErrorAs(t, err, &validationErrs)
+	assert.Equal(t, "visible_member_ids", validationErrs[0].Field)
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/eventbus"
+	"famstack/internal/models"
+)
+
+const (
+	// displayProjectionMaxAge is how old a projection may get before it is
+	// rebuilt without any change, so the window keeps moving with the clock
+	displayProjectionMaxAge = 15 * time.Minute
+	// displayProjectionDebounce gathers a burst of changes, such as a
+	// calendar sync writing hundreds of events, into a single rebuild
+	displayProjectionDebounce = 2 * time.Second
+)
+
+// DisplayProjectionService maintains the wall display's read model: each
+// family's next 48 hours of events and timelines, built in the background and
+// stored as one row. Display reads are served from memory or that row, so they
+// keep working while writes and syncs hold the database.
+type DisplayProjectionService struct {
+	db       *database.Fascade
+	calendar *CalendarService
+	timeline *TimelineService
+
+	mu       sync.Mutex
+	cached   map[string]*models.DisplayProjection
+	dirty    map[string]bool
+	pending  map[string]*time.Timer
+	debounce time.Duration
+}
+
+// NewDisplayProjectionService creates a new display projection service
+func NewDisplayProjectionService(db *database.Fascade, calendar *CalendarService, timeline *TimelineService) *DisplayProjectionService {
+	return &DisplayProjectionService{
+		db:       db,
+		calendar: calendar,
+		timeline: timeline,
+		cached:   make(map[string]*models.DisplayProjection),
+		dirty:    make(map[string]bool),
+		pending:  make(map[string]*time.Timer),
+		debounce: displayProjectionDebounce,
+	}
+}
+
+// Subscribe invalidates a family's projection whenever its calendar or tasks change
+func (s *DisplayProjectionService) Subscribe(bus *eventbus.Bus) {
+	bus.Subscribe(func(event eventbus.Event) {
+		s.Invalidate(event.FamilyID)
+	}, eventbus.TopicCalendarChanged, eventbus.TopicTasksChanged)
+}
+
+// Invalidate marks the family's projection stale and schedules a rebuild.
+// Changes that arrive before the rebuild starts join it.
+func (s *DisplayProjectionService) Invalidate(familyID string) {
+	if familyID == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dirty[familyID] = true
+	if _, ok := s.pending[familyID]; ok {
+		return
+	}
+	s.pending[familyID] = time.AfterFunc(s.debounce, func() {
+		s.mu.Lock()
+		delete(s.pending, familyID)
+		s.mu.Unlock()
+
+		if _, err := s.Refresh(familyID, time.Now()); err != nil {
+			log.Printf("Failed to refresh display projection for family %s: %v", familyID, err)
+		}
+	})
+}
+
+// Get returns the family's projection, building it on first use. A stale
+// projection is still returned, marked as such, while a rebuild is scheduled.
+func (s *DisplayProjectionService) Get(familyID string, now time.Time) (*models.DisplayProjection, error) {
+	s.mu.Lock()
+	projection, dirty := s.cached[familyID], s.dirty[familyID]
+	s.mu.Unlock()
+
+	if projection == nil {
+		stored, err := s.load(familyID)
+		if err != nil {
+			if err.Error() != "display projection not found" {
+				return nil, err
+			}
+			return s.Refresh(familyID, now)
+		}
+		s.mu.Lock()
+		if s.cached[familyID] == nil {
+			s.cached[familyID] = stored
+		}
+		projection = s.cached[familyID]
+		s.mu.Unlock()
+	}
+
+	result := *projection
+	result.Stale = dirty || now.Sub(projection.GeneratedAt) > displayProjectionMaxAge
+	if result.Stale && !dirty {
+		s.Invalidate(familyID)
+	}
+	return &result, nil
+}
+
+// Refresh rebuilds and stores the family's projection. Changes made while it
+// builds leave the projection marked stale for the next rebuild.
+func (s *DisplayProjectionService) Refresh(familyID string, now time.Time) (*models.DisplayProjection, error) {
+	s.mu.Lock()
+	delete(s.dirty, familyID)
+	s.mu.Unlock()
+
+	projection, err := s.build(familyID, now)
+	if err != nil {
+		s.markDirty(familyID)
+		return nil, err
+	}
+
+	// Serve the new projection even if storing it fails; the row only
+	// matters after a restart
+	s.mu.Lock()
+	s.cached[familyID] = projection
+	s.mu.Unlock()
+
+	if err := s.save(projection); err != nil {
+		s.markDirty(familyID)
+		return nil, err
+	}
+	return projection, nil
+}
+
+// RefreshStale rebuilds every stored projection that was invalidated or has
+// aged past its limit. It returns how many were rebuilt.
+func (s *DisplayProjectionService) RefreshStale(now time.Time) (int, error) {
+	rows, err := s.db.Query(`SELECT family_id FROM display_projections WHERE generated_at < ?`, now.Add(-displayProjectionMaxAge).UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to list aged display projections: %w", err)
+	}
+	defer rows.Close()
+
+	familyIDs := make(map[string]bool)
+	for rows.Next() {
+		var familyID string
+		if err := rows.Scan(&familyID); err != nil {
+			return 0, fmt.Errorf("failed to scan display projection: %w", err)
+		}
+		familyIDs[familyID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating display projections: %w", err)
+	}
+
+	s.mu.Lock()
+	for familyID := range s.dirty {
+		familyIDs[familyID] = true
+	}
+	s.mu.Unlock()
+
+	refreshed := 0
+	for familyID := range familyIDs {
+		if _, err := s.Refresh(familyID, now); err != nil {
+			// One family's failure shouldn't hold back everyone else's display
+			log.Printf("Failed to refresh display projection for family %s: %v", familyID, err)
+			continue
+		}
+		refreshed++
+	}
+	return refreshed, nil
+}
+
+func (s *DisplayProjectionService) markDirty(familyID string) {
+	s.mu.Lock()
+	s.dirty[familyID] = true
+	s.mu.Unlock()
+}
+
+// build assembles the projection from the start of the current hour, in the
+// family timezone, with a timeline for every day the window touches
+func (s *DisplayProjectionService) build(familyID string, now time.Time) (*models.DisplayProjection, error) {
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for display projection: %w", err)
+	}
+	loc, err := loadLocation(familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid family timezone %s: %w", familyTimezone, err)
+	}
+
+	local := now.In(loc)
+	windowStart := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), 0, 0, 0, loc)
+	windowEnd := windowStart.Add(models.DisplayProjectionWindow)
+
+	// The display is a screen the whole family sees, so it gets no viewer
+	events, err := s.calendar.GetUnifiedCalendarEventsFor(nil, familyID, windowStart, windowEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events for display projection: %w", err)
+	}
+
+	projection := &models.DisplayProjection{
+		FamilyID:    familyID,
+		Timezone:    familyTimezone,
+		WindowStart: windowStart,
+		WindowEnd:   windowEnd,
+		Events:      events,
+		Timelines:   []models.Timeline{},
+		GeneratedAt: now.UTC(),
+	}
+
+	lastDay := windowEnd.Add(-time.Nanosecond)
+	for day := windowStart; ; day = day.AddDate(0, 0, 1) {
+		date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+		if date.After(time.Date(lastDay.Year(), lastDay.Month(), lastDay.Day(), 0, 0, 0, 0, time.UTC)) {
+			break
+		}
+		timeline, err := s.timeline.BuildTimeline(familyID, date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build timeline for display projection: %w", err)
+		}
+		projection.Timelines = append(projection.Timelines, *timeline)
+	}
+
+	return projection, nil
+}
+
+func (s *DisplayProjectionService) save(projection *models.DisplayProjection) error {
+	payload, err := json.Marshal(projection)
+	if err != nil {
+		return fmt.Errorf("failed to marshal display projection: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO display_projections (family_id, window_start, window_end, payload, generated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(family_id) DO UPDATE SET
+			window_start = excluded.window_start, window_end = excluded.window_end,
+			payload = excluded.payload, generated_at = excluded.generated_at
+	`, projection.FamilyID, projection.WindowStart.UTC(), projection.WindowEnd.UTC(), string(payload), projection.GeneratedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save display projection: %w", err)
+	}
+	return nil
+}
+
+func (s *DisplayProjectionService) load(familyID string) (*models.DisplayProjection, error) {
+	var payload string
+	err := s.db.QueryRow(`SELECT payload FROM display_projections WHERE family_id = ?`, familyID).Scan(&payload)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("display projection not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get display projection: %w", err)
+	}
+
+	var projection models.DisplayProjection
+	if err := json.Unmarshal([]byte(payload), &projection); err != nil {
+		return nil, fmt.Errorf("failed to parse display projection: %w", err)
+	}
+	return &projection, nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"famstack/internal/eventbus"
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDisplayProjectionService(t *testing.T) (*DisplayProjectionService, *CalendarService, string, string) {
+	db := setupTestDB(t)
+	calendar := NewCalendarService(db)
+	tasks := NewTasksService(db)
+	timeline := NewTimelineService(db, calendar, tasks, NewSchedulesService(db), NewScheduleProfilesService(db), NewCustodyService(db))
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	bus := eventbus.New()
+	calendar.SetEventBus(bus)
+	projections := NewDisplayProjectionService(db, calendar, timeline)
+	// Tests refresh explicitly rather than racing the debounce timer
+	projections.debounce = time.Hour
+	projections.Subscribe(bus)
+
+	return projections, calendar, familyID, memberID
+}
+
+func TestDisplayProjectionService_InvalidatesOnCalendarChange(t *testing.T) {
+	projections, calendar, familyID, memberID := newTestDisplayProjectionService(t)
+	now := time.Date(2025, 10, 1, 8, 30, 0, 0, time.UTC)
+
+	projection, err := projections.Get(familyID, now)
+	require.NoError(t, err)
+	assert.False(t, projection.Stale)
+	assert.Empty(t, projection.Events)
+	assert.Equal(t, time.Date(2025, 10, 1, 8, 0, 0, 0, time.UTC), projection.WindowStart.UTC())
+	// 8am Oct 1 to 8am Oct 3 touches three days
+	require.Len(t, projection.Timelines, 3)
+	_, ok := projection.Timeline("2025-10-03")
+	assert.True(t, ok)
+	_, ok = projection.Timeline("2025-10-04")
+	assert.False(t, ok)
+
+	start := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	_, _, err = calendar.PutUnifiedCalendarEvent(context.Background(), familyID, memberID, &models.PutUnifiedCalendarEventRequest{
+		ID:        "dentist",
+		Title:     "Dentist",
+		StartTime: start,
+		EndTime:   start.Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	// The old projection is still served, flagged until the rebuild lands
+	projection, err = projections.Get(familyID, now)
+	require.NoError(t, err)
+	assert.True(t, projection.Stale)
+	assert.Empty(t, projection.Events)
+
+	_, err = projections.Refresh(familyID, now)
+	require.NoError(t, err)
+	projection, err = projections.Get(familyID, now)
+	require.NoError(t, err)
+	assert.False(t, projection.Stale)
+	require.Len(t, projection.Events, 1)
+	assert.Equal(t, "Dentist", projection.Events[0].Title)
+}
+
+func TestDisplayProjectionService_SurvivesRestartAndAges(t *testing.T) {
+	projections, _, familyID, _ := newTestDisplayProjectionService(t)
+	now := time.Date(2025, 10, 1, 8, 30, 0, 0, time.UTC)
+
+	_, err := projections.Refresh(familyID, now)
+	require.NoError(t, err)
+
+	restarted := NewDisplayProjectionService(projections.db, projections.calendar, projections.timeline)
+	restarted.debounce = time.Hour
+	projection, err := restarted.Get(familyID, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, projection.Stale)
+	assert.True(t, projection.GeneratedAt.Equal(now))
+
+	projection, err = restarted.Get(familyID, now.Add(displayProjectionMaxAge+time.Minute))
+	require.NoError(t, err)
+	assert.True(t, projection.Stale)
+
+	refreshed, err := restarted.RefreshStale(now.Add(displayProjectionMaxAge + time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, refreshed)
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/ids"
+	"famstack/internal/models"
+	"famstack/internal/validation"
+)
+
+// EventColorRulesService manages the ordered rules that categorize and color events
+type EventColorRulesService struct {
+	db *database.Fascade
+}
+
+// NewEventColorRulesService creates a new event color rules service
+func NewEventColorRulesService(db *database.Fascade) *EventColorRulesService {
+	return &EventColorRulesService{db: db}
+}
+
+const eventColorRuleColumns = `id, family_id, name, field, match_type, pattern, category, color, position, enabled, created_by, created_at, updated_at`
+
+// ListRules returns a family's rules in evaluation order
+func (s *EventColorRulesService) ListRules(familyID string) ([]models.EventColorRule, error) {
+	rows, err := s.db.Query(`SELECT `+eventColorRuleColumns+` FROM event_color_rules WHERE family_id = ? ORDER BY position, created_at`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query event color rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []models.EventColorRule{}
+	for rows.Next() {
+		rule, err := scanEventColorRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating event color rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// GetRule returns a color rule by ID
+func (s *EventColorRulesService) GetRule(ruleID string) (*models.EventColorRule, error) {
+	row := s.db.QueryRow(`SELECT `+eventColorRuleColumns+` FROM event_color_rules WHERE id = ?`, ruleID)
+	rule, err := scanEventColorRule(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("event color rule not found")
+	}
+	return rule, err
+}
+
+// CreateRule adds a rule after the family's existing rules
+func (s *EventColorRulesService) CreateRule(familyID, createdBy string, req *models.CreateEventColorRuleRequest) (*models.EventColorRule, error) {
+	name := strings.TrimSpace(req.Name)
+	pattern := strings.TrimSpace(req.Pattern)
+	category := strings.ToLower(strings.TrimSpace(req.Category))
+	if err := models.ValidateEventColorRule(name, req.Field, req.MatchType, pattern, category, req.Color); err != nil {
+		return nil, err
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	var createdByValue any
+	if createdBy != "" {
+		createdByValue = createdBy
+	}
+
+	var position int
+	err := s.db.QueryRow(`SELECT COALESCE(MAX(position), -1) + 1 FROM event_color_rules WHERE family_id = ?`, familyID).Scan(&position)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find rule position: %w", err)
+	}
+
+	ruleID := ids.New("colorrule")
+	now := time.Now().UTC()
+
+	_, err = s.db.Exec(`
+		INSERT INTO event_color_rules (id, family_id, name, field, match_type, pattern, category, color, position, enabled, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, ruleID, familyID, name, req.Field, req.MatchType, pattern, category, req.Color, position, enabled, createdByValue, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event color rule: %w", err)
+	}
+
+	return s.GetRule(ruleID)
+}
+
+// UpdateRule applies the provided fields to a color rule
+func (s *EventColorRulesService) UpdateRule(ruleID string, req *models.UpdateEventColorRuleRequest) (*models.EventColorRule, error) {
+	existing, err := s.GetRule(ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := *existing
+	if req.Name != nil {
+		merged.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Field != nil {
+		merged.Field = *req.Field
+	}
+	if req.MatchType != nil {
+		merged.MatchType = *req.MatchType
+	}
+	if req.Pattern != nil {
+		merged.Pattern = strings.TrimSpace(*req.Pattern)
+	}
+	if req.Category != nil {
+		merged.Category = strings.ToLower(strings.TrimSpace(*req.Category))
+	}
+	if req.Color != nil {
+		merged.Color = *req.Color
+	}
+	if req.Enabled != nil {
+		merged.Enabled = *req.Enabled
+	}
+
+	if err := models.ValidateEventColorRule(merged.Name, merged.Field, merged.MatchType, merged.Pattern, merged.Category, merged.Color); err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE event_color_rules
+		SET name = ?, field = ?, match_type = ?, pattern = ?, category = ?, color = ?, enabled = ?, updated_at = ?
+		WHERE id = ?
+	`, merged.Name, merged.Field, merged.MatchType, merged.Pattern, merged.Category, merged.Color, merged.Enabled, time.Now().UTC(), ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update event color rule: %w", err)
+	}
+
+	return s.GetRule(ruleID)
+}
+
+// DeleteRule removes a color rule. The remaining rules keep their relative order.
+func (s *EventColorRulesService) DeleteRule(ruleID string) error {
+	result, err := s.db.Exec(`DELETE FROM event_color_rules WHERE id = ?`, ruleID)
+	if err != nil {
+		return fmt.Errorf("failed to delete event color rule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("event color rule not found")
+	}
+
+	return nil
+}
+
+// ReorderRules sets the evaluation order. ruleIDs must list every rule of the
+// family exactly once so a stale client can't silently drop rules.
+func (s *EventColorRulesService) ReorderRules(familyID string, ruleIDs []string) ([]models.EventColorRule, error) {
+	existing, err := s.ListRules(familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(existing))
+	for _, rule := range existing {
+		known[rule.ID] = true
+	}
+
+	seen := make(map[string]bool, len(ruleIDs))
+	for _, ruleID := range ruleIDs {
+		if !known[ruleID] {
+			return nil, validation.ValidationErrors{{Field: "rule_ids", Message: fmt.Sprintf("rule %s does not belong to this family", ruleID)}}
+		}
+		if seen[ruleID] {
+			return nil, validation.ValidationErrors{{Field: "rule_ids", Message: fmt.Sprintf("rule %s is listed more than once", ruleID)}}
+		}
+		seen[ruleID] = true
+	}
+	if len(seen) != len(existing) {
+		return nil, validation.ValidationErrors{{Field: "rule_ids", Message: "rule_ids must list every rule of the family"}}
+	}
+
+	err = s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		now := time.Now().UTC()
+		for position, ruleID := range ruleIDs {
+			if _, err := tx.Exec(`UPDATE event_color_rules SET position = ?, updated_at = ? WHERE id = ?`, position, now, ruleID); err != nil {
+				return fmt.Errorf("failed to move rule %s: %w", ruleID, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to reorder event color rules: %w", err)
+	}
+
+	return s.ListRules(familyID)
+}
+
+// MatchRule returns the first enabled rule of the family matching the sample, or nil
+func (s *EventColorRulesService) MatchRule(familyID string, sample models.EventRuleSample) (*models.EventColorRule, error) {
+	rules, err := s.ListRules(familyID)
+	if err != nil {
+		return nil, err
+	}
+	return models.MatchEventColorRule(rules, sample), nil
+}
+
+// TestRules reports, for each sample, every enabled rule that matches and the
+// one that would be applied, without touching any events
+func (s *EventColorRulesService) TestRules(familyID string, samples []models.EventRuleSample) ([]models.EventRuleTestResult, error) {
+	rules, err := s.ListRules(familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.EventRuleTestResult, len(samples))
+	for i, sample := range samples {
+		results[i] = models.EventRuleTestResult{Event: sample, MatchedRules: []models.EventColorRule{}}
+		for _, rule := range rules {
+			if rule.Matches(sample) {
+				results[i].MatchedRules = append(results[i].MatchedRules, rule)
+			}
+		}
+		if len(results[i].MatchedRules) > 0 {
+			applied := results[i].MatchedRules[0]
+			results[i].AppliedRule = &applied
+			results[i].Category = applied.Category
+			results[i].Color = applied.Color
+		}
+	}
+
+	return results, nil
+}
+
+// scanEventColorRule scans an event color rule row
+func scanEventColorRule(scanner interface{ Scan(dest ...any) error }) (*models.EventColorRule, error) {
+	var rule models.EventColorRule
+	var createdBy sql.NullString
+
+	err := scanner.Scan(
+		&rule.ID, &rule.FamilyID, &rule.Name, &rule.Field, &rule.MatchType, &rule.Pattern,
+		&rule.Category, &rule.Color, &rule.Position, &rule.Enabled, &createdBy,
+		&rule.CreatedAt, &rule.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan event color rule: %w", err)
+	}
+
+	if createdBy.Valid {
+		rule.CreatedBy = &createdBy.String
+	}
+
+	return &rule, nil
+}
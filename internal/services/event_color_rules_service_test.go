@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+	"famstack/internal/validation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventColorRule_Matches(t *testing.T) {
+	dentist := models.EventColorRule{Field: models.ColorRuleFieldTitle, MatchType: models.ColorRuleMatchContains, Pattern: "Dentist", Enabled: true}
+	assert.True(t, dentist.Matches(models.EventRuleSample{Title: "Sam - dentist checkup"}))
+	assert.False(t, dentist.Matches(models.EventRuleSample{Title: "Soccer"}))
+
+	school := models.EventColorRule{Field: models.ColorRuleFieldOrganizer, MatchType: models.ColorRuleMatchDomain, Pattern: "@lincoln.k12.us", Enabled: true}
+	assert.True(t, school.Matches(models.EventRuleSample{Organizer: "office@lincoln.k12.us"}))
+	assert.True(t, school.Matches(models.EventRuleSample{Organizer: "Coach Lee <lee@sports.lincoln.k12.us>"}))
+	assert.False(t, school.Matches(models.EventRuleSample{Organizer: "someone@notlincoln.k12.us"}))
+
+	school.Enabled = false
+	assert.False(t, school.Matches(models.EventRuleSample{Organizer: "office@lincoln.k12.us"}))
+}
+
+func TestEventColorRule_MatchesSourceTypeAndAttendees(t *testing.T) {
+	google := models.EventColorRule{Field: models.ColorRuleFieldSource, MatchType: models.ColorRuleMatchEquals, Pattern: "google", Enabled: true}
+	assert.True(t, google.Matches(models.EventRuleSample{Source: "google", IntegrationID: "int_1"}))
+	assert.False(t, google.Matches(models.EventRuleSample{Source: models.EventSourceFamstack}))
+
+	feed := models.EventColorRule{Field: models.ColorRuleFieldSource, MatchType: models.ColorRuleMatchEquals, Pattern: "int_1", Enabled: true}
+	assert.True(t, feed.Matches(models.EventRuleSample{Source: "ics", IntegrationID: "int_1"}), "the integration ID is a source too")
+
+	reminders := models.EventColorRule{Field: models.ColorRuleFieldEventType, MatchType: models.ColorRuleMatchEquals, Pattern: "reminder", Enabled: true}
+	assert.True(t, reminders.Matches(models.EventRuleSample{EventType: "reminder"}))
+	assert.False(t, reminders.Matches(models.EventRuleSample{EventType: "event"}))
+
+	sam := models.EventColorRule{Field: models.ColorRuleFieldAttendee, MatchType: models.ColorRuleMatchEquals, Pattern: "mem_sam", Enabled: true}
+	assert.True(t, sam.Matches(models.EventRuleSample{Attendees: []string{"mem_alex", "mem_sam"}}), "any attendee matches")
+	assert.False(t, sam.Matches(models.EventRuleSample{Attendees: []string{"mem_alex"}}))
+	assert.False(t, sam.Matches(models.EventRuleSample{}))
+
+	work := models.EventColorRule{Field: models.ColorRuleFieldAttendee, MatchType: models.ColorRuleMatchDomain, Pattern: "acme.com", Enabled: true}
+	assert.True(t, work.Matches(models.EventRuleSample{Attendees: []string{"me@gmail.com", "boss@acme.com"}}))
+}
+
+func TestEventColorRulesService_OrderDecidesWinner(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewEventColorRulesService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	health, err := service.CreateRule(familyID, memberID, &models.CreateEventColorRuleRequest{
+		Name: "Health", Field: models.ColorRuleFieldTitle, MatchType: models.ColorRuleMatchContains,
+		Pattern: "dentist", Category: "Health", Color: "#ef4444",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "health", health.Category)
+	assert.Equal(t, 0, health.Position)
+
+	school, err := service.CreateRule(familyID, memberID, &models.CreateEventColorRuleRequest{
+		Name: "School", Field: models.ColorRuleFieldOrganizer, MatchType: models.ColorRuleMatchDomain,
+		Pattern: "lincoln.k12.us", Category: "school", Color: "#f59e0b",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, school.Position)
+
+	sample := models.EventRuleSample{Title: "Dentist visit at school", Organizer: "nurse@lincoln.k12.us"}
+	results, err := service.TestRules(familyID, []models.EventRuleSample{sample, {Title: "Soccer"}})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Len(t, results[0].MatchedRules, 2)
+	require.NotNil(t, results[0].AppliedRule)
+	assert.Equal(t, health.ID, results[0].AppliedRule.ID)
+	assert.Equal(t, "#ef4444", results[0].Color)
+	assert.Empty(t, results[1].MatchedRules)
+	assert.Nil(t, results[1].AppliedRule)
+
+	rules, err := service.ReorderRules(familyID, []string{school.ID, health.ID})
+	require.NoError(t, err)
+	assert.Equal(t, school.ID, rules[0].ID)
+
+	matched, err := service.MatchRule(familyID, sample)
+	require.NoError(t, err)
+	require.NotNil(t, matched)
+	assert.Equal(t, school.ID, matched.ID)
+
+	// Every rule must be listed exactly once
+	_, err = service.ReorderRules(familyID, []string{school.ID})
+	var validationErrs validation.ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
+	_, err = service.ReorderRules(familyID, []string{school.ID, school.ID})
+	require.ErrorAs(t, err, &validationErrs)
+}
+
+func TestEventColorRulesService_Validates(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewEventColorRulesService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	_, err := service.CreateRule(familyID, memberID, &models.CreateEventColorRuleRequest{
+		Name: "School", Field: models.ColorRuleFieldTitle, MatchType: models.ColorRuleMatchDomain, Pattern: "lincoln.k12.us", Color: "#f59e0b",
+	})
+	var validationErrs validation.ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
+
+	_, err = service.CreateRule(familyID, memberID, &models.CreateEventColorRuleRequest{
+		Name: "Nothing", Field: models.ColorRuleFieldTitle, MatchType: models.ColorRuleMatchContains, Pattern: "x",
+	})
+	require.ErrorAs(t, err, &validationErrs)
+
+	_, err = service.CreateRule(familyID, memberID, &models.CreateEventColorRuleRequest{
+		Name: "Google", Field: models.ColorRuleFieldSource, MatchType: models.ColorRuleMatchDomain, Pattern: "google", Color: "#4285f4",
+	})
+	require.ErrorAs(t, err, &validationErrs)
+
+	_, err = service.CreateRule(familyID, memberID, &models.CreateEventColorRuleRequest{
+		Name: "Work", Field: models.ColorRuleFieldAttendee, MatchType: models.ColorRuleMatchDomain, Pattern: "acme.com", Color: "#64748b",
+	})
+	require.NoError(t, err)
+}
+
+func TestBulkCreateUnifiedCalendarEvents_AppliesColorRules(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	_, err := NewEventColorRulesService(db).CreateRule(familyID, memberID, &models.CreateEventColorRuleRequest{
+		Name: "Health", Field: models.ColorRuleFieldTitle, MatchType: models.ColorRuleMatchContains,
+		Pattern: "dentist", Category: "health", Color: "#ef4444",
+	})
+	require.NoError(t, err)
+
+	start := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	items := []models.BulkCalendarEventItem{
+		{Title: "Dentist", StartTime: start, EndTime: start.Add(time.Hour)},
+		{Title: "Orthodontist dentist", StartTime: start, EndTime: start.Add(time.Hour), Color: "#00ff00"},
+		{Title: "Soccer", StartTime: start, EndTime: start.Add(time.Hour)},
+	}
+
+	response, err := service.BulkCreateUnifiedCalendarEvents(context.Background(), familyID, memberID, items)
+	require.NoError(t, err)
+	require.Equal(t, 3, response.Created)
+
+	expected := []struct{ color, category string }{
+		{"#ef4444", "health"},
+		{"#00ff00", "health"}, // an explicit color wins, the category still applies
+		{"#3b82f6", ""},
+	}
+	for i, result := range response.Results {
+		event, err := service.GetUnifiedCalendarEvent(result.EventID)
+		require.NoError(t, err)
+		assert.Equal(t, expected[i].color, event.Color, items[i].Title)
+		assert.Equal(t, expected[i].category, event.Category, items[i].Title)
+	}
+}
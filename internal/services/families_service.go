@@ -1,12 +1,14 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
 	"time"
 
 	"famstack/internal/database"
+	"famstack/internal/ids"
 	"famstack/internal/models"
 )
 
@@ -22,11 +24,12 @@ func NewFamiliesService(db *database.Fascade) *FamiliesService {
 
 // GetFamily returns a family by ID
 func (s *FamiliesService) GetFamily(familyID string) (*models.Family, error) {
-	query := `SELECT id, name, timezone, created_at FROM families WHERE id = ?`
+	query := `SELECT id, name, timezone, created_at, location_name, latitude, longitude FROM families WHERE id = ?`
 
 	var family models.Family
 	err := s.db.QueryRow(query, familyID).Scan(
 		&family.ID, &family.Name, &family.Timezone, &family.CreatedAt,
+		&family.LocationName, &family.Latitude, &family.Longitude,
 	)
 
 	if err != nil {
@@ -41,7 +44,7 @@ func (s *FamiliesService) GetFamily(familyID string) (*models.Family, error) {
 
 // ListFamilies returns all families (mainly for admin purposes)
 func (s *FamiliesService) ListFamilies() ([]models.Family, error) {
-	query := `SELECT id, name, timezone, created_at FROM families ORDER BY created_at DESC`
+	query := `SELECT id, name, timezone, created_at, location_name, latitude, longitude FROM families ORDER BY created_at DESC`
 
 	rows, err := s.db.Query(query)
 	if err != nil {
@@ -52,7 +55,8 @@ func (s *FamiliesService) ListFamilies() ([]models.Family, error) {
 	var families []models.Family
 	for rows.Next() {
 		var family models.Family
-		if scanErr := rows.Scan(&family.ID, &family.Name, &family.Timezone, &family.CreatedAt); scanErr != nil {
+		if scanErr := rows.Scan(&family.ID, &family.Name, &family.Timezone, &family.CreatedAt,
+			&family.LocationName, &family.Latitude, &family.Longitude); scanErr != nil {
 			return nil, fmt.Errorf("failed to scan family: %w", scanErr)
 		}
 		families = append(families, family)
@@ -91,15 +95,27 @@ func (s *FamiliesService) CreateFamily(name string) (*models.Family, error) {
 // UpdateFamily updates a family's information
 func (s *FamiliesService) UpdateFamily(familyID string, req *models.UpdateFamilyRequest) (*models.Family, error) {
 	// Check if there are any changes to make
-	if req.Name == nil && req.Timezone == nil {
+	locationChanged := req.ClearLocation || req.Latitude != nil || req.Longitude != nil
+	if req.Name == nil && req.Timezone == nil && req.LocationName == nil && !locationChanged {
 		return s.GetFamily(familyID) // No changes
 	}
 
 	// Validate timezone if provided
+	var oldTimezone string
 	if req.Timezone != nil {
 		if err := validateTimezone(*req.Timezone); err != nil {
 			return nil, fmt.Errorf("invalid timezone: %w", err)
 		}
+		current, err := s.GetSettings(familyID)
+		if err != nil {
+			return nil, err
+		}
+		oldTimezone = current.Timezone
+	}
+	if !req.ClearLocation && (req.Latitude != nil || req.Longitude != nil) {
+		if err := validateCoordinates(req.Latitude, req.Longitude); err != nil {
+			return nil, fmt.Errorf("invalid location: %w", err)
+		}
 	}
 
 	// Build dynamic query based on what fields are being updated
@@ -116,6 +132,18 @@ func (s *FamiliesService) UpdateFamily(familyID string, req *models.UpdateFamily
 		args = append(args, *req.Timezone)
 	}
 
+	switch {
+	case req.ClearLocation:
+		setParts = append(setParts, "location_name = NULL", "latitude = NULL", "longitude = NULL")
+	case locationChanged:
+		setParts = append(setParts, "latitude = ?", "longitude = ?")
+		args = append(args, *req.Latitude, *req.Longitude)
+	}
+	if req.LocationName != nil && !req.ClearLocation {
+		setParts = append(setParts, "location_name = ?")
+		args = append(args, optionalString(strings.TrimSpace(*req.LocationName)))
+	}
+
 	// Add familyID to args for the WHERE clause
 	args = append(args, familyID)
 
@@ -134,10 +162,134 @@ func (s *FamiliesService) UpdateFamily(familyID string, req *models.UpdateFamily
 	if rowsAffected == 0 {
 		return nil, fmt.Errorf("family not found")
 	}
+	FamilySettingsFor(s.db).Invalidate(familyID)
+
+	if req.Timezone != nil && *req.Timezone != oldTimezone {
+		if err := s.timezoneChanged(familyID, oldTimezone, *req.Timezone); err != nil {
+			return nil, err
+		}
+	} else if locationChanged {
+		// Forecasts for the old location no longer apply
+		if err := s.clearForecasts(familyID); err != nil {
+			return nil, err
+		}
+	}
 
 	return s.GetFamily(familyID)
 }
 
+// GetSettings returns how a family keeps time
+func (s *FamiliesService) GetSettings(familyID string) (*models.FamilySettings, error) {
+	settings, err := database.QueryOne[models.FamilySettings](s.db, `
+		SELECT id, COALESCE(timezone, '') AS timezone, locale, week_start, task_rollover
+		FROM families WHERE id = ?
+	`, familyID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("family not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family settings: %w", err)
+	}
+	if settings.Timezone == "" {
+		settings.Timezone = "UTC"
+	}
+	return settings, nil
+}
+
+// UpdateSettings saves the settings the request sets. Everything is
+// validated before anything is written.
+func (s *FamiliesService) UpdateSettings(familyID string, req *models.UpdateFamilySettingsRequest) (*models.FamilySettings, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	current, err := s.GetSettings(familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *current
+	if req.Timezone != nil {
+		updated.Timezone = *req.Timezone
+	}
+	if req.Locale != nil {
+		updated.Locale = *req.Locale
+	}
+	if req.WeekStart != nil {
+		updated.WeekStart = *req.WeekStart
+	}
+	if req.TaskRollover != nil {
+		updated.TaskRollover = *req.TaskRollover
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE families SET timezone = ?, locale = ?, week_start = ?, task_rollover = ? WHERE id = ?
+	`, updated.Timezone, updated.Locale, updated.WeekStart, updated.TaskRollover, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update family settings: %w", err)
+	}
+	FamilySettingsFor(s.db).Invalidate(familyID)
+
+	if updated.Timezone != current.Timezone {
+		if err := s.timezoneChanged(familyID, current.Timezone, updated.Timezone); err != nil {
+			return nil, err
+		}
+	}
+	return &updated, nil
+}
+
+// timezoneChanged brings what the family has scheduled into its new
+// timezone. Forecasts were for the old days, and tasks a schedule made keep
+// the schedule's time of day: a 7:00 chore is still due at 7:00 on the same
+// day, now in the new zone.
+func (s *FamiliesService) timezoneChanged(familyID, from, to string) error {
+	if err := s.clearForecasts(familyID); err != nil {
+		return err
+	}
+
+	type scheduledTask struct {
+		ID      string    `db:"id"`
+		DueDate time.Time `db:"due_date"`
+	}
+	tasks, err := database.QueryAll[scheduledTask](s.db, `
+		SELECT id, due_date FROM tasks
+		WHERE family_id = ? AND schedule_id IS NOT NULL AND status = 'pending'
+		AND due_date IS NOT NULL AND due_date >= ?
+	`, familyID, time.Now().UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return fmt.Errorf("failed to list scheduled tasks: %w", err)
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	return s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		for _, task := range tasks {
+			local, err := ConvertFromUTC(task.DueDate, from)
+			if err != nil {
+				return err
+			}
+			wallClock := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), 0, 0, time.UTC)
+			due, err := ConvertToUTC(wallClock, to)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec(`UPDATE tasks SET due_date = ? WHERE id = ?`, due.Format("2006-01-02 15:04:05"), task.ID); err != nil {
+				return fmt.Errorf("failed to move scheduled task: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// clearForecasts drops a family's forecasts, which no longer apply once it
+// moves or changes timezone
+func (s *FamiliesService) clearForecasts(familyID string) error {
+	if _, err := s.db.Exec(`DELETE FROM weather_forecasts WHERE family_id = ?`, familyID); err != nil {
+		return fmt.Errorf("failed to clear weather forecasts: %w", err)
+	}
+	return nil
+}
+
 // DeleteFamily deletes a family (and all associated data via CASCADE)
 func (s *FamiliesService) DeleteFamily(familyID string) error {
 	query := `DELETE FROM families WHERE id = ?`
@@ -155,6 +307,7 @@ func (s *FamiliesService) DeleteFamily(familyID string) error {
 	if rowsAffected == 0 {
 		return fmt.Errorf("family not found")
 	}
+	FamilySettingsFor(s.db).Invalidate(familyID)
 
 	return nil
 }
@@ -217,7 +370,7 @@ func (s *FamiliesService) GetFamilyStatistics(familyID string) (*models.FamilySt
 // Helper functions
 
 func generateFamilyID() string {
-	return fmt.Sprintf("fam_%d", time.Now().UTC().UnixNano())
+	return ids.New("fam")
 }
 
 // validateTimezone checks if a timezone string is valid
@@ -235,23 +388,33 @@ func validateTimezone(timezone string) error {
 	return nil
 }
 
-// GetFamilyTimezone retrieves the timezone for a family using the provided database connection
-// This is a utility function that can be used by other services
-func GetFamilyTimezone(db *database.Fascade, familyID string) (string, error) {
-	query := `SELECT timezone FROM families WHERE id = ?`
-	var timezone sql.NullString
-
-	err := db.QueryRow(query, familyID).Scan(&timezone)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return "UTC", nil // Default to UTC if family not found
-		}
-		return "", fmt.Errorf("failed to get family timezone: %w", err)
+// validateCoordinates checks a location is given whole and on the globe
+func validateCoordinates(latitude, longitude *float64) error {
+	if latitude == nil || longitude == nil {
+		return fmt.Errorf("latitude and longitude must be set together")
 	}
-
-	if !timezone.Valid || timezone.String == "" {
-		return "UTC", nil // Default to UTC if timezone is null or empty
+	if *latitude < -90 || *latitude > 90 {
+		return fmt.Errorf("latitude must be between -90 and 90")
+	}
+	if *longitude < -180 || *longitude > 180 {
+		return fmt.Errorf("longitude must be between -180 and 180")
 	}
+	return nil
+}
+
+// GetFamilyTimezone retrieves the timezone for a family using the provided
+// database connection, through the database's FamilySettings cache
+func GetFamilyTimezone(db *database.Fascade, familyID string) (string, error) {
+	return FamilySettingsFor(db).Timezone(familyID)
+}
+
+// GetFamilyLocale retrieves the locale for a family, through the database's
+// FamilySettings cache
+func GetFamilyLocale(db *database.Fascade, familyID string) (string, error) {
+	return FamilySettingsFor(db).Locale(familyID)
+}
 
-	return timezone.String, nil
+// Locale returns the locale a family's messages are worded in
+func (s *FamiliesService) Locale(familyID string) (string, error) {
+	return GetFamilyLocale(s.db, familyID)
 }
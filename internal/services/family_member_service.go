@@ -3,10 +3,13 @@ package services
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"famstack/internal/database"
+	"famstack/internal/ids"
 	"famstack/internal/models"
+	"famstack/internal/validation"
 )
 
 // FamilyMemberService handles family member operations
@@ -26,7 +29,7 @@ func (s *FamilyMemberService) ListFamilyMembers(familyID string) ([]*models.Fami
 	query := `
 		SELECT id, family_id, first_name, last_name, member_type,
 			   avatar_url, email, role, email_verified, last_login_at,
-			   display_order, is_active, created_at, updated_at
+			   display_order, is_active, birthdate, anniversary, created_at, updated_at
 		FROM family_members
 		WHERE family_id = ? AND is_active = true
 		ORDER BY display_order ASC, created_at ASC
@@ -59,19 +62,29 @@ func (s *FamilyMemberService) GetFamilyMember(memberID string) (*models.FamilyMe
 	query := `
 		SELECT id, family_id, first_name, last_name, member_type,
 			   avatar_url, email, role, email_verified, last_login_at,
-			   display_order, is_active, created_at, updated_at
+			   display_order, is_active, birthdate, anniversary, created_at, updated_at
 		FROM family_members
 		WHERE id = ?
 	`
 
-	row := s.db.QueryRow(query, memberID)
-	return s.scanFamilyMember(row)
+	member, err := database.QueryOne[models.FamilyMember](s.db, query, memberID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toFamilyTime(member)
 }
 
 // CreateFamilyMember creates a new family member
 func (s *FamilyMemberService) CreateFamilyMember(familyID string, req *models.CreateFamilyMemberRequest) (*models.FamilyMember, error) {
+	validator := validation.NewValidator()
+	birthdate := memberDate(validator, "birthdate", req.Birthdate)
+	anniversary := memberDate(validator, "anniversary", req.Anniversary)
+	if err := validator.ToError(); err != nil {
+		return nil, err
+	}
+
 	// Generate ID
-	memberID := fmt.Sprintf("member-%d", time.Now().UTC().UnixNano())
+	memberID := ids.New("member")
 
 	// Set default display order if not provided
 	displayOrder := 0
@@ -80,11 +93,13 @@ func (s *FamilyMemberService) CreateFamilyMember(familyID string, req *models.Cr
 	}
 
 	query := `
-		INSERT INTO family_members (id, family_id, first_name, last_name, member_type, avatar_url, display_order, is_active, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, true, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		INSERT INTO family_members (id, family_id, first_name, last_name, member_type, avatar_url, display_order,
+									birthdate, anniversary, is_active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, true, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 	`
 
-	_, err := s.db.Exec(query, memberID, familyID, req.FirstName, req.LastName, req.MemberType, req.AvatarURL, displayOrder)
+	_, err := s.db.Exec(query, memberID, familyID, req.FirstName, req.LastName, req.MemberType, req.AvatarURL, displayOrder,
+		birthdate, anniversary)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create family member: %w", err)
 	}
@@ -114,6 +129,10 @@ func (s *FamilyMemberService) UpdateFamilyMember(memberID string, req *models.Up
 		setParts = append(setParts, "avatar_url = ?")
 		args = append(args, *req.AvatarURL)
 	}
+	if req.Color != nil {
+		setParts = append(setParts, "color = ?")
+		args = append(args, *req.Color)
+	}
 	if req.DisplayOrder != nil {
 		setParts = append(setParts, "display_order = ?")
 		args = append(args, *req.DisplayOrder)
@@ -123,6 +142,19 @@ func (s *FamilyMemberService) UpdateFamilyMember(memberID string, req *models.Up
 		args = append(args, *req.IsActive)
 	}
 
+	validator := validation.NewValidator()
+	if req.Birthdate != nil {
+		setParts = append(setParts, "birthdate = ?")
+		args = append(args, memberDate(validator, "birthdate", req.Birthdate))
+	}
+	if req.Anniversary != nil {
+		setParts = append(setParts, "anniversary = ?")
+		args = append(args, memberDate(validator, "anniversary", req.Anniversary))
+	}
+	if err := validator.ToError(); err != nil {
+		return nil, err
+	}
+
 	if len(setParts) == 1 { // Only updated_at
 		return s.GetFamilyMember(memberID) // No changes, return current
 	}
@@ -165,6 +197,25 @@ func (s *FamilyMemberService) DeleteFamilyMember(memberID string) error {
 	return nil
 }
 
+// memberDate checks a birthdate or anniversary is a YYYY-MM-DD date that has
+// passed, returning the value to store: nil when it is empty
+func memberDate(validator *validation.Validator, field string, value *string) *string {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil
+	}
+	date := strings.TrimSpace(*value)
+	parsed, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		validator.AddErrorf(field, "%s must be a date like 2015-06-12", field)
+		return nil
+	}
+	if parsed.After(time.Now()) {
+		validator.AddErrorf(field, "%s must not be in the future", field)
+		return nil
+	}
+	return &date
+}
+
 // FIXME: These functions reference non-existent 'user_id' column in family_members table
 // The current schema shows family members CAN BE users with email/password fields directly
 // These functions should be removed or redesigned to match actual schema
@@ -188,7 +239,7 @@ func (s *FamilyMemberService) GetFamilyMembersWithStats(familyID string) ([]*mod
 	query := `
 		SELECT fm.id, fm.family_id, fm.first_name, fm.last_name, fm.member_type,
 			   fm.avatar_url, fm.email, fm.role, fm.email_verified, fm.last_login_at,
-			   fm.display_order, fm.is_active, fm.created_at, fm.updated_at,
+			   fm.display_order, fm.is_active, fm.birthdate, fm.anniversary, fm.created_at, fm.updated_at,
 			   COUNT(t.id) as total_tasks,
 			   COUNT(CASE WHEN t.status = 'completed' THEN 1 END) as completed_tasks,
 			   COUNT(CASE WHEN t.status != 'completed' THEN 1 END) as pending_tasks
@@ -197,7 +248,7 @@ func (s *FamilyMemberService) GetFamilyMembersWithStats(familyID string) ([]*mod
 		WHERE fm.family_id = ? AND fm.is_active = true
 		GROUP BY fm.id, fm.family_id, fm.first_name, fm.last_name, fm.member_type,
 				 fm.avatar_url, fm.email, fm.role, fm.email_verified, fm.last_login_at,
-				 fm.display_order, fm.is_active, fm.created_at, fm.updated_at
+				 fm.display_order, fm.is_active, fm.birthdate, fm.anniversary, fm.created_at, fm.updated_at
 		ORDER BY fm.display_order ASC, fm.created_at ASC
 	`
 
@@ -225,99 +276,68 @@ func (s *FamilyMemberService) GetFamilyMembersWithStats(familyID string) ([]*mod
 
 // Helper functions
 
-func (s *FamilyMemberService) scanFamilyMember(scanner interface {
-	Scan(dest ...any) error
-}) (*models.FamilyMember, error) {
+func (s *FamilyMemberService) scanFamilyMember(rows *sql.Rows) (*models.FamilyMember, error) {
 	var member models.FamilyMember
-	var email, role sql.NullString
-	var lastLoginAt sql.NullTime
-
-	err := scanner.Scan(
-		&member.ID, &member.FamilyID, &member.FirstName, &member.LastName, &member.MemberType,
-		&member.AvatarURL, &email, &role, &member.EmailVerified, &lastLoginAt,
-		&member.DisplayOrder, &member.IsActive, &member.CreatedAt, &member.UpdatedAt,
-	)
-	if err != nil {
+	if err := database.ScanStruct(rows, &member); err != nil {
 		return nil, err
 	}
 
-	// Get family timezone for conversions
-	familyTimezone, err := GetFamilyTimezone(s.db, member.FamilyID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get family timezone for family member conversion: %w", err)
-	}
+	return s.toFamilyTime(&member)
+}
 
-	// Handle nullable fields
-	if email.Valid {
-		member.Email = &email.String
-	}
-	if role.Valid {
-		member.Role = &role.String
-	}
-	if lastLoginAt.Valid {
-		// Convert LastLoginAt from UTC to family timezone
-		convertedLastLogin, convErr := ConvertFromUTC(lastLoginAt.Time, familyTimezone)
-		if convErr != nil {
-			return nil, fmt.Errorf("failed to convert last login time from UTC: %w", convErr)
-		}
-		member.LastLoginAt = &convertedLastLogin
-	}
+// familyMemberStatsRow adds the task counts selected alongside a member
+type familyMemberStatsRow struct {
+	models.FamilyMember
+	TotalTasks     int `db:"total_tasks"`
+	CompletedTasks int `db:"completed_tasks"`
+	PendingTasks   int `db:"pending_tasks"`
+}
 
-	// Convert CreatedAt and UpdatedAt from UTC to family timezone
-	member.CreatedAt, err = ConvertFromUTC(member.CreatedAt, familyTimezone)
-	if err != nil {
-		return nil, fmt.Errorf("failed to convert created at from UTC: %w", err)
+func (s *FamilyMemberService) scanFamilyMemberWithStats(rows *sql.Rows) (*models.FamilyMemberWithStats, error) {
+	var row familyMemberStatsRow
+	if err := database.ScanStruct(rows, &row); err != nil {
+		return nil, err
 	}
 
-	member.UpdatedAt, err = ConvertFromUTC(member.UpdatedAt, familyTimezone)
+	member, err := s.toFamilyTime(&row.FamilyMember)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert updated at from UTC: %w", err)
+		return nil, err
 	}
 
-	return &member, nil
-}
+	// Calculate completion rate
+	var completionRate float64
+	if row.TotalTasks > 0 {
+		completionRate = float64(row.CompletedTasks) / float64(row.TotalTasks) * 100
+	}
 
-func (s *FamilyMemberService) scanFamilyMemberWithStats(scanner interface {
-	Scan(dest ...any) error
-}) (*models.FamilyMemberWithStats, error) {
-	var member models.FamilyMember
-	var email, role sql.NullString
-	var lastLoginAt sql.NullTime
-	var totalTasks, completedTasks, pendingTasks int
-
-	err := scanner.Scan(
-		&member.ID, &member.FamilyID, &member.FirstName, &member.LastName, &member.MemberType,
-		&member.AvatarURL, &email, &role, &member.EmailVerified, &lastLoginAt,
-		&member.DisplayOrder, &member.IsActive, &member.CreatedAt, &member.UpdatedAt,
-		&totalTasks, &completedTasks, &pendingTasks,
-	)
-	if err != nil {
-		return nil, err
+	stats := models.TaskStats{
+		TotalTasks:     row.TotalTasks,
+		CompletedTasks: row.CompletedTasks,
+		PendingTasks:   row.PendingTasks,
+		CompletionRate: completionRate,
 	}
 
-	// Get family timezone for conversions
+	return &models.FamilyMemberWithStats{
+		FamilyMember: *member,
+		TaskStats:    stats,
+	}, nil
+}
+
+// toFamilyTime converts a member's stored UTC times to the family timezone
+func (s *FamilyMemberService) toFamilyTime(member *models.FamilyMember) (*models.FamilyMember, error) {
 	familyTimezone, err := GetFamilyTimezone(s.db, member.FamilyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get family timezone for family member conversion: %w", err)
 	}
 
-	// Handle nullable fields
-	if email.Valid {
-		member.Email = &email.String
-	}
-	if role.Valid {
-		member.Role = &role.String
-	}
-	if lastLoginAt.Valid {
-		// Convert LastLoginAt from UTC to family timezone
-		convertedLastLogin, convErr := ConvertFromUTC(lastLoginAt.Time, familyTimezone)
+	if member.LastLoginAt != nil {
+		convertedLastLogin, convErr := ConvertFromUTC(*member.LastLoginAt, familyTimezone)
 		if convErr != nil {
 			return nil, fmt.Errorf("failed to convert last login time from UTC: %w", convErr)
 		}
 		member.LastLoginAt = &convertedLastLogin
 	}
 
-	// Convert CreatedAt and UpdatedAt from UTC to family timezone
 	member.CreatedAt, err = ConvertFromUTC(member.CreatedAt, familyTimezone)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert created at from UTC: %w", err)
@@ -328,21 +348,5 @@ func (s *FamilyMemberService) scanFamilyMemberWithStats(scanner interface {
 		return nil, fmt.Errorf("failed to convert updated at from UTC: %w", err)
 	}
 
-	// Calculate completion rate
-	var completionRate float64
-	if totalTasks > 0 {
-		completionRate = float64(completedTasks) / float64(totalTasks) * 100
-	}
-
-	stats := models.TaskStats{
-		TotalTasks:     totalTasks,
-		CompletedTasks: completedTasks,
-		PendingTasks:   pendingTasks,
-		CompletionRate: completionRate,
-	}
-
-	return &models.FamilyMemberWithStats{
-		FamilyMember: member,
-		TaskStats:    stats,
-	}, nil
+	return member, nil
 }
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is wrapped by the errors of lookups that find nothing, as in
+// "task not found". Methods that take a familyID treat another family's
+// record as not found too, so a caller can't learn whether an ID exists
+// outside their family.
+var ErrNotFound = errors.New("not found")
+
+// notFound returns the error for a missing record, named by what
+func notFound(what string) error {
+	return fmt.Errorf("%s %w", what, ErrNotFound)
+}
+
+// inFamily returns the not found error for what unless the record, in
+// recordFamilyID, belongs to familyID
+func inFamily(what, recordFamilyID, familyID string) error {
+	if recordFamilyID != familyID {
+		return notFound(what)
+	}
+	return nil
+}
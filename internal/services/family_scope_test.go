@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedOtherFamily adds a second family, next to seedBulkEventFamily's, for
+// reaching across families
+func seedOtherFamily(t testing.TB, db *database.Fascade) string {
+	familyID := "fam_other"
+	_, err := db.Exec(`INSERT INTO families (id, name, timezone) VALUES (?, ?, ?)`, familyID, "Other Family", "UTC")
+	require.NoError(t, err)
+	return familyID
+}
+
+func TestFamilyScope_Tasks(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewTasksService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+	otherFamilyID := seedOtherFamily(t, db)
+
+	task, err := service.CreateTask(familyID, memberID, &models.CreateTaskRequest{Title: "Dishes", TaskType: models.TaskTypeChore})
+	require.NoError(t, err)
+
+	_, err = service.GetFamilyTask(otherFamilyID, task.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = service.GetFamilyTask(familyID, "task_missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	completed := models.TaskStatusCompleted
+	_, err = service.UpdateTask(otherFamilyID, task.ID, &models.UpdateTaskRequest{Status: &completed})
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, service.DeleteTask(otherFamilyID, task.ID), ErrNotFound)
+
+	unchanged, err := service.GetFamilyTask(familyID, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusPending, unchanged.Status)
+
+	require.NoError(t, service.DeleteTask(familyID, task.ID))
+}
+
+func TestFamilyScope_Schedules(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewSchedulesService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+	otherFamilyID := seedOtherFamily(t, db)
+
+	schedule, err := service.CreateSchedule(familyID, memberID, &models.CreateTaskScheduleRequest{
+		Title: "Bins", TaskType: models.TaskTypeChore, DaysOfWeek: []string{"tuesday"},
+	})
+	require.NoError(t, err)
+
+	_, err = service.GetFamilySchedule(otherFamilyID, schedule.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	title := "Recycling"
+	_, err = service.UpdateSchedule(otherFamilyID, schedule.ID, &models.UpdateTaskScheduleRequest{Title: &title})
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, service.DeleteSchedule(otherFamilyID, schedule.ID), ErrNotFound)
+	assert.ErrorIs(t, service.SetRotationIndex(otherFamilyID, schedule.ID, 1), ErrNotFound)
+	assert.ErrorIs(t, service.DeactivateSchedule(otherFamilyID, schedule.ID), ErrNotFound)
+
+	unchanged, err := service.GetFamilySchedule(familyID, schedule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Bins", unchanged.Title)
+	assert.True(t, unchanged.Active)
+
+	require.NoError(t, service.DeleteSchedule(familyID, schedule.ID))
+}
+
+func TestFamilyScope_CalendarEvents(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewCalendarService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+	otherFamilyID := seedOtherFamily(t, db)
+	start := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	_, err := db.Exec(`
+		INSERT INTO calendar_events (id, external_source, family_id, title, start_time, end_time, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, "evt_dentist", "manual", familyID, "Dentist", start, start.Add(time.Hour), memberID)
+	require.NoError(t, err)
+
+	_, err = service.GetFamilyEvent(otherFamilyID, "evt_dentist")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	title := "Orthodontist"
+	_, err = service.UpdateEvent(otherFamilyID, "evt_dentist", &models.UpdateCalendarEventRequest{Title: &title})
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, service.DeleteEvent(otherFamilyID, "evt_dentist"), ErrNotFound)
+
+	unchanged, err := service.GetFamilyEvent(familyID, "evt_dentist")
+	require.NoError(t, err)
+	assert.Equal(t, "Dentist", unchanged.Title)
+
+	response, err := service.BulkCreateUnifiedCalendarEvents(context.Background(), familyID, memberID, []models.BulkCalendarEventItem{
+		{Title: "Soccer", StartTime: start, EndTime: start.Add(time.Hour)},
+	})
+	require.NoError(t, err)
+	unifiedID := response.Results[0].EventID
+
+	_, err = service.GetUnifiedCalendarEventFor(nil, otherFamilyID, unifiedID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = service.UpdateUnifiedCalendarEvent(context.Background(), otherFamilyID, unifiedID, &models.UpdateUnifiedCalendarEventRequest{Title: &title})
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, service.DeleteUnifiedCalendarEvent(otherFamilyID, unifiedID, ""), ErrNotFound)
+
+	soccer, err := service.GetUnifiedCalendarEventFor(nil, familyID, unifiedID)
+	require.NoError(t, err)
+	assert.Equal(t, "Soccer", soccer.Title)
+}
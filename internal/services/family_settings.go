@@ -0,0 +1,125 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/models"
+)
+
+// familySettingsTTL bounds how long a cached setting is trusted, covering
+// changes made to the database by something other than this process
+const familySettingsTTL = 5 * time.Minute
+
+// FamilySettings caches the per-family settings that nearly every listing
+// needs, so converting a list of rows to family time reads the families
+// table once rather than once per row. There is one per database, shared by
+// every service using it; FamiliesService invalidates a family's entry when
+// it changes.
+type FamilySettings struct {
+	db  *database.Fascade
+	now func() time.Time
+
+	mu       sync.Mutex
+	families map[string]cachedFamily
+}
+
+type cachedFamily struct {
+	timezone string
+	locale   string
+	loadedAt time.Time
+}
+
+// familySettings holds the FamilySettings of each database
+var familySettings sync.Map // *database.Fascade -> *FamilySettings
+
+// FamilySettingsFor returns the settings cache shared by services using db
+func FamilySettingsFor(db *database.Fascade) *FamilySettings {
+	if settings, ok := familySettings.Load(db); ok {
+		return settings.(*FamilySettings)
+	}
+	settings, _ := familySettings.LoadOrStore(db, &FamilySettings{
+		db:       db,
+		now:      time.Now,
+		families: make(map[string]cachedFamily),
+	})
+	return settings.(*FamilySettings)
+}
+
+// Timezone returns the family's timezone, UTC when it has none. A family
+// that doesn't exist yet also reads as UTC but isn't cached, so one created
+// a moment later gets its own timezone.
+func (f *FamilySettings) Timezone(familyID string) (string, error) {
+	family, err := f.load(familyID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get family timezone: %w", err)
+	}
+	return family.timezone, nil
+}
+
+// Locale returns the language tag the family's messages are worded in
+func (f *FamilySettings) Locale(familyID string) (string, error) {
+	family, err := f.load(familyID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get family locale: %w", err)
+	}
+	return family.locale, nil
+}
+
+func (f *FamilySettings) load(familyID string) (cachedFamily, error) {
+	now := f.now()
+	f.mu.Lock()
+	cached, ok := f.families[familyID]
+	f.mu.Unlock()
+	if ok && now.Sub(cached.loadedAt) < familySettingsTTL {
+		return cached, nil
+	}
+
+	var timezone sql.NullString
+	var locale string
+	err := f.db.QueryRow(`SELECT timezone, locale FROM families WHERE id = ?`, familyID).Scan(&timezone, &locale)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Default to UTC if family not found
+			return cachedFamily{timezone: "UTC", locale: models.DefaultLocale}, nil
+		}
+		return cachedFamily{}, err
+	}
+
+	result := cachedFamily{timezone: "UTC", locale: locale, loadedAt: now} // Default to UTC if timezone is null or empty
+	if timezone.Valid && timezone.String != "" {
+		result.timezone = timezone.String
+	}
+
+	f.mu.Lock()
+	f.families[familyID] = result
+	f.mu.Unlock()
+	return result, nil
+}
+
+// Invalidate drops what is cached for a family, after it changes
+func (f *FamilySettings) Invalidate(familyID string) {
+	f.mu.Lock()
+	delete(f.families, familyID)
+	f.mu.Unlock()
+}
+
+// locations caches loaded timezones by name, as time.LoadLocation reads the
+// zone database on every call
+var locations sync.Map // string -> *time.Location
+
+// loadLocation is time.LoadLocation, cached
+func loadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
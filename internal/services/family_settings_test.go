@@ -0,0 +1,108 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+	"famstack/internal/validation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFamilySettings_CachesTimezoneUntilFamilyChanges(t *testing.T) {
+	db := setupTestDB(t)
+	familyID, _ := seedBulkEventFamily(t, db)
+	settings := FamilySettingsFor(db)
+	assert.Same(t, settings, FamilySettingsFor(db), "services on one database share the cache")
+
+	timezone, err := settings.Timezone(familyID)
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", timezone)
+
+	// A change behind the service's back isn't seen until the entry expires
+	_, err = db.Exec(`UPDATE families SET timezone = ? WHERE id = ?`, "America/Chicago", familyID)
+	require.NoError(t, err)
+	timezone, err = GetFamilyTimezone(db, familyID)
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", timezone)
+
+	denver := "America/Denver"
+	_, err = NewFamiliesService(db).UpdateFamily(familyID, &models.UpdateFamilyRequest{Timezone: &denver})
+	require.NoError(t, err)
+	timezone, err = GetFamilyTimezone(db, familyID)
+	require.NoError(t, err)
+	assert.Equal(t, denver, timezone)
+
+	// A family that doesn't exist yet isn't cached as UTC
+	timezone, err = settings.Timezone("fam_later")
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", timezone)
+	_, err = db.Exec(`INSERT INTO families (id, name, timezone) VALUES (?, ?, ?)`, "fam_later", "Later", "Europe/Paris")
+	require.NoError(t, err)
+	timezone, err = settings.Timezone("fam_later")
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Paris", timezone)
+}
+
+func TestFamiliesService_UpdateSettings(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewFamiliesService(db)
+	tasks := NewTasksService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	settings, err := service.GetSettings(familyID)
+	require.NoError(t, err)
+	assert.Equal(t, models.FamilySettings{FamilyID: familyID, Timezone: "UTC", Locale: models.DefaultLocale,
+		WeekStart: models.WeekStartSunday, TaskRollover: models.TaskRolloverKeep}, *settings)
+
+	// A generated chore due at 07:00 family time
+	_, err = db.Exec(`INSERT INTO task_schedules (id, family_id, created_by, title, task_type, days_of_week, points, active) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"sched_beds", familyID, memberID, "Make beds", "chore", `["monday"]`, 1, true)
+	require.NoError(t, err)
+	day := time.Now().UTC().AddDate(0, 0, 3)
+	due := time.Date(day.Year(), day.Month(), day.Day(), 7, 0, 0, 0, time.UTC)
+	require.NoError(t, tasks.BulkCreateTasks(familyID, memberID, []BulkTaskRequest{
+		{Title: "Make beds", TaskType: "chore", DueDate: &due, ScheduleID: "sched_beds"},
+	}))
+
+	timezone, locale, weekStart, rollover := "America/Chicago", "en-GB", models.WeekStartMonday, models.TaskRolloverRollForward
+	settings, err = service.UpdateSettings(familyID, &models.UpdateFamilySettingsRequest{
+		Timezone: &timezone, Locale: &locale, WeekStart: &weekStart, TaskRollover: &rollover,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.FamilySettings{FamilyID: familyID, Timezone: timezone, Locale: locale,
+		WeekStart: weekStart, TaskRollover: rollover}, *settings)
+
+	// The chore keeps its time of day in the new timezone
+	list, err := tasks.ListTasksForFamily(familyID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "07:00", list[0].DueDate.Format("15:04"))
+	assert.Equal(t, due.Format("2006-01-02"), list[0].DueDate.Format("2006-01-02"))
+	assert.Equal(t, timezone, list[0].DueDate.Location().String())
+
+	// Members who never chose a week start get the family's
+	members := NewFamilyMemberService(db)
+	preferences := NewMemberPreferencesService(db, members, NewDisplayPreferencesService(db, service, members), NewNotificationsService(db))
+	render, err := preferences.RenderPreferences(familyID, memberID)
+	require.NoError(t, err)
+	assert.Equal(t, weekStart, render.WeekStart)
+
+	mars, english, wednesday, forget := "Mars/Olympus_Mons", "english please", "wednesday", "forget"
+	for field, req := range map[string]models.UpdateFamilySettingsRequest{
+		"timezone":      {Timezone: &mars},
+		"locale":        {Locale: &english},
+		"week_start":    {WeekStart: &wednesday},
+		"task_rollover": {TaskRollover: &forget},
+	} {
+		_, err = service.UpdateSettings(familyID, &req)
+		var validationErrs validation.ValidationErrors
+		require.ErrorAs(t, err, &validationErrs, field)
+		assert.Equal(t, field, validationErrs[0].Field)
+	}
+
+	_, err = service.GetSettings("fam_missing")
+	assert.EqualError(t, err, "family not found")
+}
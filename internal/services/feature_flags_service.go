@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/models"
+	"famstack/internal/validation"
+)
+
+// FeatureFlagsService works out which feature flags are on for a family,
+// from the defaults in models.FeatureFlags and the overrides stored for
+// every family or for one
+type FeatureFlagsService struct {
+	db *database.Fascade
+}
+
+// NewFeatureFlagsService creates a new feature flags service
+func NewFeatureFlagsService(db *database.Fascade) *FeatureFlagsService {
+	return &FeatureFlagsService{db: db}
+}
+
+// Flags returns every flag as it stands for the family. An empty familyID
+// gives the flags as they stand for a family with no overrides of its own.
+func (s *FeatureFlagsService) Flags(familyID string) ([]models.FeatureFlagState, error) {
+	rows, err := s.db.Query(`
+		SELECT flag, family_id, enabled
+		FROM feature_flag_overrides
+		WHERE family_id IS NULL OR family_id = ?
+	`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feature flag overrides: %w", err)
+	}
+	defer rows.Close()
+
+	global, family := map[string]bool{}, map[string]bool{}
+	for rows.Next() {
+		var flag string
+		var overrideFamily sql.NullString
+		var enabled bool
+		if err := rows.Scan(&flag, &overrideFamily, &enabled); err != nil {
+			return nil, fmt.Errorf("failed to scan feature flag override: %w", err)
+		}
+		if overrideFamily.Valid {
+			family[flag] = enabled
+		} else {
+			global[flag] = enabled
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read feature flag overrides: %w", err)
+	}
+
+	states := make([]models.FeatureFlagState, 0, len(models.FeatureFlags))
+	for _, flag := range models.FeatureFlags {
+		state := models.FeatureFlagState{FeatureFlag: flag}
+		if enabled, ok := global[flag.Key]; ok {
+			state.GlobalOverride = &enabled
+		}
+		if enabled, ok := family[flag.Key]; ok {
+			state.FamilyOverride = &enabled
+		}
+		state.Resolve()
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// Enabled reports whether a flag is on for the family
+func (s *FeatureFlagsService) Enabled(familyID, key string) (bool, error) {
+	states, err := s.Flags(familyID)
+	if err != nil {
+		return false, err
+	}
+	for _, state := range states {
+		if state.Key == key {
+			return state.Enabled, nil
+		}
+	}
+	return false, fmt.Errorf("unknown feature flag %q", key)
+}
+
+// SetOverride turns a flag on or off for the family, or for every family
+// when familyID is empty. A nil enabled clears the override, so the flag
+// falls back to the global override or its default.
+func (s *FeatureFlagsService) SetOverride(familyID, key string, enabled *bool, updatedBy string) error {
+	validator := validation.NewValidator()
+	validator.OneOf("flag", key, models.FeatureFlagKeys())
+	if err := validator.ToError(); err != nil {
+		return err
+	}
+
+	return s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		scope, args := "family_id IS NULL", []any{key}
+		if familyID != "" {
+			scope, args = "family_id = ?", append(args, familyID)
+		}
+		if _, err := tx.Exec(`DELETE FROM feature_flag_overrides WHERE flag = ? AND `+scope, args...); err != nil {
+			return fmt.Errorf("failed to clear feature flag override: %w", err)
+		}
+
+		if enabled != nil {
+			if _, err := tx.Exec(`
+				INSERT INTO feature_flag_overrides (id, flag, family_id, enabled, updated_by, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?)
+			`, generateID(), key, optionalString(familyID), *enabled, optionalString(updatedBy), time.Now().UTC()); err != nil {
+				return fmt.Errorf("failed to save feature flag override: %w", err)
+			}
+		}
+		return nil
+	})
+}
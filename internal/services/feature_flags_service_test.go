@@ -0,0 +1,77 @@
+package services
+
+import (
+	"testing"
+
+	"famstack/internal/models"
+	"famstack/internal/validation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func featureState(t *testing.T, service *FeatureFlagsService, familyID, key string) models.FeatureFlagState {
+	t.Helper()
+	flags, err := service.Flags(familyID)
+	require.NoError(t, err)
+	for _, flag := range flags {
+		if flag.Key == key {
+			return flag
+		}
+	}
+	t.Fatalf("flag %s not listed", key)
+	return models.FeatureFlagState{}
+}
+
+func TestFeatureFlagsService_Overrides(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewFeatureFlagsService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+	off, on := false, true
+
+	state := featureState(t, service, familyID, models.FeatureMeals)
+	assert.True(t, state.Enabled)
+	assert.Equal(t, models.FeatureSourceDefault, state.Source)
+
+	// Turned off for every family
+	require.NoError(t, service.SetOverride("", models.FeatureMeals, &off, memberID))
+	state = featureState(t, service, familyID, models.FeatureMeals)
+	assert.False(t, state.Enabled)
+	assert.Equal(t, models.FeatureSourceGlobal, state.Source)
+
+	// The family's own override wins, and setting it again replaces it
+	require.NoError(t, service.SetOverride(familyID, models.FeatureMeals, &off, memberID))
+	require.NoError(t, service.SetOverride(familyID, models.FeatureMeals, &on, memberID))
+	enabled, err := service.Enabled(familyID, models.FeatureMeals)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+	state = featureState(t, service, familyID, models.FeatureMeals)
+	assert.Equal(t, models.FeatureSourceFamily, state.Source)
+	require.NotNil(t, state.GlobalOverride)
+	assert.False(t, *state.GlobalOverride)
+
+	// Other families only see the global override
+	assert.False(t, featureState(t, service, "", models.FeatureMeals).Enabled)
+
+	// Clearing the family's override falls back to the global one
+	require.NoError(t, service.SetOverride(familyID, models.FeatureMeals, nil, memberID))
+	enabled, err = service.Enabled(familyID, models.FeatureMeals)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	// Other flags are untouched
+	assert.True(t, featureState(t, service, familyID, models.FeatureRewards).Enabled)
+}
+
+func TestFeatureFlagsService_UnknownFlag(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewFeatureFlagsService(db)
+	on := true
+
+	err := service.SetOverride("", "teleporter", &on, "")
+	var validationErrs validation.ValidationErrors
+	assert.ErrorAs(t, err, &validationErrs)
+
+	_, err = service.Enabled("", "teleporter")
+	assert.Error(t, err)
+}
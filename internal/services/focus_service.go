@@ -0,0 +1,167 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/eventbus"
+	"famstack/internal/ids"
+	"famstack/internal/models"
+)
+
+const focusSessionColumns = `id, family_id, title, started_by, started_at, ends_at, ended_at`
+
+// FocusService runs family-wide focus sessions. Starting or ending one is
+// published on the event bus so every connected device updates at once.
+type FocusService struct {
+	db     *database.Fascade
+	events *eventbus.Bus
+}
+
+// NewFocusService creates a new focus service
+func NewFocusService(db *database.Fascade) *FocusService {
+	return &FocusService{db: db}
+}
+
+// SetEventBus sets where focus changes are announced
+func (s *FocusService) SetEventBus(bus *eventbus.Bus) {
+	s.events = bus
+}
+
+// Subscribe calls handler with the family ID whenever a focus session starts
+// or ends early. Sessions that run out aren't announced; watchers time them
+// from EndsAt. The returned function stops the subscription.
+func (s *FocusService) Subscribe(handler func(familyID string)) func() {
+	if s.events == nil {
+		return func() {}
+	}
+	return s.events.Subscribe(func(event eventbus.Event) {
+		handler(event.FamilyID)
+	}, eventbus.TopicFocusChanged)
+}
+
+// StartSession starts a focus session for the whole family. Only one may run
+// at a time.
+func (s *FocusService) StartSession(familyID, startedBy string, req *models.StartFocusSessionRequest, now time.Time) (*models.FocusSession, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = "Quiet time"
+	}
+	minutes := req.DurationMinutes
+	if minutes == 0 {
+		minutes = models.FocusSessionDefaultMinutes
+	}
+	normalized := models.StartFocusSessionRequest{Title: title, DurationMinutes: minutes}
+	if err := normalized.Validate(); err != nil {
+		return nil, err
+	}
+
+	active, err := s.GetActiveSession(familyID, now)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		return nil, fmt.Errorf("focus session already active")
+	}
+
+	var startedByValue any
+	if startedBy != "" {
+		startedByValue = startedBy
+	}
+
+	sessionID := ids.New("focus")
+	startedAt := now.UTC()
+	_, err = s.db.Exec(`
+		INSERT INTO focus_sessions (id, family_id, title, started_by, started_at, ends_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, sessionID, familyID, title, startedByValue, startedAt, startedAt.Add(time.Duration(minutes)*time.Minute))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start focus session: %w", err)
+	}
+
+	s.publishChange(familyID)
+	return s.getSession(sessionID)
+}
+
+// EndActiveSession ends the family's running focus session early
+func (s *FocusService) EndActiveSession(familyID string, now time.Time) (*models.FocusSession, error) {
+	active, err := s.GetActiveSession(familyID, now)
+	if err != nil {
+		return nil, err
+	}
+	if active == nil {
+		return nil, fmt.Errorf("no active focus session")
+	}
+
+	if _, err := s.db.Exec(`UPDATE focus_sessions SET ended_at = ? WHERE id = ?`, now.UTC(), active.ID); err != nil {
+		return nil, fmt.Errorf("failed to end focus session: %w", err)
+	}
+
+	s.publishChange(familyID)
+	return s.getSession(active.ID)
+}
+
+// GetActiveSession returns the family's running focus session, or nil when
+// there is none
+func (s *FocusService) GetActiveSession(familyID string, now time.Time) (*models.FocusSession, error) {
+	session, err := database.QueryOne[models.FocusSession](s.db, `
+		SELECT `+focusSessionColumns+` FROM focus_sessions
+		WHERE family_id = ? AND ended_at IS NULL AND started_at <= ? AND ends_at > ?
+		ORDER BY started_at DESC LIMIT 1
+	`, familyID, now.UTC(), now.UTC())
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active focus session: %w", err)
+	}
+	return session, nil
+}
+
+// ListSessions returns the family's focus log, newest first
+func (s *FocusService) ListSessions(familyID string, limit int) ([]models.FocusSession, error) {
+	sessions, err := database.QueryAll[models.FocusSession](s.db, `
+		SELECT `+focusSessionColumns+` FROM focus_sessions
+		WHERE family_id = ?
+		ORDER BY started_at DESC LIMIT ?
+	`, familyID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list focus sessions: %w", err)
+	}
+	if sessions == nil {
+		sessions = []models.FocusSession{}
+	}
+	return sessions, nil
+}
+
+// ShouldNotify reports whether a notification may go out to the family now.
+// Urgent notifications always may; everything else waits out a running
+// focus session. Notification senders check this before delivering.
+func (s *FocusService) ShouldNotify(familyID string, urgent bool, now time.Time) (bool, error) {
+	if urgent {
+		return true, nil
+	}
+	active, err := s.GetActiveSession(familyID, now)
+	if err != nil {
+		return false, err
+	}
+	return active == nil, nil
+}
+
+func (s *FocusService) getSession(sessionID string) (*models.FocusSession, error) {
+	session, err := database.QueryOne[models.FocusSession](s.db, `SELECT `+focusSessionColumns+` FROM focus_sessions WHERE id = ?`, sessionID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("focus session not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get focus session: %w", err)
+	}
+	return session, nil
+}
+
+func (s *FocusService) publishChange(familyID string) {
+	s.events.Publish(eventbus.Event{Topic: eventbus.TopicFocusChanged, FamilyID: familyID})
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/eventbus"
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFocusService_SessionLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewFocusService(db)
+	service.SetEventBus(eventbus.New())
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	var announced []string
+	stop := service.Subscribe(func(familyID string) { announced = append(announced, familyID) })
+	defer stop()
+
+	now := time.Date(2025, 10, 1, 16, 0, 0, 0, time.UTC)
+	session, err := service.StartSession(familyID, memberID, &models.StartFocusSessionRequest{DurationMinutes: 30}, now)
+	require.NoError(t, err)
+	assert.Equal(t, "Quiet time", session.Title)
+	assert.True(t, session.EndsAt.Equal(now.Add(30*time.Minute)))
+
+	_, err = service.StartSession(familyID, memberID, &models.StartFocusSessionRequest{}, now.Add(time.Minute))
+	require.Error(t, err)
+	assert.Equal(t, "focus session already active", err.Error())
+
+	quiet, err := service.ShouldNotify(familyID, false, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, quiet)
+	urgent, err := service.ShouldNotify(familyID, true, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, urgent)
+
+	// A session that ran out is no longer active
+	active, err := service.GetActiveSession(familyID, now.Add(31*time.Minute))
+	require.NoError(t, err)
+	assert.Nil(t, active)
+
+	ended, err := service.EndActiveSession(familyID, now.Add(10*time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, ended.EndedAt)
+	_, err = service.EndActiveSession(familyID, now.Add(11*time.Minute))
+	assert.Error(t, err)
+
+	sessions, err := service.ListSessions(familyID, 10)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, []string{familyID, familyID}, announced)
+}
+
+func TestFocusService_RejectsBadDuration(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewFocusService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	_, err := service.StartSession(familyID, memberID, &models.StartFocusSessionRequest{DurationMinutes: models.FocusSessionMaxMinutes + 1}, time.Now())
+	assert.Error(t, err)
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/models"
+)
+
+// GuestService builds what a guest pass shows and carries out what it
+// allows. The pass itself, and which tasks it covers, is the auth package's.
+type GuestService struct {
+	db       *database.Fascade
+	calendar *CalendarService
+	tasks    *TasksService
+}
+
+// NewGuestService creates a new guest service
+func NewGuestService(db *database.Fascade, calendar *CalendarService, tasks *TasksService) *GuestService {
+	return &GuestService{db: db, calendar: calendar, tasks: tasks}
+}
+
+// Day returns the family's events for the day now falls on, in the family
+// timezone, and those of taskIDs that still exist
+func (s *GuestService) Day(familyID string, taskIDs []string, now time.Time) (*models.GuestDay, error) {
+	var familyName string
+	if err := s.db.QueryRow(`SELECT name FROM families WHERE id = ?`, familyID).Scan(&familyName); err != nil {
+		return nil, fmt.Errorf("failed to get family: %w", err)
+	}
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for guest: %w", err)
+	}
+	loc, err := loadLocation(familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid family timezone %s: %w", familyTimezone, err)
+	}
+
+	local := now.In(loc)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	// A guest sees only what everyone may: no private events, busy ones as busy
+	events, err := s.calendar.GetUnifiedCalendarEventsFor(nil, familyID, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events for guest: %w", err)
+	}
+
+	guestDay := &models.GuestDay{
+		FamilyName: familyName,
+		Date:       day,
+		Events:     events,
+		Tasks:      []models.Task{},
+	}
+	for _, taskID := range taskIDs {
+		task, err := s.tasks.GetFamilyTask(familyID, taskID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue // deleted since the pass was made
+			}
+			return nil, err
+		}
+		guestDay.Tasks = append(guestDay.Tasks, *task)
+	}
+	return guestDay, nil
+}
+
+// SetTaskDone marks one of taskIDs done or not done
+func (s *GuestService) SetTaskDone(familyID string, taskIDs []string, taskID string, done bool) error {
+	if !slices.Contains(taskIDs, taskID) {
+		return notFound("task")
+	}
+
+	status := models.TaskStatusPending
+	if done {
+		status = models.TaskStatusCompleted
+	}
+	_, err := s.tasks.UpdateTask(familyID, taskID, &models.UpdateTaskRequest{Status: &status})
+	return err
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuestDay(t *testing.T) {
+	db := setupTestDB(t)
+	tasks := NewTasksService(db)
+	calendar := NewCalendarService(db)
+	service := NewGuestService(db, calendar, tasks)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	now := time.Date(2025, 10, 1, 15, 0, 0, 0, time.UTC)
+	_, err := calendar.BulkCreateUnifiedCalendarEvents(context.Background(), familyID, memberID, []models.BulkCalendarEventItem{
+		{Title: "Swim lesson", StartTime: now.Add(2 * time.Hour), EndTime: now.Add(3 * time.Hour)},
+		{Title: "Tomorrow's game", StartTime: now.Add(24 * time.Hour), EndTime: now.Add(25 * time.Hour)},
+	})
+	require.NoError(t, err)
+
+	bath, err := tasks.CreateTask(familyID, memberID, &models.CreateTaskRequest{Title: "Bath", TaskType: models.TaskTypeChore})
+	require.NoError(t, err)
+	dishes, err := tasks.CreateTask(familyID, memberID, &models.CreateTaskRequest{Title: "Dishes", TaskType: models.TaskTypeChore})
+	require.NoError(t, err)
+
+	day, err := service.Day(familyID, []string{bath.ID, "task_deleted"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, "Bulk Family", day.FamilyName)
+	assert.Equal(t, time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), day.Date.UTC())
+	require.Len(t, day.Events, 1)
+	assert.Equal(t, "Swim lesson", day.Events[0].Title)
+	require.Len(t, day.Tasks, 1, "deleted tasks drop out")
+	assert.Equal(t, "Bath", day.Tasks[0].Title)
+
+	require.NoError(t, service.SetTaskDone(familyID, []string{bath.ID}, bath.ID, true))
+	task, err := tasks.GetTask(bath.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusCompleted, task.Status)
+
+	err = service.SetTaskDone(familyID, []string{bath.ID}, dishes.ID, true)
+	assert.EqualError(t, err, "task not found", "only the pass's tasks can be changed")
+	err = service.SetTaskDone("fam_other", []string{bath.ID}, bath.ID, false)
+	assert.EqualError(t, err, "task not found")
+}
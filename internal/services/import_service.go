@@ -0,0 +1,372 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/eventbus"
+	"famstack/internal/models"
+	"famstack/internal/validation"
+)
+
+// ImportService recreates tasks, schedules and calendar events from a family
+// archive
+type ImportService struct {
+	db     *database.Fascade
+	events *eventbus.Bus
+}
+
+// NewImportService creates a new import service
+func NewImportService(db *database.Fascade) *ImportService {
+	return &ImportService{db: db}
+}
+
+// SetEventBus sets where imported tasks and events are announced
+func (s *ImportService) SetEventBus(bus *eventbus.Bus) {
+	s.events = bus
+}
+
+// importPlan is an import worked out before anything is written
+type importPlan struct {
+	familyID   string
+	importedBy string
+	archive    *models.FamilyArchive
+	members    map[string]string // archive member ID -> member ID in this family
+	report     *models.ImportReport
+	tasks      []plannedEntry
+	schedules  []plannedEntry
+	events     []plannedEntry
+}
+
+// plannedEntry is an archive entry to create and where its result is reported
+type plannedEntry struct {
+	index  int
+	result int
+}
+
+// ImportArchive recreates an archive's tasks, schedules and events in the
+// family under new IDs. Members are matched by email, then by name; what
+// points at a member with no match is left unassigned, with a warning.
+// Entries the family already has (a task with the same title and due date, a
+// schedule with the same title, an event with the same title and start) are
+// reported as conflicts and left out. Nothing is written on a dry run or when
+// any entry is invalid.
+func (s *ImportService) ImportArchive(familyID, importedBy string, archive *models.FamilyArchive, dryRun bool) (*models.ImportReport, error) {
+	if err := archive.Validate(); err != nil {
+		return nil, err
+	}
+
+	plan := &importPlan{
+		familyID:   familyID,
+		importedBy: importedBy,
+		archive:    archive,
+		report:     &models.ImportReport{DryRun: dryRun, Results: []models.ImportResult{}},
+	}
+	var err error
+	if plan.members, err = s.matchMembers(familyID, archive.Members); err != nil {
+		return nil, err
+	}
+	if err := s.planEntries(plan); err != nil {
+		return nil, err
+	}
+
+	if dryRun || plan.report.Invalid > 0 {
+		return plan.report, nil
+	}
+
+	if err := s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		if err := s.writePlan(tx, plan); err != nil {
+			return err
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to import archive: %w", err)
+	}
+
+	if s.events != nil {
+		if len(plan.tasks) > 0 {
+			s.events.Publish(eventbus.Event{Topic: eventbus.TopicTasksChanged, FamilyID: familyID})
+		}
+		if len(plan.events) > 0 {
+			s.events.Publish(eventbus.Event{Topic: eventbus.TopicCalendarChanged, FamilyID: familyID})
+		}
+	}
+	return plan.report, nil
+}
+
+// matchMembers maps the archive's member IDs to the family's members, by
+// email when both have one and otherwise by full name
+func (s *ImportService) matchMembers(familyID string, archived []models.FamilyMember) (map[string]string, error) {
+	members, err := database.QueryAll[models.FamilyMember](s.db, `
+		SELECT id, first_name, last_name, email FROM family_members WHERE family_id = ? AND is_active = TRUE
+	`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load family members for import: %w", err)
+	}
+
+	byEmail := make(map[string]string)
+	byName := make(map[string]string)
+	matched := make(map[string]string)
+	for _, member := range members {
+		if member.Email != nil && *member.Email != "" {
+			byEmail[strings.ToLower(*member.Email)] = member.ID
+		}
+		byName[strings.ToLower(member.FullName())] = member.ID
+		matched[member.ID] = member.ID // an archive of this same family
+	}
+
+	for _, member := range archived {
+		if _, ok := matched[member.ID]; ok {
+			continue
+		}
+		if member.Email != nil && byEmail[strings.ToLower(*member.Email)] != "" {
+			matched[member.ID] = byEmail[strings.ToLower(*member.Email)]
+		} else if id := byName[strings.ToLower(member.FullName())]; id != "" {
+			matched[member.ID] = id
+		}
+	}
+	return matched, nil
+}
+
+// member resolves an archive member ID, adding a warning when it has no match
+func (p *importPlan) member(id *string, field string, result *models.ImportResult) *string {
+	if id == nil || *id == "" {
+		return nil
+	}
+	if matched, ok := p.members[*id]; ok {
+		return &matched
+	}
+	result.Warnings = append(result.Warnings, fmt.Sprintf("%s %s has no matching member in this family", field, *id))
+	return nil
+}
+
+// planEntries validates each entry, remaps its members and checks it against
+// what the family already has
+func (s *ImportService) planEntries(plan *importPlan) error {
+	existingTasks, err := s.existingKeys(`SELECT id, title, due_date FROM tasks WHERE family_id = ?`, plan.familyID)
+	if err != nil {
+		return fmt.Errorf("failed to load tasks for import: %w", err)
+	}
+	existingSchedules, err := s.existingKeys(`SELECT id, title, NULL FROM task_schedules WHERE family_id = ?`, plan.familyID)
+	if err != nil {
+		return fmt.Errorf("failed to load schedules for import: %w", err)
+	}
+	existingEvents, err := s.existingKeys(`SELECT id, title, start_time FROM unified_calendar_events WHERE family_id = ?`, plan.familyID)
+	if err != nil {
+		return fmt.Errorf("failed to load events for import: %w", err)
+	}
+
+	for i := range plan.archive.Tasks {
+		task := &plan.archive.Tasks[i]
+		result := models.ImportResult{Kind: models.ImportKindTask, Index: i, SourceID: task.ID}
+		task.AssignedTo = plan.member(task.AssignedTo, "assigned_to", &result)
+		if entry, ok := plan.add(result, task.ValidateImport(), existingTasks, importKey(task.Title, task.DueDate)); ok {
+			plan.tasks = append(plan.tasks, entry)
+		}
+	}
+
+	for i := range plan.archive.Schedules {
+		schedule := &plan.archive.Schedules[i]
+		result := models.ImportResult{Kind: models.ImportKindSchedule, Index: i, SourceID: schedule.ID}
+		schedule.AssignedTo = plan.member(schedule.AssignedTo, "assigned_to", &result)
+		schedule.RotationMembers = plan.rotation(schedule.RotationMembers, &result)
+		if entry, ok := plan.add(result, schedule.ValidateImport(), existingSchedules, importKey(schedule.Title, nil)); ok {
+			plan.schedules = append(plan.schedules, entry)
+		}
+	}
+
+	for i := range plan.archive.Events {
+		event := &plan.archive.Events[i]
+		result := models.ImportResult{Kind: models.ImportKindEvent, Index: i, SourceID: event.ID}
+		attendees := make([]models.EventAttendee, 0, len(event.Attendees))
+		for _, attendee := range event.Attendees {
+			if id := plan.member(&attendee.ID, "attendee", &result); id != nil {
+				attendees = append(attendees, models.EventAttendee{ID: *id})
+			}
+		}
+		event.Attendees = attendees
+		start := event.StartTime
+		if entry, ok := plan.add(result, event.ValidateImport(), existingEvents, importKey(event.Title, &start)); ok {
+			plan.events = append(plan.events, entry)
+		}
+	}
+	return nil
+}
+
+// add records an entry's result and reports whether it is to be created
+func (p *importPlan) add(result models.ImportResult, errs validation.ValidationErrors, existing map[string]string, key string) (plannedEntry, bool) {
+	entry := plannedEntry{index: result.Index, result: len(p.report.Results)}
+	switch id, conflict := existing[key]; {
+	case len(errs) > 0:
+		result.Status = models.ImportStatusInvalid
+		result.Errors = errs
+		p.report.Invalid++
+	case conflict:
+		result.Status = models.ImportStatusConflict
+		result.Conflict = id
+		p.report.Conflicts++
+	default:
+		// A later duplicate in the same archive conflicts with this one
+		existing[key] = result.SourceID
+		result.Status = models.ImportStatusValid
+		p.report.Valid++
+	}
+	p.report.Results = append(p.report.Results, result)
+	return entry, result.Status == models.ImportStatusValid
+}
+
+// rotation remaps a schedule's JSON list of rotation members, dropping any
+// without a match
+func (p *importPlan) rotation(members *string, result *models.ImportResult) *string {
+	if members == nil || *members == "" {
+		return members
+	}
+	var ids []string
+	if err := json.Unmarshal([]byte(*members), &ids); err != nil {
+		result.Warnings = append(result.Warnings, "rotation_members is not a list of member IDs and was dropped")
+		return nil
+	}
+	matched := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if member := p.member(&id, "rotation member", result); member != nil {
+			matched = append(matched, *member)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	encoded, _ := json.Marshal(matched) // nolint:errcheck
+	value := string(encoded)
+	return &value
+}
+
+// existingKeys loads the conflict keys of a family's rows, keyed to their IDs
+func (s *ImportService) existingKeys(query, familyID string) (map[string]string, error) {
+	rows, err := s.db.Query(query, familyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make(map[string]string)
+	for rows.Next() {
+		var id, title string
+		var at sql.NullTime
+		if err := rows.Scan(&id, &title, &at); err != nil {
+			return nil, err
+		}
+		var when *time.Time
+		if at.Valid {
+			when = &at.Time
+		}
+		keys[importKey(title, when)] = id
+	}
+	return keys, rows.Err()
+}
+
+// importKey identifies a row for conflict checks: its title, ignoring case
+// and surrounding space, and when it happens to the second
+func importKey(title string, at *time.Time) string {
+	key := strings.ToLower(strings.TrimSpace(title))
+	if at != nil {
+		key += "|" + at.UTC().Truncate(time.Second).Format(time.RFC3339)
+	}
+	return key
+}
+
+// writePlan inserts the planned entries under new IDs
+func (s *ImportService) writePlan(tx *sql.Tx, plan *importPlan) error {
+	now := time.Now().UTC()
+	created := func(entry plannedEntry, id string) {
+		plan.report.Results[entry.result].Status = models.ImportStatusCreated
+		plan.report.Results[entry.result].ID = id
+		plan.report.Valid--
+		plan.report.Created++
+	}
+
+	for _, entry := range plan.tasks {
+		i, task := entry.index, plan.archive.Tasks[entry.index]
+		id := generateTaskID()
+		var dueDate *time.Time
+		if task.DueDate != nil {
+			due := task.DueDate.UTC()
+			dueDate = &due
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO tasks (id, family_id, assigned_to, title, description, task_type, status, priority,
+			                   due_date, has_due_time, created_by, created_at, updated_at, completed_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, id, plan.familyID, task.AssignedTo, task.Title, task.Description, task.TaskType, task.Status,
+			task.Priority, dueDate, task.HasDueTime, plan.importedBy, now, now, task.CompletedAt); err != nil {
+			return fmt.Errorf("task %d: failed to insert: %w", i, err)
+		}
+		created(entry, id)
+	}
+
+	for _, entry := range plan.schedules {
+		i, schedule := entry.index, plan.archive.Schedules[entry.index]
+		id := generateScheduleID()
+		rotation := schedule.RotationStrategy
+		if rotation == "" || schedule.RotationMembers == nil {
+			rotation = "none"
+		}
+		daysOfWeek := "[]"
+		if schedule.DaysOfWeek != nil && *schedule.DaysOfWeek != "" {
+			daysOfWeek = *schedule.DaysOfWeek
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO task_schedules (id, family_id, created_by, title, description, task_type,
+			                            assigned_to, days_of_week, time_of_day, priority, points,
+			                            active, created_at, rotation_strategy, rotation_members, rotation_anchor,
+			                            start_date, end_date, paused_until, cron_expr)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, id, plan.familyID, plan.importedBy, schedule.Title, schedule.Description, schedule.TaskType,
+			schedule.AssignedTo, daysOfWeek, schedule.TimeOfDay, schedule.Priority, schedule.Points,
+			schedule.Active, now, rotation, schedule.RotationMembers, schedule.RotationAnchor,
+			schedule.StartDate, schedule.EndDate, schedule.PausedUntil, schedule.CronExpr); err != nil {
+			return fmt.Errorf("schedule %d: failed to insert: %w", i, err)
+		}
+		created(entry, id)
+	}
+
+	for _, entry := range plan.events {
+		i, event := entry.index, plan.archive.Events[entry.index]
+		id := generateUnifiedEventID()
+		eventType := event.EventType
+		if eventType == "" {
+			eventType = models.EventTypeEvent
+		}
+		color := event.Color
+		if color == "" {
+			color = defaultEventColor
+		}
+		status := event.Status
+		if status == "" {
+			status = models.UnifiedEventStatusActive
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO unified_calendar_events (id, family_id, title, description, start_time, end_time,
+			                                     location, all_day, event_type, color, category, created_by,
+			                                     priority, status, recurrence_rule, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, id, plan.familyID, event.Title, event.Description, event.StartTime.UTC(), event.EndTime.UTC(),
+			event.Location, event.AllDay, eventType, color, event.Category, plan.importedBy,
+			event.Priority, status, normalizedRule(event.RecurrenceRule), now, now); err != nil {
+			return fmt.Errorf("event %d: failed to insert: %w", i, err)
+		}
+		for _, attendee := range event.Attendees {
+			if _, err := tx.Exec(`
+				INSERT INTO unified_calendar_event_attendees (event_id, user_id) VALUES (?, ?) ON CONFLICT DO NOTHING
+			`, id, attendee.ID); err != nil {
+				return fmt.Errorf("event %d: failed to add attendee %s: %w", i, attendee.ID, err)
+			}
+		}
+		created(entry, id)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testArchive() *models.FamilyArchive {
+	due := time.Date(2026, 3, 2, 17, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 3, 3, 15, 0, 0, 0, time.UTC)
+	assignee := "old_member"
+	days := `["monday"]`
+	return &models.FamilyArchive{
+		Version: models.FamilyArchiveVersion,
+		Members: []models.FamilyMember{{ID: assignee, FirstName: "Bulk", LastName: "Member"}},
+		Tasks: []models.Task{{
+			ID: "old_task", Title: "Return library books", TaskType: models.TaskTypeTodo,
+			Status: models.TaskStatusPending, DueDate: &due, AssignedTo: &assignee,
+		}},
+		Schedules: []models.TaskSchedule{{
+			ID: "old_schedule", Title: "Take out bins", TaskType: models.TaskTypeChore, DaysOfWeek: &days, Active: true,
+		}},
+		Events: []models.UnifiedCalendarEvent{{
+			ID: "old_event", Title: "Swim lesson", StartTime: start, EndTime: start.Add(time.Hour),
+			Attendees: []models.EventAttendee{{ID: assignee}, {ID: "gone_member"}},
+		}},
+	}
+}
+
+func TestImportService_DryRunThenImport(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewImportService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	report, err := service.ImportArchive(familyID, memberID, testArchive(), true)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Valid)
+	assert.Zero(t, report.Created)
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE family_id = ?`, familyID).Scan(&count))
+	assert.Zero(t, count, "a dry run writes nothing")
+
+	report, err = service.ImportArchive(familyID, memberID, testArchive(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Created)
+	task := report.Results[0]
+	assert.Equal(t, models.ImportStatusCreated, task.Status)
+	assert.NotEqual(t, "old_task", task.ID)
+
+	var assignedTo string
+	require.NoError(t, db.QueryRow(`SELECT assigned_to FROM tasks WHERE id = ?`, task.ID).Scan(&assignedTo))
+	assert.Equal(t, memberID, assignedTo, "members are matched by name")
+	assert.Len(t, report.Results[2].Warnings, 1, "the attendee with no match is dropped")
+
+	// Importing again finds everything already there
+	report, err = service.ImportArchive(familyID, memberID, testArchive(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Conflicts)
+	assert.Zero(t, report.Created)
+	assert.Equal(t, task.ID, report.Results[0].Conflict)
+}
+
+func TestImportService_InvalidEntryWritesNothing(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewImportService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	archive := testArchive()
+	archive.Events[0].EndTime = archive.Events[0].StartTime.Add(-time.Hour)
+	report, err := service.ImportArchive(familyID, memberID, archive, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Invalid)
+	assert.Equal(t, models.ImportStatusInvalid, report.Results[2].Status)
+	assert.Equal(t, models.ImportStatusValid, report.Results[0].Status)
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM task_schedules WHERE family_id = ?`, familyID).Scan(&count))
+	assert.Zero(t, count)
+
+	archive.Version = 99
+	_, err = service.ImportArchive(familyID, memberID, archive, false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,424 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/models"
+	"famstack/internal/validation"
+)
+
+// availabilityCacheTTL is how long a computed heatmap is reused. It is built
+// from weeks of history, so an event added since barely moves it.
+const availabilityCacheTTL = time.Hour
+
+// InsightsService turns the family's calendar history into availability
+// insights: a weekly heatmap of each member's busy hours, and the recurring
+// slots that have tended to be free for everyone
+type InsightsService struct {
+	db *database.Fascade
+
+	mu    sync.Mutex
+	cache map[string]cachedHeatmap
+}
+
+type cachedHeatmap struct {
+	heatmap *models.AvailabilityHeatmap
+	expires time.Time
+}
+
+// NewInsightsService creates a new insights service
+func NewInsightsService(db *database.Fascade) *InsightsService {
+	return &InsightsService{
+		db:    db,
+		cache: make(map[string]cachedHeatmap),
+	}
+}
+
+// GetAvailability returns the family's heatmap and the best recurring slots
+// for the members and window in query. Zero fields in query take the defaults.
+func (s *InsightsService) GetAvailability(familyID string, query models.AvailabilityQuery) (*models.AvailabilityInsights, error) {
+	query = withAvailabilityDefaults(query)
+	if err := validateAvailabilityQuery(query); err != nil {
+		return nil, err
+	}
+
+	heatmap, err := s.GetHeatmap(familyID, query.Weeks)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions, err := suggestSlots(heatmap, query)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AvailabilityInsights{Heatmap: heatmap, Suggestions: suggestions}, nil
+}
+
+// GetHeatmap returns each member's busy hours over the last weeks full weeks,
+// computing it at most once an hour per family
+func (s *InsightsService) GetHeatmap(familyID string, weeks int) (*models.AvailabilityHeatmap, error) {
+	key := fmt.Sprintf("%s/%d", familyID, weeks)
+	now := time.Now()
+
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.heatmap, nil
+	}
+
+	heatmap, err := s.buildHeatmap(familyID, weeks)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	for cachedKey, entry := range s.cache {
+		if !now.Before(entry.expires) {
+			delete(s.cache, cachedKey)
+		}
+	}
+	s.cache[key] = cachedHeatmap{heatmap: heatmap, expires: now.Add(availabilityCacheTTL)}
+	s.mu.Unlock()
+
+	return heatmap, nil
+}
+
+// buildHeatmap aggregates busy hours in SQL. Each timed event is expanded into
+// the hours it touches, attributed to its attendees (or its creator when it
+// has none), and counted once per day, so a cell holds the number of weeks the
+// member had something on in that hour. All-day events and events longer
+// than a day are left out, since they rarely mean the member is unavailable.
+func (s *InsightsService) buildHeatmap(familyID string, weeks int) (*models.AvailabilityHeatmap, error) {
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for heatmap: %w", err)
+	}
+	loc, err := loadLocation(familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid family timezone %s: %w", familyTimezone, err)
+	}
+
+	// Whole weeks ending at the start of today, so every weekday appears weeks times
+	today := time.Now().In(loc)
+	to := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, loc)
+	from := to.AddDate(0, 0, -7*weeks)
+
+	heatmap := &models.AvailabilityHeatmap{
+		FamilyID:    familyID,
+		Timezone:    familyTimezone,
+		Weeks:       weeks,
+		From:        from,
+		To:          to,
+		Members:     []models.MemberHeatmap{},
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	memberRows, err := s.db.Query(`
+		SELECT id, first_name, last_name
+		FROM family_members
+		WHERE family_id = ? AND is_active = TRUE AND member_type != 'pet'
+		ORDER BY display_order, first_name
+	`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query members for heatmap: %w", err)
+	}
+	memberIndex := make(map[string]int)
+	for memberRows.Next() {
+		var memberID, firstName, lastName string
+		if err := memberRows.Scan(&memberID, &firstName, &lastName); err != nil {
+			memberRows.Close()
+			return nil, fmt.Errorf("failed to scan member for heatmap: %w", err)
+		}
+		memberIndex[memberID] = len(heatmap.Members)
+		heatmap.Members = append(heatmap.Members, models.MemberHeatmap{
+			MemberID: memberID,
+			Name:     strings.TrimSpace(firstName + " " + lastName),
+		})
+	}
+	memberRows.Close()
+	if err := memberRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read members for heatmap: %w", err)
+	}
+
+	// SQLite has no timezone database, so hours are shifted by the family's
+	// current UTC offset; across a DST change the far side is off by an hour.
+	// PostgreSQL converts each event with the family's zone itself.
+	_, offset := to.Zone()
+	shift := fmt.Sprintf("%+d seconds", offset)
+	query, args := busyHoursQuery, []any{shift, shift, familyID, from.UTC(), to.UTC()}
+	if s.db.Dialect() == database.DialectPostgres {
+		query, args = busyHoursQueryPostgres, []any{familyTimezone, familyTimezone, familyID, from.UTC(), to.UTC()}
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query busy hours: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var memberID sql.NullString
+		var weekday, hour, weeksBusy int
+		if err := rows.Scan(&memberID, &weekday, &hour, &weeksBusy); err != nil {
+			return nil, fmt.Errorf("failed to scan busy hours: %w", err)
+		}
+		i, ok := memberIndex[memberID.String]
+		if !ok || weekday < 0 || weekday > 6 || hour < 0 || hour > 23 {
+			continue // inactive member, or a pet
+		}
+		heatmap.Members[i].Busy[weekday][hour] = weeksBusy
+		heatmap.Members[i].Total += weeksBusy
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read busy hours: %w", err)
+	}
+
+	return heatmap, nil
+}
+
+// busyHoursQuery counts, per member, weekday and local hour, the days in a
+// range the member had a timed event on. Each event becomes one row per hour
+// it touches. Times are stored in UTC as Go formats them ("2006-01-02
+// 15:04:05 +0000 UTC"), which SQLite's date functions can't read whole, so
+// only the leading date and time are handed to them.
+const busyHoursQuery = `
+	WITH RECURSIVE
+	attendance(event_id, member_id) AS (
+		SELECT a.event_id, a.user_id
+		FROM unified_calendar_event_attendees a
+		UNION
+		SELECT e.id, e.created_by
+		FROM unified_calendar_events e
+		WHERE e.created_by IS NOT NULL
+		  AND NOT EXISTS (SELECT 1 FROM unified_calendar_event_attendees a WHERE a.event_id = e.id)
+	),
+	busy(member_id, slot, end_at) AS (
+		SELECT att.member_id,
+		       strftime('%Y-%m-%d %H:00:00', datetime(substr(e.start_time, 1, 19), ?)),
+		       datetime(substr(e.end_time, 1, 19), ?)
+		FROM unified_calendar_events e
+		JOIN attendance att ON att.event_id = e.id
+		WHERE e.family_id = ?
+		  AND e.status != 'cancelled'
+		  AND e.all_day = FALSE
+		  AND e.start_time >= ? AND e.start_time < ?
+		  AND e.end_time > e.start_time
+		  AND julianday(substr(e.end_time, 1, 19)) - julianday(substr(e.start_time, 1, 19)) <= 1
+		UNION ALL
+		SELECT member_id, datetime(slot, '+1 hour'), end_at
+		FROM busy
+		WHERE datetime(slot, '+1 hour') < end_at
+	)
+	SELECT member_id,
+	       CAST(strftime('%w', slot) AS INTEGER) AS weekday,
+	       CAST(strftime('%H', slot) AS INTEGER) AS hour,
+	       COUNT(DISTINCT date(slot)) AS weeks_busy
+	FROM busy
+	GROUP BY member_id, weekday, hour
+	`
+
+// busyHoursQueryPostgres is busyHoursQuery for PostgreSQL
+const busyHoursQueryPostgres = `
+	WITH
+	attendance(event_id, member_id) AS (
+		SELECT a.event_id, a.user_id
+		FROM unified_calendar_event_attendees a
+		UNION
+		SELECT e.id, e.created_by
+		FROM unified_calendar_events e
+		WHERE e.created_by IS NOT NULL
+		  AND NOT EXISTS (SELECT 1 FROM unified_calendar_event_attendees a WHERE a.event_id = e.id)
+	),
+	busy(member_id, slot) AS (
+		SELECT att.member_id,
+		       generate_series(
+		           date_trunc('hour', e.start_time AT TIME ZONE ?),
+		           (e.end_time AT TIME ZONE ?) - interval '1 microsecond',
+		           interval '1 hour'
+		       )
+		FROM unified_calendar_events e
+		JOIN attendance att ON att.event_id = e.id
+		WHERE e.family_id = ?
+		  AND e.status != 'cancelled'
+		  AND e.all_day = FALSE
+		  AND e.start_time >= ? AND e.start_time < ?
+		  AND e.end_time > e.start_time
+		  AND e.end_time - e.start_time <= interval '1 day'
+	)
+	SELECT member_id,
+	       CAST(EXTRACT(DOW FROM slot) AS INTEGER) AS weekday,
+	       CAST(EXTRACT(HOUR FROM slot) AS INTEGER) AS hour,
+	       COUNT(DISTINCT CAST(slot AS DATE)) AS weeks_busy
+	FROM busy
+	GROUP BY member_id, weekday, hour
+`
+
+func withAvailabilityDefaults(query models.AvailabilityQuery) models.AvailabilityQuery {
+	if query.Weeks == 0 {
+		query.Weeks = models.DefaultAvailabilityWeeks
+	}
+	if query.DurationHours == 0 {
+		query.DurationHours = 1
+	}
+	if query.FromHour == 0 && query.ToHour == 0 {
+		query.FromHour, query.ToHour = models.DefaultSlotFromHour, models.DefaultSlotToHour
+	}
+	if query.Limit == 0 {
+		query.Limit = models.DefaultSlotSuggestions
+	}
+	return query
+}
+
+func validateAvailabilityQuery(query models.AvailabilityQuery) error {
+	validator := validation.NewValidator()
+	if query.Weeks < 1 || query.Weeks > models.MaxAvailabilityWeeks {
+		validator.AddErrorf("weeks", "weeks must be between 1 and %d", models.MaxAvailabilityWeeks)
+	}
+	if query.DurationHours < 1 || query.DurationHours > models.MaxSlotDurationHours {
+		validator.AddErrorf("duration", "duration must be between 1 and %d hours", models.MaxSlotDurationHours)
+	}
+	if query.FromHour < 0 || query.ToHour > 24 || query.ToHour-query.FromHour < query.DurationHours {
+		validator.AddError("from_hour", "from_hour and to_hour must be a window of at least the duration within 0 to 24")
+	}
+	if query.Limit < 1 || query.Limit > models.MaxSlotSuggestions {
+		validator.AddErrorf("limit", "limit must be between 1 and %d", models.MaxSlotSuggestions)
+	}
+	return validator.ToError()
+}
+
+// suggestSlots scores every weekly slot in the query's window by how often
+// the chosen members were free for all of it. Slots free for everyone come
+// first; the best slot on each weekday is offered before a second on the same day.
+func suggestSlots(heatmap *models.AvailabilityHeatmap, query models.AvailabilityQuery) ([]models.SlotSuggestion, error) {
+	members := heatmap.Members
+	if len(query.MemberIDs) > 0 {
+		byID := make(map[string]models.MemberHeatmap, len(heatmap.Members))
+		for _, member := range heatmap.Members {
+			byID[member.MemberID] = member
+		}
+		members = make([]models.MemberHeatmap, 0, len(query.MemberIDs))
+		for _, memberID := range query.MemberIDs {
+			member, ok := byID[memberID]
+			if !ok {
+				validator := validation.NewValidator()
+				validator.AddErrorf("member_ids", "member %s is not an active member of this family", memberID)
+				return nil, validator.ToError()
+			}
+			members = append(members, member)
+		}
+	}
+	if len(members) == 0 {
+		return []models.SlotSuggestion{}, nil
+	}
+
+	var candidates []models.SlotSuggestion
+	for weekday := 0; weekday < 7; weekday++ {
+		for hour := query.FromHour; hour+query.DurationHours <= query.ToHour; hour++ {
+			slot := models.SlotSuggestion{
+				Weekday:       weekday,
+				WeekdayName:   time.Weekday(weekday).String(),
+				Hour:          hour,
+				DurationHours: query.DurationHours,
+				UsuallyBusy:   []string{},
+			}
+
+			totalFree := 0
+			for _, member := range members {
+				busyWeeks := 0
+				for h := hour; h < hour+query.DurationHours; h++ {
+					busyWeeks = max(busyWeeks, member.Busy[weekday][h])
+				}
+				freePercent := 100 * (heatmap.Weeks - busyWeeks) / heatmap.Weeks
+				totalFree += freePercent
+				if freePercent < models.UsuallyFreePercent {
+					slot.UsuallyBusy = append(slot.UsuallyBusy, firstName(member.Name))
+				}
+			}
+			slot.FreePercent = totalFree / len(members)
+			slot.FreeForAll = len(slot.UsuallyBusy) == 0
+			slot.Summary = slotSummary(slot, members)
+			candidates = append(candidates, slot)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if len(candidates[i].UsuallyBusy) != len(candidates[j].UsuallyBusy) {
+			return len(candidates[i].UsuallyBusy) < len(candidates[j].UsuallyBusy)
+		}
+		return candidates[i].FreePercent > candidates[j].FreePercent
+	})
+
+	suggestions := make([]models.SlotSuggestion, 0, query.Limit)
+	picked := make(map[int]bool)
+	var seenDays [7]bool
+	for pass := 0; pass < 2 && len(suggestions) < query.Limit; pass++ {
+		for i, slot := range candidates {
+			if len(suggestions) == query.Limit {
+				break
+			}
+			if picked[i] || (pass == 0 && seenDays[slot.Weekday]) {
+				continue
+			}
+			picked[i] = true
+			seenDays[slot.Weekday] = true
+			suggestions = append(suggestions, slot)
+		}
+	}
+	return suggestions, nil
+}
+
+// slotSummary describes a slot, e.g. "Wednesdays 5pm is usually free for everyone"
+func slotSummary(slot models.SlotSuggestion, members []models.MemberHeatmap) string {
+	when := time.Weekday(slot.Weekday).String() + "s " + hourLabel(slot.Hour)
+	if slot.DurationHours > 1 {
+		when += " to " + hourLabel(slot.Hour+slot.DurationHours)
+	}
+
+	switch {
+	case slot.FreeForAll && len(members) == 1:
+		return fmt.Sprintf("%s is usually free for %s", when, firstName(members[0].Name))
+	case slot.FreeForAll:
+		return when + " is usually free for everyone"
+	case len(slot.UsuallyBusy) < len(members):
+		return fmt.Sprintf("%s is usually free except for %s", when, joinNames(slot.UsuallyBusy))
+	default:
+		return fmt.Sprintf("%s is free %d%% of the time", when, slot.FreePercent)
+	}
+}
+
+// hourLabel formats an hour of the day as 12am, 9am, 12pm, 5pm
+func hourLabel(hour int) string {
+	hour %= 24
+	switch {
+	case hour == 0:
+		return "12am"
+	case hour < 12:
+		return fmt.Sprintf("%dam", hour)
+	case hour == 12:
+		return "12pm"
+	default:
+		return fmt.Sprintf("%dpm", hour-12)
+	}
+}
+
+func firstName(name string) string {
+	if fields := strings.Fields(name); len(fields) > 0 {
+		return fields[0]
+	}
+	return name
+}
+
+// joinNames lists names as "Sam", "Sam and Alex" or "Sam, Alex and Jo"
+func joinNames(names []string) string {
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
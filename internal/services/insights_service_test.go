@@ -0,0 +1,150 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+	"famstack/internal/validation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestSlots_PrefersSlotsFreeForEveryone(t *testing.T) {
+	heatmap := &models.AvailabilityHeatmap{
+		Weeks: 4,
+		Members: []models.MemberHeatmap{
+			{MemberID: "m1", Name: "Sam Smith"},
+			{MemberID: "m2", Name: "Alex Smith"},
+		},
+	}
+	// Everyone is busy every evening except Wednesday, when Alex has
+	// something on at 6pm one week in four
+	for weekday := 0; weekday < 7; weekday++ {
+		for hour := 17; hour < 19; hour++ {
+			heatmap.Members[0].Busy[weekday][hour] = 4
+			heatmap.Members[1].Busy[weekday][hour] = 4
+		}
+	}
+	heatmap.Members[0].Busy[time.Wednesday][17] = 0
+	heatmap.Members[1].Busy[time.Wednesday][17] = 0
+	heatmap.Members[0].Busy[time.Wednesday][18] = 0
+	heatmap.Members[1].Busy[time.Wednesday][18] = 1
+
+	suggestions, err := suggestSlots(heatmap, models.AvailabilityQuery{DurationHours: 1, FromHour: 17, ToHour: 19, Limit: 3})
+	require.NoError(t, err)
+	require.Len(t, suggestions, 3)
+
+	assert.Equal(t, int(time.Wednesday), suggestions[0].Weekday)
+	assert.Equal(t, 17, suggestions[0].Hour)
+	assert.Equal(t, 100, suggestions[0].FreePercent)
+	assert.True(t, suggestions[0].FreeForAll)
+	assert.Equal(t, "Wednesdays 5pm is usually free for everyone", suggestions[0].Summary)
+
+	// Every other weekday is equally busy; the second Wednesday slot only
+	// comes after one slot from each other day
+	assert.Equal(t, int(time.Sunday), suggestions[1].Weekday)
+	assert.Equal(t, "Sundays 5pm is free 0% of the time", suggestions[1].Summary)
+	assert.Equal(t, []string{"Sam", "Alex"}, suggestions[1].UsuallyBusy)
+
+	// A two-hour slot has to be free for both hours
+	suggestions, err = suggestSlots(heatmap, models.AvailabilityQuery{DurationHours: 2, FromHour: 17, ToHour: 19, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, 87, suggestions[0].FreePercent)
+	assert.True(t, suggestions[0].FreeForAll, "Alex is free three weeks in four")
+	assert.Equal(t, "Wednesdays 5pm to 7pm is usually free for everyone", suggestions[0].Summary)
+}
+
+func TestSuggestSlots_FiltersMembers(t *testing.T) {
+	heatmap := &models.AvailabilityHeatmap{
+		Weeks: 2,
+		Members: []models.MemberHeatmap{
+			{MemberID: "m1", Name: "Sam Smith"},
+			{MemberID: "m2", Name: "Alex Smith"},
+			{MemberID: "m3", Name: "Jo Smith"},
+		},
+	}
+	for weekday := 0; weekday < 7; weekday++ {
+		heatmap.Members[1].Busy[weekday][9] = 2
+		heatmap.Members[2].Busy[weekday][9] = 2
+	}
+
+	suggestions, err := suggestSlots(heatmap, models.AvailabilityQuery{MemberIDs: []string{"m1"}, DurationHours: 1, FromHour: 9, ToHour: 10, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, "Sundays 9am is usually free for Sam", suggestions[0].Summary)
+
+	suggestions, err = suggestSlots(heatmap, models.AvailabilityQuery{DurationHours: 1, FromHour: 9, ToHour: 10, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "Sundays 9am is usually free except for Alex and Jo", suggestions[0].Summary)
+
+	_, err = suggestSlots(heatmap, models.AvailabilityQuery{MemberIDs: []string{"stranger"}, DurationHours: 1, FromHour: 9, ToHour: 10, Limit: 1})
+	var validationErrs validation.ValidationErrors
+	assert.ErrorAs(t, err, &validationErrs)
+}
+
+func TestValidateAvailabilityQuery(t *testing.T) {
+	valid := withAvailabilityDefaults(models.AvailabilityQuery{})
+	assert.NoError(t, validateAvailabilityQuery(valid))
+
+	for _, query := range []models.AvailabilityQuery{
+		{Weeks: models.MaxAvailabilityWeeks + 1},
+		{DurationHours: models.MaxSlotDurationHours + 1},
+		{FromHour: 20, ToHour: 19},
+		{FromHour: 22, ToHour: 25},
+		{Limit: -1},
+	} {
+		assert.Error(t, validateAvailabilityQuery(withAvailabilityDefaults(query)), "%+v", query)
+	}
+}
+
+func TestHourLabel(t *testing.T) {
+	assert.Equal(t, "12am", hourLabel(0))
+	assert.Equal(t, "9am", hourLabel(9))
+	assert.Equal(t, "12pm", hourLabel(12))
+	assert.Equal(t, "5pm", hourLabel(17))
+	assert.Equal(t, "12am", hourLabel(24))
+}
+
+func TestInsightsService_GetHeatmapAggregatesHistory(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewInsightsService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	// Soccer 5:30-6:30pm on the same weekday for the last three weeks
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	for week := 1; week <= 3; week++ {
+		start := today.AddDate(0, 0, -7*week).Add(17*time.Hour + 30*time.Minute)
+		eventID := fmt.Sprintf("event_soccer_%d", week)
+		_, err := db.Exec(`INSERT INTO unified_calendar_events (id, family_id, title, start_time, end_time, created_by) VALUES (?, ?, ?, ?, ?, ?)`,
+			eventID, familyID, "Soccer", start, start.Add(time.Hour), memberID)
+		require.NoError(t, err)
+	}
+	// All-day events don't make anyone busy
+	_, err := db.Exec(`INSERT INTO unified_calendar_events (id, family_id, title, start_time, end_time, all_day, created_by) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		"event_holiday", familyID, "Holiday", today.AddDate(0, 0, -2), today.AddDate(0, 0, -1), true, memberID)
+	require.NoError(t, err)
+
+	heatmap, err := service.GetHeatmap(familyID, 4)
+	require.NoError(t, err)
+	require.Len(t, heatmap.Members, 1)
+
+	weekday := today.Weekday()
+	busy := heatmap.Members[0].Busy
+	assert.Equal(t, 3, busy[weekday][17])
+	assert.Equal(t, 3, busy[weekday][18])
+	assert.Zero(t, busy[weekday][19])
+	assert.Equal(t, 6, heatmap.Members[0].Total)
+
+	// The heatmap is cached, so a new event doesn't show until it expires
+	start := today.AddDate(0, 0, -1).Add(9 * time.Hour)
+	_, err = db.Exec(`INSERT INTO unified_calendar_events (id, family_id, title, start_time, end_time, created_by) VALUES (?, ?, ?, ?, ?, ?)`,
+		"event_dentist", familyID, "Dentist", start, start.Add(time.Hour), memberID)
+	require.NoError(t, err)
+	cached, err := service.GetHeatmap(familyID, 4)
+	require.NoError(t, err)
+	assert.Same(t, heatmap, cached)
+}
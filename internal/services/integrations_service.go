@@ -1,13 +1,15 @@
 package services
 
 import (
-	"crypto/rand"
+	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"time"
 
 	"famstack/internal/database"
 	"famstack/internal/encryption"
+	"famstack/internal/ids"
 )
 
 // IntegrationType represents different categories of integrations
@@ -33,6 +35,7 @@ const (
 	ProviderMicrosoft Provider = "microsoft"
 	ProviderApple     Provider = "apple"
 	ProviderCalDAV    Provider = "caldav"
+	ProviderICS       Provider = "ics" // a subscribed ICS feed, such as a school or team calendar
 
 	// Storage providers
 	ProviderDropbox     Provider = "dropbox"
@@ -55,6 +58,7 @@ const (
 	ProviderHomeKit    Provider = "homekit"
 	ProviderAlexa      Provider = "alexa"
 	ProviderGoogleHome Provider = "google_home"
+	ProviderMQTT       Provider = "mqtt"
 )
 
 // AuthMethod represents how the integration authenticates
@@ -132,6 +136,9 @@ type SyncHistory struct {
 	SyncType      string     `json:"sync_type" db:"sync_type"` // manual, scheduled, webhook
 	Status        string     `json:"status" db:"status"`       // success, error, partial
 	ItemsSynced   int        `json:"items_synced" db:"items_synced"`
+	SyncMode      string     `json:"sync_mode" db:"sync_mode"`         // full, incremental
+	ItemsChanged  int        `json:"items_changed" db:"items_changed"` // items added or updated
+	ItemsRemoved  int        `json:"items_removed" db:"items_removed"`
 	ErrorMessage  string     `json:"error_message" db:"error_message"`
 	StartedAt     time.Time  `json:"started_at" db:"started_at"`
 	CompletedAt   *time.Time `json:"completed_at" db:"completed_at"`
@@ -311,13 +318,59 @@ func (s *IntegrationsService) GetIntegrationWithCredentials(integrationID string
 
 // ListIntegrations lists integrations with optional filters
 func (s *IntegrationsService) ListIntegrations(familyID string, query *ListIntegrationsQuery) ([]Integration, error) {
-	sql := `
-		SELECT id, family_id, created_by, integration_type, provider, auth_method,
-		       status, display_name, description, settings, last_sync_at,
-		       last_error, created_at, updated_at
-		FROM integrations
-		WHERE family_id = ?
-	`
+	where, args := integrationFilters(familyID, query)
+	sql := integrationColumns + where + " ORDER BY created_at DESC, id DESC"
+
+	// Add pagination
+	if query.Limit > 0 {
+		sql += " LIMIT ?"
+		args = append(args, query.Limit)
+		if query.Offset > 0 {
+			sql += " OFFSET ?"
+			args = append(args, query.Offset)
+		}
+	}
+
+	return s.queryIntegrations(sql, args...)
+}
+
+// ListIntegrationsPage returns one page of the integrations matching the
+// query's filters, newest first. The query's limit and offset are ignored.
+func (s *IntegrationsService) ListIntegrationsPage(familyID string, query *ListIntegrationsQuery, request PageRequest) (*Page[Integration], error) {
+	cursor, err := request.cursor()
+	if err != nil {
+		return nil, err
+	}
+
+	where, args := integrationFilters(familyID, query)
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM integrations"+where, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count integrations: %w", err)
+	}
+
+	condition, cursorArgs := cursor.olderThan("created_at")
+	limit := request.limit()
+	integrations, err := s.queryIntegrations(integrationColumns+where+condition+" ORDER BY created_at DESC, id DESC LIMIT ?",
+		append(append(args, cursorArgs...), limit+1)...)
+	if err != nil {
+		return nil, err
+	}
+	return newPage(integrations, limit, total, func(integration Integration) (time.Time, string) {
+		return integration.CreatedAt, integration.ID
+	}), nil
+}
+
+// integrationColumns selects the columns of an Integration
+const integrationColumns = `
+	SELECT id, family_id, created_by, integration_type, provider, auth_method,
+	       status, display_name, description, settings, enabled, last_sync_at,
+	       last_error, created_at, updated_at
+	FROM integrations`
+
+// integrationFilters returns the WHERE clause, and its arguments, for a
+// family's integrations matching the query
+func integrationFilters(familyID string, query *ListIntegrationsQuery) (string, []any) {
+	sql := " WHERE family_id = ?"
 	args := []any{familyID}
 
 	// Add filters
@@ -341,19 +394,10 @@ func (s *IntegrationsService) ListIntegrations(familyID string, query *ListInteg
 		sql += " AND created_by = ?"
 		args = append(args, *query.CreatedBy)
 	}
+	return sql, args
+}
 
-	sql += " ORDER BY created_at DESC"
-
-	// Add pagination
-	if query.Limit > 0 {
-		sql += " LIMIT ?"
-		args = append(args, query.Limit)
-		if query.Offset > 0 {
-			sql += " OFFSET ?"
-			args = append(args, query.Offset)
-		}
-	}
-
+func (s *IntegrationsService) queryIntegrations(sql string, args ...any) ([]Integration, error) {
 	rows, err := s.db.Query(sql, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list integrations: %w", err)
@@ -367,7 +411,7 @@ func (s *IntegrationsService) ListIntegrations(familyID string, query *ListInteg
 			&integration.ID, &integration.FamilyID, &integration.CreatedBy,
 			&integration.IntegrationType, &integration.Provider, &integration.AuthMethod,
 			&integration.Status, &integration.DisplayName, &integration.Description,
-			&integration.Settings, &integration.LastSyncAt, &integration.LastError,
+			&integration.Settings, &integration.Enabled, &integration.LastSyncAt, &integration.LastError,
 			&integration.CreatedAt, &integration.UpdatedAt,
 		)
 		if err != nil {
@@ -424,17 +468,43 @@ func (s *IntegrationsService) UpdateIntegration(integrationID string, req *Updat
 	return integration, nil
 }
 
-// DeleteIntegration deletes an integration and all its credentials
-func (s *IntegrationsService) DeleteIntegration(integrationID string) error {
-	// Note: credentials will be deleted by CASCADE
-	query := "DELETE FROM integrations WHERE id = ?"
-	_, err := s.db.Exec(query, integrationID)
+// SetCalendarSyncStatus moves a member's calendar integrations with provider
+// between connected, syncing and error as their syncs are queued and finish,
+// stamping last_sync_at when a sync finishes. Integrations in any other
+// state, such as disconnected, are left alone.
+func (s *IntegrationsService) SetCalendarSyncStatus(userID string, provider Provider, status Status) error {
+	now := time.Now().UTC()
+	query := `
+		UPDATE integrations
+		SET status = ?, updated_at = ?, last_sync_at = CASE WHEN ? THEN ? ELSE last_sync_at END
+		WHERE created_by = ? AND provider = ? AND integration_type = ? AND status IN (?, ?, ?)
+	`
+
+	_, err := s.db.Exec(query,
+		status, now, status != StatusSyncing, now,
+		userID, provider, TypeCalendar, StatusConnected, StatusSyncing, StatusError,
+	)
 	if err != nil {
-		return fmt.Errorf("failed to delete integration: %w", err)
+		return fmt.Errorf("failed to update integration sync status: %w", err)
 	}
 	return nil
 }
 
+// DeleteIntegration deletes an integration, all its credentials and any
+// events it brought in from a subscribed feed
+func (s *IntegrationsService) DeleteIntegration(integrationID string) error {
+	return s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM unified_calendar_events WHERE source_integration_id = ?", integrationID); err != nil {
+			return fmt.Errorf("failed to delete feed events: %w", err)
+		}
+		// Note: credentials will be deleted by CASCADE
+		if _, err := tx.Exec("DELETE FROM integrations WHERE id = ?", integrationID); err != nil {
+			return fmt.Errorf("failed to delete integration: %w", err)
+		}
+		return nil
+	})
+}
+
 // StoreOAuthCredentials stores encrypted OAuth credentials
 func (s *IntegrationsService) StoreOAuthCredentials(integrationID string, accessToken, refreshToken, tokenType, scope string, expiresAt *time.Time) error {
 	// Encrypt tokens
@@ -452,9 +522,13 @@ func (s *IntegrationsService) StoreOAuthCredentials(integrationID string, access
 	}
 
 	query := `
-		INSERT OR REPLACE INTO integration_oauth_credentials
+		INSERT INTO integration_oauth_credentials
 		(id, integration_id, access_token, refresh_token, token_type, expires_at, scope, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(integration_id) DO UPDATE SET
+			id = excluded.id, access_token = excluded.access_token, refresh_token = excluded.refresh_token,
+			token_type = excluded.token_type, expires_at = excluded.expires_at, scope = excluded.scope,
+			created_at = excluded.created_at, updated_at = excluded.updated_at
 	`
 
 	now := time.Now().UTC()
@@ -551,16 +625,50 @@ func (s *IntegrationsService) getAPICredentials(integrationID string) ([]APICred
 	return credentials, nil
 }
 
+// ListSyncHistory returns one page of an integration's sync runs, newest
+// first
+func (s *IntegrationsService) ListSyncHistory(integrationID string, request PageRequest) (*Page[SyncHistory], error) {
+	cursor, err := request.cursor()
+	if err != nil {
+		return nil, err
+	}
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM integration_sync_history WHERE integration_id = ?`, integrationID).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count sync history: %w", err)
+	}
+
+	condition, args := cursor.olderThan("started_at")
+	limit := request.limit()
+	history, err := s.querySyncHistory(`
+		SELECT id, integration_id, sync_type, status, items_synced, sync_mode, items_changed, items_removed,
+		       error_message, started_at, completed_at, created_at
+		FROM integration_sync_history
+		WHERE integration_id = ?`+condition+`
+		ORDER BY started_at DESC, id DESC
+		LIMIT ?
+	`, append(append([]any{integrationID}, args...), limit+1)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sync history: %w", err)
+	}
+	return newPage(history, limit, total, func(sync SyncHistory) (time.Time, string) {
+		return sync.StartedAt, sync.ID
+	}), nil
+}
+
 func (s *IntegrationsService) getRecentSyncHistory(integrationID string, limit int) ([]SyncHistory, error) {
-	query := `
-		SELECT id, integration_id, sync_type, status, items_synced, error_message, started_at, completed_at, created_at
+	return s.querySyncHistory(`
+		SELECT id, integration_id, sync_type, status, items_synced, sync_mode, items_changed, items_removed,
+		       error_message, started_at, completed_at, created_at
 		FROM integration_sync_history
 		WHERE integration_id = ?
-		ORDER BY started_at DESC
+		ORDER BY started_at DESC, id DESC
 		LIMIT ?
-	`
+	`, integrationID, limit)
+}
 
-	rows, err := s.db.Query(query, integrationID, limit)
+func (s *IntegrationsService) querySyncHistory(query string, args ...any) ([]SyncHistory, error) {
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -571,7 +679,8 @@ func (s *IntegrationsService) getRecentSyncHistory(integrationID string, limit i
 		var sync SyncHistory
 		err := rows.Scan(
 			&sync.ID, &sync.IntegrationID, &sync.SyncType, &sync.Status,
-			&sync.ItemsSynced, &sync.ErrorMessage, &sync.StartedAt,
+			&sync.ItemsSynced, &sync.SyncMode, &sync.ItemsChanged, &sync.ItemsRemoved,
+			&sync.ErrorMessage, &sync.StartedAt,
 			&sync.CompletedAt, &sync.CreatedAt,
 		)
 		if err != nil {
@@ -583,8 +692,100 @@ func (s *IntegrationsService) getRecentSyncHistory(integrationID string, limit i
 	return history, nil
 }
 
+// Sync modes recorded in sync history
+const (
+	SyncModeFull        = "full"        // everything in the sync range was fetched
+	SyncModeIncremental = "incremental" // only what changed since the last sync
+)
+
+// SyncRun is what one sync of an integration did
+type SyncRun struct {
+	SyncType  string // manual or scheduled
+	Mode      string // SyncModeFull or SyncModeIncremental
+	StartedAt time.Time
+	Changed   int // items added or updated
+	Removed   int
+	Err       error
+}
+
+// RecordSyncRun adds a sync run to the integration's history
+func (s *IntegrationsService) RecordSyncRun(integrationID string, run SyncRun) error {
+	return s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		if err := insertSyncHistory(tx, integrationID, run, time.Now().UTC()); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// insertSyncHistory writes a sync run to integration_sync_history
+func insertSyncHistory(tx *sql.Tx, integrationID string, run SyncRun, now time.Time) error {
+	status, message := "success", ""
+	if run.Err != nil {
+		status, message = "error", run.Err.Error()
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO integration_sync_history
+		(id, integration_id, sync_type, status, items_synced, sync_mode, items_changed, items_removed,
+		 error_message, started_at, completed_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, generateID(), integrationID, run.SyncType, status, run.Changed+run.Removed, run.Mode, run.Changed, run.Removed,
+		message, run.StartedAt.UTC(), now, now); err != nil {
+		return fmt.Errorf("failed to record sync: %w", err)
+	}
+	return nil
+}
+
+// CalendarIntegration returns the member's calendar integration with
+// provider, or nil when they have none
+func (s *IntegrationsService) CalendarIntegration(userID string, provider Provider) (*Integration, error) {
+	integrations, err := s.queryIntegrations(integrationColumns+" WHERE created_by = ? AND provider = ? AND integration_type = ? ORDER BY created_at, id",
+		userID, provider, TypeCalendar)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find calendar integration: %w", err)
+	}
+	if len(integrations) == 0 {
+		return nil, nil
+	}
+	return &integrations[0], nil
+}
+
+// SyncToken returns the token that lists what changed in one of the
+// integration's calendars since its last sync, or "" when there is none
+func (s *IntegrationsService) SyncToken(integrationID, resourceID string) (string, error) {
+	var token string
+	err := s.db.QueryRow(`SELECT sync_token FROM integration_sync_tokens WHERE integration_id = ? AND resource_id = ?`,
+		integrationID, resourceID).Scan(&token)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get sync token: %w", err)
+	}
+	return token, nil
+}
+
+// SaveSyncToken keeps the token for the next sync of one of the
+// integration's calendars; an empty token forgets it
+func (s *IntegrationsService) SaveSyncToken(integrationID, resourceID, token string) error {
+	var err error
+	if token == "" {
+		_, err = s.db.Exec(`DELETE FROM integration_sync_tokens WHERE integration_id = ? AND resource_id = ?`, integrationID, resourceID)
+	} else {
+		_, err = s.db.Exec(`
+			INSERT INTO integration_sync_tokens (integration_id, resource_id, sync_token, updated_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT(integration_id, resource_id) DO UPDATE SET sync_token = excluded.sync_token, updated_at = excluded.updated_at
+		`, integrationID, resourceID, token, time.Now().UTC())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save sync token: %w", err)
+	}
+	return nil
+}
+
 // InitiateOAuth generates an OAuth authorization URL for an integration
-func (s *IntegrationsService) InitiateOAuth(integrationID, host string) (string, error) {
+func (s *IntegrationsService) InitiateOAuth(integrationID, origin string) (string, error) {
 	// Get integration to determine provider
 	integration, err := s.GetIntegration(integrationID)
 	if err != nil {
@@ -594,20 +795,12 @@ func (s *IntegrationsService) InitiateOAuth(integrationID, host string) (string,
 	// Generate authorization URL based on provider
 	switch integration.Provider {
 	case ProviderGoogle:
-		return fmt.Sprintf("http://%s/oauth/google/connect", host), nil
+		return origin + "/oauth/google/connect", nil
 	default:
 		return "", fmt.Errorf("OAuth not supported for provider: %s", integration.Provider)
 	}
 }
 
-// generateID creates a new cryptographically secure random ID
 func generateID() string {
-	// Use crypto/rand for secure random generation
-	bytes := make([]byte, 16)
-	if _, err := rand.Read(bytes); err != nil {
-		// Fallback to timestamp if crypto/rand fails (should be extremely rare)
-		return fmt.Sprintf("fallback_%d", time.Now().UTC().UnixNano())
-	}
-	// Convert to hex string for safe use in URLs and databases
-	return fmt.Sprintf("int_%x", bytes)
+	return ids.New("int")
 }
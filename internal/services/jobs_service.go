@@ -1,6 +1,9 @@
 package services
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -40,16 +43,17 @@ type Job struct {
 
 // ScheduledJob represents a scheduled job
 type ScheduledJob struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	QueueName string    `json:"queue_name"`
-	JobType   string    `json:"job_type"`
-	Payload   string    `json:"payload"`
-	CronExpr  string    `json:"cron_expr"`
-	Enabled   bool      `json:"enabled"`
-	NextRunAt time.Time `json:"next_run_at"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        string     `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	QueueName string     `json:"queue_name" db:"queue_name"`
+	JobType   string     `json:"job_type" db:"job_type"`
+	Payload   string     `json:"payload" db:"payload"`
+	CronExpr  string     `json:"cron_expr" db:"cron_expr"`
+	Enabled   bool       `json:"enabled" db:"enabled"`
+	NextRunAt time.Time  `json:"next_run_at" db:"next_run_at"`
+	LastRunAt *time.Time `json:"last_run_at" db:"last_run_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // JobMetric represents job execution metrics
@@ -77,14 +81,15 @@ func (s *JobsService) EnqueueJob(queueName, jobType string, payload string, prio
 		payload,
 		priority,
 		maxRetries,
-		runAt.Format("2006-01-02 15:04:05"),
+		runAt.UTC().Format("2006-01-02 15:04:05"),
 		time.Now().UTC().Format("2006-01-02 15:04:05"),
 		idempotencyKey,
 	).Scan(&jobID)
 
 	if err != nil {
-		// Check if this is a duplicate idempotency key
-		if strings.Contains(err.Error(), "UNIQUE constraint failed") && strings.Contains(err.Error(), "idempotency_key") {
+		// Check if this is a duplicate idempotency key; SQLite and PostgreSQL word it differently
+		duplicate := strings.Contains(err.Error(), "UNIQUE constraint failed") || strings.Contains(err.Error(), "duplicate key value")
+		if duplicate && strings.Contains(err.Error(), "idempotency_key") {
 			// Job with this idempotency key already exists - find and return existing job ID
 			if idempotencyKey != nil {
 				existingQuery := `SELECT id FROM jobs WHERE idempotency_key = ?`
@@ -100,11 +105,19 @@ func (s *JobsService) EnqueueJob(queueName, jobType string, payload string, prio
 	return jobID, nil
 }
 
-// ScheduleJob creates a scheduled job
+// ScheduleJob creates a scheduled job, or updates the one with the same name.
+// An existing job keeps its next run unless its cron expression changed, so
+// runs missed while the server was down are still due when it restarts.
 func (s *JobsService) ScheduleJob(name, queueName, jobType, payload, cronExpr string, enabled bool, nextRunAt time.Time) error {
 	query := `
-		INSERT OR REPLACE INTO scheduled_jobs (name, queue_name, job_type, payload, cron_expr, enabled, next_run_at)
+		INSERT INTO scheduled_jobs (name, queue_name, job_type, payload, cron_expr, enabled, next_run_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			queue_name = excluded.queue_name, job_type = excluded.job_type, payload = excluded.payload,
+			enabled = excluded.enabled,
+			next_run_at = CASE WHEN scheduled_jobs.cron_expr = excluded.cron_expr
+				THEN scheduled_jobs.next_run_at ELSE excluded.next_run_at END,
+			cron_expr = excluded.cron_expr
 	`
 
 	_, err := s.db.Exec(query,
@@ -114,7 +127,7 @@ func (s *JobsService) ScheduleJob(name, queueName, jobType, payload, cronExpr st
 		payload,
 		cronExpr,
 		enabled,
-		nextRunAt.Format("2006-01-02 15:04:05"),
+		nextRunAt.UTC().Format("2006-01-02 15:04:05"),
 	)
 
 	if err != nil {
@@ -124,12 +137,49 @@ func (s *JobsService) ScheduleJob(name, queueName, jobType, payload, cronExpr st
 	return nil
 }
 
+// GetDueScheduledJobs returns the enabled scheduled jobs whose next run is at
+// or before now, most overdue first
+func (s *JobsService) GetDueScheduledJobs(now time.Time) ([]ScheduledJob, error) {
+	jobs, err := database.QueryAll[ScheduledJob](s.db, `
+		SELECT id, name, queue_name, job_type, payload, cron_expr, enabled, next_run_at, last_run_at, created_at, updated_at
+		FROM scheduled_jobs
+		WHERE enabled AND next_run_at <= ?
+		ORDER BY next_run_at ASC
+	`, now.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due scheduled jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// AdvanceScheduledJob records a run of a scheduled job and moves it to its
+// next run. It only moves a job that is still due at ranAt, so when two
+// schedulers pick up the same run just one advances it; it reports whether
+// this call did.
+func (s *JobsService) AdvanceScheduledJob(jobID string, ranAt, nextRunAt time.Time) (bool, error) {
+	ranAtText := ranAt.UTC().Format("2006-01-02 15:04:05")
+	result, err := s.db.Exec(`
+		UPDATE scheduled_jobs
+		SET next_run_at = ?, last_run_at = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND next_run_at <= ?
+	`, nextRunAt.UTC().Format("2006-01-02 15:04:05"), ranAtText, jobID, ranAtText)
+	if err != nil {
+		return false, fmt.Errorf("failed to advance scheduled job %s: %w", jobID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check rows affected for scheduled job %s: %w", jobID, err)
+	}
+	return rowsAffected > 0, nil
+}
+
 // GetPendingJobs retrieves pending jobs for a queue
 func (s *JobsService) GetPendingJobs(queueName string, limit int) ([]Job, error) {
 	query := `
 		SELECT id, queue_name, job_type, payload, status, priority, max_retries, retry_count, run_at, idempotency_key, version
 		FROM jobs
-		WHERE queue_name = ? AND status = 'pending' AND run_at <= datetime('now')
+		WHERE queue_name = ? AND status = 'pending' AND run_at <= CURRENT_TIMESTAMP
 		ORDER BY priority DESC, run_at ASC
 		LIMIT ?
 	`
@@ -202,42 +252,83 @@ func (s *JobsService) ClaimJob(jobID string, expectedVersion int) (bool, error)
 // MarkJobCompleted marks a job as completed
 func (s *JobsService) MarkJobCompleted(jobID string) error {
 	_, err := s.db.Exec(
-		"UPDATE jobs SET status = 'completed', completed_at = datetime('now'), version = version + 1, updated_at = datetime('now') WHERE id = ?",
+		"UPDATE jobs SET status = 'completed', completed_at = CURRENT_TIMESTAMP, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
 		jobID,
 	)
 	return err
 }
 
-// MarkJobFailed marks a job as failed
-func (s *JobsService) MarkJobFailed(jobID, errorMsg string) error {
+// MarkJobFailed marks a job as failed, recording the failure reason alongside the error message
+func (s *JobsService) MarkJobFailed(jobID, failureReason, errorMsg string) error {
 	_, err := s.db.Exec(
-		"UPDATE jobs SET status = 'failed', error = ?, completed_at = datetime('now'), version = version + 1, updated_at = datetime('now') WHERE id = ?",
-		errorMsg, jobID,
+		"UPDATE jobs SET status = 'failed', failure_reason = ?, error = ?, completed_at = CURRENT_TIMESTAMP, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		failureReason, errorMsg, jobID,
 	)
 	return err
 }
 
 // ScheduleJobRetry schedules a job for retry
-func (s *JobsService) ScheduleJobRetry(jobID string, retryAt time.Time, errorMsg string) error {
+func (s *JobsService) ScheduleJobRetry(jobID string, retryAt time.Time, failureReason, errorMsg string) error {
 	_, err := s.db.Exec(
-		"UPDATE jobs SET status = 'pending', retry_count = retry_count + 1, run_at = ?, error = ?, version = version + 1, updated_at = datetime('now') WHERE id = ?",
-		retryAt.Format("2006-01-02 15:04:05"), errorMsg, jobID,
+		"UPDATE jobs SET status = 'pending', retry_count = retry_count + 1, run_at = ?, failure_reason = ?, error = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		retryAt.Format("2006-01-02 15:04:05"), failureReason, errorMsg, jobID,
 	)
 	return err
 }
 
 // ResetJobToPending resets a job back to pending status
 func (s *JobsService) ResetJobToPending(jobID string) error {
-	query := `UPDATE jobs SET status = 'pending', started_at = NULL, version = version + 1, updated_at = datetime('now') WHERE id = ?`
+	query := `UPDATE jobs SET status = 'pending', started_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
 	_, err := s.db.Exec(query, jobID)
 	return err
 }
 
+// ResetStaleRunningJobs puts jobs that have been running since before
+// startedBefore back to pending. A job left running that long lost its
+// worker, most likely to a crash; its checkpoint is kept so the next run
+// resumes.
+func (s *JobsService) ResetStaleRunningJobs(startedBefore time.Time) (int64, error) {
+	result, err := s.db.Exec(`
+		UPDATE jobs SET status = 'pending', started_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE status = 'running' AND started_at < ?
+	`, startedBefore.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset stale running jobs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetJobCheckpoint returns a job's saved checkpoint, or nil when it has none
+func (s *JobsService) GetJobCheckpoint(jobID string) (*string, error) {
+	var checkpoint sql.NullString
+	if err := s.db.QueryRow(`SELECT checkpoint FROM jobs WHERE id = ?`, jobID).Scan(&checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to get job checkpoint: %w", err)
+	}
+	if !checkpoint.Valid {
+		return nil, nil
+	}
+	return &checkpoint.String, nil
+}
+
+// SaveJobCheckpoint replaces a job's checkpoint; nil clears it
+func (s *JobsService) SaveJobCheckpoint(jobID string, checkpoint *string) error {
+	var checkpointAt any
+	if checkpoint != nil {
+		checkpointAt = time.Now().UTC().Format("2006-01-02 15:04:05")
+	}
+	_, err := s.db.Exec(`UPDATE jobs SET checkpoint = ?, checkpoint_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		checkpoint, checkpointAt, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to save job checkpoint: %w", err)
+	}
+	return nil
+}
+
 // RecordJobMetric records job execution metrics
 func (s *JobsService) RecordJobMetric(queueName, jobType, status string, durationMs int64) error {
 	_, err := s.db.Exec(`
 		INSERT INTO job_metrics (queue_name, job_type, status, duration_ms, recorded_at)
-		VALUES (?, ?, ?, ?, datetime('now'))
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
 	`, queueName, jobType, status, durationMs)
 
 	return err
@@ -250,7 +341,8 @@ func (s *JobsService) GetJobMetrics(queueName, jobType string, timeWindow time.D
 	query := `
 		SELECT
 			COUNT(*) as total_jobs,
-			SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END) as failed_jobs,
+			SUM(CASE WHEN status IN ('failed', 'timed_out') THEN 1 ELSE 0 END) as failed_jobs,
+			SUM(CASE WHEN status = 'timed_out' THEN 1 ELSE 0 END) as timed_out_jobs,
 			AVG(CASE WHEN duration_ms IS NOT NULL THEN duration_ms ELSE 0 END) as avg_latency,
 			COUNT(*) / ? as jobs_per_second
 		FROM job_metrics
@@ -269,25 +361,27 @@ func (s *JobsService) GetJobMetrics(queueName, jobType string, timeWindow time.D
 		args = append(args, jobType)
 	}
 
-	var totalJobs, failedJobs int64
-	var avgLatency, jobsPerSecond float64
+	var totalJobs int64
+	var failedJobs, timedOutJobs sql.NullInt64
+	var avgLatency, jobsPerSecond sql.NullFloat64
 
-	err := s.db.QueryRow(query, args...).Scan(&totalJobs, &failedJobs, &avgLatency, &jobsPerSecond)
+	err := s.db.QueryRow(query, args...).Scan(&totalJobs, &failedJobs, &timedOutJobs, &avgLatency, &jobsPerSecond)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get basic metrics: %w", err)
 	}
 
 	errorRate := float64(0)
 	if totalJobs > 0 {
-		errorRate = float64(failedJobs) / float64(totalJobs) * 100
+		errorRate = float64(failedJobs.Int64) / float64(totalJobs) * 100
 	}
 
 	return &JobMetricsResult{
 		TotalJobs:        totalJobs,
-		FailedJobs:       failedJobs,
+		FailedJobs:       failedJobs.Int64,
+		TimedOutJobs:     timedOutJobs.Int64,
 		ErrorRate:        errorRate,
-		AverageLatencyMs: avgLatency,
-		JobsPerSecond:    jobsPerSecond,
+		AverageLatencyMs: avgLatency.Float64,
+		JobsPerSecond:    jobsPerSecond.Float64,
 	}, nil
 }
 
@@ -295,7 +389,280 @@ func (s *JobsService) GetJobMetrics(queueName, jobType string, timeWindow time.D
 type JobMetricsResult struct {
 	TotalJobs        int64   `json:"total_jobs"`
 	FailedJobs       int64   `json:"failed_jobs"`
+	TimedOutJobs     int64   `json:"timed_out_jobs"`
 	ErrorRate        float64 `json:"error_rate"`
 	AverageLatencyMs float64 `json:"average_latency_ms"`
 	JobsPerSecond    float64 `json:"jobs_per_second"`
 }
+
+// JobLogEntry represents a log line captured while a job handler ran
+type JobLogEntry struct {
+	ID       int64                  `json:"id"`
+	JobID    string                 `json:"job_id"`
+	Level    string                 `json:"level"`
+	Message  string                 `json:"message"`
+	Fields   map[string]interface{} `json:"fields,omitempty"`
+	LoggedAt time.Time              `json:"logged_at"`
+}
+
+// AppendJobLog stores a single log line for a job. fields is a JSON object or empty.
+func (s *JobsService) AppendJobLog(jobID, level, message, fields string) error {
+	var fieldsValue interface{}
+	if fields != "" {
+		fieldsValue = fields
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO job_logs (job_id, level, message, fields, logged_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, jobID, level, message, fieldsValue, time.Now().UTC().Format("2006-01-02 15:04:05.000"))
+	if err != nil {
+		return fmt.Errorf("failed to append job log: %w", err)
+	}
+
+	return nil
+}
+
+// GetJobLogs returns the captured log lines for a job in the order they were written
+func (s *JobsService) GetJobLogs(jobID string, limit int) ([]JobLogEntry, error) {
+	rows, err := s.db.Query(`
+		SELECT id, job_id, level, message, fields, logged_at
+		FROM job_logs
+		WHERE job_id = ?
+		ORDER BY id ASC
+		LIMIT ?
+	`, jobID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []JobLogEntry{}
+	for rows.Next() {
+		var entry JobLogEntry
+		var fields sql.NullString
+		var loggedAt string
+
+		if err := rows.Scan(&entry.ID, &entry.JobID, &entry.Level, &entry.Message, &fields, &loggedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan job log: %w", err)
+		}
+
+		if fields.Valid && fields.String != "" {
+			if err := json.Unmarshal([]byte(fields.String), &entry.Fields); err != nil {
+				return nil, fmt.Errorf("failed to parse job log fields: %w", err)
+			}
+		}
+
+		if entry.LoggedAt, err = time.Parse("2006-01-02 15:04:05.000", loggedAt); err != nil {
+			if entry.LoggedAt, err = time.Parse(time.RFC3339, loggedAt); err != nil {
+				return nil, fmt.Errorf("failed to parse logged_at time: %w", err)
+			}
+		}
+
+		logs = append(logs, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating job log rows: %w", err)
+	}
+
+	return logs, nil
+}
+
+// DeleteJobLogsBefore removes captured job logs older than the cutoff
+func (s *JobsService) DeleteJobLogsBefore(cutoff time.Time) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM job_logs WHERE logged_at < ?`, cutoff.UTC().Format("2006-01-02 15:04:05.000"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old job logs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// Admin job listing limits
+const (
+	DefaultJobListLimit = 50
+	MaxJobListLimit     = 500
+)
+
+// JobFilter narrows the admin job list; empty fields match everything
+type JobFilter struct {
+	QueueName string
+	Status    string
+	JobType   string
+	Limit     int
+	Offset    int
+}
+
+// JobRecord is a job row as the admin API shows it. Payload is only loaded
+// for a single job.
+type JobRecord struct {
+	ID             string          `db:"id" json:"id"`
+	QueueName      string          `db:"queue_name" json:"queue_name"`
+	JobType        string          `db:"job_type" json:"job_type"`
+	Status         string          `db:"status" json:"status"`
+	Priority       int             `db:"priority" json:"priority"`
+	MaxRetries     int             `db:"max_retries" json:"max_retries"`
+	RetryCount     int             `db:"retry_count" json:"retry_count"`
+	RunAt          time.Time       `db:"run_at" json:"run_at"`
+	CreatedAt      time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time       `db:"updated_at" json:"updated_at"`
+	StartedAt      *time.Time      `db:"started_at" json:"started_at,omitempty"`
+	CompletedAt    *time.Time      `db:"completed_at" json:"completed_at,omitempty"`
+	Error          *string         `db:"error" json:"error,omitempty"`
+	FailureReason  *string         `db:"failure_reason" json:"failure_reason,omitempty"`
+	IdempotencyKey *string         `db:"idempotency_key" json:"idempotency_key,omitempty"`
+	Checkpoint     *string         `db:"checkpoint" json:"checkpoint,omitempty"`
+	CheckpointAt   *time.Time      `db:"checkpoint_at" json:"checkpoint_at,omitempty"`
+	RawPayload     string          `db:"payload" json:"-"`
+	Payload        json.RawMessage `db:"-" json:"payload,omitempty"`
+}
+
+// jobRecordColumns are the columns listed for each job, payload aside
+const jobRecordColumns = `id, queue_name, job_type, status, priority, max_retries, retry_count, run_at,
+	created_at, updated_at, started_at, completed_at, error, failure_reason, idempotency_key, checkpoint, checkpoint_at`
+
+// ListJobs returns the newest jobs matching filter
+func (s *JobsService) ListJobs(filter JobFilter) ([]JobRecord, error) {
+	query := `SELECT ` + jobRecordColumns + ` FROM jobs WHERE 1 = 1`
+	var args []any
+	if filter.QueueName != "" {
+		query += " AND queue_name = ?"
+		args = append(args, filter.QueueName)
+	}
+	if filter.Status != "" {
+		query += " AND status = ?"
+		args = append(args, filter.Status)
+	}
+	if filter.JobType != "" {
+		query += " AND job_type = ?"
+		args = append(args, filter.JobType)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultJobListLimit
+	}
+	query += " ORDER BY created_at DESC, id LIMIT ? OFFSET ?"
+	args = append(args, min(limit, MaxJobListLimit), max(filter.Offset, 0))
+
+	jobs, err := database.QueryAll[JobRecord](s.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	if jobs == nil {
+		jobs = []JobRecord{}
+	}
+	return jobs, nil
+}
+
+// GetJob returns one job with its payload
+func (s *JobsService) GetJob(jobID string) (*JobRecord, error) {
+	job, err := database.QueryOne[JobRecord](s.db, `SELECT `+jobRecordColumns+`, payload FROM jobs WHERE id = ?`, jobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("job not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	if json.Valid([]byte(job.RawPayload)) {
+		job.Payload = json.RawMessage(job.RawPayload)
+	} else {
+		// Keep a payload that is not JSON readable rather than failing the request
+		job.Payload, _ = json.Marshal(job.RawPayload) // nolint:errcheck
+	}
+	return job, nil
+}
+
+// FindJobByIdempotencyKey returns the job enqueued with key, or nil when
+// there is none
+func (s *JobsService) FindJobByIdempotencyKey(key string) (*JobRecord, error) {
+	job, err := database.QueryOne[JobRecord](s.db, `SELECT `+jobRecordColumns+`, payload FROM jobs WHERE idempotency_key = ?`, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find job: %w", err)
+	}
+	return job, nil
+}
+
+// RetryJob puts a failed or cancelled job back on its queue to run now with
+// its full retry allowance
+func (s *JobsService) RetryJob(jobID string) (*JobRecord, error) {
+	result, err := s.db.Exec(`
+		UPDATE jobs
+		SET status = 'pending', retry_count = 0, run_at = ?, started_at = NULL, completed_at = NULL,
+			error = NULL, failure_reason = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status IN ('failed', 'cancelled')
+	`, time.Now().UTC().Format("2006-01-02 15:04:05"), jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retry job: %w", err)
+	}
+	return s.changedJob(result, jobID, "only failed or cancelled jobs can be retried")
+}
+
+// CancelJob stops a pending job from running. A running job cannot be
+// cancelled; its handler is already under way.
+func (s *JobsService) CancelJob(jobID string) (*JobRecord, error) {
+	result, err := s.db.Exec(`
+		UPDATE jobs
+		SET status = 'cancelled', completed_at = CURRENT_TIMESTAMP, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = 'pending'
+	`, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel job: %w", err)
+	}
+	return s.changedJob(result, jobID, "only pending jobs can be cancelled")
+}
+
+// changedJob returns the job an admin update touched, or why it touched
+// nothing: the job is missing or in the wrong state
+func (s *JobsService) changedJob(result sql.Result, jobID, wrongState string) (*JobRecord, error) {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check rows affected for job %s: %w", jobID, err)
+	}
+	job, err := s.GetJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("%s", wrongState)
+	}
+	return job, nil
+}
+
+// QueueDepth counts a queue's jobs by state. Ready jobs are pending and due;
+// a growing OldestReadyAt means the queue's workers are not keeping up.
+type QueueDepth struct {
+	QueueName     string     `db:"queue_name" json:"queue_name"`
+	Pending       int        `db:"pending" json:"pending"`
+	Ready         int        `db:"ready" json:"ready"`
+	Running       int        `db:"running" json:"running"`
+	Failed        int        `db:"failed" json:"failed"`
+	OldestReadyAt *time.Time `db:"oldest_ready_at" json:"oldest_ready_at,omitempty"`
+}
+
+// GetQueueDepths returns the depth of every queue with jobs in it
+func (s *JobsService) GetQueueDepths() ([]QueueDepth, error) {
+	now := time.Now().UTC().Format("2006-01-02 15:04:05")
+	depths, err := database.QueryAll[QueueDepth](s.db, `
+		SELECT
+			queue_name,
+			SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END) AS pending,
+			SUM(CASE WHEN status = 'pending' AND run_at <= ? THEN 1 ELSE 0 END) AS ready,
+			SUM(CASE WHEN status = 'running' THEN 1 ELSE 0 END) AS running,
+			SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END) AS failed,
+			MIN(CASE WHEN status = 'pending' AND run_at <= ? THEN run_at END) AS oldest_ready_at
+		FROM jobs
+		GROUP BY queue_name
+		ORDER BY queue_name
+	`, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue depths: %w", err)
+	}
+	if depths == nil {
+		depths = []QueueDepth{}
+	}
+	return depths, nil
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobsService_AdminListRetryCancel(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewJobsService(db)
+
+	past := time.Now().UTC().Add(-time.Minute)
+	syncID, err := service.EnqueueJob("calendar", "calendar_sync", `{"user_id":"u1"}`, 0, 3, past, nil)
+	require.NoError(t, err)
+	digestID, err := service.EnqueueJob("default", "digest", `{}`, 0, 3, time.Now().UTC().Add(time.Hour), nil)
+	require.NoError(t, err)
+	require.NoError(t, service.MarkJobFailed(syncID, "error", "token expired"))
+
+	jobs, err := service.ListJobs(JobFilter{QueueName: "calendar", Status: "failed"})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, syncID, jobs[0].ID)
+	assert.Nil(t, jobs[0].Payload, "lists leave out payloads")
+
+	job, err := service.GetJob(syncID)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"user_id":"u1"}`, string(job.Payload))
+	require.NotNil(t, job.Error)
+	assert.Equal(t, "token expired", *job.Error)
+
+	// Only pending jobs can be cancelled, only failed or cancelled ones retried
+	_, err = service.CancelJob(syncID)
+	assert.EqualError(t, err, "only pending jobs can be cancelled")
+	_, err = service.RetryJob(digestID)
+	assert.EqualError(t, err, "only failed or cancelled jobs can be retried")
+	_, err = service.RetryJob("missing")
+	assert.EqualError(t, err, "job not found")
+
+	job, err = service.RetryJob(syncID)
+	require.NoError(t, err)
+	assert.Equal(t, "pending", job.Status)
+	assert.Nil(t, job.Error)
+
+	job, err = service.CancelJob(digestID)
+	require.NoError(t, err)
+	assert.Equal(t, "cancelled", job.Status)
+
+	depths, err := service.GetQueueDepths()
+	require.NoError(t, err)
+	require.Len(t, depths, 2)
+	assert.Equal(t, "calendar", depths[0].QueueName)
+	assert.Equal(t, 1, depths[0].Ready)
+	assert.NotNil(t, depths[0].OldestReadyAt)
+	assert.Equal(t, "default", depths[1].QueueName)
+	assert.Zero(t, depths[1].Pending)
+	assert.Nil(t, depths[1].OldestReadyAt)
+}
@@ -0,0 +1,508 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/ids"
+	"famstack/internal/models"
+)
+
+const (
+	recipeColumns       = `id, family_id, title, instructions, servings, created_by, created_at, updated_at`
+	ingredientColumns   = `id, recipe_id, name, quantity, unit, position`
+	mealPlanColumns     = `id, family_id, meal_date, meal_type, recipe_id, title, notes, meal_time, event_id, created_by, created_at, updated_at`
+	shoppingItemColumns = `id, family_id, name, quantity, unit, checked, generated, created_by, created_at, updated_at`
+)
+
+// MealsService manages the family's recipes, meal plan and shopping list.
+// Planned dinners are put on the calendar, and the shopping list can be
+// filled from the ingredients of the meals planned for a range of days.
+type MealsService struct {
+	db       *database.Fascade
+	calendar *CalendarService
+}
+
+// NewMealsService creates a new meals service
+func NewMealsService(db *database.Fascade, calendar *CalendarService) *MealsService {
+	return &MealsService{db: db, calendar: calendar}
+}
+
+// ListRecipes returns the family's recipes by title, with their ingredients
+func (s *MealsService) ListRecipes(familyID string) ([]models.Recipe, error) {
+	recipes, err := database.QueryAll[models.Recipe](s.db, `SELECT `+recipeColumns+` FROM recipes WHERE family_id = ? ORDER BY title`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recipes: %w", err)
+	}
+	if recipes == nil {
+		return []models.Recipe{}, nil
+	}
+
+	ingredients, err := database.QueryAll[models.RecipeIngredient](s.db, `
+		SELECT `+ingredientColumns+` FROM recipe_ingredients
+		WHERE recipe_id IN (SELECT id FROM recipes WHERE family_id = ?)
+		ORDER BY recipe_id, position
+	`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recipe ingredients: %w", err)
+	}
+	byRecipe := make(map[string][]models.RecipeIngredient)
+	for _, ingredient := range ingredients {
+		byRecipe[ingredient.RecipeID] = append(byRecipe[ingredient.RecipeID], ingredient)
+	}
+	for i := range recipes {
+		recipes[i].Ingredients = byRecipe[recipes[i].ID]
+		if recipes[i].Ingredients == nil {
+			recipes[i].Ingredients = []models.RecipeIngredient{}
+		}
+	}
+	return recipes, nil
+}
+
+// GetRecipe returns one of the family's recipes with its ingredients
+func (s *MealsService) GetRecipe(familyID, recipeID string) (*models.Recipe, error) {
+	recipe, err := database.QueryOne[models.Recipe](s.db, `SELECT `+recipeColumns+` FROM recipes WHERE id = ? AND family_id = ?`, recipeID, familyID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("recipe not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recipe: %w", err)
+	}
+
+	recipe.Ingredients, err = database.QueryAll[models.RecipeIngredient](s.db, `SELECT `+ingredientColumns+` FROM recipe_ingredients WHERE recipe_id = ? ORDER BY position`, recipeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recipe ingredients: %w", err)
+	}
+	if recipe.Ingredients == nil {
+		recipe.Ingredients = []models.RecipeIngredient{}
+	}
+	return recipe, nil
+}
+
+// CreateRecipe adds a recipe
+func (s *MealsService) CreateRecipe(familyID, createdBy string, req *models.CreateRecipeRequest) (*models.Recipe, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	recipeID := ids.New("recipe")
+	now := time.Now().UTC()
+	err := s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`
+			INSERT INTO recipes (id, family_id, title, instructions, servings, created_by, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, recipeID, familyID, req.Title, req.Instructions, req.Servings, optionalString(createdBy), now, now); err != nil {
+			return fmt.Errorf("failed to insert recipe: %w", err)
+		}
+		if err := insertIngredients(tx, recipeID, req.Ingredients); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recipe: %w", err)
+	}
+	return s.GetRecipe(familyID, recipeID)
+}
+
+// UpdateRecipe changes a recipe. Meals already planned with it keep their
+// titles.
+func (s *MealsService) UpdateRecipe(familyID, recipeID string, req *models.UpdateRecipeRequest) (*models.Recipe, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	recipe, err := s.GetRecipe(familyID, recipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil {
+		recipe.Title = *req.Title
+	}
+	if req.Instructions != nil {
+		recipe.Instructions = *req.Instructions
+	}
+	if req.Servings != nil {
+		recipe.Servings = *req.Servings
+	}
+
+	err = s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`
+			UPDATE recipes SET title = ?, instructions = ?, servings = ?, updated_at = ?
+			WHERE id = ? AND family_id = ?
+		`, recipe.Title, recipe.Instructions, recipe.Servings, time.Now().UTC(), recipeID, familyID); err != nil {
+			return fmt.Errorf("failed to update recipe: %w", err)
+		}
+		if req.Ingredients != nil {
+			if _, err := tx.Exec(`DELETE FROM recipe_ingredients WHERE recipe_id = ?`, recipeID); err != nil {
+				return fmt.Errorf("failed to clear ingredients: %w", err)
+			}
+			if err := insertIngredients(tx, recipeID, *req.Ingredients); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update recipe: %w", err)
+	}
+	return s.GetRecipe(familyID, recipeID)
+}
+
+// DeleteRecipe removes a recipe. Meals planned with it stay on the plan.
+func (s *MealsService) DeleteRecipe(familyID, recipeID string) error {
+	result, err := s.db.Exec(`DELETE FROM recipes WHERE id = ? AND family_id = ?`, recipeID, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete recipe: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("recipe not found")
+	}
+	return nil
+}
+
+func insertIngredients(tx *sql.Tx, recipeID string, ingredients []models.IngredientInput) error {
+	for i, ingredient := range ingredients {
+		ingredientID := ids.New("ingredient")
+		if _, err := tx.Exec(`
+			INSERT INTO recipe_ingredients (id, recipe_id, name, quantity, unit, position)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, ingredientID, recipeID, ingredient.Name, ingredient.Quantity, ingredient.Unit, i); err != nil {
+			return fmt.Errorf("failed to insert ingredient: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetMealPlan returns the meals planned from start for a number of days, by
+// day and then meal type. An empty start is the family's today.
+func (s *MealsService) GetMealPlan(familyID, start string, days int) (*models.MealPlanResponse, error) {
+	if start == "" {
+		loc, err := s.familyLocation(familyID)
+		if err != nil {
+			return nil, err
+		}
+		start = time.Now().In(loc).Format("2006-01-02")
+	}
+	startDate, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date: %w", err)
+	}
+	end := startDate.AddDate(0, 0, days-1).Format("2006-01-02")
+
+	meals, err := database.QueryAll[models.MealPlan](s.db, `
+		SELECT `+mealPlanColumns+` FROM meal_plans
+		WHERE family_id = ? AND meal_date BETWEEN ? AND ?
+		ORDER BY meal_date,
+			CASE meal_type WHEN 'breakfast' THEN 0 WHEN 'lunch' THEN 1 WHEN 'dinner' THEN 2 ELSE 3 END
+	`, familyID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meal plan: %w", err)
+	}
+	if meals == nil {
+		meals = []models.MealPlan{}
+	}
+	return &models.MealPlanResponse{Start: start, End: end, Meals: meals}, nil
+}
+
+// GetMeal returns one of the family's planned meals
+func (s *MealsService) GetMeal(familyID, mealID string) (*models.MealPlan, error) {
+	meal, err := database.QueryOne[models.MealPlan](s.db, `SELECT `+mealPlanColumns+` FROM meal_plans WHERE id = ? AND family_id = ?`, mealID, familyID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("meal not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meal: %w", err)
+	}
+	return meal, nil
+}
+
+// PlanMeal sets the meal of a type on a day, replacing the one planned
+// already. A dinner gets a calendar event at its meal time, replacing the
+// old meal's; other meals have none.
+func (s *MealsService) PlanMeal(familyID, createdBy string, req *models.PlanMealRequest) (*models.MealPlan, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req.RecipeID != nil {
+		recipe, err := s.GetRecipe(familyID, *req.RecipeID)
+		if err != nil {
+			return nil, err
+		}
+		if req.Title == "" {
+			req.Title = recipe.Title
+		}
+	}
+
+	var dinnerStart time.Time
+	if req.MealType == models.MealDinner {
+		loc, err := s.familyLocation(familyID)
+		if err != nil {
+			return nil, err
+		}
+		mealTime := models.DefaultDinnerTime
+		if req.MealTime != nil {
+			mealTime = *req.MealTime
+		}
+		if dinnerStart, err = time.ParseInLocation("2006-01-02 15:04", req.Date+" "+mealTime, loc); err != nil {
+			return nil, fmt.Errorf("invalid meal time: %w", err)
+		}
+	}
+
+	now := time.Now().UTC()
+	var mealID string
+	err := s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		var oldEventID sql.NullString
+		err := tx.QueryRow(`SELECT id, event_id FROM meal_plans WHERE family_id = ? AND meal_date = ? AND meal_type = ?`,
+			familyID, req.Date, req.MealType).Scan(&mealID, &oldEventID)
+		switch {
+		case err == sql.ErrNoRows:
+			mealID = ids.New("meal")
+			if _, err := tx.Exec(`
+				INSERT INTO meal_plans (id, family_id, meal_date, meal_type, title, created_by, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			`, mealID, familyID, req.Date, req.MealType, req.Title, optionalString(createdBy), now, now); err != nil {
+				return fmt.Errorf("failed to insert meal: %w", err)
+			}
+		case err != nil:
+			return fmt.Errorf("failed to look up planned meal: %w", err)
+		case oldEventID.Valid:
+			if _, err := tx.Exec(`DELETE FROM unified_calendar_events WHERE id = ? AND family_id = ?`, oldEventID.String, familyID); err != nil {
+				return fmt.Errorf("failed to delete dinner event: %w", err)
+			}
+		}
+
+		var eventID *string
+		if req.MealType == models.MealDinner {
+			id := generateUnifiedEventID()
+			eventID = &id
+			if _, err := tx.Exec(`
+				INSERT INTO unified_calendar_events (id, family_id, title, description, start_time, end_time,
+				                                     location, all_day, event_type, created_by, priority,
+				                                     created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, '', false, ?, ?, 0, ?, ?)
+			`, id, familyID, "Dinner: "+req.Title, req.Notes, dinnerStart.UTC(), dinnerStart.Add(models.DinnerEventDuration).UTC(),
+				models.EventTypeEvent, optionalString(createdBy), now, now); err != nil {
+				return fmt.Errorf("failed to create dinner event: %w", err)
+			}
+		}
+
+		if _, err := tx.Exec(`
+			UPDATE meal_plans SET recipe_id = ?, title = ?, notes = ?, meal_time = ?, event_id = ?, updated_at = ?
+			WHERE id = ?
+		`, req.RecipeID, req.Title, req.Notes, req.MealTime, eventID, now, mealID); err != nil {
+			return fmt.Errorf("failed to update meal: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan meal: %w", err)
+	}
+	if req.MealType == models.MealDinner {
+		s.calendar.publishChange(familyID)
+	}
+	return s.GetMeal(familyID, mealID)
+}
+
+// RemoveMeal takes a meal off the plan, along with its calendar event
+func (s *MealsService) RemoveMeal(familyID, mealID string) error {
+	meal, err := s.GetMeal(familyID, mealID)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		if meal.EventID != nil {
+			if _, err := tx.Exec(`DELETE FROM unified_calendar_events WHERE id = ? AND family_id = ?`, *meal.EventID, familyID); err != nil {
+				return fmt.Errorf("failed to delete dinner event: %w", err)
+			}
+		}
+		if _, err := tx.Exec(`DELETE FROM meal_plans WHERE id = ?`, mealID); err != nil {
+			return fmt.Errorf("failed to delete meal: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove meal: %w", err)
+	}
+	if meal.EventID != nil {
+		s.calendar.publishChange(familyID)
+	}
+	return nil
+}
+
+// ListShoppingList returns the family's shopping list, what's left to buy
+// first
+func (s *MealsService) ListShoppingList(familyID string) ([]models.ShoppingListItem, error) {
+	items, err := database.QueryAll[models.ShoppingListItem](s.db, `
+		SELECT `+shoppingItemColumns+` FROM shopping_list_items
+		WHERE family_id = ?
+		ORDER BY checked, name COLLATE NOCASE
+	`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shopping list: %w", err)
+	}
+	if items == nil {
+		items = []models.ShoppingListItem{}
+	}
+	return items, nil
+}
+
+// GetShoppingListItem returns one item on the family's shopping list
+func (s *MealsService) GetShoppingListItem(familyID, itemID string) (*models.ShoppingListItem, error) {
+	item, err := database.QueryOne[models.ShoppingListItem](s.db, `SELECT `+shoppingItemColumns+` FROM shopping_list_items WHERE id = ? AND family_id = ?`, itemID, familyID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("shopping list item not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shopping list item: %w", err)
+	}
+	return item, nil
+}
+
+// AddShoppingListItem puts something on the list by hand
+func (s *MealsService) AddShoppingListItem(familyID, createdBy string, req *models.CreateShoppingListItemRequest) (*models.ShoppingListItem, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	itemID := ids.New("shopping")
+	now := time.Now().UTC()
+	_, err := s.db.Exec(`
+		INSERT INTO shopping_list_items (id, family_id, name, quantity, unit, checked, generated, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, FALSE, FALSE, ?, ?, ?)
+	`, itemID, familyID, req.Name, req.Quantity, req.Unit, optionalString(createdBy), now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add shopping list item: %w", err)
+	}
+	return s.GetShoppingListItem(familyID, itemID)
+}
+
+// UpdateShoppingListItem changes an item, usually to check it off
+func (s *MealsService) UpdateShoppingListItem(familyID, itemID string, req *models.UpdateShoppingListItemRequest) (*models.ShoppingListItem, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	item, err := s.GetShoppingListItem(familyID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		item.Name = *req.Name
+	}
+	if req.Quantity != nil {
+		item.Quantity = *req.Quantity
+	}
+	if req.Unit != nil {
+		item.Unit = *req.Unit
+	}
+	if req.Checked != nil {
+		item.Checked = *req.Checked
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE shopping_list_items SET name = ?, quantity = ?, unit = ?, checked = ?, updated_at = ?
+		WHERE id = ? AND family_id = ?
+	`, item.Name, item.Quantity, item.Unit, item.Checked, time.Now().UTC(), itemID, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update shopping list item: %w", err)
+	}
+	return s.GetShoppingListItem(familyID, itemID)
+}
+
+// DeleteShoppingListItem takes an item off the list
+func (s *MealsService) DeleteShoppingListItem(familyID, itemID string) error {
+	result, err := s.db.Exec(`DELETE FROM shopping_list_items WHERE id = ? AND family_id = ?`, itemID, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete shopping list item: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("shopping list item not found")
+	}
+	return nil
+}
+
+// GenerateShoppingList adds the ingredients of the recipes planned from
+// start to end to the shopping list, combining an ingredient used by more
+// than one meal. The unchecked items an earlier generation added are
+// replaced, so generating again after changing the plan doesn't double up;
+// items added by hand and ones already bought are kept.
+func (s *MealsService) GenerateShoppingList(familyID, createdBy string, req *models.GenerateShoppingListRequest) ([]models.ShoppingListItem, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	ingredients, err := database.QueryAll[models.RecipeIngredient](s.db, `
+		SELECT ri.id, ri.recipe_id, ri.name, ri.quantity, ri.unit, ri.position
+		FROM meal_plans mp
+		JOIN recipe_ingredients ri ON ri.recipe_id = mp.recipe_id
+		WHERE mp.family_id = ? AND mp.meal_date BETWEEN ? AND ?
+		ORDER BY mp.meal_date, mp.meal_type, ri.position
+	`, familyID, req.Start, req.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get planned ingredients: %w", err)
+	}
+
+	now := time.Now().UTC()
+	err = s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM shopping_list_items WHERE family_id = ? AND generated = TRUE AND checked = FALSE`, familyID); err != nil {
+			return fmt.Errorf("failed to clear generated items: %w", err)
+		}
+		for _, item := range combineIngredients(ingredients) {
+			if _, err := tx.Exec(`
+				INSERT INTO shopping_list_items (id, family_id, name, quantity, unit, checked, generated, created_by, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, FALSE, TRUE, ?, ?, ?)
+			`, ids.New("shopping"), familyID, item.Name, item.Quantity, item.Unit,
+				optionalString(createdBy), now, now); err != nil {
+				return fmt.Errorf("failed to add %s: %w", item.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate shopping list: %w", err)
+	}
+	return s.ListShoppingList(familyID)
+}
+
+func (s *MealsService) familyLocation(familyID string) (*time.Location, error) {
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for meal plan: %w", err)
+	}
+	loc, err := loadLocation(familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid family timezone %s: %w", familyTimezone, err)
+	}
+	return loc, nil
+}
+
+// combineIngredients merges ingredients with the same name and unit, adding
+// up their quantities, in the order they first appear
+func combineIngredients(ingredients []models.RecipeIngredient) []models.IngredientInput {
+	var combined []models.IngredientInput
+	index := make(map[string]int)
+	for _, ingredient := range ingredients {
+		key := strings.ToLower(ingredient.Name) + "\x00" + strings.ToLower(ingredient.Unit)
+		if i, ok := index[key]; ok {
+			combined[i].Quantity += ingredient.Quantity
+			continue
+		}
+		index[key] = len(combined)
+		combined = append(combined, models.IngredientInput{Name: ingredient.Name, Quantity: ingredient.Quantity, Unit: ingredient.Unit})
+	}
+	return combined
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCombineIngredients(t *testing.T) {
+	combined := combineIngredients([]models.RecipeIngredient{
+		{Name: "Onion", Quantity: 1},
+		{Name: "rice", Quantity: 2, Unit: "cups"},
+		{Name: "onion", Quantity: 2},
+		{Name: "Rice", Quantity: 500, Unit: "g"},
+		{Name: "rice", Quantity: 1, Unit: "Cups"},
+	})
+
+	assert.Equal(t, []models.IngredientInput{
+		{Name: "Onion", Quantity: 3},
+		{Name: "rice", Quantity: 3, Unit: "cups"},
+		{Name: "Rice", Quantity: 500, Unit: "g"},
+	}, combined)
+}
+
+func TestMealsService_DinnerIsKeptOnTheCalendar(t *testing.T) {
+	db := setupTestDB(t)
+	calendar := NewCalendarService(db)
+	service := NewMealsService(db, calendar)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	recipe, err := service.CreateRecipe(familyID, memberID, &models.CreateRecipeRequest{
+		Title:       "Tacos",
+		Ingredients: []models.IngredientInput{{Name: "Tortillas", Quantity: 8}},
+	})
+	require.NoError(t, err)
+
+	meal, err := service.PlanMeal(familyID, memberID, &models.PlanMealRequest{Date: "2025-10-06", MealType: "dinner", RecipeID: &recipe.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "Tacos", meal.Title)
+	require.NotNil(t, meal.EventID)
+
+	event, err := calendar.GetUnifiedCalendarEvent(*meal.EventID)
+	require.NoError(t, err)
+	assert.Equal(t, "Dinner: Tacos", event.Title)
+	assert.Equal(t, time.Date(2025, 10, 6, 18, 0, 0, 0, time.UTC), event.StartTime.UTC())
+
+	// Planning the dinner again replaces the meal and its event
+	mealTime := "19:30"
+	replanned, err := service.PlanMeal(familyID, memberID, &models.PlanMealRequest{Date: "2025-10-06", MealType: "dinner", Title: "Pizza night", MealTime: &mealTime})
+	require.NoError(t, err)
+	assert.Equal(t, meal.ID, replanned.ID)
+	assert.Nil(t, replanned.RecipeID)
+	require.NotNil(t, replanned.EventID)
+	assert.NotEqual(t, *meal.EventID, *replanned.EventID)
+	_, err = calendar.GetUnifiedCalendarEvent(*meal.EventID)
+	assert.Error(t, err)
+
+	require.NoError(t, service.RemoveMeal(familyID, replanned.ID))
+	_, err = calendar.GetUnifiedCalendarEvent(*replanned.EventID)
+	assert.Error(t, err)
+	plan, err := service.GetMealPlan(familyID, "2025-10-06", 7)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Meals)
+}
+
+func TestMealsService_GenerateShoppingList(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewMealsService(db, NewCalendarService(db))
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	stew, err := service.CreateRecipe(familyID, memberID, &models.CreateRecipeRequest{
+		Title:       "Stew",
+		Ingredients: []models.IngredientInput{{Name: "Carrots", Quantity: 3}, {Name: "Stock", Quantity: 2, Unit: "cups"}},
+	})
+	require.NoError(t, err)
+	soup, err := service.CreateRecipe(familyID, memberID, &models.CreateRecipeRequest{
+		Title:       "Soup",
+		Ingredients: []models.IngredientInput{{Name: "carrots", Quantity: 2}},
+	})
+	require.NoError(t, err)
+
+	_, err = service.PlanMeal(familyID, memberID, &models.PlanMealRequest{Date: "2025-10-06", MealType: "lunch", RecipeID: &soup.ID})
+	require.NoError(t, err)
+	_, err = service.PlanMeal(familyID, memberID, &models.PlanMealRequest{Date: "2025-10-07", MealType: "lunch", RecipeID: &stew.ID})
+	require.NoError(t, err)
+	_, err = service.PlanMeal(familyID, memberID, &models.PlanMealRequest{Date: "2025-10-20", MealType: "lunch", RecipeID: &stew.ID})
+	require.NoError(t, err)
+	_, err = service.AddShoppingListItem(familyID, memberID, &models.CreateShoppingListItemRequest{Name: "Coffee"})
+	require.NoError(t, err)
+
+	request := &models.GenerateShoppingListRequest{Start: "2025-10-06", End: "2025-10-12"}
+	items, err := service.GenerateShoppingList(familyID, memberID, request)
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	quantities := make(map[string]float64)
+	for _, item := range items {
+		quantities[item.Name] = item.Quantity
+	}
+	assert.Equal(t, map[string]float64{"carrots": 5, "Stock": 2, "Coffee": 0}, quantities)
+
+	// Generating again replaces the generated items rather than adding to them
+	items, err = service.GenerateShoppingList(familyID, memberID, request)
+	require.NoError(t, err)
+	assert.Len(t, items, 3)
+}
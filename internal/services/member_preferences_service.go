@@ -0,0 +1,153 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/models"
+)
+
+// MemberPreferencesService brings together how each member wants clients to
+// render for them: their color, default calendar view, week start, clock and
+// notifications. Each preference is kept where it already lives, so saving
+// one here is the same as saving it on the member, display or notifications.
+type MemberPreferencesService struct {
+	db            *database.Fascade
+	members       *FamilyMemberService
+	display       *DisplayPreferencesService
+	notifications *NotificationsService
+}
+
+// NewMemberPreferencesService creates a new member preferences service
+func NewMemberPreferencesService(db *database.Fascade, members *FamilyMemberService, display *DisplayPreferencesService, notifications *NotificationsService) *MemberPreferencesService {
+	return &MemberPreferencesService{
+		db:            db,
+		members:       members,
+		display:       display,
+		notifications: notifications,
+	}
+}
+
+// memberPreferencesRow is what member_preferences itself keeps
+type memberPreferencesRow struct {
+	WeekStart string    `db:"week_start"`
+	Clock     string    `db:"clock"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// GetPreferences returns all of a member's preferences, with the defaults
+// for any they never saved
+func (s *MemberPreferencesService) GetPreferences(familyID, memberID string) (*models.MemberPreferences, error) {
+	render, updatedAt, err := s.render(familyID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	notifications, err := s.notifications.GetPreferences(familyID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.MemberPreferences{
+		MemberID:          memberID,
+		RenderPreferences: *render,
+		Notifications:     notifications,
+		UpdatedAt:         updatedAt,
+	}, nil
+}
+
+// RenderPreferences returns how a member wants dates, times and themselves
+// drawn
+func (s *MemberPreferencesService) RenderPreferences(familyID, memberID string) (*models.RenderPreferences, error) {
+	render, _, err := s.render(familyID, memberID)
+	return render, err
+}
+
+// UpdatePreferences saves the preferences the request sets. Everything is
+// validated before anything is written.
+func (s *MemberPreferencesService) UpdatePreferences(familyID, memberID string, req *models.UpdateMemberPreferencesRequest) (*models.MemberPreferences, error) {
+	if err := req.Validate(s.notifications.Channels()); err != nil {
+		return nil, err
+	}
+
+	if req.Color != nil {
+		if _, err := s.members.UpdateFamilyMember(memberID, &models.UpdateFamilyMemberRequest{Color: req.Color}); err != nil {
+			return nil, err
+		}
+	}
+	if req.DefaultView != nil {
+		if _, err := s.display.SaveMemberPreferences(familyID, memberID, memberID, &models.SaveDisplayPreferencesRequest{DefaultView: req.DefaultView}); err != nil {
+			return nil, err
+		}
+	}
+	if req.WeekStart != nil || req.Clock != nil {
+		render, _, err := s.render(familyID, memberID)
+		if err != nil {
+			return nil, err
+		}
+		if req.WeekStart != nil {
+			render.WeekStart = *req.WeekStart
+		}
+		if req.Clock != nil {
+			render.Clock = *req.Clock
+		}
+		_, err = s.db.Exec(`
+			INSERT INTO member_preferences (member_id, family_id, week_start, clock, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(member_id) DO UPDATE SET
+				week_start = excluded.week_start, clock = excluded.clock, updated_at = excluded.updated_at
+		`, memberID, familyID, render.WeekStart, render.Clock, time.Now().UTC())
+		if err != nil {
+			return nil, fmt.Errorf("failed to save member preferences: %w", err)
+		}
+	}
+	if req.Notifications != nil {
+		if _, err := s.notifications.UpdatePreferences(familyID, memberID, req.Notifications); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetPreferences(familyID, memberID)
+}
+
+// render gathers a member's rendering preferences from where each is kept,
+// and when the ones stored here last changed
+func (s *MemberPreferencesService) render(familyID, memberID string) (*models.RenderPreferences, *time.Time, error) {
+	// Members who never chose a week start get their family's
+	var color sql.NullString
+	var familyWeekStart string
+	err := s.db.QueryRow(`
+		SELECT fm.color, f.week_start FROM family_members fm
+		JOIN families f ON f.id = fm.family_id
+		WHERE fm.id = ? AND fm.family_id = ?
+	`, memberID, familyID).Scan(&color, &familyWeekStart)
+	if err == sql.ErrNoRows {
+		return nil, nil, fmt.Errorf("family member not found")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get member color: %w", err)
+	}
+
+	display, _, err := s.display.ResolvePreferences(familyID, memberID, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	render := &models.RenderPreferences{
+		Color:       color.String,
+		DefaultView: display.DefaultView,
+		WeekStart:   familyWeekStart,
+		Clock:       models.Clock12Hour,
+	}
+	row, err := database.QueryOne[memberPreferencesRow](s.db, `
+		SELECT week_start, clock, updated_at FROM member_preferences WHERE member_id = ? AND family_id = ?
+	`, memberID, familyID)
+	if err == sql.ErrNoRows {
+		return render, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get member preferences: %w", err)
+	}
+	render.WeekStart, render.Clock = row.WeekStart, row.Clock
+	return render, &row.UpdatedAt, nil
+}
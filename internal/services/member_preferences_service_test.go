@@ -0,0 +1,56 @@
+package services
+
+import (
+	"testing"
+
+	"famstack/internal/models"
+	"famstack/internal/validation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemberPreferencesService(t *testing.T) {
+	display, familyID, parentID, childID := newTestDisplayService(t)
+	db := display.db
+	members := NewFamilyMemberService(db)
+	service := NewMemberPreferencesService(db, members, display, NewNotificationsService(db))
+
+	prefs, err := service.GetPreferences(familyID, parentID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DisplayViewWeek, prefs.DefaultView)
+	assert.Equal(t, models.WeekStartSunday, prefs.WeekStart)
+	assert.Equal(t, models.Clock12Hour, prefs.Clock)
+	assert.Nil(t, prefs.UpdatedAt)
+	require.NotNil(t, prefs.Notifications)
+	assert.Equal(t, models.DefaultDigestTime, prefs.Notifications.DigestTime)
+
+	color, view, weekStart, clock := "#ff8800", models.DisplayViewDay, models.WeekStartMonday, models.Clock24Hour
+	digest := true
+	prefs, err = service.UpdatePreferences(familyID, parentID, &models.UpdateMemberPreferencesRequest{
+		Color: &color, DefaultView: &view, WeekStart: &weekStart, Clock: &clock,
+		Notifications: &models.UpdateNotificationPreferencesRequest{DailyDigest: &digest},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.RenderPreferences{Color: color, DefaultView: view, WeekStart: weekStart, Clock: clock}, prefs.RenderPreferences)
+	assert.True(t, prefs.Notifications.DailyDigest)
+	assert.NotNil(t, prefs.UpdatedAt)
+
+	// Each preference is kept where it already lived
+	saved, err := display.GetMemberPreferences(familyID, parentID)
+	require.NoError(t, err)
+	assert.Equal(t, view, saved.DefaultView)
+
+	// Nothing is written when any field is invalid
+	bad := "fortnight"
+	_, err = service.UpdatePreferences(familyID, childID, &models.UpdateMemberPreferencesRequest{Clock: &clock, WeekStart: &bad})
+	var validationErrs validation.ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
+	assert.Equal(t, "week_start", validationErrs[0].Field)
+	render, err := service.RenderPreferences(familyID, childID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Clock12Hour, render.Clock)
+
+	_, err = service.RenderPreferences("fam_other", parentID)
+	assert.EqualError(t, err, "family member not found")
+}
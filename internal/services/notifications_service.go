@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/models"
+	"famstack/internal/notify"
+)
+
+// NotificationsService sends notifications to family members through the
+// configured channels, and keeps the browser push subscriptions web push
+// delivers to
+type NotificationsService struct {
+	db       *database.Fascade
+	channels map[string]notify.Channel
+}
+
+// NewNotificationsService creates a new notifications service
+func NewNotificationsService(db *database.Fascade) *NotificationsService {
+	return &NotificationsService{db: db, channels: map[string]notify.Channel{}}
+}
+
+// SetChannels sets the delivery channels, keyed by name
+func (s *NotificationsService) SetChannels(channels map[string]notify.Channel) {
+	s.channels = channels
+}
+
+// Channels returns the names of the enabled channels
+func (s *NotificationsService) Channels() []string {
+	names := make([]string, 0, len(s.channels))
+	for name := range s.channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WebPushKey returns the VAPID public key browsers subscribe with, or "" when
+// web push is off
+func (s *NotificationsService) WebPushKey() string {
+	if push, ok := s.channels[notify.ChannelWebPush].(*notify.WebPush); ok {
+		return push.PublicKey()
+	}
+	return ""
+}
+
+// Send delivers a message to a member through the named channel and returns
+// the delivery status. An unreachable member is a status, not an error.
+func (s *NotificationsService) Send(ctx context.Context, channelName, memberID string, msg notify.Message) (string, error) {
+	channel, ok := s.channels[channelName]
+	if !ok {
+		return models.ReminderDeliveryFailed, fmt.Errorf("notification channel %s is not enabled", channelName)
+	}
+
+	recipient, err := s.recipient(memberID)
+	if err != nil {
+		return models.ReminderDeliveryFailed, err
+	}
+
+	result, err := channel.Send(ctx, *recipient, msg)
+	if result != nil {
+		for _, endpoint := range result.GoneEndpoints {
+			if _, err := s.db.Exec(`DELETE FROM push_subscriptions WHERE endpoint = ?`, endpoint); err != nil {
+				log.Printf("Failed to remove expired push subscription: %v", err)
+			}
+		}
+	}
+	if errors.Is(err, notify.ErrUnreachable) {
+		return models.ReminderDeliveryUnreachable, nil
+	}
+	if err != nil {
+		return models.ReminderDeliveryFailed, err
+	}
+	return models.ReminderDeliverySent, nil
+}
+
+// recipient loads a member's name, email and push subscriptions
+func (s *NotificationsService) recipient(memberID string) (*notify.Recipient, error) {
+	var firstName, lastName string
+	var email sql.NullString
+	err := s.db.QueryRow(`SELECT first_name, last_name, email FROM family_members WHERE id = ?`, memberID).Scan(&firstName, &lastName, &email)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("family member not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification recipient: %w", err)
+	}
+
+	recipient := &notify.Recipient{MemberID: memberID, Name: firstName + " " + lastName, Email: email.String}
+
+	rows, err := s.db.Query(`SELECT endpoint, p256dh, auth FROM push_subscriptions WHERE member_id = ?`, memberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query push subscriptions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var sub notify.PushSubscription
+		if err := rows.Scan(&sub.Endpoint, &sub.P256dh, &sub.Auth); err != nil {
+			return nil, fmt.Errorf("failed to scan push subscription: %w", err)
+		}
+		recipient.PushSubscriptions = append(recipient.PushSubscriptions, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating push subscriptions: %w", err)
+	}
+
+	return recipient, nil
+}
+
+// ListPushSubscriptions returns the browsers a member gets push notifications on
+func (s *NotificationsService) ListPushSubscriptions(memberID string) ([]models.PushSubscription, error) {
+	subscriptions, err := database.QueryAll[models.PushSubscription](s.db, `
+		SELECT endpoint, member_id, COALESCE(user_agent, '') AS user_agent, created_at
+		FROM push_subscriptions WHERE member_id = ? ORDER BY created_at DESC
+	`, memberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list push subscriptions: %w", err)
+	}
+	if subscriptions == nil {
+		subscriptions = []models.PushSubscription{}
+	}
+	return subscriptions, nil
+}
+
+// SavePushSubscription stores a browser's push subscription for a member. A
+// browser that subscribes again replaces its old keys.
+func (s *NotificationsService) SavePushSubscription(memberID, userAgent string, req *models.SavePushSubscriptionRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO push_subscriptions (endpoint, member_id, p256dh, auth, user_agent, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(endpoint) DO UPDATE SET
+			member_id = excluded.member_id, p256dh = excluded.p256dh, auth = excluded.auth,
+			user_agent = excluded.user_agent
+	`, req.Endpoint, memberID, req.Keys.P256dh, req.Keys.Auth, userAgent, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save push subscription: %w", err)
+	}
+	return nil
+}
+
+// DeletePushSubscription removes one of a member's push subscriptions
+func (s *NotificationsService) DeletePushSubscription(memberID, endpoint string) error {
+	result, err := s.db.Exec(`DELETE FROM push_subscriptions WHERE endpoint = ? AND member_id = ?`, endpoint, memberID)
+	if err != nil {
+		return fmt.Errorf("failed to delete push subscription: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("push subscription not found")
+	}
+	return nil
+}
+
+// digestLateWindow is how long after a member's digest time the digest may
+// still go out, covering a planner that was down at the time
+const digestLateWindow = 2 * time.Hour
+
+// GetPreferences returns a member's notification preferences, or the
+// defaults if they never saved any
+func (s *NotificationsService) GetPreferences(familyID, memberID string) (*models.NotificationPreferences, error) {
+	prefs, err := database.QueryOne[models.NotificationPreferences](s.db, `
+		SELECT member_id, family_id, daily_digest, digest_time, digest_channel, updated_at
+		FROM notification_preferences WHERE member_id = ? AND family_id = ?
+	`, memberID, familyID)
+	if err == sql.ErrNoRows {
+		return &models.NotificationPreferences{
+			MemberID:      memberID,
+			FamilyID:      familyID,
+			DigestTime:    models.DefaultDigestTime,
+			DigestChannel: notify.ChannelEmail,
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+// UpdatePreferences saves the fields of a member's preferences that the
+// request sets
+func (s *NotificationsService) UpdatePreferences(familyID, memberID string, req *models.UpdateNotificationPreferencesRequest) (*models.NotificationPreferences, error) {
+	if err := req.Validate(s.Channels()); err != nil {
+		return nil, err
+	}
+
+	prefs, err := s.GetPreferences(familyID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if req.DailyDigest != nil {
+		prefs.DailyDigest = *req.DailyDigest
+	}
+	if req.DigestTime != nil {
+		prefs.DigestTime = *req.DigestTime
+	}
+	if req.DigestChannel != nil {
+		prefs.DigestChannel = *req.DigestChannel
+	}
+	prefs.UpdatedAt = time.Now().UTC()
+
+	_, err = s.db.Exec(`
+		INSERT INTO notification_preferences (member_id, family_id, daily_digest, digest_time, digest_channel, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(member_id) DO UPDATE SET
+			daily_digest = excluded.daily_digest, digest_time = excluded.digest_time,
+			digest_channel = excluded.digest_channel, updated_at = excluded.updated_at
+	`, memberID, familyID, prefs.DailyDigest, prefs.DigestTime, prefs.DigestChannel, prefs.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+// DigestsDue returns the daily digests whose time has come in each member's
+// family timezone. A digest stays due for a while after its time, so the
+// caller must make sure each one goes out only once per day.
+func (s *NotificationsService) DigestsDue(now time.Time) ([]models.DueDigest, error) {
+	rows, err := s.db.Query(`
+		SELECT p.member_id, p.family_id, p.digest_time, p.digest_channel, f.timezone
+		FROM notification_preferences p
+		JOIN families f ON f.id = p.family_id
+		JOIN family_members m ON m.id = p.member_id
+		WHERE p.daily_digest = TRUE AND m.is_active = TRUE
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query digest preferences: %w", err)
+	}
+	defer rows.Close()
+
+	due := []models.DueDigest{}
+	for rows.Next() {
+		var digest models.DueDigest
+		var digestTime, timezone string
+		if err := rows.Scan(&digest.MemberID, &digest.FamilyID, &digestTime, &digest.Channel, &timezone); err != nil {
+			return nil, fmt.Errorf("failed to scan digest preference: %w", err)
+		}
+		loc, err := loadLocation(timezone)
+		if err != nil {
+			log.Printf("Skipping digest for member %s: invalid family timezone %s", digest.MemberID, timezone)
+			continue
+		}
+		clock, err := time.Parse("15:04", digestTime)
+		if err != nil {
+			continue
+		}
+
+		local := now.In(loc)
+		sendAt := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+		if local.Before(sendAt) || !local.Before(sendAt.Add(digestLateWindow)) {
+			continue
+		}
+		digest.Date = local.Format("2006-01-02")
+		due = append(due, digest)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating digest preferences: %w", err)
+	}
+	return due, nil
+}
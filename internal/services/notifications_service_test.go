@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+	"famstack/internal/notify"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationsService_DailyDigestPreferences(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewNotificationsService(db)
+	service.SetChannels(map[string]notify.Channel{notify.ChannelEmail: &recordingChannel{}})
+	familyID, memberID := seedBulkEventFamily(t, db)
+	_, err := db.Exec(`UPDATE families SET timezone = ? WHERE id = ?`, "America/Chicago", familyID)
+	require.NoError(t, err)
+
+	prefs, err := service.GetPreferences(familyID, memberID)
+	require.NoError(t, err)
+	assert.False(t, prefs.DailyDigest)
+	assert.Equal(t, models.DefaultDigestTime, prefs.DigestTime)
+
+	badTime, pushOnly := "7am", notify.ChannelWebPush
+	_, err = service.UpdatePreferences(familyID, memberID, &models.UpdateNotificationPreferencesRequest{DigestTime: &badTime})
+	assert.Error(t, err)
+	_, err = service.UpdatePreferences(familyID, memberID, &models.UpdateNotificationPreferencesRequest{DigestChannel: &pushOnly})
+	assert.Error(t, err, "web push is not enabled")
+
+	enabled, digestTime := true, "06:30"
+	prefs, err = service.UpdatePreferences(familyID, memberID, &models.UpdateNotificationPreferencesRequest{DailyDigest: &enabled, DigestTime: &digestTime})
+	require.NoError(t, err)
+	assert.True(t, prefs.DailyDigest)
+	assert.Equal(t, notify.ChannelEmail, prefs.DigestChannel)
+
+	// 06:30 in Chicago is 11:30 UTC during daylight saving time
+	due, err := service.DigestsDue(time.Date(2025, 10, 1, 11, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	due, err = service.DigestsDue(time.Date(2025, 10, 1, 11, 35, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, memberID, due[0].MemberID)
+	assert.Equal(t, "2025-10-01", due[0].Date)
+
+	due, err = service.DigestsDue(time.Date(2025, 10, 1, 14, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Empty(t, due, "too late to send today's digest")
+}
@@ -71,10 +71,15 @@ func (s *OAuthService) GetToken(userID string, provider string) (*OAuthToken, er
 // SaveToken stores OAuth token in database
 func (s *OAuthService) SaveToken(token *OAuthToken) error {
 	query := `
-		INSERT OR REPLACE INTO oauth_tokens
+		INSERT INTO oauth_tokens
 		(id, family_id, created_by, provider, access_token, refresh_token,
 		 token_type, expires_at, scope, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(created_by, provider) DO UPDATE SET
+			id = excluded.id, family_id = excluded.family_id, access_token = excluded.access_token,
+			refresh_token = excluded.refresh_token, token_type = excluded.token_type,
+			expires_at = excluded.expires_at, scope = excluded.scope,
+			created_at = excluded.created_at, updated_at = excluded.updated_at
 	`
 
 	_, err := s.db.Exec(query,
@@ -99,9 +104,12 @@ func (s *OAuthService) DeleteToken(userID string, provider string) error {
 // SaveState stores OAuth state temporarily
 func (s *OAuthService) SaveState(state *OAuthState) error {
 	query := `
-		INSERT OR REPLACE INTO oauth_states
+		INSERT INTO oauth_states
 		(state, created_by, provider, expires_at, created_at)
 		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(state) DO UPDATE SET
+			created_by = excluded.created_by, provider = excluded.provider,
+			expires_at = excluded.expires_at, created_at = excluded.created_at
 	`
 
 	_, err := s.db.Exec(query,
@@ -170,7 +178,7 @@ func (s *OAuthService) GetUsersWithTokens(provider string) ([]OAuthToken, error)
 		SELECT id, family_id, created_by, provider, access_token, refresh_token,
 		       token_type, expires_at, scope, created_at, updated_at
 		FROM oauth_tokens
-		WHERE provider = ? AND expires_at > datetime('now')
+		WHERE provider = ? AND expires_at > CURRENT_TIMESTAMP
 	`
 
 	rows, err := s.db.Query(query, provider)
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Page sizes for cursor-paginated lists
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// PageRequest asks for one page of a list: up to Limit items after Cursor,
+// which is the NextCursor of the page before. An empty Cursor starts at the
+// top of the list.
+type PageRequest struct {
+	Limit  int
+	Cursor string
+}
+
+// Page is one page of a list. NextCursor is empty on the last page. Total
+// counts the whole list at the time of the request, as a hint for showing
+// progress; it can drift while a client pages through a list being changed.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      int    `json:"total"`
+}
+
+// pageCursor is the sort key of the last item on a page. Lists sort on a
+// time and break ties by ID, so a cursor stays put when items are added or
+// removed around it.
+type pageCursor struct {
+	Time time.Time `json:"t"`
+	ID   string    `json:"id"`
+}
+
+// limit returns the page size asked for, within bounds
+func (p PageRequest) limit() int {
+	if p.Limit <= 0 {
+		return DefaultPageLimit
+	}
+	return min(p.Limit, MaxPageLimit)
+}
+
+// cursor decodes the request's cursor, returning nil for the first page
+func (p PageRequest) cursor() (*pageCursor, error) {
+	if p.Cursor == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(p.Cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var cursor pageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &cursor, nil
+}
+
+// encodeCursor makes the cursor for the page after an item with this sort key
+func encodeCursor(t time.Time, id string) string {
+	data, _ := json.Marshal(pageCursor{Time: t.UTC(), ID: id}) // nolint:errcheck
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// olderThan returns the SQL condition, and its arguments, for rows after the
+// cursor in a list sorted newest first on column and then id. A nil cursor
+// matches every row.
+func (c *pageCursor) olderThan(column string) (string, []any) {
+	if c == nil {
+		return "", nil
+	}
+	condition := fmt.Sprintf(" AND (%[1]s < ? OR (%[1]s = ? AND id < ?))", column)
+	return condition, []any{c.Time, c.Time, c.ID}
+}
+
+// newPage makes a page from items fetched with one row more than the limit,
+// so whether a next page exists is known without counting
+func newPage[T any](items []T, limit, total int, key func(T) (time.Time, string)) *Page[T] {
+	page := &Page[T]{Items: items, Total: total}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.NextCursor = encodeCursor(key(page.Items[limit-1]))
+	}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	return page
+}
+
+// pageAscending pages a list that is already in memory, sorted oldest first
+// on key. Used where items are made rather than read, like the occurrences
+// of recurring events.
+func pageAscending[T any](items []T, request PageRequest, key func(T) (time.Time, string)) (*Page[T], error) {
+	cursor, err := request.cursor()
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := slices.Clone(items)
+	slices.SortStableFunc(sorted, func(a, b T) int {
+		aTime, aID := key(a)
+		bTime, bID := key(b)
+		if c := aTime.Compare(bTime); c != 0 {
+			return c
+		}
+		return strings.Compare(aID, bID)
+	})
+
+	start := 0
+	if cursor != nil {
+		start = len(sorted)
+		for i, item := range sorted {
+			itemTime, itemID := key(item)
+			if itemTime.After(cursor.Time) || (itemTime.Equal(cursor.Time) && itemID > cursor.ID) {
+				start = i
+				break
+			}
+		}
+	}
+
+	limit := request.limit()
+	end := min(start+limit+1, len(sorted))
+	return newPage(sorted[start:end], limit, len(sorted), key), nil
+}
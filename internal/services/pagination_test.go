@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageAscending(t *testing.T) {
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	type item struct {
+		at time.Time
+		id string
+	}
+	// Two items share a start, so the ID has to break the tie
+	items := []item{{base.Add(time.Hour), "c"}, {base, "b"}, {base, "a"}, {base.Add(2 * time.Hour), "d"}}
+	key := func(i item) (time.Time, string) { return i.at, i.id }
+
+	first, err := pageAscending(items, PageRequest{Limit: 2}, key)
+	require.NoError(t, err)
+	assert.Equal(t, []item{{base, "a"}, {base, "b"}}, first.Items)
+	assert.Equal(t, 4, first.Total)
+	require.NotEmpty(t, first.NextCursor)
+
+	second, err := pageAscending(items, PageRequest{Limit: 2, Cursor: first.NextCursor}, key)
+	require.NoError(t, err)
+	assert.Equal(t, []item{{base.Add(time.Hour), "c"}, {base.Add(2 * time.Hour), "d"}}, second.Items)
+	assert.Empty(t, second.NextCursor, "the last page has no cursor")
+
+	_, err = pageAscending(items, PageRequest{Cursor: "e30"}, key)
+	assert.EqualError(t, err, "invalid cursor")
+}
+
+func TestPageRequestLimit(t *testing.T) {
+	assert.Equal(t, DefaultPageLimit, PageRequest{}.limit())
+	assert.Equal(t, MaxPageLimit, PageRequest{Limit: 10000}.limit())
+	assert.Equal(t, 7, PageRequest{Limit: 7}.limit())
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/ids"
+	"famstack/internal/models"
+)
+
+// ProtectedBlocksService manages family-wide "do not schedule" time blocks
+type ProtectedBlocksService struct {
+	db *database.Fascade
+}
+
+// NewProtectedBlocksService creates a new protected blocks service
+func NewProtectedBlocksService(db *database.Fascade) *ProtectedBlocksService {
+	return &ProtectedBlocksService{db: db}
+}
+
+const protectedBlockColumns = `id, family_id, title, days_of_week, start_time, end_time, color, active, created_by, created_at, updated_at`
+
+// ListBlocks returns the protected blocks for a family. When activeOnly is
+// set, disabled blocks are left out.
+func (s *ProtectedBlocksService) ListBlocks(familyID string, activeOnly bool) ([]models.ProtectedTimeBlock, error) {
+	query := `SELECT ` + protectedBlockColumns + ` FROM protected_time_blocks WHERE family_id = ?`
+	if activeOnly {
+		query += " AND active = true"
+	}
+	query += " ORDER BY start_time, title"
+
+	rows, err := s.db.Query(query, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query protected blocks: %w", err)
+	}
+	defer rows.Close()
+
+	blocks := []models.ProtectedTimeBlock{}
+	for rows.Next() {
+		block, err := scanProtectedBlock(rows)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, *block)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating protected blocks: %w", err)
+	}
+
+	return blocks, nil
+}
+
+// GetBlock returns a protected block by ID
+func (s *ProtectedBlocksService) GetBlock(blockID string) (*models.ProtectedTimeBlock, error) {
+	row := s.db.QueryRow(`SELECT `+protectedBlockColumns+` FROM protected_time_blocks WHERE id = ?`, blockID)
+	block, err := scanProtectedBlock(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("protected block not found")
+	}
+	return block, err
+}
+
+// CreateBlock adds a protected block to a family
+func (s *ProtectedBlocksService) CreateBlock(familyID, createdBy string, req *models.CreateProtectedTimeBlockRequest) (*models.ProtectedTimeBlock, error) {
+	days := normalizeWeekdays(req.DaysOfWeek)
+	if err := models.ValidateProtectedTimeBlock(req.Title, days, req.StartTime, req.EndTime, req.Color); err != nil {
+		return nil, err
+	}
+
+	daysJSON, err := json.Marshal(days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal days_of_week: %w", err)
+	}
+
+	color := req.Color
+	if color == "" {
+		color = "#9ca3af"
+	}
+
+	var createdByValue any
+	if createdBy != "" {
+		createdByValue = createdBy
+	}
+
+	blockID := ids.New("block")
+	now := time.Now().UTC()
+
+	_, err = s.db.Exec(`
+		INSERT INTO protected_time_blocks (id, family_id, title, days_of_week, start_time, end_time, color, active, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, true, ?, ?, ?)
+	`, blockID, familyID, strings.TrimSpace(req.Title), string(daysJSON), req.StartTime, req.EndTime, color, createdByValue, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create protected block: %w", err)
+	}
+
+	return s.GetBlock(blockID)
+}
+
+// UpdateBlock applies the provided fields to a protected block
+func (s *ProtectedBlocksService) UpdateBlock(blockID string, req *models.UpdateProtectedTimeBlockRequest) (*models.ProtectedTimeBlock, error) {
+	existing, err := s.GetBlock(blockID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the merged result so start/end stay consistent when only one changes
+	merged := *existing
+	if req.Title != nil {
+		merged.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.DaysOfWeek != nil {
+		merged.DaysOfWeek = normalizeWeekdays(*req.DaysOfWeek)
+	}
+	if req.StartTime != nil {
+		merged.StartTime = *req.StartTime
+	}
+	if req.EndTime != nil {
+		merged.EndTime = *req.EndTime
+	}
+	if req.Color != nil {
+		merged.Color = *req.Color
+	}
+	if req.Active != nil {
+		merged.Active = *req.Active
+	}
+
+	if err := models.ValidateProtectedTimeBlock(merged.Title, merged.DaysOfWeek, merged.StartTime, merged.EndTime, merged.Color); err != nil {
+		return nil, err
+	}
+
+	daysJSON, err := json.Marshal(merged.DaysOfWeek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal days_of_week: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE protected_time_blocks
+		SET title = ?, days_of_week = ?, start_time = ?, end_time = ?, color = ?, active = ?, updated_at = ?
+		WHERE id = ?
+	`, merged.Title, string(daysJSON), merged.StartTime, merged.EndTime, merged.Color, merged.Active, time.Now().UTC(), blockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update protected block: %w", err)
+	}
+
+	return s.GetBlock(blockID)
+}
+
+// DeleteBlock removes a protected block
+func (s *ProtectedBlocksService) DeleteBlock(blockID string) error {
+	result, err := s.db.Exec(`DELETE FROM protected_time_blocks WHERE id = ?`, blockID)
+	if err != nil {
+		return fmt.Errorf("failed to delete protected block: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("protected block not found")
+	}
+
+	return nil
+}
+
+// FindConflicts returns every active protected block occurrence that overlaps
+// the range [start, end). Blocks are evaluated in the family's timezone, and
+// times without an offset are read as family-local like event creation does.
+func (s *ProtectedBlocksService) FindConflicts(familyID string, start, end time.Time) ([]models.ProtectedBlockConflict, error) {
+	blocks, err := s.ListBlocks(familyID, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) == 0 || !end.After(start) {
+		return []models.ProtectedBlockConflict{}, nil
+	}
+
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for protected blocks: %w", err)
+	}
+	loc, err := loadLocation(familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid family timezone %s: %w", familyTimezone, err)
+	}
+
+	startUTC, err := ConvertToUTC(start, familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert start time to UTC: %w", err)
+	}
+	endUTC, err := ConvertToUTC(end, familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert end time to UTC: %w", err)
+	}
+
+	return ProtectedBlockConflicts(blocks, startUTC, endUTC, loc), nil
+}
+
+// ProtectedBlockConflicts is the pure overlap computation behind FindConflicts
+func ProtectedBlockConflicts(blocks []models.ProtectedTimeBlock, start, end time.Time, loc *time.Location) []models.ProtectedBlockConflict {
+	conflicts := []models.ProtectedBlockConflict{}
+
+	localStart := start.In(loc)
+	localEnd := end.In(loc)
+	firstDay := time.Date(localStart.Year(), localStart.Month(), localStart.Day(), 0, 0, 0, 0, loc)
+
+	for day := firstDay; day.Before(localEnd); day = day.AddDate(0, 0, 1) {
+		for i := range blocks {
+			block := &blocks[i]
+			if !block.Active || !block.OccursOn(day.Weekday()) {
+				continue
+			}
+
+			blockStart, blockEnd, err := block.WindowOn(day, loc)
+			if err != nil {
+				continue
+			}
+
+			if localStart.Before(blockEnd) && localEnd.After(blockStart) {
+				conflicts = append(conflicts, models.ProtectedBlockConflict{
+					BlockID: block.ID,
+					Title:   block.Title,
+					Start:   blockStart,
+					End:     blockEnd,
+				})
+			}
+		}
+	}
+
+	return conflicts
+}
+
+// normalizeWeekdays lowercases and trims weekday names
+func normalizeWeekdays(days []string) []string {
+	normalized := make([]string, 0, len(days))
+	for _, day := range days {
+		normalized = append(normalized, strings.ToLower(strings.TrimSpace(day)))
+	}
+	return normalized
+}
+
+// scanProtectedBlock scans a protected block row
+func scanProtectedBlock(scanner interface{ Scan(dest ...any) error }) (*models.ProtectedTimeBlock, error) {
+	var block models.ProtectedTimeBlock
+	var daysJSON string
+	var createdBy sql.NullString
+
+	err := scanner.Scan(
+		&block.ID, &block.FamilyID, &block.Title, &daysJSON, &block.StartTime, &block.EndTime,
+		&block.Color, &block.Active, &createdBy, &block.CreatedAt, &block.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan protected block: %w", err)
+	}
+
+	if createdBy.Valid {
+		block.CreatedBy = &createdBy.String
+	}
+
+	block.DaysOfWeek = []string{}
+	if daysJSON != "" {
+		if err := json.Unmarshal([]byte(daysJSON), &block.DaysOfWeek); err != nil {
+			return nil, fmt.Errorf("failed to parse days_of_week: %w", err)
+		}
+	}
+
+	return &block, nil
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtectedBlocksService_FindConflicts(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewProtectedBlocksService(db)
+
+	familyID := "fam_blocks"
+	_, err := db.Exec(`INSERT INTO families (id, name, timezone) VALUES (?, ?, ?)`, familyID, "Blocks Family", "America/New_York")
+	require.NoError(t, err)
+
+	dinner, err := service.CreateBlock(familyID, "", &models.CreateProtectedTimeBlockRequest{
+		Title:     "Family dinner",
+		StartTime: "18:00",
+		EndTime:   "19:00",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, dinner.DaysOfWeek)
+
+	_, err = service.CreateBlock(familyID, "", &models.CreateProtectedTimeBlockRequest{
+		Title:      "Sunday morning",
+		DaysOfWeek: []string{"Sunday"},
+		StartTime:  "08:00",
+		EndTime:    "12:00",
+	})
+	require.NoError(t, err)
+
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// Wednesday 18:30 local overlaps dinner only
+	start := time.Date(2025, 10, 1, 18, 30, 0, 0, ny)
+	conflicts, err := service.FindConflicts(familyID, start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, dinner.ID, conflicts[0].BlockID)
+
+	// Wednesday morning is free
+	start = time.Date(2025, 10, 1, 9, 0, 0, 0, ny)
+	conflicts, err = service.FindConflicts(familyID, start, start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	// Sunday 11:00 overlaps the Sunday block
+	start = time.Date(2025, 10, 5, 11, 0, 0, 0, ny)
+	conflicts, err = service.FindConflicts(familyID, start, start.Add(30*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "Sunday morning", conflicts[0].Title)
+
+	// Ending exactly when dinner starts is not a conflict
+	start = time.Date(2025, 10, 1, 17, 0, 0, 0, ny)
+	conflicts, err = service.FindConflicts(familyID, start, start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	// Disabled blocks no longer conflict
+	inactive := false
+	_, err = service.UpdateBlock(dinner.ID, &models.UpdateProtectedTimeBlockRequest{Active: &inactive})
+	require.NoError(t, err)
+	start = time.Date(2025, 10, 1, 18, 30, 0, 0, ny)
+	conflicts, err = service.FindConflicts(familyID, start, start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+}
+
+func TestProtectedBlocksService_ValidatesTimes(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewProtectedBlocksService(db)
+
+	_, err := db.Exec(`INSERT INTO families (id, name, timezone) VALUES (?, ?, ?)`, "fam_invalid", "Invalid", "UTC")
+	require.NoError(t, err)
+
+	_, err = service.CreateBlock("fam_invalid", "", &models.CreateProtectedTimeBlockRequest{
+		Title:      "Backwards",
+		DaysOfWeek: []string{"funday"},
+		StartTime:  "19:00",
+		EndTime:    "18:00",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "end_time")
+	assert.Contains(t, err.Error(), "days_of_week")
+}
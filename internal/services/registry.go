@@ -3,19 +3,55 @@ package services
 import (
 	"famstack/internal/database"
 	"famstack/internal/encryption"
+	"famstack/internal/eventbus"
 )
 
 // Registry provides centralized access to all services
 type Registry struct {
 	// Database services
-	Tasks         *TasksService
-	Families      *FamiliesService
-	FamilyMembers *FamilyMemberService
-	Calendar      *CalendarService
-	Schedules     *SchedulesService
-	OAuth         *OAuthService
-	Jobs          *JobsService
-	Integrations  *IntegrationsService
+	Tasks            *TasksService
+	Assignments      *AssignmentsService
+	Activities       *ActivitiesService
+	Carpools         *CarpoolService
+	Families         *FamiliesService
+	FamilyMembers    *FamilyMemberService
+	Calendar         *CalendarService
+	Schedules        *SchedulesService
+	ScheduleProfiles *ScheduleProfilesService
+	OAuth            *OAuthService
+	Jobs             *JobsService
+	Integrations     *IntegrationsService
+	ProtectedBlocks  *ProtectedBlocksService
+	EventColorRules  *EventColorRulesService
+	Display          *DisplayPreferencesService
+	Preferences      *MemberPreferencesService
+	Timeline         *TimelineService
+	Suggestions      *SuggestionsService
+	Dashboard        *DashboardService
+	Storage          *StorageService
+	Attachments      *AttachmentsService
+	Custody          *CustodyService
+	Digests          *DigestService
+	Analytics        *AnalyticsService
+	Insights         *InsightsService
+	Focus            *FocusService
+	Notifications    *NotificationsService
+	Reminders        *RemindersService
+	Rewards          *RewardsService
+	Search           *SearchService
+	Meals            *MealsService
+	Announcements    *AnnouncementsService
+	Import           *ImportService
+	Weather          *WeatherService
+	Guests           *GuestService
+	Features         *FeatureFlagsService
+
+	// Per-family settings shared by the services above
+	FamilySettings *FamilySettings
+
+	// Display read model, kept current from Events
+	Events             *eventbus.Bus
+	DisplayProjections *DisplayProjectionService
 
 	// Internal references
 	db            *database.Fascade
@@ -24,18 +60,77 @@ type Registry struct {
 
 // NewRegistry creates a new service registry with all services initialized
 func NewRegistry(db *database.Fascade, encryptionSvc *encryption.Service) *Registry {
+	tasks := NewTasksService(db)
+	activities := NewActivitiesService(db, tasks)
+	calendar := NewCalendarService(db)
+	schedules := NewSchedulesService(db)
+	profiles := NewScheduleProfilesService(db)
+	families := NewFamiliesService(db)
+	members := NewFamilyMemberService(db)
+	custody := NewCustodyService(db)
+	timeline := NewTimelineService(db, calendar, tasks, schedules, profiles, custody)
+	integrations := NewIntegrationsService(db, encryptionSvc)
+	storage := NewStorageService(db)
+
+	events := eventbus.New()
+	tasks.SetEventBus(events)
+	calendar.SetEventBus(events)
+	focus := NewFocusService(db)
+	focus.SetEventBus(events)
+	imports := NewImportService(db)
+	imports.SetEventBus(events)
+	notifications := NewNotificationsService(db)
+	display := NewDisplayPreferencesService(db, families, members)
+	rewards := NewRewardsService(db)
+	rewards.Subscribe(events)
+	projections := NewDisplayProjectionService(db, calendar, timeline)
+	projections.Subscribe(events)
+
 	return &Registry{
 		// Database services (using database facade)
-		Tasks:         NewTasksService(db),
-		Families:      NewFamiliesService(db),
-		FamilyMembers: NewFamilyMemberService(db),
-		Calendar:      NewCalendarService(db),
-		Schedules:     NewSchedulesService(db),
-		OAuth:         NewOAuthService(db),
-		Jobs:          NewJobsService(db),
+		Tasks:            tasks,
+		Assignments:      NewAssignmentsService(db, tasks),
+		Activities:       activities,
+		Carpools:         NewCarpoolService(db, tasks, activities),
+		Families:         families,
+		FamilyMembers:    members,
+		Calendar:         calendar,
+		Schedules:        schedules,
+		ScheduleProfiles: profiles,
+		OAuth:            NewOAuthService(db),
+		Jobs:             NewJobsService(db),
+		ProtectedBlocks:  NewProtectedBlocksService(db),
+		EventColorRules:  NewEventColorRulesService(db),
+		Display:          display,
+		Preferences:      NewMemberPreferencesService(db, members, display, notifications),
+		Timeline:         timeline,
+		Suggestions:      NewSuggestionsService(db, tasks, schedules),
+		Dashboard:        NewDashboardService(db, tasks, timeline, integrations),
+		Storage:          storage,
+		Attachments:      NewAttachmentsService(db, storage),
+		Custody:          custody,
+		Digests:          NewDigestService(db, families, timeline),
+		Analytics:        NewAnalyticsService(db),
+		Insights:         NewInsightsService(db),
+		Focus:            focus,
+		Notifications:    notifications,
+		Reminders:        NewRemindersService(db, calendar, notifications),
+		Rewards:          rewards,
+		Search:           NewSearchService(db),
+		Meals:            NewMealsService(db, calendar),
+		Announcements:    NewAnnouncementsService(db),
+		Import:           imports,
+		Weather:          NewWeatherService(db),
+		Guests:           NewGuestService(db, calendar, tasks),
+		Features:         NewFeatureFlagsService(db),
+
+		FamilySettings: FamilySettingsFor(db),
+
+		Events:             events,
+		DisplayProjections: projections,
 
 		// External services (using database facade)
-		Integrations: NewIntegrationsService(db, encryptionSvc),
+		Integrations: integrations,
 
 		// Keep references for legacy access
 		db:            db,
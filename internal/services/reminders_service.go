@@ -0,0 +1,386 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/ids"
+	"famstack/internal/models"
+	"famstack/internal/notify"
+	"famstack/internal/templates"
+)
+
+const eventReminderColumns = `id, family_id, event_id, minutes_before, channel, created_by, created_at`
+
+// RemindersService manages event reminders and delivers them when they come
+// due. The reminder jobs plan and fire them; see jobs.NewReminderPlannerHandler.
+type RemindersService struct {
+	db            *database.Fascade
+	calendar      *CalendarService
+	notifications *NotificationsService
+}
+
+// NewRemindersService creates a new reminders service
+func NewRemindersService(db *database.Fascade, calendar *CalendarService, notifications *NotificationsService) *RemindersService {
+	return &RemindersService{db: db, calendar: calendar, notifications: notifications}
+}
+
+// ListReminders returns the reminders on one of the family's events. For an
+// occurrence of a recurring event these are the series' reminders.
+func (s *RemindersService) ListReminders(familyID, eventID string) ([]models.EventReminder, error) {
+	reminderEventID, err := s.reminderEventID(familyID, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	reminders, err := database.QueryAll[models.EventReminder](s.db, `
+		SELECT `+eventReminderColumns+` FROM event_reminders
+		WHERE event_id = ? ORDER BY minutes_before DESC, channel
+	`, reminderEventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event reminders: %w", err)
+	}
+	if reminders == nil {
+		reminders = []models.EventReminder{}
+	}
+	return reminders, nil
+}
+
+// CreateReminder adds a reminder to one of the family's events. A reminder
+// added to an occurrence of a recurring event applies to the whole series.
+func (s *RemindersService) CreateReminder(familyID, createdBy string, req *models.CreateEventReminderRequest) (*models.EventReminder, error) {
+	if err := req.Validate(s.notifications.Channels()); err != nil {
+		return nil, err
+	}
+
+	reminderEventID, err := s.reminderEventID(familyID, req.EventID)
+	if err != nil {
+		return nil, err
+	}
+
+	var createdByValue any
+	if createdBy != "" {
+		createdByValue = createdBy
+	}
+
+	reminderID := ids.New("reminder")
+	_, err = s.db.Exec(`
+		INSERT INTO event_reminders (id, family_id, event_id, minutes_before, channel, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, reminderID, familyID, reminderEventID, req.MinutesBefore, req.Channel, createdByValue, time.Now().UTC())
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return nil, fmt.Errorf("event reminder already exists")
+		}
+		return nil, fmt.Errorf("failed to create event reminder: %w", err)
+	}
+
+	return s.getReminder(reminderID)
+}
+
+// DeleteReminder removes one of the family's reminders
+func (s *RemindersService) DeleteReminder(familyID, reminderID string) error {
+	result, err := s.db.Exec(`DELETE FROM event_reminders WHERE id = ? AND family_id = ?`, reminderID, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete event reminder: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("event reminder not found")
+	}
+	return nil
+}
+
+// PlanDue returns the reminders that fire before now+horizon for events that
+// haven't started yet. Reminders whose time already passed, because they were
+// added late or the planner was down, are due right away.
+func (s *RemindersService) PlanDue(now time.Time, horizon time.Duration) ([]models.DueReminder, error) {
+	rows, err := s.db.Query(`SELECT family_id, MAX(minutes_before) FROM event_reminders GROUP BY family_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reminder families: %w", err)
+	}
+	reach := make(map[string]int)
+	for rows.Next() {
+		var familyID string
+		var maxMinutes int
+		if err := rows.Scan(&familyID, &maxMinutes); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan reminder family: %w", err)
+		}
+		reach[familyID] = maxMinutes
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reminder families: %w", err)
+	}
+
+	due := []models.DueReminder{}
+	for familyID, maxMinutes := range reach {
+		familyDue, err := s.planFamily(familyID, now, now.Add(horizon), time.Duration(maxMinutes)*time.Minute)
+		if err != nil {
+			return nil, err
+		}
+		due = append(due, familyDue...)
+	}
+	return due, nil
+}
+
+func (s *RemindersService) planFamily(familyID string, now, until time.Time, reach time.Duration) ([]models.DueReminder, error) {
+	reminders, err := database.QueryAll[models.EventReminder](s.db, `SELECT `+eventReminderColumns+` FROM event_reminders WHERE family_id = ?`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event reminders: %w", err)
+	}
+	byEvent := make(map[string][]models.EventReminder)
+	for _, reminder := range reminders {
+		byEvent[reminder.EventID] = append(byEvent[reminder.EventID], reminder)
+	}
+
+	loc, err := s.familyLocation(familyID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.calendar.GetUnifiedCalendarEvents(familyID, now.In(loc), until.Add(reach).In(loc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events for reminders: %w", err)
+	}
+
+	due := []models.DueReminder{}
+	for _, event := range events {
+		if !event.StartTime.After(now) || event.Status == models.UnifiedEventStatusCancelled {
+			continue
+		}
+		for _, reminder := range s.remindersFor(byEvent, &event) {
+			fireAt := event.StartTime.Add(-time.Duration(reminder.MinutesBefore) * time.Minute)
+			if fireAt.After(until) {
+				continue
+			}
+			due = append(due, models.DueReminder{
+				ReminderID:      reminder.ID,
+				EventID:         event.ID,
+				OccurrenceStart: event.StartTime.UTC(),
+				FireAt:          fireAt.UTC(),
+			})
+		}
+	}
+	return due, nil
+}
+
+// remindersFor returns the reminders that apply to an event: its own, and
+// for an occurrence of a series, the series'
+func (s *RemindersService) remindersFor(byEvent map[string][]models.EventReminder, event *models.UnifiedCalendarEvent) []models.EventReminder {
+	reminders := byEvent[event.ID]
+	if event.RecurringEventID != nil && *event.RecurringEventID != event.ID {
+		reminders = append(reminders[:len(reminders):len(reminders)], byEvent[*event.RecurringEventID]...)
+	}
+	return reminders
+}
+
+// Deliver sends a reminder for the occurrence starting at occurrenceStart to
+// each attendee, or to the family's adults when the event has none. Members
+// already reached are skipped, so a retried delivery never sends twice. A
+// reminder whose event was deleted, moved or has started is dropped.
+func (s *RemindersService) Deliver(ctx context.Context, reminderID string, occurrenceStart, now time.Time) error {
+	reminder, err := s.getReminder(reminderID)
+	if err != nil {
+		if err.Error() == "event reminder not found" {
+			return nil
+		}
+		return err
+	}
+	if !occurrenceStart.After(now) {
+		return nil
+	}
+
+	event, err := s.findOccurrence(reminder, occurrenceStart)
+	if err != nil || event == nil {
+		return err
+	}
+
+	recipients, err := s.recipients(event)
+	if err != nil {
+		return err
+	}
+	delivered, err := s.deliveredTo(reminder.ID, occurrenceStart)
+	if err != nil {
+		return err
+	}
+
+	msg, err := s.reminderMessage(event)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, memberID := range recipients {
+		if delivered[memberID] {
+			continue
+		}
+		status, sendErr := s.notifications.Send(ctx, reminder.Channel, memberID, msg)
+		errorText := sql.NullString{}
+		if sendErr != nil {
+			errs = append(errs, fmt.Errorf("member %s: %w", memberID, sendErr))
+			errorText = sql.NullString{String: sendErr.Error(), Valid: true}
+		}
+		if _, err := s.db.Exec(`
+			INSERT INTO reminder_deliveries (reminder_id, occurrence_start, member_id, status, error, delivered_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT(reminder_id, occurrence_start, member_id) DO UPDATE SET
+				status = excluded.status, error = excluded.error, delivered_at = excluded.delivered_at
+		`, reminder.ID, occurrenceStart.UTC(), memberID, status, errorText, now.UTC()); err != nil {
+			return fmt.Errorf("failed to record reminder delivery: %w", err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// findOccurrence returns the reminder's event as it occurs at occurrenceStart,
+// or nil when it no longer does
+func (s *RemindersService) findOccurrence(reminder *models.EventReminder, occurrenceStart time.Time) (*models.UnifiedCalendarEvent, error) {
+	loc, err := s.familyLocation(reminder.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.calendar.GetUnifiedCalendarEvents(reminder.FamilyID, occurrenceStart.In(loc), occurrenceStart.Add(time.Minute).In(loc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event for reminder: %w", err)
+	}
+
+	byEvent := map[string][]models.EventReminder{reminder.EventID: {*reminder}}
+	for i := range events {
+		event := &events[i]
+		if event.StartTime.Equal(occurrenceStart) && event.Status != models.UnifiedEventStatusCancelled &&
+			len(s.remindersFor(byEvent, event)) > 0 {
+			return event, nil
+		}
+	}
+	return nil, nil
+}
+
+// recipients returns who a reminder for the event goes to
+func (s *RemindersService) recipients(event *models.UnifiedCalendarEvent) ([]string, error) {
+	memberIDs := make([]string, 0, len(event.Attendees))
+	for _, attendee := range event.Attendees {
+		if attendee.Response != "declined" {
+			memberIDs = append(memberIDs, attendee.ID)
+		}
+	}
+	if len(event.Attendees) > 0 {
+		return memberIDs, nil
+	}
+
+	rows, err := s.db.Query(`SELECT id FROM family_members WHERE family_id = ? AND member_type = 'adult' AND is_active = TRUE`, event.FamilyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reminder recipients: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var memberID string
+		if err := rows.Scan(&memberID); err != nil {
+			return nil, fmt.Errorf("failed to scan reminder recipient: %w", err)
+		}
+		memberIDs = append(memberIDs, memberID)
+	}
+	return memberIDs, rows.Err()
+}
+
+// deliveredTo returns the members a reminder occurrence no longer needs to
+// go to: those it reached, and those with no address on its channel
+func (s *RemindersService) deliveredTo(reminderID string, occurrenceStart time.Time) (map[string]bool, error) {
+	rows, err := s.db.Query(`
+		SELECT member_id FROM reminder_deliveries
+		WHERE reminder_id = ? AND occurrence_start = ? AND status != ?
+	`, reminderID, occurrenceStart.UTC(), models.ReminderDeliveryFailed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reminder deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	delivered := make(map[string]bool)
+	for rows.Next() {
+		var memberID string
+		if err := rows.Scan(&memberID); err != nil {
+			return nil, fmt.Errorf("failed to scan reminder delivery: %w", err)
+		}
+		delivered[memberID] = true
+	}
+	return delivered, rows.Err()
+}
+
+// reminderMessage words the reminder in the family's timezone and locale
+func (s *RemindersService) reminderMessage(event *models.UnifiedCalendarEvent) (notify.Message, error) {
+	loc, err := s.familyLocation(event.FamilyID)
+	if err != nil {
+		return notify.Message{}, err
+	}
+	locale, err := GetFamilyLocale(s.db, event.FamilyID)
+	if err != nil {
+		return notify.Message{}, err
+	}
+	printer := templates.NewPrinter(locale)
+
+	start := event.StartTime.In(loc)
+	body := printer.Sprintf("reminder.starts_at", printer.Clock(start))
+	if event.AllDay {
+		body = printer.Sprintf("reminder.all_day", printer.LongDate(start))
+	} else if start.YearDay() != time.Now().In(loc).YearDay() {
+		body = printer.Sprintf("reminder.starts_on", printer.Weekday(start), printer.Clock(start))
+	}
+	if event.Location != nil && *event.Location != "" {
+		body += " · " + *event.Location
+	}
+
+	return notify.Message{
+		Title: event.Title,
+		Body:  body,
+		URL:   "/",
+		Tag:   "reminder-" + event.ID,
+	}, nil
+}
+
+// reminderEventID checks the event belongs to the family and returns the ID
+// reminders for it are kept under: the series for a generated occurrence
+func (s *RemindersService) reminderEventID(familyID, eventID string) (string, error) {
+	event, err := s.calendar.GetUnifiedCalendarEvent(eventID)
+	if err != nil {
+		return "", err
+	}
+	if err := inFamily("unified calendar event", event.FamilyID, familyID); err != nil {
+		return "", err
+	}
+	if event.RecurrenceRule != nil && event.RecurringEventID != nil {
+		return *event.RecurringEventID, nil
+	}
+	return event.ID, nil
+}
+
+func (s *RemindersService) getReminder(reminderID string) (*models.EventReminder, error) {
+	reminder, err := database.QueryOne[models.EventReminder](s.db, `SELECT `+eventReminderColumns+` FROM event_reminders WHERE id = ?`, reminderID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("event reminder not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event reminder: %w", err)
+	}
+	return reminder, nil
+}
+
+func (s *RemindersService) familyLocation(familyID string) (*time.Location, error) {
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for reminders: %w", err)
+	}
+	loc, err := loadLocation(familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid family timezone %s: %w", familyTimezone, err)
+	}
+	return loc, nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+	"famstack/internal/notify"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingChannel struct {
+	sent []notify.Recipient
+}
+
+func (c *recordingChannel) Name() string { return notify.ChannelEmail }
+
+func (c *recordingChannel) Send(ctx context.Context, to notify.Recipient, msg notify.Message) (*notify.SendResult, error) {
+	c.sent = append(c.sent, to)
+	return &notify.SendResult{}, nil
+}
+
+func TestRemindersService_PlansAndDeliversOnce(t *testing.T) {
+	db := setupTestDB(t)
+	calendar := NewCalendarService(db)
+	notifications := NewNotificationsService(db)
+	channel := &recordingChannel{}
+	notifications.SetChannels(map[string]notify.Channel{notify.ChannelEmail: channel})
+	service := NewRemindersService(db, calendar, notifications)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	start := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	event, _, err := calendar.PutUnifiedCalendarEvent(context.Background(), familyID, memberID, &models.PutUnifiedCalendarEventRequest{
+		ID:        "dentist",
+		Title:     "Dentist",
+		StartTime: start,
+		EndTime:   start.Add(time.Hour),
+		Attendees: []string{memberID},
+	})
+	require.NoError(t, err)
+
+	_, err = service.CreateReminder(familyID, memberID, &models.CreateEventReminderRequest{EventID: event.ID, MinutesBefore: 15, Channel: "sms"})
+	assert.Error(t, err, "only enabled channels can be used")
+	reminder, err := service.CreateReminder(familyID, memberID, &models.CreateEventReminderRequest{EventID: event.ID, MinutesBefore: 15, Channel: notify.ChannelEmail})
+	require.NoError(t, err)
+	_, err = service.CreateReminder(familyID, memberID, &models.CreateEventReminderRequest{EventID: event.ID, MinutesBefore: 15, Channel: notify.ChannelEmail})
+	require.Error(t, err)
+	assert.Equal(t, "event reminder already exists", err.Error())
+
+	// Not due until fifteen minutes before the start
+	due, err := service.PlanDue(event.StartTime.Add(-30*time.Minute), 5*time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	now := event.StartTime.Add(-18 * time.Minute)
+	due, err = service.PlanDue(now, 5*time.Minute)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, reminder.ID, due[0].ReminderID)
+	assert.True(t, due[0].FireAt.Equal(event.StartTime.Add(-15*time.Minute)))
+
+	require.NoError(t, service.Deliver(context.Background(), reminder.ID, due[0].OccurrenceStart, now))
+	require.NoError(t, service.Deliver(context.Background(), reminder.ID, due[0].OccurrenceStart, now), "a retry sends nothing more")
+	require.Len(t, channel.sent, 1)
+	assert.Equal(t, memberID, channel.sent[0].MemberID)
+
+	// A reminder for an event that has started is dropped
+	require.NoError(t, service.Deliver(context.Background(), reminder.ID, event.StartTime, event.StartTime.Add(time.Minute)))
+	assert.Len(t, channel.sent, 1)
+
+	require.NoError(t, service.DeleteReminder(familyID, reminder.ID))
+	reminders, err := service.ListReminders(familyID, event.ID)
+	require.NoError(t, err)
+	assert.Empty(t, reminders)
+}
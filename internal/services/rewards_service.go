@@ -0,0 +1,408 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/eventbus"
+	"famstack/internal/ids"
+	"famstack/internal/models"
+)
+
+const (
+	pointsEntryColumns = `id, family_id, member_id, points, reason, task_id, redemption_id, note, created_by, created_at`
+	rewardColumns      = `id, family_id, title, description, cost, active, created_by, created_at, updated_at`
+	redemptionColumns  = `rr.id, rr.family_id, rr.reward_id, r.title AS reward_title, rr.member_id, rr.cost, rr.status,
+		rr.requested_at, rr.decided_by, rr.decided_at, rr.note`
+)
+
+// RewardsService keeps members' points, the rewards parents offer and the
+// redemptions that spend points on them. Points only change through ledger
+// rows, so every balance can be explained by its history.
+type RewardsService struct {
+	db *database.Fascade
+}
+
+// NewRewardsService creates a new rewards service
+func NewRewardsService(db *database.Fascade) *RewardsService {
+	return &RewardsService{db: db}
+}
+
+// Subscribe keeps points in step with task completion
+func (s *RewardsService) Subscribe(bus *eventbus.Bus) {
+	bus.Subscribe(func(event eventbus.Event) {
+		if err := s.SyncTaskPoints(event.SubjectID); err != nil {
+			log.Printf("Failed to update points for task %s: %v", event.SubjectID, err)
+		}
+	}, eventbus.TopicTaskStatus)
+}
+
+// SyncTaskPoints awards a completed scheduled task's points to its assignee,
+// or takes them back if the task was reopened. It can be called any number
+// of times; a task's ledger rows never add up to more than its points.
+func (s *RewardsService) SyncTaskPoints(taskID string) error {
+	return s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		var familyID, status string
+		var assignedTo sql.NullString
+		var points int
+		err := tx.QueryRow(`
+			SELECT t.family_id, t.status, t.assigned_to, COALESCE(ts.points, 0)
+			FROM tasks t
+			LEFT JOIN task_schedules ts ON ts.id = t.schedule_id
+			WHERE t.id = ?
+		`, taskID).Scan(&familyID, &status, &assignedTo, &points)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get task for points: %w", err)
+		}
+
+		// What the task has earned so far, and who holds it
+		var earned int
+		var earnedBy sql.NullString
+		err = tx.QueryRow(`
+			SELECT COALESCE(SUM(points), 0),
+				(SELECT member_id FROM points_ledger WHERE task_id = ? AND reason = ? ORDER BY created_at DESC LIMIT 1)
+			FROM points_ledger WHERE task_id = ?
+		`, taskID, models.PointsReasonTaskCompleted, taskID).Scan(&earned, &earnedBy)
+		if err != nil {
+			return fmt.Errorf("failed to get task points: %w", err)
+		}
+
+		now := time.Now().UTC()
+		switch {
+		case status == string(models.TaskStatusCompleted) && earned == 0 && points > 0 && assignedTo.Valid:
+			_, err = insertPointsEntry(tx, familyID, assignedTo.String, points, models.PointsReasonTaskCompleted, &taskID, nil, "", nil, now)
+		case status != string(models.TaskStatusCompleted) && earned > 0 && earnedBy.Valid:
+			_, err = insertPointsEntry(tx, familyID, earnedBy.String, -earned, models.PointsReasonTaskReopened, &taskID, nil, "", nil, now)
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// GetBalances returns every active member's points
+func (s *RewardsService) GetBalances(familyID string) ([]models.PointsBalance, error) {
+	balances, err := database.QueryAll[models.PointsBalance](s.db, pointsBalanceQuery+`
+		WHERE m.family_id = ? AND m.is_active = TRUE
+		ORDER BY m.display_order, m.first_name
+	`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get points balances: %w", err)
+	}
+	if balances == nil {
+		balances = []models.PointsBalance{}
+	}
+	for i := range balances {
+		balances[i].Available = balances[i].Balance - balances[i].Pending
+	}
+	return balances, nil
+}
+
+// GetBalance returns one member's points
+func (s *RewardsService) GetBalance(familyID, memberID string) (*models.PointsBalance, error) {
+	balance, err := database.QueryOne[models.PointsBalance](s.db, pointsBalanceQuery+`
+		WHERE m.family_id = ? AND m.id = ?
+	`, familyID, memberID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("family member not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get points balance: %w", err)
+	}
+	balance.Available = balance.Balance - balance.Pending
+	return balance, nil
+}
+
+const pointsBalanceQuery = `
+	SELECT m.id AS member_id, m.first_name || ' ' || m.last_name AS name,
+		COALESCE((SELECT SUM(l.points) FROM points_ledger l WHERE l.member_id = m.id), 0) AS balance,
+		COALESCE((SELECT SUM(rr.cost) FROM reward_redemptions rr WHERE rr.member_id = m.id AND rr.status = 'pending'), 0) AS pending
+	FROM family_members m`
+
+// ListHistory returns a member's most recent points changes, newest first
+func (s *RewardsService) ListHistory(familyID, memberID string, limit int) ([]models.PointsEntry, error) {
+	entries, err := database.QueryAll[models.PointsEntry](s.db, `
+		SELECT `+pointsEntryColumns+` FROM points_ledger
+		WHERE family_id = ? AND member_id = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`, familyID, memberID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list points history: %w", err)
+	}
+	if entries == nil {
+		entries = []models.PointsEntry{}
+	}
+	return entries, nil
+}
+
+// AdjustPoints adds points to a member, or takes them away, outside of tasks
+// and rewards; an allowance, for example
+func (s *RewardsService) AdjustPoints(familyID, createdBy string, req *models.PointsAdjustmentRequest) (*models.PointsEntry, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.GetBalance(familyID, req.MemberID); err != nil {
+		return nil, err
+	}
+
+	entryID, err := insertPointsEntry(s.db, familyID, req.MemberID, req.Points, models.PointsReasonAdjustment, nil, nil, req.Note, &createdBy, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return s.getPointsEntry(entryID)
+}
+
+func (s *RewardsService) getPointsEntry(entryID string) (*models.PointsEntry, error) {
+	entry, err := database.QueryOne[models.PointsEntry](s.db, `SELECT `+pointsEntryColumns+` FROM points_ledger WHERE id = ?`, entryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get points entry: %w", err)
+	}
+	return entry, nil
+}
+
+// ledgerWriter is satisfied by both the database facade and a transaction
+type ledgerWriter interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// insertPointsEntry appends a row to the points ledger and returns its ID
+func insertPointsEntry(db ledgerWriter, familyID, memberID string, points int, reason string, taskID, redemptionID *string, note string, createdBy *string, now time.Time) (string, error) {
+	entryID := ids.New("points")
+	_, err := db.Exec(`
+		INSERT INTO points_ledger (id, family_id, member_id, points, reason, task_id, redemption_id, note, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, entryID, familyID, memberID, points, reason, taskID, redemptionID, note, createdBy, now)
+	if err != nil {
+		return "", fmt.Errorf("failed to record points: %w", err)
+	}
+	return entryID, nil
+}
+
+// ListRewards returns the family's rewards, cheapest first. Archived rewards
+// are only included when asked for.
+func (s *RewardsService) ListRewards(familyID string, includeArchived bool) ([]models.Reward, error) {
+	query := `SELECT ` + rewardColumns + ` FROM rewards WHERE family_id = ?`
+	if !includeArchived {
+		query += ` AND active = TRUE`
+	}
+	rewards, err := database.QueryAll[models.Reward](s.db, query+` ORDER BY cost, title`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rewards: %w", err)
+	}
+	if rewards == nil {
+		rewards = []models.Reward{}
+	}
+	return rewards, nil
+}
+
+// GetReward returns one of the family's rewards
+func (s *RewardsService) GetReward(familyID, rewardID string) (*models.Reward, error) {
+	reward, err := database.QueryOne[models.Reward](s.db, `SELECT `+rewardColumns+` FROM rewards WHERE id = ? AND family_id = ?`, rewardID, familyID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("reward not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reward: %w", err)
+	}
+	return reward, nil
+}
+
+// CreateReward adds a reward members can spend points on
+func (s *RewardsService) CreateReward(familyID, createdBy string, req *models.CreateRewardRequest) (*models.Reward, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	rewardID := ids.New("reward")
+	now := time.Now().UTC()
+	_, err := s.db.Exec(`
+		INSERT INTO rewards (id, family_id, title, description, cost, active, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, TRUE, ?, ?, ?)
+	`, rewardID, familyID, req.Title, req.Description, req.Cost, createdBy, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reward: %w", err)
+	}
+	return s.GetReward(familyID, rewardID)
+}
+
+// UpdateReward changes a reward. A new cost applies to later redemptions;
+// pending ones keep the cost they were requested at.
+func (s *RewardsService) UpdateReward(familyID, rewardID string, req *models.UpdateRewardRequest) (*models.Reward, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	reward, err := s.GetReward(familyID, rewardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil {
+		reward.Title = *req.Title
+	}
+	if req.Description != nil {
+		reward.Description = *req.Description
+	}
+	if req.Cost != nil {
+		reward.Cost = *req.Cost
+	}
+	if req.Active != nil {
+		reward.Active = *req.Active
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE rewards SET title = ?, description = ?, cost = ?, active = ?, updated_at = ?
+		WHERE id = ? AND family_id = ?
+	`, reward.Title, reward.Description, reward.Cost, reward.Active, time.Now().UTC(), rewardID, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update reward: %w", err)
+	}
+	return s.GetReward(familyID, rewardID)
+}
+
+// ArchiveReward takes a reward off the list without losing the redemptions
+// that point at it
+func (s *RewardsService) ArchiveReward(familyID, rewardID string) error {
+	archived := false
+	_, err := s.UpdateReward(familyID, rewardID, &models.UpdateRewardRequest{Active: &archived})
+	return err
+}
+
+// RequestRedemption asks to spend a member's points on a reward. The cost is
+// held against the member's balance until a parent decides.
+func (s *RewardsService) RequestRedemption(familyID, memberID, rewardID string) (*models.Redemption, error) {
+	redemptionID := ids.New("redemption")
+
+	err := s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		var cost int
+		var active bool
+		err := tx.QueryRow(`SELECT cost, active FROM rewards WHERE id = ? AND family_id = ?`, rewardID, familyID).Scan(&cost, &active)
+		if err == sql.ErrNoRows || (err == nil && !active) {
+			return fmt.Errorf("reward not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get reward: %w", err)
+		}
+
+		var available int
+		err = tx.QueryRow(`
+			SELECT COALESCE((SELECT SUM(points) FROM points_ledger WHERE member_id = m.id), 0)
+				- COALESCE((SELECT SUM(cost) FROM reward_redemptions WHERE member_id = m.id AND status = 'pending'), 0)
+			FROM family_members m WHERE m.id = ? AND m.family_id = ?
+		`, memberID, familyID).Scan(&available)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("family member not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get points balance: %w", err)
+		}
+		if available < cost {
+			return fmt.Errorf("not enough points")
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO reward_redemptions (id, family_id, reward_id, member_id, cost, status, requested_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, redemptionID, familyID, rewardID, memberID, cost, models.RedemptionPending, time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("failed to request redemption: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetRedemption(familyID, redemptionID)
+}
+
+// ListRedemptions returns the family's redemptions, newest first, optionally
+// only those with the given status
+func (s *RewardsService) ListRedemptions(familyID, status string) ([]models.Redemption, error) {
+	query := `SELECT ` + redemptionColumns + ` FROM reward_redemptions rr JOIN rewards r ON r.id = rr.reward_id WHERE rr.family_id = ?`
+	args := []any{familyID}
+	if status != "" {
+		query += ` AND rr.status = ?`
+		args = append(args, status)
+	}
+	redemptions, err := database.QueryAll[models.Redemption](s.db, query+` ORDER BY rr.requested_at DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list redemptions: %w", err)
+	}
+	if redemptions == nil {
+		redemptions = []models.Redemption{}
+	}
+	return redemptions, nil
+}
+
+// GetRedemption returns one of the family's redemptions
+func (s *RewardsService) GetRedemption(familyID, redemptionID string) (*models.Redemption, error) {
+	redemption, err := database.QueryOne[models.Redemption](s.db, `
+		SELECT `+redemptionColumns+` FROM reward_redemptions rr JOIN rewards r ON r.id = rr.reward_id
+		WHERE rr.id = ? AND rr.family_id = ?
+	`, redemptionID, familyID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("redemption not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get redemption: %w", err)
+	}
+	return redemption, nil
+}
+
+// ApproveRedemption spends the held points on the reward
+func (s *RewardsService) ApproveRedemption(familyID, redemptionID, decidedBy string, req *models.DecideRedemptionRequest) (*models.Redemption, error) {
+	return s.decideRedemption(familyID, redemptionID, decidedBy, models.RedemptionApproved, req.Note)
+}
+
+// RejectRedemption releases the held points
+func (s *RewardsService) RejectRedemption(familyID, redemptionID, decidedBy string, req *models.DecideRedemptionRequest) (*models.Redemption, error) {
+	return s.decideRedemption(familyID, redemptionID, decidedBy, models.RedemptionRejected, req.Note)
+}
+
+func (s *RewardsService) decideRedemption(familyID, redemptionID, decidedBy, status, note string) (*models.Redemption, error) {
+	redemption, err := s.GetRedemption(familyID, redemptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		now := time.Now().UTC()
+		result, err := tx.Exec(`
+			UPDATE reward_redemptions SET status = ?, decided_by = ?, decided_at = ?, note = ?
+			WHERE id = ? AND status = ?
+		`, status, decidedBy, now, note, redemptionID, models.RedemptionPending)
+		if err != nil {
+			return fmt.Errorf("failed to update redemption: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("redemption already decided")
+		}
+
+		if status == models.RedemptionApproved {
+			_, err = insertPointsEntry(tx, familyID, redemption.MemberID, -redemption.Cost, models.PointsReasonRedemption,
+				nil, &redemption.ID, redemption.RewardTitle, &decidedBy, now)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetRedemption(familyID, redemptionID)
+}
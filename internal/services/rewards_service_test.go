@@ -0,0 +1,73 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/eventbus"
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewardsService_EarnAndRedeemPoints(t *testing.T) {
+	db := setupTestDB(t)
+	bus := eventbus.New()
+	tasks := NewTasksService(db)
+	tasks.SetEventBus(bus)
+	service := NewRewardsService(db)
+	service.Subscribe(bus)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	now := time.Now().UTC()
+	_, err := db.Exec(`INSERT INTO task_schedules (id, family_id, created_by, title, task_type, assigned_to, days_of_week, points, active) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		"sched_dishes", familyID, memberID, "Dishes", "chore", memberID, `["monday"]`, 30, true)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO tasks (id, family_id, assigned_to, title, task_type, status, created_by, schedule_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		"task_dishes", familyID, memberID, "Dishes", "chore", "pending", memberID, "sched_dishes", now, now)
+	require.NoError(t, err)
+
+	// Completing twice earns once; reopening takes the points back
+	completed, pending := models.TaskStatusCompleted, models.TaskStatusPending
+	for _, status := range []models.TaskStatus{completed, completed, pending, completed} {
+		_, err = tasks.UpdateTask(familyID, "task_dishes", &models.UpdateTaskRequest{Status: &status})
+		require.NoError(t, err)
+	}
+	balance, err := service.GetBalance(familyID, memberID)
+	require.NoError(t, err)
+	assert.Equal(t, 30, balance.Balance)
+
+	history, err := service.ListHistory(familyID, memberID, 10)
+	require.NoError(t, err)
+	assert.Len(t, history, 3)
+
+	reward, err := service.CreateReward(familyID, memberID, &models.CreateRewardRequest{Title: "Movie night pick", Cost: 25})
+	require.NoError(t, err)
+
+	redemption, err := service.RequestRedemption(familyID, memberID, reward.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.RedemptionPending, redemption.Status)
+
+	// The pending redemption holds the points
+	_, err = service.RequestRedemption(familyID, memberID, reward.ID)
+	require.Error(t, err)
+	assert.Equal(t, "not enough points", err.Error())
+	balance, err = service.GetBalance(familyID, memberID)
+	require.NoError(t, err)
+	assert.Equal(t, 5, balance.Available)
+
+	approved, err := service.ApproveRedemption(familyID, redemption.ID, memberID, &models.DecideRedemptionRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, models.RedemptionApproved, approved.Status)
+	_, err = service.RejectRedemption(familyID, redemption.ID, memberID, &models.DecideRedemptionRequest{})
+	require.Error(t, err)
+	assert.Equal(t, "redemption already decided", err.Error())
+
+	_, err = service.AdjustPoints(familyID, memberID, &models.PointsAdjustmentRequest{MemberID: memberID, Points: 10, Note: "Allowance"})
+	require.NoError(t, err)
+	balance, err = service.GetBalance(familyID, memberID)
+	require.NoError(t, err)
+	assert.Equal(t, 15, balance.Balance)
+	assert.Equal(t, 0, balance.Pending)
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/ids"
+	"famstack/internal/models"
+)
+
+// ScheduleProfilesService manages seasonal schedule profiles and their schedule membership
+type ScheduleProfilesService struct {
+	db *database.Fascade
+}
+
+// NewScheduleProfilesService creates a new schedule profiles service
+func NewScheduleProfilesService(db *database.Fascade) *ScheduleProfilesService {
+	return &ScheduleProfilesService{db: db}
+}
+
+const scheduleProfileColumns = `id, family_id, name, start_date, end_date, created_at, updated_at`
+
+// ListProfiles returns a family's profiles with their member schedule IDs
+func (s *ScheduleProfilesService) ListProfiles(familyID string) ([]models.ScheduleProfile, error) {
+	rows, err := s.db.Query(`
+		SELECT `+scheduleProfileColumns+`
+		FROM schedule_profiles
+		WHERE family_id = ?
+		ORDER BY start_date, name
+	`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedule profiles: %w", err)
+	}
+	defer rows.Close()
+
+	profiles := []models.ScheduleProfile{}
+	for rows.Next() {
+		profile, err := scanScheduleProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *profile)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedule profiles: %w", err)
+	}
+
+	members, err := s.familyMembership(familyID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range profiles {
+		profiles[i].ScheduleIDs = members[profiles[i].ID]
+		if profiles[i].ScheduleIDs == nil {
+			profiles[i].ScheduleIDs = []string{}
+		}
+	}
+
+	return profiles, nil
+}
+
+// GetProfile returns a schedule profile by ID
+func (s *ScheduleProfilesService) GetProfile(profileID string) (*models.ScheduleProfile, error) {
+	row := s.db.QueryRow(`SELECT `+scheduleProfileColumns+` FROM schedule_profiles WHERE id = ?`, profileID)
+	profile, err := scanScheduleProfile(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("schedule profile not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`SELECT schedule_id FROM schedule_profile_members WHERE profile_id = ? ORDER BY schedule_id`, profileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedule profile members: %w", err)
+	}
+	defer rows.Close()
+
+	profile.ScheduleIDs = []string{}
+	for rows.Next() {
+		var scheduleID string
+		if err := rows.Scan(&scheduleID); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule profile member: %w", err)
+		}
+		profile.ScheduleIDs = append(profile.ScheduleIDs, scheduleID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedule profile members: %w", err)
+	}
+
+	return profile, nil
+}
+
+// CreateProfile adds a schedule profile to a family
+func (s *ScheduleProfilesService) CreateProfile(familyID string, req *models.CreateScheduleProfileRequest) (*models.ScheduleProfile, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := models.ValidateScheduleProfile(name, req.StartDate, req.EndDate); err != nil {
+		return nil, err
+	}
+
+	profileID := ids.New("profile")
+	now := time.Now().UTC()
+
+	_, err := s.db.Exec(`
+		INSERT INTO schedule_profiles (id, family_id, name, start_date, end_date, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, profileID, familyID, name, req.StartDate, req.EndDate, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schedule profile: %w", err)
+	}
+
+	return s.GetProfile(profileID)
+}
+
+// UpdateProfile applies the provided fields to a schedule profile
+func (s *ScheduleProfilesService) UpdateProfile(profileID string, req *models.UpdateScheduleProfileRequest) (*models.ScheduleProfile, error) {
+	existing, err := s.GetProfile(profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := *existing
+	if req.Name != nil {
+		merged.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.StartDate != nil {
+		merged.StartDate = *req.StartDate
+	}
+	if req.EndDate != nil {
+		merged.EndDate = *req.EndDate
+	}
+
+	if err := models.ValidateScheduleProfile(merged.Name, merged.StartDate, merged.EndDate); err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE schedule_profiles
+		SET name = ?, start_date = ?, end_date = ?, updated_at = ?
+		WHERE id = ?
+	`, merged.Name, merged.StartDate, merged.EndDate, time.Now().UTC(), profileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update schedule profile: %w", err)
+	}
+
+	return s.GetProfile(profileID)
+}
+
+// DeleteProfile removes a schedule profile. Its schedules fall back to
+// running year-round unless they belong to another profile.
+func (s *ScheduleProfilesService) DeleteProfile(profileID string) error {
+	result, err := s.db.Exec(`DELETE FROM schedule_profiles WHERE id = ?`, profileID)
+	if err != nil {
+		return fmt.Errorf("failed to delete schedule profile: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("schedule profile not found")
+	}
+
+	return nil
+}
+
+// ListProfileIDsForSchedule returns the IDs of the profiles a schedule belongs to
+func (s *ScheduleProfilesService) ListProfileIDsForSchedule(scheduleID string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT profile_id FROM schedule_profile_members WHERE schedule_id = ? ORDER BY profile_id`, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedule profiles for schedule: %w", err)
+	}
+	defer rows.Close()
+
+	profileIDs := []string{}
+	for rows.Next() {
+		var profileID string
+		if err := rows.Scan(&profileID); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule profile id: %w", err)
+		}
+		profileIDs = append(profileIDs, profileID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedule profile ids: %w", err)
+	}
+
+	return profileIDs, nil
+}
+
+// SetScheduleProfiles replaces the profiles a schedule belongs to. Every
+// profile must belong to the same family as the schedule.
+func (s *ScheduleProfilesService) SetScheduleProfiles(familyID, scheduleID string, profileIDs []string) error {
+	return s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		for _, profileID := range profileIDs {
+			var profileFamilyID string
+			err := tx.QueryRow(`SELECT family_id FROM schedule_profiles WHERE id = ?`, profileID).Scan(&profileFamilyID)
+			if err == sql.ErrNoRows || (err == nil && profileFamilyID != familyID) {
+				return fmt.Errorf("schedule profile not found")
+			}
+			if err != nil {
+				return fmt.Errorf("failed to query schedule profile %s: %w", profileID, err)
+			}
+		}
+
+		if _, err := tx.Exec(`DELETE FROM schedule_profile_members WHERE schedule_id = ?`, scheduleID); err != nil {
+			return fmt.Errorf("failed to clear schedule profiles: %w", err)
+		}
+
+		for _, profileID := range profileIDs {
+			_, err := tx.Exec(`
+				INSERT INTO schedule_profile_members (profile_id, schedule_id)
+				VALUES (?, ?)
+				ON CONFLICT DO NOTHING
+			`, profileID, scheduleID)
+			if err != nil {
+				return fmt.Errorf("failed to add schedule to profile %s: %w", profileID, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// familyMembership maps profile ID to member schedule IDs for a family
+func (s *ScheduleProfilesService) familyMembership(familyID string) (map[string][]string, error) {
+	rows, err := s.db.Query(`
+		SELECT m.profile_id, m.schedule_id
+		FROM schedule_profile_members m
+		JOIN schedule_profiles p ON p.id = m.profile_id
+		WHERE p.family_id = ?
+		ORDER BY m.schedule_id
+	`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedule profile members: %w", err)
+	}
+	defer rows.Close()
+
+	members := make(map[string][]string)
+	for rows.Next() {
+		var profileID, scheduleID string
+		if err := rows.Scan(&profileID, &scheduleID); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule profile member: %w", err)
+		}
+		members[profileID] = append(members[profileID], scheduleID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedule profile members: %w", err)
+	}
+
+	return members, nil
+}
+
+// scanScheduleProfile scans a schedule profile row
+func scanScheduleProfile(scanner interface{ Scan(dest ...any) error }) (*models.ScheduleProfile, error) {
+	var profile models.ScheduleProfile
+
+	err := scanner.Scan(
+		&profile.ID, &profile.FamilyID, &profile.Name, &profile.StartDate, &profile.EndDate,
+		&profile.CreatedAt, &profile.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan schedule profile: %w", err)
+	}
+
+	return &profile, nil
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleProfilesService_ScheduleRunsOnActiveProfile(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewScheduleProfilesService(db)
+	schedules := NewSchedulesService(db)
+
+	familyID := "fam_profiles"
+	memberID := "member_profiles"
+	_, err := db.Exec(`INSERT INTO families (id, name, timezone) VALUES (?, ?, ?)`, familyID, "Profiles Family", "UTC")
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, is_active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		memberID, familyID, "Profile", "Parent", "adult", true, time.Now(), time.Now())
+	require.NoError(t, err)
+
+	packLunch, err := schedules.CreateSchedule(familyID, memberID, &models.CreateTaskScheduleRequest{
+		Title:      "Pack lunch",
+		TaskType:   "chore",
+		DaysOfWeek: []string{"monday", "tuesday", "wednesday", "thursday", "friday"},
+	})
+	require.NoError(t, err)
+	mowLawn, err := schedules.CreateSchedule(familyID, memberID, &models.CreateTaskScheduleRequest{
+		Title:      "Mow lawn",
+		TaskType:   "chore",
+		DaysOfWeek: []string{"saturday"},
+	})
+	require.NoError(t, err)
+	dishes, err := schedules.CreateSchedule(familyID, memberID, &models.CreateTaskScheduleRequest{
+		Title:      "Dishes",
+		TaskType:   "chore",
+		DaysOfWeek: []string{"sunday"},
+	})
+	require.NoError(t, err)
+
+	schoolYear, err := service.CreateProfile(familyID, &models.CreateScheduleProfileRequest{
+		Name:      "School year",
+		StartDate: "2025-09-02",
+		EndDate:   "2026-06-19",
+	})
+	require.NoError(t, err)
+	summer, err := service.CreateProfile(familyID, &models.CreateScheduleProfileRequest{
+		Name:      "Summer",
+		StartDate: "2026-06-20",
+		EndDate:   "2026-09-01",
+	})
+	require.NoError(t, err)
+	winterBreak, err := service.CreateProfile(familyID, &models.CreateScheduleProfileRequest{
+		Name:      "Winter break",
+		StartDate: "2025-12-20",
+		EndDate:   "2026-01-04",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, service.SetScheduleProfiles(familyID, packLunch.ID, []string{schoolYear.ID}))
+	require.NoError(t, service.SetScheduleProfiles(familyID, mowLawn.ID, []string{summer.ID, winterBreak.ID}))
+
+	profiles, err := service.ListProfiles(familyID)
+	require.NoError(t, err)
+	require.Len(t, profiles, 3)
+
+	october := time.Date(2025, 10, 15, 0, 0, 0, 0, time.UTC)
+	christmas := time.Date(2025, 12, 25, 0, 0, 0, 0, time.UTC)
+	july := time.Date(2026, 7, 10, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, schoolYear.ID, models.ActiveScheduleProfile(profiles, october).ID)
+	// The shorter override that starts later wins over the season around it
+	assert.Equal(t, winterBreak.ID, models.ActiveScheduleProfile(profiles, christmas).ID)
+
+	assert.True(t, models.ScheduleRunsOn(profiles, packLunch.ID, october))
+	assert.False(t, models.ScheduleRunsOn(profiles, packLunch.ID, christmas))
+	assert.False(t, models.ScheduleRunsOn(profiles, packLunch.ID, july))
+
+	assert.False(t, models.ScheduleRunsOn(profiles, mowLawn.ID, october))
+	assert.True(t, models.ScheduleRunsOn(profiles, mowLawn.ID, christmas))
+	assert.True(t, models.ScheduleRunsOn(profiles, mowLawn.ID, july))
+
+	// Schedules outside every profile run year-round
+	assert.True(t, models.ScheduleRunsOn(profiles, dishes.ID, october))
+	assert.True(t, models.ScheduleRunsOn(profiles, dishes.ID, july))
+
+	// Clearing membership returns the schedule to year-round
+	require.NoError(t, service.SetScheduleProfiles(familyID, packLunch.ID, []string{}))
+	profileIDs, err := service.ListProfileIDsForSchedule(packLunch.ID)
+	require.NoError(t, err)
+	assert.Empty(t, profileIDs)
+}
+
+func TestScheduleProfilesService_RejectsInvalidAndForeignProfiles(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewScheduleProfilesService(db)
+
+	_, err := db.Exec(`INSERT INTO families (id, name, timezone) VALUES (?, ?, ?)`, "fam_a", "Family A", "UTC")
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO families (id, name, timezone) VALUES (?, ?, ?)`, "fam_b", "Family B", "UTC")
+	require.NoError(t, err)
+
+	_, err = service.CreateProfile("fam_a", &models.CreateScheduleProfileRequest{
+		Name:      "Backwards",
+		StartDate: "2026-06-01",
+		EndDate:   "2026-05-01",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "end_date")
+
+	other, err := service.CreateProfile("fam_b", &models.CreateScheduleProfileRequest{
+		Name:      "Summer",
+		StartDate: "2026-06-01",
+		EndDate:   "2026-08-31",
+	})
+	require.NoError(t, err)
+
+	err = service.SetScheduleProfiles("fam_a", "schedule_any", []string{other.ID})
+	require.Error(t, err)
+	assert.Equal(t, "schedule profile not found", err.Error())
+}
@@ -1,12 +1,15 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"famstack/internal/database"
+	"famstack/internal/ids"
 	"famstack/internal/models"
 )
 
@@ -25,64 +28,34 @@ func (s *SchedulesService) GetSchedule(scheduleID string) (*models.TaskSchedule,
 	query := `
 		SELECT id, family_id, created_by, title, description, task_type, assigned_to,
 			   days_of_week, time_of_day, priority, points, active, created_at,
-			   last_generated_date
+			   last_generated_date, rotation_strategy, rotation_members, rotation_anchor,
+			   rotation_index, start_date, end_date, paused_until, cron_expr
 		FROM task_schedules
 		WHERE id = ?
 	`
 
-	var schedule models.TaskSchedule
-	var description, assignedTo, daysOfWeek, timeOfDay sql.NullString
-	var lastGeneratedDate sql.NullTime
-
-	err := s.db.QueryRow(query, scheduleID).Scan(
-		&schedule.ID, &schedule.FamilyID, &schedule.CreatedBy, &schedule.Title,
-		&description, &schedule.TaskType, &assignedTo, &daysOfWeek,
-		&schedule.TimeOfDay, &schedule.Priority, &schedule.Points,
-		&schedule.Active, &schedule.CreatedAt, &schedule.LastGeneratedDate,
-	)
-
+	schedule, err := database.QueryOne[models.TaskSchedule](s.db, query, scheduleID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("schedule not found")
+			return nil, notFound("schedule")
 		}
 		return nil, fmt.Errorf("failed to get schedule: %w", err)
 	}
 
-	// Handle nullable fields
-	if description.Valid {
-		schedule.Description = &description.String
-	}
-	if assignedTo.Valid {
-		schedule.AssignedTo = &assignedTo.String
-	}
-	if daysOfWeek.Valid {
-		schedule.DaysOfWeek = &daysOfWeek.String
-	}
-	if timeOfDay.Valid {
-		schedule.TimeOfDay = &timeOfDay.String
-	}
-	// Get family timezone for conversions
-	familyTimezone, err := GetFamilyTimezone(s.db, schedule.FamilyID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get family timezone for schedule conversion: %w", err)
-	}
-
-	if lastGeneratedDate.Valid {
-		// Convert LastGeneratedDate from UTC to family timezone
-		convertedLastGenerated, convErr := ConvertFromUTC(lastGeneratedDate.Time, familyTimezone)
-		if convErr != nil {
-			return nil, fmt.Errorf("failed to convert last generated date from UTC: %w", convErr)
-		}
-		schedule.LastGeneratedDate = &convertedLastGenerated
-	}
+	return s.toFamilyTime(schedule)
+}
 
-	// Convert CreatedAt from UTC to family timezone
-	schedule.CreatedAt, err = ConvertFromUTC(schedule.CreatedAt, familyTimezone)
+// GetFamilySchedule returns one of the family's task schedules; another
+// family's schedule is not found
+func (s *SchedulesService) GetFamilySchedule(familyID, scheduleID string) (*models.TaskSchedule, error) {
+	schedule, err := s.GetSchedule(scheduleID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert created at from UTC: %w", err)
+		return nil, err
 	}
-
-	return &schedule, nil
+	if err := inFamily("schedule", schedule.FamilyID, familyID); err != nil {
+		return nil, err
+	}
+	return schedule, nil
 }
 
 // ListSchedules returns all task schedules for a family
@@ -90,7 +63,8 @@ func (s *SchedulesService) ListSchedules(familyID string) ([]models.TaskSchedule
 	query := `
 		SELECT id, family_id, created_by, title, description, task_type, assigned_to,
 			   days_of_week, time_of_day, priority, points, active, created_at,
-			   last_generated_date
+			   last_generated_date, rotation_strategy, rotation_members, rotation_anchor,
+			   rotation_index, start_date, end_date, paused_until, cron_expr
 		FROM task_schedules
 		WHERE family_id = ?
 		ORDER BY created_at DESC
@@ -123,7 +97,8 @@ func (s *SchedulesService) ListActiveSchedules() ([]models.TaskSchedule, error)
 	query := `
 		SELECT id, family_id, created_by, title, description, task_type, assigned_to,
 			   days_of_week, time_of_day, priority, points, active, created_at,
-			   last_generated_date
+			   last_generated_date, rotation_strategy, rotation_members, rotation_anchor,
+			   rotation_index, start_date, end_date, paused_until, cron_expr
 		FROM task_schedules
 		WHERE active = true
 		ORDER BY created_at ASC
@@ -153,16 +128,46 @@ func (s *SchedulesService) ListActiveSchedules() ([]models.TaskSchedule, error)
 
 // CreateSchedule creates a new task schedule
 func (s *SchedulesService) CreateSchedule(familyID, createdBy string, req *models.CreateTaskScheduleRequest) (*models.TaskSchedule, error) {
+	if err := req.Normalize(); err != nil {
+		return nil, err
+	}
+
+	if err := models.ValidateScheduleRotation(req.RotationStrategy, req.RotationMembers, req.RotationAnchor); err != nil {
+		return nil, err
+	}
+	if err := s.requireRotationMembers(familyID, req.RotationMembers); err != nil {
+		return nil, err
+	}
+	startDate, endDate, pausedUntil := optionalDate(req.StartDate), optionalDate(req.EndDate), optionalDate(req.PausedUntil)
+	if err := models.ValidateScheduleWindow(startDate, endDate, pausedUntil); err != nil {
+		return nil, err
+	}
+	cronExpr := optionalCronExpr(req.CronExpr)
+	if cronExpr != nil {
+		schedule, err := models.ValidateScheduleCron(*cronExpr)
+		if err != nil {
+			return nil, err
+		}
+		timeOfDay := schedule.TimeOfDay()
+		req.TimeOfDay = &timeOfDay
+	}
+
 	scheduleID := generateScheduleID()
 	now := time.Now().UTC()
 
+	rotationMembers, rotationAnchor, err := rotationColumns(req.RotationStrategy, req.RotationMembers, req.RotationAnchor, now)
+	if err != nil {
+		return nil, err
+	}
+
 	// For now, map the request to the actual database schema
 	// This is a temporary fix until the request models are updated
 	query := `
 		INSERT INTO task_schedules (id, family_id, created_by, title, description, task_type,
 								   assigned_to, days_of_week, time_of_day, priority, points,
-								   active, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+								   active, created_at, rotation_strategy, rotation_members, rotation_anchor,
+								   start_date, end_date, paused_until, cron_expr)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	// Convert days_of_week array to JSON string for database storage
@@ -174,6 +179,8 @@ func (s *SchedulesService) CreateSchedule(familyID, createdBy string, req *model
 	_, err = s.db.Exec(query,
 		scheduleID, familyID, createdBy, req.Title, req.Description, req.TaskType,
 		req.AssignedTo, string(daysJSON), req.TimeOfDay, req.Priority, 0, true, now,
+		req.RotationStrategy, rotationMembers, rotationAnchor,
+		startDate, endDate, pausedUntil, cronExpr,
 	)
 
 	if err != nil {
@@ -183,14 +190,39 @@ func (s *SchedulesService) CreateSchedule(familyID, createdBy string, req *model
 	return s.GetSchedule(scheduleID)
 }
 
-// UpdateSchedule updates an existing task schedule
-func (s *SchedulesService) UpdateSchedule(scheduleID string, req *models.UpdateTaskScheduleRequest) (*models.TaskSchedule, error) {
+// UpdateSchedule updates one of the family's task schedules
+func (s *SchedulesService) UpdateSchedule(familyID, scheduleID string, req *models.UpdateTaskScheduleRequest) (*models.TaskSchedule, error) {
+	if err := req.Normalize(); err != nil {
+		return nil, err
+	}
+
+	// Another family's schedule is not found
+	current, err := s.GetFamilySchedule(familyID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Simplified update function that maps to actual database schema
 	// TODO: Update request models to match database schema
 
 	setParts := []string{}
 	args := []interface{}{}
 
+	if req.CronExpr != nil {
+		cronExpr := optionalCronExpr(req.CronExpr)
+		if cronExpr != nil {
+			// The expression's time wins over a time_of_day sent with it
+			schedule, err := models.ValidateScheduleCron(*cronExpr)
+			if err != nil {
+				return nil, err
+			}
+			timeOfDay := schedule.TimeOfDay()
+			req.TimeOfDay = &timeOfDay
+		}
+		setParts = append(setParts, "cron_expr = ?")
+		args = append(args, cronExpr)
+	}
+
 	if req.Title != nil {
 		setParts = append(setParts, "title = ?")
 		args = append(args, *req.Title)
@@ -228,18 +260,35 @@ func (s *SchedulesService) UpdateSchedule(scheduleID string, req *models.UpdateT
 		setParts = append(setParts, "active = ?")
 		args = append(args, *req.Active)
 	}
+	if req.RotationStrategy != nil || req.RotationMembers != nil || req.RotationAnchor != nil {
+		rotationParts, rotationArgs, err := s.rotationUpdate(scheduleID, req)
+		if err != nil {
+			return nil, err
+		}
+		setParts = append(setParts, rotationParts...)
+		args = append(args, rotationArgs...)
+	}
+	windowChanged := req.StartDate != nil || req.EndDate != nil || req.PausedUntil != nil
+	if windowChanged {
+		windowParts, windowArgs, err := s.windowUpdate(scheduleID, req)
+		if err != nil {
+			return nil, err
+		}
+		setParts = append(setParts, windowParts...)
+		args = append(args, windowArgs...)
+	}
 
 	if len(setParts) == 0 {
-		return s.GetSchedule(scheduleID) // No changes, return current
+		return current, nil // No changes, return current
 	}
 
-	// Add scheduleID to args for WHERE clause
-	args = append(args, scheduleID)
+	// Add scheduleID and familyID to args for WHERE clause
+	args = append(args, scheduleID, familyID)
 
 	query := fmt.Sprintf(`
 		UPDATE task_schedules
 		SET %s
-		WHERE id = ?
+		WHERE id = ? AND family_id = ?
 	`, joinStrings(setParts, ", "))
 
 	result, err := s.db.Exec(query, args...)
@@ -253,17 +302,26 @@ func (s *SchedulesService) UpdateSchedule(scheduleID string, req *models.UpdateT
 	}
 
 	if rowsAffected == 0 {
-		return nil, fmt.Errorf("schedule not found")
+		return nil, notFound("schedule")
 	}
 
-	return s.GetSchedule(scheduleID)
+	schedule, err := s.GetSchedule(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if windowChanged {
+		if err := s.removeTasksOutsideWindow(schedule); err != nil {
+			return nil, err
+		}
+	}
+	return schedule, nil
 }
 
-// DeleteSchedule deletes a task schedule
-func (s *SchedulesService) DeleteSchedule(scheduleID string) error {
-	query := `DELETE FROM task_schedules WHERE id = ?`
+// DeleteSchedule deletes one of the family's task schedules
+func (s *SchedulesService) DeleteSchedule(familyID, scheduleID string) error {
+	query := `DELETE FROM task_schedules WHERE id = ? AND family_id = ?`
 
-	result, err := s.db.Exec(query, scheduleID)
+	result, err := s.db.Exec(query, scheduleID, familyID)
 	if err != nil {
 		return fmt.Errorf("failed to delete schedule: %w", err)
 	}
@@ -274,18 +332,18 @@ func (s *SchedulesService) DeleteSchedule(scheduleID string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("schedule not found")
+		return notFound("schedule")
 	}
 
 	return nil
 }
 
-// UpdateNextRunTime updates the next run time for a schedule
+// UpdateNextRunTime updates the next run time for one of the family's schedules
 // Note: This field doesn't exist in current schema - using last_generated_date as workaround
-func (s *SchedulesService) UpdateNextRunTime(scheduleID string, nextRunAt time.Time) error {
-	query := `UPDATE task_schedules SET last_generated_date = ? WHERE id = ?`
+func (s *SchedulesService) UpdateNextRunTime(familyID, scheduleID string, nextRunAt time.Time) error {
+	query := `UPDATE task_schedules SET last_generated_date = ? WHERE id = ? AND family_id = ?`
 
-	result, err := s.db.Exec(query, nextRunAt, scheduleID)
+	result, err := s.db.Exec(query, nextRunAt, scheduleID, familyID)
 	if err != nil {
 		return fmt.Errorf("failed to update next run time: %w", err)
 	}
@@ -296,17 +354,17 @@ func (s *SchedulesService) UpdateNextRunTime(scheduleID string, nextRunAt time.T
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("schedule not found")
+		return notFound("schedule")
 	}
 
 	return nil
 }
 
-// ActivateSchedule activates a schedule
-func (s *SchedulesService) ActivateSchedule(scheduleID string) error {
-	query := `UPDATE task_schedules SET active = true WHERE id = ?`
+// ActivateSchedule activates one of the family's schedules
+func (s *SchedulesService) ActivateSchedule(familyID, scheduleID string) error {
+	query := `UPDATE task_schedules SET active = true WHERE id = ? AND family_id = ?`
 
-	result, err := s.db.Exec(query, scheduleID)
+	result, err := s.db.Exec(query, scheduleID, familyID)
 	if err != nil {
 		return fmt.Errorf("failed to activate schedule: %w", err)
 	}
@@ -317,17 +375,17 @@ func (s *SchedulesService) ActivateSchedule(scheduleID string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("schedule not found")
+		return notFound("schedule")
 	}
 
 	return nil
 }
 
-// DeactivateSchedule deactivates a schedule
-func (s *SchedulesService) DeactivateSchedule(scheduleID string) error {
-	query := `UPDATE task_schedules SET active = false WHERE id = ?`
+// DeactivateSchedule deactivates one of the family's schedules
+func (s *SchedulesService) DeactivateSchedule(familyID, scheduleID string) error {
+	query := `UPDATE task_schedules SET active = false WHERE id = ? AND family_id = ?`
 
-	result, err := s.db.Exec(query, scheduleID)
+	result, err := s.db.Exec(query, scheduleID, familyID)
 	if err != nil {
 		return fmt.Errorf("failed to deactivate schedule: %w", err)
 	}
@@ -338,7 +396,52 @@ func (s *SchedulesService) DeactivateSchedule(scheduleID string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("schedule not found")
+		return notFound("schedule")
+	}
+
+	return nil
+}
+
+// PreviewCron returns the next count times a cron expression would make a
+// task, in the family's timezone
+func (s *SchedulesService) PreviewCron(familyID, cronExpr string, count int) (*models.ScheduleOccurrencesResponse, error) {
+	cronExpr = strings.TrimSpace(cronExpr)
+	schedule, err := models.ValidateScheduleCron(cronExpr)
+	if err != nil {
+		return nil, err
+	}
+
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for cron preview: %w", err)
+	}
+	now, err := ConvertFromUTC(time.Now().UTC(), familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert now to family time: %w", err)
+	}
+
+	return &models.ScheduleOccurrencesResponse{
+		CronExpr:    cronExpr,
+		TimeOfDay:   schedule.TimeOfDay(),
+		Occurrences: schedule.Next(now, count),
+	}, nil
+}
+
+// SetRotationIndex records which member a per_task rotation of one of the
+// family's schedules hands its next task to
+func (s *SchedulesService) SetRotationIndex(familyID, scheduleID string, index int) error {
+	result, err := s.db.Exec(`UPDATE task_schedules SET rotation_index = ? WHERE id = ? AND family_id = ?`, index, scheduleID, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to update rotation index: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFound("schedule")
 	}
 
 	return nil
@@ -346,58 +449,217 @@ func (s *SchedulesService) DeactivateSchedule(scheduleID string) error {
 
 // Helper functions
 
-func (s *SchedulesService) scanTaskSchedule(scanner interface {
-	Scan(dest ...interface{}) error
-}) (*models.TaskSchedule, error) {
-	var schedule models.TaskSchedule
-	var description, assignedTo, daysOfWeek, timeOfDay sql.NullString
-	var lastGeneratedDate sql.NullTime
-
-	err := scanner.Scan(
-		&schedule.ID, &schedule.FamilyID, &schedule.CreatedBy, &schedule.Title,
-		&description, &schedule.TaskType, &assignedTo, &daysOfWeek,
-		&timeOfDay, &schedule.Priority, &schedule.Points, &schedule.Active,
-		&schedule.CreatedAt, &lastGeneratedDate,
-	)
+// rotationUpdate merges a request's rotation changes into the schedule's
+// current rotation and returns the columns to set. A new strategy or member
+// list starts again from the first member.
+func (s *SchedulesService) rotationUpdate(scheduleID string, req *models.UpdateTaskScheduleRequest) ([]string, []interface{}, error) {
+	current, err := s.GetSchedule(scheduleID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	strategy := current.RotationStrategy
+	if req.RotationStrategy != nil {
+		strategy = *req.RotationStrategy
+	}
+	var members []string
+	if req.RotationMembers != nil {
+		members = *req.RotationMembers
+	} else if current.RotationMembers != nil {
+		if err := json.Unmarshal([]byte(*current.RotationMembers), &members); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal rotation members: %w", err)
+		}
+	}
+	anchor := current.RotationAnchor
+	if req.RotationAnchor != nil {
+		anchor = req.RotationAnchor
+	}
+
+	if err := models.ValidateScheduleRotation(strategy, members, anchor); err != nil {
+		return nil, nil, err
+	}
+	if req.RotationMembers != nil {
+		if err := s.requireRotationMembers(current.FamilyID, members); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	membersJSON, anchorDate, err := rotationColumns(strategy, members, anchor, time.Now().UTC())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	setParts := []string{"rotation_strategy = ?", "rotation_members = ?", "rotation_anchor = ?"}
+	args := []interface{}{strategy, membersJSON, anchorDate}
+	if strategy != current.RotationStrategy || req.RotationMembers != nil {
+		setParts = append(setParts, "rotation_index = ?")
+		args = append(args, 0)
+	}
+	return setParts, args, nil
+}
+
+// windowUpdate merges a request's start, end and pause dates into the
+// schedule's current ones and returns the columns to set
+func (s *SchedulesService) windowUpdate(scheduleID string, req *models.UpdateTaskScheduleRequest) ([]string, []interface{}, error) {
+	current, err := s.GetSchedule(scheduleID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	startDate, endDate, pausedUntil := current.StartDate, current.EndDate, current.PausedUntil
+	if req.StartDate != nil {
+		startDate = optionalDate(req.StartDate)
+	}
+	if req.EndDate != nil {
+		endDate = optionalDate(req.EndDate)
+	}
+	if req.PausedUntil != nil {
+		pausedUntil = optionalDate(req.PausedUntil)
+	}
+
+	if err := models.ValidateScheduleWindow(startDate, endDate, pausedUntil); err != nil {
+		return nil, nil, err
+	}
+	return []string{"start_date = ?", "end_date = ?", "paused_until = ?"}, []interface{}{startDate, endDate, pausedUntil}, nil
+}
+
+// removeTasksOutsideWindow deletes the schedule's pending tasks from today on
+// that fall outside its window. Tasks are generated months ahead, so without
+// this a pause or an earlier end date would only take effect after them.
+func (s *SchedulesService) removeTasksOutsideWindow(schedule *models.TaskSchedule) error {
+	familyTimezone, err := GetFamilyTimezone(s.db, schedule.FamilyID)
+	if err != nil {
+		return fmt.Errorf("failed to get family timezone for schedule window: %w", err)
+	}
+	today, err := ConvertFromUTC(time.Now().UTC(), familyTimezone)
+	if err != nil {
+		return fmt.Errorf("failed to convert today to family time: %w", err)
+	}
+
+	// Due dates are stored in UTC, so today starts at the family's midnight
+	startOfToday, err := ConvertToUTC(time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC), familyTimezone)
+	if err != nil {
+		return fmt.Errorf("failed to convert today to UTC: %w", err)
+	}
+	rows, err := s.db.Query(`
+		SELECT id, due_date
+		FROM tasks
+		WHERE schedule_id = ? AND status = 'pending' AND due_date IS NOT NULL
+		AND due_date >= ?
+	`, schedule.ID, startOfToday.Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return fmt.Errorf("failed to list schedule tasks: %w", err)
 	}
+	defer rows.Close()
 
-	// Handle nullable fields
-	if description.Valid {
-		schedule.Description = &description.String
+	var outside []string
+	for rows.Next() {
+		var taskID string
+		var dueDate time.Time
+		if err := rows.Scan(&taskID, &dueDate); err != nil {
+			return fmt.Errorf("failed to scan schedule task: %w", err)
+		}
+		if dueDate, err = ConvertFromUTC(dueDate, familyTimezone); err != nil {
+			return err
+		}
+		if !schedule.InWindow(dueDate) {
+			outside = append(outside, taskID)
+		}
 	}
-	if assignedTo.Valid {
-		schedule.AssignedTo = &assignedTo.String
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating schedule tasks: %w", err)
 	}
-	if daysOfWeek.Valid {
-		schedule.DaysOfWeek = &daysOfWeek.String
+
+	for _, taskID := range outside {
+		if _, err := s.db.Exec(`DELETE FROM tasks WHERE id = ?`, taskID); err != nil {
+			return fmt.Errorf("failed to delete task outside schedule window: %w", err)
+		}
 	}
-	if timeOfDay.Valid {
-		schedule.TimeOfDay = &timeOfDay.String
+	return nil
+}
+
+// optionalCronExpr trims a cron expression, treating an empty one as unset
+func optionalCronExpr(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	return optionalString(strings.TrimSpace(*value))
+}
+
+// optionalDate treats an empty date as unset
+func optionalDate(value *string) *string {
+	if value == nil {
+		return nil
 	}
-	// Get family timezone for conversions
+	return optionalString(*value)
+}
+
+// requireRotationMembers checks that every rotation member belongs to the family
+func (s *SchedulesService) requireRotationMembers(familyID string, members []string) error {
+	for _, memberID := range members {
+		var count int
+		err := s.db.QueryRow(`SELECT COUNT(*) FROM family_members WHERE id = ? AND family_id = ?`, memberID, familyID).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("failed to check family member: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("family member not found")
+		}
+	}
+	return nil
+}
+
+// rotationColumns returns the stored rotation_members and rotation_anchor for
+// a rotation, anchoring it on today when no date was given. Schedules that
+// don't rotate store neither.
+func rotationColumns(strategy string, members []string, anchor *string, now time.Time) (*string, *string, error) {
+	if strategy == models.RotationNone {
+		return nil, nil, nil
+	}
+
+	membersJSON, err := json.Marshal(members)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal rotation members: %w", err)
+	}
+	stored := string(membersJSON)
+
+	if anchor == nil {
+		today := now.Format("2006-01-02")
+		anchor = &today
+	}
+	return &stored, anchor, nil
+}
+
+func (s *SchedulesService) scanTaskSchedule(rows *sql.Rows) (*models.TaskSchedule, error) {
+	var schedule models.TaskSchedule
+	if err := database.ScanStruct(rows, &schedule); err != nil {
+		return nil, err
+	}
+
+	return s.toFamilyTime(&schedule)
+}
+
+// toFamilyTime converts a schedule's stored UTC times to the family timezone
+func (s *SchedulesService) toFamilyTime(schedule *models.TaskSchedule) (*models.TaskSchedule, error) {
 	familyTimezone, err := GetFamilyTimezone(s.db, schedule.FamilyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get family timezone for schedule conversion: %w", err)
 	}
 
-	if lastGeneratedDate.Valid {
-		// Convert LastGeneratedDate from UTC to family timezone
-		convertedLastGenerated, convErr := ConvertFromUTC(lastGeneratedDate.Time, familyTimezone)
+	if schedule.LastGeneratedDate != nil {
+		convertedLastGenerated, convErr := ConvertFromUTC(*schedule.LastGeneratedDate, familyTimezone)
 		if convErr != nil {
 			return nil, fmt.Errorf("failed to convert last generated date from UTC: %w", convErr)
 		}
 		schedule.LastGeneratedDate = &convertedLastGenerated
 	}
 
-	// Convert CreatedAt from UTC to family timezone
 	schedule.CreatedAt, err = ConvertFromUTC(schedule.CreatedAt, familyTimezone)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert created at from UTC: %w", err)
 	}
 
-	return &schedule, nil
+	return schedule, nil
 }
 
 // UpdateLastGeneratedDate updates the last generated date for a schedule
@@ -419,11 +681,7 @@ func (s *SchedulesService) UpdateLastGeneratedDate(scheduleID string, endDate ti
 
 // DeleteScheduleWithTasks deletes a schedule and all its tasks in a transaction
 func (s *SchedulesService) DeleteScheduleWithTasks(scheduleID string) error {
-	return s.db.BeginCommit(func(tx *sql.Tx) error {
-		defer func() {
-			_ = tx.Rollback() // nolint:errcheck
-		}()
-
+	return s.db.InTx(context.Background(), func(tx *sql.Tx) error {
 		// First, delete all tasks associated with this schedule
 		deleteTasksQuery := `DELETE FROM tasks WHERE schedule_id = ?`
 		_, err := tx.Exec(deleteTasksQuery, scheduleID)
@@ -446,26 +704,29 @@ func (s *SchedulesService) DeleteScheduleWithTasks(scheduleID string) error {
 			return fmt.Errorf("schedule %s not found", scheduleID)
 		}
 
-		return tx.Commit()
+		return nil
 	})
 }
 
 // GetSchedulesNeedingGeneration returns schedules that need task generation
 func (s *SchedulesService) GetSchedulesNeedingGeneration() ([]models.TaskSchedule, error) {
+	today := time.Now().UTC()
 	query := `
 		SELECT id, family_id, created_by, title, description, task_type, assigned_to,
 			   days_of_week, time_of_day, priority, points, active, created_at,
-			   last_generated_date
+			   last_generated_date, rotation_strategy, rotation_members, rotation_anchor,
+			   rotation_index, start_date, end_date, paused_until, cron_expr
 		FROM task_schedules
 		WHERE active = true
+		AND (end_date IS NULL OR end_date >= ?)
 		AND (
 			last_generated_date IS NULL OR
-			last_generated_date < date('now', '+1 month')
+			last_generated_date < ?
 		)
 		ORDER BY created_at ASC
 	`
 
-	rows, err := s.db.Query(query)
+	rows, err := s.db.Query(query, today.Format("2006-01-02"), today.AddDate(0, 1, 0).Format("2006-01-02"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get schedules needing generation: %w", err)
 	}
@@ -488,5 +749,5 @@ func (s *SchedulesService) GetSchedulesNeedingGeneration() ([]models.TaskSchedul
 }
 
 func generateScheduleID() string {
-	return fmt.Sprintf("schedule_%d", time.Now().UTC().UnixNano())
+	return ids.New("schedule")
 }
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulesService_GetMatchesList(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewSchedulesService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	_, err := db.Exec(`INSERT INTO task_schedules (id, family_id, created_by, title, task_type, assigned_to, days_of_week, time_of_day, points, active, last_generated_date) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		"sched_bins", familyID, memberID, "Bins", "chore", memberID, `["tuesday"]`, "07:30", 2, true, "2026-03-02 00:00:00")
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO task_schedules (id, family_id, created_by, title, description, task_type, days_of_week, points, active) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		"sched_bare", familyID, memberID, "Bare", nil, "todo", `[]`, 0, true)
+	require.NoError(t, err)
+
+	listed, err := service.ListSchedules(familyID)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+
+	for _, want := range listed {
+		got, err := service.GetSchedule(want.ID)
+		require.NoError(t, err)
+		assert.Equal(t, want, *got, want.ID)
+	}
+
+	bins, err := service.GetSchedule("sched_bins")
+	require.NoError(t, err)
+	require.NotNil(t, bins.TimeOfDay)
+	assert.Equal(t, "07:30", *bins.TimeOfDay)
+	require.NotNil(t, bins.LastGeneratedDate)
+	assert.True(t, bins.LastGeneratedDate.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)))
+
+	bare, err := service.GetSchedule("sched_bare")
+	require.NoError(t, err)
+	assert.Nil(t, bare.Description)
+	assert.Nil(t, bare.TimeOfDay)
+	assert.Nil(t, bare.LastGeneratedDate)
+
+	_, err = service.GetSchedule("sched_missing")
+	assert.EqualError(t, err, "schedule not found")
+}
+
+func TestSchedulesService_Rotation(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewSchedulesService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+	now := time.Now().UTC()
+	_, err := db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, is_active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"member_kid", familyID, "Kid", "Bulk", "child", true, now, now)
+	require.NoError(t, err)
+
+	req := &models.CreateTaskScheduleRequest{
+		Title:            "Dishes",
+		TaskType:         models.TaskTypeChore,
+		DaysOfWeek:       []string{"monday", "thursday"},
+		RotationStrategy: "Weekly",
+		RotationMembers:  []string{memberID},
+	}
+	_, err = service.CreateSchedule(familyID, memberID, req)
+	assert.Error(t, err, "a rotation needs two members")
+
+	req.RotationMembers = []string{memberID, "member_elsewhere"}
+	_, err = service.CreateSchedule(familyID, memberID, req)
+	assert.EqualError(t, err, "family member not found")
+
+	req.RotationMembers = []string{"member_kid", memberID}
+	schedule, err := service.CreateSchedule(familyID, memberID, req)
+	require.NoError(t, err)
+	assert.Equal(t, models.RotationWeekly, schedule.RotationStrategy)
+	require.NotNil(t, schedule.RotationMembers)
+	assert.JSONEq(t, `["member_kid", "member_bulk"]`, *schedule.RotationMembers)
+	require.NotNil(t, schedule.RotationAnchor, "anchored on today")
+
+	require.NoError(t, service.SetRotationIndex(familyID, schedule.ID, 1))
+	perTask := models.RotationPerTask
+	schedule, err = service.UpdateSchedule(familyID, schedule.ID, &models.UpdateTaskScheduleRequest{RotationStrategy: &perTask})
+	require.NoError(t, err)
+	assert.Equal(t, models.RotationPerTask, schedule.RotationStrategy)
+	assert.Equal(t, 0, schedule.RotationIndex, "a new strategy starts from the first member")
+	assert.JSONEq(t, `["member_kid", "member_bulk"]`, *schedule.RotationMembers)
+
+	none := models.RotationNone
+	schedule, err = service.UpdateSchedule(familyID, schedule.ID, &models.UpdateTaskScheduleRequest{RotationStrategy: &none})
+	require.NoError(t, err)
+	assert.Nil(t, schedule.RotationMembers)
+	assert.Nil(t, schedule.RotationAnchor)
+}
+
+func TestSchedulesService_PauseRemovesPendingTasks(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewSchedulesService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	start, end := "2026-05-01", "2026-04-01"
+	_, err := service.CreateSchedule(familyID, memberID, &models.CreateTaskScheduleRequest{
+		Title: "Bins", TaskType: models.TaskTypeChore, DaysOfWeek: []string{"tuesday"}, StartDate: &start, EndDate: &end,
+	})
+	assert.Error(t, err, "end before start")
+
+	schedule, err := service.CreateSchedule(familyID, memberID, &models.CreateTaskScheduleRequest{
+		Title: "Bins", TaskType: models.TaskTypeChore, DaysOfWeek: []string{"tuesday"}, StartDate: &start,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, schedule.StartDate)
+	assert.Nil(t, schedule.PausedUntil)
+
+	today := time.Now().UTC()
+	for i, days := range []int{1, 8, 15} {
+		due := today.AddDate(0, 0, days).Format("2006-01-02") + " 07:00:00"
+		_, err = db.Exec(`INSERT INTO tasks (id, family_id, assigned_to, title, task_type, status, due_date, created_by, schedule_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			fmt.Sprintf("task_bins_%d", i), familyID, memberID, "Bins", "chore", "pending", due, memberID, schedule.ID, today, today)
+		require.NoError(t, err)
+	}
+
+	pausedUntil := today.AddDate(0, 0, 10).Format("2006-01-02")
+	schedule, err = service.UpdateSchedule(familyID, schedule.ID, &models.UpdateTaskScheduleRequest{PausedUntil: &pausedUntil})
+	require.NoError(t, err)
+	require.NotNil(t, schedule.PausedUntil)
+	assert.Equal(t, pausedUntil, *schedule.PausedUntil)
+
+	var remaining int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE schedule_id = ?`, schedule.ID).Scan(&remaining))
+	assert.Equal(t, 1, remaining, "tasks during the pause are removed")
+
+	cleared := ""
+	schedule, err = service.UpdateSchedule(familyID, schedule.ID, &models.UpdateTaskScheduleRequest{PausedUntil: &cleared})
+	require.NoError(t, err)
+	assert.Nil(t, schedule.PausedUntil)
+}
+
+func TestSchedulesService_CronSchedules(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewSchedulesService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	bad := "*/15 9 * * *"
+	_, err := service.CreateSchedule(familyID, memberID, &models.CreateTaskScheduleRequest{
+		Title: "Mow", TaskType: models.TaskTypeChore, CronExpr: &bad,
+	})
+	assert.Error(t, err, "more than once a day")
+
+	firstSaturday := " 30 9 1-7 * SAT "
+	schedule, err := service.CreateSchedule(familyID, memberID, &models.CreateTaskScheduleRequest{
+		Title: "Mow", TaskType: models.TaskTypeChore, CronExpr: &firstSaturday,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, schedule.CronExpr)
+	assert.Equal(t, "30 9 1-7 * SAT", *schedule.CronExpr)
+	require.NotNil(t, schedule.TimeOfDay)
+	assert.Equal(t, "09:30", *schedule.TimeOfDay)
+
+	preview, err := service.PreviewCron(familyID, *schedule.CronExpr, 3)
+	require.NoError(t, err)
+	require.Len(t, preview.Occurrences, 3)
+	for _, occurrence := range preview.Occurrences {
+		assert.Equal(t, time.Saturday, occurrence.Weekday())
+		assert.LessOrEqual(t, occurrence.Day(), 7)
+	}
+
+	cleared, days := "", []string{"saturday"}
+	schedule, err = service.UpdateSchedule(familyID, schedule.ID, &models.UpdateTaskScheduleRequest{CronExpr: &cleared, DaysOfWeek: &days})
+	require.NoError(t, err)
+	assert.Nil(t, schedule.CronExpr)
+}
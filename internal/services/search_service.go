@@ -0,0 +1,111 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"famstack/internal/database"
+	"famstack/internal/models"
+)
+
+// SearchService searches a family's tasks, calendar events and schedules
+type SearchService struct {
+	db *database.Fascade
+}
+
+// NewSearchService creates a new search service
+func NewSearchService(db *database.Fascade) *SearchService {
+	return &SearchService{db: db}
+}
+
+// Search returns the family's best matches for query among the given kinds,
+// most relevant first. Each word in query must appear, as a whole word or the
+// start of one.
+func (s *SearchService) Search(familyID, query string, kinds []string, limit int) ([]models.SearchResult, error) {
+	match := ftsQuery(query)
+	statement := searchQuery
+	if s.db.Dialect() == database.DialectPostgres {
+		match, statement = tsQuery(query), searchQueryPostgres
+	}
+	if match == "" || len(kinds) == 0 {
+		return []models.SearchResult{}, nil
+	}
+	if limit < 1 || limit > models.MaxSearchResults {
+		limit = models.MaxSearchResults
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(kinds)), ", ")
+	args := []any{match, familyID}
+	for _, kind := range kinds {
+		args = append(args, kind)
+	}
+	args = append(args, limit)
+
+	results, err := database.QueryAll[models.SearchResult](s.db, strings.Replace(statement, "{kinds}", placeholders, 1), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	if results == nil {
+		results = []models.SearchResult{}
+	}
+	return results, nil
+}
+
+// searchQuery searches the FTS5 index. {kinds} is replaced with a
+// placeholder per kind searched.
+const searchQuery = `
+	SELECT kind, ref_id, title, snippet(search_index, -1, '', '', '…', 12) AS snippet
+	FROM search_index
+	WHERE search_index MATCH ? AND family_id = ? AND kind IN ({kinds})
+	ORDER BY rank
+	LIMIT ?
+`
+
+// searchQueryPostgres is searchQuery for PostgreSQL's text search, where
+// search_index holds a weighted tsvector of each row
+const searchQueryPostgres = `
+	WITH q AS (SELECT to_tsquery('english', ?) AS query)
+	SELECT kind, ref_id, title,
+	       ts_headline('english', title || ' ' || body, q.query,
+	                   'StartSel="", StopSel="", MaxWords=12, MinWords=4, MaxFragments=1, FragmentDelimiter=…') AS snippet
+	FROM search_index, q
+	WHERE document @@ q.query AND family_id = ? AND kind IN ({kinds})
+	ORDER BY ts_rank(document, q.query) DESC
+	LIMIT ?
+`
+
+// ftsQuery turns what someone typed into an FTS5 query that matches rows
+// containing every word as a prefix. Words are quoted so punctuation and FTS5
+// operators in the input are searched for rather than interpreted.
+func ftsQuery(query string) string {
+	var terms []string
+	for _, word := range strings.Fields(query) {
+		word = strings.ReplaceAll(word, `"`, "")
+		if word == "" {
+			continue
+		}
+		terms = append(terms, `"`+word+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// tsQuery is ftsQuery for PostgreSQL: every word, as a prefix, must match.
+// Anything but letters and digits is dropped, since to_tsquery would read
+// it as an operator.
+func tsQuery(query string) string {
+	var terms []string
+	for _, word := range strings.Fields(query) {
+		word = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, word)
+		if word == "" {
+			continue
+		}
+		terms = append(terms, word+":*")
+	}
+	return strings.Join(terms, " & ")
+}
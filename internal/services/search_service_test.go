@@ -0,0 +1,84 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchService_FindsTasksEventsAndSchedules(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewSearchService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+	allKinds := []string{models.SearchKindTask, models.SearchKindEvent, models.SearchKindSchedule}
+
+	now := time.Now().UTC()
+	_, err := db.Exec(`INSERT INTO tasks (id, family_id, assigned_to, title, description, task_type, status, created_by, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		"task_piano", familyID, memberID, "Practice piano", "Scales and the recital piece", "todo", "pending", memberID, now, now)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO unified_calendar_events (id, family_id, title, start_time, end_time, location, created_by) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		"event_recital", familyID, "Spring recital", now, now.Add(time.Hour), "Community hall", memberID)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO task_schedules (id, family_id, created_by, title, description, task_type, days_of_week, active) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"sched_trash", familyID, memberID, "Take out trash", "Recycling goes out too", "chore", `["tuesday"]`, true)
+	require.NoError(t, err)
+
+	// Another family's rows are never returned
+	_, err = db.Exec(`INSERT INTO families (id, name, timezone) VALUES (?, ?, ?)`, "fam_other", "Other", "UTC")
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO tasks (id, family_id, title, task_type, status, created_by, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"task_other", "fam_other", "Recital tickets", "todo", "pending", memberID, now, now)
+	require.NoError(t, err)
+
+	results, err := service.Search(familyID, "recit", allKinds, 10)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	ids := []string{results[0].ID, results[1].ID}
+	assert.ElementsMatch(t, []string{"task_piano", "event_recital"}, ids)
+
+	results, err = service.Search(familyID, "recital hall", allKinds, 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1, "every word must match")
+	assert.Equal(t, models.SearchKindEvent, results[0].Kind)
+
+	results, err = service.Search(familyID, "recital", []string{models.SearchKindTask}, 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "task_piano", results[0].ID)
+	assert.Contains(t, results[0].Snippet, "recital")
+
+	results, err = service.Search(familyID, "recital OR trash", allKinds, 10)
+	require.NoError(t, err)
+	assert.Empty(t, results, "operators in the query are searched for, not interpreted")
+
+	// The index follows edits and deletes
+	_, err = db.Exec(`UPDATE task_schedules SET title = ? WHERE id = ?`, "Bins night", "sched_trash")
+	require.NoError(t, err)
+	results, err = service.Search(familyID, "bins", allKinds, 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "sched_trash", results[0].ID)
+
+	_, err = db.Exec(`DELETE FROM unified_calendar_events WHERE id = ?`, "event_recital")
+	require.NoError(t, err)
+	results, err = service.Search(familyID, "recital", allKinds, 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "task_piano", results[0].ID)
+}
+
+func TestFtsQuery(t *testing.T) {
+	assert.Equal(t, `"piano"* "les"*`, ftsQuery("  piano  les "))
+	assert.Equal(t, `"OR"* "a*"*`, ftsQuery(`OR "a*"`))
+	assert.Equal(t, "", ftsQuery(` " `))
+}
+
+func TestTsQuery(t *testing.T) {
+	assert.Equal(t, "piano:* & les:*", tsQuery("  piano  les "))
+	assert.Equal(t, "a:* & café:*", tsQuery(`!a* "café"`))
+	assert.Equal(t, "", tsQuery(` & | `))
+}
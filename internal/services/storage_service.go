@@ -0,0 +1,365 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/ids"
+	"famstack/internal/models"
+	"famstack/internal/validation"
+)
+
+// StorageService tracks stored files per family and member and enforces the
+// family's quota. Upload paths record each file through RecordObject, which
+// refuses files that would go over a hard cap.
+type StorageService struct {
+	db *database.Fascade
+}
+
+// NewStorageService creates a new storage service
+func NewStorageService(db *database.Fascade) *StorageService {
+	return &StorageService{db: db}
+}
+
+// GetQuota returns a family's storage limits, or the defaults when none are set
+func (s *StorageService) GetQuota(familyID string) (*models.StorageQuota, error) {
+	quota := models.StorageQuota{FamilyID: familyID}
+	var memberLimit sql.NullInt64
+
+	err := s.db.QueryRow(`SELECT hard_limit_bytes, member_limit_bytes FROM storage_quotas WHERE family_id = ?`, familyID).
+		Scan(&quota.HardLimitBytes, &memberLimit)
+	if err == sql.ErrNoRows {
+		quota.HardLimitBytes = models.DefaultStorageHardLimitBytes
+		return &quota, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage quota: %w", err)
+	}
+
+	if memberLimit.Valid {
+		quota.MemberLimitBytes = &memberLimit.Int64
+	}
+	return &quota, nil
+}
+
+// UpdateQuota changes a family's storage limits
+func (s *StorageService) UpdateQuota(familyID string, req *models.UpdateStorageQuotaRequest) (*models.StorageQuota, error) {
+	quota, err := s.GetQuota(familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.HardLimitBytes != nil {
+		quota.HardLimitBytes = *req.HardLimitBytes
+	}
+	if req.MemberLimitBytes != nil {
+		quota.MemberLimitBytes = req.MemberLimitBytes
+		if *req.MemberLimitBytes == 0 {
+			quota.MemberLimitBytes = nil
+		}
+	}
+
+	validator := validation.NewValidator()
+	if quota.HardLimitBytes <= 0 {
+		validator.AddError("hard_limit_bytes", "hard_limit_bytes must be positive")
+	}
+	if quota.MemberLimitBytes != nil && (*quota.MemberLimitBytes < 0 || *quota.MemberLimitBytes > quota.HardLimitBytes) {
+		validator.AddError("member_limit_bytes", "member_limit_bytes must be between 0 and hard_limit_bytes")
+	}
+	if err := validator.ToError(); err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO storage_quotas (family_id, hard_limit_bytes, member_limit_bytes, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(family_id) DO UPDATE SET
+			hard_limit_bytes = excluded.hard_limit_bytes,
+			member_limit_bytes = excluded.member_limit_bytes,
+			updated_at = excluded.updated_at
+	`, familyID, quota.HardLimitBytes, quota.MemberLimitBytes, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to update storage quota: %w", err)
+	}
+
+	return s.GetQuota(familyID)
+}
+
+// GetUsage reports the family's storage by kind and by member, with a warning
+// for each threshold the family or a member has passed
+func (s *StorageService) GetUsage(familyID string) (*models.StorageUsage, error) {
+	quota, err := s.GetQuota(familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &models.StorageUsage{
+		FamilyID: familyID,
+		ByKind:   emptyStorageKinds(),
+		Members:  []models.MemberStorageUsage{},
+		Quota:    *quota,
+		Warnings: []string{},
+	}
+
+	rows, err := s.db.Query(`
+		SELECT m.id, m.first_name, m.last_name, o.kind, COALESCE(SUM(o.size_bytes), 0)
+		FROM family_members m
+		LEFT JOIN storage_objects o ON o.member_id = m.id AND o.family_id = m.family_id
+		WHERE m.family_id = ? AND m.is_active = TRUE
+		GROUP BY m.id, m.first_name, m.last_name, m.display_order, o.kind
+		ORDER BY m.display_order, m.first_name
+	`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query member storage: %w", err)
+	}
+
+	memberIndex := make(map[string]int)
+	for rows.Next() {
+		var memberID, firstName, lastName string
+		var kind sql.NullString
+		var bytes int64
+		if err := rows.Scan(&memberID, &firstName, &lastName, &kind, &bytes); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan member storage: %w", err)
+		}
+
+		i, ok := memberIndex[memberID]
+		if !ok {
+			i = len(usage.Members)
+			memberIndex[memberID] = i
+			usage.Members = append(usage.Members, models.MemberStorageUsage{
+				MemberID: memberID,
+				Name:     firstName + " " + lastName,
+				ByKind:   emptyStorageKinds(),
+			})
+		}
+		if kind.Valid {
+			usage.Members[i].ByKind[kind.String] += bytes
+			usage.Members[i].TotalBytes += bytes
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating member storage: %w", err)
+	}
+
+	// Family totals also count files whose uploader has since been removed
+	kindRows, err := s.db.Query(`SELECT kind, SUM(size_bytes) FROM storage_objects WHERE family_id = ? GROUP BY kind`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query family storage: %w", err)
+	}
+	defer kindRows.Close()
+
+	for kindRows.Next() {
+		var kind string
+		var bytes int64
+		if err := kindRows.Scan(&kind, &bytes); err != nil {
+			return nil, fmt.Errorf("failed to scan family storage: %w", err)
+		}
+		usage.ByKind[kind] = bytes
+		usage.TotalBytes += bytes
+	}
+	if err = kindRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating family storage: %w", err)
+	}
+
+	usage.PercentUsed = models.PercentOf(usage.TotalBytes, quota.HardLimitBytes)
+	if warning := storageWarning("Family storage", usage.PercentUsed); warning != "" {
+		usage.Warnings = append(usage.Warnings, warning)
+	}
+	if quota.MemberLimitBytes != nil {
+		for _, member := range usage.Members {
+			if warning := storageWarning(member.Name+"'s storage", models.PercentOf(member.TotalBytes, *quota.MemberLimitBytes)); warning != "" {
+				usage.Warnings = append(usage.Warnings, warning)
+			}
+		}
+	}
+
+	return usage, nil
+}
+
+// CheckUpload reports whether a file of sizeBytes would fit, so upload paths
+// can reject it before reading the body. It returns a *models.QuotaError when not.
+func (s *StorageService) CheckUpload(familyID, memberID string, sizeBytes int64) error {
+	return s.checkQuota(s.db.QueryRow, familyID, memberID, sizeBytes)
+}
+
+// RecordObject counts a stored file against the family's quota. The quota is
+// checked again in the insert's transaction, since the file may have been
+// accepted by CheckUpload well before it finished uploading.
+func (s *StorageService) RecordObject(familyID, memberID, kind, storageKey string, sizeBytes int64) (*models.StorageObject, error) {
+	return s.recordObject(familyID, memberID, storageKey, &models.UploadRequest{
+		Kind:        kind,
+		ContentType: "application/octet-stream",
+		SizeBytes:   sizeBytes,
+	})
+}
+
+// recordObject is RecordObject with the file's name and content type
+func (s *StorageService) recordObject(familyID, memberID, storageKey string, upload *models.UploadRequest) (*models.StorageObject, error) {
+	kind, sizeBytes := upload.Kind, upload.SizeBytes
+	validator := validation.NewValidator()
+	validator.OneOf("kind", kind, []string{models.StorageKindAttachment, models.StorageKindAvatar, models.StorageKindPhoto})
+	validator.Required("storage_key", storageKey)
+	validator.MaxLength("storage_key", storageKey, 500)
+	validator.MaxLength("file_name", upload.FileName, 255)
+	validator.MaxLength("content_type", upload.ContentType, 255)
+	if sizeBytes < 0 {
+		validator.AddError("size_bytes", "size_bytes must not be negative")
+	}
+	if err := validator.ToError(); err != nil {
+		return nil, err
+	}
+
+	var memberValue any
+	if memberID != "" {
+		memberValue = memberID
+	}
+
+	objectID := ids.New("object")
+	err := s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		if err := s.checkQuota(tx.QueryRow, familyID, memberID, sizeBytes); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO storage_objects (id, family_id, member_id, kind, storage_key, file_name, content_type, size_bytes, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, objectID, familyID, memberValue, kind, storageKey, upload.FileName, upload.ContentType, sizeBytes, time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("failed to record storage object: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getObject(familyID, objectID)
+}
+
+// DeleteObject stops counting a stored file once the upload path removes it
+func (s *StorageService) DeleteObject(familyID, storageKey string) error {
+	result, err := s.db.Exec(`DELETE FROM storage_objects WHERE family_id = ? AND storage_key = ?`, familyID, storageKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete storage object: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("storage object not found")
+	}
+
+	return nil
+}
+
+// GetObject returns one of the family's stored files
+func (s *StorageService) GetObject(familyID, objectID string) (*models.StorageObject, error) {
+	return s.getObject(familyID, objectID)
+}
+
+func (s *StorageService) getObject(familyID, objectID string) (*models.StorageObject, error) {
+	var object models.StorageObject
+	var memberID sql.NullString
+
+	err := s.db.QueryRow(`
+		SELECT id, family_id, member_id, kind, storage_key, file_name, content_type, size_bytes, created_at
+		FROM storage_objects WHERE id = ? AND family_id = ?
+	`, objectID, familyID).Scan(&object.ID, &object.FamilyID, &memberID, &object.Kind, &object.StorageKey,
+		&object.FileName, &object.ContentType, &object.SizeBytes, &object.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("storage object not found")
+		}
+		return nil, fmt.Errorf("failed to get storage object: %w", err)
+	}
+
+	if memberID.Valid {
+		object.MemberID = &memberID.String
+	}
+	return &object, nil
+}
+
+// checkQuota compares a proposed upload against the per-file, family and
+// member caps using queryRow, which is either the database or a transaction
+func (s *StorageService) checkQuota(queryRow func(query string, args ...any) *sql.Row, familyID, memberID string, sizeBytes int64) error {
+	if sizeBytes > models.MaxStorageObjectBytes {
+		return &models.QuotaError{
+			Code:           models.QuotaCodeFileTooLarge,
+			Message:        fmt.Sprintf("files can be at most %d bytes", models.MaxStorageObjectBytes),
+			LimitBytes:     models.MaxStorageObjectBytes,
+			RequestedBytes: sizeBytes,
+		}
+	}
+
+	quota := models.StorageQuota{FamilyID: familyID, HardLimitBytes: models.DefaultStorageHardLimitBytes}
+	var memberLimit sql.NullInt64
+	err := queryRow(`SELECT hard_limit_bytes, member_limit_bytes FROM storage_quotas WHERE family_id = ?`, familyID).
+		Scan(&quota.HardLimitBytes, &memberLimit)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get storage quota: %w", err)
+	}
+
+	var familyUsed int64
+	if err := queryRow(`SELECT COALESCE(SUM(size_bytes), 0) FROM storage_objects WHERE family_id = ?`, familyID).Scan(&familyUsed); err != nil {
+		return fmt.Errorf("failed to sum family storage: %w", err)
+	}
+	if familyUsed+sizeBytes > quota.HardLimitBytes {
+		return &models.QuotaError{
+			Code:           models.QuotaCodeFamilyLimitExceeded,
+			Message:        "the family's storage is full",
+			LimitBytes:     quota.HardLimitBytes,
+			UsedBytes:      familyUsed,
+			RequestedBytes: sizeBytes,
+		}
+	}
+
+	if memberLimit.Valid && memberID != "" {
+		var memberUsed int64
+		err := queryRow(`SELECT COALESCE(SUM(size_bytes), 0) FROM storage_objects WHERE family_id = ? AND member_id = ?`, familyID, memberID).Scan(&memberUsed)
+		if err != nil {
+			return fmt.Errorf("failed to sum member storage: %w", err)
+		}
+		if memberUsed+sizeBytes > memberLimit.Int64 {
+			return &models.QuotaError{
+				Code:           models.QuotaCodeMemberLimitExceeded,
+				Message:        "this member's storage is full",
+				LimitBytes:     memberLimit.Int64,
+				UsedBytes:      memberUsed,
+				RequestedBytes: sizeBytes,
+			}
+		}
+	}
+
+	return nil
+}
+
+// storageWarning returns a warning for the highest threshold percent has
+// reached, or "" below the first threshold
+func storageWarning(subject string, percent int) string {
+	reached := 0
+	for _, threshold := range models.StorageWarningThresholds {
+		if percent >= threshold {
+			reached = threshold
+		}
+	}
+	if reached == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s is %d%% full", subject, percent)
+}
+
+func emptyStorageKinds() map[string]int64 {
+	return map[string]int64{
+		models.StorageKindAttachment: 0,
+		models.StorageKindAvatar:     0,
+		models.StorageKindPhoto:      0,
+	}
+}
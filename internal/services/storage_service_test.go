@@ -0,0 +1,110 @@
+package services
+
+import (
+	"testing"
+
+	"famstack/internal/models"
+	"famstack/internal/validation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageService_EnforcesHardCaps(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewStorageService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	memberLimit := int64(1500)
+	_, err := service.UpdateQuota(familyID, &models.UpdateStorageQuotaRequest{
+		HardLimitBytes:   int64Ptr(2000),
+		MemberLimitBytes: &memberLimit,
+	})
+	require.NoError(t, err)
+
+	_, err = service.RecordObject(familyID, memberID, models.StorageKindPhoto, "photos/too-big.jpg", models.MaxStorageObjectBytes+1)
+	var quotaErr *models.QuotaError
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, models.QuotaCodeFileTooLarge, quotaErr.Code)
+
+	_, err = service.RecordObject(familyID, memberID, models.StorageKindPhoto, "photos/beach.jpg", 1000)
+	require.NoError(t, err)
+
+	// The member cap is hit before the family cap
+	_, err = service.RecordObject(familyID, memberID, models.StorageKindAttachment, "files/permission-slip.pdf", 600)
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, models.QuotaCodeMemberLimitExceeded, quotaErr.Code)
+	assert.Equal(t, int64(1000), quotaErr.UsedBytes)
+
+	// Files without an uploader only count against the family
+	_, err = service.RecordObject(familyID, "", models.StorageKindAttachment, "files/menu.pdf", 900)
+	require.NoError(t, err)
+	err = service.CheckUpload(familyID, "", 200)
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, models.QuotaCodeFamilyLimitExceeded, quotaErr.Code)
+
+	// Deleting a file frees its space
+	require.NoError(t, service.DeleteObject(familyID, "files/menu.pdf"))
+	assert.NoError(t, service.CheckUpload(familyID, "", 200))
+	assert.EqualError(t, service.DeleteObject(familyID, "files/menu.pdf"), "storage object not found")
+}
+
+func TestStorageService_GetUsage(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewStorageService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	usage, err := service.GetUsage(familyID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DefaultStorageHardLimitBytes, usage.Quota.HardLimitBytes)
+	assert.Zero(t, usage.TotalBytes)
+	assert.Empty(t, usage.Warnings)
+
+	_, err = service.UpdateQuota(familyID, &models.UpdateStorageQuotaRequest{HardLimitBytes: int64Ptr(1000)})
+	require.NoError(t, err)
+	_, err = service.RecordObject(familyID, memberID, models.StorageKindAvatar, "avatars/me.png", 300)
+	require.NoError(t, err)
+	_, err = service.RecordObject(familyID, memberID, models.StorageKindPhoto, "photos/park.jpg", 550)
+	require.NoError(t, err)
+
+	usage, err = service.GetUsage(familyID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(850), usage.TotalBytes)
+	assert.Equal(t, int64(300), usage.ByKind[models.StorageKindAvatar])
+	assert.Equal(t, int64(0), usage.ByKind[models.StorageKindAttachment])
+	assert.Equal(t, 85, usage.PercentUsed)
+	assert.Equal(t, []string{"Family storage is 85% full"}, usage.Warnings)
+
+	require.Len(t, usage.Members, 1)
+	assert.Equal(t, memberID, usage.Members[0].MemberID)
+	assert.Equal(t, int64(850), usage.Members[0].TotalBytes)
+	assert.Equal(t, int64(550), usage.Members[0].ByKind[models.StorageKindPhoto])
+}
+
+func TestStorageService_UpdateQuotaValidation(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewStorageService(db)
+	familyID, _ := seedBulkEventFamily(t, db)
+
+	_, err := service.UpdateQuota(familyID, &models.UpdateStorageQuotaRequest{HardLimitBytes: int64Ptr(0)})
+	var validationErrs validation.ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
+
+	_, err = service.UpdateQuota(familyID, &models.UpdateStorageQuotaRequest{HardLimitBytes: int64Ptr(100), MemberLimitBytes: int64Ptr(200)})
+	require.ErrorAs(t, err, &validationErrs)
+
+	quota, err := service.UpdateQuota(familyID, &models.UpdateStorageQuotaRequest{HardLimitBytes: int64Ptr(100), MemberLimitBytes: int64Ptr(50)})
+	require.NoError(t, err)
+	require.NotNil(t, quota.MemberLimitBytes)
+	assert.Equal(t, int64(50), *quota.MemberLimitBytes)
+
+	// Zero removes the member limit
+	quota, err = service.UpdateQuota(familyID, &models.UpdateStorageQuotaRequest{MemberLimitBytes: int64Ptr(0)})
+	require.NoError(t, err)
+	assert.Nil(t, quota.MemberLimitBytes)
+	assert.Equal(t, int64(100), quota.HardLimitBytes)
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
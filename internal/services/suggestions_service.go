@@ -0,0 +1,566 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/ids"
+	"famstack/internal/models"
+	"famstack/internal/validation"
+)
+
+// skippedScheduleWeeks is how many whole weeks of untouched tasks mark a schedule as forgotten
+const skippedScheduleWeeks = 3
+
+// SuggestionsService detects neglected patterns in a family's data and turns
+// them into suggestions that can be applied with one call
+type SuggestionsService struct {
+	db        *database.Fascade
+	tasks     *TasksService
+	schedules *SchedulesService
+}
+
+// NewSuggestionsService creates a new suggestions service
+func NewSuggestionsService(db *database.Fascade, tasks *TasksService, schedules *SchedulesService) *SuggestionsService {
+	return &SuggestionsService{db: db, tasks: tasks, schedules: schedules}
+}
+
+// GenerateSuggestions runs every detector for the family and stores new
+// suggestions. A pattern already suggested (even if dismissed) is not repeated.
+func (s *SuggestionsService) GenerateSuggestions(familyID string, now time.Time) (int, error) {
+	var candidates []models.Suggestion
+
+	skipped, err := s.detectSkippedSchedules(familyID, now)
+	if err != nil {
+		return 0, err
+	}
+	candidates = append(candidates, skipped...)
+
+	idle, err := s.detectIdleMembers(familyID, now)
+	if err != nil {
+		return 0, err
+	}
+	candidates = append(candidates, idle...)
+
+	pickups, err := s.detectMissingPickups(familyID, now)
+	if err != nil {
+		return 0, err
+	}
+	candidates = append(candidates, pickups...)
+
+	created := 0
+	for _, suggestion := range candidates {
+		result, err := s.db.Exec(`
+			INSERT INTO suggestions (id, family_id, kind, title, detail, action_type, target_id,
+			                         member_id, dedupe_key, status, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT DO NOTHING
+		`, ids.New("suggestion"), familyID, suggestion.Kind,
+			suggestion.Title, suggestion.Detail, suggestion.ActionType, suggestion.TargetID, suggestion.MemberID,
+			suggestion.DedupeKey, models.SuggestionStatusOpen, now.UTC())
+		if err != nil {
+			return created, fmt.Errorf("failed to store suggestion: %w", err)
+		}
+		if rowsAffected, err := result.RowsAffected(); err == nil {
+			created += int(rowsAffected)
+		}
+	}
+
+	return created, nil
+}
+
+// ListSuggestions returns a family's suggestions, newest first, optionally filtered by status
+func (s *SuggestionsService) ListSuggestions(familyID, status string) ([]models.Suggestion, error) {
+	query := `
+		SELECT id, family_id, kind, title, detail, action_type, target_id, member_id, dedupe_key,
+		       status, created_at, resolved_at
+		FROM suggestions WHERE family_id = ?`
+	args := []any{familyID}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY created_at DESC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := []models.Suggestion{}
+	for rows.Next() {
+		suggestion, err := scanSuggestion(rows)
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, *suggestion)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating suggestions: %w", err)
+	}
+
+	return suggestions, nil
+}
+
+// GetSuggestion returns a suggestion by ID
+func (s *SuggestionsService) GetSuggestion(suggestionID string) (*models.Suggestion, error) {
+	row := s.db.QueryRow(`
+		SELECT id, family_id, kind, title, detail, action_type, target_id, member_id, dedupe_key,
+		       status, created_at, resolved_at
+		FROM suggestions WHERE id = ?
+	`, suggestionID)
+	suggestion, err := scanSuggestion(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("suggestion not found")
+	}
+	return suggestion, err
+}
+
+// ApplySuggestion performs the suggestion's action and marks it applied
+func (s *SuggestionsService) ApplySuggestion(suggestionID string) (*models.Suggestion, error) {
+	suggestion, err := s.GetSuggestion(suggestionID)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion.Status != models.SuggestionStatusOpen {
+		return nil, validation.ValidationErrors{{Field: "status", Message: "suggestion has already been " + suggestion.Status}}
+	}
+
+	switch suggestion.ActionType {
+	case models.SuggestionActionPauseSchedule:
+		if err := s.schedules.DeactivateSchedule(suggestion.FamilyID, suggestion.TargetID); err != nil {
+			return nil, fmt.Errorf("failed to pause schedule: %w", err)
+		}
+
+	case models.SuggestionActionAssignTask:
+		task, err := s.tasks.GetFamilyTask(suggestion.FamilyID, suggestion.TargetID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load task: %w", err)
+		}
+		if task.AssignedTo != nil || task.Status == models.TaskStatusCompleted {
+			return nil, validation.ValidationErrors{{Field: "target_id", Message: "task has already been picked up"}}
+		}
+		if _, err := s.tasks.UpdateTask(suggestion.FamilyID, task.ID, &models.UpdateTaskRequest{AssignedTo: suggestion.MemberID}); err != nil {
+			return nil, fmt.Errorf("failed to assign task: %w", err)
+		}
+
+	case models.SuggestionActionAddAttendee:
+		_, err := s.db.Exec(`INSERT INTO unified_calendar_event_attendees (event_id, user_id) VALUES (?, ?) ON CONFLICT DO NOTHING`,
+			suggestion.TargetID, suggestion.MemberID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add pickup attendee: %w", err)
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown suggestion action %s", suggestion.ActionType)
+	}
+
+	return s.resolve(suggestion.ID, models.SuggestionStatusApplied)
+}
+
+// DismissSuggestion closes a suggestion without acting on it
+func (s *SuggestionsService) DismissSuggestion(suggestionID string) (*models.Suggestion, error) {
+	suggestion, err := s.GetSuggestion(suggestionID)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion.Status != models.SuggestionStatusOpen {
+		return nil, validation.ValidationErrors{{Field: "status", Message: "suggestion has already been " + suggestion.Status}}
+	}
+
+	return s.resolve(suggestion.ID, models.SuggestionStatusDismissed)
+}
+
+// BuildDigest summarizes the week containing now: what is still open and how
+// many suggestions were applied or dismissed since Monday
+func (s *SuggestionsService) BuildDigest(familyID string, now time.Time) (*models.SuggestionDigest, error) {
+	weekStart := models.WeekStart(now.UTC())
+
+	open, err := s.ListSuggestions(familyID, models.SuggestionStatusOpen)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := &models.SuggestionDigest{
+		FamilyID:    familyID,
+		WeekStart:   weekStart.Format("2006-01-02"),
+		GeneratedAt: now.UTC(),
+		Open:        open,
+		OpenByKind:  make(map[string]int),
+	}
+	for _, suggestion := range open {
+		digest.OpenByKind[suggestion.Kind]++
+	}
+
+	rows, err := s.db.Query(`
+		SELECT status, COUNT(*) FROM suggestions
+		WHERE family_id = ? AND status != ? AND resolved_at >= ?
+		GROUP BY status
+	`, familyID, models.SuggestionStatusOpen, weekStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count resolved suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan suggestion counts: %w", err)
+		}
+		switch status {
+		case models.SuggestionStatusApplied:
+			digest.Applied = count
+		case models.SuggestionStatusDismissed:
+			digest.Dismissed = count
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating suggestion counts: %w", err)
+	}
+
+	return digest, nil
+}
+
+// SaveDigest stores the digest as the family's digest for its week
+func (s *SuggestionsService) SaveDigest(digest *models.SuggestionDigest) error {
+	payload, err := json.Marshal(digest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal digest: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO suggestion_digests (id, family_id, week_start, payload, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(family_id, week_start) DO UPDATE SET payload = excluded.payload, created_at = excluded.created_at
+	`, ids.New("digest"), digest.FamilyID, digest.WeekStart, string(payload), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save digest: %w", err)
+	}
+	return nil
+}
+
+// LatestDigest returns the most recently stored weekly digest
+func (s *SuggestionsService) LatestDigest(familyID string) (*models.SuggestionDigest, error) {
+	var payload string
+	err := s.db.QueryRow(`
+		SELECT payload FROM suggestion_digests WHERE family_id = ? ORDER BY week_start DESC LIMIT 1
+	`, familyID).Scan(&payload)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("suggestion digest not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get digest: %w", err)
+	}
+
+	var digest models.SuggestionDigest
+	if err := json.Unmarshal([]byte(payload), &digest); err != nil {
+		return nil, fmt.Errorf("failed to parse digest: %w", err)
+	}
+	return &digest, nil
+}
+
+// detectSkippedSchedules finds active schedules whose tasks went untouched in
+// each of the last three whole weeks
+func (s *SuggestionsService) detectSkippedSchedules(familyID string, now time.Time) ([]models.Suggestion, error) {
+	weekStart := models.WeekStart(now.UTC())
+	windowStart := weekStart.AddDate(0, 0, -7*skippedScheduleWeeks)
+
+	rows, err := s.db.Query(`
+		SELECT s.id, s.title, t.due_date, t.status
+		FROM tasks t
+		JOIN task_schedules s ON s.id = t.schedule_id
+		WHERE t.family_id = ? AND s.active = true AND t.due_date >= ? AND t.due_date < ?
+	`, familyID, windowStart, weekStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scheduled tasks: %w", err)
+	}
+	defer rows.Close()
+
+	type weekCounts struct {
+		total     [skippedScheduleWeeks]int
+		completed [skippedScheduleWeeks]int
+	}
+	titles := make(map[string]string)
+	counts := make(map[string]*weekCounts)
+	var order []string
+
+	for rows.Next() {
+		var scheduleID, title, status string
+		var dueDate time.Time
+		if err := rows.Scan(&scheduleID, &title, &dueDate, &status); err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled task: %w", err)
+		}
+
+		week := int(dueDate.Sub(windowStart).Hours() / (24 * 7))
+		if week < 0 || week >= skippedScheduleWeeks {
+			continue
+		}
+		if counts[scheduleID] == nil {
+			counts[scheduleID] = &weekCounts{}
+			titles[scheduleID] = title
+			order = append(order, scheduleID)
+		}
+		counts[scheduleID].total[week]++
+		if models.TaskStatus(status) == models.TaskStatusCompleted {
+			counts[scheduleID].completed[week]++
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scheduled tasks: %w", err)
+	}
+
+	var suggestions []models.Suggestion
+	for _, scheduleID := range order {
+		c := counts[scheduleID]
+		skipped := true
+		for week := 0; week < skippedScheduleWeeks; week++ {
+			if c.total[week] == 0 || c.completed[week] > 0 {
+				skipped = false
+				break
+			}
+		}
+		if !skipped {
+			continue
+		}
+
+		suggestions = append(suggestions, models.Suggestion{
+			Kind:       models.SuggestionSkippedSchedule,
+			Title:      fmt.Sprintf("Pause \"%s\"?", titles[scheduleID]),
+			Detail:     fmt.Sprintf("None of its tasks were completed in the last %d weeks.", skippedScheduleWeeks),
+			ActionType: models.SuggestionActionPauseSchedule,
+			TargetID:   scheduleID,
+			DedupeKey:  fmt.Sprintf("%s:%s:%s", models.SuggestionSkippedSchedule, scheduleID, weekStart.Format("2006-01-02")),
+		})
+	}
+
+	return suggestions, nil
+}
+
+// detectIdleMembers finds people with no tasks a week either side of now and
+// pairs each with a distinct unassigned task they could pick up
+func (s *SuggestionsService) detectIdleMembers(familyID string, now time.Time) ([]models.Suggestion, error) {
+	rows, err := s.db.Query(`
+		SELECT m.id, m.first_name
+		FROM family_members m
+		WHERE m.family_id = ? AND m.is_active = TRUE AND m.member_type IN ('adult', 'child')
+		  AND NOT EXISTS (
+			SELECT 1 FROM tasks t
+			WHERE t.assigned_to = m.id
+			  AND ((t.due_date >= ? AND t.due_date < ?) OR (t.due_date IS NULL AND t.status = 'pending'))
+		  )
+		ORDER BY m.first_name
+	`, familyID, now.UTC().AddDate(0, 0, -7), now.UTC().AddDate(0, 0, 7))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query idle members: %w", err)
+	}
+
+	type idleMember struct{ id, name string }
+	var idle []idleMember
+	for rows.Next() {
+		var member idleMember
+		if err := rows.Scan(&member.id, &member.name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan idle member: %w", err)
+		}
+		idle = append(idle, member)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating idle members: %w", err)
+	}
+	if len(idle) == 0 {
+		return nil, nil
+	}
+
+	taskRows, err := s.db.Query(`
+		SELECT id, title FROM tasks
+		WHERE family_id = ? AND assigned_to IS NULL AND status = 'pending'
+		ORDER BY COALESCE(due_date, created_at)
+		LIMIT ?
+	`, familyID, len(idle))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unassigned tasks: %w", err)
+	}
+	defer taskRows.Close()
+
+	weekStart := models.WeekStart(now.UTC()).Format("2006-01-02")
+	var suggestions []models.Suggestion
+	for i := 0; taskRows.Next() && i < len(idle); i++ {
+		var taskID, title string
+		if err := taskRows.Scan(&taskID, &title); err != nil {
+			return nil, fmt.Errorf("failed to scan unassigned task: %w", err)
+		}
+
+		memberID := idle[i].id
+		suggestions = append(suggestions, models.Suggestion{
+			Kind:       models.SuggestionIdleMember,
+			Title:      fmt.Sprintf("Give \"%s\" to %s?", title, idle[i].name),
+			Detail:     fmt.Sprintf("%s has no tasks this week or next.", idle[i].name),
+			ActionType: models.SuggestionActionAssignTask,
+			TargetID:   taskID,
+			MemberID:   &memberID,
+			DedupeKey:  fmt.Sprintf("%s:%s:%s", models.SuggestionIdleMember, memberID, weekStart),
+		})
+	}
+	if err = taskRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating unassigned tasks: %w", err)
+	}
+
+	return suggestions, nil
+}
+
+// detectMissingPickups finds events in the coming week that a child attends
+// without any adult, and suggests the first adult who is free at that time
+func (s *SuggestionsService) detectMissingPickups(familyID string, now time.Time) ([]models.Suggestion, error) {
+	type upcomingEvent struct {
+		id, title  string
+		start, end time.Time
+		children   int
+		adults     map[string]bool
+	}
+
+	rows, err := s.db.Query(`
+		SELECT e.id, e.title, e.start_time, e.end_time, a.user_id, m.member_type
+		FROM unified_calendar_events e
+		LEFT JOIN unified_calendar_event_attendees a ON a.event_id = e.id
+		LEFT JOIN family_members m ON m.id = a.user_id
+		WHERE e.family_id = ? AND e.status = 'active' AND e.all_day = false
+		  AND e.start_time >= ? AND e.start_time < ?
+		ORDER BY e.start_time
+	`, familyID, now.UTC(), now.UTC().AddDate(0, 0, 7))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query upcoming events: %w", err)
+	}
+
+	events := make(map[string]*upcomingEvent)
+	var order []string
+	for rows.Next() {
+		var id, title string
+		var start, end time.Time
+		var attendeeID, memberType sql.NullString
+		if err := rows.Scan(&id, &title, &start, &end, &attendeeID, &memberType); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan upcoming event: %w", err)
+		}
+
+		event := events[id]
+		if event == nil {
+			event = &upcomingEvent{id: id, title: title, start: start, end: end, adults: make(map[string]bool)}
+			events[id] = event
+			order = append(order, id)
+		}
+		switch memberType.String {
+		case "child":
+			event.children++
+		case "adult":
+			event.adults[attendeeID.String] = true
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating upcoming events: %w", err)
+	}
+
+	adultRows, err := s.db.Query(`
+		SELECT id, first_name FROM family_members
+		WHERE family_id = ? AND is_active = TRUE AND member_type = 'adult'
+		ORDER BY first_name
+	`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query adults: %w", err)
+	}
+	defer adultRows.Close()
+
+	type adult struct{ id, name string }
+	var adults []adult
+	for adultRows.Next() {
+		var a adult
+		if err := adultRows.Scan(&a.id, &a.name); err != nil {
+			return nil, fmt.Errorf("failed to scan adult: %w", err)
+		}
+		adults = append(adults, a)
+	}
+	if err = adultRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating adults: %w", err)
+	}
+	if len(adults) == 0 {
+		return nil, nil
+	}
+
+	busy := func(adultID string, event *upcomingEvent) bool {
+		for _, other := range events {
+			if other.id != event.id && other.adults[adultID] && other.start.Before(event.end) && other.end.After(event.start) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var suggestions []models.Suggestion
+	for _, id := range order {
+		event := events[id]
+		if event.children == 0 || len(event.adults) > 0 {
+			continue
+		}
+
+		chosen := adults[0]
+		for _, candidate := range adults {
+			if !busy(candidate.id, event) {
+				chosen = candidate
+				break
+			}
+		}
+
+		memberID := chosen.id
+		suggestions = append(suggestions, models.Suggestion{
+			Kind:       models.SuggestionMissingPickup,
+			Title:      fmt.Sprintf("Add %s to \"%s\" for pickup?", chosen.name, event.title),
+			Detail:     "No adult is attending this event.",
+			ActionType: models.SuggestionActionAddAttendee,
+			TargetID:   event.id,
+			MemberID:   &memberID,
+			DedupeKey:  strings.Join([]string{models.SuggestionMissingPickup, event.id}, ":"),
+		})
+	}
+
+	return suggestions, nil
+}
+
+func (s *SuggestionsService) resolve(suggestionID, status string) (*models.Suggestion, error) {
+	_, err := s.db.Exec(`UPDATE suggestions SET status = ?, resolved_at = ? WHERE id = ?`, status, time.Now().UTC(), suggestionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update suggestion: %w", err)
+	}
+	return s.GetSuggestion(suggestionID)
+}
+
+func scanSuggestion(scanner interface{ Scan(dest ...any) error }) (*models.Suggestion, error) {
+	var suggestion models.Suggestion
+	var detail, memberID sql.NullString
+	var resolvedAt sql.NullTime
+
+	err := scanner.Scan(&suggestion.ID, &suggestion.FamilyID, &suggestion.Kind, &suggestion.Title, &detail,
+		&suggestion.ActionType, &suggestion.TargetID, &memberID, &suggestion.DedupeKey, &suggestion.Status,
+		&suggestion.CreatedAt, &resolvedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan suggestion: %w", err)
+	}
+
+	suggestion.Detail = detail.String
+	if memberID.Valid {
+		suggestion.MemberID = &memberID.String
+	}
+	if resolvedAt.Valid {
+		suggestion.ResolvedAt = &resolvedAt.Time
+	}
+	return &suggestion, nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedSuggestionsFamily(t *testing.T, service *SuggestionsService) (familyID, parentID, childID string) {
+	familyID = "fam_suggestions"
+	parentID = "member_parent"
+	childID = "member_child"
+
+	_, err := service.db.Exec(`INSERT INTO families (id, name, timezone) VALUES (?, ?, ?)`, familyID, "Suggestion Family", "UTC")
+	require.NoError(t, err)
+	for _, member := range [][]string{{parentID, "Pat", "adult"}, {childID, "Sam", "child"}} {
+		_, err = service.db.Exec(`INSERT INTO family_members (id, family_id, first_name, last_name, member_type, is_active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			member[0], familyID, member[1], "Suggest", member[2], true, time.Now(), time.Now())
+		require.NoError(t, err)
+	}
+
+	return familyID, parentID, childID
+}
+
+func TestWeekStart_ReturnsMonday(t *testing.T) {
+	sunday := time.Date(2026, 3, 15, 18, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), models.WeekStart(sunday))
+
+	monday := time.Date(2026, 3, 9, 6, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), models.WeekStart(monday))
+}
+
+func TestSuggestionsService_SkippedScheduleCanBePaused(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewSuggestionsService(db, NewTasksService(db), NewSchedulesService(db))
+	familyID, parentID, childID := seedSuggestionsFamily(t, service)
+
+	_, err := db.Exec(`INSERT INTO task_schedules (id, family_id, created_by, title, task_type, assigned_to, days_of_week, active) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"sched_trash", familyID, parentID, "Take out trash", "chore", childID, `["monday"]`, true)
+	require.NoError(t, err)
+
+	// A pending task in each of the three weeks before 2026-03-16
+	now := time.Date(2026, 3, 18, 9, 0, 0, 0, time.UTC)
+	for i, due := range []time.Time{
+		time.Date(2026, 2, 23, 18, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 9, 18, 0, 0, 0, time.UTC),
+	} {
+		_, err := db.Exec(`INSERT INTO tasks (id, family_id, assigned_to, title, task_type, status, due_date, created_by, schedule_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			"trash_"+string(rune('a'+i)), familyID, childID, "Take out trash", "chore", "pending", due, parentID, "sched_trash", due, due)
+		require.NoError(t, err)
+	}
+
+	created, err := service.GenerateSuggestions(familyID, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, created)
+
+	// Running again doesn't repeat the same suggestion
+	created, err = service.GenerateSuggestions(familyID, now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, created)
+
+	open, err := service.ListSuggestions(familyID, models.SuggestionStatusOpen)
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	assert.Equal(t, models.SuggestionSkippedSchedule, open[0].Kind)
+	assert.Equal(t, "sched_trash", open[0].TargetID)
+
+	applied, err := service.ApplySuggestion(open[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SuggestionStatusApplied, applied.Status)
+
+	schedule, err := service.schedules.GetSchedule("sched_trash")
+	require.NoError(t, err)
+	assert.False(t, schedule.Active)
+
+	_, err = service.ApplySuggestion(open[0].ID)
+	require.Error(t, err)
+
+	digest, err := service.BuildDigest(familyID, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, digest.Applied)
+	assert.Empty(t, digest.Open)
+}
+
+func TestSuggestionsService_IdleMemberAndMissingPickup(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewSuggestionsService(db, NewTasksService(db), NewSchedulesService(db))
+	familyID, parentID, childID := seedSuggestionsFamily(t, service)
+
+	now := time.Date(2026, 3, 18, 9, 0, 0, 0, time.UTC)
+
+	// The parent has work this week, the child has none and there's a chore nobody owns
+	_, err := db.Exec(`INSERT INTO tasks (id, family_id, assigned_to, title, task_type, status, due_date, created_by, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		"task_parent", familyID, parentID, "Pay bills", "todo", "pending", now.AddDate(0, 0, 1), parentID, now, now)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO tasks (id, family_id, title, task_type, status, created_by, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"task_unassigned", familyID, "Water plants", "chore", "pending", parentID, now, now)
+	require.NoError(t, err)
+
+	// The child has a practice with no adult attending
+	_, err = db.Exec(`INSERT INTO unified_calendar_events (id, family_id, title, start_time, end_time, all_day, event_type, status) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"event_practice", familyID, "Soccer practice", now.Add(8*time.Hour), now.Add(9*time.Hour), false, "event", "active")
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO unified_calendar_event_attendees (event_id, user_id) VALUES (?, ?)`, "event_practice", childID)
+	require.NoError(t, err)
+
+	created, err := service.GenerateSuggestions(familyID, now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, created)
+
+	open, err := service.ListSuggestions(familyID, models.SuggestionStatusOpen)
+	require.NoError(t, err)
+	byKind := make(map[string]models.Suggestion)
+	for _, suggestion := range open {
+		byKind[suggestion.Kind] = suggestion
+	}
+
+	idle, ok := byKind[models.SuggestionIdleMember]
+	require.True(t, ok)
+	assert.Equal(t, "task_unassigned", idle.TargetID)
+	require.NotNil(t, idle.MemberID)
+	assert.Equal(t, childID, *idle.MemberID)
+
+	_, err = service.ApplySuggestion(idle.ID)
+	require.NoError(t, err)
+	task, err := service.tasks.GetTask("task_unassigned")
+	require.NoError(t, err)
+	require.NotNil(t, task.AssignedTo)
+	assert.Equal(t, childID, *task.AssignedTo)
+
+	pickup, ok := byKind[models.SuggestionMissingPickup]
+	require.True(t, ok)
+	require.NotNil(t, pickup.MemberID)
+	assert.Equal(t, parentID, *pickup.MemberID)
+
+	_, err = service.ApplySuggestion(pickup.ID)
+	require.NoError(t, err)
+	var attendees int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM unified_calendar_event_attendees WHERE event_id = ?`, "event_practice").Scan(&attendees))
+	assert.Equal(t, 2, attendees)
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/models"
+)
+
+// overdueTask is a pending task due on a day that is over
+type overdueTask struct {
+	ID         string    `db:"id"`
+	DueDate    time.Time `db:"due_date"`
+	HasDueTime bool      `db:"has_due_time"`
+}
+
+// RollOverTasks applies the family's task rollover policy to its pending
+// tasks due before today, in family time, and returns how many it moved or
+// removed. It is safe to run as often as you like: a task is only ever
+// rolled to today.
+func (s *TasksService) RollOverTasks(familyID string, now time.Time) (int, error) {
+	var policy string
+	err := s.db.QueryRow(`SELECT task_rollover FROM families WHERE id = ?`, familyID).Scan(&policy)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("family not found")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get task rollover policy: %w", err)
+	}
+	if policy == models.TaskRolloverKeep {
+		return 0, nil
+	}
+
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get family timezone for rollover: %w", err)
+	}
+	today, err := ConvertFromUTC(now.UTC(), familyTimezone)
+	if err != nil {
+		return 0, err
+	}
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	startOfToday, err := ConvertToUTC(today, familyTimezone)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := startOfToday.Format("2006-01-02 15:04:05")
+
+	changed := 0
+	switch policy {
+	case models.TaskRolloverSkipScheduled:
+		result, err := s.db.Exec(`
+			DELETE FROM tasks
+			WHERE family_id = ? AND status = 'pending' AND schedule_id IS NOT NULL
+			AND due_date IS NOT NULL AND due_date < ?
+		`, familyID, cutoff)
+		if err != nil {
+			return 0, fmt.Errorf("failed to skip overdue scheduled tasks: %w", err)
+		}
+		removed, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to check affected rows: %w", err)
+		}
+		changed = int(removed)
+
+	case models.TaskRolloverRollForward:
+		tasks, err := database.QueryAll[overdueTask](s.db, `
+			SELECT id, due_date, has_due_time FROM tasks
+			WHERE family_id = ? AND status = 'pending' AND due_date IS NOT NULL AND due_date < ?
+		`, familyID, cutoff)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list overdue tasks: %w", err)
+		}
+		for _, task := range tasks {
+			if err := s.rollForward(task, today, familyTimezone); err != nil {
+				return changed, err
+			}
+			changed++
+		}
+	}
+
+	if changed > 0 {
+		s.publishChange(familyID)
+	}
+	return changed, nil
+}
+
+// rollForward moves a task to today, keeping its time of day. A scheduled
+// task whose schedule already made one for today is removed instead.
+func (s *TasksService) rollForward(task overdueTask, today time.Time, familyTimezone string) error {
+	due := today
+	if task.HasDueTime {
+		local, err := ConvertFromUTC(task.DueDate, familyTimezone)
+		if err != nil {
+			return err
+		}
+		due = time.Date(today.Year(), today.Month(), today.Day(), local.Hour(), local.Minute(), 0, 0, time.UTC)
+	}
+	dueUTC, err := ConvertToUTC(due, familyTimezone)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`UPDATE tasks SET due_date = ?, updated_at = ? WHERE id = ?`,
+		dueUTC.Format("2006-01-02 15:04:05"), time.Now().UTC(), task.ID)
+	if isUniqueConstraintViolation(err) {
+		_, err = s.db.Exec(`DELETE FROM tasks WHERE id = ?`, task.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to roll over task %s: %w", task.ID, err)
+	}
+	return nil
+}
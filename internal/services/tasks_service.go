@@ -1,18 +1,23 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
 	"time"
 
 	"famstack/internal/database"
+	"famstack/internal/eventbus"
+	"famstack/internal/ids"
 	"famstack/internal/models"
+	"famstack/internal/validation"
 )
 
 // TasksService handles all task database operations
 type TasksService struct {
-	db *database.Fascade
+	db     *database.Fascade
+	events *eventbus.Bus
 }
 
 // NewTasksService creates a new tasks service
@@ -20,6 +25,31 @@ func NewTasksService(db *database.Fascade) *TasksService {
 	return &TasksService{db: db}
 }
 
+// SetEventBus sets the bus that writes announce task changes on
+func (s *TasksService) SetEventBus(bus *eventbus.Bus) {
+	s.events = bus
+}
+
+// publishChange tells subscribers the family's tasks changed
+func (s *TasksService) publishChange(familyID string) {
+	s.events.Publish(eventbus.Event{Topic: eventbus.TopicTasksChanged, FamilyID: familyID})
+}
+
+// publishTaskChange tells subscribers one of the family's tasks changed, and how
+func (s *TasksService) publishTaskChange(familyID, taskID, detail string) {
+	s.events.Publish(eventbus.Event{Topic: eventbus.TopicTasksChanged, FamilyID: familyID, SubjectID: taskID, Detail: detail})
+}
+
+// publishStatus tells subscribers a task's status was set
+func (s *TasksService) publishStatus(familyID, taskID string, status models.TaskStatus) {
+	s.events.Publish(eventbus.Event{Topic: eventbus.TopicTaskStatus, FamilyID: familyID, SubjectID: taskID, Detail: string(status)})
+}
+
+// publishAssigned tells subscribers a task went to someone new
+func (s *TasksService) publishAssigned(familyID, taskID string) {
+	s.events.Publish(eventbus.Event{Topic: eventbus.TopicTaskAssigned, FamilyID: familyID, SubjectID: taskID})
+}
+
 // TaskColumn represents a column of tasks for a family member
 type TaskColumn struct {
 	Member TaskMember    `json:"member"`
@@ -39,8 +69,9 @@ type TasksResponse struct {
 	Date          string                `json:"date"`
 }
 
-// ListTasksByFamily returns all tasks organized by family member for a specific date
-func (s *TasksService) ListTasksByFamily(familyID, dateFilter string) (*TasksResponse, error) {
+// ListTasksByFamily returns all tasks organized by family member for a
+// specific date, each column ordered by sortBy
+func (s *TasksService) ListTasksByFamily(familyID, dateFilter, sortBy string) (*TasksResponse, error) {
 	// 1. Get all active family members
 	members, err := s.getActiveFamilyMembers(familyID)
 	if err != nil {
@@ -86,6 +117,10 @@ func (s *TasksService) ListTasksByFamily(familyID, dateFilter string) (*TasksRes
 		// it will be ignored. This is the desired behavior.
 	}
 
+	for _, column := range tasksByMember {
+		models.SortTasks(column.Tasks, sortBy)
+	}
+
 	return &TasksResponse{
 		TasksByMember: tasksByMember,
 		Date:          dateFilter,
@@ -124,9 +159,9 @@ func (s *TasksService) getActiveFamilyMembers(familyID string) ([]TaskMember, er
 func (s *TasksService) getTasksForFamily(familyID, dateFilter string) ([]models.Task, error) {
 	query := `
 		SELECT id, family_id, assigned_to, title, description, task_type, status,
-			   priority, due_date, created_by, created_at, updated_at, completed_at
+			   priority, due_date, has_due_time, created_by, created_at, updated_at, completed_at
 		FROM tasks
-		WHERE family_id = ? AND SUBSTR(due_date, 1, 10) = ?
+		WHERE family_id = ? AND SUBSTR(CAST(due_date AS TEXT), 1, 10) = ?
 		ORDER BY created_at DESC
 	`
 	rows, err := s.db.Query(query, familyID, dateFilter)
@@ -151,47 +186,41 @@ func (s *TasksService) getTasksForFamily(familyID, dateFilter string) ([]models.
 func (s *TasksService) GetTask(taskID string) (*models.Task, error) {
 	query := `
 		SELECT id, family_id, assigned_to, title, description, task_type, status,
-			   priority, due_date, created_by, created_at, updated_at, completed_at
+			   priority, due_date, has_due_time, created_by, created_at, updated_at, completed_at
 		FROM tasks
 		WHERE id = ?
 	`
 
-	var task models.Task
-	var assignedTo, dueDate, completedAt sql.NullString
-
-	err := s.db.QueryRow(query, taskID).Scan(
-		&task.ID, &task.FamilyID, &assignedTo, &task.Title, &task.Description,
-		&task.TaskType, &task.Status, &task.Priority, &dueDate,
-		&task.CreatedBy, &task.CreatedAt, &task.UpdatedAt, &completedAt,
-	)
-
+	task, err := database.QueryOne[models.Task](s.db, query, taskID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("task not found")
+			return nil, notFound("task")
 		}
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
 
-	// Handle nullable fields
-	if assignedTo.Valid {
-		task.AssignedTo = &assignedTo.String
-	}
-	if dueDate.Valid {
-		if parsed, parseErr := time.Parse(time.RFC3339, dueDate.String); parseErr == nil {
-			task.DueDate = &parsed
-		}
+	return s.toFamilyTime(task)
+}
+
+// GetFamilyTask returns one of the family's tasks; another family's task is
+// not found
+func (s *TasksService) GetFamilyTask(familyID, taskID string) (*models.Task, error) {
+	task, err := s.GetTask(taskID)
+	if err != nil {
+		return nil, err
 	}
-	if completedAt.Valid {
-		if parsed, parseErr := time.Parse(time.RFC3339, completedAt.String); parseErr == nil {
-			task.CompletedAt = &parsed
-		}
+	if err := inFamily("task", task.FamilyID, familyID); err != nil {
+		return nil, err
 	}
-
-	return &task, nil
+	return task, nil
 }
 
 // CreateTask creates a new task
 func (s *TasksService) CreateTask(familyID, createdBy string, req *models.CreateTaskRequest) (*models.Task, error) {
+	if err := req.Normalize(); err != nil {
+		return nil, err
+	}
+
 	taskID := generateTaskID()
 	now := time.Now().UTC()
 
@@ -203,7 +232,14 @@ func (s *TasksService) CreateTask(familyID, createdBy string, req *models.Create
 			return nil, fmt.Errorf("failed to get family timezone for task creation: %w", err)
 		}
 
-		convertedDueDate, err := ConvertToUTC(*req.DueDate, familyTimezone)
+		dueDate := *req.DueDate
+		if req.DueTime != nil {
+			if dueDate, err = withDueTime(dueDate, *req.DueTime, familyTimezone); err != nil {
+				return nil, err
+			}
+		}
+
+		convertedDueDate, err := ConvertToUTC(dueDate, familyTimezone)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert due date to UTC: %w", err)
 		}
@@ -212,33 +248,44 @@ func (s *TasksService) CreateTask(familyID, createdBy string, req *models.Create
 
 	query := `
 		INSERT INTO tasks (id, family_id, assigned_to, title, description, task_type,
-						  status, priority, due_date, created_by, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+						  status, priority, due_date, has_due_time, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err := s.db.Exec(query,
 		taskID, familyID, req.AssignedTo, req.Title, req.Description,
-		req.TaskType, "pending", req.Priority, dueDateUTC,
+		req.TaskType, "pending", req.Priority, dueDateUTC, req.DueTime != nil,
 		createdBy, now, now,
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
+	s.publishTaskChange(familyID, taskID, eventbus.DetailCreated)
+	if req.AssignedTo != nil && *req.AssignedTo != "" {
+		s.publishAssigned(familyID, taskID)
+	}
 
 	return s.GetTask(taskID)
 }
 
-// UpdateTask updates an existing task
-func (s *TasksService) UpdateTask(taskID string, req *models.UpdateTaskRequest) (*models.Task, error) {
-	// Get familyID for timezone conversions if needed
-	var familyID string
-	if req.DueDate != nil {
-		query := `SELECT family_id FROM tasks WHERE id = ?`
-		err := s.db.QueryRow(query, taskID).Scan(&familyID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get family ID for task: %w", err)
-		}
+// UpdateTask updates one of the family's tasks
+func (s *TasksService) UpdateTask(familyID, taskID string, req *models.UpdateTaskRequest) (*models.Task, error) {
+	if err := req.Normalize(); err != nil {
+		return nil, err
+	}
+
+	// Another family's task is not found. The current due date is needed to
+	// move just its day or just its time.
+	current, err := s.GetFamilyTask(familyID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remember the assignee so a reassignment can be announced
+	var previousAssignee sql.NullString
+	if req.AssignedTo != nil {
+		_ = s.db.QueryRow(`SELECT assigned_to FROM tasks WHERE id = ?`, taskID).Scan(&previousAssignee) // nolint:errcheck
 	}
 
 	// Build dynamic update query
@@ -272,33 +319,38 @@ func (s *TasksService) UpdateTask(taskID string, req *models.UpdateTaskRequest)
 		setParts = append(setParts, "priority = ?")
 		args = append(args, *req.Priority)
 	}
-	if req.DueDate != nil {
+	if req.DueDate != nil || req.DueTime != nil {
 		// Get family timezone and convert DueDate to UTC before storing
 		familyTimezone, err := GetFamilyTimezone(s.db, familyID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get family timezone for task update: %w", err)
 		}
 
-		convertedDueDate, err := ConvertToUTC(*req.DueDate, familyTimezone)
+		dueDate, hasDueTime, err := updatedDue(current, req, familyTimezone)
+		if err != nil {
+			return nil, err
+		}
+
+		convertedDueDate, err := ConvertToUTC(dueDate, familyTimezone)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert due date to UTC: %w", err)
 		}
 
-		setParts = append(setParts, "due_date = ?")
-		args = append(args, convertedDueDate)
+		setParts = append(setParts, "due_date = ?", "has_due_time = ?")
+		args = append(args, convertedDueDate, hasDueTime)
 	}
 
 	if len(setParts) == 1 { // Only updated_at
-		return s.GetTask(taskID) // No changes, return current
+		return current, nil // No changes, return current
 	}
 
-	// Add taskID to args for WHERE clause
-	args = append(args, taskID)
+	// Add taskID and familyID to args for WHERE clause
+	args = append(args, taskID, familyID)
 
 	query := fmt.Sprintf(`
 		UPDATE tasks
 		SET %s
-		WHERE id = ?
+		WHERE id = ? AND family_id = ?
 	`, strings.Join(setParts, ", "))
 
 	result, err := s.db.Exec(query, args...)
@@ -312,17 +364,31 @@ func (s *TasksService) UpdateTask(taskID string, req *models.UpdateTaskRequest)
 	}
 
 	if rowsAffected == 0 {
-		return nil, fmt.Errorf("task not found")
+		return nil, notFound("task")
 	}
 
-	return s.GetTask(taskID)
+	task, err := s.GetTask(taskID)
+	if err == nil {
+		detail := eventbus.DetailUpdated
+		if req.Status != nil && *req.Status == models.TaskStatusCompleted {
+			detail = eventbus.DetailCompleted
+		}
+		s.publishTaskChange(task.FamilyID, task.ID, detail)
+		if req.AssignedTo != nil && *req.AssignedTo != "" && *req.AssignedTo != previousAssignee.String {
+			s.publishAssigned(task.FamilyID, task.ID)
+		}
+		if req.Status != nil {
+			s.publishStatus(task.FamilyID, task.ID, *req.Status)
+		}
+	}
+	return task, err
 }
 
-// DeleteTask deletes a task
-func (s *TasksService) DeleteTask(taskID string) error {
-	query := `DELETE FROM tasks WHERE id = ?`
+// DeleteTask deletes one of the family's tasks
+func (s *TasksService) DeleteTask(familyID, taskID string) error {
+	query := `DELETE FROM tasks WHERE id = ? AND family_id = ?`
 
-	result, err := s.db.Exec(query, taskID)
+	result, err := s.db.Exec(query, taskID, familyID)
 	if err != nil {
 		return fmt.Errorf("failed to delete task: %w", err)
 	}
@@ -333,8 +399,9 @@ func (s *TasksService) DeleteTask(taskID string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("task not found")
+		return notFound("task")
 	}
+	s.publishTaskChange(familyID, taskID, eventbus.DetailDeleted)
 
 	return nil
 }
@@ -343,7 +410,7 @@ func (s *TasksService) DeleteTask(taskID string) error {
 func (s *TasksService) ListTasksByMember(memberID string) ([]models.Task, error) {
 	query := `
 		SELECT id, family_id, assigned_to, title, description, task_type, status,
-			   priority, due_date, created_by, created_at, updated_at, completed_at
+			   priority, due_date, has_due_time, created_by, created_at, updated_at, completed_at
 		FROM tasks
 		WHERE assigned_to = ?
 		ORDER BY created_at DESC
@@ -371,11 +438,44 @@ func (s *TasksService) ListTasksByMember(memberID string) ([]models.Task, error)
 	return tasks, nil
 }
 
+// ListOverdueTasks returns a family's pending tasks due before the given
+// YYYY-MM-DD date, oldest first
+func (s *TasksService) ListOverdueTasks(familyID, beforeDate string) ([]models.Task, error) {
+	query := `
+		SELECT id, family_id, assigned_to, title, description, task_type, status,
+			   priority, due_date, has_due_time, created_by, created_at, updated_at, completed_at
+		FROM tasks
+		WHERE family_id = ? AND status = 'pending' AND due_date IS NOT NULL AND SUBSTR(CAST(due_date AS TEXT), 1, 10) < ?
+		ORDER BY due_date ASC
+	`
+
+	rows, err := s.db.Query(query, familyID, beforeDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overdue tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := []models.Task{}
+	for rows.Next() {
+		task, scanErr := s.scanTask(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", scanErr)
+		}
+		tasks = append(tasks, *task)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task rows: %w", err)
+	}
+
+	return tasks, nil
+}
+
 // ListTasksForFamily returns all tasks for a family
 func (s *TasksService) ListTasksForFamily(familyID string) ([]models.Task, error) {
 	query := `
 		SELECT id, family_id, assigned_to, title, description, task_type, status,
-			   priority, due_date, created_by, created_at, updated_at, completed_at
+			   priority, due_date, has_due_time, created_by, created_at, updated_at, completed_at
 		FROM tasks
 		WHERE family_id = ?
 		ORDER BY created_at DESC
@@ -403,67 +503,161 @@ func (s *TasksService) ListTasksForFamily(familyID string) ([]models.Task, error
 	return tasks, nil
 }
 
-// Helper functions
+// ListTasksPage returns one page of a family's tasks, newest first
+func (s *TasksService) ListTasksPage(familyID string, request PageRequest) (*Page[models.Task], error) {
+	cursor, err := request.cursor()
+	if err != nil {
+		return nil, err
+	}
 
-func (s *TasksService) scanTask(scanner interface {
-	Scan(dest ...any) error
-}) (*models.Task, error) {
-	var task models.Task
-	var assignedTo, dueDate, completedAt sql.NullString
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE family_id = ?`, familyID).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count family tasks: %w", err)
+	}
 
-	err := scanner.Scan(
-		&task.ID, &task.FamilyID, &assignedTo, &task.Title, &task.Description,
-		&task.TaskType, &task.Status, &task.Priority, &dueDate,
-		&task.CreatedBy, &task.CreatedAt, &task.UpdatedAt, &completedAt,
-	)
+	condition, args := cursor.olderThan("created_at")
+	limit := request.limit()
+	query := `
+		SELECT id, family_id, assigned_to, title, description, task_type, status,
+			   priority, due_date, has_due_time, created_by, created_at, updated_at, completed_at
+		FROM tasks
+		WHERE family_id = ?` + condition + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`
+	rows, err := s.db.Query(query, append(append([]any{familyID}, args...), limit+1)...)
 	if err != nil {
+		return nil, fmt.Errorf("failed to query family tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		task, scanErr := s.scanTask(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", scanErr)
+		}
+		tasks = append(tasks, *task)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task rows: %w", err)
+	}
+
+	return newPage(tasks, limit, total, func(task models.Task) (time.Time, string) {
+		return task.CreatedAt, task.ID
+	}), nil
+}
+
+// Helper functions
+
+func (s *TasksService) scanTask(rows *sql.Rows) (*models.Task, error) {
+	var task models.Task
+	if err := database.ScanStruct(rows, &task); err != nil {
 		return nil, err
 	}
 
-	// Get family timezone for conversions
+	return s.toFamilyTime(&task)
+}
+
+// toFamilyTime converts a task's stored UTC times to the family timezone
+func (s *TasksService) toFamilyTime(task *models.Task) (*models.Task, error) {
 	familyTimezone, err := GetFamilyTimezone(s.db, task.FamilyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get family timezone for task conversion: %w", err)
 	}
 
-	// Handle nullable fields
-	if assignedTo.Valid {
-		task.AssignedTo = &assignedTo.String
-	}
-	if dueDate.Valid {
-		if parsed, parseErr := time.Parse(time.RFC3339, dueDate.String); parseErr == nil {
-			// Convert DueDate from UTC to family timezone
-			convertedDueDate, convErr := ConvertFromUTC(parsed, familyTimezone)
-			if convErr != nil {
-				return nil, fmt.Errorf("failed to convert due date from UTC: %w", convErr)
-			}
-			task.DueDate = &convertedDueDate
+	if task.DueDate != nil {
+		convertedDueDate, convErr := ConvertFromUTC(*task.DueDate, familyTimezone)
+		if convErr != nil {
+			return nil, fmt.Errorf("failed to convert due date from UTC: %w", convErr)
 		}
+		task.DueDate = &convertedDueDate
 	}
-	if completedAt.Valid {
-		if parsed, parseErr := time.Parse(time.RFC3339, completedAt.String); parseErr == nil {
-			// Convert CompletedAt from UTC to family timezone
-			convertedCompletedAt, convErr := ConvertFromUTC(parsed, familyTimezone)
-			if convErr != nil {
-				return nil, fmt.Errorf("failed to convert completed at from UTC: %w", convErr)
-			}
-			task.CompletedAt = &convertedCompletedAt
+	if task.CompletedAt != nil {
+		convertedCompletedAt, convErr := ConvertFromUTC(*task.CompletedAt, familyTimezone)
+		if convErr != nil {
+			return nil, fmt.Errorf("failed to convert completed at from UTC: %w", convErr)
 		}
+		task.CompletedAt = &convertedCompletedAt
 	}
 
-	// Convert CreatedAt from UTC to family timezone
 	task.CreatedAt, err = ConvertFromUTC(task.CreatedAt, familyTimezone)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert created at from UTC: %w", err)
 	}
 
-	return &task, nil
+	now, err := ConvertFromUTC(time.Now().UTC(), familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert now from UTC: %w", err)
+	}
+	task.SetDueState(now)
+
+	return task, nil
 }
 
-// GetExistingTasksInRange retrieves existing task dates in a date range for a schedule
+// withDueTime puts an HH:MM due time on due's day. A due date without a zone
+// is already in family time; one with a zone is moved into it first. The
+// result has no zone, for ConvertToUTC.
+func withDueTime(due time.Time, dueTime, familyTimezone string) (time.Time, error) {
+	clock, err := time.Parse("15:04", dueTime)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid due time %q: %w", dueTime, err)
+	}
+	if due.Location() != time.UTC {
+		if due, err = ConvertFromUTC(due.UTC(), familyTimezone); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return time.Date(due.Year(), due.Month(), due.Day(), clock.Hour(), clock.Minute(), 0, 0, time.UTC), nil
+}
+
+// updatedDue works out a task's new due date and whether it has a time from
+// its current one and an update. Moving a timed task to another day keeps its
+// time; an empty due_time makes it due any time on its day.
+func updatedDue(current *models.Task, req *models.UpdateTaskRequest, familyTimezone string) (time.Time, bool, error) {
+	var due time.Time
+	switch {
+	case req.DueDate != nil:
+		due = *req.DueDate
+	case current.DueDate != nil:
+		// Read back in family time; drop the zone so it stays there
+		local := *current.DueDate
+		due = time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), 0, 0, time.UTC)
+	default:
+		validator := validation.NewValidator()
+		validator.AddError("due_time", "due_time needs a due_date")
+		return time.Time{}, false, validator.ToError()
+	}
+
+	hasDueTime := current.HasDueTime
+	dueTime := ""
+	if hasDueTime && current.DueDate != nil {
+		dueTime = current.DueDate.Format("15:04")
+	}
+	if req.DueTime != nil {
+		dueTime = *req.DueTime
+		hasDueTime = dueTime != ""
+	}
+
+	switch {
+	case hasDueTime:
+		withTime, err := withDueTime(due, dueTime, familyTimezone)
+		return withTime, true, err
+	case req.DueDate == nil:
+		// The time was cleared, so the task is due from the start of its day
+		return time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, time.UTC), false, nil
+	}
+	return due, false, nil
+}
+
+// GetExistingTasksInRange retrieves existing task dates in a date range for a
+// schedule. Dates are the family's: a task due at 21:00 in Los Angeles counts
+// for that day, though it is stored as the next morning in UTC.
 func (s *TasksService) GetExistingTasksInRange(scheduleID string, startDate, endDate time.Time) ([]string, error) {
+	// Stored dates are UTC, so look a day either side and keep the tasks
+	// whose family date is in range
 	query := `
-		SELECT DISTINCT
+		SELECT family_id, due_date,
 			CASE
 				WHEN due_date IS NOT NULL THEN DATE(due_date)
 				ELSE DATE(created_at)
@@ -476,41 +670,61 @@ func (s *TasksService) GetExistingTasksInRange(scheduleID string, startDate, end
 		)
 	`
 
+	from, to := startDate.Format("2006-01-02"), endDate.Format("2006-01-02")
 	rows, err := s.db.Query(query, scheduleID,
-		startDate.Format("2006-01-02"), endDate.Format("2006-01-02"),
-		startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+		startDate.AddDate(0, 0, -1).Format("2006-01-02"), endDate.AddDate(0, 0, 1).Format("2006-01-02"),
+		from, to)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	var dates []string
+	seen := make(map[string]bool)
 	for rows.Next() {
-		var date string
-		if err := rows.Scan(&date); err != nil {
+		var familyID, date string
+		var dueDate sql.NullTime
+		if err := rows.Scan(&familyID, &dueDate, &date); err != nil {
 			return nil, err
 		}
+		if dueDate.Valid {
+			familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+			if err != nil {
+				return nil, err
+			}
+			local, err := ConvertFromUTC(dueDate.Time, familyTimezone)
+			if err != nil {
+				return nil, err
+			}
+			date = local.Format("2006-01-02")
+		}
+		if date < from || date > to || seen[date] {
+			continue
+		}
+		seen[date] = true
 		dates = append(dates, date)
 	}
 
-	return dates, nil
+	return dates, rows.Err()
 }
 
-// BulkCreateTasks creates multiple tasks in a single transaction
+// BulkCreateTasks creates multiple tasks in a single transaction. Due dates
+// without a zone are in family time, as for CreateTask.
 func (s *TasksService) BulkCreateTasks(familyID, createdBy string, tasks []BulkTaskRequest) error {
 	if len(tasks) == 0 {
 		return nil
 	}
 
-	return s.db.BeginCommit(func(tx *sql.Tx) error {
-		defer func() {
-			_ = tx.Rollback() // nolint:errcheck
-		}()
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to get family timezone for task creation: %w", err)
+	}
 
+	err = s.db.InTx(context.Background(), func(tx *sql.Tx) error {
 		query := `
 			INSERT INTO tasks (id, family_id, assigned_to, title, description, task_type,
-							  status, priority, due_date, created_by, schedule_id, created_at, updated_at)
-			VALUES (?, ?, ?, ?, ?, ?, 'pending', ?, ?, ?, ?, ?, ?)
+							  status, priority, due_date, has_due_time, created_by, schedule_id, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, 'pending', ?, ?, ?, ?, ?, ?, ?)
 		`
 
 		stmt, err := tx.Prepare(query)
@@ -531,14 +745,18 @@ func (s *TasksService) BulkCreateTasks(familyID, createdBy string, tasks []BulkT
 
 			var dueDateValue any
 			if task.DueDate != nil {
-				dueDateValue = task.DueDate.Format("2006-01-02 15:04:05")
+				dueDateUTC, err := ConvertToUTC(*task.DueDate, familyTimezone)
+				if err != nil {
+					return fmt.Errorf("failed to convert due date to UTC: %w", err)
+				}
+				dueDateValue = dueDateUTC.Format("2006-01-02 15:04:05")
 			} else {
 				dueDateValue = nil
 			}
 
 			_, err = stmt.Exec(
 				taskID, familyID, assignedToValue, task.Title, task.Description,
-				task.TaskType, task.Priority, dueDateValue,
+				task.TaskType, task.Priority, dueDateValue, task.DueDate != nil,
 				createdBy, task.ScheduleID, now, now,
 			)
 			if err != nil {
@@ -550,8 +768,13 @@ func (s *TasksService) BulkCreateTasks(familyID, createdBy string, tasks []BulkT
 			}
 		}
 
-		return tx.Commit()
+		return nil
 	})
+	if err != nil {
+		return err
+	}
+	s.publishChange(familyID)
+	return nil
 }
 
 // DeleteTasksBySchedule deletes all tasks for a given schedule
@@ -574,9 +797,9 @@ func (s *TasksService) DeleteTasksBySchedule(scheduleID string) (int64, error) {
 type BulkTaskRequest struct {
 	Title       string
 	Description string
-	TaskType    string
+	TaskType    models.TaskType
 	AssignedTo  *string
-	Priority    int
+	Priority    models.Priority
 	Points      int
 	DueDate     *time.Time
 	ScheduleID  string
@@ -593,5 +816,5 @@ func isUniqueConstraintViolation(err error) bool {
 }
 
 func generateTaskID() string {
-	return fmt.Sprintf("task_%d", time.Now().UTC().UnixNano())
+	return ids.New("task")
 }
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/eventbus"
+	"famstack/internal/models"
+	"famstack/internal/validation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTaskEnums(t *testing.T) {
+	status, err := models.ParseTaskStatus(" Pending ")
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusPending, status)
+
+	taskType, err := models.ParseTaskType("CHORE")
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskTypeChore, taskType)
+
+	eventType, err := models.ParseEventType("Reminder")
+	require.NoError(t, err)
+	assert.Equal(t, models.EventTypeReminder, eventType)
+
+	_, err = models.ParseTaskStatus("done")
+	assert.Error(t, err)
+	_, err = models.ParseEventType("task")
+	assert.Error(t, err)
+	_, err = models.ParsePriority(4)
+	assert.Error(t, err)
+}
+
+func TestTasksService_NormalizesEnums(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewTasksService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	task, err := service.CreateTask(familyID, memberID, &models.CreateTaskRequest{
+		Title:    "Feed the cat",
+		TaskType: "Chore ",
+		Priority: models.PriorityHigh,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskTypeChore, task.TaskType)
+	assert.Equal(t, models.TaskStatusPending, task.Status)
+
+	completed := models.TaskStatus("Completed")
+	task, err = service.UpdateTask(familyID, task.ID, &models.UpdateTaskRequest{Status: &completed})
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusCompleted, task.Status)
+
+	var validationErrs validation.ValidationErrors
+	_, err = service.CreateTask(familyID, memberID, &models.CreateTaskRequest{Title: "Nap", TaskType: "errand"})
+	require.ErrorAs(t, err, &validationErrs)
+	assert.Equal(t, "task_type", validationErrs[0].Field)
+
+	urgentPlus := models.Priority(7)
+	_, err = service.UpdateTask(familyID, task.ID, &models.UpdateTaskRequest{Priority: &urgentPlus})
+	require.ErrorAs(t, err, &validationErrs)
+	assert.Equal(t, "priority", validationErrs[0].Field)
+}
+
+func TestTasksService_AnnouncesAssignments(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewTasksService(db)
+	bus := eventbus.New()
+	service.SetEventBus(bus)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	var assigned []string
+	bus.Subscribe(func(event eventbus.Event) { assigned = append(assigned, event.SubjectID) }, eventbus.TopicTaskAssigned)
+
+	task, err := service.CreateTask(familyID, memberID, &models.CreateTaskRequest{Title: "Trash", TaskType: "chore", AssignedTo: &memberID})
+	require.NoError(t, err)
+	unassigned, err := service.CreateTask(familyID, memberID, &models.CreateTaskRequest{Title: "Dishes", TaskType: "chore"})
+	require.NoError(t, err)
+
+	// Saving the same assignee again is not a new assignment
+	_, err = service.UpdateTask(familyID, task.ID, &models.UpdateTaskRequest{AssignedTo: &memberID})
+	require.NoError(t, err)
+	_, err = service.UpdateTask(familyID, unassigned.ID, &models.UpdateTaskRequest{AssignedTo: &memberID})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{task.ID, unassigned.ID}, assigned)
+}
+
+func TestTaskDueState(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	now := time.Date(2026, 3, 10, 14, 0, 0, 0, chicago)
+	at := func(day, hour, minute int) *time.Time {
+		due := time.Date(2026, 3, day, hour, minute, 0, 0, chicago)
+		return &due
+	}
+
+	tasks := []models.Task{
+		{ID: "later_today", Status: models.TaskStatusPending, DueDate: at(10, 0, 0), Priority: models.PriorityUrgent},
+		{ID: "soon", Status: models.TaskStatusPending, DueDate: at(10, 15, 30), HasDueTime: true},
+		{ID: "missed", Status: models.TaskStatusPending, DueDate: at(10, 9, 0), HasDueTime: true},
+		{ID: "yesterday", Status: models.TaskStatusPending, DueDate: at(9, 0, 0)},
+		{ID: "done", Status: models.TaskStatusCompleted, DueDate: at(8, 0, 0)},
+		{ID: "undated", Status: models.TaskStatusPending, Priority: models.PriorityHigh},
+	}
+	for i := range tasks {
+		tasks[i].SetDueState(now)
+	}
+
+	state := map[string][2]bool{}
+	for _, task := range tasks {
+		state[task.ID] = [2]bool{task.DueSoon, task.Overdue}
+	}
+	assert.Equal(t, [2]bool{true, false}, state["later_today"], "due some time today")
+	assert.Equal(t, [2]bool{true, false}, state["soon"])
+	assert.Equal(t, [2]bool{false, true}, state["missed"], "its time has passed")
+	assert.Equal(t, [2]bool{false, true}, state["yesterday"])
+	assert.Equal(t, [2]bool{false, false}, state["done"])
+	assert.Equal(t, [2]bool{false, false}, state["undated"])
+
+	ids := func() []string {
+		var out []string
+		for _, task := range tasks {
+			out = append(out, task.ID)
+		}
+		return out
+	}
+	models.SortTasks(tasks, models.TaskSortDue)
+	assert.Equal(t, []string{"done", "yesterday", "missed", "soon", "later_today", "undated"}, ids())
+
+	models.SortTasks(tasks, models.TaskSortPriority)
+	assert.Equal(t, []string{"later_today", "undated", "done", "yesterday", "missed", "soon"}, ids())
+}
+
+func TestTasksService_DueTimes(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewTasksService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+	_, err := db.Exec(`UPDATE families SET timezone = ? WHERE id = ?`, "America/Chicago", familyID)
+	require.NoError(t, err)
+
+	day := time.Now().UTC().AddDate(0, 0, 2)
+	dueDate := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	dueTime := "15:30"
+	task, err := service.CreateTask(familyID, memberID, &models.CreateTaskRequest{
+		Title: "Dentist", TaskType: models.TaskTypeAppointment, DueDate: &dueDate, DueTime: &dueTime,
+	})
+	require.NoError(t, err)
+	assert.True(t, task.HasDueTime)
+	require.NotNil(t, task.DueDate)
+	assert.Equal(t, "15:30", task.DueDate.Format("15:04"))
+	assert.Equal(t, dueDate.Format("2006-01-02"), task.DueDate.Format("2006-01-02"))
+	assert.False(t, task.Overdue)
+
+	// Moving the day keeps the time; clearing the time keeps the day
+	nextDay := dueDate.AddDate(0, 0, 1)
+	task, err = service.UpdateTask(familyID, task.ID, &models.UpdateTaskRequest{DueDate: &nextDay})
+	require.NoError(t, err)
+	assert.Equal(t, "15:30", task.DueDate.Format("15:04"))
+	assert.Equal(t, nextDay.Format("2006-01-02"), task.DueDate.Format("2006-01-02"))
+
+	anyTime := ""
+	task, err = service.UpdateTask(familyID, task.ID, &models.UpdateTaskRequest{DueTime: &anyTime})
+	require.NoError(t, err)
+	assert.False(t, task.HasDueTime)
+	assert.Equal(t, "00:00", task.DueDate.Format("15:04"))
+
+	undated, err := service.CreateTask(familyID, memberID, &models.CreateTaskRequest{Title: "Someday", TaskType: models.TaskTypeTodo})
+	require.NoError(t, err)
+	_, err = service.UpdateTask(familyID, undated.ID, &models.UpdateTaskRequest{DueTime: &dueTime})
+	var validationErrs validation.ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
+	assert.Equal(t, "due_time", validationErrs[0].Field)
+}
+
+func TestTasksService_ListTasksPage(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewTasksService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+
+	for _, title := range []string{"Dishes", "Trash", "Laundry", "Lawn", "Bins"} {
+		_, err := service.CreateTask(familyID, memberID, &models.CreateTaskRequest{Title: title, TaskType: models.TaskTypeChore})
+		require.NoError(t, err)
+	}
+
+	seen := map[string]bool{}
+	request := PageRequest{Limit: 2}
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 5, "paging should end")
+		page, err := service.ListTasksPage(familyID, request)
+		require.NoError(t, err)
+		assert.Equal(t, 5, page.Total)
+		for _, task := range page.Items {
+			assert.False(t, seen[task.ID], "task %s listed twice", task.ID)
+			seen[task.ID] = true
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		request.Cursor = page.NextCursor
+	}
+	assert.Len(t, seen, 5)
+
+	_, err := service.ListTasksPage(familyID, PageRequest{Cursor: "not-a-cursor"})
+	assert.EqualError(t, err, "invalid cursor")
+}
+
+func TestTasksService_RollOverTasks(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewTasksService(db)
+	familyID, memberID := seedBulkEventFamily(t, db)
+	_, err := db.Exec(`INSERT INTO task_schedules (id, family_id, created_by, title, task_type, days_of_week, points, active) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"sched_dishes", familyID, memberID, "Dishes", "chore", `["monday","tuesday"]`, 1, true)
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	yesterday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+	dueTime := "18:00"
+	homework, err := service.CreateTask(familyID, memberID, &models.CreateTaskRequest{
+		Title: "Homework", TaskType: models.TaskTypeTodo, DueDate: &yesterday, DueTime: &dueTime,
+	})
+	require.NoError(t, err)
+	dishes := yesterday.Add(19 * time.Hour)
+	require.NoError(t, service.BulkCreateTasks(familyID, memberID, []BulkTaskRequest{
+		{Title: "Dishes", TaskType: models.TaskTypeChore, DueDate: &dishes, ScheduleID: "sched_dishes"},
+	}))
+
+	// Families keep overdue tasks unless they choose otherwise
+	changed, err := service.RollOverTasks(familyID, now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, changed)
+
+	_, err = db.Exec(`UPDATE families SET task_rollover = ? WHERE id = ?`, models.TaskRolloverSkipScheduled, familyID)
+	require.NoError(t, err)
+	changed, err = service.RollOverTasks(familyID, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed, "only the scheduled chore is skipped")
+
+	_, err = db.Exec(`UPDATE families SET task_rollover = ? WHERE id = ?`, models.TaskRolloverRollForward, familyID)
+	require.NoError(t, err)
+	changed, err = service.RollOverTasks(familyID, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+
+	task, err := service.GetTask(homework.ID)
+	require.NoError(t, err)
+	assert.Equal(t, now.Format("2006-01-02"), task.DueDate.Format("2006-01-02"))
+	assert.Equal(t, "18:00", task.DueDate.Format("15:04"))
+
+	// Nothing is left to roll over
+	changed, err = service.RollOverTasks(familyID, now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, changed)
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/models"
+)
+
+// routineSlotLength is how long a routine occupies on the timeline; schedules only carry a start time
+const routineSlotLength = 15 * time.Minute
+
+// TimelineService merges events, task due times and routine slots into a
+// per-member stream for a single day
+type TimelineService struct {
+	db        *database.Fascade
+	calendar  *CalendarService
+	tasks     *TasksService
+	schedules *SchedulesService
+	profiles  *ScheduleProfilesService
+	custody   *CustodyService
+}
+
+// NewTimelineService creates a new timeline service
+func NewTimelineService(db *database.Fascade, calendar *CalendarService, tasks *TasksService, schedules *SchedulesService, profiles *ScheduleProfilesService, custody *CustodyService) *TimelineService {
+	return &TimelineService{
+		db:        db,
+		calendar:  calendar,
+		tasks:     tasks,
+		schedules: schedules,
+		profiles:  profiles,
+		custody:   custody,
+	}
+}
+
+// BuildTimeline returns one lane per active member, plus an "unassigned" lane
+// for family-wide items, with every item's times in the family timezone.
+// Slot positions and overlap layout are left for the caller.
+func (s *TimelineService) BuildTimeline(familyID string, date time.Time) (*models.Timeline, error) {
+	familyTimezone, err := GetFamilyTimezone(s.db, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family timezone for timeline: %w", err)
+	}
+	loc, err := loadLocation(familyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid family timezone %s: %w", familyTimezone, err)
+	}
+
+	dateStr := date.Format("2006-01-02")
+	dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+
+	// The task columns double as the member list, including the unassigned bucket
+	tasksResp, err := s.tasks.ListTasksByFamily(familyID, dateStr, models.TaskSortCreated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks for timeline: %w", err)
+	}
+
+	lanes := make(map[string]*models.TimelineLane, len(tasksResp.TasksByMember))
+	for memberID, column := range tasksResp.TasksByMember {
+		lanes[memberID] = &models.TimelineLane{
+			MemberID:   memberID,
+			Name:       column.Member.Name,
+			MemberType: column.Member.MemberType,
+			Items:      []models.TimelineItem{},
+			AllDay:     []models.TimelineItem{},
+		}
+	}
+	// Members at the other household today keep their lane, marked away
+	isPresent, err := s.custody.PresenceChecker(familyID, dayStart, dayStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custody for timeline: %w", err)
+	}
+	for memberID, lane := range lanes {
+		if memberID != "unassigned" {
+			lane.Away = !isPresent(memberID, dayStart)
+		}
+	}
+
+	laneFor := func(memberID *string) *models.TimelineLane {
+		if memberID != nil {
+			if lane, ok := lanes[*memberID]; ok {
+				return lane
+			}
+		}
+		return lanes["unassigned"]
+	}
+
+	for memberID, column := range tasksResp.TasksByMember {
+		for _, task := range column.Tasks {
+			if task.DueDate == nil {
+				continue
+			}
+			due := task.DueDate.In(loc)
+			item := models.TimelineItem{
+				ID:       "task:" + task.ID,
+				Kind:     models.TimelineItemTask,
+				SourceID: task.ID,
+				Title:    task.Title,
+				Start:    due,
+				End:      due.Add(routineSlotLength),
+				Status:   string(task.Status),
+			}
+			// Tasks due at midnight have a date but no time
+			if due.Hour() == 0 && due.Minute() == 0 {
+				item.AllDay = true
+			}
+			addTimelineItem(lanes[memberID], item)
+		}
+	}
+
+	// The timeline is shared by the family and its display, so it shows
+	// events the way everyone may see them
+	events, err := s.calendar.GetUnifiedCalendarEventsFor(nil, familyID, date, date.Add(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events for timeline: %w", err)
+	}
+	for _, event := range events {
+		item := models.TimelineItem{
+			ID:       "event:" + event.ID,
+			Kind:     models.TimelineItemEvent,
+			SourceID: event.ID,
+			Title:    event.Title,
+			Start:    event.StartTime.In(loc),
+			End:      event.EndTime.In(loc),
+			AllDay:   event.AllDay,
+			Color:    event.Color,
+			Status:   event.Status,
+		}
+
+		// An event shows on every attendee's lane, falling back to its creator
+		memberIDs := make([]*string, 0, len(event.Attendees)+1)
+		for i := range event.Attendees {
+			memberIDs = append(memberIDs, &event.Attendees[i].ID)
+		}
+		if len(memberIDs) == 0 {
+			memberIDs = append(memberIDs, event.CreatedBy)
+		}
+		seen := make(map[*models.TimelineLane]bool)
+		for _, memberID := range memberIDs {
+			lane := laneFor(memberID)
+			if lane == nil || seen[lane] {
+				continue
+			}
+			seen[lane] = true
+			addTimelineItem(lane, item)
+		}
+	}
+
+	routines, err := s.routinesOn(familyID, dayStart)
+	if err != nil {
+		return nil, err
+	}
+	for _, routine := range routines {
+		lane := laneFor(routine.AssignedTo)
+		if lane == nil || lane.Away || laneHasTask(lane, routine.Title) {
+			// Either the member isn't here today, or the schedule already
+			// generated today's task, which is on the timeline
+			continue
+		}
+
+		start, err := time.ParseInLocation("15:04", *routine.TimeOfDay, loc)
+		if err != nil {
+			continue
+		}
+		slotStart := time.Date(dayStart.Year(), dayStart.Month(), dayStart.Day(), start.Hour(), start.Minute(), 0, 0, loc)
+		addTimelineItem(lane, models.TimelineItem{
+			ID:       "routine:" + routine.ID,
+			Kind:     models.TimelineItemRoutine,
+			SourceID: routine.ID,
+			Title:    routine.Title,
+			Start:    slotStart,
+			End:      slotStart.Add(routineSlotLength),
+		})
+	}
+
+	timeline := &models.Timeline{
+		Date:     dateStr,
+		Timezone: familyTimezone,
+		Lanes:    make([]models.TimelineLane, 0, len(lanes)),
+	}
+	for _, lane := range lanes {
+		sortTimelineItems(lane.Items)
+		sortTimelineItems(lane.AllDay)
+		timeline.TotalItems += len(lane.Items) + len(lane.AllDay)
+		timeline.Lanes = append(timeline.Lanes, *lane)
+	}
+
+	// Members in name order, with the family-wide lane last
+	sort.Slice(timeline.Lanes, func(i, j int) bool {
+		if (timeline.Lanes[i].MemberID == "unassigned") != (timeline.Lanes[j].MemberID == "unassigned") {
+			return timeline.Lanes[j].MemberID == "unassigned"
+		}
+		return timeline.Lanes[i].Name < timeline.Lanes[j].Name
+	})
+
+	return timeline, nil
+}
+
+// routinesOn returns the active, timed schedules that run on the given day
+func (s *TimelineService) routinesOn(familyID string, day time.Time) ([]models.TaskSchedule, error) {
+	schedules, err := s.schedules.ListSchedules(familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedules for timeline: %w", err)
+	}
+	profiles, err := s.profiles.ListProfiles(familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule profiles for timeline: %w", err)
+	}
+
+	weekday := strings.ToLower(day.Weekday().String())
+	var routines []models.TaskSchedule
+	for _, schedule := range schedules {
+		if !schedule.Active || schedule.TimeOfDay == nil || schedule.DaysOfWeek == nil {
+			continue
+		}
+
+		var daysOfWeek []string
+		if err := json.Unmarshal([]byte(*schedule.DaysOfWeek), &daysOfWeek); err != nil {
+			continue
+		}
+		runsToday := false
+		for _, d := range daysOfWeek {
+			if strings.ToLower(d) == weekday {
+				runsToday = true
+				break
+			}
+		}
+		if !runsToday || !models.ScheduleRunsOn(profiles, schedule.ID, day) {
+			continue
+		}
+
+		routines = append(routines, schedule)
+	}
+
+	return routines, nil
+}
+
+func addTimelineItem(lane *models.TimelineLane, item models.TimelineItem) {
+	if lane == nil {
+		return
+	}
+	if item.AllDay {
+		lane.AllDay = append(lane.AllDay, item)
+		return
+	}
+	lane.Items = append(lane.Items, item)
+}
+
+func laneHasTask(lane *models.TimelineLane, title string) bool {
+	for _, items := range [][]models.TimelineItem{lane.Items, lane.AllDay} {
+		for _, item := range items {
+			if item.Kind == models.TimelineItemTask && item.Title == title {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sortTimelineItems orders items by start time, longest first on ties
+func sortTimelineItems(items []models.TimelineItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if !items[i].Start.Equal(items[j].Start) {
+			return items[i].Start.Before(items[j].Start)
+		}
+		return items[i].End.After(items[j].End)
+	})
+}
@@ -11,7 +11,7 @@ func ConvertToUTC(t time.Time, timezone string) (time.Time, error) {
 		return t.UTC(), nil
 	}
 
-	loc, err := time.LoadLocation(timezone)
+	loc, err := loadLocation(timezone)
 	if err != nil {
 		return time.Time{}, fmt.Errorf("invalid timezone %s: %w", timezone, err)
 	}
@@ -33,7 +33,7 @@ func ConvertFromUTC(utcTime time.Time, timezone string) (time.Time, error) {
 		return utcTime.UTC(), nil
 	}
 
-	loc, err := time.LoadLocation(timezone)
+	loc, err := loadLocation(timezone)
 	if err != nil {
 		return time.Time{}, fmt.Errorf("invalid timezone %s: %w", timezone, err)
 	}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"famstack/internal/database"
+	"famstack/internal/models"
+	"famstack/internal/weather"
+)
+
+// WeatherForecastMaxAge is how old a family's forecast gets before it is
+// fetched again
+const WeatherForecastMaxAge = 3 * time.Hour
+
+// weatherHistoryDays is how long forecasts for past days are kept, so the
+// calendar still shows last week's weather
+const weatherHistoryDays = 7
+
+// WeatherService keeps the forecasts shown on families' calendar days
+type WeatherService struct {
+	db *database.Fascade
+}
+
+// NewWeatherService creates a new weather service
+func NewWeatherService(db *database.Fascade) *WeatherService {
+	return &WeatherService{db: db}
+}
+
+// FamiliesDue returns the families with a location whose forecast is
+// missing or older than WeatherForecastMaxAge as of now
+func (s *WeatherService) FamiliesDue(now time.Time) ([]models.Family, error) {
+	rows, err := s.db.Query(`
+		SELECT f.id, f.name, f.timezone, f.latitude, f.longitude
+		FROM families f
+		WHERE f.latitude IS NOT NULL AND f.longitude IS NOT NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM weather_forecasts w WHERE w.family_id = f.id AND w.fetched_at > ?
+		  )
+	`, now.UTC().Add(-WeatherForecastMaxAge))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list families due a forecast: %w", err)
+	}
+	defer rows.Close()
+
+	var families []models.Family
+	for rows.Next() {
+		var family models.Family
+		if err := rows.Scan(&family.ID, &family.Name, &family.Timezone, &family.Latitude, &family.Longitude); err != nil {
+			return nil, fmt.Errorf("failed to scan family: %w", err)
+		}
+		families = append(families, family)
+	}
+	return families, rows.Err()
+}
+
+// StoreForecast saves a family's forecast, replacing what was forecast for
+// the same days, and drops forecasts for days long past
+func (s *WeatherService) StoreForecast(familyID string, days []models.DayForecast) error {
+	if len(days) == 0 {
+		return nil
+	}
+	return s.db.InTx(context.Background(), func(tx *sql.Tx) error {
+		for _, day := range days {
+			if _, err := tx.Exec(`
+				INSERT INTO weather_forecasts (family_id, forecast_date, weather_code, temperature_max,
+											   temperature_min, precipitation_chance, fetched_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (family_id, forecast_date) DO UPDATE SET
+					weather_code = excluded.weather_code, temperature_max = excluded.temperature_max,
+					temperature_min = excluded.temperature_min, precipitation_chance = excluded.precipitation_chance,
+					fetched_at = excluded.fetched_at
+			`, familyID, day.Date, day.WeatherCode, day.TemperatureMax, day.TemperatureMin,
+				day.PrecipitationChance, day.FetchedAt.UTC()); err != nil {
+				return fmt.Errorf("failed to store forecast for %s: %w", day.Date, err)
+			}
+		}
+
+		// Dates sort as text; the forecast starts today in the family's zone
+		first, err := time.Parse("2006-01-02", days[0].Date)
+		if err != nil {
+			return fmt.Errorf("invalid forecast date %q: %w", days[0].Date, err)
+		}
+		cutoff := first.AddDate(0, 0, -weatherHistoryDays).Format("2006-01-02")
+		if _, err := tx.Exec(`DELETE FROM weather_forecasts WHERE family_id = ? AND forecast_date < ?`, familyID, cutoff); err != nil {
+			return fmt.Errorf("failed to drop old forecasts: %w", err)
+		}
+		return nil
+	})
+}
+
+// Forecasts returns the family's forecasts for the dates from to to,
+// inclusive, by date. Days without a forecast are missing.
+func (s *WeatherService) Forecasts(familyID, from, to string) (map[string]models.DayForecast, error) {
+	days, err := database.QueryAll[models.DayForecast](s.db, `
+		SELECT forecast_date, weather_code, temperature_max, temperature_min, precipitation_chance, fetched_at
+		FROM weather_forecasts
+		WHERE family_id = ? AND forecast_date >= ? AND forecast_date <= ?
+	`, familyID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weather forecasts: %w", err)
+	}
+
+	forecasts := make(map[string]models.DayForecast, len(days))
+	for _, day := range days {
+		day.Summary, day.Icon = weather.Describe(day.WeatherCode)
+		forecasts[day.Date] = day
+	}
+	return forecasts, nil
+}
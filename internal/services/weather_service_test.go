@@ -0,0 +1,65 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeatherForecasts(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewWeatherService(db)
+	families := NewFamiliesService(db)
+	familyID, _ := seedBulkEventFamily(t, db)
+	now := time.Now()
+
+	due, err := service.FamiliesDue(now)
+	require.NoError(t, err)
+	assert.Empty(t, due, "families without a location get no forecast")
+
+	latitude, longitude := 45.52, -122.68
+	name := " Portland "
+	family, err := families.UpdateFamily(familyID, &models.UpdateFamilyRequest{Latitude: &latitude, LocationName: &name})
+	assert.ErrorContains(t, err, "invalid location")
+	assert.Nil(t, family)
+
+	family, err = families.UpdateFamily(familyID, &models.UpdateFamilyRequest{Latitude: &latitude, Longitude: &longitude, LocationName: &name})
+	require.NoError(t, err)
+	require.NotNil(t, family.LocationName)
+	assert.Equal(t, "Portland", *family.LocationName)
+
+	due, err = service.FamiliesDue(now)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, latitude, *due[0].Latitude)
+
+	require.NoError(t, service.StoreForecast(familyID, []models.DayForecast{
+		{Date: "2025-08-20", WeatherCode: 0, TemperatureMax: 30, TemperatureMin: 18, FetchedAt: now.Add(-24 * time.Hour)},
+	}))
+	require.NoError(t, service.StoreForecast(familyID, []models.DayForecast{
+		{Date: "2025-09-01", WeatherCode: 3, TemperatureMax: 20, TemperatureMin: 12, FetchedAt: now},
+		{Date: "2025-09-02", WeatherCode: 63, TemperatureMax: 16.5, TemperatureMin: 11, PrecipitationChance: 90, FetchedAt: now},
+	}))
+
+	due, err = service.FamiliesDue(now)
+	require.NoError(t, err)
+	assert.Empty(t, due, "a fresh forecast isn't fetched again")
+
+	forecasts, err := service.Forecasts(familyID, "2025-08-01", "2025-09-30")
+	require.NoError(t, err)
+	require.Len(t, forecasts, 2, "days more than a week before the forecast are dropped")
+	rain := forecasts["2025-09-02"]
+	assert.Equal(t, "Rain", rain.Summary)
+	assert.Equal(t, 16.5, rain.TemperatureMax)
+	assert.Equal(t, 90, rain.PrecipitationChance)
+
+	_, err = families.UpdateFamily(familyID, &models.UpdateFamilyRequest{ClearLocation: true})
+	require.NoError(t, err)
+	forecasts, err = service.Forecasts(familyID, "2025-08-01", "2025-09-30")
+	require.NoError(t, err)
+	assert.Empty(t, forecasts, "forecasts go with the location")
+}
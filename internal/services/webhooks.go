@@ -0,0 +1,198 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Webhook events an IFTTT or Zapier integration can be sent
+const (
+	WebhookTaskCreated   = "task.created"
+	WebhookTaskCompleted = "task.completed"
+	WebhookEventCreated  = "event.created"
+)
+
+// WebhookEvents lists the events outgoing webhooks can subscribe to
+var WebhookEvents = []string{WebhookTaskCreated, WebhookTaskCompleted, WebhookEventCreated}
+
+// Names of an integration's webhook credentials
+const (
+	webhookCredentialType = "webhook_secret"
+	webhookSigningSecret  = "signing_secret" // signs outgoing deliveries
+	webhookInboundToken   = "inbound_token"  // authenticates incoming triggers
+)
+
+// WebhookSettings are the settings of an IFTTT or Zapier integration: where
+// to deliver and which events
+type WebhookSettings struct {
+	URL    string   `json:"webhook_url"`
+	Events []string `json:"events"`
+}
+
+// WebhookSecrets are an integration's signing secret, which outgoing
+// deliveries are signed with, and the token that lets triggers in
+type WebhookSecrets struct {
+	SigningSecret string `json:"signing_secret"`
+	InboundToken  string `json:"inbound_token"`
+}
+
+// IsWebhookProvider reports whether the provider talks to famstack through
+// webhooks
+func IsWebhookProvider(provider Provider) bool {
+	return provider == ProviderIFTTT || provider == ProviderZapier
+}
+
+// WebhookSettings reads the integration's webhook settings
+func (i *Integration) WebhookSettings() WebhookSettings {
+	var settings WebhookSettings
+	if i.Settings != "" {
+		_ = json.Unmarshal([]byte(i.Settings), &settings) // nolint:errcheck // settings of other shapes have none
+	}
+	return settings
+}
+
+// WebhookTargets returns the family's enabled IFTTT and Zapier integrations
+// that deliver the event
+func (s *IntegrationsService) WebhookTargets(familyID, event string) ([]Integration, error) {
+	integrationType := TypeAutomation
+	integrations, err := s.ListIntegrations(familyID, &ListIntegrationsQuery{IntegrationType: &integrationType})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook integrations: %w", err)
+	}
+
+	var targets []Integration
+	for _, integration := range integrations {
+		if !integration.Enabled || !IsWebhookProvider(integration.Provider) {
+			continue
+		}
+		settings := integration.WebhookSettings()
+		if settings.URL != "" && slices.Contains(settings.Events, event) {
+			targets = append(targets, integration)
+		}
+	}
+	return targets, nil
+}
+
+// WebhookSecrets returns the integration's webhook secrets, creating them
+// the first time they are asked for
+func (s *IntegrationsService) WebhookSecrets(integrationID string) (*WebhookSecrets, error) {
+	signingSecret, err := s.webhookCredential(integrationID, webhookSigningSecret)
+	if err != nil {
+		return nil, err
+	}
+	inboundToken, err := s.webhookCredential(integrationID, webhookInboundToken)
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookSecrets{SigningSecret: signingSecret, InboundToken: inboundToken}, nil
+}
+
+// RotateWebhookSecrets replaces the integration's webhook secrets, for when
+// they leaked. Receivers must be given the new signing secret, and triggers
+// the new inbound URL.
+func (s *IntegrationsService) RotateWebhookSecrets(integrationID string) (*WebhookSecrets, error) {
+	_, err := s.db.Exec(`DELETE FROM integration_api_credentials WHERE integration_id = ? AND credential_type = ?`,
+		integrationID, webhookCredentialType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove webhook secrets: %w", err)
+	}
+	return s.WebhookSecrets(integrationID)
+}
+
+// VerifyInboundToken returns the integration a trigger is for when token is
+// its inbound token
+func (s *IntegrationsService) VerifyInboundToken(integrationID, token string) (*Integration, error) {
+	integration, err := s.GetIntegration(integrationID)
+	if err != nil || !integration.Enabled || !IsWebhookProvider(integration.Provider) {
+		return nil, errors.New("integration not found")
+	}
+	secrets, err := s.WebhookSecrets(integrationID)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(secrets.InboundToken)) != 1 {
+		return nil, errors.New("invalid webhook token")
+	}
+	return integration, nil
+}
+
+// webhookCredential returns one of the integration's webhook secrets,
+// generating and storing it when there is none yet
+func (s *IntegrationsService) webhookCredential(integrationID, name string) (string, error) {
+	var encrypted string
+	err := s.db.QueryRow(`
+		SELECT credential_value FROM integration_api_credentials
+		WHERE integration_id = ? AND credential_type = ? AND credential_name = ?
+		ORDER BY created_at LIMIT 1
+	`, integrationID, webhookCredentialType, name).Scan(&encrypted)
+	if err == nil {
+		value, err := s.encryptionSvc.Decrypt(encrypted)
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt %s: %w", name, err)
+		}
+		return value, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("failed to get %s: %w", name, err)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate %s: %w", name, err)
+	}
+	value := hex.EncodeToString(secret)
+	if encrypted, err = s.encryptionSvc.Encrypt(value); err != nil {
+		return "", fmt.Errorf("failed to encrypt %s: %w", name, err)
+	}
+	now := time.Now().UTC()
+	_, err = s.db.Exec(`
+		INSERT INTO integration_api_credentials
+		(id, integration_id, credential_type, credential_name, credential_value, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, generateID(), integrationID, webhookCredentialType, name, encrypted, now, now)
+	if err != nil {
+		return "", fmt.Errorf("failed to store %s: %w", name, err)
+	}
+	return value, nil
+}
+
+// RecordWebhook adds a delivery or trigger to the integration's sync
+// history; deliveryErr is nil when it went through
+func (s *IntegrationsService) RecordWebhook(integrationID string, startedAt time.Time, deliveryErr error) error {
+	status, items, message := "success", 1, ""
+	if deliveryErr != nil {
+		status, items, message = "error", 0, deliveryErr.Error()
+	}
+	now := time.Now().UTC()
+	_, err := s.db.Exec(`
+		INSERT INTO integration_sync_history
+		(id, integration_id, sync_type, status, items_synced, error_message, started_at, completed_at, created_at)
+		VALUES (?, ?, 'webhook', ?, ?, ?, ?, ?, ?)
+	`, generateID(), integrationID, status, items, message, startedAt.UTC(), now, now)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook: %w", err)
+	}
+	return nil
+}
+
+// SignWebhook signs a delivery's body for the X-FamStack-Signature header.
+// The timestamp, sent as X-FamStack-Timestamp, is signed with the body so a
+// captured delivery can't be replayed later: receivers compute
+// hex(HMAC-SHA256(secret, timestamp + "." + body)) and compare.
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
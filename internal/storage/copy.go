@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// CopyOptions controls Copy
+type CopyOptions struct {
+	DryRun       bool // report what would be copied without copying
+	DeleteSource bool // remove each object from the source once it is copied
+	// Progress, when set, is called after each object with the action taken
+	Progress func(key, action string)
+}
+
+// CopyResult counts what Copy did
+type CopyResult struct {
+	Copied  int   `json:"copied"`
+	Skipped int   `json:"skipped"` // already in the destination with the same size
+	Failed  int   `json:"failed"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// Copy streams every object in src to dst under the same key. Objects that
+// dst already holds at the same size are skipped, so an interrupted copy can
+// simply be run again. A failed object is counted and the copy goes on; the
+// first failure is returned once every object has been tried.
+func Copy(ctx context.Context, src, dst Storage, opts CopyOptions) (*CopyResult, error) {
+	result := &CopyResult{}
+	var firstErr error
+	report := func(key, action string) {
+		if opts.Progress != nil {
+			opts.Progress(key, action)
+		}
+	}
+
+	err := src.Walk(ctx, func(info ObjectInfo) error {
+		if existing, err := dst.Stat(ctx, info.Key); err == nil && existing.Size == info.Size {
+			result.Skipped++
+			report(info.Key, "skipped")
+			return nil
+		}
+		if opts.DryRun {
+			result.Copied++
+			result.Bytes += info.Size
+			report(info.Key, "would copy")
+			return nil
+		}
+
+		if err := copyObject(ctx, src, dst, info.Key); err != nil {
+			result.Failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+			report(info.Key, "failed: "+err.Error())
+			return nil
+		}
+		result.Copied++
+		result.Bytes += info.Size
+		report(info.Key, "copied")
+
+		if opts.DeleteSource {
+			if err := src.Delete(ctx, info.Key); err != nil {
+				result.Failed++
+				if firstErr == nil {
+					firstErr = err
+				}
+				report(info.Key, "failed to delete source: "+err.Error())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to list %s: %w", src.Name(), err)
+	}
+	return result, firstErr
+}
+
+func copyObject(ctx context.Context, src, dst Storage, key string) error {
+	body, info, err := src.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	defer body.Close()
+
+	if err := dst.Put(ctx, key, body, info.Size, info.ContentType); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Package storage keeps uploaded files (attachments, avatars, photos) in a
+// pluggable backend: a directory on local disk or an S3-compatible bucket
+// such as MinIO. The backend is picked by the storage section of the config
+// file. Which files a family owns, and how much space they use, is recorded
+// separately by services.StorageService; a backend only maps keys to bytes.
+//
+// Keys are slash-separated paths such as "fam_1/attachment/3f2a.pdf" and are
+// the same in every backend, so files can be copied from one backend to
+// another (see Copy) without touching the database.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"famstack/internal/config"
+)
+
+// Backend names used in the config file
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// MaxKeyLength matches the storage_key column
+const MaxKeyLength = 500
+
+// ErrNotFound is returned for a key that has no object
+var ErrNotFound = errors.New("storage object not found")
+
+// ErrPresignDisabled is returned by a Presigner configured not to presign
+var ErrPresignDisabled = errors.New("presigned URLs are disabled")
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// Storage is a backend for file contents. Put and Get stream, so a file is
+// never held in memory.
+type Storage interface {
+	// Name identifies the backend in logs and command output
+	Name() string
+	// Put stores size bytes read from body, replacing any object at key. It
+	// fails, storing nothing, if body does not hold exactly size bytes.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get opens an object; the caller closes it
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	// Stat describes an object without reading it
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// Delete removes an object. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// Walk calls fn for every object
+	Walk(ctx context.Context, fn func(ObjectInfo) error) error
+}
+
+// Presigner is implemented by backends that can hand clients a temporary URL
+// to download an object directly, so the file doesn't pass through the server
+type Presigner interface {
+	PresignGet(ctx context.Context, key string) (string, error)
+}
+
+// New creates the backend named in the config
+func New(cfg config.StorageConfig) (Storage, error) {
+	return NewBackend(cfg.Backend, cfg)
+}
+
+// NewBackend creates the named backend from its section of cfg, whichever
+// backend cfg selects. The migration command uses it to open both ends.
+func NewBackend(name string, cfg config.StorageConfig) (Storage, error) {
+	switch name {
+	case BackendLocal, "":
+		return NewLocal(cfg.Local.Path)
+	case BackendS3:
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q (expected %s or %s)", name, BackendLocal, BackendS3)
+	}
+}
+
+var keySegmentPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// ValidateKey checks that a key is a relative path of plain segments, so it
+// can't escape a local root or be read differently by another backend
+func ValidateKey(key string) error {
+	if key == "" || len(key) > MaxKeyLength {
+		return fmt.Errorf("storage key must be 1 to %d characters", MaxKeyLength)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." || strings.HasPrefix(segment, ".") || !keySegmentPattern.MatchString(segment) {
+			return fmt.Errorf("invalid storage key %q", key)
+		}
+	}
+	return nil
+}
+
+// sizeReader fails a Put whose body is longer or shorter than declared
+type sizeReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (s *sizeReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	s.remaining -= int64(n)
+	if s.remaining < 0 {
+		return n, fmt.Errorf("body is longer than the declared size")
+	}
+	if err == io.EOF && s.remaining > 0 {
+		return n, fmt.Errorf("body is %d bytes shorter than the declared size", s.remaining)
+	}
+	return n, err
+}
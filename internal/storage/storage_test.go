@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateKey(t *testing.T) {
+	valid := []string{"fam_1/attachment/3f2a.pdf", "a", "fam-1/photo/x_y.JPG"}
+	for _, key := range valid {
+		assert.NoError(t, ValidateKey(key), key)
+	}
+
+	invalid := []string{"", "/abs", "a//b", "../escape", "a/../b", ".hidden", "a/.upload-123", "has space", "a\\b", strings.Repeat("a", MaxKeyLength+1)}
+	for _, key := range invalid {
+		assert.Error(t, ValidateKey(key), key)
+	}
+}
+
+func TestLocal_PutGetStatDelete(t *testing.T) {
+	ctx := context.Background()
+	local, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	content := []byte("%PDF-1.4 report")
+	require.NoError(t, local.Put(ctx, "fam_1/attachment/report.pdf", bytes.NewReader(content), int64(len(content)), "application/pdf"))
+
+	body, info, err := local.Get(ctx, "fam_1/attachment/report.pdf")
+	require.NoError(t, err)
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	body.Close()
+	assert.Equal(t, content, data)
+	assert.Equal(t, int64(len(content)), info.Size)
+	assert.Equal(t, "application/pdf", info.ContentType)
+
+	stat, err := local.Stat(ctx, "fam_1/attachment/report.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), stat.Size)
+
+	require.NoError(t, local.Delete(ctx, "fam_1/attachment/report.pdf"))
+	_, err = local.Stat(ctx, "fam_1/attachment/report.pdf")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, _, err = local.Get(ctx, "fam_1/attachment/report.pdf")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, local.Delete(ctx, "fam_1/attachment/report.pdf"), "deleting a missing key is not an error")
+}
+
+func TestLocal_PutRejectsSizeMismatch(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	local, err := NewLocal(dir)
+	require.NoError(t, err)
+
+	assert.Error(t, local.Put(ctx, "fam_1/attachment/short.txt", strings.NewReader("abc"), 10, "text/plain"))
+	assert.Error(t, local.Put(ctx, "fam_1/attachment/long.txt", strings.NewReader("abcdef"), 3, "text/plain"))
+	assert.Error(t, local.Put(ctx, "../outside.txt", strings.NewReader("abc"), 3, "text/plain"))
+
+	// Nothing is left behind, not even the temporary files
+	entries, err := os.ReadDir(filepath.Join(dir, "fam_1", "attachment"))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestLocal_WalkSkipsPartialUploads(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	local, err := NewLocal(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, local.Put(ctx, "fam_1/photo/a.jpg", strings.NewReader("aa"), 2, "image/jpeg"))
+	require.NoError(t, local.Put(ctx, "fam_2/avatar/b.png", strings.NewReader("bbb"), 3, "image/png"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fam_1", "photo", tempPrefix+"123"), []byte("partial"), 0o600))
+
+	sizes := map[string]int64{}
+	require.NoError(t, local.Walk(ctx, func(info ObjectInfo) error {
+		sizes[info.Key] = info.Size
+		return nil
+	}))
+	assert.Equal(t, map[string]int64{"fam_1/photo/a.jpg": 2, "fam_2/avatar/b.png": 3}, sizes)
+}
+
+func TestCopy_BetweenLocalBackends(t *testing.T) {
+	ctx := context.Background()
+	src, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+	dst, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, src.Put(ctx, "fam_1/attachment/a.txt", strings.NewReader("alpha"), 5, "text/plain"))
+	require.NoError(t, src.Put(ctx, "fam_1/attachment/b.txt", strings.NewReader("bravo!"), 6, "text/plain"))
+	require.NoError(t, dst.Put(ctx, "fam_1/attachment/b.txt", strings.NewReader("BRAVO!"), 6, "text/plain"))
+
+	// A dry run copies nothing
+	result, err := Copy(ctx, src, dst, CopyOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, &CopyResult{Copied: 1, Skipped: 1, Bytes: 5}, result)
+	_, err = dst.Stat(ctx, "fam_1/attachment/a.txt")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	var actions []string
+	result, err = Copy(ctx, src, dst, CopyOptions{
+		DeleteSource: true,
+		Progress:     func(key, action string) { actions = append(actions, key+" "+action) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &CopyResult{Copied: 1, Skipped: 1, Bytes: 5}, result)
+	assert.Equal(t, []string{"fam_1/attachment/a.txt copied", "fam_1/attachment/b.txt skipped"}, actions)
+
+	body, _, err := dst.Get(ctx, "fam_1/attachment/a.txt")
+	require.NoError(t, err)
+	data, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "alpha", string(data))
+
+	// Copied objects leave the source; skipped ones stay put
+	_, err = src.Stat(ctx, "fam_1/attachment/a.txt")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = src.Stat(ctx, "fam_1/attachment/b.txt")
+	assert.NoError(t, err)
+
+	// Running it again finds nothing left to do
+	result, err = Copy(ctx, src, dst, CopyOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, &CopyResult{Skipped: 1}, result)
+}
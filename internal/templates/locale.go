@@ -0,0 +1,65 @@
+package templates
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// localeFormat holds the date and time wording for one locale. Go's time
+// layouts only produce English names, so the names are listed here.
+type localeFormat struct {
+	weekdays  [7]string  // indexed by time.Weekday, Sunday first
+	months    [12]string // January first
+	longDate  string     // {weekday}, {day} and {month} placeholders
+	shortDate string
+	dayMonth  string
+	clock     string // time.Format layout
+}
+
+var localeFormats = map[string]localeFormat{
+	"en": {
+		weekdays:  [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		months:    [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		longDate:  "{weekday}, {month} {day}",
+		shortDate: "{weekday} {day}",
+		dayMonth:  "{month} {day}",
+		clock:     "3:04 PM",
+	},
+	"es": {
+		weekdays:  [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		months:    [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		longDate:  "{weekday} {day} de {month}",
+		shortDate: "{weekday} {day}",
+		dayMonth:  "{day} de {month}",
+		clock:     "15:04",
+	},
+}
+
+// funcs returns the template functions bound to this locale
+func (f localeFormat) funcs() map[string]any {
+	return map[string]any{
+		"weekday":    func(t time.Time) string { return f.weekdays[t.Weekday()] },
+		"longDate":   func(t time.Time) string { return f.date(f.longDate, t) },
+		"shortDate":  func(t time.Time) string { return f.date(f.shortDate, t) },
+		"clock":      func(t time.Time) string { return t.Format(f.clock) },
+		"join":       strings.Join,
+		"jsonEscape": jsonEscape,
+	}
+}
+
+func (f localeFormat) date(pattern string, t time.Time) string {
+	return strings.NewReplacer(
+		"{weekday}", f.weekdays[t.Weekday()],
+		"{month}", f.months[t.Month()-1],
+		"{day}", strconv.Itoa(t.Day()),
+	).Replace(pattern)
+}
+
+// jsonEscape escapes s for use between the quotes of a JSON string, so
+// Slack templates can build one text field from several values
+func jsonEscape(s string) string {
+	encoded, _ := json.Marshal(s) // marshaling a string cannot fail
+	return string(encoded[1 : len(encoded)-1])
+}
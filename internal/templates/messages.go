@@ -0,0 +1,137 @@
+package templates
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Messages live at messages/<locale>.json, each a map from message ID to a
+// fmt format. A locale may leave messages out; they come from DefaultLocale.
+
+//go:embed messages
+var messageFiles embed.FS
+
+// catalogs holds every locale's messages, parsed once. They are embedded at
+// build time, so an error here is a bug, not a runtime condition.
+var catalogs = sync.OnceValue(func() map[string]map[string]string {
+	loaded, err := loadCatalogs(messageFiles, "messages")
+	if err != nil {
+		panic(err)
+	}
+	return loaded
+})
+
+func loadCatalogs(files fs.FS, root string) (map[string]map[string]string, error) {
+	paths, err := fs.Glob(files, path.Join(root, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := make(map[string]map[string]string)
+	for _, file := range paths {
+		locale := strings.TrimSuffix(path.Base(file), ".json")
+		if _, known := localeFormats[locale]; !known {
+			return nil, fmt.Errorf("messages %s have no date formats for locale %q", file, locale)
+		}
+		source, err := fs.ReadFile(files, file)
+		if err != nil {
+			return nil, err
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(source, &messages); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		loaded[locale] = messages
+	}
+	if _, ok := loaded[DefaultLocale]; !ok {
+		return nil, fmt.Errorf("no messages for the default locale %q", DefaultLocale)
+	}
+	return loaded, nil
+}
+
+// Printer words messages, dates and times for one locale, for what the
+// server sends that isn't a whole template: notification titles, email
+// bodies and the like
+type Printer struct {
+	locale   string
+	messages map[string]string
+	fallback map[string]string
+	format   localeFormat
+}
+
+// NewPrinter returns the printer for a locale. Like Render, a regional tag
+// such as "es-MX" falls back to its language, then to DefaultLocale.
+func NewPrinter(locale string) *Printer {
+	loaded := catalogs()
+	resolved := DefaultLocale
+	for _, candidate := range localeCandidates(locale) {
+		if _, ok := loaded[candidate]; ok {
+			resolved = candidate
+			break
+		}
+	}
+	return &Printer{
+		locale:   resolved,
+		messages: loaded[resolved],
+		fallback: loaded[DefaultLocale],
+		format:   localeFormats[resolved],
+	}
+}
+
+// Locale returns the locale used, after any fallback
+func (p *Printer) Locale() string {
+	return p.locale
+}
+
+// Sprintf formats the message with the given ID. A message no locale has is
+// printed as its ID, so a mistyped one shows up rather than vanishing.
+func (p *Printer) Sprintf(id string, args ...any) string {
+	format, ok := p.messages[id]
+	if !ok {
+		if format, ok = p.fallback[id]; !ok {
+			return id
+		}
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Plural formats the ".one" or ".other" form of a message for n
+func (p *Printer) Plural(id string, n int) string {
+	if n == 1 {
+		return p.Sprintf(id+".one", n)
+	}
+	return p.Sprintf(id+".other", n)
+}
+
+// Weekday names t's day of the week
+func (p *Printer) Weekday(t time.Time) string {
+	return p.format.weekdays[t.Weekday()]
+}
+
+// LongDate writes t like "Monday, March 2"
+func (p *Printer) LongDate(t time.Time) string {
+	return p.format.date(p.format.longDate, t)
+}
+
+// DayMonth writes t like "March 2"
+func (p *Printer) DayMonth(t time.Time) string {
+	return p.format.date(p.format.dayMonth, t)
+}
+
+// Clock writes t's time of day like "3:04 PM"
+func (p *Printer) Clock(t time.Time) string {
+	return t.Format(p.format.clock)
+}
+
+// localeCandidates lists the locales to try for a tag, most specific first
+func localeCandidates(locale string) []string {
+	locale = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+	language, _, _ := strings.Cut(locale, "-")
+	return []string{locale, language}
+}
@@ -0,0 +1,46 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogs_MatchDefaultLocale(t *testing.T) {
+	loaded, err := loadCatalogs(messageFiles, "messages")
+	require.NoError(t, err)
+	require.Contains(t, loaded, "es")
+
+	defaults := loaded[DefaultLocale]
+	for locale, messages := range loaded {
+		for id, format := range messages {
+			want, ok := defaults[id]
+			if !assert.True(t, ok, "%s has %s, which %s doesn't", locale, id, DefaultLocale) {
+				continue
+			}
+			assert.Equal(t, strings.Count(want, "%"), strings.Count(format, "%"), "%s %s takes other arguments", locale, id)
+		}
+	}
+}
+
+func TestPrinter(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 17, 30, 0, 0, time.UTC)
+
+	en := NewPrinter("en-US")
+	assert.Equal(t, "en", en.Locale())
+	assert.Equal(t, "New task for you, due March 2", en.Sprintf("task.assigned_due", en.DayMonth(monday)))
+	assert.Equal(t, "1 event", en.Plural("digest.events", 1))
+	assert.Equal(t, "3 tasks", en.Plural("digest.tasks", 3))
+	assert.Equal(t, "Starts Monday at 5:30 PM", en.Sprintf("reminder.starts_on", en.Weekday(monday), en.Clock(monday)))
+	assert.Equal(t, "no.such.message", en.Sprintf("no.such.message"))
+
+	es := NewPrinter("es_MX")
+	assert.Equal(t, "es", es.Locale())
+	assert.Equal(t, "Todo el día, lunes 2 de marzo", es.Sprintf("reminder.all_day", es.LongDate(monday)))
+	assert.Equal(t, "17:30", es.Clock(monday))
+
+	assert.Equal(t, DefaultLocale, NewPrinter("fr").Locale())
+}
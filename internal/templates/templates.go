@@ -0,0 +1,240 @@
+// Package templates renders digests and notifications from embedded,
+// per-locale templates, one file per channel, and words the server's other
+// messages from per-locale message catalogs.
+//
+// Templates live at digest/<locale>/<name>.<channel>.tmpl. Email and text
+// templates define a "subject" and a "body"; Slack templates define only a
+// "body" that must render to a Block Kit JSON payload.
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed digest
+var digestFiles embed.FS
+
+// Delivery channels
+const (
+	ChannelEmailHTML = "email_html"
+	ChannelText      = "text"
+	ChannelSlack     = "slack"
+)
+
+// DefaultLocale is used when no template exists for the requested locale
+const DefaultLocale = "en"
+
+var channelContentTypes = map[string]string{
+	ChannelEmailHTML: "text/html; charset=utf-8",
+	ChannelText:      "text/plain; charset=utf-8",
+	ChannelSlack:     "application/json",
+}
+
+// Rendered is a template rendered for one locale and channel
+type Rendered struct {
+	Template    string `json:"template"`
+	Locale      string `json:"locale"` // the locale used, after any fallback
+	Channel     string `json:"channel"`
+	ContentType string `json:"content_type"`
+	Subject     string `json:"subject,omitempty"`
+	Body        string `json:"body"`
+}
+
+// executor is satisfied by both html/template and text/template
+type executor interface {
+	ExecuteTemplate(w io.Writer, name string, data any) error
+}
+
+type templateKey struct {
+	name, locale, channel string
+}
+
+type parsedTemplate struct {
+	executor   executor
+	hasSubject bool
+}
+
+// Renderer holds every embedded template, parsed once
+type Renderer struct {
+	templates map[templateKey]parsedTemplate
+	names     map[string]bool
+	locales   map[string]bool
+}
+
+// NewRenderer parses the embedded templates. It fails if any template is
+// malformed or its locale has no date formats.
+func NewRenderer() (*Renderer, error) {
+	return newRenderer(digestFiles, "digest")
+}
+
+// MustNewRenderer is like NewRenderer but panics on error. The templates are
+// embedded at build time, so an error here is a bug, not a runtime condition.
+func MustNewRenderer() *Renderer {
+	r, err := NewRenderer()
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func newRenderer(files fs.FS, root string) (*Renderer, error) {
+	r := &Renderer{
+		templates: make(map[templateKey]parsedTemplate),
+		names:     make(map[string]bool),
+		locales:   make(map[string]bool),
+	}
+
+	paths, err := fs.Glob(files, path.Join(root, "*", "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range paths {
+		locale := path.Base(path.Dir(file))
+		name, channel, ok := strings.Cut(strings.TrimSuffix(path.Base(file), ".tmpl"), ".")
+		if !ok {
+			return nil, fmt.Errorf("template %s is not named <name>.<channel>.tmpl", file)
+		}
+		if _, known := channelContentTypes[channel]; !known {
+			return nil, fmt.Errorf("template %s has unknown channel %q", file, channel)
+		}
+		format, known := localeFormats[locale]
+		if !known {
+			return nil, fmt.Errorf("template %s has no date formats for locale %q", file, locale)
+		}
+
+		source, err := fs.ReadFile(files, file)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := parseTemplate(file, channel, string(source), format.funcs())
+		if err != nil {
+			return nil, err
+		}
+
+		r.templates[templateKey{name, locale, channel}] = parsed
+		r.names[name] = true
+		r.locales[locale] = true
+	}
+
+	return r, nil
+}
+
+func parseTemplate(file, channel, source string, funcs map[string]any) (parsedTemplate, error) {
+	if channel == ChannelEmailHTML {
+		tmpl, err := htmltemplate.New(file).Funcs(funcs).Parse(source)
+		if err != nil {
+			return parsedTemplate{}, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		if tmpl.Lookup("body") == nil {
+			return parsedTemplate{}, fmt.Errorf("template %s does not define a body", file)
+		}
+		return parsedTemplate{executor: tmpl, hasSubject: tmpl.Lookup("subject") != nil}, nil
+	}
+
+	tmpl, err := texttemplate.New(file).Funcs(funcs).Parse(source)
+	if err != nil {
+		return parsedTemplate{}, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	if tmpl.Lookup("body") == nil {
+		return parsedTemplate{}, fmt.Errorf("template %s does not define a body", file)
+	}
+	return parsedTemplate{executor: tmpl, hasSubject: tmpl.Lookup("subject") != nil}, nil
+}
+
+// Render renders the named template. The locale falls back from a regional
+// tag such as "es-MX" to its language, then to DefaultLocale.
+func (r *Renderer) Render(name, locale, channel string, data any) (*Rendered, error) {
+	if !r.names[name] {
+		return nil, fmt.Errorf("unknown template %q", name)
+	}
+	contentType, ok := channelContentTypes[channel]
+	if !ok {
+		return nil, fmt.Errorf("unknown channel %q", channel)
+	}
+
+	resolved := r.resolveLocale(name, locale, channel)
+	tmpl, ok := r.templates[templateKey{name, resolved, channel}]
+	if !ok {
+		return nil, fmt.Errorf("template %q has no %s version", name, channel)
+	}
+
+	rendered := &Rendered{Template: name, Locale: resolved, Channel: channel, ContentType: contentType}
+	if tmpl.hasSubject {
+		subject, err := execute(tmpl.executor, "subject", data)
+		if err != nil {
+			return nil, err
+		}
+		if channel == ChannelEmailHTML {
+			// html/template escapes the subject too, but mail headers are plain text
+			subject = html.UnescapeString(subject)
+		}
+		rendered.Subject = strings.Join(strings.Fields(subject), " ")
+	}
+
+	body, err := execute(tmpl.executor, "body", data)
+	if err != nil {
+		return nil, err
+	}
+	if channel == ChannelSlack {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, []byte(body), "", "  "); err != nil {
+			return nil, fmt.Errorf("template %q rendered invalid Slack JSON for %s: %w", name, resolved, err)
+		}
+		body = indented.String()
+	}
+	rendered.Body = strings.TrimSpace(body) + "\n"
+
+	return rendered, nil
+}
+
+func execute(tmpl executor, block string, data any) (string, error) {
+	var out bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&out, block, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", block, err)
+	}
+	return out.String(), nil
+}
+
+func (r *Renderer) resolveLocale(name, locale, channel string) string {
+	for _, candidate := range localeCandidates(locale) {
+		if _, ok := r.templates[templateKey{name, candidate, channel}]; ok {
+			return candidate
+		}
+	}
+	return DefaultLocale
+}
+
+// Templates returns the template names in alphabetical order
+func (r *Renderer) Templates() []string {
+	return sortedKeys(r.names)
+}
+
+// Locales returns the locales that have at least one template
+func (r *Renderer) Locales() []string {
+	return sortedKeys(r.locales)
+}
+
+// Channels returns the supported delivery channels
+func Channels() []string {
+	return []string{ChannelEmailHTML, ChannelText, ChannelSlack}
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
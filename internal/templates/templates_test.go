@@ -0,0 +1,106 @@
+package templates
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"famstack/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func sampleDigest(days int) *models.AgendaDigest {
+	loc := time.FixedZone("CST", -6*60*60)
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, loc)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, 2+day, hour, minute, 0, 0, loc)
+	}
+
+	all := []models.AgendaDay{
+		{
+			Date: monday,
+			Events: []models.AgendaEntry{
+				{Kind: models.TimelineItemEvent, Title: "Spring break", Start: monday, AllDay: true, Members: []string{}},
+				{Kind: models.TimelineItemEvent, Title: "Soccer & snacks", Start: at(0, 17, 30), Members: []string{"Ana Rivera", "Leo Rivera"}},
+			},
+			Tasks: []models.AgendaEntry{
+				{Kind: models.TimelineItemTask, Title: "Feed the \"cat\"", Start: monday, AllDay: true, Status: "completed", Members: []string{"Leo Rivera"}},
+				{Kind: models.TimelineItemRoutine, Title: "Pack lunch", Start: at(0, 7, 15), Members: []string{"Ana Rivera"}},
+			},
+		},
+		{Date: monday.AddDate(0, 0, 1), Events: []models.AgendaEntry{}, Tasks: []models.AgendaEntry{}},
+	}
+
+	digest := &models.AgendaDigest{
+		FamilyID:   "fam_rivera",
+		FamilyName: "Rivera <Family>",
+		Timezone:   "America/Chicago",
+		Start:      monday,
+		Days:       all[:days],
+	}
+	for _, day := range digest.Days {
+		digest.TotalItems += len(day.Events) + len(day.Tasks)
+	}
+	return digest
+}
+
+func TestRender_Golden(t *testing.T) {
+	renderer, err := NewRenderer()
+	require.NoError(t, err)
+
+	days := map[string]int{models.DigestTemplateWeek: 2, models.DigestTemplateAgenda: 1}
+	for _, name := range renderer.Templates() {
+		for _, locale := range renderer.Locales() {
+			for _, channel := range Channels() {
+				t.Run(fmt.Sprintf("%s/%s/%s", name, locale, channel), func(t *testing.T) {
+					rendered, err := renderer.Render(name, locale, channel, sampleDigest(days[name]))
+					require.NoError(t, err)
+					assert.Equal(t, locale, rendered.Locale)
+
+					got := rendered.Body
+					if rendered.Subject != "" {
+						got = "Subject: " + rendered.Subject + "\n\n" + got
+					}
+					if channel == ChannelSlack {
+						assert.True(t, json.Valid([]byte(rendered.Body)))
+					}
+
+					golden := filepath.Join("testdata", fmt.Sprintf("%s.%s.%s.golden", name, locale, channel))
+					if *update {
+						require.NoError(t, os.WriteFile(golden, []byte(got), 0o644))
+					}
+					want, err := os.ReadFile(golden)
+					require.NoError(t, err, "run go test ./internal/templates -update to create it")
+					assert.Equal(t, string(want), got)
+				})
+			}
+		}
+	}
+}
+
+func TestRender_LocaleFallback(t *testing.T) {
+	renderer, err := NewRenderer()
+	require.NoError(t, err)
+
+	rendered, err := renderer.Render(models.DigestTemplateAgenda, "es_MX", ChannelText, sampleDigest(1))
+	require.NoError(t, err)
+	assert.Equal(t, "es", rendered.Locale)
+	assert.Contains(t, rendered.Body, "lunes 2 de marzo")
+
+	rendered, err = renderer.Render(models.DigestTemplateAgenda, "fr-CA", ChannelText, sampleDigest(1))
+	require.NoError(t, err)
+	assert.Equal(t, DefaultLocale, rendered.Locale)
+
+	_, err = renderer.Render("newsletter", "en", ChannelText, sampleDigest(1))
+	assert.EqualError(t, err, `unknown template "newsletter"`)
+	_, err = renderer.Render(models.DigestTemplateAgenda, "en", "sms", sampleDigest(1))
+	assert.EqualError(t, err, `unknown channel "sms"`)
+}
@@ -0,0 +1,37 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"famstack/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoute(t *testing.T) {
+	tests := []struct{ path, want string }{
+		{"/api/v1/tasks", "/api/v1/tasks"},
+		{"/api/v1/tasks/task_1712345678901/complete", "/api/v1/tasks/{id}/complete"},
+		{"/api/v1/families/fam_1712345678901/members", "/api/v1/families/{id}/members"},
+		{"/api/v1/integrations/9f86d081884c7d659a2feaa0c55ad015", "/api/v1/integrations/{id}"},
+		{"/api/v1/tasks/task_01J9ZQ3K8M4X7B2C5D6E7F8G9H", "/api/v1/tasks/{id}"},
+		{"/api/v1/reports/2026", "/api/v1/reports/2026"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Route(tt.path), tt.path)
+	}
+}
+
+func TestSetupDisabled(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.TracingConfig{}, "dev")
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	// With no provider installed spans are no-ops
+	ctx, span := Start(context.Background(), "test")
+	assert.NotNil(t, ctx)
+	assert.False(t, span.IsRecording())
+	End(span, assert.AnError)
+}
@@ -0,0 +1,212 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Struct checks v, a struct or a pointer to one, against the rules in its
+// fields' validate tags and returns the fields that break them as
+// ValidationErrors, named by their JSON names. Nested structs are checked
+// too, their fields named like settings.color.
+//
+// The rules are:
+//
+//	required   set: non-blank strings, non-nil pointers, non-empty slices, non-zero times and numbers
+//	omitempty  skip the remaining rules when the field is not set
+//	min=n      at least n characters, items or, for numbers, n
+//	max=n      at most n characters, items or, for numbers, n
+//	len=n      exactly n characters or items
+//	oneof=a b  one of the listed values
+//	email      an email address
+//	url        an absolute URL
+//	hexcolor   a color like #fff or #1e90ff
+//
+// An unknown rule is a programming error and panics.
+func Struct(v any) error {
+	validator := NewValidator()
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Struct {
+		validateStruct(validator, value, "")
+	}
+	return validator.ToError()
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func validateStruct(validator *Validator, value reflect.Value, prefix string) {
+	structType := value.Type()
+	for i := range structType.NumField() {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := value.Field(i)
+
+		// Embedded structs contribute their fields at the same level
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			validateStruct(validator, fieldValue, prefix)
+			continue
+		}
+
+		name := fieldName(field)
+		if name == "-" {
+			continue
+		}
+		name = prefix + name
+
+		if tag := field.Tag.Get("validate"); tag != "" {
+			validateField(validator, name, fieldValue, tag)
+		}
+		validateNested(validator, name, fieldValue)
+	}
+}
+
+// validateNested checks a struct held by a field. Slices of structs are left
+// to their own checks, so a bulk request can accept its valid items and
+// report the rest.
+func validateNested(validator *Validator, name string, value reflect.Value) {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Struct && value.Type() != timeType {
+		validateStruct(validator, value, name+".")
+	}
+}
+
+// fieldName is the name a field goes by in JSON
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func validateField(validator *Validator, name string, value reflect.Value, tag string) {
+	set := !isZero(value)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		rule, param, _ := strings.Cut(rule, "=")
+		switch rule {
+		case "omitempty":
+			if !set {
+				return
+			}
+		case "required":
+			if !set {
+				validator.AddErrorf(name, "%s is required", name)
+				return
+			}
+		case "min":
+			if size, unit, ok := measure(value); ok && size < number(param) {
+				validator.AddErrorf(name, "%s must be at least %s%s", name, param, units(unit, param))
+			}
+		case "max":
+			if size, unit, ok := measure(value); ok && size > number(param) {
+				validator.AddErrorf(name, "%s must be no more than %s%s", name, param, units(unit, param))
+			}
+		case "len":
+			if size, unit, ok := measure(value); ok && size != number(param) {
+				validator.AddErrorf(name, "%s must be exactly %s%s", name, param, units(unit, param))
+			}
+		case "oneof":
+			allowed := strings.Fields(param)
+			if !slices.Contains(allowed, fmt.Sprint(value.Interface())) {
+				validator.AddErrorf(name, "%s must be one of: %s", name, strings.Join(allowed, ", "))
+			}
+		case "email":
+			if _, err := mail.ParseAddress(value.String()); err != nil {
+				validator.AddErrorf(name, "%s must be an email address", name)
+			}
+		case "url":
+			if parsed, err := url.Parse(value.String()); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+				validator.AddErrorf(name, "%s must be an absolute URL", name)
+			}
+		case "hexcolor":
+			if !isHexColor(value.String()) {
+				validator.AddErrorf(name, "%s must be a hex color like #1e90ff", name)
+			}
+		default:
+			panic(fmt.Sprintf("validation: unknown rule %q on %s", rule, name))
+		}
+	}
+}
+
+// isZero reports whether a field counts as not set
+func isZero(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return value.IsNil()
+	case reflect.String:
+		return strings.TrimSpace(value.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+	return value.IsZero()
+}
+
+// measure returns what min, max and len compare: the length of strings in
+// characters and of slices in items, or the value of a number
+func measure(value reflect.Value) (float64, string, bool) {
+	switch value.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), " characters", true
+	case reflect.Slice, reflect.Map:
+		return float64(value.Len()), " items", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), "", true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), "", true
+	}
+	return 0, "", false
+}
+
+// units makes "1 characters" read "1 character"
+func units(unit, param string) string {
+	if param == "1" {
+		return strings.TrimSuffix(unit, "s")
+	}
+	return unit
+}
+
+func number(param string) float64 {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validation: %q is not a number", param))
+	}
+	return n
+}
+
+func isHexColor(s string) bool {
+	if (len(s) != 4 && len(s) != 7) || s[0] != '#' {
+		return false
+	}
+	for _, c := range s[1:] {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
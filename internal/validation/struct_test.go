@@ -0,0 +1,48 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSettings struct {
+	Color string `json:"color" validate:"omitempty,hexcolor"`
+}
+
+type testRequest struct {
+	Title    string       `json:"title" validate:"required,max=5"`
+	Kind     string       `json:"kind" validate:"required,oneof=chore todo"`
+	Priority int          `json:"priority" validate:"min=0,max=3"`
+	Days     []string     `json:"days" validate:"required,min=1"`
+	Initial  *string      `json:"initial,omitempty" validate:"omitempty,len=1"`
+	Email    string       `json:"email" validate:"omitempty,email"`
+	Settings testSettings `json:"settings"`
+}
+
+func TestStruct(t *testing.T) {
+	initial := "AB"
+	err := Struct(&testRequest{
+		Title:    "  ",
+		Kind:     "errand",
+		Priority: 4,
+		Initial:  &initial,
+		Email:    "not-an-email",
+		Settings: testSettings{Color: "blue"},
+	})
+
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	assert.Equal(t, ValidationErrors{
+		{Field: "title", Message: "title is required"},
+		{Field: "kind", Message: "kind must be one of: chore, todo"},
+		{Field: "priority", Message: "priority must be no more than 3"},
+		{Field: "days", Message: "days is required"},
+		{Field: "initial", Message: "initial must be exactly 1 character"},
+		{Field: "email", Message: "email must be an email address"},
+		{Field: "settings.color", Message: "settings.color must be a hex color like #1e90ff"},
+	}, errs)
+
+	assert.NoError(t, Struct(&testRequest{Title: "Mop", Kind: "chore", Days: []string{"mon"}}))
+}
@@ -0,0 +1,178 @@
+package voice
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/config"
+)
+
+// alexaMaxSkew is how far an Alexa request's timestamp may be from now;
+// Amazon rejects skills that take older requests, which may be replays
+const alexaMaxSkew = 150 * time.Second
+
+var errNotLinked = errors.New("account not linked")
+
+// alexaRequest is the part of an Alexa skill request the handler reads
+type alexaRequest struct {
+	Context struct {
+		System struct {
+			Application struct {
+				ApplicationID string `json:"applicationId"`
+			} `json:"application"`
+			User struct {
+				AccessToken string `json:"accessToken"`
+			} `json:"user"`
+		} `json:"System"`
+	} `json:"context"`
+	Request struct {
+		Type      string    `json:"type"`
+		Timestamp time.Time `json:"timestamp"`
+		Intent    struct {
+			Name  string `json:"name"`
+			Slots map[string]struct {
+				Value string `json:"value"`
+			} `json:"slots"`
+		} `json:"intent"`
+	} `json:"request"`
+}
+
+type alexaResponse struct {
+	Version  string            `json:"version"`
+	Response alexaResponseBody `json:"response"`
+}
+
+type alexaResponseBody struct {
+	OutputSpeech     alexaSpeech `json:"outputSpeech"`
+	Card             *alexaCard  `json:"card,omitempty"`
+	ShouldEndSession bool        `json:"shouldEndSession"`
+}
+
+type alexaSpeech struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type alexaCard struct {
+	Type string `json:"type"`
+}
+
+// alexaIntents maps Alexa's built-in intents to the assistant's
+var alexaIntents = map[string]string{
+	"AMAZON.HelpIntent":   IntentHelp,
+	"AMAZON.StopIntent":   IntentStop,
+	"AMAZON.CancelIntent": IntentStop,
+}
+
+// AlexaHandler answers an Alexa skill's requests at /voice/alexa
+type AlexaHandler struct {
+	authService *auth.Service
+	assistant   *Assistant
+	config      func() config.AlexaConfig
+	now         func() time.Time
+}
+
+// NewAlexaHandler creates an Alexa handler; config is read on each request
+// so the skill can be set up without a restart
+func NewAlexaHandler(authService *auth.Service, assistant *Assistant, config func() config.AlexaConfig) *AlexaHandler {
+	return &AlexaHandler{authService: authService, assistant: assistant, config: config, now: time.Now}
+}
+
+func (h *AlexaHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	cfg := h.config()
+	if cfg.ClientID == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	var req alexaRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if cfg.SkillID != "" && req.Context.System.Application.ApplicationID != cfg.SkillID {
+		http.Error(w, "Unknown skill", http.StatusForbidden)
+		return
+	}
+	if skew := h.now().Sub(req.Request.Timestamp); skew > alexaMaxSkew || skew < -alexaMaxSkew {
+		http.Error(w, "Stale request", http.StatusBadRequest)
+		return
+	}
+
+	reply, link := h.reply(&req)
+	writeAlexa(w, reply, link)
+}
+
+// reply answers the request; link asks Alexa to show the account linking
+// card because the skill isn't linked
+func (h *AlexaHandler) reply(req *alexaRequest) (Reply, bool) {
+	if req.Request.Type == "SessionEndedRequest" {
+		return Reply{End: true}, false
+	}
+
+	caller, err := identify(h.authService, req.Context.System.User.AccessToken)
+	if err != nil {
+		return NotLinked(), true
+	}
+
+	switch req.Request.Type {
+	case "LaunchRequest":
+		return h.assistant.Welcome(), false
+	case "IntentRequest":
+		return h.assistant.Answer(caller, alexaIntent(req)), false
+	default:
+		return Reply{End: true}, false
+	}
+}
+
+func alexaIntent(req *alexaRequest) Intent {
+	intent := Intent{Name: req.Request.Intent.Name, Slots: map[string]string{}}
+	if name, ok := alexaIntents[intent.Name]; ok {
+		intent.Name = name
+	}
+	for name, slot := range req.Request.Intent.Slots {
+		intent.Slots[name] = slot.Value
+	}
+	return intent
+}
+
+func writeAlexa(w http.ResponseWriter, reply Reply, link bool) {
+	resp := alexaResponse{
+		Version: "1.0",
+		Response: alexaResponseBody{
+			OutputSpeech:     alexaSpeech{Type: "PlainText", Text: reply.Speech},
+			ShouldEndSession: reply.End,
+		},
+	}
+	if link {
+		resp.Response.Card = &alexaCard{Type: "LinkAccount"}
+	}
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("voice: failed to encode Alexa response: %v", err)
+	}
+}
+
+// identify finds the member an assistant's access token was issued to
+func identify(authService *auth.Service, token string) (*Caller, error) {
+	if token == "" {
+		return nil, errNotLinked
+	}
+	session, err := authService.ValidateToken(token)
+	if err != nil {
+		return nil, err
+	}
+	member, err := authService.GetFamilyMemberByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	return &Caller{Session: session, Member: member}, nil
+}
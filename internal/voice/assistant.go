@@ -0,0 +1,219 @@
+// Package voice answers voice assistants. The Assistant understands a few
+// intents whatever the platform; the Alexa and Google handlers translate
+// each platform's requests and replies, and identify the member from the
+// token the assistant got through account linking.
+package voice
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"famstack/internal/auth"
+	"famstack/internal/models"
+	"famstack/internal/services"
+)
+
+// Intents, and their slots. Both platforms' interaction models name intents
+// and slots the same way.
+const (
+	// IntentMemberTasks asks what a member has to do. Slots: member, a
+	// first name, the caller when empty; date, YYYY-MM-DD, today when empty.
+	IntentMemberTasks = "MemberTasksIntent"
+	// IntentAddShoppingItem adds to the shopping list. Slot: item.
+	IntentAddShoppingItem = "AddShoppingItemIntent"
+	IntentHelp            = "HelpIntent"
+	IntentStop            = "StopIntent"
+)
+
+// Scopes are what a linked assistant's token may do: read tasks and the
+// members they are assigned to, and add to the shopping list
+var Scopes = []string{"task:read", "task:write", "family:read"}
+
+const helpSpeech = "You can ask what chores Riley has today, or say add milk to the shopping list."
+
+// Intent is a recognized request with its slot values
+type Intent struct {
+	Name  string
+	Slots map[string]string
+}
+
+// Reply is what the assistant says. End closes the conversation; otherwise
+// the assistant listens for a follow-up.
+type Reply struct {
+	Speech string
+	End    bool
+}
+
+// Caller is the member an assistant was linked to
+type Caller struct {
+	Session *auth.Session
+	Member  *models.FamilyMember
+}
+
+// Assistant answers intents with the family's data
+type Assistant struct {
+	registry *services.Registry
+	now      func() time.Time
+}
+
+// NewAssistant returns an assistant backed by the services
+func NewAssistant(registry *services.Registry) *Assistant {
+	return &Assistant{registry: registry, now: time.Now}
+}
+
+// Welcome greets a caller who opened the skill without asking anything
+func (a *Assistant) Welcome() Reply {
+	return Reply{Speech: "Welcome to FamStack. " + helpSpeech}
+}
+
+// NotLinked asks the caller to link their account first
+func NotLinked() Reply {
+	return Reply{Speech: "Please link your FamStack account in your assistant's app first.", End: true}
+}
+
+// Answer replies to an intent
+func (a *Assistant) Answer(caller *Caller, intent Intent) Reply {
+	switch intent.Name {
+	case IntentMemberTasks:
+		return a.memberTasks(caller, strings.TrimSpace(intent.Slots["member"]), intent.Slots["date"])
+	case IntentAddShoppingItem:
+		return a.addShoppingItem(caller, strings.TrimSpace(intent.Slots["item"]))
+	case IntentHelp:
+		return Reply{Speech: helpSpeech}
+	case IntentStop:
+		return Reply{Speech: "Goodbye.", End: true}
+	default:
+		return Reply{Speech: "Sorry, I can't do that yet. " + helpSpeech}
+	}
+}
+
+func (a *Assistant) memberTasks(caller *Caller, name, date string) Reply {
+	if !auth.NewAuthorizationService(caller.Session).HasPermission(auth.EntityTask, auth.ActionRead, nil) {
+		return Reply{Speech: "Your account isn't allowed to see tasks.", End: true}
+	}
+
+	family, err := a.registry.Families.GetFamily(caller.Session.FamilyID)
+	if err != nil {
+		return a.failed("look up the family", err)
+	}
+	loc, err := time.LoadLocation(family.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	today := a.now().In(loc)
+	day := today
+	if parsed, err := time.ParseInLocation("2006-01-02", date, loc); err == nil {
+		day = parsed
+	}
+
+	tasks, err := a.registry.Tasks.ListTasksByFamily(family.ID, day.Format("2006-01-02"), models.TaskSortDue)
+	if err != nil {
+		return a.failed("look up tasks", err)
+	}
+
+	memberID, self := caller.Member.ID, true
+	if name != "" && !matchesName(caller.Member.FirstName+" "+caller.Member.LastName, name) {
+		memberID, self = "", false
+		for id, column := range tasks.TasksByMember {
+			if id != "unassigned" && matchesName(column.Member.Name, name) {
+				memberID, name = id, firstName(column.Member.Name)
+				break
+			}
+		}
+		if memberID == "" {
+			return Reply{Speech: fmt.Sprintf("I couldn't find %s in your family.", name), End: true}
+		}
+	}
+
+	var pending []string
+	done := 0
+	for _, task := range tasks.TasksByMember[memberID].Tasks {
+		if task.Status == models.TaskStatusCompleted {
+			done++
+		} else {
+			pending = append(pending, task.Title)
+		}
+	}
+	return Reply{Speech: tasksSpeech(name, self, pending, done, dayPhrase(day, today)), End: true}
+}
+
+func (a *Assistant) addShoppingItem(caller *Caller, item string) Reply {
+	if item == "" {
+		return Reply{Speech: "What should I add to the shopping list?"}
+	}
+	if !auth.NewAuthorizationService(caller.Session).HasPermission(auth.EntityTask, auth.ActionUpdate, nil) {
+		return Reply{Speech: "Your account isn't allowed to change the shopping list.", End: true}
+	}
+
+	req := models.CreateShoppingListItemRequest{Name: item}
+	if err := req.Validate(); err != nil {
+		return Reply{Speech: "Sorry, I couldn't add that.", End: true}
+	}
+	if _, err := a.registry.Meals.AddShoppingListItem(caller.Session.FamilyID, caller.Member.ID, &req); err != nil {
+		return a.failed("add to the shopping list", err)
+	}
+	return Reply{Speech: fmt.Sprintf("Added %s to the shopping list.", req.Name), End: true}
+}
+
+func (a *Assistant) failed(what string, err error) Reply {
+	log.Printf("voice: failed to %s: %v", what, err)
+	return Reply{Speech: "Sorry, something went wrong. Please try again later.", End: true}
+}
+
+// tasksSpeech says what someone has left to do. name is unused when self.
+func tasksSpeech(name string, self bool, pending []string, done int, when string) string {
+	subject, has, is := name, "has", "is"
+	if self {
+		subject, has, is = "You", "have", "are"
+	}
+	switch {
+	case len(pending) == 0 && done > 0:
+		return fmt.Sprintf("%s %s all done %s.", subject, is, when)
+	case len(pending) == 0:
+		return fmt.Sprintf("%s %s nothing to do %s.", subject, has, when)
+	case len(pending) == 1:
+		return fmt.Sprintf("%s %s one thing to do %s: %s.", subject, has, when, pending[0])
+	default:
+		return fmt.Sprintf("%s %s %d things to do %s: %s.", subject, has, len(pending), when, joinList(pending))
+	}
+}
+
+// dayPhrase names day as spoken relative to today
+func dayPhrase(day, today time.Time) string {
+	dayDate := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	todayDate := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	switch days := int(dayDate.Sub(todayDate).Hours() / 24); {
+	case days == 0:
+		return "today"
+	case days == 1:
+		return "tomorrow"
+	case days == -1:
+		return "yesterday"
+	case days > 1 && days < 7:
+		return "on " + day.Weekday().String()
+	default:
+		return "on " + day.Format("Monday, January 2")
+	}
+}
+
+// joinList joins items as spoken: "a, b and c"
+func joinList(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
+
+// matchesName reports whether a spoken name is a member's first or full name
+func matchesName(memberName, spoken string) bool {
+	return strings.EqualFold(memberName, spoken) || strings.EqualFold(firstName(memberName), spoken)
+}
+
+func firstName(name string) string {
+	if fields := strings.Fields(name); len(fields) > 0 {
+		return fields[0]
+	}
+	return name
+}
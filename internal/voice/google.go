@@ -0,0 +1,164 @@
+package voice
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"famstack/internal/auth"
+	"famstack/internal/config"
+)
+
+// googleRequest is the part of an Actions Builder webhook request the
+// handler reads
+type googleRequest struct {
+	Handler struct {
+		Name string `json:"name"`
+	} `json:"handler"`
+	Intent struct {
+		Name   string `json:"name"`
+		Params map[string]struct {
+			Original string `json:"original"`
+			Resolved any    `json:"resolved"`
+		} `json:"params"`
+	} `json:"intent"`
+	Scene struct {
+		Name string `json:"name"`
+	} `json:"scene"`
+	Session struct {
+		ID string `json:"id"`
+	} `json:"session"`
+	User struct {
+		Params struct {
+			BearerToken string `json:"bearerToken"`
+		} `json:"params"`
+	} `json:"user"`
+}
+
+type googleResponse struct {
+	Session googleSession `json:"session"`
+	Prompt  googlePrompt  `json:"prompt"`
+	Scene   *googleScene  `json:"scene,omitempty"`
+}
+
+type googleSession struct {
+	ID     string         `json:"id"`
+	Params map[string]any `json:"params"`
+}
+
+type googlePrompt struct {
+	Override    bool         `json:"override"`
+	FirstSimple googleSimple `json:"firstSimple"`
+}
+
+type googleSimple struct {
+	Speech string `json:"speech"`
+	Text   string `json:"text"`
+}
+
+type googleScene struct {
+	Name string `json:"name"`
+	Next struct {
+		Name string `json:"name"`
+	} `json:"next"`
+}
+
+// googleIntents maps Google's system intents to the assistant's
+var googleIntents = map[string]string{
+	"actions.intent.CANCEL": IntentStop,
+}
+
+// GoogleHandler answers a Google Action's fulfillment webhook at
+// /voice/google
+type GoogleHandler struct {
+	authService *auth.Service
+	assistant   *Assistant
+	config      func() config.VoiceClientConfig
+}
+
+// NewGoogleHandler creates a Google handler; config is read on each request
+// so the Action can be set up without a restart
+func NewGoogleHandler(authService *auth.Service, assistant *Assistant, config func() config.VoiceClientConfig) *GoogleHandler {
+	return &GoogleHandler{authService: authService, assistant: assistant, config: config}
+}
+
+func (h *GoogleHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.config().ClientID == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	var req googleRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	token := req.User.Params.BearerToken
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	caller, err := identify(h.authService, token)
+	var reply Reply
+	switch intent := googleIntent(&req); {
+	case err != nil:
+		reply = NotLinked()
+	case intent.Name == "actions.intent.MAIN":
+		reply = h.assistant.Welcome()
+	default:
+		reply = h.assistant.Answer(caller, intent)
+	}
+	writeGoogle(w, &req, reply)
+}
+
+// googleIntent is the matched intent, or for a webhook called from a scene
+// rather than an intent, the handler's name
+func googleIntent(req *googleRequest) Intent {
+	intent := Intent{Name: req.Intent.Name, Slots: map[string]string{}}
+	if intent.Name == "" {
+		intent.Name = req.Handler.Name
+	}
+	if name, ok := googleIntents[intent.Name]; ok {
+		intent.Name = name
+	}
+	for name, param := range req.Intent.Params {
+		value := param.Original
+		switch resolved := param.Resolved.(type) {
+		case string:
+			if resolved != "" {
+				value = resolved
+			}
+		case map[string]any:
+			// Dates resolve to {"year": 2024, "month": 5, "day": 17}
+			year, _ := resolved["year"].(float64)
+			month, _ := resolved["month"].(float64)
+			day, _ := resolved["day"].(float64)
+			if year > 0 && month > 0 && day > 0 {
+				value = fmt.Sprintf("%04d-%02d-%02d", int(year), int(month), int(day))
+			}
+		}
+		intent.Slots[name] = value
+	}
+	return intent
+}
+
+func writeGoogle(w http.ResponseWriter, req *googleRequest, reply Reply) {
+	resp := googleResponse{
+		Session: googleSession{ID: req.Session.ID, Params: map[string]any{}},
+		Prompt:  googlePrompt{FirstSimple: googleSimple{Speech: reply.Speech, Text: reply.Speech}},
+	}
+	if reply.End {
+		resp.Scene = &googleScene{Name: req.Scene.Name}
+		resp.Scene.Next.Name = "actions.scene.END_CONVERSATION"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("voice: failed to encode Google response: %v", err)
+	}
+}
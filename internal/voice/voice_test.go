@@ -0,0 +1,159 @@
+package voice
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"famstack/internal/config"
+)
+
+func TestTasksSpeech(t *testing.T) {
+	tests := []struct {
+		name    string
+		self    bool
+		pending []string
+		done    int
+		want    string
+	}{
+		{"Riley", false, []string{"Feed the cat", "Empty the dishwasher", "Homework"}, 0,
+			"Riley has 3 things to do today: Feed the cat, Empty the dishwasher and Homework."},
+		{"Riley", false, []string{"Homework"}, 2, "Riley has one thing to do today: Homework."},
+		{"Riley", false, nil, 2, "Riley is all done today."},
+		{"", true, nil, 0, "You have nothing to do today."},
+		{"", true, []string{"Laundry", "Trash"}, 0, "You have 2 things to do today: Laundry and Trash."},
+	}
+	for _, tt := range tests {
+		if got := tasksSpeech(tt.name, tt.self, tt.pending, tt.done, "today"); got != tt.want {
+			t.Errorf("tasksSpeech(%q, %v, %v, %d) = %q, want %q", tt.name, tt.self, tt.pending, tt.done, got, tt.want)
+		}
+	}
+}
+
+func TestDayPhrase(t *testing.T) {
+	today := time.Date(2024, 5, 17, 20, 0, 0, 0, time.UTC) // a Friday
+	tests := []struct {
+		day  time.Time
+		want string
+	}{
+		{today, "today"},
+		{time.Date(2024, 5, 18, 0, 0, 0, 0, time.UTC), "tomorrow"},
+		{time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC), "yesterday"},
+		{time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC), "on Monday"},
+		{time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), "on Saturday, June 1"},
+	}
+	for _, tt := range tests {
+		if got := dayPhrase(tt.day, today); got != tt.want {
+			t.Errorf("dayPhrase(%s) = %q, want %q", tt.day.Format("2006-01-02"), got, tt.want)
+		}
+	}
+}
+
+func TestMatchesName(t *testing.T) {
+	if !matchesName("Riley Smith", "riley") || !matchesName("Riley Smith", "Riley Smith") {
+		t.Error("expected first and full names to match")
+	}
+	if matchesName("Riley Smith", "Smith") || matchesName("Riley Smith", "Ril") {
+		t.Error("expected other names not to match")
+	}
+}
+
+func TestAlexaIntent(t *testing.T) {
+	var req alexaRequest
+	body := `{"request": {"type": "IntentRequest", "intent": {"name": "MemberTasksIntent",
+		"slots": {"member": {"name": "member", "value": "Riley"}, "date": {"name": "date"}}}}}`
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatal(err)
+	}
+	intent := alexaIntent(&req)
+	if intent.Name != IntentMemberTasks || intent.Slots["member"] != "Riley" || intent.Slots["date"] != "" {
+		t.Errorf("alexaIntent = %+v", intent)
+	}
+
+	req.Request.Intent.Name = "AMAZON.CancelIntent"
+	if intent := alexaIntent(&req); intent.Name != IntentStop {
+		t.Errorf("AMAZON.CancelIntent mapped to %q, want %q", intent.Name, IntentStop)
+	}
+}
+
+func TestAlexaHandlerChecksRequests(t *testing.T) {
+	now := time.Date(2024, 5, 17, 12, 0, 0, 0, time.UTC)
+	h := NewAlexaHandler(nil, nil, func() config.AlexaConfig {
+		return config.AlexaConfig{VoiceClientConfig: config.VoiceClientConfig{ClientID: "alexa"}, SkillID: "amzn1.ask.skill.ours"}
+	})
+	h.now = func() time.Time { return now }
+
+	post := func(skill string, timestamp time.Time, requestType string) *httptest.ResponseRecorder {
+		body := `{"context": {"System": {"application": {"applicationId": "` + skill + `"}}},
+			"request": {"type": "` + requestType + `", "timestamp": "` + timestamp.Format(time.RFC3339) + `"}}`
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/voice/alexa", strings.NewReader(body)))
+		return w
+	}
+
+	if w := post("amzn1.ask.skill.theirs", now, "LaunchRequest"); w.Code != http.StatusForbidden {
+		t.Errorf("other skill: status %d, want %d", w.Code, http.StatusForbidden)
+	}
+	if w := post("amzn1.ask.skill.ours", now.Add(-5*time.Minute), "LaunchRequest"); w.Code != http.StatusBadRequest {
+		t.Errorf("stale request: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	w := post("amzn1.ask.skill.ours", now, "LaunchRequest")
+	if w.Code != http.StatusOK {
+		t.Fatalf("unlinked launch: status %d", w.Code)
+	}
+	var resp alexaResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Response.Card == nil || resp.Response.Card.Type != "LinkAccount" || !resp.Response.ShouldEndSession {
+		t.Errorf("unlinked launch: want a LinkAccount card, got %+v", resp.Response)
+	}
+}
+
+func TestGoogleIntent(t *testing.T) {
+	var req googleRequest
+	body := `{"handler": {"name": "tasks"}, "intent": {"name": "MemberTasksIntent", "params": {
+		"member": {"original": "riley", "resolved": "Riley"},
+		"date": {"original": "tomorrow", "resolved": {"year": 2024, "month": 5, "day": 18}}}}}`
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatal(err)
+	}
+	intent := googleIntent(&req)
+	if intent.Name != IntentMemberTasks || intent.Slots["member"] != "Riley" || intent.Slots["date"] != "2024-05-18" {
+		t.Errorf("googleIntent = %+v", intent)
+	}
+
+	req.Intent.Name = ""
+	if intent := googleIntent(&req); intent.Name != "tasks" {
+		t.Errorf("without an intent, name = %q, want the handler's", intent.Name)
+	}
+}
+
+func TestWriteGoogleEndsConversation(t *testing.T) {
+	req := &googleRequest{}
+	req.Session.ID = "session-1"
+	req.Scene.Name = "Main"
+
+	w := httptest.NewRecorder()
+	writeGoogle(w, req, Reply{Speech: "Goodbye.", End: true})
+	var resp googleResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Session.ID != "session-1" || resp.Prompt.FirstSimple.Speech != "Goodbye." {
+		t.Errorf("response = %+v", resp)
+	}
+	if resp.Scene == nil || resp.Scene.Next.Name != "actions.scene.END_CONVERSATION" {
+		t.Errorf("expected the conversation to end, got %+v", resp.Scene)
+	}
+
+	w = httptest.NewRecorder()
+	writeGoogle(w, req, Reply{Speech: "What should I add?"})
+	if strings.Contains(w.Body.String(), "END_CONVERSATION") {
+		t.Error("expected the conversation to continue")
+	}
+}
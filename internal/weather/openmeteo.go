@@ -0,0 +1,152 @@
+// Package weather fetches daily forecasts from Open-Meteo
+// (https://open-meteo.com), which needs no API key.
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"famstack/internal/models"
+)
+
+// DefaultForecastURL is Open-Meteo's forecast API
+const DefaultForecastURL = "https://api.open-meteo.com/v1/forecast"
+
+// ForecastDays is how many days ahead, today included, forecasts reach
+const ForecastDays = 14
+
+// Client fetches forecasts
+type Client struct {
+	http        *http.Client
+	forecastURL string
+}
+
+// NewClient creates a client of the forecast API at forecastURL, Open-Meteo's
+// when empty
+func NewClient(httpClient *http.Client, forecastURL string) *Client {
+	if forecastURL == "" {
+		forecastURL = DefaultForecastURL
+	}
+	return &Client{http: httpClient, forecastURL: forecastURL}
+}
+
+// forecastResponse is the part of Open-Meteo's answer that is read. Values
+// are null for days the models don't reach.
+type forecastResponse struct {
+	Daily struct {
+		Time                []string   `json:"time"`
+		WeatherCode         []*int     `json:"weather_code"`
+		TemperatureMax      []*float64 `json:"temperature_2m_max"`
+		TemperatureMin      []*float64 `json:"temperature_2m_min"`
+		PrecipitationChance []*int     `json:"precipitation_probability_max"`
+	} `json:"daily"`
+}
+
+// Forecast returns the daily forecast at the coordinates, for days in the
+// timezone, starting today. Days the forecast doesn't cover are left out.
+func (c *Client) Forecast(ctx context.Context, latitude, longitude float64, timezone string) ([]models.DayForecast, error) {
+	query := url.Values{
+		"latitude":      {strconv.FormatFloat(latitude, 'f', 4, 64)},
+		"longitude":     {strconv.FormatFloat(longitude, 'f', 4, 64)},
+		"daily":         {"weather_code,temperature_2m_max,temperature_2m_min,precipitation_probability_max"},
+		"timezone":      {timezone},
+		"forecast_days": {strconv.Itoa(ForecastDays)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.forecastURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "FamStack-Weather/1.0")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch forecast: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read forecast: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("forecast service answered %s", resp.Status)
+	}
+
+	var parsed forecastResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode forecast: %w", err)
+	}
+
+	daily := parsed.Daily
+	now := time.Now().UTC()
+	var days []models.DayForecast
+	for i, date := range daily.Time {
+		if i >= len(daily.WeatherCode) || i >= len(daily.TemperatureMax) || i >= len(daily.TemperatureMin) {
+			break
+		}
+		if daily.WeatherCode[i] == nil || daily.TemperatureMax[i] == nil || daily.TemperatureMin[i] == nil {
+			continue
+		}
+		day := models.DayForecast{
+			Date:           date,
+			WeatherCode:    *daily.WeatherCode[i],
+			TemperatureMax: *daily.TemperatureMax[i],
+			TemperatureMin: *daily.TemperatureMin[i],
+			FetchedAt:      now,
+		}
+		if i < len(daily.PrecipitationChance) && daily.PrecipitationChance[i] != nil {
+			day.PrecipitationChance = *daily.PrecipitationChance[i]
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// Describe names a WMO weather code, as Open-Meteo reports it, and gives an
+// icon for it
+func Describe(code int) (summary, icon string) {
+	switch code {
+	case 0:
+		return "Clear", "☀️"
+	case 1:
+		return "Mostly clear", "🌤️"
+	case 2:
+		return "Partly cloudy", "⛅"
+	case 3:
+		return "Overcast", "☁️"
+	case 45, 48:
+		return "Fog", "🌫️"
+	case 51, 53, 55:
+		return "Drizzle", "🌦️"
+	case 56, 57, 66, 67:
+		return "Freezing rain", "🌧️"
+	case 61:
+		return "Light rain", "🌧️"
+	case 63:
+		return "Rain", "🌧️"
+	case 65:
+		return "Heavy rain", "🌧️"
+	case 71, 77:
+		return "Light snow", "🌨️"
+	case 73:
+		return "Snow", "🌨️"
+	case 75:
+		return "Heavy snow", "❄️"
+	case 80, 81:
+		return "Showers", "🌦️"
+	case 82:
+		return "Heavy showers", "🌧️"
+	case 85, 86:
+		return "Snow showers", "🌨️"
+	case 95:
+		return "Thunderstorms", "⛈️"
+	case 96, 99:
+		return "Thunderstorms with hail", "⛈️"
+	}
+	return "Unknown", "🌡️"
+}
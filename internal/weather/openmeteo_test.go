@@ -0,0 +1,67 @@
+package weather
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForecast(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "45.5200", query.Get("latitude"))
+		assert.Equal(t, "-122.6800", query.Get("longitude"))
+		assert.Equal(t, "America/Los_Angeles", query.Get("timezone"))
+		assert.Equal(t, "14", query.Get("forecast_days"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"daily": {
+			"time": ["2025-09-01", "2025-09-02", "2025-09-03"],
+			"weather_code": [61, 0, null],
+			"temperature_2m_max": [18.4, 24.1, null],
+			"temperature_2m_min": [11.2, 13.0, null],
+			"precipitation_probability_max": [80, null, null]
+		}}`))
+	}))
+	defer server.Close()
+
+	days, err := NewClient(server.Client(), server.URL).Forecast(context.Background(), 45.52, -122.68, "America/Los_Angeles")
+	require.NoError(t, err)
+	require.Len(t, days, 2, "days the forecast doesn't reach are left out")
+
+	assert.Equal(t, "2025-09-01", days[0].Date)
+	assert.Equal(t, 61, days[0].WeatherCode)
+	assert.Equal(t, 18.4, days[0].TemperatureMax)
+	assert.Equal(t, 11.2, days[0].TemperatureMin)
+	assert.Equal(t, 80, days[0].PrecipitationChance)
+	assert.False(t, days[0].FetchedAt.IsZero())
+
+	assert.Equal(t, "2025-09-02", days[1].Date)
+	assert.Equal(t, 0, days[1].PrecipitationChance)
+}
+
+func TestForecastServiceError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.Client(), server.URL).Forecast(context.Background(), 0, 0, "UTC")
+	assert.ErrorContains(t, err, "503")
+}
+
+func TestDescribe(t *testing.T) {
+	summary, icon := Describe(0)
+	assert.Equal(t, "Clear", summary)
+	assert.Equal(t, "☀️", icon)
+
+	summary, _ = Describe(96)
+	assert.Equal(t, "Thunderstorms with hail", summary)
+
+	summary, _ = Describe(42)
+	assert.Equal(t, "Unknown", summary)
+}
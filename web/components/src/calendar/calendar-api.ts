@@ -1,3 +1,5 @@
+import { RenderPreferences } from '../common/types.js';
+
 // Event attendee with display information for person identification
 export interface EventAttendee {
   id: string;
@@ -71,6 +73,7 @@ export interface DaysResponse {
     lastUpdated: string;
     maxDaysLimit: number;
   };
+  preferences?: RenderPreferences;
 }
 
 export interface GetDaysOptions {
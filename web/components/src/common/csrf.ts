@@ -0,0 +1,38 @@
+/**
+ * CSRF tokens for state-changing requests. The server keeps the token in the
+ * readable csrf_token cookie and expects it back in the X-CSRF-Token header.
+ * Reading the cookie on every request picks up the new token issued on login
+ * and logout; the token in the page config is only a fallback.
+ */
+
+export const CSRF_HEADER = 'X-CSRF-Token';
+
+const CSRF_COOKIE = 'csrf_token';
+
+/**
+ * Get the current CSRF token, or the fallback when the cookie is missing
+ */
+export function getCSRFToken(fallback = ''): string {
+  const match = document.cookie.match(new RegExp(`(?:^|;\\s*)${CSRF_COOKIE}=([^;]*)`));
+  return match ? decodeURIComponent(match[1]) : fallback;
+}
+
+/**
+ * Get the CSRF header to spread into a request's headers
+ */
+export function csrfHeaders(fallback = ''): Record<string, string> {
+  const token = getCSRFToken(fallback);
+  return token ? { [CSRF_HEADER]: token } : {};
+}
+
+/**
+ * Ask the server for a token, issuing one when the cookie has expired
+ */
+export async function refreshCSRFToken(): Promise<string> {
+  const response = await fetch('/auth/csrf', { credentials: 'same-origin' });
+  if (!response.ok) {
+    throw new Error('Failed to refresh CSRF token');
+  }
+  const data = await response.json();
+  return data.csrf_token;
+}
@@ -91,3 +91,23 @@ export const errorHandler = {
     );
   },
 };
+
+interface ApiFieldError {
+  field: string;
+  message: string;
+}
+
+/**
+ * Read the message from a failed API response. API errors arrive as
+ * {"error": {"code", "message", "field_errors"}}; a validation failure
+ * reads as its field messages.
+ */
+export async function apiErrorMessage(response: Response, fallback: string): Promise<string> {
+  const body = await response.json().catch(() => null);
+  const error = body?.error;
+  const fieldErrors: ApiFieldError[] | undefined = error?.field_errors;
+  if (fieldErrors?.length) {
+    return fieldErrors.map(fieldError => fieldError.message).join('; ');
+  }
+  return error?.message || body?.message || fallback;
+}
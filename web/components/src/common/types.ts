@@ -8,3 +8,12 @@ export interface ComponentConfig {
   apiBaseUrl: string;
   csrfToken: string;
 }
+
+// How the signed-in member wants dates, times and themselves drawn, as
+// calendar and task responses carry it
+export interface RenderPreferences {
+  color: string;
+  default_view: 'day' | 'week' | 'month' | 'agenda';
+  week_start: 'sunday' | 'monday';
+  clock: '12h' | '24h';
+}
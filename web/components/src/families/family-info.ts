@@ -10,6 +10,7 @@ import { buttonStyles, formStyles } from '../common/shared-styles.js';
 import { errorHandler } from '../common/error-handler.js';
 import { familyContext, Family } from './family-context.js';
 import { showToast } from '../common/toast-notification.js';
+import { csrfHeaders } from '../common/csrf.js';
 
 @customElement('family-info')
 export class FamilyInfo extends LitElement {
@@ -167,6 +168,7 @@ export class FamilyInfo extends LitElement {
             method: 'PATCH',
             headers: {
               'Content-Type': 'application/json',
+              ...csrfHeaders(),
             },
             body: JSON.stringify({ name }),
           });
@@ -202,6 +204,7 @@ export class FamilyInfo extends LitElement {
             method: 'POST',
             headers: {
               'Content-Type': 'application/json',
+              ...csrfHeaders(),
             },
             body: JSON.stringify({ name }),
           });
@@ -85,6 +85,8 @@ export class FamilyMemberModal extends LitElement {
     const memberType = formData.get('member_type') as string;
     const age = formData.get('age') as string;
     const avatarUrl = (formData.get('avatar_url') as string).trim();
+    const birthdate = formData.get('birthdate') as string;
+    const anniversary = formData.get('anniversary') as string;
 
     if (!name) {
       this.errorMessage = 'Name is required';
@@ -109,6 +111,9 @@ export class FamilyMemberModal extends LitElement {
       }
     }
     if (avatarUrl) data.avatar_url = avatarUrl;
+    // Empty dates are sent when editing, to clear them
+    if (birthdate || this.member?.birthdate) data.birthdate = birthdate;
+    if (anniversary || this.member?.anniversary) data.anniversary = anniversary;
 
     this.isSubmitting = true;
     this.errorMessage = '';
@@ -203,6 +208,26 @@ export class FamilyMemberModal extends LitElement {
                 />
               </div>
 
+              <div class="form-group">
+                <label for="member-birthdate">Birthday</label>
+                <input
+                  type="date"
+                  id="member-birthdate"
+                  name="birthdate"
+                  .value=${this.member?.birthdate || ''}
+                />
+              </div>
+
+              <div class="form-group">
+                <label for="member-anniversary">Anniversary</label>
+                <input
+                  type="date"
+                  id="member-anniversary"
+                  name="anniversary"
+                  .value=${this.member?.anniversary || ''}
+                />
+              </div>
+
               <div class="form-group">
                 <label for="member-avatar-url">Avatar URL</label>
                 <input
@@ -1,4 +1,5 @@
 import { ComponentConfig } from '../common/types.js';
+import { csrfHeaders } from '../common/csrf.js';
 
 export interface FamilyMember {
   id: string;
@@ -8,6 +9,8 @@ export interface FamilyMember {
   member_type: 'adult' | 'child' | 'pet';
   age?: number;
   avatar_url?: string;
+  birthdate?: string;
+  anniversary?: string;
   user_id?: string;
   display_order: number;
   is_active: boolean;
@@ -28,6 +31,8 @@ export interface CreateFamilyMemberRequest {
   member_type: 'adult' | 'child' | 'pet';
   age?: number;
   avatar_url?: string;
+  birthdate?: string;
+  anniversary?: string;
   display_order?: number;
 }
 
@@ -54,7 +59,7 @@ export class FamilyService {
       method: 'GET',
       headers: {
         'Content-Type': 'application/json',
-        'X-CSRF-Token': this.config.csrfToken,
+        ...csrfHeaders(this.config.csrfToken),
       },
     });
 
@@ -70,7 +75,7 @@ export class FamilyService {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
-        'X-CSRF-Token': this.config.csrfToken,
+        ...csrfHeaders(this.config.csrfToken),
       },
       body: JSON.stringify(familyData),
     });
@@ -88,7 +93,7 @@ export class FamilyService {
       method: 'GET',
       headers: {
         'Content-Type': 'application/json',
-        'X-CSRF-Token': this.config.csrfToken,
+        ...csrfHeaders(this.config.csrfToken),
       },
     });
 
@@ -104,7 +109,7 @@ export class FamilyService {
       method: 'PATCH',
       headers: {
         'Content-Type': 'application/json',
-        'X-CSRF-Token': this.config.csrfToken,
+        ...csrfHeaders(this.config.csrfToken),
       },
       body: JSON.stringify(updates),
     });
@@ -120,7 +125,7 @@ export class FamilyService {
     const response = await fetch(`${this.config.apiBaseUrl}/families/${familyId}`, {
       method: 'DELETE',
       headers: {
-        'X-CSRF-Token': this.config.csrfToken,
+        ...csrfHeaders(this.config.csrfToken),
       },
     });
 
@@ -135,7 +140,7 @@ export class FamilyService {
       method: 'GET',
       headers: {
         'Content-Type': 'application/json',
-        'X-CSRF-Token': this.config.csrfToken,
+        ...csrfHeaders(this.config.csrfToken),
       },
     });
 
@@ -156,7 +161,7 @@ export class FamilyService {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
-        'X-CSRF-Token': this.config.csrfToken,
+        ...csrfHeaders(this.config.csrfToken),
       },
       body: JSON.stringify(memberData),
     });
@@ -176,7 +181,7 @@ export class FamilyService {
         method: 'GET',
         headers: {
           'Content-Type': 'application/json',
-          'X-CSRF-Token': this.config.csrfToken,
+          ...csrfHeaders(this.config.csrfToken),
         },
       }
     );
@@ -199,7 +204,7 @@ export class FamilyService {
         method: 'PATCH',
         headers: {
           'Content-Type': 'application/json',
-          'X-CSRF-Token': this.config.csrfToken,
+          ...csrfHeaders(this.config.csrfToken),
         },
         body: JSON.stringify(updates),
       }
@@ -218,7 +223,7 @@ export class FamilyService {
       {
         method: 'DELETE',
         headers: {
-          'X-CSRF-Token': this.config.csrfToken,
+          ...csrfHeaders(this.config.csrfToken),
         },
       }
     );
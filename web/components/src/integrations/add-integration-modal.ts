@@ -139,7 +139,7 @@ export class AddIntegrationModal extends LitElement {
       auth_method: this.selectedAuthMethod || null,
       display_name: formData.get('display_name'),
       description: formData.get('description') || '',
-      settings: {},
+      settings: this.selectedProvider === 'ics' ? { url: formData.get('feed_url') } : {},
     };
 
     this.dispatchEvent(
@@ -232,6 +232,21 @@ export class AddIntegrationModal extends LitElement {
                 </select>
               </div>
 
+              ${this.selectedProvider === 'ics'
+                ? html`
+                    <div class="form-group">
+                      <label for="feed-url">Calendar Feed URL</label>
+                      <input
+                        type="url"
+                        id="feed-url"
+                        name="feed_url"
+                        required
+                        placeholder="webcal://school.example.org/calendar.ics"
+                      />
+                    </div>
+                  `
+                : ''}
+
               <div class="form-group">
                 <label for="display-name">Display Name</label>
                 <input
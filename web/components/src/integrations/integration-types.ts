@@ -58,6 +58,7 @@ export const INTEGRATION_CATEGORIES: IntegrationCategory[] = [
       { value: 'microsoft', label: 'Microsoft Outlook', auth: 'oauth2' },
       { value: 'apple', label: 'Apple Calendar', auth: 'oauth2' },
       { value: 'caldav', label: 'CalDAV', auth: 'basic_auth' },
+      { value: 'ics', label: 'ICS Subscription', auth: 'token' },
     ],
   },
   {
@@ -90,6 +91,7 @@ export const INTEGRATION_CATEGORIES: IntegrationCategory[] = [
       { value: 'homekit', label: 'Apple HomeKit', auth: 'api_key' },
       { value: 'alexa', label: 'Amazon Alexa', auth: 'oauth2' },
       { value: 'google_home', label: 'Google Home', auth: 'oauth2' },
+      { value: 'mqtt', label: 'MQTT', auth: 'token' },
     ],
   },
   {
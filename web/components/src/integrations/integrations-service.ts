@@ -14,6 +14,8 @@
 import { Integration, OAuthConfig } from './integration-types.js';
 import { ComponentConfig } from '../common/types.js';
 import { logger } from '../common/logger.js';
+import { apiErrorMessage } from '../common/error-handler.js';
+import { csrfHeaders } from '../common/csrf.js';
 
 // Backend API response types
 interface BackendIntegrationResponse {
@@ -61,13 +63,10 @@ export class IntegrationsService {
    * Get common headers including CSRF token
    */
   private getHeaders(): Record<string, string> {
-    const headers: Record<string, string> = {
+    return {
       'Content-Type': 'application/json',
+      ...csrfHeaders(this.config?.csrfToken),
     };
-    if (this.config?.csrfToken) {
-      headers['X-CSRF-Token'] = this.config.csrfToken;
-    }
-    return headers;
   }
 
   /**
@@ -210,8 +209,9 @@ export class IntegrationsService {
     });
 
     if (!response.ok) {
-      const error = await response.json().catch(() => ({}));
-      throw new Error(error.message || `Failed to create integration: ${response.statusText}`);
+      throw new Error(
+        await apiErrorMessage(response, `Failed to create integration: ${response.statusText}`)
+      );
     }
 
     const backendIntegration = await response.json();
@@ -236,8 +236,9 @@ export class IntegrationsService {
     });
 
     if (!response.ok) {
-      const error = await response.json().catch(() => ({}));
-      throw new Error(error.message || `Failed to update integration: ${response.statusText}`);
+      throw new Error(
+        await apiErrorMessage(response, `Failed to update integration: ${response.statusText}`)
+      );
     }
 
     const backendIntegration = await response.json();
@@ -254,8 +255,9 @@ export class IntegrationsService {
     });
 
     if (!response.ok) {
-      const error = await response.json().catch(() => ({}));
-      throw new Error(error.message || `Failed to delete integration: ${response.statusText}`);
+      throw new Error(
+        await apiErrorMessage(response, `Failed to delete integration: ${response.statusText}`)
+      );
     }
   }
 
@@ -269,8 +271,9 @@ export class IntegrationsService {
     });
 
     if (!response.ok) {
-      const error = await response.json().catch(() => ({}));
-      throw new Error(error.message || `Failed to sync integration: ${response.statusText}`);
+      throw new Error(
+        await apiErrorMessage(response, `Failed to sync integration: ${response.statusText}`)
+      );
     }
 
     return response.json();
@@ -286,8 +289,9 @@ export class IntegrationsService {
     });
 
     if (!response.ok) {
-      const error = await response.json().catch(() => ({}));
-      throw new Error(error.message || `Failed to test integration: ${response.statusText}`);
+      throw new Error(
+        await apiErrorMessage(response, `Failed to test integration: ${response.statusText}`)
+      );
     }
 
     return response.json();
@@ -320,8 +324,9 @@ export class IntegrationsService {
     });
 
     if (!response.ok) {
-      const error = await response.json().catch(() => ({}));
-      throw new Error(error.message || `Failed to update OAuth config: ${response.statusText}`);
+      throw new Error(
+        await apiErrorMessage(response, `Failed to update OAuth config: ${response.statusText}`)
+      );
     }
 
     return response.json();
@@ -337,8 +342,9 @@ export class IntegrationsService {
     });
 
     if (!response.ok) {
-      const error = await response.json().catch(() => ({}));
-      throw new Error(error.message || `Failed to initiate OAuth: ${response.statusText}`);
+      throw new Error(
+        await apiErrorMessage(response, `Failed to initiate OAuth: ${response.statusText}`)
+      );
     }
 
     return response.json();
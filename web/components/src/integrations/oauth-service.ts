@@ -13,6 +13,7 @@
 
 import { OAuthConfig } from './integration-types.js';
 import { ComponentConfig } from '../common/types.js';
+import { csrfHeaders } from '../common/csrf.js';
 
 export class OAuthService {
   private baseUrl = '/api/v1';
@@ -26,13 +27,10 @@ export class OAuthService {
    * Get common headers including CSRF token
    */
   private getHeaders(): Record<string, string> {
-    const headers: Record<string, string> = {
+    return {
       'Content-Type': 'application/json',
+      ...csrfHeaders(this.config?.csrfToken),
     };
-    if (this.config?.csrfToken) {
-      headers['X-CSRF-Token'] = this.config.csrfToken;
-    }
-    return headers;
   }
 
   /**
@@ -8,6 +8,7 @@ import { logger } from '../common/logger.js';
 
 export class LoginPage extends BasePage {
   private authManager: any;
+  private submitLabel = 'Sign In';
 
   constructor(container: HTMLElement, config: ComponentConfig) {
     super(container, config, 'login');
@@ -17,6 +18,20 @@ export class LoginPage extends BasePage {
   async init(): Promise<void> {
     this.render();
     this.setupEventListeners();
+
+    await this.renderPinProfiles();
+
+    const params = new URLSearchParams(window.location.search);
+    // An emailed link that didn't work lands back here
+    if (params.get('error') === 'magic_link') {
+      this.showError('That sign-in link has expired or was already used. Ask for a new one below.');
+    }
+
+    // So does an emailed invitation
+    const invite = params.get('invite');
+    if (invite) {
+      await this.renderInvitation(invite);
+    }
   }
 
   private render(): void {
@@ -30,6 +45,8 @@ export class LoginPage extends BasePage {
 
           <div id="message-container"></div>
 
+          <div id="pin-profiles" class="pin-profiles" hidden></div>
+
           <form id="login-form">
             <div class="form-group">
               <label for="email" class="form-label">Email</label>
@@ -42,6 +59,23 @@ export class LoginPage extends BasePage {
             <button type="submit" class="login-button" id="login-submit">Sign In</button>
           </form>
 
+          <div class="login-alt">
+            <p class="login-alt-title">No password? Sign in with a code from a parent or a link by email.</p>
+            <form id="code-form" class="login-alt-form">
+              <input type="text" id="code" name="code" class="form-input" placeholder="ABCD-2345" required autocomplete="one-time-code" autocapitalize="characters">
+              <button type="submit" class="login-alt-button">Use Code</button>
+            </form>
+            <form id="magic-link-form" class="login-alt-form">
+              <input type="email" id="magic-email" name="email" class="form-input" placeholder="Email" required autocomplete="email">
+              <button type="submit" class="login-alt-button">Email Me a Link</button>
+            </form>
+            <p class="login-alt-title">Invited to join your family? Enter the invitation code.</p>
+            <form id="invite-code-form" class="login-alt-form">
+              <input type="text" id="invite-code" name="code" class="form-input" placeholder="ABCD-2345" required autocapitalize="characters">
+              <button type="submit" class="login-alt-button">Join</button>
+            </form>
+          </div>
+
           <div class="login-footer">
             <p class="login-help">
               Need help? Contact your family administrator.
@@ -143,6 +177,65 @@ export class LoginPage extends BasePage {
           cursor: not-allowed;
         }
 
+        .pin-profiles {
+          margin-bottom: 1.5rem;
+          padding-bottom: 1.5rem;
+          border-bottom: 1px solid #e5e7eb;
+        }
+
+        .pin-profile-list {
+          display: flex;
+          flex-wrap: wrap;
+          gap: 0.5rem;
+          margin-bottom: 0.75rem;
+        }
+
+        .pin-profile {
+          background: #eef2ff;
+          color: #374151;
+          border: 2px solid transparent;
+          border-radius: 0.5rem;
+          padding: 0.5rem 0.75rem;
+          font-size: 1rem;
+          cursor: pointer;
+        }
+
+        .pin-profile.selected {
+          border-color: #6366f1;
+        }
+
+        .login-alt {
+          margin-top: 0.5rem;
+        }
+
+        .login-alt-title {
+          color: #6b7280;
+          font-size: 0.875rem;
+          margin: 0 0 0.75rem 0;
+        }
+
+        .login-alt-form {
+          display: flex;
+          gap: 0.5rem;
+          margin-bottom: 0.5rem;
+        }
+
+        .login-alt-button {
+          flex-shrink: 0;
+          background: white;
+          color: #6366f1;
+          border: 1px solid #6366f1;
+          padding: 0 0.75rem;
+          border-radius: 0.5rem;
+          font-size: 0.875rem;
+          font-weight: 500;
+          cursor: pointer;
+        }
+
+        .login-alt-button:hover {
+          background: #eef2ff;
+        }
+
         .login-footer {
           text-align: center;
           margin-top: 1.5rem;
@@ -208,6 +301,17 @@ export class LoginPage extends BasePage {
     if (form) {
       form.addEventListener('submit', e => this.handleLogin(e));
     }
+    document.getElementById('code-form')?.addEventListener('submit', e => this.handleCode(e));
+    document
+      .getElementById('magic-link-form')
+      ?.addEventListener('submit', e => this.handleMagicLink(e));
+    document.getElementById('invite-code-form')?.addEventListener('submit', e => {
+      e.preventDefault();
+      const code = (new FormData(e.target as HTMLFormElement).get('code') as string).trim();
+      if (code) {
+        this.renderInvitation(code);
+      }
+    });
 
     // Auto-focus email input
     const emailInput = document.getElementById('email') as HTMLInputElement;
@@ -236,45 +340,211 @@ export class LoginPage extends BasePage {
       const success = await this.authManager.login({ email, password });
 
       if (success) {
-        this.showSuccess('Login successful! Redirecting...');
-
-        // Wait a moment then redirect
-        setTimeout(() => {
-          const appContainer = document.getElementById('app');
-          const router = (window as any).famstackApp?.getRouter();
-
-          logger.debug('Login redirect debug', {
-            appContainerExists: !!appContainer,
-            routerExists: !!router,
-            currentLocation: window.location.pathname,
-          });
-
-          if (!appContainer) {
-            logger.error('App container not found during redirect');
-            return;
-          }
+        this.redirectAfterLogin();
+      }
+    } catch (error) {
+      const message = error instanceof Error ? error.message : 'Login failed';
+      this.showError(message);
+    } finally {
+      this.setLoading(false);
+    }
+  }
 
-          if (router) {
-            logger.debug('Calling router.navigate("/tasks")');
-            try {
-              router.navigate('/tasks');
-              logger.debug('router.navigate() completed');
-            } catch (error) {
-              logger.error('Error during navigation:', error);
-            }
-          } else {
-            logger.error('Router not found during redirect');
-          }
-        }, 500);
+  /**
+   * On a device a parent trusted, show who can switch in with a PIN
+   */
+  private async renderPinProfiles(): Promise<void> {
+    const container = document.getElementById('pin-profiles');
+    const profiles = await this.authManager.listPinProfiles();
+    if (!container || profiles.length === 0) {
+      return;
+    }
+
+    container.innerHTML = `
+      <p class="login-alt-title">Who's using FamStack?</p>
+      <div class="pin-profile-list"></div>
+      <form id="pin-form" class="login-alt-form" hidden>
+        <input type="password" id="pin" name="pin" class="form-input" placeholder="PIN" required
+          inputmode="numeric" pattern="[0-9]{4}" maxlength="4" autocomplete="off">
+        <button type="submit" class="login-alt-button">Go</button>
+      </form>
+    `;
+    container.hidden = false;
+
+    const list = container.querySelector('.pin-profile-list') as HTMLElement;
+    const form = container.querySelector('#pin-form') as HTMLFormElement;
+    let memberId = '';
+
+    for (const profile of profiles) {
+      const button = document.createElement('button');
+      button.type = 'button';
+      button.className = 'pin-profile';
+      button.textContent = profile.first_name;
+      button.addEventListener('click', () => {
+        memberId = profile.member_id;
+        list.querySelectorAll('.pin-profile').forEach(el => el.classList.remove('selected'));
+        button.classList.add('selected');
+        form.hidden = false;
+        (form.querySelector('#pin') as HTMLInputElement).focus();
+      });
+      list.appendChild(button);
+    }
+
+    form.addEventListener('submit', e => this.handlePin(e, memberId));
+  }
+
+  private async handlePin(event: Event, memberId: string): Promise<void> {
+    event.preventDefault();
+
+    const form = event.target as HTMLFormElement;
+    const pin = new FormData(form).get('pin') as string;
+
+    this.setLoading(true);
+    this.clearMessages();
+
+    try {
+      if (await this.authManager.loginWithPin(memberId, pin)) {
+        this.redirectAfterLogin();
       }
     } catch (error) {
       const message = error instanceof Error ? error.message : 'Login failed';
       this.showError(message);
+      form.reset();
+    } finally {
+      this.setLoading(false);
+    }
+  }
+
+  private async handleCode(event: Event): Promise<void> {
+    event.preventDefault();
+
+    const code = (new FormData(event.target as HTMLFormElement).get('code') as string).trim();
+    if (!code) {
+      this.showError('Please enter your code');
+      return;
+    }
+
+    this.setLoading(true);
+    this.clearMessages();
+
+    try {
+      if (await this.authManager.loginWithCode(code)) {
+        this.redirectAfterLogin();
+      }
+    } catch (error) {
+      const message = error instanceof Error ? error.message : 'Login failed';
+      this.showError(message);
+    } finally {
+      this.setLoading(false);
+    }
+  }
+
+  private async handleMagicLink(event: Event): Promise<void> {
+    event.preventDefault();
+
+    const email = (new FormData(event.target as HTMLFormElement).get('email') as string).trim();
+    if (!email) {
+      this.showError('Please enter your email');
+      return;
+    }
+
+    this.setLoading(true);
+    this.clearMessages();
+
+    try {
+      this.showSuccess(await this.authManager.requestMagicLink(email));
+    } catch (error) {
+      const message = error instanceof Error ? error.message : 'Could not send a sign-in link';
+      this.showError(message);
     } finally {
       this.setLoading(false);
     }
   }
 
+  /**
+   * Swap the sign-in forms for one accepting an invitation
+   */
+  private async renderInvitation(token: string): Promise<void> {
+    this.clearMessages();
+    const invitation = await this.authManager.getInvitation(token);
+    if (!invitation) {
+      this.showError('That invitation has expired, was revoked, or was already used. Ask for a new one.');
+      return;
+    }
+
+    const form = document.getElementById('login-form') as HTMLFormElement;
+    document.querySelector('.login-alt')?.remove();
+    document.getElementById('pin-profiles')?.remove();
+
+    const subtitle = document.querySelector('.login-subtitle');
+    if (subtitle) {
+      subtitle.textContent = `Join ${invitation.family_name} as ${invitation.first_name}`;
+    }
+
+    const replacement = form.cloneNode(true) as HTMLFormElement;
+    form.replaceWith(replacement);
+    const email = replacement.querySelector('#email') as HTMLInputElement;
+    const password = replacement.querySelector('#password') as HTMLInputElement;
+    password.autocomplete = 'new-password';
+    password.minLength = 8;
+    if (invitation.email) {
+      email.value = invitation.email;
+      email.readOnly = true;
+    }
+    this.submitLabel = 'Join';
+    (replacement.querySelector('#login-submit') as HTMLButtonElement).textContent = this.submitLabel;
+
+    replacement.addEventListener('submit', async e => {
+      e.preventDefault();
+      this.setLoading(true);
+      this.clearMessages();
+      try {
+        if (await this.authManager.acceptInvitation(token, email.value, password.value)) {
+          this.redirectAfterLogin();
+        }
+      } catch (error) {
+        const message = error instanceof Error ? error.message : 'Could not accept the invitation';
+        this.showError(message);
+      } finally {
+        this.setLoading(false);
+      }
+    });
+    (invitation.email ? password : email).focus();
+  }
+
+  private redirectAfterLogin(): void {
+    this.showSuccess('Login successful! Redirecting...');
+
+    // Wait a moment then redirect
+    setTimeout(() => {
+      const appContainer = document.getElementById('app');
+      const router = (window as any).famstackApp?.getRouter();
+
+      logger.debug('Login redirect debug', {
+        appContainerExists: !!appContainer,
+        routerExists: !!router,
+        currentLocation: window.location.pathname,
+      });
+
+      if (!appContainer) {
+        logger.error('App container not found during redirect');
+        return;
+      }
+
+      if (router) {
+        logger.debug('Calling router.navigate("/tasks")');
+        try {
+          router.navigate('/tasks');
+          logger.debug('router.navigate() completed');
+        } catch (error) {
+          logger.error('Error during navigation:', error);
+        }
+      } else {
+        logger.error('Router not found during redirect');
+      }
+    }, 500);
+  }
+
   protected override showError(message: string): void {
     this.showMessage(message, 'error');
   }
@@ -307,7 +577,7 @@ export class LoginPage extends BasePage {
 
     if (button) {
       button.disabled = loading;
-      button.textContent = loading ? 'Signing In...' : 'Sign In';
+      button.textContent = loading ? 'Signing In...' : this.submitLabel;
     }
 
     if (card) {
@@ -1,4 +1,6 @@
 import { ComponentConfig } from '../common/types.js';
+import { apiErrorMessage } from '../common/error-handler.js';
+import { csrfHeaders } from '../common/csrf.js';
 
 export interface TaskSchedule {
   id: string;
@@ -54,7 +56,7 @@ export class ScheduleService {
       method: 'GET',
       headers: {
         'Content-Type': 'application/json',
-        'X-CSRF-Token': this.config.csrfToken,
+        ...csrfHeaders(this.config.csrfToken),
       },
       credentials: 'include', // Include authentication cookies
     });
@@ -77,15 +79,16 @@ export class ScheduleService {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
-        'X-CSRF-Token': this.config.csrfToken,
+        ...csrfHeaders(this.config.csrfToken),
       },
       credentials: 'include',
       body: JSON.stringify(apiData),
     });
 
     if (!response.ok) {
-      const errorText = await response.text();
-      throw new Error(errorText || `Failed to create schedule: ${response.statusText}`);
+      throw new Error(
+        await apiErrorMessage(response, `Failed to create schedule: ${response.statusText}`)
+      );
     }
 
     return response.json();
@@ -96,7 +99,7 @@ export class ScheduleService {
       method: 'GET',
       headers: {
         'Content-Type': 'application/json',
-        'X-CSRF-Token': this.config.csrfToken,
+        ...csrfHeaders(this.config.csrfToken),
       },
       credentials: 'include',
     });
@@ -116,7 +119,7 @@ export class ScheduleService {
       method: 'PATCH',
       headers: {
         'Content-Type': 'application/json',
-        'X-CSRF-Token': this.config.csrfToken,
+        ...csrfHeaders(this.config.csrfToken),
       },
       credentials: 'include',
       body: JSON.stringify(updates),
@@ -133,7 +136,7 @@ export class ScheduleService {
     const response = await fetch(`${this.config.apiBaseUrl}/schedules/${scheduleId}`, {
       method: 'DELETE',
       headers: {
-        'X-CSRF-Token': this.config.csrfToken,
+        ...csrfHeaders(this.config.csrfToken),
       },
       credentials: 'include',
     });
@@ -4,6 +4,7 @@
  */
 
 import { logger } from '../common/logger.js';
+import { csrfHeaders, refreshCSRFToken } from '../common/csrf.js';
 
 export interface User {
   id: string;
@@ -34,6 +35,20 @@ export interface LoginCredentials {
   password: string;
 }
 
+export interface PinProfile {
+  member_id: string;
+  first_name: string;
+  avatar_url?: string;
+}
+
+export interface InvitationDetails {
+  family_name: string;
+  first_name: string;
+  role: string;
+  email?: string;
+  expires_at: string;
+}
+
 export class AuthManager {
   private token: string | null = null;
   private user: User | null = null;
@@ -79,14 +94,85 @@ export class AuthManager {
    * Login with email and password
    */
   async login(credentials: LoginCredentials): Promise<boolean> {
+    return this.signIn('/auth/login', credentials);
+  }
+
+  /**
+   * Login with a one-time code a parent created
+   */
+  async loginWithCode(code: string): Promise<boolean> {
+    return this.signIn('/auth/magic', { code });
+  }
+
+  /**
+   * Login with a PIN on a device a parent trusted
+   */
+  async loginWithPin(memberId: string, pin: string): Promise<boolean> {
+    return this.signIn('/auth/pin', { member_id: memberId, pin });
+  }
+
+  /**
+   * The members who can sign in with a PIN here, empty when the device isn't
+   * trusted
+   */
+  async listPinProfiles(): Promise<PinProfile[]> {
     try {
-      const response = await fetch('/auth/login', {
-        method: 'POST',
-        headers: {
-          'Content-Type': 'application/json',
-        },
-        body: JSON.stringify(credentials),
+      const response = await fetch('/auth/pin', { credentials: 'include' });
+      return response.ok ? await response.json() : [];
+    } catch (error) {
+      logger.error('Failed to load PIN profiles:', error);
+      return [];
+    }
+  }
+
+  /**
+   * Who an invitation link or code is for, or null when it no longer works
+   */
+  async getInvitation(token: string): Promise<InvitationDetails | null> {
+    try {
+      const response = await fetch(`/auth/invite/${encodeURIComponent(token)}`, {
+        headers: { Accept: 'application/json' },
       });
+      return response.ok ? await response.json() : null;
+    } catch (error) {
+      logger.error('Failed to load invitation:', error);
+      return null;
+    }
+  }
+
+  /**
+   * Accept an invitation, setting the member's email and password
+   */
+  async acceptInvitation(token: string, email: string, password: string): Promise<boolean> {
+    return this.signIn(`/auth/invite/${encodeURIComponent(token)}`, { email, password });
+  }
+
+  /**
+   * Ask for a sign-in link to be emailed. The server answers the same whether
+   * or not the email can use one.
+   */
+  async requestMagicLink(email: string): Promise<string> {
+    let response = await this.post('/auth/magic/request', { email });
+    if (response.status === 403) {
+      await refreshCSRFToken();
+      response = await this.post('/auth/magic/request', { email });
+    }
+
+    const data = await response.json();
+    if (!response.ok) {
+      throw new Error(data.message || 'Could not send a sign-in link');
+    }
+    return data.message;
+  }
+
+  private async signIn(url: string, body: object): Promise<boolean> {
+    try {
+      let response = await this.post(url, body);
+      if (response.status === 403) {
+        // The CSRF cookie expired while the page was open
+        await refreshCSRFToken();
+        response = await this.post(url, body);
+      }
 
       if (response.ok) {
         const serverResponse = await response.json();
@@ -116,6 +202,17 @@ export class AuthManager {
     }
   }
 
+  private post(url: string, body: object): Promise<Response> {
+    return fetch(url, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+        ...csrfHeaders(),
+      },
+      body: JSON.stringify(body),
+    });
+  }
+
   /**
    * Logout user
    */
@@ -221,7 +318,7 @@ export class AuthManager {
    * Get headers for authenticated requests
    */
   getAuthHeaders(): Record<string, string> {
-    const headers: Record<string, string> = {};
+    const headers: Record<string, string> = { ...csrfHeaders() };
 
     if (this.token) {
       headers['Authorization'] = `Bearer ${this.token}`;
@@ -103,6 +103,18 @@ export class SPARouter {
 
     // Update navigation active states
     this.updateNavigationState(path);
+
+    if (isAuthenticated) {
+      this.sendPageViewBeacon(path);
+    }
+  }
+
+  // Counts the page view locally; the server drops it unless analytics are enabled
+  private sendPageViewBeacon(path: string): void {
+    if (typeof navigator.sendBeacon !== 'function') {
+      return;
+    }
+    navigator.sendBeacon('/api/v1/analytics/beacon', JSON.stringify({ page: path }));
   }
 
   private findRoute(path: string): Route | undefined {
@@ -1,4 +1,6 @@
-import { ComponentConfig } from '../common/types.js';
+import { ComponentConfig, RenderPreferences } from '../common/types.js';
+import { apiErrorMessage } from '../common/error-handler.js';
+import { csrfHeaders } from '../common/csrf.js';
 import { Task } from './task-types.js';
 
 export interface TaskColumn {
@@ -13,6 +15,7 @@ export interface TaskColumn {
 export interface TasksResponse {
   tasks_by_member: { [key: string]: TaskColumn };
   date: string;
+  preferences?: RenderPreferences;
 }
 
 export interface CreateTaskData {
@@ -62,14 +65,13 @@ export class TaskService {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
-        'X-CSRF-Token': this.config.csrfToken,
+        ...csrfHeaders(this.config.csrfToken),
       },
       body: JSON.stringify(apiData),
     });
 
     if (!response.ok) {
-      const error = await response.json();
-      throw new Error(error.details || 'Failed to create task');
+      throw new Error(await apiErrorMessage(response, 'Failed to create task'));
     }
 
     return await response.json();
@@ -83,7 +85,7 @@ export class TaskService {
       method: 'PATCH',
       headers: {
         'Content-Type': 'application/json',
-        'X-CSRF-Token': this.config.csrfToken,
+        ...csrfHeaders(this.config.csrfToken),
       },
       body: JSON.stringify(updates),
     });
@@ -99,7 +101,7 @@ export class TaskService {
     const response = await fetch(`${this.config.apiBaseUrl}/tasks/${taskId}`, {
       method: 'DELETE',
       headers: {
-        'X-CSRF-Token': this.config.csrfToken,
+        ...csrfHeaders(this.config.csrfToken),
       },
     });
 